@@ -0,0 +1,19 @@
+package scoutpb
+
+// Event log levels carried in LogEvent.level.
+const (
+	EventLevelCritical    = "critical"
+	EventLevelError       = "error"
+	EventLevelWarning     = "warning"
+	EventLevelInformation = "information"
+	EventLevelVerbose     = "verbose"
+)
+
+// EventLevels lists the event log levels, most severe first.
+var EventLevels = []string{
+	EventLevelCritical,
+	EventLevelError,
+	EventLevelWarning,
+	EventLevelInformation,
+	EventLevelVerbose,
+}
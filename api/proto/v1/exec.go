@@ -0,0 +1,81 @@
+package scoutpb
+
+import "strings"
+
+// Command types carried in Command.type on the CommandStream RPC.
+const (
+	// CommandTypeExec runs an allowlisted executable; the payload is an ExecPayload.
+	CommandTypeExec = "exec"
+	// CommandTypeCheck runs a monitoring check from the agent's network
+	// location; the payload is a CheckPayload.
+	CommandTypeCheck = "check"
+	// CommandTypeEnd marks the end of the pending commands for this stream.
+	CommandTypeEnd = "end"
+)
+
+// AgentIDMetadataKey is the gRPC metadata key an agent uses to identify
+// itself when opening a CommandStream.
+const AgentIDMetadataKey = "x-agent-id"
+
+// ExecPayload is the JSON payload of a CommandTypeExec command.
+type ExecPayload struct {
+	Command        string   `json:"command"`
+	Args           []string `json:"args,omitempty"`
+	TimeoutSeconds int      `json:"timeout_seconds"`
+}
+
+// ExecAllowed reports whether command with args matches one of patterns.
+// Each pattern is a full command line: the executable followed by its
+// arguments, separated by spaces. Arguments must match one for one; a "*"
+// matches any single argument that does not start with "-", so a pattern
+// can leave a host or path open without admitting extra options.
+func ExecAllowed(patterns []string, command string, args []string) bool {
+	for _, p := range patterns {
+		if matchExecPattern(strings.Fields(p), command, args) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchExecPattern(pattern []string, command string, args []string) bool {
+	if len(pattern) == 0 || pattern[0] != command || len(pattern)-1 != len(args) {
+		return false
+	}
+	for i, want := range pattern[1:] {
+		if want == "*" {
+			if args[i] == "" || strings.HasPrefix(args[i], "-") {
+				return false
+			}
+			continue
+		}
+		if args[i] != want {
+			return false
+		}
+	}
+	return true
+}
+
+// ExecOutput is the JSON output of a CommandTypeExec command, sent in
+// CommandResponse.output.
+type ExecOutput struct {
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+}
+
+// CheckPayload is the JSON payload of a CommandTypeCheck command.
+type CheckPayload struct {
+	CheckType      string `json:"check_type"` // icmp, tcp, or http
+	Target         string `json:"target"`
+	TimeoutSeconds int    `json:"timeout_seconds"`
+}
+
+// CheckOutput is the JSON output of a CommandTypeCheck command, sent in
+// CommandResponse.output. PacketLoss is a fraction between 0 and 1.
+type CheckOutput struct {
+	Success    bool    `json:"success"`
+	LatencyMs  float64 `json:"latency_ms"`
+	PacketLoss float64 `json:"packet_loss"`
+	Error      string  `json:"error,omitempty"`
+}
@@ -0,0 +1,7 @@
+package scoutpb
+
+// Temperature sensor kinds carried in TemperatureMetric.kind.
+const (
+	TemperatureKindCPU     = "cpu"
+	TemperatureKindChassis = "chassis"
+)
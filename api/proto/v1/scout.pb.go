@@ -300,6 +300,8 @@ type SystemMetrics struct {
 	Disks            []*DiskMetric           `protobuf:"bytes,5,rep,name=disks,proto3" json:"disks,omitempty"`
 	Networks         []*NetworkMetric        `protobuf:"bytes,6,rep,name=networks,proto3" json:"networks,omitempty"`
 	ContainerStats   []*DockerContainerStats `protobuf:"bytes,7,rep,name=container_stats,json=containerStats,proto3" json:"container_stats,omitempty"`
+	Gpus             []*GPUMetric            `protobuf:"bytes,8,rep,name=gpus,proto3" json:"gpus,omitempty"`
+	Temperatures     []*TemperatureMetric    `protobuf:"bytes,9,rep,name=temperatures,proto3" json:"temperatures,omitempty"`
 	unknownFields    protoimpl.UnknownFields
 	sizeCache        protoimpl.SizeCache
 }
@@ -383,6 +385,20 @@ func (x *SystemMetrics) GetContainerStats() []*DockerContainerStats {
 	return nil
 }
 
+func (x *SystemMetrics) GetGpus() []*GPUMetric {
+	if x != nil {
+		return x.Gpus
+	}
+	return nil
+}
+
+func (x *SystemMetrics) GetTemperatures() []*TemperatureMetric {
+	if x != nil {
+		return x.Temperatures
+	}
+	return nil
+}
+
 type DiskMetric struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	MountPoint    string                 `protobuf:"bytes,1,opt,name=mount_point,json=mountPoint,proto3" json:"mount_point,omitempty"`
@@ -635,6 +651,153 @@ func (x *DockerContainerStats) GetPids() uint32 {
 	return 0
 }
 
+// GPUMetric is a live utilization sample for one GPU. Values a driver does
+// not report are zero.
+type GPUMetric struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Index              uint32                 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Name               string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	UtilizationPercent float64                `protobuf:"fixed64,3,opt,name=utilization_percent,json=utilizationPercent,proto3" json:"utilization_percent,omitempty"`
+	MemoryUsedBytes    float64                `protobuf:"fixed64,4,opt,name=memory_used_bytes,json=memoryUsedBytes,proto3" json:"memory_used_bytes,omitempty"`
+	MemoryTotalBytes   float64                `protobuf:"fixed64,5,opt,name=memory_total_bytes,json=memoryTotalBytes,proto3" json:"memory_total_bytes,omitempty"`
+	TemperatureCelsius float64                `protobuf:"fixed64,6,opt,name=temperature_celsius,json=temperatureCelsius,proto3" json:"temperature_celsius,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *GPUMetric) Reset() {
+	*x = GPUMetric{}
+	mi := &file_api_proto_v1_scout_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GPUMetric) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GPUMetric) ProtoMessage() {}
+
+func (x *GPUMetric) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_scout_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GPUMetric.ProtoReflect.Descriptor instead.
+func (*GPUMetric) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GPUMetric) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *GPUMetric) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *GPUMetric) GetUtilizationPercent() float64 {
+	if x != nil {
+		return x.UtilizationPercent
+	}
+	return 0
+}
+
+func (x *GPUMetric) GetMemoryUsedBytes() float64 {
+	if x != nil {
+		return x.MemoryUsedBytes
+	}
+	return 0
+}
+
+func (x *GPUMetric) GetMemoryTotalBytes() float64 {
+	if x != nil {
+		return x.MemoryTotalBytes
+	}
+	return 0
+}
+
+func (x *GPUMetric) GetTemperatureCelsius() float64 {
+	if x != nil {
+		return x.TemperatureCelsius
+	}
+	return 0
+}
+
+// TemperatureMetric is a reading from a hardware temperature sensor.
+type TemperatureMetric struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sensor        string                 `protobuf:"bytes,1,opt,name=sensor,proto3" json:"sensor,omitempty"`
+	Kind          string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"` // cpu or chassis
+	Celsius       float64                `protobuf:"fixed64,3,opt,name=celsius,proto3" json:"celsius,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TemperatureMetric) Reset() {
+	*x = TemperatureMetric{}
+	mi := &file_api_proto_v1_scout_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TemperatureMetric) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TemperatureMetric) ProtoMessage() {}
+
+func (x *TemperatureMetric) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_scout_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TemperatureMetric.ProtoReflect.Descriptor instead.
+func (*TemperatureMetric) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TemperatureMetric) GetSensor() string {
+	if x != nil {
+		return x.Sensor
+	}
+	return ""
+}
+
+func (x *TemperatureMetric) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *TemperatureMetric) GetCelsius() float64 {
+	if x != nil {
+		return x.Celsius
+	}
+	return 0
+}
+
 type MetricsReport struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
@@ -646,7 +809,7 @@ type MetricsReport struct {
 
 func (x *MetricsReport) Reset() {
 	*x = MetricsReport{}
-	mi := &file_api_proto_v1_scout_proto_msgTypes[6]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -658,7 +821,7 @@ func (x *MetricsReport) String() string {
 func (*MetricsReport) ProtoMessage() {}
 
 func (x *MetricsReport) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_v1_scout_proto_msgTypes[6]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -671,7 +834,7 @@ func (x *MetricsReport) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MetricsReport.ProtoReflect.Descriptor instead.
 func (*MetricsReport) Descriptor() ([]byte, []int) {
-	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{6}
+	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *MetricsReport) GetAgentId() string {
@@ -704,7 +867,7 @@ type Ack struct {
 
 func (x *Ack) Reset() {
 	*x = Ack{}
-	mi := &file_api_proto_v1_scout_proto_msgTypes[7]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -716,7 +879,7 @@ func (x *Ack) String() string {
 func (*Ack) ProtoMessage() {}
 
 func (x *Ack) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_v1_scout_proto_msgTypes[7]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -729,7 +892,7 @@ func (x *Ack) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Ack.ProtoReflect.Descriptor instead.
 func (*Ack) Descriptor() ([]byte, []int) {
-	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{7}
+	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *Ack) GetSuccess() bool {
@@ -750,7 +913,7 @@ type Command struct {
 
 func (x *Command) Reset() {
 	*x = Command{}
-	mi := &file_api_proto_v1_scout_proto_msgTypes[8]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -762,7 +925,7 @@ func (x *Command) String() string {
 func (*Command) ProtoMessage() {}
 
 func (x *Command) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_v1_scout_proto_msgTypes[8]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -775,7 +938,7 @@ func (x *Command) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Command.ProtoReflect.Descriptor instead.
 func (*Command) Descriptor() ([]byte, []int) {
-	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{8}
+	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *Command) GetId() string {
@@ -811,7 +974,7 @@ type CommandResponse struct {
 
 func (x *CommandResponse) Reset() {
 	*x = CommandResponse{}
-	mi := &file_api_proto_v1_scout_proto_msgTypes[9]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -823,7 +986,7 @@ func (x *CommandResponse) String() string {
 func (*CommandResponse) ProtoMessage() {}
 
 func (x *CommandResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_v1_scout_proto_msgTypes[9]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -836,7 +999,7 @@ func (x *CommandResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CommandResponse.ProtoReflect.Descriptor instead.
 func (*CommandResponse) Descriptor() ([]byte, []int) {
-	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{9}
+	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *CommandResponse) GetCommandId() string {
@@ -878,7 +1041,7 @@ type ProfileReport struct {
 
 func (x *ProfileReport) Reset() {
 	*x = ProfileReport{}
-	mi := &file_api_proto_v1_scout_proto_msgTypes[10]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -890,7 +1053,7 @@ func (x *ProfileReport) String() string {
 func (*ProfileReport) ProtoMessage() {}
 
 func (x *ProfileReport) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_v1_scout_proto_msgTypes[10]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -903,7 +1066,7 @@ func (x *ProfileReport) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProfileReport.ProtoReflect.Descriptor instead.
 func (*ProfileReport) Descriptor() ([]byte, []int) {
-	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{10}
+	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *ProfileReport) GetAgentId() string {
@@ -938,7 +1101,7 @@ type SystemProfile struct {
 
 func (x *SystemProfile) Reset() {
 	*x = SystemProfile{}
-	mi := &file_api_proto_v1_scout_proto_msgTypes[11]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -950,7 +1113,7 @@ func (x *SystemProfile) String() string {
 func (*SystemProfile) ProtoMessage() {}
 
 func (x *SystemProfile) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_v1_scout_proto_msgTypes[11]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -963,7 +1126,7 @@ func (x *SystemProfile) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SystemProfile.ProtoReflect.Descriptor instead.
 func (*SystemProfile) Descriptor() ([]byte, []int) {
-	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{11}
+	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *SystemProfile) GetHardware() *HardwareProfile {
@@ -1006,7 +1169,7 @@ type HardwareProfile struct {
 
 func (x *HardwareProfile) Reset() {
 	*x = HardwareProfile{}
-	mi := &file_api_proto_v1_scout_proto_msgTypes[12]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1018,7 +1181,7 @@ func (x *HardwareProfile) String() string {
 func (*HardwareProfile) ProtoMessage() {}
 
 func (x *HardwareProfile) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_v1_scout_proto_msgTypes[12]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1031,7 +1194,7 @@ func (x *HardwareProfile) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HardwareProfile.ProtoReflect.Descriptor instead.
 func (*HardwareProfile) Descriptor() ([]byte, []int) {
-	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{12}
+	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *HardwareProfile) GetCpuModel() string {
@@ -1124,7 +1287,7 @@ type DiskInfo struct {
 
 func (x *DiskInfo) Reset() {
 	*x = DiskInfo{}
-	mi := &file_api_proto_v1_scout_proto_msgTypes[13]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1136,7 +1299,7 @@ func (x *DiskInfo) String() string {
 func (*DiskInfo) ProtoMessage() {}
 
 func (x *DiskInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_v1_scout_proto_msgTypes[13]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1149,7 +1312,7 @@ func (x *DiskInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DiskInfo.ProtoReflect.Descriptor instead.
 func (*DiskInfo) Descriptor() ([]byte, []int) {
-	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{13}
+	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *DiskInfo) GetName() string {
@@ -1198,7 +1361,7 @@ type GPUInfo struct {
 
 func (x *GPUInfo) Reset() {
 	*x = GPUInfo{}
-	mi := &file_api_proto_v1_scout_proto_msgTypes[14]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1210,7 +1373,7 @@ func (x *GPUInfo) String() string {
 func (*GPUInfo) ProtoMessage() {}
 
 func (x *GPUInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_v1_scout_proto_msgTypes[14]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1223,7 +1386,7 @@ func (x *GPUInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GPUInfo.ProtoReflect.Descriptor instead.
 func (*GPUInfo) Descriptor() ([]byte, []int) {
-	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{14}
+	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *GPUInfo) GetModel() string {
@@ -1259,7 +1422,7 @@ type NICInfo struct {
 
 func (x *NICInfo) Reset() {
 	*x = NICInfo{}
-	mi := &file_api_proto_v1_scout_proto_msgTypes[15]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1271,7 +1434,7 @@ func (x *NICInfo) String() string {
 func (*NICInfo) ProtoMessage() {}
 
 func (x *NICInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_v1_scout_proto_msgTypes[15]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1284,7 +1447,7 @@ func (x *NICInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NICInfo.ProtoReflect.Descriptor instead.
 func (*NICInfo) Descriptor() ([]byte, []int) {
-	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{15}
+	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *NICInfo) GetName() string {
@@ -1328,7 +1491,7 @@ type SoftwareInventory struct {
 
 func (x *SoftwareInventory) Reset() {
 	*x = SoftwareInventory{}
-	mi := &file_api_proto_v1_scout_proto_msgTypes[16]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1340,7 +1503,7 @@ func (x *SoftwareInventory) String() string {
 func (*SoftwareInventory) ProtoMessage() {}
 
 func (x *SoftwareInventory) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_v1_scout_proto_msgTypes[16]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1353,7 +1516,7 @@ func (x *SoftwareInventory) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SoftwareInventory.ProtoReflect.Descriptor instead.
 func (*SoftwareInventory) Descriptor() ([]byte, []int) {
-	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{16}
+	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *SoftwareInventory) GetOsName() string {
@@ -1403,7 +1566,7 @@ type InstalledPackage struct {
 
 func (x *InstalledPackage) Reset() {
 	*x = InstalledPackage{}
-	mi := &file_api_proto_v1_scout_proto_msgTypes[17]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1415,7 +1578,7 @@ func (x *InstalledPackage) String() string {
 func (*InstalledPackage) ProtoMessage() {}
 
 func (x *InstalledPackage) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_v1_scout_proto_msgTypes[17]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1428,7 +1591,7 @@ func (x *InstalledPackage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use InstalledPackage.ProtoReflect.Descriptor instead.
 func (*InstalledPackage) Descriptor() ([]byte, []int) {
-	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{17}
+	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *InstalledPackage) GetName() string {
@@ -1465,13 +1628,15 @@ type DockerContainer struct {
 	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
 	Image         string                 `protobuf:"bytes,3,opt,name=image,proto3" json:"image,omitempty"`
 	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Ports         []string               `protobuf:"bytes,5,rep,name=ports,proto3" json:"ports,omitempty"`
+	State         string                 `protobuf:"bytes,6,opt,name=state,proto3" json:"state,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *DockerContainer) Reset() {
 	*x = DockerContainer{}
-	mi := &file_api_proto_v1_scout_proto_msgTypes[18]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1483,7 +1648,7 @@ func (x *DockerContainer) String() string {
 func (*DockerContainer) ProtoMessage() {}
 
 func (x *DockerContainer) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_v1_scout_proto_msgTypes[18]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1496,7 +1661,7 @@ func (x *DockerContainer) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DockerContainer.ProtoReflect.Descriptor instead.
 func (*DockerContainer) Descriptor() ([]byte, []int) {
-	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{18}
+	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *DockerContainer) GetContainerId() string {
@@ -1527,6 +1692,20 @@ func (x *DockerContainer) GetStatus() string {
 	return ""
 }
 
+func (x *DockerContainer) GetPorts() []string {
+	if x != nil {
+		return x.Ports
+	}
+	return nil
+}
+
+func (x *DockerContainer) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
 type ServiceInfo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -1542,7 +1721,7 @@ type ServiceInfo struct {
 
 func (x *ServiceInfo) Reset() {
 	*x = ServiceInfo{}
-	mi := &file_api_proto_v1_scout_proto_msgTypes[19]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1554,7 +1733,7 @@ func (x *ServiceInfo) String() string {
 func (*ServiceInfo) ProtoMessage() {}
 
 func (x *ServiceInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_v1_scout_proto_msgTypes[19]
+	mi := &file_api_proto_v1_scout_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1567,7 +1746,7 @@ func (x *ServiceInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ServiceInfo.ProtoReflect.Descriptor instead.
 func (*ServiceInfo) Descriptor() ([]byte, []int) {
-	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{19}
+	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *ServiceInfo) GetName() string {
@@ -1619,6 +1798,150 @@ func (x *ServiceInfo) GetPorts() []int32 {
 	return nil
 }
 
+type EventReport struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Events        []*LogEvent            `protobuf:"bytes,2,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EventReport) Reset() {
+	*x = EventReport{}
+	mi := &file_api_proto_v1_scout_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EventReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventReport) ProtoMessage() {}
+
+func (x *EventReport) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_scout_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventReport.ProtoReflect.Descriptor instead.
+func (*EventReport) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *EventReport) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *EventReport) GetEvents() []*LogEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type LogEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Channel       string                 `protobuf:"bytes,1,opt,name=channel,proto3" json:"channel,omitempty"` // e.g. System, Application
+	Source        string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`   // event provider name
+	EventId       uint32                 `protobuf:"varint,3,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Level         string                 `protobuf:"bytes,4,opt,name=level,proto3" json:"level,omitempty"` // critical, error, warning, information, verbose
+	Message       string                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	TimeCreated   *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=time_created,json=timeCreated,proto3" json:"time_created,omitempty"`
+	RecordId      uint64                 `protobuf:"varint,7,opt,name=record_id,json=recordId,proto3" json:"record_id,omitempty"` // per-channel sequence number
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogEvent) Reset() {
+	*x = LogEvent{}
+	mi := &file_api_proto_v1_scout_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogEvent) ProtoMessage() {}
+
+func (x *LogEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_scout_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogEvent.ProtoReflect.Descriptor instead.
+func (*LogEvent) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_scout_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *LogEvent) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
+func (x *LogEvent) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *LogEvent) GetEventId() uint32 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+func (x *LogEvent) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *LogEvent) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *LogEvent) GetTimeCreated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.TimeCreated
+	}
+	return nil
+}
+
+func (x *LogEvent) GetRecordId() uint64 {
+	if x != nil {
+		return x.RecordId
+	}
+	return 0
+}
+
 var File_api_proto_v1_scout_proto protoreflect.FileDescriptor
 
 const file_api_proto_v1_scout_proto_rawDesc = "" +
@@ -1632,7 +1955,7 @@ const file_api_proto_v1_scout_proto_rawDesc = "" +
 	"\ametrics\x18\x05 \x01(\v2\x1b.subnetree.v1.SystemMetricsR\ametrics\x12#\n" +
 	"\rproto_version\x18\x06 \x01(\rR\fprotoVersion\x12!\n" +
 	"\fenroll_token\x18\a \x01(\tR\venrollToken\x12/\n" +
-	"\x13certificate_request\x18\b \x01(\fR\x12certificateRequest\"\xac\x03\n" +
+	"\x13certificate_request\x18\b \x01(\fR\x12certificateRequest\"\xcb\x03\n" +
 	"\x0fCheckInResponse\x12\"\n" +
 	"\facknowledged\x18\x01 \x01(\bR\facknowledged\x124\n" +
 	"\x16check_interval_seconds\x18\x02 \x01(\x05R\x14checkIntervalSeconds\x12)\n" +
@@ -1642,7 +1965,10 @@ const file_api_proto_v1_scout_proto_rawDesc = "" +
 	"\x0fupgrade_message\x18\x06 \x01(\tR\x0eupgradeMessage\x12*\n" +
 	"\x11assigned_agent_id\x18\a \x01(\tR\x0fassignedAgentId\x12-\n" +
 	"\x12signed_certificate\x18\b \x01(\fR\x11signedCertificate\x12%\n" +
-	"\x0eca_certificate\x18\t \x01(\fR\rcaCertificate\"\xe7\x02\n" +
+	"\x0eca_certificate\x18\t \x01(\fR\rcaCertificate\x12\x1d\n" +
+	"\n" +
+	"update_url\x18\n" +
+	" \x01(\tR\tupdateUrl\"\xd9\x03\n" +
 	"\rSystemMetrics\x12\x1f\n" +
 	"\vcpu_percent\x18\x01 \x01(\x01R\n" +
 	"cpuPercent\x12%\n" +
@@ -1651,7 +1977,9 @@ const file_api_proto_v1_scout_proto_rawDesc = "" +
 	"\x12memory_total_bytes\x18\x04 \x01(\x01R\x10memoryTotalBytes\x12.\n" +
 	"\x05disks\x18\x05 \x03(\v2\x18.subnetree.v1.DiskMetricR\x05disks\x127\n" +
 	"\bnetworks\x18\x06 \x03(\v2\x1b.subnetree.v1.NetworkMetricR\bnetworks\x12K\n" +
-	"\x0fcontainer_stats\x18\a \x03(\v2\".subnetree.v1.DockerContainerStatsR\x0econtainerStats\"\x8c\x01\n" +
+	"\x0fcontainer_stats\x18\a \x03(\v2\".subnetree.v1.DockerContainerStatsR\x0econtainerStats\x12+\n" +
+	"\x04gpus\x18\b \x03(\v2\x17.subnetree.v1.GPUMetricR\x04gpus\x12C\n" +
+	"\ftemperatures\x18\t \x03(\v2\x1f.subnetree.v1.TemperatureMetricR\ftemperatures\"\x8c\x01\n" +
 	"\n" +
 	"DiskMetric\x12\x1f\n" +
 	"\vmount_point\x18\x01 \x01(\tR\n" +
@@ -1681,7 +2009,18 @@ const file_api_proto_v1_scout_proto_rawDesc = "" +
 	"\x10block_read_bytes\x18\t \x01(\x04R\x0eblockReadBytes\x12*\n" +
 	"\x11block_write_bytes\x18\n" +
 	" \x01(\x04R\x0fblockWriteBytes\x12\x12\n" +
-	"\x04pids\x18\v \x01(\rR\x04pids\"\x7f\n" +
+	"\x04pids\x18\v \x01(\rR\x04pids\"\xf1\x01\n" +
+	"\tGPUMetric\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\rR\x05index\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12/\n" +
+	"\x13utilization_percent\x18\x03 \x01(\x01R\x12utilizationPercent\x12*\n" +
+	"\x11memory_used_bytes\x18\x04 \x01(\x01R\x0fmemoryUsedBytes\x12,\n" +
+	"\x12memory_total_bytes\x18\x05 \x01(\x01R\x10memoryTotalBytes\x12/\n" +
+	"\x13temperature_celsius\x18\x06 \x01(\x01R\x12temperatureCelsius\"Y\n" +
+	"\x11TemperatureMetric\x12\x16\n" +
+	"\x06sensor\x18\x01 \x01(\tR\x06sensor\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x18\n" +
+	"\acelsius\x18\x03 \x01(\x01R\acelsius\"\x7f\n" +
 	"\rMetricsReport\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1c\n" +
 	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\x125\n" +
@@ -1750,12 +2089,14 @@ const file_api_proto_v1_scout_proto_rawDesc = "" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
 	"\aversion\x18\x02 \x01(\tR\aversion\x12\x1c\n" +
 	"\tpublisher\x18\x03 \x01(\tR\tpublisher\x12!\n" +
-	"\finstall_date\x18\x04 \x01(\tR\vinstallDate\"v\n" +
+	"\finstall_date\x18\x04 \x01(\tR\vinstallDate\"\xa2\x01\n" +
 	"\x0fDockerContainer\x12!\n" +
 	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
 	"\x05image\x18\x03 \x01(\tR\x05image\x12\x16\n" +
-	"\x06status\x18\x04 \x01(\tR\x06status\"\xd5\x01\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x14\n" +
+	"\x05ports\x18\x05 \x03(\tR\x05ports\x12\x14\n" +
+	"\x05state\x18\x06 \x01(\tR\x05state\"\xd5\x01\n" +
 	"\vServiceInfo\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12!\n" +
 	"\fdisplay_name\x18\x02 \x01(\tR\vdisplayName\x12\x16\n" +
@@ -1765,18 +2106,30 @@ const file_api_proto_v1_scout_proto_rawDesc = "" +
 	"\vcpu_percent\x18\x05 \x01(\x01R\n" +
 	"cpuPercent\x12!\n" +
 	"\fmemory_bytes\x18\x06 \x01(\x03R\vmemoryBytes\x12\x14\n" +
-	"\x05ports\x18\a \x03(\x05R\x05ports*k\n" +
+	"\x05ports\x18\a \x03(\x05R\x05ports\"X\n" +
+	"\vEventReport\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12.\n" +
+	"\x06events\x18\x02 \x03(\v2\x16.subnetree.v1.LogEventR\x06events\"\xe3\x01\n" +
+	"\bLogEvent\x12\x18\n" +
+	"\achannel\x18\x01 \x01(\tR\achannel\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12\x19\n" +
+	"\bevent_id\x18\x03 \x01(\rR\aeventId\x12\x14\n" +
+	"\x05level\x18\x04 \x01(\tR\x05level\x12\x18\n" +
+	"\amessage\x18\x05 \x01(\tR\amessage\x12=\n" +
+	"\ftime_created\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\vtimeCreated\x12\x1b\n" +
+	"\trecord_id\x18\a \x01(\x04R\brecordId*k\n" +
 	"\rVersionStatus\x12\x0e\n" +
 	"\n" +
 	"VERSION_OK\x10\x00\x12\x16\n" +
 	"\x12VERSION_DEPRECATED\x10\x01\x12\x14\n" +
 	"\x10VERSION_REJECTED\x10\x02\x12\x1c\n" +
-	"\x18VERSION_UPDATE_AVAILABLE\x10\x032\xa5\x02\n" +
+	"\x18VERSION_UPDATE_AVAILABLE\x10\x032\xe3\x02\n" +
 	"\fScoutService\x12F\n" +
 	"\aCheckIn\x12\x1c.subnetree.v1.CheckInRequest\x1a\x1d.subnetree.v1.CheckInResponse\x12A\n" +
 	"\rReportMetrics\x12\x1b.subnetree.v1.MetricsReport\x1a\x11.subnetree.v1.Ack(\x01\x12I\n" +
 	"\rCommandStream\x12\x1d.subnetree.v1.CommandResponse\x1a\x15.subnetree.v1.Command(\x010\x01\x12?\n" +
-	"\rReportProfile\x12\x1b.subnetree.v1.ProfileReport\x1a\x11.subnetree.v1.AckB4Z2github.com/HerbHall/subnetree/api/proto/v1;scoutpbb\x06proto3"
+	"\rReportProfile\x12\x1b.subnetree.v1.ProfileReport\x1a\x11.subnetree.v1.Ack\x12<\n" +
+	"\fReportEvents\x12\x19.subnetree.v1.EventReport\x1a\x11.subnetree.v1.AckB4Z2github.com/HerbHall/subnetree/api/proto/v1;scoutpbb\x06proto3"
 
 var (
 	file_api_proto_v1_scout_proto_rawDescOnce sync.Once
@@ -1791,7 +2144,7 @@ func file_api_proto_v1_scout_proto_rawDescGZIP() []byte {
 }
 
 var file_api_proto_v1_scout_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_api_proto_v1_scout_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
+var file_api_proto_v1_scout_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
 var file_api_proto_v1_scout_proto_goTypes = []any{
 	(VersionStatus)(0),            // 0: subnetree.v1.VersionStatus
 	(*CheckInRequest)(nil),        // 1: subnetree.v1.CheckInRequest
@@ -1800,21 +2153,25 @@ var file_api_proto_v1_scout_proto_goTypes = []any{
 	(*DiskMetric)(nil),            // 4: subnetree.v1.DiskMetric
 	(*NetworkMetric)(nil),         // 5: subnetree.v1.NetworkMetric
 	(*DockerContainerStats)(nil),  // 6: subnetree.v1.DockerContainerStats
-	(*MetricsReport)(nil),         // 7: subnetree.v1.MetricsReport
-	(*Ack)(nil),                   // 8: subnetree.v1.Ack
-	(*Command)(nil),               // 9: subnetree.v1.Command
-	(*CommandResponse)(nil),       // 10: subnetree.v1.CommandResponse
-	(*ProfileReport)(nil),         // 11: subnetree.v1.ProfileReport
-	(*SystemProfile)(nil),         // 12: subnetree.v1.SystemProfile
-	(*HardwareProfile)(nil),       // 13: subnetree.v1.HardwareProfile
-	(*DiskInfo)(nil),              // 14: subnetree.v1.DiskInfo
-	(*GPUInfo)(nil),               // 15: subnetree.v1.GPUInfo
-	(*NICInfo)(nil),               // 16: subnetree.v1.NICInfo
-	(*SoftwareInventory)(nil),     // 17: subnetree.v1.SoftwareInventory
-	(*InstalledPackage)(nil),      // 18: subnetree.v1.InstalledPackage
-	(*DockerContainer)(nil),       // 19: subnetree.v1.DockerContainer
-	(*ServiceInfo)(nil),           // 20: subnetree.v1.ServiceInfo
-	(*timestamppb.Timestamp)(nil), // 21: google.protobuf.Timestamp
+	(*GPUMetric)(nil),             // 7: subnetree.v1.GPUMetric
+	(*TemperatureMetric)(nil),     // 8: subnetree.v1.TemperatureMetric
+	(*MetricsReport)(nil),         // 9: subnetree.v1.MetricsReport
+	(*Ack)(nil),                   // 10: subnetree.v1.Ack
+	(*Command)(nil),               // 11: subnetree.v1.Command
+	(*CommandResponse)(nil),       // 12: subnetree.v1.CommandResponse
+	(*ProfileReport)(nil),         // 13: subnetree.v1.ProfileReport
+	(*SystemProfile)(nil),         // 14: subnetree.v1.SystemProfile
+	(*HardwareProfile)(nil),       // 15: subnetree.v1.HardwareProfile
+	(*DiskInfo)(nil),              // 16: subnetree.v1.DiskInfo
+	(*GPUInfo)(nil),               // 17: subnetree.v1.GPUInfo
+	(*NICInfo)(nil),               // 18: subnetree.v1.NICInfo
+	(*SoftwareInventory)(nil),     // 19: subnetree.v1.SoftwareInventory
+	(*InstalledPackage)(nil),      // 20: subnetree.v1.InstalledPackage
+	(*DockerContainer)(nil),       // 21: subnetree.v1.DockerContainer
+	(*ServiceInfo)(nil),           // 22: subnetree.v1.ServiceInfo
+	(*EventReport)(nil),           // 23: subnetree.v1.EventReport
+	(*LogEvent)(nil),              // 24: subnetree.v1.LogEvent
+	(*timestamppb.Timestamp)(nil), // 25: google.protobuf.Timestamp
 }
 var file_api_proto_v1_scout_proto_depIdxs = []int32{
 	3,  // 0: subnetree.v1.CheckInRequest.metrics:type_name -> subnetree.v1.SystemMetrics
@@ -1822,30 +2179,36 @@ var file_api_proto_v1_scout_proto_depIdxs = []int32{
 	4,  // 2: subnetree.v1.SystemMetrics.disks:type_name -> subnetree.v1.DiskMetric
 	5,  // 3: subnetree.v1.SystemMetrics.networks:type_name -> subnetree.v1.NetworkMetric
 	6,  // 4: subnetree.v1.SystemMetrics.container_stats:type_name -> subnetree.v1.DockerContainerStats
-	3,  // 5: subnetree.v1.MetricsReport.metrics:type_name -> subnetree.v1.SystemMetrics
-	21, // 6: subnetree.v1.ProfileReport.collected_at:type_name -> google.protobuf.Timestamp
-	12, // 7: subnetree.v1.ProfileReport.profile:type_name -> subnetree.v1.SystemProfile
-	13, // 8: subnetree.v1.SystemProfile.hardware:type_name -> subnetree.v1.HardwareProfile
-	17, // 9: subnetree.v1.SystemProfile.software:type_name -> subnetree.v1.SoftwareInventory
-	20, // 10: subnetree.v1.SystemProfile.services:type_name -> subnetree.v1.ServiceInfo
-	14, // 11: subnetree.v1.HardwareProfile.disks:type_name -> subnetree.v1.DiskInfo
-	15, // 12: subnetree.v1.HardwareProfile.gpus:type_name -> subnetree.v1.GPUInfo
-	16, // 13: subnetree.v1.HardwareProfile.nics:type_name -> subnetree.v1.NICInfo
-	18, // 14: subnetree.v1.SoftwareInventory.packages:type_name -> subnetree.v1.InstalledPackage
-	19, // 15: subnetree.v1.SoftwareInventory.docker_containers:type_name -> subnetree.v1.DockerContainer
-	1,  // 16: subnetree.v1.ScoutService.CheckIn:input_type -> subnetree.v1.CheckInRequest
-	7,  // 17: subnetree.v1.ScoutService.ReportMetrics:input_type -> subnetree.v1.MetricsReport
-	10, // 18: subnetree.v1.ScoutService.CommandStream:input_type -> subnetree.v1.CommandResponse
-	11, // 19: subnetree.v1.ScoutService.ReportProfile:input_type -> subnetree.v1.ProfileReport
-	2,  // 20: subnetree.v1.ScoutService.CheckIn:output_type -> subnetree.v1.CheckInResponse
-	8,  // 21: subnetree.v1.ScoutService.ReportMetrics:output_type -> subnetree.v1.Ack
-	9,  // 22: subnetree.v1.ScoutService.CommandStream:output_type -> subnetree.v1.Command
-	8,  // 23: subnetree.v1.ScoutService.ReportProfile:output_type -> subnetree.v1.Ack
-	20, // [20:24] is the sub-list for method output_type
-	16, // [16:20] is the sub-list for method input_type
-	16, // [16:16] is the sub-list for extension type_name
-	16, // [16:16] is the sub-list for extension extendee
-	0,  // [0:16] is the sub-list for field type_name
+	7,  // 5: subnetree.v1.SystemMetrics.gpus:type_name -> subnetree.v1.GPUMetric
+	8,  // 6: subnetree.v1.SystemMetrics.temperatures:type_name -> subnetree.v1.TemperatureMetric
+	3,  // 7: subnetree.v1.MetricsReport.metrics:type_name -> subnetree.v1.SystemMetrics
+	25, // 8: subnetree.v1.ProfileReport.collected_at:type_name -> google.protobuf.Timestamp
+	14, // 9: subnetree.v1.ProfileReport.profile:type_name -> subnetree.v1.SystemProfile
+	15, // 10: subnetree.v1.SystemProfile.hardware:type_name -> subnetree.v1.HardwareProfile
+	19, // 11: subnetree.v1.SystemProfile.software:type_name -> subnetree.v1.SoftwareInventory
+	22, // 12: subnetree.v1.SystemProfile.services:type_name -> subnetree.v1.ServiceInfo
+	16, // 13: subnetree.v1.HardwareProfile.disks:type_name -> subnetree.v1.DiskInfo
+	17, // 14: subnetree.v1.HardwareProfile.gpus:type_name -> subnetree.v1.GPUInfo
+	18, // 15: subnetree.v1.HardwareProfile.nics:type_name -> subnetree.v1.NICInfo
+	20, // 16: subnetree.v1.SoftwareInventory.packages:type_name -> subnetree.v1.InstalledPackage
+	21, // 17: subnetree.v1.SoftwareInventory.docker_containers:type_name -> subnetree.v1.DockerContainer
+	24, // 18: subnetree.v1.EventReport.events:type_name -> subnetree.v1.LogEvent
+	25, // 19: subnetree.v1.LogEvent.time_created:type_name -> google.protobuf.Timestamp
+	1,  // 20: subnetree.v1.ScoutService.CheckIn:input_type -> subnetree.v1.CheckInRequest
+	9,  // 21: subnetree.v1.ScoutService.ReportMetrics:input_type -> subnetree.v1.MetricsReport
+	12, // 22: subnetree.v1.ScoutService.CommandStream:input_type -> subnetree.v1.CommandResponse
+	13, // 23: subnetree.v1.ScoutService.ReportProfile:input_type -> subnetree.v1.ProfileReport
+	23, // 24: subnetree.v1.ScoutService.ReportEvents:input_type -> subnetree.v1.EventReport
+	2,  // 25: subnetree.v1.ScoutService.CheckIn:output_type -> subnetree.v1.CheckInResponse
+	10, // 26: subnetree.v1.ScoutService.ReportMetrics:output_type -> subnetree.v1.Ack
+	11, // 27: subnetree.v1.ScoutService.CommandStream:output_type -> subnetree.v1.Command
+	10, // 28: subnetree.v1.ScoutService.ReportProfile:output_type -> subnetree.v1.Ack
+	10, // 29: subnetree.v1.ScoutService.ReportEvents:output_type -> subnetree.v1.Ack
+	25, // [25:30] is the sub-list for method output_type
+	20, // [20:25] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
 }
 
 func init() { file_api_proto_v1_scout_proto_init() }
@@ -1859,7 +2222,7 @@ func file_api_proto_v1_scout_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_proto_v1_scout_proto_rawDesc), len(file_api_proto_v1_scout_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   20,
+			NumMessages:   24,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -23,6 +23,7 @@ const (
 	ScoutService_ReportMetrics_FullMethodName = "/subnetree.v1.ScoutService/ReportMetrics"
 	ScoutService_CommandStream_FullMethodName = "/subnetree.v1.ScoutService/CommandStream"
 	ScoutService_ReportProfile_FullMethodName = "/subnetree.v1.ScoutService/ReportProfile"
+	ScoutService_ReportEvents_FullMethodName  = "/subnetree.v1.ScoutService/ReportEvents"
 )
 
 // ScoutServiceClient is the client API for ScoutService service.
@@ -39,6 +40,8 @@ type ScoutServiceClient interface {
 	CommandStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[CommandResponse, Command], error)
 	// ReportProfile sends a full system profile snapshot from agent to server.
 	ReportProfile(ctx context.Context, in *ProfileReport, opts ...grpc.CallOption) (*Ack, error)
+	// ReportEvents forwards a batch of OS event log entries from agent to server.
+	ReportEvents(ctx context.Context, in *EventReport, opts ...grpc.CallOption) (*Ack, error)
 }
 
 type scoutServiceClient struct {
@@ -95,6 +98,16 @@ func (c *scoutServiceClient) ReportProfile(ctx context.Context, in *ProfileRepor
 	return out, nil
 }
 
+func (c *scoutServiceClient) ReportEvents(ctx context.Context, in *EventReport, opts ...grpc.CallOption) (*Ack, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ack)
+	err := c.cc.Invoke(ctx, ScoutService_ReportEvents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ScoutServiceServer is the server API for ScoutService service.
 // All implementations must embed UnimplementedScoutServiceServer
 // for forward compatibility.
@@ -109,6 +122,8 @@ type ScoutServiceServer interface {
 	CommandStream(grpc.BidiStreamingServer[CommandResponse, Command]) error
 	// ReportProfile sends a full system profile snapshot from agent to server.
 	ReportProfile(context.Context, *ProfileReport) (*Ack, error)
+	// ReportEvents forwards a batch of OS event log entries from agent to server.
+	ReportEvents(context.Context, *EventReport) (*Ack, error)
 	mustEmbedUnimplementedScoutServiceServer()
 }
 
@@ -131,6 +146,9 @@ func (UnimplementedScoutServiceServer) CommandStream(grpc.BidiStreamingServer[Co
 func (UnimplementedScoutServiceServer) ReportProfile(context.Context, *ProfileReport) (*Ack, error) {
 	return nil, status.Error(codes.Unimplemented, "method ReportProfile not implemented")
 }
+func (UnimplementedScoutServiceServer) ReportEvents(context.Context, *EventReport) (*Ack, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReportEvents not implemented")
+}
 func (UnimplementedScoutServiceServer) mustEmbedUnimplementedScoutServiceServer() {}
 func (UnimplementedScoutServiceServer) testEmbeddedByValue()                      {}
 
@@ -202,6 +220,24 @@ func _ScoutService_ReportProfile_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ScoutService_ReportEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EventReport)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScoutServiceServer).ReportEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ScoutService_ReportEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScoutServiceServer).ReportEvents(ctx, req.(*EventReport))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ScoutService_ServiceDesc is the grpc.ServiceDesc for ScoutService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -217,6 +253,10 @@ var ScoutService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ReportProfile",
 			Handler:    _ScoutService_ReportProfile_Handler,
 		},
+		{
+			MethodName: "ReportEvents",
+			Handler:    _ScoutService_ReportEvents_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/signal"
 	"runtime"
+	"strings"
 	"syscall"
 
 	"github.com/HerbHall/subnetree/internal/scout"
@@ -59,6 +60,8 @@ func runCmd(args []string) {
 	caCert := fs.String("ca-cert", "", "Path to CA certificate for TLS verification")
 	insecureFlag := fs.Bool("insecure", false, "Use insecure gRPC transport (dev/testing only)")
 	autoRestart := fs.Bool("auto-restart", false, "Enable auto-restart on version rejection (requires init system support)")
+	eventChannels, eventLevels, eventSources := eventLogFlags(fs)
+	execAllowlist := fs.String("exec-allowlist", "", "Comma-separated command lines the server may run remotely, e.g. \"ping -c 4 *,uptime\"; empty refuses remote exec")
 
 	if err := fs.Parse(args); err != nil {
 		os.Exit(1)
@@ -86,6 +89,10 @@ func runCmd(args []string) {
 		CACertPath:    *caCert,
 		Insecure:      useInsecure,
 		AutoRestart:   *autoRestart,
+		EventChannels: splitList(*eventChannels),
+		EventLevels:   splitList(*eventLevels),
+		EventSources:  splitList(*eventSources),
+		ExecAllowlist: splitList(*execAllowlist),
 	}
 
 	// Check if running as a Windows service.
@@ -129,6 +136,8 @@ func installCmd(args []string) {
 	keyPath := fs.String("key", "", "Path to agent TLS private key")
 	caCert := fs.String("ca-cert", "", "Path to CA certificate")
 	insecureFlag := fs.Bool("insecure", false, "Use insecure gRPC transport")
+	eventChannels, eventLevels, eventSources := eventLogFlags(fs)
+	execAllowlist := fs.String("exec-allowlist", "", "Comma-separated command lines the server may run remotely, e.g. \"ping -c 4 *,uptime\"; empty refuses remote exec")
 
 	if err := fs.Parse(args); err != nil {
 		os.Exit(1)
@@ -147,6 +156,10 @@ func installCmd(args []string) {
 		KeyPath:       *keyPath,
 		CACertPath:    *caCert,
 		Insecure:      *insecureFlag,
+		EventChannels: splitList(*eventChannels),
+		EventLevels:   splitList(*eventLevels),
+		EventSources:  splitList(*eventSources),
+		ExecAllowlist: splitList(*execAllowlist),
 	}
 
 	if err := service.InstallService(exePath, config); err != nil {
@@ -154,7 +167,9 @@ func installCmd(args []string) {
 		os.Exit(1)
 	}
 	fmt.Println("SubNetree Scout service installed successfully")
-	fmt.Println("Start with: sc start SubNetreeScout")
+	if hint := service.StartHint(); hint != "" {
+		fmt.Println("Start with: " + hint)
+	}
 }
 
 func uninstallCmd() {
@@ -242,6 +257,27 @@ func runUpdate(logger *zap.Logger, serverAddr string) error {
 	return nil
 }
 
+// eventLogFlags registers the Windows event log forwarding flags.
+func eventLogFlags(fs *flag.FlagSet) (channels, levels, sources *string) {
+	def := scout.DefaultConfig()
+	channels = fs.String("event-channels", strings.Join(def.EventChannels, ","), "Comma-separated Windows event log channels to forward; empty disables forwarding")
+	levels = fs.String("event-levels", strings.Join(def.EventLevels, ","), "Comma-separated event levels to forward (critical, error, warning, information, verbose)")
+	sources = fs.String("event-sources", "", "Comma-separated event providers to forward; empty forwards all")
+	return channels, levels, sources
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries.
+// The result is non-nil so an empty value stays distinguishable from unset.
+func splitList(s string) []string {
+	items := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func versionCmd() {
 	fmt.Printf("SubNetree Scout %s\n", version.Version)
 	fmt.Printf("  Commit: %s\n", version.GitCommit)
@@ -12,18 +12,22 @@ package main
 import (
 	"context"
 	"crypto/rand"
+	"database/sql"
 	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
 	_ "github.com/HerbHall/subnetree/api/swagger"
 	"github.com/HerbHall/subnetree/internal/auth"
 	"github.com/HerbHall/subnetree/internal/autodoc"
+	"github.com/HerbHall/subnetree/internal/backup"
 	mcpmod "github.com/HerbHall/subnetree/internal/mcp"
 	nbmod "github.com/HerbHall/subnetree/internal/netbox"
 	"github.com/HerbHall/subnetree/internal/catalog"
@@ -33,6 +37,7 @@ import (
 	"github.com/HerbHall/subnetree/internal/docs"
 	"github.com/HerbHall/subnetree/internal/event"
 	"github.com/HerbHall/subnetree/internal/gateway"
+	"github.com/HerbHall/subnetree/internal/grafana"
 	"github.com/HerbHall/subnetree/internal/insight"
 	"github.com/HerbHall/subnetree/internal/llm"
 	"github.com/HerbHall/subnetree/internal/mqtt"
@@ -47,6 +52,7 @@ import (
 	"github.com/HerbHall/subnetree/internal/svcmap"
 	tsmod "github.com/HerbHall/subnetree/internal/tailscale"
 	"github.com/HerbHall/subnetree/internal/tier"
+	"github.com/HerbHall/subnetree/internal/tracing"
 	"github.com/HerbHall/subnetree/internal/vault"
 	"github.com/HerbHall/subnetree/internal/version"
 	"github.com/HerbHall/subnetree/internal/webhook"
@@ -101,12 +107,17 @@ func main() {
 	}
 	cfg := config.New(viperCfg)
 
-	// Detect hardware tier and apply tier-specific defaults.
-	detectedTier := tier.DetectTier()
-	tier.ApplyDefaults(viperCfg, detectedTier)
+	// Detect hardware tier (or take it from tier.override) and apply
+	// tier-specific defaults.
+	activeTier, tierSource, err := tier.Resolve(viperCfg.GetString("tier.override"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid tier.override: %v\n", err)
+		os.Exit(1)
+	}
+	tier.ApplyDefaults(viperCfg, activeTier)
 
 	// Initialize logger from configuration.
-	logger, err := config.NewLogger(viperCfg)
+	logger, logLevel, err := config.NewLoggerWithLevel(viperCfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to initialize logger: %v\n", err)
 		os.Exit(1)
@@ -115,10 +126,17 @@ func main() {
 
 	logger.Info("SubNetree server starting", zap.String("version", version.Short()))
 
-	logger.Info("hardware tier detected",
-		zap.Int("tier", int(detectedTier)),
-		zap.String("tier_name", tier.Name(detectedTier)),
-	)
+	if tierSource == tier.SourceOverride {
+		logger.Warn("hardware tier overridden by tier.override, detection skipped",
+			zap.Int("tier", int(activeTier)),
+			zap.String("tier_name", tier.Name(activeTier)),
+		)
+	} else {
+		logger.Info("hardware tier detected",
+			zap.Int("tier", int(activeTier)),
+			zap.String("tier_name", tier.Name(activeTier)),
+		)
+	}
 
 	if f := viperCfg.ConfigFileUsed(); f != "" {
 		logger.Info("configuration loaded",
@@ -131,21 +149,51 @@ func main() {
 		)
 	}
 
-	// Open database
-	dbPath := viperCfg.GetString("database.path")
-	if dbPath == "" {
-		dbPath = "subnetree.db"
+	// Configure tracing before any instrumented component starts. Tracing is
+	// off unless tracing.enabled is set.
+	tracingCfg := tracing.DefaultConfig()
+	if err := viperCfg.UnmarshalKey("tracing", &tracingCfg); err != nil {
+		logger.Fatal("invalid tracing configuration", zap.Error(err))
 	}
-	db, err := store.New(dbPath)
+	shutdownTracing, err := tracing.Setup(context.Background(), tracingCfg)
+	if err != nil {
+		logger.Fatal("failed to initialize tracing", zap.Error(err))
+	}
+	if tracingCfg.Enabled {
+		logger.Info("OpenTelemetry tracing enabled",
+			zap.String("component", "tracing"),
+			zap.String("endpoint", tracingCfg.Endpoint),
+			zap.Float64("sample_rate", tracingCfg.SampleRate),
+		)
+	}
+
+	// Open database. SQLite (the default) takes a file path; PostgreSQL
+	// takes a connection string in database.dsn.
+	dbDriver := viperCfg.GetString("database.driver")
+	if dbDriver == "" {
+		dbDriver = store.DriverSQLite
+	}
+	dbSource := viperCfg.GetString("database.path")
+	if dbDriver == store.DriverPostgres {
+		dbSource = viperCfg.GetString("database.dsn")
+	} else if dbSource == "" {
+		dbSource = "subnetree.db"
+	}
+	db, err := store.Open(dbDriver, dbSource)
 	if err != nil {
 		logger.Fatal("failed to open database", zap.Error(err))
 	}
 	defer db.Close()
 
-	logger.Info("database initialized",
+	dbFields := []zap.Field{
 		zap.String("component", "database"),
-		zap.String("path", dbPath),
-	)
+		zap.String("driver", dbDriver),
+	}
+	if dbDriver == store.DriverSQLite {
+		// The PostgreSQL DSN may carry a password, so only log SQLite paths.
+		dbFields = append(dbFields, zap.String("path", dbSource))
+	}
+	logger.Info("database initialized", dbFields...)
 
 	// Check database schema version compatibility.
 	if err := db.CheckVersion(context.Background(), version.Short()); err != nil {
@@ -159,6 +207,18 @@ func main() {
 	bus := event.NewBus(logger.Named("event"))
 	logger.Info("event bus created", zap.String("component", "event"))
 
+	// Optionally persist published events for history queries and replay.
+	// The log is an async sink; publishing stays in-memory.
+	var eventLog *event.Log
+	if viperCfg.GetBool("events.persist") {
+		eventLog, err = event.NewLog(db.DB(), viperCfg.GetDuration("events.retention"), logger.Named("event"))
+		if err != nil {
+			logger.Fatal("failed to initialize event log", zap.Error(err))
+		}
+		eventLog.Start(bus)
+		logger.Info("event log enabled", zap.String("component", "event"))
+	}
+
 	// Create plugin registry
 	reg := registry.New(logger.Named("registry"))
 	logger.Info("plugin registry created", zap.String("component", "registry"))
@@ -179,6 +239,7 @@ func main() {
 		mcpmod.New(),
 		nbmod.New(),
 		tsmod.New(),
+		grafana.New(),
 	}
 	for _, m := range modules {
 		if err := reg.Register(m); err != nil {
@@ -236,6 +297,30 @@ func main() {
 	svcmapScheduler := svcmap.NewScheduler(svcCorrelator, svcSourceAdapter, nil, agentAdapter, correlateInterval, logger.Named("svcmap"))
 	svcmapScheduler.Start(ctx)
 
+	// Create the backup scheduler. Online backups use the SQLite backup API,
+	// so the admin backup endpoints are only mounted for the SQLite backend.
+	var backupScheduler *backup.Scheduler
+	var backupHandler *backup.Handler
+	if dbDriver == store.DriverSQLite {
+		backupDir := viperCfg.GetString("backup.dir")
+		if backupDir == "" {
+			backupDir = "backups"
+		}
+		backupRetain := 7
+		if viperCfg.IsSet("backup.retain") {
+			backupRetain = viperCfg.GetInt("backup.retain")
+		}
+		backupScheduler = backup.NewScheduler(db.DB(), backup.SchedulerConfig{
+			Dir:        backupDir,
+			Interval:   viperCfg.GetDuration("backup.interval"),
+			Retain:     backupRetain,
+			DBName:     filepath.Base(dbSource),
+			ConfigPath: viperCfg.ConfigFileUsed(),
+		}, logger.Named("backup"))
+		backupScheduler.Start(ctx)
+		backupHandler = backup.NewHandler(backupScheduler, logger.Named("backup"))
+	}
+
 	// Create auth service
 	authStore, err := auth.NewUserStore(ctx, db)
 	if err != nil {
@@ -270,7 +355,71 @@ func main() {
 	tokens := auth.NewTokenService([]byte(jwtSecret), accessTTL, refreshTTL)
 	totpSvc := auth.NewTOTPService([]byte(jwtSecret))
 	authService := auth.NewService(authStore, tokens, totpSvc, logger.Named("auth"))
+	authService.SetEventBus(bus)
 	authHandler := auth.NewHandler(authService, logger.Named("auth"))
+	oidcCfg := auth.DefaultOIDCConfig()
+	if err := viperCfg.UnmarshalKey("auth.oidc", &oidcCfg); err != nil {
+		logger.Warn("invalid auth.oidc config, OIDC login disabled", zap.Error(err))
+		oidcCfg.Enabled = false
+	}
+	if oidcCfg.Enabled {
+		oidcProvider, err := auth.NewOIDCProvider(ctx, oidcCfg)
+		if err != nil {
+			// Local login keeps working when the IdP is unreachable or misconfigured.
+			logger.Error("failed to initialize OIDC provider, OIDC login disabled",
+				zap.String("component", "auth"),
+				zap.Error(err),
+			)
+		} else {
+			authHandler.SetOIDC(oidcProvider, oidcCfg)
+			logger.Info("OIDC login enabled",
+				zap.String("component", "auth"),
+				zap.String("issuer", oidcCfg.Issuer),
+			)
+		}
+	}
+	webauthnCfg := auth.DefaultWebAuthnConfig()
+	if err := viperCfg.UnmarshalKey("auth.webauthn", &webauthnCfg); err != nil {
+		logger.Warn("invalid auth.webauthn config, passkey login disabled", zap.Error(err))
+		webauthnCfg.Enabled = false
+	}
+	if webauthnCfg.Enabled {
+		wa, err := auth.NewWebAuthn(webauthnCfg)
+		if err != nil {
+			logger.Error("failed to initialize WebAuthn, passkey login disabled",
+				zap.String("component", "auth"),
+				zap.Error(err),
+			)
+		} else {
+			authService.SetWebAuthn(wa)
+			logger.Info("passkey login enabled",
+				zap.String("component", "auth"),
+				zap.String("rp_id", webauthnCfg.RPID),
+			)
+		}
+	}
+	var ldapProvider *auth.LDAPProvider
+	ldapCfg := auth.DefaultLDAPConfig()
+	if err := viperCfg.UnmarshalKey("auth.ldap", &ldapCfg); err != nil {
+		logger.Warn("invalid auth.ldap config, LDAP login disabled", zap.Error(err))
+		ldapCfg.Enabled = false
+	}
+	if ldapCfg.Enabled {
+		ldapProvider, err = auth.NewLDAPProvider(ldapCfg)
+		if err != nil {
+			logger.Error("failed to initialize LDAP provider, LDAP login disabled",
+				zap.String("component", "auth"),
+				zap.Error(err),
+			)
+		} else {
+			authService.SetLDAP(ldapProvider, ldapCfg)
+			logger.Info("LDAP login enabled",
+				zap.String("component", "auth"),
+				zap.String("url", ldapCfg.URL),
+				zap.String("base_dn", ldapCfg.BaseDN),
+			)
+		}
+	}
 	logger.Info("auth service initialized",
 		zap.String("component", "auth"),
 		zap.Duration("access_token_ttl", accessTTL),
@@ -287,12 +436,19 @@ func main() {
 
 	// Create WebSocket handler for real-time scan updates
 	wsHandler := ws.NewHandler(tokens, bus, logger.Named("ws"))
+	wsHandler.SetAccessControl(authService, authService)
+	if eventLog != nil {
+		wsHandler.SetEventLog(eventLog)
+	}
 	logger.Info("websocket handler initialized", zap.String("component", "ws"))
 
 	// Wire SNMP credential adapter: recon -> vault.
 	var reconMod *recon.Module
 	var vaultMod *vault.Module
 	var pulseMod *pulse.Module
+	var llmMod *llm.Module
+	var insightMod *insight.Module
+	var webhookMod *webhook.Module
 	for _, m := range modules {
 		switch mod := m.(type) {
 		case *recon.Module:
@@ -301,14 +457,41 @@ func main() {
 			vaultMod = mod
 		case *pulse.Module:
 			pulseMod = mod
+		case *llm.Module:
+			llmMod = mod
+		case *insight.Module:
+			insightMod = mod
+		case *webhook.Module:
+			webhookMod = mod
 		}
 	}
 	if reconMod != nil && vaultMod != nil {
-		reconMod.SetCredentialAccessor(recon.NewVaultCredentialAdapter(&vaultDecryptAdapter{vault: vaultMod}))
+		reconMod.SetCredentialAccessor(recon.NewVaultCredentialAdapter(&vaultDecryptAdapter{vault: vaultMod, caller: "recon"}))
 		reconMod.SetCredentialProvider(vaultMod)
 		logger.Info("SNMP credential adapter wired", zap.String("component", "recon"))
 	}
 
+	// Wire service impact alerts: svcmap -> pulse store.
+	if pulseMod != nil && pulseMod.Store() != nil {
+		svcmapHandler.SetAlertSource(&svcmapAlertAdapter{store: pulseMod.Store()})
+		logger.Info("svcmap alert source wired", zap.String("component", "svcmap"))
+	}
+
+	// Wire webhook retry queue: pulse notification channels -> webhook.
+	if pulseMod != nil && webhookMod != nil && webhookMod.Queue() != nil {
+		pulseMod.SetWebhookQueue(webhookMod.Queue())
+		logger.Info("webhook retry queue wired", zap.String("component", "pulse"))
+	}
+
+	// Wire scheduled-scan targets: settings scan interfaces -> recon.
+	if reconMod != nil {
+		reconMod.SetScanTargetSource(&scanTargetAdapter{
+			settings:   settingsRepo,
+			interfaces: services.NewInterfaceService(),
+		})
+		logger.Info("scan interface targets wired", zap.String("component", "recon"))
+	}
+
 	// Wire hardware profile bridge: dispatch -> recon.
 	if reconMod != nil {
 		profileAdapter := &profileSourceAdapter{store: dispatchProfileStore}
@@ -316,13 +499,43 @@ func main() {
 		logger.Info("hardware profile bridge wired", zap.String("component", "recon"))
 	}
 
-	// Wire MCP device querier and service querier: mcp -> recon store, svcmap store.
+	// Wire LLM-assisted classification: recon -> llm.
+	if reconMod != nil && llmMod != nil && llmMod.Provider() != nil {
+		reconMod.SetLLMProvider(llmMod.Provider())
+		logger.Info("llm classifier wired", zap.String("component", "recon"))
+	}
+
+	// Wire scan metric anomaly detection and device queries: insight -> recon.
+	if reconMod != nil && insightMod != nil {
+		insightMod.SetScanMetricsSource(&scanMetricsAdapter{store: reconMod.Store()})
+		insightMod.SetDeviceSearcher(&deviceSearchAdapter{store: reconMod.Store()})
+		insightMod.SetCapacitySource(&capacityAdapter{store: reconMod.Store()})
+		logger.Info("scan metrics source, device searcher, and capacity source wired", zap.String("component", "insight"))
+	}
+
+	// Wire alert summaries: insight -> pulse store, recon store.
+	if reconMod != nil && insightMod != nil && pulseMod != nil && pulseMod.Store() != nil {
+		insightMod.SetAlertSource(&insightAlertAdapter{alerts: pulseMod.Store(), devices: reconMod.Store()})
+		logger.Info("alert source wired", zap.String("component", "insight"))
+	}
+
+	// Wire MCP queriers and actuators: mcp -> recon, svcmap store, pulse store.
 	if reconMod != nil {
 		for _, m := range modules {
 			if mcpMod, ok := m.(*mcpmod.Module); ok {
 				mcpMod.SetQuerier(&mcpDeviceAdapter{store: reconMod.Store()})
 				mcpMod.SetServiceQuerier(&mcpServiceAdapter{store: svcmapStore})
-				logger.Info("MCP device and service queriers wired", zap.String("component", "mcp"))
+				mcpMod.SetTopologyReader(&mcpTopologyAdapter{store: reconMod.Store()})
+				mcpMod.SetScanActuator(&mcpScanAdapter{recon: reconMod})
+				if pulseMod != nil && pulseMod.Store() != nil {
+					mcpMod.SetAlertActuator(&mcpAlertAdapter{store: pulseMod.Store()})
+					mcpMod.SetCheckActuator(&mcpCheckAdapter{pulse: pulseMod})
+				}
+				mcpMod.SetReadOnly(isDemoMode)
+				logger.Info("MCP queriers and actuators wired",
+					zap.String("component", "mcp"),
+					zap.Bool("read_only", isDemoMode),
+				)
 				break
 			}
 		}
@@ -333,14 +546,14 @@ func main() {
 		for _, m := range modules {
 			if ts, ok := m.(*tsmod.Module); ok {
 				ts.SetDeviceStore(&tailscaleDeviceAdapter{store: reconMod.Store()})
-				ts.SetCredentialDecrypter(&vaultDecryptAdapter{vault: vaultMod})
+				ts.SetCredentialDecrypter(&vaultDecryptAdapter{vault: vaultMod, caller: "tailscale"})
 				logger.Info("tailscale adapters wired", zap.String("component", "tailscale"))
 				break
 			}
 		}
 	}
 
-	// Wire AutoDoc device and alert readers: autodoc -> recon store, pulse store.
+	// Wire AutoDoc readers: autodoc -> recon store, pulse store, dispatch store, svcmap store.
 	if reconMod != nil {
 		for _, m := range modules {
 			if adMod, ok := m.(*autodoc.Module); ok {
@@ -348,23 +561,73 @@ func main() {
 				if pulseMod != nil && pulseMod.Store() != nil {
 					adMod.SetAlertReader(&autodocAlertAdapter{store: pulseMod.Store()})
 				}
-				logger.Info("autodoc device and alert readers wired", zap.String("component", "autodoc"))
+				adMod.SetSoftwareReader(&autodocSoftwareAdapter{store: dispatchProfileStore})
+				adMod.SetPortNamer(svcmapStore)
+				adMod.SetRelationshipReader(&autodocRelationshipAdapter{store: reconMod.Store()})
+				logger.Info("autodoc device, alert, software, port name, and relationship readers wired", zap.String("component", "autodoc"))
 				break
 			}
 		}
 	}
 
-	// Wire NetBox device reader: netbox -> recon store.
+	// Wire LDAP bind password: auth -> vault.
+	if ldapProvider != nil && vaultMod != nil {
+		ldapProvider.SetCredentialDecrypter(&vaultDecryptAdapter{vault: vaultMod, caller: "auth"})
+		logger.Info("ldap credential decrypter wired", zap.String("component", "auth"))
+	}
+
+	// Wire MQTT adapters: mqtt -> vault, recon store.
+	for _, m := range modules {
+		if mq, ok := m.(*mqtt.Module); ok {
+			if vaultMod != nil {
+				mq.SetCredentialDecrypter(&vaultDecryptAdapter{vault: vaultMod, caller: "mqtt"})
+			}
+			if reconMod != nil {
+				mq.SetDeviceLister(reconMod.Store())
+			}
+			logger.Info("mqtt adapters wired",
+				zap.String("component", "mqtt"),
+				zap.Bool("vault", vaultMod != nil),
+				zap.Bool("devices", reconMod != nil),
+			)
+			break
+		}
+	}
+
+	// Wire NetBox device reader/writer: netbox -> recon store, vault.
 	if reconMod != nil {
 		for _, m := range modules {
 			if nb, ok := m.(*nbmod.Module); ok {
-				nb.SetDeviceReader(&netboxDeviceAdapter{store: reconMod.Store()})
-				logger.Info("netbox device reader wired", zap.String("component", "netbox"))
+				adapter := &netboxDeviceAdapter{store: reconMod.Store()}
+				nb.SetDeviceReader(adapter)
+				nb.SetDeviceWriter(adapter)
+				if vaultMod != nil {
+					nb.SetCredentialDecrypter(&vaultDecryptAdapter{vault: vaultMod, caller: "netbox"})
+				}
+				logger.Info("netbox device reader/writer wired",
+					zap.String("component", "netbox"),
+					zap.Bool("vault", vaultMod != nil),
+				)
 				break
 			}
 		}
 	}
 
+	// Wire Grafana datasource readers: grafana -> pulse store, recon store.
+	for _, m := range modules {
+		if gf, ok := m.(*grafana.Module); ok {
+			if pulseMod != nil && pulseMod.Store() != nil {
+				gf.SetMetricsReader(&grafanaMetricsAdapter{store: pulseMod.Store()})
+				gf.SetAlertReader(&grafanaAlertAdapter{store: pulseMod.Store()})
+			}
+			if reconMod != nil && reconMod.Store() != nil {
+				gf.SetScanMetricsReader(reconMod.Store())
+			}
+			logger.Info("grafana datasource readers wired", zap.String("component", "grafana"))
+			break
+		}
+	}
+
 	// Seed demo data if requested via --seed flag or NV_SEED_DATA env var.
 	if *seedData || os.Getenv("NV_SEED_DATA") == "true" {
 		if reconMod != nil {
@@ -407,7 +670,10 @@ func main() {
 	}
 	var sshHandler *gateway.SSHWebSocketHandler
 	if gw != nil {
-		sshHandler = gateway.NewSSHWebSocketHandler(gw, &tokenAdapter{tokens}, logger.Named("gateway-ssh"))
+		if vaultMod != nil {
+			gw.SetCredentialDecrypter(&vaultDecryptAdapter{vault: vaultMod, caller: "gateway"})
+		}
+		sshHandler = gateway.NewSSHWebSocketHandler(gw, &tokenAdapter{svc: tokens, sessions: authService, roles: authService}, logger.Named("gateway-ssh"))
 		logger.Info("gateway SSH handler initialized", zap.String("component", "gateway"))
 	}
 
@@ -426,15 +692,29 @@ func main() {
 	})
 	dashboardHandler := dashboard.Handler()
 
-	// Create catalog recommendation handler.
+	// Create catalog recommendation handler, merging custom entries into the
+	// built-in catalog.
 	cat := pkgcatalog.NewCatalog()
 	catalogEngine := catalog.NewEngine(cat)
+	catalogStore, err := catalog.NewStore(db.DB())
+	if err != nil {
+		logger.Fatal("failed to initialize catalog store", zap.Error(err))
+	}
+	catalogEngine.SetCustomStore(catalogStore)
 	catalogHandler := catalog.NewHandler(catalogEngine, logger.Named("catalog"))
 
-	extraRoutes := []server.SimpleRouteRegistrar{settingsHandler, wsHandler, svcmapHandler, catalogHandler}
+	tierHandler := tier.NewHandler(activeTier, tierSource)
+
+	eventHandler := event.NewHandler(eventLog, logger.Named("event"))
+	eventHandler.SetRoleResolver(authService)
+
+	extraRoutes := []server.SimpleRouteRegistrar{settingsHandler, wsHandler, svcmapHandler, catalogHandler, tierHandler, eventHandler}
 	if sshHandler != nil {
 		extraRoutes = append(extraRoutes, sshHandler)
 	}
+	if backupHandler != nil {
+		extraRoutes = append(extraRoutes, backupHandler)
+	}
 	// In demo mode, use DemoAuthMiddleware instead of JWT validation.
 	var authRegistrar server.RouteRegistrar
 	if isDemoMode {
@@ -444,7 +724,66 @@ func main() {
 		authRegistrar = authHandler
 	}
 
-	srv := server.New(addr, reg, logger, readyCheck, authRegistrar, dashboardHandler, devMode, isDemoMode, extraRoutes...)
+	// Rate limits are charged to the authenticated user (which covers API
+	// keys) and fall back to the client IP for anonymous requests. Rejected
+	// credentials are limited per client IP before authentication.
+	rateLimitCfg := server.DefaultRateLimitConfig()
+	if err := viperCfg.UnmarshalKey("server.rate_limit", &rateLimitCfg); err != nil {
+		logger.Fatal("invalid rate limit configuration", zap.Error(err))
+	}
+	rateLimitCfg.KeyFunc = func(r *http.Request) string {
+		if claims := auth.UserFromContext(r.Context()); claims != nil {
+			return "user:" + claims.UserID
+		}
+		return ""
+	}
+	rateLimiter, err := server.NewRateLimiter(rateLimitCfg)
+	if err != nil {
+		logger.Fatal("invalid rate limit configuration", zap.Error(err))
+	}
+	logger.Info("rate limiting configured",
+		zap.String("component", "server"),
+		zap.Bool("enabled", rateLimitCfg.Enabled),
+		zap.Float64("requests_per_second", rateLimitCfg.RequestsPerSecond),
+		zap.Int("burst", rateLimitCfg.Burst),
+		zap.Float64("failed_auth_requests_per_second", rateLimitCfg.FailedAuthRequestsPerSecond),
+		zap.Int("failed_auth_burst", rateLimitCfg.FailedAuthBurst),
+		zap.Int("route_overrides", len(rateLimitCfg.Routes)),
+		zap.Strings("trusted_networks", rateLimitCfg.TrustedNetworks),
+		zap.Strings("trusted_proxies", rateLimitCfg.TrustedProxies),
+	)
+
+	compressionCfg := server.DefaultCompressionConfig()
+	if err := viperCfg.UnmarshalKey("server.compression", &compressionCfg); err != nil {
+		logger.Fatal("invalid compression configuration", zap.Error(err))
+	}
+	logger.Info("response compression configured",
+		zap.String("component", "server"),
+		zap.Bool("enabled", compressionCfg.Enabled),
+		zap.Int("min_size", compressionCfg.MinSize),
+	)
+
+	allowlistCfg := server.DefaultIPAllowlistConfig()
+	if err := viperCfg.UnmarshalKey("server.ip_allowlist", &allowlistCfg); err != nil {
+		logger.Fatal("invalid ip allowlist configuration", zap.Error(err))
+	}
+	allowlist, err := server.NewIPAllowlistMiddleware(allowlistCfg)
+	if err != nil {
+		logger.Fatal("invalid ip allowlist configuration", zap.Error(err))
+	}
+	if allowlistCfg.Enabled {
+		logger.Info("ip allowlist configured",
+			zap.String("component", "server"),
+			zap.Strings("allowed_networks", allowlistCfg.AllowedNetworks),
+			zap.Strings("path_prefixes", allowlistCfg.PathPrefixes),
+			zap.Strings("trusted_proxies", allowlistCfg.TrustedProxies),
+		)
+	}
+
+	srv := server.New(addr, reg, logger, readyCheck, authRegistrar, dashboardHandler, rateLimiter, server.NewCompressionMiddleware(compressionCfg), allowlist, devMode, isDemoMode, extraRoutes...)
+	// Plugins (including the MQTT connection) report through
+	// plugin.HealthChecker; core services are registered here.
+	srv.AddHealthCheck("svcmap_scheduler", false, svcmapScheduler.Health)
 
 	// Start server in background
 	go func() {
@@ -462,10 +801,24 @@ func main() {
 	}
 	fmt.Fprintf(os.Stderr, "\n  SubNetree %s is ready!\n  Open http://localhost:%s in your browser.\n\n", version.Short(), port)
 
-	// Wait for shutdown signal
+	// Wait for shutdown signal. SIGHUP reloads the configuration file.
+	reloader := &configReloader{
+		path:     *configPath,
+		tier:     activeTier,
+		startup:  viperCfg,
+		current:  viperCfg,
+		level:    logLevel,
+		registry: reg,
+		logger:   logger,
+	}
 	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 	sig := <-sigCh
+	for sig == syscall.SIGHUP {
+		logger.Info("received SIGHUP, reloading configuration")
+		reloader.reload(ctx)
+		sig = <-sigCh
+	}
 
 	logger.Info("received shutdown signal", zap.String("signal", sig.String()))
 
@@ -474,29 +827,46 @@ func main() {
 	defer shutdownCancel()
 
 	svcmapScheduler.Stop()
+	if backupScheduler != nil {
+		backupScheduler.Stop()
+	}
 	reg.StopAll(shutdownCtx)
+	if eventLog != nil {
+		eventLog.Stop()
+	}
 
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		logger.Error("server shutdown error", zap.Error(err))
 	}
 
+	// Flush spans recorded during shutdown.
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("tracing shutdown error", zap.Error(err))
+	}
+
 	logger.Info("SubNetree server stopped")
 }
 
 // vaultDecryptAdapter adapts vault.Module to the recon.CredentialDecrypter interface.
 // Lives in the composition root to avoid coupling recon -> vault.
+// The caller name is recorded in the vault's credential access log.
 type vaultDecryptAdapter struct {
-	vault *vault.Module
+	vault  *vault.Module
+	caller string
 }
 
 func (a *vaultDecryptAdapter) DecryptCredential(ctx context.Context, id string) (map[string]any, error) {
-	return a.vault.DecryptCredentialData(ctx, id)
+	return a.vault.DecryptCredentialData(vault.WithCaller(ctx, a.caller), id)
 }
 
 // tokenAdapter adapts auth.TokenService to the gateway.TokenValidator interface.
 // Lives in the composition root to avoid coupling gateway -> auth.
+// Tokens whose session has been revoked are rejected, as the auth
+// middleware does for ordinary requests.
 type tokenAdapter struct {
-	svc *auth.TokenService
+	svc      *auth.TokenService
+	sessions auth.CredentialBackend
+	roles    auth.RoleResolver
 }
 
 func (a *tokenAdapter) ValidateAccessToken(token string) (*gateway.TokenClaims, error) {
@@ -504,7 +874,18 @@ func (a *tokenAdapter) ValidateAccessToken(token string) (*gateway.TokenClaims,
 	if err != nil {
 		return nil, err
 	}
-	return &gateway.TokenClaims{UserID: claims.UserID}, nil
+	if !a.sessions.SessionActive(context.Background(), claims.SessionID) {
+		return nil, auth.ErrSessionNotFound
+	}
+	return &gateway.TokenClaims{UserID: claims.UserID, Role: claims.Role}, nil
+}
+
+func (a *tokenAdapter) RoleAllows(ctx context.Context, role, module string, write bool) bool {
+	def, err := a.roles.GetRole(ctx, auth.Role(role))
+	if err != nil {
+		return false
+	}
+	return def.Allows(module, write)
 }
 
 // serviceSourceAdapter adapts dispatch.DispatchStore to svcmap.ServiceSource.
@@ -581,6 +962,23 @@ func (a *agentListerAdapter) ListAgentsWithDevice(ctx context.Context) ([]svcmap
 	return refs, nil
 }
 
+// scanTargetAdapter adapts the scan interface setting to recon.ScanTargetSource.
+type scanTargetAdapter struct {
+	settings   services.SettingsRepository
+	interfaces *services.InterfaceService
+}
+
+func (a *scanTargetAdapter) ScanSubnets(ctx context.Context) ([]string, error) {
+	setting, err := a.settings.Get(ctx, services.ScanInterfacesKey)
+	if err != nil {
+		if err == services.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return a.interfaces.SubnetsForInterfaces(services.ParseScanInterfaces(setting.Value))
+}
+
 // profileSourceAdapter adapts dispatch.DispatchStore to recon.ProfileSource.
 type profileSourceAdapter struct {
 	store *dispatch.DispatchStore
@@ -661,6 +1059,287 @@ func (a *profileSourceAdapter) GetServices(ctx context.Context, agentID string)
 	return result, nil
 }
 
+func (a *profileSourceAdapter) GetContainers(ctx context.Context, agentID string) ([]recon.ContainerData, error) {
+	containers, err := a.store.ListAgentContainers(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]recon.ContainerData, len(containers))
+	for i := range containers {
+		result[i] = recon.ContainerData{
+			ContainerID: containers[i].ContainerID,
+			Name:        containers[i].Name,
+			Image:       containers[i].Image,
+			State:       containers[i].State,
+		}
+	}
+	return result, nil
+}
+
+// scanMetricsAdapter adapts recon.ReconStore to insight.ScanMetricsSource.
+// Lives in the composition root to avoid coupling insight -> recon.
+type scanMetricsAdapter struct {
+	store *recon.ReconStore
+}
+
+func (a *scanMetricsAdapter) ListRawMetrics(ctx context.Context, limit int) ([]models.ScanMetrics, error) {
+	return a.store.ListRawMetrics(ctx, limit)
+}
+
+func (a *scanMetricsAdapter) WeeklyFailedScans(ctx context.Context, start, end time.Time) ([]insight.WeeklyScanFailures, error) {
+	aggs, err := a.store.GetWeeklyAggregatesInRange(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]insight.WeeklyScanFailures, 0, len(aggs))
+	for i := range aggs {
+		periodStart, err := time.Parse(time.RFC3339, aggs[i].PeriodStart)
+		if err != nil {
+			continue
+		}
+		result = append(result, insight.WeeklyScanFailures{
+			PeriodStart: periodStart,
+			FailedScans: aggs[i].FailedScans,
+		})
+	}
+	return result, nil
+}
+
+// capacityAdapter adapts recon.ReconStore to insight.CapacitySource.
+// Lives in the composition root to avoid coupling insight -> recon.
+type capacityAdapter struct {
+	store *recon.ReconStore
+}
+
+func (a *capacityAdapter) ListDeviceSightings(ctx context.Context) ([]insight.DeviceSighting, error) {
+	devices, err := a.store.ListAllDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]insight.DeviceSighting, len(devices))
+	for i := range devices {
+		result[i] = insight.DeviceSighting{
+			FirstSeen:   devices[i].FirstSeen,
+			IPAddresses: devices[i].IPAddresses,
+		}
+	}
+	return result, nil
+}
+
+func (a *capacityAdapter) WeeklyHostsAlive(ctx context.Context, start, end time.Time) ([]insight.WeeklyHostsAlive, error) {
+	aggs, err := a.store.GetWeeklyAggregatesInRange(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]insight.WeeklyHostsAlive, 0, len(aggs))
+	for i := range aggs {
+		periodStart, err := time.Parse(time.RFC3339, aggs[i].PeriodStart)
+		if err != nil {
+			continue
+		}
+		result = append(result, insight.WeeklyHostsAlive{
+			PeriodStart:   periodStart,
+			AvgHostsAlive: aggs[i].AvgHostsAlive,
+		})
+	}
+	return result, nil
+}
+
+// insightAlertAdapter adapts pulse.PulseStore and recon.ReconStore to
+// insight.AlertSource, adding device context to each active alert.
+// Lives in the composition root to avoid coupling insight -> pulse/recon.
+type insightAlertAdapter struct {
+	alerts  *pulse.PulseStore
+	devices *recon.ReconStore
+}
+
+func (a *insightAlertAdapter) ListActiveAlerts(ctx context.Context) ([]insight.ActiveAlert, error) {
+	alerts, err := a.alerts.ListActiveAlerts(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Look each device up once; alerts for missing devices keep the
+	// name pulse resolved.
+	devices := make(map[string]*models.Device)
+	device := func(id string) *models.Device {
+		if d, ok := devices[id]; ok {
+			return d
+		}
+		d, err := a.devices.GetDevice(ctx, id)
+		if err != nil {
+			d = nil
+		}
+		devices[id] = d
+		return d
+	}
+
+	result := make([]insight.ActiveAlert, len(alerts))
+	for i := range alerts {
+		al := &alerts[i]
+		aa := insight.ActiveAlert{
+			ID:            al.ID,
+			DeviceID:      al.DeviceID,
+			DeviceName:    al.DeviceName,
+			Severity:      al.Severity,
+			Message:       al.Message,
+			TriggeredAt:   al.TriggeredAt,
+			Acknowledged:  al.AcknowledgedAt != nil,
+			InMaintenance: al.MaintWindowID != "",
+			SuppressedBy:  al.SuppressedBy,
+		}
+		if d := device(al.DeviceID); d != nil {
+			aa.DeviceType = string(d.DeviceType)
+			aa.Location = d.Location
+		}
+		if al.SuppressedBy != "" {
+			if d := device(al.SuppressedBy); d != nil {
+				aa.SuppressedName = d.Hostname
+				if aa.SuppressedName == "" && len(d.IPAddresses) > 0 {
+					aa.SuppressedName = d.IPAddresses[0]
+				}
+			}
+		}
+		result[i] = aa
+	}
+	return result, nil
+}
+
+// deviceSearchAdapter adapts recon.ReconStore to insight.DeviceSearcher.
+// Lives in the composition root to avoid coupling insight -> recon.
+type deviceSearchAdapter struct {
+	store *recon.ReconStore
+}
+
+func (a *deviceSearchAdapter) SearchDevices(ctx context.Context, f insight.DeviceFilter) ([]models.Device, int, error) {
+	return a.store.ListDevices(ctx, recon.ListDevicesOptions{
+		Limit:      f.Limit,
+		Status:     f.Status,
+		DeviceType: f.DeviceType,
+		Category:   f.Category,
+		Owner:      f.Owner,
+		Location:   f.Location,
+		Search:     f.Text,
+	})
+}
+
+// mcpScanAdapter adapts recon.Module to mcp.ScanActuator.
+// Lives in the composition root to avoid coupling mcp -> recon.
+type mcpScanAdapter struct {
+	recon *recon.Module
+}
+
+func (a *mcpScanAdapter) StartScan(ctx context.Context, subnet string) (*models.ScanResult, error) {
+	return a.recon.StartScan(ctx, subnet)
+}
+
+func (a *mcpScanAdapter) GetScan(ctx context.Context, id string) (*models.ScanResult, error) {
+	scan, err := a.recon.Store().GetScan(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return scan, err
+}
+
+// mcpAlertAdapter adapts pulse.PulseStore to mcp.AlertActuator.
+// Lives in the composition root to avoid coupling mcp -> pulse.
+type mcpAlertAdapter struct {
+	store *pulse.PulseStore
+}
+
+func (a *mcpAlertAdapter) AcknowledgeAlert(ctx context.Context, id string) (*mcpmod.AlertState, error) {
+	if err := a.store.AcknowledgeAlert(ctx, id); err != nil {
+		return nil, err
+	}
+	return a.alertState(ctx, id)
+}
+
+func (a *mcpAlertAdapter) ResolveAlert(ctx context.Context, id string) (*mcpmod.AlertState, error) {
+	if err := a.store.ResolveAlert(ctx, id, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return a.alertState(ctx, id)
+}
+
+func (a *mcpAlertAdapter) alertState(ctx context.Context, id string) (*mcpmod.AlertState, error) {
+	alert, err := a.store.GetAlert(ctx, id)
+	if err != nil || alert == nil {
+		return nil, err
+	}
+	return &mcpmod.AlertState{
+		ID:             alert.ID,
+		CheckID:        alert.CheckID,
+		DeviceID:       alert.DeviceID,
+		Severity:       alert.Severity,
+		Message:        alert.Message,
+		TriggeredAt:    alert.TriggeredAt,
+		AcknowledgedAt: alert.AcknowledgedAt,
+		ResolvedAt:     alert.ResolvedAt,
+	}, nil
+}
+
+// mcpCheckAdapter adapts pulse.Module to mcp.CheckActuator. Checks are
+// created through the module so they get the same validation as the API.
+// Lives in the composition root to avoid coupling mcp -> pulse.
+type mcpCheckAdapter struct {
+	pulse *pulse.Module
+}
+
+func (a *mcpCheckAdapter) CreateCheck(ctx context.Context, spec mcpmod.CheckSpec) (*mcpmod.CheckState, error) {
+	check, err := a.pulse.CreateCheck(ctx, pulse.NewCheck{
+		DeviceID:        spec.DeviceID,
+		CheckType:       spec.CheckType,
+		Target:          spec.Target,
+		IntervalSeconds: spec.IntervalSeconds,
+		AgentID:         spec.AgentID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	state := mcpCheckState(check)
+	return &state, nil
+}
+
+func (a *mcpCheckAdapter) ListChecks(ctx context.Context, deviceID string) ([]mcpmod.CheckState, error) {
+	checks, err := a.pulse.Store().ListAllChecks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	states := make([]mcpmod.CheckState, 0, len(checks))
+	for i := range checks {
+		if deviceID != "" && checks[i].DeviceID != deviceID {
+			continue
+		}
+		states = append(states, mcpCheckState(&checks[i]))
+	}
+	return states, nil
+}
+
+func (a *mcpCheckAdapter) DeleteCheck(ctx context.Context, id string) (bool, error) {
+	check, err := a.pulse.Store().GetCheck(ctx, id)
+	if err != nil || check == nil {
+		return false, err
+	}
+	if err := a.pulse.Store().DeleteCheck(ctx, id); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func mcpCheckState(c *pulse.Check) mcpmod.CheckState {
+	return mcpmod.CheckState{
+		ID:              c.ID,
+		DeviceID:        c.DeviceID,
+		DeviceName:      c.DeviceName,
+		CheckType:       c.CheckType,
+		Target:          c.Target,
+		IntervalSeconds: c.IntervalSeconds,
+		AgentID:         c.AgentID,
+		Enabled:         c.Enabled,
+		CreatedAt:       c.CreatedAt,
+	}
+}
+
 // mcpDeviceAdapter adapts recon.ReconStore to mcp.DeviceQuerier.
 // Lives in the composition root to avoid coupling mcp -> recon.
 type mcpDeviceAdapter struct {
@@ -694,6 +1373,51 @@ func (a *mcpDeviceAdapter) FindStaleDevices(ctx context.Context, threshold time.
 	return a.store.FindStaleDevices(ctx, threshold)
 }
 
+// mcpTopologyAdapter adapts recon.ReconStore to mcp.TopologyReader.
+// Lives in the composition root to avoid coupling mcp -> recon.
+type mcpTopologyAdapter struct {
+	store *recon.ReconStore
+}
+
+func (a *mcpTopologyAdapter) GetDeviceTree(ctx context.Context) ([]mcpmod.TopologyNode, error) {
+	tree, err := a.store.GetDeviceTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]mcpmod.TopologyNode, len(tree))
+	for i := range tree {
+		nodes[i] = mcpmod.TopologyNode{
+			ID:             tree[i].ID,
+			Hostname:       tree[i].Hostname,
+			DeviceType:     string(tree[i].DeviceType),
+			Status:         string(tree[i].Status),
+			IPAddresses:    tree[i].IPAddresses,
+			ParentDeviceID: tree[i].ParentDeviceID,
+			NetworkLayer:   tree[i].NetworkLayer,
+		}
+	}
+	return nodes, nil
+}
+
+func (a *mcpTopologyAdapter) GetTopologyLinks(ctx context.Context) ([]mcpmod.TopologyLink, error) {
+	links, err := a.store.GetTopologyLinks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]mcpmod.TopologyLink, len(links))
+	for i := range links {
+		out[i] = mcpmod.TopologyLink{
+			SourceDeviceID: links[i].SourceDeviceID,
+			TargetDeviceID: links[i].TargetDeviceID,
+			SourcePort:     links[i].SourcePort,
+			TargetPort:     links[i].TargetPort,
+			LinkType:       links[i].LinkType,
+			Speed:          links[i].Speed,
+		}
+	}
+	return out, nil
+}
+
 // mcpServiceAdapter adapts svcmap.Store to mcp.ServiceQuerier.
 // Lives in the composition root to avoid coupling mcp -> svcmap.
 type mcpServiceAdapter struct {
@@ -725,6 +1449,26 @@ func (a *tailscaleDeviceAdapter) GetDeviceByMAC(ctx context.Context, mac string)
 	return a.store.GetDeviceByMAC(ctx, mac)
 }
 
+func (a *tailscaleDeviceAdapter) SetDeviceTags(ctx context.Context, id string, tags []string) error {
+	return a.store.UpdateDevice(ctx, id, recon.UpdateDeviceParams{Tags: &tags})
+}
+
+func (a *tailscaleDeviceAdapter) SetRouteLinks(ctx context.Context, routerID string, deviceIDs []string) error {
+	if err := a.store.RemoveTailscaleRouteLinksForDevice(ctx, routerID); err != nil {
+		return err
+	}
+	for _, id := range deviceIDs {
+		if err := a.store.UpsertTopologyLink(ctx, &recon.TopologyLink{
+			SourceDeviceID: routerID,
+			TargetDeviceID: id,
+			LinkType:       tsmod.RouteLinkType,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // demoAuthRegistrar implements server.RouteRegistrar for demo mode.
 // It registers no routes (login/setup not needed) and provides the
 // DemoAuthMiddleware that injects synthetic viewer claims on every API request.
@@ -768,6 +1512,10 @@ func (a *autodocDeviceAdapter) GetDeviceServices(ctx context.Context, deviceID s
 	return a.store.GetDeviceServices(ctx, deviceID)
 }
 
+func (a *autodocDeviceAdapter) GetDevicePorts(ctx context.Context, deviceID string) ([]models.DevicePort, error) {
+	return a.store.GetDevicePorts(ctx, deviceID)
+}
+
 func (a *autodocDeviceAdapter) GetChildDevices(ctx context.Context, parentID string) ([]models.Device, error) {
 	all, err := a.store.ListAllDevices(ctx)
 	if err != nil {
@@ -782,6 +1530,57 @@ func (a *autodocDeviceAdapter) GetChildDevices(ctx context.Context, parentID str
 	return children, nil
 }
 
+// autodocRelationshipAdapter adapts recon.ReconStore to autodoc.RelationshipReader.
+// Lives in the composition root to avoid coupling autodoc -> recon.
+type autodocRelationshipAdapter struct {
+	store *recon.ReconStore
+}
+
+func (a *autodocRelationshipAdapter) ListDeviceRelationships(ctx context.Context, deviceID string) ([]autodoc.DeviceRelationship, error) {
+	rels, err := a.store.ListDeviceRelationships(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]autodoc.DeviceRelationship, len(rels))
+	for i := range rels {
+		rel := autodoc.DeviceRelationship{
+			Type:     rels[i].Type,
+			Incoming: rels[i].ToDeviceID == deviceID,
+			PeerID:   rels[i].ToDeviceID,
+			PeerName: rels[i].ToHostname,
+			Note:     rels[i].Note,
+		}
+		if rel.Incoming {
+			rel.PeerID, rel.PeerName = rels[i].FromDeviceID, rels[i].FromHostname
+		}
+		if rel.PeerName == "" {
+			rel.PeerName = rel.PeerID
+		}
+		result[i] = rel
+	}
+	return result, nil
+}
+
+// svcmapAlertAdapter adapts pulse.PulseStore to svcmap.AlertSource.
+// Lives in the composition root to avoid coupling svcmap -> pulse.
+type svcmapAlertAdapter struct {
+	store *pulse.PulseStore
+}
+
+func (a *svcmapAlertAdapter) CriticalDeviceIDs(ctx context.Context) (map[string]bool, error) {
+	alerts, err := a.store.ListActiveAlerts(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool)
+	for i := range alerts {
+		if alerts[i].Severity == "critical" {
+			ids[alerts[i].DeviceID] = true
+		}
+	}
+	return ids, nil
+}
+
 // autodocAlertAdapter adapts pulse.PulseStore to autodoc.AlertReader.
 // Lives in the composition root to avoid coupling autodoc -> pulse.
 type autodocAlertAdapter struct {
@@ -808,7 +1607,30 @@ func (a *autodocAlertAdapter) ListDeviceAlerts(ctx context.Context, deviceID str
 	return result, nil
 }
 
-// netboxDeviceAdapter adapts recon.ReconStore to netbox.DeviceReader.
+// autodocSoftwareAdapter adapts dispatch.DispatchStore to autodoc.SoftwareReader.
+// Lives in the composition root to avoid coupling autodoc -> dispatch.
+type autodocSoftwareAdapter struct {
+	store *dispatch.DispatchStore
+}
+
+func (a *autodocSoftwareAdapter) ListDeviceSoftware(ctx context.Context, deviceID string) ([]autodoc.InstalledSoftware, error) {
+	pkgs, err := a.store.ListDeviceSoftware(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]autodoc.InstalledSoftware, len(pkgs))
+	for i := range pkgs {
+		result[i] = autodoc.InstalledSoftware{
+			Name:      pkgs[i].Name,
+			Version:   pkgs[i].Version,
+			Publisher: pkgs[i].Publisher,
+		}
+	}
+	return result, nil
+}
+
+// netboxDeviceAdapter adapts recon.ReconStore to netbox.DeviceReader and
+// netbox.DeviceWriter.
 // Lives in the composition root to avoid coupling netbox -> recon.
 type netboxDeviceAdapter struct {
 	store *recon.ReconStore
@@ -821,3 +1643,72 @@ func (a *netboxDeviceAdapter) ListAllDevices(ctx context.Context) ([]models.Devi
 func (a *netboxDeviceAdapter) GetDevice(ctx context.Context, id string) (*models.Device, error) {
 	return a.store.GetDevice(ctx, id)
 }
+
+func (a *netboxDeviceAdapter) SetDeviceCustomField(ctx context.Context, id, key, value string) error {
+	return a.store.SetDeviceCustomField(ctx, id, key, value)
+}
+
+// grafanaMetricsAdapter adapts pulse.PulseStore to grafana.MetricsReader.
+// Lives in the composition root to avoid coupling grafana -> pulse.
+type grafanaMetricsAdapter struct {
+	store *pulse.PulseStore
+}
+
+func (a *grafanaMetricsAdapter) ListMonitoredDevices(ctx context.Context) ([]grafana.MonitoredDevice, error) {
+	checks, err := a.store.ListAllChecks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(checks))
+	devices := make([]grafana.MonitoredDevice, 0, len(checks))
+	for i := range checks {
+		c := &checks[i]
+		if seen[c.DeviceID] {
+			continue
+		}
+		seen[c.DeviceID] = true
+		name := c.DeviceName
+		if name == "" {
+			name = c.DeviceID
+		}
+		devices = append(devices, grafana.MonitoredDevice{ID: c.DeviceID, Name: name})
+	}
+	return devices, nil
+}
+
+func (a *grafanaMetricsAdapter) QueryDeviceMetric(ctx context.Context, deviceID, metric, timeRange string) ([]grafana.DataPoint, error) {
+	series, err := a.store.QueryMetrics(ctx, deviceID, metric, timeRange)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]grafana.DataPoint, len(series.Points))
+	for i, p := range series.Points {
+		points[i] = grafana.DataPoint{Timestamp: p.Timestamp, Value: p.Value}
+	}
+	return points, nil
+}
+
+// grafanaAlertAdapter adapts pulse.PulseStore to grafana.AlertReader.
+// Lives in the composition root to avoid coupling grafana -> pulse.
+type grafanaAlertAdapter struct {
+	store *pulse.PulseStore
+}
+
+func (a *grafanaAlertAdapter) ListAlertsInRange(ctx context.Context, from, to time.Time, limit int) ([]grafana.Alert, error) {
+	alerts, err := a.store.ListAlerts(ctx, pulse.AlertFilters{Since: from, Until: to, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]grafana.Alert, len(alerts))
+	for i := range alerts {
+		result[i] = grafana.Alert{
+			ID:          alerts[i].ID,
+			DeviceName:  alerts[i].DeviceName,
+			Severity:    alerts[i].Severity,
+			Message:     alerts[i].Message,
+			TriggeredAt: alerts[i].TriggeredAt,
+			ResolvedAt:  alerts[i].ResolvedAt,
+		}
+	}
+	return result, nil
+}
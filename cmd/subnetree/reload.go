@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/HerbHall/subnetree/internal/config"
+	"github.com/HerbHall/subnetree/internal/registry"
+	"github.com/HerbHall/subnetree/internal/server"
+	"github.com/HerbHall/subnetree/internal/tier"
+	pkgcatalog "github.com/HerbHall/subnetree/pkg/catalog"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// configReloader re-reads the configuration file on SIGHUP and applies the
+// settings that can change without a restart: the log level and the config
+// of plugins implementing plugin.Reloadable. Every other changed key, such
+// as the listen address or database path, is reported as requiring a
+// restart until the server is restarted.
+type configReloader struct {
+	path     string
+	tier     pkgcatalog.HardwareTier
+	startup  *viper.Viper // config the server was started with
+	current  *viper.Viper // config as of the last reload
+	level    zap.AtomicLevel
+	registry *registry.Registry
+	logger   *zap.Logger
+}
+
+// reload loads the configuration file and applies what changed. A config
+// that fails to load leaves the running configuration untouched.
+func (r *configReloader) reload(ctx context.Context) {
+	next, err := server.LoadConfig(r.path)
+	if err != nil {
+		r.logger.Error("config reload failed, keeping current configuration", zap.Error(err))
+		return
+	}
+	tier.ApplyDefaults(next, r.tier)
+
+	var applied, failed []string
+	reloadPlugins := make(map[string]bool)
+	for _, key := range config.ChangedKeys(r.current, next) {
+		switch {
+		case key == "logging.level":
+			if err := r.level.UnmarshalText([]byte(next.GetString(key))); err != nil {
+				r.logger.Error("invalid log level in reloaded config, keeping current level",
+					zap.String("level", next.GetString(key)), zap.Error(err))
+				failed = append(failed, key)
+				continue
+			}
+			applied = append(applied, key)
+		case r.liveReloadable(key):
+			reloadPlugins[pluginName(key)] = true
+			applied = append(applied, key)
+		}
+	}
+
+	names := make([]string, 0, len(reloadPlugins))
+	for name := range reloadPlugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	cfg := config.New(next)
+	for _, name := range names {
+		if err := r.registry.Reload(ctx, name, cfg.Sub("plugins."+name)); err != nil {
+			r.logger.Error("plugin rejected reloaded config, keeping its current settings",
+				zap.String("plugin", name), zap.Error(err))
+			failed = append(failed, "plugins."+name)
+		}
+	}
+
+	// Compare against the startup config so a pending change keeps being
+	// reported on every reload until the server is restarted.
+	var restart []string
+	for _, key := range config.ChangedKeys(r.startup, next) {
+		if !r.liveReloadable(key) {
+			restart = append(restart, key)
+		}
+	}
+
+	switch {
+	case len(applied) == 0 && len(restart) == 0:
+		r.logger.Info("configuration reloaded, no changes")
+	case len(applied) > 0:
+		r.logger.Info("configuration reloaded",
+			zap.Strings("changed", applied),
+			zap.String("log_level", r.level.String()),
+		)
+	}
+	if len(failed) > 0 {
+		r.logger.Warn("some reloaded settings were not applied", zap.Strings("keys", failed))
+	}
+	if len(restart) > 0 {
+		r.logger.Warn("changed settings require a restart to take effect", zap.Strings("keys", restart))
+	}
+
+	r.current = next
+}
+
+// liveReloadable reports whether a changed key is applied without a restart.
+// Enabling or disabling a plugin always requires a restart.
+func (r *configReloader) liveReloadable(key string) bool {
+	if key == "logging.level" {
+		return true
+	}
+	name := pluginName(key)
+	return name != "" && key != "plugins."+name+".enabled" && r.registry.Reloadable(name)
+}
+
+// pluginName returns the plugin a "plugins.<name>.*" key belongs to, or "".
+func pluginName(key string) string {
+	rest, ok := strings.CutPrefix(key, "plugins.")
+	if !ok {
+		return ""
+	}
+	name, _, _ := strings.Cut(rest, ".")
+	return name
+}
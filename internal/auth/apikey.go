@@ -0,0 +1,363 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// APIKeyPrefix marks a bearer credential as an API key rather than a JWT.
+const APIKeyPrefix = "sk_"
+
+// API key scopes. Every key carries exactly one of ScopeRead or ScopeWrite;
+// any other scope is a plugin name that limits the key to /api/v1/{plugin}/.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+// API key errors.
+var (
+	ErrInvalidScopes  = errors.New("scopes must include exactly one of read or write, plus optional module names")
+	ErrInvalidKeyName = errors.New("name is required (max 64 characters)")
+	ErrAPIKeyNotFound = errors.New("api key not found")
+)
+
+var moduleScopePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+
+// APIKey is a long-lived credential for automation. Only the SHA-256 hash of
+// the key is stored; the raw key is returned once at creation.
+type APIKey struct {
+	ID         string     `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name" example:"scout-updater"`
+	Prefix     string     `json:"prefix" example:"sk_3f9a1c"` // First characters of the key, for identification
+	Scopes     []string   `json:"scopes" example:"read,recon"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreateAPIKeyRequest is the request body for POST /auth/api-keys.
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" example:"scout-updater"`
+	Scopes []string `json:"scopes" example:"read,recon"`
+}
+
+// CreateAPIKeyResponse returns the new key. Key is shown only once.
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key" example:"sk_3f9a1c..."`
+}
+
+// allows reports whether the key's scopes permit the request.
+func (k *APIKey) allows(method, path string) bool {
+	if !slices.Contains(k.Scopes, ScopeWrite) && isWriteRequest(method, path) {
+		return false
+	}
+	var modules []string
+	for _, s := range k.Scopes {
+		if s != ScopeRead && s != ScopeWrite {
+			modules = append(modules, s)
+		}
+	}
+	if len(modules) == 0 {
+		return true
+	}
+	rest, ok := strings.CutPrefix(path, "/api/v1/")
+	if !ok {
+		return false
+	}
+	module, _, _ := strings.Cut(rest, "/")
+	return slices.Contains(modules, module)
+}
+
+// normalizeScopes validates and de-duplicates requested scopes.
+func normalizeScopes(scopes []string) ([]string, error) {
+	var out []string
+	access := 0
+	for _, s := range scopes {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if slices.Contains(out, s) {
+			continue
+		}
+		switch {
+		case s == ScopeRead || s == ScopeWrite:
+			access++
+		case !moduleScopePattern.MatchString(s) || s == "auth" || s == "users":
+			return nil, ErrInvalidScopes
+		}
+		out = append(out, s)
+	}
+	if access != 1 {
+		return nil, ErrInvalidScopes
+	}
+	return out, nil
+}
+
+// CreateAPIKey mints a new API key for the user and returns it with the raw key.
+func (s *Service) CreateAPIKey(ctx context.Context, userID, name string, scopes []string) (*APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 64 {
+		return nil, "", ErrInvalidKeyName
+	}
+	scopes, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", fmt.Errorf("generate api key: %w", err)
+	}
+	raw := APIKeyPrefix + hex.EncodeToString(b)
+
+	key := &APIKey{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    raw[:len(APIKeyPrefix)+6],
+		Scopes:    scopes,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.store.CreateAPIKey(ctx, key, HashToken(raw)); err != nil {
+		return nil, "", err
+	}
+	s.logger.Info("api key created",
+		zap.String("user_id", userID),
+		zap.String("key_id", key.ID),
+		zap.Strings("scopes", scopes),
+	)
+	return key, raw, nil
+}
+
+// ListAPIKeys returns the user's API keys, including revoked ones.
+func (s *Service) ListAPIKeys(ctx context.Context, userID string) ([]APIKey, error) {
+	return s.store.ListAPIKeys(ctx, userID)
+}
+
+// RevokeAPIKey revokes a key owned by userID. Admins may revoke any key.
+func (s *Service) RevokeAPIKey(ctx context.Context, userID string, isAdmin bool, id string) error {
+	key, err := s.store.GetAPIKey(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && key.UserID != userID && !isAdmin) {
+		return ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return s.store.RevokeAPIKey(ctx, id)
+}
+
+// AuthenticateAPIKey resolves a raw API key to the owning user's claims.
+// Revoked keys and keys of disabled users are rejected.
+func (s *Service) AuthenticateAPIKey(ctx context.Context, raw string) (*Claims, *APIKey, error) {
+	key, err := s.store.GetAPIKeyByHash(ctx, HashToken(raw))
+	if err != nil || key.RevokedAt != nil {
+		return nil, nil, ErrInvalidToken
+	}
+	user, err := s.store.GetUserByID(ctx, key.UserID)
+	if err != nil || user.Disabled {
+		return nil, nil, ErrInvalidToken
+	}
+	_ = s.store.TouchAPIKey(ctx, key.ID)
+	return &Claims{UserID: user.ID, Username: user.Username, Role: string(user.Role)}, key, nil
+}
+
+// CreateAPIKey inserts an API key with its hash.
+func (s *UserStore) CreateAPIKey(ctx context.Context, k *APIKey, keyHash string) error {
+	scopes, err := json.Marshal(k.Scopes)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO auth_api_keys (id, user_id, name, prefix, key_hash, scopes, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		k.ID, k.UserID, k.Name, k.Prefix, keyHash, string(scopes), k.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert api key: %w", err)
+	}
+	return nil
+}
+
+// apiKeyColumns is the shared SELECT column list for API key queries.
+const apiKeyColumns = `id, user_id, name, prefix, scopes, created_at, last_used_at, revoked_at`
+
+// GetAPIKey returns an API key by ID.
+func (s *UserStore) GetAPIKey(ctx context.Context, id string) (*APIKey, error) {
+	return scanAPIKey(s.db.QueryRowContext(ctx,
+		`SELECT `+apiKeyColumns+` FROM auth_api_keys WHERE id = ?`, id))
+}
+
+// GetAPIKeyByHash returns an API key by the hash of its raw value.
+func (s *UserStore) GetAPIKeyByHash(ctx context.Context, keyHash string) (*APIKey, error) {
+	return scanAPIKey(s.db.QueryRowContext(ctx,
+		`SELECT `+apiKeyColumns+` FROM auth_api_keys WHERE key_hash = ?`, keyHash))
+}
+
+// ListAPIKeys returns all API keys owned by a user, newest first.
+func (s *UserStore) ListAPIKeys(ctx context.Context, userID string) ([]APIKey, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+apiKeyColumns+` FROM auth_api_keys WHERE user_id = ? ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *k)
+	}
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey marks an API key as revoked.
+func (s *UserStore) RevokeAPIKey(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE auth_api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`,
+		time.Now().UTC(), id)
+	return err
+}
+
+// TouchAPIKey records that an API key was just used.
+func (s *UserStore) TouchAPIKey(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE auth_api_keys SET last_used_at = ? WHERE id = ?`, time.Now().UTC(), id)
+	return err
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIKey(row rowScanner) (*APIKey, error) {
+	var k APIKey
+	var scopes string
+	var lastUsed, revoked sql.NullTime
+	if err := row.Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &scopes,
+		&k.CreatedAt, &lastUsed, &revoked); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(scopes), &k.Scopes); err != nil {
+		return nil, fmt.Errorf("decode api key scopes: %w", err)
+	}
+	if lastUsed.Valid {
+		k.LastUsedAt = &lastUsed.Time
+	}
+	if revoked.Valid {
+		k.RevokedAt = &revoked.Time
+	}
+	return &k, nil
+}
+
+// handleCreateAPIKey mints an API key for the authenticated user.
+//
+//	@Summary		Create API key
+//	@Description	Mint a long-lived API key. The key is returned only once.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		CreateAPIKeyRequest	true	"Key name and scopes"
+//	@Success		201		{object}	CreateAPIKeyResponse
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		401		{object}	models.APIProblem
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/auth/api-keys [post]
+func (h *Handler) handleCreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	claims := UserFromContext(r.Context())
+	if claims == nil {
+		writeAuthError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAuthError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	key, raw, err := h.service.CreateAPIKey(r.Context(), claims.UserID, req.Name, req.Scopes)
+	if err != nil {
+		if errors.Is(err, ErrInvalidScopes) || errors.Is(err, ErrInvalidKeyName) {
+			writeAuthError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.Error("create api key error", zap.Error(err))
+		writeAuthError(w, http.StatusInternalServerError, "failed to create api key")
+		return
+	}
+	writeJSON(w, http.StatusCreated, CreateAPIKeyResponse{APIKey: *key, Key: raw})
+}
+
+// handleListAPIKeys lists the authenticated user's API keys.
+//
+//	@Summary		List API keys
+//	@Description	Returns the authenticated user's API keys. Raw keys are never returned.
+//	@Tags			auth
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{array}		APIKey
+//	@Failure		401	{object}	models.APIProblem
+//	@Failure		500	{object}	models.APIProblem
+//	@Router			/auth/api-keys [get]
+func (h *Handler) handleListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	claims := UserFromContext(r.Context())
+	if claims == nil {
+		writeAuthError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	keys, err := h.service.ListAPIKeys(r.Context(), claims.UserID)
+	if err != nil {
+		h.logger.Error("list api keys error", zap.Error(err))
+		writeAuthError(w, http.StatusInternalServerError, "failed to list api keys")
+		return
+	}
+	writeJSON(w, http.StatusOK, keys)
+}
+
+// handleRevokeAPIKey revokes an API key.
+//
+//	@Summary		Revoke API key
+//	@Description	Revoke an API key. Users may revoke their own keys; admins may revoke any key.
+//	@Tags			auth
+//	@Security		BearerAuth
+//	@Param			id	path	string	true	"API key ID"
+//	@Success		204	"No Content"
+//	@Failure		401	{object}	models.APIProblem
+//	@Failure		404	{object}	models.APIProblem
+//	@Router			/auth/api-keys/{id} [delete]
+func (h *Handler) handleRevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	claims := UserFromContext(r.Context())
+	if claims == nil {
+		writeAuthError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	err := h.service.RevokeAPIKey(r.Context(), claims.UserID, Role(claims.Role) == RoleAdmin, r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			writeAuthError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h.logger.Error("revoke api key error", zap.Error(err))
+		writeAuthError(w, http.StatusInternalServerError, "failed to revoke api key")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
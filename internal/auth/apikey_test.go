@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNormalizeScopes(t *testing.T) {
+	tests := []struct {
+		name    string
+		scopes  []string
+		want    int
+		wantErr bool
+	}{
+		{"read only", []string{"read"}, 1, false},
+		{"write with modules", []string{"Write", "recon", "pulse", "recon"}, 3, false},
+		{"no access scope", []string{"recon"}, 0, true},
+		{"read and write", []string{"read", "write"}, 0, true},
+		{"auth module", []string{"write", "auth"}, 0, true},
+		{"bad module name", []string{"read", "../x"}, 0, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := normalizeScopes(tc.scopes)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tc.wantErr)
+			}
+			if len(got) != tc.want {
+				t.Errorf("scopes = %v, want %d entries", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAPIKey_Middleware(t *testing.T) {
+	_, _, svc := testEnv(t)
+	ctx := context.Background()
+	admin, err := svc.Setup(ctx, "admin", "admin@example.com", "securepassword")
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	key, raw, err := svc.CreateAPIKey(ctx, admin.ID, "ci", []string{"read", "recon"})
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+
+	mw := AuthMiddlewareWithBackend(svc.Tokens(), svc)
+	var gotUser *Claims
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser = UserFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+	do := func(method, path, token string) int {
+		req := httptest.NewRequest(method, path, http.NoBody)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := do("GET", "/api/v1/recon/devices", raw); code != http.StatusOK {
+		t.Errorf("GET recon = %d, want 200", code)
+	}
+	if gotUser == nil || gotUser.UserID != admin.ID {
+		t.Errorf("claims = %+v, want owner %s", gotUser, admin.ID)
+	}
+	if code := do("POST", "/api/v1/recon/scan", raw); code != http.StatusForbidden {
+		t.Errorf("POST with read scope = %d, want 403", code)
+	}
+	if code := do("GET", "/api/v1/pulse/checks", raw); code != http.StatusForbidden {
+		t.Errorf("GET other module = %d, want 403", code)
+	}
+	if code := do("GET", "/api/v1/auth/api-keys", raw); code != http.StatusForbidden {
+		t.Errorf("GET api-keys with api key = %d, want 403", code)
+	}
+	if code := do("GET", "/api/v1/recon/devices", "sk_bogus"); code != http.StatusUnauthorized {
+		t.Errorf("unknown key = %d, want 401", code)
+	}
+
+	if err := svc.RevokeAPIKey(ctx, admin.ID, false, key.ID); err != nil {
+		t.Fatalf("RevokeAPIKey: %v", err)
+	}
+	if code := do("GET", "/api/v1/recon/devices", raw); code != http.StatusUnauthorized {
+		t.Errorf("revoked key = %d, want 401", code)
+	}
+}
+
+func TestRevokeAPIKey_OtherUser(t *testing.T) {
+	_, _, svc := testEnv(t)
+	ctx := context.Background()
+	admin, err := svc.Setup(ctx, "admin", "admin@example.com", "securepassword")
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	key, _, err := svc.CreateAPIKey(ctx, admin.ID, "ci", []string{"write"})
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+
+	if err := svc.RevokeAPIKey(ctx, "someone-else", false, key.ID); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("err = %v, want ErrAPIKeyNotFound", err)
+	}
+	keys, err := svc.ListAPIKeys(ctx, admin.ID)
+	if err != nil {
+		t.Fatalf("ListAPIKeys: %v", err)
+	}
+	if len(keys) != 1 || keys[0].RevokedAt != nil {
+		t.Errorf("keys = %+v, want one active key", keys)
+	}
+}
+
+func TestHandleCreateAPIKey(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+
+	w := doAuthRequest(mux, "POST", "/api/v1/auth/api-keys", "token", map[string]any{
+		"name":   "updater",
+		"scopes": []string{"nope"},
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400; body: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,297 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HerbHall/subnetree/internal/pagination"
+	"github.com/HerbHall/subnetree/pkg/plugin"
+	"go.uber.org/zap"
+)
+
+// Audit event types.
+const (
+	AuditLogin          = "login"
+	AuditLogout         = "logout"
+	AuditRefresh        = "refresh"
+	AuditPasswordChange = "password_change"
+	AuditPasswordReset  = "password_reset"
+)
+
+// Audit outcomes.
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+// Audit event topics. Every audit entry is published on
+// "auth.<event_type>.<outcome>"; see AuditTopic.
+const (
+	TopicLoginSucceeded = "auth.login.success"
+	TopicLoginFailed    = "auth.login.failure"
+	TopicLoggedOut      = "auth.logout.success"
+	TopicTokenRefreshed = "auth.refresh.success"
+)
+
+// AuditTopic returns the event bus topic for an audit entry.
+func AuditTopic(eventType, outcome string) string {
+	return "auth." + eventType + "." + outcome
+}
+
+// AuditEntry records one authentication event. It is also the payload of
+// the event published for it.
+type AuditEntry struct {
+	ID        int64     `json:"id" example:"42"`
+	EventType string    `json:"event_type" example:"login"`
+	Outcome   string    `json:"outcome" example:"failure"`
+	UserID    string    `json:"user_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Username  string    `json:"username,omitempty" example:"admin"`
+	IP        string    `json:"ip,omitempty" example:"192.168.1.20"`
+	UserAgent string    `json:"user_agent,omitempty" example:"Mozilla/5.0"`
+	Detail    string    `json:"detail,omitempty" example:"invalid_credentials"` // Login method on success, reason on failure
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// AuditFilter selects audit entries. Empty fields match everything.
+type AuditFilter struct {
+	UserID    string
+	EventType string
+	Outcome   string
+	Since     time.Time
+	Limit     int
+	Offset    int
+}
+
+// audit stores an entry and publishes it on the event bus. Failures are
+// logged and never fail the operation being audited. The client address
+// and user agent default to those recorded on ctx by withClientInfo.
+func (s *Service) audit(ctx context.Context, e *AuditEntry) {
+	ci := clientInfoFrom(ctx)
+	if e.IP == "" {
+		e.IP = ci.ip
+	}
+	if e.UserAgent == "" {
+		e.UserAgent = ci.userAgent
+	}
+	e.Timestamp = time.Now().UTC()
+
+	if err := s.store.InsertAuditEntry(ctx, e); err != nil {
+		s.logger.Warn("failed to write auth audit entry", zap.String("event_type", e.EventType), zap.Error(err))
+	}
+	if s.bus == nil {
+		return
+	}
+	if err := s.bus.Publish(ctx, plugin.Event{
+		Topic:     AuditTopic(e.EventType, e.Outcome),
+		Source:    "auth",
+		Timestamp: e.Timestamp,
+		Payload:   e,
+	}); err != nil {
+		s.logger.Warn("failed to publish auth audit event", zap.Error(err))
+	}
+}
+
+// auditSuccess records a successful event for user.
+func (s *Service) auditSuccess(ctx context.Context, eventType string, user *User, detail string) {
+	s.audit(ctx, &AuditEntry{
+		EventType: eventType,
+		Outcome:   AuditSuccess,
+		UserID:    user.ID,
+		Username:  user.Username,
+		Detail:    detail,
+	})
+}
+
+// auditFailure records a rejected attempt by user, which may carry only a
+// username or nothing at all. Errors that are not authentication failures
+// (database errors and the like) are not recorded.
+func (s *Service) auditFailure(ctx context.Context, eventType string, user *User, err error) {
+	reason := auditReason(err)
+	if reason == "" {
+		return
+	}
+	s.audit(ctx, &AuditEntry{
+		EventType: eventType,
+		Outcome:   AuditFailure,
+		UserID:    user.ID,
+		Username:  user.Username,
+		Detail:    reason,
+	})
+}
+
+// auditReason maps an authentication error to the reason stored in the
+// audit log, or "" if err is not an authentication failure.
+func auditReason(err error) string {
+	switch {
+	case errors.Is(err, ErrInvalidCredentials):
+		return "invalid_credentials"
+	case errors.Is(err, ErrUserDisabled):
+		return "account_disabled"
+	case errors.Is(err, ErrAccountLocked):
+		return "account_locked"
+	case errors.Is(err, ErrInvalidMFACode), errors.Is(err, ErrMFANotEnabled):
+		return "invalid_mfa_code"
+	case errors.Is(err, ErrInvalidPasskey):
+		return "invalid_passkey"
+	case errors.Is(err, ErrInvalidToken):
+		return "invalid_token"
+	case errors.Is(err, ErrInvalidResetToken):
+		return "invalid_reset_token"
+	default:
+		return ""
+	}
+}
+
+// ListAuditEntries returns audit entries matching f, newest first, and the
+// total number of matches.
+func (s *Service) ListAuditEntries(ctx context.Context, f AuditFilter) ([]AuditEntry, int, error) {
+	entries, err := s.store.ListAuditEntries(ctx, f)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.store.CountAuditEntries(ctx, f)
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// InsertAuditEntry stores an audit entry and sets its ID.
+func (s *UserStore) InsertAuditEntry(ctx context.Context, e *AuditEntry) error {
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO auth_audit (event_type, outcome, user_id, username, ip, user_agent, detail, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id`,
+		e.EventType, e.Outcome, e.UserID, e.Username, e.IP, e.UserAgent, e.Detail, e.Timestamp).Scan(&e.ID)
+	if err != nil {
+		return fmt.Errorf("insert audit entry: %w", err)
+	}
+	return nil
+}
+
+// auditWhere builds the WHERE clause and arguments for f.
+func auditWhere(f AuditFilter) (string, []any) {
+	var conds []string
+	var args []any
+	if f.UserID != "" {
+		conds = append(conds, "user_id = ?")
+		args = append(args, f.UserID)
+	}
+	if f.EventType != "" {
+		conds = append(conds, "event_type = ?")
+		args = append(args, f.EventType)
+	}
+	if f.Outcome != "" {
+		conds = append(conds, "outcome = ?")
+		args = append(args, f.Outcome)
+	}
+	if !f.Since.IsZero() {
+		conds = append(conds, "timestamp >= ?")
+		args = append(args, f.Since.UTC())
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// ListAuditEntries returns audit entries matching f, newest first.
+func (s *UserStore) ListAuditEntries(ctx context.Context, f AuditFilter) ([]AuditEntry, error) {
+	where, args := auditWhere(f)
+	limit := f.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	args = append(args, limit, max(f.Offset, 0))
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, event_type, outcome, user_id, username, ip, user_agent, detail, timestamp
+		FROM auth_audit`+where+` ORDER BY timestamp DESC, id DESC LIMIT ? OFFSET ?`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var e AuditEntry
+		if err := rows.Scan(&e.ID, &e.EventType, &e.Outcome, &e.UserID, &e.Username,
+			&e.IP, &e.UserAgent, &e.Detail, &e.Timestamp); err != nil {
+			return nil, fmt.Errorf("scan audit entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// CountAuditEntries returns the number of audit entries matching f.
+func (s *UserStore) CountAuditEntries(ctx context.Context, f AuditFilter) (int, error) {
+	where, args := auditWhere(f)
+	var n int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM auth_audit`+where, args...).Scan(&n)
+	return n, err
+}
+
+// handleListAudit returns authentication audit entries.
+//
+//	@Summary		List auth audit log
+//	@Description	Returns authentication events (logins, logouts, failed attempts, token refreshes, password changes), newest first. Requires admin role.
+//	@Tags			auth
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			user_id		query		string	false	"Filter by user ID"
+//	@Param			event_type	query		string	false	"Filter by event type (login, logout, refresh, password_change, password_reset)"
+//	@Param			outcome		query		string	false	"Filter by outcome (success, failure)"
+//	@Param			since		query		string	false	"Only entries at or after this RFC 3339 time"
+//	@Param			limit		query		int		false	"Maximum entries (default 100, max 1000)"
+//	@Param			offset		query		int		false	"Entries to skip"
+//	@Success		200			{array}		AuditEntry
+//	@Failure		400			{object}	models.APIProblem
+//	@Failure		401			{object}	models.APIProblem
+//	@Failure		403			{object}	models.APIProblem
+//	@Failure		500			{object}	models.APIProblem
+//	@Router			/auth/audit [get]
+func (h *Handler) handleListAudit(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	q := r.URL.Query()
+	f := AuditFilter{
+		UserID:    q.Get("user_id"),
+		EventType: q.Get("event_type"),
+		Outcome:   q.Get("outcome"),
+		Limit:     100,
+	}
+	if s := q.Get("since"); s != "" {
+		since, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			writeAuthError(w, http.StatusBadRequest, "since must be an RFC 3339 timestamp")
+			return
+		}
+		f.Since = since
+	}
+	if s := q.Get("limit"); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n > 0 && n <= 1000 {
+			f.Limit = n
+		}
+	}
+	if s := q.Get("offset"); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n > 0 {
+			f.Offset = n
+		}
+	}
+
+	entries, total, err := h.service.ListAuditEntries(r.Context(), f)
+	if err != nil {
+		h.logger.Error("list audit entries error", zap.Error(err))
+		writeAuthError(w, http.StatusInternalServerError, "failed to list audit entries")
+		return
+	}
+	pagination.SetHeaders(w, r, total, f.Limit, f.Offset)
+	writeJSON(w, http.StatusOK, entries)
+}
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/HerbHall/subnetree/internal/pagination"
+)
+
+func TestAudit_RecordsLoginLifecycle(t *testing.T) {
+	_, _, svc := testEnv(t)
+	ctx := context.Background()
+	admin, err := svc.Setup(ctx, "admin", "admin@example.com", "securepassword")
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	pub := &recordingPublisher{}
+	svc.SetEventBus(pub)
+
+	_, _ = svc.LoginFrom(ctx, "admin", "wrong", "192.0.2.1")
+	pair := loginWithClient(t, svc, "Firefox", "192.168.1.20:5555")
+	pair, err = svc.Refresh(ctx, pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if _, err := svc.Refresh(ctx, "not-a-token"); err == nil {
+		t.Fatal("expected refresh with a bogus token to fail")
+	}
+	if err := svc.Logout(ctx, pair.RefreshToken); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+
+	entries, total, err := svc.ListAuditEntries(ctx, AuditFilter{})
+	if err != nil {
+		t.Fatalf("ListAuditEntries: %v", err)
+	}
+	if total != 5 || len(entries) != 5 {
+		t.Fatalf("entries = %d (total %d), want 5", len(entries), total)
+	}
+
+	// Newest first.
+	want := []struct{ eventType, outcome string }{
+		{AuditLogout, AuditSuccess},
+		{AuditRefresh, AuditFailure},
+		{AuditRefresh, AuditSuccess},
+		{AuditLogin, AuditSuccess},
+		{AuditLogin, AuditFailure},
+	}
+	for i, w := range want {
+		if entries[i].EventType != w.eventType || entries[i].Outcome != w.outcome {
+			t.Errorf("entries[%d] = %s/%s, want %s/%s", i, entries[i].EventType, entries[i].Outcome, w.eventType, w.outcome)
+		}
+	}
+
+	failed := entries[4]
+	if failed.Username != "admin" || failed.IP != "192.0.2.1" || failed.Detail != "invalid_credentials" {
+		t.Errorf("failed login entry = %+v", failed)
+	}
+	login := entries[3]
+	if login.UserID != admin.ID || login.IP != "192.168.1.20" || login.UserAgent != "Firefox" || login.Detail != "password" {
+		t.Errorf("login entry = %+v", login)
+	}
+	if entries[0].UserID != admin.ID || entries[0].Username != "admin" {
+		t.Errorf("logout entry = %+v", entries[0])
+	}
+
+	// Logout also announces the revoked session.
+	if len(pub.events) != 6 || pub.events[0].Topic != TopicLoginFailed || pub.events[1].Topic != TopicLoginSucceeded ||
+		pub.events[4].Topic != TopicSessionRevoked || pub.events[5].Topic != TopicLoggedOut {
+		t.Errorf("events = %+v, want one per audit entry plus the session revocation", pub.events)
+	}
+	if p, ok := pub.events[0].Payload.(*AuditEntry); !ok || p.ID == 0 || p.ID != failed.ID {
+		t.Errorf("published entry = %+v, want the stored ID %d", pub.events[0].Payload, failed.ID)
+	}
+}
+
+func TestAudit_Filter(t *testing.T) {
+	_, _, svc := testEnv(t)
+	ctx := context.Background()
+	if _, err := svc.Setup(ctx, "admin", "admin@example.com", "securepassword"); err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	for range 3 {
+		_, _ = svc.Login(ctx, "admin", "wrong")
+	}
+	if _, err := svc.Login(ctx, "admin", "securepassword"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	entries, total, err := svc.ListAuditEntries(ctx, AuditFilter{Outcome: AuditFailure, Limit: 2})
+	if err != nil {
+		t.Fatalf("ListAuditEntries: %v", err)
+	}
+	if total != 3 || len(entries) != 2 {
+		t.Errorf("entries = %d (total %d), want 2 of 3", len(entries), total)
+	}
+	entries, _, _ = svc.ListAuditEntries(ctx, AuditFilter{EventType: AuditLogout})
+	if len(entries) != 0 {
+		t.Errorf("logout entries = %d, want 0", len(entries))
+	}
+}
+
+func TestHandleListAudit(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+	doRequest(mux, http.MethodPost, "/api/v1/auth/setup", map[string]string{
+		"username": "admin",
+		"email":    "admin@example.com",
+		"password": "securepassword",
+	})
+	doRequest(mux, http.MethodPost, "/api/v1/auth/login", map[string]string{"username": "admin", "password": "wrong"})
+
+	w := doRequest(mux, http.MethodGet, "/api/v1/auth/audit", nil)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("unauthenticated status = %d, want 401", w.Code)
+	}
+
+	w = doAuthRequest(mux, http.MethodGet, "/api/v1/auth/audit?outcome=failure", "token", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get(pagination.TotalCountHeader); got != "1" {
+		t.Errorf("%s = %q, want 1", pagination.TotalCountHeader, got)
+	}
+	var entries []AuditEntry
+	if err := json.NewDecoder(w.Body).Decode(&entries); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Username != "admin" {
+		t.Errorf("entries = %+v", entries)
+	}
+
+	w = doAuthRequest(mux, http.MethodGet, "/api/v1/auth/audit?since=yesterday", "token", nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bad since status = %d, want 400", w.Code)
+	}
+}
+
+func TestHandleListAudit_NonAdminForbidden(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/audit", http.NoBody)
+	claims := &Claims{UserID: "viewer-1", Username: "viewer", Role: string(RoleViewer)}
+	req = req.WithContext(context.WithValue(req.Context(), authUserKey{}, claims))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want 403", w.Code)
+	}
+}
@@ -7,9 +7,12 @@ import (
 )
 
 // DemoAuthMiddleware skips JWT validation and injects synthetic viewer claims.
-// This allows all API endpoints to work without login in demo mode.
+// This allows all API endpoints to work without login in demo mode. The
+// built-in viewer role's permissions are enforced on every request.
 func DemoAuthMiddleware() func(http.Handler) http.Handler {
+	authz := RBACMiddleware(staticRoles(DefaultRoleDefinitions()))
 	return func(next http.Handler) http.Handler {
+		next = authz(next)
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Only inject claims for API paths (same logic as AuthMiddleware).
 			if !strings.HasPrefix(r.URL.Path, "/api/") {
@@ -20,7 +23,7 @@ func DemoAuthMiddleware() func(http.Handler) http.Handler {
 			demoClaims := &Claims{
 				UserID:   "demo-user",
 				Username: "demo",
-				Role:     "viewer",
+				Role:     string(RoleViewer),
 			}
 			ctx := context.WithValue(r.Context(), authUserKey{}, demoClaims)
 			next.ServeHTTP(w, r.WithContext(ctx))
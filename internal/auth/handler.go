@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 
 	_ "github.com/HerbHall/subnetree/pkg/models" // swagger type reference
 	"github.com/HerbHall/subnetree/internal/version"
@@ -14,6 +15,8 @@ import (
 type Handler struct {
 	service *Service
 	logger  *zap.Logger
+	oidc    oidcAuthenticator
+	oidcCfg OIDCConfig
 }
 
 // NewHandler creates an auth Handler.
@@ -29,6 +32,10 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("POST /api/v1/auth/logout", h.handleLogout)
 	mux.HandleFunc("POST /api/v1/auth/setup", h.handleSetup)
 	mux.HandleFunc("GET /api/v1/auth/setup/status", h.handleSetupStatus)
+	mux.HandleFunc("POST /api/v1/auth/reset-password", h.handleResetPassword)
+
+	// Password change for the authenticated user.
+	mux.HandleFunc("POST /api/v1/auth/change-password", h.handleChangePassword)
 
 	// MFA endpoints (verify/recovery are public since the user only has an MFA token).
 	mux.HandleFunc("POST /api/v1/auth/mfa/verify", h.handleMFAVerify)
@@ -37,6 +44,42 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("POST /api/v1/auth/mfa/setup", h.handleMFASetup)
 	mux.HandleFunc("POST /api/v1/auth/mfa/verify-setup", h.handleMFAVerifySetup)
 	mux.HandleFunc("POST /api/v1/auth/mfa/disable", h.handleMFADisable)
+	mux.HandleFunc("POST /api/v1/auth/totp/recovery-codes", h.handleRegenerateRecoveryCodes)
+
+	// Passkeys: login is public (the assertion authenticates the user);
+	// registration and management require authentication.
+	mux.HandleFunc("GET /api/v1/auth/webauthn/status", h.handleWebAuthnStatus)
+	mux.HandleFunc("POST /api/v1/auth/webauthn/login/begin", h.handlePasskeyLoginBegin)
+	mux.HandleFunc("POST /api/v1/auth/webauthn/login/finish", h.handlePasskeyLoginFinish)
+	mux.HandleFunc("POST /api/v1/auth/webauthn/register/begin", h.handlePasskeyRegisterBegin)
+	mux.HandleFunc("POST /api/v1/auth/webauthn/register/finish", h.handlePasskeyRegisterFinish)
+	mux.HandleFunc("GET /api/v1/auth/webauthn/credentials", h.handleListPasskeys)
+	mux.HandleFunc("PUT /api/v1/auth/webauthn/credentials/{id}", h.handleRenamePasskey)
+	mux.HandleFunc("DELETE /api/v1/auth/webauthn/credentials/{id}", h.handleDeletePasskey)
+
+	// API key management (require authentication; API keys cannot manage keys).
+	mux.HandleFunc("POST /api/v1/auth/api-keys", h.handleCreateAPIKey)
+	mux.HandleFunc("GET /api/v1/auth/api-keys", h.handleListAPIKeys)
+	mux.HandleFunc("DELETE /api/v1/auth/api-keys/{id}", h.handleRevokeAPIKey)
+
+	// Session management (require authentication).
+	mux.HandleFunc("GET /api/v1/auth/sessions", h.handleListSessions)
+	mux.HandleFunc("DELETE /api/v1/auth/sessions", h.handleRevokeOtherSessions)
+	mux.HandleFunc("DELETE /api/v1/auth/sessions/{id}", h.handleRevokeSession)
+
+	// Authentication audit log (admin only).
+	mux.HandleFunc("GET /api/v1/auth/audit", h.handleListAudit)
+
+	// Role management (list requires authentication; changes require admin).
+	mux.HandleFunc("GET /api/v1/auth/roles", h.handleListRoles)
+	mux.HandleFunc("POST /api/v1/auth/roles", h.handleCreateRole)
+	mux.HandleFunc("PUT /api/v1/auth/roles/{name}", h.handleUpdateRole)
+	mux.HandleFunc("DELETE /api/v1/auth/roles/{name}", h.handleDeleteRole)
+
+	// OIDC authorization-code login (public; the IdP authenticates the user).
+	mux.HandleFunc("GET /api/v1/auth/oidc/status", h.handleOIDCStatus)
+	mux.HandleFunc("GET /api/v1/auth/oidc/login", h.handleOIDCLogin)
+	mux.HandleFunc("GET /api/v1/auth/oidc/callback", h.handleOIDCCallback)
 
 	// Admin-only user management endpoints (auth enforced by middleware,
 	// role checked in handlers).
@@ -44,11 +87,17 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/v1/users/{id}", h.handleGetUser)
 	mux.HandleFunc("PUT /api/v1/users/{id}", h.handleUpdateUser)
 	mux.HandleFunc("DELETE /api/v1/users/{id}", h.handleDeleteUser)
+	mux.HandleFunc("POST /api/v1/users/{id}/reset-password", h.handleCreatePasswordReset)
 }
 
-// Middleware returns the JWT authentication middleware.
+// Middleware returns the JWT authentication middleware followed by
+// role-based permission checks.
 func (h *Handler) Middleware() func(http.Handler) http.Handler {
-	return AuthMiddleware(h.service.Tokens())
+	authn := AuthMiddlewareWithBackend(h.service.Tokens(), h.service)
+	authz := RBACMiddleware(h.service)
+	return func(next http.Handler) http.Handler {
+		return authn(authz(next))
+	}
 }
 
 // handleLogin authenticates a user and returns a token pair.
@@ -62,6 +111,7 @@ func (h *Handler) Middleware() func(http.Handler) http.Handler {
 //	@Success		200		{object}	TokenPair
 //	@Failure		400		{object}	models.APIProblem
 //	@Failure		401		{object}	models.APIProblem
+//	@Failure		429		{object}	models.APIProblem
 //	@Failure		500		{object}	models.APIProblem
 //	@Router			/auth/login [post]
 func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
@@ -78,14 +128,16 @@ func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := h.service.Login(r.Context(), req.Username, req.Password)
+	result, err := h.service.LoginFrom(withClientInfo(r), req.Username, req.Password, loginClientIP(r))
 	if err != nil {
 		if errors.Is(err, ErrInvalidCredentials) || errors.Is(err, ErrUserDisabled) {
 			writeAuthError(w, http.StatusUnauthorized, "invalid username or password")
 			return
 		}
-		if errors.Is(err, ErrAccountLocked) {
-			writeAuthError(w, http.StatusUnauthorized, "account is locked")
+		var lockErr *LockoutError
+		if errors.As(err, &lockErr) {
+			w.Header().Set("Retry-After", strconv.Itoa(lockErr.RetryAfter()))
+			writeAuthError(w, http.StatusTooManyRequests, "too many failed login attempts; try again later")
 			return
 		}
 		h.logger.Error("login error", zap.Error(err))
@@ -97,6 +149,7 @@ func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, http.StatusOK, MFAChallengeResponse{
 			MFARequired: true,
 			MFAToken:    result.MFAToken,
+			MFAMethods:  result.MFAMethods,
 		})
 		return
 	}
@@ -129,7 +182,7 @@ func (h *Handler) handleRefresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pair, err := h.service.Refresh(r.Context(), req.RefreshToken)
+	pair, err := h.service.Refresh(withClientInfo(r), req.RefreshToken)
 	if err != nil {
 		if errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrUserDisabled) {
 			writeAuthError(w, http.StatusUnauthorized, "invalid or expired refresh token")
@@ -168,7 +221,7 @@ func (h *Handler) handleLogout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.Logout(r.Context(), req.RefreshToken); err != nil {
+	if err := h.service.Logout(withClientInfo(r), req.RefreshToken); err != nil {
 		h.logger.Error("logout error", zap.Error(err))
 		writeAuthError(w, http.StatusInternalServerError, "logout failed")
 		return
@@ -331,8 +384,8 @@ func (h *Handler) handleUpdateUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	role := Role(req.Role)
-	if !ValidRoles[role] {
-		writeAuthError(w, http.StatusBadRequest, "invalid role: must be admin, operator, or viewer")
+	if _, err := h.service.GetRole(r.Context(), role); err != nil {
+		writeAuthError(w, http.StatusBadRequest, "invalid role: must be a built-in or custom role name")
 		return
 	}
 
@@ -408,7 +461,7 @@ func (h *Handler) handleMFAVerify(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pair, err := h.service.CompleteMFALogin(r.Context(), req.MFAToken, req.TOTPCode)
+	pair, err := h.service.CompleteMFALogin(withClientInfo(r), req.MFAToken, req.TOTPCode)
 	if err != nil {
 		if errors.Is(err, ErrInvalidMFACode) {
 			writeAuthError(w, http.StatusUnauthorized, "invalid or expired MFA code")
@@ -449,7 +502,7 @@ func (h *Handler) handleMFAVerifyRecovery(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	pair, err := h.service.CompleteMFAWithRecovery(r.Context(), req.MFAToken, req.RecoveryCode)
+	pair, err := h.service.CompleteMFAWithRecovery(withClientInfo(r), req.MFAToken, req.RecoveryCode)
 	if err != nil {
 		if errors.Is(err, ErrInvalidMFACode) {
 			writeAuthError(w, http.StatusUnauthorized, "invalid or expired recovery code")
@@ -627,3 +680,52 @@ func writeAuthError(w http.ResponseWriter, status int, detail string) {
 		"detail": detail,
 	})
 }
+
+// handleRegenerateRecoveryCodes replaces the user's TOTP recovery codes.
+//
+//	@Summary		Regenerate recovery codes
+//	@Description	Replace all TOTP recovery codes after verifying a current TOTP code. The new codes are returned only once.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		RecoveryCodesRequest	true	"Current TOTP code"
+//	@Success		200		{object}	RecoveryCodesResponse
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		401		{object}	models.APIProblem
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/auth/totp/recovery-codes [post]
+func (h *Handler) handleRegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	claims := UserFromContext(r.Context())
+	if claims == nil {
+		writeAuthError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	var req RecoveryCodesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAuthError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.TOTPCode == "" {
+		writeAuthError(w, http.StatusBadRequest, "totp_code is required")
+		return
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodes(r.Context(), claims.UserID, req.TOTPCode)
+	if err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			writeAuthError(w, http.StatusUnauthorized, "invalid TOTP code")
+			return
+		}
+		if errors.Is(err, ErrMFANotEnabled) {
+			writeAuthError(w, http.StatusBadRequest, "MFA is not enabled")
+			return
+		}
+		h.logger.Error("recovery code regeneration error", zap.Error(err))
+		writeAuthError(w, http.StatusInternalServerError, "failed to regenerate recovery codes")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
@@ -0,0 +1,348 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// AuthProviderLDAP is the auth_provider value for users created via LDAP.
+const AuthProviderLDAP = "ldap"
+
+// ErrLDAPUnavailable is returned when the directory cannot be reached or the
+// service account bind fails. Logins then fall back to local accounts.
+var ErrLDAPUnavailable = errors.New("ldap directory unavailable")
+
+// LDAPConfig holds LDAP / Active Directory login settings.
+type LDAPConfig struct {
+	Enabled            bool          `mapstructure:"enabled"`
+	URL                string        `mapstructure:"url"`       // ldap://dc.example.com:389 or ldaps://dc.example.com:636
+	StartTLS           bool          `mapstructure:"start_tls"` // Upgrade ldap:// connections with StartTLS
+	InsecureSkipVerify bool          `mapstructure:"insecure_skip_verify"`
+	Timeout            time.Duration `mapstructure:"timeout"`
+	BaseDN             string        `mapstructure:"base_dn"` // Subtree searched for users
+	BindDN             string        `mapstructure:"bind_dn"` // Service account used to search; empty binds anonymously
+	// BindPasswordCredentialID names a vault credential whose password
+	// authenticates BindDN.
+	BindPasswordCredentialID string `mapstructure:"bind_password_credential_id"` //nolint:gosec // G101: vault credential ID, not a credential
+	// UserFilter finds the user entry; {username} is replaced with the
+	// escaped login name.
+	UserFilter     string `mapstructure:"user_filter"`
+	EmailAttribute string `mapstructure:"email_attribute"`
+	GroupAttribute string `mapstructure:"group_attribute"`
+	// GroupRoles maps directory groups to roles, checked in order. A group
+	// matches by full DN or by its CN.
+	GroupRoles []LDAPGroupRole `mapstructure:"group_roles"`
+	// DefaultRole applies when no group matches. Empty rejects such users.
+	DefaultRole Role `mapstructure:"default_role"`
+}
+
+// LDAPGroupRole maps one directory group to a SubNetree role.
+type LDAPGroupRole struct {
+	Group string `mapstructure:"group"`
+	Role  Role   `mapstructure:"role"`
+}
+
+// DefaultLDAPConfig returns LDAP defaults suited to Active Directory.
+// LDAP is disabled until configured.
+func DefaultLDAPConfig() LDAPConfig {
+	return LDAPConfig{
+		Timeout:        10 * time.Second,
+		UserFilter:     "(&(objectClass=user)(sAMAccountName={username}))",
+		EmailAttribute: "mail",
+		GroupAttribute: "memberOf",
+	}
+}
+
+// roleFor returns the role for a user in groups, or "" if none applies.
+func (c *LDAPConfig) roleFor(groups []string) Role {
+	for _, gr := range c.GroupRoles {
+		for _, g := range groups {
+			if strings.EqualFold(g, gr.Group) || strings.EqualFold(groupCN(g), gr.Group) {
+				return gr.Role
+			}
+		}
+	}
+	return c.DefaultRole
+}
+
+// groupCN returns the value of the first RDN of a group DN.
+func groupCN(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil || len(parsed.RDNs) == 0 || len(parsed.RDNs[0].Attributes) == 0 {
+		return ""
+	}
+	return parsed.RDNs[0].Attributes[0].Value
+}
+
+// LDAPIdentity holds the directory attributes of an authenticated user.
+type LDAPIdentity struct {
+	DN     string
+	Email  string
+	Groups []string
+}
+
+// ldapDirectory verifies a username and password against a directory.
+// It returns ErrInvalidCredentials when the user is unknown or the password
+// is wrong, and ErrLDAPUnavailable when the directory cannot be used.
+type ldapDirectory interface {
+	Authenticate(ctx context.Context, username, password string) (*LDAPIdentity, error)
+}
+
+// CredentialDecrypter retrieves decrypted credential data from the vault.
+// Implemented by the vault module; wired via composition root adapter.
+type CredentialDecrypter interface {
+	DecryptCredential(ctx context.Context, id string) (map[string]any, error)
+}
+
+// LDAPProvider authenticates users with a search-then-bind against an LDAP
+// directory.
+type LDAPProvider struct {
+	cfg LDAPConfig
+
+	mu        sync.RWMutex
+	decrypter CredentialDecrypter
+}
+
+// NewLDAPProvider validates cfg and returns a provider. No connection is
+// made until the first login.
+func NewLDAPProvider(cfg LDAPConfig) (*LDAPProvider, error) {
+	if cfg.URL == "" || cfg.BaseDN == "" {
+		return nil, errors.New("ldap url and base_dn are required")
+	}
+	def := DefaultLDAPConfig()
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	if cfg.UserFilter == "" {
+		cfg.UserFilter = def.UserFilter
+	}
+	if !strings.Contains(cfg.UserFilter, "{username}") {
+		return nil, errors.New("ldap user_filter must contain {username}")
+	}
+	if cfg.EmailAttribute == "" {
+		cfg.EmailAttribute = def.EmailAttribute
+	}
+	if cfg.GroupAttribute == "" {
+		cfg.GroupAttribute = def.GroupAttribute
+	}
+	return &LDAPProvider{cfg: cfg}, nil
+}
+
+// SetCredentialDecrypter injects the vault decrypter used to resolve the
+// bind password from auth.ldap.bind_password_credential_id. Called from the
+// composition root.
+func (p *LDAPProvider) SetCredentialDecrypter(d CredentialDecrypter) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.decrypter = d
+}
+
+// Authenticate searches for username with the service account, then binds
+// as the found entry with password.
+func (p *LDAPProvider) Authenticate(ctx context.Context, username, password string) (*LDAPIdentity, error) {
+	// An empty password would be an unauthenticated bind, which many
+	// directories accept for any DN.
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := p.dial()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrLDAPUnavailable, err)
+	}
+	defer conn.Close()
+
+	bindPassword, err := p.bindPassword(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrLDAPUnavailable, err)
+	}
+	if p.cfg.BindDN != "" {
+		err = conn.Bind(p.cfg.BindDN, bindPassword)
+	} else {
+		err = conn.UnauthenticatedBind("")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: service bind: %w", ErrLDAPUnavailable, err)
+	}
+
+	filter := strings.ReplaceAll(p.cfg.UserFilter, "{username}", ldap.EscapeFilter(username))
+	res, err := conn.Search(ldap.NewSearchRequest(
+		p.cfg.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, int(p.cfg.Timeout.Seconds()), false,
+		filter, []string{p.cfg.EmailAttribute, p.cfg.GroupAttribute}, nil,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("%w: search: %w", ErrLDAPUnavailable, err)
+	}
+	if len(res.Entries) != 1 {
+		// Unknown user, or an ambiguous filter that must not pick one at random.
+		return nil, ErrInvalidCredentials
+	}
+	entry := res.Entries[0]
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("%w: user bind: %w", ErrLDAPUnavailable, err)
+	}
+
+	return &LDAPIdentity{
+		DN:     entry.DN,
+		Email:  entry.GetAttributeValue(p.cfg.EmailAttribute),
+		Groups: entry.GetAttributeValues(p.cfg.GroupAttribute),
+	}, nil
+}
+
+func (p *LDAPProvider) dial() (*ldap.Conn, error) {
+	tlsCfg := &tls.Config{
+		InsecureSkipVerify: p.cfg.InsecureSkipVerify, //nolint:gosec // G402: opt-in for directories with self-signed certificates
+		MinVersion:         tls.VersionTLS12,
+	}
+	if u, err := url.Parse(p.cfg.URL); err == nil {
+		tlsCfg.ServerName = u.Hostname()
+	}
+	conn, err := ldap.DialURL(p.cfg.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: p.cfg.Timeout}),
+		ldap.DialWithTLSConfig(tlsCfg),
+	)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(p.cfg.Timeout)
+	if p.cfg.StartTLS && strings.HasPrefix(p.cfg.URL, "ldap://") {
+		if err := conn.StartTLS(tlsCfg); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("starttls: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// bindPassword resolves the service account password from the vault.
+func (p *LDAPProvider) bindPassword(ctx context.Context) (string, error) {
+	if p.cfg.BindDN == "" || p.cfg.BindPasswordCredentialID == "" {
+		return "", nil
+	}
+	p.mu.RLock()
+	decrypter := p.decrypter
+	p.mu.RUnlock()
+	if decrypter == nil {
+		return "", errors.New("vault is not available to resolve the bind password")
+	}
+	data, err := decrypter.DecryptCredential(ctx, p.cfg.BindPasswordCredentialID)
+	if err != nil {
+		return "", fmt.Errorf("decrypt bind password: %w", err)
+	}
+	password, _ := data["password"].(string)
+	if password == "" {
+		return "", errors.New("bind password credential has no password")
+	}
+	return password, nil
+}
+
+// SetLDAP enables LDAP login. Called from the composition root when
+// auth.ldap is enabled.
+func (s *Service) SetLDAP(dir ldapDirectory, cfg LDAPConfig) {
+	s.ldap = dir
+	s.ldapCfg = cfg
+}
+
+// authenticateLDAP verifies the credentials against the directory and
+// returns the matching local user, creating it on first login and syncing
+// its role and email from the directory on every login.
+func (s *Service) authenticateLDAP(ctx context.Context, username, password string) (*User, error) {
+	identity, err := s.ldap.Authenticate(ctx, username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	role := s.ldapCfg.roleFor(identity.Groups)
+	if role == "" {
+		s.logger.Warn("ldap user has no group mapped to a role",
+			zap.String("username", username),
+			zap.Strings("groups", identity.Groups),
+		)
+		return nil, ErrInvalidCredentials
+	}
+	if _, err := s.GetRole(ctx, role); err != nil {
+		s.logger.Warn("ldap group mapping names an unknown role",
+			zap.String("username", username),
+			zap.String("role", string(role)),
+		)
+		return nil, ErrInvalidCredentials
+	}
+
+	user, err := s.store.GetUserByUsername(ctx, username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return s.createLDAPUser(ctx, username, identity, role)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lookup user: %w", err)
+	}
+	if user.AuthProvider != AuthProviderLDAP {
+		// Never link a directory login to a local or OIDC account of the
+		// same name; the caller falls back to local authentication.
+		return nil, ErrUserExists
+	}
+
+	email := identity.Email
+	if email == "" {
+		email = user.Email
+	}
+	if user.Role != role || user.Email != email {
+		user.Role, user.Email = role, email
+		if err := s.store.UpdateUser(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// createLDAPUser provisions a local account for a first-time LDAP login.
+func (s *Service) createLDAPUser(ctx context.Context, username string, identity *LDAPIdentity, role Role) (*User, error) {
+	needsSetup, err := s.NeedsSetup(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if needsSetup {
+		return nil, ErrSetupRequired
+	}
+
+	email := identity.Email
+	if email == "" {
+		// Email is unique and required; synthesize a non-routable one.
+		email = username + "@ldap.invalid"
+	}
+	user := &User{
+		ID:           uuid.New().String(),
+		Username:     username,
+		Email:        email,
+		Role:         role,
+		AuthProvider: AuthProviderLDAP,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := s.store.CreateUser(ctx, user); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return nil, ErrUserExists
+		}
+		return nil, err
+	}
+	s.logger.Info("ldap user created",
+		zap.String("username", username),
+		zap.String("role", string(role)),
+	)
+	return user, nil
+}
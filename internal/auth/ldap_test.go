@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// fakeLDAP is an ldapDirectory with a fixed set of accounts.
+type fakeLDAP struct {
+	users       map[string]string // username -> password
+	identity    LDAPIdentity
+	unreachable bool
+}
+
+func (f *fakeLDAP) Authenticate(_ context.Context, username, password string) (*LDAPIdentity, error) {
+	if f.unreachable {
+		return nil, fmt.Errorf("%w: dial: connection refused", ErrLDAPUnavailable)
+	}
+	if pw, ok := f.users[username]; !ok || pw != password {
+		return nil, ErrInvalidCredentials
+	}
+	id := f.identity
+	return &id, nil
+}
+
+func ldapEnv(t *testing.T) (*UserStore, *Service, *fakeLDAP) {
+	t.Helper()
+	userStore, _, svc := testEnv(t)
+	if _, err := svc.Setup(context.Background(), "admin", "admin@example.com", "securepassword"); err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	dir := &fakeLDAP{
+		users: map[string]string{"alice": "ad-password"},
+		identity: LDAPIdentity{
+			DN:     "CN=Alice,OU=Users,DC=example,DC=com",
+			Email:  "alice@example.com",
+			Groups: []string{"CN=IT Staff,OU=Groups,DC=example,DC=com"},
+		},
+	}
+	cfg := DefaultLDAPConfig()
+	cfg.GroupRoles = []LDAPGroupRole{
+		{Group: "SubNetree Admins", Role: RoleAdmin},
+		{Group: "cn=it staff,ou=groups,dc=example,dc=com", Role: RoleOperator},
+	}
+	svc.SetLDAP(dir, cfg)
+	return userStore, svc, dir
+}
+
+func TestLDAPConfig_RoleFor(t *testing.T) {
+	cfg := LDAPConfig{
+		GroupRoles: []LDAPGroupRole{
+			{Group: "SubNetree Admins", Role: RoleAdmin},
+			{Group: "CN=Helpdesk,DC=example,DC=com", Role: RoleOperator},
+		},
+	}
+	tests := []struct {
+		name   string
+		groups []string
+		want   Role
+	}{
+		{"match by CN", []string{"CN=SubNetree Admins,OU=Groups,DC=example,DC=com"}, RoleAdmin},
+		{"match by DN", []string{"cn=helpdesk,dc=example,dc=com"}, RoleOperator},
+		{"first mapping wins", []string{"CN=Helpdesk,DC=example,DC=com", "CN=SubNetree Admins,DC=example,DC=com"}, RoleAdmin},
+		{"no match", []string{"CN=Sales,DC=example,DC=com"}, ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := cfg.roleFor(tc.groups); got != tc.want {
+				t.Errorf("roleFor = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	cfg.DefaultRole = RoleViewer
+	if got := cfg.roleFor(nil); got != RoleViewer {
+		t.Errorf("roleFor(nil) = %q, want default viewer", got)
+	}
+}
+
+func TestNewLDAPProvider_Validation(t *testing.T) {
+	if _, err := NewLDAPProvider(LDAPConfig{BaseDN: "DC=example,DC=com"}); err == nil {
+		t.Error("expected error without url")
+	}
+	if _, err := NewLDAPProvider(LDAPConfig{URL: "ldap://dc", BaseDN: "DC=example,DC=com", UserFilter: "(uid=alice)"}); err == nil {
+		t.Error("expected error for a filter without {username}")
+	}
+	if _, err := NewLDAPProvider(LDAPConfig{URL: "ldap://dc", BaseDN: "DC=example,DC=com"}); err != nil {
+		t.Errorf("NewLDAPProvider: %v", err)
+	}
+}
+
+func TestLoginLDAP_CreatesUserWithMappedRole(t *testing.T) {
+	userStore, svc, dir := ldapEnv(t)
+	ctx := context.Background()
+
+	result, err := svc.Login(ctx, "alice", "ad-password")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if result.Pair == nil {
+		t.Fatal("expected a token pair")
+	}
+	user, err := userStore.GetUserByUsername(ctx, "alice")
+	if err != nil {
+		t.Fatalf("GetUserByUsername: %v", err)
+	}
+	if user.AuthProvider != AuthProviderLDAP || user.Role != RoleOperator || user.Email != "alice@example.com" {
+		t.Errorf("user = %+v, want ldap operator alice@example.com", user)
+	}
+
+	// Group changes in the directory are applied on the next login.
+	dir.identity.Groups = []string{"CN=SubNetree Admins,OU=Groups,DC=example,DC=com"}
+	if _, err := svc.Login(ctx, "alice", "ad-password"); err != nil {
+		t.Fatalf("second Login: %v", err)
+	}
+	user, _ = userStore.GetUserByUsername(ctx, "alice")
+	if user.Role != RoleAdmin {
+		t.Errorf("Role = %q after group change, want admin", user.Role)
+	}
+}
+
+func TestLoginLDAP_UnmappedGroupRejected(t *testing.T) {
+	_, svc, dir := ldapEnv(t)
+	dir.identity.Groups = []string{"CN=Sales,DC=example,DC=com"}
+
+	if _, err := svc.Login(context.Background(), "alice", "ad-password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("err = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestLoginLDAP_LocalAccountsStillWork(t *testing.T) {
+	_, svc, dir := ldapEnv(t)
+	ctx := context.Background()
+
+	// The setup admin is not in the directory.
+	if _, err := svc.Login(ctx, "admin", "securepassword"); err != nil {
+		t.Errorf("local login with directory up: %v", err)
+	}
+
+	dir.unreachable = true
+	if _, err := svc.Login(ctx, "admin", "securepassword"); err != nil {
+		t.Errorf("local login with directory down: %v", err)
+	}
+	if _, err := svc.Login(ctx, "alice", "ad-password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("directory login while unreachable err = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestLoginLDAP_DoesNotTakeOverLocalAccount(t *testing.T) {
+	_, svc, dir := ldapEnv(t)
+	ctx := context.Background()
+
+	// A directory account named like the local admin must not log in as it.
+	dir.users["admin"] = "directory-password"
+	if _, err := svc.Login(ctx, "admin", "directory-password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("err = %v, want ErrInvalidCredentials", err)
+	}
+	if _, err := svc.Login(ctx, "admin", "securepassword"); err != nil {
+		t.Errorf("local password: %v", err)
+	}
+}
+
+func TestLoginLDAP_WrongPasswordCountsTowardLockout(t *testing.T) {
+	userStore, svc, _ := ldapEnv(t)
+	ctx := context.Background()
+
+	if _, err := svc.Login(ctx, "alice", "ad-password"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	for range DefaultMaxFailedAttempts {
+		_, _ = svc.Login(ctx, "alice", "wrong")
+	}
+	user, _ := userStore.GetUserByUsername(ctx, "alice")
+	if user.LockedUntil == nil {
+		t.Fatal("expected account to be locked")
+	}
+	var lockErr *LockoutError
+	if _, err := svc.Login(ctx, "alice", "ad-password"); !errors.As(err, &lockErr) {
+		t.Errorf("err = %v, want *LockoutError", err)
+	}
+}
@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/plugin"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultMaxFailedAttemptsPerIP is the number of failed logins from one
+	// client address, across all usernames, before that address is locked out.
+	DefaultMaxFailedAttemptsPerIP = 20
+	// MaxLockoutDuration caps the exponential lockout backoff.
+	MaxLockoutDuration = 24 * time.Hour
+	// IPFailureWindow is how long an address must go without a failed login
+	// or an active lockout before its failure count starts again from zero,
+	// so occasional typos from a shared address never add up to a lockout.
+	IPFailureWindow = time.Hour
+)
+
+// TopicAccountLocked is published when a username or client address is locked out.
+const TopicAccountLocked = "auth.account.locked"
+
+// AccountLockedEvent is the payload for TopicAccountLocked.
+type AccountLockedEvent struct {
+	Username    string    `json:"username,omitempty"`
+	UserID      string    `json:"user_id,omitempty"`
+	IP          string    `json:"ip,omitempty"`
+	Attempts    int       `json:"attempts"`
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+// LockoutError reports a temporary lockout and when it ends.
+// It matches ErrAccountLocked with errors.Is.
+type LockoutError struct {
+	Until time.Time
+}
+
+func (e *LockoutError) Error() string {
+	return fmt.Sprintf("%s until %s", ErrAccountLocked, e.Until.UTC().Format(time.RFC3339))
+}
+
+// Is reports whether target is ErrAccountLocked.
+func (e *LockoutError) Is(target error) bool {
+	return target == ErrAccountLocked
+}
+
+// RetryAfter returns the whole seconds until the lockout ends (at least 1).
+func (e *LockoutError) RetryAfter() int {
+	secs := int(time.Until(e.Until).Seconds()) + 1
+	if secs < 1 {
+		secs = 1
+	}
+	return secs
+}
+
+// SetEventBus sets the publisher used for lockout, audit, and session events.
+// Called from the composition root after the event bus is created.
+func (s *Service) SetEventBus(bus plugin.Publisher) {
+	s.bus = bus
+}
+
+// lockoutDuration returns how long to lock after the given number of
+// consecutive failures, or zero if no lockout applies. Every threshold
+// failures locks again, doubling the previous duration up to MaxLockoutDuration.
+func lockoutDuration(attempts, threshold int) time.Duration {
+	if threshold <= 0 || attempts < threshold || attempts%threshold != 0 {
+		return 0
+	}
+	d := DefaultLockoutDuration
+	for i := attempts/threshold - 1; i > 0 && d < MaxLockoutDuration; i-- {
+		d *= 2
+	}
+	return min(d, MaxLockoutDuration)
+}
+
+// checkIPLockout returns a LockoutError if the client address is locked.
+func (s *Service) checkIPLockout(ctx context.Context, ip string) error {
+	if ip == "" {
+		return nil
+	}
+	_, lockedUntil, err := s.store.GetIPLoginFailures(ctx, ip)
+	if err != nil {
+		return err
+	}
+	if lockedUntil != nil && lockedUntil.After(time.Now()) {
+		return &LockoutError{Until: *lockedUntil}
+	}
+	return nil
+}
+
+// userLockApplies reports whether a per-user lockout should block a login
+// from ip. The lock only applies to addresses that have failed logins
+// themselves (or to unknown addresses), so guessing passwords for a known
+// username cannot lock its owner out of a client that never failed.
+func (s *Service) userLockApplies(ctx context.Context, ip string) bool {
+	if ip == "" {
+		return true
+	}
+	attempts, _, err := s.store.GetIPLoginFailures(ctx, ip)
+	return err != nil || attempts > 0
+}
+
+// recordIPFailure counts a failed login from ip and locks the address once
+// it crosses the per-IP threshold.
+func (s *Service) recordIPFailure(ctx context.Context, ip string) {
+	if ip == "" {
+		return
+	}
+	attempts, err := s.store.RecordIPLoginFailure(ctx, ip, time.Now().Add(-IPFailureWindow))
+	if err != nil {
+		s.logger.Error("failed to record failed login for address", zap.Error(err))
+		return
+	}
+	d := lockoutDuration(attempts, DefaultMaxFailedAttemptsPerIP)
+	if d == 0 {
+		return
+	}
+	lockedUntil := time.Now().Add(d)
+	if err := s.store.LockIP(ctx, ip, lockedUntil); err != nil {
+		s.logger.Error("failed to lock address", zap.Error(err))
+		return
+	}
+	s.logger.Warn("client address locked due to failed login attempts",
+		zap.String("ip", ip),
+		zap.Int("attempts", attempts),
+		zap.Time("locked_until", lockedUntil),
+	)
+	s.publishLockout(ctx, &AccountLockedEvent{IP: ip, Attempts: attempts, LockedUntil: lockedUntil})
+}
+
+func (s *Service) publishLockout(ctx context.Context, ev *AccountLockedEvent) {
+	if s.bus == nil {
+		return
+	}
+	if err := s.bus.Publish(ctx, plugin.Event{
+		Topic:     TopicAccountLocked,
+		Source:    "auth",
+		Timestamp: time.Now(),
+		Payload:   ev,
+	}); err != nil {
+		s.logger.Warn("failed to publish lockout event", zap.Error(err))
+	}
+}
+
+// loginClientIP returns the client address used for login throttling.
+// X-Forwarded-For is deliberately ignored: it is client-controlled, so
+// trusting it would let an attacker rotate addresses or lock out others.
+func loginClientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+// GetIPLoginFailures returns the failed login count and lockout for an address.
+func (s *UserStore) GetIPLoginFailures(ctx context.Context, ip string) (attempts int, lockedUntil *time.Time, err error) {
+	var locked sql.NullTime
+	err = s.db.QueryRowContext(ctx,
+		`SELECT attempts, locked_until FROM auth_login_failures WHERE ip = ?`, ip).Scan(&attempts, &locked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil, nil
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("get login failures: %w", err)
+	}
+	if locked.Valid {
+		lockedUntil = &locked.Time
+	}
+	return attempts, lockedUntil, nil
+}
+
+// RecordIPLoginFailure increments the failed login count for an address.
+// When the address has had no failure and no lockout since quietSince, the
+// count restarts at one.
+func (s *UserStore) RecordIPLoginFailure(ctx context.Context, ip string, quietSince time.Time) (attempts int, err error) {
+	err = s.db.QueryRowContext(ctx, `
+		INSERT INTO auth_login_failures (ip, attempts, updated_at) VALUES (?1, 1, ?2)
+		ON CONFLICT(ip) DO UPDATE SET
+			attempts = CASE WHEN updated_at < ?3 AND (locked_until IS NULL OR locked_until < ?3)
+				THEN 1 ELSE attempts + 1 END,
+			locked_until = CASE WHEN updated_at < ?3 AND (locked_until IS NULL OR locked_until < ?3)
+				THEN NULL ELSE locked_until END,
+			updated_at = excluded.updated_at
+		RETURNING attempts`,
+		ip, time.Now().UTC(), quietSince.UTC()).Scan(&attempts)
+	if err != nil {
+		return 0, fmt.Errorf("record login failure: %w", err)
+	}
+	return attempts, nil
+}
+
+// LockIP sets the lockout expiry for an address.
+func (s *UserStore) LockIP(ctx context.Context, ip string, lockedUntil time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE auth_login_failures SET locked_until = ? WHERE ip = ?`, lockedUntil.UTC(), ip)
+	return err
+}
+
+// ClearIPLoginFailures resets failed login tracking for an address.
+func (s *UserStore) ClearIPLoginFailures(ctx context.Context, ip string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM auth_login_failures WHERE ip = ?`, ip)
+	return err
+}
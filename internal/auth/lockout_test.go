@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/plugin"
+)
+
+// recordingPublisher captures published events.
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []plugin.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, e plugin.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, e)
+	return nil
+}
+
+func TestLockoutDuration(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{4, 0},
+		{5, DefaultLockoutDuration},
+		{6, 0},
+		{10, 2 * DefaultLockoutDuration},
+		{15, 4 * DefaultLockoutDuration},
+		{500, MaxLockoutDuration},
+	}
+	for _, tc := range tests {
+		if got := lockoutDuration(tc.attempts, 5); got != tc.want {
+			t.Errorf("lockoutDuration(%d) = %v, want %v", tc.attempts, got, tc.want)
+		}
+	}
+}
+
+func TestLogin_LockoutPublishesEvent(t *testing.T) {
+	_, _, svc := testEnv(t)
+	ctx := context.Background()
+	if _, err := svc.Setup(ctx, "admin", "admin@example.com", "securepassword"); err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	pub := &recordingPublisher{}
+	svc.SetEventBus(pub)
+
+	for i := 0; i < DefaultMaxFailedAttempts; i++ {
+		_, _ = svc.LoginFrom(ctx, "admin", "wrong", "192.0.2.1")
+	}
+
+	var locked []plugin.Event
+	for _, e := range pub.events {
+		if e.Topic == TopicAccountLocked {
+			locked = append(locked, e)
+		}
+	}
+	if len(locked) != 1 {
+		t.Fatalf("events = %+v, want one %s event", pub.events, TopicAccountLocked)
+	}
+	ev, ok := locked[0].Payload.(*AccountLockedEvent)
+	if !ok || ev.Username != "admin" || ev.IP != "192.0.2.1" {
+		t.Errorf("payload = %+v", locked[0].Payload)
+	}
+}
+
+func TestLogin_IPLockoutAcrossUsernames(t *testing.T) {
+	_, _, svc := testEnv(t)
+	ctx := context.Background()
+	if _, err := svc.Setup(ctx, "admin", "admin@example.com", "securepassword"); err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+
+	for i := 0; i < DefaultMaxFailedAttemptsPerIP; i++ {
+		_, _ = svc.LoginFrom(ctx, "nobody", "wrong", "192.0.2.9")
+	}
+
+	_, err := svc.LoginFrom(ctx, "admin", "securepassword", "192.0.2.9")
+	var lockErr *LockoutError
+	if !errors.As(err, &lockErr) {
+		t.Fatalf("err = %v, want LockoutError", err)
+	}
+	// Other addresses are unaffected.
+	if _, err := svc.LoginFrom(ctx, "admin", "securepassword", "192.0.2.10"); err != nil {
+		t.Errorf("login from other address: %v", err)
+	}
+}
+
+func TestLogin_IPFailuresDecay(t *testing.T) {
+	userStore, _, svc := testEnv(t)
+	ctx := context.Background()
+	if _, err := svc.Setup(ctx, "admin", "admin@example.com", "securepassword"); err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+
+	for i := 0; i < DefaultMaxFailedAttemptsPerIP-1; i++ {
+		_, _ = svc.LoginFrom(ctx, "nobody", "wrong", "192.0.2.9")
+	}
+	// The address then goes quiet for longer than the window.
+	if _, err := userStore.db.ExecContext(ctx, `UPDATE auth_login_failures SET updated_at = ?`,
+		time.Now().Add(-2*IPFailureWindow).UTC()); err != nil {
+		t.Fatalf("age failures: %v", err)
+	}
+
+	_, _ = svc.LoginFrom(ctx, "nobody", "wrong", "192.0.2.9")
+	attempts, lockedUntil, err := userStore.GetIPLoginFailures(ctx, "192.0.2.9")
+	if err != nil {
+		t.Fatalf("GetIPLoginFailures: %v", err)
+	}
+	if attempts != 1 || lockedUntil != nil {
+		t.Errorf("attempts = %d, locked = %v; want the count to restart at 1", attempts, lockedUntil)
+	}
+}
+
+func TestLogin_UserLockoutScopedToFailingAddress(t *testing.T) {
+	_, _, svc := testEnv(t)
+	ctx := context.Background()
+	if _, err := svc.Setup(ctx, "admin", "admin@example.com", "securepassword"); err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+
+	for i := 0; i < DefaultMaxFailedAttempts; i++ {
+		_, _ = svc.LoginFrom(ctx, "admin", "wrong", "192.0.2.1")
+	}
+
+	if _, err := svc.LoginFrom(ctx, "admin", "securepassword", "192.0.2.1"); !errors.Is(err, ErrAccountLocked) {
+		t.Errorf("failing address err = %v, want ErrAccountLocked", err)
+	}
+	if _, err := svc.LoginFrom(ctx, "admin", "securepassword", "192.0.2.2"); err != nil {
+		t.Errorf("clean address login: %v", err)
+	}
+}
+
+func TestHandleLogin_LockedReturns429(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+	doRequest(mux, "POST", "/api/v1/auth/setup", map[string]string{
+		"username": "admin",
+		"email":    "admin@example.com",
+		"password": "securepassword",
+	})
+
+	for i := 0; i < DefaultMaxFailedAttempts; i++ {
+		doRequest(mux, "POST", "/api/v1/auth/login", map[string]string{"username": "admin", "password": "wrong"})
+	}
+	w := doRequest(mux, "POST", "/api/v1/auth/login", map[string]string{"username": "admin", "password": "securepassword"})
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429; body: %s", w.Code, w.Body.String())
+	}
+	if ra := w.Header().Get("Retry-After"); ra == "" || strings.HasPrefix(ra, "-") {
+		t.Errorf("Retry-After = %q, want positive seconds", ra)
+	}
+}
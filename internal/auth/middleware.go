@@ -9,6 +9,10 @@ import (
 // authUserKey is a context key for the authenticated user.
 type authUserKey struct{}
 
+// authAPIKeyKey is a context key for the API key that authenticated a
+// request, if any.
+type authAPIKeyKey struct{}
+
 // UserFromContext returns the authenticated user from the request context.
 // Returns nil if the request is not authenticated.
 func UserFromContext(ctx context.Context) *Claims {
@@ -20,18 +24,40 @@ func UserFromContext(ctx context.Context) *Claims {
 
 // Public paths that don't require authentication.
 var publicPaths = map[string]bool{
-	"/api/v1/auth/login":              true,
-	"/api/v1/auth/refresh":            true,
-	"/api/v1/auth/logout":             true,
-	"/api/v1/auth/setup":              true,
-	"/api/v1/auth/setup/status":       true,
-	"/api/v1/auth/mfa/verify":         true,
-	"/api/v1/auth/mfa/verify-recovery": true,
+	"/api/v1/auth/login":                 true,
+	"/api/v1/auth/refresh":               true,
+	"/api/v1/auth/logout":                true,
+	"/api/v1/auth/setup":                 true,
+	"/api/v1/auth/setup/status":          true,
+	"/api/v1/auth/reset-password":        true,
+	"/api/v1/auth/mfa/verify":            true,
+	"/api/v1/auth/mfa/verify-recovery":   true,
+	"/api/v1/auth/oidc/status":           true,
+	"/api/v1/auth/oidc/login":            true,
+	"/api/v1/auth/oidc/callback":         true,
+	"/api/v1/auth/webauthn/status":       true,
+	"/api/v1/auth/webauthn/login/begin":  true,
+	"/api/v1/auth/webauthn/login/finish": true,
+}
+
+// CredentialBackend performs the stateful credential checks that a signed
+// JWT alone cannot: API key lookup and session revocation.
+type CredentialBackend interface {
+	AuthenticateAPIKey(ctx context.Context, raw string) (*Claims, *APIKey, error)
+	SessionActive(ctx context.Context, sessionID string) bool
 }
 
 // AuthMiddleware validates JWT access tokens on API routes.
 // Public paths and non-API paths (healthz, readyz, metrics) are skipped.
 func AuthMiddleware(tokens *TokenService) func(http.Handler) http.Handler {
+	return AuthMiddlewareWithBackend(tokens, nil)
+}
+
+// AuthMiddlewareWithBackend is AuthMiddleware that, when backend is non-nil,
+// also accepts API keys ("Bearer sk_...") and rejects access tokens whose
+// session has been revoked. API key requests are limited to the key's
+// scopes and may not reach auth or user management endpoints.
+func AuthMiddlewareWithBackend(tokens *TokenService, backend CredentialBackend) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Skip non-API paths (healthz, readyz, metrics, etc.).
@@ -40,8 +66,9 @@ func AuthMiddleware(tokens *TokenService) func(http.Handler) http.Handler {
 				return
 			}
 
-			// Skip WebSocket paths (auth handled by WS handler via query param).
-			if strings.HasPrefix(r.URL.Path, "/api/v1/ws/") {
+			// Skip WebSocket and event stream paths (auth handled by the
+			// handler via query param).
+			if strings.HasPrefix(r.URL.Path, "/api/v1/ws/") || r.URL.Path == "/api/v1/events/stream" {
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -60,11 +87,32 @@ func AuthMiddleware(tokens *TokenService) func(http.Handler) http.Handler {
 			}
 			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
+			if backend != nil && strings.HasPrefix(tokenString, APIKeyPrefix) {
+				claims, key, err := backend.AuthenticateAPIKey(r.Context(), tokenString)
+				if err != nil {
+					writeAuthError(w, http.StatusUnauthorized, "invalid or revoked api key")
+					return
+				}
+				if strings.HasPrefix(r.URL.Path, "/api/v1/auth/") || strings.HasPrefix(r.URL.Path, "/api/v1/users") ||
+					!key.allows(r.Method, r.URL.Path) {
+					writeAuthError(w, http.StatusForbidden, "api key scope does not permit this request")
+					return
+				}
+				ctx := context.WithValue(r.Context(), authUserKey{}, claims)
+				ctx = context.WithValue(ctx, authAPIKeyKey{}, key)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
 			claims, err := tokens.ValidateAccessToken(tokenString)
 			if err != nil {
 				writeAuthError(w, http.StatusUnauthorized, "invalid or expired access token")
 				return
 			}
+			if backend != nil && !backend.SessionActive(r.Context(), claims.SessionID) {
+				writeAuthError(w, http.StatusUnauthorized, "session has been revoked")
+				return
+			}
 
 			// Set claims in context for downstream handlers.
 			ctx := context.WithValue(r.Context(), authUserKey{}, claims)
@@ -0,0 +1,356 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+// AuthProviderOIDC is the auth_provider value for users created via OIDC.
+const AuthProviderOIDC = "oidc"
+
+// OIDC errors.
+var (
+	ErrOIDCDisabled  = errors.New("oidc login is not configured")
+	ErrSetupRequired = errors.New("initial setup must be completed before oidc login")
+)
+
+// OIDCConfig holds OpenID Connect login settings.
+type OIDCConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`
+	Issuer       string `mapstructure:"issuer"` // IdP issuer URL used for discovery
+	ClientID     string `mapstructure:"client_id"`
+	ClientSecret string `mapstructure:"client_secret"` //nolint:gosec // G101: config field name, not a credential
+	RedirectURI  string `mapstructure:"redirect_uri"`  // Must point at /api/v1/auth/oidc/callback
+	// PostLoginRedirect is the UI path the callback redirects to. Tokens (or
+	// an error) are passed in the URL fragment so they never reach a server log.
+	PostLoginRedirect string   `mapstructure:"post_login_redirect"`
+	Scopes            []string `mapstructure:"scopes"`
+	DefaultRole       Role     `mapstructure:"default_role"` // Role for newly created OIDC users
+	AdminGroups       []string `mapstructure:"admin_groups"` // Members of these groups are created as admins
+}
+
+// DefaultOIDCConfig returns OIDC defaults. OIDC is disabled until configured.
+func DefaultOIDCConfig() OIDCConfig {
+	return OIDCConfig{
+		PostLoginRedirect: "/login",
+		Scopes:            []string{oidc.ScopeOpenID, "profile", "email"},
+		DefaultRole:       RoleViewer,
+	}
+}
+
+// OIDCIdentity holds the verified claims of an OIDC ID token.
+type OIDCIdentity struct {
+	Subject  string   `json:"sub"`
+	Email    string   `json:"email"`
+	Username string   `json:"preferred_username"`
+	Name     string   `json:"name"`
+	Groups   []string `json:"groups"`
+}
+
+// oidcAuthenticator performs the authorization-code exchange with an IdP.
+type oidcAuthenticator interface {
+	AuthCodeURL(state, nonce, verifier string) string
+	Exchange(ctx context.Context, code, verifier, nonce string) (*OIDCIdentity, error)
+}
+
+// OIDCProvider implements the authorization-code flow against an OIDC issuer.
+type OIDCProvider struct {
+	oauth    oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// NewOIDCProvider discovers the issuer's endpoints and returns a provider.
+func NewOIDCProvider(ctx context.Context, cfg OIDCConfig) (*OIDCProvider, error) {
+	if cfg.Issuer == "" || cfg.ClientID == "" || cfg.RedirectURI == "" {
+		return nil, errors.New("oidc issuer, client_id, and redirect_uri are required")
+	}
+	provider, err := oidc.NewProvider(ctx, cfg.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("discover oidc issuer: %w", err)
+	}
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = DefaultOIDCConfig().Scopes
+	}
+	return &OIDCProvider{
+		oauth: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			Endpoint:     provider.Endpoint(),
+			RedirectURL:  cfg.RedirectURI,
+			Scopes:       scopes,
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
+	}, nil
+}
+
+// AuthCodeURL returns the IdP authorization URL for a new login attempt.
+func (p *OIDCProvider) AuthCodeURL(state, nonce, verifier string) string {
+	return p.oauth.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+}
+
+// Exchange trades an authorization code for tokens and verifies the ID token,
+// including its audience, expiry, signature, and nonce.
+func (p *OIDCProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*OIDCIdentity, error) {
+	token, err := p.oauth.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("exchange code: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("verify id token: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, errors.New("id token nonce mismatch")
+	}
+
+	var identity OIDCIdentity
+	if err := idToken.Claims(&identity); err != nil {
+		return nil, fmt.Errorf("parse id token claims: %w", err)
+	}
+	identity.Subject = idToken.Subject
+	return &identity, nil
+}
+
+// LoginOIDC maps a verified OIDC identity to a local user, creating the user
+// on first login, and issues a token pair. Local TOTP is not applied: the IdP
+// is responsible for its own MFA.
+func (s *Service) LoginOIDC(ctx context.Context, identity *OIDCIdentity, cfg OIDCConfig) (pair *TokenPair, err error) {
+	defer func() {
+		if err != nil {
+			s.auditFailure(ctx, AuditLogin, &User{}, err)
+		}
+	}()
+
+	if identity == nil || identity.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.store.GetUserByOIDCSubject(ctx, identity.Subject)
+	if errors.Is(err, sql.ErrNoRows) {
+		user, err = s.createOIDCUser(ctx, identity, cfg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Disabled {
+		return nil, ErrUserDisabled
+	}
+
+	pair, err = s.issueTokenPair(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	_ = s.store.UpdateLastLogin(ctx, user.ID)
+	s.logger.Info("user logged in via oidc", zap.String("username", user.Username), zap.String("user_id", user.ID))
+	s.auditSuccess(ctx, AuditLogin, user, "oidc")
+	return pair, nil
+}
+
+// createOIDCUser provisions a local account for a first-time OIDC login.
+// Existing local accounts are never linked by username or email, since that
+// would let anyone controlling a matching IdP account take them over.
+func (s *Service) createOIDCUser(ctx context.Context, identity *OIDCIdentity, cfg OIDCConfig) (*User, error) {
+	needsSetup, err := s.NeedsSetup(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if needsSetup {
+		return nil, ErrSetupRequired
+	}
+
+	username := identity.Username
+	if username == "" {
+		username, _, _ = strings.Cut(identity.Email, "@")
+	}
+	if username == "" {
+		username = "oidc-" + identity.Subject
+	}
+	email := identity.Email
+	if email == "" {
+		// Email is unique and required; synthesize a non-routable one.
+		email = identity.Subject + "@oidc.invalid"
+	}
+
+	role := cfg.DefaultRole
+	if _, err := s.GetRole(ctx, role); err != nil {
+		role = RoleViewer
+	}
+	for _, g := range identity.Groups {
+		if slices.Contains(cfg.AdminGroups, g) {
+			role = RoleAdmin
+			break
+		}
+	}
+
+	user := &User{
+		ID:           uuid.New().String(),
+		Username:     username,
+		Email:        email,
+		Role:         role,
+		AuthProvider: AuthProviderOIDC,
+		OIDCSubject:  identity.Subject,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := s.store.CreateUser(ctx, user); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return nil, ErrUserExists
+		}
+		return nil, err
+	}
+	s.logger.Info("oidc user created",
+		zap.String("username", username),
+		zap.String("role", string(role)),
+	)
+	return user, nil
+}
+
+// oidcStateCookie carries the state, nonce, and PKCE verifier of an in-flight
+// OIDC login between the login redirect and the callback.
+const oidcStateCookie = "subnetree_oidc_state"
+
+// OIDCStatusResponse reports whether OIDC login is available.
+type OIDCStatusResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+// SetOIDC enables OIDC login on the handler.
+// Called from the composition root when auth.oidc is enabled.
+func (h *Handler) SetOIDC(provider oidcAuthenticator, cfg OIDCConfig) {
+	h.oidc = provider
+	h.oidcCfg = cfg
+}
+
+// handleOIDCStatus reports whether OIDC login is configured.
+//
+//	@Summary		OIDC status
+//	@Description	Returns whether OIDC single sign-on is enabled.
+//	@Tags			auth
+//	@Produce		json
+//	@Success		200	{object}	OIDCStatusResponse
+//	@Router			/auth/oidc/status [get]
+func (h *Handler) handleOIDCStatus(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, OIDCStatusResponse{Enabled: h.oidc != nil})
+}
+
+// handleOIDCLogin redirects the browser to the IdP authorization endpoint.
+//
+//	@Summary		Start OIDC login
+//	@Description	Redirects to the configured OIDC identity provider.
+//	@Tags			auth
+//	@Success		302
+//	@Failure		404	{object}	models.APIProblem
+//	@Router			/auth/oidc/login [get]
+func (h *Handler) handleOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	if h.oidc == nil {
+		writeAuthError(w, http.StatusNotFound, ErrOIDCDisabled.Error())
+		return
+	}
+	state, nonce, verifier := oauth2.GenerateVerifier(), oauth2.GenerateVerifier(), oauth2.GenerateVerifier()
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state + "." + nonce + "." + verifier,
+		Path:     "/api/v1/auth/oidc",
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, h.oidc.AuthCodeURL(state, nonce, verifier), http.StatusFound)
+}
+
+// handleOIDCCallback completes the authorization-code flow and redirects to
+// the UI with the issued token pair in the URL fragment.
+//
+//	@Summary		OIDC callback
+//	@Description	Exchanges the authorization code, maps the identity to a local user, and redirects to the UI with tokens.
+//	@Tags			auth
+//	@Param			code	query	string	true	"Authorization code"
+//	@Param			state	query	string	true	"Login state"
+//	@Success		302
+//	@Failure		404	{object}	models.APIProblem
+//	@Router			/auth/oidc/callback [get]
+func (h *Handler) handleOIDCCallback(w http.ResponseWriter, r *http.Request) {
+	if h.oidc == nil {
+		writeAuthError(w, http.StatusNotFound, ErrOIDCDisabled.Error())
+		return
+	}
+	// The state cookie is single-use.
+	http.SetCookie(w, &http.Cookie{Name: oidcStateCookie, Path: "/api/v1/auth/oidc", MaxAge: -1})
+
+	q := r.URL.Query()
+	if idpErr := q.Get("error"); idpErr != "" {
+		h.oidcRedirect(w, r, url.Values{"error": {idpErr}})
+		return
+	}
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil {
+		h.oidcRedirect(w, r, url.Values{"error": {"missing_state"}})
+		return
+	}
+	parts := strings.Split(cookie.Value, ".")
+	if len(parts) != 3 || q.Get("state") == "" ||
+		subtle.ConstantTimeCompare([]byte(parts[0]), []byte(q.Get("state"))) != 1 {
+		h.oidcRedirect(w, r, url.Values{"error": {"invalid_state"}})
+		return
+	}
+
+	identity, err := h.oidc.Exchange(r.Context(), q.Get("code"), parts[2], parts[1])
+	if err != nil {
+		h.logger.Warn("oidc code exchange failed", zap.Error(err))
+		h.oidcRedirect(w, r, url.Values{"error": {"exchange_failed"}})
+		return
+	}
+
+	pair, err := h.service.LoginOIDC(withClientInfo(r), identity, h.oidcCfg)
+	if err != nil {
+		code := "login_failed"
+		switch {
+		case errors.Is(err, ErrUserDisabled):
+			code = "account_disabled"
+		case errors.Is(err, ErrUserExists):
+			code = "account_conflict"
+		case errors.Is(err, ErrSetupRequired):
+			code = "setup_required"
+		default:
+			h.logger.Error("oidc login failed", zap.Error(err))
+		}
+		h.oidcRedirect(w, r, url.Values{"error": {code}})
+		return
+	}
+
+	h.oidcRedirect(w, r, url.Values{
+		"access_token":  {pair.AccessToken},
+		"refresh_token": {pair.RefreshToken},
+		"expires_in":    {strconv.Itoa(pair.ExpiresIn)},
+	})
+}
+
+// oidcRedirect sends the browser to the post-login UI route with values in
+// the URL fragment, which browsers never send to servers.
+func (h *Handler) oidcRedirect(w http.ResponseWriter, r *http.Request, values url.Values) {
+	target := h.oidcCfg.PostLoginRedirect
+	if target == "" || !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") {
+		target = DefaultOIDCConfig().PostLoginRedirect
+	}
+	http.Redirect(w, r, target+"#"+values.Encode(), http.StatusFound)
+}
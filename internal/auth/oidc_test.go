@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// fakeOIDC is an oidcAuthenticator that returns a fixed identity.
+type fakeOIDC struct {
+	identity    *OIDCIdentity
+	gotVerifier string
+	gotNonce    string
+}
+
+func (f *fakeOIDC) AuthCodeURL(state, _, _ string) string {
+	return "https://idp.example.com/authorize?state=" + url.QueryEscape(state)
+}
+
+func (f *fakeOIDC) Exchange(_ context.Context, code, verifier, nonce string) (*OIDCIdentity, error) {
+	f.gotVerifier, f.gotNonce = verifier, nonce
+	if code != "good-code" {
+		return nil, errors.New("bad code")
+	}
+	return f.identity, nil
+}
+
+func TestLoginOIDC_RequiresSetup(t *testing.T) {
+	_, _, svc := testEnv(t)
+
+	_, err := svc.LoginOIDC(context.Background(), &OIDCIdentity{Subject: "sub-1"}, DefaultOIDCConfig())
+	if !errors.Is(err, ErrSetupRequired) {
+		t.Errorf("err = %v, want ErrSetupRequired", err)
+	}
+}
+
+func TestLoginOIDC_CreatesAndReusesUser(t *testing.T) {
+	userStore, tokens, svc := testEnv(t)
+	ctx := context.Background()
+	if _, err := svc.Setup(ctx, "admin", "admin@example.com", "securepassword"); err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+
+	cfg := DefaultOIDCConfig()
+	cfg.AdminGroups = []string{"netadmins"}
+	identity := &OIDCIdentity{Subject: "sub-1", Email: "alice@example.com", Groups: []string{"netadmins"}}
+
+	pair, err := svc.LoginOIDC(ctx, identity, cfg)
+	if err != nil {
+		t.Fatalf("LoginOIDC: %v", err)
+	}
+	claims, err := tokens.ValidateAccessToken(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken: %v", err)
+	}
+	if claims.Username != "alice" || claims.Role != string(RoleAdmin) {
+		t.Errorf("claims = %s/%s, want alice/admin", claims.Username, claims.Role)
+	}
+
+	// A second login with the same subject maps to the same user.
+	if _, err := svc.LoginOIDC(ctx, identity, cfg); err != nil {
+		t.Fatalf("second LoginOIDC: %v", err)
+	}
+	user, err := userStore.GetUserByOIDCSubject(ctx, "sub-1")
+	if err != nil {
+		t.Fatalf("GetUserByOIDCSubject: %v", err)
+	}
+	if user.ID != claims.UserID || user.AuthProvider != AuthProviderOIDC {
+		t.Errorf("user = %+v, want id %s with oidc provider", user, claims.UserID)
+	}
+	if count, _ := userStore.CountUsers(ctx); count != 2 {
+		t.Errorf("user count = %d, want 2", count)
+	}
+}
+
+func TestLoginOIDC_DoesNotLinkLocalUser(t *testing.T) {
+	_, _, svc := testEnv(t)
+	ctx := context.Background()
+	if _, err := svc.Setup(ctx, "admin", "admin@example.com", "securepassword"); err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+
+	_, err := svc.LoginOIDC(ctx, &OIDCIdentity{Subject: "sub-2", Username: "admin"}, DefaultOIDCConfig())
+	if !errors.Is(err, ErrUserExists) {
+		t.Errorf("err = %v, want ErrUserExists", err)
+	}
+}
+
+func TestHandleOIDCCallback(t *testing.T) {
+	h, mux := setupHandlerEnv(t)
+	if _, err := h.service.Setup(context.Background(), "admin", "admin@example.com", "securepassword"); err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	idp := &fakeOIDC{identity: &OIDCIdentity{Subject: "sub-1", Username: "bob"}}
+	h.SetOIDC(idp, DefaultOIDCConfig())
+
+	login := doRequest(mux, "GET", "/api/v1/auth/oidc/login", nil)
+	if login.Code != http.StatusFound {
+		t.Fatalf("login status = %d, want 302", login.Code)
+	}
+	var cookie *http.Cookie
+	for _, c := range login.Result().Cookies() {
+		if c.Name == oidcStateCookie {
+			cookie = c
+		}
+	}
+	if cookie == nil || !cookie.HttpOnly {
+		t.Fatalf("state cookie = %+v, want HttpOnly cookie", cookie)
+	}
+	loc, _ := url.Parse(login.Header().Get("Location"))
+	state := loc.Query().Get("state")
+
+	tests := []struct {
+		name     string
+		query    string
+		wantFrag string
+	}{
+		{"state mismatch", "code=good-code&state=other", "error=invalid_state"},
+		{"bad code", "code=bad-code&state=" + url.QueryEscape(state), "error=exchange_failed"},
+		{"success", "code=good-code&state=" + url.QueryEscape(state), "access_token="},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/auth/oidc/callback?"+tc.query, http.NoBody)
+			req.AddCookie(cookie)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			if w.Code != http.StatusFound {
+				t.Fatalf("status = %d, want 302", w.Code)
+			}
+			target := w.Header().Get("Location")
+			if !strings.HasPrefix(target, "/login#") || !strings.Contains(target, tc.wantFrag) {
+				t.Errorf("Location = %q, want /login#...%s", target, tc.wantFrag)
+			}
+		})
+	}
+
+	parts := strings.Split(cookie.Value, ".")
+	if idp.gotNonce != parts[1] || idp.gotVerifier != parts[2] {
+		t.Error("callback did not pass the stored nonce and verifier to the exchange")
+	}
+}
+
+func TestHandleOIDC_Disabled(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+
+	if w := doRequest(mux, "GET", "/api/v1/auth/oidc/login", nil); w.Code != http.StatusNotFound {
+		t.Errorf("login status = %d, want 404", w.Code)
+	}
+	w := doRequest(mux, "GET", "/api/v1/auth/oidc/status", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"enabled":false`) {
+		t.Errorf("status = %d body = %s, want enabled=false", w.Code, w.Body.String())
+	}
+}
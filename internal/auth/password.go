@@ -0,0 +1,424 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.uber.org/zap"
+)
+
+// Password errors.
+var (
+	ErrWeakPassword      = errors.New("password does not meet the strength policy")
+	ErrPasswordNotLocal  = errors.New("password is managed by an external identity provider")
+	ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+)
+
+const (
+	// PasswordResetTTL is how long an admin-issued reset token stays valid.
+	PasswordResetTTL = 24 * time.Hour
+	// maxPasswordBytes is bcrypt's input limit; longer passwords would be
+	// silently truncated.
+	maxPasswordBytes = 72
+	// strongPasswordLen is the length at which character variety is no
+	// longer required.
+	strongPasswordLen = 16
+)
+
+// commonPasswords are rejected regardless of length or variety.
+var commonPasswords = map[string]bool{
+	"password": true, "password1": true, "password123": true, "passw0rd": true,
+	"12345678": true, "123456789": true, "1234567890": true, "87654321": true,
+	"qwerty123": true, "qwertyuiop": true, "iloveyou": true, "sunshine": true,
+	"letmein1": true, "welcome1": true, "admin123": true, "administrator": true,
+	"changeme": true, "subnetree": true, "football": true, "baseball": true,
+}
+
+// CheckPasswordStrength applies the password policy used when a password is
+// changed or reset: at least 8 characters and at most 72 bytes, not a common
+// password, not containing the username, and, below 16 characters, at least
+// three of lowercase, uppercase, digits, and symbols.
+func CheckPasswordStrength(password, username string) error {
+	if err := ValidatePassword(password); err != nil {
+		return fmt.Errorf("%w: %v", ErrWeakPassword, err)
+	}
+	if len(password) > maxPasswordBytes {
+		return fmt.Errorf("%w: password must be at most %d bytes", ErrWeakPassword, maxPasswordBytes)
+	}
+	lower := strings.ToLower(password)
+	if commonPasswords[lower] {
+		return fmt.Errorf("%w: password is too common", ErrWeakPassword)
+	}
+	if len(username) >= 3 && strings.Contains(lower, strings.ToLower(username)) {
+		return fmt.Errorf("%w: password must not contain the username", ErrWeakPassword)
+	}
+	if len(password) < strongPasswordLen && characterClasses(password) < 3 {
+		return fmt.Errorf("%w: use at least %d characters, or mix three of lowercase, uppercase, digits, and symbols",
+			ErrWeakPassword, strongPasswordLen)
+	}
+	return nil
+}
+
+func characterClasses(s string) int {
+	var lower, upper, digit, other bool
+	for _, r := range s {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+	n := 0
+	for _, ok := range []bool{lower, upper, digit, other} {
+		if ok {
+			n++
+		}
+	}
+	return n
+}
+
+// ChangePasswordRequest is the request body for POST /auth/change-password.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" example:"securepassword123"`
+	NewPassword     string `json:"new_password" example:"Correct-Horse-42"`
+}
+
+// PasswordResetResponse is the response from POST /users/{id}/reset-password.
+// The token is returned only once.
+type PasswordResetResponse struct {
+	Token     string    `json:"token" example:"9f86d081884c7d659a2feaa0c55ad015..."`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ResetPasswordRequest is the request body for POST /auth/reset-password.
+type ResetPasswordRequest struct {
+	Token       string `json:"token" example:"9f86d081884c7d659a2feaa0c55ad015..."`
+	NewPassword string `json:"new_password" example:"Correct-Horse-42"`
+}
+
+// ChangePassword replaces a local user's password after verifying the
+// current one. All other sessions and refresh tokens are revoked; the
+// session identified by keepSessionID stays signed in.
+func (s *Service) ChangePassword(ctx context.Context, userID, keepSessionID, current, newPassword string) error {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.AuthProvider != "local" {
+		return ErrPasswordNotLocal
+	}
+	if !CheckPassword(user.PasswordHash, current) {
+		s.auditFailure(ctx, AuditPasswordChange, user, ErrInvalidCredentials)
+		return ErrInvalidCredentials
+	}
+	if err := CheckPasswordStrength(newPassword, user.Username); err != nil {
+		return err
+	}
+	if CheckPassword(user.PasswordHash, newPassword) {
+		return fmt.Errorf("%w: new password must differ from the current one", ErrWeakPassword)
+	}
+
+	if err := s.setPassword(ctx, user.ID, newPassword); err != nil {
+		return err
+	}
+	if err := s.revokeSessions(ctx, user.ID, "", keepSessionID); err != nil {
+		return err
+	}
+	if err := s.store.RevokeRefreshTokensOutsideSession(ctx, user.ID, keepSessionID); err != nil {
+		return fmt.Errorf("revoke refresh tokens: %w", err)
+	}
+
+	s.logger.Info("password changed",
+		zap.String("username", user.Username),
+		zap.String("user_id", user.ID),
+	)
+	s.auditSuccess(ctx, AuditPasswordChange, user, "")
+	return nil
+}
+
+// CreatePasswordReset issues a one-time token that lets a local user set a
+// new password without the old one. Any earlier unused token for the user
+// is replaced.
+func (s *Service) CreatePasswordReset(ctx context.Context, userID string) (*PasswordResetResponse, error) {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.AuthProvider != "local" {
+		return nil, ErrPasswordNotLocal
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("generate reset token: %w", err)
+	}
+	raw := hex.EncodeToString(b)
+	expiresAt := time.Now().Add(PasswordResetTTL).UTC()
+	if err := s.store.SavePasswordReset(ctx, user.ID, HashToken(raw), expiresAt); err != nil {
+		return nil, fmt.Errorf("save reset token: %w", err)
+	}
+
+	s.logger.Info("password reset issued",
+		zap.String("username", user.Username),
+		zap.String("user_id", user.ID),
+		zap.Time("expires_at", expiresAt),
+	)
+	return &PasswordResetResponse{Token: raw, ExpiresAt: expiresAt}, nil
+}
+
+// ResetPassword consumes a reset token and sets the user's new password.
+// Every session and refresh token of the user is revoked and any lockout
+// is cleared.
+func (s *Service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	tokenHash := HashToken(token)
+	userID, err := s.store.GetPasswordReset(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.auditFailure(ctx, AuditPasswordReset, &User{}, ErrInvalidResetToken)
+			return ErrInvalidResetToken
+		}
+		return fmt.Errorf("lookup reset token: %w", err)
+	}
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	// A weak password leaves the token usable for another attempt.
+	if err := CheckPasswordStrength(newPassword, user.Username); err != nil {
+		return err
+	}
+	consumed, err := s.store.DeletePasswordReset(ctx, tokenHash)
+	if err != nil {
+		return fmt.Errorf("consume reset token: %w", err)
+	}
+	if !consumed {
+		return ErrInvalidResetToken
+	}
+
+	if err := s.setPassword(ctx, user.ID, newPassword); err != nil {
+		return err
+	}
+	if err := s.revokeSessions(ctx, user.ID, "", ""); err != nil {
+		return err
+	}
+	if err := s.store.RevokeUserRefreshTokens(ctx, user.ID); err != nil {
+		return fmt.Errorf("revoke refresh tokens: %w", err)
+	}
+	_ = s.store.ClearFailedLogins(ctx, user.ID)
+
+	s.logger.Info("password reset completed",
+		zap.String("username", user.Username),
+		zap.String("user_id", user.ID),
+	)
+	s.auditSuccess(ctx, AuditPasswordReset, user, "")
+	return nil
+}
+
+func (s *Service) setPassword(ctx context.Context, userID, password string) error {
+	hash, err := HashPassword(password, 0)
+	if err != nil {
+		return err
+	}
+	if err := s.store.UpdatePasswordHash(ctx, userID, hash); err != nil {
+		return fmt.Errorf("update password: %w", err)
+	}
+	return nil
+}
+
+// UpdatePasswordHash replaces a user's password hash.
+func (s *UserStore) UpdatePasswordHash(ctx context.Context, userID, hash string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE auth_users SET password_hash = ? WHERE id = ?`, hash, userID)
+	return err
+}
+
+// RevokeRefreshTokensOutsideSession revokes all of the user's refresh tokens
+// except those belonging to keepSessionID, including tokens issued before
+// sessions were tracked.
+func (s *UserStore) RevokeRefreshTokensOutsideSession(ctx context.Context, userID, keepSessionID string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE auth_refresh_tokens SET revoked = 1
+		WHERE user_id = ? AND (session_id IS NULL OR session_id != ?)`,
+		userID, keepSessionID)
+	return err
+}
+
+// SavePasswordReset stores a hashed reset token, replacing any earlier token
+// for the same user.
+func (s *UserStore) SavePasswordReset(ctx context.Context, userID, tokenHash string, expiresAt time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM auth_password_resets WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO auth_password_resets (token_hash, user_id, expires_at, created_at)
+		VALUES (?, ?, ?, ?)`,
+		tokenHash, userID, expiresAt, time.Now().UTC()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetPasswordReset returns the user an unexpired reset token belongs to.
+func (s *UserStore) GetPasswordReset(ctx context.Context, tokenHash string) (string, error) {
+	var userID string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT user_id FROM auth_password_resets WHERE token_hash = ? AND expires_at > ?`,
+		tokenHash, time.Now().UTC()).Scan(&userID)
+	return userID, err
+}
+
+// DeletePasswordReset removes a reset token. It reports false if the token
+// was already gone, e.g. consumed by a concurrent request.
+func (s *UserStore) DeletePasswordReset(ctx context.Context, tokenHash string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM auth_password_resets WHERE token_hash = ?`, tokenHash)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// handleChangePassword changes the authenticated user's password.
+//
+//	@Summary		Change password
+//	@Description	Change the authenticated user's password. The current password is required. All other sessions are signed out.
+//	@Tags			auth
+//	@Accept			json
+//	@Security		BearerAuth
+//	@Param			request	body	ChangePasswordRequest	true	"Current and new password"
+//	@Success		204		"No Content"
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		401		{object}	models.APIProblem
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/auth/change-password [post]
+func (h *Handler) handleChangePassword(w http.ResponseWriter, r *http.Request) {
+	claims := UserFromContext(r.Context())
+	if claims == nil {
+		writeAuthError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAuthError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		writeAuthError(w, http.StatusBadRequest, "current_password and new_password are required")
+		return
+	}
+
+	err := h.service.ChangePassword(withClientInfo(r), claims.UserID, claims.SessionID, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidCredentials):
+			writeAuthError(w, http.StatusUnauthorized, "current password is incorrect")
+		case errors.Is(err, ErrWeakPassword), errors.Is(err, ErrPasswordNotLocal):
+			writeAuthError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrUserNotFound):
+			writeAuthError(w, http.StatusUnauthorized, "authentication required")
+		default:
+			h.logger.Error("change password error", zap.Error(err))
+			writeAuthError(w, http.StatusInternalServerError, "failed to change password")
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleCreatePasswordReset issues a one-time password reset token for a user.
+//
+//	@Summary		Issue password reset
+//	@Description	Issue a one-time token the user can redeem at /auth/reset-password to set a new password. Replaces any earlier token. Requires admin role.
+//	@Tags			users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"User ID"
+//	@Success		201	{object}	PasswordResetResponse
+//	@Failure		400	{object}	models.APIProblem
+//	@Failure		401	{object}	models.APIProblem
+//	@Failure		403	{object}	models.APIProblem
+//	@Failure		404	{object}	models.APIProblem
+//	@Failure		500	{object}	models.APIProblem
+//	@Router			/users/{id}/reset-password [post]
+func (h *Handler) handleCreatePasswordReset(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	resp, err := h.service.CreatePasswordReset(r.Context(), r.PathValue("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			writeAuthError(w, http.StatusNotFound, "user not found")
+		case errors.Is(err, ErrPasswordNotLocal):
+			writeAuthError(w, http.StatusBadRequest, err.Error())
+		default:
+			h.logger.Error("create password reset error", zap.Error(err))
+			writeAuthError(w, http.StatusInternalServerError, "failed to issue password reset")
+		}
+		return
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// handleResetPassword sets a new password using a reset token.
+//
+//	@Summary		Reset password
+//	@Description	Redeem a one-time reset token issued by an admin and set a new password. All sessions of the user are signed out.
+//	@Tags			auth
+//	@Accept			json
+//	@Param			request	body	ResetPasswordRequest	true	"Reset token and new password"
+//	@Success		204		"No Content"
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		401		{object}	models.APIProblem
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/auth/reset-password [post]
+func (h *Handler) handleResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAuthError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Token == "" || req.NewPassword == "" {
+		writeAuthError(w, http.StatusBadRequest, "token and new_password are required")
+		return
+	}
+
+	if err := h.service.ResetPassword(withClientInfo(r), req.Token, req.NewPassword); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidResetToken):
+			writeAuthError(w, http.StatusUnauthorized, err.Error())
+		case errors.Is(err, ErrWeakPassword):
+			writeAuthError(w, http.StatusBadRequest, err.Error())
+		default:
+			h.logger.Error("reset password error", zap.Error(err))
+			writeAuthError(w, http.StatusInternalServerError, "failed to reset password")
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheckPasswordStrength(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{"mixed classes", "Tr0ub4dor&3", false},
+		{"long passphrase", "correct horse battery staple", false},
+		{"too short", "Ab1!", true},
+		{"common", "Password123", true},
+		{"contains username", "xx-Alice-2024!", true},
+		{"single class", "lowercaseonly", true},
+		{"two classes", "lowercase123", true},
+		{"over bcrypt limit", "Aa1!" + strings.Repeat("x", 70), true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckPasswordStrength(tc.password, "alice")
+			if (err != nil) != tc.wantErr {
+				t.Errorf("CheckPasswordStrength(%q) error = %v, wantErr %v", tc.password, err, tc.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrWeakPassword) {
+				t.Errorf("err = %v, want ErrWeakPassword", err)
+			}
+		})
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	_, _, svc := testEnv(t)
+	ctx := context.Background()
+	admin, err := svc.Setup(ctx, "admin", "admin@example.com", "securepassword")
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+
+	current := loginWithClient(t, svc, "Firefox", "192.168.1.20:5555")
+	other := loginWithClient(t, svc, "curl/8.0", "192.168.1.30:4444")
+	sid := sessionIDOf(t, svc, current)
+
+	if err := svc.ChangePassword(ctx, admin.ID, sid, "wrong", "N3w-Passphrase"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("wrong current password err = %v, want ErrInvalidCredentials", err)
+	}
+	if err := svc.ChangePassword(ctx, admin.ID, sid, "securepassword", "weakpass"); !errors.Is(err, ErrWeakPassword) {
+		t.Errorf("weak password err = %v, want ErrWeakPassword", err)
+	}
+	if err := svc.ChangePassword(ctx, admin.ID, sid, "securepassword", "N3w-Passphrase"); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+
+	if _, err := svc.Login(ctx, "admin", "securepassword"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("old password err = %v, want ErrInvalidCredentials", err)
+	}
+	if _, err := svc.Login(ctx, "admin", "N3w-Passphrase"); err != nil {
+		t.Errorf("new password: %v", err)
+	}
+	if _, err := svc.Refresh(ctx, other.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("other session refresh err = %v, want ErrInvalidToken", err)
+	}
+	if _, err := svc.Refresh(ctx, current.RefreshToken); err != nil {
+		t.Errorf("current session refresh: %v", err)
+	}
+}
+
+func TestChangePassword_ExternalUserRejected(t *testing.T) {
+	userStore, svc, _ := ldapEnv(t)
+	ctx := context.Background()
+	if _, err := svc.Login(ctx, "alice", "ad-password"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	alice, _ := userStore.GetUserByUsername(ctx, "alice")
+
+	if err := svc.ChangePassword(ctx, alice.ID, "", "ad-password", "N3w-Passphrase"); !errors.Is(err, ErrPasswordNotLocal) {
+		t.Errorf("ChangePassword err = %v, want ErrPasswordNotLocal", err)
+	}
+	if _, err := svc.CreatePasswordReset(ctx, alice.ID); !errors.Is(err, ErrPasswordNotLocal) {
+		t.Errorf("CreatePasswordReset err = %v, want ErrPasswordNotLocal", err)
+	}
+}
+
+func TestPasswordReset(t *testing.T) {
+	userStore, _, svc := testEnv(t)
+	ctx := context.Background()
+	admin, err := svc.Setup(ctx, "admin", "admin@example.com", "securepassword")
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	session := loginWithClient(t, svc, "Firefox", "192.168.1.20:5555")
+
+	first, err := svc.CreatePasswordReset(ctx, admin.ID)
+	if err != nil {
+		t.Fatalf("CreatePasswordReset: %v", err)
+	}
+	reset, err := svc.CreatePasswordReset(ctx, admin.ID)
+	if err != nil {
+		t.Fatalf("CreatePasswordReset: %v", err)
+	}
+	if err := svc.ResetPassword(ctx, first.Token, "N3w-Passphrase"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("replaced token err = %v, want ErrInvalidResetToken", err)
+	}
+
+	// A rejected password does not burn the token.
+	if err := svc.ResetPassword(ctx, reset.Token, "weakpass"); !errors.Is(err, ErrWeakPassword) {
+		t.Errorf("weak password err = %v, want ErrWeakPassword", err)
+	}
+	if err := userStore.LockAccount(ctx, admin.ID, reset.ExpiresAt); err != nil {
+		t.Fatalf("LockAccount: %v", err)
+	}
+	if err := svc.ResetPassword(ctx, reset.Token, "N3w-Passphrase"); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	if err := svc.ResetPassword(ctx, reset.Token, "An0ther-Passphrase"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("reused token err = %v, want ErrInvalidResetToken", err)
+	}
+
+	if _, err := svc.Refresh(ctx, session.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("refresh after reset err = %v, want ErrInvalidToken", err)
+	}
+	if _, err := svc.Login(ctx, "admin", "N3w-Passphrase"); err != nil {
+		t.Errorf("login after reset: %v", err)
+	}
+}
+
+func TestHandleResetPassword_Public(t *testing.T) {
+	h, mux := setupHandlerEnv(t)
+	handler := AuthMiddleware(h.service.Tokens())(mux)
+
+	body := strings.NewReader(`{"token":"bogus","new_password":"N3w-Passphrase"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/reset-password", body)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("unknown token status = %d, want 401", w.Code)
+	}
+
+	w = doRequest(mux, http.MethodPost, "/api/v1/auth/reset-password", map[string]string{})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("missing fields status = %d, want 400", w.Code)
+	}
+}
+
+func TestHandleCreatePasswordReset_RequiresAdmin(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+
+	w := doRequest(mux, http.MethodPost, "/api/v1/users/some-id/reset-password", nil)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("unauthenticated status = %d, want 401", w.Code)
+	}
+	w = doAuthRequest(mux, http.MethodPost, "/api/v1/users/some-id/reset-password", "token", nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown user status = %d, want 404", w.Code)
+	}
+}
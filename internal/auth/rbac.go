@@ -0,0 +1,459 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Access is the level of access a role grants to a module.
+type Access string
+
+const (
+	AccessNone  Access = "none"
+	AccessRead  Access = "read"  // GET, HEAD, and OPTIONS requests
+	AccessWrite Access = "write" // All requests; implies read
+)
+
+// AllModules is the permission key that applies to modules without an
+// explicit entry.
+const AllModules = "*"
+
+// RBAC errors.
+var (
+	ErrRoleNotFound    = errors.New("role not found")
+	ErrRoleExists      = errors.New("role already exists")
+	ErrRoleBuiltIn     = errors.New("built-in roles cannot be modified or deleted")
+	ErrRoleInUse       = errors.New("role is assigned to one or more users")
+	ErrInvalidRoleSpec = errors.New("role name must be lowercase alphanumeric and permissions must map module names or * to none, read, or write")
+)
+
+// RoleDefinition is a named set of per-module permissions. Modules are the
+// first path segment under /api/v1/ (e.g. "pulse" for /api/v1/pulse/checks).
+type RoleDefinition struct {
+	Name        Role              `json:"name" example:"pulse-operator"`
+	Description string            `json:"description,omitempty" example:"Manages monitoring checks"`
+	Permissions map[string]Access `json:"permissions"`
+	BuiltIn     bool              `json:"built_in"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// DefaultRoleDefinitions returns the built-in roles.
+func DefaultRoleDefinitions() []RoleDefinition {
+	return []RoleDefinition{
+		{
+			Name:        RoleAdmin,
+			Description: "Full access to all modules",
+			Permissions: map[string]Access{AllModules: AccessWrite},
+			BuiltIn:     true,
+		},
+		{
+			Name:        RoleOperator,
+			Description: "Manages all modules except vault credentials",
+			Permissions: map[string]Access{AllModules: AccessWrite, "vault": AccessRead},
+			BuiltIn:     true,
+		},
+		{
+			Name:        RoleViewer,
+			Description: "Read-only access to all modules except vault",
+			Permissions: map[string]Access{AllModules: AccessRead, "vault": AccessNone},
+			BuiltIn:     true,
+		},
+	}
+}
+
+// Allows reports whether the role grants the requested access to a module.
+func (d *RoleDefinition) Allows(module string, write bool) bool {
+	access, ok := d.Permissions[module]
+	if !ok {
+		access = d.Permissions[AllModules]
+	}
+	switch access {
+	case AccessWrite:
+		return true
+	case AccessRead:
+		return !write
+	default:
+		return false
+	}
+}
+
+func (d *RoleDefinition) validate() error {
+	if !moduleScopePattern.MatchString(string(d.Name)) || len(d.Permissions) == 0 {
+		return ErrInvalidRoleSpec
+	}
+	for module, access := range d.Permissions {
+		if module != AllModules && !moduleScopePattern.MatchString(module) {
+			return ErrInvalidRoleSpec
+		}
+		if access != AccessNone && access != AccessRead && access != AccessWrite {
+			return ErrInvalidRoleSpec
+		}
+	}
+	return nil
+}
+
+// RoleResolver looks up role definitions for permission checks.
+type RoleResolver interface {
+	GetRole(ctx context.Context, name Role) (*RoleDefinition, error)
+}
+
+// staticRoles resolves roles from a fixed set of definitions.
+type staticRoles []RoleDefinition
+
+func (s staticRoles) GetRole(_ context.Context, name Role) (*RoleDefinition, error) {
+	for i := range s {
+		if s[i].Name == name {
+			return &s[i], nil
+		}
+	}
+	return nil, ErrRoleNotFound
+}
+
+// RBACMiddleware enforces role permissions on module routes. It must run
+// after a middleware that sets claims. Auth and user management routes are
+// skipped because their handlers check the caller themselves.
+func RBACMiddleware(roles RoleResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := UserFromContext(r.Context())
+			module, ok := routeModule(r.URL.Path)
+			if claims == nil || !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			role, err := roles.GetRole(r.Context(), Role(claims.Role))
+			if err != nil {
+				writeAuthError(w, http.StatusForbidden, "unknown role")
+				return
+			}
+			write := isWriteRequest(r.Method, r.URL.Path)
+			if !role.Allows(module, write) {
+				writeAuthError(w, http.StatusForbidden, fmt.Sprintf("role %q does not permit %s access to %s", claims.Role, accessName(write), module))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// readOnlyPaths are POST endpoints that only read data. Grafana's datasource
+// protocol sends its queries as POST requests.
+var readOnlyPaths = map[string]bool{
+	"/api/v1/grafana/search":      true,
+	"/api/v1/grafana/query":       true,
+	"/api/v1/grafana/annotations": true,
+}
+
+// secretReadPattern matches GET endpoints that return decrypted secrets.
+// They need write access to their module so read-only roles cannot
+// extract credentials.
+var secretReadPattern = regexp.MustCompile(`^/api/v1/vault/credentials/[^/]+/data$`)
+
+// isWriteRequest reports whether a request needs write access.
+func isWriteRequest(method, path string) bool {
+	if secretReadPattern.MatchString(path) {
+		return true
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	case http.MethodPost:
+		return !readOnlyPaths[path]
+	}
+	return true
+}
+
+// routeModule returns the module a request path belongs to, or false for
+// paths not subject to module permissions.
+func routeModule(path string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, "/api/v1/")
+	if !ok {
+		return "", false
+	}
+	module, _, _ := strings.Cut(rest, "/")
+	switch module {
+	case "", "auth", "users", "ws":
+		return "", false
+	}
+	return module, true
+}
+
+func accessName(write bool) string {
+	if write {
+		return string(AccessWrite)
+	}
+	return string(AccessRead)
+}
+
+// GetRole returns a role definition by name.
+func (s *Service) GetRole(ctx context.Context, name Role) (*RoleDefinition, error) {
+	d, err := s.store.GetRole(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRoleNotFound
+	}
+	return d, err
+}
+
+// ListRoles returns all role definitions.
+func (s *Service) ListRoles(ctx context.Context) ([]RoleDefinition, error) {
+	return s.store.ListRoles(ctx)
+}
+
+// CreateRole adds a custom role.
+func (s *Service) CreateRole(ctx context.Context, d *RoleDefinition) error {
+	if err := d.validate(); err != nil {
+		return err
+	}
+	if _, err := s.store.GetRole(ctx, d.Name); err == nil {
+		return ErrRoleExists
+	}
+	d.BuiltIn = false
+	d.CreatedAt = time.Now().UTC()
+	if err := s.store.SaveRole(ctx, d); err != nil {
+		return err
+	}
+	s.logger.Info("role created", zap.String("role", string(d.Name)))
+	return nil
+}
+
+// UpdateRole replaces the description and permissions of a custom role.
+func (s *Service) UpdateRole(ctx context.Context, d *RoleDefinition) error {
+	existing, err := s.GetRole(ctx, d.Name)
+	if err != nil {
+		return err
+	}
+	if existing.BuiltIn {
+		return ErrRoleBuiltIn
+	}
+	if err := d.validate(); err != nil {
+		return err
+	}
+	d.BuiltIn = false
+	d.CreatedAt = existing.CreatedAt
+	return s.store.SaveRole(ctx, d)
+}
+
+// DeleteRole removes a custom role that is not assigned to any user.
+func (s *Service) DeleteRole(ctx context.Context, name Role) error {
+	existing, err := s.GetRole(ctx, name)
+	if err != nil {
+		return err
+	}
+	if existing.BuiltIn {
+		return ErrRoleBuiltIn
+	}
+	users, err := s.store.CountUsersWithRole(ctx, name)
+	if err != nil {
+		return err
+	}
+	if users > 0 {
+		return ErrRoleInUse
+	}
+	return s.store.DeleteRole(ctx, name)
+}
+
+// GetRole returns a role definition by name.
+func (s *UserStore) GetRole(ctx context.Context, name Role) (*RoleDefinition, error) {
+	var d RoleDefinition
+	var perms string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT name, description, permissions, built_in, created_at FROM auth_roles WHERE name = ?`,
+		string(name)).Scan(&d.Name, &d.Description, &perms, &d.BuiltIn, &d.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(perms), &d.Permissions); err != nil {
+		return nil, fmt.Errorf("decode role permissions: %w", err)
+	}
+	return &d, nil
+}
+
+// ListRoles returns all role definitions, built-in roles first.
+func (s *UserStore) ListRoles(ctx context.Context) ([]RoleDefinition, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT name, description, permissions, built_in, created_at FROM auth_roles ORDER BY built_in DESC, name`)
+	if err != nil {
+		return nil, fmt.Errorf("list roles: %w", err)
+	}
+	defer rows.Close()
+
+	roles := []RoleDefinition{}
+	for rows.Next() {
+		var d RoleDefinition
+		var perms string
+		if err := rows.Scan(&d.Name, &d.Description, &perms, &d.BuiltIn, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(perms), &d.Permissions); err != nil {
+			return nil, fmt.Errorf("decode role permissions: %w", err)
+		}
+		roles = append(roles, d)
+	}
+	return roles, rows.Err()
+}
+
+// SaveRole inserts or replaces a role definition.
+func (s *UserStore) SaveRole(ctx context.Context, d *RoleDefinition) error {
+	perms, err := json.Marshal(d.Permissions)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO auth_roles (name, description, permissions, built_in, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET description = excluded.description, permissions = excluded.permissions`,
+		string(d.Name), d.Description, string(perms), d.BuiltIn, d.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("save role: %w", err)
+	}
+	return nil
+}
+
+// DeleteRole removes a role definition.
+func (s *UserStore) DeleteRole(ctx context.Context, name Role) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM auth_roles WHERE name = ?`, string(name))
+	return err
+}
+
+// CountUsersWithRole returns the number of users assigned a role.
+func (s *UserStore) CountUsersWithRole(ctx context.Context, name Role) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM auth_users WHERE role = ?`, string(name)).Scan(&n)
+	return n, err
+}
+
+// handleListRoles returns all role definitions.
+//
+//	@Summary		List roles
+//	@Description	Returns built-in and custom roles with their module permissions.
+//	@Tags			users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{array}		RoleDefinition
+//	@Failure		401	{object}	models.APIProblem
+//	@Failure		500	{object}	models.APIProblem
+//	@Router			/auth/roles [get]
+func (h *Handler) handleListRoles(w http.ResponseWriter, r *http.Request) {
+	if UserFromContext(r.Context()) == nil {
+		writeAuthError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	roles, err := h.service.ListRoles(r.Context())
+	if err != nil {
+		h.logger.Error("list roles error", zap.Error(err))
+		writeAuthError(w, http.StatusInternalServerError, "failed to list roles")
+		return
+	}
+	writeJSON(w, http.StatusOK, roles)
+}
+
+// handleCreateRole adds a custom role.
+//
+//	@Summary		Create role
+//	@Description	Create a custom role with per-module permissions. Requires admin role.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		RoleDefinition	true	"Role definition"
+//	@Success		201		{object}	RoleDefinition
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		403		{object}	models.APIProblem
+//	@Failure		409		{object}	models.APIProblem
+//	@Router			/auth/roles [post]
+func (h *Handler) handleCreateRole(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	var d RoleDefinition
+	if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+		writeAuthError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := h.service.CreateRole(r.Context(), &d); err != nil {
+		h.writeRoleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, d)
+}
+
+// handleUpdateRole replaces a custom role's permissions.
+//
+//	@Summary		Update role
+//	@Description	Replace a custom role's description and permissions. Requires admin role.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			name	path		string			true	"Role name"
+//	@Param			request	body		RoleDefinition	true	"Role definition"
+//	@Success		200		{object}	RoleDefinition
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		403		{object}	models.APIProblem
+//	@Failure		404		{object}	models.APIProblem
+//	@Router			/auth/roles/{name} [put]
+func (h *Handler) handleUpdateRole(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	var d RoleDefinition
+	if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+		writeAuthError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	d.Name = Role(r.PathValue("name"))
+	if err := h.service.UpdateRole(r.Context(), &d); err != nil {
+		h.writeRoleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, d)
+}
+
+// handleDeleteRole removes a custom role.
+//
+//	@Summary		Delete role
+//	@Description	Delete a custom role that is not assigned to any user. Requires admin role.
+//	@Tags			users
+//	@Security		BearerAuth
+//	@Param			name	path	string	true	"Role name"
+//	@Success		204		"No Content"
+//	@Failure		403		{object}	models.APIProblem
+//	@Failure		404		{object}	models.APIProblem
+//	@Failure		409		{object}	models.APIProblem
+//	@Router			/auth/roles/{name} [delete]
+func (h *Handler) handleDeleteRole(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	if err := h.service.DeleteRole(r.Context(), Role(r.PathValue("name"))); err != nil {
+		h.writeRoleError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeRoleError maps role service errors to HTTP responses.
+func (h *Handler) writeRoleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidRoleSpec):
+		writeAuthError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrRoleNotFound):
+		writeAuthError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrRoleExists), errors.Is(err, ErrRoleInUse), errors.Is(err, ErrRoleBuiltIn):
+		writeAuthError(w, http.StatusConflict, err.Error())
+	default:
+		h.logger.Error("role management error", zap.Error(err))
+		writeAuthError(w, http.StatusInternalServerError, "role operation failed")
+	}
+}
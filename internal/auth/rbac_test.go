@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRoleDefinition_Allows(t *testing.T) {
+	pulseOp := RoleDefinition{
+		Name:        "pulse-operator",
+		Permissions: map[string]Access{AllModules: AccessRead, "pulse": AccessWrite, "vault": AccessNone},
+	}
+	tests := []struct {
+		module string
+		write  bool
+		want   bool
+	}{
+		{"pulse", true, true},
+		{"pulse", false, true},
+		{"recon", false, true},
+		{"recon", true, false},
+		{"vault", false, false},
+	}
+	for _, tc := range tests {
+		if got := pulseOp.Allows(tc.module, tc.write); got != tc.want {
+			t.Errorf("Allows(%q, write=%v) = %v, want %v", tc.module, tc.write, got, tc.want)
+		}
+	}
+}
+
+func TestRBACMiddleware(t *testing.T) {
+	_, _, svc := testEnv(t)
+	ctx := context.Background()
+	err := svc.CreateRole(ctx, &RoleDefinition{
+		Name:        "pulse-operator",
+		Permissions: map[string]Access{AllModules: AccessRead, "pulse": AccessWrite, "vault": AccessNone},
+	})
+	if err != nil {
+		t.Fatalf("CreateRole: %v", err)
+	}
+
+	handler := RBACMiddleware(svc)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	do := func(role, method, path string) int {
+		req := httptest.NewRequest(method, path, http.NoBody)
+		req = req.WithContext(context.WithValue(req.Context(), authUserKey{}, &Claims{UserID: "u1", Role: role}))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	tests := []struct {
+		name, role, method, path string
+		want                     int
+	}{
+		{"custom role writes own module", "pulse-operator", "POST", "/api/v1/pulse/checks", http.StatusOK},
+		{"custom role cannot write others", "pulse-operator", "POST", "/api/v1/recon/scan", http.StatusForbidden},
+		{"custom role cannot read vault", "pulse-operator", "GET", "/api/v1/vault/credentials", http.StatusForbidden},
+		{"operator reads vault", "operator", "GET", "/api/v1/vault/credentials", http.StatusOK},
+		{"operator cannot write vault", "operator", "POST", "/api/v1/vault/credentials", http.StatusForbidden},
+		{"operator cannot decrypt secrets", "operator", "GET", "/api/v1/vault/credentials/c1/data", http.StatusForbidden},
+		{"admin decrypts secrets", "admin", "GET", "/api/v1/vault/credentials/c1/data", http.StatusOK},
+		{"viewer cannot read vault", "viewer", "GET", "/api/v1/vault/credentials", http.StatusForbidden},
+		{"viewer cannot write", "viewer", "DELETE", "/api/v1/recon/devices/x", http.StatusForbidden},
+		{"viewer queries grafana", "viewer", "POST", "/api/v1/grafana/query", http.StatusOK},
+		{"auth routes skipped", "viewer", "POST", "/api/v1/auth/mfa/setup", http.StatusOK},
+		{"unknown role", "ghost", "GET", "/api/v1/recon/devices", http.StatusForbidden},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := do(tc.role, tc.method, tc.path); got != tc.want {
+				t.Errorf("status = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRoleCRUD(t *testing.T) {
+	_, _, svc := testEnv(t)
+	ctx := context.Background()
+	admin, err := svc.Setup(ctx, "admin", "admin@example.com", "securepassword")
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+
+	roles, err := svc.ListRoles(ctx)
+	if err != nil || len(roles) != 3 {
+		t.Fatalf("ListRoles = %d roles, err %v; want 3 built-in", len(roles), err)
+	}
+	if err := svc.DeleteRole(ctx, RoleViewer); !errors.Is(err, ErrRoleBuiltIn) {
+		t.Errorf("delete built-in err = %v, want ErrRoleBuiltIn", err)
+	}
+	if err := svc.CreateRole(ctx, &RoleDefinition{Name: "Bad Name", Permissions: map[string]Access{AllModules: AccessRead}}); !errors.Is(err, ErrInvalidRoleSpec) {
+		t.Errorf("invalid name err = %v, want ErrInvalidRoleSpec", err)
+	}
+
+	custom := &RoleDefinition{Name: "auditor", Permissions: map[string]Access{AllModules: AccessRead}}
+	if err := svc.CreateRole(ctx, custom); err != nil {
+		t.Fatalf("CreateRole: %v", err)
+	}
+	if _, err := svc.UpdateUser(ctx, admin.ID, admin.Email, "auditor", false); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	if err := svc.DeleteRole(ctx, "auditor"); !errors.Is(err, ErrRoleInUse) {
+		t.Errorf("delete in-use err = %v, want ErrRoleInUse", err)
+	}
+
+	custom.Permissions = map[string]Access{AllModules: AccessNone, "recon": AccessRead}
+	if err := svc.UpdateRole(ctx, custom); err != nil {
+		t.Fatalf("UpdateRole: %v", err)
+	}
+	got, err := svc.GetRole(ctx, "auditor")
+	if err != nil {
+		t.Fatalf("GetRole: %v", err)
+	}
+	if got.Allows("pulse", false) || !got.Allows("recon", false) {
+		t.Errorf("updated permissions = %v", got.Permissions)
+	}
+}
+
+func TestDemoAuthMiddleware_EnforcesViewer(t *testing.T) {
+	handler := DemoAuthMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/pulse/checks", http.NoBody)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want 403", w.Code)
+	}
+}
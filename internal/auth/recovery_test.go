@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+)
+
+// enrollTOTP sets up the admin user with verified TOTP and returns the user
+// ID, the TOTP secret, and the initial recovery codes.
+func enrollTOTP(t *testing.T, svc *Service) (userID, secret string, codes []string) {
+	t.Helper()
+	ctx := context.Background()
+	admin, err := svc.Setup(ctx, "admin", "admin@example.com", "securepassword")
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	otpURL, codes, err := svc.SetupTOTP(ctx, admin.ID)
+	if err != nil {
+		t.Fatalf("SetupTOTP: %v", err)
+	}
+	u, err := url.Parse(otpURL)
+	if err != nil {
+		t.Fatalf("parse otpauth url: %v", err)
+	}
+	secret = u.Query().Get("secret")
+	code, _ := totp.GenerateCode(secret, time.Now())
+	if err := svc.VerifyTOTPSetup(ctx, admin.ID, code); err != nil {
+		t.Fatalf("VerifyTOTPSetup: %v", err)
+	}
+	return admin.ID, secret, codes
+}
+
+func mfaToken(t *testing.T, svc *Service) string {
+	t.Helper()
+	result, err := svc.Login(context.Background(), "admin", "securepassword")
+	if err != nil || !result.MFARequired {
+		t.Fatalf("Login = %+v, %v; want MFA challenge", result, err)
+	}
+	return result.MFAToken
+}
+
+func TestRecoveryCode_AcceptedInPlaceOfTOTPOnce(t *testing.T) {
+	_, _, svc := testEnv(t)
+	_, _, codes := enrollTOTP(t, svc)
+	ctx := context.Background()
+
+	if _, err := svc.CompleteMFALogin(ctx, mfaToken(t, svc), " "+strings.ToUpper(codes[0])+" "); err != nil {
+		t.Fatalf("CompleteMFALogin with recovery code: %v", err)
+	}
+	if _, err := svc.CompleteMFALogin(ctx, mfaToken(t, svc), codes[0]); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("reused code err = %v, want ErrInvalidMFACode", err)
+	}
+}
+
+func TestRecoveryCode_ConcurrentUseSucceedsOnce(t *testing.T) {
+	_, _, svc := testEnv(t)
+	_, _, codes := enrollTOTP(t, svc)
+	ctx := context.Background()
+	tok := mfaToken(t, svc)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	successes := 0
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := svc.CompleteMFAWithRecovery(ctx, tok, codes[1]); err == nil {
+				mu.Lock()
+				successes++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if successes != 1 {
+		t.Errorf("successes = %d, want 1", successes)
+	}
+}
+
+func TestRegenerateRecoveryCodes(t *testing.T) {
+	userStore, _, svc := testEnv(t)
+	userID, secret, oldCodes := enrollTOTP(t, svc)
+	ctx := context.Background()
+
+	if _, err := svc.RegenerateRecoveryCodes(ctx, userID, "000000"); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("bad TOTP err = %v, want ErrInvalidMFACode", err)
+	}
+
+	code, _ := totp.GenerateCode(secret, time.Now())
+	newCodes, err := svc.RegenerateRecoveryCodes(ctx, userID, code)
+	if err != nil {
+		t.Fatalf("RegenerateRecoveryCodes: %v", err)
+	}
+	if len(newCodes) != 10 {
+		t.Errorf("codes = %d, want 10", len(newCodes))
+	}
+	if n, _ := userStore.CountUnusedRecoveryCodes(ctx, userID); n != 10 {
+		t.Errorf("unused codes = %d, want 10", n)
+	}
+	tok := mfaToken(t, svc)
+	if _, err := svc.CompleteMFAWithRecovery(ctx, tok, oldCodes[0]); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("old code err = %v, want ErrInvalidMFACode", err)
+	}
+	if _, err := svc.CompleteMFAWithRecovery(ctx, tok, newCodes[0]); err != nil {
+		t.Errorf("new code: %v", err)
+	}
+}
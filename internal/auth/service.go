@@ -5,8 +5,11 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/HerbHall/subnetree/pkg/plugin"
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -38,6 +41,7 @@ type LoginResult struct {
 	Pair        *TokenPair `json:"pair,omitempty"`
 	MFARequired bool       `json:"mfa_required,omitempty"`
 	MFAToken    string     `json:"mfa_token,omitempty"`
+	MFAMethods  []string   `json:"mfa_methods,omitempty"` // "totp", plus "webauthn" when the user has passkeys
 }
 
 // TokenPair contains an access token and refresh token.
@@ -53,6 +57,11 @@ type Service struct {
 	tokens *TokenService
 	totp   *TOTPService
 	logger *zap.Logger
+	bus    plugin.Publisher // optional; lockout, audit, and session events
+
+	webauthn *webauthn.WebAuthn // optional; nil when passkeys are disabled
+	ldap     ldapDirectory      // optional; nil when LDAP login is disabled
+	ldapCfg  LDAPConfig
 }
 
 // NewService creates an auth Service.
@@ -73,9 +82,59 @@ func (s *Service) Tokens() *TokenService {
 // Login authenticates a user and returns a LoginResult.
 // If the user has MFA enabled, a partial result with an MFA token is returned instead of a full token pair.
 func (s *Service) Login(ctx context.Context, username, password string) (*LoginResult, error) {
+	return s.LoginFrom(ctx, username, password, "")
+}
+
+// LoginFrom is Login with failed-attempt tracking for the client address ip
+// in addition to the username. Locked usernames and addresses return a
+// *LockoutError.
+func (s *Service) LoginFrom(ctx context.Context, username, password, ip string) (result *LoginResult, err error) {
+	if ci := clientInfoFrom(ctx); ci.ip == "" && ip != "" {
+		ci.ip = ip
+		ctx = context.WithValue(ctx, clientInfoKey{}, ci)
+	}
+	defer func() {
+		if err != nil {
+			s.auditFailure(ctx, AuditLogin, &User{Username: username}, err)
+		}
+	}()
+
+	if err := s.checkIPLockout(ctx, ip); err != nil {
+		if errors.Is(err, ErrAccountLocked) {
+			s.logger.Warn("login attempt from locked address", zap.String("ip", ip))
+		}
+		return nil, err
+	}
+
+	if s.ldap != nil {
+		user, err := s.authenticateLDAP(ctx, username, password)
+		switch {
+		case err == nil:
+			if user.Disabled {
+				return nil, ErrUserDisabled
+			}
+			if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) && s.userLockApplies(ctx, ip) {
+				return nil, &LockoutError{Until: *user.LockedUntil}
+			}
+			return s.completeLogin(ctx, user, ip)
+		case errors.Is(err, ErrLDAPUnavailable):
+			s.logger.Warn("ldap directory unreachable, falling back to local accounts",
+				zap.String("username", username),
+				zap.Error(err),
+			)
+		case errors.Is(err, ErrUserExists):
+			s.logger.Info("ldap login matches a non-ldap account, using local authentication",
+				zap.String("username", username))
+		}
+		// Unknown directory users and wrong passwords fall through so local
+		// accounts (such as the setup admin) keep working and failed
+		// attempts are counted.
+	}
+
 	user, err := s.store.GetUserByUsername(ctx, username)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
+			s.recordIPFailure(ctx, ip)
 			return nil, ErrInvalidCredentials
 		}
 		return nil, fmt.Errorf("lookup user: %w", err)
@@ -85,24 +144,37 @@ func (s *Service) Login(ctx context.Context, username, password string) (*LoginR
 		return nil, ErrUserDisabled
 	}
 
-	// Check if account is locked.
-	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+	// Check if account is locked. See userLockApplies for why a lock does
+	// not block addresses that have never failed.
+	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) && s.userLockApplies(ctx, ip) {
 		s.logger.Warn("login attempt on locked account",
 			zap.String("username", username),
 			zap.Time("locked_until", *user.LockedUntil),
 		)
-		return nil, ErrAccountLocked
+		return nil, &LockoutError{Until: *user.LockedUntil}
 	}
 
 	if !CheckPassword(user.PasswordHash, password) {
-		s.handleFailedLogin(ctx, user)
+		s.handleFailedLogin(ctx, user, ip)
+		s.recordIPFailure(ctx, ip)
 		return nil, ErrInvalidCredentials
 	}
 
+	return s.completeLogin(ctx, user, ip)
+}
+
+// completeLogin finishes a login whose password was verified: it clears
+// failed attempts and issues either an MFA challenge or a token pair.
+func (s *Service) completeLogin(ctx context.Context, user *User, ip string) (*LoginResult, error) {
+	username := user.Username
+
 	// Successful login -- clear any failed attempts.
 	if user.FailedLoginAttempts > 0 {
 		_ = s.store.ClearFailedLogins(ctx, user.ID)
 	}
+	if ip != "" {
+		_ = s.store.ClearIPLoginFailures(ctx, ip)
+	}
 
 	// If MFA is enabled, issue an MFA challenge token instead of a full token pair.
 	if user.TOTPEnabled && user.TOTPVerified {
@@ -115,7 +187,11 @@ func (s *Service) Login(ctx context.Context, username, password string) (*LoginR
 			return nil, fmt.Errorf("save mfa token: %w", saveErr)
 		}
 		s.logger.Info("MFA challenge issued", zap.String("username", username), zap.String("user_id", user.ID))
-		return &LoginResult{MFARequired: true, MFAToken: mfaToken}, nil
+		methods := []string{"totp"}
+		if s.hasPasskeys(ctx, user.ID) {
+			methods = append(methods, "webauthn")
+		}
+		return &LoginResult{MFARequired: true, MFAToken: mfaToken, MFAMethods: methods}, nil
 	}
 
 	pair, pairErr := s.issueTokenPair(ctx, user)
@@ -125,18 +201,23 @@ func (s *Service) Login(ctx context.Context, username, password string) (*LoginR
 
 	_ = s.store.UpdateLastLogin(ctx, user.ID)
 	s.logger.Info("user logged in", zap.String("username", username), zap.String("user_id", user.ID))
+	method := "password"
+	if user.AuthProvider == AuthProviderLDAP {
+		method = AuthProviderLDAP
+	}
+	s.auditSuccess(ctx, AuditLogin, user, method)
 	return &LoginResult{Pair: pair}, nil
 }
 
-func (s *Service) handleFailedLogin(ctx context.Context, user *User) {
+func (s *Service) handleFailedLogin(ctx context.Context, user *User, ip string) {
 	attempts, err := s.store.RecordFailedLogin(ctx, user.ID)
 	if err != nil {
 		s.logger.Error("failed to record failed login", zap.Error(err))
 		return
 	}
 
-	if attempts >= DefaultMaxFailedAttempts {
-		lockedUntil := time.Now().Add(DefaultLockoutDuration)
+	if d := lockoutDuration(attempts, DefaultMaxFailedAttempts); d > 0 {
+		lockedUntil := time.Now().Add(d)
 		if err := s.store.LockAccount(ctx, user.ID, lockedUntil); err != nil {
 			s.logger.Error("failed to lock account", zap.Error(err))
 			return
@@ -147,6 +228,13 @@ func (s *Service) handleFailedLogin(ctx context.Context, user *User) {
 			zap.Int("attempts", attempts),
 			zap.Time("locked_until", lockedUntil),
 		)
+		s.publishLockout(ctx, &AccountLockedEvent{
+			Username:    user.Username,
+			UserID:      user.ID,
+			IP:          ip,
+			Attempts:    attempts,
+			LockedUntil: lockedUntil,
+		})
 	}
 }
 
@@ -188,7 +276,13 @@ func (s *Service) Setup(ctx context.Context, username, email, password string) (
 }
 
 // Refresh validates a refresh token and returns a new token pair (rotation).
-func (s *Service) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+func (s *Service) Refresh(ctx context.Context, refreshToken string) (pair *TokenPair, err error) {
+	defer func() {
+		if err != nil {
+			s.auditFailure(ctx, AuditRefresh, &User{}, err)
+		}
+	}()
+
 	tokenHash := HashToken(refreshToken)
 	rt, err := s.store.GetRefreshToken(ctx, tokenHash)
 	if err != nil {
@@ -202,6 +296,11 @@ func (s *Service) Refresh(ctx context.Context, refreshToken string) (*TokenPair,
 		return nil, ErrInvalidToken
 	}
 
+	// Tokens from a revoked session are rejected even if not yet rotated.
+	if rt.SessionID != "" && !s.SessionActive(ctx, rt.SessionID) {
+		return nil, ErrInvalidToken
+	}
+
 	// Revoke the old token (rotation).
 	_ = s.store.RevokeRefreshToken(ctx, rt.ID)
 
@@ -213,7 +312,17 @@ func (s *Service) Refresh(ctx context.Context, refreshToken string) (*TokenPair,
 		return nil, ErrUserDisabled
 	}
 
-	return s.issueTokenPair(ctx, user)
+	if rt.SessionID == "" {
+		// Refresh token issued before sessions were tracked.
+		pair, err = s.issueTokenPair(ctx, user)
+	} else {
+		pair, err = s.issueSessionTokens(ctx, user, rt.SessionID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.auditSuccess(ctx, AuditRefresh, user, "")
+	return pair, nil
 }
 
 // Logout revokes a refresh token.
@@ -226,7 +335,20 @@ func (s *Service) Logout(ctx context.Context, refreshToken string) error {
 		}
 		return fmt.Errorf("lookup refresh token: %w", err)
 	}
-	return s.store.RevokeRefreshToken(ctx, rt.ID)
+	if rt.SessionID != "" {
+		err = s.revokeSessions(ctx, rt.UserID, rt.SessionID, "")
+	} else {
+		err = s.store.RevokeRefreshToken(ctx, rt.ID)
+	}
+	if err != nil {
+		return err
+	}
+	user, err := s.store.GetUserByID(ctx, rt.UserID)
+	if err != nil {
+		user = &User{ID: rt.UserID}
+	}
+	s.auditSuccess(ctx, AuditLogout, user, "")
+	return nil
 }
 
 // NeedsSetup returns true if no users exist (first-run state).
@@ -292,8 +414,18 @@ func (s *Service) DeleteUser(ctx context.Context, id string) error {
 	return nil
 }
 
+// issueTokenPair starts a new session for user and issues its first token pair.
 func (s *Service) issueTokenPair(ctx context.Context, user *User) (*TokenPair, error) {
-	accessToken, err := s.tokens.IssueAccessToken(user)
+	sessionID, err := s.startSession(ctx, user, time.Now().Add(s.tokens.RefreshTokenTTL()))
+	if err != nil {
+		return nil, err
+	}
+	return s.issueSessionTokens(ctx, user, sessionID)
+}
+
+// issueSessionTokens issues a token pair within an existing session.
+func (s *Service) issueSessionTokens(ctx context.Context, user *User, sessionID string) (*TokenPair, error) {
+	accessToken, err := s.tokens.IssueSessionAccessToken(user, sessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -304,9 +436,10 @@ func (s *Service) issueTokenPair(ctx context.Context, user *User) (*TokenPair, e
 	}
 
 	tokenID := uuid.New().String()
-	if err := s.store.SaveRefreshToken(ctx, tokenID, user.ID, hashRefresh, expiresAt); err != nil {
+	if err := s.store.SaveRefreshToken(ctx, tokenID, user.ID, sessionID, hashRefresh, expiresAt); err != nil {
 		return nil, fmt.Errorf("save refresh token: %w", err)
 	}
+	_ = s.store.TouchSession(ctx, sessionID, clientInfoFrom(ctx).ip, expiresAt)
 
 	return &TokenPair{
 		AccessToken:  accessToken,
@@ -378,7 +511,13 @@ func (s *Service) VerifyTOTPSetup(ctx context.Context, userID, totpCode string)
 }
 
 // CompleteMFALogin validates a TOTP code against an MFA token and returns a full token pair.
-func (s *Service) CompleteMFALogin(ctx context.Context, mfaToken, totpCode string) (*TokenPair, error) {
+func (s *Service) CompleteMFALogin(ctx context.Context, mfaToken, totpCode string) (pair *TokenPair, err error) {
+	defer func() {
+		if err != nil {
+			s.auditFailure(ctx, AuditLogin, &User{}, err)
+		}
+	}()
+
 	userID, err := s.totp.ValidateMFAToken(mfaToken)
 	if err != nil {
 		return nil, ErrInvalidMFACode
@@ -409,25 +548,39 @@ func (s *Service) CompleteMFALogin(ctx context.Context, mfaToken, totpCode strin
 		return nil, fmt.Errorf("decrypt TOTP secret: %w", err)
 	}
 
+	method := "totp"
 	if !s.totp.Validate(totpCode, secret) {
-		return nil, ErrInvalidMFACode
+		// Accept a recovery code in place of the TOTP code.
+		ok, err := s.store.ConsumeRecoveryCode(ctx, userID, HashToken(normalizeRecoveryCode(totpCode)))
+		if err != nil || !ok {
+			return nil, ErrInvalidMFACode
+		}
+		s.logger.Info("recovery code used in place of TOTP code", zap.String("user_id", userID))
+		method = "recovery_code"
 	}
 
 	// Revoke the MFA token (single use).
 	_ = s.store.RevokeMFAToken(ctx, tokenHash)
 
-	pair, err := s.issueTokenPair(ctx, user)
+	pair, err = s.issueTokenPair(ctx, user)
 	if err != nil {
 		return nil, err
 	}
 
 	_ = s.store.UpdateLastLogin(ctx, user.ID)
 	s.logger.Info("MFA login completed", zap.String("user_id", userID))
+	s.auditSuccess(ctx, AuditLogin, user, method)
 	return pair, nil
 }
 
 // CompleteMFAWithRecovery validates a recovery code against an MFA token and returns a full token pair.
-func (s *Service) CompleteMFAWithRecovery(ctx context.Context, mfaToken, recoveryCode string) (*TokenPair, error) {
+func (s *Service) CompleteMFAWithRecovery(ctx context.Context, mfaToken, recoveryCode string) (pair *TokenPair, err error) {
+	defer func() {
+		if err != nil {
+			s.auditFailure(ctx, AuditLogin, &User{}, err)
+		}
+	}()
+
 	userID, err := s.totp.ValidateMFAToken(mfaToken)
 	if err != nil {
 		return nil, ErrInvalidMFACode
@@ -442,15 +595,11 @@ func (s *Service) CompleteMFAWithRecovery(ctx context.Context, mfaToken, recover
 		return nil, ErrInvalidMFACode
 	}
 
-	codeHash := HashToken(recoveryCode)
-	valid, err := s.store.ValidateRecoveryCode(ctx, userID, codeHash)
+	valid, err := s.store.ConsumeRecoveryCode(ctx, userID, HashToken(normalizeRecoveryCode(recoveryCode)))
 	if err != nil || !valid {
 		return nil, ErrInvalidMFACode
 	}
 
-	// Mark recovery code as used.
-	_ = s.store.MarkRecoveryCodeUsed(ctx, codeHash)
-
 	// Revoke the MFA token.
 	_ = s.store.RevokeMFAToken(ctx, tokenHash)
 
@@ -459,16 +608,59 @@ func (s *Service) CompleteMFAWithRecovery(ctx context.Context, mfaToken, recover
 		return nil, fmt.Errorf("lookup user: %w", err)
 	}
 
-	pair, err := s.issueTokenPair(ctx, user)
+	pair, err = s.issueTokenPair(ctx, user)
 	if err != nil {
 		return nil, err
 	}
 
 	_ = s.store.UpdateLastLogin(ctx, user.ID)
 	s.logger.Info("MFA login completed with recovery code", zap.String("user_id", userID))
+	s.auditSuccess(ctx, AuditLogin, user, "recovery_code")
 	return pair, nil
 }
 
+// RegenerateRecoveryCodes replaces a user's recovery codes after verifying a
+// valid TOTP code, and returns the new plaintext codes. Previously issued
+// codes, used or not, stop working.
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID, totpCode string) ([]string, error) {
+	user, err := s.store.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+	if !user.TOTPEnabled || !user.TOTPVerified {
+		return nil, ErrMFANotEnabled
+	}
+
+	encrypted, err := s.store.GetTOTPSecret(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get TOTP secret: %w", err)
+	}
+	secret, err := s.totp.Decrypt(encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt TOTP secret: %w", err)
+	}
+	if !s.totp.Validate(totpCode, secret) {
+		return nil, ErrInvalidMFACode
+	}
+
+	plain, hashed, err := s.totp.GenerateRecoveryCodes(10)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.SaveRecoveryCodes(ctx, userID, hashed); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("recovery codes regenerated", zap.String("user_id", userID))
+	return plain, nil
+}
+
+// normalizeRecoveryCode trims whitespace and lowercases a user-entered code
+// to match the generated format.
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
+
 // DisableTOTP disables MFA for a user after verifying a valid TOTP code.
 func (s *Service) DisableTOTP(ctx context.Context, userID, totpCode string) error {
 	encrypted, err := s.store.GetTOTPSecret(ctx, userID)
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -295,7 +296,7 @@ func TestLogin_AccountLockout(t *testing.T) {
 
 	// Next login attempt (even with correct password) should be locked.
 	_, err := svc.Login(ctx, "admin", "securepassword")
-	if err != ErrAccountLocked {
+	if !errors.Is(err, ErrAccountLocked) {
 		t.Errorf("Login after lockout: err = %v, want ErrAccountLocked", err)
 	}
 
@@ -325,7 +326,7 @@ func TestLogin_LockoutExpires(t *testing.T) {
 
 	// Verify locked.
 	_, err := svc.Login(ctx, "admin", "securepassword")
-	if err != ErrAccountLocked {
+	if !errors.Is(err, ErrAccountLocked) {
 		t.Fatalf("expected ErrAccountLocked, got %v", err)
 	}
 
@@ -389,7 +390,7 @@ func TestLogin_LockoutPreventsCorrectPassword(t *testing.T) {
 	// Repeated correct password attempts should all fail with ErrAccountLocked.
 	for i := 0; i < 3; i++ {
 		_, err := svc.Login(ctx, "admin", "securepassword")
-		if err != ErrAccountLocked {
+		if !errors.Is(err, ErrAccountLocked) {
 			t.Errorf("attempt %d with correct password: err = %v, want ErrAccountLocked", i+1, err)
 		}
 	}
@@ -0,0 +1,321 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/plugin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ErrSessionNotFound is returned when a session does not exist or belongs
+// to another user.
+var ErrSessionNotFound = errors.New("session not found")
+
+// TopicSessionRevoked is published when one or more of a user's sessions
+// are revoked, so holders of long-lived connections can re-check theirs.
+const TopicSessionRevoked = "auth.session.revoked"
+
+// SessionRevokedEvent is the payload of TopicSessionRevoked. SessionID is
+// empty when several sessions were revoked at once.
+type SessionRevokedEvent struct {
+	UserID    string `json:"user_id"`
+	SessionID string `json:"session_id,omitempty"`
+}
+
+// maxUserAgentLen bounds the stored User-Agent header.
+const maxUserAgentLen = 256
+
+// Session is a login session: the chain of rotated refresh tokens issued
+// from one login. Access tokens carry the session ID so revoking a session
+// also rejects its outstanding access tokens.
+type Session struct {
+	ID         string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserID     string    `json:"-"`
+	UserAgent  string    `json:"user_agent" example:"Mozilla/5.0"`
+	IP         string    `json:"ip" example:"192.168.1.20"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+// clientInfoKey is a context key for the login client's metadata.
+type clientInfoKey struct{}
+
+type clientInfo struct {
+	userAgent string
+	ip        string
+}
+
+// withClientInfo records the request's user agent and address on the context
+// so sessions created while handling it can be identified later.
+func withClientInfo(r *http.Request) context.Context {
+	ua := r.UserAgent()
+	if len(ua) > maxUserAgentLen {
+		ua = ua[:maxUserAgentLen]
+	}
+	return context.WithValue(r.Context(), clientInfoKey{}, clientInfo{userAgent: ua, ip: loginClientIP(r)})
+}
+
+func clientInfoFrom(ctx context.Context) clientInfo {
+	ci, _ := ctx.Value(clientInfoKey{}).(clientInfo)
+	return ci
+}
+
+// SessionActive reports whether a session exists and has not been revoked
+// or expired. Access tokens issued before sessions were tracked carry no
+// session ID and are accepted until they expire.
+func (s *Service) SessionActive(ctx context.Context, sessionID string) bool {
+	if sessionID == "" {
+		return true
+	}
+	sess, err := s.store.GetSession(ctx, sessionID)
+	if err != nil {
+		return false
+	}
+	return sess.ExpiresAt.After(time.Now())
+}
+
+// ListSessions returns the user's active sessions, marking currentID.
+func (s *Service) ListSessions(ctx context.Context, userID, currentID string) ([]Session, error) {
+	sessions, err := s.store.ListActiveSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes one of the user's sessions and its refresh tokens.
+func (s *Service) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	sess, err := s.store.GetSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	if sess.UserID != userID {
+		return ErrSessionNotFound
+	}
+	if err := s.revokeSessions(ctx, userID, sessionID, ""); err != nil {
+		return err
+	}
+	s.logger.Info("session revoked", zap.String("user_id", userID), zap.String("session_id", sessionID))
+	return nil
+}
+
+// RevokeOtherSessions revokes all of the user's sessions except keepID.
+func (s *Service) RevokeOtherSessions(ctx context.Context, userID, keepID string) error {
+	if err := s.revokeSessions(ctx, userID, "", keepID); err != nil {
+		return err
+	}
+	s.logger.Info("other sessions revoked", zap.String("user_id", userID), zap.String("kept_session_id", keepID))
+	return nil
+}
+
+// revokeSessions revokes sessions as UserStore.RevokeSessions does and
+// publishes TopicSessionRevoked.
+func (s *Service) revokeSessions(ctx context.Context, userID, onlyID, exceptID string) error {
+	if err := s.store.RevokeSessions(ctx, userID, onlyID, exceptID); err != nil {
+		return err
+	}
+	if s.bus == nil {
+		return nil
+	}
+	if err := s.bus.Publish(ctx, plugin.Event{
+		Topic:     TopicSessionRevoked,
+		Source:    "auth",
+		Timestamp: time.Now(),
+		Payload:   &SessionRevokedEvent{UserID: userID, SessionID: onlyID},
+	}); err != nil {
+		s.logger.Warn("failed to publish session revocation", zap.Error(err))
+	}
+	return nil
+}
+
+// startSession records a new session for user using client metadata from ctx.
+func (s *Service) startSession(ctx context.Context, user *User, expiresAt time.Time) (string, error) {
+	ci := clientInfoFrom(ctx)
+	now := time.Now().UTC()
+	sess := &Session{
+		ID:         uuid.New().String(),
+		UserID:     user.ID,
+		UserAgent:  ci.userAgent,
+		IP:         ci.ip,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  expiresAt,
+	}
+	if err := s.store.CreateSession(ctx, sess); err != nil {
+		return "", fmt.Errorf("create session: %w", err)
+	}
+	return sess.ID, nil
+}
+
+// CreateSession inserts a session.
+func (s *UserStore) CreateSession(ctx context.Context, sess *Session) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO auth_sessions (id, user_id, user_agent, ip, created_at, last_seen_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		sess.ID, sess.UserID, sess.UserAgent, sess.IP, sess.CreatedAt, sess.LastSeenAt, sess.ExpiresAt)
+	return err
+}
+
+// GetSession returns an unrevoked session by ID.
+func (s *UserStore) GetSession(ctx context.Context, id string) (*Session, error) {
+	var sess Session
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, user_id, user_agent, ip, created_at, last_seen_at, expires_at
+		FROM auth_sessions WHERE id = ? AND revoked_at IS NULL`, id,
+	).Scan(&sess.ID, &sess.UserID, &sess.UserAgent, &sess.IP, &sess.CreatedAt, &sess.LastSeenAt, &sess.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &sess, nil
+}
+
+// ListActiveSessions returns the user's unrevoked, unexpired sessions, most
+// recently used first.
+func (s *UserStore) ListActiveSessions(ctx context.Context, userID string) ([]Session, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, user_id, user_agent, ip, created_at, last_seen_at, expires_at
+		FROM auth_sessions
+		WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ?
+		ORDER BY last_seen_at DESC`, userID, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		var sess Session
+		if err := rows.Scan(&sess.ID, &sess.UserID, &sess.UserAgent, &sess.IP,
+			&sess.CreatedAt, &sess.LastSeenAt, &sess.ExpiresAt); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, sess)
+	}
+	return sessions, rows.Err()
+}
+
+// TouchSession records a refresh on a session and extends its expiry.
+func (s *UserStore) TouchSession(ctx context.Context, id, ip string, expiresAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE auth_sessions SET last_seen_at = ?, expires_at = ?, ip = CASE WHEN ? = '' THEN ip ELSE ? END
+		WHERE id = ?`,
+		time.Now().UTC(), expiresAt, ip, ip, id)
+	return err
+}
+
+// RevokeSessions revokes the user's sessions and their refresh tokens. If
+// onlyID is set, only that session is revoked; otherwise all sessions except
+// exceptID are revoked.
+func (s *UserStore) RevokeSessions(ctx context.Context, userID, onlyID, exceptID string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	filter := `user_id = ? AND (? = '' OR id = ?) AND id != ?`
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE auth_sessions SET revoked_at = ? WHERE revoked_at IS NULL AND `+filter,
+		time.Now().UTC(), userID, onlyID, onlyID, exceptID); err != nil {
+		return fmt.Errorf("revoke sessions: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE auth_refresh_tokens SET revoked = 1 WHERE session_id IN (SELECT id FROM auth_sessions WHERE `+filter+`)`,
+		userID, onlyID, onlyID, exceptID); err != nil {
+		return fmt.Errorf("revoke session refresh tokens: %w", err)
+	}
+	return tx.Commit()
+}
+
+// handleListSessions lists the authenticated user's active sessions.
+//
+//	@Summary		List sessions
+//	@Description	Returns the authenticated user's active login sessions.
+//	@Tags			auth
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{array}		Session
+//	@Failure		401	{object}	models.APIProblem
+//	@Failure		500	{object}	models.APIProblem
+//	@Router			/auth/sessions [get]
+func (h *Handler) handleListSessions(w http.ResponseWriter, r *http.Request) {
+	claims := UserFromContext(r.Context())
+	if claims == nil {
+		writeAuthError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	sessions, err := h.service.ListSessions(r.Context(), claims.UserID, claims.SessionID)
+	if err != nil {
+		h.logger.Error("list sessions error", zap.Error(err))
+		writeAuthError(w, http.StatusInternalServerError, "failed to list sessions")
+		return
+	}
+	writeJSON(w, http.StatusOK, sessions)
+}
+
+// handleRevokeSession revokes one of the authenticated user's sessions.
+//
+//	@Summary		Revoke session
+//	@Description	Revoke a login session. Its refresh token stops working and its access tokens are rejected.
+//	@Tags			auth
+//	@Security		BearerAuth
+//	@Param			id	path	string	true	"Session ID"
+//	@Success		204	"No Content"
+//	@Failure		401	{object}	models.APIProblem
+//	@Failure		404	{object}	models.APIProblem
+//	@Router			/auth/sessions/{id} [delete]
+func (h *Handler) handleRevokeSession(w http.ResponseWriter, r *http.Request) {
+	claims := UserFromContext(r.Context())
+	if claims == nil {
+		writeAuthError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	if err := h.service.RevokeSession(r.Context(), claims.UserID, r.PathValue("id")); err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			writeAuthError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h.logger.Error("revoke session error", zap.Error(err))
+		writeAuthError(w, http.StatusInternalServerError, "failed to revoke session")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRevokeOtherSessions revokes all sessions except the caller's own.
+//
+//	@Summary		Revoke other sessions
+//	@Description	Revoke every login session of the authenticated user except the current one.
+//	@Tags			auth
+//	@Security		BearerAuth
+//	@Success		204	"No Content"
+//	@Failure		401	{object}	models.APIProblem
+//	@Failure		500	{object}	models.APIProblem
+//	@Router			/auth/sessions [delete]
+func (h *Handler) handleRevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	claims := UserFromContext(r.Context())
+	if claims == nil {
+		writeAuthError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	if err := h.service.RevokeOtherSessions(r.Context(), claims.UserID, claims.SessionID); err != nil {
+		h.logger.Error("revoke other sessions error", zap.Error(err))
+		writeAuthError(w, http.StatusInternalServerError, "failed to revoke sessions")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// loginWithClient logs in as if from a request with the given user agent and address.
+func loginWithClient(t *testing.T, svc *Service, userAgent, remoteAddr string) *TokenPair {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", http.NoBody)
+	req.Header.Set("User-Agent", userAgent)
+	req.RemoteAddr = remoteAddr
+	result, err := svc.Login(withClientInfo(req), "admin", "securepassword")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	return result.Pair
+}
+
+func sessionIDOf(t *testing.T, svc *Service, pair *TokenPair) string {
+	t.Helper()
+	claims, err := svc.Tokens().ValidateAccessToken(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken: %v", err)
+	}
+	if claims.SessionID == "" {
+		t.Fatal("access token has no session ID")
+	}
+	return claims.SessionID
+}
+
+func TestListSessions(t *testing.T) {
+	_, _, svc := testEnv(t)
+	ctx := context.Background()
+	admin, err := svc.Setup(ctx, "admin", "admin@example.com", "securepassword")
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+
+	laptop := loginWithClient(t, svc, "Firefox", "192.168.1.20:5555")
+	loginWithClient(t, svc, "curl/8.0", "192.168.1.30:4444")
+	current := sessionIDOf(t, svc, laptop)
+
+	sessions, err := svc.ListSessions(ctx, admin.ID, current)
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("sessions = %d, want 2", len(sessions))
+	}
+	for _, s := range sessions {
+		if s.ID == current {
+			if !s.Current || s.UserAgent != "Firefox" || s.IP != "192.168.1.20" {
+				t.Errorf("current session = %+v", s)
+			}
+		} else if s.Current || s.UserAgent != "curl/8.0" {
+			t.Errorf("other session = %+v", s)
+		}
+	}
+}
+
+func TestRevokeSession_RejectsTokens(t *testing.T) {
+	_, _, svc := testEnv(t)
+	ctx := context.Background()
+	admin, err := svc.Setup(ctx, "admin", "admin@example.com", "securepassword")
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	pair := loginWithClient(t, svc, "Firefox", "192.168.1.20:5555")
+	sid := sessionIDOf(t, svc, pair)
+
+	handler := AuthMiddlewareWithBackend(svc.Tokens(), svc)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	do := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/recon/devices", http.NoBody)
+		req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+	if code := do(); code != http.StatusOK {
+		t.Fatalf("before revoke = %d, want 200", code)
+	}
+
+	if err := svc.RevokeSession(ctx, "someone-else", sid); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("revoke other user's session err = %v, want ErrSessionNotFound", err)
+	}
+	if err := svc.RevokeSession(ctx, admin.ID, sid); err != nil {
+		t.Fatalf("RevokeSession: %v", err)
+	}
+	if code := do(); code != http.StatusUnauthorized {
+		t.Errorf("after revoke = %d, want 401", code)
+	}
+	if _, err := svc.Refresh(ctx, pair.RefreshToken); err == nil {
+		t.Error("refresh after revoke succeeded, want error")
+	}
+}
+
+func TestRefresh_KeepsSession(t *testing.T) {
+	_, _, svc := testEnv(t)
+	ctx := context.Background()
+	if _, err := svc.Setup(ctx, "admin", "admin@example.com", "securepassword"); err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	pair := loginWithClient(t, svc, "Firefox", "192.168.1.20:5555")
+	sid := sessionIDOf(t, svc, pair)
+
+	refreshed, err := svc.Refresh(ctx, pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if got := sessionIDOf(t, svc, refreshed); got != sid {
+		t.Errorf("session after refresh = %s, want %s", got, sid)
+	}
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	_, _, svc := testEnv(t)
+	ctx := context.Background()
+	admin, err := svc.Setup(ctx, "admin", "admin@example.com", "securepassword")
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	keep := loginWithClient(t, svc, "Firefox", "192.168.1.20:5555")
+	other := loginWithClient(t, svc, "curl/8.0", "192.168.1.30:4444")
+	keepID := sessionIDOf(t, svc, keep)
+
+	if err := svc.RevokeOtherSessions(ctx, admin.ID, keepID); err != nil {
+		t.Fatalf("RevokeOtherSessions: %v", err)
+	}
+	sessions, err := svc.ListSessions(ctx, admin.ID, keepID)
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != keepID {
+		t.Errorf("sessions = %+v, want only %s", sessions, keepID)
+	}
+	if _, err := svc.Refresh(ctx, other.RefreshToken); err == nil {
+		t.Error("refresh of revoked session succeeded, want error")
+	}
+	if _, err := svc.Refresh(ctx, keep.RefreshToken); err != nil {
+		t.Errorf("refresh of kept session: %v", err)
+	}
+}
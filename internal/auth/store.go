@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -50,6 +51,12 @@ func (s *UserStore) GetUserByUsername(ctx context.Context, username string) (*Us
 		`SELECT `+userColumns+` FROM auth_users WHERE username = ?`, username))
 }
 
+// GetUserByOIDCSubject returns the user linked to an OIDC subject.
+func (s *UserStore) GetUserByOIDCSubject(ctx context.Context, subject string) (*User, error) {
+	return s.scanUser(s.db.QueryRowContext(ctx,
+		`SELECT `+userColumns+` FROM auth_users WHERE auth_provider = 'oidc' AND oidc_subject = ?`, subject))
+}
+
 // ListUsers returns all users.
 func (s *UserStore) ListUsers(ctx context.Context) ([]User, error) {
 	rows, err := s.db.QueryContext(ctx,
@@ -111,12 +118,12 @@ func (s *UserStore) CountUsers(ctx context.Context) (int, error) {
 	return count, err
 }
 
-// SaveRefreshToken stores a hashed refresh token.
-func (s *UserStore) SaveRefreshToken(ctx context.Context, id, userID, tokenHash string, expiresAt time.Time) error {
+// SaveRefreshToken stores a hashed refresh token belonging to a session.
+func (s *UserStore) SaveRefreshToken(ctx context.Context, id, userID, sessionID, tokenHash string, expiresAt time.Time) error {
 	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO auth_refresh_tokens (id, user_id, token_hash, expires_at, created_at)
-		VALUES (?, ?, ?, ?, ?)`,
-		id, userID, tokenHash, expiresAt, time.Now().UTC(),
+		INSERT INTO auth_refresh_tokens (id, user_id, session_id, token_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		id, userID, sessionID, tokenHash, expiresAt, time.Now().UTC(),
 	)
 	return err
 }
@@ -124,13 +131,15 @@ func (s *UserStore) SaveRefreshToken(ctx context.Context, id, userID, tokenHash
 // GetRefreshToken looks up a refresh token by its hash.
 func (s *UserStore) GetRefreshToken(ctx context.Context, tokenHash string) (*RefreshToken, error) {
 	var rt RefreshToken
+	var sessionID sql.NullString
 	err := s.db.QueryRowContext(ctx, `
-		SELECT id, user_id, token_hash, expires_at, created_at, revoked
+		SELECT id, user_id, session_id, token_hash, expires_at, created_at, revoked
 		FROM auth_refresh_tokens WHERE token_hash = ?`, tokenHash,
-	).Scan(&rt.ID, &rt.UserID, &rt.TokenHash, &rt.ExpiresAt, &rt.CreatedAt, &rt.Revoked)
+	).Scan(&rt.ID, &rt.UserID, &sessionID, &rt.TokenHash, &rt.ExpiresAt, &rt.CreatedAt, &rt.Revoked)
 	if err != nil {
 		return nil, err
 	}
+	rt.SessionID = sessionID.String
 	return &rt, nil
 }
 
@@ -161,6 +170,7 @@ func (s *UserStore) CleanExpiredTokens(ctx context.Context) error {
 type RefreshToken struct {
 	ID        string
 	UserID    string
+	SessionID string
 	TokenHash string
 	ExpiresAt time.Time
 	CreatedAt time.Time
@@ -353,6 +363,191 @@ var migrations = []plugin.Migration{
 			return err
 		},
 	},
+	{
+		Version:     5,
+		Description: "create auth_api_keys table",
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`
+				CREATE TABLE auth_api_keys (
+					id           TEXT PRIMARY KEY,
+					user_id      TEXT NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+					name         TEXT NOT NULL,
+					prefix       TEXT NOT NULL,
+					key_hash     TEXT NOT NULL UNIQUE,
+					scopes       TEXT NOT NULL DEFAULT '[]',
+					created_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					last_used_at DATETIME,
+					revoked_at   DATETIME
+				)`)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(`CREATE INDEX idx_api_keys_user ON auth_api_keys(user_id)`)
+			return err
+		},
+	},
+	{
+		Version:     6,
+		Description: "create auth_roles table with built-in roles",
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`
+				CREATE TABLE auth_roles (
+					name        TEXT PRIMARY KEY,
+					description TEXT NOT NULL DEFAULT '',
+					permissions TEXT NOT NULL DEFAULT '{}',
+					built_in    INTEGER NOT NULL DEFAULT 0,
+					created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+				)`)
+			if err != nil {
+				return err
+			}
+			for _, d := range DefaultRoleDefinitions() {
+				perms, err := json.Marshal(d.Permissions)
+				if err != nil {
+					return err
+				}
+				if _, err := tx.Exec(
+					`INSERT INTO auth_roles (name, description, permissions, built_in) VALUES (?, ?, ?, 1)`,
+					string(d.Name), d.Description, string(perms)); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     7,
+		Description: "create auth_login_failures table",
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`
+				CREATE TABLE auth_login_failures (
+					ip           TEXT PRIMARY KEY,
+					attempts     INTEGER NOT NULL DEFAULT 0,
+					locked_until DATETIME,
+					updated_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+				)`)
+			return err
+		},
+	},
+	{
+		Version:     8,
+		Description: "create auth_sessions table and link refresh tokens",
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`
+				CREATE TABLE auth_sessions (
+					id           TEXT PRIMARY KEY,
+					user_id      TEXT NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+					user_agent   TEXT NOT NULL DEFAULT '',
+					ip           TEXT NOT NULL DEFAULT '',
+					created_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					last_seen_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					expires_at   DATETIME NOT NULL,
+					revoked_at   DATETIME
+				)`)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(`CREATE INDEX idx_sessions_user ON auth_sessions(user_id)`)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(`ALTER TABLE auth_refresh_tokens ADD COLUMN session_id TEXT`)
+			return err
+		},
+	},
+	{
+		Version:     9,
+		Description: "create webauthn credential and ceremony tables",
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`
+				CREATE TABLE auth_webauthn_credentials (
+					id            TEXT PRIMARY KEY,
+					user_id       TEXT NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+					name          TEXT NOT NULL,
+					credential_id TEXT NOT NULL UNIQUE,
+					credential    TEXT NOT NULL,
+					created_at    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					last_used_at  DATETIME
+				)`)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(`CREATE INDEX idx_webauthn_credentials_user ON auth_webauthn_credentials(user_id)`)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(`
+				CREATE TABLE auth_webauthn_sessions (
+					id         TEXT PRIMARY KEY,
+					user_id    TEXT NOT NULL DEFAULT '',
+					data       TEXT NOT NULL,
+					expires_at DATETIME NOT NULL
+				)`)
+			return err
+		},
+	},
+	{
+		Version:     10,
+		Description: "create password reset token table",
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`
+				CREATE TABLE auth_password_resets (
+					token_hash TEXT PRIMARY KEY,
+					user_id    TEXT NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+					expires_at DATETIME NOT NULL,
+					created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+				)`)
+			return err
+		},
+	},
+	{
+		Version:     11,
+		Description: "create auth audit log table",
+		Up: func(tx *sql.Tx) error {
+			// No foreign key on user_id: entries outlive deleted users.
+			_, err := tx.Exec(`
+				CREATE TABLE auth_audit (
+					id         INTEGER PRIMARY KEY AUTOINCREMENT,
+					event_type TEXT NOT NULL,
+					outcome    TEXT NOT NULL,
+					user_id    TEXT NOT NULL DEFAULT '',
+					username   TEXT NOT NULL DEFAULT '',
+					ip         TEXT NOT NULL DEFAULT '',
+					user_agent TEXT NOT NULL DEFAULT '',
+					detail     TEXT NOT NULL DEFAULT '',
+					timestamp  DATETIME NOT NULL
+				)`)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(`CREATE INDEX idx_auth_audit_timestamp ON auth_audit(timestamp)`)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(`CREATE INDEX idx_auth_audit_user ON auth_audit(user_id)`)
+			return err
+		},
+	},
+	{
+		Version:     12,
+		Description: "resync built-in role permissions",
+		Up: func(tx *sql.Tx) error {
+			// Built-in roles cannot be edited, so overwriting them only
+			// picks up changed defaults (viewer no longer reads vault).
+			for _, d := range DefaultRoleDefinitions() {
+				perms, err := json.Marshal(d.Permissions)
+				if err != nil {
+					return err
+				}
+				if _, err := tx.Exec(
+					`UPDATE auth_roles SET description = ?, permissions = ? WHERE name = ? AND built_in = 1`,
+					d.Description, string(perms), string(d.Name)); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
 }
 
 // GetTOTPSecret returns the encrypted TOTP secret for a user.
@@ -439,6 +634,31 @@ func (s *UserStore) ValidateRecoveryCode(ctx context.Context, userID, codeHash s
 	return count > 0, nil
 }
 
+// ConsumeRecoveryCode atomically marks an unused recovery code of the user as
+// used. It returns false if the code does not exist or was already used, so
+// concurrent attempts with the same code cannot both succeed.
+func (s *UserStore) ConsumeRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE auth_recovery_codes SET used = 1 WHERE user_id = ? AND code_hash = ? AND used = 0`,
+		userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("consume recovery code: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// CountUnusedRecoveryCodes returns the number of unused recovery codes for a user.
+func (s *UserStore) CountUnusedRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM auth_recovery_codes WHERE user_id = ? AND used = 0`, userID).Scan(&n)
+	return n, err
+}
+
 // MarkRecoveryCodeUsed marks a recovery code as used.
 func (s *UserStore) MarkRecoveryCodeUsed(ctx context.Context, codeHash string) error {
 	_, err := s.db.ExecContext(ctx,
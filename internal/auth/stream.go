@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrStreamForbidden is returned when a credential is valid but may not
+// open an event stream.
+var ErrStreamForbidden = errors.New("credential does not permit event streaming")
+
+// StreamAccess is what an authenticated event stream client may see. A
+// stream is authenticated once, when it opens, so the handler uses this to
+// keep applying the rules AuthMiddlewareWithBackend and RBACMiddleware apply
+// to each ordinary request.
+type StreamAccess struct {
+	Claims *Claims
+	// ExpiresAt is when an access token expires. It is zero for API keys.
+	ExpiresAt time.Time
+
+	raw     string
+	key     *APIKey
+	backend CredentialBackend
+	roles   RoleResolver
+
+	mu   sync.RWMutex
+	role *RoleDefinition
+}
+
+// AuthenticateStream authenticates an event stream client from raw, an
+// access token or, when backend is non-nil, an API key. Access tokens of
+// revoked sessions are rejected. roles may be nil to use the built-in
+// role definitions.
+func AuthenticateStream(ctx context.Context, tokens *TokenService, backend CredentialBackend, roles RoleResolver, raw string) (*StreamAccess, error) {
+	if roles == nil {
+		roles = staticRoles(DefaultRoleDefinitions())
+	}
+	a := &StreamAccess{raw: raw, backend: backend, roles: roles}
+
+	if backend != nil && strings.HasPrefix(raw, APIKeyPrefix) {
+		claims, key, err := backend.AuthenticateAPIKey(ctx, raw)
+		if err != nil {
+			return nil, err
+		}
+		a.Claims, a.key = claims, key
+	} else {
+		claims, err := tokens.ValidateAccessToken(raw)
+		if err != nil {
+			return nil, err
+		}
+		if backend != nil && !backend.SessionActive(ctx, claims.SessionID) {
+			return nil, ErrSessionNotFound
+		}
+		a.Claims = claims
+		if claims.ExpiresAt != nil {
+			a.ExpiresAt = claims.ExpiresAt.Time
+		}
+	}
+
+	role, err := roles.GetRole(ctx, Role(a.Claims.Role))
+	if err != nil {
+		return nil, ErrStreamForbidden
+	}
+	a.role = role
+	return a, nil
+}
+
+// RequestAccess returns the topic access of a request already authenticated
+// by AuthMiddlewareWithBackend, so handlers serving stored events apply the
+// same per-topic rules as an event stream. roles may be nil to use the
+// built-in role definitions.
+func RequestAccess(r *http.Request, roles RoleResolver) (*StreamAccess, error) {
+	claims := UserFromContext(r.Context())
+	if claims == nil {
+		return nil, ErrStreamForbidden
+	}
+	if roles == nil {
+		roles = staticRoles(DefaultRoleDefinitions())
+	}
+	role, err := roles.GetRole(r.Context(), Role(claims.Role))
+	if err != nil {
+		return nil, ErrStreamForbidden
+	}
+	key, _ := r.Context().Value(authAPIKeyKey{}).(*APIKey)
+	return &StreamAccess{Claims: claims, key: key, roles: roles, role: role}, nil
+}
+
+// CanReadTopic reports whether the client may receive events on topic. A
+// topic's first segment names its module, which the client's role, and an
+// API key's scopes, must allow it to read. Auth events are for admins only,
+// matching the auth and user management routes.
+func (a *StreamAccess) CanReadTopic(topic string) bool {
+	module, _, _ := strings.Cut(topic, ".")
+	if module == "auth" || module == "users" {
+		return a.key == nil && Role(a.Claims.Role) == RoleAdmin
+	}
+	if a.key != nil && !a.key.allows(http.MethodGet, "/api/v1/"+module+"/") {
+		return false
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.role.Allows(module, false)
+}
+
+// Active re-checks the credential: it reports false once the access token
+// has expired, its session or the API key has been revoked, or the role no
+// longer exists. The role is reloaded so permission changes apply to an
+// open stream.
+func (a *StreamAccess) Active(ctx context.Context) bool {
+	if !a.ExpiresAt.IsZero() && !time.Now().Before(a.ExpiresAt) {
+		return false
+	}
+	if a.backend != nil {
+		if a.key != nil {
+			if _, _, err := a.backend.AuthenticateAPIKey(ctx, a.raw); err != nil {
+				return false
+			}
+		} else if !a.backend.SessionActive(ctx, a.Claims.SessionID) {
+			return false
+		}
+	}
+	role, err := a.roles.GetRole(ctx, Role(a.Claims.Role))
+	if err != nil {
+		return false
+	}
+	a.mu.Lock()
+	a.role = role
+	a.mu.Unlock()
+	return true
+}
@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAuthenticateStream_AccessToken(t *testing.T) {
+	_, _, svc := testEnv(t)
+	ctx := context.Background()
+	if _, err := svc.Setup(ctx, "admin", "admin@example.com", "securepassword"); err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	pub := &recordingPublisher{}
+	svc.SetEventBus(pub)
+	pair := loginWithClient(t, svc, "Firefox", "192.168.1.20:5555")
+
+	access, err := AuthenticateStream(ctx, svc.Tokens(), svc, svc, pair.AccessToken)
+	if err != nil {
+		t.Fatalf("AuthenticateStream: %v", err)
+	}
+	if access.ExpiresAt.IsZero() {
+		t.Error("ExpiresAt not set for an access token")
+	}
+	if !access.CanReadTopic(TopicLoginFailed) || !access.CanReadTopic("vault.credential.created") {
+		t.Error("admin should read auth and vault topics")
+	}
+	if !access.Active(ctx) {
+		t.Fatal("Active = false for a live session")
+	}
+
+	sessionID := sessionIDOf(t, svc, pair)
+	if err := svc.RevokeSession(ctx, access.Claims.UserID, sessionID); err != nil {
+		t.Fatalf("RevokeSession: %v", err)
+	}
+	if access.Active(ctx) {
+		t.Error("Active = true after the session was revoked")
+	}
+	if _, err := AuthenticateStream(ctx, svc.Tokens(), svc, svc, pair.AccessToken); err == nil {
+		t.Error("expected a revoked session's token to be rejected")
+	}
+
+	var revoked *SessionRevokedEvent
+	for _, ev := range pub.events {
+		if ev.Topic == TopicSessionRevoked {
+			revoked, _ = ev.Payload.(*SessionRevokedEvent)
+		}
+	}
+	if revoked == nil || revoked.UserID != access.Claims.UserID || revoked.SessionID != sessionID {
+		t.Errorf("session revoked event = %+v, want user %s session %s", revoked, access.Claims.UserID, sessionID)
+	}
+}
+
+func TestAuthenticateStream_APIKey(t *testing.T) {
+	_, _, svc := testEnv(t)
+	ctx := context.Background()
+	admin, err := svc.Setup(ctx, "admin", "admin@example.com", "securepassword")
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	key, raw, err := svc.CreateAPIKey(ctx, admin.ID, "dashboard", []string{ScopeRead, "pulse"})
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+
+	if _, err := AuthenticateStream(ctx, svc.Tokens(), nil, nil, raw); err == nil {
+		t.Error("expected API keys to be rejected without a credential backend")
+	}
+	access, err := AuthenticateStream(ctx, svc.Tokens(), svc, svc, raw)
+	if err != nil {
+		t.Fatalf("AuthenticateStream: %v", err)
+	}
+	tests := []struct {
+		topic string
+		want  bool
+	}{
+		{"pulse.alert.triggered", true},
+		{"recon.device.discovered", false}, // outside the key's module scope
+		{TopicLoginSucceeded, false},       // API keys never see auth events
+	}
+	for _, tt := range tests {
+		if got := access.CanReadTopic(tt.topic); got != tt.want {
+			t.Errorf("CanReadTopic(%q) = %v, want %v", tt.topic, got, tt.want)
+		}
+	}
+
+	if err := svc.RevokeAPIKey(ctx, admin.ID, true, key.ID); err != nil {
+		t.Fatalf("RevokeAPIKey: %v", err)
+	}
+	if access.Active(ctx) {
+		t.Error("Active = true after the API key was revoked")
+	}
+}
+
+func TestStreamAccess_RoleTopics(t *testing.T) {
+	tokens := NewTokenService([]byte("test-secret-for-stream-access-0123456789"), time.Minute, time.Hour)
+	ctx := context.Background()
+
+	tests := []struct {
+		role  Role
+		topic string
+		want  bool
+	}{
+		{RoleViewer, "recon.device.discovered", true},
+		{RoleViewer, "vault.credential.created", false},
+		{RoleViewer, TopicLoginFailed, false},
+		{RoleOperator, "vault.credential.created", true},
+		{RoleOperator, TopicAccountLocked, false},
+		{RoleAdmin, TopicAccountLocked, true},
+	}
+	for _, tt := range tests {
+		token, err := tokens.IssueAccessToken(&User{ID: "u1", Username: "someone", Role: tt.role})
+		if err != nil {
+			t.Fatal(err)
+		}
+		access, err := AuthenticateStream(ctx, tokens, nil, nil, token)
+		if err != nil {
+			t.Fatalf("AuthenticateStream(%s): %v", tt.role, err)
+		}
+		if got := access.CanReadTopic(tt.topic); got != tt.want {
+			t.Errorf("%s CanReadTopic(%q) = %v, want %v", tt.role, tt.topic, got, tt.want)
+		}
+	}
+}
@@ -38,8 +38,9 @@ type SetupStatusResponse struct {
 
 // MFAChallengeResponse is returned when MFA verification is required after password auth.
 type MFAChallengeResponse struct {
-	MFARequired bool   `json:"mfa_required" example:"true"`
-	MFAToken    string `json:"mfa_token" example:"eyJhbG..."`
+	MFARequired bool     `json:"mfa_required" example:"true"`
+	MFAToken    string   `json:"mfa_token" example:"eyJhbG..."`
+	MFAMethods  []string `json:"mfa_methods,omitempty" example:"totp,webauthn"`
 }
 
 // MFAVerifyRequest is the request body for POST /auth/mfa/verify.
@@ -65,6 +66,17 @@ type MFAVerifySetupRequest struct {
 	TOTPCode string `json:"totp_code" example:"123456"`
 }
 
+// RecoveryCodesRequest is the request body for POST /auth/totp/recovery-codes.
+type RecoveryCodesRequest struct {
+	TOTPCode string `json:"totp_code" example:"123456"`
+}
+
+// RecoveryCodesResponse is the response from POST /auth/totp/recovery-codes.
+// The plaintext codes are returned only once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
 // MFADisableRequest is the request body for POST /auth/mfa/disable.
 type MFADisableRequest struct {
 	TOTPCode string `json:"totp_code" example:"123456"`
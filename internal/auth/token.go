@@ -16,6 +16,9 @@ type Claims struct {
 	UserID   string `json:"uid"`
 	Username string `json:"usr"`
 	Role     string `json:"role"`
+	// SessionID ties the token to a revocable login session (empty for
+	// tokens issued before sessions were tracked).
+	SessionID string `json:"sid,omitempty"`
 }
 
 // TokenService handles JWT access tokens and refresh token generation.
@@ -36,6 +39,11 @@ func NewTokenService(secret []byte, accessTTL, refreshTTL time.Duration) *TokenS
 
 // IssueAccessToken generates a signed JWT access token for the given user.
 func (s *TokenService) IssueAccessToken(user *User) (string, error) {
+	return s.IssueSessionAccessToken(user, "")
+}
+
+// IssueSessionAccessToken generates a signed JWT access token bound to a session.
+func (s *TokenService) IssueSessionAccessToken(user *User, sessionID string) (string, error) {
 	now := time.Now()
 	claims := Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
@@ -44,9 +52,10 @@ func (s *TokenService) IssueAccessToken(user *User) (string, error) {
 			ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTokenTTL)),
 			Issuer:    "subnetree",
 		},
-		UserID:   user.ID,
-		Username: user.Username,
-		Role:     string(user.Role),
+		UserID:    user.ID,
+		Username:  user.Username,
+		Role:      string(user.Role),
+		SessionID: sessionID,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
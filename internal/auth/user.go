@@ -16,7 +16,8 @@ const (
 	RoleViewer   Role = "viewer"
 )
 
-// ValidRoles contains all valid role values.
+// ValidRoles contains the built-in role values. Custom roles are stored in
+// auth_roles; see RoleDefinition.
 var ValidRoles = map[Role]bool{
 	RoleAdmin:    true,
 	RoleOperator: true,
@@ -0,0 +1,860 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// WebAuthn errors.
+var (
+	ErrWebAuthnDisabled   = errors.New("passkey login is not configured")
+	ErrPasskeyNotFound    = errors.New("passkey not found")
+	ErrInvalidPasskey     = errors.New("passkey verification failed")
+	ErrInvalidPasskeyName = errors.New("name is required (max 64 characters)")
+)
+
+// webauthnCeremonyTTL bounds how long a registration or login challenge may
+// be answered when the library does not set its own expiry.
+const webauthnCeremonyTTL = 5 * time.Minute
+
+// WebAuthnConfig holds passkey (WebAuthn) settings.
+type WebAuthnConfig struct {
+	Enabled       bool   `mapstructure:"enabled"`
+	RPID          string `mapstructure:"rp_id"`           // Host name the passkeys are bound to, e.g. subnetree.example.com
+	RPDisplayName string `mapstructure:"rp_display_name"` // Shown by the authenticator during registration
+	// RPOrigins are the browser origins allowed to perform ceremonies,
+	// e.g. https://subnetree.example.com:8080.
+	RPOrigins []string `mapstructure:"rp_origins"`
+}
+
+// DefaultWebAuthnConfig returns WebAuthn defaults. Passkeys are disabled
+// until an RP ID and origin are configured.
+func DefaultWebAuthnConfig() WebAuthnConfig {
+	return WebAuthnConfig{RPDisplayName: "SubNetree"}
+}
+
+// NewWebAuthn validates cfg and returns a relying party for passkey ceremonies.
+func NewWebAuthn(cfg WebAuthnConfig) (*webauthn.WebAuthn, error) {
+	if cfg.RPID == "" || len(cfg.RPOrigins) == 0 {
+		return nil, errors.New("webauthn rp_id and rp_origins are required")
+	}
+	name := cfg.RPDisplayName
+	if name == "" {
+		name = DefaultWebAuthnConfig().RPDisplayName
+	}
+	return webauthn.New(&webauthn.Config{
+		RPID:          cfg.RPID,
+		RPDisplayName: name,
+		RPOrigins:     cfg.RPOrigins,
+	})
+}
+
+// Passkey is a registered WebAuthn authenticator. The public key and
+// signature counter are stored but never serialized.
+type Passkey struct {
+	ID         string     `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserID     string     `json:"-"`
+	Name       string     `json:"name" example:"YubiKey 5C"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+
+	credential webauthn.Credential
+}
+
+// WebAuthnStatusResponse reports whether passkey login is available.
+type WebAuthnStatusResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+// WebAuthnBeginResponse starts a registration or login ceremony. Options is
+// passed to navigator.credentials.create() or .get() as-is; SessionID must be
+// sent back with the authenticator's response.
+type WebAuthnBeginResponse struct {
+	SessionID string          `json:"session_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Options   json.RawMessage `json:"options" swaggertype:"object"`
+}
+
+// PasskeyRegisterFinishRequest is the request body for POST /auth/webauthn/register/finish.
+type PasskeyRegisterFinishRequest struct {
+	SessionID  string          `json:"session_id"`
+	Name       string          `json:"name" example:"YubiKey 5C"`
+	Credential json.RawMessage `json:"credential" swaggertype:"object"`
+}
+
+// PasskeyLoginBeginRequest is the request body for POST /auth/webauthn/login/begin.
+// Without an MFA token the login is passwordless and any discoverable
+// passkey is accepted; with one, the passkey completes a password login in
+// place of a TOTP code.
+type PasskeyLoginBeginRequest struct {
+	MFAToken string `json:"mfa_token,omitempty" example:"eyJhbG..."`
+}
+
+// PasskeyLoginFinishRequest is the request body for POST /auth/webauthn/login/finish.
+type PasskeyLoginFinishRequest struct {
+	SessionID  string          `json:"session_id"`
+	MFAToken   string          `json:"mfa_token,omitempty" example:"eyJhbG..."`
+	Credential json.RawMessage `json:"credential" swaggertype:"object"`
+}
+
+// RenamePasskeyRequest is the request body for PUT /auth/webauthn/credentials/{id}.
+type RenamePasskeyRequest struct {
+	Name string `json:"name" example:"Phone"`
+}
+
+// webauthnUser adapts a User and its passkeys to webauthn.User. The user
+// handle is the user ID, so discoverable logins resolve without a username.
+type webauthnUser struct {
+	user     *User
+	passkeys []Passkey
+}
+
+func (u *webauthnUser) WebAuthnID() []byte          { return []byte(u.user.ID) }
+func (u *webauthnUser) WebAuthnName() string        { return u.user.Username }
+func (u *webauthnUser) WebAuthnDisplayName() string { return u.user.Username }
+
+func (u *webauthnUser) WebAuthnCredentials() []webauthn.Credential {
+	creds := make([]webauthn.Credential, len(u.passkeys))
+	for i := range u.passkeys {
+		creds[i] = u.passkeys[i].credential
+	}
+	return creds
+}
+
+// passkeyFor returns the stored passkey with the given credential ID.
+func (u *webauthnUser) passkeyFor(credentialID []byte) *Passkey {
+	for i := range u.passkeys {
+		if bytes.Equal(u.passkeys[i].credential.ID, credentialID) {
+			return &u.passkeys[i]
+		}
+	}
+	return nil
+}
+
+// SetWebAuthn enables passkey registration and login.
+// Called from the composition root when auth.webauthn is enabled.
+func (s *Service) SetWebAuthn(wa *webauthn.WebAuthn) {
+	s.webauthn = wa
+}
+
+// WebAuthnEnabled reports whether passkeys are configured.
+func (s *Service) WebAuthnEnabled() bool {
+	return s.webauthn != nil
+}
+
+// loadWebAuthnUser returns the user with their registered passkeys.
+func (s *Service) loadWebAuthnUser(ctx context.Context, userID string) (*webauthnUser, error) {
+	user, err := s.store.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+	passkeys, err := s.store.ListPasskeys(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &webauthnUser{user: user, passkeys: passkeys}, nil
+}
+
+// BeginPasskeyRegistration starts registering a new passkey for the user.
+// Already registered authenticators are excluded so the same device is not
+// enrolled twice.
+func (s *Service) BeginPasskeyRegistration(ctx context.Context, userID string) (*WebAuthnBeginResponse, error) {
+	if s.webauthn == nil {
+		return nil, ErrWebAuthnDisabled
+	}
+	wu, err := s.loadWebAuthnUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	creation, session, err := s.webauthn.BeginRegistration(wu,
+		webauthn.WithExclusions(webauthn.Credentials(wu.WebAuthnCredentials()).CredentialDescriptors()),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("begin passkey registration: %w", err)
+	}
+	return s.saveCeremony(ctx, userID, session, creation)
+}
+
+// FinishPasskeyRegistration verifies the authenticator's attestation and
+// stores the new passkey under name.
+func (s *Service) FinishPasskeyRegistration(ctx context.Context, userID, sessionID, name string, response []byte) (*Passkey, error) {
+	if s.webauthn == nil {
+		return nil, ErrWebAuthnDisabled
+	}
+	name, err := normalizePasskeyName(name)
+	if err != nil {
+		return nil, err
+	}
+	session, sessionUserID, err := s.consumeCeremony(ctx, sessionID)
+	if err != nil || sessionUserID != userID {
+		return nil, ErrInvalidPasskey
+	}
+	wu, err := s.loadWebAuthnUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(response)
+	if err != nil {
+		return nil, ErrInvalidPasskey
+	}
+	cred, err := s.webauthn.CreateCredential(wu, *session, parsed)
+	if err != nil {
+		s.logger.Warn("passkey registration rejected", zap.String("user_id", userID), zap.Error(err))
+		return nil, ErrInvalidPasskey
+	}
+
+	pk := &Passkey{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		Name:       name,
+		CreatedAt:  time.Now().UTC(),
+		credential: *cred,
+	}
+	if err := s.store.CreatePasskey(ctx, pk); err != nil {
+		return nil, err
+	}
+	s.logger.Info("passkey registered", zap.String("user_id", userID), zap.String("passkey_id", pk.ID))
+	return pk, nil
+}
+
+// ListPasskeys returns the user's registered passkeys.
+func (s *Service) ListPasskeys(ctx context.Context, userID string) ([]Passkey, error) {
+	return s.store.ListPasskeys(ctx, userID)
+}
+
+// RenamePasskey changes the display name of one of the user's passkeys.
+func (s *Service) RenamePasskey(ctx context.Context, userID, id, name string) (*Passkey, error) {
+	name, err := normalizePasskeyName(name)
+	if err != nil {
+		return nil, err
+	}
+	pk, err := s.store.GetPasskey(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && pk.UserID != userID) {
+		return nil, ErrPasskeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.RenamePasskey(ctx, id, name); err != nil {
+		return nil, err
+	}
+	pk.Name = name
+	return pk, nil
+}
+
+// DeletePasskey removes one of the user's passkeys.
+func (s *Service) DeletePasskey(ctx context.Context, userID, id string) error {
+	pk, err := s.store.GetPasskey(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && pk.UserID != userID) {
+		return ErrPasskeyNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.store.DeletePasskey(ctx, id); err != nil {
+		return err
+	}
+	s.logger.Info("passkey deleted", zap.String("user_id", userID), zap.String("passkey_id", id))
+	return nil
+}
+
+// BeginPasskeyLogin starts a passkey assertion. With an empty mfaToken the
+// browser may offer any discoverable passkey for this site; otherwise the
+// assertion is limited to the passkeys of the user the MFA token was issued
+// to.
+func (s *Service) BeginPasskeyLogin(ctx context.Context, mfaToken string) (*WebAuthnBeginResponse, error) {
+	if s.webauthn == nil {
+		return nil, ErrWebAuthnDisabled
+	}
+	if mfaToken == "" {
+		assertion, session, err := s.webauthn.BeginDiscoverableLogin()
+		if err != nil {
+			return nil, fmt.Errorf("begin passkey login: %w", err)
+		}
+		return s.saveCeremony(ctx, "", session, assertion)
+	}
+
+	userID, err := s.mfaTokenUser(ctx, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	wu, err := s.loadWebAuthnUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(wu.passkeys) == 0 {
+		return nil, ErrPasskeyNotFound
+	}
+	assertion, session, err := s.webauthn.BeginLogin(wu)
+	if err != nil {
+		return nil, fmt.Errorf("begin passkey login: %w", err)
+	}
+	return s.saveCeremony(ctx, userID, session, assertion)
+}
+
+// FinishPasskeyLogin verifies a passkey assertion and issues a token pair.
+// mfaToken must be the token the ceremony was started with, if any.
+func (s *Service) FinishPasskeyLogin(ctx context.Context, sessionID, mfaToken string, response []byte) (pair *TokenPair, err error) {
+	defer func() {
+		if err != nil {
+			s.auditFailure(ctx, AuditLogin, &User{}, err)
+		}
+	}()
+
+	if s.webauthn == nil {
+		return nil, ErrWebAuthnDisabled
+	}
+	session, sessionUserID, err := s.consumeCeremony(ctx, sessionID)
+	if err != nil {
+		return nil, ErrInvalidPasskey
+	}
+	if (mfaToken == "") != (sessionUserID == "") {
+		return nil, ErrInvalidPasskey
+	}
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(response)
+	if err != nil {
+		return nil, ErrInvalidPasskey
+	}
+
+	var wu *webauthnUser
+	var cred *webauthn.Credential
+	if mfaToken == "" {
+		var found webauthn.User
+		found, cred, err = s.webauthn.ValidatePasskeyLogin(func(_, userHandle []byte) (webauthn.User, error) {
+			return s.loadWebAuthnUser(ctx, string(userHandle))
+		}, *session, parsed)
+		if err == nil {
+			wu, _ = found.(*webauthnUser)
+		}
+	} else {
+		userID, tokenErr := s.mfaTokenUser(ctx, mfaToken)
+		if tokenErr != nil || userID != sessionUserID {
+			return nil, ErrInvalidMFACode
+		}
+		if wu, err = s.loadWebAuthnUser(ctx, userID); err != nil {
+			return nil, err
+		}
+		cred, err = s.webauthn.ValidateLogin(wu, *session, parsed)
+	}
+	if err != nil || wu == nil {
+		s.logger.Warn("passkey assertion rejected", zap.Error(err))
+		return nil, ErrInvalidPasskey
+	}
+	if cred.Authenticator.CloneWarning {
+		s.logger.Warn("passkey signature counter went backwards, possible cloned authenticator",
+			zap.String("user_id", wu.user.ID))
+		return nil, ErrInvalidPasskey
+	}
+	if wu.user.Disabled {
+		return nil, ErrUserDisabled
+	}
+
+	if pk := wu.passkeyFor(cred.ID); pk != nil {
+		pk.credential = *cred
+		if err := s.store.UpdatePasskeyCredential(ctx, pk); err != nil {
+			s.logger.Warn("failed to update passkey counter", zap.Error(err))
+		}
+	}
+	if mfaToken != "" {
+		_ = s.store.RevokeMFAToken(ctx, HashToken(mfaToken))
+	}
+
+	pair, err = s.issueTokenPair(ctx, wu.user)
+	if err != nil {
+		return nil, err
+	}
+	_ = s.store.UpdateLastLogin(ctx, wu.user.ID)
+	s.logger.Info("user logged in with passkey", zap.String("username", wu.user.Username), zap.String("user_id", wu.user.ID))
+	s.auditSuccess(ctx, AuditLogin, wu.user, "passkey")
+	return pair, nil
+}
+
+// mfaTokenUser validates an MFA token issued by a password login and
+// returns its user ID without consuming it.
+func (s *Service) mfaTokenUser(ctx context.Context, mfaToken string) (string, error) {
+	userID, err := s.totp.ValidateMFAToken(mfaToken)
+	if err != nil {
+		return "", ErrInvalidMFACode
+	}
+	storedUserID, err := s.store.GetMFAToken(ctx, HashToken(mfaToken))
+	if err != nil || storedUserID != userID {
+		return "", ErrInvalidMFACode
+	}
+	return userID, nil
+}
+
+// saveCeremony persists the challenge state of a ceremony and returns the
+// options the browser needs to answer it.
+func (s *Service) saveCeremony(ctx context.Context, userID string, session *webauthn.SessionData, options any) (*WebAuthnBeginResponse, error) {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return nil, fmt.Errorf("encode webauthn session: %w", err)
+	}
+	opts, err := json.Marshal(options)
+	if err != nil {
+		return nil, fmt.Errorf("encode webauthn options: %w", err)
+	}
+	expiresAt := session.Expires
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(webauthnCeremonyTTL)
+	}
+	id := uuid.New().String()
+	if err := s.store.SaveWebAuthnSession(ctx, id, userID, string(data), expiresAt); err != nil {
+		return nil, err
+	}
+	return &WebAuthnBeginResponse{SessionID: id, Options: opts}, nil
+}
+
+// consumeCeremony loads and deletes a ceremony's challenge state so each
+// challenge can be answered at most once.
+func (s *Service) consumeCeremony(ctx context.Context, id string) (*webauthn.SessionData, string, error) {
+	data, userID, err := s.store.TakeWebAuthnSession(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	var session webauthn.SessionData
+	if err := json.Unmarshal([]byte(data), &session); err != nil {
+		return nil, "", fmt.Errorf("decode webauthn session: %w", err)
+	}
+	return &session, userID, nil
+}
+
+// hasPasskeys reports whether the user can answer an MFA challenge with a passkey.
+func (s *Service) hasPasskeys(ctx context.Context, userID string) bool {
+	if s.webauthn == nil {
+		return false
+	}
+	n, err := s.store.CountPasskeys(ctx, userID)
+	return err == nil && n > 0
+}
+
+func normalizePasskeyName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 64 {
+		return "", ErrInvalidPasskeyName
+	}
+	return name, nil
+}
+
+// CreatePasskey inserts a passkey and its credential record.
+func (s *UserStore) CreatePasskey(ctx context.Context, pk *Passkey) error {
+	cred, err := json.Marshal(pk.credential)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO auth_webauthn_credentials (id, user_id, name, credential_id, credential, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		pk.ID, pk.UserID, pk.Name, base64.RawURLEncoding.EncodeToString(pk.credential.ID), string(cred), pk.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert passkey: %w", err)
+	}
+	return nil
+}
+
+// passkeyColumns is the shared SELECT column list for passkey queries.
+const passkeyColumns = `id, user_id, name, credential, created_at, last_used_at`
+
+// GetPasskey returns a passkey by ID.
+func (s *UserStore) GetPasskey(ctx context.Context, id string) (*Passkey, error) {
+	return scanPasskey(s.db.QueryRowContext(ctx,
+		`SELECT `+passkeyColumns+` FROM auth_webauthn_credentials WHERE id = ?`, id))
+}
+
+// ListPasskeys returns all passkeys registered by a user, oldest first.
+func (s *UserStore) ListPasskeys(ctx context.Context, userID string) ([]Passkey, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+passkeyColumns+` FROM auth_webauthn_credentials WHERE user_id = ? ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list passkeys: %w", err)
+	}
+	defer rows.Close()
+
+	passkeys := []Passkey{}
+	for rows.Next() {
+		pk, err := scanPasskey(rows)
+		if err != nil {
+			return nil, err
+		}
+		passkeys = append(passkeys, *pk)
+	}
+	return passkeys, rows.Err()
+}
+
+// CountPasskeys returns the number of passkeys registered by a user.
+func (s *UserStore) CountPasskeys(ctx context.Context, userID string) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM auth_webauthn_credentials WHERE user_id = ?`, userID).Scan(&n)
+	return n, err
+}
+
+// RenamePasskey updates a passkey's display name.
+func (s *UserStore) RenamePasskey(ctx context.Context, id, name string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE auth_webauthn_credentials SET name = ? WHERE id = ?`, name, id)
+	return err
+}
+
+// UpdatePasskeyCredential stores a passkey's credential record after a
+// login, persisting the new signature counter and flags.
+func (s *UserStore) UpdatePasskeyCredential(ctx context.Context, pk *Passkey) error {
+	cred, err := json.Marshal(pk.credential)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx,
+		`UPDATE auth_webauthn_credentials SET credential = ?, last_used_at = ? WHERE id = ?`,
+		string(cred), time.Now().UTC(), pk.ID)
+	return err
+}
+
+// DeletePasskey removes a passkey.
+func (s *UserStore) DeletePasskey(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM auth_webauthn_credentials WHERE id = ?`, id)
+	return err
+}
+
+// SaveWebAuthnSession stores the challenge state of an in-flight ceremony.
+// userID is empty for discoverable logins.
+func (s *UserStore) SaveWebAuthnSession(ctx context.Context, id, userID, data string, expiresAt time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO auth_webauthn_sessions (id, user_id, data, expires_at) VALUES (?, ?, ?, ?)`,
+		id, userID, data, expiresAt)
+	if err != nil {
+		return fmt.Errorf("save webauthn session: %w", err)
+	}
+	_, _ = s.db.ExecContext(ctx, `DELETE FROM auth_webauthn_sessions WHERE expires_at < ?`, time.Now().UTC())
+	return nil
+}
+
+// TakeWebAuthnSession deletes an unexpired ceremony and returns its state.
+func (s *UserStore) TakeWebAuthnSession(ctx context.Context, id string) (data, userID string, err error) {
+	var expiresAt time.Time
+	err = s.db.QueryRowContext(ctx,
+		`SELECT data, user_id, expires_at FROM auth_webauthn_sessions WHERE id = ?`, id).
+		Scan(&data, &userID, &expiresAt)
+	if err != nil {
+		return "", "", err
+	}
+	res, err := s.db.ExecContext(ctx, `DELETE FROM auth_webauthn_sessions WHERE id = ?`, id)
+	if err != nil {
+		return "", "", err
+	}
+	// A concurrent request consumed it first.
+	if n, _ := res.RowsAffected(); n == 0 {
+		return "", "", sql.ErrNoRows
+	}
+	if expiresAt.Before(time.Now()) {
+		return "", "", errors.New("webauthn session expired")
+	}
+	return data, userID, nil
+}
+
+func scanPasskey(row rowScanner) (*Passkey, error) {
+	var pk Passkey
+	var cred string
+	var lastUsed sql.NullTime
+	if err := row.Scan(&pk.ID, &pk.UserID, &pk.Name, &cred, &pk.CreatedAt, &lastUsed); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(cred), &pk.credential); err != nil {
+		return nil, fmt.Errorf("decode passkey credential: %w", err)
+	}
+	if lastUsed.Valid {
+		pk.LastUsedAt = &lastUsed.Time
+	}
+	return &pk, nil
+}
+
+// handleWebAuthnStatus reports whether passkey login is configured.
+//
+//	@Summary		Passkey status
+//	@Description	Returns whether WebAuthn passkey login is enabled.
+//	@Tags			auth
+//	@Produce		json
+//	@Success		200	{object}	WebAuthnStatusResponse
+//	@Router			/auth/webauthn/status [get]
+func (h *Handler) handleWebAuthnStatus(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, WebAuthnStatusResponse{Enabled: h.service.WebAuthnEnabled()})
+}
+
+// handlePasskeyRegisterBegin starts passkey registration for the authenticated user.
+//
+//	@Summary		Begin passkey registration
+//	@Description	Returns credential creation options for navigator.credentials.create().
+//	@Tags			auth
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	WebAuthnBeginResponse
+//	@Failure		401	{object}	models.APIProblem
+//	@Failure		404	{object}	models.APIProblem
+//	@Failure		500	{object}	models.APIProblem
+//	@Router			/auth/webauthn/register/begin [post]
+func (h *Handler) handlePasskeyRegisterBegin(w http.ResponseWriter, r *http.Request) {
+	claims := UserFromContext(r.Context())
+	if claims == nil {
+		writeAuthError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	resp, err := h.service.BeginPasskeyRegistration(r.Context(), claims.UserID)
+	if err != nil {
+		if errors.Is(err, ErrWebAuthnDisabled) {
+			writeAuthError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h.logger.Error("passkey registration begin error", zap.Error(err))
+		writeAuthError(w, http.StatusInternalServerError, "failed to start passkey registration")
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handlePasskeyRegisterFinish verifies and stores a new passkey.
+//
+//	@Summary		Finish passkey registration
+//	@Description	Verify the authenticator's response and register the passkey under a name.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		PasskeyRegisterFinishRequest	true	"Ceremony session, name, and authenticator response"
+//	@Success		201		{object}	Passkey
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		401		{object}	models.APIProblem
+//	@Failure		404		{object}	models.APIProblem
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/auth/webauthn/register/finish [post]
+func (h *Handler) handlePasskeyRegisterFinish(w http.ResponseWriter, r *http.Request) {
+	claims := UserFromContext(r.Context())
+	if claims == nil {
+		writeAuthError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	var req PasskeyRegisterFinishRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAuthError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.SessionID == "" || len(req.Credential) == 0 {
+		writeAuthError(w, http.StatusBadRequest, "session_id and credential are required")
+		return
+	}
+
+	pk, err := h.service.FinishPasskeyRegistration(r.Context(), claims.UserID, req.SessionID, req.Name, req.Credential)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrWebAuthnDisabled):
+			writeAuthError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, ErrInvalidPasskeyName), errors.Is(err, ErrInvalidPasskey):
+			writeAuthError(w, http.StatusBadRequest, err.Error())
+		default:
+			h.logger.Error("passkey registration finish error", zap.Error(err))
+			writeAuthError(w, http.StatusInternalServerError, "failed to register passkey")
+		}
+		return
+	}
+	writeJSON(w, http.StatusCreated, pk)
+}
+
+// handleListPasskeys lists the authenticated user's passkeys.
+//
+//	@Summary		List passkeys
+//	@Description	Returns the authenticated user's registered passkeys.
+//	@Tags			auth
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{array}		Passkey
+//	@Failure		401	{object}	models.APIProblem
+//	@Failure		500	{object}	models.APIProblem
+//	@Router			/auth/webauthn/credentials [get]
+func (h *Handler) handleListPasskeys(w http.ResponseWriter, r *http.Request) {
+	claims := UserFromContext(r.Context())
+	if claims == nil {
+		writeAuthError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	passkeys, err := h.service.ListPasskeys(r.Context(), claims.UserID)
+	if err != nil {
+		h.logger.Error("list passkeys error", zap.Error(err))
+		writeAuthError(w, http.StatusInternalServerError, "failed to list passkeys")
+		return
+	}
+	writeJSON(w, http.StatusOK, passkeys)
+}
+
+// handleRenamePasskey renames one of the authenticated user's passkeys.
+//
+//	@Summary		Rename passkey
+//	@Description	Change the display name of a registered passkey.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string					true	"Passkey ID"
+//	@Param			request	body		RenamePasskeyRequest	true	"New name"
+//	@Success		200		{object}	Passkey
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		401		{object}	models.APIProblem
+//	@Failure		404		{object}	models.APIProblem
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/auth/webauthn/credentials/{id} [put]
+func (h *Handler) handleRenamePasskey(w http.ResponseWriter, r *http.Request) {
+	claims := UserFromContext(r.Context())
+	if claims == nil {
+		writeAuthError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	var req RenamePasskeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAuthError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	pk, err := h.service.RenamePasskey(r.Context(), claims.UserID, r.PathValue("id"), req.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidPasskeyName):
+			writeAuthError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrPasskeyNotFound):
+			writeAuthError(w, http.StatusNotFound, err.Error())
+		default:
+			h.logger.Error("rename passkey error", zap.Error(err))
+			writeAuthError(w, http.StatusInternalServerError, "failed to rename passkey")
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, pk)
+}
+
+// handleDeletePasskey removes one of the authenticated user's passkeys.
+//
+//	@Summary		Delete passkey
+//	@Description	Remove a registered passkey. It can no longer be used to log in.
+//	@Tags			auth
+//	@Security		BearerAuth
+//	@Param			id	path	string	true	"Passkey ID"
+//	@Success		204	"No Content"
+//	@Failure		401	{object}	models.APIProblem
+//	@Failure		404	{object}	models.APIProblem
+//	@Failure		500	{object}	models.APIProblem
+//	@Router			/auth/webauthn/credentials/{id} [delete]
+func (h *Handler) handleDeletePasskey(w http.ResponseWriter, r *http.Request) {
+	claims := UserFromContext(r.Context())
+	if claims == nil {
+		writeAuthError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	if err := h.service.DeletePasskey(r.Context(), claims.UserID, r.PathValue("id")); err != nil {
+		if errors.Is(err, ErrPasskeyNotFound) {
+			writeAuthError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h.logger.Error("delete passkey error", zap.Error(err))
+		writeAuthError(w, http.StatusInternalServerError, "failed to delete passkey")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePasskeyLoginBegin starts a passkey login.
+//
+//	@Summary		Begin passkey login
+//	@Description	Returns assertion options for navigator.credentials.get(). Pass the mfa_token from a password login to use a passkey as the second factor; omit it for passwordless login.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		PasskeyLoginBeginRequest	false	"Optional MFA token"
+//	@Success		200		{object}	WebAuthnBeginResponse
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		401		{object}	models.APIProblem
+//	@Failure		404		{object}	models.APIProblem
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/auth/webauthn/login/begin [post]
+func (h *Handler) handlePasskeyLoginBegin(w http.ResponseWriter, r *http.Request) {
+	var req PasskeyLoginBeginRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeAuthError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	resp, err := h.service.BeginPasskeyLogin(r.Context(), req.MFAToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrWebAuthnDisabled):
+			writeAuthError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, ErrInvalidMFACode):
+			writeAuthError(w, http.StatusUnauthorized, "invalid or expired MFA token")
+		case errors.Is(err, ErrPasskeyNotFound):
+			writeAuthError(w, http.StatusBadRequest, "no passkeys are registered for this account")
+		default:
+			h.logger.Error("passkey login begin error", zap.Error(err))
+			writeAuthError(w, http.StatusInternalServerError, "failed to start passkey login")
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handlePasskeyLoginFinish verifies a passkey assertion and returns a token pair.
+//
+//	@Summary		Finish passkey login
+//	@Description	Verify the authenticator's assertion and issue a JWT token pair.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		PasskeyLoginFinishRequest	true	"Ceremony session, optional MFA token, and authenticator response"
+//	@Success		200		{object}	TokenPair
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		401		{object}	models.APIProblem
+//	@Failure		404		{object}	models.APIProblem
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/auth/webauthn/login/finish [post]
+func (h *Handler) handlePasskeyLoginFinish(w http.ResponseWriter, r *http.Request) {
+	var req PasskeyLoginFinishRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAuthError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.SessionID == "" || len(req.Credential) == 0 {
+		writeAuthError(w, http.StatusBadRequest, "session_id and credential are required")
+		return
+	}
+
+	pair, err := h.service.FinishPasskeyLogin(withClientInfo(r), req.SessionID, req.MFAToken, req.Credential)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrWebAuthnDisabled):
+			writeAuthError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, ErrInvalidPasskey), errors.Is(err, ErrInvalidMFACode), errors.Is(err, ErrUserDisabled):
+			writeAuthError(w, http.StatusUnauthorized, "passkey verification failed")
+		default:
+			h.logger.Error("passkey login finish error", zap.Error(err))
+			writeAuthError(w, http.StatusInternalServerError, "passkey login failed")
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, pair)
+}
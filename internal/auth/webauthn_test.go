@@ -0,0 +1,217 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// webauthnEnv returns a test Service with passkeys enabled and an admin user.
+func webauthnEnv(t *testing.T) (*UserStore, *Service, *User) {
+	t.Helper()
+	userStore, _, svc := testEnv(t)
+	wa, err := NewWebAuthn(WebAuthnConfig{Enabled: true, RPID: "localhost", RPOrigins: []string{"http://localhost:8080"}})
+	if err != nil {
+		t.Fatalf("NewWebAuthn: %v", err)
+	}
+	svc.SetWebAuthn(wa)
+
+	user, err := svc.Setup(context.Background(), "admin", "admin@example.com", "securepassword")
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	return userStore, svc, user
+}
+
+func TestNewWebAuthn_RequiresRPIDAndOrigins(t *testing.T) {
+	if _, err := NewWebAuthn(WebAuthnConfig{Enabled: true, RPID: "localhost"}); err == nil {
+		t.Error("expected error without rp_origins")
+	}
+	if _, err := NewWebAuthn(WebAuthnConfig{Enabled: true, RPOrigins: []string{"http://localhost"}}); err == nil {
+		t.Error("expected error without rp_id")
+	}
+}
+
+func TestPasskeys_DisabledByDefault(t *testing.T) {
+	_, _, svc := testEnv(t)
+	ctx := context.Background()
+
+	if _, err := svc.BeginPasskeyRegistration(ctx, "user-1"); !errors.Is(err, ErrWebAuthnDisabled) {
+		t.Errorf("BeginPasskeyRegistration err = %v, want ErrWebAuthnDisabled", err)
+	}
+	if _, err := svc.BeginPasskeyLogin(ctx, ""); !errors.Is(err, ErrWebAuthnDisabled) {
+		t.Errorf("BeginPasskeyLogin err = %v, want ErrWebAuthnDisabled", err)
+	}
+}
+
+func TestBeginPasskeyRegistration_ReturnsOptions(t *testing.T) {
+	_, svc, user := webauthnEnv(t)
+	ctx := context.Background()
+
+	resp, err := svc.BeginPasskeyRegistration(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("BeginPasskeyRegistration: %v", err)
+	}
+	if resp.SessionID == "" {
+		t.Error("expected a session ID")
+	}
+	var opts struct {
+		PublicKey struct {
+			RP   struct{ ID string }
+			User struct{ Name string }
+		} `json:"publicKey"`
+	}
+	if err := json.Unmarshal(resp.Options, &opts); err != nil {
+		t.Fatalf("decode options: %v", err)
+	}
+	if opts.PublicKey.RP.ID != "localhost" || opts.PublicKey.User.Name != "admin" {
+		t.Errorf("options = %+v, want rp localhost and user admin", opts.PublicKey)
+	}
+}
+
+func TestFinishPasskeyRegistration_ChallengeIsSingleUse(t *testing.T) {
+	_, svc, user := webauthnEnv(t)
+	ctx := context.Background()
+
+	resp, err := svc.BeginPasskeyRegistration(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("BeginPasskeyRegistration: %v", err)
+	}
+	_, err = svc.FinishPasskeyRegistration(ctx, "other-user", resp.SessionID, "key", []byte(`{}`))
+	if !errors.Is(err, ErrInvalidPasskey) {
+		t.Errorf("other user err = %v, want ErrInvalidPasskey", err)
+	}
+	// The failed attempt consumed the challenge.
+	_, err = svc.FinishPasskeyRegistration(ctx, user.ID, resp.SessionID, "key", []byte(`{}`))
+	if !errors.Is(err, ErrInvalidPasskey) {
+		t.Errorf("reused session err = %v, want ErrInvalidPasskey", err)
+	}
+
+	_, err = svc.FinishPasskeyRegistration(ctx, user.ID, resp.SessionID, " ", []byte(`{}`))
+	if !errors.Is(err, ErrInvalidPasskeyName) {
+		t.Errorf("blank name err = %v, want ErrInvalidPasskeyName", err)
+	}
+}
+
+func TestPasskeyManagement(t *testing.T) {
+	userStore, svc, user := webauthnEnv(t)
+	ctx := context.Background()
+
+	pk := &Passkey{
+		ID:         "pk-1",
+		UserID:     user.ID,
+		Name:       "YubiKey",
+		CreatedAt:  time.Now().UTC(),
+		credential: webauthn.Credential{ID: []byte("cred-1"), PublicKey: []byte("pub")},
+	}
+	if err := userStore.CreatePasskey(ctx, pk); err != nil {
+		t.Fatalf("CreatePasskey: %v", err)
+	}
+
+	passkeys, err := svc.ListPasskeys(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ListPasskeys: %v", err)
+	}
+	if len(passkeys) != 1 || string(passkeys[0].credential.ID) != "cred-1" {
+		t.Fatalf("ListPasskeys = %+v, want the stored passkey", passkeys)
+	}
+
+	if _, err := svc.RenamePasskey(ctx, "someone-else", "pk-1", "Mine"); !errors.Is(err, ErrPasskeyNotFound) {
+		t.Errorf("rename by other user err = %v, want ErrPasskeyNotFound", err)
+	}
+	renamed, err := svc.RenamePasskey(ctx, user.ID, "pk-1", "  Phone ")
+	if err != nil {
+		t.Fatalf("RenamePasskey: %v", err)
+	}
+	if renamed.Name != "Phone" {
+		t.Errorf("Name = %q, want Phone", renamed.Name)
+	}
+
+	if err := svc.DeletePasskey(ctx, "someone-else", "pk-1"); !errors.Is(err, ErrPasskeyNotFound) {
+		t.Errorf("delete by other user err = %v, want ErrPasskeyNotFound", err)
+	}
+	if err := svc.DeletePasskey(ctx, user.ID, "pk-1"); err != nil {
+		t.Fatalf("DeletePasskey: %v", err)
+	}
+	if n, _ := userStore.CountPasskeys(ctx, user.ID); n != 0 {
+		t.Errorf("CountPasskeys = %d, want 0", n)
+	}
+}
+
+func TestLogin_MFAChallengeListsPasskeys(t *testing.T) {
+	userStore, svc, user := webauthnEnv(t)
+	ctx := context.Background()
+
+	if err := userStore.SetTOTPSecret(ctx, user.ID, "secret"); err != nil {
+		t.Fatalf("SetTOTPSecret: %v", err)
+	}
+	if err := userStore.EnableTOTP(ctx, user.ID); err != nil {
+		t.Fatalf("EnableTOTP: %v", err)
+	}
+	pk := &Passkey{ID: "pk-1", UserID: user.ID, Name: "YubiKey", CreatedAt: time.Now().UTC(),
+		credential: webauthn.Credential{ID: []byte("cred-1")}}
+	if err := userStore.CreatePasskey(ctx, pk); err != nil {
+		t.Fatalf("CreatePasskey: %v", err)
+	}
+
+	result, err := svc.Login(ctx, "admin", "securepassword")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if !result.MFARequired || strings.Join(result.MFAMethods, ",") != "totp,webauthn" {
+		t.Errorf("result = %+v, want MFA with totp and webauthn", result)
+	}
+
+	resp, err := svc.BeginPasskeyLogin(ctx, result.MFAToken)
+	if err != nil {
+		t.Fatalf("BeginPasskeyLogin: %v", err)
+	}
+	if !strings.Contains(string(resp.Options), "allowCredentials") {
+		t.Errorf("options %s should restrict the assertion to the user's passkeys", resp.Options)
+	}
+
+	if _, err := svc.BeginPasskeyLogin(ctx, "not-a-token"); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("invalid MFA token err = %v, want ErrInvalidMFACode", err)
+	}
+}
+
+func TestFinishPasskeyLogin_RejectsMismatchedMFAToken(t *testing.T) {
+	_, svc, _ := webauthnEnv(t)
+	ctx := context.Background()
+
+	resp, err := svc.BeginPasskeyLogin(ctx, "")
+	if err != nil {
+		t.Fatalf("BeginPasskeyLogin: %v", err)
+	}
+	// A passwordless ceremony cannot be completed as a second factor.
+	if _, err := svc.FinishPasskeyLogin(ctx, resp.SessionID, "some-mfa-token", []byte(`{}`)); !errors.Is(err, ErrInvalidPasskey) {
+		t.Errorf("err = %v, want ErrInvalidPasskey", err)
+	}
+}
+
+func TestHandleWebAuthnStatus_Public(t *testing.T) {
+	h, mux := setupHandlerEnv(t)
+	handler := AuthMiddleware(h.service.Tokens())(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/webauthn/status", http.NoBody)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var resp WebAuthnStatusResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Enabled {
+		t.Error("passkeys should be disabled without configuration")
+	}
+}
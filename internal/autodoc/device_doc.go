@@ -16,9 +16,14 @@ type DeviceDocData struct {
 	Storage       []models.DeviceStorage
 	GPUs          []models.DeviceGPU
 	Services      []models.DeviceService
+	ServicePorts  map[int]string // Service port -> well-known service name
+	Ports         []models.DevicePort
+	Software      []InstalledSoftware
 	Children      []models.Device
+	Relationships []DeviceRelationship
 	Alerts        []DeviceAlert
 	RecentChanges []ChangelogEntry
+	IconURL       string // Device icon image; empty omits the image
 	GeneratedAt   time.Time
 }
 
@@ -32,6 +37,10 @@ func RenderDeviceDoc(data DeviceDocData) (doc string, err error) {
 		"primaryIP":         primaryIP,
 		"eventIcon":         eventIcon,
 		"sourceTag":         sourceTag,
+		"softwareRows":      softwareRows,
+		"relationshipLabel": relationshipLabel,
+		"tableCell":         tableCell,
+		"rackPosition":      rackPosition,
 		"derefTime": func(t *time.Time) time.Time {
 			if t == nil {
 				return time.Time{}
@@ -130,6 +139,29 @@ func deviceTypeLabel(dt models.DeviceType) string {
 	return string(dt)
 }
 
+// maxDocSoftware caps the installed-software rows rendered per device.
+const maxDocSoftware = 50
+
+// softwareRows returns at most maxDocSoftware packages for rendering.
+func softwareRows(sw []InstalledSoftware) []InstalledSoftware {
+	if len(sw) > maxDocSoftware {
+		return sw[:maxDocSoftware]
+	}
+	return sw
+}
+
+// relationshipLabel turns a snake_case relationship type into words,
+// e.g. "powered_by" -> "powered by".
+func relationshipLabel(relType string) string {
+	return strings.ReplaceAll(relType, "_", " ")
+}
+
+// tableCell makes free text safe to place in a Markdown table cell.
+func tableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
 // primaryIP returns the first IP address from a slice of IPs, or "N/A".
 func primaryIP(ips []string) string {
 	if len(ips) == 0 {
@@ -139,7 +171,9 @@ func primaryIP(ips []string) string {
 }
 
 const defaultDeviceTemplate = `# {{ .Device.Hostname }}{{ if .Device.IPAddresses }} ({{ primaryIP .Device.IPAddresses }}){{ end }}
-
+{{ if .IconURL }}
+![{{ deviceTypeLabel .Device.DeviceType }} icon]({{ .IconURL }})
+{{ end }}
 **Device Type:** {{ deviceTypeLabel .Device.DeviceType }} | **Status:** {{ .Device.Status }} | **Confidence:** {{ .Device.ClassificationConfidence }}%
 **First Seen:** {{ formatTime .Device.FirstSeen }} | **Last Seen:** {{ formatTime .Device.LastSeen }}
 **MAC Address:** {{ if .Device.MACAddress }}{{ .Device.MACAddress }}{{ else }}N/A{{ end }} | **Manufacturer:** {{ if .Device.Manufacturer }}{{ .Device.Manufacturer }}{{ else }}N/A{{ end }}
@@ -179,7 +213,30 @@ const defaultDeviceTemplate = `# {{ .Device.Hostname }}{{ if .Device.IPAddresses
 | Service | Type | Port | Status | Version |
 |---------|------|------|--------|---------|
 {{ range .Services -}}
-| {{ .Name }} | {{ .ServiceType }} | {{ .Port }} | {{ .Status }} | {{ .Version }} |
+| {{ .Name }} | {{ .ServiceType }} | {{ .Port }}{{ with index $.ServicePorts .Port }} ({{ . }}){{ end }} | {{ .Status }} | {{ .Version }} |
+{{ end }}
+{{- end }}
+{{- if .Ports }}
+
+## Open Ports
+
+| Port | Protocol | Service | Last Seen |
+|------|----------|---------|-----------|
+{{ range .Ports -}}
+| {{ .Port }} | {{ .Protocol }} | {{ .Service }} | {{ formatTime .LastSeen }} |
+{{ end }}
+{{- end }}
+{{- if .Software }}
+
+## Installed Software
+
+| Package | Version | Publisher |
+|---------|---------|-----------|
+{{ range softwareRows .Software -}}
+| {{ .Name }} | {{ .Version }} | {{ .Publisher }} |
+{{ end }}
+{{- if gt (len .Software) (len (softwareRows .Software)) }}
+*Showing {{ len (softwareRows .Software) }} of {{ len .Software }} packages.*
 {{ end }}
 {{- end }}
 
@@ -188,6 +245,15 @@ const defaultDeviceTemplate = `# {{ .Device.Hostname }}{{ if .Device.IPAddresses
 **Network Layer:** {{ networkLayerLabel .Device.NetworkLayer }}
 **Parent Device:** {{ if .Device.ParentDeviceID }}{{ .Device.ParentDeviceID }}{{ else }}Gateway/Root{{ end }}
 **Connection Type:** {{ if .Device.ConnectionType }}{{ .Device.ConnectionType }}{{ else }}N/A{{ end }}
+{{- with rackPosition .Device }}
+**Rack Position:** {{ . }}
+{{- end }}
+{{- with index .Device.CustomFields "tailscale_routes" }}
+**Tailscale Subnet Router:** {{ . }}
+{{- end }}
+{{- if eq (index .Device.CustomFields "tailscale_exit_node") "true" }}
+**Tailscale Exit Node:** Yes
+{{- end }}
 {{ if .Children -}}
 **Connected Devices:** {{ len .Children }}
 
@@ -197,6 +263,20 @@ const defaultDeviceTemplate = `# {{ .Device.Hostname }}{{ if .Device.IPAddresses
 | {{ .Hostname }} | {{ primaryIP .IPAddresses }} | {{ deviceTypeLabel .DeviceType }} | {{ .Status }} |
 {{ end }}
 {{- end }}
+{{- if .Relationships }}
+
+## Relationships
+
+| From | Relationship | To | Note |
+|------|--------------|----|------|
+{{ range .Relationships -}}
+{{ if .Incoming -}}
+| {{ .PeerName }} | {{ relationshipLabel .Type }} | This device | {{ tableCell .Note }} |
+{{ else -}}
+| This device | {{ relationshipLabel .Type }} | {{ .PeerName }} | {{ tableCell .Note }} |
+{{ end -}}
+{{ end }}
+{{- end }}
 {{- if .Alerts }}
 
 ## Active Alerts
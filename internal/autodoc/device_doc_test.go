@@ -3,6 +3,7 @@ package autodoc
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -67,6 +68,7 @@ func TestRenderDeviceDoc_FullData(t *testing.T) {
 		RecentChanges: []ChangelogEntry{
 			{EventType: TopicDeviceUpdated, Summary: "Device updated: web-server-01", SourceModule: "recon", CreatedAt: now.Add(-1 * time.Hour)},
 		},
+		IconURL:     "/api/v1/recon/devices/dev-001/icon",
 		GeneratedAt: now,
 	}
 
@@ -77,6 +79,7 @@ func TestRenderDeviceDoc_FullData(t *testing.T) {
 
 	checks := []string{
 		"# web-server-01 (192.168.1.10)",
+		"![Server icon](/api/v1/recon/devices/dev-001/icon)",
 		"Server",
 		"online",
 		"85%",
@@ -181,6 +184,104 @@ func TestRenderDeviceDoc_NilHardware(t *testing.T) {
 	}
 }
 
+func TestRenderDeviceDoc_OpenPorts(t *testing.T) {
+	data := DeviceDocData{
+		Device: &models.Device{
+			ID:         "dev-005",
+			Hostname:   "switch-01",
+			DeviceType: models.DeviceTypeSwitch,
+			Status:     models.DeviceStatusOnline,
+		},
+		Ports: []models.DevicePort{
+			{DeviceID: "dev-005", Port: 22, Protocol: "tcp", Service: "ssh", LastSeen: time.Now().UTC()},
+			{DeviceID: "dev-005", Port: 8291, Protocol: "tcp", LastSeen: time.Now().UTC()},
+		},
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	md, err := RenderDeviceDoc(data)
+	if err != nil {
+		t.Fatalf("RenderDeviceDoc: %v", err)
+	}
+
+	if !strings.Contains(md, "## Open Ports") {
+		t.Error("open ports section should be present")
+	}
+	if !strings.Contains(md, "| 22 | tcp | ssh |") {
+		t.Error("expected port 22 row in output")
+	}
+	if !strings.Contains(md, "| 8291 | tcp |  |") {
+		t.Error("expected port 8291 row in output")
+	}
+}
+
+func TestRenderDeviceDoc_TailscaleRoutes(t *testing.T) {
+	data := DeviceDocData{
+		Device: &models.Device{
+			ID:         "dev-006",
+			Hostname:   "ts-gateway",
+			DeviceType: models.DeviceTypeServer,
+			Status:     models.DeviceStatusOnline,
+			CustomFields: map[string]string{
+				"tailscale_routes":    "192.168.1.0/24,10.0.0.0/16",
+				"tailscale_exit_node": "true",
+			},
+		},
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	md, err := RenderDeviceDoc(data)
+	if err != nil {
+		t.Fatalf("RenderDeviceDoc: %v", err)
+	}
+
+	if !strings.Contains(md, "**Tailscale Subnet Router:** 192.168.1.0/24,10.0.0.0/16") {
+		t.Error("expected subnet routes in network position section")
+	}
+	if !strings.Contains(md, "**Tailscale Exit Node:** Yes") {
+		t.Error("expected exit node in network position section")
+	}
+
+	// Devices without Tailscale routes render neither line.
+	data.Device.CustomFields = nil
+	md, err = RenderDeviceDoc(data)
+	if err != nil {
+		t.Fatalf("RenderDeviceDoc: %v", err)
+	}
+	if strings.Contains(md, "Tailscale") {
+		t.Error("tailscale lines should be omitted for ordinary devices")
+	}
+}
+
+func TestRenderDeviceDoc_ServicePortNames(t *testing.T) {
+	data := DeviceDocData{
+		Device: &models.Device{
+			ID:         "dev-007",
+			Hostname:   "media-01",
+			DeviceType: models.DeviceTypeServer,
+			Status:     models.DeviceStatusOnline,
+		},
+		Services: []models.DeviceService{
+			{Name: "plex", ServiceType: "docker", Port: 32400, Status: "running"},
+			{Name: "agent", ServiceType: "systemd", Port: 7777, Status: "running"},
+		},
+		ServicePorts: map[int]string{32400: "Plex"},
+		GeneratedAt:  time.Now().UTC(),
+	}
+
+	md, err := RenderDeviceDoc(data)
+	if err != nil {
+		t.Fatalf("RenderDeviceDoc: %v", err)
+	}
+
+	if !strings.Contains(md, "| plex | docker | 32400 (Plex) | running |") {
+		t.Error("expected named port in plex row")
+	}
+	if !strings.Contains(md, "| agent | systemd | 7777 | running |") {
+		t.Error("expected bare port in agent row")
+	}
+}
+
 func TestRenderDeviceDoc_EmptyServices(t *testing.T) {
 	data := DeviceDocData{
 		Device: &models.Device{
@@ -360,3 +461,60 @@ func TestListFilter_DeviceID(t *testing.T) {
 		t.Errorf("results = %d, want 1", len(results))
 	}
 }
+
+func TestRenderDeviceDoc_Software(t *testing.T) {
+	sw := make([]InstalledSoftware, 0, maxDocSoftware+5)
+	sw = append(sw, InstalledSoftware{Name: "openssl", Version: "3.0.11", Publisher: "Debian"})
+	for i := 1; i < maxDocSoftware+5; i++ {
+		sw = append(sw, InstalledSoftware{Name: fmt.Sprintf("pkg-%03d", i), Version: "1.0"})
+	}
+
+	md, err := RenderDeviceDoc(DeviceDocData{
+		Device:      &models.Device{ID: "dev-003", Hostname: "pkg-host"},
+		Software:    sw,
+		GeneratedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		t.Fatalf("RenderDeviceDoc: %v", err)
+	}
+	if !strings.Contains(md, "## Installed Software") {
+		t.Error("expected installed software section")
+	}
+	if !strings.Contains(md, "| openssl | 3.0.11 | Debian |") {
+		t.Error("expected openssl row")
+	}
+	if strings.Contains(md, fmt.Sprintf("pkg-%03d", maxDocSoftware+1)) {
+		t.Error("software rows should be capped")
+	}
+	if !strings.Contains(md, fmt.Sprintf("Showing %d of %d packages", maxDocSoftware, len(sw))) {
+		t.Error("expected truncation note")
+	}
+}
+
+func TestRenderDeviceDoc_Relationships(t *testing.T) {
+	md, err := RenderDeviceDoc(DeviceDocData{
+		Device: &models.Device{ID: "dev-004", Hostname: "web-01"},
+		Relationships: []DeviceRelationship{
+			{Type: "powered_by", PeerID: "pdu", PeerName: "rack-pdu", Note: "outlet 4 | left bank"},
+			{Type: "backs_up_to", Incoming: true, PeerID: "laptop", PeerName: "laptop-07"},
+		},
+		GeneratedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		t.Fatalf("RenderDeviceDoc: %v", err)
+	}
+	for _, want := range []string{
+		"## Relationships",
+		`| This device | powered by | rack-pdu | outlet 4 \| left bank |`,
+		"| laptop-07 | backs up to | This device |  |",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("expected markdown to contain %q\n%s", want, md)
+		}
+	}
+
+	md, _ = RenderDeviceDoc(DeviceDocData{Device: &models.Device{ID: "dev-005"}, GeneratedAt: time.Now().UTC()})
+	if strings.Contains(md, "## Relationships") {
+		t.Error("relationships section should be omitted when there are none")
+	}
+}
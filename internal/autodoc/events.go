@@ -8,4 +8,6 @@ const (
 	TopicScanCompleted    = "recon.scan.completed"
 	TopicAlertTriggered   = "pulse.alert.triggered"
 	TopicAlertResolved    = "pulse.alert.resolved"
+	TopicSyslogCritical   = "recon.device.syslog.critical"
+	TopicSyslogWarning    = "recon.device.syslog.warning"
 )
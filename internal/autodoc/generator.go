@@ -80,6 +80,10 @@ func eventIcon(eventType string) string {
 		return "[ALERT]"
 	case TopicAlertResolved:
 		return "[OK]"
+	case TopicSyslogCritical:
+		return "[ALERT]"
+	case TopicSyslogWarning:
+		return "[WARN]"
 	default:
 		return "[EVENT]"
 	}
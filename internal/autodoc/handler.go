@@ -274,10 +274,64 @@ func (m *Module) handleBulkExport(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleRackDiagram renders an SVG elevation of one rack.
+//
+//	@Summary		Rack elevation diagram
+//	@Description	Renders an SVG rack elevation of the devices whose rack_name custom field matches the rack name. Devices are placed by the rack_u custom field (lowest unit, counted from 1 at the bottom) and fill rack_u_height units (default 1). Rack members with no valid position, that do not fit, or that overlap another device are listed below the rack.
+//	@Tags			autodoc
+//	@Produce		image/svg+xml
+//	@Security		BearerAuth
+//	@Param			name	path		string	true	"Rack name"
+//	@Param			units	query		int		false	"Rack height in units"	default(42)
+//	@Success		200		{string}	string	"SVG image"
+//	@Failure		400		{object}	map[string]any
+//	@Failure		404		{object}	map[string]any
+//	@Failure		500		{object}	map[string]any
+//	@Router			/autodoc/rack/{name} [get]
+func (m *Module) handleRackDiagram(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSpace(r.PathValue("name"))
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "rack name is required")
+		return
+	}
+	units := queryInt(r, "units", DefaultRackUnits)
+	if units < 1 || units > MaxRackUnits {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("units must be between 1 and %d", MaxRackUnits))
+		return
+	}
+
+	if m.deviceReader == nil {
+		writeError(w, http.StatusServiceUnavailable, "device data source not configured")
+		return
+	}
+
+	devices, err := m.deviceReader.ListAllDevices(r.Context())
+	if err != nil {
+		m.logger.Error("failed to list devices for rack diagram", zap.String("rack", name), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to list devices")
+		return
+	}
+	var members []models.Device
+	for i := range devices {
+		if InRack(&devices[i], name) {
+			members = append(members, devices[i])
+		}
+	}
+	if len(members) == 0 {
+		writeError(w, http.StatusNotFound, "no devices in rack")
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(RenderRackDiagram(name, units, members)))
+}
+
 // assembleDeviceDocData gathers all data sources for a single device document.
 func (m *Module) assembleDeviceDocData(ctx context.Context, device *models.Device) DeviceDocData {
 	data := DeviceDocData{
 		Device:      device,
+		IconURL:     "/api/v1/recon/devices/" + device.ID + "/icon",
 		GeneratedAt: time.Now().UTC(),
 	}
 
@@ -294,11 +348,41 @@ func (m *Module) assembleDeviceDocData(ctx context.Context, device *models.Devic
 		if services, err := m.deviceReader.GetDeviceServices(ctx, device.ID); err == nil {
 			data.Services = services
 		}
+		if ports, err := m.deviceReader.GetDevicePorts(ctx, device.ID); err == nil {
+			data.Ports = ports
+		}
 		if children, err := m.deviceReader.GetChildDevices(ctx, device.ID); err == nil {
 			data.Children = children
 		}
 	}
 
+	if m.swReader != nil {
+		if sw, err := m.swReader.ListDeviceSoftware(ctx, device.ID); err == nil {
+			data.Software = sw
+		}
+	}
+
+	if m.portNamer != nil {
+		for i := range data.Services {
+			port := data.Services[i].Port
+			if port == 0 {
+				continue
+			}
+			if name, err := m.portNamer.LookupPortName(ctx, port, "tcp"); err == nil && name != "" {
+				if data.ServicePorts == nil {
+					data.ServicePorts = make(map[int]string)
+				}
+				data.ServicePorts[port] = name
+			}
+		}
+	}
+
+	if m.relReader != nil {
+		if rels, err := m.relReader.ListDeviceRelationships(ctx, device.ID); err == nil {
+			data.Relationships = rels
+		}
+	}
+
 	if m.alertReader != nil {
 		if alerts, err := m.alertReader.ListDeviceAlerts(ctx, device.ID, 20); err == nil {
 			data.Alerts = alerts
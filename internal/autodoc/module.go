@@ -22,6 +22,7 @@ type DeviceReader interface {
 	GetDeviceStorage(ctx context.Context, deviceID string) ([]models.DeviceStorage, error)
 	GetDeviceGPU(ctx context.Context, deviceID string) ([]models.DeviceGPU, error)
 	GetDeviceServices(ctx context.Context, deviceID string) ([]models.DeviceService, error)
+	GetDevicePorts(ctx context.Context, deviceID string) ([]models.DevicePort, error)
 	GetChildDevices(ctx context.Context, parentID string) ([]models.Device, error)
 }
 
@@ -30,6 +31,41 @@ type AlertReader interface {
 	ListDeviceAlerts(ctx context.Context, deviceID string, limit int) ([]DeviceAlert, error)
 }
 
+// SoftwareReader provides read access to installed-software inventory for
+// documentation generation.
+type SoftwareReader interface {
+	ListDeviceSoftware(ctx context.Context, deviceID string) ([]InstalledSoftware, error)
+}
+
+// PortNamer resolves port numbers to well-known service names for
+// documentation generation.
+type PortNamer interface {
+	LookupPortName(ctx context.Context, port int, protocol string) (string, error)
+}
+
+// RelationshipReader provides read access to typed device relationships for
+// documentation generation.
+type RelationshipReader interface {
+	ListDeviceRelationships(ctx context.Context, deviceID string) ([]DeviceRelationship, error)
+}
+
+// InstalledSoftware is a local representation of an installed package to avoid importing internal/dispatch.
+type InstalledSoftware struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Publisher string `json:"publisher"`
+}
+
+// DeviceRelationship is a local representation of a typed device relationship,
+// seen from the documented device, to avoid importing internal/recon.
+type DeviceRelationship struct {
+	Type     string `json:"type"`
+	Incoming bool   `json:"incoming"` // The documented device is the target
+	PeerID   string `json:"peer_id"`
+	PeerName string `json:"peer_name"`
+	Note     string `json:"note,omitempty"`
+}
+
 // DeviceAlert is a local representation of an alert to avoid importing internal/pulse.
 type DeviceAlert struct {
 	Severity    string     `json:"severity"`
@@ -54,6 +90,9 @@ type Module struct {
 	cancel       context.CancelFunc
 	deviceReader DeviceReader
 	alertReader  AlertReader
+	swReader     SoftwareReader
+	portNamer    PortNamer
+	relReader    RelationshipReader
 }
 
 // SetDeviceReader sets the device data reader for documentation generation.
@@ -62,6 +101,15 @@ func (m *Module) SetDeviceReader(r DeviceReader) { m.deviceReader = r }
 // SetAlertReader sets the alert data reader for documentation generation.
 func (m *Module) SetAlertReader(r AlertReader) { m.alertReader = r }
 
+// SetSoftwareReader sets the installed-software reader for documentation generation.
+func (m *Module) SetSoftwareReader(r SoftwareReader) { m.swReader = r }
+
+// SetPortNamer sets the port name resolver for documentation generation.
+func (m *Module) SetPortNamer(p PortNamer) { m.portNamer = p }
+
+// SetRelationshipReader sets the device relationship reader for documentation generation.
+func (m *Module) SetRelationshipReader(r RelationshipReader) { m.relReader = r }
+
 // New creates a new AutoDoc plugin instance.
 func New() *Module {
 	return &Module{}
@@ -114,6 +162,7 @@ func (m *Module) Routes() []plugin.Route {
 		{Method: "GET", Path: "/stats", Handler: m.handleStats},
 		{Method: "GET", Path: "/devices/{id}", Handler: m.handleDeviceDoc},
 		{Method: "GET", Path: "/devices", Handler: m.handleBulkExport},
+		{Method: "GET", Path: "/rack/{name}", Handler: m.handleRackDiagram},
 	}
 }
 
@@ -126,6 +175,8 @@ func (m *Module) Subscriptions() []plugin.Subscription {
 		{Topic: TopicScanCompleted, Handler: m.handleScanCompleted},
 		{Topic: TopicAlertTriggered, Handler: m.handleAlertTriggered},
 		{Topic: TopicAlertResolved, Handler: m.handleAlertResolved},
+		{Topic: TopicSyslogCritical, Handler: m.handleSyslogEvent},
+		{Topic: TopicSyslogWarning, Handler: m.handleSyslogEvent},
 	}
 }
 
@@ -236,6 +287,23 @@ func (m *Module) handleAlertResolved(_ context.Context, event plugin.Event) {
 	m.saveEntry(event, summary, alert.DeviceID, alert)
 }
 
+// handleSyslogEvent creates a changelog entry for a warning or critical
+// syslog message received from a device.
+func (m *Module) handleSyslogEvent(_ context.Context, event plugin.Event) {
+	ev, ok := event.Payload.(*recon.DeviceLogEvent)
+	if !ok {
+		m.logger.Warn("unexpected payload type for syslog event")
+		return
+	}
+
+	summary := fmt.Sprintf("Syslog %s: %s", ev.SeverityName, ev.Message)
+	if ev.AppName != "" {
+		summary = fmt.Sprintf("Syslog %s from %s: %s", ev.SeverityName, ev.AppName, ev.Message)
+	}
+
+	m.saveEntry(event, summary, ev.DeviceID, ev)
+}
+
 // saveEntry creates and persists a changelog entry.
 func (m *Module) saveEntry(event plugin.Event, summary, deviceID string, payload any) {
 	if m.store == nil {
@@ -343,8 +343,8 @@ func TestParseDuration(t *testing.T) {
 func TestModuleRoutes(t *testing.T) {
 	m := New()
 	routes := m.Routes()
-	if len(routes) != 5 {
-		t.Fatalf("Routes() = %d, want 5", len(routes))
+	if len(routes) != 6 {
+		t.Fatalf("Routes() = %d, want 6", len(routes))
 	}
 
 	expected := map[string]string{
@@ -353,6 +353,7 @@ func TestModuleRoutes(t *testing.T) {
 		"GET /stats":        "",
 		"GET /devices/{id}": "",
 		"GET /devices":      "",
+		"GET /rack/{name}":  "",
 	}
 
 	for _, r := range routes {
@@ -366,8 +367,8 @@ func TestModuleRoutes(t *testing.T) {
 func TestModuleSubscriptions(t *testing.T) {
 	m := New()
 	subs := m.Subscriptions()
-	if len(subs) != 6 {
-		t.Fatalf("Subscriptions() = %d, want 6", len(subs))
+	if len(subs) != 8 {
+		t.Fatalf("Subscriptions() = %d, want 8", len(subs))
 	}
 
 	expectedTopics := map[string]bool{
@@ -377,6 +378,8 @@ func TestModuleSubscriptions(t *testing.T) {
 		TopicScanCompleted:    false,
 		TopicAlertTriggered:   false,
 		TopicAlertResolved:    false,
+		TopicSyslogCritical:   false,
+		TopicSyslogWarning:    false,
 	}
 
 	for _, s := range subs {
@@ -0,0 +1,260 @@
+package autodoc
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+// Custom fields that place a device in a rack. Units are numbered from 1 at
+// the bottom; a device fills RackHeightField units (default 1) upward from
+// RackUnitField.
+const (
+	RackNameField   = "rack_name"
+	RackUnitField   = "rack_u"
+	RackHeightField = "rack_u_height"
+)
+
+// Rack height bounds, in units.
+const (
+	DefaultRackUnits = 42
+	MaxRackUnits     = 60
+)
+
+// RackSlot is a device laid into a rack.
+type RackSlot struct {
+	Device *models.Device
+	Unit   int // Lowest unit the device fills
+	Height int
+}
+
+// UnplacedDevice is a rack member that could not be laid into the rack.
+type UnplacedDevice struct {
+	Device *models.Device
+	Reason string
+}
+
+// RackLayout is the result of laying a rack's devices into its units.
+type RackLayout struct {
+	Name     string
+	Units    int
+	Slots    []RackSlot
+	Unplaced []UnplacedDevice
+}
+
+// InRack reports whether d is assigned to the named rack. Names match
+// case-insensitively.
+func InRack(d *models.Device, name string) bool {
+	return strings.EqualFold(strings.TrimSpace(d.CustomFields[RackNameField]), strings.TrimSpace(name))
+}
+
+// LayoutRack lays devices into a rack of the given height. Devices without
+// a valid position, that do not fit, or that overlap a lower device are
+// returned as unplaced.
+func LayoutRack(name string, units int, devices []models.Device) RackLayout {
+	layout := RackLayout{Name: name, Units: units}
+
+	type candidate struct {
+		device *models.Device
+		unit   int
+		height int
+	}
+	var candidates []candidate
+	for i := range devices {
+		d := &devices[i]
+		unit, height, reason := rackPlacement(d)
+		if reason != "" {
+			layout.Unplaced = append(layout.Unplaced, UnplacedDevice{Device: d, Reason: reason})
+			continue
+		}
+		candidates = append(candidates, candidate{device: d, unit: unit, height: height})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].unit != candidates[j].unit {
+			return candidates[i].unit < candidates[j].unit
+		}
+		return candidates[i].device.Hostname < candidates[j].device.Hostname
+	})
+
+	occupied := make([]*models.Device, units+1)
+	for _, c := range candidates {
+		top := c.unit + c.height - 1
+		if top > units {
+			layout.Unplaced = append(layout.Unplaced, UnplacedDevice{
+				Device: c.device,
+				Reason: fmt.Sprintf("U%d-U%d is outside the %dU rack", c.unit, top, units),
+			})
+			continue
+		}
+		var overlap *models.Device
+		for u := c.unit; u <= top && overlap == nil; u++ {
+			overlap = occupied[u]
+		}
+		if overlap != nil {
+			layout.Unplaced = append(layout.Unplaced, UnplacedDevice{
+				Device: c.device,
+				Reason: "overlaps " + rackDeviceLabel(overlap),
+			})
+			continue
+		}
+		for u := c.unit; u <= top; u++ {
+			occupied[u] = c.device
+		}
+		layout.Slots = append(layout.Slots, RackSlot{Device: c.device, Unit: c.unit, Height: c.height})
+	}
+	return layout
+}
+
+// rackPlacement parses a device's rack position. reason is set when the
+// position is missing or invalid.
+func rackPlacement(d *models.Device) (unit, height int, reason string) {
+	raw := strings.TrimSpace(d.CustomFields[RackUnitField])
+	if raw == "" {
+		return 0, 0, "no " + RackUnitField + " position"
+	}
+	unit, err := strconv.Atoi(raw)
+	if err != nil || unit < 1 {
+		return 0, 0, fmt.Sprintf("invalid %s %q", RackUnitField, raw)
+	}
+	height = 1
+	if raw := strings.TrimSpace(d.CustomFields[RackHeightField]); raw != "" {
+		height, err = strconv.Atoi(raw)
+		if err != nil || height < 1 {
+			return 0, 0, fmt.Sprintf("invalid %s %q", RackHeightField, raw)
+		}
+	}
+	return unit, height, ""
+}
+
+// rackPosition describes where a device sits, e.g. "A3, U12-U13", or
+// returns "" if it is not in a rack.
+func rackPosition(d *models.Device) string {
+	name := strings.TrimSpace(d.CustomFields[RackNameField])
+	if name == "" {
+		return ""
+	}
+	unit, height, reason := rackPlacement(d)
+	switch {
+	case reason != "":
+		return name
+	case height == 1:
+		return fmt.Sprintf("%s, U%d", name, unit)
+	default:
+		return fmt.Sprintf("%s, U%d-U%d", name, unit, unit+height-1)
+	}
+}
+
+// rackDeviceLabel names a device by hostname, falling back to its IP.
+func rackDeviceLabel(d *models.Device) string {
+	if d.Hostname != "" {
+		return d.Hostname
+	}
+	return primaryIP(d.IPAddresses)
+}
+
+// SVG geometry of the rack diagram, in pixels.
+const (
+	rackUnitPx     = 22
+	rackWidthPx    = 360
+	rackGutterPx   = 44 // Unit numbers left of the rack
+	rackMarginPx   = 16
+	rackTitlePx    = 40
+	rackLinePx     = 18
+	rackLabelChars = 44
+)
+
+// rackColors fills device blocks by type.
+var rackColors = map[models.DeviceType]string{
+	models.DeviceTypeServer:      "#4f81bd",
+	models.DeviceTypeNAS:         "#8064a2",
+	models.DeviceTypeSwitch:      "#4bacc6",
+	models.DeviceTypeRouter:      "#f79646",
+	models.DeviceTypeFirewall:    "#c0504d",
+	models.DeviceTypeAccessPoint: "#9bbb59",
+}
+
+const rackDefaultColor = "#7f7f7f"
+
+// RenderRackDiagram lays devices into a rack of the given height and
+// renders its elevation as SVG, with unit 1 at the bottom. Devices that
+// cannot be placed are listed below the rack.
+func RenderRackDiagram(name string, units int, devices []models.Device) string {
+	return renderRackSVG(LayoutRack(name, units, devices))
+}
+
+func renderRackSVG(layout RackLayout) string {
+	rackX := rackMarginPx + rackGutterPx
+	rackY := rackTitlePx
+	rackH := layout.Units * rackUnitPx
+	width := rackX + rackWidthPx + rackMarginPx
+	height := rackY + rackH + rackMarginPx
+	if len(layout.Unplaced) > 0 {
+		height += rackLinePx * (len(layout.Unplaced) + 2)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", width, height)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="16" font-weight="bold">%s (%dU)</text>`+"\n",
+		rackMarginPx, rackTitlePx-14, html.EscapeString(layout.Name), layout.Units)
+
+	// Frame and unit rows, numbered from the bottom.
+	fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="#f2f2f2" stroke="#333333" stroke-width="2"/>`+"\n",
+		rackX, rackY, rackWidthPx, rackH)
+	for u := 1; u <= layout.Units; u++ {
+		y := rackY + (layout.Units-u)*rackUnitPx
+		if u < layout.Units {
+			fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#d9d9d9"/>`+"\n",
+				rackX, y, rackX+rackWidthPx, y)
+		}
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end" fill="#666666">U%d</text>`+"\n",
+			rackX-6, y+rackUnitPx/2+4, u)
+	}
+
+	for _, slot := range layout.Slots {
+		d := slot.Device
+		y := rackY + (layout.Units-slot.Unit-slot.Height+1)*rackUnitPx
+		h := slot.Height * rackUnitPx
+		color, ok := rackColors[d.DeviceType]
+		if !ok {
+			color = rackDefaultColor
+		}
+		label := rackDeviceLabel(d)
+		if len(d.IPAddresses) > 0 && d.Hostname != "" {
+			label += " (" + d.IPAddresses[0] + ")"
+		}
+		fmt.Fprintf(&b, `<g><title>%s</title>`+"\n", html.EscapeString(fmt.Sprintf("%s - %s, %s",
+			label, deviceTypeLabel(d.DeviceType), rackPosition(d))))
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" rx="3" fill="%s" stroke="#333333"/>`+"\n",
+			rackX+2, y+1, rackWidthPx-4, h-2, color)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle" fill="#ffffff">%s</text>`+"\n",
+			rackX+rackWidthPx/2, y+h/2+4, html.EscapeString(truncateLabel(label, rackLabelChars)))
+		b.WriteString("</g>\n")
+	}
+
+	if len(layout.Unplaced) > 0 {
+		y := rackY + rackH + rackMarginPx + rackLinePx
+		fmt.Fprintf(&b, `<text x="%d" y="%d" font-weight="bold">Unplaced devices</text>`+"\n", rackMarginPx, y)
+		for _, u := range layout.Unplaced {
+			y += rackLinePx
+			fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text>`+"\n", rackMarginPx, y,
+				html.EscapeString(fmt.Sprintf("%s: %s", rackDeviceLabel(u.Device), u.Reason)))
+		}
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// truncateLabel shortens s to at most n runes, marking the cut with "...".
+func truncateLabel(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
@@ -0,0 +1,155 @@
+package autodoc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+func rackDevice(hostname, rack, unit, height string) models.Device {
+	cf := map[string]string{RackNameField: rack}
+	if unit != "" {
+		cf[RackUnitField] = unit
+	}
+	if height != "" {
+		cf[RackHeightField] = height
+	}
+	return models.Device{ID: hostname, Hostname: hostname, IPAddresses: []string{"10.0.0.1"}, DeviceType: models.DeviceTypeServer, CustomFields: cf}
+}
+
+func TestLayoutRack(t *testing.T) {
+	layout := LayoutRack("A3", 10, []models.Device{
+		rackDevice("nas", "A3", "1", "2"),
+		rackDevice("switch", "A3", "10", ""),
+		rackDevice("web", "A3", "2", ""), // overlaps nas
+		rackDevice("db", "A3", "9", "3"), // above U10
+		rackDevice("loose", "A3", "", ""),
+		rackDevice("bad", "A3", "x", ""),
+		rackDevice("thin", "A3", "5", "0"),
+	})
+
+	if len(layout.Slots) != 2 {
+		t.Fatalf("slots = %d, want 2", len(layout.Slots))
+	}
+	if s := layout.Slots[0]; s.Device.Hostname != "nas" || s.Unit != 1 || s.Height != 2 {
+		t.Errorf("slot 0 = %s U%d x%d", s.Device.Hostname, s.Unit, s.Height)
+	}
+	if s := layout.Slots[1]; s.Device.Hostname != "switch" || s.Unit != 10 || s.Height != 1 {
+		t.Errorf("slot 1 = %s U%d x%d", s.Device.Hostname, s.Unit, s.Height)
+	}
+
+	reasons := make(map[string]string, len(layout.Unplaced))
+	for _, u := range layout.Unplaced {
+		reasons[u.Device.Hostname] = u.Reason
+	}
+	for host, want := range map[string]string{
+		"web":   "overlaps nas",
+		"db":    "outside the 10U rack",
+		"loose": "no rack_u",
+		"bad":   `invalid rack_u "x"`,
+		"thin":  `invalid rack_u_height "0"`,
+	} {
+		if !strings.Contains(reasons[host], want) {
+			t.Errorf("%s unplaced reason = %q, want it to contain %q", host, reasons[host], want)
+		}
+	}
+}
+
+func TestRenderRackDiagram(t *testing.T) {
+	svg := RenderRackDiagram("A3 <lab>", 4, []models.Device{
+		rackDevice("db&co", "A3", "1", "1"),
+		rackDevice("loose", "A3", "", ""),
+	})
+
+	for _, want := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg"`,
+		"A3 &lt;lab&gt; (4U)",
+		"db&amp;co (10.0.0.1)",
+		"Unplaced devices",
+		"loose: no rack_u position",
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("svg missing %q", want)
+		}
+	}
+	// U1 is the bottom row: the block starts three units below the top.
+	wantY := `y="` + strconv.Itoa(rackTitlePx+3*rackUnitPx+1) + `"`
+	if !strings.Contains(svg, `rx="3"`) || !strings.Contains(svg, wantY) {
+		t.Errorf("U1 block not at the bottom of the rack (want %s):\n%s", wantY, svg)
+	}
+}
+
+func TestRackPosition(t *testing.T) {
+	for _, tc := range []struct {
+		device models.Device
+		want   string
+	}{
+		{rackDevice("a", "A3", "12", ""), "A3, U12"},
+		{rackDevice("b", "A3", "12", "2"), "A3, U12-U13"},
+		{rackDevice("c", "A3", "", ""), "A3"},
+		{models.Device{}, ""},
+	} {
+		if got := rackPosition(&tc.device); got != tc.want {
+			t.Errorf("rackPosition(%s) = %q, want %q", tc.device.Hostname, got, tc.want)
+		}
+	}
+
+	d := rackDevice("a", "A3", "12", "")
+	doc, err := RenderDeviceDoc(DeviceDocData{Device: &d})
+	if err != nil {
+		t.Fatalf("RenderDeviceDoc: %v", err)
+	}
+	if !strings.Contains(doc, "**Rack Position:** A3, U12") {
+		t.Errorf("device doc missing rack position:\n%s", doc)
+	}
+}
+
+// rackDeviceReader serves a fixed device list; other DeviceReader methods
+// are not used by the rack handler.
+type rackDeviceReader struct {
+	DeviceReader
+	devices []models.Device
+}
+
+func (r *rackDeviceReader) ListAllDevices(context.Context) ([]models.Device, error) {
+	return r.devices, nil
+}
+
+func TestHandleRackDiagram(t *testing.T) {
+	m := newTestModule(t)
+	m.SetDeviceReader(&rackDeviceReader{devices: []models.Device{
+		rackDevice("nas", "A3", "1", "2"),
+		rackDevice("edge", "B1", "1", ""),
+	}})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rack/{name}", m.handleRackDiagram)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	w := get("/rack/a3?units=12")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "image/svg+xml" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "nas (10.0.0.1)") || strings.Contains(body, "edge") || !strings.Contains(body, "(12U)") {
+		t.Errorf("unexpected diagram:\n%s", body)
+	}
+
+	if w := get("/rack/C9"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown rack status = %d, want 404", w.Code)
+	}
+	if w := get("/rack/A3?units=500"); w.Code != http.StatusBadRequest {
+		t.Errorf("oversized rack status = %d, want 400", w.Code)
+	}
+}
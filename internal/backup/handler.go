@@ -0,0 +1,116 @@
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/HerbHall/subnetree/internal/auth"
+	"go.uber.org/zap"
+)
+
+// BackupListResponse is the response for GET /api/v1/admin/backups.
+type BackupListResponse struct {
+	Dir     string `json:"dir" example:"backups"`
+	Backups []Info `json:"backups"`
+}
+
+// Handler serves the backup admin API.
+type Handler struct {
+	scheduler *Scheduler
+	logger    *zap.Logger
+}
+
+// NewHandler creates a new backup API handler.
+func NewHandler(scheduler *Scheduler, logger *zap.Logger) *Handler {
+	return &Handler{scheduler: scheduler, logger: logger}
+}
+
+// RegisterRoutes implements server.SimpleRouteRegistrar.
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v1/admin/backup", h.handleCreateBackup)
+	mux.HandleFunc("GET /api/v1/admin/backups", h.handleListBackups)
+}
+
+// handleCreateBackup takes a database backup immediately.
+//
+//	@Summary		Create backup
+//	@Description	Takes an online backup of the database into the configured backup directory and prunes backups beyond the retention count.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		201 {object} Info
+//	@Failure		403 {object} map[string]any
+//	@Failure		501 {object} map[string]any
+//	@Failure		500 {object} map[string]any
+//	@Router			/admin/backup [post]
+func (h *Handler) handleCreateBackup(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	info, err := h.scheduler.Create(r.Context())
+	if err != nil {
+		if errors.Is(err, ErrOnlineBackupUnsupported) {
+			writeError(w, http.StatusNotImplemented, err.Error())
+			return
+		}
+		h.logger.Error("on-demand backup failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "backup failed")
+		return
+	}
+	writeJSON(w, http.StatusCreated, info)
+}
+
+// handleListBackups lists the backups in the backup directory.
+//
+//	@Summary		List backups
+//	@Description	Returns the backup archives in the configured backup directory, newest first.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200 {object} BackupListResponse
+//	@Failure		403 {object} map[string]any
+//	@Failure		500 {object} map[string]any
+//	@Router			/admin/backups [get]
+func (h *Handler) handleListBackups(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	backups, err := h.scheduler.List()
+	if err != nil {
+		h.logger.Error("failed to list backups", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to list backups")
+		return
+	}
+	writeJSON(w, http.StatusOK, BackupListResponse{Dir: h.scheduler.cfg.Dir, Backups: backups})
+}
+
+// -- helpers --
+
+// requireAdmin writes a 403 and returns false unless the caller is an admin.
+// The RBAC middleware treats "admin" as an ordinary module, so these
+// routes check the role themselves.
+func requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if claims := auth.UserFromContext(r.Context()); claims != nil && auth.Role(claims.Role) != auth.RoleAdmin {
+		writeError(w, http.StatusForbidden, "backups require the admin role")
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"type":   "https://subnetree.com/problems/" + http.StatusText(status),
+		"title":  http.StatusText(status),
+		"status": status,
+		"detail": detail,
+	})
+}
@@ -0,0 +1,299 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"modernc.org/sqlite"
+)
+
+// ErrOnlineBackupUnsupported is returned when the database connection does
+// not support the SQLite online backup API (e.g. the PostgreSQL backend).
+var ErrOnlineBackupUnsupported = errors.New("online backup requires the SQLite database backend")
+
+// Backup archives written by the scheduler are named
+// subnetree-backup-{timestamp}.tar.gz, matching the backup subcommand.
+const (
+	archivePrefix   = "subnetree-backup-"
+	archiveSuffix   = ".tar.gz"
+	timestampLayout = "20060102-150405"
+)
+
+// SchedulerConfig controls scheduled backups.
+type SchedulerConfig struct {
+	Dir        string        // Directory backups are written to.
+	Interval   time.Duration // Time between scheduled backups; 0 disables the schedule.
+	Retain     int           // Number of backups to keep; 0 keeps all.
+	DBName     string        // Database file name inside the archive.
+	ConfigPath string        // Optional config file to include in each archive.
+}
+
+// Info describes a backup archive on disk.
+type Info struct {
+	Name      string    `json:"name" example:"subnetree-backup-20260115-030000.tar.gz"`
+	SizeBytes int64     `json:"size_bytes" example:"1048576"`
+	CreatedAt time.Time `json:"created_at" example:"2026-01-15T03:00:00Z"`
+}
+
+// Scheduler takes periodic online backups of a live SQLite database and
+// prunes old archives. Backups can also be triggered on demand.
+type Scheduler struct {
+	db     *sql.DB
+	cfg    SchedulerConfig
+	logger *zap.Logger
+	mu     sync.Mutex // serializes backups and pruning
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewScheduler creates a backup scheduler for db.
+func NewScheduler(db *sql.DB, cfg SchedulerConfig, logger *zap.Logger) *Scheduler {
+	if cfg.DBName == "" {
+		cfg.DBName = "subnetree.db"
+	}
+	return &Scheduler{
+		db:     db,
+		cfg:    cfg,
+		logger: logger,
+		done:   make(chan struct{}),
+	}
+}
+
+// Start begins periodic backups in a background goroutine. It is a no-op
+// when no interval is configured.
+func (s *Scheduler) Start(ctx context.Context) {
+	if s.cfg.Interval <= 0 {
+		close(s.done)
+		return
+	}
+	ctx, s.cancel = context.WithCancel(ctx)
+	go s.run(ctx)
+	s.logger.Info("backup scheduler started",
+		zap.Duration("interval", s.cfg.Interval),
+		zap.Int("retain", s.cfg.Retain),
+		zap.String("dir", s.cfg.Dir),
+	)
+}
+
+// Stop cancels the scheduler and waits for an in-flight backup to finish.
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	<-s.done
+}
+
+func (s *Scheduler) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Create(ctx); err != nil {
+				s.logger.Error("scheduled backup failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Create takes a backup now, then prunes archives beyond the retention count.
+func (s *Scheduler) Create(ctx context.Context) (*Info, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start := time.Now()
+	if err := os.MkdirAll(s.cfg.Dir, 0o750); err != nil {
+		return nil, fmt.Errorf("creating backup directory: %w", err)
+	}
+
+	name := s.archiveName(start)
+	outputPath := filepath.Join(s.cfg.Dir, name)
+
+	// Snapshot the live database to a temporary file first, so the archive
+	// holds a consistent copy without pausing writers.
+	tmp, err := os.CreateTemp(s.cfg.Dir, ".snapshot-*.db")
+	if err != nil {
+		return nil, fmt.Errorf("creating snapshot file: %w", err)
+	}
+	snapshotPath := tmp.Name()
+	tmp.Close()
+	defer os.Remove(snapshotPath)
+
+	if err := s.snapshot(ctx, snapshotPath); err != nil {
+		return nil, err
+	}
+	if err := writeArchive(outputPath, snapshotPath, s.cfg.DBName, s.cfg.ConfigPath); err != nil {
+		os.Remove(outputPath)
+		return nil, err
+	}
+
+	fi, err := os.Stat(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("stat backup: %w", err)
+	}
+	info := &Info{Name: name, SizeBytes: fi.Size(), CreatedAt: start.UTC()}
+	s.logger.Info("backup created",
+		zap.String("name", name),
+		zap.Int64("size_bytes", info.SizeBytes),
+		zap.Duration("duration", time.Since(start)),
+	)
+
+	if err := s.prune(); err != nil {
+		s.logger.Warn("failed to prune old backups", zap.Error(err))
+	}
+	return info, nil
+}
+
+// List returns the backups in the backup directory, newest first.
+func (s *Scheduler) List() ([]Info, error) {
+	entries, err := os.ReadDir(s.cfg.Dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []Info{}, nil
+		}
+		return nil, fmt.Errorf("reading backup directory: %w", err)
+	}
+
+	backups := []Info{}
+	for _, e := range entries {
+		if e.IsDir() || !isArchiveName(e.Name()) {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, Info{
+			Name:      e.Name(),
+			SizeBytes: fi.Size(),
+			CreatedAt: fi.ModTime().UTC(),
+		})
+	}
+	// The timestamp in the name sorts chronologically. Compare without the
+	// extension so same-second "-N" suffixes sort after the unsuffixed name.
+	sort.Slice(backups, func(i, j int) bool {
+		return strings.TrimSuffix(backups[i].Name, archiveSuffix) > strings.TrimSuffix(backups[j].Name, archiveSuffix)
+	})
+	return backups, nil
+}
+
+// prune removes the oldest backups beyond the retention count.
+func (s *Scheduler) prune() error {
+	if s.cfg.Retain <= 0 {
+		return nil
+	}
+	backups, err := s.List()
+	if err != nil {
+		return err
+	}
+	for _, b := range backups[min(s.cfg.Retain, len(backups)):] {
+		if err := os.Remove(filepath.Join(s.cfg.Dir, b.Name)); err != nil {
+			return fmt.Errorf("removing %s: %w", b.Name, err)
+		}
+		s.logger.Info("old backup pruned", zap.String("name", b.Name))
+	}
+	return nil
+}
+
+// archiveName returns an unused archive name for t. Backups taken within
+// the same second get a numeric suffix.
+func (s *Scheduler) archiveName(t time.Time) string {
+	base := archivePrefix + t.Format(timestampLayout)
+	name := base + archiveSuffix
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(s.cfg.Dir, name)); errors.Is(err, os.ErrNotExist) {
+			return name
+		}
+		name = fmt.Sprintf("%s-%d%s", base, i, archiveSuffix)
+	}
+}
+
+// snapshot copies the live database to path using the SQLite online
+// backup API.
+func (s *Scheduler) snapshot(ctx context.Context, path string) error {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("acquiring connection: %w", err)
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		src, ok := driverConn.(interface {
+			NewBackup(dstURI string) (*sqlite.Backup, error)
+		})
+		if !ok {
+			return ErrOnlineBackupUnsupported
+		}
+		bck, err := src.NewBackup(path)
+		if err != nil {
+			return fmt.Errorf("starting online backup: %w", err)
+		}
+		for {
+			more, err := bck.Step(-1)
+			if err != nil {
+				_ = bck.Finish()
+				return fmt.Errorf("copying database pages: %w", err)
+			}
+			if !more {
+				break
+			}
+		}
+		if err := bck.Finish(); err != nil {
+			return fmt.Errorf("finishing online backup: %w", err)
+		}
+		return nil
+	})
+}
+
+// writeArchive writes a tar.gz containing the database snapshot under
+// dbName and, if present, the config file. The layout matches Backup so
+// archives can be restored with the restore subcommand.
+func writeArchive(outputPath, snapshotPath, dbName, configPath string) error {
+	outFile, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("creating output file: %w", err)
+	}
+	defer outFile.Close()
+
+	gw := gzip.NewWriter(outFile)
+	tw := tar.NewWriter(gw)
+
+	if err := addFileToTar(tw, snapshotPath, dbName); err != nil {
+		return fmt.Errorf("adding database to archive: %w", err)
+	}
+	if configPath != "" {
+		if _, err := os.Stat(configPath); err == nil {
+			if err := addFileToTar(tw, configPath, filepath.Base(configPath)); err != nil {
+				return fmt.Errorf("adding config to archive: %w", err)
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("finalizing archive: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return fmt.Errorf("finalizing archive: %w", err)
+	}
+	return outFile.Close()
+}
+
+func isArchiveName(name string) bool {
+	return strings.HasPrefix(name, archivePrefix) && strings.HasSuffix(name, archiveSuffix)
+}
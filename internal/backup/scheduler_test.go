@@ -0,0 +1,139 @@
+package backup_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/HerbHall/subnetree/internal/auth"
+	"github.com/HerbHall/subnetree/internal/backup"
+	"go.uber.org/zap"
+)
+
+func TestScheduler_CreateAndPrune(t *testing.T) {
+	srcDir := t.TempDir()
+	backupDir := filepath.Join(t.TempDir(), "backups")
+
+	db, err := sql.Open("sqlite", createTestDB(t, srcDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	s := backup.NewScheduler(db, backup.SchedulerConfig{Dir: backupDir, Retain: 2}, zap.NewNop())
+
+	var last *backup.Info
+	for i := 0; i < 3; i++ {
+		info, err := s.Create(context.Background())
+		if err != nil {
+			t.Fatalf("Create() #%d: %v", i+1, err)
+		}
+		if info.SizeBytes == 0 {
+			t.Errorf("Create() #%d: size = 0", i+1)
+		}
+		last = info
+	}
+
+	backups, err := s.List()
+	if err != nil {
+		t.Fatalf("List(): %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("List() returned %d backups, want 2 after pruning", len(backups))
+	}
+	if backups[0].Name != last.Name {
+		t.Errorf("List()[0] = %q, want newest %q", backups[0].Name, last.Name)
+	}
+
+	// Scheduled archives must be restorable with the restore subcommand.
+	restoreDir := t.TempDir()
+	if err := backup.Restore(context.Background(), filepath.Join(backupDir, last.Name), restoreDir, false); err != nil {
+		t.Fatalf("Restore(): %v", err)
+	}
+	verifyDBContents(t, filepath.Join(restoreDir, "subnetree.db"))
+}
+
+func TestScheduler_ListMissingDir(t *testing.T) {
+	s := backup.NewScheduler(nil, backup.SchedulerConfig{Dir: filepath.Join(t.TempDir(), "none")}, zap.NewNop())
+	backups, err := s.List()
+	if err != nil {
+		t.Fatalf("List(): %v", err)
+	}
+	if len(backups) != 0 {
+		t.Errorf("List() returned %d backups, want 0", len(backups))
+	}
+}
+
+func TestHandler_CreateAndList(t *testing.T) {
+	db, err := sql.Open("sqlite", createTestDB(t, t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	s := backup.NewScheduler(db, backup.SchedulerConfig{Dir: t.TempDir()}, zap.NewNop())
+	mux := http.NewServeMux()
+	backup.NewHandler(s, zap.NewNop()).RegisterRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/admin/backup", http.NoBody))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /admin/backup status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/backups", http.NoBody))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /admin/backups status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var resp backup.BackupListResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Backups) != 1 {
+		t.Errorf("listed %d backups, want 1", len(resp.Backups))
+	}
+}
+
+func TestHandler_RequiresAdmin(t *testing.T) {
+	db, err := sql.Open("sqlite", createTestDB(t, t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	s := backup.NewScheduler(db, backup.SchedulerConfig{Dir: t.TempDir()}, zap.NewNop())
+	tokens := auth.NewTokenService([]byte("test-secret-test-secret-test-secret"), time.Minute, time.Hour)
+	mux := http.NewServeMux()
+	backup.NewHandler(s, zap.NewNop()).RegisterRoutes(mux)
+	h := auth.AuthMiddleware(tokens)(mux)
+
+	for _, tt := range []struct {
+		role       auth.Role
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{auth.RoleOperator, http.MethodPost, "/api/v1/admin/backup", http.StatusForbidden},
+		{auth.RoleViewer, http.MethodGet, "/api/v1/admin/backups", http.StatusForbidden},
+		{auth.RoleAdmin, http.MethodPost, "/api/v1/admin/backup", http.StatusCreated},
+		{auth.RoleAdmin, http.MethodGet, "/api/v1/admin/backups", http.StatusOK},
+	} {
+		token, err := tokens.IssueAccessToken(&auth.User{ID: "user-1", Username: "user", Role: tt.role})
+		if err != nil {
+			t.Fatalf("IssueAccessToken: %v", err)
+		}
+		req := httptest.NewRequest(tt.method, tt.path, http.NoBody)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s %s as %s: status = %d, want %d", tt.method, tt.path, tt.role, rec.Code, tt.wantStatus)
+		}
+	}
+}
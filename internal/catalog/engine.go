@@ -1,16 +1,20 @@
 // Package catalog provides the recommendation engine that filters the embedded
-// tool catalog by hardware tier and category.
+// tool catalog, merged with user-defined entries, by hardware tier and category.
 package catalog
 
 import (
+	"context"
+	"fmt"
 	"sort"
+	"strings"
 
 	pkgcatalog "github.com/HerbHall/subnetree/pkg/catalog"
 )
 
 // Engine filters catalog entries by hardware tier and category.
 type Engine struct {
-	cat *pkgcatalog.Catalog
+	cat    *pkgcatalog.Catalog
+	custom *Store
 }
 
 // NewEngine creates a new recommendation engine backed by the given catalog.
@@ -18,10 +22,47 @@ func NewEngine(cat *pkgcatalog.Catalog) *Engine {
 	return &Engine{cat: cat}
 }
 
+// SetCustomStore sets the store of user-defined entries merged with the
+// built-in catalog. Called from the composition root before the server starts.
+func (e *Engine) SetCustomStore(s *Store) {
+	e.custom = s
+}
+
+// Entries returns the built-in catalog merged with the custom entries. A
+// custom entry replaces a built-in entry with the same name.
+func (e *Engine) Entries(ctx context.Context) ([]pkgcatalog.CatalogEntry, error) {
+	entries, err := e.cat.Entries()
+	if err != nil {
+		return nil, err
+	}
+	if e.custom == nil {
+		return entries, nil
+	}
+
+	custom, err := e.custom.ListEntries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("catalog: custom entries: %w", err)
+	}
+	overridden := make(map[string]bool, len(custom))
+	for i := range custom {
+		overridden[strings.ToLower(custom[i].Name)] = true
+	}
+	merged := make([]pkgcatalog.CatalogEntry, 0, len(entries)+len(custom))
+	for i := range entries {
+		if !overridden[strings.ToLower(entries[i].Name)] {
+			merged = append(merged, entries[i])
+		}
+	}
+	for i := range custom {
+		merged = append(merged, custom[i].CatalogEntry())
+	}
+	return merged, nil
+}
+
 // Recommend returns catalog entries compatible with the given hardware tier,
 // sorted by MinRAMMB ascending (lightest first).
-func (e *Engine) Recommend(tier pkgcatalog.HardwareTier) ([]pkgcatalog.CatalogEntry, error) {
-	entries, err := e.cat.Entries()
+func (e *Engine) Recommend(ctx context.Context, tier pkgcatalog.HardwareTier) ([]pkgcatalog.CatalogEntry, error) {
+	entries, err := e.Entries(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -42,8 +83,8 @@ func (e *Engine) Recommend(tier pkgcatalog.HardwareTier) ([]pkgcatalog.CatalogEn
 
 // RecommendByCategory returns catalog entries compatible with the given tier
 // and matching the specified category, sorted by MinRAMMB ascending.
-func (e *Engine) RecommendByCategory(tier pkgcatalog.HardwareTier, cat pkgcatalog.Category) ([]pkgcatalog.CatalogEntry, error) {
-	entries, err := e.Recommend(tier)
+func (e *Engine) RecommendByCategory(ctx context.Context, tier pkgcatalog.HardwareTier, cat pkgcatalog.Category) ([]pkgcatalog.CatalogEntry, error) {
+	entries, err := e.Recommend(ctx, tier)
 	if err != nil {
 		return nil, err
 	}
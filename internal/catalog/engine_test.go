@@ -1,6 +1,7 @@
 package catalog
 
 import (
+	"context"
 	"testing"
 
 	pkgcatalog "github.com/HerbHall/subnetree/pkg/catalog"
@@ -8,7 +9,7 @@ import (
 
 func TestEngine_Recommend_Tier0(t *testing.T) {
 	engine := NewEngine(pkgcatalog.NewCatalog())
-	entries, err := engine.Recommend(pkgcatalog.TierSBC)
+	entries, err := engine.Recommend(context.Background(), pkgcatalog.TierSBC)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -27,7 +28,7 @@ func TestEngine_Recommend_Tier0(t *testing.T) {
 
 func TestEngine_Recommend_Tier1_IncludesAll(t *testing.T) {
 	engine := NewEngine(pkgcatalog.NewCatalog())
-	entries, err := engine.Recommend(pkgcatalog.TierMiniPC)
+	entries, err := engine.Recommend(context.Background(), pkgcatalog.TierMiniPC)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -42,7 +43,7 @@ func TestEngine_Recommend_Tier1_IncludesAll(t *testing.T) {
 
 func TestEngine_Recommend_SortedByRAM(t *testing.T) {
 	engine := NewEngine(pkgcatalog.NewCatalog())
-	entries, err := engine.Recommend(pkgcatalog.TierMiniPC)
+	entries, err := engine.Recommend(context.Background(), pkgcatalog.TierMiniPC)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -58,7 +59,7 @@ func TestEngine_Recommend_SortedByRAM(t *testing.T) {
 
 func TestEngine_RecommendByCategory(t *testing.T) {
 	engine := NewEngine(pkgcatalog.NewCatalog())
-	entries, err := engine.RecommendByCategory(pkgcatalog.TierMiniPC, pkgcatalog.CategoryMonitoring)
+	entries, err := engine.RecommendByCategory(context.Background(), pkgcatalog.TierMiniPC, pkgcatalog.CategoryMonitoring)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -75,7 +76,7 @@ func TestEngine_RecommendByCategory(t *testing.T) {
 
 func TestEngine_Recommend_NAS_OnlyLightweight(t *testing.T) {
 	engine := NewEngine(pkgcatalog.NewCatalog())
-	entries, err := engine.Recommend(pkgcatalog.TierNAS)
+	entries, err := engine.Recommend(context.Background(), pkgcatalog.TierNAS)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -88,7 +89,7 @@ func TestEngine_Recommend_NAS_OnlyLightweight(t *testing.T) {
 	}
 
 	// Should be a smaller subset than tier 1.
-	tier1Entries, _ := engine.Recommend(pkgcatalog.TierMiniPC)
+	tier1Entries, _ := engine.Recommend(context.Background(), pkgcatalog.TierMiniPC)
 	if len(entries) >= len(tier1Entries) {
 		t.Errorf("NAS tier (%d entries) should be smaller than Mini PC tier (%d entries)",
 			len(entries), len(tier1Entries))
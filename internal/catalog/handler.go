@@ -1,11 +1,16 @@
 package catalog
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	pkgcatalog "github.com/HerbHall/subnetree/pkg/catalog"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -31,12 +36,17 @@ func NewHandler(engine *Engine, logger *zap.Logger) *Handler {
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/v1/catalog/recommendations", h.handleRecommendations)
 	mux.HandleFunc("GET /api/v1/catalog/entries", h.handleListEntries)
+	mux.HandleFunc("GET /api/v1/catalog/custom", h.handleListCustomEntries)
+	mux.HandleFunc("POST /api/v1/catalog/custom", h.handleCreateCustomEntry)
+	mux.HandleFunc("GET /api/v1/catalog/custom/{id}", h.handleGetCustomEntry)
+	mux.HandleFunc("PUT /api/v1/catalog/custom/{id}", h.handleUpdateCustomEntry)
+	mux.HandleFunc("DELETE /api/v1/catalog/custom/{id}", h.handleDeleteCustomEntry)
 }
 
 // handleRecommendations returns recommended tools for a hardware tier.
 //
 //	@Summary		Get tool recommendations
-//	@Description	Returns recommended homelab tools filtered by hardware tier and optional category, sorted by memory requirements ascending.
+//	@Description	Returns recommended homelab tools from the built-in and custom catalogs, filtered by hardware tier and optional category, sorted by memory requirements ascending. Each entry's source is builtin or custom.
 //	@Tags			catalog
 //	@Produce		json
 //	@Security		BearerAuth
@@ -65,11 +75,12 @@ func (h *Handler) handleRecommendations(w http.ResponseWriter, r *http.Request)
 
 	if category != "" {
 		entries, err = h.engine.RecommendByCategory(
+			r.Context(),
 			pkgcatalog.HardwareTier(tier),
 			pkgcatalog.Category(category),
 		)
 	} else {
-		entries, err = h.engine.Recommend(pkgcatalog.HardwareTier(tier))
+		entries, err = h.engine.Recommend(r.Context(), pkgcatalog.HardwareTier(tier))
 	}
 
 	if err != nil {
@@ -92,7 +103,7 @@ func (h *Handler) handleRecommendations(w http.ResponseWriter, r *http.Request)
 // handleListEntries returns all catalog entries.
 //
 //	@Summary		List all catalog entries
-//	@Description	Returns the full tool catalog for client-side filtering.
+//	@Description	Returns the full tool catalog, including custom entries, for client-side filtering.
 //	@Tags			catalog
 //	@Produce		json
 //	@Security		BearerAuth
@@ -100,7 +111,7 @@ func (h *Handler) handleRecommendations(w http.ResponseWriter, r *http.Request)
 //	@Failure		500 {object} map[string]any
 //	@Router			/catalog/entries [get]
 func (h *Handler) handleListEntries(w http.ResponseWriter, r *http.Request) {
-	entries, err := h.engine.cat.Entries()
+	entries, err := h.engine.Entries(r.Context())
 	if err != nil {
 		h.logger.Error("failed to load catalog", zap.Error(err))
 		writeError(w, http.StatusInternalServerError, "failed to load catalog")
@@ -114,6 +125,293 @@ func (h *Handler) handleListEntries(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, entries)
 }
 
+// customEntryRequest is the request body for creating or replacing a custom
+// catalog entry.
+type customEntryRequest struct {
+	Name           string                    `json:"name" example:"Tautulli"`
+	Description    string                    `json:"description" example:"Plex usage statistics"`
+	Category       pkgcatalog.Category       `json:"category" example:"media"`
+	DefaultPorts   []int                     `json:"default_ports"`
+	DocsURL        string                    `json:"docs_url" example:"https://tautulli.com/#docs"`
+	Icon           string                    `json:"icon" example:"https://tautulli.com/favicon.ico"`
+	DockerImage    string                    `json:"docker_image" example:"tautulli/tautulli"`
+	MinRAMMB       int                       `json:"min_ram_mb" example:"128"`
+	SupportedTiers []pkgcatalog.HardwareTier `json:"supported_tiers"`
+	Tags           []string                  `json:"tags"`
+}
+
+// validate checks the request and fills defaults. Entries without supported
+// tiers are recommended for every tier.
+func (req *customEntryRequest) validate() error {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Category = pkgcatalog.Category(strings.ToLower(strings.TrimSpace(string(req.Category))))
+	if req.Name == "" {
+		return errors.New("name is required")
+	}
+	if req.Category == "" {
+		return errors.New("category is required")
+	}
+	if req.MinRAMMB < 0 {
+		return errors.New("min_ram_mb must not be negative")
+	}
+	for _, p := range req.DefaultPorts {
+		if p < 1 || p > 65535 {
+			return errors.New("default_ports must be between 1 and 65535")
+		}
+	}
+	for _, t := range req.SupportedTiers {
+		if t < pkgcatalog.TierSBC || t > pkgcatalog.TierSMB {
+			return errors.New("supported_tiers must be between 0 and 4")
+		}
+	}
+	if len(req.SupportedTiers) == 0 {
+		req.SupportedTiers = []pkgcatalog.HardwareTier{
+			pkgcatalog.TierSBC, pkgcatalog.TierMiniPC, pkgcatalog.TierNAS, pkgcatalog.TierCluster, pkgcatalog.TierSMB,
+		}
+	}
+	if req.DefaultPorts == nil {
+		req.DefaultPorts = []int{}
+	}
+	if req.Tags == nil {
+		req.Tags = []string{}
+	}
+	return nil
+}
+
+// apply copies the request fields onto an entry.
+func (req *customEntryRequest) apply(e *CustomEntry) {
+	e.Name = req.Name
+	e.Description = req.Description
+	e.Category = req.Category
+	e.DefaultPorts = req.DefaultPorts
+	e.DocsURL = req.DocsURL
+	e.Icon = req.Icon
+	e.DockerImage = req.DockerImage
+	e.MinRAMMB = req.MinRAMMB
+	e.SupportedTiers = req.SupportedTiers
+	e.Tags = req.Tags
+}
+
+// customStore returns the custom entry store, writing an error if custom
+// entries are unavailable.
+func (h *Handler) customStore(w http.ResponseWriter) *Store {
+	if h.engine.custom == nil {
+		writeError(w, http.StatusServiceUnavailable, "custom catalog not available")
+	}
+	return h.engine.custom
+}
+
+// handleListCustomEntries returns all custom catalog entries.
+//
+//	@Summary		List custom catalog entries
+//	@Description	Returns the user-defined catalog entries merged into recommendations.
+//	@Tags			catalog
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200 {array} CustomEntry
+//	@Failure		500 {object} map[string]any
+//	@Failure		503 {object} map[string]any
+//	@Router			/catalog/custom [get]
+func (h *Handler) handleListCustomEntries(w http.ResponseWriter, r *http.Request) {
+	store := h.customStore(w)
+	if store == nil {
+		return
+	}
+
+	entries, err := store.ListEntries(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list custom catalog entries", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to list custom catalog entries")
+		return
+	}
+	if entries == nil {
+		entries = []CustomEntry{}
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// handleCreateCustomEntry adds a custom catalog entry.
+//
+//	@Summary		Create custom catalog entry
+//	@Description	Adds a user-defined tool to the catalog. A custom entry replaces any built-in entry with the same name in recommendations.
+//	@Tags			catalog
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request body customEntryRequest true "Catalog entry"
+//	@Success		201 {object} CustomEntry
+//	@Failure		400 {object} map[string]any
+//	@Failure		409 {object} map[string]any
+//	@Failure		500 {object} map[string]any
+//	@Failure		503 {object} map[string]any
+//	@Router			/catalog/custom [post]
+func (h *Handler) handleCreateCustomEntry(w http.ResponseWriter, r *http.Request) {
+	store := h.customStore(w)
+	if store == nil {
+		return
+	}
+
+	var req customEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	existing, err := store.FindEntryByName(r.Context(), req.Name)
+	if err != nil {
+		h.logger.Error("failed to find custom catalog entry", zap.String("name", req.Name), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to create custom catalog entry")
+		return
+	}
+	if existing != nil {
+		writeError(w, http.StatusConflict, "custom catalog entry already exists")
+		return
+	}
+
+	now := time.Now().UTC()
+	entry := &CustomEntry{ID: uuid.New().String(), CreatedAt: now, UpdatedAt: now}
+	req.apply(entry)
+	if err := store.CreateEntry(r.Context(), entry); err != nil {
+		h.logger.Error("failed to create custom catalog entry", zap.String("name", req.Name), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to create custom catalog entry")
+		return
+	}
+	writeJSON(w, http.StatusCreated, entry)
+}
+
+// handleGetCustomEntry returns a custom catalog entry.
+//
+//	@Summary		Get custom catalog entry
+//	@Description	Returns a user-defined catalog entry by ID.
+//	@Tags			catalog
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id path string true "Entry ID"
+//	@Success		200 {object} CustomEntry
+//	@Failure		404 {object} map[string]any
+//	@Failure		500 {object} map[string]any
+//	@Failure		503 {object} map[string]any
+//	@Router			/catalog/custom/{id} [get]
+func (h *Handler) handleGetCustomEntry(w http.ResponseWriter, r *http.Request) {
+	store := h.customStore(w)
+	if store == nil {
+		return
+	}
+
+	id := r.PathValue("id")
+	entry, err := store.GetEntry(r.Context(), id)
+	if err != nil {
+		h.logger.Error("failed to get custom catalog entry", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to get custom catalog entry")
+		return
+	}
+	if entry == nil {
+		writeError(w, http.StatusNotFound, "custom catalog entry not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, entry)
+}
+
+// handleUpdateCustomEntry replaces a custom catalog entry.
+//
+//	@Summary		Update custom catalog entry
+//	@Description	Replaces a user-defined catalog entry.
+//	@Tags			catalog
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id path string true "Entry ID"
+//	@Param			request body customEntryRequest true "Catalog entry"
+//	@Success		200 {object} CustomEntry
+//	@Failure		400 {object} map[string]any
+//	@Failure		404 {object} map[string]any
+//	@Failure		409 {object} map[string]any
+//	@Failure		500 {object} map[string]any
+//	@Failure		503 {object} map[string]any
+//	@Router			/catalog/custom/{id} [put]
+func (h *Handler) handleUpdateCustomEntry(w http.ResponseWriter, r *http.Request) {
+	store := h.customStore(w)
+	if store == nil {
+		return
+	}
+
+	id := r.PathValue("id")
+	var req customEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entry, err := store.GetEntry(r.Context(), id)
+	if err != nil {
+		h.logger.Error("failed to get custom catalog entry", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to update custom catalog entry")
+		return
+	}
+	if entry == nil {
+		writeError(w, http.StatusNotFound, "custom catalog entry not found")
+		return
+	}
+	existing, err := store.FindEntryByName(r.Context(), req.Name)
+	if err != nil {
+		h.logger.Error("failed to find custom catalog entry", zap.String("name", req.Name), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to update custom catalog entry")
+		return
+	}
+	if existing != nil && existing.ID != id {
+		writeError(w, http.StatusConflict, "custom catalog entry already exists")
+		return
+	}
+
+	req.apply(entry)
+	entry.UpdatedAt = time.Now().UTC()
+	if err := store.UpdateEntry(r.Context(), entry); err != nil {
+		h.logger.Error("failed to update custom catalog entry", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to update custom catalog entry")
+		return
+	}
+	writeJSON(w, http.StatusOK, entry)
+}
+
+// handleDeleteCustomEntry removes a custom catalog entry.
+//
+//	@Summary		Delete custom catalog entry
+//	@Description	Removes a user-defined catalog entry.
+//	@Tags			catalog
+//	@Security		BearerAuth
+//	@Param			id path string true "Entry ID"
+//	@Success		204 "No Content"
+//	@Failure		404 {object} map[string]any
+//	@Failure		500 {object} map[string]any
+//	@Failure		503 {object} map[string]any
+//	@Router			/catalog/custom/{id} [delete]
+func (h *Handler) handleDeleteCustomEntry(w http.ResponseWriter, r *http.Request) {
+	store := h.customStore(w)
+	if store == nil {
+		return
+	}
+
+	id := r.PathValue("id")
+	if err := store.DeleteEntry(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "custom catalog entry not found")
+			return
+		}
+		h.logger.Error("failed to delete custom catalog entry", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to delete custom catalog entry")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // -- helpers --
 
 func writeJSON(w http.ResponseWriter, status int, data any) {
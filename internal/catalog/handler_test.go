@@ -1,13 +1,17 @@
 package catalog
 
 import (
+	"database/sql"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	pkgcatalog "github.com/HerbHall/subnetree/pkg/catalog"
 	"go.uber.org/zap"
+	_ "modernc.org/sqlite"
 )
 
 func newTestHandler(t *testing.T) *Handler {
@@ -17,6 +21,34 @@ func newTestHandler(t *testing.T) *Handler {
 	return NewHandler(engine, zap.NewNop())
 }
 
+// newTestCustomHandler creates a handler with an in-memory custom entry
+// store and a mux with routes registered.
+func newTestCustomHandler(t *testing.T) *http.ServeMux {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open in-memory db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	store, err := NewStore(db)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	engine := NewEngine(pkgcatalog.NewCatalog())
+	engine.SetCustomStore(store)
+	mux := http.NewServeMux()
+	NewHandler(engine, zap.NewNop()).RegisterRoutes(mux)
+	return mux
+}
+
+func serve(mux *http.ServeMux, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
 func TestHandleRecommendations_DefaultTier(t *testing.T) {
 	h := newTestHandler(t)
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/catalog/recommendations", http.NoBody)
@@ -153,3 +185,108 @@ func TestHandleListEntries(t *testing.T) {
 		t.Errorf("expected 48 entries, got %d", len(entries))
 	}
 }
+
+func TestHandleCustomEntries_CRUD(t *testing.T) {
+	mux := newTestCustomHandler(t)
+
+	rec := serve(mux, http.MethodPost, "/api/v1/catalog/custom",
+		`{"name":"Tautulli","category":"Media","default_ports":[8181],"docs_url":"https://tautulli.com","min_ram_mb":64}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created CustomEntry
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("decode created entry: %v", err)
+	}
+	if created.ID == "" || created.Category != pkgcatalog.CategoryMedia || len(created.SupportedTiers) != 5 {
+		t.Errorf("created = %+v, want ID, media category, and all tiers", created)
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"duplicate name", `{"name":"tautulli","category":"media"}`, http.StatusConflict},
+		{"missing name", `{"category":"media"}`, http.StatusBadRequest},
+		{"missing category", `{"name":"x"}`, http.StatusBadRequest},
+		{"bad port", `{"name":"x","category":"media","default_ports":[70000]}`, http.StatusBadRequest},
+		{"bad tier", `{"name":"x","category":"media","supported_tiers":[9]}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := serve(mux, http.MethodPost, "/api/v1/catalog/custom", tt.body); rec.Code != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+
+	rec = serve(mux, http.MethodPut, "/api/v1/catalog/custom/"+created.ID,
+		`{"name":"Tautulli","category":"media","supported_tiers":[2]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = serve(mux, http.MethodGet, "/api/v1/catalog/recommendations?tier=2&category=media", "")
+	var resp RecommendationResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode recommendations: %v", err)
+	}
+	found := false
+	for i := range resp.Entries {
+		e := &resp.Entries[i]
+		if e.Name == "Tautulli" {
+			found = true
+			if e.Source != pkgcatalog.SourceCustom {
+				t.Errorf("Tautulli source = %q, want custom", e.Source)
+			}
+		} else if e.Source != pkgcatalog.SourceBuiltin {
+			t.Errorf("%s source = %q, want builtin", e.Name, e.Source)
+		}
+	}
+	if !found {
+		t.Error("custom entry missing from tier 2 recommendations")
+	}
+
+	if rec := serve(mux, http.MethodDelete, "/api/v1/catalog/custom/"+created.ID, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("delete: expected 204, got %d", rec.Code)
+	}
+	if rec := serve(mux, http.MethodGet, "/api/v1/catalog/custom/"+created.ID, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("get after delete: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestHandleCustomEntries_Unavailable(t *testing.T) {
+	mux := http.NewServeMux()
+	newTestHandler(t).RegisterRoutes(mux)
+
+	if rec := serve(mux, http.MethodGet, "/api/v1/catalog/custom", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", rec.Code)
+	}
+}
+
+func TestStore_NameUniqueIgnoresCase(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open in-memory db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	store, err := NewStore(db)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+
+	now := time.Now().UTC()
+	entry := CustomEntry{ID: "e1", Name: "Tautulli", Category: pkgcatalog.CategoryMedia, CreatedAt: now, UpdatedAt: now}
+	if err := store.CreateEntry(t.Context(), &entry); err != nil {
+		t.Fatalf("CreateEntry: %v", err)
+	}
+	dup := CustomEntry{ID: "e2", Name: "TAUTULLI", Category: pkgcatalog.CategoryMedia, CreatedAt: now, UpdatedAt: now}
+	if err := store.CreateEntry(t.Context(), &dup); err == nil {
+		t.Error("CreateEntry with a name differing only in case succeeded, want unique violation")
+	}
+	found, err := store.FindEntryByName(t.Context(), "tautulli")
+	if err != nil || found == nil || found.ID != "e1" {
+		t.Errorf("FindEntryByName(tautulli) = %+v, %v; want e1", found, err)
+	}
+}
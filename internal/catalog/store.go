@@ -0,0 +1,236 @@
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	pkgcatalog "github.com/HerbHall/subnetree/pkg/catalog"
+)
+
+// CustomEntry is a user-defined catalog entry for tools the built-in
+// catalog does not know about.
+type CustomEntry struct {
+	ID             string                    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name           string                    `json:"name" example:"Tautulli"`
+	Description    string                    `json:"description" example:"Plex usage statistics"`
+	Category       pkgcatalog.Category       `json:"category" example:"media"`
+	DefaultPorts   []int                     `json:"default_ports"`
+	DocsURL        string                    `json:"docs_url" example:"https://tautulli.com/#docs"`
+	Icon           string                    `json:"icon" example:"https://tautulli.com/favicon.ico"`
+	DockerImage    string                    `json:"docker_image" example:"tautulli/tautulli"`
+	MinRAMMB       int                       `json:"min_ram_mb" example:"128"`
+	SupportedTiers []pkgcatalog.HardwareTier `json:"supported_tiers"`
+	Tags           []string                  `json:"tags"`
+	CreatedAt      time.Time                 `json:"created_at"`
+	UpdatedAt      time.Time                 `json:"updated_at"`
+}
+
+// CatalogEntry converts the custom entry to a recommendation catalog entry.
+func (c *CustomEntry) CatalogEntry() pkgcatalog.CatalogEntry {
+	return pkgcatalog.CatalogEntry{
+		Name:           c.Name,
+		Description:    c.Description,
+		Category:       c.Category,
+		DockerImage:    c.DockerImage,
+		MinRAMMB:       c.MinRAMMB,
+		SupportedTiers: c.SupportedTiers,
+		Tags:           c.Tags,
+		DefaultPorts:   c.DefaultPorts,
+		DocsURL:        c.DocsURL,
+		Icon:           c.Icon,
+		Source:         pkgcatalog.SourceCustom,
+	}
+}
+
+// Store provides database operations for user-defined catalog entries.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore creates a new Store and ensures the custom catalog table exists.
+func NewStore(db *sql.DB) (*Store, error) {
+	s := &Store{db: db}
+	if err := s.migrate(); err != nil {
+		return nil, fmt.Errorf("catalog store migrate: %w", err)
+	}
+	return s, nil
+}
+
+// migrate creates the custom entries table. Names are unique regardless of
+// case; the expression index enforces that on both SQLite and PostgreSQL.
+func (s *Store) migrate() error {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS catalog_custom_entries (
+			id                   TEXT PRIMARY KEY,
+			name                 TEXT NOT NULL,
+			description          TEXT NOT NULL DEFAULT '',
+			category             TEXT NOT NULL,
+			default_ports_json   TEXT NOT NULL DEFAULT '[]',
+			docs_url             TEXT NOT NULL DEFAULT '',
+			icon                 TEXT NOT NULL DEFAULT '',
+			docker_image         TEXT NOT NULL DEFAULT '',
+			min_ram_mb           INTEGER NOT NULL DEFAULT 0,
+			supported_tiers_json TEXT NOT NULL DEFAULT '[]',
+			tags_json            TEXT NOT NULL DEFAULT '[]',
+			created_at           DATETIME NOT NULL,
+			updated_at           DATETIME NOT NULL
+		);
+	`)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_catalog_custom_entries_name
+		ON catalog_custom_entries (LOWER(name))`)
+	return err
+}
+
+// CreateEntry inserts a new custom catalog entry.
+func (s *Store) CreateEntry(ctx context.Context, e *CustomEntry) error {
+	ports, tiers, tags, err := marshalEntryLists(e)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO catalog_custom_entries (
+			id, name, description, category, default_ports_json, docs_url, icon,
+			docker_image, min_ram_mb, supported_tiers_json, tags_json, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.ID, e.Name, e.Description, e.Category, ports, e.DocsURL, e.Icon,
+		e.DockerImage, e.MinRAMMB, tiers, tags, e.CreatedAt, e.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("create custom catalog entry: %w", err)
+	}
+	return nil
+}
+
+// UpdateEntry replaces a custom catalog entry.
+// Returns sql.ErrNoRows if it does not exist.
+func (s *Store) UpdateEntry(ctx context.Context, e *CustomEntry) error {
+	ports, tiers, tags, err := marshalEntryLists(e)
+	if err != nil {
+		return err
+	}
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE catalog_custom_entries SET
+			name = ?, description = ?, category = ?, default_ports_json = ?, docs_url = ?,
+			icon = ?, docker_image = ?, min_ram_mb = ?, supported_tiers_json = ?,
+			tags_json = ?, updated_at = ?
+		WHERE id = ?`,
+		e.Name, e.Description, e.Category, ports, e.DocsURL,
+		e.Icon, e.DockerImage, e.MinRAMMB, tiers,
+		tags, e.UpdatedAt, e.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update custom catalog entry: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteEntry deletes a custom catalog entry.
+// Returns sql.ErrNoRows if it does not exist.
+func (s *Store) DeleteEntry(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM catalog_custom_entries WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete custom catalog entry: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetEntry returns a custom catalog entry by ID. Returns nil, nil if not found.
+func (s *Store) GetEntry(ctx context.Context, id string) (*CustomEntry, error) {
+	rows, err := s.db.QueryContext(ctx, customEntrySelect+` WHERE id = ?`, id)
+	if err != nil {
+		return nil, fmt.Errorf("get custom catalog entry: %w", err)
+	}
+	defer rows.Close()
+	entries, err := scanCustomEntries(rows)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+// FindEntryByName returns the custom catalog entry with the given name,
+// compared case-insensitively. Returns nil, nil if not found.
+func (s *Store) FindEntryByName(ctx context.Context, name string) (*CustomEntry, error) {
+	rows, err := s.db.QueryContext(ctx, customEntrySelect+` WHERE LOWER(name) = LOWER(?)`, name)
+	if err != nil {
+		return nil, fmt.Errorf("find custom catalog entry: %w", err)
+	}
+	defer rows.Close()
+	entries, err := scanCustomEntries(rows)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+// ListEntries returns all custom catalog entries ordered by name.
+func (s *Store) ListEntries(ctx context.Context) ([]CustomEntry, error) {
+	rows, err := s.db.QueryContext(ctx, customEntrySelect+` ORDER BY LOWER(name)`)
+	if err != nil {
+		return nil, fmt.Errorf("list custom catalog entries: %w", err)
+	}
+	defer rows.Close()
+	return scanCustomEntries(rows)
+}
+
+const customEntrySelect = `
+	SELECT id, name, description, category, default_ports_json, docs_url, icon,
+		docker_image, min_ram_mb, supported_tiers_json, tags_json, created_at, updated_at
+	FROM catalog_custom_entries`
+
+// marshalEntryLists encodes the list fields of an entry for storage.
+func marshalEntryLists(e *CustomEntry) (ports, tiers, tags string, err error) {
+	portsJSON, err := json.Marshal(e.DefaultPorts)
+	if err != nil {
+		return "", "", "", fmt.Errorf("marshal default ports: %w", err)
+	}
+	tiersJSON, err := json.Marshal(e.SupportedTiers)
+	if err != nil {
+		return "", "", "", fmt.Errorf("marshal supported tiers: %w", err)
+	}
+	tagsJSON, err := json.Marshal(e.Tags)
+	if err != nil {
+		return "", "", "", fmt.Errorf("marshal tags: %w", err)
+	}
+	return string(portsJSON), string(tiersJSON), string(tagsJSON), nil
+}
+
+// scanCustomEntries extracts custom catalog entries from database rows.
+func scanCustomEntries(rows *sql.Rows) ([]CustomEntry, error) {
+	var entries []CustomEntry
+	for rows.Next() {
+		var e CustomEntry
+		var ports, tiers, tags string
+		if err := rows.Scan(
+			&e.ID, &e.Name, &e.Description, &e.Category, &ports, &e.DocsURL, &e.Icon,
+			&e.DockerImage, &e.MinRAMMB, &tiers, &tags, &e.CreatedAt, &e.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan custom catalog entry row: %w", err)
+		}
+		if err := json.Unmarshal([]byte(ports), &e.DefaultPorts); err != nil {
+			return nil, fmt.Errorf("unmarshal default ports: %w", err)
+		}
+		if err := json.Unmarshal([]byte(tiers), &e.SupportedTiers); err != nil {
+			return nil, fmt.Errorf("unmarshal supported tiers: %w", err)
+		}
+		if err := json.Unmarshal([]byte(tags), &e.Tags); err != nil {
+			return nil, fmt.Errorf("unmarshal tags: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
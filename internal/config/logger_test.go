@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/spf13/viper"
+	"go.uber.org/zap"
 )
 
 func TestNewLogger_Defaults(t *testing.T) {
@@ -69,3 +70,22 @@ func TestNewLogger_InvalidFormat(t *testing.T) {
 		t.Fatal("expected error for invalid format")
 	}
 }
+
+func TestNewLoggerWithLevel_Adjustable(t *testing.T) {
+	v := viper.New()
+	v.Set("logging.level", "info")
+
+	logger, level, err := NewLoggerWithLevel(v)
+	if err != nil {
+		t.Fatalf("NewLoggerWithLevel: %v", err)
+	}
+	if logger.Core().Enabled(zap.DebugLevel) {
+		t.Fatal("debug enabled at info level")
+	}
+	if err := level.UnmarshalText([]byte("debug")); err != nil {
+		t.Fatalf("set level: %v", err)
+	}
+	if !logger.Core().Enabled(zap.DebugLevel) {
+		t.Error("debug not enabled after changing level")
+	}
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/spf13/viper"
+)
+
+// ChangedKeys returns the keys whose values differ between two Viper
+// instances, including keys present in only one of them, sorted.
+func ChangedKeys(running, reloaded *viper.Viper) []string {
+	keys := make(map[string]struct{})
+	for _, k := range running.AllKeys() {
+		keys[k] = struct{}{}
+	}
+	for _, k := range reloaded.AllKeys() {
+		keys[k] = struct{}{}
+	}
+
+	var changed []string
+	for k := range keys {
+		if !reflect.DeepEqual(running.Get(k), reloaded.Get(k)) {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestChangedKeys(t *testing.T) {
+	running := viper.New()
+	running.Set("logging.level", "info")
+	running.Set("server.port", 8080)
+	running.Set("plugins.recon.schedule.interval", "1h")
+	running.Set("plugins.recon.removed", true)
+
+	reloaded := viper.New()
+	reloaded.Set("logging.level", "debug")
+	reloaded.Set("server.port", 8080)
+	reloaded.Set("plugins.recon.schedule.interval", "30m")
+	reloaded.Set("plugins.pulse.enabled", true)
+
+	got := ChangedKeys(running, reloaded)
+	want := []string{
+		"logging.level",
+		"plugins.pulse.enabled",
+		"plugins.recon.removed",
+		"plugins.recon.schedule.interval",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("ChangedKeys() = %v, want %v", got, want)
+	}
+
+	if got := ChangedKeys(running, running); len(got) != 0 {
+		t.Errorf("ChangedKeys(same) = %v, want none", got)
+	}
+}
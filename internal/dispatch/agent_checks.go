@@ -0,0 +1,85 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// agentCheckPollInterval is how often RunAgentCheck looks for the result.
+const agentCheckPollInterval = time.Second
+
+// RunAgentCheck queues a monitoring check on an agent over the command
+// channel and waits for the agent to report its outcome. The agent picks the
+// check up on its next check-in, so ctx should allow at least one check-in
+// interval. A check still pending when ctx ends is cancelled.
+func (m *Module) RunAgentCheck(ctx context.Context, agentID string, payload scoutpb.CheckPayload) (*scoutpb.CheckOutput, error) {
+	if m.store == nil {
+		return nil, errors.New("dispatch store not available")
+	}
+
+	agent, err := m.store.GetAgent(ctx, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("get agent: %w", err)
+	}
+	if agent == nil {
+		return nil, fmt.Errorf("agent %q not found", agentID)
+	}
+	if !agent.Online {
+		return nil, fmt.Errorf("agent %q is offline", agentID)
+	}
+
+	cmd := &RemoteCommand{
+		ID:             uuid.New().String(),
+		AgentID:        agentID,
+		Type:           scoutpb.CommandTypeCheck,
+		Command:        payload.CheckType,
+		Args:           []string{payload.Target},
+		TimeoutSeconds: payload.TimeoutSeconds,
+		Status:         CommandStatusPending,
+		CreatedAt:      time.Now().UTC(),
+	}
+	if err := m.store.CreateCommand(ctx, cmd); err != nil {
+		return nil, err
+	}
+
+	ticker := time.NewTicker(agentCheckPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// Use a fresh context; ctx is already done.
+			if err := m.store.CancelCommand(context.Background(), cmd.ID); err != nil {
+				m.logger.Debug("agent check already dispatched at cancellation",
+					zap.String("command_id", cmd.ID),
+					zap.String("agent_id", agentID),
+				)
+			}
+			return nil, fmt.Errorf("agent %q did not report check result: %w", agentID, ctx.Err())
+		case <-ticker.C:
+		}
+
+		got, err := m.store.GetCommand(ctx, cmd.ID)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case got == nil:
+			return nil, fmt.Errorf("agent check command %q disappeared", cmd.ID)
+		case got.Status == CommandStatusFailed:
+			return nil, fmt.Errorf("agent check failed: %s", got.Error)
+		case got.Status == CommandStatusCompleted:
+			var out scoutpb.CheckOutput
+			if err := json.Unmarshal([]byte(got.Stdout), &out); err != nil {
+				return nil, fmt.Errorf("invalid agent check output: %w", err)
+			}
+			return &out, nil
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRunAgentCheck(t *testing.T) {
+	client, store := testGRPCServer(t)
+	ctx := context.Background()
+	seedAgent(t, store, "agent-001")
+	m := &Module{logger: zap.NewNop(), store: store, cfg: DefaultConfig()}
+
+	type result struct {
+		out *scoutpb.CheckOutput
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		runCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		out, err := m.RunAgentCheck(runCtx, "agent-001", scoutpb.CheckPayload{CheckType: "tcp", Target: "10.0.0.5:443", TimeoutSeconds: 5})
+		done <- result{out, err}
+	}()
+
+	// Act as the agent: wait for the queued check, then run the stream.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		pending, err := store.ListPendingCommands(ctx, "agent-001")
+		if err != nil {
+			t.Fatalf("ListPendingCommands: %v", err)
+		}
+		if len(pending) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("check command was never queued")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	stream, err := client.CommandStream(metadata.AppendToOutgoingContext(ctx, scoutpb.AgentIDMetadataKey, "agent-001"))
+	if err != nil {
+		t.Fatalf("CommandStream: %v", err)
+	}
+	cmd, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv command: %v", err)
+	}
+	if cmd.Type != scoutpb.CommandTypeCheck {
+		t.Fatalf("command type = %q, want %q", cmd.Type, scoutpb.CommandTypeCheck)
+	}
+	var payload scoutpb.CheckPayload
+	if err := json.Unmarshal(cmd.Payload, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.CheckType != "tcp" || payload.Target != "10.0.0.5:443" || payload.TimeoutSeconds != 5 {
+		t.Errorf("payload = %+v", payload)
+	}
+	if end, err := stream.Recv(); err != nil || end.Type != scoutpb.CommandTypeEnd {
+		t.Fatalf("expected end marker, got %v, %v", end, err)
+	}
+
+	output, _ := json.Marshal(scoutpb.CheckOutput{Success: false, LatencyMs: 12.5, Error: "connection refused"})
+	if err := stream.Send(&scoutpb.CommandResponse{CommandId: cmd.Id, Success: true, Output: output}); err != nil {
+		t.Fatalf("Send result: %v", err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatalf("CloseSend: %v", err)
+	}
+
+	res := <-done
+	if res.err != nil {
+		t.Fatalf("RunAgentCheck: %v", res.err)
+	}
+	if res.out.Success || res.out.LatencyMs != 12.5 || res.out.Error != "connection refused" {
+		t.Errorf("output = %+v, want the agent's failed tcp result", res.out)
+	}
+}
+
+func TestRunAgentCheck_CancelsUndeliveredCheck(t *testing.T) {
+	s := testStore(t)
+	seedAgent(t, s, "agent-001")
+	m := &Module{logger: zap.NewNop(), store: s, cfg: DefaultConfig()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := m.RunAgentCheck(ctx, "agent-001", scoutpb.CheckPayload{CheckType: "icmp", Target: "10.0.0.5"}); err == nil {
+		t.Fatal("RunAgentCheck succeeded without an agent, want timeout error")
+	}
+
+	pending, err := s.ListPendingCommands(context.Background(), "agent-001")
+	if err != nil {
+		t.Fatalf("ListPendingCommands: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("pending = %+v, want the timed-out check cancelled", pending)
+	}
+
+	if _, err := m.RunAgentCheck(context.Background(), "missing", scoutpb.CheckPayload{CheckType: "icmp", Target: "10.0.0.5"}); err == nil {
+		t.Error("RunAgentCheck on unknown agent succeeded, want error")
+	}
+}
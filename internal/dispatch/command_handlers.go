@@ -0,0 +1,177 @@
+package dispatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+	"github.com/HerbHall/subnetree/internal/auth"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Limits applied to remote command arguments.
+const (
+	maxExecArgs   = 32
+	maxExecArgLen = 1024
+)
+
+// execRequest is the JSON body for queueing a remote command.
+type execRequest struct {
+	Command        string   `json:"command"`
+	Args           []string `json:"args,omitempty"`
+	TimeoutSeconds int      `json:"timeout_seconds,omitempty"`
+}
+
+// handleExecCommand queues an allowlisted command for execution on an agent.
+//
+//	@Summary		Run command on agent
+//	@Description	Queues an allowlisted diagnostic command for an agent. The command and its arguments must match an exec_allowlist pattern. Requires admin role. The agent runs it on its next check-in.
+//	@Tags			dispatch
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string		true	"Agent ID"
+//	@Param			body	body		execRequest	true	"Command to run"
+//	@Success		202		{object}	RemoteCommand
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		403		{object}	models.APIProblem
+//	@Failure		404		{object}	models.APIProblem
+//	@Router			/dispatch/agents/{id}/exec [post]
+func (m *Module) handleExecCommand(w http.ResponseWriter, r *http.Request) {
+	if m.store == nil {
+		dispatchWriteError(w, http.StatusServiceUnavailable, "dispatch store not available")
+		return
+	}
+	if claims := auth.UserFromContext(r.Context()); claims != nil && auth.Role(claims.Role) != auth.RoleAdmin {
+		dispatchWriteError(w, http.StatusForbidden, "admin role required to run remote commands")
+		return
+	}
+	if len(m.cfg.ExecAllowlist) == 0 {
+		dispatchWriteError(w, http.StatusForbidden, "remote exec is disabled; configure dispatch.exec_allowlist to enable it")
+		return
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		dispatchWriteError(w, http.StatusBadRequest, "agent id is required")
+		return
+	}
+
+	var req execRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		dispatchWriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.Command = strings.TrimSpace(req.Command)
+	if req.Command == "" {
+		dispatchWriteError(w, http.StatusBadRequest, "command is required")
+		return
+	}
+	if err := validateExecArgs(req.Args); err != nil {
+		dispatchWriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !scoutpb.ExecAllowed(m.cfg.ExecAllowlist, req.Command, req.Args) {
+		dispatchWriteError(w, http.StatusForbidden, fmt.Sprintf("command %q with these args is not in the exec allowlist", req.Command))
+		return
+	}
+
+	maxTimeout := int(m.cfg.ExecTimeout / time.Second)
+	if maxTimeout <= 0 {
+		maxTimeout = int(DefaultConfig().ExecTimeout / time.Second)
+	}
+	switch {
+	case req.TimeoutSeconds == 0:
+		req.TimeoutSeconds = maxTimeout
+	case req.TimeoutSeconds < 0 || req.TimeoutSeconds > maxTimeout:
+		dispatchWriteError(w, http.StatusBadRequest, fmt.Sprintf("timeout_seconds must be between 1 and %d", maxTimeout))
+		return
+	}
+
+	agent, err := m.store.GetAgent(r.Context(), id)
+	if err != nil {
+		m.logger.Warn("failed to get agent", zap.String("id", id), zap.Error(err))
+		dispatchWriteError(w, http.StatusInternalServerError, "failed to get agent")
+		return
+	}
+	if agent == nil {
+		dispatchWriteError(w, http.StatusNotFound, "agent not found")
+		return
+	}
+
+	cmd := &RemoteCommand{
+		ID:             uuid.New().String(),
+		AgentID:        id,
+		Command:        req.Command,
+		Args:           req.Args,
+		TimeoutSeconds: req.TimeoutSeconds,
+		Status:         CommandStatusPending,
+		CreatedAt:      time.Now().UTC(),
+	}
+	if cmd.Args == nil {
+		cmd.Args = []string{}
+	}
+	if err := m.store.CreateCommand(r.Context(), cmd); err != nil {
+		m.logger.Warn("failed to queue command", zap.String("agent_id", id), zap.Error(err))
+		dispatchWriteError(w, http.StatusInternalServerError, "failed to queue command")
+		return
+	}
+
+	m.logger.Info("remote command queued",
+		zap.String("command_id", cmd.ID),
+		zap.String("agent_id", id),
+		zap.String("command", cmd.Command),
+	)
+	dispatchWriteJSON(w, http.StatusAccepted, cmd)
+}
+
+// handleGetCommand returns a remote command and its result.
+//
+//	@Summary		Get command
+//	@Description	Returns a remote command record including stdout, stderr, and exit code once complete.
+//	@Tags			dispatch
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Command ID"
+//	@Success		200	{object}	RemoteCommand
+//	@Failure		404	{object}	models.APIProblem
+//	@Router			/dispatch/commands/{id} [get]
+func (m *Module) handleGetCommand(w http.ResponseWriter, r *http.Request) {
+	if m.store == nil {
+		dispatchWriteError(w, http.StatusServiceUnavailable, "dispatch store not available")
+		return
+	}
+
+	id := r.PathValue("id")
+	cmd, err := m.store.GetCommand(r.Context(), id)
+	if err != nil {
+		m.logger.Warn("failed to get command", zap.String("id", id), zap.Error(err))
+		dispatchWriteError(w, http.StatusInternalServerError, "failed to get command")
+		return
+	}
+	if cmd == nil {
+		dispatchWriteError(w, http.StatusNotFound, "command not found")
+		return
+	}
+	dispatchWriteJSON(w, http.StatusOK, cmd)
+}
+
+// validateExecArgs bounds argument count and size and rejects NUL bytes.
+func validateExecArgs(args []string) error {
+	if len(args) > maxExecArgs {
+		return fmt.Errorf("at most %d args are allowed", maxExecArgs)
+	}
+	for _, a := range args {
+		if len(a) > maxExecArgLen {
+			return fmt.Errorf("args must be at most %d bytes each", maxExecArgLen)
+		}
+		if strings.ContainsRune(a, 0) {
+			return fmt.Errorf("args must not contain NUL bytes")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package dispatch
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HerbHall/subnetree/internal/auth"
+	"go.uber.org/zap"
+)
+
+func TestHandleExecCommand(t *testing.T) {
+	s := testStore(t)
+	seedAgent(t, s, "agent-001")
+
+	cfg := DefaultConfig()
+	cfg.ExecAllowlist = []string{"uptime", "ping -c 1 *"}
+	cfg.ExecTimeout = 60 * time.Second
+	m := &Module{logger: zap.NewNop(), store: s, cfg: cfg}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /agents/{id}/exec", m.handleExecCommand)
+	mux.HandleFunc("GET /commands/{id}", m.handleGetCommand)
+
+	tests := []struct {
+		name       string
+		agent      string
+		body       string
+		wantStatus int
+	}{
+		{"allowlisted", "agent-001", `{"command":"ping","args":["-c","1","10.0.0.1"]}`, http.StatusAccepted},
+		{"not allowlisted", "agent-001", `{"command":"rm","args":["-rf","/"]}`, http.StatusForbidden},
+		{"extra args", "agent-001", `{"command":"ping","args":["-c","1","-f","10.0.0.1"]}`, http.StatusForbidden},
+		{"option in wildcard", "agent-001", `{"command":"ping","args":["-c","1","-f"]}`, http.StatusForbidden},
+		{"args on bare command", "agent-001", `{"command":"uptime","args":["-p"]}`, http.StatusForbidden},
+		{"shell string", "agent-001", `{"command":"uptime; reboot"}`, http.StatusForbidden},
+		{"missing command", "agent-001", `{}`, http.StatusBadRequest},
+		{"timeout too long", "agent-001", `{"command":"uptime","timeout_seconds":600}`, http.StatusBadRequest},
+		{"unknown agent", "missing", `{"command":"uptime"}`, http.StatusNotFound},
+		{"invalid json", "agent-001", `{`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/agents/"+tt.agent+"/exec", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if rec.Code != http.StatusAccepted {
+				return
+			}
+
+			var cmd RemoteCommand
+			if err := json.NewDecoder(rec.Body).Decode(&cmd); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if cmd.Status != CommandStatusPending || cmd.TimeoutSeconds != 60 {
+				t.Errorf("got status=%q timeout=%d", cmd.Status, cmd.TimeoutSeconds)
+			}
+
+			get := httptest.NewRequest(http.MethodGet, "/commands/"+cmd.ID, http.NoBody)
+			rec = httptest.NewRecorder()
+			mux.ServeHTTP(rec, get)
+			if rec.Code != http.StatusOK {
+				t.Errorf("GET command status = %d, want 200", rec.Code)
+			}
+		})
+	}
+}
+
+func TestHandleExecCommand_Disabled(t *testing.T) {
+	s := testStore(t)
+	seedAgent(t, s, "agent-001")
+	m := &Module{logger: zap.NewNop(), store: s, cfg: DefaultConfig()}
+
+	req := httptest.NewRequest(http.MethodPost, "/agents/agent-001/exec", strings.NewReader(`{"command":"uptime"}`))
+	req.SetPathValue("id", "agent-001")
+	rec := httptest.NewRecorder()
+	m.handleExecCommand(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestHandleExecCommand_RequiresAdmin(t *testing.T) {
+	s := testStore(t)
+	seedAgent(t, s, "agent-001")
+	cfg := DefaultConfig()
+	cfg.ExecAllowlist = []string{"uptime"}
+	m := &Module{logger: zap.NewNop(), store: s, cfg: cfg}
+
+	tokens := auth.NewTokenService([]byte("test-secret-test-secret-test-secret"), time.Minute, time.Hour)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/dispatch/agents/{id}/exec", m.handleExecCommand)
+	h := auth.AuthMiddleware(tokens)(mux)
+
+	for _, tt := range []struct {
+		role       auth.Role
+		wantStatus int
+	}{
+		{auth.RoleOperator, http.StatusForbidden},
+		{auth.RoleAdmin, http.StatusAccepted},
+	} {
+		token, err := tokens.IssueAccessToken(&auth.User{ID: "user-1", Username: "user", Role: tt.role})
+		if err != nil {
+			t.Fatalf("IssueAccessToken: %v", err)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/dispatch/agents/agent-001/exec", strings.NewReader(`{"command":"uptime"}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d: %s", tt.role, rec.Code, tt.wantStatus, rec.Body.String())
+		}
+	}
+}
+
+func TestHandleGetCommand_NotFound(t *testing.T) {
+	m := &Module{logger: zap.NewNop(), store: testStore(t)}
+
+	req := httptest.NewRequest(http.MethodGet, "/commands/missing", http.NoBody)
+	req.SetPathValue("id", "missing")
+	rec := httptest.NewRecorder()
+	m.handleGetCommand(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
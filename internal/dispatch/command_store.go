@@ -0,0 +1,207 @@
+package dispatch
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+)
+
+// Remote command statuses.
+const (
+	CommandStatusPending    = "pending"
+	CommandStatusDispatched = "dispatched"
+	CommandStatusCompleted  = "completed"
+	CommandStatusFailed     = "failed"
+)
+
+// RemoteCommand is a command queued for execution on a Scout agent.
+// Exec commands carry an executable and its args. Check commands carry the
+// check type in Command and the target as the only arg; the agent's
+// CheckOutput JSON is stored in Stdout.
+type RemoteCommand struct {
+	ID             string     `json:"id"`
+	AgentID        string     `json:"agent_id"`
+	Type           string     `json:"type"` // exec or check
+	Command        string     `json:"command"`
+	Args           []string   `json:"args"`
+	TimeoutSeconds int        `json:"timeout_seconds"`
+	Status         string     `json:"status"` // pending, dispatched, completed, failed
+	ExitCode       *int       `json:"exit_code,omitempty"`
+	Stdout         string     `json:"stdout"`
+	Stderr         string     `json:"stderr"`
+	Error          string     `json:"error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DispatchedAt   *time.Time `json:"dispatched_at,omitempty"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+}
+
+// CommandResult is the outcome of a remote command reported by an agent.
+type CommandResult struct {
+	ExitCode *int
+	Stdout   string
+	Stderr   string
+	Error    string
+}
+
+const commandColumns = `id, agent_id, type, command, args_json, timeout_seconds, status,
+	exit_code, stdout, stderr, error, created_at, dispatched_at, completed_at`
+
+// CreateCommand inserts a new pending command. An empty Type is stored as
+// an exec command.
+func (s *DispatchStore) CreateCommand(ctx context.Context, cmd *RemoteCommand) error {
+	if cmd.Type == "" {
+		cmd.Type = scoutpb.CommandTypeExec
+	}
+	args, err := json.Marshal(cmd.Args)
+	if err != nil {
+		return fmt.Errorf("marshal command args: %w", err)
+	}
+	if cmd.Args == nil {
+		args = []byte("[]")
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO dispatch_commands (id, agent_id, type, command, args_json, timeout_seconds, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		cmd.ID, cmd.AgentID, cmd.Type, cmd.Command, string(args), cmd.TimeoutSeconds, cmd.Status, cmd.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("create command: %w", err)
+	}
+	return nil
+}
+
+// GetCommand returns a command by ID. Returns nil, nil if not found.
+func (s *DispatchStore) GetCommand(ctx context.Context, id string) (*RemoteCommand, error) {
+	cmd, err := scanCommand(s.db.QueryRowContext(ctx,
+		`SELECT `+commandColumns+` FROM dispatch_commands WHERE id = ?`, id,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get command: %w", err)
+	}
+	return cmd, nil
+}
+
+// ListPendingCommands returns the commands waiting to be sent to an agent,
+// oldest first.
+func (s *DispatchStore) ListPendingCommands(ctx context.Context, agentID string) ([]RemoteCommand, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+commandColumns+` FROM dispatch_commands
+		WHERE agent_id = ? AND status = ? ORDER BY created_at ASC`,
+		agentID, CommandStatusPending,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list pending commands: %w", err)
+	}
+	defer rows.Close()
+
+	var cmds []RemoteCommand
+	for rows.Next() {
+		cmd, err := scanCommand(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan command row: %w", err)
+		}
+		cmds = append(cmds, *cmd)
+	}
+	return cmds, rows.Err()
+}
+
+// MarkCommandDispatched records that a pending command was sent to its agent.
+// Returns sql.ErrNoRows if the command is not pending.
+func (s *DispatchStore) MarkCommandDispatched(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE dispatch_commands SET status = ?, dispatched_at = ?
+		WHERE id = ? AND status = ?`,
+		CommandStatusDispatched, time.Now().UTC(), id, CommandStatusPending,
+	)
+	if err != nil {
+		return fmt.Errorf("mark command dispatched: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// CompleteCommand stores the result of a dispatched command. The agent ID
+// must match the command's agent. Returns sql.ErrNoRows otherwise.
+func (s *DispatchStore) CompleteCommand(ctx context.Context, id, agentID string, result CommandResult) error {
+	status := CommandStatusCompleted
+	if result.Error != "" {
+		status = CommandStatusFailed
+	}
+	var exitCode sql.NullInt64
+	if result.ExitCode != nil {
+		exitCode = sql.NullInt64{Int64: int64(*result.ExitCode), Valid: true}
+	}
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE dispatch_commands SET
+			status = ?, exit_code = ?, stdout = ?, stderr = ?, error = ?, completed_at = ?
+		WHERE id = ? AND agent_id = ? AND status = ?`,
+		status, exitCode, result.Stdout, result.Stderr, result.Error, time.Now().UTC(),
+		id, agentID, CommandStatusDispatched,
+	)
+	if err != nil {
+		return fmt.Errorf("complete command: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// CancelCommand fails a command that has not been sent to its agent yet, so
+// an abandoned request is not delivered later. Returns sql.ErrNoRows if the
+// command is no longer pending.
+func (s *DispatchStore) CancelCommand(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE dispatch_commands SET status = ?, error = ?, completed_at = ?
+		WHERE id = ? AND status = ?`,
+		CommandStatusFailed, "cancelled before dispatch", time.Now().UTC(), id, CommandStatusPending,
+	)
+	if err != nil {
+		return fmt.Errorf("cancel command: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// scanCommand scans a row selected with commandColumns into a RemoteCommand.
+func scanCommand(row rowScanner) (*RemoteCommand, error) {
+	var c RemoteCommand
+	var argsJSON string
+	var exitCode sql.NullInt64
+	var dispatchedAt, completedAt sql.NullTime
+	if err := row.Scan(
+		&c.ID, &c.AgentID, &c.Type, &c.Command, &argsJSON, &c.TimeoutSeconds, &c.Status,
+		&exitCode, &c.Stdout, &c.Stderr, &c.Error, &c.CreatedAt, &dispatchedAt, &completedAt,
+	); err != nil {
+		return nil, err
+	}
+	c.Args = []string{}
+	if argsJSON != "" {
+		_ = json.Unmarshal([]byte(argsJSON), &c.Args)
+	}
+	if exitCode.Valid {
+		code := int(exitCode.Int64)
+		c.ExitCode = &code
+	}
+	if dispatchedAt.Valid {
+		c.DispatchedAt = &dispatchedAt.Time
+	}
+	if completedAt.Valid {
+		c.CompletedAt = &completedAt.Time
+	}
+	return &c, nil
+}
@@ -0,0 +1,123 @@
+package dispatch
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func seedAgent(t *testing.T, s *DispatchStore, id string) {
+	t.Helper()
+	if err := s.UpsertAgent(context.Background(), &Agent{
+		ID:         id,
+		Status:     "connected",
+		EnrolledAt: time.Now().UTC(),
+		ConfigJSON: "{}",
+	}); err != nil {
+		t.Fatalf("UpsertAgent %s: %v", id, err)
+	}
+}
+
+func TestDispatchStore_CommandLifecycle(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+	seedAgent(t, s, "agent-001")
+
+	cmd := &RemoteCommand{
+		ID:             "cmd-001",
+		AgentID:        "agent-001",
+		Command:        "uptime",
+		Args:           []string{"-p"},
+		TimeoutSeconds: 10,
+		Status:         CommandStatusPending,
+		CreatedAt:      time.Now().UTC(),
+	}
+	if err := s.CreateCommand(ctx, cmd); err != nil {
+		t.Fatalf("CreateCommand: %v", err)
+	}
+
+	pending, err := s.ListPendingCommands(ctx, "agent-001")
+	if err != nil {
+		t.Fatalf("ListPendingCommands: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != "cmd-001" {
+		t.Fatalf("pending = %+v, want [cmd-001]", pending)
+	}
+	if len(pending[0].Args) != 1 || pending[0].Args[0] != "-p" {
+		t.Errorf("Args = %v, want [-p]", pending[0].Args)
+	}
+
+	// Results are only accepted for dispatched commands.
+	if err := s.CompleteCommand(ctx, "cmd-001", "agent-001", CommandResult{}); err == nil {
+		t.Error("CompleteCommand on pending command should fail")
+	}
+
+	if err := s.MarkCommandDispatched(ctx, "cmd-001"); err != nil {
+		t.Fatalf("MarkCommandDispatched: %v", err)
+	}
+	if err := s.MarkCommandDispatched(ctx, "cmd-001"); err == nil {
+		t.Error("second MarkCommandDispatched should fail")
+	}
+	pending, _ = s.ListPendingCommands(ctx, "agent-001")
+	if len(pending) != 0 {
+		t.Errorf("pending after dispatch = %d, want 0", len(pending))
+	}
+
+	// Results from a different agent are rejected.
+	if err := s.CompleteCommand(ctx, "cmd-001", "agent-002", CommandResult{}); err == nil {
+		t.Error("CompleteCommand from wrong agent should fail")
+	}
+
+	code := 0
+	if err := s.CompleteCommand(ctx, "cmd-001", "agent-001", CommandResult{ExitCode: &code, Stdout: "up 3 days"}); err != nil {
+		t.Fatalf("CompleteCommand: %v", err)
+	}
+
+	got, err := s.GetCommand(ctx, "cmd-001")
+	if err != nil || got == nil {
+		t.Fatalf("GetCommand: %v", err)
+	}
+	if got.Status != CommandStatusCompleted {
+		t.Errorf("Status = %q, want %q", got.Status, CommandStatusCompleted)
+	}
+	if got.ExitCode == nil || *got.ExitCode != 0 {
+		t.Errorf("ExitCode = %v, want 0", got.ExitCode)
+	}
+	if got.Stdout != "up 3 days" {
+		t.Errorf("Stdout = %q", got.Stdout)
+	}
+	if got.DispatchedAt == nil || got.CompletedAt == nil {
+		t.Error("expected dispatched_at and completed_at to be set")
+	}
+
+	missing, err := s.GetCommand(ctx, "nonexistent")
+	if err != nil || missing != nil {
+		t.Errorf("GetCommand(nonexistent) = %v, %v; want nil, nil", missing, err)
+	}
+}
+
+func TestDispatchStore_CompleteCommand_Failed(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+	seedAgent(t, s, "agent-001")
+
+	if err := s.CreateCommand(ctx, &RemoteCommand{
+		ID: "cmd-001", AgentID: "agent-001", Command: "ping",
+		TimeoutSeconds: 5, Status: CommandStatusPending, CreatedAt: time.Now().UTC(),
+	}); err != nil {
+		t.Fatalf("CreateCommand: %v", err)
+	}
+	if err := s.MarkCommandDispatched(ctx, "cmd-001"); err != nil {
+		t.Fatalf("MarkCommandDispatched: %v", err)
+	}
+	if err := s.CompleteCommand(ctx, "cmd-001", "agent-001", CommandResult{Error: "command timed out"}); err != nil {
+		t.Fatalf("CompleteCommand: %v", err)
+	}
+	got, _ := s.GetCommand(ctx, "cmd-001")
+	if got.Status != CommandStatusFailed || got.Error != "command timed out" {
+		t.Errorf("got status=%q error=%q", got.Status, got.Error)
+	}
+	if len(got.Args) != 0 || got.Args == nil {
+		t.Errorf("Args = %#v, want empty slice", got.Args)
+	}
+}
@@ -15,6 +15,21 @@ type DispatchConfig struct {
 	TLSEnabled            bool          `mapstructure:"tls_enabled"`
 	ServerCertPath        string        `mapstructure:"server_cert_path"` //nolint:gosec // G101: file path, not a credential
 	ServerKeyPath         string        `mapstructure:"server_key_path"`
+
+	// ExecAllowlist lists the command lines agents may be asked to run via
+	// the remote exec endpoint, e.g. "ping -c 4 *". See
+	// scoutpb.ExecAllowed for the pattern syntax. Remote exec is disabled
+	// when empty.
+	ExecAllowlist []string `mapstructure:"exec_allowlist"`
+	// ExecTimeout is the default and maximum run time for a remote command.
+	ExecTimeout time.Duration `mapstructure:"exec_timeout"`
+
+	// EventRetention is how long forwarded agent event log entries are
+	// kept. Zero keeps them indefinitely.
+	EventRetention time.Duration `mapstructure:"event_retention"`
+	// MetricsRetention is how long agent GPU and temperature samples are
+	// kept. Zero keeps them indefinitely.
+	MetricsRetention time.Duration `mapstructure:"metrics_retention"`
 }
 
 // DefaultConfig returns the default Dispatch configuration.
@@ -25,6 +40,9 @@ func DefaultConfig() DispatchConfig {
 		GRPCAddr:              ":9090",
 		AgentTimeout:          5 * time.Minute,
 		EnrollmentTokenExpiry: 24 * time.Hour,
+		ExecTimeout:           30 * time.Second,
+		EventRetention:        30 * 24 * time.Hour,
+		MetricsRetention:      30 * 24 * time.Hour,
 		CAConfig: ca.Config{
 			Validity:     ca.DefaultValidity,
 			Organization: ca.DefaultOrganization,
@@ -9,6 +9,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
@@ -35,6 +36,8 @@ type Module struct {
 	authority  *ca.Authority
 	grpcServer *grpc.Server
 	grpcLis    net.Listener
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
 }
 
 // New creates a new Dispatch plugin instance.
@@ -141,11 +144,22 @@ func (m *Module) Start(_ context.Context) error {
 		}
 	}()
 
+	if m.cfg.AgentTimeout > 0 {
+		sweepCtx, cancel := context.WithCancel(context.Background())
+		m.cancel = cancel
+		m.wg.Add(1)
+		go m.runAgentOfflineSweep(sweepCtx)
+	}
+
 	m.logger.Info("dispatch module started")
 	return nil
 }
 
 func (m *Module) Stop(_ context.Context) error {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.wg.Wait()
 	if m.grpcServer != nil {
 		m.grpcServer.GracefulStop()
 		m.logger.Info("gRPC server stopped")
@@ -93,10 +93,16 @@ func TestRoutes(t *testing.T) {
 		"GET /agents":                  "",
 		"GET /agents/{id}":             "",
 		"POST /enroll":                 "",
+		"PATCH /agents/{id}":           "",
 		"DELETE /agents/{id}":          "",
 		"GET /agents/{id}/hardware":    "",
 		"GET /agents/{id}/software":    "",
 		"GET /agents/{id}/services":    "",
+		"GET /agents/{id}/containers":  "",
+		"GET /agents/{id}/events":      "",
+		"GET /agents/{id}/metrics":     "",
+		"POST /agents/{id}/exec":       "",
+		"GET /commands/{id}":           "",
 		"GET /install/{platform}/{arch}":  "",
 		"GET /download/{platform}/{arch}": "",
 		"GET /updates/latest":             "",
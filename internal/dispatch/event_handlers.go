@@ -0,0 +1,91 @@
+package dispatch
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+	"go.uber.org/zap"
+)
+
+// handleListAgentEvents returns the event log entries forwarded by an agent.
+//
+//	@Summary		List agent events
+//	@Description	Returns Windows event log entries forwarded by a Scout agent, newest first.
+//	@Tags			dispatch
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string	true	"Agent ID"
+//	@Param			level	query		string	false	"Only events at this level (critical, error, warning, information, verbose)"
+//	@Param			channel	query		string	false	"Only events from this channel, e.g. System"
+//	@Param			source	query		string	false	"Only events from this provider (case-insensitive)"
+//	@Param			since	query		string	false	"Only events created at or after this RFC 3339 time"
+//	@Param			limit	query		int		false	"Maximum events to return (default 100, max 1000)"
+//	@Success		200		{array}		AgentEvent
+//	@Failure		400		{object}	object
+//	@Failure		404		{object}	object
+//	@Router			/dispatch/agents/{id}/events [get]
+func (m *Module) handleListAgentEvents(w http.ResponseWriter, r *http.Request) {
+	if m.store == nil {
+		dispatchWriteError(w, http.StatusServiceUnavailable, "dispatch store not available")
+		return
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		dispatchWriteError(w, http.StatusBadRequest, "agent id is required")
+		return
+	}
+
+	q := r.URL.Query()
+	filter := AgentEventFilter{
+		Level:   q.Get("level"),
+		Channel: q.Get("channel"),
+		Source:  q.Get("source"),
+		Limit:   defaultAgentEventLimit,
+	}
+	if filter.Level != "" && !slices.Contains(scoutpb.EventLevels, filter.Level) {
+		dispatchWriteError(w, http.StatusBadRequest, "level must be one of critical, error, warning, information, verbose")
+		return
+	}
+	if s := q.Get("since"); s != "" {
+		since, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			dispatchWriteError(w, http.StatusBadRequest, "since must be an RFC 3339 time")
+			return
+		}
+		filter.Since = since
+	}
+	if s := q.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit < 1 || limit > maxAgentEventLimit {
+			dispatchWriteError(w, http.StatusBadRequest, "limit must be an integer between 1 and 1000")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	agent, err := m.store.GetAgent(r.Context(), id)
+	if err != nil {
+		m.logger.Warn("failed to get agent", zap.String("agent_id", id), zap.Error(err))
+		dispatchWriteError(w, http.StatusInternalServerError, "failed to list events")
+		return
+	}
+	if agent == nil {
+		dispatchWriteError(w, http.StatusNotFound, "agent not found")
+		return
+	}
+
+	events, err := m.store.ListAgentEvents(r.Context(), id, filter)
+	if err != nil {
+		m.logger.Warn("failed to list agent events", zap.String("agent_id", id), zap.Error(err))
+		dispatchWriteError(w, http.StatusInternalServerError, "failed to list events")
+		return
+	}
+	if events == nil {
+		events = []AgentEvent{}
+	}
+	dispatchWriteJSON(w, http.StatusOK, events)
+}
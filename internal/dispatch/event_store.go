@@ -0,0 +1,139 @@
+package dispatch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Default and maximum number of agent events returned by ListAgentEvents.
+const (
+	defaultAgentEventLimit = 100
+	maxAgentEventLimit     = 1000
+)
+
+// AgentEvent is a Windows event log entry forwarded by a Scout agent.
+type AgentEvent struct {
+	ID          int64     `json:"id"`
+	AgentID     string    `json:"agent_id"`
+	Channel     string    `json:"channel"`
+	Source      string    `json:"source"`
+	EventID     uint32    `json:"event_id"`
+	Level       string    `json:"level"` // critical, error, warning, information, verbose
+	Message     string    `json:"message"`
+	TimeCreated time.Time `json:"time_created"`
+	RecordID    uint64    `json:"record_id"`
+	ReceivedAt  time.Time `json:"received_at"`
+}
+
+// AgentEventFilter narrows the events returned by ListAgentEvents. Empty
+// fields match every event.
+type AgentEventFilter struct {
+	Level   string
+	Channel string
+	Source  string
+	Since   time.Time
+	Limit   int
+}
+
+// InsertAgentEvents stores a batch of events for an agent. ReceivedAt is
+// set on each event.
+func (s *DispatchStore) InsertAgentEvents(ctx context.Context, agentID string, events []AgentEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin insert agent events: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO dispatch_agent_events (
+			agent_id, channel, source, event_id, level, message,
+			time_created, record_id, received_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id`)
+	if err != nil {
+		return fmt.Errorf("prepare insert agent event: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC()
+	for i := range events {
+		e := &events[i]
+		e.AgentID = agentID
+		e.ReceivedAt = now
+		err := stmt.QueryRowContext(ctx,
+			agentID, e.Channel, e.Source, e.EventID, e.Level, e.Message,
+			e.TimeCreated.UTC(), e.RecordID, now,
+		).Scan(&e.ID)
+		if err != nil {
+			return fmt.Errorf("insert agent event: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// ListAgentEvents returns an agent's events matching filter, newest first.
+// Source matches case-insensitively.
+func (s *DispatchStore) ListAgentEvents(ctx context.Context, agentID string, filter AgentEventFilter) ([]AgentEvent, error) {
+	where := []string{`agent_id = ?`}
+	args := []any{agentID}
+	if filter.Level != "" {
+		where = append(where, `level = ?`)
+		args = append(args, filter.Level)
+	}
+	if filter.Channel != "" {
+		where = append(where, `channel = ?`)
+		args = append(args, filter.Channel)
+	}
+	if filter.Source != "" {
+		where = append(where, `LOWER(source) = LOWER(?)`)
+		args = append(args, filter.Source)
+	}
+	if !filter.Since.IsZero() {
+		where = append(where, `time_created >= ?`)
+		args = append(args, filter.Since.UTC())
+	}
+	limit := filter.Limit
+	if limit <= 0 || limit > maxAgentEventLimit {
+		limit = defaultAgentEventLimit
+	}
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, agent_id, channel, source, event_id, level, message,
+			time_created, record_id, received_at
+		FROM dispatch_agent_events
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY time_created DESC, id DESC
+		LIMIT ?`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list agent events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []AgentEvent
+	for rows.Next() {
+		var e AgentEvent
+		if err := rows.Scan(&e.ID, &e.AgentID, &e.Channel, &e.Source, &e.EventID, &e.Level,
+			&e.Message, &e.TimeCreated, &e.RecordID, &e.ReceivedAt); err != nil {
+			return nil, fmt.Errorf("scan agent event row: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// PruneAgentEvents deletes events received before cutoff and returns the
+// number deleted.
+func (s *DispatchStore) PruneAgentEvents(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM dispatch_agent_events WHERE received_at < ?`, cutoff.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("prune agent events: %w", err)
+	}
+	return res.RowsAffected()
+}
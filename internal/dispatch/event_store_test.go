@@ -0,0 +1,146 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestAgentEventStore(t *testing.T) {
+	s, agentID := testStoreWithAgent(t)
+	ctx := context.Background()
+	base := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	events := []AgentEvent{
+		{Channel: "System", Source: "Service Control Manager", EventID: 7031, Level: scoutpb.EventLevelError, Message: "spooler stopped", TimeCreated: base, RecordID: 1},
+		{Channel: "System", Source: "disk", EventID: 153, Level: scoutpb.EventLevelWarning, Message: "retry", TimeCreated: base.Add(time.Minute), RecordID: 2},
+		{Channel: "Application", Source: "MSSQLSERVER", EventID: 17063, Level: scoutpb.EventLevelError, Message: "db error", TimeCreated: base.Add(2 * time.Minute), RecordID: 3},
+	}
+	if err := s.InsertAgentEvents(ctx, agentID, events); err != nil {
+		t.Fatalf("InsertAgentEvents: %v", err)
+	}
+	if events[0].ID == 0 || events[0].AgentID != agentID || events[0].ReceivedAt.IsZero() {
+		t.Errorf("inserted event not populated: %+v", events[0])
+	}
+
+	all, err := s.ListAgentEvents(ctx, agentID, AgentEventFilter{})
+	if err != nil {
+		t.Fatalf("ListAgentEvents: %v", err)
+	}
+	if len(all) != 3 || all[0].EventID != 17063 {
+		t.Fatalf("events = %+v, want 3 newest first", all)
+	}
+
+	for name, tc := range map[string]struct {
+		filter AgentEventFilter
+		want   int
+	}{
+		"level":   {AgentEventFilter{Level: scoutpb.EventLevelError}, 2},
+		"channel": {AgentEventFilter{Channel: "System"}, 2},
+		"source":  {AgentEventFilter{Source: "service control manager"}, 1},
+		"since":   {AgentEventFilter{Since: base.Add(time.Minute)}, 2},
+		"limit":   {AgentEventFilter{Limit: 1}, 1},
+	} {
+		got, err := s.ListAgentEvents(ctx, agentID, tc.filter)
+		if err != nil {
+			t.Fatalf("%s: ListAgentEvents: %v", name, err)
+		}
+		if len(got) != tc.want {
+			t.Errorf("%s: got %d events, want %d", name, len(got), tc.want)
+		}
+	}
+
+	n, err := s.PruneAgentEvents(ctx, time.Now().UTC().Add(-time.Hour))
+	if err != nil || n != 0 {
+		t.Errorf("PruneAgentEvents(past) = %d, %v; want 0", n, err)
+	}
+	n, err = s.PruneAgentEvents(ctx, time.Now().UTC().Add(time.Hour))
+	if err != nil || n != 3 {
+		t.Errorf("PruneAgentEvents(future) = %d, %v; want 3", n, err)
+	}
+}
+
+func TestGRPC_ReportEvents(t *testing.T) {
+	client, store := testGRPCServer(t)
+	ctx := context.Background()
+
+	if _, err := client.ReportEvents(ctx, &scoutpb.EventReport{AgentId: "missing"}); err == nil {
+		t.Error("expected error for unknown agent")
+	}
+
+	if err := store.UpsertAgent(ctx, &Agent{ID: "agent-ev", Hostname: "srv01", Platform: "windows/amd64", Status: "connected", ConfigJSON: "{}"}); err != nil {
+		t.Fatalf("UpsertAgent: %v", err)
+	}
+	created := time.Date(2026, 1, 15, 10, 30, 0, 0, time.UTC)
+	ack, err := client.ReportEvents(ctx, &scoutpb.EventReport{
+		AgentId: "agent-ev",
+		Events: []*scoutpb.LogEvent{{
+			Channel:     "System",
+			Source:      "Service Control Manager",
+			EventId:     7031,
+			Level:       scoutpb.EventLevelError,
+			Message:     "The Print Spooler service terminated unexpectedly.",
+			TimeCreated: timestamppb.New(created),
+			RecordId:    101,
+		}},
+	})
+	if err != nil || !ack.GetSuccess() {
+		t.Fatalf("ReportEvents = %v, %v", ack, err)
+	}
+
+	events, err := store.ListAgentEvents(ctx, "agent-ev", AgentEventFilter{})
+	if err != nil {
+		t.Fatalf("ListAgentEvents: %v", err)
+	}
+	if len(events) != 1 || events[0].RecordID != 101 || !events[0].TimeCreated.Equal(created) {
+		t.Errorf("stored events = %+v", events)
+	}
+}
+
+func TestHandleListAgentEvents(t *testing.T) {
+	s, agentID := testStoreWithAgent(t)
+	if err := s.InsertAgentEvents(context.Background(), agentID, []AgentEvent{
+		{Channel: "System", Source: "disk", Level: scoutpb.EventLevelWarning, TimeCreated: time.Now().UTC()},
+	}); err != nil {
+		t.Fatalf("InsertAgentEvents: %v", err)
+	}
+	m := &Module{logger: zap.NewNop(), store: s}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /agents/{id}/events", m.handleListAgentEvents)
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+		return rec
+	}
+
+	rec := get("/agents/" + agentID + "/events?level=warning")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var events []AgentEvent
+	if err := json.NewDecoder(rec.Body).Decode(&events); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(events) != 1 || events[0].Source != "disk" {
+		t.Errorf("events = %+v", events)
+	}
+
+	for path, want := range map[string]int{
+		"/agents/" + agentID + "/events?level=fatal": http.StatusBadRequest,
+		"/agents/" + agentID + "/events?since=today": http.StatusBadRequest,
+		"/agents/" + agentID + "/events?limit=5000":  http.StatusBadRequest,
+		"/agents/missing/events":                     http.StatusNotFound,
+	} {
+		if rec := get(path); rec.Code != want {
+			t.Errorf("GET %s status = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
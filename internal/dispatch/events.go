@@ -1,9 +1,41 @@
 package dispatch
 
+import "time"
+
 // Event topics published by the Dispatch module.
 const (
 	TopicAgentEnrolled     = "dispatch.agent.enrolled"
 	TopicAgentCheckIn      = "dispatch.agent.checkin"
 	TopicAgentDisconnected = "dispatch.agent.disconnected"
+	TopicAgentReconnected  = "dispatch.agent.reconnected"
 	TopicDeviceProfiled    = "dispatch.device.profiled"
+	TopicAgentEvents       = "dispatch.agent.events"
+	TopicAgentHardware     = "dispatch.agent.hardware"
 )
+
+// AgentStatusEvent is the payload for TopicAgentDisconnected and
+// TopicAgentReconnected.
+type AgentStatusEvent struct {
+	AgentID     string     `json:"agent_id"`
+	Hostname    string     `json:"hostname"`
+	DeviceID    string     `json:"device_id"`
+	LastCheckIn *time.Time `json:"last_check_in,omitempty"`
+}
+
+// AgentEventsEvent is the payload for TopicAgentEvents, published when an
+// agent forwards a batch of event log entries.
+type AgentEventsEvent struct {
+	AgentID  string       `json:"agent_id"`
+	Hostname string       `json:"hostname"`
+	DeviceID string       `json:"device_id"`
+	Events   []AgentEvent `json:"events"`
+}
+
+// AgentHardwareEvent is the payload for TopicAgentHardware, published when
+// an agent check-in carries GPU or temperature readings.
+type AgentHardwareEvent struct {
+	AgentID  string           `json:"agent_id"`
+	Hostname string           `json:"hostname"`
+	DeviceID string           `json:"device_id"`
+	Samples  []HardwareSample `json:"samples"`
+}
@@ -104,9 +104,26 @@ func (s *scoutServer) CheckIn(ctx context.Context, req *scoutpb.CheckInRequest)
 		}
 	}
 
-	// 4. Update check-in in store.
+	// 4. Update check-in in store, noting agents that come back after being
+	// marked offline.
+	prev, _ := s.store.GetAgent(ctx, agentID)
 	if err := s.store.UpdateCheckIn(ctx, agentID, req.Hostname, req.Platform, req.AgentVersion, int(req.ProtoVersion)); err != nil {
 		s.logger.Warn("check-in update failed", zap.String("agent_id", agentID), zap.Error(err))
+	} else if prev != nil && prev.Status == agentStatusDisconnected {
+		s.logger.Info("agent back online", zap.String("agent_id", agentID))
+		if s.bus != nil {
+			s.bus.PublishAsync(context.WithoutCancel(ctx), plugin.Event{
+				Topic:     TopicAgentReconnected,
+				Source:    "dispatch",
+				Timestamp: time.Now(),
+				Payload: &AgentStatusEvent{
+					AgentID:     agentID,
+					Hostname:    req.Hostname,
+					DeviceID:    prev.DeviceID,
+					LastCheckIn: prev.LastCheckIn,
+				},
+			})
+		}
 	}
 
 	// 5. Log and publish metrics if present.
@@ -125,9 +142,17 @@ func (s *scoutServer) CheckIn(ctx context.Context, req *scoutpb.CheckInRequest)
 			zap.Int("disk_count", len(req.Metrics.Disks)),
 			zap.Int("network_count", len(req.Metrics.Networks)),
 			zap.Int("container_count", len(req.Metrics.ContainerStats)),
+			zap.Int("gpu_count", len(req.Metrics.Gpus)),
+			zap.Int("temperature_count", len(req.Metrics.Temperatures)),
 		)
 		payload["cpu_percent"] = strconv.FormatFloat(req.Metrics.CpuPercent, 'f', 2, 64)
 		payload["memory_percent"] = strconv.FormatFloat(req.Metrics.MemoryPercent, 'f', 2, 64)
+
+		var deviceID string
+		if prev != nil {
+			deviceID = prev.DeviceID
+		}
+		s.recordHardwareMetrics(ctx, agentID, req.Hostname, deviceID, req.Metrics)
 	}
 	if s.bus != nil {
 		_ = s.bus.Publish(ctx, plugin.Event{
@@ -161,6 +186,7 @@ func (s *scoutServer) CheckIn(ctx context.Context, req *scoutpb.CheckInRequest)
 		SignedCertificate:    certDER,
 		CaCertificate:        caCertDER,
 		UpdateUrl:            updateURL,
+		PendingCommands:      s.pendingCommandIDs(ctx, agentID),
 	}, nil
 }
 
@@ -0,0 +1,159 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// pendingCommandIDs returns the IDs of commands waiting for an agent. Errors
+// are logged and treated as no pending commands so check-in is unaffected.
+func (s *scoutServer) pendingCommandIDs(ctx context.Context, agentID string) []string {
+	cmds, err := s.store.ListPendingCommands(ctx, agentID)
+	if err != nil {
+		s.logger.Warn("failed to list pending commands", zap.String("agent_id", agentID), zap.Error(err))
+		return nil
+	}
+	ids := make([]string, 0, len(cmds))
+	for i := range cmds {
+		ids = append(ids, cmds[i].ID)
+	}
+	return ids
+}
+
+// CommandStream sends an agent its pending commands followed by an end
+// marker, then records each result the agent reports until it closes the
+// stream. The agent identifies itself with the x-agent-id metadata key,
+// which must match its client certificate when one is presented.
+func (s *scoutServer) CommandStream(stream grpc.BidiStreamingServer[scoutpb.CommandResponse, scoutpb.Command]) error {
+	ctx := stream.Context()
+
+	agentID, err := streamAgentID(ctx)
+	if err != nil {
+		return err
+	}
+	agent, err := s.store.GetAgent(ctx, agentID)
+	if err != nil {
+		return status.Errorf(codes.Internal, "get agent: %v", err)
+	}
+	if agent == nil {
+		return status.Errorf(codes.NotFound, "agent %q not found", agentID)
+	}
+
+	cmds, err := s.store.ListPendingCommands(ctx, agentID)
+	if err != nil {
+		return status.Errorf(codes.Internal, "list pending commands: %v", err)
+	}
+	for i := range cmds {
+		c := &cmds[i]
+		payload, err := commandPayload(c)
+		if err != nil {
+			return status.Errorf(codes.Internal, "marshal command payload: %v", err)
+		}
+		if err := s.store.MarkCommandDispatched(ctx, c.ID); err != nil {
+			// Another stream already took it.
+			continue
+		}
+		if err := stream.Send(&scoutpb.Command{Id: c.ID, Type: c.Type, Payload: payload}); err != nil {
+			return err
+		}
+		s.logger.Info("remote command dispatched",
+			zap.String("command_id", c.ID),
+			zap.String("agent_id", agentID),
+		)
+	}
+	if err := stream.Send(&scoutpb.Command{Type: scoutpb.CommandTypeEnd}); err != nil {
+		return err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		s.recordCommandResult(ctx, agentID, resp)
+	}
+}
+
+// commandPayload builds the JSON payload sent to the agent for a command.
+func commandPayload(c *RemoteCommand) ([]byte, error) {
+	if c.Type == scoutpb.CommandTypeCheck {
+		var target string
+		if len(c.Args) > 0 {
+			target = c.Args[0]
+		}
+		return json.Marshal(scoutpb.CheckPayload{
+			CheckType:      c.Command,
+			Target:         target,
+			TimeoutSeconds: c.TimeoutSeconds,
+		})
+	}
+	return json.Marshal(scoutpb.ExecPayload{
+		Command:        c.Command,
+		Args:           c.Args,
+		TimeoutSeconds: c.TimeoutSeconds,
+	})
+}
+
+// recordCommandResult stores a command result reported by an agent. The
+// output of a check command is stored verbatim as its stdout.
+func (s *scoutServer) recordCommandResult(ctx context.Context, agentID string, resp *scoutpb.CommandResponse) {
+	result := CommandResult{Error: resp.GetError()}
+	cmd, err := s.store.GetCommand(ctx, resp.GetCommandId())
+	if err == nil && cmd != nil && cmd.Type == scoutpb.CommandTypeCheck {
+		result.Stdout = string(resp.GetOutput())
+	} else if len(resp.GetOutput()) > 0 {
+		var out scoutpb.ExecOutput
+		if err := json.Unmarshal(resp.GetOutput(), &out); err != nil {
+			result.Error = fmt.Sprintf("invalid command output: %v", err)
+		} else {
+			result.ExitCode = out.ExitCode
+			result.Stdout = out.Stdout
+			result.Stderr = out.Stderr
+		}
+	}
+	if !resp.GetSuccess() && result.Error == "" {
+		result.Error = "command failed"
+	}
+
+	if err := s.store.CompleteCommand(ctx, resp.GetCommandId(), agentID, result); err != nil {
+		s.logger.Warn("failed to record command result",
+			zap.String("command_id", resp.GetCommandId()),
+			zap.String("agent_id", agentID),
+			zap.Error(err),
+		)
+		return
+	}
+	s.logger.Info("remote command completed",
+		zap.String("command_id", resp.GetCommandId()),
+		zap.String("agent_id", agentID),
+		zap.Bool("success", resp.GetSuccess()),
+	)
+}
+
+// streamAgentID reads the agent ID from stream metadata and checks it
+// against the client certificate CN when mTLS is in use.
+func streamAgentID(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	vals := md.Get(scoutpb.AgentIDMetadataKey)
+	if len(vals) == 0 || vals[0] == "" {
+		return "", status.Error(codes.Unauthenticated, "agent id metadata is required")
+	}
+	agentID := vals[0]
+	if certCN, ok := extractAgentIDFromCert(ctx); ok && certCN != agentID {
+		return "", status.Errorf(codes.PermissionDenied, "agent_id %q does not match client certificate CN %q", agentID, certCN)
+	}
+	return agentID, nil
+}
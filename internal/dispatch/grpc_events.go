@@ -0,0 +1,76 @@
+package dispatch
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+	"github.com/HerbHall/subnetree/pkg/plugin"
+	"go.uber.org/zap"
+)
+
+// ReportEvents stores the event log entries forwarded by an agent and
+// publishes them on TopicAgentEvents for alerting.
+func (s *scoutServer) ReportEvents(ctx context.Context, req *scoutpb.EventReport) (*scoutpb.Ack, error) {
+	if req.AgentId == "" {
+		return &scoutpb.Ack{Success: false}, fmt.Errorf("agent_id is required")
+	}
+
+	agent, err := s.store.GetAgent(ctx, req.AgentId)
+	if err != nil {
+		return &scoutpb.Ack{Success: false}, fmt.Errorf("get agent: %w", err)
+	}
+	if agent == nil {
+		return &scoutpb.Ack{Success: false}, fmt.Errorf("agent %q not found", req.AgentId)
+	}
+
+	events := make([]AgentEvent, 0, len(req.GetEvents()))
+	for _, ev := range req.GetEvents() {
+		created := time.Now().UTC()
+		if ev.GetTimeCreated() != nil {
+			created = ev.GetTimeCreated().AsTime()
+		}
+		events = append(events, AgentEvent{
+			Channel:     ev.GetChannel(),
+			Source:      ev.GetSource(),
+			EventID:     ev.GetEventId(),
+			Level:       ev.GetLevel(),
+			Message:     ev.GetMessage(),
+			TimeCreated: created,
+			RecordID:    ev.GetRecordId(),
+		})
+	}
+	if len(events) == 0 {
+		return &scoutpb.Ack{Success: true}, nil
+	}
+
+	if err := s.store.InsertAgentEvents(ctx, req.AgentId, events); err != nil {
+		s.logger.Error("failed to store agent events",
+			zap.String("agent_id", req.AgentId),
+			zap.Error(err),
+		)
+		return &scoutpb.Ack{Success: false}, fmt.Errorf("store events: %w", err)
+	}
+
+	s.logger.Debug("agent events received",
+		zap.String("agent_id", req.AgentId),
+		zap.Int("count", len(events)),
+	)
+
+	if s.bus != nil {
+		s.bus.PublishAsync(ctx, plugin.Event{
+			Topic:     TopicAgentEvents,
+			Source:    "dispatch",
+			Timestamp: time.Now(),
+			Payload: &AgentEventsEvent{
+				AgentID:  agent.ID,
+				Hostname: agent.Hostname,
+				DeviceID: agent.DeviceID,
+				Events:   events,
+			},
+		})
+	}
+
+	return &scoutpb.Ack{Success: true}, nil
+}
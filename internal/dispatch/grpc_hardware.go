@@ -0,0 +1,40 @@
+package dispatch
+
+import (
+	"context"
+	"time"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+	"github.com/HerbHall/subnetree/pkg/plugin"
+	"go.uber.org/zap"
+)
+
+// recordHardwareMetrics stores the GPU and temperature readings of a
+// check-in and publishes them on TopicAgentHardware for threshold alerts.
+// Errors are logged so check-in is unaffected.
+func (s *scoutServer) recordHardwareMetrics(ctx context.Context, agentID, hostname, deviceID string, m *scoutpb.SystemMetrics) {
+	samples := hardwareSamples(m)
+	if len(samples) == 0 {
+		return
+	}
+
+	now := time.Now()
+	if err := s.store.InsertHardwareSamples(ctx, agentID, now, samples); err != nil {
+		s.logger.Warn("failed to store hardware metrics", zap.String("agent_id", agentID), zap.Error(err))
+		return
+	}
+
+	if s.bus != nil {
+		s.bus.PublishAsync(context.WithoutCancel(ctx), plugin.Event{
+			Topic:     TopicAgentHardware,
+			Source:    "dispatch",
+			Timestamp: now,
+			Payload: &AgentHardwareEvent{
+				AgentID:  agentID,
+				Hostname: hostname,
+				DeviceID: deviceID,
+				Samples:  samples,
+			},
+		})
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"path/filepath"
@@ -21,6 +23,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/test/bufconn"
 )
 
@@ -963,3 +966,87 @@ func TestExtractAgentIDFromCert_NoPeer(t *testing.T) {
 		t.Errorf("expected ok=false for context without peer, got cn=%q", cn)
 	}
 }
+
+func TestGRPC_CommandStream(t *testing.T) {
+	client, store := testGRPCServer(t)
+	ctx := context.Background()
+	seedAgent(t, store, "agent-001")
+
+	if err := store.CreateCommand(ctx, &RemoteCommand{
+		ID:             "cmd-001",
+		AgentID:        "agent-001",
+		Command:        "uptime",
+		TimeoutSeconds: 10,
+		Status:         CommandStatusPending,
+		CreatedAt:      time.Now().UTC(),
+	}); err != nil {
+		t.Fatalf("CreateCommand: %v", err)
+	}
+
+	// Check-in advertises the pending command.
+	resp, err := client.CheckIn(ctx, &scoutpb.CheckInRequest{AgentId: "agent-001", ProtoVersion: 1})
+	if err != nil {
+		t.Fatalf("CheckIn: %v", err)
+	}
+	if len(resp.PendingCommands) != 1 || resp.PendingCommands[0] != "cmd-001" {
+		t.Fatalf("pending_commands = %v, want [cmd-001]", resp.PendingCommands)
+	}
+
+	streamCtx := metadata.AppendToOutgoingContext(ctx, scoutpb.AgentIDMetadataKey, "agent-001")
+	stream, err := client.CommandStream(streamCtx)
+	if err != nil {
+		t.Fatalf("CommandStream: %v", err)
+	}
+
+	cmd, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv command: %v", err)
+	}
+	if cmd.Id != "cmd-001" || cmd.Type != scoutpb.CommandTypeExec {
+		t.Fatalf("got command %q type %q", cmd.Id, cmd.Type)
+	}
+	var payload scoutpb.ExecPayload
+	if err := json.Unmarshal(cmd.Payload, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.Command != "uptime" || payload.TimeoutSeconds != 10 {
+		t.Errorf("payload = %+v", payload)
+	}
+
+	end, err := stream.Recv()
+	if err != nil || end.Type != scoutpb.CommandTypeEnd {
+		t.Fatalf("expected end marker, got %v, %v", end, err)
+	}
+
+	code := 0
+	output, _ := json.Marshal(scoutpb.ExecOutput{ExitCode: &code, Stdout: "up 1 day"})
+	if err := stream.Send(&scoutpb.CommandResponse{CommandId: "cmd-001", Success: true, Output: output}); err != nil {
+		t.Fatalf("Send result: %v", err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatalf("CloseSend: %v", err)
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Fatalf("expected EOF after close, got %v", err)
+	}
+
+	got, err := store.GetCommand(ctx, "cmd-001")
+	if err != nil || got == nil {
+		t.Fatalf("GetCommand: %v", err)
+	}
+	if got.Status != CommandStatusCompleted || got.Stdout != "up 1 day" {
+		t.Errorf("got status=%q stdout=%q", got.Status, got.Stdout)
+	}
+}
+
+func TestGRPC_CommandStream_NoAgentID(t *testing.T) {
+	client, _ := testGRPCServer(t)
+
+	stream, err := client.CommandStream(context.Background())
+	if err != nil {
+		t.Fatalf("CommandStream: %v", err)
+	}
+	if _, err := stream.Recv(); err == nil {
+		t.Fatal("expected error without agent id metadata")
+	}
+}
@@ -2,8 +2,10 @@ package dispatch
 
 import (
 	"crypto/sha256"
+	"database/sql"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
@@ -19,10 +21,16 @@ func (m *Module) Routes() []plugin.Route {
 		{Method: "GET", Path: "/agents", Handler: m.handleListAgents},
 		{Method: "GET", Path: "/agents/{id}", Handler: m.handleGetAgent},
 		{Method: "POST", Path: "/enroll", Handler: m.handleCreateEnrollmentToken},
+		{Method: "PATCH", Path: "/agents/{id}", Handler: m.handleUpdateAgent},
 		{Method: "DELETE", Path: "/agents/{id}", Handler: m.handleDeleteAgent},
 		{Method: "GET", Path: "/agents/{id}/hardware", Handler: m.handleGetHardwareProfile},
 		{Method: "GET", Path: "/agents/{id}/software", Handler: m.handleGetSoftwareInventory},
 		{Method: "GET", Path: "/agents/{id}/services", Handler: m.handleGetServices},
+		{Method: "GET", Path: "/agents/{id}/containers", Handler: m.handleGetContainers},
+		{Method: "GET", Path: "/agents/{id}/events", Handler: m.handleListAgentEvents},
+		{Method: "GET", Path: "/agents/{id}/metrics", Handler: m.handleGetHardwareMetrics},
+		{Method: "POST", Path: "/agents/{id}/exec", Handler: m.handleExecCommand},
+		{Method: "GET", Path: "/commands/{id}", Handler: m.handleGetCommand},
 		{Method: "GET", Path: "/install/{platform}/{arch}", Handler: m.handleInstallScript},
 		{Method: "GET", Path: "/download/{platform}/{arch}", Handler: m.handleDownloadRedirect},
 		{Method: "GET", Path: "/updates/latest", Handler: m.handleGetUpdateManifest},
@@ -32,11 +40,14 @@ func (m *Module) Routes() []plugin.Route {
 // handleListAgents returns all connected Scout agents.
 //
 //	@Summary		List agents
-//	@Description	Returns all registered Scout agents.
+//	@Description	Returns all registered Scout agents, optionally filtered by group, tag, or status.
 //	@Tags			dispatch
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Success		200	{array}	models.AgentInfo
+//	@Param			group	query	string	false	"Only agents in this group"
+//	@Param			tag		query	string	false	"Only agents carrying this tag"
+//	@Param			status	query	string	false	"Only agents with this status (connected, disconnected)"
+//	@Success		200		{array}	models.AgentInfo
 //	@Router			/dispatch/agents [get]
 func (m *Module) handleListAgents(w http.ResponseWriter, r *http.Request) {
 	if m.store == nil {
@@ -44,7 +55,12 @@ func (m *Module) handleListAgents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	agents, err := m.store.ListAgents(r.Context())
+	q := r.URL.Query()
+	agents, err := m.store.ListAgentsFiltered(r.Context(), AgentFilter{
+		Group:  q.Get("group"),
+		Tag:    q.Get("tag"),
+		Status: q.Get("status"),
+	})
 	if err != nil {
 		m.logger.Warn("failed to list agents", zap.Error(err))
 		dispatchWriteError(w, http.StatusInternalServerError, "failed to list agents")
@@ -92,6 +108,81 @@ func (m *Module) handleGetAgent(w http.ResponseWriter, r *http.Request) {
 	dispatchWriteJSON(w, http.StatusOK, agent)
 }
 
+// updateAgentRequest is the JSON body for updating agent grouping.
+// Omitted fields are left unchanged.
+type updateAgentRequest struct {
+	Group *string   `json:"group,omitempty"`
+	Tags  *[]string `json:"tags,omitempty"`
+}
+
+// handleUpdateAgent sets an agent's group and/or tags.
+//
+//	@Summary		Update agent
+//	@Description	Sets the group and/or tags of a Scout agent.
+//	@Tags			dispatch
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string				true	"Agent ID"
+//	@Param			body	body		updateAgentRequest	true	"Fields to update"
+//	@Success		200		{object}	models.AgentInfo
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		404		{object}	models.APIProblem
+//	@Router			/dispatch/agents/{id} [patch]
+func (m *Module) handleUpdateAgent(w http.ResponseWriter, r *http.Request) {
+	if m.store == nil {
+		dispatchWriteError(w, http.StatusServiceUnavailable, "dispatch store not available")
+		return
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		dispatchWriteError(w, http.StatusBadRequest, "agent id is required")
+		return
+	}
+
+	var req updateAgentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		dispatchWriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Group == nil && req.Tags == nil {
+		dispatchWriteError(w, http.StatusBadRequest, "group or tags is required")
+		return
+	}
+
+	ctx := r.Context()
+	if req.Group != nil {
+		if err := m.store.SetAgentGroup(ctx, id, *req.Group); err != nil {
+			m.writeAgentUpdateError(w, id, err)
+			return
+		}
+	}
+	if req.Tags != nil {
+		if err := m.store.SetAgentTags(ctx, id, *req.Tags); err != nil {
+			m.writeAgentUpdateError(w, id, err)
+			return
+		}
+	}
+
+	agent, err := m.store.GetAgent(ctx, id)
+	if err != nil || agent == nil {
+		m.writeAgentUpdateError(w, id, err)
+		return
+	}
+	dispatchWriteJSON(w, http.StatusOK, agent)
+}
+
+// writeAgentUpdateError maps a store error from an agent update to a response.
+func (m *Module) writeAgentUpdateError(w http.ResponseWriter, id string, err error) {
+	if err == nil || errors.Is(err, sql.ErrNoRows) {
+		dispatchWriteError(w, http.StatusNotFound, "agent not found")
+		return
+	}
+	m.logger.Warn("failed to update agent", zap.String("id", id), zap.Error(err))
+	dispatchWriteError(w, http.StatusInternalServerError, "failed to update agent")
+}
+
 // handleDeleteAgent removes a Scout agent by ID.
 //
 //	@Summary		Delete agent
@@ -0,0 +1,113 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+	"go.uber.org/zap"
+)
+
+func TestHandleUpdateAgent(t *testing.T) {
+	s := testStore(t)
+	if err := s.UpsertAgent(context.Background(), &Agent{
+		ID:         "agent-001",
+		Status:     "connected",
+		EnrolledAt: time.Now().UTC(),
+		ConfigJSON: "{}",
+	}); err != nil {
+		t.Fatalf("UpsertAgent: %v", err)
+	}
+	m := &Module{logger: zap.NewNop(), store: s}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("PATCH /agents/{id}", m.handleUpdateAgent)
+	mux.HandleFunc("GET /agents", m.handleListAgents)
+
+	tests := []struct {
+		name       string
+		id         string
+		body       string
+		wantStatus int
+	}{
+		{"set group and tags", "agent-001", `{"group":"edge","tags":["poe","rack-a"]}`, http.StatusOK},
+		{"empty body", "agent-001", `{}`, http.StatusBadRequest},
+		{"invalid json", "agent-001", `{`, http.StatusBadRequest},
+		{"unknown agent", "missing", `{"group":"edge"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/agents/"+tt.id, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+
+	for _, q := range []struct {
+		query string
+		want  int
+	}{
+		{"?group=edge", 1},
+		{"?group=core", 0},
+		{"?tag=poe", 1},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/agents"+q.query, http.NoBody)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("list %s: status = %d", q.query, rec.Code)
+		}
+		var agents []Agent
+		if err := json.NewDecoder(rec.Body).Decode(&agents); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if len(agents) != q.want {
+			t.Errorf("list %s: got %d agents, want %d", q.query, len(agents), q.want)
+		}
+	}
+}
+
+func TestHandleGetContainers(t *testing.T) {
+	s, agentID := testStoreWithAgent(t)
+	sw := &scoutpb.SoftwareInventory{
+		DockerContainers: []*scoutpb.DockerContainer{
+			{ContainerId: "abc", Name: "web", Image: "nginx", State: "running"},
+		},
+	}
+	if err := s.UpsertFullProfile(context.Background(), agentID, &scoutpb.HardwareProfile{}, sw, nil); err != nil {
+		t.Fatalf("UpsertFullProfile: %v", err)
+	}
+	m := &Module{logger: zap.NewNop(), store: s}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /agents/{id}/containers", m.handleGetContainers)
+
+	req := httptest.NewRequest(http.MethodGet, "/agents/"+agentID+"/containers", http.NoBody)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var containers []AgentContainer
+	if err := json.NewDecoder(rec.Body).Decode(&containers); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(containers) != 1 || containers[0].Name != "web" {
+		t.Errorf("containers = %+v", containers)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/agents/missing/containers", http.NoBody)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown agent status = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,76 @@
+package dispatch
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// handleGetHardwareMetrics returns a time series of an agent's GPU or
+// temperature readings.
+//
+//	@Summary		Get agent hardware metrics
+//	@Description	Returns a downsampled time series of GPU utilization, VRAM, or temperature readings
+//	@Description	reported by a Scout agent, in the same shape as pulse device metrics.
+//	@Tags			dispatch
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string	true	"Agent ID"
+//	@Param			metric	query		string	true	"Metric name" Enums(gpu_utilization, gpu_memory_used, gpu_memory_percent, gpu_temperature, cpu_temperature, chassis_temperature)
+//	@Param			range	query		string	false	"Time range" Enums(1h, 6h, 24h, 7d, 30d) default(24h)
+//	@Param			label	query		string	false	"Only this GPU (e.g. gpu0) or sensor"
+//	@Success		200		{object}	HardwareMetricSeries
+//	@Failure		400		{object}	object
+//	@Failure		404		{object}	object
+//	@Router			/dispatch/agents/{id}/metrics [get]
+func (m *Module) handleGetHardwareMetrics(w http.ResponseWriter, r *http.Request) {
+	if m.store == nil {
+		dispatchWriteError(w, http.StatusServiceUnavailable, "dispatch store not available")
+		return
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		dispatchWriteError(w, http.StatusBadRequest, "agent id is required")
+		return
+	}
+
+	q := r.URL.Query()
+	metric := q.Get("metric")
+	if !validHardwareMetrics[metric] {
+		dispatchWriteError(w, http.StatusBadRequest,
+			"metric must be gpu_utilization, gpu_memory_used, gpu_memory_percent, gpu_temperature, cpu_temperature, or chassis_temperature")
+		return
+	}
+	timeRange := q.Get("range")
+	if timeRange == "" {
+		timeRange = "24h"
+	}
+	if _, ok := hardwareRanges[timeRange]; !ok {
+		dispatchWriteError(w, http.StatusBadRequest, "range must be 1h, 6h, 24h, 7d, or 30d")
+		return
+	}
+
+	agent, err := m.store.GetAgent(r.Context(), id)
+	if err != nil {
+		m.logger.Warn("failed to get agent", zap.String("agent_id", id), zap.Error(err))
+		dispatchWriteError(w, http.StatusInternalServerError, "failed to query metrics")
+		return
+	}
+	if agent == nil {
+		dispatchWriteError(w, http.StatusNotFound, "agent not found")
+		return
+	}
+
+	series, err := m.store.QueryHardwareMetrics(r.Context(), id, metric, timeRange, q.Get("label"))
+	if err != nil {
+		m.logger.Warn("failed to query hardware metrics",
+			zap.String("agent_id", id),
+			zap.String("metric", metric),
+			zap.Error(err),
+		)
+		dispatchWriteError(w, http.StatusInternalServerError, "failed to query metrics")
+		return
+	}
+	dispatchWriteJSON(w, http.StatusOK, series)
+}
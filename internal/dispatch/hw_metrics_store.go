@@ -0,0 +1,230 @@
+package dispatch
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+)
+
+// Hardware metric names stored from agent check-ins.
+const (
+	MetricGPUUtilization     = "gpu_utilization"     // Percent
+	MetricGPUMemoryUsed      = "gpu_memory_used"     // Bytes
+	MetricGPUMemoryPercent   = "gpu_memory_percent"  // Percent of VRAM
+	MetricGPUTemperature     = "gpu_temperature"     // Celsius
+	MetricCPUTemperature     = "cpu_temperature"     // Celsius
+	MetricChassisTemperature = "chassis_temperature" // Celsius
+)
+
+// validHardwareMetrics is the set of metric names accepted by
+// QueryHardwareMetrics.
+var validHardwareMetrics = map[string]bool{
+	MetricGPUUtilization:     true,
+	MetricGPUMemoryUsed:      true,
+	MetricGPUMemoryPercent:   true,
+	MetricGPUTemperature:     true,
+	MetricCPUTemperature:     true,
+	MetricChassisTemperature: true,
+}
+
+// IsHardwareMetric reports whether name is a hardware metric stored from
+// agent check-ins.
+func IsHardwareMetric(name string) bool {
+	return validHardwareMetrics[name]
+}
+
+// hardwareRanges maps time range strings to their durations. They match
+// the ranges of pulse device metrics.
+var hardwareRanges = map[string]time.Duration{
+	"1h":  1 * time.Hour,
+	"6h":  6 * time.Hour,
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+// HardwareSample is one GPU or temperature reading. Label identifies the
+// GPU ("gpu0") or sensor the value came from.
+type HardwareSample struct {
+	Metric string  `json:"metric"`
+	Label  string  `json:"label"`
+	Value  float64 `json:"value"`
+}
+
+// HardwareMetricPoint is an averaged sample value at a point in time.
+type HardwareMetricPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// HardwareMetricSeries is a downsampled time series of one hardware metric.
+// It has the same shape as a pulse device metric series. Labels lists the
+// GPUs or sensors that reported the metric in the range.
+type HardwareMetricSeries struct {
+	AgentID string                `json:"agent_id"`
+	Metric  string                `json:"metric"`
+	Range   string                `json:"range"`
+	Label   string                `json:"label,omitempty"`
+	Labels  []string              `json:"labels"`
+	Points  []HardwareMetricPoint `json:"points"`
+}
+
+// hardwareSamples flattens the GPU and temperature readings of a metrics
+// report into samples.
+func hardwareSamples(m *scoutpb.SystemMetrics) []HardwareSample {
+	var samples []HardwareSample
+	for _, g := range m.GetGpus() {
+		label := "gpu" + strconv.FormatUint(uint64(g.GetIndex()), 10)
+		samples = append(samples,
+			HardwareSample{Metric: MetricGPUUtilization, Label: label, Value: g.GetUtilizationPercent()},
+			HardwareSample{Metric: MetricGPUMemoryUsed, Label: label, Value: g.GetMemoryUsedBytes()},
+		)
+		if g.GetMemoryTotalBytes() > 0 {
+			samples = append(samples, HardwareSample{
+				Metric: MetricGPUMemoryPercent,
+				Label:  label,
+				Value:  g.GetMemoryUsedBytes() / g.GetMemoryTotalBytes() * 100,
+			})
+		}
+		if g.GetTemperatureCelsius() > 0 {
+			samples = append(samples, HardwareSample{Metric: MetricGPUTemperature, Label: label, Value: g.GetTemperatureCelsius()})
+		}
+	}
+	for _, t := range m.GetTemperatures() {
+		metric := MetricChassisTemperature
+		if t.GetKind() == scoutpb.TemperatureKindCPU {
+			metric = MetricCPUTemperature
+		}
+		samples = append(samples, HardwareSample{Metric: metric, Label: t.GetSensor(), Value: t.GetCelsius()})
+	}
+	return samples
+}
+
+// InsertHardwareSamples stores samples recorded for an agent at the given time.
+func (s *DispatchStore) InsertHardwareSamples(ctx context.Context, agentID string, at time.Time, samples []HardwareSample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin insert hardware samples: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO dispatch_agent_hw_metrics (agent_id, metric, label, value, recorded_at)
+		VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("prepare insert hardware sample: %w", err)
+	}
+	defer stmt.Close()
+
+	at = at.UTC()
+	for i := range samples {
+		if _, err := stmt.ExecContext(ctx, agentID, samples[i].Metric, samples[i].Label, samples[i].Value, at); err != nil {
+			return fmt.Errorf("insert hardware sample: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// QueryHardwareMetrics returns an agent's samples of one metric over a time
+// range, averaged into buckets sized like pulse device metrics: 1 minute up
+// to a day, 5 minutes up to a week, then 1 hour. A non-empty label limits
+// the series to one GPU or sensor; otherwise each bucket averages them all.
+// Bucketing is performed in Go to avoid SQLite date-format parsing issues.
+func (s *DispatchStore) QueryHardwareMetrics(ctx context.Context, agentID, metric, timeRange, label string) (*HardwareMetricSeries, error) {
+	if !validHardwareMetrics[metric] {
+		return nil, fmt.Errorf("unknown hardware metric %q", metric)
+	}
+	duration, ok := hardwareRanges[timeRange]
+	if !ok {
+		return nil, fmt.Errorf("unknown range %q: must be 1h, 6h, 24h, 7d, or 30d", timeRange)
+	}
+	since := time.Now().UTC().Add(-duration)
+
+	var bucketSec int64
+	switch {
+	case duration <= 24*time.Hour:
+		bucketSec = 60
+	case duration <= 7*24*time.Hour:
+		bucketSec = 300
+	default:
+		bucketSec = 3600
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT label, value, recorded_at FROM dispatch_agent_hw_metrics
+		WHERE agent_id = ? AND metric = ? AND recorded_at >= ?`,
+		agentID, metric, since,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query hardware metrics: %w", err)
+	}
+	defer rows.Close()
+
+	type bucket struct {
+		sum   float64
+		count int
+	}
+	buckets := make(map[int64]*bucket)
+	labels := make(map[string]bool)
+	for rows.Next() {
+		var rowLabel string
+		var value float64
+		var recordedAt time.Time
+		if err := rows.Scan(&rowLabel, &value, &recordedAt); err != nil {
+			return nil, fmt.Errorf("scan hardware metric row: %w", err)
+		}
+		labels[rowLabel] = true
+		if label != "" && rowLabel != label {
+			continue
+		}
+		key := (recordedAt.Unix() / bucketSec) * bucketSec
+		b, exists := buckets[key]
+		if !exists {
+			b = &bucket{}
+			buckets[key] = b
+		}
+		b.sum += value
+		b.count++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate hardware metric rows: %w", err)
+	}
+
+	keys := slices.Sorted(maps.Keys(buckets))
+	points := make([]HardwareMetricPoint, 0, len(keys))
+	for _, key := range keys {
+		b := buckets[key]
+		points = append(points, HardwareMetricPoint{
+			Timestamp: time.Unix(key, 0).UTC(),
+			Value:     b.sum / float64(b.count),
+		})
+	}
+
+	return &HardwareMetricSeries{
+		AgentID: agentID,
+		Metric:  metric,
+		Range:   timeRange,
+		Label:   label,
+		Labels:  slices.Sorted(maps.Keys(labels)),
+		Points:  points,
+	}, nil
+}
+
+// PruneHardwareMetrics deletes samples recorded before cutoff and returns
+// the number deleted.
+func (s *DispatchStore) PruneHardwareMetrics(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM dispatch_agent_hw_metrics WHERE recorded_at < ?`, cutoff.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("prune hardware metrics: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,164 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+	"go.uber.org/zap"
+)
+
+func TestHardwareSamples(t *testing.T) {
+	samples := hardwareSamples(&scoutpb.SystemMetrics{
+		Gpus: []*scoutpb.GPUMetric{{
+			Index: 1, UtilizationPercent: 80, MemoryUsedBytes: 6, MemoryTotalBytes: 24, TemperatureCelsius: 70,
+		}},
+		Temperatures: []*scoutpb.TemperatureMetric{
+			{Sensor: "coretemp Package id 0", Kind: scoutpb.TemperatureKindCPU, Celsius: 55},
+			{Sensor: "acpitz temp1", Kind: scoutpb.TemperatureKindChassis, Celsius: 30},
+		},
+	})
+
+	got := make(map[string]HardwareSample, len(samples))
+	for _, s := range samples {
+		got[s.Metric+"/"+s.Label] = s
+	}
+	for key, want := range map[string]float64{
+		"gpu_utilization/gpu1":                  80,
+		"gpu_memory_used/gpu1":                  6,
+		"gpu_memory_percent/gpu1":               25,
+		"gpu_temperature/gpu1":                  70,
+		"cpu_temperature/coretemp Package id 0": 55,
+		"chassis_temperature/acpitz temp1":      30,
+	} {
+		if s, ok := got[key]; !ok || s.Value != want {
+			t.Errorf("sample %s = %+v, want value %v", key, s, want)
+		}
+	}
+	if len(samples) != 6 {
+		t.Errorf("got %d samples, want 6", len(samples))
+	}
+
+	if samples := hardwareSamples(&scoutpb.SystemMetrics{CpuPercent: 10}); len(samples) != 0 {
+		t.Errorf("metrics without sensors produced %v", samples)
+	}
+}
+
+func TestHardwareMetricStore(t *testing.T) {
+	s, agentID := testStoreWithAgent(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	for _, at := range []time.Time{now.Add(-2 * time.Minute), now} {
+		if err := s.InsertHardwareSamples(ctx, agentID, at, []HardwareSample{
+			{Metric: MetricGPUTemperature, Label: "gpu0", Value: 60},
+			{Metric: MetricGPUTemperature, Label: "gpu1", Value: 80},
+		}); err != nil {
+			t.Fatalf("InsertHardwareSamples: %v", err)
+		}
+	}
+
+	series, err := s.QueryHardwareMetrics(ctx, agentID, MetricGPUTemperature, "1h", "")
+	if err != nil {
+		t.Fatalf("QueryHardwareMetrics: %v", err)
+	}
+	if len(series.Points) != 2 || series.Points[0].Value != 70 {
+		t.Errorf("points = %+v, want 2 buckets averaging 70", series.Points)
+	}
+	if len(series.Labels) != 2 || series.Labels[0] != "gpu0" {
+		t.Errorf("labels = %v", series.Labels)
+	}
+
+	series, err = s.QueryHardwareMetrics(ctx, agentID, MetricGPUTemperature, "1h", "gpu1")
+	if err != nil {
+		t.Fatalf("QueryHardwareMetrics(label): %v", err)
+	}
+	if len(series.Points) != 2 || series.Points[1].Value != 80 {
+		t.Errorf("gpu1 points = %+v", series.Points)
+	}
+
+	if _, err := s.QueryHardwareMetrics(ctx, agentID, "fan_speed", "1h", ""); err == nil {
+		t.Error("expected error for unknown metric")
+	}
+	if _, err := s.QueryHardwareMetrics(ctx, agentID, MetricGPUTemperature, "2h", ""); err == nil {
+		t.Error("expected error for unknown range")
+	}
+
+	n, err := s.PruneHardwareMetrics(ctx, now.Add(-time.Minute))
+	if err != nil || n != 2 {
+		t.Errorf("PruneHardwareMetrics = %d, %v; want 2", n, err)
+	}
+}
+
+func TestGRPC_CheckIn_StoresHardwareMetrics(t *testing.T) {
+	client, store := testGRPCServer(t)
+	ctx := context.Background()
+
+	if err := store.UpsertAgent(ctx, &Agent{ID: "agent-gpu", Hostname: "ml-box", Platform: "linux/amd64", Status: "connected", ConfigJSON: "{}"}); err != nil {
+		t.Fatalf("setup agent: %v", err)
+	}
+	if _, err := client.CheckIn(ctx, &scoutpb.CheckInRequest{
+		AgentId:      "agent-gpu",
+		Hostname:     "ml-box",
+		Platform:     "linux/amd64",
+		AgentVersion: "0.1.0",
+		ProtoVersion: 1,
+		Metrics: &scoutpb.SystemMetrics{
+			Gpus: []*scoutpb.GPUMetric{{Index: 0, Name: "RTX 3090", UtilizationPercent: 95}},
+		},
+	}); err != nil {
+		t.Fatalf("CheckIn: %v", err)
+	}
+
+	series, err := store.QueryHardwareMetrics(ctx, "agent-gpu", MetricGPUUtilization, "1h", "gpu0")
+	if err != nil {
+		t.Fatalf("QueryHardwareMetrics: %v", err)
+	}
+	if len(series.Points) != 1 || series.Points[0].Value != 95 {
+		t.Errorf("points = %+v", series.Points)
+	}
+}
+
+func TestHandleGetHardwareMetrics(t *testing.T) {
+	s, agentID := testStoreWithAgent(t)
+	if err := s.InsertHardwareSamples(context.Background(), agentID, time.Now(), []HardwareSample{
+		{Metric: MetricCPUTemperature, Label: "coretemp Package id 0", Value: 58},
+	}); err != nil {
+		t.Fatalf("InsertHardwareSamples: %v", err)
+	}
+	m := &Module{logger: zap.NewNop(), store: s}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /agents/{id}/metrics", m.handleGetHardwareMetrics)
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+		return rec
+	}
+
+	rec := get("/agents/" + agentID + "/metrics?metric=cpu_temperature&range=1h")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var series HardwareMetricSeries
+	if err := json.NewDecoder(rec.Body).Decode(&series); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if series.Metric != MetricCPUTemperature || series.Range != "1h" || len(series.Points) != 1 {
+		t.Errorf("series = %+v", series)
+	}
+
+	for path, want := range map[string]int{
+		"/agents/" + agentID + "/metrics":                                 http.StatusBadRequest,
+		"/agents/" + agentID + "/metrics?metric=cpu_temperature&range=2h": http.StatusBadRequest,
+		"/agents/missing/metrics?metric=cpu_temperature":                  http.StatusNotFound,
+	} {
+		if rec := get(path); rec.Code != want {
+			t.Errorf("GET %s status = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
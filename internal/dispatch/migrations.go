@@ -67,5 +67,155 @@ func migrations() []plugin.Migration {
 				return err
 			},
 		},
+		{
+			Version:     3,
+			Description: "add group and tags columns to dispatch agents",
+			Up: func(tx *sql.Tx) error {
+				stmts := []string{
+					`ALTER TABLE dispatch_agents ADD COLUMN agent_group TEXT NOT NULL DEFAULT ''`,
+					`ALTER TABLE dispatch_agents ADD COLUMN tags TEXT NOT NULL DEFAULT '[]'`,
+					`CREATE INDEX IF NOT EXISTS idx_dispatch_agents_group ON dispatch_agents(agent_group)`,
+				}
+				for _, stmt := range stmts {
+					if _, err := tx.ExecContext(context.Background(), stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version:     4,
+			Description: "create dispatch commands table for remote execution",
+			Up: func(tx *sql.Tx) error {
+				stmts := []string{
+					`CREATE TABLE IF NOT EXISTS dispatch_commands (
+						id TEXT PRIMARY KEY,
+						agent_id TEXT NOT NULL REFERENCES dispatch_agents(id) ON DELETE CASCADE,
+						command TEXT NOT NULL,
+						args_json TEXT NOT NULL DEFAULT '[]',
+						timeout_seconds INTEGER NOT NULL DEFAULT 30,
+						status TEXT NOT NULL DEFAULT 'pending',
+						exit_code INTEGER,
+						stdout TEXT NOT NULL DEFAULT '',
+						stderr TEXT NOT NULL DEFAULT '',
+						error TEXT NOT NULL DEFAULT '',
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						dispatched_at DATETIME,
+						completed_at DATETIME
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_dispatch_commands_agent_status ON dispatch_commands(agent_id, status)`,
+				}
+				for _, stmt := range stmts {
+					if _, err := tx.ExecContext(context.Background(), stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version:     5,
+			Description: "create dispatch agent software inventory table",
+			Up: func(tx *sql.Tx) error {
+				stmts := []string{
+					`CREATE TABLE IF NOT EXISTS dispatch_agent_software (
+						agent_id TEXT NOT NULL REFERENCES dispatch_agents(id) ON DELETE CASCADE,
+						name TEXT NOT NULL,
+						version TEXT NOT NULL DEFAULT '',
+						publisher TEXT NOT NULL DEFAULT '',
+						install_date TEXT NOT NULL DEFAULT '',
+						collected_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						PRIMARY KEY (agent_id, name, version)
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_dispatch_agent_software_name ON dispatch_agent_software(name)`,
+				}
+				for _, stmt := range stmts {
+					if _, err := tx.ExecContext(context.Background(), stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version:     6,
+			Description: "create dispatch agent containers table",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(context.Background(), `
+					CREATE TABLE IF NOT EXISTS dispatch_agent_containers (
+						agent_id TEXT NOT NULL REFERENCES dispatch_agents(id) ON DELETE CASCADE,
+						container_id TEXT NOT NULL,
+						name TEXT NOT NULL DEFAULT '',
+						image TEXT NOT NULL DEFAULT '',
+						state TEXT NOT NULL DEFAULT '',
+						status TEXT NOT NULL DEFAULT '',
+						ports_json TEXT NOT NULL DEFAULT '[]',
+						collected_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						PRIMARY KEY (agent_id, container_id)
+					)`)
+				return err
+			},
+		},
+		{
+			Version:     7,
+			Description: "add command type for agent-run monitoring checks",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(context.Background(),
+					`ALTER TABLE dispatch_commands ADD COLUMN type TEXT NOT NULL DEFAULT 'exec'`)
+				return err
+			},
+		},
+		{
+			Version:     8,
+			Description: "create dispatch agent event log table",
+			Up: func(tx *sql.Tx) error {
+				stmts := []string{
+					`CREATE TABLE IF NOT EXISTS dispatch_agent_events (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						agent_id TEXT NOT NULL REFERENCES dispatch_agents(id) ON DELETE CASCADE,
+						channel TEXT NOT NULL DEFAULT '',
+						source TEXT NOT NULL DEFAULT '',
+						event_id INTEGER NOT NULL DEFAULT 0,
+						level TEXT NOT NULL DEFAULT '',
+						message TEXT NOT NULL DEFAULT '',
+						time_created DATETIME NOT NULL,
+						record_id INTEGER NOT NULL DEFAULT 0,
+						received_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_dispatch_agent_events_agent_time ON dispatch_agent_events(agent_id, time_created)`,
+					`CREATE INDEX IF NOT EXISTS idx_dispatch_agent_events_received ON dispatch_agent_events(received_at)`,
+				}
+				for _, stmt := range stmts {
+					if _, err := tx.ExecContext(context.Background(), stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version:     9,
+			Description: "create dispatch agent hardware metrics table",
+			Up: func(tx *sql.Tx) error {
+				stmts := []string{
+					`CREATE TABLE IF NOT EXISTS dispatch_agent_hw_metrics (
+						agent_id TEXT NOT NULL REFERENCES dispatch_agents(id) ON DELETE CASCADE,
+						metric TEXT NOT NULL,
+						label TEXT NOT NULL DEFAULT '',
+						value REAL NOT NULL,
+						recorded_at DATETIME NOT NULL
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_dispatch_agent_hw_metrics_series ON dispatch_agent_hw_metrics(agent_id, metric, recorded_at)`,
+					`CREATE INDEX IF NOT EXISTS idx_dispatch_agent_hw_metrics_recorded ON dispatch_agent_hw_metrics(recorded_at)`,
+				}
+				for _, stmt := range stmts {
+					if _, err := tx.ExecContext(context.Background(), stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
 	}
 }
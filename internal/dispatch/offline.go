@@ -0,0 +1,115 @@
+package dispatch
+
+import (
+	"context"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/plugin"
+	"go.uber.org/zap"
+)
+
+// runAgentOfflineSweep periodically marks agents that have not checked in
+// within AgentTimeout as disconnected and prunes expired agent events and
+// hardware metrics.
+func (m *Module) runAgentOfflineSweep(ctx context.Context) {
+	defer m.wg.Done()
+
+	interval := m.cfg.AgentTimeout / 4
+	if interval < 15*time.Second {
+		interval = 15 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	m.logger.Info("agent offline sweep started",
+		zap.Duration("check_interval", interval),
+		zap.Duration("agent_timeout", m.cfg.AgentTimeout),
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			m.logger.Info("agent offline sweep stopped")
+			return
+		case <-ticker.C:
+			m.checkForOfflineAgents(ctx)
+			m.pruneAgentEvents(ctx)
+			m.pruneHardwareMetrics(ctx)
+		}
+	}
+}
+
+// checkForOfflineAgents marks stale agents disconnected and publishes
+// TopicAgentDisconnected for each one.
+func (m *Module) checkForOfflineAgents(ctx context.Context) {
+	threshold := time.Now().UTC().Add(-m.cfg.AgentTimeout)
+
+	stale, err := m.store.FindStaleAgents(ctx, threshold)
+	if err != nil {
+		m.logger.Error("failed to find stale agents", zap.Error(err))
+		return
+	}
+
+	for i := range stale {
+		marked, err := m.store.MarkAgentDisconnected(ctx, stale[i].ID)
+		if err != nil {
+			m.logger.Error("failed to mark agent disconnected",
+				zap.String("agent_id", stale[i].ID),
+				zap.Error(err),
+			)
+			continue
+		}
+		if !marked {
+			continue
+		}
+
+		m.logger.Warn("agent marked offline",
+			zap.String("agent_id", stale[i].ID),
+			zap.String("hostname", stale[i].Hostname),
+		)
+
+		if m.bus != nil {
+			m.bus.PublishAsync(ctx, plugin.Event{
+				Topic:     TopicAgentDisconnected,
+				Source:    "dispatch",
+				Timestamp: time.Now(),
+				Payload: &AgentStatusEvent{
+					AgentID:     stale[i].ID,
+					Hostname:    stale[i].Hostname,
+					DeviceID:    stale[i].DeviceID,
+					LastCheckIn: stale[i].LastCheckIn,
+				},
+			})
+		}
+	}
+}
+
+// pruneAgentEvents deletes agent events older than EventRetention.
+func (m *Module) pruneAgentEvents(ctx context.Context) {
+	if m.cfg.EventRetention <= 0 {
+		return
+	}
+	n, err := m.store.PruneAgentEvents(ctx, time.Now().UTC().Add(-m.cfg.EventRetention))
+	if err != nil {
+		m.logger.Error("failed to prune agent events", zap.Error(err))
+		return
+	}
+	if n > 0 {
+		m.logger.Debug("pruned agent events", zap.Int64("count", n))
+	}
+}
+
+// pruneHardwareMetrics deletes agent hardware samples older than MetricsRetention.
+func (m *Module) pruneHardwareMetrics(ctx context.Context) {
+	if m.cfg.MetricsRetention <= 0 {
+		return
+	}
+	n, err := m.store.PruneHardwareMetrics(ctx, time.Now().UTC().Add(-m.cfg.MetricsRetention))
+	if err != nil {
+		m.logger.Error("failed to prune hardware metrics", zap.Error(err))
+		return
+	}
+	if n > 0 {
+		m.logger.Debug("pruned hardware metrics", zap.Int64("count", n))
+	}
+}
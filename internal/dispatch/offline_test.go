@@ -0,0 +1,67 @@
+package dispatch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HerbHall/subnetree/internal/event"
+	"github.com/HerbHall/subnetree/pkg/plugin"
+	"go.uber.org/zap"
+)
+
+func TestCheckForOfflineAgents(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+
+	stale := time.Now().UTC().Add(-time.Hour)
+	fresh := time.Now().UTC()
+	for _, a := range []*Agent{
+		{ID: "stale", Hostname: "old-box", Status: "connected", LastCheckIn: &stale, EnrolledAt: stale, DeviceID: "dev-1"},
+		{ID: "fresh", Status: "connected", LastCheckIn: &fresh, EnrolledAt: stale},
+		{ID: "pending", Status: "pending", EnrolledAt: stale},
+	} {
+		a.ConfigJSON = "{}"
+		if err := s.UpsertAgent(ctx, a); err != nil {
+			t.Fatalf("UpsertAgent(%s): %v", a.ID, err)
+		}
+	}
+
+	bus := event.NewBus(zap.NewNop())
+	events := make(chan *AgentStatusEvent, 4)
+	bus.Subscribe(TopicAgentDisconnected, func(_ context.Context, e plugin.Event) {
+		events <- e.Payload.(*AgentStatusEvent)
+	})
+
+	m := &Module{logger: zap.NewNop(), store: s, bus: bus, cfg: DefaultConfig()}
+	m.checkForOfflineAgents(ctx)
+
+	select {
+	case e := <-events:
+		if e.AgentID != "stale" || e.DeviceID != "dev-1" {
+			t.Errorf("event = %+v, want stale agent", e)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no disconnected event published")
+	}
+
+	offline, err := s.ListAgentsFiltered(ctx, AgentFilter{Status: "disconnected"})
+	if err != nil {
+		t.Fatalf("ListAgentsFiltered: %v", err)
+	}
+	if len(offline) != 1 || offline[0].ID != "stale" || offline[0].Online {
+		t.Errorf("offline agents = %+v, want only stale", offline)
+	}
+	got, _ := s.GetAgent(ctx, "fresh")
+	if !got.Online {
+		t.Error("fresh agent should still be online")
+	}
+
+	// A second sweep finds nothing new.
+	m.checkForOfflineAgents(ctx)
+	select {
+	case e := <-events:
+		t.Errorf("unexpected second event: %+v", e)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
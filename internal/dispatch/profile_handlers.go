@@ -3,6 +3,7 @@ package dispatch
 import (
 	"net/http"
 
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
 	"go.uber.org/zap"
 )
 
@@ -45,11 +46,13 @@ func (m *Module) handleGetHardwareProfile(w http.ResponseWriter, r *http.Request
 // handleGetSoftwareInventory returns the software inventory for an agent.
 //
 //	@Summary		Get agent software inventory
-//	@Description	Returns the stored software inventory for a specific agent.
+//	@Description	Returns the stored software inventory for a specific agent. Packages come
+//	@Description	from the normalized installed-software table and can be filtered by name.
 //	@Tags			dispatch
 //	@Produce		json
 //	@Security		BearerAuth
 //	@Param			id	path		string	true	"Agent ID"
+//	@Param			q	query		string	false	"Case-insensitive package name filter"
 //	@Success		200	{object}	object
 //	@Failure		404	{object}	object
 //	@Router			/dispatch/agents/{id}/software [get]
@@ -75,6 +78,22 @@ func (m *Module) handleGetSoftwareInventory(w http.ResponseWriter, r *http.Reque
 		dispatchWriteError(w, http.StatusNotFound, "software inventory not found")
 		return
 	}
+
+	pkgs, err := m.store.ListAgentSoftware(r.Context(), id, r.URL.Query().Get("q"))
+	if err != nil {
+		m.logger.Warn("failed to list agent software", zap.String("agent_id", id), zap.Error(err))
+		dispatchWriteError(w, http.StatusInternalServerError, "failed to get software inventory")
+		return
+	}
+	sw.Packages = make([]*scoutpb.InstalledPackage, 0, len(pkgs))
+	for i := range pkgs {
+		sw.Packages = append(sw.Packages, &scoutpb.InstalledPackage{
+			Name:        pkgs[i].Name,
+			Version:     pkgs[i].Version,
+			Publisher:   pkgs[i].Publisher,
+			InstallDate: pkgs[i].InstallDate,
+		})
+	}
 	dispatchWriteJSON(w, http.StatusOK, sw)
 }
 
@@ -113,3 +132,49 @@ func (m *Module) handleGetServices(w http.ResponseWriter, r *http.Request) {
 	}
 	dispatchWriteJSON(w, http.StatusOK, services)
 }
+
+// handleGetContainers returns the running containers reported by an agent.
+//
+//	@Summary		Get agent containers
+//	@Description	Returns the Docker containers last reported by a specific agent.
+//	@Tags			dispatch
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Agent ID"
+//	@Success		200	{array}		AgentContainer
+//	@Failure		404	{object}	object
+//	@Router			/dispatch/agents/{id}/containers [get]
+func (m *Module) handleGetContainers(w http.ResponseWriter, r *http.Request) {
+	if m.store == nil {
+		dispatchWriteError(w, http.StatusServiceUnavailable, "dispatch store not available")
+		return
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		dispatchWriteError(w, http.StatusBadRequest, "agent id is required")
+		return
+	}
+
+	agent, err := m.store.GetAgent(r.Context(), id)
+	if err != nil {
+		m.logger.Warn("failed to get agent", zap.String("agent_id", id), zap.Error(err))
+		dispatchWriteError(w, http.StatusInternalServerError, "failed to get containers")
+		return
+	}
+	if agent == nil {
+		dispatchWriteError(w, http.StatusNotFound, "agent not found")
+		return
+	}
+
+	containers, err := m.store.ListAgentContainers(r.Context(), id)
+	if err != nil {
+		m.logger.Warn("failed to list containers", zap.String("agent_id", id), zap.Error(err))
+		dispatchWriteError(w, http.StatusInternalServerError, "failed to get containers")
+		return
+	}
+	if containers == nil {
+		containers = []AgentContainer{}
+	}
+	dispatchWriteJSON(w, http.StatusOK, containers)
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
@@ -86,7 +87,13 @@ func (s *DispatchStore) UpsertFullProfile(ctx context.Context, agentID string, h
 	}
 
 	now := time.Now().UTC()
-	_, err = s.db.ExecContext(ctx, `
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin profile tx: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // no-op after commit
+
+	_, err = tx.ExecContext(ctx, `
 		INSERT INTO dispatch_device_profiles (agent_id, hardware_json, software_json, services_json, collected_at, updated_at)
 		VALUES (?, ?, ?, ?, ?, ?)
 		ON CONFLICT (agent_id) DO UPDATE SET
@@ -100,9 +107,172 @@ func (s *DispatchStore) UpsertFullProfile(ctx context.Context, agentID string, h
 	if err != nil {
 		return fmt.Errorf("upsert full profile: %w", err)
 	}
+	if err := replaceAgentSoftwareTx(ctx, tx, agentID, sw.GetPackages(), now); err != nil {
+		return err
+	}
+	if err := replaceAgentContainersTx(ctx, tx, agentID, sw.GetDockerContainers(), now); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AgentSoftware is one installed package reported by an agent.
+type AgentSoftware struct {
+	AgentID     string    `json:"agent_id"`
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	Publisher   string    `json:"publisher"`
+	InstallDate string    `json:"install_date,omitempty"`
+	CollectedAt time.Time `json:"collected_at"`
+}
+
+// replaceAgentSoftwareTx replaces an agent's installed-software rows with pkgs.
+func replaceAgentSoftwareTx(ctx context.Context, tx *sql.Tx, agentID string, pkgs []*scoutpb.InstalledPackage, now time.Time) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM dispatch_agent_software WHERE agent_id = ?`, agentID); err != nil {
+		return fmt.Errorf("clear agent software: %w", err)
+	}
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO dispatch_agent_software (agent_id, name, version, publisher, install_date, collected_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (agent_id, name, version) DO NOTHING`)
+	if err != nil {
+		return fmt.Errorf("prepare agent software insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, p := range pkgs {
+		if p.GetName() == "" {
+			continue
+		}
+		if _, err := stmt.ExecContext(ctx, agentID, p.GetName(), p.GetVersion(), p.GetPublisher(), p.GetInstallDate(), now); err != nil {
+			return fmt.Errorf("insert agent software: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListAgentSoftware returns the installed software reported by an agent,
+// sorted by name. A non-empty query keeps only names containing it
+// (case-insensitive).
+func (s *DispatchStore) ListAgentSoftware(ctx context.Context, agentID, query string) ([]AgentSoftware, error) {
+	q := `SELECT agent_id, name, version, publisher, install_date, collected_at
+		FROM dispatch_agent_software WHERE agent_id = ?`
+	args := []any{agentID}
+	if query != "" {
+		q += ` AND name LIKE ? ESCAPE '\'`
+		args = append(args, "%"+escapeLike(query)+"%")
+	}
+	q += ` ORDER BY name COLLATE NOCASE, version`
+	return s.querySoftware(ctx, q, args...)
+}
+
+// ListDeviceSoftware returns the installed software reported by the agents
+// linked to a device, sorted by name.
+func (s *DispatchStore) ListDeviceSoftware(ctx context.Context, deviceID string) ([]AgentSoftware, error) {
+	return s.querySoftware(ctx, `
+		SELECT sw.agent_id, sw.name, sw.version, sw.publisher, sw.install_date, sw.collected_at
+		FROM dispatch_agent_software sw
+		JOIN dispatch_agents a ON a.id = sw.agent_id
+		WHERE a.device_id = ? AND a.device_id != ''
+		ORDER BY sw.name COLLATE NOCASE, sw.version`, deviceID)
+}
+
+func (s *DispatchStore) querySoftware(ctx context.Context, query string, args ...any) ([]AgentSoftware, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list agent software: %w", err)
+	}
+	defer rows.Close()
+
+	var out []AgentSoftware
+	for rows.Next() {
+		var sw AgentSoftware
+		if err := rows.Scan(&sw.AgentID, &sw.Name, &sw.Version, &sw.Publisher, &sw.InstallDate, &sw.CollectedAt); err != nil {
+			return nil, fmt.Errorf("scan agent software row: %w", err)
+		}
+		out = append(out, sw)
+	}
+	return out, rows.Err()
+}
+
+// AgentContainer is one running container reported by an agent.
+type AgentContainer struct {
+	AgentID     string    `json:"agent_id"`
+	ContainerID string    `json:"container_id"`
+	Name        string    `json:"name"`
+	Image       string    `json:"image"`
+	State       string    `json:"state"`
+	Status      string    `json:"status"`
+	Ports       []string  `json:"ports"`
+	CollectedAt time.Time `json:"collected_at"`
+}
+
+// replaceAgentContainersTx replaces an agent's container rows with containers.
+func replaceAgentContainersTx(ctx context.Context, tx *sql.Tx, agentID string, containers []*scoutpb.DockerContainer, now time.Time) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM dispatch_agent_containers WHERE agent_id = ?`, agentID); err != nil {
+		return fmt.Errorf("clear agent containers: %w", err)
+	}
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO dispatch_agent_containers (agent_id, container_id, name, image, state, status, ports_json, collected_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (agent_id, container_id) DO NOTHING`)
+	if err != nil {
+		return fmt.Errorf("prepare agent container insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, c := range containers {
+		if c.GetContainerId() == "" {
+			continue
+		}
+		ports := c.GetPorts()
+		if ports == nil {
+			ports = []string{}
+		}
+		portsJSON, err := json.Marshal(ports)
+		if err != nil {
+			return fmt.Errorf("marshal container ports: %w", err)
+		}
+		if _, err := stmt.ExecContext(ctx, agentID, c.GetContainerId(), c.GetName(), c.GetImage(),
+			c.GetState(), c.GetStatus(), string(portsJSON), now); err != nil {
+			return fmt.Errorf("insert agent container: %w", err)
+		}
+	}
 	return nil
 }
 
+// ListAgentContainers returns the containers reported by an agent, sorted by name.
+func (s *DispatchStore) ListAgentContainers(ctx context.Context, agentID string) ([]AgentContainer, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT agent_id, container_id, name, image, state, status, ports_json, collected_at
+		FROM dispatch_agent_containers WHERE agent_id = ?
+		ORDER BY name COLLATE NOCASE, container_id`, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("list agent containers: %w", err)
+	}
+	defer rows.Close()
+
+	var out []AgentContainer
+	for rows.Next() {
+		var c AgentContainer
+		var portsJSON string
+		if err := rows.Scan(&c.AgentID, &c.ContainerID, &c.Name, &c.Image, &c.State, &c.Status, &portsJSON, &c.CollectedAt); err != nil {
+			return nil, fmt.Errorf("scan agent container row: %w", err)
+		}
+		if err := json.Unmarshal([]byte(portsJSON), &c.Ports); err != nil {
+			return nil, fmt.Errorf("unmarshal container ports: %w", err)
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// escapeLike escapes LIKE wildcards so s matches literally.
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return r.Replace(s)
+}
+
 // GetHardwareProfile retrieves the stored hardware profile for an agent.
 func (s *DispatchStore) GetHardwareProfile(ctx context.Context, agentID string) (*scoutpb.HardwareProfile, error) {
 	var jsonStr string
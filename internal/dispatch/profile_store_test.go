@@ -259,3 +259,111 @@ func TestProfileStore_UpdateExistingProfile(t *testing.T) {
 		t.Errorf("CpuCores = %d, want 8 after update", got.CpuCores)
 	}
 }
+
+func TestProfileStore_AgentSoftwareTable(t *testing.T) {
+	s, agentID := testStoreWithAgent(t)
+	ctx := context.Background()
+
+	sw := &scoutpb.SoftwareInventory{
+		OsName: "Debian GNU/Linux 12",
+		Packages: []*scoutpb.InstalledPackage{
+			{Name: "openssl", Version: "3.0.11", Publisher: "Debian"},
+			{Name: "libssl3", Version: "3.0.11", Publisher: "Debian"},
+			{Name: "curl", Version: "7.88.1"},
+			{Name: "curl", Version: "7.88.1"}, // duplicate rows are ignored
+			{Name: ""},                        // nameless rows are skipped
+		},
+	}
+	if err := s.UpsertFullProfile(ctx, agentID, &scoutpb.HardwareProfile{}, sw, nil); err != nil {
+		t.Fatalf("UpsertFullProfile: %v", err)
+	}
+
+	all, err := s.ListAgentSoftware(ctx, agentID, "")
+	if err != nil {
+		t.Fatalf("ListAgentSoftware: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("got %d packages, want 3", len(all))
+	}
+	if all[0].Name != "curl" || all[2].Name != "openssl" {
+		t.Errorf("packages not sorted by name: %v, %v", all[0].Name, all[2].Name)
+	}
+
+	ssl, err := s.ListAgentSoftware(ctx, agentID, "SSL")
+	if err != nil {
+		t.Fatalf("ListAgentSoftware(SSL): %v", err)
+	}
+	if len(ssl) != 2 {
+		t.Errorf("query SSL matched %d packages, want 2", len(ssl))
+	}
+	none, _ := s.ListAgentSoftware(ctx, agentID, "%")
+	if len(none) != 0 {
+		t.Errorf("wildcard query matched %d packages, want 0", len(none))
+	}
+
+	// A new report replaces the previous inventory.
+	sw.Packages = []*scoutpb.InstalledPackage{{Name: "openssl", Version: "3.0.13"}}
+	if err := s.UpsertFullProfile(ctx, agentID, &scoutpb.HardwareProfile{}, sw, nil); err != nil {
+		t.Fatalf("UpsertFullProfile (replace): %v", err)
+	}
+	all, _ = s.ListAgentSoftware(ctx, agentID, "")
+	if len(all) != 1 || all[0].Version != "3.0.13" {
+		t.Errorf("after replace got %+v", all)
+	}
+
+	// Device lookup goes through the agent's device link.
+	agent, _ := s.GetAgent(ctx, agentID)
+	agent.DeviceID = "dev-001"
+	if err := s.UpsertAgent(ctx, agent); err != nil {
+		t.Fatalf("UpsertAgent: %v", err)
+	}
+	byDevice, err := s.ListDeviceSoftware(ctx, "dev-001")
+	if err != nil {
+		t.Fatalf("ListDeviceSoftware: %v", err)
+	}
+	if len(byDevice) != 1 {
+		t.Errorf("ListDeviceSoftware got %d, want 1", len(byDevice))
+	}
+}
+
+func TestProfileStore_AgentContainersTable(t *testing.T) {
+	s, agentID := testStoreWithAgent(t)
+	ctx := context.Background()
+
+	sw := &scoutpb.SoftwareInventory{
+		DockerContainers: []*scoutpb.DockerContainer{
+			{ContainerId: "bbb", Name: "web", Image: "nginx:1.27", State: "running", Ports: []string{"0.0.0.0:8080->80/tcp"}},
+			{ContainerId: "aaa", Name: "db", Image: "postgres:16", State: "exited"},
+			{ContainerId: ""}, // id-less rows are skipped
+		},
+	}
+	if err := s.UpsertFullProfile(ctx, agentID, &scoutpb.HardwareProfile{}, sw, nil); err != nil {
+		t.Fatalf("UpsertFullProfile: %v", err)
+	}
+
+	got, err := s.ListAgentContainers(ctx, agentID)
+	if err != nil {
+		t.Fatalf("ListAgentContainers: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d containers, want 2", len(got))
+	}
+	if got[0].Name != "db" || got[1].Name != "web" {
+		t.Errorf("containers not sorted by name: %s, %s", got[0].Name, got[1].Name)
+	}
+	if len(got[1].Ports) != 1 || got[1].Ports[0] != "0.0.0.0:8080->80/tcp" {
+		t.Errorf("web ports = %v", got[1].Ports)
+	}
+	if got[0].Ports == nil || len(got[0].Ports) != 0 {
+		t.Errorf("db ports = %v, want empty", got[0].Ports)
+	}
+
+	// A report without containers clears the table.
+	if err := s.UpsertFullProfile(ctx, agentID, &scoutpb.HardwareProfile{}, &scoutpb.SoftwareInventory{}, nil); err != nil {
+		t.Fatalf("UpsertFullProfile (clear): %v", err)
+	}
+	got, _ = s.ListAgentContainers(ctx, agentID)
+	if len(got) != 0 {
+		t.Errorf("after clear got %d containers", len(got))
+	}
+}
@@ -3,10 +3,19 @@ package dispatch
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 )
 
+// Agent status values.
+const (
+	agentStatusConnected    = "connected"
+	agentStatusDisconnected = "disconnected"
+)
+
 // Agent represents a registered Scout agent.
 type Agent struct {
 	ID           string     `json:"id"`
@@ -16,11 +25,22 @@ type Agent struct {
 	ProtoVersion int        `json:"proto_version"`
 	DeviceID     string     `json:"device_id"`
 	Status       string     `json:"status"` // pending, connected, disconnected
+	Online       bool       `json:"online"`
 	LastCheckIn  *time.Time `json:"last_check_in,omitempty"`
 	EnrolledAt   time.Time  `json:"enrolled_at"`
 	CertSerial   string     `json:"cert_serial"`
 	CertExpires  *time.Time `json:"cert_expires_at,omitempty"`
 	ConfigJSON   string     `json:"config_json"`
+	Group        string     `json:"group"`
+	Tags         []string   `json:"tags"`
+}
+
+// AgentFilter narrows the agents returned by ListAgentsFiltered.
+// Empty fields match every agent.
+type AgentFilter struct {
+	Group  string
+	Tag    string
+	Status string
 }
 
 // EnrollmentToken represents a one-time or multi-use enrollment token.
@@ -54,8 +74,9 @@ func (s *DispatchStore) UpsertAgent(ctx context.Context, agent *Agent) error {
 		INSERT INTO dispatch_agents (
 			id, hostname, platform, agent_version, proto_version,
 			device_id, status, last_check_in, enrolled_at,
-			cert_serial, cert_expires_at, config_json
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			cert_serial, cert_expires_at, config_json,
+			agent_group, tags
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT (id) DO UPDATE SET
 			hostname = excluded.hostname,
 			platform = excluded.platform,
@@ -70,6 +91,7 @@ func (s *DispatchStore) UpsertAgent(ctx context.Context, agent *Agent) error {
 		agent.ID, agent.Hostname, agent.Platform, agent.AgentVersion, agent.ProtoVersion,
 		agent.DeviceID, agent.Status, nullTime(agent.LastCheckIn), agent.EnrolledAt,
 		agent.CertSerial, nullTime(agent.CertExpires), agent.ConfigJSON,
+		agent.Group, marshalTags(agent.Tags),
 	)
 	if err != nil {
 		return fmt.Errorf("upsert agent: %w", err)
@@ -77,25 +99,30 @@ func (s *DispatchStore) UpsertAgent(ctx context.Context, agent *Agent) error {
 	return nil
 }
 
-// GetAgent returns an agent by ID. Returns nil, nil if not found.
-func (s *DispatchStore) GetAgent(ctx context.Context, id string) (*Agent, error) {
+// agentColumns is the column list shared by agent SELECT queries; it must
+// match the scan order in scanAgent.
+const agentColumns = `id, hostname, platform, agent_version, proto_version,
+			device_id, status, last_check_in, enrolled_at,
+			cert_serial, cert_expires_at, config_json,
+			agent_group, tags`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanAgent scans a row selected with agentColumns into an Agent.
+func scanAgent(row rowScanner) (*Agent, error) {
 	var a Agent
 	var lastCheckIn, certExpires sql.NullTime
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, hostname, platform, agent_version, proto_version,
-			device_id, status, last_check_in, enrolled_at,
-			cert_serial, cert_expires_at, config_json
-		FROM dispatch_agents WHERE id = ?`, id,
-	).Scan(
+	var tagsJSON string
+	if err := row.Scan(
 		&a.ID, &a.Hostname, &a.Platform, &a.AgentVersion, &a.ProtoVersion,
 		&a.DeviceID, &a.Status, &lastCheckIn, &a.EnrolledAt,
 		&a.CertSerial, &certExpires, &a.ConfigJSON,
-	)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("get agent: %w", err)
+		&a.Group, &tagsJSON,
+	); err != nil {
+		return nil, err
 	}
 	if lastCheckIn.Valid {
 		a.LastCheckIn = &lastCheckIn.Time
@@ -103,17 +130,49 @@ func (s *DispatchStore) GetAgent(ctx context.Context, id string) (*Agent, error)
 	if certExpires.Valid {
 		a.CertExpires = &certExpires.Time
 	}
+	a.Tags = unmarshalTags(tagsJSON)
+	a.Online = a.Status == agentStatusConnected
 	return &a, nil
 }
 
+// GetAgent returns an agent by ID. Returns nil, nil if not found.
+func (s *DispatchStore) GetAgent(ctx context.Context, id string) (*Agent, error) {
+	a, err := scanAgent(s.db.QueryRowContext(ctx,
+		`SELECT `+agentColumns+` FROM dispatch_agents WHERE id = ?`, id,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get agent: %w", err)
+	}
+	return a, nil
+}
+
 // ListAgents returns all registered agents.
 func (s *DispatchStore) ListAgents(ctx context.Context) ([]Agent, error) {
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, hostname, platform, agent_version, proto_version,
-			device_id, status, last_check_in, enrolled_at,
-			cert_serial, cert_expires_at, config_json
-		FROM dispatch_agents ORDER BY enrolled_at DESC`,
-	)
+	return s.ListAgentsFiltered(ctx, AgentFilter{})
+}
+
+// ListAgentsFiltered returns the registered agents matching filter.
+func (s *DispatchStore) ListAgentsFiltered(ctx context.Context, filter AgentFilter) ([]Agent, error) {
+	query := `SELECT ` + agentColumns + ` FROM dispatch_agents`
+	var where []string
+	var args []any
+	if filter.Group != "" {
+		where = append(where, `agent_group = ?`)
+		args = append(args, filter.Group)
+	}
+	if filter.Status != "" {
+		where = append(where, `status = ?`)
+		args = append(args, filter.Status)
+	}
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, ` AND `)
+	}
+	query += ` ORDER BY enrolled_at DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("list agents: %w", err)
 	}
@@ -121,26 +180,50 @@ func (s *DispatchStore) ListAgents(ctx context.Context) ([]Agent, error) {
 
 	var agents []Agent
 	for rows.Next() {
-		var a Agent
-		var lastCheckIn, certExpires sql.NullTime
-		if err := rows.Scan(
-			&a.ID, &a.Hostname, &a.Platform, &a.AgentVersion, &a.ProtoVersion,
-			&a.DeviceID, &a.Status, &lastCheckIn, &a.EnrolledAt,
-			&a.CertSerial, &certExpires, &a.ConfigJSON,
-		); err != nil {
+		a, err := scanAgent(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan agent row: %w", err)
 		}
-		if lastCheckIn.Valid {
-			a.LastCheckIn = &lastCheckIn.Time
+		if filter.Tag != "" && !slices.Contains(a.Tags, filter.Tag) {
+			continue
 		}
-		if certExpires.Valid {
-			a.CertExpires = &certExpires.Time
-		}
-		agents = append(agents, a)
+		agents = append(agents, *a)
 	}
 	return agents, rows.Err()
 }
 
+// SetAgentGroup assigns an agent to a group. An empty group clears it.
+func (s *DispatchStore) SetAgentGroup(ctx context.Context, agentID, group string) error {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE dispatch_agents SET agent_group = ? WHERE id = ?`,
+		strings.TrimSpace(group), agentID,
+	)
+	if err != nil {
+		return fmt.Errorf("set agent group: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetAgentTags replaces an agent's tags. Tags are trimmed and de-duplicated.
+func (s *DispatchStore) SetAgentTags(ctx context.Context, agentID string, tags []string) error {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE dispatch_agents SET tags = ? WHERE id = ?`,
+		marshalTags(normalizeTags(tags)), agentID,
+	)
+	if err != nil {
+		return fmt.Errorf("set agent tags: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
 // UpdateCheckIn updates an agent's check-in timestamp and metadata.
 func (s *DispatchStore) UpdateCheckIn(ctx context.Context, agentID, hostname, platform, version string, protoVersion int) error {
 	now := time.Now().UTC()
@@ -161,6 +244,44 @@ func (s *DispatchStore) UpdateCheckIn(ctx context.Context, agentID, hostname, pl
 	return nil
 }
 
+// FindStaleAgents returns connected agents whose last check-in is older than
+// threshold (or that never checked in after enrolling before it).
+func (s *DispatchStore) FindStaleAgents(ctx context.Context, threshold time.Time) ([]Agent, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+agentColumns+` FROM dispatch_agents
+		WHERE status = ? AND COALESCE(last_check_in, enrolled_at) < ?`,
+		agentStatusConnected, threshold,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("find stale agents: %w", err)
+	}
+	defer rows.Close()
+
+	var agents []Agent
+	for rows.Next() {
+		a, err := scanAgent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan agent row: %w", err)
+		}
+		agents = append(agents, *a)
+	}
+	return agents, rows.Err()
+}
+
+// MarkAgentDisconnected sets a connected agent's status to disconnected.
+// Returns false if the agent was not connected (e.g. it checked in meanwhile).
+func (s *DispatchStore) MarkAgentDisconnected(ctx context.Context, agentID string) (bool, error) {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE dispatch_agents SET status = ? WHERE id = ? AND status = ?`,
+		agentStatusDisconnected, agentID, agentStatusConnected,
+	)
+	if err != nil {
+		return false, fmt.Errorf("mark agent disconnected: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
 // UpdateAgentCert updates the certificate serial and expiry for an agent.
 // Used during certificate renewal for existing agents.
 func (s *DispatchStore) UpdateAgentCert(ctx context.Context, agentID, certSerial string, certExpires time.Time) error {
@@ -282,3 +403,41 @@ func nullTime(t *time.Time) sql.NullTime {
 	}
 	return sql.NullTime{Time: *t, Valid: true}
 }
+
+// normalizeTags trims whitespace, drops empty entries, and removes duplicates
+// while preserving order.
+func normalizeTags(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		if t == "" || slices.Contains(out, t) {
+			continue
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+// marshalTags encodes tags as a JSON array, using "[]" for nil.
+func marshalTags(tags []string) string {
+	if tags == nil {
+		return "[]"
+	}
+	b, err := json.Marshal(tags)
+	if err != nil {
+		return "[]"
+	}
+	return string(b)
+}
+
+// unmarshalTags decodes a JSON tag array, returning an empty slice on error.
+func unmarshalTags(s string) []string {
+	tags := []string{}
+	if s == "" {
+		return tags
+	}
+	if err := json.Unmarshal([]byte(s), &tags); err != nil || tags == nil {
+		return []string{}
+	}
+	return tags
+}
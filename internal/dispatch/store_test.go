@@ -277,6 +277,83 @@ func TestDispatchStore_DeleteAgent(t *testing.T) {
 	}
 }
 
+func TestDispatchStore_AgentGroupAndTags(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, id := range []string{"agent-001", "agent-002", "agent-003"} {
+		if err := s.UpsertAgent(ctx, &Agent{
+			ID:         id,
+			Status:     "connected",
+			EnrolledAt: now,
+			ConfigJSON: "{}",
+		}); err != nil {
+			t.Fatalf("UpsertAgent %s: %v", id, err)
+		}
+	}
+
+	if err := s.SetAgentGroup(ctx, "agent-001", "edge"); err != nil {
+		t.Fatalf("SetAgentGroup: %v", err)
+	}
+	if err := s.SetAgentGroup(ctx, "agent-002", " edge "); err != nil {
+		t.Fatalf("SetAgentGroup: %v", err)
+	}
+	if err := s.SetAgentTags(ctx, "agent-002", []string{"poe", " rack-a", "poe", ""}); err != nil {
+		t.Fatalf("SetAgentTags: %v", err)
+	}
+
+	got, err := s.GetAgent(ctx, "agent-002")
+	if err != nil || got == nil {
+		t.Fatalf("GetAgent: %v", err)
+	}
+	if got.Group != "edge" {
+		t.Errorf("Group = %q, want %q", got.Group, "edge")
+	}
+	if len(got.Tags) != 2 || got.Tags[0] != "poe" || got.Tags[1] != "rack-a" {
+		t.Errorf("Tags = %v, want [poe rack-a]", got.Tags)
+	}
+
+	// Re-upserting on check-in must not clear grouping.
+	if err := s.UpsertAgent(ctx, &Agent{ID: "agent-002", Status: "connected", EnrolledAt: now, ConfigJSON: "{}"}); err != nil {
+		t.Fatalf("UpsertAgent: %v", err)
+	}
+	got, _ = s.GetAgent(ctx, "agent-002")
+	if got.Group != "edge" || len(got.Tags) != 2 {
+		t.Errorf("grouping lost after upsert: group=%q tags=%v", got.Group, got.Tags)
+	}
+
+	tests := []struct {
+		name   string
+		filter AgentFilter
+		want   int
+	}{
+		{"no filter", AgentFilter{}, 3},
+		{"group", AgentFilter{Group: "edge"}, 2},
+		{"tag", AgentFilter{Tag: "poe"}, 1},
+		{"group and tag", AgentFilter{Group: "edge", Tag: "rack-a"}, 1},
+		{"unknown group", AgentFilter{Group: "core"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agents, err := s.ListAgentsFiltered(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("ListAgentsFiltered: %v", err)
+			}
+			if len(agents) != tt.want {
+				t.Errorf("got %d agents, want %d", len(agents), tt.want)
+			}
+		})
+	}
+
+	if err := s.SetAgentGroup(ctx, "nonexistent", "edge"); err == nil {
+		t.Error("SetAgentGroup for nonexistent agent should return error")
+	}
+	if err := s.SetAgentTags(ctx, "nonexistent", nil); err == nil {
+		t.Error("SetAgentTags for nonexistent agent should return error")
+	}
+}
+
 // -- Enrollment token tests --
 
 func TestDispatchStore_EnrollmentTokenLifecycle(t *testing.T) {
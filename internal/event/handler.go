@@ -0,0 +1,151 @@
+package event
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HerbHall/subnetree/internal/auth"
+	"go.uber.org/zap"
+)
+
+// Default and maximum number of events returned by the history endpoint.
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+// Handler serves the persisted event history API.
+type Handler struct {
+	log    *Log
+	logger *zap.Logger
+	roles  auth.RoleResolver
+}
+
+// NewHandler creates a new event history API handler.
+func NewHandler(log *Log, logger *zap.Logger) *Handler {
+	return &Handler{log: log, logger: logger}
+}
+
+// SetRoleResolver sets the role resolver used to decide which topics a
+// caller may read. Without one the built-in roles apply.
+func (h *Handler) SetRoleResolver(roles auth.RoleResolver) {
+	h.roles = roles
+}
+
+// RegisterRoutes implements server.SimpleRouteRegistrar.
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/events/history", h.handleHistory)
+}
+
+// handleHistory returns persisted events, oldest first. Only topics the
+// caller could receive on the event stream are returned.
+//
+//	@Summary		Event history
+//	@Description	Returns events recorded by the persistent event log, oldest first, limited to topics the caller's role may read. Requires events.persist to be enabled.
+//	@Tags			events
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			topic	query		string	false	"Topic to include; a trailing * matches a prefix (e.g. recon.*)"
+//	@Param			since	query		string	false	"Only events at or after this RFC 3339 time"
+//	@Param			limit	query		int		false	"Maximum events to return (1-1000)" default(100)
+//	@Success		200		{array}		Record
+//	@Failure		400		{object}	map[string]any
+//	@Failure		403		{object}	map[string]any
+//	@Failure		500		{object}	map[string]any
+//	@Failure		503		{object}	map[string]any
+//	@Router			/events/history [get]
+func (h *Handler) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if h.log == nil {
+		writeError(w, http.StatusServiceUnavailable, "event log not enabled")
+		return
+	}
+
+	q := r.URL.Query()
+	filter := HistoryFilter{Topic: q.Get("topic"), Limit: defaultHistoryLimit}
+	if s := q.Get("since"); s != "" {
+		since, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "since must be an RFC 3339 time")
+			return
+		}
+		filter.Since = since
+	}
+	if s := q.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit < 1 || limit > maxHistoryLimit {
+			writeError(w, http.StatusBadRequest, "limit must be an integer between 1 and 1000")
+			return
+		}
+		filter.Limit = limit
+	}
+	if !h.restrictTopics(w, r, &filter) {
+		return
+	}
+
+	records, err := h.log.History(r.Context(), filter)
+	if err != nil {
+		h.logger.Error("failed to query event history", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to query event history")
+		return
+	}
+	if records == nil {
+		records = []Record{}
+	}
+	writeJSON(w, http.StatusOK, records)
+}
+
+// restrictTopics limits filter to the topics the authenticated caller may
+// read, using the same per-topic rules as the event stream. It writes an
+// error response and returns false when the caller may not read the
+// requested topic. Unauthenticated requests (auth disabled) are not
+// restricted.
+func (h *Handler) restrictTopics(w http.ResponseWriter, r *http.Request, filter *HistoryFilter) bool {
+	if auth.UserFromContext(r.Context()) == nil {
+		return true
+	}
+	access, err := auth.RequestAccess(r, h.roles)
+	if err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return false
+	}
+	if filter.Topic != "" && !access.CanReadTopic(strings.TrimSuffix(filter.Topic, "*")) {
+		writeError(w, http.StatusForbidden, "not permitted to read topic "+filter.Topic)
+		return false
+	}
+
+	topics, err := h.log.Topics(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list event topics", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to query event history")
+		return false
+	}
+	filter.Topics = []string{}
+	for _, topic := range topics {
+		if access.CanReadTopic(topic) {
+			filter.Topics = append(filter.Topics, topic)
+		}
+	}
+	return true
+}
+
+// -- helpers --
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"type":   "https://subnetree.com/problems/" + http.StatusText(status),
+		"title":  http.StatusText(status),
+		"status": status,
+		"detail": detail,
+	})
+}
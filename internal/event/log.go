@@ -0,0 +1,305 @@
+package event
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/plugin"
+	"go.uber.org/zap"
+)
+
+// logBufferSize is the number of events queued for persistence. Events are
+// dropped, not blocked on, when the writer falls further behind.
+const logBufferSize = 1024
+
+// logBatchSize caps the number of events written per transaction.
+const logBatchSize = 100
+
+// logPruneInterval is how often events older than the retention are deleted.
+const logPruneInterval = time.Hour
+
+// Record is a persisted event.
+type Record struct {
+	ID        int64           `json:"id" example:"42"`
+	Topic     string          `json:"topic" example:"recon.device.discovered"`
+	Source    string          `json:"source" example:"recon"`
+	Timestamp time.Time       `json:"timestamp"`
+	Payload   json.RawMessage `json:"payload" swaggertype:"object"`
+}
+
+// HistoryFilter selects persisted events. A Topic ending in "*" matches by
+// prefix. Zero values do not filter, except that a non-nil empty Topics
+// matches nothing.
+type HistoryFilter struct {
+	Topic string
+	// Topics, when non-nil, limits results to these exact topics.
+	Topics []string
+	Since  time.Time
+	Limit  int
+}
+
+// Log persists published events to the database. It subscribes to every
+// topic and queues events for a background writer, so publishing stays
+// in-memory and never waits on the database.
+type Log struct {
+	db        *sql.DB
+	logger    *zap.Logger
+	retention time.Duration
+
+	queue       chan plugin.Event
+	unsubscribe func()
+	wg          sync.WaitGroup
+	dropped     atomic.Uint64
+}
+
+// NewLog creates an event log and ensures its table exists. Events older
+// than retention are pruned; zero keeps them forever.
+func NewLog(db *sql.DB, retention time.Duration, logger *zap.Logger) (*Log, error) {
+	l := &Log{
+		db:        db,
+		logger:    logger,
+		retention: retention,
+		queue:     make(chan plugin.Event, logBufferSize),
+	}
+	if err := l.migrate(); err != nil {
+		return nil, fmt.Errorf("event log migrate: %w", err)
+	}
+	return l, nil
+}
+
+func (l *Log) migrate() error {
+	_, err := l.db.Exec(`
+		CREATE TABLE IF NOT EXISTS event_log (
+			id           INTEGER PRIMARY KEY AUTOINCREMENT,
+			topic        TEXT NOT NULL,
+			source       TEXT NOT NULL DEFAULT '',
+			payload      TEXT NOT NULL DEFAULT 'null',
+			published_at DATETIME NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_event_log_published_at ON event_log(published_at);
+		CREATE INDEX IF NOT EXISTS idx_event_log_topic ON event_log(topic, published_at);
+	`)
+	return err
+}
+
+// Start subscribes the log to all topics on the bus and launches the
+// background writer. Stop must be called to flush queued events.
+func (l *Log) Start(bus plugin.EventBus) {
+	l.unsubscribe = bus.SubscribeAll(l.enqueue)
+	l.wg.Add(1)
+	go l.run()
+}
+
+// Stop unsubscribes from the bus and waits for queued events to be written.
+func (l *Log) Stop() {
+	if l.unsubscribe == nil {
+		return
+	}
+	l.unsubscribe()
+	l.unsubscribe = nil
+	close(l.queue)
+	l.wg.Wait()
+}
+
+// enqueue is the bus handler. It must not block the publisher.
+func (l *Log) enqueue(_ context.Context, ev plugin.Event) {
+	select {
+	case l.queue <- ev:
+	default:
+		if dropped := l.dropped.Add(1); dropped == 1 || dropped%100 == 0 {
+			l.logger.Warn("event log queue full, dropping events",
+				zap.String("topic", ev.Topic),
+				zap.Uint64("dropped", dropped),
+			)
+		}
+	}
+}
+
+// run writes queued events in batches and prunes old events until the
+// queue is closed.
+func (l *Log) run() {
+	defer l.wg.Done()
+	prune := time.NewTicker(logPruneInterval)
+	defer prune.Stop()
+
+	batch := make([]plugin.Event, 0, logBatchSize)
+	for {
+		select {
+		case ev, ok := <-l.queue:
+			if !ok {
+				return
+			}
+			batch = append(batch[:0], ev)
+		drain:
+			for len(batch) < logBatchSize {
+				select {
+				case next, ok := <-l.queue:
+					if !ok {
+						break drain
+					}
+					batch = append(batch, next)
+				default:
+					break drain
+				}
+			}
+			if err := l.write(context.Background(), batch); err != nil {
+				l.logger.Warn("failed to persist events", zap.Int("count", len(batch)), zap.Error(err))
+			}
+		case <-prune.C:
+			if l.retention <= 0 {
+				continue
+			}
+			n, err := l.Prune(context.Background(), time.Now().Add(-l.retention))
+			if err != nil {
+				l.logger.Warn("failed to prune event log", zap.Error(err))
+			} else if n > 0 {
+				l.logger.Debug("pruned event log", zap.Int64("deleted", n))
+			}
+		}
+	}
+}
+
+// write inserts a batch of events in one transaction. Payloads that cannot
+// be encoded as JSON are stored as null.
+func (l *Log) write(ctx context.Context, events []plugin.Event) error {
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin event log write: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
+
+	for i := range events {
+		ev := &events[i]
+		payload, err := json.Marshal(ev.Payload)
+		if err != nil {
+			l.logger.Debug("storing unencodable event payload as null", zap.String("topic", ev.Topic), zap.Error(err))
+			payload = []byte("null")
+		}
+		ts := ev.Timestamp
+		if ts.IsZero() {
+			ts = time.Now()
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO event_log (topic, source, payload, published_at) VALUES (?, ?, ?, ?)`,
+			ev.Topic, ev.Source, string(payload), ts.UTC(),
+		); err != nil {
+			return fmt.Errorf("insert event: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// History returns persisted events matching the filter, oldest first.
+func (l *Log) History(ctx context.Context, filter HistoryFilter) ([]Record, error) {
+	if filter.Topics != nil && len(filter.Topics) == 0 {
+		return nil, nil
+	}
+	query := `SELECT id, topic, source, payload, published_at FROM event_log WHERE 1=1`
+	var args []any
+	if filter.Topic != "" {
+		if prefix, ok := strings.CutSuffix(filter.Topic, "*"); ok {
+			query += ` AND substr(topic, 1, ?) = ?`
+			args = append(args, len(prefix), prefix)
+		} else {
+			query += ` AND topic = ?`
+			args = append(args, filter.Topic)
+		}
+	}
+	if len(filter.Topics) > 0 {
+		query += ` AND topic IN (?` + strings.Repeat(`, ?`, len(filter.Topics)-1) + `)`
+		for _, t := range filter.Topics {
+			args = append(args, t)
+		}
+	}
+	if !filter.Since.IsZero() {
+		query += ` AND published_at >= ?`
+		args = append(args, filter.Since.UTC())
+	}
+	query += ` ORDER BY published_at, id`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := l.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query event log: %w", err)
+	}
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		var rec Record
+		var payload string
+		if err := rows.Scan(&rec.ID, &rec.Topic, &rec.Source, &payload, &rec.Timestamp); err != nil {
+			return nil, fmt.Errorf("scan event log row: %w", err)
+		}
+		rec.Payload = json.RawMessage(payload)
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
+
+// Topics returns the distinct topics in the event log, sorted.
+func (l *Log) Topics(ctx context.Context) ([]string, error) {
+	rows, err := l.db.QueryContext(ctx, `SELECT DISTINCT topic FROM event_log ORDER BY topic`)
+	if err != nil {
+		return nil, fmt.Errorf("query event log topics: %w", err)
+	}
+	defer rows.Close()
+
+	var topics []string
+	for rows.Next() {
+		var topic string
+		if err := rows.Scan(&topic); err != nil {
+			return nil, fmt.Errorf("scan event log topic: %w", err)
+		}
+		topics = append(topics, topic)
+	}
+	return topics, rows.Err()
+}
+
+// Replay delivers persisted events matching the filter to handler, oldest
+// first, so a late subscriber can catch up before handling live events.
+// Replayed payloads are the stored JSON as a json.RawMessage, not the
+// original payload type.
+func (l *Log) Replay(ctx context.Context, filter HistoryFilter, handler plugin.EventHandler) error {
+	records, err := l.History(ctx, filter)
+	if err != nil {
+		return err
+	}
+	for i := range records {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		handler(ctx, records[i].Event())
+	}
+	return nil
+}
+
+// Event converts the record back to a bus event.
+func (r *Record) Event() plugin.Event {
+	return plugin.Event{
+		Topic:     r.Topic,
+		Source:    r.Source,
+		Timestamp: r.Timestamp,
+		Payload:   r.Payload,
+	}
+}
+
+// Prune deletes events published before the given time. Returns the number
+// of events deleted.
+func (l *Log) Prune(ctx context.Context, before time.Time) (int64, error) {
+	res, err := l.db.ExecContext(ctx, `DELETE FROM event_log WHERE published_at < ?`, before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("prune event log: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,198 @@
+package event
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/HerbHall/subnetree/internal/auth"
+	"github.com/HerbHall/subnetree/pkg/plugin"
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite"
+)
+
+// testLog creates an event log over an in-memory database, records the
+// given events through a bus, and flushes them.
+func testLog(t *testing.T, events ...plugin.Event) *Log {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open in-memory db: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	l, err := NewLog(db, 0, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewLog: %v", err)
+	}
+	bus := NewBus(testLogger())
+	l.Start(bus)
+	for _, ev := range events {
+		if err := bus.Publish(context.Background(), ev); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+	l.Stop()
+	return l
+}
+
+func TestLog_HistoryAndReplay(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	l := testLog(t,
+		plugin.Event{Topic: "recon.device.discovered", Source: "recon", Timestamp: base, Payload: map[string]string{"id": "d1"}},
+		plugin.Event{Topic: "pulse.alert.triggered", Source: "pulse", Timestamp: base.Add(time.Minute), Payload: "down"},
+		plugin.Event{Topic: "recon.scan.completed", Source: "recon", Timestamp: base.Add(2 * time.Minute), Payload: nil},
+	)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		filter HistoryFilter
+		want   []string
+	}{
+		{"all", HistoryFilter{}, []string{"recon.device.discovered", "pulse.alert.triggered", "recon.scan.completed"}},
+		{"exact topic", HistoryFilter{Topic: "pulse.alert.triggered"}, []string{"pulse.alert.triggered"}},
+		{"topic prefix", HistoryFilter{Topic: "recon.*"}, []string{"recon.device.discovered", "recon.scan.completed"}},
+		{"since", HistoryFilter{Since: base.Add(time.Minute)}, []string{"pulse.alert.triggered", "recon.scan.completed"}},
+		{"limit", HistoryFilter{Limit: 1}, []string{"recon.device.discovered"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := l.History(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("History: %v", err)
+			}
+			if len(records) != len(tt.want) {
+				t.Fatalf("got %d records, want %d", len(records), len(tt.want))
+			}
+			for i := range records {
+				if records[i].Topic != tt.want[i] {
+					t.Errorf("records[%d].Topic = %q, want %q", i, records[i].Topic, tt.want[i])
+				}
+			}
+		})
+	}
+
+	var replayed []plugin.Event
+	err := l.Replay(ctx, HistoryFilter{Topic: "recon.device.*"}, func(_ context.Context, ev plugin.Event) {
+		replayed = append(replayed, ev)
+	})
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if len(replayed) != 1 || !replayed[0].Timestamp.Equal(base) || replayed[0].Source != "recon" {
+		t.Fatalf("replayed = %+v, want the discovered event", replayed)
+	}
+	if raw, ok := replayed[0].Payload.(json.RawMessage); !ok || string(raw) != `{"id":"d1"}` {
+		t.Errorf("replayed payload = %v, want stored JSON", replayed[0].Payload)
+	}
+
+	n, err := l.Prune(ctx, base.Add(90*time.Second))
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Prune deleted %d, want 2", n)
+	}
+}
+
+func TestHandleHistory(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	l := testLog(t, plugin.Event{Topic: "recon.scan.completed", Source: "recon", Timestamp: base})
+
+	tests := []struct {
+		name  string
+		log   *Log
+		query string
+		want  int
+	}{
+		{"ok", l, "?topic=recon.*&since=2026-03-01T00:00:00Z", http.StatusOK},
+		{"bad since", l, "?since=yesterday", http.StatusBadRequest},
+		{"bad limit", l, "?limit=0", http.StatusBadRequest},
+		{"disabled", nil, "", http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			NewHandler(tt.log, zap.NewNop()).RegisterRoutes(mux)
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/events/history"+tt.query, http.NoBody))
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+			var records []Record
+			if err := json.NewDecoder(rec.Body).Decode(&records); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if len(records) != 1 || records[0].Topic != "recon.scan.completed" {
+				t.Errorf("records = %+v, want the scan event", records)
+			}
+		})
+	}
+}
+
+func TestHandleHistory_FiltersTopicsByRole(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	l := testLog(t,
+		plugin.Event{Topic: "recon.scan.completed", Source: "recon", Timestamp: base},
+		plugin.Event{Topic: "auth.login.succeeded", Source: "auth", Timestamp: base.Add(time.Second)},
+		plugin.Event{Topic: "vault.credential.accessed", Source: "vault", Timestamp: base.Add(2 * time.Second)},
+	)
+
+	tokens := auth.NewTokenService([]byte("test-secret-test-secret-test-secret"), time.Minute, time.Hour)
+	mux := http.NewServeMux()
+	NewHandler(l, zap.NewNop()).RegisterRoutes(mux)
+	h := auth.AuthMiddleware(tokens)(mux)
+
+	tests := []struct {
+		name       string
+		role       auth.Role
+		query      string
+		wantStatus int
+		wantTopics []string
+	}{
+		{"viewer sees module events", auth.RoleViewer, "", http.StatusOK, []string{"recon.scan.completed"}},
+		{"viewer auth topic", auth.RoleViewer, "?topic=auth.*", http.StatusForbidden, nil},
+		{"viewer vault topic", auth.RoleViewer, "?topic=vault.credential.accessed", http.StatusForbidden, nil},
+		{"operator excludes auth", auth.RoleOperator, "", http.StatusOK, []string{"recon.scan.completed", "vault.credential.accessed"}},
+		{"admin sees all", auth.RoleAdmin, "", http.StatusOK, []string{"recon.scan.completed", "auth.login.succeeded", "vault.credential.accessed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := tokens.IssueAccessToken(&auth.User{ID: "user-1", Username: "user", Role: tt.role})
+			if err != nil {
+				t.Fatalf("IssueAccessToken: %v", err)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/events/history"+tt.query, http.NoBody)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var records []Record
+			if err := json.NewDecoder(rec.Body).Decode(&records); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if len(records) != len(tt.wantTopics) {
+				t.Fatalf("got %d records, want %v", len(records), tt.wantTopics)
+			}
+			for i := range records {
+				if records[i].Topic != tt.wantTopics[i] {
+					t.Errorf("records[%d].Topic = %q, want %q", i, records[i].Topic, tt.wantTopics[i])
+				}
+			}
+		})
+	}
+}
@@ -9,6 +9,19 @@ type GatewayConfig struct {
 	AuditRetentionDays  int           `mapstructure:"audit_retention_days"`
 	MaintenanceInterval time.Duration `mapstructure:"maintenance_interval"`
 	DefaultProxyPort    int           `mapstructure:"default_proxy_port"`
+
+	// RecordSessions enables asciicast recording of SSH session input and
+	// output. Off by default; session metadata is stored either way.
+	RecordSessions bool   `mapstructure:"record_sessions"`
+	RecordingDir   string `mapstructure:"recording_dir"`
+
+	// SFTPMaxFileSize caps SFTP uploads and downloads in bytes. Zero disables the limit.
+	SFTPMaxFileSize int64 `mapstructure:"sftp_max_file_size"`
+
+	// MaxSSHSessions and MaxSSHSessionsPerUser cap concurrent SSH bridge
+	// sessions across the gateway and for a single user. Zero disables a cap.
+	MaxSSHSessions        int `mapstructure:"max_ssh_sessions"`
+	MaxSSHSessionsPerUser int `mapstructure:"max_ssh_sessions_per_user"`
 }
 
 // DefaultConfig returns the default Gateway configuration.
@@ -19,5 +32,11 @@ func DefaultConfig() GatewayConfig {
 		AuditRetentionDays:  90,
 		MaintenanceInterval: 5 * time.Minute,
 		DefaultProxyPort:    80,
+		RecordSessions:      false,
+		RecordingDir:        "data/recordings",
+		SFTPMaxFileSize:     100 << 20, // 100 MiB
+
+		MaxSSHSessions:        50,
+		MaxSSHSessionsPerUser: 5,
 	}
 }
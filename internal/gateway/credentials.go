@@ -0,0 +1,91 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// CredentialDecrypter retrieves decrypted credential data from the vault.
+// Implemented by the existing vaultDecryptAdapter in main.go.
+type CredentialDecrypter interface {
+	DecryptCredential(ctx context.Context, id string) (map[string]any, error)
+}
+
+// SetCredentialDecrypter wires the vault credential decrypter so SSH
+// sessions can authenticate with a stored credential ID.
+func (m *Module) SetCredentialDecrypter(d CredentialDecrypter) {
+	m.credDecrypter = d
+}
+
+// errCredentialForbidden is returned when the caller's role may not use
+// stored vault credentials.
+var errCredentialForbidden = errors.New("vault write access required to use a stored credential")
+
+// resolveCredentials resolves a vault credential on behalf of the caller.
+// Using a stored secret requires the same vault write access as reading
+// it through the vault API.
+func (b *SSHBridge) resolveCredentials(ctx context.Context, claims *TokenClaims, creds *sshCredentials) error {
+	if creds.CredentialID != "" && !b.tokens.RoleAllows(ctx, claims.Role, "vault", true) {
+		return errCredentialForbidden
+	}
+	return b.module.resolveSSHCredentials(ctx, creds)
+}
+
+// resolveSSHCredentials fills username and secrets from the vault when the
+// client referenced a stored credential. Explicit fields sent by the client
+// take precedence over stored values.
+func (m *Module) resolveSSHCredentials(ctx context.Context, creds *sshCredentials) error {
+	if creds.CredentialID == "" {
+		return nil
+	}
+	if m.credDecrypter == nil {
+		return errors.New("credential store not available")
+	}
+
+	data, err := m.credDecrypter.DecryptCredential(ctx, creds.CredentialID)
+	if err != nil {
+		return fmt.Errorf("decrypt credential: %w", err)
+	}
+	if creds.Username == "" {
+		creds.Username, _ = data["username"].(string)
+	}
+	if creds.Password == "" {
+		creds.Password, _ = data["password"].(string)
+	}
+	if creds.PrivateKey == "" {
+		creds.PrivateKey, _ = data["private_key"].(string)
+	}
+	if creds.Passphrase == "" {
+		creds.Passphrase, _ = data["passphrase"].(string)
+	}
+	return nil
+}
+
+// sshAuthMethods builds the SSH auth methods for the given credentials.
+// Key-based auth is tried first when a private key is present.
+func sshAuthMethods(creds *sshCredentials) ([]ssh.AuthMethod, error) {
+	var methods []ssh.AuthMethod
+	if creds.PrivateKey != "" {
+		var signer ssh.Signer
+		var err error
+		if creds.Passphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(creds.PrivateKey), []byte(creds.Passphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey([]byte(creds.PrivateKey))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse private key: %w", err)
+		}
+		methods = append(methods, ssh.PublicKeys(signer))
+	}
+	if creds.Password != "" {
+		methods = append(methods, ssh.Password(creds.Password))
+	}
+	if len(methods) == 0 {
+		return nil, errors.New("password or private key is required")
+	}
+	return methods, nil
+}
@@ -3,6 +3,7 @@ package gateway
 import (
 	"context"
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
@@ -27,9 +28,12 @@ type Module struct {
 	bus          plugin.EventBus
 	plugins      plugin.PluginResolver
 	sessions     *SessionManager
+	sshLimits    *sshSessionLimiter
 	proxies      *ReverseProxyManager
 	deviceLookup DeviceLookup
 
+	credDecrypter CredentialDecrypter
+
 	ctx    context.Context
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
@@ -70,6 +74,7 @@ func (m *Module) Init(_ context.Context, deps plugin.Dependencies) error {
 	m.bus = deps.Bus
 	m.plugins = deps.Plugins
 	m.sessions = NewSessionManager(m.cfg.MaxSessions)
+	m.sshLimits = newSSHSessionLimiter(m.cfg.MaxSSHSessions, m.cfg.MaxSSHSessionsPerUser)
 
 	m.logger.Info("gateway module initialized")
 	return nil
@@ -214,6 +219,18 @@ func (m *Module) runMaintenance() {
 				zap.Int64("deleted", deleted),
 			)
 		}
+
+		// SSH session history and recordings follow the audit retention.
+		paths, err := m.store.DeleteOldSSHSessions(m.ctx, cutoff)
+		if err != nil {
+			m.logger.Warn("gateway session history maintenance failed", zap.Error(err))
+			return
+		}
+		for _, p := range paths {
+			if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+				m.logger.Warn("failed to remove SSH session recording", zap.String("path", p), zap.Error(err))
+			}
+		}
 	}
 }
 
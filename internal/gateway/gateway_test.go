@@ -118,6 +118,9 @@ func TestRoutes(t *testing.T) {
 
 	want := map[string]string{
 		"GET /sessions":                           "",
+		"GET /sessions/history":                   "",
+		"GET /sessions/stats":                     "",
+		"GET /sessions/{id}/recording":            "",
 		"GET /sessions/{id}":                      "",
 		"DELETE /sessions/{id}":                   "",
 		"GET /status":                             "",
@@ -3,10 +3,13 @@ package gateway
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
 	"strconv"
 	"time"
 
+	"github.com/HerbHall/subnetree/internal/auth"
 	"github.com/HerbHall/subnetree/pkg/plugin"
 	"go.uber.org/zap"
 )
@@ -28,6 +31,9 @@ type createProxyResponse struct {
 func (m *Module) Routes() []plugin.Route {
 	return []plugin.Route{
 		{Method: "GET", Path: "/sessions", Handler: m.handleListSessions},
+		{Method: "GET", Path: "/sessions/history", Handler: m.handleListSessionHistory},
+		{Method: "GET", Path: "/sessions/stats", Handler: m.handleSessionStats},
+		{Method: "GET", Path: "/sessions/{id}/recording", Handler: m.handleGetSessionRecording},
 		{Method: "GET", Path: "/sessions/{id}", Handler: m.handleGetSession},
 		{Method: "DELETE", Path: "/sessions/{id}", Handler: m.handleDeleteSession},
 		{Method: "GET", Path: "/status", Handler: m.handleStatus},
@@ -125,6 +131,12 @@ func (m *Module) handleStatus(w http.ResponseWriter, _ *http.Request) {
 	})
 }
 
+// handleSessionStats returns the number of active SSH bridge sessions,
+// overall and per user, with the configured limits.
+func (m *Module) handleSessionStats(w http.ResponseWriter, _ *http.Request) {
+	gatewayWriteJSON(w, http.StatusOK, m.sshLimits.stats())
+}
+
 // handleListAudit returns audit log entries with optional device filtering.
 func (m *Module) handleListAudit(w http.ResponseWriter, r *http.Request) {
 	if m.store == nil {
@@ -147,6 +159,75 @@ func (m *Module) handleListAudit(w http.ResponseWriter, r *http.Request) {
 	gatewayWriteJSON(w, http.StatusOK, entries)
 }
 
+// handleListSessionHistory returns past and active SSH sessions, newest first.
+// Non-admins only see the sessions they opened.
+func (m *Module) handleListSessionHistory(w http.ResponseWriter, r *http.Request) {
+	if m.store == nil {
+		gatewayWriteError(w, http.StatusServiceUnavailable, "gateway store not available")
+		return
+	}
+
+	deviceID := r.URL.Query().Get("device_id")
+	limit := gatewayParseLimit(r, 100)
+	var userID string
+	if claims := auth.UserFromContext(r.Context()); claims != nil && auth.Role(claims.Role) != auth.RoleAdmin {
+		userID = claims.UserID
+	}
+
+	records, err := m.store.ListSSHSessions(r.Context(), deviceID, userID, limit)
+	if err != nil {
+		m.logger.Warn("failed to list SSH session history", zap.Error(err))
+		gatewayWriteError(w, http.StatusInternalServerError, "failed to list session history")
+		return
+	}
+	if records == nil {
+		records = []SSHSessionRecord{}
+	}
+	gatewayWriteJSON(w, http.StatusOK, records)
+}
+
+// handleGetSessionRecording downloads the asciicast recording of an SSH session.
+// Recordings hold every keystroke, so only admins and the session owner may
+// download them.
+func (m *Module) handleGetSessionRecording(w http.ResponseWriter, r *http.Request) {
+	if m.store == nil {
+		gatewayWriteError(w, http.StatusServiceUnavailable, "gateway store not available")
+		return
+	}
+
+	id := r.PathValue("id")
+	rec, err := m.store.GetSSHSession(r.Context(), id)
+	if err != nil {
+		m.logger.Warn("failed to get SSH session", zap.String("id", id), zap.Error(err))
+		gatewayWriteError(w, http.StatusInternalServerError, "failed to get session")
+		return
+	}
+	if rec == nil {
+		gatewayWriteError(w, http.StatusNotFound, "session not found")
+		return
+	}
+	if claims := auth.UserFromContext(r.Context()); claims != nil && auth.Role(claims.Role) != auth.RoleAdmin && claims.UserID != rec.UserID {
+		gatewayWriteError(w, http.StatusForbidden, "only admins and the session owner may download a recording")
+		return
+	}
+	if !rec.HasRecording {
+		gatewayWriteError(w, http.StatusNotFound, "session has no recording")
+		return
+	}
+
+	f, err := os.Open(rec.RecordingPath)
+	if err != nil {
+		gatewayWriteError(w, http.StatusNotFound, "recording file not available")
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", "application/x-asciicast")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", id+".cast"))
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, f)
+}
+
 // --- Proxy Handlers ---
 
 // handleCreateProxy creates a new proxy session for a device.
@@ -12,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/HerbHall/subnetree/internal/auth"
 	"github.com/HerbHall/subnetree/internal/store"
 	"github.com/HerbHall/subnetree/pkg/plugin"
 	"go.uber.org/zap"
@@ -707,3 +708,160 @@ func TestGatewayParseLimit(t *testing.T) {
 		})
 	}
 }
+
+// --- SSH Session History ---
+
+func TestHandleListSessionHistory(t *testing.T) {
+	m := newTestModule(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	_ = m.store.InsertSSHSession(ctx, &SSHSessionRecord{ID: "gw-1", DeviceID: "dev-1", UserID: "u1", Target: "10.0.0.1:22", StartedAt: now.Add(-time.Hour)})
+	_ = m.store.InsertSSHSession(ctx, &SSHSessionRecord{ID: "gw-2", DeviceID: "dev-2", UserID: "u1", Target: "10.0.0.2:22", StartedAt: now})
+	_ = m.store.EndSSHSession(ctx, "gw-1", now.Add(-30*time.Minute), 10, 20)
+
+	req := httptest.NewRequest(http.MethodGet, "/sessions/history?device_id=dev-1", http.NoBody)
+	rr := httptest.NewRecorder()
+	m.handleListSessionHistory(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rr.Code, http.StatusOK)
+	}
+	var records []SSHSessionRecord
+	if err := json.NewDecoder(rr.Body).Decode(&records); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(records) != 1 || records[0].ID != "gw-1" {
+		t.Fatalf("records = %+v, want only gw-1", records)
+	}
+	if records[0].EndedAt == nil || records[0].BytesOut != 20 {
+		t.Errorf("record = %+v, want ended with bytes_out 20", records[0])
+	}
+}
+
+func TestHandleGetSessionRecording(t *testing.T) {
+	m := newTestModule(t)
+	ctx := context.Background()
+	start := time.Now().UTC()
+
+	rec, err := newSessionRecorder(t.TempDir(), "gw-1", "u1@host", 80, 24, start)
+	if err != nil {
+		t.Fatalf("newSessionRecorder() error = %v", err)
+	}
+	rec.Output([]byte("$ "))
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	_ = m.store.InsertSSHSession(ctx, &SSHSessionRecord{ID: "gw-1", DeviceID: "dev-1", Target: "h:22", StartedAt: start, RecordingPath: rec.path})
+	_ = m.store.InsertSSHSession(ctx, &SSHSessionRecord{ID: "gw-2", DeviceID: "dev-1", Target: "h:22", StartedAt: start})
+
+	tests := []struct {
+		id         string
+		wantStatus int
+	}{
+		{"gw-1", http.StatusOK},
+		{"gw-2", http.StatusNotFound},
+		{"missing", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/sessions/"+tt.id+"/recording", http.NoBody)
+		req.SetPathValue("id", tt.id)
+		rr := httptest.NewRecorder()
+		m.handleGetSessionRecording(rr, req)
+
+		if rr.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.id, rr.Code, tt.wantStatus)
+			continue
+		}
+		if tt.wantStatus == http.StatusOK {
+			if ct := rr.Header().Get("Content-Type"); ct != "application/x-asciicast" {
+				t.Errorf("Content-Type = %q", ct)
+			}
+			if !strings.Contains(rr.Body.String(), `"o","$ "`) {
+				t.Errorf("body = %q, want output event", rr.Body.String())
+			}
+		}
+	}
+}
+
+func TestSessionHistory_RestrictedToOwner(t *testing.T) {
+	m := newTestModule(t)
+	ctx := context.Background()
+	start := time.Now().UTC()
+
+	rec, err := newSessionRecorder(t.TempDir(), "gw-2", "u2@host", 80, 24, start)
+	if err != nil {
+		t.Fatalf("newSessionRecorder() error = %v", err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	_ = m.store.InsertSSHSession(ctx, &SSHSessionRecord{ID: "gw-1", DeviceID: "dev-1", UserID: "u1", Target: "h:22", StartedAt: start})
+	_ = m.store.InsertSSHSession(ctx, &SSHSessionRecord{ID: "gw-2", DeviceID: "dev-1", UserID: "u2", Target: "h:22", StartedAt: start, RecordingPath: rec.path})
+
+	tokens := auth.NewTokenService([]byte("test-secret-test-secret-test-secret"), time.Minute, time.Hour)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/gateway/sessions/history", m.handleListSessionHistory)
+	mux.HandleFunc("GET /api/v1/gateway/sessions/{id}/recording", m.handleGetSessionRecording)
+	h := auth.AuthMiddleware(tokens)(mux)
+
+	do := func(userID string, role auth.Role, path string) *httptest.ResponseRecorder {
+		t.Helper()
+		token, err := tokens.IssueAccessToken(&auth.User{ID: userID, Username: userID, Role: role})
+		if err != nil {
+			t.Fatalf("IssueAccessToken: %v", err)
+		}
+		req := httptest.NewRequest(http.MethodGet, path, http.NoBody)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	historyTests := []struct {
+		name    string
+		userID  string
+		role    auth.Role
+		wantIDs int
+	}{
+		{"viewer sees own sessions", "u1", auth.RoleViewer, 1},
+		{"non-owner sees none", "u3", auth.RoleOperator, 0},
+		{"admin sees all", "admin", auth.RoleAdmin, 2},
+	}
+	for _, tt := range historyTests {
+		rr := do(tt.userID, tt.role, "/api/v1/gateway/sessions/history")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d", tt.name, rr.Code)
+		}
+		var records []SSHSessionRecord
+		if err := json.NewDecoder(rr.Body).Decode(&records); err != nil {
+			t.Fatalf("%s: decode: %v", tt.name, err)
+		}
+		if len(records) != tt.wantIDs {
+			t.Errorf("%s: got %d records, want %d", tt.name, len(records), tt.wantIDs)
+		}
+		for _, r := range records {
+			if tt.role != auth.RoleAdmin && r.UserID != tt.userID {
+				t.Errorf("%s: got session %s owned by %s", tt.name, r.ID, r.UserID)
+			}
+		}
+	}
+
+	recordingTests := []struct {
+		name       string
+		userID     string
+		role       auth.Role
+		wantStatus int
+	}{
+		{"viewer non-owner", "u1", auth.RoleViewer, http.StatusForbidden},
+		{"operator non-owner", "u3", auth.RoleOperator, http.StatusForbidden},
+		{"owner", "u2", auth.RoleViewer, http.StatusOK},
+		{"admin", "admin", auth.RoleAdmin, http.StatusOK},
+	}
+	for _, tt := range recordingTests {
+		rr := do(tt.userID, tt.role, "/api/v1/gateway/sessions/gw-2/recording")
+		if rr.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, rr.Code, tt.wantStatus)
+		}
+	}
+}
@@ -37,5 +37,33 @@ func migrations() []plugin.Migration {
 				return nil
 			},
 		},
+		{
+			Version:     2,
+			Description: "create gateway SSH session history table",
+			Up: func(tx *sql.Tx) error {
+				stmts := []string{
+					`CREATE TABLE IF NOT EXISTS gateway_ssh_sessions (
+						id TEXT PRIMARY KEY,
+						device_id TEXT NOT NULL,
+						user_id TEXT NOT NULL DEFAULT '',
+						target TEXT NOT NULL,
+						source_ip TEXT DEFAULT '',
+						started_at DATETIME NOT NULL,
+						ended_at DATETIME,
+						bytes_in INTEGER DEFAULT 0,
+						bytes_out INTEGER DEFAULT 0,
+						recording_path TEXT NOT NULL DEFAULT ''
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_gateway_ssh_sessions_started ON gateway_ssh_sessions(started_at)`,
+					`CREATE INDEX IF NOT EXISTS idx_gateway_ssh_sessions_device ON gateway_ssh_sessions(device_id)`,
+				}
+				for _, stmt := range stmts {
+					if _, err := tx.Exec(stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
 	}
 }
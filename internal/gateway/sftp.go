@@ -0,0 +1,261 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+)
+
+// sftpConn bundles the SSH and SFTP clients for a single file transfer.
+type sftpConn struct {
+	ssh      *ssh.Client
+	sftp     *sftp.Client
+	claims   *TokenClaims
+	deviceID string
+	target   string
+}
+
+func (c *sftpConn) Close() {
+	c.sftp.Close()
+	c.ssh.Close()
+}
+
+// HandleSFTPDownload streams a file from the device to the client.
+// GET /api/v1/gateway/sftp/{device_id}/download?path=/etc/config
+func (b *SSHBridge) HandleSFTPDownload(w http.ResponseWriter, r *http.Request) {
+	remotePath := r.URL.Query().Get("path")
+	if remotePath == "" {
+		gatewayWriteError(w, http.StatusBadRequest, "path is required")
+		return
+	}
+
+	conn, ok := b.openSFTP(w, r)
+	if !ok {
+		return
+	}
+	defer conn.Close()
+
+	info, err := conn.sftp.Stat(remotePath)
+	if err != nil {
+		gatewayWriteError(w, sftpErrorStatus(err), "stat remote file: "+err.Error())
+		return
+	}
+	if info.IsDir() {
+		gatewayWriteError(w, http.StatusBadRequest, "path is a directory")
+		return
+	}
+	maxSize := b.module.cfg.SFTPMaxFileSize
+	if maxSize > 0 && info.Size() > maxSize {
+		gatewayWriteError(w, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("file size %d exceeds limit of %d bytes", info.Size(), maxSize))
+		return
+	}
+
+	f, err := conn.sftp.Open(remotePath)
+	if err != nil {
+		gatewayWriteError(w, sftpErrorStatus(err), "open remote file: "+err.Error())
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", path.Base(remotePath)))
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size(), 10))
+	w.WriteHeader(http.StatusOK)
+
+	var src io.Reader = f
+	if maxSize > 0 {
+		src = io.LimitReader(f, maxSize)
+	}
+	n, err := io.Copy(w, src)
+	if err != nil {
+		b.logger.Debug("sftp download interrupted", zap.String("path", remotePath), zap.Error(err))
+	}
+	b.auditSFTP(r, conn, "download:"+remotePath, 0, n)
+}
+
+// HandleSFTPUpload streams the request body to a file on the device,
+// creating or truncating it.
+// POST /api/v1/gateway/sftp/{device_id}/upload?path=/tmp/config
+func (b *SSHBridge) HandleSFTPUpload(w http.ResponseWriter, r *http.Request) {
+	remotePath := r.URL.Query().Get("path")
+	if remotePath == "" {
+		gatewayWriteError(w, http.StatusBadRequest, "path is required")
+		return
+	}
+
+	maxSize := b.module.cfg.SFTPMaxFileSize
+	if maxSize > 0 {
+		if r.ContentLength > maxSize {
+			gatewayWriteError(w, http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("upload exceeds limit of %d bytes", maxSize))
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxSize)
+	}
+
+	conn, ok := b.openSFTP(w, r)
+	if !ok {
+		return
+	}
+	defer conn.Close()
+
+	f, err := conn.sftp.OpenFile(remotePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		gatewayWriteError(w, sftpErrorStatus(err), "open remote file: "+err.Error())
+		return
+	}
+
+	n, err := io.Copy(f, r.Body)
+	closeErr := f.Close()
+	if err != nil {
+		// Don't leave a truncated file behind.
+		_ = conn.sftp.Remove(remotePath)
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			gatewayWriteError(w, http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("upload exceeds limit of %d bytes", maxSize))
+			return
+		}
+		gatewayWriteError(w, http.StatusBadGateway, "write remote file: "+err.Error())
+		return
+	}
+	if closeErr != nil {
+		gatewayWriteError(w, http.StatusBadGateway, "write remote file: "+closeErr.Error())
+		return
+	}
+
+	b.auditSFTP(r, conn, "upload:"+remotePath, n, 0)
+	gatewayWriteJSON(w, http.StatusCreated, map[string]any{
+		"path":  remotePath,
+		"bytes": n,
+	})
+}
+
+// openSFTP authenticates the request with the same token and credential
+// resolution as the SSH WebSocket bridge and opens an SFTP session on the
+// target device. On failure it writes the error response and returns false.
+//
+// The access token is read from the Authorization header. Both downloads
+// and uploads open a shell-capable session on the device, so the caller
+// needs write access to the gateway. SSH credentials come from
+// ?credential_id= (vault) and/or ?username= with the password in the
+// X-SSH-Password header.
+func (b *SSHBridge) openSFTP(w http.ResponseWriter, r *http.Request) (*sftpConn, bool) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		gatewayWriteError(w, http.StatusUnauthorized, "missing token")
+		return nil, false
+	}
+	claims, err := b.tokens.ValidateAccessToken(token)
+	if err != nil {
+		gatewayWriteError(w, http.StatusUnauthorized, "invalid or expired token")
+		return nil, false
+	}
+	if !b.tokens.RoleAllows(r.Context(), claims.Role, "gateway", true) {
+		gatewayWriteError(w, http.StatusForbidden, "gateway write access required for SFTP")
+		return nil, false
+	}
+
+	deviceID := r.PathValue("device_id")
+	if deviceID == "" {
+		gatewayWriteError(w, http.StatusBadRequest, "device_id is required")
+		return nil, false
+	}
+	host, port := b.resolveTarget(r, deviceID)
+	if host == "" {
+		gatewayWriteError(w, http.StatusBadRequest, "unable to resolve device address; provide ?host= parameter")
+		return nil, false
+	}
+
+	creds := sshCredentials{
+		Username:     r.URL.Query().Get("username"),
+		Password:     r.Header.Get("X-SSH-Password"),
+		CredentialID: r.URL.Query().Get("credential_id"),
+	}
+	if err := b.resolveCredentials(r.Context(), claims, &creds); err != nil {
+		b.logger.Debug("failed to resolve vault credential",
+			zap.String("credential_id", creds.CredentialID),
+			zap.Error(err),
+		)
+		if errors.Is(err, errCredentialForbidden) {
+			gatewayWriteError(w, http.StatusForbidden, err.Error())
+			return nil, false
+		}
+		gatewayWriteError(w, http.StatusBadRequest, "credential lookup failed")
+		return nil, false
+	}
+	if creds.Username == "" {
+		gatewayWriteError(w, http.StatusBadRequest, "username is required")
+		return nil, false
+	}
+	authMethods, err := sshAuthMethods(&creds)
+	if err != nil {
+		gatewayWriteError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+
+	client, err := b.dialSSH(host, port, creds.Username, authMethods)
+	if err != nil {
+		gatewayWriteError(w, http.StatusBadGateway, "SSH connection failed: "+err.Error())
+		return nil, false
+	}
+	sc, err := sftp.NewClient(client)
+	if err != nil {
+		client.Close()
+		gatewayWriteError(w, http.StatusBadGateway, "SFTP session failed: "+err.Error())
+		return nil, false
+	}
+
+	return &sftpConn{
+		ssh:      client,
+		sftp:     sc,
+		claims:   claims,
+		deviceID: deviceID,
+		target:   fmt.Sprintf("%s:%d", host, port),
+	}, true
+}
+
+// auditSFTP records a completed file transfer in the gateway audit log.
+func (b *SSHBridge) auditSFTP(r *http.Request, conn *sftpConn, action string, bytesIn, bytesOut int64) {
+	if b.module.store == nil {
+		return
+	}
+	entry := &AuditEntry{
+		SessionID:   generateSessionID(),
+		DeviceID:    conn.deviceID,
+		UserID:      conn.claims.UserID,
+		SessionType: string(SessionTypeSFTP),
+		Target:      conn.target,
+		Action:      action,
+		BytesIn:     bytesIn,
+		BytesOut:    bytesOut,
+		SourceIP:    r.RemoteAddr,
+		Timestamp:   time.Now().UTC(),
+	}
+	if err := b.module.store.InsertAuditEntry(r.Context(), entry); err != nil {
+		b.logger.Warn("failed to write SFTP audit entry", zap.Error(err))
+	}
+}
+
+// sftpErrorStatus maps SFTP errors to HTTP status codes.
+func sftpErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return http.StatusNotFound
+	case errors.Is(err, os.ErrPermission):
+		return http.StatusForbidden
+	default:
+		return http.StatusBadGateway
+	}
+}
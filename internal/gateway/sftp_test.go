@@ -0,0 +1,180 @@
+package gateway
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// newTestSFTPServer starts an in-process SSH server exposing an in-memory
+// SFTP subsystem. The filesystem is shared across connections.
+func newTestSFTPServer(t *testing.T, username, password string) string {
+	t.Helper()
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() == username && string(pass) == password {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("invalid credentials")
+		},
+	}
+	config.AddHostKey(generateTestHostKey(t))
+	handlers := sftp.InMemHandler()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestSFTPConn(conn, config, handlers)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func serveTestSFTPConn(conn net.Conn, config *ssh.ServerConfig, handlers sftp.Handlers) {
+	defer conn.Close()
+
+	sshConn, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	defer sshConn.Close()
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				ok := req.Type == "subsystem" && string(req.Payload[4:]) == "sftp"
+				if req.WantReply {
+					req.Reply(ok, nil)
+				}
+				if ok {
+					server := sftp.NewRequestServer(channel, handlers)
+					_ = server.Serve()
+					server.Close()
+					return
+				}
+			}
+		}()
+	}
+}
+
+func newTestSFTPMux(t *testing.T) (*http.ServeMux, *Module) {
+	t.Helper()
+	return newTestSFTPMuxWithValidator(t, &mockTokenValidator{userID: "user-1"})
+}
+
+func newTestSFTPMuxWithValidator(t *testing.T, validator TokenValidator) (*http.ServeMux, *Module) {
+	t.Helper()
+	m := newTestModule(t)
+	handler := NewSSHWebSocketHandler(m, validator, m.logger)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+	return mux, m
+}
+
+func sftpRequest(method, addr, op, remotePath string, body io.Reader) *http.Request {
+	host, port, _ := net.SplitHostPort(addr)
+	url := fmt.Sprintf("/api/v1/gateway/sftp/dev-1/%s?host=%s&port=%s&username=admin&path=%s", op, host, port, remotePath)
+	req := httptest.NewRequest(method, url, body)
+	req.Header.Set("Authorization", "Bearer valid")
+	req.Header.Set("X-SSH-Password", "secret")
+	return req
+}
+
+func TestSFTP_UploadThenDownload(t *testing.T) {
+	addr := newTestSFTPServer(t, "admin", "secret")
+	mux, m := newTestSFTPMux(t)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, sftpRequest(http.MethodPost, addr, "upload", "/switch.cfg", strings.NewReader("hostname core-sw1\n")))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("upload status = %d, body = %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, sftpRequest(http.MethodGet, addr, "download", "/switch.cfg", http.NoBody))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("download status = %d, body = %s", rr.Code, rr.Body.String())
+	}
+	if got := rr.Body.String(); got != "hostname core-sw1\n" {
+		t.Errorf("download body = %q", got)
+	}
+	if cd := rr.Header().Get("Content-Disposition"); !strings.Contains(cd, "switch.cfg") {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+
+	entries, err := m.store.ListAuditEntries(t.Context(), "dev-1", 10)
+	if err != nil {
+		t.Fatalf("ListAuditEntries() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].SessionType != string(SessionTypeSFTP) {
+		t.Errorf("audit entries = %+v, want 2 sftp entries", entries)
+	}
+}
+
+func TestSFTP_ViewerForbidden(t *testing.T) {
+	addr := newTestSFTPServer(t, "admin", "secret")
+	mux, _ := newTestSFTPMuxWithValidator(t, &mockTokenValidator{userID: "user-1", role: "viewer"})
+
+	for _, req := range []*http.Request{
+		sftpRequest(http.MethodGet, addr, "download", "/switch.cfg", http.NoBody),
+		sftpRequest(http.MethodPost, addr, "upload", "/switch.cfg", strings.NewReader("x")),
+	} {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("%s status = %d, want %d", req.Method, rr.Code, http.StatusForbidden)
+		}
+	}
+}
+
+func TestSFTP_Errors(t *testing.T) {
+	addr := newTestSFTPServer(t, "admin", "secret")
+	mux, m := newTestSFTPMux(t)
+	m.cfg.SFTPMaxFileSize = 8
+
+	noToken := sftpRequest(http.MethodGet, addr, "download", "/x", http.NoBody)
+	noToken.Header.Del("Authorization")
+
+	tests := []struct {
+		name       string
+		req        *http.Request
+		wantStatus int
+	}{
+		{"missing token", noToken, http.StatusUnauthorized},
+		{"missing file", sftpRequest(http.MethodGet, addr, "download", "/nope", http.NoBody), http.StatusNotFound},
+		{"missing path", sftpRequest(http.MethodGet, addr, "download", "", http.NoBody), http.StatusBadRequest},
+		{"upload too large", sftpRequest(http.MethodPost, addr, "upload", "/big", strings.NewReader("0123456789")), http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, tt.req)
+			if rr.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package gateway
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -19,20 +21,36 @@ import (
 // following the consumer-side interface convention.
 type TokenValidator interface {
 	ValidateAccessToken(token string) (*TokenClaims, error)
+	// RoleAllows reports whether role grants read or write access to a
+	// module. The WebSocket route is outside the RBAC middleware, so the
+	// bridge checks permissions itself.
+	RoleAllows(ctx context.Context, role, module string, write bool) bool
 }
 
 // TokenClaims holds the subset of JWT claims needed by the SSH bridge.
 type TokenClaims struct {
 	UserID string
+	Role   string
 }
 
 // sshCredentials is the JSON payload sent as the first WebSocket message
-// to provide authentication credentials for the SSH connection.
+// to provide authentication credentials for the SSH connection. Either the
+// secrets are sent inline or CredentialID names a vault credential
+// (ssh_password or ssh_key) to authenticate with.
 type sshCredentials struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username     string `json:"username"`
+	Password     string `json:"password"`
+	PrivateKey   string `json:"private_key,omitempty"`
+	Passphrase   string `json:"passphrase,omitempty"`
+	CredentialID string `json:"credential_id,omitempty"`
 }
 
+// Terminal dimensions requested for bridged SSH sessions.
+const (
+	ptyWidth  = 80
+	ptyHeight = 24
+)
+
 // SSHBridge handles WebSocket-to-SSH bridging.
 type SSHBridge struct {
 	module *Module
@@ -67,22 +85,8 @@ func (b *SSHBridge) HandleSSH(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 3. Extract optional port (default 22) and host fallback.
-	port := 22
-	if portStr := r.URL.Query().Get("port"); portStr != "" {
-		if p, err := strconv.Atoi(portStr); err == nil && p > 0 && p <= 65535 {
-			port = p
-		}
-	}
-
-	// 4. Resolve device IP via module's deviceLookup, or accept ?host= as fallback.
-	host := r.URL.Query().Get("host")
-	if b.module.deviceLookup != nil {
-		device, err := b.module.deviceLookup.DeviceByID(r.Context(), deviceID)
-		if err == nil && device != nil && len(device.IPAddresses) > 0 {
-			host = device.IPAddresses[0]
-		}
-	}
+	// 3-4. Resolve device IP and port (default 22), accepting ?host= as fallback.
+	host, port := b.resolveTarget(r, deviceID)
 	if host == "" {
 		http.Error(w, "unable to resolve device address; provide ?host= parameter", http.StatusBadRequest)
 		return
@@ -105,7 +109,18 @@ func (b *SSHBridge) HandleSSH(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 
-	// 7. Read first message: JSON with credentials.
+	// 7. Enforce the global and per-user concurrent SSH session limits.
+	if reason, ok := b.module.sshLimits.acquire(claims.UserID); !ok {
+		b.logger.Warn("SSH session rejected",
+			zap.String("user_id", claims.UserID),
+			zap.String("reason", reason),
+		)
+		conn.Close(websocket.StatusTryAgainLater, reason)
+		return
+	}
+	defer b.module.sshLimits.release(claims.UserID)
+
+	// 8. Read first message: JSON with credentials.
 	_, msg, err := conn.Read(ctx)
 	if err != nil {
 		b.logger.Debug("failed to read credentials from websocket", zap.Error(err))
@@ -118,32 +133,31 @@ func (b *SSHBridge) HandleSSH(w http.ResponseWriter, r *http.Request) {
 		conn.Close(websocket.StatusPolicyViolation, "invalid credentials JSON")
 		return
 	}
+	if err := b.resolveCredentials(ctx, claims, &creds); err != nil {
+		b.logger.Debug("failed to resolve vault credential",
+			zap.String("credential_id", creds.CredentialID),
+			zap.Error(err),
+		)
+		if errors.Is(err, errCredentialForbidden) {
+			conn.Close(websocket.StatusPolicyViolation, err.Error())
+			return
+		}
+		conn.Close(websocket.StatusPolicyViolation, "credential lookup failed")
+		return
+	}
 	if creds.Username == "" {
 		conn.Close(websocket.StatusPolicyViolation, "username is required")
 		return
 	}
-
-	// 8. Dial SSH.
-	addr := net.JoinHostPort(host, strconv.Itoa(port))
-	sshConfig := &ssh.ClientConfig{
-		User: creds.Username,
-		Auth: []ssh.AuthMethod{
-			ssh.Password(creds.Password),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(), //nolint:gosec // G106: user-facing tool, host key verification is a future enhancement
-		Timeout:         10 * time.Second,
+	authMethods, err := sshAuthMethods(&creds)
+	if err != nil {
+		conn.Close(websocket.StatusPolicyViolation, err.Error())
+		return
 	}
 
-	dial := b.sshDial
-	if dial == nil {
-		dial = ssh.Dial
-	}
-	client, err := dial("tcp", addr, sshConfig)
+	// 9. Dial SSH.
+	client, err := b.dialSSH(host, port, creds.Username, authMethods)
 	if err != nil {
-		b.logger.Debug("ssh dial failed",
-			zap.String("addr", addr),
-			zap.Error(err),
-		)
 		conn.Close(websocket.StatusInternalError, "SSH connection failed: "+err.Error())
 		return
 	}
@@ -155,13 +169,13 @@ func (b *SSHBridge) HandleSSH(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 9. Request PTY and start shell.
+	// 10. Request PTY and start shell.
 	modes := ssh.TerminalModes{
 		ssh.ECHO:          1,
 		ssh.TTY_OP_ISPEED: 14400,
 		ssh.TTY_OP_OSPEED: 14400,
 	}
-	if err := session.RequestPty("xterm", 24, 80, modes); err != nil {
+	if err := session.RequestPty("xterm", ptyHeight, ptyWidth, modes); err != nil {
 		session.Close()
 		client.Close()
 		conn.Close(websocket.StatusInternalError, "PTY request failed")
@@ -191,7 +205,7 @@ func (b *SSHBridge) HandleSSH(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 10. Create a gateway session.
+	// 11. Create a gateway session.
 	gwSession := &Session{
 		ID:          generateSessionID(),
 		DeviceID:    deviceID,
@@ -210,7 +224,7 @@ func (b *SSHBridge) HandleSSH(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 11. Record audit entry and publish event.
+	// 12. Record audit entry and publish event.
 	if b.module.store != nil {
 		entry := &AuditEntry{
 			SessionID:   gwSession.ID,
@@ -234,7 +248,10 @@ func (b *SSHBridge) HandleSSH(w http.ResponseWriter, r *http.Request) {
 		"target":       fmt.Sprintf("%s:%d", host, port),
 	})
 
-	// 12. Bidirectional copy between WebSocket and SSH.
+	// 13. Persist session metadata and start recording if enabled.
+	rec := b.module.beginSSHHistory(ctx, gwSession, ptyWidth, ptyHeight)
+
+	// 14. Bidirectional copy between WebSocket and SSH.
 	done := make(chan struct{}, 2)
 
 	// WS -> SSH stdin
@@ -246,6 +263,9 @@ func (b *SSHBridge) HandleSSH(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 			gwSession.BytesIn.Add(int64(len(data)))
+			if rec != nil {
+				rec.Input(data)
+			}
 			if _, err := stdin.Write(data); err != nil {
 				return
 			}
@@ -260,6 +280,9 @@ func (b *SSHBridge) HandleSSH(w http.ResponseWriter, r *http.Request) {
 			n, err := stdout.Read(buf)
 			if n > 0 {
 				gwSession.BytesOut.Add(int64(n))
+				if rec != nil {
+					rec.Output(buf[:n])
+				}
 				if wErr := conn.Write(ctx, websocket.MessageBinary, buf[:n]); wErr != nil {
 					return
 				}
@@ -282,5 +305,52 @@ func (b *SSHBridge) HandleSSH(w http.ResponseWriter, r *http.Request) {
 
 	// Remove session and audit.
 	b.module.sessions.Delete(gwSession.ID)
+	b.module.endSSHHistory(gwSession, rec)
 	b.module.logSessionClosed(gwSession, "disconnected")
 }
+
+// resolveTarget returns the SSH host and port for a device. The port comes
+// from ?port= (default 22); the host is looked up via the module's
+// DeviceLookup, falling back to ?host=. host is empty if unresolved.
+func (b *SSHBridge) resolveTarget(r *http.Request, deviceID string) (host string, port int) {
+	port = 22
+	if portStr := r.URL.Query().Get("port"); portStr != "" {
+		if p, err := strconv.Atoi(portStr); err == nil && p > 0 && p <= 65535 {
+			port = p
+		}
+	}
+
+	host = r.URL.Query().Get("host")
+	if b.module.deviceLookup != nil {
+		device, err := b.module.deviceLookup.DeviceByID(r.Context(), deviceID)
+		if err == nil && device != nil && len(device.IPAddresses) > 0 {
+			host = device.IPAddresses[0]
+		}
+	}
+	return host, port
+}
+
+// dialSSH establishes an SSH client connection to host:port.
+func (b *SSHBridge) dialSSH(host string, port int, username string, authMethods []ssh.AuthMethod) (*ssh.Client, error) {
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	sshConfig := &ssh.ClientConfig{
+		User:            username,
+		Auth:            authMethods,
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(), //nolint:gosec // G106: user-facing tool, host key verification is a future enhancement
+		Timeout:         10 * time.Second,
+	}
+
+	dial := b.sshDial
+	if dial == nil {
+		dial = ssh.Dial
+	}
+	client, err := dial("tcp", addr, sshConfig)
+	if err != nil {
+		b.logger.Debug("ssh dial failed",
+			zap.String("addr", addr),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return client, nil
+}
@@ -28,7 +28,10 @@ func NewSSHWebSocketHandler(module *Module, tokens TokenValidator, logger *zap.L
 	}
 }
 
-// RegisterRoutes registers the SSH WebSocket route on the server mux.
+// RegisterRoutes registers the SSH WebSocket and SFTP routes on the server mux.
+// SFTP routes authenticate with the same TokenValidator as the WebSocket bridge.
 func (h *SSHWebSocketHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/v1/ws/gateway/ssh/{device_id}", h.bridge.HandleSSH)
+	mux.HandleFunc("GET /api/v1/gateway/sftp/{device_id}/download", h.bridge.HandleSFTPDownload)
+	mux.HandleFunc("POST /api/v1/gateway/sftp/{device_id}/upload", h.bridge.HandleSFTPUpload)
 }
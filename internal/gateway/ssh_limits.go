@@ -0,0 +1,95 @@
+package gateway
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SSHSessionStats reports the SSH bridge sessions currently open and the
+// configured caps. A cap of zero means unlimited.
+type SSHSessionStats struct {
+	Active             int            `json:"active"`
+	MaxSessions        int            `json:"max_sessions"`
+	MaxSessionsPerUser int            `json:"max_sessions_per_user"`
+	Users              map[string]int `json:"users"`
+}
+
+// sshSessionLimiter caps concurrent SSH bridge sessions globally and per
+// user. A slot is held from the WebSocket upgrade until the bridge exits,
+// so sessions still authenticating count towards the limits. A nil
+// limiter allows everything.
+type sshSessionLimiter struct {
+	mu         sync.Mutex
+	maxTotal   int
+	maxPerUser int
+	total      int
+	perUser    map[string]int
+}
+
+// newSSHSessionLimiter creates a limiter with the given caps. Zero or
+// negative caps disable that limit.
+func newSSHSessionLimiter(maxTotal, maxPerUser int) *sshSessionLimiter {
+	return &sshSessionLimiter{
+		maxTotal:   maxTotal,
+		maxPerUser: maxPerUser,
+		perUser:    make(map[string]int),
+	}
+}
+
+// acquire reserves a session slot for userID. When a limit is reached it
+// returns false and the reason to report to the client.
+func (l *sshSessionLimiter) acquire(userID string) (string, bool) {
+	if l == nil {
+		return "", true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.maxTotal > 0 && l.total >= l.maxTotal {
+		return fmt.Sprintf("gateway SSH session limit reached (%d)", l.maxTotal), false
+	}
+	if l.maxPerUser > 0 && l.perUser[userID] >= l.maxPerUser {
+		return fmt.Sprintf("too many SSH sessions for user (limit %d)", l.maxPerUser), false
+	}
+	l.total++
+	l.perUser[userID]++
+	return "", true
+}
+
+// release frees a slot reserved by acquire.
+func (l *sshSessionLimiter) release(userID string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.perUser[userID] <= 1 {
+		delete(l.perUser, userID)
+	} else {
+		l.perUser[userID]--
+	}
+	if l.total > 0 {
+		l.total--
+	}
+}
+
+// stats returns a snapshot of the active session counts.
+func (l *sshSessionLimiter) stats() SSHSessionStats {
+	if l == nil {
+		return SSHSessionStats{Users: map[string]int{}}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	users := make(map[string]int, len(l.perUser))
+	for id, n := range l.perUser {
+		users[id] = n
+	}
+	return SSHSessionStats{
+		Active:             l.total,
+		MaxSessions:        l.maxTotal,
+		MaxSessionsPerUser: l.maxPerUser,
+		Users:              users,
+	}
+}
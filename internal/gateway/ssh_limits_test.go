@@ -0,0 +1,117 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+func TestSSHSessionLimiter(t *testing.T) {
+	l := newSSHSessionLimiter(3, 2)
+
+	for i := 0; i < 2; i++ {
+		if _, ok := l.acquire("alice"); !ok {
+			t.Fatalf("acquire alice #%d rejected", i+1)
+		}
+	}
+	if reason, ok := l.acquire("alice"); ok || !strings.Contains(reason, "for user") {
+		t.Errorf("third alice session = %q, %v; want per-user rejection", reason, ok)
+	}
+	if _, ok := l.acquire("bob"); !ok {
+		t.Fatal("acquire bob rejected")
+	}
+	if reason, ok := l.acquire("carol"); ok || !strings.Contains(reason, "gateway SSH session limit") {
+		t.Errorf("fourth session = %q, %v; want global rejection", reason, ok)
+	}
+
+	stats := l.stats()
+	if stats.Active != 3 || stats.Users["alice"] != 2 || stats.Users["bob"] != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	l.release("alice")
+	l.release("bob")
+	if _, ok := l.acquire("carol"); !ok {
+		t.Error("acquire after release rejected")
+	}
+	stats = l.stats()
+	if _, ok := stats.Users["bob"]; ok || stats.Active != 2 {
+		t.Errorf("stats after release = %+v", stats)
+	}
+
+	unlimited := newSSHSessionLimiter(0, 0)
+	for i := 0; i < 10; i++ {
+		if _, ok := unlimited.acquire("alice"); !ok {
+			t.Fatal("unlimited limiter rejected a session")
+		}
+	}
+
+	var none *sshSessionLimiter
+	if _, ok := none.acquire("alice"); !ok {
+		t.Error("nil limiter rejected a session")
+	}
+	none.release("alice")
+}
+
+func TestSSHBridge_SessionLimitRejects(t *testing.T) {
+	bridge, m := newTestBridge(t, &mockTokenValidator{userID: "user-1"})
+	m.sshLimits = newSSHSessionLimiter(0, 1)
+	if _, ok := m.sshLimits.acquire("user-1"); !ok {
+		t.Fatal("setup acquire rejected")
+	}
+
+	srv := newTestSSHHTTPServer(t, bridge)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, resp, err := websocket.Dial(ctx, sshWSURL(srv.URL, "dev-1", map[string]string{
+		"token": "valid", "host": "10.0.0.1",
+	}), nil)
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+	if err != nil {
+		t.Fatalf("websocket dial: %v", err)
+	}
+	defer conn.CloseNow()
+
+	_, _, err = conn.Read(ctx)
+	var closeErr websocket.CloseError
+	if !errors.As(err, &closeErr) {
+		t.Fatalf("read err = %v, want close error", err)
+	}
+	if closeErr.Code != websocket.StatusTryAgainLater || !strings.Contains(closeErr.Reason, "too many SSH sessions") {
+		t.Errorf("close = %d %q", closeErr.Code, closeErr.Reason)
+	}
+	if got := m.sshLimits.stats().Users["user-1"]; got != 1 {
+		t.Errorf("user-1 sessions after rejection = %d, want 1", got)
+	}
+}
+
+func TestHandleSessionStats(t *testing.T) {
+	m := newTestModule(t)
+	m.sshLimits = newSSHSessionLimiter(10, 2)
+	m.sshLimits.acquire("user-1")
+
+	rec := httptest.NewRecorder()
+	m.handleSessionStats(rec, httptest.NewRequest(http.MethodGet, "/sessions/stats", http.NoBody))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var stats SSHSessionStats
+	if err := json.NewDecoder(rec.Body).Decode(&stats); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if stats.Active != 1 || stats.MaxSessions != 10 || stats.MaxSessionsPerUser != 2 || stats.Users["user-1"] != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
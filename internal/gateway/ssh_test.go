@@ -5,11 +5,14 @@ import (
 	"crypto/ed25519"
 	"crypto/rand"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -23,6 +26,7 @@ import (
 
 type mockTokenValidator struct {
 	userID string
+	role   string
 	err    error
 }
 
@@ -30,7 +34,16 @@ func (m *mockTokenValidator) ValidateAccessToken(_ string) (*TokenClaims, error)
 	if m.err != nil {
 		return nil, m.err
 	}
-	return &TokenClaims{UserID: m.userID}, nil
+	return &TokenClaims{UserID: m.userID, Role: m.role}, nil
+}
+
+// RoleAllows mirrors the built-in viewer role: read everything except the
+// vault. Any other role is allowed.
+func (m *mockTokenValidator) RoleAllows(_ context.Context, role, module string, write bool) bool {
+	if role != "viewer" {
+		return true
+	}
+	return !write && module != "vault"
 }
 
 // --- Test SSH Server ---
@@ -512,6 +525,74 @@ func TestSSHBridge_FullSession(t *testing.T) {
 	}
 }
 
+// TestSSHBridge_RecordsSession verifies that an SSH session is persisted to
+// the history table and, with recording enabled, captured as asciicast.
+func TestSSHBridge_RecordsSession(t *testing.T) {
+	sshAddr, cleanup := newTestSSHServer(t, "admin", "secret")
+	defer cleanup()
+
+	host, portStr, _ := net.SplitHostPort(sshAddr)
+
+	bridge, m := newTestBridge(t, &mockTokenValidator{userID: "user-42"})
+	m.cfg.RecordSessions = true
+	m.cfg.RecordingDir = t.TempDir()
+
+	srv := newTestSSHHTTPServer(t, bridge)
+	defer srv.Close()
+
+	wsURL := sshWSURL(srv.URL, "dev-1", map[string]string{
+		"token": "valid", "host": host, "port": portStr,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn, resp, err := websocket.Dial(ctx, wsURL, nil)
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+	if err != nil {
+		t.Fatalf("websocket dial: %v", err)
+	}
+
+	creds, _ := json.Marshal(sshCredentials{Username: "admin", Password: "secret"})
+	if err := conn.Write(ctx, websocket.MessageText, creds); err != nil {
+		t.Fatalf("write creds: %v", err)
+	}
+	if err := conn.Write(ctx, websocket.MessageBinary, []byte("uptime")); err != nil {
+		t.Fatalf("write test data: %v", err)
+	}
+	if _, _, err := conn.Read(ctx); err != nil {
+		t.Fatalf("read echo: %v", err)
+	}
+	conn.Close(websocket.StatusNormalClosure, "done")
+	time.Sleep(300 * time.Millisecond)
+
+	records, err := m.store.ListSSHSessions(context.Background(), "dev-1", "", 10)
+	if err != nil {
+		t.Fatalf("ListSSHSessions() error = %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("got %d session records, want 1", len(records))
+	}
+	rec := records[0]
+	if rec.UserID != "user-42" || rec.EndedAt == nil || !rec.HasRecording {
+		t.Errorf("record = %+v, want user-42, ended, with recording", rec)
+	}
+
+	data, err := os.ReadFile(rec.RecordingPath)
+	if err != nil {
+		t.Fatalf("read recording: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if !strings.Contains(lines[0], `"version":2`) {
+		t.Errorf("header = %s, want asciicast v2", lines[0])
+	}
+	if !strings.Contains(string(data), `"i","uptime"`) || !strings.Contains(string(data), `"o","uptime"`) {
+		t.Errorf("recording missing input/output events:\n%s", data)
+	}
+}
+
 // TestSSHBridge_SessionCreatedEvent verifies that session creation publishes the correct event.
 func TestSSHBridge_SessionCreatedEvent(t *testing.T) {
 	sshAddr, cleanup := newTestSSHServer(t, "admin", "secret")
@@ -703,3 +784,89 @@ func TestNewSSHWebSocketHandler(t *testing.T) {
 		t.Error("bridge.tokens should reference the provided validator")
 	}
 }
+
+// --- Vault credential resolution ---
+
+type mockCredentialDecrypter struct {
+	data map[string]any
+	err  error
+}
+
+func (m *mockCredentialDecrypter) DecryptCredential(_ context.Context, _ string) (map[string]any, error) {
+	return m.data, m.err
+}
+
+func TestResolveSSHCredentials_SSHKey(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte("hunter2"))
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	keyPEM := string(pem.EncodeToMemory(block))
+
+	m := &Module{}
+	m.SetCredentialDecrypter(&mockCredentialDecrypter{data: map[string]any{
+		"username":    "deploy",
+		"private_key": keyPEM,
+		"passphrase":  "hunter2",
+	}})
+
+	creds := sshCredentials{CredentialID: "vault-1"}
+	if err := m.resolveSSHCredentials(context.Background(), &creds); err != nil {
+		t.Fatalf("resolveSSHCredentials: %v", err)
+	}
+	if creds.Username != "deploy" || creds.PrivateKey != keyPEM {
+		t.Errorf("credentials not filled from vault: %+v", creds)
+	}
+
+	methods, err := sshAuthMethods(&creds)
+	if err != nil {
+		t.Fatalf("sshAuthMethods: %v", err)
+	}
+	if len(methods) != 1 {
+		t.Errorf("got %d auth methods, want 1 (public key)", len(methods))
+	}
+
+	creds.Passphrase = "wrong"
+	if _, err := sshAuthMethods(&creds); err == nil {
+		t.Error("expected error for wrong passphrase")
+	}
+}
+
+func TestResolveCredentials_ViewerRejected(t *testing.T) {
+	bridge, m := newTestBridge(t, &mockTokenValidator{userID: "user-1", role: "viewer"})
+	m.SetCredentialDecrypter(&mockCredentialDecrypter{data: map[string]any{
+		"username": "admin",
+		"password": "secret",
+	}})
+
+	creds := sshCredentials{CredentialID: "vault-1"}
+	err := bridge.resolveCredentials(context.Background(), &TokenClaims{UserID: "user-1", Role: "viewer"}, &creds)
+	if !errors.Is(err, errCredentialForbidden) {
+		t.Fatalf("resolveCredentials() error = %v, want errCredentialForbidden", err)
+	}
+	if creds.Password != "" {
+		t.Error("vault secret was decrypted for a viewer")
+	}
+
+	// Inline credentials need no vault access.
+	inline := sshCredentials{Username: "admin", Password: "secret"}
+	if err := bridge.resolveCredentials(context.Background(), &TokenClaims{UserID: "user-1", Role: "viewer"}, &inline); err != nil {
+		t.Errorf("resolveCredentials(inline) error = %v", err)
+	}
+}
+
+func TestResolveSSHCredentials_NoDecrypter(t *testing.T) {
+	m := &Module{}
+	creds := sshCredentials{CredentialID: "vault-1"}
+	if err := m.resolveSSHCredentials(context.Background(), &creds); err == nil {
+		t.Error("expected error without a credential decrypter")
+	}
+
+	if _, err := sshAuthMethods(&sshCredentials{Username: "admin"}); err == nil {
+		t.Error("expected error when neither password nor key is set")
+	}
+}
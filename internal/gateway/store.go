@@ -3,6 +3,7 @@ package gateway
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 )
@@ -81,3 +82,123 @@ func (s *GatewayStore) DeleteOldAuditEntries(ctx context.Context, before time.Ti
 func (s *GatewayStore) ListAuditEntriesByDevice(ctx context.Context, deviceID string, limit int) ([]AuditEntry, error) {
 	return s.ListAuditEntries(ctx, deviceID, limit)
 }
+
+// InsertSSHSession records the start of an SSH session.
+func (s *GatewayStore) InsertSSHSession(ctx context.Context, rec *SSHSessionRecord) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO gateway_ssh_sessions (id, device_id, user_id, target, source_ip, started_at, recording_path)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		rec.ID, rec.DeviceID, rec.UserID, rec.Target, rec.SourceIP, rec.StartedAt, rec.RecordingPath,
+	)
+	if err != nil {
+		return fmt.Errorf("insert ssh session: %w", err)
+	}
+	return nil
+}
+
+// EndSSHSession records the end time and traffic counters of an SSH session.
+func (s *GatewayStore) EndSSHSession(ctx context.Context, id string, endedAt time.Time, bytesIn, bytesOut int64) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE gateway_ssh_sessions SET ended_at = ?, bytes_in = ?, bytes_out = ?
+		WHERE id = ?`,
+		endedAt, bytesIn, bytesOut, id,
+	)
+	if err != nil {
+		return fmt.Errorf("end ssh session: %w", err)
+	}
+	return nil
+}
+
+const sshSessionColumns = `id, device_id, user_id, target, source_ip, started_at, ended_at, bytes_in, bytes_out, recording_path`
+
+// GetSSHSession returns a single SSH session record, or nil if not found.
+func (s *GatewayStore) GetSSHSession(ctx context.Context, id string) (*SSHSessionRecord, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+sshSessionColumns+` FROM gateway_ssh_sessions WHERE id = ?`, id)
+	rec, err := scanSSHSession(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get ssh session: %w", err)
+	}
+	return rec, nil
+}
+
+// ListSSHSessions returns SSH sessions newest first, optionally filtered by
+// device ID and by the user who opened them.
+func (s *GatewayStore) ListSSHSessions(ctx context.Context, deviceID, userID string, limit int) ([]SSHSessionRecord, error) {
+	query := `SELECT ` + sshSessionColumns + ` FROM gateway_ssh_sessions WHERE 1=1`
+	var args []any
+	if deviceID != "" {
+		query += ` AND device_id = ?`
+		args = append(args, deviceID)
+	}
+	if userID != "" {
+		query += ` AND user_id = ?`
+		args = append(args, userID)
+	}
+	query += ` ORDER BY started_at DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list ssh sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var records []SSHSessionRecord
+	for rows.Next() {
+		rec, err := scanSSHSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan ssh session row: %w", err)
+		}
+		records = append(records, *rec)
+	}
+	return records, rows.Err()
+}
+
+// DeleteOldSSHSessions deletes SSH session records that started before the
+// given time and returns the recording paths of the deleted rows so the
+// caller can remove the files.
+func (s *GatewayStore) DeleteOldSSHSessions(ctx context.Context, before time.Time) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT recording_path FROM gateway_ssh_sessions
+		WHERE started_at < ? AND recording_path != ''`, before)
+	if err != nil {
+		return nil, fmt.Errorf("list old ssh sessions: %w", err)
+	}
+	var paths []string
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan old ssh session: %w", err)
+		}
+		paths = append(paths, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM gateway_ssh_sessions WHERE started_at < ?`, before); err != nil {
+		return nil, fmt.Errorf("delete old ssh sessions: %w", err)
+	}
+	return paths, nil
+}
+
+// scanSSHSession scans a row selected with sshSessionColumns.
+func scanSSHSession(row interface{ Scan(...any) error }) (*SSHSessionRecord, error) {
+	var rec SSHSessionRecord
+	var endedAt sql.NullTime
+	if err := row.Scan(&rec.ID, &rec.DeviceID, &rec.UserID, &rec.Target, &rec.SourceIP,
+		&rec.StartedAt, &endedAt, &rec.BytesIn, &rec.BytesOut, &rec.RecordingPath); err != nil {
+		return nil, err
+	}
+	if endedAt.Valid {
+		t := endedAt.Time
+		rec.EndedAt = &t
+	}
+	rec.HasRecording = rec.RecordingPath != ""
+	return &rec, nil
+}
@@ -12,6 +12,7 @@ type SessionType string
 const (
 	SessionTypeProxy SessionType = "http_proxy"
 	SessionTypeSSH   SessionType = "ssh"
+	SessionTypeSFTP  SessionType = "sftp"
 )
 
 // Session represents an active remote access session.
@@ -61,6 +62,22 @@ type AuditEntry struct {
 	Timestamp   time.Time `json:"timestamp"`
 }
 
+// SSHSessionRecord is the persisted metadata for an SSH session opened
+// through the gateway. RecordingPath is empty when recording was disabled.
+type SSHSessionRecord struct {
+	ID            string     `json:"id"`
+	DeviceID      string     `json:"device_id"`
+	UserID        string     `json:"user_id"`
+	Target        string     `json:"target"`
+	SourceIP      string     `json:"source_ip"`
+	StartedAt     time.Time  `json:"started_at"`
+	EndedAt       *time.Time `json:"ended_at,omitempty"`
+	BytesIn       int64      `json:"bytes_in"`
+	BytesOut      int64      `json:"bytes_out"`
+	RecordingPath string     `json:"-"`
+	HasRecording  bool       `json:"has_recording"`
+}
+
 // sessionView is the JSON-serializable representation of a Session.
 // It includes byte counters that are otherwise hidden via json:"-" on atomics.
 type sessionView struct {
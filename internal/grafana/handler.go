@@ -0,0 +1,357 @@
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+	"go.uber.org/zap"
+)
+
+// Target prefixes. Pulse targets are "pulse:<metric>:<device id>"; scan
+// targets are "recon:<field>".
+const (
+	pulsePrefix = "pulse:"
+	reconPrefix = "recon:"
+)
+
+// maxAnnotations bounds alerts returned per annotation query.
+const maxAnnotations = 1000
+
+// pulseMetrics maps pulse metric names to display labels.
+var pulseMetrics = map[string]string{
+	"latency":      "Latency (ms)",
+	"packet_loss":  "Packet loss",
+	"success_rate": "Success rate (%)",
+}
+
+// scanFields maps recon scan metric fields to display labels and extractors.
+var scanFields = map[string]struct {
+	label string
+	value func(*models.ScanMetrics) float64
+}{
+	"duration_ms":     {"Scan duration (ms)", func(m *models.ScanMetrics) float64 { return float64(m.DurationMs) }},
+	"hosts_scanned":   {"Hosts scanned", func(m *models.ScanMetrics) float64 { return float64(m.HostsScanned) }},
+	"hosts_alive":     {"Hosts alive", func(m *models.ScanMetrics) float64 { return float64(m.HostsAlive) }},
+	"devices_created": {"Devices created", func(m *models.ScanMetrics) float64 { return float64(m.DevicesCreated) }},
+	"devices_updated": {"Devices updated", func(m *models.ScanMetrics) float64 { return float64(m.DevicesUpdated) }},
+}
+
+// pulseRanges are the pulse query ranges, smallest first.
+var pulseRanges = []struct {
+	name string
+	span time.Duration
+}{
+	{"1h", time.Hour},
+	{"6h", 6 * time.Hour},
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+// TimeRange is the dashboard time range sent with queries.
+type TimeRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// SearchRequest is the body of POST /search.
+type SearchRequest struct {
+	Target string `json:"target"`
+}
+
+// SearchResult is one selectable metric.
+type SearchResult struct {
+	Text  string `json:"text"`
+	Value string `json:"value"`
+}
+
+// QueryTarget is one requested series.
+type QueryTarget struct {
+	Target string `json:"target"`
+	RefID  string `json:"refId"`
+}
+
+// QueryRequest is the body of POST /query.
+type QueryRequest struct {
+	Range   TimeRange     `json:"range"`
+	Targets []QueryTarget `json:"targets"`
+}
+
+// TimeSeries is one series in a query response. Datapoints are
+// [value, unix milliseconds] pairs.
+type TimeSeries struct {
+	Target     string       `json:"target"`
+	RefID      string       `json:"refId,omitempty"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+// AnnotationQuery identifies the annotation being requested. Query, when
+// set, filters alerts by severity.
+type AnnotationQuery struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+// AnnotationRequest is the body of POST /annotations.
+type AnnotationRequest struct {
+	Range      TimeRange       `json:"range"`
+	Annotation AnnotationQuery `json:"annotation"`
+}
+
+// Annotation is one alert marker on a Grafana panel.
+type Annotation struct {
+	Annotation AnnotationQuery `json:"annotation"`
+	Time       int64           `json:"time"`
+	TimeEnd    int64           `json:"timeEnd,omitempty"`
+	Title      string          `json:"title"`
+	Text       string          `json:"text"`
+	Tags       []string        `json:"tags"`
+}
+
+// writeJSON writes a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+// writeError writes an RFC 7807 problem detail response.
+func writeError(w http.ResponseWriter, status int, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"type":   "https://subnetree.com/problems/" + http.StatusText(status),
+		"title":  http.StatusText(status),
+		"status": status,
+		"detail": detail,
+	})
+}
+
+// handleTest answers Grafana's datasource connection test.
+//
+//	@Summary		Grafana datasource test
+//	@Description	Returns 200 so Grafana's "Save & test" succeeds.
+//	@Tags			grafana
+//	@Security		BearerAuth
+//	@Success		200
+//	@Router			/grafana/ [get]
+func (m *Module) handleTest(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleSearch lists the metrics available to query.
+//
+//	@Summary		Grafana metric search
+//	@Description	Lists pulse device metrics and recon scan metrics, optionally filtered by a substring.
+//	@Tags			grafana
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		SearchRequest	false	"Filter"
+//	@Success		200		{array}		SearchResult
+//	@Failure		500		{object}	map[string]any
+//	@Router			/grafana/search [post]
+func (m *Module) handleSearch(w http.ResponseWriter, r *http.Request) {
+	var req SearchRequest
+	if r.ContentLength != 0 {
+		_ = json.NewDecoder(r.Body).Decode(&req) // An empty or invalid body lists everything.
+	}
+
+	results := []SearchResult{}
+	if m.metrics != nil {
+		devices, err := m.metrics.ListMonitoredDevices(r.Context())
+		if err != nil {
+			m.logger.Error("list monitored devices failed", zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "failed to list devices")
+			return
+		}
+		for _, d := range devices {
+			for _, metric := range sortedKeys(pulseMetrics) {
+				results = append(results, SearchResult{
+					Text:  pulseMetrics[metric] + " - " + d.Name,
+					Value: pulsePrefix + metric + ":" + d.ID,
+				})
+			}
+		}
+	}
+	if m.scans != nil {
+		for _, field := range sortedKeys(scanFields) {
+			results = append(results, SearchResult{Text: scanFields[field].label, Value: reconPrefix + field})
+		}
+	}
+
+	if filter := strings.ToLower(strings.TrimSpace(req.Target)); filter != "" {
+		filtered := results[:0]
+		for _, res := range results {
+			if strings.Contains(strings.ToLower(res.Text), filter) || strings.Contains(strings.ToLower(res.Value), filter) {
+				filtered = append(filtered, res)
+			}
+		}
+		results = filtered
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+// handleQuery returns time series for the requested targets.
+//
+//	@Summary		Grafana time-series query
+//	@Description	Returns pulse device metrics and recon scan metrics for the requested time range.
+//	@Tags			grafana
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		QueryRequest	true	"Query"
+//	@Success		200		{array}		TimeSeries
+//	@Failure		400		{object}	map[string]any
+//	@Failure		500		{object}	map[string]any
+//	@Router			/grafana/query [post]
+func (m *Module) handleQuery(w http.ResponseWriter, r *http.Request) {
+	var req QueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Range.To.IsZero() {
+		req.Range.To = time.Now()
+	}
+	if req.Range.From.IsZero() || !req.Range.From.Before(req.Range.To) {
+		writeError(w, http.StatusBadRequest, "range.from must be before range.to")
+		return
+	}
+
+	var scans []models.ScanMetrics
+	scansLoaded := false
+	series := make([]TimeSeries, 0, len(req.Targets))
+	for _, t := range req.Targets {
+		ts := TimeSeries{Target: t.Target, RefID: t.RefID, Datapoints: [][2]float64{}}
+		switch {
+		case strings.HasPrefix(t.Target, pulsePrefix):
+			metric, deviceID, ok := strings.Cut(strings.TrimPrefix(t.Target, pulsePrefix), ":")
+			if _, known := pulseMetrics[metric]; !ok || !known || m.metrics == nil {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown target %q", t.Target))
+				return
+			}
+			points, err := m.metrics.QueryDeviceMetric(r.Context(), deviceID, metric, pulseRangeFor(req.Range.From))
+			if err != nil {
+				m.logger.Error("query device metric failed", zap.String("target", t.Target), zap.Error(err))
+				writeError(w, http.StatusInternalServerError, "failed to query metrics")
+				return
+			}
+			for _, p := range points {
+				if !p.Timestamp.Before(req.Range.From) && !p.Timestamp.After(req.Range.To) {
+					ts.Datapoints = append(ts.Datapoints, [2]float64{p.Value, float64(p.Timestamp.UnixMilli())})
+				}
+			}
+		case strings.HasPrefix(t.Target, reconPrefix):
+			field, known := scanFields[strings.TrimPrefix(t.Target, reconPrefix)]
+			if !known || m.scans == nil {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown target %q", t.Target))
+				return
+			}
+			if !scansLoaded {
+				var err error
+				scans, err = m.scans.GetRawMetricsInRange(r.Context(), req.Range.From, req.Range.To)
+				if err != nil {
+					m.logger.Error("query scan metrics failed", zap.Error(err))
+					writeError(w, http.StatusInternalServerError, "failed to query scan metrics")
+					return
+				}
+				scansLoaded = true
+			}
+			for i := range scans {
+				at, err := time.Parse(time.RFC3339, scans[i].CreatedAt)
+				if err != nil {
+					continue
+				}
+				ts.Datapoints = append(ts.Datapoints, [2]float64{field.value(&scans[i]), float64(at.UnixMilli())})
+			}
+		default:
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown target %q", t.Target))
+			return
+		}
+		series = append(series, ts)
+	}
+	writeJSON(w, http.StatusOK, series)
+}
+
+// handleAnnotations returns alerts triggered within the range as annotations.
+//
+//	@Summary		Grafana alert annotations
+//	@Description	Returns monitoring alerts triggered in the time range. annotation.query optionally filters by severity.
+//	@Tags			grafana
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		AnnotationRequest	true	"Annotation query"
+//	@Success		200		{array}		Annotation
+//	@Failure		400		{object}	map[string]any
+//	@Failure		500		{object}	map[string]any
+//	@Failure		503		{object}	map[string]any
+//	@Router			/grafana/annotations [post]
+func (m *Module) handleAnnotations(w http.ResponseWriter, r *http.Request) {
+	if m.alerts == nil {
+		writeError(w, http.StatusServiceUnavailable, "alert reader not available")
+		return
+	}
+	var req AnnotationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Range.To.IsZero() {
+		req.Range.To = time.Now()
+	}
+
+	alerts, err := m.alerts.ListAlertsInRange(r.Context(), req.Range.From, req.Range.To, maxAnnotations)
+	if err != nil {
+		m.logger.Error("list alerts failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to list alerts")
+		return
+	}
+
+	severity := strings.ToLower(strings.TrimSpace(req.Annotation.Query))
+	annotations := []Annotation{}
+	for i := range alerts {
+		a := &alerts[i]
+		if severity != "" && a.Severity != severity {
+			continue
+		}
+		ann := Annotation{
+			Annotation: req.Annotation,
+			Time:       a.TriggeredAt.UnixMilli(),
+			Title:      a.DeviceName,
+			Text:       a.Message,
+			Tags:       []string{"subnetree", a.Severity},
+		}
+		if a.ResolvedAt != nil {
+			ann.TimeEnd = a.ResolvedAt.UnixMilli()
+		}
+		annotations = append(annotations, ann)
+	}
+	writeJSON(w, http.StatusOK, annotations)
+}
+
+// pulseRangeFor returns the smallest pulse range reaching back to from.
+func pulseRangeFor(from time.Time) string {
+	age := time.Since(from)
+	for _, pr := range pulseRanges {
+		if age <= pr.span {
+			return pr.name
+		}
+	}
+	return pulseRanges[len(pulseRanges)-1].name
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
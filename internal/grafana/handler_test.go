@@ -0,0 +1,156 @@
+package grafana
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+	"github.com/HerbHall/subnetree/pkg/plugin"
+	"github.com/HerbHall/subnetree/pkg/plugin/plugintest"
+	"go.uber.org/zap"
+)
+
+type fakeMetrics struct {
+	gotRange string
+	points   []DataPoint
+}
+
+func (f *fakeMetrics) ListMonitoredDevices(_ context.Context) ([]MonitoredDevice, error) {
+	return []MonitoredDevice{{ID: "dev-1", Name: "router"}}, nil
+}
+
+func (f *fakeMetrics) QueryDeviceMetric(_ context.Context, _, _, timeRange string) ([]DataPoint, error) {
+	f.gotRange = timeRange
+	return f.points, nil
+}
+
+type fakeScans struct {
+	metrics []models.ScanMetrics
+}
+
+func (f *fakeScans) GetRawMetricsInRange(_ context.Context, _, _ time.Time) ([]models.ScanMetrics, error) {
+	return f.metrics, nil
+}
+
+type fakeAlerts struct {
+	alerts []Alert
+}
+
+func (f *fakeAlerts) ListAlertsInRange(_ context.Context, _, _ time.Time, _ int) ([]Alert, error) {
+	return f.alerts, nil
+}
+
+func newTestModule(t *testing.T) (*Module, *fakeMetrics) {
+	t.Helper()
+	m := New()
+	if err := m.Init(context.Background(), plugin.Dependencies{Logger: zap.NewNop()}); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	metrics := &fakeMetrics{}
+	m.SetMetricsReader(metrics)
+	m.SetScanMetricsReader(&fakeScans{})
+	return m, metrics
+}
+
+func post(h http.HandlerFunc, body any) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	_ = json.NewEncoder(&buf).Encode(body)
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodPost, "/", &buf))
+	return w
+}
+
+func TestContract(t *testing.T) {
+	plugintest.TestPluginContract(t, func() plugin.Plugin { return New() })
+}
+
+func TestHandleSearch(t *testing.T) {
+	m, _ := newTestModule(t)
+
+	w := post(m.handleSearch, SearchRequest{})
+	var all []SearchResult
+	if err := json.NewDecoder(w.Body).Decode(&all); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(all) != len(pulseMetrics)+len(scanFields) {
+		t.Errorf("results = %d, want %d", len(all), len(pulseMetrics)+len(scanFields))
+	}
+
+	w = post(m.handleSearch, SearchRequest{Target: "latency"})
+	var filtered []SearchResult
+	_ = json.NewDecoder(w.Body).Decode(&filtered)
+	if len(filtered) != 1 || filtered[0].Value != "pulse:latency:dev-1" {
+		t.Errorf("filtered = %+v, want pulse:latency:dev-1", filtered)
+	}
+}
+
+func TestHandleQuery(t *testing.T) {
+	m, metrics := newTestModule(t)
+	now := time.Now().UTC().Truncate(time.Second)
+	metrics.points = []DataPoint{
+		{Timestamp: now.Add(-3 * time.Hour), Value: 1}, // outside the requested range
+		{Timestamp: now.Add(-30 * time.Minute), Value: 12.5},
+	}
+	m.SetScanMetricsReader(&fakeScans{metrics: []models.ScanMetrics{
+		{ScanID: "s1", HostsAlive: 7, CreatedAt: now.Add(-10 * time.Minute).Format(time.RFC3339)},
+	}})
+
+	w := post(m.handleQuery, QueryRequest{
+		Range:   TimeRange{From: now.Add(-2 * time.Hour), To: now},
+		Targets: []QueryTarget{{Target: "pulse:latency:dev-1", RefID: "A"}, {Target: "recon:hosts_alive", RefID: "B"}},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var series []TimeSeries
+	if err := json.NewDecoder(w.Body).Decode(&series); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if metrics.gotRange != "6h" {
+		t.Errorf("pulse range = %q, want 6h", metrics.gotRange)
+	}
+	if len(series) != 2 || len(series[0].Datapoints) != 1 || series[0].Datapoints[0][0] != 12.5 {
+		t.Fatalf("series = %+v", series)
+	}
+	if got := series[1].Datapoints; len(got) != 1 || got[0][0] != 7 {
+		t.Errorf("scan datapoints = %v, want one point of 7", got)
+	}
+
+	w = post(m.handleQuery, QueryRequest{
+		Range:   TimeRange{From: now.Add(-time.Hour), To: now},
+		Targets: []QueryTarget{{Target: "pulse:bogus:dev-1"}},
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("unknown target status = %d, want 400", w.Code)
+	}
+}
+
+func TestHandleAnnotations(t *testing.T) {
+	m, _ := newTestModule(t)
+	triggered := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	resolved := triggered.Add(5 * time.Minute)
+	m.SetAlertReader(&fakeAlerts{alerts: []Alert{
+		{ID: "a1", DeviceName: "router", Severity: "critical", Message: "down", TriggeredAt: triggered, ResolvedAt: &resolved},
+		{ID: "a2", DeviceName: "nas", Severity: "warning", Message: "slow", TriggeredAt: triggered},
+	}})
+
+	w := post(m.handleAnnotations, AnnotationRequest{
+		Range:      TimeRange{From: triggered.Add(-time.Hour), To: triggered.Add(time.Hour)},
+		Annotation: AnnotationQuery{Name: "alerts", Query: "critical"},
+	})
+	var anns []Annotation
+	if err := json.NewDecoder(w.Body).Decode(&anns); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(anns) != 1 {
+		t.Fatalf("annotations = %+v, want only the critical alert", anns)
+	}
+	if anns[0].Time != triggered.UnixMilli() || anns[0].TimeEnd != resolved.UnixMilli() || anns[0].Title != "router" {
+		t.Errorf("annotation = %+v", anns[0])
+	}
+}
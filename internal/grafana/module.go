@@ -0,0 +1,132 @@
+// Package grafana exposes SubNetree metrics and alerts through the
+// SimpleJSON / Infinity datasource protocol so an existing Grafana instance
+// can chart historical data without a separate exporter.
+package grafana
+
+import (
+	"context"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+	"github.com/HerbHall/subnetree/pkg/plugin"
+	"go.uber.org/zap"
+)
+
+// Compile-time interface guards.
+var (
+	_ plugin.Plugin       = (*Module)(nil)
+	_ plugin.HTTPProvider = (*Module)(nil)
+)
+
+// MonitoredDevice is a device with pulse monitoring data.
+type MonitoredDevice struct {
+	ID   string
+	Name string
+}
+
+// DataPoint is one time-series sample.
+type DataPoint struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// MetricsReader provides pulse monitoring time series.
+// Implemented via an adapter in the composition root (main.go).
+type MetricsReader interface {
+	ListMonitoredDevices(ctx context.Context) ([]MonitoredDevice, error)
+	// QueryDeviceMetric returns downsampled points for metric over the
+	// pulse time range ("1h", "6h", "24h", "7d", "30d").
+	QueryDeviceMetric(ctx context.Context, deviceID, metric, timeRange string) ([]DataPoint, error)
+}
+
+// ScanMetricsReader provides recon scan metrics.
+// Satisfied by *recon.ReconStore.
+type ScanMetricsReader interface {
+	GetRawMetricsInRange(ctx context.Context, start, end time.Time) ([]models.ScanMetrics, error)
+}
+
+// Alert is a monitoring alert surfaced as a Grafana annotation.
+type Alert struct {
+	ID          string
+	DeviceName  string
+	Severity    string
+	Message     string
+	TriggeredAt time.Time
+	ResolvedAt  *time.Time
+}
+
+// AlertReader provides alerts triggered within a time range.
+// Implemented via an adapter in the composition root (main.go).
+type AlertReader interface {
+	ListAlertsInRange(ctx context.Context, from, to time.Time, limit int) ([]Alert, error)
+}
+
+// Module implements the Grafana datasource plugin.
+type Module struct {
+	logger  *zap.Logger
+	metrics MetricsReader
+	scans   ScanMetricsReader
+	alerts  AlertReader
+}
+
+// New creates a new Grafana plugin instance.
+func New() *Module {
+	return &Module{}
+}
+
+// SetMetricsReader injects the pulse metrics reader. Called from the
+// composition root (main.go).
+func (m *Module) SetMetricsReader(r MetricsReader) {
+	m.metrics = r
+}
+
+// SetScanMetricsReader injects the recon scan metrics reader. Called from the
+// composition root (main.go).
+func (m *Module) SetScanMetricsReader(r ScanMetricsReader) {
+	m.scans = r
+}
+
+// SetAlertReader injects the alert reader. Called from the composition root
+// (main.go).
+func (m *Module) SetAlertReader(r AlertReader) {
+	m.alerts = r
+}
+
+// Info returns the plugin metadata.
+func (m *Module) Info() plugin.PluginInfo {
+	return plugin.PluginInfo{
+		Name:        "grafana",
+		Version:     "0.1.0",
+		Description: "Grafana SimpleJSON/Infinity datasource for metrics and alerts",
+		APIVersion:  plugin.APIVersionCurrent,
+	}
+}
+
+// Init initializes the module with its dependencies.
+func (m *Module) Init(_ context.Context, deps plugin.Dependencies) error {
+	m.logger = deps.Logger
+	m.logger.Info("grafana module initialized")
+	return nil
+}
+
+// Start begins the module's operations. The datasource is stateless.
+func (m *Module) Start(_ context.Context) error {
+	m.logger.Info("grafana module started")
+	return nil
+}
+
+// Stop gracefully shuts down the module.
+func (m *Module) Stop(_ context.Context) error {
+	m.logger.Info("grafana module stopped")
+	return nil
+}
+
+// Routes implements plugin.HTTPProvider.
+func (m *Module) Routes() []plugin.Route {
+	return []plugin.Route{
+		{Method: "GET", Path: "/{$}", Handler: m.handleTest},
+		{Method: "POST", Path: "/search", Handler: m.handleSearch},
+		{Method: "POST", Path: "/query", Handler: m.handleQuery},
+		{Method: "POST", Path: "/annotations", Handler: m.handleAnnotations},
+	}
+}
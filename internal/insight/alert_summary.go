@@ -0,0 +1,346 @@
+package insight
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/llm"
+	"go.uber.org/zap"
+)
+
+// maxSummaryAlerts caps the alerts sent to the LLM so a storm of alerts
+// cannot blow the prompt size; the template summary still counts them all.
+const maxSummaryAlerts = 50
+
+// alertSummarySystemPrompt instructs the LLM how to summarize alert groups.
+const alertSummarySystemPrompt = `You summarize active alerts for a network monitoring system called SubNetree.
+
+You receive JSON alert groups. Each group is keyed by its probable root-cause device: alerts on devices that depend on (or sit behind) a failing upstream device are grouped under that upstream device, which is marked "root_alerting" when it is alerting itself. Groups are already sorted by priority.
+
+Write ONE concise paragraph (under 120 words) for an operator who has just logged in:
+- Lead with the most urgent probable root cause and what it takes down.
+- Mention remaining groups briefly, most severe first.
+- Say when alerts are acknowledged or in maintenance so they can be deprioritized.
+- Refer to devices by name. Do not invent causes beyond the data. No lists, no headings.`
+
+// ActiveAlert is an unresolved alert with device context for summaries.
+type ActiveAlert struct {
+	ID             string    `json:"id"`
+	DeviceID       string    `json:"device_id"`
+	DeviceName     string    `json:"device_name"`
+	DeviceType     string    `json:"device_type,omitempty"`
+	Location       string    `json:"location,omitempty"`
+	Severity       string    `json:"severity"`
+	Message        string    `json:"message"`
+	TriggeredAt    time.Time `json:"triggered_at"`
+	Acknowledged   bool      `json:"acknowledged,omitempty"`
+	InMaintenance  bool      `json:"in_maintenance,omitempty"`
+	SuppressedBy   string    `json:"suppressed_by,omitempty"` // Upstream device whose failure suppresses this alert
+	SuppressedName string    `json:"suppressed_by_name,omitempty"`
+}
+
+// AlertSource provides active alerts joined with device context.
+// Defined here (consumer-side interface) to avoid coupling insight -> pulse.
+type AlertSource interface {
+	ListActiveAlerts(ctx context.Context) ([]ActiveAlert, error)
+}
+
+// SetAlertSource sets the source of active alerts for alert summaries.
+// Called from the composition root after all plugins are initialized.
+func (m *Module) SetAlertSource(src AlertSource) {
+	m.alerts = src
+}
+
+// AlertSummaryGroup is the set of active alerts attributed to one probable
+// root-cause device.
+type AlertSummaryGroup struct {
+	RootDeviceID   string        `json:"root_device_id"`
+	RootDeviceName string        `json:"root_device_name"`
+	RootAlerting   bool          `json:"root_alerting"` // The root device has an active alert of its own
+	Severity       string        `json:"severity"`      // Highest severity in the group
+	AlertCount     int           `json:"alert_count"`
+	DeviceCount    int           `json:"device_count"`
+	Alerts         []ActiveAlert `json:"alerts"`
+}
+
+// AlertSummary is the response for GET /insight/alert-summary.
+type AlertSummary struct {
+	Summary     string              `json:"summary"`
+	Source      string              `json:"source" example:"llm"` // "llm" or "template"
+	Model       string              `json:"model,omitempty"`
+	AlertCount  int                 `json:"alert_count"`
+	Groups      []AlertSummaryGroup `json:"groups"`
+	GeneratedAt time.Time           `json:"generated_at"`
+	Cached      bool                `json:"cached"`
+}
+
+// cachedAlertSummary is the last generated summary and the alert set it
+// was generated for.
+type cachedAlertSummary struct {
+	fingerprint string
+	expires     time.Time
+	summary     AlertSummary
+}
+
+// severityRank orders severities for prioritizing groups.
+func severityRank(s string) int {
+	switch s {
+	case "critical":
+		return 3
+	case "warning":
+		return 2
+	case "info":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// groupAlertsByRootCause attributes each alert to the upstream device that
+// suppresses it, or to its own device, and orders the groups by highest
+// severity, then size, then the oldest alert.
+func groupAlertsByRootCause(alerts []ActiveAlert) []AlertSummaryGroup {
+	index := make(map[string]int)
+	var groups []AlertSummaryGroup
+	alerting := make(map[string]bool)
+	names := make(map[string]string)
+	for i := range alerts {
+		alerting[alerts[i].DeviceID] = true
+		names[alerts[i].DeviceID] = alerts[i].DeviceName
+	}
+
+	for i := range alerts {
+		a := alerts[i]
+		root, rootName := a.DeviceID, a.DeviceName
+		if a.SuppressedBy != "" {
+			root, rootName = a.SuppressedBy, a.SuppressedName
+			if rootName == "" {
+				rootName = names[root]
+			}
+			if rootName == "" {
+				rootName = root
+			}
+		}
+		idx, ok := index[root]
+		if !ok {
+			idx = len(groups)
+			index[root] = idx
+			groups = append(groups, AlertSummaryGroup{
+				RootDeviceID:   root,
+				RootDeviceName: rootName,
+				RootAlerting:   alerting[root],
+			})
+		}
+		g := &groups[idx]
+		g.Alerts = append(g.Alerts, a)
+		g.AlertCount++
+		if severityRank(a.Severity) > severityRank(g.Severity) {
+			g.Severity = a.Severity
+		}
+	}
+
+	for i := range groups {
+		devices := make(map[string]struct{})
+		for j := range groups[i].Alerts {
+			devices[groups[i].Alerts[j].DeviceID] = struct{}{}
+		}
+		groups[i].DeviceCount = len(devices)
+		sort.SliceStable(groups[i].Alerts, func(a, b int) bool {
+			return groups[i].Alerts[a].TriggeredAt.Before(groups[i].Alerts[b].TriggeredAt)
+		})
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if ri, rj := severityRank(groups[i].Severity), severityRank(groups[j].Severity); ri != rj {
+			return ri > rj
+		}
+		if groups[i].AlertCount != groups[j].AlertCount {
+			return groups[i].AlertCount > groups[j].AlertCount
+		}
+		return groups[i].Alerts[0].TriggeredAt.Before(groups[j].Alerts[0].TriggeredAt)
+	})
+	return groups
+}
+
+// templateAlertSummary builds a plain summary without the LLM.
+func templateAlertSummary(alerts []ActiveAlert, groups []AlertSummaryGroup) string {
+	if len(alerts) == 0 {
+		return "No active alerts. Everything is healthy."
+	}
+
+	bySeverity := make(map[string]int)
+	devices := make(map[string]struct{})
+	for i := range alerts {
+		bySeverity[alerts[i].Severity]++
+		devices[alerts[i].DeviceID] = struct{}{}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d active %s on %d %s", len(alerts), plural(len(alerts), "alert", "alerts"),
+		len(devices), plural(len(devices), "device", "devices"))
+	var parts []string
+	for _, sev := range []string{"critical", "warning", "info"} {
+		if n := bySeverity[sev]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, sev))
+		}
+	}
+	if len(parts) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(parts, ", "))
+	}
+	b.WriteString(".")
+
+	top := groups[0]
+	if top.AlertCount > 1 && top.DeviceCount > 1 {
+		fmt.Fprintf(&b, " Most likely root cause: %s, which accounts for %d alerts across %d devices.",
+			top.RootDeviceName, top.AlertCount, top.DeviceCount)
+	} else {
+		fmt.Fprintf(&b, " Most urgent: %s on %s.", top.Alerts[0].Message, top.RootDeviceName)
+	}
+	if rest := len(groups) - 1; rest > 0 {
+		fmt.Fprintf(&b, " %d other %s.", rest, plural(rest, "issue is open", "issues are open"))
+	}
+	return b.String()
+}
+
+// plural picks the singular or plural form for n.
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// alertFingerprint identifies an alert set so a cached summary is reused
+// only while the same alerts are active in the same state.
+func alertFingerprint(alerts []ActiveAlert) string {
+	keys := make([]string, len(alerts))
+	for i := range alerts {
+		a := &alerts[i]
+		keys[i] = fmt.Sprintf("%s|%s|%t|%t|%s", a.ID, a.Severity, a.Acknowledged, a.InMaintenance, a.SuppressedBy)
+	}
+	sort.Strings(keys)
+	sum := sha256.Sum256([]byte(strings.Join(keys, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// llmAlertSummary asks the LLM for a prioritized paragraph about the groups.
+func llmAlertSummary(ctx context.Context, provider llm.Provider, groups []AlertSummaryGroup) (content, model string, err error) {
+	// Trim the groups to maxSummaryAlerts alerts, keeping priority order.
+	trimmed := make([]AlertSummaryGroup, 0, len(groups))
+	budget := maxSummaryAlerts
+	for i := range groups {
+		if budget <= 0 {
+			break
+		}
+		g := groups[i]
+		if len(g.Alerts) > budget {
+			g.Alerts = g.Alerts[:budget]
+		}
+		budget -= len(g.Alerts)
+		trimmed = append(trimmed, g)
+	}
+
+	data, err := json.Marshal(trimmed)
+	if err != nil {
+		return "", "", err
+	}
+	resp, err := provider.Chat(ctx, []llm.Message{
+		{Role: llm.RoleSystem, Content: alertSummarySystemPrompt},
+		{Role: llm.RoleUser, Content: string(data)},
+	}, llm.WithTemperature(0.3), llm.WithMaxTokens(400))
+	if err != nil {
+		return "", "", err
+	}
+	content = strings.TrimSpace(resp.Content)
+	if content == "" {
+		return "", "", errors.New("LLM returned an empty summary")
+	}
+	return content, resp.Model, nil
+}
+
+// SummarizeAlerts summarizes the active alerts, grouped by probable root
+// cause. The LLM writes the summary when available, otherwise a template
+// does. Summaries are cached for AlertSummaryCacheTTL while the alert set
+// is unchanged.
+func (m *Module) SummarizeAlerts(ctx context.Context) (*AlertSummary, error) {
+	if m.alerts == nil {
+		return nil, errors.New("alert source not available")
+	}
+	alerts, err := m.alerts.ListActiveAlerts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list active alerts: %w", err)
+	}
+
+	now := time.Now().UTC()
+	fingerprint := alertFingerprint(alerts)
+
+	m.summaryMu.Lock()
+	defer m.summaryMu.Unlock()
+	if c := m.summaryCache; c != nil && c.fingerprint == fingerprint && now.Before(c.expires) {
+		cached := c.summary
+		cached.Cached = true
+		return &cached, nil
+	}
+
+	groups := groupAlertsByRootCause(alerts)
+	if groups == nil {
+		groups = []AlertSummaryGroup{}
+	}
+	summary := AlertSummary{
+		Source:      "template",
+		AlertCount:  len(alerts),
+		Groups:      groups,
+		GeneratedAt: now,
+	}
+
+	// Skip the LLM when there is nothing to summarize.
+	if provider := resolveLLMProvider(m.plugins); provider != nil && len(alerts) > 0 {
+		content, model, llmErr := llmAlertSummary(ctx, provider, groups)
+		if llmErr == nil {
+			summary.Summary, summary.Source, summary.Model = content, "llm", model
+		} else {
+			m.logger.Warn("llm alert summary failed, using template", zap.Error(llmErr))
+		}
+	}
+	if summary.Summary == "" {
+		summary.Summary = templateAlertSummary(alerts, groups)
+	}
+
+	if ttl := m.cfg.AlertSummaryCacheTTL; ttl > 0 {
+		m.summaryCache = &cachedAlertSummary{fingerprint: fingerprint, expires: now.Add(ttl), summary: summary}
+	}
+	return &summary, nil
+}
+
+// handleAlertSummary returns a prioritized summary of the active alerts.
+//
+//	@Summary		Active alert summary
+//	@Description	Summarizes active alerts in one paragraph, grouped by probable root cause using dependency and topology suppression. Written by the LLM when available, otherwise templated. Cached briefly while the alert set is unchanged.
+//	@Tags			insight
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200 {object} AlertSummary
+//	@Failure		500 {object} map[string]any
+//	@Failure		503 {object} map[string]any
+//	@Router			/insight/alert-summary [get]
+func (m *Module) handleAlertSummary(w http.ResponseWriter, r *http.Request) {
+	if m.alerts == nil {
+		writeError(w, http.StatusServiceUnavailable, "alert summaries require the pulse module")
+		return
+	}
+	summary, err := m.SummarizeAlerts(r.Context())
+	if err != nil {
+		m.logger.Error("alert summary failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to summarize alerts")
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
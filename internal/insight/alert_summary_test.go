@@ -0,0 +1,159 @@
+package insight
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/llm"
+	"github.com/HerbHall/subnetree/pkg/plugin"
+	"github.com/HerbHall/subnetree/pkg/roles"
+	"go.uber.org/zap"
+)
+
+type mockAlertSource struct {
+	alerts []ActiveAlert
+}
+
+func (s *mockAlertSource) ListActiveAlerts(_ context.Context) ([]ActiveAlert, error) {
+	return s.alerts, nil
+}
+
+// outageAlerts returns a switch outage that suppresses two downstream
+// alerts, plus an unrelated camera warning.
+func outageAlerts() []ActiveAlert {
+	t0 := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	return []ActiveAlert{
+		{ID: "a-cam", DeviceID: "cam", DeviceName: "garage-cam", Severity: "warning", Message: "HTTP check failing", TriggeredAt: t0},
+		{ID: "a-nas", DeviceID: "nas", DeviceName: "nas", Severity: "critical", Message: "Ping failed", TriggeredAt: t0.Add(2 * time.Minute), SuppressedBy: "sw"},
+		{ID: "a-sw", DeviceID: "sw", DeviceName: "core-switch", Severity: "critical", Message: "Ping failed", TriggeredAt: t0.Add(time.Minute)},
+		{ID: "a-prn", DeviceID: "printer", DeviceName: "printer", Severity: "warning", Message: "Port 9100 closed", TriggeredAt: t0.Add(3 * time.Minute), SuppressedBy: "sw", SuppressedName: "core-switch"},
+	}
+}
+
+func newAlertSummaryModule(src AlertSource, provider llm.Provider) *Module {
+	m := &Module{logger: zap.NewNop(), cfg: DefaultConfig()}
+	if provider != nil {
+		m.plugins = &mockPluginResolver{byRole: map[string][]plugin.Plugin{
+			roles.RoleLLM: {&mockLLMPlugin{provider: provider}},
+		}}
+	}
+	m.SetAlertSource(src)
+	return m
+}
+
+func TestGroupAlertsByRootCause(t *testing.T) {
+	groups := groupAlertsByRootCause(outageAlerts())
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups, want 2", len(groups))
+	}
+
+	sw := groups[0]
+	if sw.RootDeviceID != "sw" || sw.RootDeviceName != "core-switch" || !sw.RootAlerting {
+		t.Errorf("first group = %+v, want alerting core-switch", sw)
+	}
+	if sw.Severity != "critical" || sw.AlertCount != 3 || sw.DeviceCount != 3 {
+		t.Errorf("switch group severity=%s alerts=%d devices=%d, want critical/3/3", sw.Severity, sw.AlertCount, sw.DeviceCount)
+	}
+	if sw.Alerts[0].ID != "a-sw" {
+		t.Errorf("oldest switch group alert = %s, want a-sw", sw.Alerts[0].ID)
+	}
+	if groups[1].RootDeviceID != "cam" || groups[1].Severity != "warning" {
+		t.Errorf("second group = %+v, want camera warning", groups[1])
+	}
+}
+
+func TestSummarizeAlerts_TemplateFallback(t *testing.T) {
+	m := newAlertSummaryModule(&mockAlertSource{alerts: outageAlerts()}, nil)
+
+	summary, err := m.SummarizeAlerts(context.Background())
+	if err != nil {
+		t.Fatalf("SummarizeAlerts: %v", err)
+	}
+	if summary.Source != "template" || summary.AlertCount != 4 {
+		t.Errorf("summary = %+v, want template over 4 alerts", summary)
+	}
+	for _, want := range []string{"4 active alerts on 4 devices", "2 critical, 2 warning", "core-switch", "1 other issue is open"} {
+		if !strings.Contains(summary.Summary, want) {
+			t.Errorf("summary %q missing %q", summary.Summary, want)
+		}
+	}
+
+	empty := newAlertSummaryModule(&mockAlertSource{}, nil)
+	summary, err = empty.SummarizeAlerts(context.Background())
+	if err != nil {
+		t.Fatalf("SummarizeAlerts(empty): %v", err)
+	}
+	if !strings.HasPrefix(summary.Summary, "No active alerts") || summary.Groups == nil {
+		t.Errorf("empty summary = %+v", summary)
+	}
+}
+
+func TestSummarizeAlerts_LLMAndCache(t *testing.T) {
+	calls := 0
+	provider := &mockLLMProvider{
+		chatFunc: func(_ context.Context, messages []llm.Message, _ ...llm.CallOption) (*llm.Response, error) {
+			calls++
+			if calls == 1 && !strings.Contains(messages[1].Content, `"root_device_name":"core-switch"`) {
+				t.Errorf("prompt missing root cause group: %s", messages[1].Content)
+			}
+			return &llm.Response{Content: " core-switch is down, taking nas and printer with it. ", Model: "mock-model"}, nil
+		},
+	}
+	src := &mockAlertSource{alerts: outageAlerts()}
+	m := newAlertSummaryModule(src, provider)
+
+	summary, err := m.SummarizeAlerts(context.Background())
+	if err != nil {
+		t.Fatalf("SummarizeAlerts: %v", err)
+	}
+	if summary.Source != "llm" || summary.Model != "mock-model" || summary.Cached {
+		t.Errorf("summary = %+v, want fresh llm summary", summary)
+	}
+	if summary.Summary != "core-switch is down, taking nas and printer with it." {
+		t.Errorf("Summary = %q", summary.Summary)
+	}
+
+	summary, _ = m.SummarizeAlerts(context.Background())
+	if !summary.Cached || calls != 1 {
+		t.Errorf("second call cached=%v llm calls=%d, want cached with 1 call", summary.Cached, calls)
+	}
+
+	// A changed alert set bypasses the cache.
+	src.alerts = src.alerts[:1]
+	summary, _ = m.SummarizeAlerts(context.Background())
+	if summary.Cached || calls != 2 {
+		t.Errorf("after alert change cached=%v llm calls=%d, want fresh with 2 calls", summary.Cached, calls)
+	}
+}
+
+func TestSummarizeAlerts_LLMError(t *testing.T) {
+	provider := &mockLLMProvider{
+		chatFunc: func(_ context.Context, _ []llm.Message, _ ...llm.CallOption) (*llm.Response, error) {
+			return nil, errors.New("model offline")
+		},
+	}
+	m := newAlertSummaryModule(&mockAlertSource{alerts: outageAlerts()}, provider)
+
+	summary, err := m.SummarizeAlerts(context.Background())
+	if err != nil {
+		t.Fatalf("SummarizeAlerts: %v", err)
+	}
+	if summary.Source != "template" || summary.Summary == "" {
+		t.Errorf("summary = %+v, want template fallback", summary)
+	}
+}
+
+func TestHandleAlertSummary_NoSource(t *testing.T) {
+	m := &Module{logger: zap.NewNop()}
+
+	w := httptest.NewRecorder()
+	m.handleAlertSummary(w, httptest.NewRequest(http.MethodGet, "/alert-summary", http.NoBody))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
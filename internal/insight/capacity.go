@@ -0,0 +1,386 @@
+package insight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HerbHall/subnetree/internal/insight/forecast"
+	"go.uber.org/zap"
+)
+
+// Capacity projection limits.
+const (
+	defaultCapacityHistoryDays = 180
+	maxCapacityHistoryDays     = 730
+	defaultCapacityThreshold   = 80.0
+	// maxProjectionDays bounds reported crossing dates; slower trends are
+	// reported as not crossing.
+	maxProjectionDays = 3650
+	// capacityBandSigmas sizes the ~95% prediction band.
+	capacityBandSigmas = 1.96
+)
+
+// Capacity projection series sources.
+const (
+	CapacitySourceDevices    = "devices"     // Cumulative device count from first_seen
+	CapacitySourceHostsAlive = "hosts_alive" // Weekly average hosts alive from scan aggregates
+)
+
+// ErrInvalidCapacityQuery is returned for bad capacity projection parameters.
+var ErrInvalidCapacityQuery = errors.New("invalid capacity query")
+
+// DeviceSighting is the first_seen time and addresses of one device.
+type DeviceSighting struct {
+	FirstSeen   time.Time
+	IPAddresses []string
+}
+
+// WeeklyHostsAlive is the average hosts alive for one weekly aggregate period.
+type WeeklyHostsAlive struct {
+	PeriodStart   time.Time
+	AvgHostsAlive float64
+}
+
+// CapacitySource provides historical inventory data for capacity projections.
+// Defined here (consumer-side interface) to avoid coupling insight -> recon.
+type CapacitySource interface {
+	// ListDeviceSightings returns every known device's first_seen time and IPs.
+	ListDeviceSightings(ctx context.Context) ([]DeviceSighting, error)
+	// WeeklyHostsAlive returns weekly hosts-alive averages in the range, oldest first.
+	WeeklyHostsAlive(ctx context.Context, start, end time.Time) ([]WeeklyHostsAlive, error)
+}
+
+// SetCapacitySource sets the history source for capacity projections.
+// Called from the composition root after all plugins are initialized.
+func (m *Module) SetCapacitySource(src CapacitySource) {
+	m.capacity = src
+}
+
+// CapacityQuery describes a capacity projection request.
+type CapacityQuery struct {
+	Source       string  // CapacitySourceDevices or CapacitySourceHostsAlive
+	Subnet       string  // Optional CIDR; filters devices and sets Capacity to its usable hosts
+	Capacity     float64 // Explicit capacity; overrides the subnet size
+	ThresholdPct float64 // Percent of capacity to project the crossing for
+	HistoryDays  int     // Days of history to fit
+	Model        string  // "linear", "exponential", or "" to pick the better fit
+}
+
+// CapacityFit is one fitted trend and its projected threshold crossing.
+type CapacityFit struct {
+	Model        string   `json:"model" example:"linear"`
+	Intercept    float64  `json:"intercept"`                   // Value at the first point (ln of it for exponential)
+	Slope        float64  `json:"slope"`                       // Growth per day (continuous ln growth rate for exponential)
+	DoublingDays *float64 `json:"doubling_days,omitempty"`     // Exponential only: days for the value to double
+	RSquared     float64  `json:"r_squared"`                   // Goodness of fit (0-1)
+	StdErr       float64  `json:"std_error"`                   // Residual standard error in model space
+	Projected    float64  `json:"projected"`                   // Modelled value at the last point
+	Crossing     *string  `json:"crossing_date,omitempty"`     // Date the trend reaches the threshold
+	Earliest     *string  `json:"crossing_earliest,omitempty"` // Upper edge of the 95% band
+	Latest       *string  `json:"crossing_latest,omitempty"`   // Lower edge of the 95% band
+}
+
+// CapacityProjection is the response for GET /insight/capacity.
+type CapacityProjection struct {
+	Source          string        `json:"source" example:"devices"`
+	Subnet          string        `json:"subnet,omitempty" example:"192.168.1.0/24"`
+	Capacity        float64       `json:"capacity" example:"254"`
+	ThresholdPct    float64       `json:"threshold_pct" example:"80"`
+	ThresholdValue  float64       `json:"threshold_value" example:"203.2"`
+	Current         float64       `json:"current" example:"150"`
+	Utilization     float64       `json:"utilization_pct" example:"59.1"`
+	AlreadyExceeded bool          `json:"already_exceeded"`
+	HistoryDays     int           `json:"history_days" example:"180"`
+	Points          int           `json:"points" example:"180"`
+	Confidence      float64       `json:"confidence" example:"0.95"`
+	Model           string        `json:"model,omitempty" example:"linear"` // Selected fit
+	Fits            []CapacityFit `json:"fits"`
+}
+
+// capacityPoint is one observation of the projected series.
+type capacityPoint struct {
+	At    time.Time
+	Value float64
+}
+
+// usableHosts returns the number of assignable addresses in an IPv4 subnet.
+func usableHosts(ipNet *net.IPNet) (float64, error) {
+	ones, bits := ipNet.Mask.Size()
+	if bits != 32 {
+		return 0, fmt.Errorf("%w: only IPv4 subnets are supported", ErrInvalidCapacityQuery)
+	}
+	switch hostBits := bits - ones; {
+	case hostBits == 0:
+		return 1, nil
+	case hostBits == 1:
+		return 2, nil // RFC 3021 point-to-point
+	default:
+		return math.Exp2(float64(hostBits)) - 2, nil
+	}
+}
+
+// deviceGrowthSeries counts devices first seen by the end of each day in
+// the window. With a subnet, only devices with an address in it count.
+func deviceGrowthSeries(sightings []DeviceSighting, ipNet *net.IPNet, now time.Time, days int) []capacityPoint {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	start := today.AddDate(0, 0, -(days - 1))
+
+	perDay := make([]int, days)
+	baseline := 0
+	for i := range sightings {
+		if ipNet != nil && !sightingInSubnet(&sightings[i], ipNet) {
+			continue
+		}
+		seen := sightings[i].FirstSeen.UTC()
+		if seen.Before(start) {
+			baseline++
+			continue
+		}
+		idx := int(seen.Sub(start) / (24 * time.Hour))
+		if idx >= days {
+			continue // first seen after now, e.g. clock skew
+		}
+		perDay[idx]++
+	}
+
+	points := make([]capacityPoint, days)
+	total := baseline
+	for i := range points {
+		total += perDay[i]
+		points[i] = capacityPoint{At: start.AddDate(0, 0, i), Value: float64(total)}
+	}
+	return points
+}
+
+// sightingInSubnet reports whether any of the device's addresses is in ipNet.
+func sightingInSubnet(s *DeviceSighting, ipNet *net.IPNet) bool {
+	for _, addr := range s.IPAddresses {
+		if ip := net.ParseIP(addr); ip != nil && ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// projectCapacity fits linear and exponential trends to the series and
+// projects when each reaches the threshold. Days are counted from the
+// first point; crossings before the last point are reported as its date.
+func projectCapacity(points []capacityPoint, q CapacityQuery) *CapacityProjection {
+	threshold := q.Capacity * q.ThresholdPct / 100
+	p := &CapacityProjection{
+		Source:         q.Source,
+		Subnet:         q.Subnet,
+		Capacity:       q.Capacity,
+		ThresholdPct:   q.ThresholdPct,
+		ThresholdValue: threshold,
+		HistoryDays:    q.HistoryDays,
+		Points:         len(points),
+		Confidence:     0.95,
+		Fits:           []CapacityFit{},
+	}
+	if len(points) == 0 {
+		return p
+	}
+
+	first, last := points[0].At, points[len(points)-1].At
+	x := make([]float64, len(points))
+	y := make([]float64, len(points))
+	for i := range points {
+		x[i] = points[i].At.Sub(first).Hours() / 24
+		y[i] = points[i].Value
+	}
+	lastX := x[len(x)-1]
+
+	p.Current = y[len(y)-1]
+	if q.Capacity > 0 {
+		p.Utilization = math.Round(p.Current/q.Capacity*1000) / 10
+	}
+	p.AlreadyExceeded = p.Current >= threshold
+
+	crossingDate := func(t *forecast.Trend, sigmas float64) *string {
+		if p.AlreadyExceeded {
+			return nil
+		}
+		cx, ok := t.Crossing(threshold, sigmas)
+		if !ok || cx-lastX > maxProjectionDays {
+			return nil
+		}
+		at := last
+		if cx > lastX {
+			at = first.Add(time.Duration(cx * 24 * float64(time.Hour)))
+		}
+		s := at.UTC().Format(time.DateOnly)
+		return &s
+	}
+
+	bestR2 := -1.0
+	for _, t := range []*forecast.Trend{forecast.FitLinear(x, y), forecast.FitExponential(x, y)} {
+		if t == nil || (q.Model != "" && q.Model != t.Model) {
+			continue
+		}
+		fit := CapacityFit{
+			Model:     t.Model,
+			Intercept: t.Intercept,
+			Slope:     t.Slope,
+			RSquared:  t.RSquared,
+			StdErr:    t.StdErr,
+			Projected: t.Predict(lastX),
+			Crossing:  crossingDate(t, 0),
+			Earliest:  crossingDate(t, capacityBandSigmas),
+			Latest:    crossingDate(t, -capacityBandSigmas),
+		}
+		if t.Model == forecast.TrendExponential && t.Slope > 0 {
+			d := math.Ln2 / t.Slope
+			fit.DoublingDays = &d
+		}
+		p.Fits = append(p.Fits, fit)
+		if t.RSquared > bestR2 {
+			bestR2 = t.RSquared
+			p.Model = t.Model
+		}
+	}
+	return p
+}
+
+// ProjectCapacity loads the requested history and projects when it
+// reaches the threshold. Parameter problems wrap ErrInvalidCapacityQuery.
+func (m *Module) ProjectCapacity(ctx context.Context, q CapacityQuery, now time.Time) (*CapacityProjection, error) {
+	if m.capacity == nil {
+		return nil, errors.New("capacity history source not available")
+	}
+
+	if q.Source == "" {
+		q.Source = CapacitySourceDevices
+	}
+	if q.Source != CapacitySourceDevices && q.Source != CapacitySourceHostsAlive {
+		return nil, fmt.Errorf("%w: source must be %q or %q", ErrInvalidCapacityQuery, CapacitySourceDevices, CapacitySourceHostsAlive)
+	}
+	if q.Model != "" && q.Model != forecast.TrendLinear && q.Model != forecast.TrendExponential {
+		return nil, fmt.Errorf("%w: model must be %q or %q", ErrInvalidCapacityQuery, forecast.TrendLinear, forecast.TrendExponential)
+	}
+	if q.HistoryDays == 0 {
+		q.HistoryDays = defaultCapacityHistoryDays
+	}
+	if q.HistoryDays < 2 || q.HistoryDays > maxCapacityHistoryDays {
+		return nil, fmt.Errorf("%w: days must be between 2 and %d", ErrInvalidCapacityQuery, maxCapacityHistoryDays)
+	}
+	if q.ThresholdPct == 0 {
+		q.ThresholdPct = defaultCapacityThreshold
+	}
+	if q.ThresholdPct <= 0 || q.ThresholdPct > 100 {
+		return nil, fmt.Errorf("%w: threshold must be a percentage between 0 and 100", ErrInvalidCapacityQuery)
+	}
+
+	var ipNet *net.IPNet
+	if q.Subnet != "" {
+		_, n, err := net.ParseCIDR(q.Subnet)
+		if err != nil {
+			return nil, fmt.Errorf("%w: subnet must be a CIDR such as 192.168.1.0/24", ErrInvalidCapacityQuery)
+		}
+		ipNet = n
+		q.Subnet = n.String()
+		if q.Capacity == 0 {
+			hosts, err := usableHosts(n)
+			if err != nil {
+				return nil, err
+			}
+			q.Capacity = hosts
+		}
+	}
+	if q.Capacity <= 0 {
+		return nil, fmt.Errorf("%w: subnet or capacity is required", ErrInvalidCapacityQuery)
+	}
+
+	var points []capacityPoint
+	switch q.Source {
+	case CapacitySourceDevices:
+		sightings, err := m.capacity.ListDeviceSightings(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list device sightings: %w", err)
+		}
+		points = deviceGrowthSeries(sightings, ipNet, now.UTC(), q.HistoryDays)
+	case CapacitySourceHostsAlive:
+		weeks, err := m.capacity.WeeklyHostsAlive(ctx, now.AddDate(0, 0, -q.HistoryDays), now)
+		if err != nil {
+			return nil, fmt.Errorf("list weekly hosts alive: %w", err)
+		}
+		for i := range weeks {
+			points = append(points, capacityPoint{At: weeks[i].PeriodStart, Value: weeks[i].AvgHostsAlive})
+		}
+	}
+
+	return projectCapacity(points, q), nil
+}
+
+// handleCapacityProjection projects when inventory growth reaches a
+// capacity threshold.
+//
+//	@Summary		Capacity projection
+//	@Description	Fits linear and exponential trends to device growth (from first_seen) or weekly hosts-alive scan aggregates and projects the date each reaches a percentage of capacity, with a 95% prediction band. Capacity is the usable host count of subnet unless capacity is given.
+//	@Tags			insight
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			source query string false "Series to project (devices or hosts_alive)" default(devices)
+//	@Param			subnet query string false "IPv4 CIDR; filters devices and sets capacity to its usable hosts"
+//	@Param			capacity query number false "Explicit capacity (overrides the subnet size)"
+//	@Param			threshold query number false "Percent of capacity to project" default(80)
+//	@Param			days query int false "Days of history to fit (max 730)" default(180)
+//	@Param			model query string false "Only fit linear or exponential"
+//	@Success		200 {object} CapacityProjection
+//	@Failure		400 {object} map[string]any
+//	@Failure		500 {object} map[string]any
+//	@Failure		503 {object} map[string]any
+//	@Router			/insight/capacity [get]
+func (m *Module) handleCapacityProjection(w http.ResponseWriter, r *http.Request) {
+	if m.capacity == nil {
+		writeError(w, http.StatusServiceUnavailable, "capacity projections require the recon module")
+		return
+	}
+
+	query := r.URL.Query()
+	q := CapacityQuery{
+		Source: query.Get("source"),
+		Subnet: query.Get("subnet"),
+		Model:  query.Get("model"),
+	}
+	for _, param := range []struct {
+		name string
+		dst  *float64
+	}{{"capacity", &q.Capacity}, {"threshold", &q.ThresholdPct}} {
+		if s := query.Get(param.name); s != "" {
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil || v <= 0 {
+				writeError(w, http.StatusBadRequest, param.name+" must be a positive number")
+				return
+			}
+			*param.dst = v
+		}
+	}
+	if s := query.Get("days"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "days must be an integer")
+			return
+		}
+		q.HistoryDays = v
+	}
+
+	projection, err := m.ProjectCapacity(r.Context(), q, time.Now())
+	if errors.Is(err, ErrInvalidCapacityQuery) {
+		writeError(w, http.StatusBadRequest, strings.TrimPrefix(err.Error(), ErrInvalidCapacityQuery.Error()+": "))
+		return
+	}
+	if err != nil {
+		m.logger.Error("capacity projection failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to project capacity")
+		return
+	}
+	writeJSON(w, http.StatusOK, projection)
+}
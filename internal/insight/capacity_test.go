@@ -0,0 +1,161 @@
+package insight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type mockCapacitySource struct {
+	sightings []DeviceSighting
+	weeks     []WeeklyHostsAlive
+}
+
+func (s *mockCapacitySource) ListDeviceSightings(_ context.Context) ([]DeviceSighting, error) {
+	return s.sightings, nil
+}
+
+func (s *mockCapacitySource) WeeklyHostsAlive(_ context.Context, _, _ time.Time) ([]WeeklyHostsAlive, error) {
+	return s.weeks, nil
+}
+
+var capacityNow = time.Date(2026, 6, 30, 12, 0, 0, 0, time.UTC)
+
+// steadyGrowth returns one device in 192.168.1.0/24 first seen each day for
+// the given number of days before capacityNow, plus one device elsewhere.
+func steadyGrowth(days int) []DeviceSighting {
+	sightings := []DeviceSighting{{FirstSeen: capacityNow.AddDate(0, 0, -days), IPAddresses: []string{"10.0.0.1"}}}
+	for i := 0; i < days; i++ {
+		sightings = append(sightings, DeviceSighting{
+			FirstSeen:   capacityNow.AddDate(0, 0, -i),
+			IPAddresses: []string{"192.168.1.10"},
+		})
+	}
+	return sightings
+}
+
+func newCapacityModule(src CapacitySource) *Module {
+	m := &Module{logger: zap.NewNop()}
+	m.SetCapacitySource(src)
+	return m
+}
+
+func TestProjectCapacity_SubnetLinearGrowth(t *testing.T) {
+	m := newCapacityModule(&mockCapacitySource{sightings: steadyGrowth(100)})
+
+	p, err := m.ProjectCapacity(context.Background(), CapacityQuery{Subnet: "192.168.1.7/24", HistoryDays: 60}, capacityNow)
+	if err != nil {
+		t.Fatalf("ProjectCapacity: %v", err)
+	}
+	if p.Subnet != "192.168.1.0/24" || p.Capacity != 254 || p.ThresholdPct != 80 {
+		t.Errorf("projection = %+v, want /24 capacity 254 at 80%%", p)
+	}
+	if p.Current != 100 || p.Points != 60 {
+		t.Errorf("current = %v over %d points, want 100 over 60", p.Current, p.Points)
+	}
+	if p.Model != "linear" || len(p.Fits) != 2 {
+		t.Fatalf("model = %q with %d fits, want linear selected of 2", p.Model, len(p.Fits))
+	}
+
+	// One device a day from 100 reaches 203.2 in ~103 days.
+	fit := p.Fits[0]
+	if fit.Crossing == nil || fit.Earliest == nil || fit.Latest == nil {
+		t.Fatalf("fit = %+v, want crossing with band", fit)
+	}
+	crossing, _ := time.Parse(time.DateOnly, *fit.Crossing)
+	if days := crossing.Sub(capacityNow).Hours() / 24; days < 100 || days > 106 {
+		t.Errorf("crossing = %s (%.0f days out), want ~103 days", *fit.Crossing, days)
+	}
+	if *fit.Earliest > *fit.Crossing || *fit.Latest < *fit.Crossing {
+		t.Errorf("band [%s, %s] does not contain %s", *fit.Earliest, *fit.Latest, *fit.Crossing)
+	}
+	if p.Fits[1].DoublingDays == nil {
+		t.Error("exponential fit missing doubling_days")
+	}
+}
+
+func TestProjectCapacity_AlreadyExceeded(t *testing.T) {
+	m := newCapacityModule(&mockCapacitySource{sightings: steadyGrowth(30)})
+
+	p, err := m.ProjectCapacity(context.Background(), CapacityQuery{Capacity: 20, Model: "linear"}, capacityNow)
+	if err != nil {
+		t.Fatalf("ProjectCapacity: %v", err)
+	}
+	if !p.AlreadyExceeded || len(p.Fits) != 1 || p.Fits[0].Crossing != nil {
+		t.Errorf("projection = %+v, want exceeded with no crossing", p)
+	}
+}
+
+func TestProjectCapacity_HostsAlive(t *testing.T) {
+	var weeks []WeeklyHostsAlive
+	for i := 0; i < 10; i++ {
+		weeks = append(weeks, WeeklyHostsAlive{PeriodStart: capacityNow.AddDate(0, 0, 7*(i-10)), AvgHostsAlive: 50})
+	}
+	m := newCapacityModule(&mockCapacitySource{weeks: weeks})
+
+	p, err := m.ProjectCapacity(context.Background(), CapacityQuery{Source: CapacitySourceHostsAlive, Capacity: 100}, capacityNow)
+	if err != nil {
+		t.Fatalf("ProjectCapacity: %v", err)
+	}
+	if p.Points != 10 || p.Utilization != 50 {
+		t.Errorf("projection = %+v", p)
+	}
+	for _, fit := range p.Fits {
+		if fit.Crossing != nil {
+			t.Errorf("%s fit crosses at %s, want none for a flat series", fit.Model, *fit.Crossing)
+		}
+	}
+}
+
+func TestProjectCapacity_InvalidQuery(t *testing.T) {
+	m := newCapacityModule(&mockCapacitySource{})
+
+	for _, q := range []CapacityQuery{
+		{},
+		{Subnet: "not-a-cidr"},
+		{Subnet: "fd00::/64"},
+		{Capacity: 10, Source: "bandwidth"},
+		{Capacity: 10, Model: "quadratic"},
+		{Capacity: 10, ThresholdPct: 150},
+		{Capacity: 10, HistoryDays: 1000},
+	} {
+		if _, err := m.ProjectCapacity(context.Background(), q, capacityNow); !errors.Is(err, ErrInvalidCapacityQuery) {
+			t.Errorf("ProjectCapacity(%+v) err = %v, want ErrInvalidCapacityQuery", q, err)
+		}
+	}
+}
+
+func TestHandleCapacityProjection(t *testing.T) {
+	m := newCapacityModule(&mockCapacitySource{sightings: steadyGrowth(10)})
+
+	w := httptest.NewRecorder()
+	m.handleCapacityProjection(w, httptest.NewRequest(http.MethodGet, "/capacity?subnet=192.168.1.0/24&threshold=90", http.NoBody))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var p CapacityProjection
+	if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if p.ThresholdPct != 90 || p.Capacity != 254 {
+		t.Errorf("projection = %+v", p)
+	}
+
+	w = httptest.NewRecorder()
+	m.handleCapacityProjection(w, httptest.NewRequest(http.MethodGet, "/capacity", http.NoBody))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("without capacity status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	w = httptest.NewRecorder()
+	(&Module{logger: zap.NewNop()}).handleCapacityProjection(w, httptest.NewRequest(http.MethodGet, "/capacity?capacity=10", http.NoBody))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("without source status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
@@ -13,6 +13,11 @@ type InsightConfig struct {
 	ForecastWindow      time.Duration `mapstructure:"forecast_window"`
 	AnomalyRetention    time.Duration `mapstructure:"anomaly_retention"`
 	MaintenanceInterval time.Duration `mapstructure:"maintenance_interval"`
+	ScanAnomalyWindow   int           `mapstructure:"scan_anomaly_window"` // Scans (and weeks) of history for scan metric z-scores
+
+	// AlertSummaryCacheTTL is how long an alert summary is reused while the
+	// active alerts are unchanged (0 disables caching).
+	AlertSummaryCacheTTL time.Duration `mapstructure:"alert_summary_cache_ttl"`
 
 	// Holt-Winters triple exponential smoothing parameters.
 	HWAlpha     float64 `mapstructure:"hw_alpha"`      // Level smoothing (0-1)
@@ -34,6 +39,9 @@ func DefaultConfig() InsightConfig {
 		ForecastWindow:      7 * 24 * time.Hour,
 		AnomalyRetention:    30 * 24 * time.Hour,
 		MaintenanceInterval: 1 * time.Hour,
+		ScanAnomalyWindow:   20,
+
+		AlertSummaryCacheTTL: 5 * time.Minute,
 
 		HWAlpha:      0.3,
 		HWBeta:       0.1,
@@ -7,6 +7,7 @@ const (
 	TopicDeviceUpdated    = "recon.device.updated"
 	TopicAlertTriggered   = "pulse.alert.triggered"
 	TopicAlertResolved    = "pulse.alert.resolved"
+	TopicScanCompleted    = "recon.scan.completed"
 )
 
 // Event topics published by the Insight module.
@@ -0,0 +1,98 @@
+package forecast
+
+import "math"
+
+// Trend model names.
+const (
+	TrendLinear      = "linear"
+	TrendExponential = "exponential"
+)
+
+// Trend is a growth model fitted by least squares. Linear trends model
+// y = Intercept + Slope*x; exponential trends model ln(y) = Intercept +
+// Slope*x, i.e. y = e^Intercept * e^(Slope*x). StdErr is the residual
+// standard error in model space (units of y for linear, ln(y) for
+// exponential) and sizes the prediction band.
+type Trend struct {
+	Model     string
+	Intercept float64
+	Slope     float64
+	RSquared  float64
+	StdErr    float64
+	N         int
+}
+
+// FitLinear fits a linear trend. Returns nil if fewer than 2 points are
+// provided.
+func FitLinear(x, y []float64) *Trend {
+	return fitTrend(TrendLinear, x, y)
+}
+
+// FitExponential fits an exponential trend by regressing ln(y) on x.
+// Non-positive values cannot be log-transformed and are skipped. Returns
+// nil if fewer than 2 usable points remain.
+func FitExponential(x, y []float64) *Trend {
+	if len(x) != len(y) {
+		return nil
+	}
+	lx := make([]float64, 0, len(x))
+	ly := make([]float64, 0, len(y))
+	for i := range y {
+		if y[i] > 0 {
+			lx = append(lx, x[i])
+			ly = append(ly, math.Log(y[i]))
+		}
+	}
+	return fitTrend(TrendExponential, lx, ly)
+}
+
+// fitTrend runs the regression in model space and adds the residual
+// standard error.
+func fitTrend(model string, x, y []float64) *Trend {
+	reg := LinearRegression(x, y, 0)
+	if reg == nil {
+		return nil
+	}
+	t := &Trend{
+		Model:     model,
+		Intercept: reg.Intercept,
+		Slope:     reg.Slope,
+		RSquared:  reg.RSquared,
+		N:         len(x),
+	}
+	if t.N > 2 {
+		var sse float64
+		for i := range x {
+			r := y[i] - (t.Intercept + t.Slope*x[i])
+			sse += r * r
+		}
+		t.StdErr = math.Sqrt(sse / float64(t.N-2))
+	}
+	return t
+}
+
+// Predict returns the modelled value at x.
+func (t *Trend) Predict(x float64) float64 {
+	v := t.Intercept + t.Slope*x
+	if t.Model == TrendExponential {
+		return math.Exp(v)
+	}
+	return v
+}
+
+// Crossing returns the x at which the trend, shifted by sigmas residual
+// standard errors, reaches target. Positive sigmas give the upper edge of
+// the band (an earlier crossing), negative sigmas the lower edge. ok is
+// false when the trend is not growing or target cannot be modelled.
+func (t *Trend) Crossing(target, sigmas float64) (x float64, ok bool) {
+	if t.Slope <= 0 {
+		return 0, false
+	}
+	if t.Model == TrendExponential {
+		if target <= 0 {
+			return 0, false
+		}
+		target = math.Log(target)
+	}
+	return (target - t.Intercept - sigmas*t.StdErr) / t.Slope, true
+}
@@ -0,0 +1,87 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFitLinear(t *testing.T) {
+	t.Parallel()
+
+	// y = 2x + 10 with alternating +/-1 noise.
+	x := []float64{0, 1, 2, 3, 4, 5}
+	y := []float64{11, 11, 15, 15, 19, 19}
+
+	trend := FitLinear(x, y)
+	if trend == nil {
+		t.Fatal("expected trend, got nil")
+	}
+	if trend.Model != TrendLinear || trend.N != 6 {
+		t.Errorf("trend = %+v", trend)
+	}
+	if math.Abs(trend.Slope-1.8286) > 0.001 {
+		t.Errorf("Slope = %v, want ~1.83", trend.Slope)
+	}
+	if trend.StdErr <= 0 {
+		t.Errorf("StdErr = %v, want > 0 for noisy data", trend.StdErr)
+	}
+
+	mid, ok := trend.Crossing(30, 0)
+	if !ok {
+		t.Fatal("expected crossing for growing trend")
+	}
+	if got := trend.Predict(mid); math.Abs(got-30) > 1e-9 {
+		t.Errorf("Predict(crossing) = %v, want 30", got)
+	}
+	early, _ := trend.Crossing(30, 1.96)
+	late, _ := trend.Crossing(30, -1.96)
+	if early >= mid || late <= mid {
+		t.Errorf("band = [%v, %v], want around %v", early, late, mid)
+	}
+}
+
+func TestFitExponential(t *testing.T) {
+	t.Parallel()
+
+	// y = 5 * 2^(x/10): doubles every 10 units. The zero is skipped.
+	x := []float64{0, 0, 10, 20, 30}
+	y := []float64{0, 5, 10, 20, 40}
+
+	trend := FitExponential(x, y)
+	if trend == nil {
+		t.Fatal("expected trend, got nil")
+	}
+	if trend.N != 4 {
+		t.Errorf("N = %d, want 4 (non-positive value skipped)", trend.N)
+	}
+	if math.Abs(trend.Slope-math.Ln2/10) > 1e-9 {
+		t.Errorf("Slope = %v, want ln2/10", trend.Slope)
+	}
+	if math.Abs(trend.RSquared-1) > 1e-9 || trend.StdErr > 1e-9 {
+		t.Errorf("RSquared = %v, StdErr = %v; want perfect fit", trend.RSquared, trend.StdErr)
+	}
+
+	x80, ok := trend.Crossing(80, 0)
+	if !ok || math.Abs(x80-40) > 1e-9 {
+		t.Errorf("Crossing(80) = %v, %v; want 40", x80, ok)
+	}
+	if got := trend.Predict(20); math.Abs(got-20) > 1e-9 {
+		t.Errorf("Predict(20) = %v, want 20", got)
+	}
+}
+
+func TestTrend_NoCrossing(t *testing.T) {
+	t.Parallel()
+
+	flat := FitLinear([]float64{0, 1, 2}, []float64{5, 5, 5})
+	if _, ok := flat.Crossing(10, 0); ok {
+		t.Error("flat trend should never cross")
+	}
+	shrinking := FitLinear([]float64{0, 1, 2}, []float64{9, 7, 5})
+	if _, ok := shrinking.Crossing(10, 0); ok {
+		t.Error("shrinking trend should never cross")
+	}
+	if FitExponential([]float64{0, 1}, []float64{0, 3}) != nil {
+		t.Error("expected nil with one positive value")
+	}
+}
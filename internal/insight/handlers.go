@@ -2,6 +2,7 @@ package insight
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -21,6 +22,8 @@ func (m *Module) Routes() []plugin.Route {
 		{Method: "GET", Path: "/baselines/{device_id}", Handler: m.handleDeviceBaselines},
 		{Method: "POST", Path: "/query", Handler: m.handleNLQuery},
 		{Method: "GET", Path: "/recommendations", Handler: m.handleRecommendations},
+		{Method: "GET", Path: "/capacity", Handler: m.handleCapacityProjection},
+		{Method: "GET", Path: "/alert-summary", Handler: m.handleAlertSummary},
 	}
 }
 
@@ -187,7 +190,7 @@ func (m *Module) handleDeviceBaselines(w http.ResponseWriter, r *http.Request) {
 // handleNLQuery processes a natural language query.
 //
 //	@Summary		Natural language query
-//	@Description	Translate a natural language question into a structured query and return results.
+//	@Description	Translate a natural language question into a structured query and return results. Device searches fall back to full-text search when the LLM is unavailable.
 //	@Tags			insight
 //	@Accept			json
 //	@Produce		json
@@ -210,10 +213,15 @@ func (m *Module) handleNLQuery(w http.ResponseWriter, r *http.Request) {
 
 	proc := newNLQueryProcessor(m.plugins, m.store)
 	if proc == nil {
+		if m.devices != nil {
+			m.fullTextDeviceQuery(w, r, req.Query)
+			return
+		}
 		writeError(w, http.StatusServiceUnavailable,
 			"natural language queries require the LLM plugin")
 		return
 	}
+	proc.devices = m.devices
 
 	resp, err := proc.Process(r.Context(), req.Query)
 	if err != nil {
@@ -221,6 +229,10 @@ func (m *Module) handleNLQuery(w http.ResponseWriter, r *http.Request) {
 			zap.String("query", req.Query),
 			zap.Error(err),
 		)
+		if m.devices != nil {
+			m.fullTextDeviceQuery(w, r, req.Query)
+			return
+		}
 		writeError(w, http.StatusInternalServerError, "query processing failed")
 		return
 	}
@@ -228,6 +240,22 @@ func (m *Module) handleNLQuery(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, resp)
 }
 
+// fullTextDeviceQuery answers a query with a keyword device search when the
+// LLM is unavailable.
+func (m *Module) fullTextDeviceQuery(w http.ResponseWriter, r *http.Request, query string) {
+	result, err := searchDevices(r.Context(), m.devices, fallbackDeviceFilter(query), deviceQueryModeFullText)
+	if err != nil {
+		m.logger.Error("full-text device query failed", zap.String("query", query), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "query processing failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, analytics.NLQueryResponse{
+		Query:      query,
+		Answer:     fmt.Sprintf("LLM unavailable; full-text search found %d matching devices.", result.Total),
+		Structured: result,
+	})
+}
+
 // handleRecommendations returns AI optimization recommendations.
 //
 //	@Summary		Get recommendations
@@ -21,9 +21,11 @@ Available intent types:
 - "list_correlations": Show active alert correlation groups. No parameters needed.
 - "list_devices": Show all discovered network devices. No parameters needed.
 - "device_status": Show comprehensive status for a specific device including anomalies, baselines, and forecasts. Requires "device_id".
+- "search_devices": Find devices matching filters. Requires a "filter" object using ONLY these optional keys:
+  "status" (online, offline, degraded, unknown), "device_type" (server, desktop, laptop, mobile, router, switch, printer, iot, access_point, firewall, nas, phone, tablet, camera, virtual_machine, container), "category", "owner", "location", "text" (free-text terms), "limit".
 
 Output format — return ONLY valid JSON, no explanation:
-{"type":"<intent_type>","device_id":"<id_if_applicable>","limit":<number_if_applicable>}
+{"type":"<intent_type>","device_id":"<id_if_applicable>","limit":<number_if_applicable>,"filter":{<search_devices_only>}}
 
 Examples:
 - "show me recent anomalies" → {"type":"list_anomalies","limit":10}
@@ -32,7 +34,9 @@ Examples:
 - "status of web-server-01" → {"type":"device_status","device_id":"web-server-01"}
 - "forecasts for db-primary" → {"type":"list_forecasts","device_id":"db-primary"}
 - "are there correlated alerts?" → {"type":"list_correlations"}
-- "baselines for switch-core" → {"type":"list_baselines","device_id":"switch-core"}`
+- "baselines for switch-core" → {"type":"list_baselines","device_id":"switch-core"}
+- "show me all offline cameras in the garage" → {"type":"search_devices","filter":{"status":"offline","device_type":"camera","location":"garage"}}
+- "printers owned by alice" → {"type":"search_devices","filter":{"device_type":"printer","owner":"alice"}}`
 
 // responseFormatterTemplate is used for the second LLM call that converts structured data
 // into a natural language answer.
@@ -50,11 +54,27 @@ type nlQueryProcessor struct {
 	llmProvider llm.Provider
 	store       *InsightStore
 	plugins     plugin.PluginResolver
+	devices     DeviceSearcher
 }
 
 // newNLQueryProcessor creates a processor by resolving the LLM plugin.
 // Returns nil if no LLM provider is available.
 func newNLQueryProcessor(plugins plugin.PluginResolver, store *InsightStore) *nlQueryProcessor {
+	provider := resolveLLMProvider(plugins)
+	if provider == nil {
+		return nil
+	}
+
+	return &nlQueryProcessor{
+		llmProvider: provider,
+		store:       store,
+		plugins:     plugins,
+	}
+}
+
+// resolveLLMProvider returns the first registered LLM provider, or nil if
+// none is available.
+func resolveLLMProvider(plugins plugin.PluginResolver) llm.Provider {
 	if plugins == nil {
 		return nil
 	}
@@ -68,12 +88,7 @@ func newNLQueryProcessor(plugins plugin.PluginResolver, store *InsightStore) *nl
 	if !ok {
 		return nil
 	}
-
-	return &nlQueryProcessor{
-		llmProvider: llmPlugin.Provider(),
-		store:       store,
-		plugins:     plugins,
-	}
+	return llmPlugin.Provider()
 }
 
 // Process executes a natural language query through a two-phase LLM pipeline:
@@ -86,7 +101,18 @@ func (p *nlQueryProcessor) Process(ctx context.Context, query string) (*analytic
 		return nil, fmt.Errorf("parse intent: %w", err)
 	}
 
-	structured, err := intent.execute(ctx, p.store, p.plugins)
+	// Device searches run through the bounded filter rather than execute so
+	// the LLM output never reaches the store unvalidated.
+	var structured any
+	if intent.Type == IntentSearchDevices {
+		var filter DeviceFilter
+		if intent.Filter != nil {
+			filter = *intent.Filter
+		}
+		structured, err = searchDevices(ctx, p.devices, filter, deviceQueryModeLLM)
+	} else {
+		structured, err = intent.execute(ctx, p.store, p.plugins)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("execute intent: %w", err)
 	}
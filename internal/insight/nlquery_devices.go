@@ -0,0 +1,179 @@
+package insight
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+// IntentSearchDevices filters devices by a bounded set of fields.
+const IntentSearchDevices = "search_devices"
+
+// Device query modes reported in deviceQueryResult.
+const (
+	deviceQueryModeLLM      = "llm"
+	deviceQueryModeFullText = "fulltext"
+)
+
+// maxFilterValueLen caps the length of free-text filter values from the LLM.
+const maxFilterValueLen = 64
+
+// maxDeviceQueryLimit caps the number of devices returned by a query.
+const maxDeviceQueryLimit = 200
+
+// DeviceFilter is the bounded set of device filters a natural-language query
+// can be translated into. The LLM can only populate these fields; values are
+// validated before being passed to the device store as bound parameters.
+type DeviceFilter struct {
+	Status     string `json:"status,omitempty"`
+	DeviceType string `json:"device_type,omitempty"`
+	Category   string `json:"category,omitempty"`
+	Owner      string `json:"owner,omitempty"`
+	Location   string `json:"location,omitempty"`
+	Text       string `json:"text,omitempty"` // Free-text terms matched against hostname, notes, etc.
+	Limit      int    `json:"limit,omitempty"`
+}
+
+// DeviceSearcher runs device filters against the device inventory.
+// Defined here (consumer-side interface) to avoid coupling insight -> recon.
+type DeviceSearcher interface {
+	SearchDevices(ctx context.Context, filter DeviceFilter) ([]models.Device, int, error)
+}
+
+// deviceQueryResult is the structured result of a device query, including
+// the interpreted filter for transparency.
+type deviceQueryResult struct {
+	Mode    string          `json:"mode"` // "llm" or "fulltext"
+	Filter  DeviceFilter    `json:"filter"`
+	Devices []models.Device `json:"devices"`
+	Total   int             `json:"total"`
+}
+
+// SetDeviceSearcher sets the device searcher used by natural-language device queries.
+// Called from the composition root after all plugins are initialized.
+func (m *Module) SetDeviceSearcher(ds DeviceSearcher) {
+	m.devices = ds
+}
+
+var knownDeviceStatuses = map[string]bool{
+	string(models.DeviceStatusOnline):   true,
+	string(models.DeviceStatusOffline):  true,
+	string(models.DeviceStatusDegraded): true,
+	string(models.DeviceStatusUnknown):  true,
+}
+
+// deviceTypeWords maps query words (including plurals) to device types.
+var deviceTypeWords = map[string]models.DeviceType{
+	"server":          models.DeviceTypeServer,
+	"servers":         models.DeviceTypeServer,
+	"desktop":         models.DeviceTypeDesktop,
+	"desktops":        models.DeviceTypeDesktop,
+	"laptop":          models.DeviceTypeLaptop,
+	"laptops":         models.DeviceTypeLaptop,
+	"mobile":          models.DeviceTypeMobile,
+	"router":          models.DeviceTypeRouter,
+	"routers":         models.DeviceTypeRouter,
+	"switch":          models.DeviceTypeSwitch,
+	"switches":        models.DeviceTypeSwitch,
+	"printer":         models.DeviceTypePrinter,
+	"printers":        models.DeviceTypePrinter,
+	"iot":             models.DeviceTypeIoT,
+	"access_point":    models.DeviceTypeAccessPoint,
+	"firewall":        models.DeviceTypeFirewall,
+	"firewalls":       models.DeviceTypeFirewall,
+	"nas":             models.DeviceTypeNAS,
+	"phone":           models.DeviceTypePhone,
+	"phones":          models.DeviceTypePhone,
+	"tablet":          models.DeviceTypeTablet,
+	"tablets":         models.DeviceTypeTablet,
+	"camera":          models.DeviceTypeCamera,
+	"cameras":         models.DeviceTypeCamera,
+	"vm":              models.DeviceTypeVM,
+	"vms":             models.DeviceTypeVM,
+	"virtual_machine": models.DeviceTypeVM,
+	"container":       models.DeviceTypeContainer,
+	"containers":      models.DeviceTypeContainer,
+}
+
+// queryStopWords are dropped from full-text fallback queries.
+var queryStopWords = map[string]bool{
+	"a": true, "all": true, "an": true, "any": true, "are": true, "at": true,
+	"device": true, "devices": true, "find": true, "for": true, "in": true,
+	"is": true, "list": true, "me": true, "my": true, "of": true, "on": true,
+	"show": true, "that": true, "the": true, "what": true, "which": true, "with": true,
+}
+
+// sanitize drops values outside the known enumerations and bounds free text,
+// so only well-formed filters reach the store.
+func (f *DeviceFilter) sanitize() {
+	f.Status = strings.ToLower(strings.TrimSpace(f.Status))
+	if !knownDeviceStatuses[f.Status] {
+		f.Status = ""
+	}
+	f.DeviceType = strings.ToLower(strings.TrimSpace(f.DeviceType))
+	if dt, ok := deviceTypeWords[f.DeviceType]; ok {
+		f.DeviceType = string(dt)
+	} else {
+		f.DeviceType = ""
+	}
+	f.Category = boundFilterValue(f.Category)
+	f.Owner = boundFilterValue(f.Owner)
+	f.Location = boundFilterValue(f.Location)
+	f.Text = boundFilterValue(f.Text)
+	if f.Limit <= 0 || f.Limit > maxDeviceQueryLimit {
+		f.Limit = 50
+	}
+}
+
+func boundFilterValue(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) > maxFilterValueLen {
+		s = s[:maxFilterValueLen]
+	}
+	return s
+}
+
+// fallbackDeviceFilter builds a filter from a raw query without the LLM:
+// status and device type words become filters, the rest is full-text search.
+func fallbackDeviceFilter(query string) DeviceFilter {
+	var f DeviceFilter
+	var terms []string
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		word = strings.Trim(word, `.,;:!?"'`)
+		switch {
+		case word == "" || queryStopWords[word]:
+		case knownDeviceStatuses[word] && f.Status == "":
+			f.Status = word
+		case deviceTypeWords[word] != "" && f.DeviceType == "":
+			f.DeviceType = string(deviceTypeWords[word])
+		default:
+			terms = append(terms, word)
+		}
+	}
+	f.Text = strings.Join(terms, " ")
+	f.sanitize()
+	return f
+}
+
+// searchDevices runs a sanitized filter through the device searcher.
+func searchDevices(ctx context.Context, ds DeviceSearcher, filter DeviceFilter, mode string) (*deviceQueryResult, error) {
+	if ds == nil {
+		return nil, fmt.Errorf("device search not available")
+	}
+	filter.sanitize()
+	devices, total, err := ds.SearchDevices(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if devices == nil {
+		devices = []models.Device{}
+	}
+	return &deviceQueryResult{
+		Mode:    mode,
+		Filter:  filter,
+		Devices: devices,
+		Total:   total,
+	}, nil
+}
@@ -0,0 +1,119 @@
+package insight
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/HerbHall/subnetree/pkg/llm"
+	"github.com/HerbHall/subnetree/pkg/models"
+	"github.com/HerbHall/subnetree/pkg/plugin"
+	"github.com/HerbHall/subnetree/pkg/roles"
+)
+
+// mockDeviceSearcher records the last filter and returns canned devices.
+type mockDeviceSearcher struct {
+	devices []models.Device
+	last    DeviceFilter
+}
+
+func (s *mockDeviceSearcher) SearchDevices(_ context.Context, f DeviceFilter) ([]models.Device, int, error) {
+	s.last = f
+	return s.devices, len(s.devices), nil
+}
+
+func TestProcess_SearchDevicesIntent(t *testing.T) {
+	provider := &mockLLMProvider{
+		chatFunc: func(_ context.Context, _ []llm.Message, _ ...llm.CallOption) (*llm.Response, error) {
+			return &llm.Response{
+				Content: `{"type":"search_devices","filter":{"status":"offline","device_type":"camera","location":"garage","sql":"DROP TABLE"}}`,
+				Model:   "mock-model",
+			}, nil
+		},
+	}
+	resolver := &mockPluginResolver{
+		byRole: map[string][]plugin.Plugin{roles.RoleLLM: {&mockLLMPlugin{provider: provider}}},
+	}
+	searcher := &mockDeviceSearcher{devices: []models.Device{{ID: "cam-1", DeviceType: models.DeviceTypeCamera}}}
+
+	proc := newNLQueryProcessor(resolver, nil)
+	proc.devices = searcher
+
+	resp, err := proc.Process(context.Background(), "show me all offline cameras in the garage")
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	result, ok := resp.Structured.(*deviceQueryResult)
+	if !ok {
+		t.Fatalf("Structured = %T, want *deviceQueryResult", resp.Structured)
+	}
+	want := DeviceFilter{Status: "offline", DeviceType: "camera", Location: "garage", Limit: 50}
+	if searcher.last != want {
+		t.Errorf("filter = %+v, want %+v", searcher.last, want)
+	}
+	if result.Mode != deviceQueryModeLLM || len(result.Devices) != 1 || result.Filter != want {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestDeviceFilter_SanitizeDropsUnknownValues(t *testing.T) {
+	f := DeviceFilter{
+		Status:     "exploded",
+		DeviceType: "toaster",
+		Location:   strings.Repeat("x", 200),
+		Limit:      10000,
+	}
+	f.sanitize()
+
+	if f.Status != "" || f.DeviceType != "" {
+		t.Errorf("status/type = %q/%q, want empty", f.Status, f.DeviceType)
+	}
+	if len(f.Location) != maxFilterValueLen {
+		t.Errorf("location length = %d, want %d", len(f.Location), maxFilterValueLen)
+	}
+	if f.Limit != 50 {
+		t.Errorf("limit = %d, want 50", f.Limit)
+	}
+}
+
+func TestFallbackDeviceFilter(t *testing.T) {
+	got := fallbackDeviceFilter("Show me all offline cameras in the garage!")
+	want := DeviceFilter{Status: "offline", DeviceType: "camera", Text: "garage", Limit: 50}
+	if got != want {
+		t.Errorf("fallbackDeviceFilter = %+v, want %+v", got, want)
+	}
+}
+
+func TestHandleNLQuery_FullTextFallback(t *testing.T) {
+	m := newTestModule(t)
+	searcher := &mockDeviceSearcher{devices: []models.Device{{ID: "printer-1"}}}
+	m.SetDeviceSearcher(searcher)
+
+	req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(`{"query":"printers upstairs"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	m.handleNLQuery(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var got struct {
+		Answer     string            `json:"answer"`
+		Structured deviceQueryResult `json:"structured"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Structured.Mode != deviceQueryModeFullText {
+		t.Errorf("mode = %q, want fulltext", got.Structured.Mode)
+	}
+	if searcher.last.DeviceType != "printer" || searcher.last.Text != "upstairs" {
+		t.Errorf("filter = %+v, want printer + upstairs", searcher.last)
+	}
+	if len(got.Structured.Devices) != 1 {
+		t.Errorf("devices = %d, want 1", len(got.Structured.Devices))
+	}
+}
@@ -22,9 +22,10 @@ const (
 
 // queryIntent represents the structured output from the LLM intent parser.
 type queryIntent struct {
-	Type     string `json:"type"`
-	DeviceID string `json:"device_id,omitempty"`
-	Limit    int    `json:"limit,omitempty"`
+	Type     string        `json:"type"`
+	DeviceID string        `json:"device_id,omitempty"`
+	Limit    int           `json:"limit,omitempty"`
+	Filter   *DeviceFilter `json:"filter,omitempty"` // search_devices only
 }
 
 // deviceStatusResult is the composite response for a device_status intent.
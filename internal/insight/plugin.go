@@ -34,6 +34,14 @@ type Module struct {
 	plugins plugin.PluginResolver
 	states  *stateManager
 
+	scanMetrics ScanMetricsSource
+	devices     DeviceSearcher
+	capacity    CapacitySource
+	alerts      AlertSource
+
+	summaryMu    sync.Mutex
+	summaryCache *cachedAlertSummary
+
 	mu        sync.RWMutex
 	baselines map[string]struct{} // Tracked device:metric pairs
 
@@ -148,6 +156,7 @@ func (m *Module) Subscriptions() []plugin.Subscription {
 		{Topic: TopicAlertTriggered, Handler: m.handleAlertTriggered},
 		{Topic: TopicAlertResolved, Handler: m.handleAlertResolved},
 		{Topic: TopicDeviceDiscovered, Handler: m.handleDeviceDiscovered},
+		{Topic: TopicScanCompleted, Handler: m.handleScanCompleted},
 	}
 }
 
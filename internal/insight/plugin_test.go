@@ -119,8 +119,8 @@ func TestSubscriptions_ReturnsTopics(t *testing.T) {
 	m := New()
 
 	subs := m.Subscriptions()
-	if len(subs) != 4 {
-		t.Fatalf("Subscriptions() returned %d, want 4", len(subs))
+	if len(subs) != 5 {
+		t.Fatalf("Subscriptions() returned %d, want 5", len(subs))
 	}
 
 	expected := map[string]bool{
@@ -128,6 +128,7 @@ func TestSubscriptions_ReturnsTopics(t *testing.T) {
 		TopicAlertTriggered:   false,
 		TopicAlertResolved:    false,
 		TopicDeviceDiscovered: false,
+		TopicScanCompleted:    false,
 	}
 	for _, s := range subs {
 		if _, ok := expected[s.Topic]; !ok {
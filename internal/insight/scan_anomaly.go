@@ -0,0 +1,157 @@
+package insight
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/HerbHall/subnetree/internal/insight/anomaly"
+	"github.com/HerbHall/subnetree/pkg/analytics"
+	"github.com/HerbHall/subnetree/pkg/models"
+	"github.com/HerbHall/subnetree/pkg/plugin"
+	"go.uber.org/zap"
+)
+
+// Scan metric names recorded on anomalies.
+const (
+	ScanMetricHostsAlive  = "scan.hosts_alive"
+	ScanMetricDuration    = "scan.duration_ms"
+	ScanMetricFailedScans = "scan.failed_scans"
+)
+
+// scanAnomalyType is the anomaly type for scan metric anomalies.
+const scanAnomalyType = "scan_zscore"
+
+// minScanSamples is the minimum history required before a scan series is analyzed.
+const minScanSamples = 5
+
+// WeeklyScanFailures is the failed scan count for one weekly aggregate period.
+type WeeklyScanFailures struct {
+	PeriodStart time.Time
+	FailedScans int
+}
+
+// ScanMetricsSource provides recon scan metrics to the insight module.
+// Defined here (consumer-side interface) to avoid coupling insight -> recon.
+type ScanMetricsSource interface {
+	// ListRawMetrics returns the most recent per-scan metrics, newest first.
+	ListRawMetrics(ctx context.Context, limit int) ([]models.ScanMetrics, error)
+	// WeeklyFailedScans returns weekly failed scan counts in the range, oldest first.
+	WeeklyFailedScans(ctx context.Context, start, end time.Time) ([]WeeklyScanFailures, error)
+}
+
+// SetScanMetricsSource sets the source of scan metrics for scan anomaly detection.
+// Called from the composition root after all plugins are initialized.
+func (m *Module) SetScanMetricsSource(src ScanMetricsSource) {
+	m.scanMetrics = src
+}
+
+// handleScanCompleted re-analyzes the scan metric series after each scan.
+func (m *Module) handleScanCompleted(ctx context.Context, _ plugin.Event) {
+	if m.scanMetrics == nil || m.store == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	m.analyzeScanMetrics(ctx)
+}
+
+// analyzeScanMetrics checks the newest scan metrics against a rolling window
+// of history using a z-score. Only the direction that indicates a problem is
+// flagged: a drop in hosts alive, a longer scan duration, or more failed scans.
+func (m *Module) analyzeScanMetrics(ctx context.Context) {
+	window := m.cfg.ScanAnomalyWindow
+	if window < minScanSamples {
+		window = minScanSamples
+	}
+
+	raw, err := m.scanMetrics.ListRawMetrics(ctx, window+1)
+	if err != nil {
+		m.logger.Warn("failed to list scan metrics", zap.Error(err))
+	} else if len(raw) > minScanSamples {
+		latest := raw[0]
+		hostsAlive := make([]float64, 0, len(raw)-1)
+		durations := make([]float64, 0, len(raw)-1)
+		for i := range raw[1:] {
+			hostsAlive = append(hostsAlive, float64(raw[i+1].HostsAlive))
+			durations = append(durations, float64(raw[i+1].DurationMs))
+		}
+		m.checkScanSeries(ctx, ScanMetricHostsAlive, latest.ScanID, float64(latest.HostsAlive), hostsAlive, -1)
+		m.checkScanSeries(ctx, ScanMetricDuration, latest.ScanID, float64(latest.DurationMs), durations, 1)
+	}
+
+	now := time.Now().UTC()
+	weeks, err := m.scanMetrics.WeeklyFailedScans(ctx, now.AddDate(0, 0, -7*(window+1)), now)
+	if err != nil {
+		m.logger.Warn("failed to list weekly scan aggregates", zap.Error(err))
+	} else if len(weeks) > minScanSamples {
+		latest := weeks[len(weeks)-1]
+		failed := make([]float64, 0, len(weeks)-1)
+		for i := range weeks[:len(weeks)-1] {
+			failed = append(failed, float64(weeks[i].FailedScans))
+		}
+		m.checkScanSeries(ctx, ScanMetricFailedScans, latest.PeriodStart.Format("2006-01-02"), float64(latest.FailedScans), failed, 1)
+	}
+}
+
+// checkScanSeries flags value as anomalous when its z-score against history
+// exceeds the threshold in the given direction (1 = spike, -1 = drop).
+// key identifies the sample so repeat analyses do not duplicate the anomaly.
+func (m *Module) checkScanSeries(ctx context.Context, metric, key string, value float64, history []float64, direction float64) {
+	mean, stdDev := meanStdDev(history)
+	result := anomaly.ZScoreCheck(value, mean, stdDev, m.cfg.ZScoreThreshold)
+	if !result.IsAnomaly || result.ZScore*direction < 0 {
+		return
+	}
+
+	a := &analytics.Anomaly{
+		ID:          fmt.Sprintf("scan:%s:%s", metric, key),
+		MetricName:  metric,
+		Severity:    result.Severity,
+		Type:        scanAnomalyType,
+		Value:       value,
+		Expected:    mean,
+		Deviation:   result.ZScore,
+		DetectedAt:  time.Now(),
+		Description: fmt.Sprintf("%s anomaly: value=%.2f expected=%.2f z=%.2f", metric, value, mean, result.ZScore),
+	}
+	inserted, err := m.store.InsertAnomalyIfNew(ctx, a)
+	if err != nil {
+		m.logger.Warn("failed to store scan anomaly", zap.Error(err))
+		return
+	}
+	if !inserted {
+		return
+	}
+
+	m.logger.Info("scan anomaly detected",
+		zap.String("metric", metric),
+		zap.String("severity", a.Severity),
+		zap.Float64("value", value),
+		zap.Float64("expected", mean),
+	)
+	if m.bus != nil {
+		m.bus.PublishAsync(ctx, plugin.Event{
+			Topic:   TopicAnomalyDetected,
+			Source:  "insight",
+			Payload: a,
+		})
+	}
+}
+
+// meanStdDev returns the mean and population standard deviation of values.
+func meanStdDev(values []float64) (mean, stdDev float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(values)))
+}
@@ -0,0 +1,133 @@
+package insight
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/analytics"
+	"github.com/HerbHall/subnetree/pkg/models"
+	"go.uber.org/zap"
+)
+
+type mockScanMetricsSource struct {
+	raw   []models.ScanMetrics
+	weeks []WeeklyScanFailures
+}
+
+func (s *mockScanMetricsSource) ListRawMetrics(_ context.Context, limit int) ([]models.ScanMetrics, error) {
+	if len(s.raw) > limit {
+		return s.raw[:limit], nil
+	}
+	return s.raw, nil
+}
+
+func (s *mockScanMetricsSource) WeeklyFailedScans(_ context.Context, _, _ time.Time) ([]WeeklyScanFailures, error) {
+	return s.weeks, nil
+}
+
+// scanHistory builds newest-first raw metrics: latest followed by a stable history.
+func scanHistory(latest models.ScanMetrics, n int) []models.ScanMetrics {
+	raw := []models.ScanMetrics{latest}
+	for i := 0; i < n; i++ {
+		raw = append(raw, models.ScanMetrics{
+			ScanID:     fmt.Sprintf("scan-%d", i),
+			HostsAlive: 40 + i%3,
+			DurationMs: int64(10000 + (i%3)*200),
+		})
+	}
+	return raw
+}
+
+func newScanAnomalyModule(t *testing.T, src ScanMetricsSource) *Module {
+	t.Helper()
+	m := &Module{
+		logger: zap.NewNop(),
+		cfg:    DefaultConfig(),
+		store:  testStore(t),
+	}
+	m.SetScanMetricsSource(src)
+	return m
+}
+
+func listScanAnomalies(t *testing.T, m *Module) map[string]analytics.Anomaly {
+	t.Helper()
+	list, err := m.store.ListAnomalies(context.Background(), "", 100)
+	if err != nil {
+		t.Fatalf("ListAnomalies: %v", err)
+	}
+	byMetric := make(map[string]analytics.Anomaly)
+	for i := range list {
+		byMetric[list[i].MetricName] = list[i]
+	}
+	return byMetric
+}
+
+func TestAnalyzeScanMetrics_FlagsDropAndRegression(t *testing.T) {
+	src := &mockScanMetricsSource{
+		raw: scanHistory(models.ScanMetrics{ScanID: "scan-latest", HostsAlive: 5, DurationMs: 60000}, 10),
+	}
+	m := newScanAnomalyModule(t, src)
+
+	m.analyzeScanMetrics(context.Background())
+	got := listScanAnomalies(t, m)
+
+	if a, ok := got[ScanMetricHostsAlive]; !ok || a.Deviation >= 0 || a.Type != scanAnomalyType {
+		t.Errorf("hosts_alive anomaly = %+v (found %v), want negative deviation", a, ok)
+	}
+	if _, ok := got[ScanMetricDuration]; !ok {
+		t.Error("expected scan duration anomaly")
+	}
+
+	// Re-analyzing the same scan must not duplicate anomalies.
+	m.analyzeScanMetrics(context.Background())
+	list, _ := m.store.ListAnomalies(context.Background(), "", 100)
+	if len(list) != 2 {
+		t.Errorf("anomalies after re-run = %d, want 2", len(list))
+	}
+}
+
+func TestAnalyzeScanMetrics_IgnoresImprovement(t *testing.T) {
+	// More hosts alive and faster scans are not problems.
+	src := &mockScanMetricsSource{
+		raw: scanHistory(models.ScanMetrics{ScanID: "scan-latest", HostsAlive: 200, DurationMs: 100}, 10),
+	}
+	m := newScanAnomalyModule(t, src)
+
+	m.analyzeScanMetrics(context.Background())
+	if got := listScanAnomalies(t, m); len(got) != 0 {
+		t.Errorf("anomalies = %v, want none", got)
+	}
+}
+
+func TestAnalyzeScanMetrics_FailedScansSpike(t *testing.T) {
+	start := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	var weeks []WeeklyScanFailures
+	for i := 0; i < 8; i++ {
+		weeks = append(weeks, WeeklyScanFailures{PeriodStart: start.AddDate(0, 0, 7*i), FailedScans: i % 2})
+	}
+	weeks = append(weeks, WeeklyScanFailures{PeriodStart: start.AddDate(0, 0, 56), FailedScans: 12})
+	m := newScanAnomalyModule(t, &mockScanMetricsSource{weeks: weeks})
+
+	m.analyzeScanMetrics(context.Background())
+	a, ok := listScanAnomalies(t, m)[ScanMetricFailedScans]
+	if !ok {
+		t.Fatal("expected failed_scans anomaly")
+	}
+	if a.Value != 12 {
+		t.Errorf("Value = %v, want 12", a.Value)
+	}
+}
+
+func TestAnalyzeScanMetrics_InsufficientHistory(t *testing.T) {
+	src := &mockScanMetricsSource{
+		raw: scanHistory(models.ScanMetrics{ScanID: "scan-latest", HostsAlive: 0}, 3),
+	}
+	m := newScanAnomalyModule(t, src)
+
+	m.analyzeScanMetrics(context.Background())
+	if got := listScanAnomalies(t, m); len(got) != 0 {
+		t.Errorf("anomalies = %v, want none with short history", got)
+	}
+}
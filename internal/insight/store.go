@@ -29,10 +29,15 @@ func (s *InsightStore) UpsertBaseline(ctx context.Context, b *analytics.Baseline
 		stable = 1
 	}
 	_, err := s.db.ExecContext(ctx, `
-		INSERT OR REPLACE INTO analytics_baselines (
+		INSERT INTO analytics_baselines (
 			device_id, metric_name, algorithm, mean, std_dev, variance,
 			samples, stable, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(device_id, metric_name) DO UPDATE SET
+			algorithm = excluded.algorithm, mean = excluded.mean,
+			std_dev = excluded.std_dev, variance = excluded.variance,
+			samples = excluded.samples, stable = excluded.stable,
+			updated_at = excluded.updated_at`,
 		b.DeviceID, b.MetricName, b.Algorithm, b.Mean, b.StdDev,
 		b.StdDev*b.StdDev, b.Samples, stable, b.UpdatedAt,
 	)
@@ -88,6 +93,24 @@ func (s *InsightStore) InsertAnomaly(ctx context.Context, a *analytics.Anomaly)
 	return nil
 }
 
+// InsertAnomalyIfNew inserts an anomaly unless one with the same ID already
+// exists. Returns true if a new record was written.
+func (s *InsightStore) InsertAnomalyIfNew(ctx context.Context, a *analytics.Anomaly) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `
+		INSERT OR IGNORE INTO analytics_anomalies (
+			id, device_id, metric_name, severity, type,
+			value, expected, deviation, description, detected_at, resolved_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		a.ID, a.DeviceID, a.MetricName, a.Severity, a.Type,
+		a.Value, a.Expected, a.Deviation, a.Description, a.DetectedAt, a.ResolvedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("insert anomaly: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
 // ListAnomalies returns anomalies, optionally filtered by device.
 // Pass empty deviceID to list all. Results are ordered by detected_at descending.
 func (s *InsightStore) ListAnomalies(ctx context.Context, deviceID string, limit int) ([]analytics.Anomaly, error) {
@@ -171,10 +194,15 @@ func (s *InsightStore) UpsertForecast(ctx context.Context, f *analytics.Forecast
 		thresholdSecs = &secs
 	}
 	_, err := s.db.ExecContext(ctx, `
-		INSERT OR REPLACE INTO analytics_forecasts (
+		INSERT INTO analytics_forecasts (
 			device_id, metric_name, current_value, predicted_value,
 			threshold, slope, confidence, time_to_threshold_secs, generated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(device_id, metric_name) DO UPDATE SET
+			current_value = excluded.current_value, predicted_value = excluded.predicted_value,
+			threshold = excluded.threshold, slope = excluded.slope,
+			confidence = excluded.confidence, time_to_threshold_secs = excluded.time_to_threshold_secs,
+			generated_at = excluded.generated_at`,
 		f.DeviceID, f.MetricName, f.CurrentValue, f.PredictedValue,
 		f.Threshold, f.Slope, f.Confidence, thresholdSecs, f.GeneratedAt,
 	)
@@ -0,0 +1,194 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+// ScanActuator starts and tracks network scans for the MCP module.
+// Implemented by the recon module; wired via composition root adapter.
+type ScanActuator interface {
+	StartScan(ctx context.Context, subnet string) (*models.ScanResult, error)
+	GetScan(ctx context.Context, id string) (*models.ScanResult, error)
+}
+
+// AlertState is the MCP view of a monitoring alert after a state change.
+type AlertState struct {
+	ID             string     `json:"id"`
+	CheckID        string     `json:"check_id"`
+	DeviceID       string     `json:"device_id"`
+	Severity       string     `json:"severity"`
+	Message        string     `json:"message"`
+	TriggeredAt    time.Time  `json:"triggered_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+}
+
+// AlertActuator acknowledges and resolves monitoring alerts for the MCP module.
+// Implemented by the pulse store; wired via composition root adapter.
+// Both methods return nil, nil when the alert does not exist.
+type AlertActuator interface {
+	AcknowledgeAlert(ctx context.Context, id string) (*AlertState, error)
+	ResolveAlert(ctx context.Context, id string) (*AlertState, error)
+}
+
+// SetScanActuator injects the scan actuator. Called from the composition root
+// (main.go) to wire the recon module without cross-internal imports.
+func (m *Module) SetScanActuator(a ScanActuator) {
+	m.scanActuator = a
+}
+
+// SetAlertActuator injects the alert actuator. Called from the composition
+// root (main.go) to wire the pulse store without cross-internal imports.
+func (m *Module) SetAlertActuator(a AlertActuator) {
+	m.alertActuator = a
+}
+
+// SetReadOnly disables tools that change state. Called from the composition
+// root when the server runs in demo mode. Must be called before Start.
+func (m *Module) SetReadOnly(readOnly bool) {
+	m.readOnly = readOnly
+}
+
+type scanSubnetInput struct {
+	Subnet string `json:"subnet" jsonschema:"Subnet to scan in CIDR notation, e.g. 10.0.0.0/24 (maximum /16)"`
+}
+
+type getScanStatusInput struct {
+	ScanID string `json:"scan_id" jsonschema:"The scan ID returned by scan_subnet"`
+}
+
+type alertIDInput struct {
+	AlertID string `json:"alert_id" jsonschema:"The unique alert identifier"`
+}
+
+// registerActionTools adds tools that change state. They are omitted
+// entirely in read-only mode so clients never see them.
+func (m *Module) registerActionTools() {
+	if m.readOnly {
+		return
+	}
+
+	sdkmcp.AddTool(m.server, &sdkmcp.Tool{
+		Name:        "scan_subnet",
+		Description: "Start a network discovery scan of a subnet (CIDR, maximum /16). Returns the scan ID; use get_scan_status to follow progress.",
+	}, m.handleScanSubnet)
+
+	sdkmcp.AddTool(m.server, &sdkmcp.Tool{
+		Name:        "get_scan_status",
+		Description: "Get the status of a network scan by ID, including whether it has completed and how many devices were found online.",
+	}, m.handleGetScanStatus)
+
+	sdkmcp.AddTool(m.server, &sdkmcp.Tool{
+		Name:        "acknowledge_alert",
+		Description: "Acknowledge a monitoring alert so others know it is being handled. Returns the updated alert.",
+	}, m.handleAcknowledgeAlert)
+
+	sdkmcp.AddTool(m.server, &sdkmcp.Tool{
+		Name:        "resolve_alert",
+		Description: "Mark a monitoring alert as resolved. Returns the updated alert.",
+	}, m.handleResolveAlert)
+
+	sdkmcp.AddTool(m.server, &sdkmcp.Tool{
+		Name:        "create_check",
+		Description: "Start monitoring a device with a ping (icmp), TCP port (tcp), or HTTP (http) check run every interval_seconds. Look up the device's ID and address with list_devices or get_device first. Returns the created check.",
+	}, m.handleCreateCheck)
+
+	sdkmcp.AddTool(m.server, &sdkmcp.Tool{
+		Name:        "delete_check",
+		Description: "Stop a monitoring check and delete its results. Use list_checks to find the check ID.",
+	}, m.handleDeleteCheck)
+}
+
+func (m *Module) handleScanSubnet(ctx context.Context, _ *sdkmcp.CallToolRequest, input scanSubnetInput) (*sdkmcp.CallToolResult, any, error) {
+	start := time.Now()
+	inputJSON := marshalInput(input)
+	m.publishToolCall("scan_subnet", input)
+
+	if m.scanActuator == nil {
+		m.auditToolCall(ctx, "scan_subnet", inputJSON, "http", start, false, "scan actuator not available")
+		return textResult("Scanning not available. The recon module may not be loaded."), nil, nil
+	}
+
+	scan, err := m.scanActuator.StartScan(context.Background(), input.Subnet)
+	if err != nil {
+		msg := fmt.Sprintf("failed to start scan: %v", err)
+		m.auditToolCall(ctx, "scan_subnet", inputJSON, "http", start, false, msg)
+		return errorResult(msg), nil, nil
+	}
+
+	m.auditToolCall(ctx, "scan_subnet", inputJSON, "http", start, true, "")
+	return textResult(writeToolJSON(scan)), nil, nil
+}
+
+func (m *Module) handleGetScanStatus(ctx context.Context, _ *sdkmcp.CallToolRequest, input getScanStatusInput) (*sdkmcp.CallToolResult, any, error) {
+	start := time.Now()
+	inputJSON := marshalInput(input)
+	m.publishToolCall("get_scan_status", input)
+
+	if m.scanActuator == nil {
+		m.auditToolCall(ctx, "get_scan_status", inputJSON, "http", start, false, "scan actuator not available")
+		return textResult("Scanning not available. The recon module may not be loaded."), nil, nil
+	}
+
+	scan, err := m.scanActuator.GetScan(context.Background(), input.ScanID)
+	if err != nil {
+		msg := fmt.Sprintf("failed to get scan: %v", err)
+		m.auditToolCall(ctx, "get_scan_status", inputJSON, "http", start, false, msg)
+		return errorResult(msg), nil, nil
+	}
+	if scan == nil {
+		m.auditToolCall(ctx, "get_scan_status", inputJSON, "http", start, true, "")
+		return textResult(fmt.Sprintf("No scan found with ID %q", input.ScanID)), nil, nil
+	}
+
+	m.auditToolCall(ctx, "get_scan_status", inputJSON, "http", start, true, "")
+	return textResult(writeToolJSON(scan)), nil, nil
+}
+
+func (m *Module) handleAcknowledgeAlert(ctx context.Context, _ *sdkmcp.CallToolRequest, input alertIDInput) (*sdkmcp.CallToolResult, any, error) {
+	return m.changeAlertState(ctx, "acknowledge_alert", input, func(a AlertActuator) (*AlertState, error) {
+		return a.AcknowledgeAlert(context.Background(), input.AlertID)
+	})
+}
+
+func (m *Module) handleResolveAlert(ctx context.Context, _ *sdkmcp.CallToolRequest, input alertIDInput) (*sdkmcp.CallToolResult, any, error) {
+	return m.changeAlertState(ctx, "resolve_alert", input, func(a AlertActuator) (*AlertState, error) {
+		return a.ResolveAlert(context.Background(), input.AlertID)
+	})
+}
+
+// changeAlertState runs an alert state change and formats the tool result.
+func (m *Module) changeAlertState(ctx context.Context, toolName string, input alertIDInput, change func(AlertActuator) (*AlertState, error)) (*sdkmcp.CallToolResult, any, error) {
+	start := time.Now()
+	inputJSON := marshalInput(input)
+	m.publishToolCall(toolName, input)
+
+	if m.alertActuator == nil {
+		m.auditToolCall(ctx, toolName, inputJSON, "http", start, false, "alert actuator not available")
+		return textResult("Alert management not available. The pulse module may not be loaded."), nil, nil
+	}
+	if input.AlertID == "" {
+		m.auditToolCall(ctx, toolName, inputJSON, "http", start, false, "alert_id is required")
+		return errorResult("alert_id is required"), nil, nil
+	}
+
+	alert, err := change(m.alertActuator)
+	if err != nil {
+		msg := fmt.Sprintf("failed to update alert: %v", err)
+		m.auditToolCall(ctx, toolName, inputJSON, "http", start, false, msg)
+		return errorResult(msg), nil, nil
+	}
+	if alert == nil {
+		m.auditToolCall(ctx, toolName, inputJSON, "http", start, true, "")
+		return textResult(fmt.Sprintf("No alert found with ID %q", input.AlertID)), nil, nil
+	}
+
+	m.auditToolCall(ctx, toolName, inputJSON, "http", start, true, "")
+	return textResult(writeToolJSON(alert)), nil, nil
+}
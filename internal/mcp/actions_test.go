@@ -0,0 +1,197 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+// mockScanActuator implements ScanActuator for testing.
+type mockScanActuator struct {
+	scans map[string]*models.ScanResult
+}
+
+func (a *mockScanActuator) StartScan(_ context.Context, subnet string) (*models.ScanResult, error) {
+	if subnet == "" {
+		return nil, errors.New("invalid subnet: subnet is required")
+	}
+	scan := &models.ScanResult{ID: "scan-1", Subnet: subnet, Status: "running"}
+	a.scans[scan.ID] = scan
+	return scan, nil
+}
+
+func (a *mockScanActuator) GetScan(_ context.Context, id string) (*models.ScanResult, error) {
+	return a.scans[id], nil
+}
+
+// mockAlertActuator implements AlertActuator for testing.
+type mockAlertActuator struct {
+	alerts map[string]*AlertState
+}
+
+func (a *mockAlertActuator) AcknowledgeAlert(_ context.Context, id string) (*AlertState, error) {
+	alert, ok := a.alerts[id]
+	if !ok {
+		return nil, nil
+	}
+	now := time.Now().UTC()
+	alert.AcknowledgedAt = &now
+	return alert, nil
+}
+
+func (a *mockAlertActuator) ResolveAlert(_ context.Context, id string) (*AlertState, error) {
+	alert, ok := a.alerts[id]
+	if !ok {
+		return nil, nil
+	}
+	now := time.Now().UTC()
+	alert.ResolvedAt = &now
+	return alert, nil
+}
+
+// listToolNames connects an in-memory client to the module's server and
+// returns the advertised tool names, sorted.
+func listToolNames(t *testing.T, m *Module) []string {
+	t.Helper()
+	ctx := context.Background()
+	serverTransport, clientTransport := sdkmcp.NewInMemoryTransports()
+	if _, err := m.server.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	client := sdkmcp.NewClient(&sdkmcp.Implementation{Name: "test", Version: "0.0.1"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer session.Close()
+
+	res, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools: %v", err)
+	}
+	names := make([]string, 0, len(res.Tools))
+	for _, tool := range res.Tools {
+		names = append(names, tool.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func containsTool(names []string, name string) bool {
+	i := sort.SearchStrings(names, name)
+	return i < len(names) && names[i] == name
+}
+
+func TestScanTools(t *testing.T) {
+	m := newTestModule(t)
+	m.SetScanActuator(&mockScanActuator{scans: map[string]*models.ScanResult{}})
+
+	result, _, err := m.handleScanSubnet(context.Background(), nil, scanSubnetInput{Subnet: "10.0.0.0/24"})
+	if err != nil {
+		t.Fatalf("handleScanSubnet: %v", err)
+	}
+	check := toCheck(result)
+	if check.isError {
+		t.Fatalf("scan_subnet returned error: %s", check.text)
+	}
+	var scan models.ScanResult
+	if err := json.Unmarshal([]byte(check.text), &scan); err != nil {
+		t.Fatalf("unmarshal scan: %v", err)
+	}
+	if scan.ID != "scan-1" || scan.Status != "running" {
+		t.Errorf("scan = %+v, want scan-1 running", scan)
+	}
+
+	result, _, _ = m.handleGetScanStatus(context.Background(), nil, getScanStatusInput{ScanID: "scan-1"})
+	if check := toCheck(result); check.isError || !json.Valid([]byte(check.text)) {
+		t.Errorf("get_scan_status = %+v, want scan JSON", check)
+	}
+
+	result, _, _ = m.handleGetScanStatus(context.Background(), nil, getScanStatusInput{ScanID: "missing"})
+	if check := toCheck(result); check.isError || json.Valid([]byte(check.text)) {
+		t.Errorf("get_scan_status(missing) = %+v, want not-found text", check)
+	}
+
+	result, _, _ = m.handleScanSubnet(context.Background(), nil, scanSubnetInput{})
+	if check := toCheck(result); !check.isError {
+		t.Errorf("scan_subnet with empty subnet should return an error result")
+	}
+}
+
+func TestScanTools_NoActuator(t *testing.T) {
+	m := newTestModule(t)
+
+	result, _, _ := m.handleScanSubnet(context.Background(), nil, scanSubnetInput{Subnet: "10.0.0.0/24"})
+	if check := toCheck(result); check.isError || check.text == "" {
+		t.Errorf("scan_subnet without actuator = %+v, want informational text", check)
+	}
+}
+
+func TestActionTools_ReadOnly(t *testing.T) {
+	m := newTestModule(t)
+	if names := listToolNames(t, m); !containsTool(names, "scan_subnet") {
+		t.Errorf("tools = %v, want scan_subnet when writable", names)
+	}
+
+	for _, name := range []string{"acknowledge_alert", "resolve_alert"} {
+		if !containsTool(listToolNames(t, m), name) {
+			t.Errorf("missing %s when writable", name)
+		}
+	}
+
+	ro := New()
+	ro.logger = m.logger
+	ro.SetReadOnly(true)
+	if err := ro.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	names := listToolNames(t, ro)
+	if containsTool(names, "scan_subnet") || containsTool(names, "get_scan_status") ||
+		containsTool(names, "acknowledge_alert") || containsTool(names, "resolve_alert") {
+		t.Errorf("tools = %v, want no action tools in read-only mode", names)
+	}
+	if !containsTool(names, "get_device") {
+		t.Errorf("tools = %v, want read tools in read-only mode", names)
+	}
+}
+
+func TestAlertTools(t *testing.T) {
+	m := newTestModule(t)
+	m.SetAlertActuator(&mockAlertActuator{alerts: map[string]*AlertState{
+		"alert-1": {ID: "alert-1", DeviceID: "dev-001", Severity: "critical", Message: "down"},
+	}})
+
+	result, _, _ := m.handleAcknowledgeAlert(context.Background(), nil, alertIDInput{AlertID: "alert-1"})
+	var alert AlertState
+	if err := json.Unmarshal([]byte(toCheck(result).text), &alert); err != nil {
+		t.Fatalf("unmarshal alert: %v", err)
+	}
+	if alert.AcknowledgedAt == nil || alert.ResolvedAt != nil {
+		t.Errorf("after acknowledge = %+v, want acknowledged and unresolved", alert)
+	}
+
+	result, _, _ = m.handleResolveAlert(context.Background(), nil, alertIDInput{AlertID: "alert-1"})
+	if err := json.Unmarshal([]byte(toCheck(result).text), &alert); err != nil {
+		t.Fatalf("unmarshal alert: %v", err)
+	}
+	if alert.ResolvedAt == nil {
+		t.Errorf("after resolve = %+v, want resolved", alert)
+	}
+
+	result, _, _ = m.handleResolveAlert(context.Background(), nil, alertIDInput{AlertID: "missing"})
+	if check := toCheck(result); check.isError || json.Valid([]byte(check.text)) {
+		t.Errorf("resolve_alert(missing) = %+v, want not-found text", check)
+	}
+
+	result, _, _ = m.handleAcknowledgeAlert(context.Background(), nil, alertIDInput{})
+	if check := toCheck(result); !check.isError {
+		t.Errorf("acknowledge_alert without alert_id should return an error result")
+	}
+}
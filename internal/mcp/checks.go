@@ -0,0 +1,151 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// CheckSpec describes a monitoring check to create. Zero values take the
+// same defaults as the pulse REST API.
+type CheckSpec struct {
+	DeviceID        string
+	CheckType       string
+	Target          string
+	IntervalSeconds int
+	AgentID         string
+}
+
+// CheckState is the MCP view of a monitoring check.
+type CheckState struct {
+	ID              string    `json:"id"`
+	DeviceID        string    `json:"device_id"`
+	DeviceName      string    `json:"device_name,omitempty"`
+	CheckType       string    `json:"check_type"`
+	Target          string    `json:"target"`
+	IntervalSeconds int       `json:"interval_seconds"`
+	AgentID         string    `json:"agent_id,omitempty"`
+	Enabled         bool      `json:"enabled"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// CheckActuator creates, lists, and deletes monitoring checks for the MCP
+// module. Implemented by the pulse module; wired via composition root
+// adapter. CreateCheck applies the same validation as the pulse REST API.
+// DeleteCheck reports false when the check does not exist.
+type CheckActuator interface {
+	CreateCheck(ctx context.Context, spec CheckSpec) (*CheckState, error)
+	ListChecks(ctx context.Context, deviceID string) ([]CheckState, error)
+	DeleteCheck(ctx context.Context, id string) (bool, error)
+}
+
+// SetCheckActuator injects the check actuator. Called from the composition
+// root (main.go) to wire the pulse module without cross-internal imports.
+func (m *Module) SetCheckActuator(a CheckActuator) {
+	m.checkActuator = a
+}
+
+type createCheckInput struct {
+	DeviceID        string `json:"device_id" jsonschema:"The device to monitor"`
+	CheckType       string `json:"check_type" jsonschema:"Check type: icmp (ping), tcp (port connect), or http"`
+	Target          string `json:"target" jsonschema:"What to probe: an IP or hostname for icmp, host:port for tcp, or an http(s) URL for http"`
+	IntervalSeconds int    `json:"interval_seconds,omitempty" jsonschema:"Seconds between checks (default 30)"`
+	AgentID         string `json:"agent_id,omitempty" jsonschema:"Run the check from this Scout agent instead of the server"`
+}
+
+type listChecksInput struct {
+	DeviceID string `json:"device_id,omitempty" jsonschema:"Only list checks for this device"`
+}
+
+type checkIDInput struct {
+	CheckID string `json:"check_id" jsonschema:"The unique check identifier"`
+}
+
+func (m *Module) handleCreateCheck(ctx context.Context, _ *sdkmcp.CallToolRequest, input createCheckInput) (*sdkmcp.CallToolResult, any, error) {
+	start := time.Now()
+	inputJSON := marshalInput(input)
+	m.publishToolCall("create_check", input)
+
+	if m.checkActuator == nil {
+		m.auditToolCall(ctx, "create_check", inputJSON, "http", start, false, "check actuator not available")
+		return textResult("Check management not available. The pulse module may not be loaded."), nil, nil
+	}
+
+	check, err := m.checkActuator.CreateCheck(context.Background(), CheckSpec{
+		DeviceID:        input.DeviceID,
+		CheckType:       input.CheckType,
+		Target:          input.Target,
+		IntervalSeconds: input.IntervalSeconds,
+		AgentID:         input.AgentID,
+	})
+	if err != nil {
+		msg := fmt.Sprintf("failed to create check: %v", err)
+		m.auditToolCall(ctx, "create_check", inputJSON, "http", start, false, msg)
+		return errorResult(msg), nil, nil
+	}
+
+	m.auditToolCall(ctx, "create_check", inputJSON, "http", start, true, "")
+	return textResult(writeToolJSON(check)), nil, nil
+}
+
+func (m *Module) handleListChecks(ctx context.Context, _ *sdkmcp.CallToolRequest, input listChecksInput) (*sdkmcp.CallToolResult, any, error) {
+	start := time.Now()
+	inputJSON := marshalInput(input)
+	m.publishToolCall("list_checks", input)
+
+	if m.checkActuator == nil {
+		m.auditToolCall(ctx, "list_checks", inputJSON, "http", start, false, "check actuator not available")
+		return textResult("Check management not available. The pulse module may not be loaded."), nil, nil
+	}
+
+	checks, err := m.checkActuator.ListChecks(context.Background(), input.DeviceID)
+	if err != nil {
+		msg := fmt.Sprintf("failed to list checks: %v", err)
+		m.auditToolCall(ctx, "list_checks", inputJSON, "http", start, false, msg)
+		return errorResult(msg), nil, nil
+	}
+	if checks == nil {
+		checks = []CheckState{}
+	}
+
+	resp := struct {
+		Checks []CheckState `json:"checks"`
+		Count  int          `json:"count"`
+	}{
+		Checks: checks,
+		Count:  len(checks),
+	}
+
+	m.auditToolCall(ctx, "list_checks", inputJSON, "http", start, true, "")
+	return textResult(writeToolJSON(resp)), nil, nil
+}
+
+func (m *Module) handleDeleteCheck(ctx context.Context, _ *sdkmcp.CallToolRequest, input checkIDInput) (*sdkmcp.CallToolResult, any, error) {
+	start := time.Now()
+	inputJSON := marshalInput(input)
+	m.publishToolCall("delete_check", input)
+
+	if m.checkActuator == nil {
+		m.auditToolCall(ctx, "delete_check", inputJSON, "http", start, false, "check actuator not available")
+		return textResult("Check management not available. The pulse module may not be loaded."), nil, nil
+	}
+	if input.CheckID == "" {
+		m.auditToolCall(ctx, "delete_check", inputJSON, "http", start, false, "check_id is required")
+		return errorResult("check_id is required"), nil, nil
+	}
+
+	deleted, err := m.checkActuator.DeleteCheck(context.Background(), input.CheckID)
+	if err != nil {
+		msg := fmt.Sprintf("failed to delete check: %v", err)
+		m.auditToolCall(ctx, "delete_check", inputJSON, "http", start, false, msg)
+		return errorResult(msg), nil, nil
+	}
+
+	m.auditToolCall(ctx, "delete_check", inputJSON, "http", start, true, "")
+	if !deleted {
+		return textResult(fmt.Sprintf("No check found with ID %q", input.CheckID)), nil, nil
+	}
+	return textResult(fmt.Sprintf("Deleted check %q", input.CheckID)), nil, nil
+}
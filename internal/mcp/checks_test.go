@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// mockCheckActuator implements CheckActuator for testing.
+type mockCheckActuator struct {
+	checks []CheckState
+}
+
+func (a *mockCheckActuator) CreateCheck(_ context.Context, spec CheckSpec) (*CheckState, error) {
+	if spec.Target == "" {
+		return nil, errors.New("invalid check: target is required")
+	}
+	interval := spec.IntervalSeconds
+	if interval == 0 {
+		interval = 30
+	}
+	check := CheckState{
+		ID:              "check-1",
+		DeviceID:        spec.DeviceID,
+		CheckType:       spec.CheckType,
+		Target:          spec.Target,
+		IntervalSeconds: interval,
+		Enabled:         true,
+	}
+	a.checks = append(a.checks, check)
+	return &check, nil
+}
+
+func (a *mockCheckActuator) ListChecks(_ context.Context, deviceID string) ([]CheckState, error) {
+	var out []CheckState
+	for _, c := range a.checks {
+		if deviceID == "" || c.DeviceID == deviceID {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (a *mockCheckActuator) DeleteCheck(_ context.Context, id string) (bool, error) {
+	for i, c := range a.checks {
+		if c.ID == id {
+			a.checks = append(a.checks[:i], a.checks[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestCheckTools(t *testing.T) {
+	m := newTestModule(t)
+	m.SetCheckActuator(&mockCheckActuator{})
+
+	result, _, _ := m.handleCreateCheck(context.Background(), nil, createCheckInput{
+		DeviceID: "dev-001", CheckType: "tcp", Target: "10.0.0.5:22",
+	})
+	var created CheckState
+	if err := json.Unmarshal([]byte(toCheck(result).text), &created); err != nil {
+		t.Fatalf("unmarshal check: %v", err)
+	}
+	if created.ID != "check-1" || created.IntervalSeconds != 30 || !created.Enabled {
+		t.Errorf("created = %+v", created)
+	}
+
+	result, _, _ = m.handleCreateCheck(context.Background(), nil, createCheckInput{DeviceID: "dev-001", CheckType: "icmp"})
+	if check := toCheck(result); !check.isError || !strings.Contains(check.text, "target is required") {
+		t.Errorf("create without target = %+v, want validation error", check)
+	}
+
+	result, _, _ = m.handleListChecks(context.Background(), nil, listChecksInput{DeviceID: "dev-001"})
+	if check := toCheck(result); check.isError || !strings.Contains(check.text, `"count":1`) {
+		t.Errorf("list_checks = %+v, want one check", check)
+	}
+	result, _, _ = m.handleListChecks(context.Background(), nil, listChecksInput{DeviceID: "dev-999"})
+	if check := toCheck(result); check.isError || !strings.Contains(check.text, `"checks":[]`) {
+		t.Errorf("list_checks(other) = %+v, want empty list", check)
+	}
+
+	result, _, _ = m.handleDeleteCheck(context.Background(), nil, checkIDInput{CheckID: "check-1"})
+	if check := toCheck(result); check.isError || !strings.Contains(check.text, "Deleted") {
+		t.Errorf("delete_check = %+v", check)
+	}
+	result, _, _ = m.handleDeleteCheck(context.Background(), nil, checkIDInput{CheckID: "check-1"})
+	if check := toCheck(result); check.isError || !strings.Contains(check.text, "No check found") {
+		t.Errorf("delete_check(again) = %+v, want not-found text", check)
+	}
+}
+
+func TestCheckTools_NoActuator(t *testing.T) {
+	m := newTestModule(t)
+
+	result, _, _ := m.handleCreateCheck(context.Background(), nil, createCheckInput{Target: "10.0.0.5"})
+	if check := toCheck(result); check.isError || !strings.Contains(check.text, "not available") {
+		t.Errorf("create_check without actuator = %+v, want unavailable text", check)
+	}
+}
+
+func TestCheckTools_ReadOnly(t *testing.T) {
+	ro := New()
+	ro.logger = newTestModule(t).logger
+	ro.SetReadOnly(true)
+	if err := ro.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	names := listToolNames(t, ro)
+	if containsTool(names, "create_check") || containsTool(names, "delete_check") {
+		t.Errorf("tools = %v, want no check mutation tools in read-only mode", names)
+	}
+	if !containsTool(names, "list_checks") {
+		t.Errorf("tools = %v, want list_checks in read-only mode", names)
+	}
+}
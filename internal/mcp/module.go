@@ -46,6 +46,11 @@ type Module struct {
 	bus            plugin.EventBus
 	querier        DeviceQuerier
 	serviceQuerier ServiceQuerier
+	topology       TopologyReader
+	scanActuator   ScanActuator
+	alertActuator  AlertActuator
+	checkActuator  CheckActuator
+	readOnly       bool
 	server         *sdkmcp.Server
 	apiKey         string
 	auditStore     *AuditStore
@@ -72,6 +77,9 @@ func (m *Module) Init(_ context.Context, deps plugin.Dependencies) error {
 
 	if deps.Config != nil {
 		m.apiKey = deps.Config.GetString("api_key")
+		if deps.Config.GetBool("read_only") {
+			m.readOnly = true
+		}
 	}
 
 	if deps.Store != nil {
@@ -107,6 +115,7 @@ func (m *Module) Start(_ context.Context) error {
 	)
 
 	m.registerTools()
+	m.registerActionTools()
 
 	m.logger.Info("mcp module started")
 	return nil
@@ -96,6 +96,16 @@ func (m *Module) registerTools() {
 		Name:        "get_service_inventory",
 		Description: "Get tracked services (Docker containers, systemd services, Windows services, applications) optionally filtered by device, type, or status.",
 	}, m.handleGetServiceInventory)
+
+	sdkmcp.AddTool(m.server, &sdkmcp.Tool{
+		Name:        "get_topology",
+		Description: "Get the network topology: devices as nodes labelled with their hierarchy layer (gateway, distribution, access, endpoint) and parent device, plus discovered links between devices as edges. Pass device_id to also get the path of upstream devices from it to the gateway, e.g. to answer what sits between a laptop and the internet.",
+	}, m.handleGetTopology)
+
+	sdkmcp.AddTool(m.server, &sdkmcp.Tool{
+		Name:        "list_checks",
+		Description: "List monitoring checks (ping, TCP port, HTTP) with their targets, intervals, and whether they are enabled, optionally for one device.",
+	}, m.handleListChecks)
 }
 
 func (m *Module) handleGetDevice(ctx context.Context, _ *sdkmcp.CallToolRequest, input getDeviceInput) (*sdkmcp.CallToolResult, any, error) {
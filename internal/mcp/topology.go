@@ -0,0 +1,182 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+// TopologyNode is the MCP view of a device in the network hierarchy.
+type TopologyNode struct {
+	ID             string
+	Hostname       string
+	DeviceType     string
+	Status         string
+	IPAddresses    []string
+	ParentDeviceID string
+	NetworkLayer   int
+}
+
+// TopologyLink is the MCP view of a discovered link between two devices.
+type TopologyLink struct {
+	SourceDeviceID string
+	TargetDeviceID string
+	SourcePort     string
+	TargetPort     string
+	LinkType       string
+	Speed          int
+}
+
+// TopologyReader abstracts topology data access for the MCP module.
+// Implemented by the recon store; wired via composition root adapter.
+type TopologyReader interface {
+	GetDeviceTree(ctx context.Context) ([]TopologyNode, error)
+	GetTopologyLinks(ctx context.Context) ([]TopologyLink, error)
+}
+
+// SetTopologyReader injects the topology reader. Called from the composition
+// root (main.go) to wire the recon store without cross-internal imports.
+func (m *Module) SetTopologyReader(r TopologyReader) {
+	m.topology = r
+}
+
+type getTopologyInput struct {
+	DeviceID string `json:"device_id,omitempty" jsonschema:"Optional device ID; adds the chain of upstream devices from it to the gateway"`
+}
+
+// networkLayerNames labels the hierarchy levels assigned by recon.
+var networkLayerNames = map[int]string{
+	models.NetworkLayerUnknown:      "unknown",
+	models.NetworkLayerGateway:      "gateway",
+	models.NetworkLayerDistribution: "distribution",
+	models.NetworkLayerAccess:       "access",
+	models.NetworkLayerEndpoint:     "endpoint",
+}
+
+// topologyNodeView is the compact node form returned to the model.
+type topologyNodeView struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Type   string `json:"type,omitempty"`
+	IP     string `json:"ip,omitempty"`
+	Status string `json:"status,omitempty"`
+	Layer  string `json:"layer"`
+	Parent string `json:"parent,omitempty"`
+}
+
+// topologyEdgeView is the compact edge form returned to the model.
+type topologyEdgeView struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	FromPort string `json:"from_port,omitempty"`
+	ToPort   string `json:"to_port,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Speed    int    `json:"speed,omitempty"`
+}
+
+// topologyView is the get_topology response.
+type topologyView struct {
+	Layers []string           `json:"layers"`
+	Nodes  []topologyNodeView `json:"nodes"`
+	Edges  []topologyEdgeView `json:"edges"`
+	Path   []string           `json:"path,omitempty"`
+}
+
+// buildTopologyView converts the device tree and links into the compact
+// response. When deviceID is set, Path lists the device and its upstream
+// parents up to the top of the hierarchy.
+func buildTopologyView(nodes []TopologyNode, links []TopologyLink, deviceID string) (*topologyView, error) {
+	view := &topologyView{
+		Layers: []string{"gateway", "distribution", "access", "endpoint", "unknown"},
+		Nodes:  make([]topologyNodeView, 0, len(nodes)),
+		Edges:  make([]topologyEdgeView, 0, len(links)),
+	}
+
+	parents := make(map[string]string, len(nodes))
+	for i := range nodes {
+		n := &nodes[i]
+		name := n.Hostname
+		if name == "" && len(n.IPAddresses) > 0 {
+			name = n.IPAddresses[0]
+		}
+		layer, ok := networkLayerNames[n.NetworkLayer]
+		if !ok {
+			layer = "unknown"
+		}
+		nv := topologyNodeView{
+			ID:     n.ID,
+			Name:   name,
+			Type:   n.DeviceType,
+			Status: n.Status,
+			Layer:  layer,
+			Parent: n.ParentDeviceID,
+		}
+		if len(n.IPAddresses) > 0 {
+			nv.IP = n.IPAddresses[0]
+		}
+		view.Nodes = append(view.Nodes, nv)
+		parents[n.ID] = n.ParentDeviceID
+	}
+
+	for i := range links {
+		l := &links[i]
+		view.Edges = append(view.Edges, topologyEdgeView{
+			From:     l.SourceDeviceID,
+			To:       l.TargetDeviceID,
+			FromPort: l.SourcePort,
+			ToPort:   l.TargetPort,
+			Type:     l.LinkType,
+			Speed:    l.Speed,
+		})
+	}
+
+	if deviceID != "" {
+		if _, ok := parents[deviceID]; !ok {
+			return nil, fmt.Errorf("no device found with ID %q", deviceID)
+		}
+		// Follow parents upwards; the seen set guards against cycles.
+		seen := make(map[string]bool)
+		for id := deviceID; id != "" && !seen[id]; id = parents[id] {
+			seen[id] = true
+			view.Path = append(view.Path, id)
+		}
+	}
+	return view, nil
+}
+
+func (m *Module) handleGetTopology(ctx context.Context, _ *sdkmcp.CallToolRequest, input getTopologyInput) (*sdkmcp.CallToolResult, any, error) {
+	start := time.Now()
+	inputJSON := marshalInput(input)
+	m.publishToolCall("get_topology", input)
+
+	if m.topology == nil {
+		m.auditToolCall(ctx, "get_topology", inputJSON, "http", start, false, "topology reader not available")
+		return textResult("Topology data not available. The recon module may not be loaded."), nil, nil
+	}
+
+	nodes, err := m.topology.GetDeviceTree(context.Background())
+	if err != nil {
+		msg := fmt.Sprintf("failed to get device tree: %v", err)
+		m.auditToolCall(ctx, "get_topology", inputJSON, "http", start, false, msg)
+		return errorResult(msg), nil, nil
+	}
+	links, err := m.topology.GetTopologyLinks(context.Background())
+	if err != nil {
+		msg := fmt.Sprintf("failed to get topology links: %v", err)
+		m.auditToolCall(ctx, "get_topology", inputJSON, "http", start, false, msg)
+		return errorResult(msg), nil, nil
+	}
+
+	view, err := buildTopologyView(nodes, links, input.DeviceID)
+	if err != nil {
+		m.auditToolCall(ctx, "get_topology", inputJSON, "http", start, true, "")
+		return textResult(err.Error()), nil, nil
+	}
+
+	m.auditToolCall(ctx, "get_topology", inputJSON, "http", start, true, "")
+	return textResult(writeToolJSON(view)), nil, nil
+}
@@ -0,0 +1,112 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+// mockTopologyReader implements TopologyReader for testing.
+type mockTopologyReader struct {
+	nodes []TopologyNode
+	links []TopologyLink
+	err   error
+}
+
+func (r *mockTopologyReader) GetDeviceTree(_ context.Context) ([]TopologyNode, error) {
+	return r.nodes, r.err
+}
+
+func (r *mockTopologyReader) GetTopologyLinks(_ context.Context) ([]TopologyLink, error) {
+	return r.links, r.err
+}
+
+func newMockTopologyReader() *mockTopologyReader {
+	return &mockTopologyReader{
+		nodes: []TopologyNode{
+			{ID: "router", Hostname: "edge-router", DeviceType: "router", IPAddresses: []string{"10.0.0.1"}, NetworkLayer: models.NetworkLayerGateway},
+			{ID: "switch", Hostname: "core-switch", DeviceType: "switch", ParentDeviceID: "router", NetworkLayer: models.NetworkLayerDistribution},
+			{ID: "laptop", IPAddresses: []string{"10.0.0.50"}, ParentDeviceID: "switch", NetworkLayer: models.NetworkLayerEndpoint},
+		},
+		links: []TopologyLink{
+			{SourceDeviceID: "switch", TargetDeviceID: "router", SourcePort: "Gi0/1", TargetPort: "eth1", LinkType: "lldp", Speed: 1000},
+		},
+	}
+}
+
+func TestGetTopology(t *testing.T) {
+	m := newTestModule(t)
+	m.SetTopologyReader(newMockTopologyReader())
+
+	result, _, err := m.handleGetTopology(context.Background(), nil, getTopologyInput{DeviceID: "laptop"})
+	if err != nil {
+		t.Fatalf("handleGetTopology: %v", err)
+	}
+	check := toCheck(result)
+	if check.isError {
+		t.Fatalf("get_topology returned error: %s", check.text)
+	}
+
+	var view topologyView
+	if err := json.Unmarshal([]byte(check.text), &view); err != nil {
+		t.Fatalf("unmarshal topology: %v", err)
+	}
+	if len(view.Nodes) != 3 || len(view.Edges) != 1 {
+		t.Fatalf("got %d nodes, %d edges; want 3, 1", len(view.Nodes), len(view.Edges))
+	}
+	if n := view.Nodes[0]; n.Layer != "gateway" || n.Name != "edge-router" || n.IP != "10.0.0.1" {
+		t.Errorf("router node = %+v", n)
+	}
+	if n := view.Nodes[2]; n.Name != "10.0.0.50" || n.Layer != "endpoint" || n.Parent != "switch" {
+		t.Errorf("laptop node = %+v, want named by IP", n)
+	}
+	if e := view.Edges[0]; e.From != "switch" || e.To != "router" || e.FromPort != "Gi0/1" {
+		t.Errorf("edge = %+v", e)
+	}
+	if got := strings.Join(view.Path, ">"); got != "laptop>switch>router" {
+		t.Errorf("path = %q, want laptop>switch>router", got)
+	}
+
+	// Without a device the path is omitted.
+	result, _, _ = m.handleGetTopology(context.Background(), nil, getTopologyInput{})
+	if check := toCheck(result); check.isError || strings.Contains(check.text, `"path"`) {
+		t.Errorf("get_topology() = %+v, want no path", check)
+	}
+
+	result, _, _ = m.handleGetTopology(context.Background(), nil, getTopologyInput{DeviceID: "missing"})
+	if check := toCheck(result); check.isError || !strings.Contains(check.text, "no device found") {
+		t.Errorf("get_topology(missing) = %+v, want not-found text", check)
+	}
+}
+
+func TestGetTopology_Errors(t *testing.T) {
+	m := newTestModule(t)
+
+	result, _, _ := m.handleGetTopology(context.Background(), nil, getTopologyInput{})
+	if check := toCheck(result); check.isError || !strings.Contains(check.text, "not available") {
+		t.Errorf("without reader = %+v, want unavailable text", check)
+	}
+
+	m.SetTopologyReader(&mockTopologyReader{err: errors.New("db closed")})
+	result, _, _ = m.handleGetTopology(context.Background(), nil, getTopologyInput{})
+	if check := toCheck(result); !check.isError {
+		t.Errorf("reader error = %+v, want error result", check)
+	}
+}
+
+func TestBuildTopologyView_ParentCycle(t *testing.T) {
+	view, err := buildTopologyView([]TopologyNode{
+		{ID: "a", ParentDeviceID: "b"},
+		{ID: "b", ParentDeviceID: "a"},
+	}, nil, "a")
+	if err != nil {
+		t.Fatalf("buildTopologyView: %v", err)
+	}
+	if len(view.Path) != 2 {
+		t.Errorf("path = %v, want cycle cut after 2 nodes", view.Path)
+	}
+}
@@ -14,6 +14,24 @@ type Config struct {
 	UseTLS      bool          `mapstructure:"use_tls"`
 	Timeout     time.Duration `mapstructure:"timeout"`
 
+	// PasswordCredentialID names a vault credential (http_basic or
+	// ssh_password) whose password, and username when Username is empty,
+	// authenticate to the broker. Takes precedence over Password.
+	PasswordCredentialID string `mapstructure:"password_credential_id"` //nolint:gosec // G101: vault credential ID, not a credential
+
+	// TLS settings. Setting any certificate implies UseTLS. CACert verifies
+	// the broker (system roots when empty); ClientCert and ClientKey enable
+	// mutual TLS and must be set together.
+	CACert     string `mapstructure:"ca_cert"`     // PEM CA bundle for the broker certificate
+	ClientCert string `mapstructure:"client_cert"` // PEM client certificate
+	ClientKey  string `mapstructure:"client_key"`  // PEM client private key
+
+	// Device status topic settings. StatusTopic is a template expanded with
+	// {prefix} (TopicPrefix) and {id} (device ID); empty disables status
+	// publishing.
+	StatusTopic string `mapstructure:"status_topic"` // Retained device status topic (default: "{prefix}/devices/{id}/status")
+	StatusQoS   byte   `mapstructure:"status_qos"`   // QoS for status messages (default: 1)
+
 	// Home Assistant MQTT auto-discovery settings.
 	HADiscovery       bool   `mapstructure:"ha_discovery"`        // Enable HA auto-discovery (default: false)
 	HADiscoveryPrefix string `mapstructure:"ha_discovery_prefix"` // HA discovery topic prefix (default: "homeassistant")
@@ -28,6 +46,8 @@ func DefaultConfig() Config {
 		QoS:               1,
 		Retain:            false,
 		Timeout:           10 * time.Second,
+		StatusTopic:       "{prefix}/devices/{id}/status",
+		StatusQoS:         1,
 		HADiscovery:       false,
 		HADiscoveryPrefix: "homeassistant",
 	}
@@ -2,8 +2,10 @@ package mqtt
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"sync"
+	"time"
 
 	"github.com/HerbHall/subnetree/internal/pulse"
 	"github.com/HerbHall/subnetree/internal/recon"
@@ -30,6 +32,28 @@ type Module struct {
 	mu        sync.RWMutex
 	haEnabled bool
 	haPrefix  string
+	tlsConfig *tls.Config
+	configErr error // TLS misconfiguration found in Init; blocks connecting
+	decrypter CredentialDecrypter
+	devices   DeviceLister
+
+	statusMu sync.Mutex
+	statuses map[string]DeviceStatusMessage // last published status per device
+}
+
+// CredentialDecrypter retrieves decrypted credential data from the vault.
+// Implemented by the vault module; wired via composition root adapter.
+type CredentialDecrypter interface {
+	DecryptCredential(ctx context.Context, id string) (map[string]any, error)
+}
+
+// SetCredentialDecrypter injects the vault decrypter used to resolve the
+// broker password from plugins.mqtt.password_credential_id. Called from the
+// composition root.
+func (m *Module) SetCredentialDecrypter(d CredentialDecrypter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.decrypter = d
 }
 
 // New creates a new MQTT publisher plugin instance.
@@ -61,6 +85,9 @@ func (m *Module) Init(_ context.Context, deps plugin.Dependencies) error {
 		if p := deps.Config.GetString("password"); p != "" {
 			m.cfg.Password = p
 		}
+		if id := deps.Config.GetString("password_credential_id"); id != "" {
+			m.cfg.PasswordCredentialID = id
+		}
 		if c := deps.Config.GetString("client_id"); c != "" {
 			m.cfg.ClientID = c
 		}
@@ -79,6 +106,21 @@ func (m *Module) Init(_ context.Context, deps plugin.Dependencies) error {
 		if d := deps.Config.GetDuration("timeout"); d > 0 {
 			m.cfg.Timeout = d
 		}
+		if p := deps.Config.GetString("ca_cert"); p != "" {
+			m.cfg.CACert = p
+		}
+		if p := deps.Config.GetString("client_cert"); p != "" {
+			m.cfg.ClientCert = p
+		}
+		if p := deps.Config.GetString("client_key"); p != "" {
+			m.cfg.ClientKey = p
+		}
+		if deps.Config.IsSet("status_topic") {
+			m.cfg.StatusTopic = deps.Config.GetString("status_topic")
+		}
+		if deps.Config.IsSet("status_qos") {
+			m.cfg.StatusQoS = byte(deps.Config.GetInt("status_qos"))
+		}
 		if deps.Config.IsSet("ha_discovery") {
 			m.cfg.HADiscovery = deps.Config.GetBool("ha_discovery")
 		}
@@ -94,6 +136,16 @@ func (m *Module) Init(_ context.Context, deps plugin.Dependencies) error {
 		m.logger.Warn("MQTT broker URL not configured; events will be dropped",
 			zap.String("component", "mqtt"),
 		)
+	} else {
+		// Load certificates up front so a bad path is reported at startup
+		// rather than as an opaque handshake failure on every reconnect.
+		m.tlsConfig, m.configErr = buildTLSConfig(&m.cfg)
+		if m.configErr != nil {
+			m.logger.Error("invalid MQTT TLS configuration; broker connection disabled",
+				zap.String("component", "mqtt"),
+				zap.Error(m.configErr),
+			)
+		}
 	}
 
 	m.logger.Info("mqtt module initialized",
@@ -101,6 +153,9 @@ func (m *Module) Init(_ context.Context, deps plugin.Dependencies) error {
 		zap.String("client_id", m.cfg.ClientID),
 		zap.String("topic_prefix", m.cfg.TopicPrefix),
 		zap.Uint8("qos", m.cfg.QoS),
+		zap.Bool("tls", m.cfg.tlsEnabled()),
+		zap.Bool("vault_password", m.cfg.PasswordCredentialID != ""),
+		zap.String("status_topic", m.cfg.StatusTopic),
 		zap.Bool("ha_discovery", m.haEnabled),
 	)
 	return nil
@@ -111,16 +166,32 @@ func (m *Module) Start(_ context.Context) error {
 		m.logger.Info("mqtt module started (no-op: no broker configured)")
 		return nil
 	}
+	if m.configErr != nil {
+		m.logger.Error("mqtt module started without a broker connection", zap.Error(m.configErr))
+		return nil
+	}
 
+	// ConnectRetry keeps retrying the initial connection, which also lets a
+	// vault-backed password resolve once the decrypter is wired after Start.
 	opts := pahomqtt.NewClientOptions().
-		AddBroker(m.cfg.BrokerURL).
+		AddBroker(brokerURL(&m.cfg)).
 		SetClientID(m.cfg.ClientID).
 		SetAutoReconnect(true).
-		SetConnectTimeout(m.cfg.Timeout)
-
-	if m.cfg.Username != "" {
-		opts.SetUsername(m.cfg.Username)
-		opts.SetPassword(m.cfg.Password) //nolint:gosec // G101: config field
+		SetConnectRetry(true).
+		SetConnectTimeout(m.cfg.Timeout).
+		SetOnConnectHandler(func(pahomqtt.Client) {
+			// Refresh retained device statuses on every (re)connect. Run
+			// asynchronously: publishing from the handler would block paho.
+			go m.publishStatusSnapshot()
+		})
+
+	if m.tlsConfig != nil {
+		opts.SetTLSConfig(m.tlsConfig)
+	}
+	if m.cfg.Username != "" || m.cfg.PasswordCredentialID != "" {
+		// Credentials are resolved on every (re)connect so a rotated vault
+		// password is picked up without a restart.
+		opts.SetCredentialsProvider(m.credentials)
 	}
 
 	m.client = pahomqtt.NewClient(opts)
@@ -141,6 +212,44 @@ func (m *Module) Start(_ context.Context) error {
 	return nil
 }
 
+// credentials returns the broker username and password. When
+// password_credential_id is set, the vault credential's password (and its
+// username if none is configured) replaces the static password.
+func (m *Module) credentials() (username, password string) {
+	username, password = m.cfg.Username, m.cfg.Password
+	if m.cfg.PasswordCredentialID == "" {
+		return username, password
+	}
+
+	m.mu.RLock()
+	decrypter := m.decrypter
+	m.mu.RUnlock()
+	if decrypter == nil {
+		m.logger.Warn("vault not available to resolve mqtt password credential",
+			zap.String("credential_id", m.cfg.PasswordCredentialID),
+		)
+		return username, password
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	data, err := decrypter.DecryptCredential(ctx, m.cfg.PasswordCredentialID)
+	if err != nil {
+		m.logger.Warn("failed to decrypt mqtt password credential",
+			zap.String("credential_id", m.cfg.PasswordCredentialID),
+			zap.Error(err),
+		)
+		return username, password
+	}
+	if p, ok := data["password"].(string); ok && p != "" {
+		password = p
+	}
+	if u, ok := data["username"].(string); ok && u != "" && username == "" {
+		username = u
+	}
+	return username, password
+}
+
 func (m *Module) Stop(_ context.Context) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -157,6 +266,7 @@ func (m *Module) Subscriptions() []plugin.Subscription {
 		{Topic: recon.TopicDeviceDiscovered, Handler: m.publishEvent},
 		{Topic: recon.TopicDeviceUpdated, Handler: m.publishEvent},
 		{Topic: recon.TopicDeviceLost, Handler: m.publishEvent},
+		{Topic: recon.TopicDeviceDeleted, Handler: m.publishEvent},
 		{Topic: "pulse.alert.triggered", Handler: m.publishEvent},
 		{Topic: "pulse.alert.resolved", Handler: m.publishEvent},
 	}
@@ -170,9 +280,16 @@ func (m *Module) Health(_ context.Context) plugin.HealthStatus {
 			Message: "no broker configured (no-op mode)",
 		}
 	}
+	if m.configErr != nil {
+		return plugin.HealthStatus{
+			Status:  "unhealthy",
+			Message: "invalid TLS configuration: " + m.configErr.Error(),
+		}
+	}
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	if m.client == nil || !m.client.IsConnected() {
+	// IsConnected also reports true while ConnectRetry is still connecting.
+	if m.client == nil || !m.client.IsConnectionOpen() {
 		return plugin.HealthStatus{
 			Status:  "degraded",
 			Message: "not connected to MQTT broker",
@@ -193,6 +310,8 @@ func (m *Module) mqttTopicFromEvent(eventTopic string) string {
 		return m.cfg.TopicPrefix + "/device/updated"
 	case recon.TopicDeviceLost:
 		return m.cfg.TopicPrefix + "/device/lost"
+	case recon.TopicDeviceDeleted:
+		return m.cfg.TopicPrefix + "/device/deleted"
 	case "pulse.alert.triggered":
 		return m.cfg.TopicPrefix + "/alert/triggered"
 	case "pulse.alert.resolved":
@@ -240,6 +359,8 @@ func (m *Module) publishEvent(_ context.Context, event plugin.Event) {
 		zap.String("event_topic", event.Topic),
 	)
 
+	m.publishStatusForEvent(event)
+
 	// Publish HA discovery configs if enabled.
 	if m.haEnabled {
 		m.publishHAForEvent(event)
@@ -263,10 +384,21 @@ func (m *Module) publishHAForEvent(event plugin.Event) {
 		if deviceID == "" {
 			return
 		}
-		// Publish offline state before removing discovery configs.
+		// Lost devices stay registered in HA and simply report offline.
 		m.publishState(m.cfg.TopicPrefix+"/device/"+deviceID+"/online", "OFF")
-		configs := BuildDeviceRemovalConfigs(deviceID, m.haPrefix)
-		m.publishHADiscovery(configs)
+
+	case recon.TopicDeviceDeleted:
+		deviceID := extractDeletedDeviceID(event.Payload)
+		if deviceID == "" {
+			return
+		}
+		// Empty retained configs remove the entities from HA; clearing the
+		// retained state topics keeps the broker from replaying stale values.
+		m.publishHADiscovery(BuildDeviceRemovalConfigs(deviceID, m.haPrefix))
+		prefix := m.cfg.TopicPrefix + "/device/" + deviceID
+		for _, suffix := range []string{"/online", "/type", "/ip"} {
+			m.publishState(prefix+suffix, "")
+		}
 
 	case "pulse.alert.triggered":
 		alert := extractAlert(event.Payload)
@@ -395,6 +527,26 @@ func extractDeviceID(payload interface{}) string {
 	}
 }
 
+// extractDeletedDeviceID attempts to extract a device ID from a device-deleted event payload.
+func extractDeletedDeviceID(payload interface{}) string {
+	switch v := payload.(type) {
+	case recon.DeviceDeletedEvent:
+		return v.DeviceID
+	case *recon.DeviceDeletedEvent:
+		return v.DeviceID
+	default:
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return ""
+		}
+		var dde recon.DeviceDeletedEvent
+		if err := json.Unmarshal(data, &dde); err != nil {
+			return ""
+		}
+		return dde.DeviceID
+	}
+}
+
 // extractAlert attempts to extract a *pulse.Alert from an event payload.
 func extractAlert(payload interface{}) *pulse.Alert {
 	switch v := payload.(type) {
@@ -2,12 +2,16 @@ package mqtt
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/HerbHall/subnetree/internal/recon"
 	"github.com/HerbHall/subnetree/pkg/plugin"
 	"github.com/HerbHall/subnetree/pkg/plugin/plugintest"
+	pahomqtt "github.com/eclipse/paho.mqtt.golang"
 	"go.uber.org/zap"
 )
 
@@ -43,8 +47,8 @@ func TestSubscriptions_ReturnsExpectedTopics(t *testing.T) {
 	}
 
 	subs := m.Subscriptions()
-	if len(subs) != 5 {
-		t.Fatalf("Subscriptions() returned %d, want 5", len(subs))
+	if len(subs) != 6 {
+		t.Fatalf("Subscriptions() returned %d, want 6", len(subs))
 	}
 
 	topics := make(map[string]bool)
@@ -56,6 +60,7 @@ func TestSubscriptions_ReturnsExpectedTopics(t *testing.T) {
 		recon.TopicDeviceDiscovered,
 		recon.TopicDeviceUpdated,
 		recon.TopicDeviceLost,
+		recon.TopicDeviceDeleted,
 		"pulse.alert.triggered",
 		"pulse.alert.resolved",
 	}
@@ -76,6 +81,7 @@ func TestMqttTopicFromEvent_MapsCorrectly(t *testing.T) {
 		{recon.TopicDeviceDiscovered, "subnetree/device/discovered"},
 		{recon.TopicDeviceUpdated, "subnetree/device/updated"},
 		{recon.TopicDeviceLost, "subnetree/device/lost"},
+		{recon.TopicDeviceDeleted, "subnetree/device/deleted"},
 		{"pulse.alert.triggered", "subnetree/alert/triggered"},
 		{"pulse.alert.resolved", "subnetree/alert/resolved"},
 		{"unknown.topic", "subnetree/unknown"},
@@ -151,6 +157,56 @@ func TestHealth_DegradedWhenNotConnected(t *testing.T) {
 	}
 }
 
+func TestHealth_UnhealthyOnTLSConfigError(t *testing.T) {
+	m := &Module{
+		logger:    zap.NewNop(),
+		cfg:       Config{BrokerURL: "ssl://localhost:8883"},
+		configErr: errors.New(`ca_cert file "/missing.pem" does not exist`),
+	}
+
+	status := m.Health(context.Background())
+	if status.Status != "unhealthy" || !strings.Contains(status.Message, "ca_cert") {
+		t.Errorf("Health() = %+v, want unhealthy naming ca_cert", status)
+	}
+}
+
+// mockDecrypter implements CredentialDecrypter for testing.
+type mockDecrypter struct {
+	data map[string]any
+	err  error
+}
+
+func (d *mockDecrypter) DecryptCredential(_ context.Context, _ string) (map[string]any, error) {
+	return d.data, d.err
+}
+
+func TestCredentials(t *testing.T) {
+	m := &Module{
+		logger: zap.NewNop(),
+		cfg:    Config{Username: "static", Password: "fallback", PasswordCredentialID: "cred-1"},
+	}
+
+	// Without the vault wired the static password is used.
+	if u, p := m.credentials(); u != "static" || p != "fallback" {
+		t.Errorf("credentials() without vault = %q, %q", u, p)
+	}
+
+	m.SetCredentialDecrypter(&mockDecrypter{data: map[string]any{"username": "vault-user", "password": "s3cret"}})
+	if u, p := m.credentials(); u != "static" || p != "s3cret" {
+		t.Errorf("credentials() = %q, %q; want configured username and vault password", u, p)
+	}
+
+	m.cfg.Username = ""
+	if u, _ := m.credentials(); u != "vault-user" {
+		t.Errorf("username = %q, want vault-user from credential", u)
+	}
+
+	m.SetCredentialDecrypter(&mockDecrypter{err: errors.New("vault sealed")})
+	if _, p := m.credentials(); p != "fallback" {
+		t.Errorf("password on vault error = %q, want fallback", p)
+	}
+}
+
 func TestMqttTopicFromEvent_CustomPrefix(t *testing.T) {
 	m := &Module{cfg: Config{TopicPrefix: "homelab/net"}}
 
@@ -160,3 +216,110 @@ func TestMqttTopicFromEvent_CustomPrefix(t *testing.T) {
 		t.Errorf("mqttTopicFromEvent with custom prefix = %q, want %q", got, want)
 	}
 }
+
+// fakeToken is a pahomqtt.Token that completes immediately.
+type fakeToken struct{}
+
+func (fakeToken) Wait() bool                     { return true }
+func (fakeToken) WaitTimeout(time.Duration) bool { return true }
+func (fakeToken) Done() <-chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}
+func (fakeToken) Error() error { return nil }
+
+type publishedMessage struct {
+	topic    string
+	retained bool
+	payload  string
+}
+
+// fakeClient records publishes; unused Client methods panic via the nil embed.
+type fakeClient struct {
+	pahomqtt.Client
+	mu        sync.Mutex
+	published []publishedMessage
+}
+
+func (c *fakeClient) IsConnected() bool { return true }
+
+func (c *fakeClient) Publish(topic string, _ byte, retained bool, payload interface{}) pahomqtt.Token {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var body string
+	switch p := payload.(type) {
+	case []byte:
+		body = string(p)
+	case string:
+		body = p
+	}
+	c.published = append(c.published, publishedMessage{topic: topic, retained: retained, payload: body})
+	return fakeToken{}
+}
+
+func (c *fakeClient) find(topic string) (publishedMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := len(c.published) - 1; i >= 0; i-- {
+		if c.published[i].topic == topic {
+			return c.published[i], true
+		}
+	}
+	return publishedMessage{}, false
+}
+
+func newHATestModule(client *fakeClient) *Module {
+	return &Module{
+		logger:    zap.NewNop(),
+		cfg:       Config{TopicPrefix: "subnetree", Timeout: time.Second},
+		client:    client,
+		haEnabled: true,
+		haPrefix:  "homeassistant",
+	}
+}
+
+func TestPublishEvent_DeviceLostKeepsDiscovery(t *testing.T) {
+	client := &fakeClient{}
+	m := newHATestModule(client)
+
+	m.publishEvent(context.Background(), plugin.Event{
+		Topic:   recon.TopicDeviceLost,
+		Payload: &recon.DeviceLostEvent{DeviceID: "dev-1"},
+	})
+
+	state, ok := client.find("subnetree/device/dev-1/online")
+	if !ok || state.payload != "OFF" || !state.retained {
+		t.Errorf("online state = %+v (found %v), want retained OFF", state, ok)
+	}
+	if _, ok := client.find("homeassistant/binary_sensor/subnetree_dev_1/online/config"); ok {
+		t.Error("device lost should not remove discovery config")
+	}
+}
+
+func TestPublishEvent_DeviceDeletedRemovesDiscovery(t *testing.T) {
+	client := &fakeClient{}
+	m := newHATestModule(client)
+
+	m.publishEvent(context.Background(), plugin.Event{
+		Topic:   recon.TopicDeviceDeleted,
+		Payload: &recon.DeviceDeletedEvent{DeviceID: "dev-1", Hostname: "nas"},
+	})
+
+	for _, cfg := range BuildDeviceRemovalConfigs("dev-1", "homeassistant") {
+		msg, ok := client.find(cfg.Topic)
+		if !ok {
+			t.Errorf("missing removal publish for %s", cfg.Topic)
+			continue
+		}
+		if msg.payload != "" || !msg.retained {
+			t.Errorf("%s = %+v, want empty retained payload", cfg.Topic, msg)
+		}
+	}
+	for _, suffix := range []string{"/online", "/type", "/ip"} {
+		msg, ok := client.find("subnetree/device/dev-1" + suffix)
+		if !ok || msg.payload != "" || !msg.retained {
+			t.Errorf("state %s = %+v (found %v), want cleared retained", suffix, msg, ok)
+		}
+	}
+}
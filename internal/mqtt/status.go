@@ -0,0 +1,211 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/HerbHall/subnetree/internal/recon"
+	"github.com/HerbHall/subnetree/pkg/models"
+	"github.com/HerbHall/subnetree/pkg/plugin"
+	"go.uber.org/zap"
+)
+
+// DeviceLister lists all known devices so their current status can be
+// published when the broker connection comes up. Implemented by the recon
+// store; wired via the composition root.
+type DeviceLister interface {
+	ListAllDevices(ctx context.Context) ([]models.Device, error)
+}
+
+// SetDeviceLister injects the device lister. If the broker is already
+// connected the current status of every device is published straight away.
+func (m *Module) SetDeviceLister(l DeviceLister) {
+	m.mu.Lock()
+	m.devices = l
+	connected := m.client != nil && m.client.IsConnected()
+	m.mu.Unlock()
+
+	if connected {
+		go m.publishStatusSnapshot()
+	}
+}
+
+// DeviceStatusMessage is the retained payload published to the device
+// status topic.
+type DeviceStatusMessage struct {
+	DeviceID   string    `json:"device_id"`
+	Status     string    `json:"status"`
+	Online     bool      `json:"online"`
+	Hostname   string    `json:"hostname,omitempty"`
+	IP         string    `json:"ip,omitempty"`
+	DeviceType string    `json:"device_type,omitempty"`
+	LastSeen   time.Time `json:"last_seen"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// newDeviceStatusMessage builds the status payload for a device.
+func newDeviceStatusMessage(device *models.Device) DeviceStatusMessage {
+	status := device.Status
+	if status == "" {
+		status = models.DeviceStatusUnknown
+	}
+	msg := DeviceStatusMessage{
+		DeviceID:   device.ID,
+		Status:     string(status),
+		Online:     status == models.DeviceStatusOnline || status == models.DeviceStatusDegraded,
+		Hostname:   device.Hostname,
+		DeviceType: string(device.DeviceType),
+		LastSeen:   device.LastSeen,
+		Timestamp:  time.Now().UTC(),
+	}
+	if len(device.IPAddresses) > 0 {
+		msg.IP = device.IPAddresses[0]
+	}
+	return msg
+}
+
+// statusTopic expands the status topic template for a device. The
+// template may reference {prefix} (topic_prefix) and {id} (device ID).
+func (m *Module) statusTopic(deviceID string) string {
+	return strings.NewReplacer("{prefix}", m.cfg.TopicPrefix, "{id}", deviceID).Replace(m.cfg.StatusTopic)
+}
+
+// publishStatusForEvent publishes a retained status message when a device
+// event changes the device's status. Updates that leave the status as it
+// was are skipped so subscribers only see transitions. The caller holds
+// m.mu for reading and has checked the client is connected.
+func (m *Module) publishStatusForEvent(event plugin.Event) {
+	if m.cfg.StatusTopic == "" {
+		return
+	}
+
+	switch event.Topic {
+	case recon.TopicDeviceDiscovered, recon.TopicDeviceUpdated:
+		device := extractDevice(event.Payload)
+		if device == nil {
+			return
+		}
+		msg := newDeviceStatusMessage(device)
+		if prev, ok := m.lastStatus(msg.DeviceID); ok && prev.Status == msg.Status {
+			return
+		}
+		m.publishStatus(msg)
+
+	case recon.TopicDeviceLost:
+		lost := extractDeviceLost(event.Payload)
+		if lost == nil {
+			return
+		}
+		// The lost event carries no device metadata; reuse what was last
+		// published so subscribers keep the hostname and type.
+		msg, _ := m.lastStatus(lost.DeviceID)
+		msg.DeviceID = lost.DeviceID
+		msg.Status = string(models.DeviceStatusOffline)
+		msg.Online = false
+		if lost.IP != "" {
+			msg.IP = lost.IP
+		}
+		if !lost.LastSeen.IsZero() {
+			msg.LastSeen = lost.LastSeen
+		}
+		msg.Timestamp = time.Now().UTC()
+		m.publishStatus(msg)
+
+	case recon.TopicDeviceDeleted:
+		deviceID := extractDeletedDeviceID(event.Payload)
+		if deviceID == "" {
+			return
+		}
+		// An empty retained message clears the topic on the broker.
+		m.publishState(m.statusTopic(deviceID), "")
+		m.statusMu.Lock()
+		delete(m.statuses, deviceID)
+		m.statusMu.Unlock()
+	}
+}
+
+// publishStatusSnapshot publishes the current status of every device so
+// the retained values are fresh after startup or a reconnect.
+func (m *Module) publishStatusSnapshot() {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.cfg.StatusTopic == "" || m.devices == nil || m.client == nil || !m.client.IsConnected() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.cfg.Timeout)
+	defer cancel()
+	devices, err := m.devices.ListAllDevices(ctx)
+	if err != nil {
+		m.logger.Warn("failed to list devices for mqtt status snapshot", zap.Error(err))
+		return
+	}
+
+	for i := range devices {
+		m.publishStatus(newDeviceStatusMessage(&devices[i]))
+	}
+	m.logger.Info("mqtt device status snapshot published", zap.Int("devices", len(devices)))
+}
+
+// publishStatus publishes a retained status message and remembers it for
+// change detection.
+func (m *Module) publishStatus(msg DeviceStatusMessage) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		m.logger.Warn("failed to marshal device status", zap.String("device_id", msg.DeviceID), zap.Error(err))
+		return
+	}
+
+	topic := m.statusTopic(msg.DeviceID)
+	token := m.client.Publish(topic, m.cfg.StatusQoS, true, payload)
+	if !token.WaitTimeout(m.cfg.Timeout) {
+		m.logger.Warn("device status publish timed out", zap.String("topic", topic))
+		return
+	}
+	if token.Error() != nil {
+		m.logger.Warn("device status publish failed",
+			zap.String("topic", topic),
+			zap.Error(token.Error()),
+		)
+		return
+	}
+
+	m.statusMu.Lock()
+	if m.statuses == nil {
+		m.statuses = make(map[string]DeviceStatusMessage)
+	}
+	m.statuses[msg.DeviceID] = msg
+	m.statusMu.Unlock()
+	m.logger.Debug("device status published", zap.String("topic", topic), zap.String("status", msg.Status))
+}
+
+// lastStatus returns the status message last published for a device.
+func (m *Module) lastStatus(deviceID string) (DeviceStatusMessage, bool) {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+	msg, ok := m.statuses[deviceID]
+	return msg, ok
+}
+
+// extractDeviceLost attempts to extract a device-lost event payload.
+func extractDeviceLost(payload interface{}) *recon.DeviceLostEvent {
+	switch v := payload.(type) {
+	case recon.DeviceLostEvent:
+		return &v
+	case *recon.DeviceLostEvent:
+		return v
+	default:
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil
+		}
+		var dle recon.DeviceLostEvent
+		if err := json.Unmarshal(data, &dle); err != nil || dle.DeviceID == "" {
+			return nil
+		}
+		return &dle
+	}
+}
@@ -0,0 +1,125 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/HerbHall/subnetree/internal/recon"
+	"github.com/HerbHall/subnetree/pkg/models"
+	"github.com/HerbHall/subnetree/pkg/plugin"
+)
+
+// mockDeviceLister implements DeviceLister for testing.
+type mockDeviceLister struct {
+	devices []models.Device
+}
+
+func (l *mockDeviceLister) ListAllDevices(_ context.Context) ([]models.Device, error) {
+	return l.devices, nil
+}
+
+func newStatusTestModule(client *fakeClient) *Module {
+	m := newHATestModule(client)
+	m.haEnabled = false
+	m.cfg.StatusTopic = "{prefix}/devices/{id}/status"
+	m.cfg.StatusQoS = 1
+	return m
+}
+
+func decodeStatus(t *testing.T, msg publishedMessage) DeviceStatusMessage {
+	t.Helper()
+	var status DeviceStatusMessage
+	if err := json.Unmarshal([]byte(msg.payload), &status); err != nil {
+		t.Fatalf("unmarshal status %q: %v", msg.payload, err)
+	}
+	return status
+}
+
+func TestStatusTopic(t *testing.T) {
+	m := &Module{cfg: Config{TopicPrefix: "home", StatusTopic: "{prefix}/net/{id}"}}
+	if got := m.statusTopic("dev-1"); got != "home/net/dev-1" {
+		t.Errorf("statusTopic = %q, want home/net/dev-1", got)
+	}
+}
+
+func TestPublishEvent_DeviceStatusTransitions(t *testing.T) {
+	client := &fakeClient{}
+	m := newStatusTestModule(client)
+	const topic = "subnetree/devices/dev-1/status"
+	device := &models.Device{
+		ID: "dev-1", Hostname: "nas", DeviceType: models.DeviceTypeNAS,
+		Status: models.DeviceStatusOnline, IPAddresses: []string{"10.0.0.5"},
+	}
+
+	m.publishEvent(context.Background(), plugin.Event{Topic: recon.TopicDeviceDiscovered, Payload: &recon.DeviceEvent{Device: device}})
+	msg, ok := client.find(topic)
+	if !ok || !msg.retained {
+		t.Fatalf("status = %+v (found %v), want retained message", msg, ok)
+	}
+	if s := decodeStatus(t, msg); s.Status != "online" || !s.Online || s.Hostname != "nas" || s.IP != "10.0.0.5" {
+		t.Errorf("discovered status = %+v", s)
+	}
+
+	// An update that leaves the status unchanged is not republished.
+	before := len(client.published)
+	m.publishEvent(context.Background(), plugin.Event{Topic: recon.TopicDeviceUpdated, Payload: &recon.DeviceEvent{Device: device}})
+	for _, p := range client.published[before:] {
+		if p.topic == topic {
+			t.Errorf("unchanged status republished: %+v", p)
+		}
+	}
+
+	m.publishEvent(context.Background(), plugin.Event{Topic: recon.TopicDeviceLost, Payload: &recon.DeviceLostEvent{DeviceID: "dev-1", IP: "10.0.0.5"}})
+	msg, _ = client.find(topic)
+	if s := decodeStatus(t, msg); s.Status != "offline" || s.Online || s.Hostname != "nas" {
+		t.Errorf("lost status = %+v, want offline keeping hostname", s)
+	}
+
+	m.publishEvent(context.Background(), plugin.Event{Topic: recon.TopicDeviceDeleted, Payload: &recon.DeviceDeletedEvent{DeviceID: "dev-1"}})
+	msg, _ = client.find(topic)
+	if msg.payload != "" || !msg.retained {
+		t.Errorf("deleted status = %+v, want cleared retained", msg)
+	}
+	if _, ok := m.lastStatus("dev-1"); ok {
+		t.Error("deleted device still cached")
+	}
+}
+
+func TestPublishEvent_StatusTopicDisabled(t *testing.T) {
+	client := &fakeClient{}
+	m := newStatusTestModule(client)
+	m.cfg.StatusTopic = ""
+
+	m.publishEvent(context.Background(), plugin.Event{
+		Topic:   recon.TopicDeviceLost,
+		Payload: &recon.DeviceLostEvent{DeviceID: "dev-1"},
+	})
+	if _, ok := client.find("subnetree/devices/dev-1/status"); ok {
+		t.Error("status published with status_topic disabled")
+	}
+}
+
+func TestPublishStatusSnapshot(t *testing.T) {
+	client := &fakeClient{}
+	m := newStatusTestModule(client)
+	m.devices = &mockDeviceLister{devices: []models.Device{
+		{ID: "dev-1", Status: models.DeviceStatusOnline},
+		{ID: "dev-2", Status: models.DeviceStatusOffline},
+		{ID: "dev-3"},
+	}}
+
+	m.publishStatusSnapshot()
+
+	want := map[string]string{"dev-1": "online", "dev-2": "offline", "dev-3": "unknown"}
+	for id, status := range want {
+		msg, ok := client.find("subnetree/devices/" + id + "/status")
+		if !ok || !msg.retained {
+			t.Errorf("%s status = %+v (found %v), want retained", id, msg, ok)
+			continue
+		}
+		if s := decodeStatus(t, msg); s.Status != status {
+			t.Errorf("%s status = %q, want %q", id, s.Status, status)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package mqtt
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// tlsEnabled reports whether the broker connection should use TLS.
+// Configuring any certificate turns TLS on even without use_tls.
+func (c *Config) tlsEnabled() bool {
+	return c.UseTLS || c.CACert != "" || c.ClientCert != "" || c.ClientKey != ""
+}
+
+// buildTLSConfig loads the configured CA bundle and client key pair. It
+// returns nil when TLS is disabled. Errors name the offending config key
+// so a missing or unreadable file is easy to track down.
+func buildTLSConfig(cfg *Config) (*tls.Config, error) {
+	if !cfg.tlsEnabled() {
+		return nil, nil
+	}
+	if (cfg.ClientCert == "") != (cfg.ClientKey == "") {
+		return nil, errors.New("client_cert and client_key must be set together")
+	}
+
+	tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.CACert != "" {
+		pem, err := readCertFile("ca_cert", cfg.CACert)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ca_cert %q contains no PEM certificates", cfg.CACert)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if cfg.ClientCert != "" {
+		if _, err := readCertFile("client_cert", cfg.ClientCert); err != nil {
+			return nil, err
+		}
+		if _, err := readCertFile("client_key", cfg.ClientKey); err != nil {
+			return nil, err
+		}
+		pair, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("load client_cert/client_key: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{pair}
+	}
+
+	return tlsCfg, nil
+}
+
+// readCertFile reads a PEM file named by the given config key.
+func readCertFile(key, path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s file %q does not exist", key, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", key, err)
+	}
+	return data, nil
+}
+
+// brokerURL returns the broker URL to dial. When TLS is enabled, plaintext
+// tcp:// and mqtt:// schemes are upgraded to ssl:// so paho negotiates TLS
+// instead of silently ignoring the TLS config.
+func brokerURL(cfg *Config) string {
+	if !cfg.tlsEnabled() {
+		return cfg.BrokerURL
+	}
+	u, err := url.Parse(cfg.BrokerURL)
+	if err != nil {
+		return cfg.BrokerURL
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "tcp", "mqtt":
+		u.Scheme = "ssl"
+	}
+	return u.String()
+}
@@ -0,0 +1,125 @@
+package mqtt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestCert writes a self-signed certificate and its key as PEM files
+// in dir and returns their paths.
+func writeTestCert(t *testing.T, dir string) (certPath, keyPath string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "subnetree-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	certPath = filepath.Join(dir, "cert.pem")
+	keyPath = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write cert: %v", err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	return certPath, keyPath
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath := writeTestCert(t, dir)
+
+	tlsCfg, err := buildTLSConfig(&Config{})
+	if err != nil || tlsCfg != nil {
+		t.Errorf("TLS disabled = %v, %v; want nil, nil", tlsCfg, err)
+	}
+
+	tlsCfg, err = buildTLSConfig(&Config{UseTLS: true})
+	if err != nil || tlsCfg == nil || tlsCfg.RootCAs != nil {
+		t.Errorf("use_tls only = %+v, %v; want system roots", tlsCfg, err)
+	}
+
+	tlsCfg, err = buildTLSConfig(&Config{CACert: certPath, ClientCert: certPath, ClientKey: keyPath})
+	if err != nil {
+		t.Fatalf("buildTLSConfig: %v", err)
+	}
+	if tlsCfg.RootCAs == nil || len(tlsCfg.Certificates) != 1 {
+		t.Errorf("tls config = %+v, want CA pool and client certificate", tlsCfg)
+	}
+}
+
+func TestBuildTLSConfig_Errors(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath := writeTestCert(t, dir)
+	garbage := filepath.Join(dir, "garbage.pem")
+	if err := os.WriteFile(garbage, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("write garbage: %v", err)
+	}
+	missing := filepath.Join(dir, "missing.pem")
+
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"missing ca", Config{CACert: missing}, `ca_cert file "` + missing + `" does not exist`},
+		{"missing client key", Config{ClientCert: certPath, ClientKey: missing}, "client_key file"},
+		{"cert without key", Config{ClientCert: certPath}, "must be set together"},
+		{"ca not pem", Config{CACert: garbage}, "contains no PEM certificates"},
+		{"key mismatch", Config{ClientCert: certPath, ClientKey: garbage}, "load client_cert/client_key"},
+		{"key as ca", Config{CACert: keyPath}, "contains no PEM certificates"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buildTLSConfig(&tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBrokerURL(t *testing.T) {
+	tests := []struct {
+		cfg  Config
+		want string
+	}{
+		{Config{BrokerURL: "tcp://broker:1883"}, "tcp://broker:1883"},
+		{Config{BrokerURL: "tcp://broker:8883", UseTLS: true}, "ssl://broker:8883"},
+		{Config{BrokerURL: "mqtt://broker:8883", CACert: "/ca.pem"}, "ssl://broker:8883"},
+		{Config{BrokerURL: "mqtts://broker:8883", UseTLS: true}, "mqtts://broker:8883"},
+		{Config{BrokerURL: "wss://broker/mqtt", UseTLS: true}, "wss://broker/mqtt"},
+	}
+	for _, tt := range tests {
+		if got := brokerURL(&tt.cfg); got != tt.want {
+			t.Errorf("brokerURL(%q, tls=%v) = %q, want %q", tt.cfg.BrokerURL, tt.cfg.tlsEnabled(), got, tt.want)
+		}
+	}
+}
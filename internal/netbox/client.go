@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -40,6 +41,19 @@ func (c *Client) ListDevicesByTag(ctx context.Context, tag string) ([]NBDevice,
 	return resp.Results, nil
 }
 
+// GetDevice retrieves a device by ID. Returns nil, nil if it does not exist.
+func (c *Client) GetDevice(ctx context.Context, id int) (*NBDevice, error) {
+	path := fmt.Sprintf("/api/dcim/devices/?id=%d", id)
+	var resp ListResponse[NBDevice]
+	if err := c.doJSON(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, fmt.Errorf("get device %d: %w", id, err)
+	}
+	if len(resp.Results) == 0 {
+		return nil, nil
+	}
+	return &resp.Results[0], nil
+}
+
 // CreateDevice creates a new device in NetBox.
 func (c *Client) CreateDevice(ctx context.Context, req NBDeviceCreateRequest) (*NBDevice, error) {
 	var device NBDevice
@@ -143,6 +157,27 @@ func (c *Client) GetOrCreateSite(ctx context.Context, name string) (int, error)
 	return created.ID, nil
 }
 
+// ListDeviceInterfaces retrieves the interfaces of a device.
+func (c *Client) ListDeviceInterfaces(ctx context.Context, deviceID int) ([]NBInterface, error) {
+	path := fmt.Sprintf("/api/dcim/interfaces/?device_id=%d&limit=1000", deviceID)
+	var resp ListResponse[NBInterface]
+	if err := c.doJSON(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, fmt.Errorf("list interfaces of device %d: %w", deviceID, err)
+	}
+	return resp.Results, nil
+}
+
+// FindInterfacesByMAC retrieves the interfaces with the given MAC address,
+// on any device.
+func (c *Client) FindInterfacesByMAC(ctx context.Context, mac string) ([]NBInterface, error) {
+	path := "/api/dcim/interfaces/?mac_address=" + url.QueryEscape(mac)
+	var resp ListResponse[NBInterface]
+	if err := c.doJSON(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, fmt.Errorf("find interfaces by mac: %w", err)
+	}
+	return resp.Results, nil
+}
+
 // CreateInterface creates a network interface on a device.
 func (c *Client) CreateInterface(ctx context.Context, deviceID int, name, macAddr string) (*NBInterface, error) {
 	req := NBInterfaceCreateRequest{
@@ -174,6 +209,17 @@ func (c *Client) CreateIPAddress(ctx context.Context, address string, interfaceI
 	return &ip, nil
 }
 
+// FindIPAddresses retrieves the IP address records for a host address.
+// NetBox matches the address regardless of prefix length.
+func (c *Client) FindIPAddresses(ctx context.Context, address string) ([]NBIPAddress, error) {
+	path := "/api/ipam/ip-addresses/?address=" + url.QueryEscape(address)
+	var resp ListResponse[NBIPAddress]
+	if err := c.doJSON(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, fmt.Errorf("find ip addresses: %w", err)
+	}
+	return resp.Results, nil
+}
+
 // EnsureTag finds a tag by name or creates it. Returns the tag ID.
 func (c *Client) EnsureTag(ctx context.Context, name string) (int, error) {
 	slug := SlugFromName(name)
@@ -205,8 +251,8 @@ func (c *Client) doJSON(ctx context.Context, method, path string, body, result i
 		reqBody = bytes.NewReader(data)
 	}
 
-	url := c.baseURL + path
-	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	reqURL := c.baseURL + path
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, reqBody)
 	if err != nil {
 		return fmt.Errorf("create request: %w", err)
 	}
@@ -109,6 +109,10 @@ func newMockNetBox(t *testing.T) (srv *httptest.Server, paths *[]string) {
 	})
 
 	// Interfaces
+	mux.HandleFunc("GET /api/dcim/interfaces/", func(w http.ResponseWriter, _ *http.Request) {
+		requests = append(requests, "GET /api/dcim/interfaces/")
+		writeTestJSON(w, ListResponse[NBInterface]{Count: 0, Results: []NBInterface{}})
+	})
 	mux.HandleFunc("POST /api/dcim/interfaces/", func(w http.ResponseWriter, r *http.Request) {
 		requests = append(requests, "POST /api/dcim/interfaces/")
 		var req NBInterfaceCreateRequest
@@ -118,6 +122,10 @@ func newMockNetBox(t *testing.T) (srv *httptest.Server, paths *[]string) {
 	})
 
 	// IP Addresses
+	mux.HandleFunc("GET /api/ipam/ip-addresses/", func(w http.ResponseWriter, _ *http.Request) {
+		requests = append(requests, "GET /api/ipam/ip-addresses/")
+		writeTestJSON(w, ListResponse[NBIPAddress]{Count: 0, Results: []NBIPAddress{}})
+	})
 	mux.HandleFunc("POST /api/ipam/ip-addresses/", func(w http.ResponseWriter, r *http.Request) {
 		requests = append(requests, "POST /api/ipam/ip-addresses/")
 		var req NBIPAddressCreateRequest
@@ -291,7 +299,7 @@ func TestSyncAll(t *testing.T) {
 		TagName:  "subnetree-managed",
 	}
 
-	engine := newSyncEngine(client, reader, cfg, zapNop())
+	engine := newSyncEngine(client, reader, nil, cfg, zapNop())
 	result, err := engine.SyncAll(context.Background(), false)
 	if err != nil {
 		t.Fatalf("SyncAll error: %v", err)
@@ -363,7 +371,7 @@ func TestSyncAll_DryRun(t *testing.T) {
 		TagName:  "subnetree-managed",
 	}
 
-	engine := newSyncEngine(client, reader, cfg, zapNop())
+	engine := newSyncEngine(client, reader, nil, cfg, zapNop())
 	result, err := engine.SyncAll(context.Background(), true)
 	if err != nil {
 		t.Fatalf("SyncAll dry-run error: %v", err)
@@ -4,13 +4,15 @@ import "time"
 
 // Config holds the NetBox integration configuration.
 type Config struct {
-	URL      string        `mapstructure:"url"`       // NetBox base URL (e.g., "https://netbox.example.com")
-	Token    string        `mapstructure:"token"`     // API token
-	SiteID   int           `mapstructure:"site_id"`   // Default site ID for new devices (0 = auto-create)
-	SiteName string        `mapstructure:"site_name"` // Default site name (used when SiteID=0)
-	TagName  string        `mapstructure:"tag_name"`  // Tag for SubNetree-managed devices (default: "subnetree-managed")
-	DryRun   bool          `mapstructure:"dry_run"`   // Default dry-run mode
-	Timeout  time.Duration `mapstructure:"timeout"`   // HTTP client timeout (default: 30s)
+	URL          string        `mapstructure:"url"`           // NetBox base URL (e.g., "https://netbox.example.com")
+	Token        string        `mapstructure:"token"`         // API token (prefer credential_id)
+	CredentialID string        `mapstructure:"credential_id"` //nolint:gosec // G101: vault credential holding the API token
+	SiteID       int           `mapstructure:"site_id"`       // Default site ID for new devices (0 = auto-create)
+	SiteName     string        `mapstructure:"site_name"`     // Default site name (used when SiteID=0)
+	TagName      string        `mapstructure:"tag_name"`      // Tag for SubNetree-managed devices (default: "subnetree-managed")
+	DryRun       bool          `mapstructure:"dry_run"`       // Default dry-run mode
+	Timeout      time.Duration `mapstructure:"timeout"`       // HTTP client timeout (default: 30s)
+	SyncInterval time.Duration `mapstructure:"sync_interval"` // Scheduled sync interval (0 = on-demand only)
 }
 
 // DefaultConfig returns a Config with sensible defaults.
@@ -21,3 +23,8 @@ func DefaultConfig() Config {
 		Timeout: 30 * time.Second,
 	}
 }
+
+// configured reports whether a NetBox URL and a token source are set.
+func (c *Config) configured() bool {
+	return c.URL != "" && (c.Token != "" || c.CredentialID != "")
+}
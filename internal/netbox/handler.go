@@ -3,6 +3,7 @@ package netbox
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -28,16 +29,20 @@ func writeError(w http.ResponseWriter, status int, detail string) {
 
 // StatusResponse is the response for the GET /status endpoint.
 type StatusResponse struct {
-	Configured bool   `json:"configured"`
-	URL        string `json:"url,omitempty"`
-	TagName    string `json:"tag_name"`
-	DryRun     bool   `json:"dry_run"`
+	Configured   bool        `json:"configured"`
+	URL          string      `json:"url,omitempty"`
+	TagName      string      `json:"tag_name"`
+	DryRun       bool        `json:"dry_run"`
+	SyncInterval string      `json:"sync_interval,omitempty" example:"1h0m0s"`
+	LastSyncAt   *time.Time  `json:"last_sync_at,omitempty"`
+	LastSync     *SyncResult `json:"last_sync,omitempty"`
+	LastError    string      `json:"last_error,omitempty"`
 }
 
 // handleSync triggers a full sync of all SubNetree devices to NetBox.
 //
 //	@Summary		Sync all devices to NetBox
-//	@Description	Triggers a full sync of all SubNetree devices to a NetBox CMDB instance.
+//	@Description	Triggers a full sync of all SubNetree devices to a NetBox CMDB instance. Devices are matched to existing NetBox devices by recorded NetBox ID, MAC address, primary IP, then name, and the NetBox ID is recorded in the device's netbox_id custom field.
 //	@Tags			netbox
 //	@Produce		json
 //	@Security		BearerAuth
@@ -47,19 +52,15 @@ type StatusResponse struct {
 //	@Failure		503		{object}	map[string]any
 //	@Router			/netbox/sync [post]
 func (m *Module) handleSync(w http.ResponseWriter, r *http.Request) {
-	if m.client == nil {
-		writeError(w, http.StatusServiceUnavailable, "netbox module not configured (set plugins.netbox.url and plugins.netbox.token)")
-		return
-	}
-	if m.deviceReader == nil {
-		writeError(w, http.StatusServiceUnavailable, "device reader not available")
+	engine, err := m.newEngine(r.Context())
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
 		return
 	}
 
 	dryRun := r.URL.Query().Get("dry_run") == "true" || m.cfg.DryRun
 
-	engine := newSyncEngine(m.client, m.deviceReader, m.cfg, m.logger)
-	result, err := engine.SyncAll(r.Context(), dryRun)
+	result, err := m.syncAll(r.Context(), engine, dryRun)
 	if err != nil {
 		m.logger.Error("sync failed", zap.Error(err))
 		writeError(w, http.StatusInternalServerError, err.Error())
@@ -84,25 +85,21 @@ func (m *Module) handleSync(w http.ResponseWriter, r *http.Request) {
 //	@Failure		503		{object}	map[string]any
 //	@Router			/netbox/sync/{id} [post]
 func (m *Module) handleSyncDevice(w http.ResponseWriter, r *http.Request) {
-	if m.client == nil {
-		writeError(w, http.StatusServiceUnavailable, "netbox module not configured")
-		return
-	}
-	if m.deviceReader == nil {
-		writeError(w, http.StatusServiceUnavailable, "device reader not available")
-		return
-	}
-
 	id := r.PathValue("id")
 	if id == "" {
 		writeError(w, http.StatusBadRequest, "device id is required")
 		return
 	}
 
+	engine, err := m.newEngine(r.Context())
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
 	dryRun := r.URL.Query().Get("dry_run") == "true" || m.cfg.DryRun
 
-	engine := newSyncEngine(m.client, m.deviceReader, m.cfg, m.logger)
-	result, err := engine.SyncDevice(r.Context(), id, dryRun)
+	result, err := m.syncDevice(r.Context(), engine, id, dryRun)
 	if err != nil {
 		m.logger.Error("device sync failed", zap.String("device_id", id), zap.Error(err))
 		writeError(w, http.StatusInternalServerError, err.Error())
@@ -115,7 +112,7 @@ func (m *Module) handleSyncDevice(w http.ResponseWriter, r *http.Request) {
 // handleStatus returns the current NetBox integration configuration status.
 //
 //	@Summary		Get NetBox integration status
-//	@Description	Returns whether the NetBox integration is configured and connection details.
+//	@Description	Returns whether the NetBox integration is configured, connection details, and the outcome of the last full sync.
 //	@Tags			netbox
 //	@Produce		json
 //	@Security		BearerAuth
@@ -123,12 +120,27 @@ func (m *Module) handleSyncDevice(w http.ResponseWriter, r *http.Request) {
 //	@Router			/netbox/status [get]
 func (m *Module) handleStatus(w http.ResponseWriter, _ *http.Request) {
 	resp := StatusResponse{
-		Configured: m.client != nil,
+		Configured: m.cfg.configured(),
 		TagName:    m.cfg.TagName,
 		DryRun:     m.cfg.DryRun,
 	}
-	if m.client != nil {
+	if resp.Configured {
 		resp.URL = m.cfg.URL
 	}
+	if m.cfg.SyncInterval > 0 {
+		resp.SyncInterval = m.cfg.SyncInterval.String()
+	}
+
+	m.mu.RLock()
+	if !m.lastSyncTime.IsZero() {
+		t := m.lastSyncTime
+		resp.LastSyncAt = &t
+		resp.LastSync = m.lastSyncResult
+	}
+	if m.lastSyncErr != nil {
+		resp.LastError = m.lastSyncErr.Error()
+	}
+	m.mu.RUnlock()
+
 	writeJSON(w, http.StatusOK, resp)
 }
@@ -2,6 +2,10 @@ package netbox
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/HerbHall/subnetree/pkg/models"
 	"github.com/HerbHall/subnetree/pkg/plugin"
@@ -14,6 +18,10 @@ var (
 	_ plugin.HTTPProvider = (*Module)(nil)
 )
 
+// errNotConfigured is returned when a sync is requested before the NetBox URL
+// and a token source are configured.
+var errNotConfigured = errors.New("netbox module not configured (set plugins.netbox.url and plugins.netbox.credential_id or token)")
+
 // DeviceReader provides read access to SubNetree device data.
 // Implemented via an adapter in the composition root (main.go).
 type DeviceReader interface {
@@ -21,13 +29,39 @@ type DeviceReader interface {
 	GetDevice(ctx context.Context, id string) (*models.Device, error)
 }
 
+// DeviceWriter records sync results on SubNetree devices.
+// Implemented via an adapter in the composition root (main.go).
+type DeviceWriter interface {
+	SetDeviceCustomField(ctx context.Context, id, key, value string) error
+}
+
+// CredentialDecrypter retrieves decrypted credential data from the vault.
+// Implemented by the vaultDecryptAdapter in main.go.
+type CredentialDecrypter interface {
+	DecryptCredential(ctx context.Context, id string) (map[string]any, error)
+}
+
 // Module implements the NetBox CMDB export plugin.
-// It syncs SubNetree device inventory to a NetBox instance via its REST API.
+// It syncs SubNetree device inventory to a NetBox instance via its REST API,
+// on demand and optionally on a schedule.
 type Module struct {
 	logger       *zap.Logger
 	cfg          Config
-	client       *Client
 	deviceReader DeviceReader
+	deviceWriter DeviceWriter
+	decrypter    CredentialDecrypter
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+
+	// syncMu serializes syncs so scheduled and manual runs do not race.
+	syncMu sync.Mutex
+
+	mu             sync.RWMutex
+	client         *Client
+	lastSyncTime   time.Time
+	lastSyncResult *SyncResult
+	lastSyncErr    error
 }
 
 // New creates a new NetBox plugin instance.
@@ -41,6 +75,18 @@ func (m *Module) SetDeviceReader(r DeviceReader) {
 	m.deviceReader = r
 }
 
+// SetDeviceWriter injects the device writer used to record NetBox IDs on
+// synced devices. Called from the composition root.
+func (m *Module) SetDeviceWriter(w DeviceWriter) {
+	m.deviceWriter = w
+}
+
+// SetCredentialDecrypter injects the vault decrypter used to resolve the API
+// token from plugins.netbox.credential_id. Called from the composition root.
+func (m *Module) SetCredentialDecrypter(d CredentialDecrypter) {
+	m.decrypter = d
+}
+
 // Info returns the plugin metadata.
 func (m *Module) Info() plugin.PluginInfo {
 	return plugin.PluginInfo{
@@ -63,14 +109,22 @@ func (m *Module) Init(_ context.Context, deps plugin.Dependencies) error {
 		}
 	}
 
-	// Only create the client if URL and token are configured.
-	if m.cfg.URL != "" && m.cfg.Token != "" {
+	// A token from the vault is resolved on first sync, once the decrypter
+	// is wired; a plain token creates the client now.
+	switch {
+	case m.cfg.URL != "" && m.cfg.Token != "":
 		m.client = NewClient(m.cfg.URL, m.cfg.Token, m.cfg.Timeout)
 		m.logger.Info("netbox client configured",
 			zap.String("url", m.cfg.URL),
 			zap.Bool("dry_run", m.cfg.DryRun),
 		)
-	} else {
+	case m.cfg.configured():
+		m.logger.Info("netbox configured with vault credential",
+			zap.String("url", m.cfg.URL),
+			zap.String("credential_id", m.cfg.CredentialID),
+			zap.Bool("dry_run", m.cfg.DryRun),
+		)
+	default:
 		m.logger.Info("netbox module disabled (url or token not configured)")
 	}
 
@@ -78,15 +132,30 @@ func (m *Module) Init(_ context.Context, deps plugin.Dependencies) error {
 	return nil
 }
 
-// Start begins the module's operations. NetBox sync is stateless and on-demand,
-// so there is nothing to start.
+// Start begins the scheduled sync loop when sync_interval is set. Otherwise
+// syncs only run on demand.
 func (m *Module) Start(_ context.Context) error {
-	m.logger.Info("netbox module started")
+	if m.cfg.SyncInterval <= 0 || !m.cfg.configured() {
+		m.logger.Info("netbox module started")
+		return nil
+	}
+
+	m.stopCh = make(chan struct{})
+	m.wg.Add(1)
+	go m.syncLoop()
+
+	m.logger.Info("netbox module started",
+		zap.Duration("sync_interval", m.cfg.SyncInterval),
+	)
 	return nil
 }
 
 // Stop gracefully shuts down the module.
 func (m *Module) Stop(_ context.Context) error {
+	if m.stopCh != nil {
+		close(m.stopCh)
+		m.wg.Wait()
+	}
 	m.logger.Info("netbox module stopped")
 	return nil
 }
@@ -99,3 +168,121 @@ func (m *Module) Routes() []plugin.Route {
 		{Method: "GET", Path: "/status", Handler: m.handleStatus},
 	}
 }
+
+// syncLoop runs a full sync every sync interval until Stop is called.
+func (m *Module) syncLoop() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.cfg.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.runScheduledSync()
+		case <-m.stopCh:
+			return
+		}
+	}
+}
+
+func (m *Module) runScheduledSync() {
+	ctx, cancel := context.WithTimeout(context.Background(), m.cfg.SyncInterval)
+	defer cancel()
+
+	engine, err := m.newEngine(ctx)
+	if err != nil {
+		m.logger.Warn("scheduled netbox sync skipped", zap.Error(err))
+		return
+	}
+	result, err := m.syncAll(ctx, engine, m.cfg.DryRun)
+	if err != nil {
+		m.logger.Error("scheduled netbox sync failed", zap.Error(err))
+		return
+	}
+	m.logger.Info("scheduled netbox sync completed",
+		zap.Int("created", result.Created),
+		zap.Int("updated", result.Updated),
+		zap.Int("failed", result.Failed),
+		zap.Bool("dry_run", result.DryRun),
+	)
+}
+
+// syncAll runs a full sync and records its outcome for the status endpoint.
+func (m *Module) syncAll(ctx context.Context, engine *syncEngine, dryRun bool) (*SyncResult, error) {
+	m.syncMu.Lock()
+	defer m.syncMu.Unlock()
+
+	result, err := engine.SyncAll(ctx, dryRun)
+
+	m.mu.Lock()
+	m.lastSyncTime = time.Now().UTC()
+	m.lastSyncResult = result
+	m.lastSyncErr = err
+	m.mu.Unlock()
+
+	return result, err
+}
+
+// syncDevice syncs a single device.
+func (m *Module) syncDevice(ctx context.Context, engine *syncEngine, id string, dryRun bool) (*SyncResult, error) {
+	m.syncMu.Lock()
+	defer m.syncMu.Unlock()
+	return engine.SyncDevice(ctx, id, dryRun)
+}
+
+// newEngine creates a sync engine, resolving the API client first. An error
+// means the integration is not usable yet rather than that a sync failed.
+func (m *Module) newEngine(ctx context.Context) (*syncEngine, error) {
+	if m.deviceReader == nil {
+		return nil, errors.New("device reader not available")
+	}
+	client, err := m.apiClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newSyncEngine(client, m.deviceReader, m.deviceWriter, m.cfg, m.logger), nil
+}
+
+// apiClient returns the NetBox client, creating it on first use when the
+// token is stored in the vault.
+func (m *Module) apiClient(ctx context.Context) (*Client, error) {
+	m.mu.RLock()
+	client := m.client
+	m.mu.RUnlock()
+	if client != nil {
+		return client, nil
+	}
+	if !m.cfg.configured() {
+		return nil, errNotConfigured
+	}
+	if m.decrypter == nil {
+		return nil, errors.New("vault not available to resolve netbox credential")
+	}
+
+	token, err := m.resolveToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.client == nil {
+		m.client = NewClient(m.cfg.URL, token, m.cfg.Timeout)
+	}
+	return m.client, nil
+}
+
+// resolveToken retrieves the API token from the vault credential.
+func (m *Module) resolveToken(ctx context.Context) (string, error) {
+	data, err := m.decrypter.DecryptCredential(ctx, m.cfg.CredentialID)
+	if err != nil {
+		return "", fmt.Errorf("decrypt netbox credential: %w", err)
+	}
+	for _, field := range []string{"token", "api_key", "password"} {
+		if s, ok := data[field].(string); ok && s != "" {
+			return s, nil
+		}
+	}
+	return "", errors.New("netbox credential does not contain a token, api_key, or password field")
+}
@@ -3,24 +3,37 @@ package netbox
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/HerbHall/subnetree/pkg/models"
 	"go.uber.org/zap"
 )
 
+// NetBoxIDField is the SubNetree device custom field that records the ID of
+// the NetBox device the device was synced to.
+const NetBoxIDField = "netbox_id"
+
+// defaultInterfaceName is the interface created on a NetBox device when none
+// of its interfaces carries the SubNetree device's MAC address.
+const defaultInterfaceName = "eth0"
+
 // syncEngine orchestrates the sync between SubNetree devices and NetBox.
 type syncEngine struct {
 	client       *Client
 	deviceReader DeviceReader
+	deviceWriter DeviceWriter
 	cfg          Config
 	logger       *zap.Logger
 }
 
-// newSyncEngine creates a new sync engine.
-func newSyncEngine(client *Client, reader DeviceReader, cfg Config, logger *zap.Logger) *syncEngine {
+// newSyncEngine creates a new sync engine. A nil writer skips recording
+// NetBox IDs on SubNetree devices.
+func newSyncEngine(client *Client, reader DeviceReader, writer DeviceWriter, cfg Config, logger *zap.Logger) *syncEngine {
 	return &syncEngine{
 		client:       client,
 		deviceReader: reader,
+		deviceWriter: writer,
 		cfg:          cfg,
 		logger:       logger,
 	}
@@ -56,14 +69,11 @@ func (s *syncEngine) SyncAll(ctx context.Context, dryRun bool) (result *SyncResu
 	if err != nil {
 		return nil, fmt.Errorf("list netbox devices: %w", err)
 	}
-	existingByName := make(map[string]*NBDevice, len(existing))
-	for i := range existing {
-		existingByName[existing[i].Name] = &existing[i]
-	}
+	index := newDeviceIndex(existing)
 
 	// Sync each device.
 	for i := range devices {
-		syncErr := s.syncOneDevice(ctx, &devices[i], siteID, tagID, existingByName, dryRun, result)
+		syncErr := s.syncOneDevice(ctx, &devices[i], siteID, tagID, index, dryRun, result)
 		if syncErr != nil {
 			result.Failed++
 			result.Errors = append(result.Errors, syncErr.Error())
@@ -103,12 +113,8 @@ func (s *syncEngine) SyncDevice(ctx context.Context, deviceID string, dryRun boo
 	if err != nil {
 		return nil, fmt.Errorf("list netbox devices: %w", err)
 	}
-	existingByName := make(map[string]*NBDevice, len(existing))
-	for i := range existing {
-		existingByName[existing[i].Name] = &existing[i]
-	}
 
-	syncErr := s.syncOneDevice(ctx, device, siteID, tagID, existingByName, dryRun, result)
+	syncErr := s.syncOneDevice(ctx, device, siteID, tagID, newDeviceIndex(existing), dryRun, result)
 	if syncErr != nil {
 		result.Failed++
 		result.Errors = append(result.Errors, syncErr.Error())
@@ -122,7 +128,7 @@ func (s *syncEngine) syncOneDevice(
 	ctx context.Context,
 	device *models.Device,
 	siteID, tagID int,
-	existingByName map[string]*NBDevice,
+	index *deviceIndex,
 	dryRun bool,
 	result *SyncResult,
 ) error {
@@ -156,17 +162,24 @@ func (s *syncEngine) syncOneDevice(
 	req := DeviceToNetBoxRequest(device, roleID, typeID, siteID, tagID)
 
 	// Check if device already exists in NetBox.
-	if nbDev, exists := existingByName[req.Name]; exists {
+	nbDev, err := s.findExisting(ctx, device, req.Name, index)
+	if err != nil {
+		return fmt.Errorf("match device %q: %w", req.Name, err)
+	}
+	if nbDev != nil {
 		if dryRun {
 			s.logger.Info("dry-run: would update device", zap.String("name", req.Name), zap.Int("netbox_id", nbDev.ID))
 			result.Updated++
 			return nil
 		}
-		_, err := s.client.UpdateDevice(ctx, nbDev.ID, req)
-		if err != nil {
+		// Keep tags set in NetBox; a PATCH replaces the whole list.
+		req.Tags = mergeTagIDs(nbDev.Tags, tagID)
+		if _, err := s.client.UpdateDevice(ctx, nbDev.ID, req); err != nil {
 			return fmt.Errorf("update device %q: %w", req.Name, err)
 		}
 		s.logger.Info("device updated", zap.String("name", req.Name), zap.Int("netbox_id", nbDev.ID))
+		s.syncAddresses(ctx, nbDev.ID, device)
+		s.recordNetBoxID(ctx, device, nbDev.ID)
 		result.Updated++
 		return nil
 	}
@@ -178,34 +191,132 @@ func (s *syncEngine) syncOneDevice(
 	}
 
 	// Create the device.
-	nbDev, err := s.client.CreateDevice(ctx, req)
+	created, err := s.client.CreateDevice(ctx, req)
 	if err != nil {
 		return fmt.Errorf("create device %q: %w", req.Name, err)
 	}
-	s.logger.Info("device created", zap.String("name", req.Name), zap.Int("netbox_id", nbDev.ID))
-
-	// Create interface + IP assignments.
-	if device.MACAddress != "" || len(device.IPAddresses) > 0 {
-		ifaceName := "eth0"
-		iface, ifErr := s.client.CreateInterface(ctx, nbDev.ID, ifaceName, device.MACAddress)
-		if ifErr != nil {
-			s.logger.Warn("failed to create interface", zap.Error(ifErr))
-		} else {
-			for _, ip := range device.IPAddresses {
-				// NetBox requires CIDR notation; default to /32 for single hosts.
-				addr := ip
-				if !containsSlash(addr) {
-					addr += "/32"
-				}
-				if _, ipErr := s.client.CreateIPAddress(ctx, addr, iface.ID); ipErr != nil {
-					s.logger.Warn("failed to create IP address", zap.String("ip", ip), zap.Error(ipErr))
-				}
+	s.logger.Info("device created", zap.String("name", req.Name), zap.Int("netbox_id", created.ID))
+	index.add(created)
+	s.syncAddresses(ctx, created.ID, device)
+	s.recordNetBoxID(ctx, device, created.ID)
+
+	result.Created++
+	return nil
+}
+
+// findExisting returns the NetBox device that corresponds to a SubNetree
+// device, or nil if there is none. Devices are matched by the NetBox ID
+// recorded on the SubNetree device, then by SubNetree ID, MAC address,
+// primary IP, and finally name. The MAC and IP lookups also find devices
+// created in NetBox directly, which are then updated instead of duplicated.
+func (s *syncEngine) findExisting(ctx context.Context, device *models.Device, name string, index *deviceIndex) (*NBDevice, error) {
+	if id, err := strconv.Atoi(device.CustomFields[NetBoxIDField]); err == nil && id > 0 {
+		nbDev, err := s.deviceByID(ctx, index, id)
+		if err != nil || nbDev != nil {
+			return nbDev, err
+		}
+		// Deleted in NetBox since the last sync; match it afresh.
+	}
+	if nbDev := index.bySubnetreeID[device.ID]; nbDev != nil {
+		return nbDev, nil
+	}
+
+	if device.MACAddress != "" {
+		if nbDev := index.byMAC[normalizeMAC(device.MACAddress)]; nbDev != nil {
+			return nbDev, nil
+		}
+		ifaces, err := s.client.FindInterfacesByMAC(ctx, normalizeMAC(device.MACAddress))
+		if err != nil {
+			return nil, err
+		}
+		for i := range ifaces {
+			if ifaces[i].Device != nil {
+				return s.deviceByID(ctx, index, ifaces[i].Device.ID)
 			}
 		}
 	}
 
-	result.Created++
-	return nil
+	if len(device.IPAddresses) > 0 {
+		addrs, err := s.client.FindIPAddresses(ctx, hostIP(device.IPAddresses[0]))
+		if err != nil {
+			return nil, err
+		}
+		for i := range addrs {
+			if obj := addrs[i].AssignedObject; obj != nil && obj.Device != nil {
+				return s.deviceByID(ctx, index, obj.Device.ID)
+			}
+		}
+	}
+
+	return index.byName[name], nil
+}
+
+// deviceByID returns a NetBox device from the index, fetching it when it
+// does not carry the SubNetree tag. Returns nil, nil if it does not exist.
+func (s *syncEngine) deviceByID(ctx context.Context, index *deviceIndex, id int) (*NBDevice, error) {
+	if nbDev := index.byID[id]; nbDev != nil {
+		return nbDev, nil
+	}
+	return s.client.GetDevice(ctx, id)
+}
+
+// syncAddresses ensures the device's MAC address and IP addresses exist on
+// the NetBox device. IP addresses already recorded in NetBox are left as
+// they are, so assignments made in NetBox take precedence. Failures are
+// logged rather than failing the device sync.
+func (s *syncEngine) syncAddresses(ctx context.Context, nbDeviceID int, device *models.Device) {
+	if device.MACAddress == "" && len(device.IPAddresses) == 0 {
+		return
+	}
+
+	ifaces, err := s.client.ListDeviceInterfaces(ctx, nbDeviceID)
+	if err != nil {
+		s.logger.Warn("failed to list interfaces", zap.Int("netbox_id", nbDeviceID), zap.Error(err))
+		return
+	}
+	iface := findInterface(ifaces, device.MACAddress)
+	if iface == nil {
+		iface, err = s.client.CreateInterface(ctx, nbDeviceID, defaultInterfaceName, device.MACAddress)
+		if err != nil {
+			s.logger.Warn("failed to create interface", zap.Error(err))
+			return
+		}
+	}
+
+	for _, ip := range device.IPAddresses {
+		existing, err := s.client.FindIPAddresses(ctx, hostIP(ip))
+		if err != nil {
+			s.logger.Warn("failed to look up IP address", zap.String("ip", ip), zap.Error(err))
+			continue
+		}
+		if len(existing) > 0 {
+			continue
+		}
+		// NetBox requires CIDR notation; default to /32 for single hosts.
+		addr := ip
+		if !containsSlash(addr) {
+			addr += "/32"
+		}
+		if _, ipErr := s.client.CreateIPAddress(ctx, addr, iface.ID); ipErr != nil {
+			s.logger.Warn("failed to create IP address", zap.String("ip", ip), zap.Error(ipErr))
+		}
+	}
+}
+
+// recordNetBoxID stores the NetBox device ID on the SubNetree device so later
+// syncs match it directly.
+func (s *syncEngine) recordNetBoxID(ctx context.Context, device *models.Device, nbDeviceID int) {
+	id := strconv.Itoa(nbDeviceID)
+	if s.deviceWriter == nil || device.CustomFields[NetBoxIDField] == id {
+		return
+	}
+	if err := s.deviceWriter.SetDeviceCustomField(ctx, device.ID, NetBoxIDField, id); err != nil {
+		s.logger.Warn("failed to record netbox id on device",
+			zap.String("device_id", device.ID),
+			zap.Int("netbox_id", nbDeviceID),
+			zap.Error(err),
+		)
+	}
 }
 
 // resolveSite determines the NetBox site ID, creating one if needed.
@@ -220,6 +331,87 @@ func (s *syncEngine) resolveSite(ctx context.Context) (int, error) {
 	return s.client.GetOrCreateSite(ctx, siteName)
 }
 
+// deviceIndex looks up the NetBox devices that carry the SubNetree tag.
+type deviceIndex struct {
+	byID          map[int]*NBDevice
+	bySubnetreeID map[string]*NBDevice
+	byMAC         map[string]*NBDevice
+	byName        map[string]*NBDevice
+}
+
+// newDeviceIndex indexes NetBox devices by ID, name, and the SubNetree ID
+// and MAC custom fields written on sync.
+func newDeviceIndex(devices []NBDevice) *deviceIndex {
+	index := &deviceIndex{
+		byID:          make(map[int]*NBDevice, len(devices)),
+		bySubnetreeID: make(map[string]*NBDevice, len(devices)),
+		byMAC:         make(map[string]*NBDevice, len(devices)),
+		byName:        make(map[string]*NBDevice, len(devices)),
+	}
+	for i := range devices {
+		index.add(&devices[i])
+	}
+	return index
+}
+
+// add indexes a device, so devices created during a sync are matched by
+// later SubNetree devices with the same name, MAC, or ID.
+func (x *deviceIndex) add(d *NBDevice) {
+	x.byID[d.ID] = d
+	if d.Name != "" {
+		x.byName[d.Name] = d
+	}
+	if id, ok := d.CustomFields["subnetree_id"].(string); ok && id != "" {
+		x.bySubnetreeID[id] = d
+	}
+	if mac, ok := d.CustomFields["subnetree_mac"].(string); ok && mac != "" {
+		x.byMAC[normalizeMAC(mac)] = d
+	}
+}
+
+// findInterface returns the interface carrying the given MAC address, or
+// else the one SubNetree creates by default. Returns nil if neither exists.
+func findInterface(ifaces []NBInterface, mac string) *NBInterface {
+	if mac != "" {
+		for i := range ifaces {
+			if normalizeMAC(ifaces[i].MACAddress) == normalizeMAC(mac) {
+				return &ifaces[i]
+			}
+		}
+	}
+	for i := range ifaces {
+		if ifaces[i].Name == defaultInterfaceName {
+			return &ifaces[i]
+		}
+	}
+	return nil
+}
+
+// mergeTagIDs returns the IDs of the existing tags plus tagID.
+func mergeTagIDs(existing []NBTag, tagID int) []int {
+	ids := make([]int, 0, len(existing)+1)
+	for _, t := range existing {
+		if t.ID != tagID {
+			ids = append(ids, t.ID)
+		}
+	}
+	if tagID > 0 {
+		ids = append(ids, tagID)
+	}
+	return ids
+}
+
+// normalizeMAC lowercases a MAC address and uses colon separators.
+func normalizeMAC(mac string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(mac)), "-", ":")
+}
+
+// hostIP strips a prefix length from an address.
+func hostIP(addr string) string {
+	host, _, _ := strings.Cut(addr, "/")
+	return host
+}
+
 // containsSlash checks if a string contains a forward slash.
 func containsSlash(s string) bool {
 	for _, c := range s {
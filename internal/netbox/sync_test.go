@@ -0,0 +1,174 @@
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+// newDedupeNetBox serves a NetBox with one untagged device (ID 77) that has
+// the given MAC on an interface and owns 192.168.1.50. Returns the server and
+// the bodies of device PATCH requests keyed by path.
+func newDedupeNetBox(t *testing.T) (srv *httptest.Server, patches map[string]NBDeviceCreateRequest, creates *int) {
+	t.Helper()
+	patches = make(map[string]NBDeviceCreateRequest)
+	var created int
+	nbDev := NBDevice{ID: 77, Name: "nas", Tags: []NBTag{{ID: 3, Name: "storage"}}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/extras/tags/", func(w http.ResponseWriter, _ *http.Request) {
+		writeTestJSON(w, ListResponse[NBTag]{Count: 1, Results: []NBTag{{ID: 10, Name: "subnetree-managed"}}})
+	})
+	mux.HandleFunc("GET /api/dcim/sites/", func(w http.ResponseWriter, _ *http.Request) {
+		writeTestJSON(w, ListResponse[NBSite]{Count: 1, Results: []NBSite{{ID: 1}}})
+	})
+	mux.HandleFunc("GET /api/dcim/manufacturers/", func(w http.ResponseWriter, _ *http.Request) {
+		writeTestJSON(w, ListResponse[NBManufacturer]{Count: 1, Results: []NBManufacturer{{ID: 5}}})
+	})
+	mux.HandleFunc("GET /api/dcim/device-types/", func(w http.ResponseWriter, _ *http.Request) {
+		writeTestJSON(w, ListResponse[NBDeviceType]{Count: 1, Results: []NBDeviceType{{ID: 30}}})
+	})
+	mux.HandleFunc("GET /api/dcim/device-roles/", func(w http.ResponseWriter, _ *http.Request) {
+		writeTestJSON(w, ListResponse[NBDeviceRole]{Count: 1, Results: []NBDeviceRole{{ID: 40}}})
+	})
+	mux.HandleFunc("GET /api/dcim/devices/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("id") == "77" {
+			writeTestJSON(w, ListResponse[NBDevice]{Count: 1, Results: []NBDevice{nbDev}})
+			return
+		}
+		writeTestJSON(w, ListResponse[NBDevice]{Count: 0, Results: []NBDevice{}})
+	})
+	mux.HandleFunc("POST /api/dcim/devices/", func(w http.ResponseWriter, _ *http.Request) {
+		created++
+		w.WriteHeader(http.StatusCreated)
+		writeTestJSON(w, NBDevice{ID: 100})
+	})
+	mux.HandleFunc("PATCH /api/dcim/devices/{id}/", func(w http.ResponseWriter, r *http.Request) {
+		var req NBDeviceCreateRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		patches[r.URL.Path] = req
+		writeTestJSON(w, nbDev)
+	})
+	mux.HandleFunc("GET /api/dcim/interfaces/", func(w http.ResponseWriter, r *http.Request) {
+		iface := NBInterface{ID: 200, Name: "bond0", MACAddress: "00:11:22:33:44:55", Device: &NBDevice{ID: 77}}
+		if r.URL.Query().Get("mac_address") == "00:11:22:33:44:55" || r.URL.Query().Get("device_id") == "77" {
+			writeTestJSON(w, ListResponse[NBInterface]{Count: 1, Results: []NBInterface{iface}})
+			return
+		}
+		writeTestJSON(w, ListResponse[NBInterface]{Count: 0, Results: []NBInterface{}})
+	})
+	mux.HandleFunc("GET /api/ipam/ip-addresses/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("address") == "192.168.1.50" {
+			writeTestJSON(w, ListResponse[NBIPAddress]{Count: 1, Results: []NBIPAddress{{
+				ID: 300, Address: "192.168.1.50/24",
+				AssignedObject: &NBAssignedObject{ID: 200, Device: &NBDevice{ID: 77}},
+			}}})
+			return
+		}
+		writeTestJSON(w, ListResponse[NBIPAddress]{Count: 0, Results: []NBIPAddress{}})
+	})
+	mux.HandleFunc("POST /api/ipam/ip-addresses/", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		writeTestJSON(w, NBIPAddress{ID: 301})
+	})
+
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, patches, &created
+}
+
+func TestSyncAll_MatchesExistingNetBoxDevice(t *testing.T) {
+	tests := []struct {
+		name   string
+		device models.Device
+	}{
+		{"by mac", models.Device{ID: "dev-mac", Hostname: "nas-01", MACAddress: "00-11-22-33-44-55"}},
+		{"by primary ip", models.Device{ID: "dev-ip", Hostname: "nas-02", IPAddresses: []string{"192.168.1.50"}}},
+		{"by recorded id", models.Device{ID: "dev-id", Hostname: "nas-03", CustomFields: map[string]string{NetBoxIDField: "77"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, patches, creates := newDedupeNetBox(t)
+			client := NewClient(srv.URL, "test-token", 5*time.Second)
+			writer := &mockDeviceWriter{}
+			cfg := Config{URL: srv.URL, TagName: "subnetree-managed"}
+
+			engine := newSyncEngine(client, &mockDeviceReader{devices: []models.Device{tt.device}}, writer, cfg, zapNop())
+			result, err := engine.SyncAll(context.Background(), false)
+			if err != nil {
+				t.Fatalf("SyncAll error: %v", err)
+			}
+			if result.Updated != 1 || result.Created != 0 || *creates != 0 {
+				t.Fatalf("result = %+v, creates = %d; want the existing device updated", result, *creates)
+			}
+
+			req, ok := patches["/api/dcim/devices/77/"]
+			if !ok {
+				t.Fatal("expected PATCH of NetBox device 77")
+			}
+			if !slices.Equal(req.Tags, []int{3, 10}) {
+				t.Errorf("patched tags = %v, want existing tag kept plus [10]", req.Tags)
+			}
+
+			_, recorded := tt.device.CustomFields[NetBoxIDField]
+			switch {
+			case recorded && len(writer.fields) != 0:
+				t.Errorf("unchanged netbox_id rewritten: %v", writer.fields)
+			case !recorded && writer.fields[tt.device.ID+"/"+NetBoxIDField] != "77":
+				t.Errorf("recorded fields = %v, want netbox_id 77 on %s", writer.fields, tt.device.ID)
+			}
+		})
+	}
+}
+
+func TestModuleAPIClient_VaultToken(t *testing.T) {
+	m := New()
+	m.cfg = Config{URL: "http://netbox.local", CredentialID: "cred-1"}
+
+	if _, err := m.apiClient(context.Background()); err == nil {
+		t.Fatal("expected error without a decrypter")
+	}
+
+	m.SetCredentialDecrypter(&mockDecrypter{data: map[string]any{"token": "vault-token"}})
+	client, err := m.apiClient(context.Background())
+	if err != nil {
+		t.Fatalf("apiClient: %v", err)
+	}
+	if client.token != "vault-token" {
+		t.Errorf("token = %q, want the vault token", client.token)
+	}
+
+	m.cfg = Config{}
+	m.client = nil
+	if _, err := m.apiClient(context.Background()); err != errNotConfigured {
+		t.Errorf("err = %v, want errNotConfigured", err)
+	}
+}
+
+// mockDeviceWriter records custom fields set through DeviceWriter.
+type mockDeviceWriter struct {
+	fields map[string]string
+}
+
+func (m *mockDeviceWriter) SetDeviceCustomField(_ context.Context, id, key, value string) error {
+	if m.fields == nil {
+		m.fields = make(map[string]string)
+	}
+	m.fields[id+"/"+key] = value
+	return nil
+}
+
+// mockDecrypter returns fixed credential data.
+type mockDecrypter struct {
+	data map[string]any
+}
+
+func (m *mockDecrypter) DecryptCredential(_ context.Context, _ string) (map[string]any, error) {
+	return m.data, nil
+}
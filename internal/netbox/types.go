@@ -86,11 +86,20 @@ type NBTypeVal struct {
 
 // NBIPAddress represents a NetBox IP address assignment.
 type NBIPAddress struct {
-	ID                 int    `json:"id"`
-	Address            string `json:"address"`
-	AssignedObjectType string `json:"assigned_object_type,omitempty"`
-	AssignedObjectID   int    `json:"assigned_object_id,omitempty"`
-	URL                string `json:"url,omitempty"`
+	ID                 int               `json:"id"`
+	Address            string            `json:"address"`
+	AssignedObjectType string            `json:"assigned_object_type,omitempty"`
+	AssignedObjectID   int               `json:"assigned_object_id,omitempty"`
+	AssignedObject     *NBAssignedObject `json:"assigned_object,omitempty"`
+	URL                string            `json:"url,omitempty"`
+}
+
+// NBAssignedObject is the nested object an IP address is assigned to.
+// For interfaces it carries the owning device.
+type NBAssignedObject struct {
+	ID     int       `json:"id"`
+	Name   string    `json:"name,omitempty"`
+	Device *NBDevice `json:"device,omitempty"`
 }
 
 // ListResponse is the generic paginated response from NetBox list endpoints.
@@ -0,0 +1,58 @@
+// Package pagination sets the response headers shared by paginated list
+// endpoints.
+//
+// List endpoints keep their JSON body unchanged and describe the page in
+// headers: X-Total-Count carries the number of items across all pages, and
+// an RFC 8288 (formerly RFC 5988) Link header carries first, prev, next, and
+// last page URLs built from the request URL with limit and offset replaced.
+// A generic client can page through any list by following rel="next".
+package pagination
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// TotalCountHeader is the response header carrying the total item count.
+const TotalCountHeader = "X-Total-Count"
+
+// SetHeaders writes X-Total-Count and Link headers for a page of a list
+// holding total items, starting at offset with at most limit items. Links
+// that would be empty or point at the current page are omitted. It must be
+// called before the response body is written.
+func SetHeaders(w http.ResponseWriter, r *http.Request, total, limit, offset int) {
+	w.Header().Set(TotalCountHeader, strconv.Itoa(total))
+	if limit <= 0 || total <= 0 {
+		return
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	last := ((total - 1) / limit) * limit
+	var links []string
+	if offset > 0 {
+		links = append(links, link(r, limit, 0, "first"))
+		links = append(links, link(r, limit, max(offset-limit, 0), "prev"))
+	}
+	if offset+limit < total {
+		links = append(links, link(r, limit, offset+limit, "next"))
+	}
+	if offset != last {
+		links = append(links, link(r, limit, last, "last"))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+// link formats one Link header value for the request URL at the given page.
+func link(r *http.Request, limit, offset int, rel string) string {
+	q := r.URL.Query()
+	q.Set("limit", strconv.Itoa(limit))
+	q.Set("offset", strconv.Itoa(offset))
+	u := *r.URL
+	u.RawQuery = q.Encode()
+	return `<` + u.RequestURI() + `>; rel="` + rel + `"`
+}
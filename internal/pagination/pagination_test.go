@@ -0,0 +1,74 @@
+package pagination
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestSetHeaders(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    string
+		total     int
+		limit     int
+		offset    int
+		wantLinks string
+	}{
+		{
+			name:      "first page",
+			target:    "/api/v1/recon/devices?status=online",
+			total:     120,
+			limit:     50,
+			offset:    0,
+			wantLinks: `</api/v1/recon/devices?limit=50&offset=50&status=online>; rel="next", </api/v1/recon/devices?limit=50&offset=100&status=online>; rel="last"`,
+		},
+		{
+			name:      "middle page",
+			target:    "/api/v1/recon/scans?limit=50&offset=60",
+			total:     120,
+			limit:     50,
+			offset:    60,
+			wantLinks: `</api/v1/recon/scans?limit=50&offset=0>; rel="first", </api/v1/recon/scans?limit=50&offset=10>; rel="prev", </api/v1/recon/scans?limit=50&offset=110>; rel="next", </api/v1/recon/scans?limit=50&offset=100>; rel="last"`,
+		},
+		{
+			name:      "last page",
+			target:    "/items?offset=100",
+			total:     120,
+			limit:     50,
+			offset:    100,
+			wantLinks: `</items?limit=50&offset=0>; rel="first", </items?limit=50&offset=50>; rel="prev"`,
+		},
+		{
+			name:      "single page",
+			target:    "/items",
+			total:     3,
+			limit:     50,
+			offset:    0,
+			wantLinks: "",
+		},
+		{
+			name:      "empty",
+			target:    "/items",
+			total:     0,
+			limit:     50,
+			offset:    0,
+			wantLinks: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, tt.target, http.NoBody)
+			SetHeaders(w, r, tt.total, tt.limit, tt.offset)
+
+			if got, want := w.Header().Get(TotalCountHeader), strconv.Itoa(tt.total); got != want {
+				t.Errorf("%s = %q, want %q", TotalCountHeader, got, want)
+			}
+			if got := w.Header().Get("Link"); got != tt.wantLinks {
+				t.Errorf("Link =\n  %s\nwant\n  %s", got, tt.wantLinks)
+			}
+		})
+	}
+}
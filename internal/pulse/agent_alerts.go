@@ -0,0 +1,152 @@
+package pulse
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/HerbHall/subnetree/internal/dispatch"
+	"github.com/HerbHall/subnetree/pkg/plugin"
+	"go.uber.org/zap"
+)
+
+// agentCheckType marks the passive checks that back Scout agent offline
+// alerts. They are created disabled so the scheduler never runs them; their
+// alerts are driven by dispatch events instead.
+const agentCheckType = "agent"
+
+// agentCheckID returns the ID of the passive check for a Scout agent.
+func agentCheckID(agentID string) string {
+	return "agent-" + agentID
+}
+
+// handleAgentDisconnected raises an alert when dispatch marks a Scout agent offline.
+func (m *Module) handleAgentDisconnected(ctx context.Context, event plugin.Event) {
+	if m.store == nil || !m.cfg.AgentAlerts {
+		return
+	}
+
+	ae, ok := event.Payload.(*dispatch.AgentStatusEvent)
+	if !ok {
+		m.logger.Warn("unexpected payload type for agent disconnected event")
+		return
+	}
+
+	checkID := agentCheckID(ae.AgentID)
+	if err := m.ensureAgentCheck(ctx, checkID, ae); err != nil {
+		m.logger.Warn("failed to create agent check", zap.String("agent_id", ae.AgentID), zap.Error(err))
+		return
+	}
+
+	existing, err := m.store.GetActiveAlert(ctx, checkID)
+	if err != nil {
+		m.logger.Warn("failed to check existing alert", zap.String("check_id", checkID), zap.Error(err))
+		return
+	}
+	if existing != nil {
+		return
+	}
+
+	name := ae.Hostname
+	if name == "" {
+		name = ae.AgentID
+	}
+	message := fmt.Sprintf("Scout agent %s stopped checking in", name)
+	if ae.LastCheckIn != nil {
+		message = fmt.Sprintf("Scout agent %s has not checked in since %s", name, ae.LastCheckIn.UTC().Format(time.RFC3339))
+	}
+
+	now := time.Now().UTC()
+	alert := &Alert{
+		ID:          fmt.Sprintf("alert-%s-%d", checkID, now.UnixMilli()),
+		CheckID:     checkID,
+		DeviceID:    ae.DeviceID,
+		Severity:    "warning",
+		Message:     message,
+		TriggeredAt: now,
+	}
+	if err := m.store.InsertAlert(ctx, alert); err != nil {
+		m.logger.Warn("failed to insert agent alert", zap.String("check_id", checkID), zap.Error(err))
+		return
+	}
+
+	m.logger.Warn("agent offline alert triggered",
+		zap.String("alert_id", alert.ID),
+		zap.String("agent_id", ae.AgentID),
+	)
+
+	if m.bus != nil {
+		m.bus.PublishAsync(ctx, plugin.Event{
+			Topic:     TopicAlertTriggered,
+			Source:    "pulse",
+			Timestamp: now,
+			Payload:   alert,
+		})
+	}
+}
+
+// handleAgentReconnected resolves the offline alert when a Scout agent checks in again.
+func (m *Module) handleAgentReconnected(ctx context.Context, event plugin.Event) {
+	if m.store == nil {
+		return
+	}
+
+	ae, ok := event.Payload.(*dispatch.AgentStatusEvent)
+	if !ok {
+		m.logger.Warn("unexpected payload type for agent reconnected event")
+		return
+	}
+
+	checkID := agentCheckID(ae.AgentID)
+	alert, err := m.store.GetActiveAlert(ctx, checkID)
+	if err != nil {
+		m.logger.Warn("failed to get active alert", zap.String("check_id", checkID), zap.Error(err))
+		return
+	}
+	if alert == nil {
+		return
+	}
+
+	now := time.Now().UTC()
+	if err := m.store.ResolveAlert(ctx, alert.ID, now); err != nil {
+		m.logger.Warn("failed to resolve alert", zap.String("alert_id", alert.ID), zap.Error(err))
+		return
+	}
+	alert.ResolvedAt = &now
+
+	m.logger.Info("agent offline alert resolved",
+		zap.String("alert_id", alert.ID),
+		zap.String("agent_id", ae.AgentID),
+	)
+
+	if m.bus != nil {
+		m.bus.PublishAsync(ctx, plugin.Event{
+			Topic:     TopicAlertResolved,
+			Source:    "pulse",
+			Timestamp: now,
+			Payload:   alert,
+		})
+	}
+}
+
+// ensureAgentCheck creates the passive check row an agent alert references.
+func (m *Module) ensureAgentCheck(ctx context.Context, checkID string, ae *dispatch.AgentStatusEvent) error {
+	existing, err := m.store.GetCheck(ctx, checkID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	return m.store.InsertCheck(ctx, &Check{
+		ID:        checkID,
+		DeviceID:  ae.DeviceID,
+		CheckType: agentCheckType,
+		Target:    ae.AgentID,
+		Enabled:   false,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
@@ -0,0 +1,72 @@
+package pulse
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HerbHall/subnetree/internal/dispatch"
+	"github.com/HerbHall/subnetree/pkg/plugin"
+)
+
+func TestHandleAgentDisconnected_TriggersAndResolves(t *testing.T) {
+	m, ps := newTestModule(t)
+	ctx := context.Background()
+
+	last := time.Now().Add(-10 * time.Minute)
+	evt := plugin.Event{
+		Topic: TopicAgentDisconnected,
+		Payload: &dispatch.AgentStatusEvent{
+			AgentID:     "agent-001",
+			Hostname:    "nas",
+			DeviceID:    "dev-001",
+			LastCheckIn: &last,
+		},
+	}
+
+	m.handleAgentDisconnected(ctx, evt)
+	// A repeated event must not open a second alert.
+	m.handleAgentDisconnected(ctx, evt)
+
+	check, err := ps.GetCheck(ctx, "agent-agent-001")
+	if err != nil || check == nil {
+		t.Fatalf("agent check not created: %v", err)
+	}
+	if check.Enabled || check.CheckType != agentCheckType {
+		t.Errorf("check = %+v, want disabled agent check", check)
+	}
+
+	alerts, err := ps.ListActiveAlerts(ctx, "dev-001")
+	if err != nil {
+		t.Fatalf("ListActiveAlerts: %v", err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("got %d active alerts, want 1", len(alerts))
+	}
+
+	evt.Topic = TopicAgentReconnected
+	m.handleAgentReconnected(ctx, evt)
+
+	alert, err := ps.GetActiveAlert(ctx, "agent-agent-001")
+	if err != nil {
+		t.Fatalf("GetActiveAlert: %v", err)
+	}
+	if alert != nil {
+		t.Errorf("alert still active after reconnect: %+v", alert)
+	}
+}
+
+func TestHandleAgentDisconnected_Disabled(t *testing.T) {
+	m, ps := newTestModule(t)
+	m.cfg.AgentAlerts = false
+	ctx := context.Background()
+
+	m.handleAgentDisconnected(ctx, plugin.Event{
+		Topic:   TopicAgentDisconnected,
+		Payload: &dispatch.AgentStatusEvent{AgentID: "agent-001"},
+	})
+
+	if check, _ := ps.GetCheck(ctx, "agent-agent-001"); check != nil {
+		t.Errorf("agent check created while agent alerts disabled")
+	}
+}
@@ -0,0 +1,63 @@
+package pulse
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+	"github.com/HerbHall/subnetree/pkg/roles"
+)
+
+// AgentCheckRunner runs a check on a Scout agent and waits for its outcome.
+// Implemented by the dispatch module, which sends the check over the agent
+// command channel; resolved via the agent_management role.
+type AgentCheckRunner interface {
+	RunAgentCheck(ctx context.Context, agentID string, payload scoutpb.CheckPayload) (*scoutpb.CheckOutput, error)
+}
+
+// agentCheckRunner returns the agent management plugin's check runner, or
+// nil when no such plugin is registered.
+func (m *Module) agentCheckRunner() AgentCheckRunner {
+	if m.agentRunner != nil {
+		return m.agentRunner
+	}
+	if m.plugins == nil {
+		return nil
+	}
+	for _, p := range m.plugins.ResolveByRole(roles.RoleAgentManagement) {
+		if r, ok := p.(AgentCheckRunner); ok {
+			return r
+		}
+	}
+	return nil
+}
+
+// runAgentCheck runs check from its agent and converts the agent's output to
+// a result tagged with the agent as its vantage point. An error without a
+// result means the agent produced no measurement, e.g. it is offline or did
+// not check in before ctx ended; such runs are not recorded so an unreachable
+// agent does not raise alerts for the devices it monitors.
+func (m *Module) runAgentCheck(ctx context.Context, check Check, checkType string) (*CheckResult, error) {
+	runner := m.agentCheckRunner()
+	if runner == nil {
+		return nil, fmt.Errorf("no agent management plugin available to run agent checks")
+	}
+
+	out, err := runner.RunAgentCheck(ctx, check.AgentID, scoutpb.CheckPayload{
+		CheckType:      checkType,
+		Target:         check.Target,
+		TimeoutSeconds: int(m.cfg.PingTimeout / time.Second),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &CheckResult{
+		Success:      out.Success,
+		LatencyMs:    out.LatencyMs,
+		PacketLoss:   out.PacketLoss,
+		ErrorMessage: out.Error,
+		AgentID:      check.AgentID,
+		CheckedAt:    time.Now().UTC(),
+	}, nil
+}
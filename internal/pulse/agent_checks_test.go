@@ -0,0 +1,127 @@
+package pulse
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+)
+
+// fakeAgentRunner records agent check requests and returns a fixed outcome.
+type fakeAgentRunner struct {
+	out      *scoutpb.CheckOutput
+	err      error
+	agentID  string
+	payloads []scoutpb.CheckPayload
+}
+
+func (f *fakeAgentRunner) RunAgentCheck(_ context.Context, agentID string, payload scoutpb.CheckPayload) (*scoutpb.CheckOutput, error) {
+	f.agentID = agentID
+	f.payloads = append(f.payloads, payload)
+	return f.out, f.err
+}
+
+func TestExecuteCheck_RunsOnAgent(t *testing.T) {
+	m, ps := newTestModule(t)
+	ctx := context.Background()
+	runner := &fakeAgentRunner{out: &scoutpb.CheckOutput{Success: false, LatencyMs: 3, Error: "connection refused"}}
+	m.agentRunner = runner
+
+	now := time.Now().UTC()
+	check := Check{ID: "chk-1", DeviceID: "dev-1", CheckType: "tcp", Target: "10.0.0.5:443", IntervalSeconds: 30, AgentID: "agent-remote", Enabled: true, CreatedAt: now, UpdatedAt: now}
+	if err := ps.InsertCheck(ctx, &check); err != nil {
+		t.Fatalf("InsertCheck: %v", err)
+	}
+
+	m.executeCheck(ctx, check)
+
+	if runner.agentID != "agent-remote" || len(runner.payloads) != 1 {
+		t.Fatalf("runner called for %q with %+v, want one call for agent-remote", runner.agentID, runner.payloads)
+	}
+	if p := runner.payloads[0]; p.CheckType != "tcp" || p.Target != "10.0.0.5:443" {
+		t.Errorf("payload = %+v, want tcp 10.0.0.5:443", p)
+	}
+
+	results, err := ps.ListResults(ctx, "dev-1", 10)
+	if err != nil {
+		t.Fatalf("ListResults: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	if r := results[0]; r.AgentID != "agent-remote" || r.Success || r.ErrorMessage != "connection refused" {
+		t.Errorf("result = %+v, want failed result from agent-remote", r)
+	}
+
+	// An agent that produces no measurement records nothing.
+	runner.out, runner.err = nil, errors.New("agent offline")
+	m.executeCheck(ctx, check)
+	if results, _ := ps.ListResults(ctx, "dev-1", 10); len(results) != 1 {
+		t.Errorf("got %d results after agent error, want still 1", len(results))
+	}
+}
+
+func TestQueryVantageMetrics(t *testing.T) {
+	_, ps := newTestModule(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	if err := ps.InsertCheck(ctx, &Check{ID: "chk-1", DeviceID: "dev-1", CheckType: "icmp", Target: "10.0.0.5", IntervalSeconds: 30, Enabled: true, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("InsertCheck: %v", err)
+	}
+	for _, r := range []CheckResult{
+		{CheckID: "chk-1", DeviceID: "dev-1", Success: true, LatencyMs: 2, CheckedAt: now.Add(-time.Minute)},
+		{CheckID: "chk-1", DeviceID: "dev-1", Success: false, LatencyMs: 80, AgentID: "agent-remote", CheckedAt: now.Add(-time.Minute)},
+	} {
+		if err := ps.InsertResult(ctx, &r); err != nil {
+			t.Fatalf("InsertResult: %v", err)
+		}
+	}
+
+	tests := []struct {
+		vantage string
+		want    float64
+	}{
+		{"", 50},
+		{"server", 100},
+		{"agent-remote", 0},
+	}
+	for _, tt := range tests {
+		series, err := ps.QueryVantageMetrics(ctx, "dev-1", "success_rate", "1h", tt.vantage)
+		if err != nil {
+			t.Fatalf("QueryVantageMetrics(%q): %v", tt.vantage, err)
+		}
+		if len(series.Points) != 1 || series.Points[0].Value != tt.want {
+			t.Errorf("vantage %q: points = %+v, want one point of %v", tt.vantage, series.Points, tt.want)
+		}
+		if series.Vantage != tt.vantage {
+			t.Errorf("Vantage = %q, want %q", series.Vantage, tt.vantage)
+		}
+	}
+}
+
+func TestHandleCreateCheck_AgentIDRequiresDispatch(t *testing.T) {
+	m, _ := newTestModule(t)
+	body := `{"device_id":"dev-1","check_type":"icmp","target":"10.0.0.5","agent_id":"agent-remote"}`
+
+	w := httptest.NewRecorder()
+	m.handleCreateCheck(w, httptest.NewRequest(http.MethodPost, "/checks", bytes.NewBufferString(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("without dispatch: status = %d, want 400", w.Code)
+	}
+
+	m.agentRunner = &fakeAgentRunner{}
+	w = httptest.NewRecorder()
+	m.handleCreateCheck(w, httptest.NewRequest(http.MethodPost, "/checks", bytes.NewBufferString(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("with dispatch: status = %d, want 201: %s", w.Code, w.Body.String())
+	}
+	if !bytes.Contains(w.Body.Bytes(), []byte(`"agent_id":"agent-remote"`)) {
+		t.Errorf("body = %s, want agent_id in response", w.Body.String())
+	}
+}
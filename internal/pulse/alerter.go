@@ -86,12 +86,28 @@ func (a *Alerter) handleSuccess(ctx context.Context, check Check) {
 	}
 }
 
+// thresholds returns the consecutive failure counts at which a check's alert
+// triggers as a warning and becomes critical, applying the check's overrides
+// to the alerter defaults.
+func (a *Alerter) thresholds(check Check) (warningAfter, criticalAfter int) {
+	warningAfter = a.threshold
+	if check.WarningAfter > 0 {
+		warningAfter = check.WarningAfter
+	}
+	criticalAfter = warningAfter * 2
+	if check.CriticalAfter > 0 {
+		criticalAfter = check.CriticalAfter
+	}
+	return warningAfter, criticalAfter
+}
+
 // handleFailure increments the failure counter and triggers an alert if threshold reached.
 func (a *Alerter) handleFailure(ctx context.Context, check Check, result *CheckResult) {
 	a.failures[check.ID]++
 	count := a.failures[check.ID]
 
-	if count < a.threshold {
+	warningAfter, criticalAfter := a.thresholds(check)
+	if count < warningAfter {
 		return
 	}
 
@@ -105,8 +121,11 @@ func (a *Alerter) handleFailure(ctx context.Context, check Check, result *CheckR
 	now := time.Now().UTC()
 
 	if existing != nil {
+		if existing.MaintWindowID != "" {
+			a.endMaintSuppression(ctx, check, existing, now)
+		}
 		// Update severity if escalation threshold reached.
-		if count >= a.threshold*2 && existing.Severity != "critical" {
+		if count >= criticalAfter && existing.Severity != "critical" {
 			a.logger.Info("alert escalated to critical",
 				zap.String("alert_id", existing.ID),
 				zap.String("check_id", check.ID),
@@ -118,7 +137,7 @@ func (a *Alerter) handleFailure(ctx context.Context, check Check, result *CheckR
 
 	// Determine severity.
 	severity := "warning"
-	if count >= a.threshold*2 {
+	if count >= criticalAfter {
 		severity = "critical"
 	}
 
@@ -137,17 +156,34 @@ func (a *Alerter) handleFailure(ctx context.Context, check Check, result *CheckR
 		ConsecutiveFailures: count,
 	}
 
-	// Check if this alert should be suppressed due to an upstream device failure.
-	suppressed, byDevice, suppErr := a.store.IsSuppressed(ctx, check.ID)
-	if suppErr != nil {
-		a.logger.Warn("suppression check failed, proceeding with alert",
-			zap.String("check_id", check.ID),
-			zap.Error(suppErr),
-		)
+	// Suppress alerts for devices inside a maintenance window.
+	if check.DeviceID != "" {
+		inWindow, windowID, maintErr := a.store.IsInMaintenanceWindow(ctx, check.DeviceID, now)
+		if maintErr != nil {
+			a.logger.Warn("maintenance window check failed, proceeding with alert",
+				zap.String("check_id", check.ID),
+				zap.Error(maintErr),
+			)
+		}
+		if inWindow {
+			alert.Suppressed = true
+			alert.MaintWindowID = windowID
+		}
 	}
-	if suppressed {
-		alert.Suppressed = true
-		alert.SuppressedBy = byDevice
+
+	// Check if this alert should be suppressed due to an upstream device failure.
+	if !alert.Suppressed {
+		suppressed, byDevice, suppErr := a.store.IsSuppressed(ctx, check.ID)
+		if suppErr != nil {
+			a.logger.Warn("suppression check failed, proceeding with alert",
+				zap.String("check_id", check.ID),
+				zap.Error(suppErr),
+			)
+		}
+		if suppressed {
+			alert.Suppressed = true
+			alert.SuppressedBy = byDevice
+		}
 	}
 
 	// Check topology-aware correlation if not already suppressed.
@@ -176,6 +212,7 @@ func (a *Alerter) handleFailure(ctx context.Context, check Check, result *CheckR
 			zap.String("check_id", check.ID),
 			zap.String("device_id", check.DeviceID),
 			zap.String("suppressed_by", alert.SuppressedBy),
+			zap.String("maint_window_id", alert.MaintWindowID),
 		)
 
 		if a.bus != nil {
@@ -206,3 +243,40 @@ func (a *Alerter) handleFailure(ctx context.Context, check Check, result *CheckR
 		})
 	}
 }
+
+// endMaintSuppression unsuppresses an alert that a maintenance window
+// suppressed once the device is no longer in any window, and publishes it
+// as triggered since the check is still failing.
+func (a *Alerter) endMaintSuppression(ctx context.Context, check Check, alert *Alert, now time.Time) {
+	inWindow, _, err := a.store.IsInMaintenanceWindow(ctx, check.DeviceID, now)
+	if err != nil {
+		a.logger.Warn("maintenance window check failed", zap.String("check_id", check.ID), zap.Error(err))
+		return
+	}
+	if inWindow {
+		return
+	}
+	if err := a.store.ClearAlertSuppression(ctx, alert.ID); err != nil {
+		a.logger.Warn("failed to clear alert suppression", zap.String("alert_id", alert.ID), zap.Error(err))
+		return
+	}
+
+	a.logger.Warn("alert unsuppressed (maintenance window ended)",
+		zap.String("alert_id", alert.ID),
+		zap.String("check_id", check.ID),
+		zap.String("device_id", check.DeviceID),
+		zap.String("maint_window_id", alert.MaintWindowID),
+	)
+	alert.Suppressed = false
+	alert.SuppressedBy = ""
+	alert.MaintWindowID = ""
+
+	if a.bus != nil {
+		a.bus.PublishAsync(ctx, plugin.Event{
+			Topic:     TopicAlertTriggered,
+			Source:    "pulse",
+			Timestamp: now,
+			Payload:   alert,
+		})
+	}
+}
@@ -532,3 +532,143 @@ func TestAlerter_ErrorMessage(t *testing.T) {
 		t.Errorf("alert.Message = %q, want %q", alert.Message, customError)
 	}
 }
+
+func TestAlerter_MaintenanceWindow_SuppressesUntilWindowEnds(t *testing.T) {
+	ps := alerterTestStore(t)
+	bus := &mockEventBus{}
+	threshold := 3
+	alerter := NewAlerter(ps, bus, threshold, zap.NewNop())
+
+	check := makeTestCheck(t, ps, "device1", "ping", "192.168.1.1")
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	mw := &MaintWindow{
+		ID:         "mw-1",
+		Name:       "Patching",
+		StartTime:  now.Add(-time.Hour),
+		EndTime:    now.Add(time.Hour),
+		Recurrence: "once",
+		DeviceIDs:  []string{check.DeviceID},
+		Enabled:    true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := ps.InsertMaintWindow(ctx, mw); err != nil {
+		t.Fatalf("InsertMaintWindow: %v", err)
+	}
+
+	result := &CheckResult{
+		CheckID:      check.ID,
+		DeviceID:     check.DeviceID,
+		Success:      false,
+		ErrorMessage: "timeout",
+		CheckedAt:    now,
+	}
+	for i := 0; i < threshold; i++ {
+		alerter.ProcessResult(ctx, check, result)
+	}
+
+	alert, err := ps.GetActiveAlert(ctx, check.ID)
+	if err != nil {
+		t.Fatalf("GetActiveAlert: %v", err)
+	}
+	if alert == nil {
+		t.Fatal("got nil alert, want suppressed alert")
+	}
+	if !alert.Suppressed || alert.MaintWindowID != mw.ID {
+		t.Errorf("alert suppressed = %v, maint_window_id = %q; want true, %q", alert.Suppressed, alert.MaintWindowID, mw.ID)
+	}
+	if len(bus.events) != 1 || bus.events[0].Topic != TopicAlertSuppressed {
+		t.Fatalf("events = %v, want one %s", bus.events, TopicAlertSuppressed)
+	}
+
+	// Still failing while the window is active: stays suppressed.
+	alerter.ProcessResult(ctx, check, result)
+	if len(bus.events) != 1 {
+		t.Errorf("got %d events inside window, want 1", len(bus.events))
+	}
+
+	// End the window; the next failure unsuppresses and triggers the alert.
+	mw.EndTime = now.Add(-time.Minute)
+	if err := ps.UpdateMaintWindow(ctx, mw); err != nil {
+		t.Fatalf("UpdateMaintWindow: %v", err)
+	}
+	alerter.ProcessResult(ctx, check, result)
+
+	alert, err = ps.GetActiveAlert(ctx, check.ID)
+	if err != nil {
+		t.Fatalf("GetActiveAlert: %v", err)
+	}
+	if alert == nil || alert.Suppressed || alert.MaintWindowID != "" {
+		t.Fatalf("alert after window = %+v, want unsuppressed", alert)
+	}
+	if len(bus.events) != 2 || bus.events[1].Topic != TopicAlertTriggered {
+		t.Fatalf("events = %v, want %s after window ends", bus.events, TopicAlertTriggered)
+	}
+}
+
+func TestAlerter_PerCheckThresholds(t *testing.T) {
+	ps := alerterTestStore(t)
+	bus := &mockEventBus{}
+	alerter := NewAlerter(ps, bus, 3, zap.NewNop())
+	ctx := context.Background()
+
+	check := makeTestCheck(t, ps, "camera", "ping", "192.168.1.50")
+	check.WarningAfter = 5
+	check.CriticalAfter = 5
+
+	result := &CheckResult{
+		CheckID:   check.ID,
+		DeviceID:  check.DeviceID,
+		Success:   false,
+		CheckedAt: time.Now().UTC(),
+	}
+
+	// The global threshold of 3 does not apply to this check.
+	for i := 0; i < 4; i++ {
+		alerter.ProcessResult(ctx, check, result)
+	}
+	alert, err := ps.GetActiveAlert(ctx, check.ID)
+	if err != nil {
+		t.Fatalf("GetActiveAlert: %v", err)
+	}
+	if alert != nil {
+		t.Fatalf("got alert after 4 failures, want none before warning_after=5")
+	}
+
+	alerter.ProcessResult(ctx, check, result)
+	alert, err = ps.GetActiveAlert(ctx, check.ID)
+	if err != nil {
+		t.Fatalf("GetActiveAlert: %v", err)
+	}
+	if alert == nil {
+		t.Fatal("got nil alert after 5 failures, want alert")
+	}
+	if alert.Severity != "critical" {
+		t.Errorf("alert.Severity = %q, want critical (critical_after=5)", alert.Severity)
+	}
+}
+
+func TestAlerter_Thresholds(t *testing.T) {
+	alerter := NewAlerter(nil, nil, 3, zap.NewNop())
+	tests := []struct {
+		name         string
+		check        Check
+		wantWarning  int
+		wantCritical int
+	}{
+		{"defaults", Check{}, 3, 6},
+		{"warning override doubles for critical", Check{WarningAfter: 10}, 10, 20},
+		{"critical override", Check{CriticalAfter: 4}, 3, 4},
+		{"both", Check{WarningAfter: 2, CriticalAfter: 8}, 2, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, c := alerter.thresholds(tt.check)
+			if w != tt.wantWarning || c != tt.wantCritical {
+				t.Errorf("thresholds() = %d, %d; want %d, %d", w, c, tt.wantWarning, tt.wantCritical)
+			}
+		})
+	}
+}
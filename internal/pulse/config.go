@@ -12,6 +12,38 @@ type PulseConfig struct {
 	MaintenanceInterval time.Duration `mapstructure:"maintenance_interval"`
 	CorrelationEnabled  bool          `mapstructure:"correlation_enabled"`
 	CorrelationWindow   time.Duration `mapstructure:"correlation_window"`
+	AgentAlerts         bool          `mapstructure:"agent_alerts"`
+	Jitter              bool          `mapstructure:"jitter"`
+
+	// EventLogRules raise alerts for matching event log entries forwarded
+	// by Scout agents.
+	EventLogRules []EventLogRule `mapstructure:"event_log_rules"`
+
+	// HardwareThresholds raise alerts when Scout agent GPU or temperature
+	// readings exceed a limit.
+	HardwareThresholds []HardwareThreshold `mapstructure:"hardware_thresholds"`
+}
+
+// HardwareThreshold alerts when an agent hardware metric exceeds Above. The
+// alert resolves once every matching reading is back at or below it.
+type HardwareThreshold struct {
+	Name     string  `mapstructure:"name"`
+	Metric   string  `mapstructure:"metric"`   // e.g. gpu_temperature, cpu_temperature
+	Label    string  `mapstructure:"label"`    // GPU (e.g. gpu0) or sensor; empty matches all
+	Above    float64 `mapstructure:"above"`    // Limit in the metric's unit
+	Severity string  `mapstructure:"severity"` // warning (default) or critical
+}
+
+// EventLogRule matches forwarded event log entries. Empty fields match any
+// event; a rule needs at least one non-empty matcher besides Name.
+type EventLogRule struct {
+	Name     string   `mapstructure:"name"`
+	Channel  string   `mapstructure:"channel"`  // e.g. System; case-insensitive
+	Source   string   `mapstructure:"source"`   // Event provider; case-insensitive
+	EventID  uint32   `mapstructure:"event_id"` // 0 matches any ID
+	Levels   []string `mapstructure:"levels"`   // e.g. [critical, error]
+	Pattern  string   `mapstructure:"pattern"`  // Regular expression matched against the message
+	Severity string   `mapstructure:"severity"` // warning (default) or critical
 }
 
 func DefaultConfig() PulseConfig {
@@ -25,5 +57,7 @@ func DefaultConfig() PulseConfig {
 		MaintenanceInterval: 1 * time.Hour,
 		CorrelationEnabled:  true,
 		CorrelationWindow:   5 * time.Minute,
+		AgentAlerts:         true,
+		Jitter:              true,
 	}
 }
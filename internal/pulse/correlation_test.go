@@ -44,7 +44,8 @@ func correlationTestStore(t *testing.T) *PulseStore {
 		custom_fields    TEXT NOT NULL DEFAULT '{}',
 		parent_device_id TEXT NOT NULL DEFAULT '',
 		network_layer    INTEGER NOT NULL DEFAULT 0,
-		connection_type  TEXT NOT NULL DEFAULT ''
+		connection_type  TEXT NOT NULL DEFAULT '',
+		deleted_at       DATETIME
 	)`)
 	if err != nil {
 		t.Fatalf("create recon_devices table: %v", err)
@@ -332,6 +333,38 @@ func TestGetParentActiveAlerts_ReturnsParentAlerts(t *testing.T) {
 	}
 }
 
+func TestGetParentActiveAlerts_IgnoresTrashedDevices(t *testing.T) {
+	ps := correlationTestStore(t)
+	ctx := context.Background()
+
+	insertTestDevice(t, ps, "router-1", "router", "")
+	insertTestDevice(t, ps, "switch-1", "switch", "router-1")
+	insertCorrelationCheck(t, ps, "chk-router", "router-1")
+	insertTestAlert(t, ps, &Alert{
+		ID:                  "alert-router-1",
+		CheckID:             "chk-router",
+		DeviceID:            "router-1",
+		Severity:            "critical",
+		Message:             "router down",
+		TriggeredAt:         time.Now().UTC().Add(-1 * time.Minute),
+		ConsecutiveFailures: 5,
+	})
+
+	if _, err := ps.db.ExecContext(ctx,
+		`UPDATE recon_devices SET deleted_at = CURRENT_TIMESTAMP WHERE id = 'router-1'`,
+	); err != nil {
+		t.Fatalf("trash parent: %v", err)
+	}
+
+	alerts, parentID, err := ps.GetParentActiveAlerts(ctx, "switch-1", 5*time.Minute)
+	if err != nil {
+		t.Fatalf("GetParentActiveAlerts: %v", err)
+	}
+	if parentID != "" || len(alerts) != 0 {
+		t.Errorf("got parent %q with %d alerts, want none for trashed parent", parentID, len(alerts))
+	}
+}
+
 func TestAlerter_CorrelationIntegration(t *testing.T) {
 	ps := correlationTestStore(t)
 	bus := &mockEventBus{}
@@ -0,0 +1,193 @@
+package pulse
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+	"github.com/HerbHall/subnetree/internal/dispatch"
+	"github.com/HerbHall/subnetree/pkg/plugin"
+	"go.uber.org/zap"
+)
+
+// eventLogCheckType marks the passive checks that back event log alerts.
+// Like agent checks they are created disabled and driven by dispatch events.
+const eventLogCheckType = "eventlog"
+
+// eventLogCheckID returns the ID of the passive check for a rule on an agent.
+func eventLogCheckID(rule, agentID string) string {
+	return "eventlog-" + rule + "-" + agentID
+}
+
+// eventLogMatcher is a validated EventLogRule with its pattern compiled.
+type eventLogMatcher struct {
+	rule    EventLogRule
+	pattern *regexp.Regexp
+}
+
+// compileEventLogRules validates rules and compiles their patterns.
+func compileEventLogRules(rules []EventLogRule) ([]eventLogMatcher, error) {
+	matchers := make([]eventLogMatcher, 0, len(rules))
+	seen := make(map[string]bool, len(rules))
+	for i := range rules {
+		r := rules[i]
+		if r.Name == "" {
+			return nil, fmt.Errorf("rule %d: name is required", i)
+		}
+		if seen[r.Name] {
+			return nil, fmt.Errorf("rule %q: duplicate name", r.Name)
+		}
+		seen[r.Name] = true
+		if r.Channel == "" && r.Source == "" && r.EventID == 0 && len(r.Levels) == 0 && r.Pattern == "" {
+			return nil, fmt.Errorf("rule %q: at least one of channel, source, event_id, levels, or pattern is required", r.Name)
+		}
+		for _, level := range r.Levels {
+			if !slices.Contains(scoutpb.EventLevels, level) {
+				return nil, fmt.Errorf("rule %q: unknown level %q", r.Name, level)
+			}
+		}
+		switch r.Severity {
+		case "":
+			r.Severity = "warning"
+		case "warning", "critical":
+		default:
+			return nil, fmt.Errorf("rule %q: severity must be warning or critical", r.Name)
+		}
+		m := eventLogMatcher{rule: r}
+		if r.Pattern != "" {
+			re, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q: invalid pattern: %w", r.Name, err)
+			}
+			m.pattern = re
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// matches reports whether ev satisfies every matcher set on the rule.
+func (m *eventLogMatcher) matches(ev *dispatch.AgentEvent) bool {
+	r := &m.rule
+	switch {
+	case r.Channel != "" && !strings.EqualFold(r.Channel, ev.Channel):
+		return false
+	case r.Source != "" && !strings.EqualFold(r.Source, ev.Source):
+		return false
+	case r.EventID != 0 && r.EventID != ev.EventID:
+		return false
+	case len(r.Levels) > 0 && !slices.Contains(r.Levels, ev.Level):
+		return false
+	case m.pattern != nil && !m.pattern.MatchString(ev.Message):
+		return false
+	}
+	return true
+}
+
+// handleAgentEvents raises an alert for each event log rule matched by a
+// batch of forwarded events. A rule keeps at most one active alert per
+// agent; further matches are ignored until it is resolved.
+func (m *Module) handleAgentEvents(ctx context.Context, event plugin.Event) {
+	if m.store == nil || len(m.eventRules) == 0 {
+		return
+	}
+
+	ae, ok := event.Payload.(*dispatch.AgentEventsEvent)
+	if !ok {
+		m.logger.Warn("unexpected payload type for agent events event")
+		return
+	}
+
+	for i := range m.eventRules {
+		matcher := &m.eventRules[i]
+		for j := range ae.Events {
+			if matcher.matches(&ae.Events[j]) {
+				m.raiseEventLogAlert(ctx, &matcher.rule, ae, &ae.Events[j])
+				break
+			}
+		}
+	}
+}
+
+// raiseEventLogAlert opens an alert for rule on the agent unless one is
+// already active.
+func (m *Module) raiseEventLogAlert(ctx context.Context, rule *EventLogRule, ae *dispatch.AgentEventsEvent, ev *dispatch.AgentEvent) {
+	checkID := eventLogCheckID(rule.Name, ae.AgentID)
+	if err := m.ensureEventLogCheck(ctx, checkID, rule, ae); err != nil {
+		m.logger.Warn("failed to create event log check", zap.String("check_id", checkID), zap.Error(err))
+		return
+	}
+
+	existing, err := m.store.GetActiveAlert(ctx, checkID)
+	if err != nil {
+		m.logger.Warn("failed to check existing alert", zap.String("check_id", checkID), zap.Error(err))
+		return
+	}
+	if existing != nil {
+		return
+	}
+
+	name := ae.Hostname
+	if name == "" {
+		name = ae.AgentID
+	}
+	message := fmt.Sprintf("%s: %s event %d on %s", rule.Name, ev.Source, ev.EventID, name)
+	if ev.Message != "" {
+		message += ": " + ev.Message
+	}
+
+	now := time.Now().UTC()
+	alert := &Alert{
+		ID:          fmt.Sprintf("alert-%s-%d", checkID, now.UnixMilli()),
+		CheckID:     checkID,
+		DeviceID:    ae.DeviceID,
+		Severity:    rule.Severity,
+		Message:     message,
+		TriggeredAt: now,
+	}
+	if err := m.store.InsertAlert(ctx, alert); err != nil {
+		m.logger.Warn("failed to insert event log alert", zap.String("check_id", checkID), zap.Error(err))
+		return
+	}
+
+	m.logger.Warn("event log alert triggered",
+		zap.String("alert_id", alert.ID),
+		zap.String("rule", rule.Name),
+		zap.String("agent_id", ae.AgentID),
+	)
+
+	if m.bus != nil {
+		m.bus.PublishAsync(ctx, plugin.Event{
+			Topic:     TopicAlertTriggered,
+			Source:    "pulse",
+			Timestamp: now,
+			Payload:   alert,
+		})
+	}
+}
+
+// ensureEventLogCheck creates the passive check row an event log alert references.
+func (m *Module) ensureEventLogCheck(ctx context.Context, checkID string, rule *EventLogRule, ae *dispatch.AgentEventsEvent) error {
+	existing, err := m.store.GetCheck(ctx, checkID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	return m.store.InsertCheck(ctx, &Check{
+		ID:        checkID,
+		DeviceID:  ae.DeviceID,
+		CheckType: eventLogCheckType,
+		Target:    ae.AgentID + "/" + rule.Name,
+		Enabled:   false,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
@@ -0,0 +1,132 @@
+package pulse
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/HerbHall/subnetree/internal/dispatch"
+	"github.com/HerbHall/subnetree/pkg/plugin"
+)
+
+func TestCompileEventLogRules(t *testing.T) {
+	rules, err := compileEventLogRules([]EventLogRule{{Name: "spooler", Source: "Service Control Manager", EventID: 7031}})
+	if err != nil {
+		t.Fatalf("compileEventLogRules: %v", err)
+	}
+	if rules[0].rule.Severity != "warning" {
+		t.Errorf("default severity = %q, want warning", rules[0].rule.Severity)
+	}
+
+	for name, rule := range map[string]EventLogRule{
+		"no name":     {Source: "disk"},
+		"no matcher":  {Name: "all"},
+		"bad level":   {Name: "x", Levels: []string{"fatal"}},
+		"bad pattern": {Name: "x", Pattern: "("},
+		"bad sev":     {Name: "x", Source: "disk", Severity: "info"},
+	} {
+		if _, err := compileEventLogRules([]EventLogRule{rule}); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if _, err := compileEventLogRules([]EventLogRule{{Name: "x", Source: "a"}, {Name: "x", Source: "b"}}); err == nil {
+		t.Error("duplicate names: expected error")
+	}
+}
+
+func TestEventLogMatcher(t *testing.T) {
+	rules, err := compileEventLogRules([]EventLogRule{{
+		Name:    "sql",
+		Channel: "application",
+		Source:  "mssqlserver",
+		Levels:  []string{"critical", "error"},
+		Pattern: `(?i)login failed`,
+	}})
+	if err != nil {
+		t.Fatalf("compileEventLogRules: %v", err)
+	}
+	m := &rules[0]
+
+	ev := dispatch.AgentEvent{Channel: "Application", Source: "MSSQLSERVER", Level: "error", Message: "Login failed for user 'sa'."}
+	if !m.matches(&ev) {
+		t.Errorf("expected match for %+v", ev)
+	}
+	for _, miss := range []dispatch.AgentEvent{
+		{Channel: "System", Source: "MSSQLSERVER", Level: "error", Message: "Login failed"},
+		{Channel: "Application", Source: "disk", Level: "error", Message: "Login failed"},
+		{Channel: "Application", Source: "MSSQLSERVER", Level: "warning", Message: "Login failed"},
+		{Channel: "Application", Source: "MSSQLSERVER", Level: "error", Message: "Backup completed"},
+	} {
+		if m.matches(&miss) {
+			t.Errorf("unexpected match for %+v", miss)
+		}
+	}
+}
+
+func TestHandleAgentEvents_RaisesAlertOnce(t *testing.T) {
+	m, ps := newTestModule(t)
+	rules, err := compileEventLogRules([]EventLogRule{{Name: "spooler", EventID: 7031, Severity: "critical"}})
+	if err != nil {
+		t.Fatalf("compileEventLogRules: %v", err)
+	}
+	m.eventRules = rules
+	ctx := context.Background()
+
+	evt := plugin.Event{
+		Topic: TopicAgentEvents,
+		Payload: &dispatch.AgentEventsEvent{
+			AgentID:  "agent-001",
+			Hostname: "srv01",
+			DeviceID: "dev-001",
+			Events: []dispatch.AgentEvent{
+				{Source: "disk", EventID: 153},
+				{Source: "Service Control Manager", EventID: 7031, Message: "The Print Spooler service terminated unexpectedly."},
+			},
+		},
+	}
+	m.handleAgentEvents(ctx, evt)
+	// A repeated match must not open a second alert.
+	m.handleAgentEvents(ctx, evt)
+
+	checkID := eventLogCheckID("spooler", "agent-001")
+	check, err := ps.GetCheck(ctx, checkID)
+	if err != nil || check == nil {
+		t.Fatalf("event log check not created: %v", err)
+	}
+	if check.Enabled || check.CheckType != eventLogCheckType {
+		t.Errorf("check = %+v, want disabled event log check", check)
+	}
+
+	alerts, err := ps.ListActiveAlerts(ctx, "dev-001")
+	if err != nil {
+		t.Fatalf("ListActiveAlerts: %v", err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("got %d active alerts, want 1", len(alerts))
+	}
+	if alerts[0].Severity != "critical" || !strings.Contains(alerts[0].Message, "Print Spooler") {
+		t.Errorf("alert = %+v", alerts[0])
+	}
+}
+
+func TestHandleAgentEvents_NoRules(t *testing.T) {
+	m, ps := newTestModule(t)
+	ctx := context.Background()
+
+	m.handleAgentEvents(ctx, plugin.Event{
+		Topic: TopicAgentEvents,
+		Payload: &dispatch.AgentEventsEvent{
+			AgentID:  "agent-001",
+			DeviceID: "dev-001",
+			Events:   []dispatch.AgentEvent{{EventID: 7031}},
+		},
+	})
+
+	alerts, err := ps.ListActiveAlerts(ctx, "dev-001")
+	if err != nil {
+		t.Fatalf("ListActiveAlerts: %v", err)
+	}
+	if len(alerts) != 0 {
+		t.Errorf("got %d alerts without rules, want 0", len(alerts))
+	}
+}
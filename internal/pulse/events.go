@@ -2,7 +2,11 @@ package pulse
 
 // Event topics consumed by the Pulse module.
 const (
-	TopicDeviceDiscovered = "recon.device.discovered"
+	TopicDeviceDiscovered  = "recon.device.discovered"
+	TopicAgentDisconnected = "dispatch.agent.disconnected"
+	TopicAgentReconnected  = "dispatch.agent.reconnected"
+	TopicAgentEvents       = "dispatch.agent.events"
+	TopicAgentHardware     = "dispatch.agent.hardware"
 )
 
 // Event topics published by the Pulse module.
@@ -0,0 +1,478 @@
+package pulse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/roles"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// configBundleVersion is the format version of exported bundles.
+const configBundleVersion = 1
+
+// ConfigBundle is a portable copy of the pulse configuration: checks,
+// notification channels, and maintenance windows. Devices are referenced by
+// ID and name so an import into another install can remap them.
+type ConfigBundle struct {
+	Version            int                   `json:"version"`
+	ExportedAt         time.Time             `json:"exported_at"`
+	Checks             []ExportedCheck       `json:"checks"`
+	Notifications      []ExportedChannel     `json:"notifications"`
+	MaintenanceWindows []ExportedMaintWindow `json:"maintenance_windows"`
+}
+
+// ExportedCheck is a check in a ConfigBundle.
+type ExportedCheck struct {
+	DeviceID        string `json:"device_id"`
+	DeviceName      string `json:"device_name,omitempty"` // Hostname or IP used to remap the device on import
+	CheckType       string `json:"check_type"`
+	Target          string `json:"target"`
+	IntervalSeconds int    `json:"interval_seconds"`
+	AgentID         string `json:"agent_id,omitempty"`
+	RetentionDays   int    `json:"retention_days,omitempty"`
+	WarningAfter    int    `json:"warning_after,omitempty"`
+	CriticalAfter   int    `json:"critical_after,omitempty"`
+	Enabled         bool   `json:"enabled"`
+}
+
+// ExportedChannel is a notification channel in a ConfigBundle.
+type ExportedChannel struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Config  string `json:"config"` // Secrets are "****" unless exported with include_secrets=true
+	Enabled bool   `json:"enabled"`
+}
+
+// ExportedMaintWindow is a maintenance window in a ConfigBundle.
+type ExportedMaintWindow struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	StartTime   time.Time           `json:"start_time"`
+	EndTime     time.Time           `json:"end_time"`
+	Recurrence  string              `json:"recurrence"`
+	Timezone    string              `json:"timezone,omitempty"`
+	Devices     []ExportedDeviceRef `json:"devices"`
+	Enabled     bool                `json:"enabled"`
+}
+
+// ExportedDeviceRef references a device by its ID in the exporting install
+// and its name.
+type ExportedDeviceRef struct {
+	DeviceID   string `json:"device_id"`
+	DeviceName string `json:"device_name,omitempty"`
+}
+
+// importRequest is the JSON body for POST /import: an exported bundle plus
+// an optional mapping of exported device IDs to device IDs in this install.
+type importRequest struct {
+	ConfigBundle
+	DeviceMap map[string]string `json:"device_map,omitempty"`
+}
+
+// ImportReport summarizes a configuration import.
+type ImportReport struct {
+	ChecksCreated        int          `json:"checks_created"`
+	NotificationsCreated int          `json:"notifications_created"`
+	MaintWindowsCreated  int          `json:"maintenance_windows_created"`
+	Skipped              []ImportSkip `json:"skipped"`
+}
+
+// ImportSkip records a bundle entry that was not imported, or was imported
+// with a caveat.
+type ImportSkip struct {
+	Kind   string `json:"kind"` // "check", "notification", or "maintenance_window"
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// discoveryProvider returns the plugin providing discovered devices, or nil
+// when none is registered.
+func (m *Module) discoveryProvider() roles.DiscoveryProvider {
+	if m.discovery != nil {
+		return m.discovery
+	}
+	if m.plugins == nil {
+		return nil
+	}
+	for _, p := range m.plugins.ResolveByRole(roles.RoleDiscovery) {
+		if dp, ok := p.(roles.DiscoveryProvider); ok {
+			return dp
+		}
+	}
+	return nil
+}
+
+// deviceResolver maps device IDs of an exported bundle to devices in this
+// install: through the explicit mapping first, then by an unchanged ID, then
+// by a unique hostname or IP address match on the exported device name.
+type deviceResolver struct {
+	mapping map[string]string
+	ids     map[string]bool
+	names   map[string][]string // lowercased hostname or IP -> device IDs
+}
+
+func newDeviceResolver(ctx context.Context, dp roles.DiscoveryProvider, mapping map[string]string) (*deviceResolver, error) {
+	r := &deviceResolver{
+		mapping: mapping,
+		ids:     make(map[string]bool),
+		names:   make(map[string][]string),
+	}
+	if dp == nil {
+		return r, nil
+	}
+	devices, err := dp.Devices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list devices: %w", err)
+	}
+	for i := range devices {
+		d := &devices[i]
+		r.ids[d.ID] = true
+		keys := append([]string{d.Hostname}, d.IPAddresses...)
+		for _, k := range keys {
+			k = strings.ToLower(strings.TrimSpace(k))
+			if k != "" && !slices.Contains(r.names[k], d.ID) {
+				r.names[k] = append(r.names[k], d.ID)
+			}
+		}
+	}
+	return r, nil
+}
+
+// resolve returns the local device ID for an exported device, or an empty
+// ID and the reason it could not be resolved.
+func (r *deviceResolver) resolve(deviceID, deviceName string) (id, reason string) {
+	if mapped, ok := r.mapping[deviceID]; ok && mapped != "" {
+		return mapped, ""
+	}
+	if r.ids[deviceID] {
+		return deviceID, ""
+	}
+	if deviceName == "" {
+		return "", fmt.Sprintf("device %s not found", deviceID)
+	}
+	switch matches := r.names[strings.ToLower(deviceName)]; len(matches) {
+	case 0:
+		return "", fmt.Sprintf("device %s (%s) not found", deviceID, deviceName)
+	case 1:
+		return matches[0], ""
+	default:
+		return "", fmt.Sprintf("device name %s matches %d devices", deviceName, len(matches))
+	}
+}
+
+// handleExportConfig returns the pulse configuration as a portable bundle.
+//
+//	@Summary		Export monitoring configuration
+//	@Description	Returns checks, notification channels, and maintenance windows as a JSON bundle for backup or POST /pulse/import. Notification secrets are masked unless include_secrets=true.
+//	@Tags			pulse
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			include_secrets query bool false "Export notification secrets unmasked"
+//	@Success		200 {object} ConfigBundle
+//	@Failure		500 {object} map[string]any
+//	@Router			/pulse/checks/export [get]
+func (m *Module) handleExportConfig(w http.ResponseWriter, r *http.Request) {
+	if m.store == nil {
+		pulseWriteError(w, http.StatusServiceUnavailable, "pulse store not available")
+		return
+	}
+	ctx := r.Context()
+	includeSecrets := r.URL.Query().Get("include_secrets") == "true"
+
+	checks, err := m.store.ListAllChecks(ctx)
+	if err != nil {
+		m.logger.Warn("failed to list checks for export", zap.Error(err))
+		pulseWriteError(w, http.StatusInternalServerError, "failed to export configuration")
+		return
+	}
+	channels, err := m.store.ListChannels(ctx)
+	if err != nil {
+		m.logger.Warn("failed to list notification channels for export", zap.Error(err))
+		pulseWriteError(w, http.StatusInternalServerError, "failed to export configuration")
+		return
+	}
+	windows, err := m.store.ListMaintWindows(ctx)
+	if err != nil {
+		m.logger.Warn("failed to list maintenance windows for export", zap.Error(err))
+		pulseWriteError(w, http.StatusInternalServerError, "failed to export configuration")
+		return
+	}
+
+	bundle := ConfigBundle{
+		Version:            configBundleVersion,
+		ExportedAt:         time.Now().UTC(),
+		Checks:             make([]ExportedCheck, 0, len(checks)),
+		Notifications:      make([]ExportedChannel, 0, len(channels)),
+		MaintenanceWindows: make([]ExportedMaintWindow, 0, len(windows)),
+	}
+	deviceNames := make(map[string]string)
+	for i := range checks {
+		c := &checks[i]
+		name := c.DeviceName
+		if name == c.DeviceID {
+			name = ""
+		}
+		deviceNames[c.DeviceID] = name
+		bundle.Checks = append(bundle.Checks, ExportedCheck{
+			DeviceID:        c.DeviceID,
+			DeviceName:      name,
+			CheckType:       c.CheckType,
+			Target:          c.Target,
+			IntervalSeconds: c.IntervalSeconds,
+			AgentID:         c.AgentID,
+			RetentionDays:   c.RetentionDays,
+			WarningAfter:    c.WarningAfter,
+			CriticalAfter:   c.CriticalAfter,
+			Enabled:         c.Enabled,
+		})
+	}
+	for i := range channels {
+		ch := &channels[i]
+		cfg := ch.Config
+		if !includeSecrets {
+			cfg = maskChannelConfig(cfg)
+		}
+		bundle.Notifications = append(bundle.Notifications, ExportedChannel{
+			Name:    ch.Name,
+			Type:    ch.Type,
+			Config:  cfg,
+			Enabled: ch.Enabled,
+		})
+	}
+	for i := range windows {
+		mw := &windows[i]
+		devices := make([]ExportedDeviceRef, 0, len(mw.DeviceIDs))
+		for _, id := range mw.DeviceIDs {
+			devices = append(devices, ExportedDeviceRef{DeviceID: id, DeviceName: deviceNames[id]})
+		}
+		bundle.MaintenanceWindows = append(bundle.MaintenanceWindows, ExportedMaintWindow{
+			Name:        mw.Name,
+			Description: mw.Description,
+			StartTime:   mw.StartTime,
+			EndTime:     mw.EndTime,
+			Recurrence:  mw.Recurrence,
+			Timezone:    mw.Timezone,
+			Devices:     devices,
+			Enabled:     mw.Enabled,
+		})
+	}
+
+	pulseWriteJSON(w, http.StatusOK, bundle)
+}
+
+// handleImportConfig recreates checks, notification channels, and
+// maintenance windows from an exported bundle.
+//
+//	@Summary		Import monitoring configuration
+//	@Description	Recreates the entries of an exported bundle. Device IDs are remapped through device_map, an unchanged ID, or a unique hostname/IP match on the exported device name. Entries whose device cannot be resolved, that fail validation, or that already exist are skipped and reported. Channels with masked secrets are imported disabled.
+//	@Tags			pulse
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			body body importRequest true "Exported bundle and optional device_map"
+//	@Success		200 {object} ImportReport
+//	@Failure		400 {object} map[string]any
+//	@Failure		500 {object} map[string]any
+//	@Router			/pulse/import [post]
+func (m *Module) handleImportConfig(w http.ResponseWriter, r *http.Request) {
+	if m.store == nil {
+		pulseWriteError(w, http.StatusServiceUnavailable, "pulse store not available")
+		return
+	}
+	var req importRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pulseWriteError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if req.Version != configBundleVersion {
+		pulseWriteError(w, http.StatusBadRequest, fmt.Sprintf("unsupported bundle version %d", req.Version))
+		return
+	}
+
+	report, err := m.importConfig(r.Context(), &req)
+	if err != nil {
+		m.logger.Warn("failed to import configuration", zap.Error(err))
+		pulseWriteError(w, http.StatusInternalServerError, "failed to import configuration")
+		return
+	}
+	pulseWriteJSON(w, http.StatusOK, report)
+}
+
+// importConfig creates the entries of req, skipping those that cannot be
+// resolved, are invalid, or duplicate existing configuration.
+func (m *Module) importConfig(ctx context.Context, req *importRequest) (*ImportReport, error) {
+	resolver, err := newDeviceResolver(ctx, m.discoveryProvider(), req.DeviceMap)
+	if err != nil {
+		return nil, err
+	}
+	report := &ImportReport{Skipped: []ImportSkip{}}
+	skip := func(kind, name, reason string) {
+		report.Skipped = append(report.Skipped, ImportSkip{Kind: kind, Name: name, Reason: reason})
+	}
+	now := time.Now().UTC()
+
+	existingChecks, err := m.store.ListAllChecks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range req.Checks {
+		ec := &req.Checks[i]
+		name := ec.CheckType + " " + ec.Target
+		deviceID, reason := resolver.resolve(ec.DeviceID, ec.DeviceName)
+		if deviceID == "" {
+			skip("check", name, reason)
+			continue
+		}
+		if err := m.validateImportedCheck(ec); err != nil {
+			skip("check", name, err.Error())
+			continue
+		}
+		if slices.ContainsFunc(existingChecks, func(c Check) bool {
+			return c.DeviceID == deviceID && c.CheckType == ec.CheckType && c.Target == ec.Target
+		}) {
+			skip("check", name, "check already exists")
+			continue
+		}
+		check := Check{
+			ID:              uuid.New().String(),
+			DeviceID:        deviceID,
+			CheckType:       ec.CheckType,
+			Target:          ec.Target,
+			IntervalSeconds: ec.IntervalSeconds,
+			AgentID:         ec.AgentID,
+			RetentionDays:   ec.RetentionDays,
+			WarningAfter:    ec.WarningAfter,
+			CriticalAfter:   ec.CriticalAfter,
+			Enabled:         ec.Enabled,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+		if check.IntervalSeconds <= 0 {
+			check.IntervalSeconds = 30
+		}
+		if err := m.store.InsertCheck(ctx, &check); err != nil {
+			return nil, err
+		}
+		existingChecks = append(existingChecks, check)
+		report.ChecksCreated++
+	}
+
+	existingChannels, err := m.store.ListChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range req.Notifications {
+		ec := &req.Notifications[i]
+		if ec.Name == "" {
+			skip("notification", ec.Name, "name is required")
+			continue
+		}
+		if slices.ContainsFunc(existingChannels, func(ch NotificationChannel) bool {
+			return ch.Name == ec.Name && ch.Type == ec.Type
+		}) {
+			skip("notification", ec.Name, "notification channel already exists")
+			continue
+		}
+		ch := NotificationChannel{
+			ID:        fmt.Sprintf("notif-%s-%s", ec.Type, uuid.New().String()),
+			Name:      ec.Name,
+			Type:      ec.Type,
+			Config:    ec.Config,
+			Enabled:   ec.Enabled,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if _, err := buildNotifier(ch, nil); err != nil || !json.Valid([]byte(ch.Config)) {
+			skip("notification", ec.Name, "invalid notification channel type or config")
+			continue
+		}
+		if strings.Contains(ch.Config, `"****"`) {
+			ch.Enabled = false
+			skip("notification", ec.Name, "imported disabled: secrets were masked in the export")
+		}
+		if err := m.store.InsertChannel(ctx, &ch); err != nil {
+			return nil, err
+		}
+		existingChannels = append(existingChannels, ch)
+		report.NotificationsCreated++
+	}
+
+	existingWindows, err := m.store.ListMaintWindows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range req.MaintenanceWindows {
+		ew := &req.MaintenanceWindows[i]
+		if ew.Name == "" || !validRecurrence[ew.Recurrence] || !ew.EndTime.After(ew.StartTime) {
+			skip("maintenance_window", ew.Name, "invalid name, recurrence, or time range")
+			continue
+		}
+		if _, err := time.LoadLocation(ew.Timezone); err != nil {
+			skip("maintenance_window", ew.Name, "invalid timezone")
+			continue
+		}
+		if slices.ContainsFunc(existingWindows, func(mw MaintWindow) bool { return mw.Name == ew.Name }) {
+			skip("maintenance_window", ew.Name, "maintenance window already exists")
+			continue
+		}
+		var deviceIDs []string
+		for _, d := range ew.Devices {
+			id, reason := resolver.resolve(d.DeviceID, d.DeviceName)
+			if id == "" {
+				skip("maintenance_window", ew.Name, "dropped "+reason)
+				continue
+			}
+			deviceIDs = append(deviceIDs, id)
+		}
+		if len(deviceIDs) == 0 {
+			skip("maintenance_window", ew.Name, "no devices could be resolved")
+			continue
+		}
+		mw := MaintWindow{
+			ID:          uuid.New().String(),
+			Name:        ew.Name,
+			Description: ew.Description,
+			StartTime:   ew.StartTime.UTC(),
+			EndTime:     ew.EndTime.UTC(),
+			Recurrence:  ew.Recurrence,
+			Timezone:    ew.Timezone,
+			DeviceIDs:   deviceIDs,
+			Enabled:     ew.Enabled,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if err := m.store.InsertMaintWindow(ctx, &mw); err != nil {
+			return nil, err
+		}
+		existingWindows = append(existingWindows, mw)
+		report.MaintWindowsCreated++
+	}
+
+	return report, nil
+}
+
+// validateImportedCheck applies the create-check validation to a bundle entry.
+func (m *Module) validateImportedCheck(ec *ExportedCheck) error {
+	switch ec.CheckType {
+	case "icmp", "tcp", "http":
+	default:
+		return fmt.Errorf("check_type must be icmp, tcp, or http")
+	}
+	if err := validateTarget(ec.CheckType, ec.Target); err != nil {
+		return err
+	}
+	if ec.AgentID != "" && m.agentCheckRunner() == nil {
+		return fmt.Errorf("agent_id requires the dispatch module")
+	}
+	if err := validateRetentionDays(ec.RetentionDays); err != nil {
+		return err
+	}
+	return m.validateThresholds(ec.WarningAfter, ec.CriticalAfter)
+}
@@ -0,0 +1,157 @@
+package pulse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+// fakeDiscovery is a roles.DiscoveryProvider over a fixed device list.
+type fakeDiscovery struct {
+	devices []models.Device
+}
+
+func (f *fakeDiscovery) Devices(_ context.Context) ([]models.Device, error) {
+	return f.devices, nil
+}
+
+func (f *fakeDiscovery) DeviceByID(_ context.Context, id string) (*models.Device, error) {
+	for i := range f.devices {
+		if f.devices[i].ID == id {
+			return &f.devices[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func TestExportImportConfig(t *testing.T) {
+	src, srcStore := newTestModule(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	if _, err := srcStore.db.ExecContext(ctx,
+		`INSERT INTO recon_devices (id, hostname, ip_addresses) VALUES ('old-router', 'router', '["10.0.0.1"]'), ('old-nas', 'nas', '["10.0.0.5"]')`,
+	); err != nil {
+		t.Fatalf("insert devices: %v", err)
+	}
+	for _, c := range []*Check{
+		{ID: "c1", DeviceID: "old-router", CheckType: "icmp", Target: "10.0.0.1", IntervalSeconds: 30, WarningAfter: 2, CriticalAfter: 4, Enabled: true},
+		{ID: "c2", DeviceID: "old-nas", CheckType: "tcp", Target: "10.0.0.5:445", IntervalSeconds: 60, Enabled: true},
+	} {
+		c.CreatedAt, c.UpdatedAt = now, now
+		insertTestCheck(t, srcStore, c)
+	}
+	if err := srcStore.InsertChannel(ctx, &NotificationChannel{
+		ID: "n1", Name: "ops", Type: "webhook", Enabled: true, CreatedAt: now, UpdatedAt: now,
+		Config: `{"url":"https://hooks.example.com/x","secret":"s3cret"}`,
+	}); err != nil {
+		t.Fatalf("InsertChannel: %v", err)
+	}
+	if err := srcStore.InsertMaintWindow(ctx, &MaintWindow{
+		ID: "mw1", Name: "patching", StartTime: now, EndTime: now.Add(time.Hour), Recurrence: "weekly",
+		DeviceIDs: []string{"old-router", "old-nas"}, Enabled: true, CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("InsertMaintWindow: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	src.handleExportConfig(w, httptest.NewRequest(http.MethodGet, "/checks/export", http.NoBody))
+	if w.Code != http.StatusOK {
+		t.Fatalf("export status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	exported := w.Body.Bytes()
+	var bundle ConfigBundle
+	if err := json.Unmarshal(exported, &bundle); err != nil {
+		t.Fatalf("decode bundle: %v", err)
+	}
+	if len(bundle.Checks) != 2 || len(bundle.Notifications) != 1 || len(bundle.MaintenanceWindows) != 1 {
+		t.Fatalf("bundle = %+v, want 2 checks, 1 notification, 1 window", bundle)
+	}
+	if strings.Contains(bundle.Notifications[0].Config, "s3cret") {
+		t.Error("exported notification config contains unmasked secret")
+	}
+
+	// Import into a fresh install where the router has a new ID and the NAS
+	// is unknown.
+	dst, dstStore := newTestModule(t)
+	dst.discovery = &fakeDiscovery{devices: []models.Device{
+		{ID: "new-router", Hostname: "Router", IPAddresses: []string{"10.0.0.1"}},
+	}}
+	w = httptest.NewRecorder()
+	dst.handleImportConfig(w, httptest.NewRequest(http.MethodPost, "/import", bytes.NewReader(exported)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("import status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var report ImportReport
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if report.ChecksCreated != 1 || report.NotificationsCreated != 1 || report.MaintWindowsCreated != 1 {
+		t.Errorf("report = %+v, want 1 check, 1 notification, 1 window", report)
+	}
+	kinds := map[string]int{}
+	for _, s := range report.Skipped {
+		kinds[s.Kind]++
+	}
+	// The NAS check, the NAS in the window, and the masked channel.
+	if kinds["check"] != 1 || kinds["maintenance_window"] != 1 || kinds["notification"] != 1 {
+		t.Errorf("skipped = %+v, want one of each kind", report.Skipped)
+	}
+
+	checks, err := dstStore.ListAllChecks(ctx)
+	if err != nil {
+		t.Fatalf("ListAllChecks: %v", err)
+	}
+	if len(checks) != 1 || checks[0].DeviceID != "new-router" || checks[0].WarningAfter != 2 || checks[0].CriticalAfter != 4 {
+		t.Errorf("imported checks = %+v, want router check remapped to new-router", checks)
+	}
+	channels, err := dstStore.ListChannels(ctx)
+	if err != nil {
+		t.Fatalf("ListChannels: %v", err)
+	}
+	if len(channels) != 1 || channels[0].Enabled {
+		t.Errorf("imported channels = %+v, want one disabled channel", channels)
+	}
+	windows, err := dstStore.ListMaintWindows(ctx)
+	if err != nil {
+		t.Fatalf("ListMaintWindows: %v", err)
+	}
+	if len(windows) != 1 || len(windows[0].DeviceIDs) != 1 || windows[0].DeviceIDs[0] != "new-router" {
+		t.Errorf("imported windows = %+v, want patching on new-router", windows)
+	}
+
+	// A second import with an explicit mapping adds only the NAS check.
+	var req map[string]any
+	if err := json.Unmarshal(exported, &req); err != nil {
+		t.Fatalf("decode bundle: %v", err)
+	}
+	req["device_map"] = map[string]string{"old-nas": "nas-id"}
+	body, _ := json.Marshal(req)
+	w = httptest.NewRecorder()
+	dst.handleImportConfig(w, httptest.NewRequest(http.MethodPost, "/import", bytes.NewReader(body)))
+	report = ImportReport{}
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if report.ChecksCreated != 1 || report.NotificationsCreated != 0 || report.MaintWindowsCreated != 0 {
+		t.Errorf("second import report = %+v, want only the mapped NAS check", report)
+	}
+}
+
+func TestHandleImportConfig_UnsupportedVersion(t *testing.T) {
+	m, _ := newTestModule(t)
+
+	w := httptest.NewRecorder()
+	m.handleImportConfig(w, httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(`{"version":99}`)))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
@@ -1,7 +1,11 @@
 package pulse
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -12,6 +16,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/HerbHall/subnetree/internal/pagination"
 	"github.com/HerbHall/subnetree/pkg/plugin"
 	"go.uber.org/zap"
 )
@@ -22,14 +27,22 @@ type createCheckRequest struct {
 	CheckType       string `json:"check_type"`
 	Target          string `json:"target"`
 	IntervalSeconds int    `json:"interval_seconds"`
+	AgentID         string `json:"agent_id,omitempty"`       // Run from this Scout agent instead of the server
+	RetentionDays   int    `json:"retention_days,omitempty"` // Days of results to keep; 0 uses the global retention period
+	WarningAfter    int    `json:"warning_after,omitempty"`  // Consecutive failures before a warning alert; 0 uses the global default
+	CriticalAfter   int    `json:"critical_after,omitempty"` // Consecutive failures before the alert is critical; 0 uses twice warning_after
 }
 
 // updateCheckRequest is the JSON body for PUT /checks/{id}.
 type updateCheckRequest struct {
-	Target          string `json:"target,omitempty"`
-	CheckType       string `json:"check_type,omitempty"`
-	IntervalSeconds int    `json:"interval_seconds,omitempty"`
-	Enabled         *bool  `json:"enabled,omitempty"`
+	Target          string  `json:"target,omitempty"`
+	CheckType       string  `json:"check_type,omitempty"`
+	IntervalSeconds int     `json:"interval_seconds,omitempty"`
+	AgentID         *string `json:"agent_id,omitempty"`       // Empty string moves the check back to the server
+	RetentionDays   *int    `json:"retention_days,omitempty"` // 0 returns the check to the global retention period
+	WarningAfter    *int    `json:"warning_after,omitempty"`  // 0 returns the check to the global default
+	CriticalAfter   *int    `json:"critical_after,omitempty"` // 0 returns the check to twice warning_after
+	Enabled         *bool   `json:"enabled,omitempty"`
 }
 
 // createNotificationRequest is the JSON body for POST /notifications.
@@ -53,6 +66,7 @@ type createMaintWindowRequest struct {
 	StartTime   string   `json:"start_time"`
 	EndTime     string   `json:"end_time"`
 	Recurrence  string   `json:"recurrence"`
+	Timezone    string   `json:"timezone"`
 	DeviceIDs   []string `json:"device_ids"`
 }
 
@@ -63,6 +77,7 @@ type updateMaintWindowRequest struct {
 	StartTime   string   `json:"start_time,omitempty"`
 	EndTime     string   `json:"end_time,omitempty"`
 	Recurrence  string   `json:"recurrence,omitempty"`
+	Timezone    *string  `json:"timezone,omitempty"`
 	DeviceIDs   []string `json:"device_ids,omitempty"`
 	Enabled     *bool    `json:"enabled,omitempty"`
 }
@@ -72,6 +87,8 @@ func (m *Module) Routes() []plugin.Route {
 	return []plugin.Route{
 		{Method: "GET", Path: "/checks", Handler: m.handleListChecks},
 		{Method: "POST", Path: "/checks", Handler: m.handleCreateCheck},
+		{Method: "GET", Path: "/checks/export", Handler: m.handleExportConfig},
+		{Method: "POST", Path: "/import", Handler: m.handleImportConfig},
 		{Method: "GET", Path: "/checks/{device_id}", Handler: m.handleDeviceChecks},
 		{Method: "PUT", Path: "/checks/{id}", Handler: m.handleUpdateCheck},
 		{Method: "DELETE", Path: "/checks/{id}", Handler: m.handleDeleteCheck},
@@ -86,6 +103,7 @@ func (m *Module) Routes() []plugin.Route {
 		{Method: "GET", Path: "/alerts/{id}", Handler: m.handleGetAlert},
 		{Method: "POST", Path: "/alerts/{id}/acknowledge", Handler: m.handleAcknowledgeAlert},
 		{Method: "POST", Path: "/alerts/{id}/resolve", Handler: m.handleResolveAlert},
+		{Method: "GET", Path: "/incidents", Handler: m.handleListIncidents},
 		{Method: "GET", Path: "/status/{device_id}", Handler: m.handleDeviceStatus},
 		{Method: "GET", Path: "/notifications", Handler: m.handleListNotifications},
 		{Method: "GET", Path: "/notifications/{id}", Handler: m.handleGetNotification},
@@ -104,11 +122,14 @@ func (m *Module) Routes() []plugin.Route {
 // handleListChecks returns all registered monitoring checks.
 //
 //	@Summary		List checks
-//	@Description	Returns all monitoring checks (enabled and disabled).
+//	@Description	Returns all monitoring checks (enabled and disabled). Responses carry a weak ETag; send it back in If-None-Match to get 304 when nothing changed.
 //	@Tags			pulse
 //	@Produce		json
 //	@Security		BearerAuth
+//	@Param			If-None-Match header string false "ETag from a previous response"
 //	@Success		200 {array} Check
+//	@Header			200 {integer} X-Total-Count "Total number of checks"
+//	@Success		304 "Not modified"
 //	@Failure		500 {object} map[string]any
 //	@Router			/pulse/checks [get]
 func (m *Module) handleListChecks(w http.ResponseWriter, r *http.Request) {
@@ -125,13 +146,14 @@ func (m *Module) handleListChecks(w http.ResponseWriter, r *http.Request) {
 	if checks == nil {
 		checks = []Check{}
 	}
-	pulseWriteJSON(w, http.StatusOK, checks)
+	w.Header().Set(pagination.TotalCountHeader, strconv.Itoa(len(checks)))
+	pulseWriteJSONWithETag(w, r, checks)
 }
 
 // handleCreateCheck creates a new monitoring check.
 //
 //	@Summary		Create check
-//	@Description	Creates a new monitoring check for a device.
+//	@Description	Creates a new monitoring check for a device. Set agent_id to run the check from a Scout agent's network location; its results arrive on the agent's next check-in, so the agent should check in more often than the pulse check interval.
 //	@Tags			pulse
 //	@Accept			json
 //	@Produce		json
@@ -153,33 +175,78 @@ func (m *Module) handleCreateCheck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.DeviceID == "" {
-		pulseWriteError(w, http.StatusBadRequest, "device_id is required")
+	check, err := m.CreateCheck(r.Context(), NewCheck(req))
+	if errors.Is(err, ErrInvalidCheck) {
+		pulseWriteError(w, http.StatusBadRequest, strings.TrimPrefix(err.Error(), ErrInvalidCheck.Error()+": "))
 		return
 	}
+	if err != nil {
+		m.logger.Warn("failed to create check", zap.Error(err))
+		pulseWriteError(w, http.StatusInternalServerError, "failed to create check")
+		return
+	}
+
+	pulseWriteJSON(w, http.StatusCreated, check)
+}
+
+// ErrInvalidCheck is returned by CreateCheck when the check definition
+// fails validation.
+var ErrInvalidCheck = errors.New("invalid check")
+
+// NewCheck is a check definition for CreateCheck. Zero values take the
+// same defaults as POST /checks.
+type NewCheck struct {
+	DeviceID        string
+	CheckType       string
+	Target          string
+	IntervalSeconds int
+	AgentID         string
+	RetentionDays   int
+	WarningAfter    int
+	CriticalAfter   int
+}
+
+// CreateCheck validates a check definition and stores it as an enabled
+// check. Validation failures wrap ErrInvalidCheck.
+func (m *Module) CreateCheck(ctx context.Context, req NewCheck) (*Check, error) {
+	if m.store == nil {
+		return nil, errors.New("pulse store not available")
+	}
+
+	if req.DeviceID == "" {
+		return nil, fmt.Errorf("%w: device_id is required", ErrInvalidCheck)
+	}
 
 	// Validate check_type.
 	switch req.CheckType {
 	case "icmp", "tcp", "http":
 		// valid
 	default:
-		pulseWriteError(w, http.StatusBadRequest, "check_type must be icmp, tcp, or http")
-		return
+		return nil, fmt.Errorf("%w: check_type must be icmp, tcp, or http", ErrInvalidCheck)
 	}
 
 	// Validate target based on check type.
 	if req.Target == "" {
-		pulseWriteError(w, http.StatusBadRequest, "target is required")
-		return
+		return nil, fmt.Errorf("%w: target is required", ErrInvalidCheck)
 	}
 	if err := validateTarget(req.CheckType, req.Target); err != nil {
-		pulseWriteError(w, http.StatusBadRequest, err.Error())
-		return
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCheck, err.Error())
 	}
 
 	if req.IntervalSeconds <= 0 {
 		req.IntervalSeconds = 30
 	}
+	if err := validateRetentionDays(req.RetentionDays); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCheck, err.Error())
+	}
+	if err := m.validateThresholds(req.WarningAfter, req.CriticalAfter); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCheck, err.Error())
+	}
+
+	req.AgentID = strings.TrimSpace(req.AgentID)
+	if req.AgentID != "" && m.agentCheckRunner() == nil {
+		return nil, fmt.Errorf("%w: agent_id requires the dispatch module", ErrInvalidCheck)
+	}
 
 	now := time.Now().UTC()
 	check := &Check{
@@ -188,18 +255,19 @@ func (m *Module) handleCreateCheck(w http.ResponseWriter, r *http.Request) {
 		CheckType:       req.CheckType,
 		Target:          req.Target,
 		IntervalSeconds: req.IntervalSeconds,
+		AgentID:         req.AgentID,
+		RetentionDays:   req.RetentionDays,
+		WarningAfter:    req.WarningAfter,
+		CriticalAfter:   req.CriticalAfter,
 		Enabled:         true,
 		CreatedAt:       now,
 		UpdatedAt:       now,
 	}
 
-	if err := m.store.InsertCheck(r.Context(), check); err != nil {
-		m.logger.Warn("failed to create check", zap.Error(err))
-		pulseWriteError(w, http.StatusInternalServerError, "failed to create check")
-		return
+	if err := m.store.InsertCheck(ctx, check); err != nil {
+		return nil, err
 	}
-
-	pulseWriteJSON(w, http.StatusCreated, check)
+	return check, nil
 }
 
 // handleUpdateCheck updates an existing monitoring check.
@@ -265,6 +333,33 @@ func (m *Module) handleUpdateCheck(w http.ResponseWriter, r *http.Request) {
 	if req.IntervalSeconds > 0 {
 		existing.IntervalSeconds = req.IntervalSeconds
 	}
+	if req.AgentID != nil {
+		agentID := strings.TrimSpace(*req.AgentID)
+		if agentID != "" && m.agentCheckRunner() == nil {
+			pulseWriteError(w, http.StatusBadRequest, "agent_id requires the dispatch module")
+			return
+		}
+		existing.AgentID = agentID
+	}
+	if req.RetentionDays != nil {
+		if err := validateRetentionDays(*req.RetentionDays); err != nil {
+			pulseWriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		existing.RetentionDays = *req.RetentionDays
+	}
+	if req.WarningAfter != nil {
+		existing.WarningAfter = *req.WarningAfter
+	}
+	if req.CriticalAfter != nil {
+		existing.CriticalAfter = *req.CriticalAfter
+	}
+	if req.WarningAfter != nil || req.CriticalAfter != nil {
+		if err := m.validateThresholds(existing.WarningAfter, existing.CriticalAfter); err != nil {
+			pulseWriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
 	if req.Enabled != nil {
 		existing.Enabled = *req.Enabled
 	}
@@ -457,7 +552,7 @@ func (m *Module) handleCorrelatedAlerts(w http.ResponseWriter, r *http.Request)
 // handleListAlerts returns alerts with optional filtering.
 //
 //	@Summary		List alerts
-//	@Description	Returns monitoring alerts with optional filters.
+//	@Description	Returns monitoring alerts with optional filters. The total count is in the X-Total-Count header and page links in the Link header.
 //	@Tags			pulse
 //	@Produce		json
 //	@Security		BearerAuth
@@ -465,7 +560,10 @@ func (m *Module) handleCorrelatedAlerts(w http.ResponseWriter, r *http.Request)
 //	@Param			severity query string false "Filter by severity (warning, critical)"
 //	@Param			active query bool false "Only active (unresolved) alerts" default(true)
 //	@Param			limit query int false "Maximum alerts" default(50)
+//	@Param			offset query int false "Offset" default(0)
 //	@Success		200 {array} Alert
+//	@Header			200 {integer} X-Total-Count "Total number of matching alerts"
+//	@Header			200 {string} Link "RFC 8288 first/prev/next/last page links"
 //	@Failure		500 {object} map[string]any
 //	@Router			/pulse/alerts [get]
 func (m *Module) handleListAlerts(w http.ResponseWriter, r *http.Request) {
@@ -480,6 +578,11 @@ func (m *Module) handleListAlerts(w http.ResponseWriter, r *http.Request) {
 		ActiveOnly: true,
 		Limit:      pulseParseLimit(r, 50),
 	}
+	if s := r.URL.Query().Get("offset"); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n > 0 {
+			filters.Offset = n
+		}
+	}
 
 	if activeStr := r.URL.Query().Get("active"); activeStr != "" {
 		filters.ActiveOnly = activeStr != "false"
@@ -495,9 +598,16 @@ func (m *Module) handleListAlerts(w http.ResponseWriter, r *http.Request) {
 		pulseWriteError(w, http.StatusInternalServerError, "failed to list alerts")
 		return
 	}
+	total, err := m.store.CountAlerts(r.Context(), filters)
+	if err != nil {
+		m.logger.Warn("failed to count alerts", zap.Error(err))
+		pulseWriteError(w, http.StatusInternalServerError, "failed to list alerts")
+		return
+	}
 	if alerts == nil {
 		alerts = []Alert{}
 	}
+	pagination.SetHeaders(w, r, total, filters.Limit, filters.Offset)
 	pulseWriteJSON(w, http.StatusOK, alerts)
 }
 
@@ -659,13 +769,14 @@ func (m *Module) handleDeviceStatus(w http.ResponseWriter, r *http.Request) {
 // handleDeviceMetrics returns time-series metrics for a device with automatic downsampling.
 //
 //	@Summary		Device metrics
-//	@Description	Returns time-series metrics for a device with automatic downsampling.
+//	@Description	Returns time-series metrics for a device with automatic downsampling. Use vantage to split results by where the check ran.
 //	@Tags			pulse
 //	@Produce		json
 //	@Security		BearerAuth
 //	@Param			device_id path string true "Device ID"
 //	@Param			metric query string true "Metric name" Enums(latency, packet_loss, success_rate)
 //	@Param			range query string false "Time range" Enums(1h, 6h, 24h, 7d, 30d) default(24h)
+//	@Param			vantage query string false "Only results run from the server ('server') or from this agent ID"
 //	@Success		200 {object} MetricSeries
 //	@Failure		400 {object} map[string]any
 //	@Failure		500 {object} map[string]any
@@ -701,7 +812,9 @@ func (m *Module) handleDeviceMetrics(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	series, err := m.store.QueryMetrics(r.Context(), deviceID, metric, timeRange)
+	vantage := strings.TrimSpace(r.URL.Query().Get("vantage"))
+
+	series, err := m.store.QueryVantageMetrics(r.Context(), deviceID, metric, timeRange, vantage)
 	if err != nil {
 		m.logger.Warn("failed to query metrics",
 			zap.String("device_id", deviceID),
@@ -846,6 +959,32 @@ func pulseWriteJSON(w http.ResponseWriter, status int, data any) {
 	_ = json.NewEncoder(w).Encode(data)
 }
 
+// pulseWriteJSONWithETag writes a 200 JSON response carrying a weak ETag
+// derived from the encoded body, or 304 Not Modified when the request's
+// If-None-Match matches it.
+func pulseWriteJSONWithETag(w http.ResponseWriter, r *http.Request, data any) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		pulseWriteError(w, http.StatusInternalServerError, "failed to encode response")
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(append(body, '\n'))
+}
+
 func pulseWriteError(w http.ResponseWriter, status int, detail string) {
 	w.Header().Set("Content-Type", "application/problem+json")
 	w.WriteHeader(status)
@@ -1135,7 +1274,8 @@ func (m *Module) handleTestNotification(w http.ResponseWriter, r *http.Request)
 		ConsecutiveFailures: 1,
 	}
 
-	notifier, err := buildNotifier(*ch)
+	// Test sends bypass the retry queue so the caller sees the result.
+	notifier, err := buildNotifier(*ch, nil)
 	if err != nil {
 		m.logger.Warn("failed to build notifier for test", zap.String("id", id), zap.Error(err))
 		pulseWriteError(w, http.StatusInternalServerError, "failed to build notifier: "+err.Error())
@@ -1161,19 +1301,26 @@ func (m *Module) handleTestNotification(w http.ResponseWriter, r *http.Request)
 	})
 }
 
-// maskChannelConfig replaces sensitive values (secret, password) with "****" in config JSON.
+// sensitiveConfigKeys are channel config keys whose values are masked in API
+// responses. Keys are matched case-insensitively with "-" read as "_", so
+// headers such as "Authorization" and "Api-Key" match.
+var sensitiveConfigKeys = map[string]bool{
+	"secret":        true,
+	"password":      true,
+	"token":         true,
+	"api_key":       true,
+	"authorization": true,
+}
+
+// maskChannelConfig replaces sensitive values with "****" in config JSON,
+// both at the top level and one level down in nested objects such as a
+// webhook's headers map.
 func maskChannelConfig(cfgJSON string) string {
 	var raw map[string]any
 	if err := json.Unmarshal([]byte(cfgJSON), &raw); err != nil {
 		return cfgJSON
 	}
-	for _, key := range []string{"secret", "password"} {
-		if _, ok := raw[key]; ok {
-			if v, isStr := raw[key].(string); isStr && v != "" {
-				raw[key] = "****"
-			}
-		}
-	}
+	maskSensitiveValues(raw, 1)
 	masked, err := json.Marshal(raw)
 	if err != nil {
 		return cfgJSON
@@ -1181,8 +1328,27 @@ func maskChannelConfig(cfgJSON string) string {
 	return string(masked)
 }
 
+// maskSensitiveValues masks non-empty string values of sensitive keys in obj,
+// descending depth further levels into nested objects.
+func maskSensitiveValues(obj map[string]any, depth int) {
+	for key, v := range obj {
+		switch val := v.(type) {
+		case string:
+			name := strings.ReplaceAll(strings.ToLower(key), "-", "_")
+			if val != "" && sensitiveConfigKeys[name] {
+				obj[key] = "****"
+			}
+		case map[string]any:
+			if depth > 0 {
+				maskSensitiveValues(val, depth-1)
+			}
+		}
+	}
+}
+
 // buildNotifier creates a Notifier from a NotificationChannel configuration.
-func buildNotifier(ch NotificationChannel) (Notifier, error) {
+// Webhook notifiers deliver through queue when it is non-nil.
+func buildNotifier(ch NotificationChannel, queue WebhookQueue) (Notifier, error) {
 	switch ch.Type {
 	case "webhook":
 		var cfg WebhookConfig
@@ -1192,7 +1358,9 @@ func buildNotifier(ch NotificationChannel) (Notifier, error) {
 		if cfg.URL == "" {
 			return nil, fmt.Errorf("webhook URL is required")
 		}
-		return NewWebhookNotifier(cfg), nil
+		n := NewWebhookNotifier(cfg)
+		n.queue = queue
+		return n, nil
 	case "alertmanager":
 		var cfg AlertmanagerConfig
 		if err := json.Unmarshal([]byte(ch.Config), &cfg); err != nil {
@@ -1249,6 +1417,10 @@ func (m *Module) handleCreateMaintWindow(w http.ResponseWriter, r *http.Request)
 		pulseWriteError(w, http.StatusBadRequest, "recurrence must be once, daily, weekly, or monthly")
 		return
 	}
+	if _, err := time.LoadLocation(req.Timezone); err != nil {
+		pulseWriteError(w, http.StatusBadRequest, "timezone must be an IANA time zone name")
+		return
+	}
 	startTime, err := time.Parse(time.RFC3339, req.StartTime)
 	if err != nil {
 		pulseWriteError(w, http.StatusBadRequest, "start_time must be RFC3339 format")
@@ -1276,6 +1448,7 @@ func (m *Module) handleCreateMaintWindow(w http.ResponseWriter, r *http.Request)
 		StartTime:   startTime.UTC(),
 		EndTime:     endTime.UTC(),
 		Recurrence:  req.Recurrence,
+		Timezone:    req.Timezone,
 		DeviceIDs:   req.DeviceIDs,
 		Enabled:     true,
 		CreatedAt:   now,
@@ -1371,6 +1544,13 @@ func (m *Module) handleUpdateMaintWindow(w http.ResponseWriter, r *http.Request)
 		}
 		existing.Recurrence = req.Recurrence
 	}
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil {
+			pulseWriteError(w, http.StatusBadRequest, "timezone must be an IANA time zone name")
+			return
+		}
+		existing.Timezone = *req.Timezone
+	}
 	if len(req.DeviceIDs) > 0 {
 		existing.DeviceIDs = req.DeviceIDs
 	}
@@ -1406,6 +1586,37 @@ func (m *Module) handleDeleteMaintWindow(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// maxRetentionDays bounds per-check result retention.
+const maxRetentionDays = 3650
+
+// validateRetentionDays checks a per-check retention override. 0 means the
+// global retention period applies.
+func validateRetentionDays(days int) error {
+	if days < 0 || days > maxRetentionDays {
+		return fmt.Errorf("retention_days must be between 0 and %d", maxRetentionDays)
+	}
+	return nil
+}
+
+// validateThresholds checks per-check alert thresholds. 0 means the default
+// applies; the effective warning threshold must not exceed the critical one.
+func (m *Module) validateThresholds(warningAfter, criticalAfter int) error {
+	if warningAfter < 0 || criticalAfter < 0 {
+		return fmt.Errorf("warning_after and critical_after must be positive")
+	}
+	if criticalAfter == 0 {
+		return nil
+	}
+	effectiveWarning := warningAfter
+	if effectiveWarning == 0 {
+		effectiveWarning = m.cfg.ConsecutiveFailures
+	}
+	if effectiveWarning > criticalAfter {
+		return fmt.Errorf("warning_after (%d) must not exceed critical_after (%d)", effectiveWarning, criticalAfter)
+	}
+	return nil
+}
+
 // validateTarget validates a check target based on the check type.
 func validateTarget(checkType, target string) error {
 	switch checkType {
@@ -3,6 +3,8 @@ package pulse
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -89,6 +91,50 @@ func TestHandleListChecks_WithData(t *testing.T) {
 	}
 }
 
+func TestHandleListChecks_ETag(t *testing.T) {
+	m, _ := newTestModule(t)
+	now := time.Now().UTC().Truncate(time.Second)
+	check := &Check{
+		ID:              "check-1",
+		DeviceID:        "dev-1",
+		CheckType:       "icmp",
+		Target:          "192.168.1.1",
+		IntervalSeconds: 60,
+		Enabled:         true,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := m.store.InsertCheck(context.Background(), check); err != nil {
+		t.Fatalf("insert check: %v", err)
+	}
+
+	list := func(etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/checks", http.NoBody)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		m.handleListChecks(w, req)
+		return w
+	}
+
+	etag := list("").Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected ETag header")
+	}
+	if w := list(etag); w.Code != http.StatusNotModified {
+		t.Errorf("conditional GET status = %d, want %d", w.Code, http.StatusNotModified)
+	}
+
+	if err := m.store.UpdateCheckEnabled(context.Background(), "check-1", false); err != nil {
+		t.Fatalf("disable check: %v", err)
+	}
+	w := list(etag)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("after change: status = %d, ETag = %q; want 200 with a new ETag", w.Code, w.Header().Get("ETag"))
+	}
+}
+
 func TestHandleListChecks_NilStore(t *testing.T) {
 	m := &Module{logger: zap.NewNop()}
 	req := httptest.NewRequest(http.MethodGet, "/checks", http.NoBody)
@@ -176,6 +222,84 @@ func TestHandleCreateCheck_InvalidType(t *testing.T) {
 	}
 }
 
+func TestCreateCheck(t *testing.T) {
+	m, _ := newTestModule(t)
+
+	check, err := m.CreateCheck(context.Background(), NewCheck{DeviceID: "dev-1", CheckType: "tcp", Target: "192.168.1.1:22"})
+	if err != nil {
+		t.Fatalf("CreateCheck: %v", err)
+	}
+	if check.ID == "" || check.IntervalSeconds != 30 || !check.Enabled {
+		t.Errorf("check = %+v, want stored enabled check with default interval", check)
+	}
+
+	_, err = m.CreateCheck(context.Background(), NewCheck{DeviceID: "dev-1", CheckType: "icmp"})
+	if !errors.Is(err, ErrInvalidCheck) {
+		t.Errorf("CreateCheck without target err = %v, want ErrInvalidCheck", err)
+	}
+}
+
+func TestHandleCreateCheck_RetentionDays(t *testing.T) {
+	m, _ := newTestModule(t)
+
+	body := `{"device_id":"dev-1","check_type":"icmp","target":"192.168.1.1","retention_days":90}`
+	req := httptest.NewRequest(http.MethodPost, "/checks", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	m.handleCreateCheck(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	var check Check
+	if err := json.NewDecoder(w.Body).Decode(&check); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if check.RetentionDays != 90 {
+		t.Errorf("check.RetentionDays = %d, want 90", check.RetentionDays)
+	}
+
+	body = `{"device_id":"dev-1","check_type":"icmp","target":"192.168.1.1","retention_days":-1}`
+	req = httptest.NewRequest(http.MethodPost, "/checks", strings.NewReader(body))
+	w = httptest.NewRecorder()
+
+	m.handleCreateCheck(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("negative retention_days: status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandleCreateCheck_Thresholds(t *testing.T) {
+	m, _ := newTestModule(t)
+
+	// Accepted cases use distinct devices: check IDs embed the device and a
+	// millisecond timestamp, so two creates for one device can collide.
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{"both set", `{"device_id":"dev-1","check_type":"icmp","target":"192.168.1.1","warning_after":5,"critical_after":10}`, http.StatusCreated},
+		{"equal", `{"device_id":"dev-2","check_type":"icmp","target":"192.168.1.1","warning_after":5,"critical_after":5}`, http.StatusCreated},
+		{"warning above critical", `{"device_id":"dev-1","check_type":"icmp","target":"192.168.1.1","warning_after":10,"critical_after":5}`, http.StatusBadRequest},
+		{"critical below default warning", `{"device_id":"dev-1","check_type":"icmp","target":"192.168.1.1","critical_after":2}`, http.StatusBadRequest},
+		{"negative", `{"device_id":"dev-1","check_type":"icmp","target":"192.168.1.1","warning_after":-1}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/checks", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			m.handleCreateCheck(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
+
 func TestHandleCreateCheck_InvalidTarget_TCP(t *testing.T) {
 	m, _ := newTestModule(t)
 
@@ -620,6 +744,57 @@ func TestHandleListAlerts_Empty(t *testing.T) {
 	}
 }
 
+func TestHandleListAlerts_Pagination(t *testing.T) {
+	m, _ := newTestModule(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	check := &Check{
+		ID:              "check-1",
+		DeviceID:        "dev-1",
+		CheckType:       "icmp",
+		Target:          "192.168.1.1",
+		IntervalSeconds: 60,
+		Enabled:         true,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := m.store.InsertCheck(ctx, check); err != nil {
+		t.Fatalf("insert check: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		alert := &Alert{
+			ID:          fmt.Sprintf("alert-%d", i),
+			CheckID:     "check-1",
+			DeviceID:    "dev-1",
+			Severity:    "warning",
+			Message:     "Device unreachable",
+			TriggeredAt: now.Add(time.Duration(i) * time.Minute),
+		}
+		if err := m.store.InsertAlert(ctx, alert); err != nil {
+			t.Fatalf("insert alert: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/alerts?limit=2&offset=2", http.NoBody)
+	w := httptest.NewRecorder()
+	m.handleListAlerts(w, req)
+
+	var alerts []Alert
+	if err := json.NewDecoder(w.Body).Decode(&alerts); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].ID != "alert-0" {
+		t.Fatalf("alerts = %+v, want only the oldest alert", alerts)
+	}
+	if got := w.Header().Get("X-Total-Count"); got != "3" {
+		t.Errorf("X-Total-Count = %q, want 3", got)
+	}
+	if got, want := w.Header().Get("Link"), `</alerts?limit=2&offset=0>; rel="first", </alerts?limit=2&offset=0>; rel="prev"`; got != want {
+		t.Errorf("Link = %q, want %q", got, want)
+	}
+}
+
 func TestHandleListAlerts_WithData(t *testing.T) {
 	m, _ := newTestModule(t)
 
@@ -1055,3 +1230,76 @@ func TestPulseParseLimit(t *testing.T) {
 		})
 	}
 }
+
+// -- notification channel masking tests --
+
+func TestMaskChannelConfig(t *testing.T) {
+	in := `{"url":"https://hooks.example.com/x","secret":"s3cr3t","token":"tok",` +
+		`"api_key":"key","empty":"","headers":{"Authorization":"Bearer abc","Api-Key":"k2","Content-Type":"application/json"},` +
+		`"nested":{"inner":{"password":"deep"}}}`
+
+	var got map[string]any
+	if err := json.Unmarshal([]byte(maskChannelConfig(in)), &got); err != nil {
+		t.Fatalf("unmarshal masked config: %v", err)
+	}
+	for _, key := range []string{"secret", "token", "api_key"} {
+		if got[key] != "****" {
+			t.Errorf("%s = %v, want masked", key, got[key])
+		}
+	}
+	if got["url"] != "https://hooks.example.com/x" || got["empty"] != "" {
+		t.Errorf("non-secret values changed: url=%v empty=%v", got["url"], got["empty"])
+	}
+	headers, _ := got["headers"].(map[string]any)
+	if headers["Authorization"] != "****" || headers["Api-Key"] != "****" {
+		t.Errorf("headers = %v, want Authorization and Api-Key masked", headers)
+	}
+	if headers["Content-Type"] != "application/json" {
+		t.Errorf("Content-Type = %v, want unchanged", headers["Content-Type"])
+	}
+	// Masking recurses one level only.
+	inner, _ := got["nested"].(map[string]any)["inner"].(map[string]any)
+	if inner["password"] != "deep" {
+		t.Errorf("nested.inner.password = %v, want left as-is beyond one level", inner["password"])
+	}
+}
+
+func TestHandleListNotifications_MasksBearerHeader(t *testing.T) {
+	m, s := newTestModule(t)
+	now := time.Now().UTC()
+	ch := &NotificationChannel{
+		ID:        "ch-1",
+		Name:      "ops webhook",
+		Type:      "webhook",
+		Config:    `{"url":"https://hooks.example.com/x","headers":{"Authorization":"Bearer abc123"}}`,
+		Enabled:   true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.InsertChannel(context.Background(), ch); err != nil {
+		t.Fatalf("InsertChannel: %v", err)
+	}
+
+	for _, tc := range []struct {
+		path    string
+		handler http.HandlerFunc
+	}{
+		{"/notifications", m.handleListNotifications},
+		{"/notifications/ch-1", m.handleGetNotification},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tc.path, http.NoBody)
+		req.SetPathValue("id", "ch-1")
+		w := httptest.NewRecorder()
+		tc.handler(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: status = %d, want 200", tc.path, w.Code)
+		}
+		body := w.Body.String()
+		if strings.Contains(body, "abc123") {
+			t.Errorf("GET %s leaks bearer token: %s", tc.path, body)
+		}
+		if !strings.Contains(body, "****") {
+			t.Errorf("GET %s: no masked value in %s", tc.path, body)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package pulse
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/HerbHall/subnetree/internal/dispatch"
+	"github.com/HerbHall/subnetree/pkg/plugin"
+	"go.uber.org/zap"
+)
+
+// hardwareCheckType marks the passive checks that back hardware threshold
+// alerts. Like agent checks they are created disabled and driven by
+// dispatch events.
+const hardwareCheckType = "hardware"
+
+// hardwareCheckID returns the ID of the passive check for a threshold on an agent.
+func hardwareCheckID(threshold, agentID string) string {
+	return "hw-" + threshold + "-" + agentID
+}
+
+// validateHardwareThresholds checks thresholds and fills in default severities.
+func validateHardwareThresholds(thresholds []HardwareThreshold) ([]HardwareThreshold, error) {
+	out := make([]HardwareThreshold, 0, len(thresholds))
+	seen := make(map[string]bool, len(thresholds))
+	for i := range thresholds {
+		t := thresholds[i]
+		if t.Name == "" {
+			return nil, fmt.Errorf("threshold %d: name is required", i)
+		}
+		if seen[t.Name] {
+			return nil, fmt.Errorf("threshold %q: duplicate name", t.Name)
+		}
+		seen[t.Name] = true
+		if !dispatch.IsHardwareMetric(t.Metric) {
+			return nil, fmt.Errorf("threshold %q: unknown metric %q", t.Name, t.Metric)
+		}
+		switch t.Severity {
+		case "":
+			t.Severity = "warning"
+		case "warning", "critical":
+		default:
+			return nil, fmt.Errorf("threshold %q: severity must be warning or critical", t.Name)
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// handleAgentHardware evaluates hardware thresholds against the readings of
+// an agent check-in, raising an alert when a reading exceeds its limit and
+// resolving it once all matching readings are back within it.
+func (m *Module) handleAgentHardware(ctx context.Context, event plugin.Event) {
+	if m.store == nil || len(m.hwLimits) == 0 {
+		return
+	}
+
+	he, ok := event.Payload.(*dispatch.AgentHardwareEvent)
+	if !ok {
+		m.logger.Warn("unexpected payload type for agent hardware event")
+		return
+	}
+
+	for i := range m.hwLimits {
+		limit := &m.hwLimits[i]
+		var worst *dispatch.HardwareSample
+		for j := range he.Samples {
+			s := &he.Samples[j]
+			if s.Metric != limit.Metric || (limit.Label != "" && s.Label != limit.Label) {
+				continue
+			}
+			if worst == nil || s.Value > worst.Value {
+				worst = s
+			}
+		}
+		if worst == nil {
+			continue // No reading for this threshold in the check-in.
+		}
+		if worst.Value > limit.Above {
+			m.raiseHardwareAlert(ctx, limit, he, worst)
+		} else {
+			m.resolveHardwareAlert(ctx, limit, he)
+		}
+	}
+}
+
+// raiseHardwareAlert opens an alert for a threshold on an agent unless one
+// is already active.
+func (m *Module) raiseHardwareAlert(ctx context.Context, limit *HardwareThreshold, he *dispatch.AgentHardwareEvent, sample *dispatch.HardwareSample) {
+	checkID := hardwareCheckID(limit.Name, he.AgentID)
+	if err := m.ensureHardwareCheck(ctx, checkID, limit, he); err != nil {
+		m.logger.Warn("failed to create hardware check", zap.String("check_id", checkID), zap.Error(err))
+		return
+	}
+
+	existing, err := m.store.GetActiveAlert(ctx, checkID)
+	if err != nil {
+		m.logger.Warn("failed to check existing alert", zap.String("check_id", checkID), zap.Error(err))
+		return
+	}
+	if existing != nil {
+		return
+	}
+
+	name := he.Hostname
+	if name == "" {
+		name = he.AgentID
+	}
+	now := time.Now().UTC()
+	alert := &Alert{
+		ID:       fmt.Sprintf("alert-%s-%d", checkID, now.UnixMilli()),
+		CheckID:  checkID,
+		DeviceID: he.DeviceID,
+		Severity: limit.Severity,
+		Message: fmt.Sprintf("%s: %s %s on %s is %.1f, above %.1f",
+			limit.Name, sample.Label, sample.Metric, name, sample.Value, limit.Above),
+		TriggeredAt: now,
+	}
+	if err := m.store.InsertAlert(ctx, alert); err != nil {
+		m.logger.Warn("failed to insert hardware alert", zap.String("check_id", checkID), zap.Error(err))
+		return
+	}
+
+	m.logger.Warn("hardware threshold alert triggered",
+		zap.String("alert_id", alert.ID),
+		zap.String("threshold", limit.Name),
+		zap.String("agent_id", he.AgentID),
+		zap.Float64("value", sample.Value),
+	)
+
+	if m.bus != nil {
+		m.bus.PublishAsync(ctx, plugin.Event{
+			Topic:     TopicAlertTriggered,
+			Source:    "pulse",
+			Timestamp: now,
+			Payload:   alert,
+		})
+	}
+}
+
+// resolveHardwareAlert resolves the active alert for a threshold on an agent, if any.
+func (m *Module) resolveHardwareAlert(ctx context.Context, limit *HardwareThreshold, he *dispatch.AgentHardwareEvent) {
+	checkID := hardwareCheckID(limit.Name, he.AgentID)
+	alert, err := m.store.GetActiveAlert(ctx, checkID)
+	if err != nil {
+		m.logger.Warn("failed to get active alert", zap.String("check_id", checkID), zap.Error(err))
+		return
+	}
+	if alert == nil {
+		return
+	}
+
+	now := time.Now().UTC()
+	if err := m.store.ResolveAlert(ctx, alert.ID, now); err != nil {
+		m.logger.Warn("failed to resolve alert", zap.String("alert_id", alert.ID), zap.Error(err))
+		return
+	}
+	alert.ResolvedAt = &now
+
+	m.logger.Info("hardware threshold alert resolved",
+		zap.String("alert_id", alert.ID),
+		zap.String("threshold", limit.Name),
+		zap.String("agent_id", he.AgentID),
+	)
+
+	if m.bus != nil {
+		m.bus.PublishAsync(ctx, plugin.Event{
+			Topic:     TopicAlertResolved,
+			Source:    "pulse",
+			Timestamp: now,
+			Payload:   alert,
+		})
+	}
+}
+
+// ensureHardwareCheck creates the passive check row a hardware alert references.
+func (m *Module) ensureHardwareCheck(ctx context.Context, checkID string, limit *HardwareThreshold, he *dispatch.AgentHardwareEvent) error {
+	existing, err := m.store.GetCheck(ctx, checkID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	return m.store.InsertCheck(ctx, &Check{
+		ID:        checkID,
+		DeviceID:  he.DeviceID,
+		CheckType: hardwareCheckType,
+		Target:    he.AgentID + "/" + limit.Name,
+		Enabled:   false,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
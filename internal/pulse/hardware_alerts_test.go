@@ -0,0 +1,95 @@
+package pulse
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/HerbHall/subnetree/internal/dispatch"
+	"github.com/HerbHall/subnetree/pkg/plugin"
+)
+
+func TestValidateHardwareThresholds(t *testing.T) {
+	limits, err := validateHardwareThresholds([]HardwareThreshold{{Name: "gpu-hot", Metric: dispatch.MetricGPUTemperature, Above: 85}})
+	if err != nil {
+		t.Fatalf("validateHardwareThresholds: %v", err)
+	}
+	if limits[0].Severity != "warning" {
+		t.Errorf("default severity = %q, want warning", limits[0].Severity)
+	}
+
+	for name, limit := range map[string]HardwareThreshold{
+		"no name":    {Metric: dispatch.MetricGPUTemperature},
+		"bad metric": {Name: "x", Metric: "fan_speed"},
+		"bad sev":    {Name: "x", Metric: dispatch.MetricCPUTemperature, Severity: "info"},
+	} {
+		if _, err := validateHardwareThresholds([]HardwareThreshold{limit}); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestHandleAgentHardware_TriggersAndResolves(t *testing.T) {
+	m, ps := newTestModule(t)
+	limits, err := validateHardwareThresholds([]HardwareThreshold{
+		{Name: "gpu-hot", Metric: dispatch.MetricGPUTemperature, Above: 85, Severity: "critical"},
+		{Name: "gpu1-busy", Metric: dispatch.MetricGPUUtilization, Label: "gpu1", Above: 90},
+	})
+	if err != nil {
+		t.Fatalf("validateHardwareThresholds: %v", err)
+	}
+	m.hwLimits = limits
+	ctx := context.Background()
+
+	hardwareEvent := func(samples ...dispatch.HardwareSample) plugin.Event {
+		return plugin.Event{
+			Topic: TopicAgentHardware,
+			Payload: &dispatch.AgentHardwareEvent{
+				AgentID:  "agent-001",
+				Hostname: "ml-box",
+				DeviceID: "dev-001",
+				Samples:  samples,
+			},
+		}
+	}
+
+	hot := hardwareEvent(
+		dispatch.HardwareSample{Metric: dispatch.MetricGPUTemperature, Label: "gpu0", Value: 70},
+		dispatch.HardwareSample{Metric: dispatch.MetricGPUTemperature, Label: "gpu1", Value: 91},
+		dispatch.HardwareSample{Metric: dispatch.MetricGPUUtilization, Label: "gpu0", Value: 99}, // other GPU
+	)
+	m.handleAgentHardware(ctx, hot)
+	// A repeated reading must not open a second alert.
+	m.handleAgentHardware(ctx, hot)
+
+	checkID := hardwareCheckID("gpu-hot", "agent-001")
+	check, err := ps.GetCheck(ctx, checkID)
+	if err != nil || check == nil {
+		t.Fatalf("hardware check not created: %v", err)
+	}
+	if check.Enabled || check.CheckType != hardwareCheckType {
+		t.Errorf("check = %+v, want disabled hardware check", check)
+	}
+
+	alerts, err := ps.ListActiveAlerts(ctx, "dev-001")
+	if err != nil {
+		t.Fatalf("ListActiveAlerts: %v", err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("got %d active alerts, want 1", len(alerts))
+	}
+	if alerts[0].Severity != "critical" || !strings.Contains(alerts[0].Message, "gpu1 gpu_temperature on ml-box is 91.0") {
+		t.Errorf("alert = %+v", alerts[0])
+	}
+
+	// A check-in without temperature readings leaves the alert open.
+	m.handleAgentHardware(ctx, hardwareEvent(dispatch.HardwareSample{Metric: dispatch.MetricCPUTemperature, Value: 40}))
+	if alert, _ := ps.GetActiveAlert(ctx, checkID); alert == nil {
+		t.Fatal("alert resolved without a matching reading")
+	}
+
+	m.handleAgentHardware(ctx, hardwareEvent(dispatch.HardwareSample{Metric: dispatch.MetricGPUTemperature, Label: "gpu1", Value: 80}))
+	if alert, err := ps.GetActiveAlert(ctx, checkID); err != nil || alert != nil {
+		t.Errorf("alert still active after cooling down: %+v, %v", alert, err)
+	}
+}
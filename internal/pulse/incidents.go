@@ -0,0 +1,214 @@
+package pulse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Incident groups the alerts of a single outage: the alert of the root cause
+// device plus the downstream alerts suppressed by it. An incident is open
+// while any member alert is active.
+type Incident struct {
+	ID           string     `json:"id"`
+	RootDeviceID string     `json:"root_device_id"`
+	RootAlertID  string     `json:"root_alert_id,omitempty"`
+	StartedAt    time.Time  `json:"started_at"`
+	EndedAt      *time.Time `json:"ended_at,omitempty"`
+	DeviceIDs    []string   `json:"device_ids"` // Affected devices, root first
+	AlertIDs     []string   `json:"alert_ids"`
+}
+
+// IncidentFilters controls which incidents ListIncidents returns.
+type IncidentFilters struct {
+	ActiveOnly bool
+	Limit      int
+}
+
+// attachIncident assigns an active alert to an incident. An alert suppressed
+// by an upstream device joins the open incident of that device's active
+// alert; any other alert starts a new incident with its device as the root.
+func (s *PulseStore) attachIncident(ctx context.Context, a *Alert) error {
+	incidentID := ""
+	rootDeviceID := a.DeviceID
+	if a.Suppressed && a.SuppressedBy != "" {
+		rootDeviceID = a.SuppressedBy
+		err := s.db.QueryRowContext(ctx, `
+			SELECT a.incident_id FROM pulse_alerts a
+			JOIN pulse_incidents i ON i.id = a.incident_id
+			WHERE a.device_id = ? AND a.resolved_at IS NULL AND i.ended_at IS NULL
+			ORDER BY a.triggered_at ASC LIMIT 1`,
+			a.SuppressedBy,
+		).Scan(&incidentID)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("find upstream incident: %w", err)
+		}
+	}
+
+	if incidentID == "" {
+		incidentID = uuid.New().String()
+		rootAlertID := ""
+		if rootDeviceID == a.DeviceID {
+			rootAlertID = a.ID
+		}
+		_, err := s.db.ExecContext(ctx, `
+			INSERT INTO pulse_incidents (id, root_device_id, root_alert_id, started_at)
+			VALUES (?, ?, ?, ?)`,
+			incidentID, rootDeviceID, rootAlertID, a.TriggeredAt,
+		)
+		if err != nil {
+			return fmt.Errorf("insert incident: %w", err)
+		}
+	}
+
+	if _, err := s.db.ExecContext(ctx, `
+		UPDATE pulse_alerts SET incident_id = ? WHERE id = ?`,
+		incidentID, a.ID,
+	); err != nil {
+		return fmt.Errorf("assign alert incident: %w", err)
+	}
+	a.IncidentID = incidentID
+	return nil
+}
+
+// closeIncidentIfResolved ends the incident of the given alert once none of
+// its member alerts is active.
+func (s *PulseStore) closeIncidentIfResolved(ctx context.Context, alertID string, endedAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE pulse_incidents SET ended_at = ?
+		WHERE id = (SELECT incident_id FROM pulse_alerts WHERE id = ?)
+			AND ended_at IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM pulse_alerts
+				WHERE incident_id = pulse_incidents.id AND resolved_at IS NULL
+			)`,
+		endedAt, alertID,
+	)
+	if err != nil {
+		return fmt.Errorf("close incident: %w", err)
+	}
+	return nil
+}
+
+// ListIncidents returns incidents, most recent first, with their member
+// alerts and affected devices.
+func (s *PulseStore) ListIncidents(ctx context.Context, filters IncidentFilters) ([]Incident, error) {
+	query := `SELECT id, root_device_id, root_alert_id, started_at, ended_at FROM pulse_incidents`
+	if filters.ActiveOnly {
+		query += " WHERE ended_at IS NULL"
+	}
+	limit := filters.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	query += fmt.Sprintf(" ORDER BY started_at DESC LIMIT %d", limit)
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("list incidents: %w", err)
+	}
+	var incidents []Incident
+	for rows.Next() {
+		var inc Incident
+		var endedAt sql.NullTime
+		if err := rows.Scan(&inc.ID, &inc.RootDeviceID, &inc.RootAlertID, &inc.StartedAt, &endedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan incident: %w", err)
+		}
+		if endedAt.Valid {
+			inc.EndedAt = &endedAt.Time
+		}
+		incidents = append(incidents, inc)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list incidents: %w", err)
+	}
+
+	for i := range incidents {
+		if err := s.loadIncidentMembers(ctx, &incidents[i]); err != nil {
+			return nil, err
+		}
+	}
+	return incidents, nil
+}
+
+// loadIncidentMembers fills the alert and device IDs of an incident.
+func (s *PulseStore) loadIncidentMembers(ctx context.Context, inc *Incident) error {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, device_id FROM pulse_alerts
+		WHERE incident_id = ? ORDER BY triggered_at ASC`,
+		inc.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("list incident alerts: %w", err)
+	}
+	defer rows.Close()
+
+	inc.AlertIDs = []string{}
+	inc.DeviceIDs = []string{inc.RootDeviceID}
+	for rows.Next() {
+		var alertID, deviceID string
+		if err := rows.Scan(&alertID, &deviceID); err != nil {
+			return fmt.Errorf("scan incident alert: %w", err)
+		}
+		inc.AlertIDs = append(inc.AlertIDs, alertID)
+		if !slices.Contains(inc.DeviceIDs, deviceID) {
+			inc.DeviceIDs = append(inc.DeviceIDs, deviceID)
+		}
+	}
+	return rows.Err()
+}
+
+// DeleteOldIncidents deletes incidents that ended before the given time.
+// Returns the number of rows deleted.
+func (s *PulseStore) DeleteOldIncidents(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM pulse_incidents WHERE ended_at IS NOT NULL AND ended_at < ?`,
+		before,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("delete old incidents: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// handleListIncidents returns incidents grouping correlated alerts.
+//
+//	@Summary		List incidents
+//	@Description	Returns incidents, each grouping the alert of a root cause device with the downstream alerts it suppressed. An incident ends when all of its alerts resolve.
+//	@Tags			pulse
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			active query bool false "Only return open incidents"
+//	@Param			limit query int false "Maximum incidents to return" default(50)
+//	@Success		200 {array} Incident
+//	@Failure		500 {object} map[string]any
+//	@Router			/pulse/incidents [get]
+func (m *Module) handleListIncidents(w http.ResponseWriter, r *http.Request) {
+	if m.store == nil {
+		pulseWriteError(w, http.StatusServiceUnavailable, "pulse store not available")
+		return
+	}
+
+	filters := IncidentFilters{
+		ActiveOnly: r.URL.Query().Get("active") == "true",
+		Limit:      pulseParseLimit(r, 50),
+	}
+	incidents, err := m.store.ListIncidents(r.Context(), filters)
+	if err != nil {
+		m.logger.Warn("failed to list incidents", zap.Error(err))
+		pulseWriteError(w, http.StatusInternalServerError, "failed to list incidents")
+		return
+	}
+	if incidents == nil {
+		incidents = []Incident{}
+	}
+	pulseWriteJSON(w, http.StatusOK, incidents)
+}
@@ -0,0 +1,157 @@
+package pulse
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIncidents_GroupAndClose(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	root := &Alert{ID: "a-switch", CheckID: "c-switch", DeviceID: "switch", Severity: "critical", Message: "down", TriggeredAt: now}
+	child := &Alert{ID: "a-host", CheckID: "c-host", DeviceID: "host", Severity: "warning", Message: "down",
+		TriggeredAt: now.Add(time.Second), Suppressed: true, SuppressedBy: "switch"}
+	other := &Alert{ID: "a-nas", CheckID: "c-nas", DeviceID: "nas", Severity: "warning", Message: "down", TriggeredAt: now.Add(2 * time.Second)}
+	for _, a := range []*Alert{root, child, other} {
+		insertCorrelationCheck(t, s, a.CheckID, a.DeviceID)
+		if err := s.InsertAlert(ctx, a); err != nil {
+			t.Fatalf("InsertAlert(%s): %v", a.ID, err)
+		}
+	}
+
+	if root.IncidentID == "" {
+		t.Fatal("root alert has no incident")
+	}
+	if child.IncidentID != root.IncidentID {
+		t.Errorf("suppressed alert incident = %q, want upstream incident %q", child.IncidentID, root.IncidentID)
+	}
+	if other.IncidentID == "" || other.IncidentID == root.IncidentID {
+		t.Errorf("unrelated alert incident = %q, want a separate incident", other.IncidentID)
+	}
+
+	incidents, err := s.ListIncidents(ctx, IncidentFilters{ActiveOnly: true})
+	if err != nil {
+		t.Fatalf("ListIncidents: %v", err)
+	}
+	if len(incidents) != 2 {
+		t.Fatalf("got %d open incidents, want 2", len(incidents))
+	}
+	var outage *Incident
+	for i := range incidents {
+		if incidents[i].ID == root.IncidentID {
+			outage = &incidents[i]
+		}
+	}
+	if outage == nil {
+		t.Fatalf("incident %s not listed", root.IncidentID)
+	}
+	if outage.RootDeviceID != "switch" || outage.RootAlertID != root.ID {
+		t.Errorf("root = %q/%q, want switch/%s", outage.RootDeviceID, outage.RootAlertID, root.ID)
+	}
+	if len(outage.DeviceIDs) != 2 || outage.DeviceIDs[0] != "switch" || outage.DeviceIDs[1] != "host" {
+		t.Errorf("DeviceIDs = %v, want [switch host]", outage.DeviceIDs)
+	}
+	if len(outage.AlertIDs) != 2 {
+		t.Errorf("AlertIDs = %v, want 2 alerts", outage.AlertIDs)
+	}
+
+	// The incident stays open until every member alert resolves.
+	if err := s.ResolveAlert(ctx, root.ID, now.Add(time.Minute)); err != nil {
+		t.Fatalf("ResolveAlert: %v", err)
+	}
+	incidents, err = s.ListIncidents(ctx, IncidentFilters{ActiveOnly: true})
+	if err != nil {
+		t.Fatalf("ListIncidents: %v", err)
+	}
+	if len(incidents) != 2 {
+		t.Fatalf("got %d open incidents after resolving root, want 2", len(incidents))
+	}
+
+	ended := now.Add(2 * time.Minute)
+	if err := s.ResolveAlert(ctx, child.ID, ended); err != nil {
+		t.Fatalf("ResolveAlert: %v", err)
+	}
+	incidents, err = s.ListIncidents(ctx, IncidentFilters{})
+	if err != nil {
+		t.Fatalf("ListIncidents: %v", err)
+	}
+	for i := range incidents {
+		inc := incidents[i]
+		switch inc.ID {
+		case root.IncidentID:
+			if inc.EndedAt == nil || !inc.EndedAt.Equal(ended) {
+				t.Errorf("EndedAt = %v, want %v", inc.EndedAt, ended)
+			}
+		case other.IncidentID:
+			if inc.EndedAt != nil {
+				t.Errorf("unrelated incident ended at %v, want open", inc.EndedAt)
+			}
+		}
+	}
+
+	deleted, err := s.DeleteOldIncidents(ctx, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("DeleteOldIncidents: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("DeleteOldIncidents deleted %d, want 1", deleted)
+	}
+}
+
+func TestIncidents_MaintenanceSuppressedAlertHasNoIncident(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+
+	a := &Alert{ID: "a-1", CheckID: "c-1", DeviceID: "dev-1", Severity: "warning", Message: "down",
+		TriggeredAt: time.Now().UTC(), Suppressed: true, MaintWindowID: "mw-1"}
+	insertCorrelationCheck(t, s, a.CheckID, a.DeviceID)
+	if err := s.InsertAlert(ctx, a); err != nil {
+		t.Fatalf("InsertAlert: %v", err)
+	}
+	if a.IncidentID != "" {
+		t.Errorf("IncidentID = %q, want none during maintenance", a.IncidentID)
+	}
+
+	// Once the window ends the alert becomes the root of its own incident.
+	if err := s.ClearAlertSuppression(ctx, a.ID); err != nil {
+		t.Fatalf("ClearAlertSuppression: %v", err)
+	}
+	got, err := s.GetAlert(ctx, a.ID)
+	if err != nil {
+		t.Fatalf("GetAlert: %v", err)
+	}
+	if got.IncidentID == "" {
+		t.Error("unsuppressed alert has no incident")
+	}
+}
+
+func TestHandleListIncidents(t *testing.T) {
+	m, s := newTestModule(t)
+	ctx := context.Background()
+
+	insertCorrelationCheck(t, s, "c-1", "dev-1")
+	if err := s.InsertAlert(ctx, &Alert{ID: "a-1", CheckID: "c-1", DeviceID: "dev-1", Severity: "warning", Message: "down", TriggeredAt: time.Now().UTC()}); err != nil {
+		t.Fatalf("InsertAlert: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/incidents?active=true", http.NoBody)
+	w := httptest.NewRecorder()
+	m.handleListIncidents(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var incidents []Incident
+	if err := json.NewDecoder(w.Body).Decode(&incidents); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(incidents) != 1 || len(incidents[0].AlertIDs) != 1 || incidents[0].AlertIDs[0] != "a-1" {
+		t.Errorf("incidents = %+v, want one incident with alert a-1", incidents)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"time"
 )
 
@@ -16,7 +17,8 @@ type MaintWindow struct {
 	Description string    `json:"description"`
 	StartTime   time.Time `json:"start_time"`
 	EndTime     time.Time `json:"end_time"`
-	Recurrence  string    `json:"recurrence"` // "once", "daily", "weekly", "monthly"
+	Recurrence  string    `json:"recurrence"`         // "once", "daily", "weekly", "monthly"
+	Timezone    string    `json:"timezone,omitempty"` // IANA zone recurrences follow; empty means UTC
 	DeviceIDs   []string  `json:"device_ids"`
 	Enabled     bool      `json:"enabled"`
 	CreatedAt   time.Time `json:"created_at"`
@@ -45,10 +47,11 @@ func (s *PulseStore) InsertMaintWindow(ctx context.Context, mw *MaintWindow) err
 	_, err = s.db.ExecContext(ctx, `
 		INSERT INTO pulse_maint_windows (
 			id, name, description, start_time, end_time, recurrence,
-			device_ids, enabled, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			device_ids, enabled, created_at, updated_at, timezone
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		mw.ID, mw.Name, mw.Description, mw.StartTime, mw.EndTime,
 		mw.Recurrence, string(deviceJSON), enabled, mw.CreatedAt, mw.UpdatedAt,
+		mw.Timezone,
 	)
 	if err != nil {
 		return fmt.Errorf("insert maint window: %w", err)
@@ -63,12 +66,13 @@ func (s *PulseStore) GetMaintWindow(ctx context.Context, id string) (*MaintWindo
 	var deviceJSON string
 	err := s.db.QueryRowContext(ctx, `
 		SELECT id, name, description, start_time, end_time, recurrence,
-			device_ids, enabled, created_at, updated_at
+			device_ids, enabled, created_at, updated_at, timezone
 		FROM pulse_maint_windows WHERE id = ?`,
 		id,
 	).Scan(
 		&mw.ID, &mw.Name, &mw.Description, &mw.StartTime, &mw.EndTime,
 		&mw.Recurrence, &deviceJSON, &enabledInt, &mw.CreatedAt, &mw.UpdatedAt,
+		&mw.Timezone,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -87,7 +91,7 @@ func (s *PulseStore) GetMaintWindow(ctx context.Context, id string) (*MaintWindo
 func (s *PulseStore) ListMaintWindows(ctx context.Context) ([]MaintWindow, error) {
 	rows, err := s.db.QueryContext(ctx, `
 		SELECT id, name, description, start_time, end_time, recurrence,
-			device_ids, enabled, created_at, updated_at
+			device_ids, enabled, created_at, updated_at, timezone
 		FROM pulse_maint_windows ORDER BY start_time DESC`)
 	if err != nil {
 		return nil, fmt.Errorf("list maint windows: %w", err)
@@ -102,6 +106,7 @@ func (s *PulseStore) ListMaintWindows(ctx context.Context) ([]MaintWindow, error
 		if err := rows.Scan(
 			&mw.ID, &mw.Name, &mw.Description, &mw.StartTime, &mw.EndTime,
 			&mw.Recurrence, &deviceJSON, &enabledInt, &mw.CreatedAt, &mw.UpdatedAt,
+			&mw.Timezone,
 		); err != nil {
 			return nil, fmt.Errorf("scan maint window: %w", err)
 		}
@@ -127,10 +132,12 @@ func (s *PulseStore) UpdateMaintWindow(ctx context.Context, mw *MaintWindow) err
 	_, err = s.db.ExecContext(ctx, `
 		UPDATE pulse_maint_windows SET
 			name = ?, description = ?, start_time = ?, end_time = ?,
-			recurrence = ?, device_ids = ?, enabled = ?, updated_at = ?
+			recurrence = ?, device_ids = ?, enabled = ?, updated_at = ?,
+			timezone = ?
 		WHERE id = ?`,
 		mw.Name, mw.Description, mw.StartTime, mw.EndTime,
-		mw.Recurrence, string(deviceJSON), enabled, mw.UpdatedAt, mw.ID,
+		mw.Recurrence, string(deviceJSON), enabled, mw.UpdatedAt,
+		mw.Timezone, mw.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("update maint window: %w", err)
@@ -150,74 +157,100 @@ func (s *PulseStore) DeleteMaintWindow(ctx context.Context, id string) error {
 // IsDeviceInMaintenanceWindow checks whether the given device is currently
 // inside any enabled maintenance window, accounting for recurrence.
 func (s *PulseStore) IsDeviceInMaintenanceWindow(ctx context.Context, deviceID string) (bool, error) {
+	inWindow, _, err := s.IsInMaintenanceWindow(ctx, deviceID, time.Now())
+	return inWindow, err
+}
+
+// IsInMaintenanceWindow checks whether the given device is inside an enabled
+// maintenance window at the given time. Recurring windows are evaluated on
+// the wall clock of the window's timezone, so they keep their local hours
+// across DST changes. Returns the ID of the first matching window.
+func (s *PulseStore) IsInMaintenanceWindow(ctx context.Context, deviceID string, at time.Time) (inWindow bool, windowID string, err error) {
 	windows, err := s.ListMaintWindows(ctx)
 	if err != nil {
-		return false, err
+		return false, "", err
 	}
-	now := time.Now().UTC()
 	for i := range windows {
-		if !windows[i].Enabled {
+		mw := &windows[i]
+		if !mw.Enabled || !slices.Contains(mw.DeviceIDs, deviceID) {
 			continue
 		}
-		// Check if device is in this window's device list.
-		found := false
-		for _, did := range windows[i].DeviceIDs {
-			if did == deviceID {
-				found = true
-				break
+		loc := time.UTC
+		if mw.Timezone != "" {
+			if l, err := time.LoadLocation(mw.Timezone); err == nil {
+				loc = l
 			}
 		}
-		if !found {
-			continue
-		}
-		if isTimeInWindow(now, windows[i].StartTime, windows[i].EndTime, windows[i].Recurrence) {
-			return true, nil
+		if isTimeInWindow(at, mw.StartTime.In(loc), mw.EndTime.In(loc), mw.Recurrence) {
+			return true, mw.ID, nil
 		}
 	}
-	return false, nil
+	return false, "", nil
 }
 
 // isTimeInWindow returns true if t falls within the maintenance window
-// defined by start/end with the given recurrence type.
+// defined by start/end with the given recurrence type. Recurring windows
+// repeat the wall-clock span from start to end in start's location: daily
+// every day, weekly on start's weekday, and monthly on start's day of the
+// month (the last day in shorter months). An end time of day before the
+// start's on the same date means the window runs past midnight.
 func isTimeInWindow(t, start, end time.Time, recurrence string) bool {
-	switch recurrence {
-	case "once":
+	if recurrence == "once" {
 		return !t.Before(start) && !t.After(end)
+	}
+	if !validRecurrence[recurrence] {
+		return false
+	}
 
-	case "daily":
-		return isTimeOfDayInRange(t, start, end)
+	loc := start.Location()
+	t = t.In(loc)
+	end = end.In(loc)
 
-	case "weekly":
-		if t.Weekday() != start.Weekday() {
-			return false
-		}
-		return isTimeOfDayInRange(t, start, end)
+	// Whole days from the start's date to the end's date.
+	spanDays := calendarDaysBetween(start, end)
+	if spanDays < 0 || (spanDays == 0 && timeOfDaySeconds(end) < timeOfDaySeconds(start)) {
+		spanDays = 1
+	}
 
-	case "monthly":
-		if t.Day() != start.Day() {
-			return false
+	// An occurrence containing t began on t's date or up to spanDays before.
+	for back := 0; back <= spanDays; back++ {
+		day := time.Date(t.Year(), t.Month(), t.Day()-back, 0, 0, 0, 0, loc)
+		if !occursOn(day, start, recurrence) {
+			continue
+		}
+		occStart := time.Date(day.Year(), day.Month(), day.Day(),
+			start.Hour(), start.Minute(), start.Second(), 0, loc)
+		occEnd := time.Date(day.Year(), day.Month(), day.Day()+spanDays,
+			end.Hour(), end.Minute(), end.Second(), 0, loc)
+		if !t.Before(occStart) && !t.After(occEnd) {
+			return true
 		}
-		return isTimeOfDayInRange(t, start, end)
+	}
+	return false
+}
 
+// occursOn reports whether a recurring window starting at start has an
+// occurrence beginning on the given date.
+func occursOn(day, start time.Time, recurrence string) bool {
+	switch recurrence {
+	case "daily":
+		return true
+	case "weekly":
+		return day.Weekday() == start.Weekday()
+	case "monthly":
+		lastDay := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+		return day.Day() == min(start.Day(), lastDay)
 	default:
 		return false
 	}
 }
 
-// isTimeOfDayInRange checks whether the time-of-day of t falls within
-// the time-of-day range defined by start and end. Supports midnight
-// crossing (e.g., 22:00-02:00).
-func isTimeOfDayInRange(t, start, end time.Time) bool {
-	tSec := timeOfDaySeconds(t)
-	startSec := timeOfDaySeconds(start)
-	endSec := timeOfDaySeconds(end)
-
-	if startSec <= endSec {
-		// Normal range (e.g., 02:00-06:00).
-		return tSec >= startSec && tSec <= endSec
-	}
-	// Midnight-crossing range (e.g., 22:00-02:00).
-	return tSec >= startSec || tSec <= endSec
+// calendarDaysBetween returns the number of dates from a's date to b's date,
+// ignoring time of day.
+func calendarDaysBetween(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da).Hours() / 24)
 }
 
 // timeOfDaySeconds returns the number of seconds elapsed since midnight.
@@ -65,7 +65,7 @@ func TestIsTimeInWindow(t *testing.T) {
 			name:       "daily: midnight crossing within",
 			now:        time.Date(2025, 7, 10, 23, 30, 0, 0, time.UTC),
 			start:      base.Add(22 * time.Hour), // 22:00
-			end:        base.Add(2 * time.Hour),   // 02:00 next day
+			end:        base.Add(2 * time.Hour),  // 02:00 next day
 			recurrence: "daily",
 			want:       true,
 		},
@@ -73,39 +73,39 @@ func TestIsTimeInWindow(t *testing.T) {
 			name:       "daily: midnight crossing within (after midnight)",
 			now:        time.Date(2025, 7, 11, 1, 30, 0, 0, time.UTC),
 			start:      base.Add(22 * time.Hour), // 22:00
-			end:        base.Add(2 * time.Hour),   // 02:00
+			end:        base.Add(2 * time.Hour),  // 02:00
 			recurrence: "daily",
 			want:       true,
 		},
 		{
 			name:       "weekly: correct weekday within time",
 			now:        time.Date(2025, 6, 22, 3, 0, 0, 0, time.UTC), // Sunday
-			start:      base.Add(2 * time.Hour),                       // Sunday 02:00
-			end:        base.Add(4 * time.Hour),                       // Sunday 04:00
+			start:      base.Add(2 * time.Hour),                      // Sunday 02:00
+			end:        base.Add(4 * time.Hour),                      // Sunday 04:00
 			recurrence: "weekly",
 			want:       true,
 		},
 		{
 			name:       "weekly: wrong weekday",
 			now:        time.Date(2025, 6, 23, 3, 0, 0, 0, time.UTC), // Monday
-			start:      base.Add(2 * time.Hour),                       // Sunday 02:00
-			end:        base.Add(4 * time.Hour),                       // Sunday 04:00
+			start:      base.Add(2 * time.Hour),                      // Sunday 02:00
+			end:        base.Add(4 * time.Hour),                      // Sunday 04:00
 			recurrence: "weekly",
 			want:       false,
 		},
 		{
 			name:       "monthly: correct day within time",
 			now:        time.Date(2025, 7, 15, 3, 0, 0, 0, time.UTC), // 15th
-			start:      base.Add(2 * time.Hour),                       // 15th 02:00
-			end:        base.Add(4 * time.Hour),                       // 15th 04:00
+			start:      base.Add(2 * time.Hour),                      // 15th 02:00
+			end:        base.Add(4 * time.Hour),                      // 15th 04:00
 			recurrence: "monthly",
 			want:       true,
 		},
 		{
 			name:       "monthly: wrong day",
 			now:        time.Date(2025, 7, 16, 3, 0, 0, 0, time.UTC), // 16th
-			start:      base.Add(2 * time.Hour),                       // 15th 02:00
-			end:        base.Add(4 * time.Hour),                       // 15th 04:00
+			start:      base.Add(2 * time.Hour),                      // 15th 02:00
+			end:        base.Add(4 * time.Hour),                      // 15th 04:00
 			recurrence: "monthly",
 			want:       false,
 		},
@@ -125,6 +125,70 @@ func TestIsTimeInWindow(t *testing.T) {
 			recurrence: "once",
 			want:       true,
 		},
+		{
+			name:       "once: across midnight",
+			now:        time.Date(2025, 6, 16, 1, 0, 0, 0, time.UTC),
+			start:      base.Add(22 * time.Hour),                     // Sunday 22:00
+			end:        time.Date(2025, 6, 16, 2, 0, 0, 0, time.UTC), // Monday 02:00
+			recurrence: "once",
+			want:       true,
+		},
+		{
+			name:       "daily: multi-day span next day",
+			now:        time.Date(2025, 7, 11, 5, 0, 0, 0, time.UTC),
+			start:      base.Add(22 * time.Hour),                     // 22:00
+			end:        time.Date(2025, 6, 16, 6, 0, 0, 0, time.UTC), // 06:00 next day
+			recurrence: "daily",
+			want:       true,
+		},
+		{
+			name:       "weekly: midnight crossing after midnight",
+			now:        time.Date(2025, 6, 23, 1, 0, 0, 0, time.UTC), // Monday
+			start:      base.Add(22 * time.Hour),                     // Sunday 22:00
+			end:        time.Date(2025, 6, 16, 2, 0, 0, 0, time.UTC), // Monday 02:00
+			recurrence: "weekly",
+			want:       true,
+		},
+		{
+			name:       "weekly: midnight crossing after end",
+			now:        time.Date(2025, 6, 23, 3, 0, 0, 0, time.UTC), // Monday
+			start:      base.Add(22 * time.Hour),                     // Sunday 22:00
+			end:        time.Date(2025, 6, 16, 2, 0, 0, 0, time.UTC), // Monday 02:00
+			recurrence: "weekly",
+			want:       false,
+		},
+		{
+			name:       "weekly: early hours of start weekday",
+			now:        time.Date(2025, 6, 22, 1, 0, 0, 0, time.UTC), // Sunday
+			start:      base.Add(22 * time.Hour),                     // Sunday 22:00
+			end:        time.Date(2025, 6, 16, 2, 0, 0, 0, time.UTC), // Monday 02:00
+			recurrence: "weekly",
+			want:       false,
+		},
+		{
+			name:       "monthly: midnight crossing into next day",
+			now:        time.Date(2025, 7, 16, 1, 0, 0, 0, time.UTC), // 16th
+			start:      base.Add(22 * time.Hour),                     // 15th 22:00
+			end:        time.Date(2025, 6, 16, 2, 0, 0, 0, time.UTC), // 16th 02:00
+			recurrence: "monthly",
+			want:       true,
+		},
+		{
+			name:       "monthly: 31st runs on last day of shorter month",
+			now:        time.Date(2025, 3, 1, 1, 0, 0, 0, time.UTC), // 1 March
+			start:      time.Date(2025, 1, 31, 22, 0, 0, 0, time.UTC),
+			end:        time.Date(2025, 2, 1, 2, 0, 0, 0, time.UTC),
+			recurrence: "monthly",
+			want:       true,
+		},
+		{
+			name:       "monthly: 31st not on 30th of a 31-day month",
+			now:        time.Date(2025, 5, 30, 23, 0, 0, 0, time.UTC),
+			start:      time.Date(2025, 1, 31, 22, 0, 0, 0, time.UTC),
+			end:        time.Date(2025, 2, 1, 2, 0, 0, 0, time.UTC),
+			recurrence: "monthly",
+			want:       false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -136,6 +200,52 @@ func TestIsTimeInWindow(t *testing.T) {
 	}
 }
 
+func TestIsTimeInWindow_DST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata not available: %v", err)
+	}
+	// Window defined in winter (EST, UTC-5): 01:00-03:00 local.
+	start := time.Date(2025, 1, 5, 1, 0, 0, 0, ny) // Sunday
+	end := time.Date(2025, 1, 5, 3, 0, 0, 0, ny)
+	utc := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2025, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		recurrence string
+		want       bool
+	}{
+		// Summer (EDT, UTC-4): 01:00-03:00 local is 05:00-07:00 UTC.
+		{"daily: summer start keeps local hour", utc(time.July, 10, 5, 30), "daily", true},
+		{"daily: summer before start", utc(time.July, 10, 4, 30), "daily", false},
+		{"daily: summer after local end", utc(time.July, 10, 7, 30), "daily", false},
+		// 9 March 2025: clocks jump from 02:00 EST to 03:00 EDT.
+		{"daily: spring forward before jump", utc(time.March, 9, 6, 30), "daily", true}, // 01:30 EST
+		{"daily: spring forward after end", utc(time.March, 9, 7, 30), "daily", false},  // 03:30 EDT
+		{"weekly: spring forward on Sunday", utc(time.March, 9, 6, 59), "weekly", true}, // 01:59 EST
+		{"weekly: spring forward Monday", utc(time.March, 10, 6, 30), "weekly", false},  // Monday 02:30 EDT
+		// 2 November 2025: 01:00-02:00 repeats, once in EDT and once in EST.
+		{"daily: fall back first 01:30", utc(time.November, 2, 5, 30), "daily", true},    // 01:30 EDT
+		{"daily: fall back second 01:30", utc(time.November, 2, 6, 30), "daily", true},   // 01:30 EST
+		{"daily: fall back 02:30 EST", utc(time.November, 2, 7, 30), "daily", true},      // 02:30 EST
+		{"daily: fall back after end", utc(time.November, 2, 8, 30), "daily", false},     // 03:30 EST
+		{"weekly: fall back on Sunday", utc(time.November, 2, 6, 30), "weekly", true},    // 01:30 EST
+		{"monthly: 5th in summer", utc(time.July, 5, 5, 30), "monthly", true},            // 01:30 EDT
+		{"monthly: 5th in summer after end", utc(time.July, 5, 7, 30), "monthly", false}, // 03:30 EDT
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := isTimeInWindow(tt.now, start, end, tt.recurrence)
+			if got != tt.want {
+				t.Errorf("isTimeInWindow(%s) = %v, want %v", tt.now.In(ny), got, tt.want)
+			}
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Store CRUD
 // ---------------------------------------------------------------------------
@@ -361,3 +471,53 @@ func TestIsDeviceInMaintenanceWindow_DeviceNotInList(t *testing.T) {
 		t.Error("expected device NOT to be in maintenance window (not in list)")
 	}
 }
+
+func TestIsInMaintenanceWindow_Timezone(t *testing.T) {
+	if _, err := time.LoadLocation("America/New_York"); err != nil {
+		t.Skipf("tzdata not available: %v", err)
+	}
+	s := testStore(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	// Daily 01:00-03:00 New York time, stored in UTC as the handlers do.
+	mw := &MaintWindow{
+		ID:         "mw-nightly",
+		Name:       "Nightly",
+		StartTime:  time.Date(2025, 1, 6, 6, 0, 0, 0, time.UTC), // 01:00 EST
+		EndTime:    time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC), // 03:00 EST
+		Recurrence: "daily",
+		Timezone:   "America/New_York",
+		DeviceIDs:  []string{"dev-1"},
+		Enabled:    true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := s.InsertMaintWindow(ctx, mw); err != nil {
+		t.Fatalf("InsertMaintWindow: %v", err)
+	}
+	got, err := s.GetMaintWindow(ctx, mw.ID)
+	if err != nil {
+		t.Fatalf("GetMaintWindow: %v", err)
+	}
+	if got.Timezone != "America/New_York" {
+		t.Errorf("Timezone = %q, want America/New_York", got.Timezone)
+	}
+
+	// 05:30 UTC in July is 01:30 EDT; a UTC evaluation would miss it.
+	inWindow, windowID, err := s.IsInMaintenanceWindow(ctx, "dev-1", time.Date(2025, 7, 1, 5, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("IsInMaintenanceWindow: %v", err)
+	}
+	if !inWindow || windowID != mw.ID {
+		t.Errorf("IsInMaintenanceWindow = %v, %q; want true, %q", inWindow, windowID, mw.ID)
+	}
+
+	inWindow, windowID, err = s.IsInMaintenanceWindow(ctx, "dev-1", time.Date(2025, 7, 1, 7, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("IsInMaintenanceWindow: %v", err)
+	}
+	if inWindow || windowID != "" {
+		t.Errorf("IsInMaintenanceWindow after end = %v, %q; want false, \"\"", inWindow, windowID)
+	}
+}
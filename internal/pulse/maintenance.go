@@ -8,7 +8,8 @@ import (
 )
 
 // startMaintenance launches a background goroutine that periodically
-// deletes old check results and resolved alerts past the retention window.
+// deletes old check results, resolved alerts, and ended incidents past the
+// retention window, and metrics rollups past the range they serve.
 func (m *Module) startMaintenance() {
 	m.wg.Add(1)
 	go func() {
@@ -35,10 +36,11 @@ func (m *Module) runMaintenance() {
 	ctx, cancel := context.WithTimeout(m.ctx, 30*time.Second)
 	defer cancel()
 
-	cutoff := time.Now().Add(-m.cfg.RetentionPeriod)
+	now := time.Now()
+	cutoff := now.Add(-m.cfg.RetentionPeriod)
 
-	// Purge old check results.
-	deletedResults, err := m.store.DeleteOldResults(ctx, cutoff)
+	// Purge old check results, honoring per-check retention.
+	deletedResults, err := m.store.DeleteExpiredResults(ctx, now, m.cfg.RetentionPeriod)
 	if err != nil {
 		m.logger.Warn("failed to delete old results", zap.Error(err))
 	} else if deletedResults > 0 {
@@ -52,4 +54,25 @@ func (m *Module) runMaintenance() {
 	} else if deletedAlerts > 0 {
 		m.logger.Info("purged old resolved alerts", zap.Int64("count", deletedAlerts))
 	}
+
+	// Purge old ended incidents.
+	deletedIncidents, err := m.store.DeleteOldIncidents(ctx, cutoff)
+	if err != nil {
+		m.logger.Warn("failed to delete old incidents", zap.Error(err))
+	} else if deletedIncidents > 0 {
+		m.logger.Info("purged old ended incidents", zap.Int64("count", deletedIncidents))
+	}
+
+	// Purge metrics rollups older than the longest range they serve.
+	for _, level := range rollupLevels {
+		deletedRollups, err := m.store.DeleteOldRollups(ctx, level.bucketSeconds, now.Add(-level.retention))
+		if err != nil {
+			m.logger.Warn("failed to delete old metrics rollups", zap.Error(err))
+		} else if deletedRollups > 0 {
+			m.logger.Info("purged old metrics rollups",
+				zap.Int64("bucket_seconds", level.bucketSeconds),
+				zap.Int64("count", deletedRollups),
+			)
+		}
+	}
 }
@@ -87,6 +87,69 @@ func TestRunMaintenance_DeletesOldResults(t *testing.T) {
 	}
 }
 
+// TestRunMaintenance_PerCheckRetention verifies that checks with
+// retention_days keep results for that long while other checks use the
+// global retention period.
+func TestRunMaintenance_PerCheckRetention(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	checks := []*Check{
+		{ID: "chk-critical", DeviceID: "dev-critical", RetentionDays: 90},
+		{ID: "chk-lab", DeviceID: "dev-lab", RetentionDays: 7},
+		{ID: "chk-default", DeviceID: "dev-default"},
+	}
+	for _, c := range checks {
+		c.CheckType = "icmp"
+		c.Target = "192.168.1.1"
+		c.IntervalSeconds = 30
+		c.Enabled = true
+		c.CreatedAt = now
+		c.UpdatedAt = now
+		insertTestCheck(t, s, c)
+
+		// One result 60 days old, one 10 days old.
+		for _, age := range []int{60, 10} {
+			r := &CheckResult{
+				CheckID:   c.ID,
+				DeviceID:  c.DeviceID,
+				Success:   true,
+				CheckedAt: now.AddDate(0, 0, -age),
+			}
+			if err := s.InsertResult(ctx, r); err != nil {
+				t.Fatalf("InsertResult: %v", err)
+			}
+		}
+	}
+
+	m := &Module{
+		logger: zap.NewNop(),
+		cfg: PulseConfig{
+			RetentionPeriod: 30 * 24 * time.Hour,
+		},
+		store: s,
+	}
+	m.ctx = context.Background()
+
+	m.runMaintenance()
+
+	want := map[string]int{
+		"dev-critical": 2, // 90 days keeps both
+		"dev-lab":      0, // 7 days drops both
+		"dev-default":  1, // global 30 days drops the 60-day-old result
+	}
+	for deviceID, n := range want {
+		results, err := s.ListResults(ctx, deviceID, 100)
+		if err != nil {
+			t.Fatalf("ListResults(%s): %v", deviceID, err)
+		}
+		if len(results) != n {
+			t.Errorf("%s: %d results remain, want %d", deviceID, len(results), n)
+		}
+	}
+}
+
 // TestRunMaintenance_DeletesOldResolvedAlerts verifies that runMaintenance
 // deletes only old resolved alerts, preserving recent resolved alerts and
 // all active alerts regardless of age.
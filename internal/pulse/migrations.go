@@ -130,5 +130,110 @@ func migrations() []plugin.Migration {
 				return err
 			},
 		},
+		{
+			Version:     6,
+			Description: "add agent vantage point to checks and results",
+			Up: func(tx *sql.Tx) error {
+				stmts := []string{
+					`ALTER TABLE pulse_checks ADD COLUMN agent_id TEXT NOT NULL DEFAULT ''`,
+					`ALTER TABLE pulse_check_results ADD COLUMN agent_id TEXT NOT NULL DEFAULT ''`,
+				}
+				for _, stmt := range stmts {
+					if _, err := tx.Exec(stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version:     7,
+			Description: "add maintenance window timezone and alert maintenance suppression",
+			Up: func(tx *sql.Tx) error {
+				stmts := []string{
+					`ALTER TABLE pulse_maint_windows ADD COLUMN timezone TEXT NOT NULL DEFAULT ''`,
+					`ALTER TABLE pulse_alerts ADD COLUMN maint_window_id TEXT NOT NULL DEFAULT ''`,
+				}
+				for _, stmt := range stmts {
+					if _, err := tx.Exec(stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version:     8,
+			Description: "create incidents table",
+			Up: func(tx *sql.Tx) error {
+				stmts := []string{
+					`CREATE TABLE IF NOT EXISTS pulse_incidents (
+						id TEXT PRIMARY KEY,
+						root_device_id TEXT NOT NULL,
+						root_alert_id TEXT NOT NULL DEFAULT '',
+						started_at DATETIME NOT NULL,
+						ended_at DATETIME
+					)`,
+					`ALTER TABLE pulse_alerts ADD COLUMN incident_id TEXT NOT NULL DEFAULT ''`,
+					`CREATE INDEX IF NOT EXISTS idx_pulse_alerts_incident ON pulse_alerts(incident_id)`,
+				}
+				for _, stmt := range stmts {
+					if _, err := tx.Exec(stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version:     9,
+			Description: "add per-check result retention",
+			Up: func(tx *sql.Tx) error {
+				// 0 keeps existing checks on the global retention_period.
+				_, err := tx.Exec(`ALTER TABLE pulse_checks ADD COLUMN retention_days INTEGER NOT NULL DEFAULT 0`)
+				return err
+			},
+		},
+		{
+			Version:     10,
+			Description: "add per-check alert thresholds",
+			Up: func(tx *sql.Tx) error {
+				// 0 keeps existing checks on the global consecutive_failures policy.
+				stmts := []string{
+					`ALTER TABLE pulse_checks ADD COLUMN warning_after INTEGER NOT NULL DEFAULT 0`,
+					`ALTER TABLE pulse_checks ADD COLUMN critical_after INTEGER NOT NULL DEFAULT 0`,
+				}
+				for _, stmt := range stmts {
+					if _, err := tx.Exec(stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version:     11,
+			Description: "create pulse_metrics_rollup table",
+			Up: func(tx *sql.Tx) error {
+				// bucket_start is a unix timestamp so buckets can be compared
+				// and aggregated without parsing SQLite date strings.
+				_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS pulse_metrics_rollup (
+					device_id TEXT NOT NULL,
+					agent_id TEXT NOT NULL DEFAULT '',
+					bucket_seconds INTEGER NOT NULL,
+					bucket_start INTEGER NOT NULL,
+					sample_count INTEGER NOT NULL,
+					success_count INTEGER NOT NULL,
+					latency_min REAL NOT NULL,
+					latency_avg REAL NOT NULL,
+					latency_max REAL NOT NULL,
+					packet_loss_min REAL NOT NULL,
+					packet_loss_avg REAL NOT NULL,
+					packet_loss_max REAL NOT NULL,
+					PRIMARY KEY (device_id, agent_id, bucket_seconds, bucket_start)
+				)`)
+				return err
+			},
+		},
 	}
 }
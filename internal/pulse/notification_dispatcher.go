@@ -11,8 +11,9 @@ import (
 // NotificationDispatcher handles alert events and dispatches notifications
 // to all enabled notification channels.
 type NotificationDispatcher struct {
-	store  *PulseStore
-	logger *zap.Logger
+	store        *PulseStore
+	logger       *zap.Logger
+	webhookQueue WebhookQueue
 }
 
 // NewNotificationDispatcher creates a new dispatcher.
@@ -23,6 +24,11 @@ func NewNotificationDispatcher(store *PulseStore, logger *zap.Logger) *Notificat
 	}
 }
 
+// SetWebhookQueue routes webhook channel deliveries through q for retries.
+func (d *NotificationDispatcher) SetWebhookQueue(q WebhookQueue) {
+	d.webhookQueue = q
+}
+
 // HandleAlertEvent processes an alert event from the event bus and delivers
 // notifications to all enabled channels.
 func (d *NotificationDispatcher) HandleAlertEvent(ctx context.Context, event plugin.Event) {
@@ -51,7 +57,7 @@ func (d *NotificationDispatcher) HandleAlertEvent(ctx context.Context, event plu
 	}
 
 	for i := range channels {
-		notifier, buildErr := buildNotifier(channels[i])
+		notifier, buildErr := buildNotifier(channels[i], d.webhookQueue)
 		if buildErr != nil {
 			d.logger.Warn("failed to build notifier",
 				zap.String("channel_id", channels[i].ID),
@@ -3,6 +3,8 @@ package pulse
 import (
 	"context"
 	"time"
+
+	"github.com/HerbHall/subnetree/internal/webhook"
 )
 
 // Notifier delivers alert notifications through a specific channel type.
@@ -24,6 +26,12 @@ type NotificationChannel struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// WebhookQueue persists webhook deliveries and retries them with backoff.
+// Implemented by *webhook.Queue; wired from the composition root.
+type WebhookQueue interface {
+	Enqueue(ctx context.Context, req webhook.DeliveryRequest) (*webhook.Delivery, error)
+}
+
 // WebhookConfig holds configuration for webhook notification delivery.
 type WebhookConfig struct {
 	URL     string            `json:"url"`
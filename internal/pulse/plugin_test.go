@@ -145,14 +145,18 @@ func TestSubscriptions_ReturnsTopics(t *testing.T) {
 	m := New()
 
 	subs := m.Subscriptions()
-	if len(subs) != 3 {
-		t.Fatalf("Subscriptions() returned %d, want 3", len(subs))
+	if len(subs) != 7 {
+		t.Fatalf("Subscriptions() returned %d, want 7", len(subs))
 	}
 
 	expectedTopics := map[string]bool{
-		TopicDeviceDiscovered: false,
-		TopicAlertTriggered:   false,
-		TopicAlertResolved:    false,
+		TopicDeviceDiscovered:  false,
+		TopicAgentDisconnected: false,
+		TopicAgentReconnected:  false,
+		TopicAgentEvents:       false,
+		TopicAgentHardware:     false,
+		TopicAlertTriggered:    false,
+		TopicAlertResolved:     false,
 	}
 	for i := range subs {
 		if subs[i].Handler == nil {
@@ -32,6 +32,13 @@ type Module struct {
 	checkers   map[string]Checker
 	alerter    *Alerter
 	dispatcher *NotificationDispatcher
+	eventRules []eventLogMatcher
+	hwLimits   []HardwareThreshold
+
+	// agentRunner overrides role-based resolution of the agent check runner.
+	agentRunner AgentCheckRunner
+	// discovery overrides role-based resolution of the device provider.
+	discovery roles.DiscoveryProvider
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -71,6 +78,18 @@ func (m *Module) Init(_ context.Context, deps plugin.Dependencies) error {
 		m.store = NewPulseStore(deps.Store.DB())
 	}
 
+	rules, err := compileEventLogRules(m.cfg.EventLogRules)
+	if err != nil {
+		return fmt.Errorf("pulse event log rules: %w", err)
+	}
+	m.eventRules = rules
+
+	limits, err := validateHardwareThresholds(m.cfg.HardwareThresholds)
+	if err != nil {
+		return fmt.Errorf("pulse hardware thresholds: %w", err)
+	}
+	m.hwLimits = limits
+
 	m.bus = deps.Bus
 	m.plugins = deps.Plugins
 
@@ -79,6 +98,7 @@ func (m *Module) Init(_ context.Context, deps plugin.Dependencies) error {
 		zap.Duration("ping_timeout", m.cfg.PingTimeout),
 		zap.Int("ping_count", m.cfg.PingCount),
 		zap.Int("max_workers", m.cfg.MaxWorkers),
+		zap.Bool("jitter", m.cfg.Jitter),
 	)
 	return nil
 }
@@ -110,10 +130,12 @@ func (m *Module) Start(_ context.Context) error {
 			m.cfg.MaxWorkers,
 			m.logger,
 		)
+		m.scheduler.SetJitter(m.cfg.Jitter)
 		m.scheduler.Start(m.ctx)
 	}
 
 	m.startMaintenance()
+	m.startRollup()
 
 	m.logger.Info("pulse module started")
 	return nil
@@ -132,32 +154,47 @@ func (m *Module) Stop(_ context.Context) error {
 }
 
 // executeCheck runs a check using the appropriate checker for the check type,
-// stores the result, processes alerts, and publishes metrics.
+// or on the check's Scout agent when AgentID is set, stores the result,
+// processes alerts, and publishes metrics.
 func (m *Module) executeCheck(ctx context.Context, check Check) {
 	checkType := check.CheckType
 	if checkType == "" {
 		checkType = "icmp" // default for legacy checks
 	}
 
-	checker, ok := m.checkers[checkType]
-	if !ok {
-		m.logger.Warn("unknown check type",
-			zap.String("check_id", check.ID),
-			zap.String("check_type", checkType),
-		)
-		return
-	}
+	var result *CheckResult
+	var err error
+	if check.AgentID != "" {
+		result, err = m.runAgentCheck(ctx, check, checkType)
+		if err != nil {
+			m.logger.Debug("agent check produced no result",
+				zap.String("check_id", check.ID),
+				zap.String("agent_id", check.AgentID),
+				zap.Error(err),
+			)
+			return
+		}
+	} else {
+		checker, ok := m.checkers[checkType]
+		if !ok {
+			m.logger.Warn("unknown check type",
+				zap.String("check_id", check.ID),
+				zap.String("check_type", checkType),
+			)
+			return
+		}
 
-	result, err := checker.Check(ctx, check.Target)
-	if err != nil {
-		m.logger.Debug("check returned error",
-			zap.String("check_id", check.ID),
-			zap.String("target", check.Target),
-			zap.Error(err),
-		)
-	}
-	if result == nil {
-		return
+		result, err = checker.Check(ctx, check.Target)
+		if err != nil {
+			m.logger.Debug("check returned error",
+				zap.String("check_id", check.ID),
+				zap.String("target", check.Target),
+				zap.Error(err),
+			)
+		}
+		if result == nil {
+			return
+		}
 	}
 
 	result.CheckID = check.ID
@@ -214,6 +251,12 @@ func (m *Module) publishMetrics(ctx context.Context, check Check, result *CheckR
 		{DeviceID: check.DeviceID, MetricName: prefix + "_success", Value: successVal, Timestamp: now},
 	}
 
+	if result.AgentID != "" {
+		for i := range metrics {
+			metrics[i].Tags = map[string]string{"agent_id": result.AgentID}
+		}
+	}
+
 	m.bus.PublishAsync(ctx, plugin.Event{
 		Topic:     TopicMetricsCollected,
 		Source:    "pulse",
@@ -257,6 +300,10 @@ func (m *Module) Health(_ context.Context) plugin.HealthStatus {
 func (m *Module) Subscriptions() []plugin.Subscription {
 	return []plugin.Subscription{
 		{Topic: TopicDeviceDiscovered, Handler: m.handleDeviceDiscovered},
+		{Topic: TopicAgentDisconnected, Handler: m.handleAgentDisconnected},
+		{Topic: TopicAgentReconnected, Handler: m.handleAgentReconnected},
+		{Topic: TopicAgentEvents, Handler: m.handleAgentEvents},
+		{Topic: TopicAgentHardware, Handler: m.handleAgentHardware},
 		{Topic: TopicAlertTriggered, Handler: m.handleAlertNotification},
 		{Topic: TopicAlertResolved, Handler: m.handleAlertNotification},
 	}
@@ -313,6 +360,15 @@ func (m *Module) Status(ctx context.Context, deviceID string) (*roles.MonitorSta
 	return status, nil
 }
 
+// SetWebhookQueue routes webhook notification channels through the webhook
+// module's persistent retry queue. Called from the composition root after
+// the webhook module is initialized.
+func (m *Module) SetWebhookQueue(q WebhookQueue) {
+	if m.dispatcher != nil {
+		m.dispatcher.SetWebhookQueue(q)
+	}
+}
+
 // Store returns the PulseStore for external use (e.g., seeding demo data).
 func (m *Module) Store() *PulseStore {
 	return m.store
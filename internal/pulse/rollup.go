@@ -0,0 +1,271 @@
+package pulse
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// rollupInterval is how often completed buckets are rolled up.
+const rollupInterval = 5 * time.Minute
+
+// rollupBackfill bounds how far back the first rollup, or a rollup after a
+// long pause, reads raw results.
+const rollupBackfill = 30 * 24 * time.Hour
+
+// rollupLevel is one pre-aggregated bucket size and how long its rows are
+// kept. Retention matches the longest metrics range served from the level.
+type rollupLevel struct {
+	bucketSeconds int64
+	retention     time.Duration
+}
+
+// rollupLevels are the bucket sizes QueryVantageMetrics reads for ranges
+// longer than a day.
+var rollupLevels = []rollupLevel{
+	{bucketSeconds: 300, retention: 7 * 24 * time.Hour},
+	{bucketSeconds: 3600, retention: 30 * 24 * time.Hour},
+}
+
+// rollupKey identifies one rollup row.
+type rollupKey struct {
+	deviceID    string
+	agentID     string
+	bucketStart int64
+}
+
+// rollupBucket accumulates raw results for one rollup row.
+type rollupBucket struct {
+	count        int
+	successCount int
+	latencyMin   float64
+	latencySum   float64
+	latencyMax   float64
+	lossMin      float64
+	lossSum      float64
+	lossMax      float64
+}
+
+func (b *rollupBucket) add(latency, loss float64, success bool) {
+	if b.count == 0 || latency < b.latencyMin {
+		b.latencyMin = latency
+	}
+	if b.count == 0 || latency > b.latencyMax {
+		b.latencyMax = latency
+	}
+	if b.count == 0 || loss < b.lossMin {
+		b.lossMin = loss
+	}
+	if b.count == 0 || loss > b.lossMax {
+		b.lossMax = loss
+	}
+	b.latencySum += latency
+	b.lossSum += loss
+	if success {
+		b.successCount++
+	}
+	b.count++
+}
+
+// RollupMetrics aggregates raw check results into completed buckets of the
+// given size, continuing from the last rolled up bucket. Buckets are keyed
+// by device and vantage point so QueryVantageMetrics can filter them.
+// Returns the number of rollup rows written.
+func (s *PulseStore) RollupMetrics(ctx context.Context, bucketSec int64, now time.Time) (int, error) {
+	end := (now.Unix() / bucketSec) * bucketSec
+
+	var last int64
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(bucket_start), 0) FROM pulse_metrics_rollup
+		WHERE bucket_seconds = ?`,
+		bucketSec,
+	).Scan(&last); err != nil {
+		return 0, fmt.Errorf("find last rollup: %w", err)
+	}
+	start := last + bucketSec
+	if earliest := end - int64(rollupBackfill/time.Second); start < earliest {
+		start = (earliest / bucketSec) * bucketSec
+	}
+	if start >= end {
+		return 0, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT device_id, agent_id, latency_ms, packet_loss, success, checked_at
+		FROM pulse_check_results
+		WHERE checked_at >= ? AND checked_at < ?`,
+		time.Unix(start, 0).UTC(), time.Unix(end, 0).UTC(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("query results for rollup: %w", err)
+	}
+	buckets := make(map[rollupKey]*rollupBucket)
+	for rows.Next() {
+		var key rollupKey
+		var latency, loss float64
+		var successInt int
+		var checkedAt time.Time
+		if err := rows.Scan(&key.deviceID, &key.agentID, &latency, &loss, &successInt, &checkedAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan result for rollup: %w", err)
+		}
+		key.bucketStart = (checkedAt.Unix() / bucketSec) * bucketSec
+		b, ok := buckets[key]
+		if !ok {
+			b = &rollupBucket{}
+			buckets[key] = b
+		}
+		b.add(latency, loss, successInt != 0)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterate results for rollup: %w", err)
+	}
+	if len(buckets) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin rollup: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
+
+	for key, b := range buckets {
+		n := float64(b.count)
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO pulse_metrics_rollup (
+				device_id, agent_id, bucket_seconds, bucket_start, sample_count, success_count,
+				latency_min, latency_avg, latency_max, packet_loss_min, packet_loss_avg, packet_loss_max
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (device_id, agent_id, bucket_seconds, bucket_start) DO UPDATE SET
+				sample_count = excluded.sample_count,
+				success_count = excluded.success_count,
+				latency_min = excluded.latency_min,
+				latency_avg = excluded.latency_avg,
+				latency_max = excluded.latency_max,
+				packet_loss_min = excluded.packet_loss_min,
+				packet_loss_avg = excluded.packet_loss_avg,
+				packet_loss_max = excluded.packet_loss_max`,
+			key.deviceID, key.agentID, bucketSec, key.bucketStart, b.count, b.successCount,
+			b.latencyMin, b.latencySum/n, b.latencyMax, b.lossMin, b.lossSum/n, b.lossMax,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("upsert rollup: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit rollup: %w", err)
+	}
+	return len(buckets), nil
+}
+
+// addRollupBuckets merges the rollup rows of a device since the given time
+// into buckets, combining vantage points by sample count. Returns the end of
+// the last bucket read, or zero if there were none.
+func (s *PulseStore) addRollupBuckets(ctx context.Context, buckets map[int64]*metricBucket, deviceID, vantage string, bucketSec int64, since time.Time) (int64, error) {
+	query := `
+		SELECT bucket_start, sample_count, success_count, latency_avg, packet_loss_avg
+		FROM pulse_metrics_rollup
+		WHERE device_id = ? AND bucket_seconds = ? AND bucket_start >= ?`
+	args := []any{deviceID, bucketSec, (since.Unix() / bucketSec) * bucketSec}
+	switch vantage {
+	case "":
+	case vantageServer:
+		query += ` AND agent_id = ''`
+	default:
+		query += ` AND agent_id = ?`
+		args = append(args, vantage)
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("query metrics rollup: %w", err)
+	}
+	defer rows.Close()
+
+	var end int64
+	for rows.Next() {
+		var start int64
+		var count, successCount int
+		var latencyAvg, lossAvg float64
+		if err := rows.Scan(&start, &count, &successCount, &latencyAvg, &lossAvg); err != nil {
+			return 0, fmt.Errorf("scan metrics rollup: %w", err)
+		}
+		b, ok := buckets[start]
+		if !ok {
+			b = &metricBucket{}
+			buckets[start] = b
+		}
+		b.latencySum += latencyAvg * float64(count)
+		b.packetLossSum += lossAvg * float64(count)
+		b.successCount += successCount
+		b.total += count
+		end = max(end, start+bucketSec)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterate metrics rollup: %w", err)
+	}
+	return end, nil
+}
+
+// DeleteOldRollups deletes rollup rows of the given bucket size that start
+// before the given time. Returns the number of rows deleted.
+func (s *PulseStore) DeleteOldRollups(ctx context.Context, bucketSec int64, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM pulse_metrics_rollup WHERE bucket_seconds = ? AND bucket_start < ?`,
+		bucketSec, before.Unix(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("delete old rollups: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// startRollup launches a background goroutine that periodically rolls raw
+// check results up into the pre-aggregated metrics buckets.
+func (m *Module) startRollup() {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(rollupInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-ticker.C:
+				m.runRollup()
+			}
+		}
+	}()
+}
+
+// runRollup executes a single rollup cycle for every rollup level.
+func (m *Module) runRollup() {
+	if m.store == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(m.ctx, time.Minute)
+	defer cancel()
+
+	now := time.Now()
+	for _, level := range rollupLevels {
+		n, err := m.store.RollupMetrics(ctx, level.bucketSeconds, now)
+		if err != nil {
+			m.logger.Warn("failed to roll up metrics",
+				zap.Int64("bucket_seconds", level.bucketSeconds),
+				zap.Error(err),
+			)
+			continue
+		}
+		if n > 0 {
+			m.logger.Debug("rolled up metrics",
+				zap.Int64("bucket_seconds", level.bucketSeconds),
+				zap.Int("buckets", n),
+			)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package pulse
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRollupMetrics_QueryMatchesRaw(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	seedMetricsData(t, s, "dev-r", 1000, now.Add(-10*24*time.Hour), 864*time.Second)
+
+	// Before any rollup, long ranges are bucketed from raw results.
+	want := map[string]*MetricSeries{}
+	for _, r := range []string{"7d", "30d"} {
+		series, err := s.QueryMetrics(ctx, "dev-r", "latency", r)
+		if err != nil {
+			t.Fatalf("QueryMetrics(%s): %v", r, err)
+		}
+		want[r] = series
+	}
+
+	for _, level := range rollupLevels {
+		n, err := s.RollupMetrics(ctx, level.bucketSeconds, now)
+		if err != nil {
+			t.Fatalf("RollupMetrics(%d): %v", level.bucketSeconds, err)
+		}
+		if n == 0 {
+			t.Fatalf("RollupMetrics(%d) wrote no buckets", level.bucketSeconds)
+		}
+		// A second pass has no completed buckets left to roll up.
+		if n, err := s.RollupMetrics(ctx, level.bucketSeconds, now); err != nil || n != 0 {
+			t.Errorf("second RollupMetrics(%d) = %d, %v; want 0, nil", level.bucketSeconds, n, err)
+		}
+	}
+
+	// Remove raw results covered by the rollup; queries must still return
+	// the same series.
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM pulse_check_results WHERE checked_at < ?`, now.Add(-2*time.Hour)); err != nil {
+		t.Fatalf("delete raw results: %v", err)
+	}
+	for r, w := range want {
+		got, err := s.QueryMetrics(ctx, "dev-r", "latency", r)
+		if err != nil {
+			t.Fatalf("QueryMetrics(%s): %v", r, err)
+		}
+		// The first bucket may straddle the start of the range, so it can
+		// include results the raw query skipped.
+		cut := now.Add(-validRanges[r] + time.Hour)
+		gotPoints, wantPoints := pointsAfter(got.Points, cut), pointsAfter(w.Points, cut)
+		if len(gotPoints) != len(wantPoints) {
+			t.Fatalf("%s: got %d points, want %d", r, len(gotPoints), len(wantPoints))
+		}
+		for i := range gotPoints {
+			if !gotPoints[i].Timestamp.Equal(wantPoints[i].Timestamp) || math.Abs(gotPoints[i].Value-wantPoints[i].Value) > 0.001 {
+				t.Errorf("%s: points[%d] = %+v, want %+v", r, i, gotPoints[i], wantPoints[i])
+				break
+			}
+		}
+	}
+}
+
+func pointsAfter(points []MetricDataPoint, cut time.Time) []MetricDataPoint {
+	var out []MetricDataPoint
+	for _, p := range points {
+		if p.Timestamp.After(cut) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func TestRollupMetrics_Vantage(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Hour)
+	check := makeTestCheck(t, s, "dev-v", "icmp", "10.0.0.1")
+	for i, agentID := range []string{"", "agent-1"} {
+		if err := s.InsertResult(ctx, &CheckResult{
+			CheckID: check.ID, DeviceID: "dev-v", AgentID: agentID, Success: i == 0,
+			LatencyMs: 10, CheckedAt: now.Add(-2 * time.Hour),
+		}); err != nil {
+			t.Fatalf("InsertResult: %v", err)
+		}
+	}
+	if _, err := s.RollupMetrics(ctx, 300, now); err != nil {
+		t.Fatalf("RollupMetrics: %v", err)
+	}
+
+	tests := []struct {
+		vantage string
+		want    float64
+	}{
+		{"", 50},
+		{vantageServer, 100},
+		{"agent-1", 0},
+	}
+	for _, tt := range tests {
+		series, err := s.QueryVantageMetrics(ctx, "dev-v", "success_rate", "7d", tt.vantage)
+		if err != nil {
+			t.Fatalf("QueryVantageMetrics(%q): %v", tt.vantage, err)
+		}
+		if len(series.Points) != 1 || series.Points[0].Value != tt.want {
+			t.Errorf("vantage %q: points = %+v, want one point of %v", tt.vantage, series.Points, tt.want)
+		}
+	}
+
+	deleted, err := s.DeleteOldRollups(ctx, 300, now)
+	if err != nil {
+		t.Fatalf("DeleteOldRollups: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("DeleteOldRollups deleted %d, want 2", deleted)
+	}
+}
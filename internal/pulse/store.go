@@ -4,6 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -18,6 +21,7 @@ type MetricSeries struct {
 	DeviceID string            `json:"device_id"`
 	Metric   string            `json:"metric"`
 	Range    string            `json:"range"`
+	Vantage  string            `json:"vantage,omitempty"` // "server" or an agent ID; empty for all results
 	Points   []MetricDataPoint `json:"points"`
 }
 
@@ -29,6 +33,10 @@ type Check struct {
 	CheckType       string    `json:"check_type"`
 	Target          string    `json:"target"`
 	IntervalSeconds int       `json:"interval_seconds"`
+	AgentID         string    `json:"agent_id,omitempty"`       // Scout agent that runs the check; empty runs it from the server
+	RetentionDays   int       `json:"retention_days,omitempty"` // Days of results to keep; 0 uses the global retention period
+	WarningAfter    int       `json:"warning_after,omitempty"`  // Consecutive failures before a warning alert; 0 uses consecutive_failures
+	CriticalAfter   int       `json:"critical_after,omitempty"` // Consecutive failures before the alert is critical; 0 uses twice the warning threshold
 	Enabled         bool      `json:"enabled"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
@@ -43,6 +51,7 @@ type CheckResult struct {
 	LatencyMs    float64   `json:"latency_ms"`
 	PacketLoss   float64   `json:"packet_loss"`
 	ErrorMessage string    `json:"error_message,omitempty"`
+	AgentID      string    `json:"agent_id,omitempty"` // Vantage point; empty when run from the server
 	CheckedAt    time.Time `json:"checked_at"`
 }
 
@@ -60,6 +69,8 @@ type Alert struct {
 	ConsecutiveFailures int        `json:"consecutive_failures"`
 	Suppressed          bool       `json:"suppressed"`
 	SuppressedBy        string     `json:"suppressed_by,omitempty"`
+	MaintWindowID       string     `json:"maint_window_id,omitempty"` // Set while suppressed by a maintenance window
+	IncidentID          string     `json:"incident_id,omitempty"`
 }
 
 // CheckDependency represents a dependency between a check and an upstream device.
@@ -72,11 +83,14 @@ type CheckDependency struct {
 
 // AlertFilters controls filtering for ListAlerts queries.
 type AlertFilters struct {
-	DeviceID      string
-	Severity      string
-	ActiveOnly    bool
-	Suppressed    *bool // nil = no filter, true = only suppressed, false = only non-suppressed
-	Limit         int
+	DeviceID   string
+	Severity   string
+	ActiveOnly bool
+	Suppressed *bool     // nil = no filter, true = only suppressed, false = only non-suppressed
+	Since      time.Time // zero = no lower bound on triggered_at
+	Until      time.Time // zero = no upper bound on triggered_at
+	Limit      int
+	Offset     int
 }
 
 // PulseStore provides database access for the Pulse monitoring plugin.
@@ -99,10 +113,11 @@ func (s *PulseStore) InsertCheck(ctx context.Context, c *Check) error {
 	}
 	_, err := s.db.ExecContext(ctx, `
 		INSERT INTO pulse_checks (
-			id, device_id, check_type, target, interval_seconds, enabled, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
-		c.ID, c.DeviceID, c.CheckType, c.Target, c.IntervalSeconds,
-		enabled, c.CreatedAt, c.UpdatedAt,
+			id, device_id, check_type, target, interval_seconds, agent_id, retention_days,
+			warning_after, critical_after, enabled, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.ID, c.DeviceID, c.CheckType, c.Target, c.IntervalSeconds, c.AgentID, c.RetentionDays,
+		c.WarningAfter, c.CriticalAfter, enabled, c.CreatedAt, c.UpdatedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("insert check: %w", err)
@@ -115,11 +130,13 @@ func (s *PulseStore) GetCheck(ctx context.Context, id string) (*Check, error) {
 	var c Check
 	var enabledInt int
 	err := s.db.QueryRowContext(ctx, `
-		SELECT id, device_id, check_type, target, interval_seconds, enabled, created_at, updated_at
+		SELECT id, device_id, check_type, target, interval_seconds, agent_id, retention_days,
+			warning_after, critical_after, enabled, created_at, updated_at
 		FROM pulse_checks WHERE id = ?`,
 		id,
 	).Scan(
-		&c.ID, &c.DeviceID, &c.CheckType, &c.Target, &c.IntervalSeconds,
+		&c.ID, &c.DeviceID, &c.CheckType, &c.Target, &c.IntervalSeconds, &c.AgentID, &c.RetentionDays,
+		&c.WarningAfter, &c.CriticalAfter,
 		&enabledInt, &c.CreatedAt, &c.UpdatedAt,
 	)
 	if err != nil {
@@ -137,11 +154,13 @@ func (s *PulseStore) GetCheckByDeviceID(ctx context.Context, deviceID string) (*
 	var c Check
 	var enabledInt int
 	err := s.db.QueryRowContext(ctx, `
-		SELECT id, device_id, check_type, target, interval_seconds, enabled, created_at, updated_at
+		SELECT id, device_id, check_type, target, interval_seconds, agent_id, retention_days,
+			warning_after, critical_after, enabled, created_at, updated_at
 		FROM pulse_checks WHERE device_id = ? LIMIT 1`,
 		deviceID,
 	).Scan(
-		&c.ID, &c.DeviceID, &c.CheckType, &c.Target, &c.IntervalSeconds,
+		&c.ID, &c.DeviceID, &c.CheckType, &c.Target, &c.IntervalSeconds, &c.AgentID, &c.RetentionDays,
+		&c.WarningAfter, &c.CriticalAfter,
 		&enabledInt, &c.CreatedAt, &c.UpdatedAt,
 	)
 	if err != nil {
@@ -157,7 +176,8 @@ func (s *PulseStore) GetCheckByDeviceID(ctx context.Context, deviceID string) (*
 // ListEnabledChecks returns all enabled monitoring checks.
 func (s *PulseStore) ListEnabledChecks(ctx context.Context) ([]Check, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, device_id, check_type, target, interval_seconds, enabled, created_at, updated_at
+		SELECT id, device_id, check_type, target, interval_seconds, agent_id, retention_days,
+			warning_after, critical_after, enabled, created_at, updated_at
 		FROM pulse_checks WHERE enabled = 1 ORDER BY created_at`,
 	)
 	if err != nil {
@@ -170,7 +190,8 @@ func (s *PulseStore) ListEnabledChecks(ctx context.Context) ([]Check, error) {
 		var c Check
 		var enabledInt int
 		if err := rows.Scan(
-			&c.ID, &c.DeviceID, &c.CheckType, &c.Target, &c.IntervalSeconds,
+			&c.ID, &c.DeviceID, &c.CheckType, &c.Target, &c.IntervalSeconds, &c.AgentID, &c.RetentionDays,
+			&c.WarningAfter, &c.CriticalAfter,
 			&enabledInt, &c.CreatedAt, &c.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan check row: %w", err)
@@ -202,7 +223,8 @@ func (s *PulseStore) UpdateCheckEnabled(ctx context.Context, id string, enabled
 // the first IP address or the raw device_id when hostname is empty.
 func (s *PulseStore) ListAllChecks(ctx context.Context) ([]Check, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT c.id, c.device_id, c.check_type, c.target, c.interval_seconds,
+		SELECT c.id, c.device_id, c.check_type, c.target, c.interval_seconds, c.agent_id, c.retention_days,
+			c.warning_after, c.critical_after,
 			c.enabled, c.created_at, c.updated_at,
 			COALESCE(NULLIF(d.hostname, ''), json_extract(d.ip_addresses, '$[0]'), c.device_id) AS device_name
 		FROM pulse_checks c
@@ -219,7 +241,8 @@ func (s *PulseStore) ListAllChecks(ctx context.Context) ([]Check, error) {
 		var c Check
 		var enabledInt int
 		if err := rows.Scan(
-			&c.ID, &c.DeviceID, &c.CheckType, &c.Target, &c.IntervalSeconds,
+			&c.ID, &c.DeviceID, &c.CheckType, &c.Target, &c.IntervalSeconds, &c.AgentID, &c.RetentionDays,
+			&c.WarningAfter, &c.CriticalAfter,
 			&enabledInt, &c.CreatedAt, &c.UpdatedAt, &c.DeviceName,
 		); err != nil {
 			return nil, fmt.Errorf("scan check row: %w", err)
@@ -230,16 +253,19 @@ func (s *PulseStore) ListAllChecks(ctx context.Context) ([]Check, error) {
 	return checks, rows.Err()
 }
 
-// UpdateCheck updates a check's type, target, interval, and enabled state.
+// UpdateCheck updates a check's type, target, interval, agent, retention,
+// alert thresholds, and enabled state.
 func (s *PulseStore) UpdateCheck(ctx context.Context, c *Check) error {
 	enabledInt := 0
 	if c.Enabled {
 		enabledInt = 1
 	}
 	_, err := s.db.ExecContext(ctx, `
-		UPDATE pulse_checks SET check_type = ?, target = ?, interval_seconds = ?, enabled = ?, updated_at = ?
+		UPDATE pulse_checks SET check_type = ?, target = ?, interval_seconds = ?, agent_id = ?, retention_days = ?,
+			warning_after = ?, critical_after = ?, enabled = ?, updated_at = ?
 		WHERE id = ?`,
-		c.CheckType, c.Target, c.IntervalSeconds, enabledInt, c.UpdatedAt, c.ID,
+		c.CheckType, c.Target, c.IntervalSeconds, c.AgentID, c.RetentionDays,
+		c.WarningAfter, c.CriticalAfter, enabledInt, c.UpdatedAt, c.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("update check: %w", err)
@@ -270,10 +296,10 @@ func (s *PulseStore) InsertResult(ctx context.Context, r *CheckResult) error {
 	}
 	_, err := s.db.ExecContext(ctx, `
 		INSERT INTO pulse_check_results (
-			check_id, device_id, success, latency_ms, packet_loss, error_message, checked_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			check_id, device_id, success, latency_ms, packet_loss, error_message, agent_id, checked_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
 		r.CheckID, r.DeviceID, success, r.LatencyMs, r.PacketLoss,
-		r.ErrorMessage, r.CheckedAt,
+		r.ErrorMessage, r.AgentID, r.CheckedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("insert result: %w", err)
@@ -300,7 +326,7 @@ func (s *PulseStore) ListResults(ctx context.Context, deviceID string, limit int
 		limit = 100
 	}
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, check_id, device_id, success, latency_ms, packet_loss, error_message, checked_at
+		SELECT id, check_id, device_id, success, latency_ms, packet_loss, error_message, agent_id, checked_at
 		FROM pulse_check_results WHERE device_id = ? ORDER BY checked_at DESC LIMIT ?`,
 		deviceID, limit,
 	)
@@ -315,7 +341,7 @@ func (s *PulseStore) ListResults(ctx context.Context, deviceID string, limit int
 		var successInt int
 		if err := rows.Scan(
 			&r.ID, &r.CheckID, &r.DeviceID, &successInt, &r.LatencyMs,
-			&r.PacketLoss, &r.ErrorMessage, &r.CheckedAt,
+			&r.PacketLoss, &r.ErrorMessage, &r.AgentID, &r.CheckedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan result row: %w", err)
 		}
@@ -338,6 +364,56 @@ func (s *PulseStore) DeleteOldResults(ctx context.Context, before time.Time) (in
 	return result.RowsAffected()
 }
 
+// DeleteExpiredResults deletes check results past their check's retention:
+// retention_days for checks that set it, and defaultRetention for all other
+// results, including those of deleted checks. Returns the number of rows
+// deleted.
+func (s *PulseStore) DeleteExpiredResults(ctx context.Context, now time.Time, defaultRetention time.Duration) (int64, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT DISTINCT retention_days FROM pulse_checks WHERE retention_days > 0`)
+	if err != nil {
+		return 0, fmt.Errorf("list check retention: %w", err)
+	}
+	var retentions []int
+	for rows.Next() {
+		var days int
+		if err := rows.Scan(&days); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan check retention: %w", err)
+		}
+		retentions = append(retentions, days)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("list check retention: %w", err)
+	}
+
+	var total int64
+	for _, days := range retentions {
+		result, err := s.db.ExecContext(ctx, `
+			DELETE FROM pulse_check_results WHERE checked_at < ?
+				AND check_id IN (SELECT id FROM pulse_checks WHERE retention_days = ?)`,
+			now.AddDate(0, 0, -days), days,
+		)
+		if err != nil {
+			return total, fmt.Errorf("delete expired results: %w", err)
+		}
+		n, _ := result.RowsAffected()
+		total += n
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM pulse_check_results WHERE checked_at < ?
+			AND check_id NOT IN (SELECT id FROM pulse_checks WHERE retention_days > 0)`,
+		now.Add(-defaultRetention),
+	)
+	if err != nil {
+		return total, fmt.Errorf("delete expired results: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return total + n, nil
+}
+
 // -- Metrics Queries --
 
 // validMetrics is the set of supported metric names for QueryMetrics.
@@ -364,10 +440,23 @@ type metricBucket struct {
 	total         int
 }
 
+// vantageServer selects results of checks run from the server itself in
+// QueryVantageMetrics.
+const vantageServer = "server"
+
 // QueryMetrics returns aggregated time-series data for a device, with
-// automatic downsampling based on the requested time range.
+// automatic downsampling based on the requested time range. Ranges up to a
+// day are bucketed from raw results; longer ranges read the 5-minute and
+// 1-hour rollups written by RollupMetrics.
 // Bucketing is performed in Go to avoid SQLite date-format parsing issues.
 func (s *PulseStore) QueryMetrics(ctx context.Context, deviceID, metric, timeRange string) (*MetricSeries, error) {
+	return s.QueryVantageMetrics(ctx, deviceID, metric, timeRange, "")
+}
+
+// QueryVantageMetrics is QueryMetrics restricted to the results produced
+// from one vantage point: "server" for checks run by the server, or an
+// agent ID. An empty vantage includes all results.
+func (s *PulseStore) QueryVantageMetrics(ctx context.Context, deviceID, metric, timeRange, vantage string) (*MetricSeries, error) {
 	if !validMetrics[metric] {
 		return nil, fmt.Errorf("unknown metric %q: must be latency, packet_loss, or success_rate", metric)
 	}
@@ -390,46 +479,26 @@ func (s *PulseStore) QueryMetrics(ctx context.Context, deviceID, metric, timeRan
 		bucketSec = 3600 // 1-hour buckets
 	}
 
-	// Fetch raw results within the time range.
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT latency_ms, packet_loss, success, checked_at
-		FROM pulse_check_results
-		WHERE device_id = ? AND checked_at >= ?
-		ORDER BY checked_at ASC`,
-		deviceID, since,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("query metrics: %w", err)
-	}
-	defer rows.Close()
-
-	// Aggregate results into time buckets in Go.
 	buckets := make(map[int64]*metricBucket)
-	var bucketKeys []int64
-	for rows.Next() {
-		var latency, packetLoss float64
-		var successInt int
-		var checkedAt time.Time
-		if err := rows.Scan(&latency, &packetLoss, &successInt, &checkedAt); err != nil {
-			return nil, fmt.Errorf("scan metric row: %w", err)
+
+	// Rolled up ranges fill only the buckets not rolled up yet from raw
+	// results.
+	rawSince := since
+	if bucketSec > 60 {
+		end, err := s.addRollupBuckets(ctx, buckets, deviceID, vantage, bucketSec, since)
+		if err != nil {
+			return nil, err
 		}
-		key := (checkedAt.Unix() / bucketSec) * bucketSec
-		b, exists := buckets[key]
-		if !exists {
-			b = &metricBucket{}
-			buckets[key] = b
-			bucketKeys = append(bucketKeys, key)
+		if end > 0 {
+			rawSince = time.Unix(end, 0).UTC()
 		}
-		b.latencySum += latency
-		b.packetLossSum += packetLoss
-		b.successCount += successInt
-		b.total++
 	}
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate metric rows: %w", err)
+	if err := s.addRawBuckets(ctx, buckets, deviceID, vantage, bucketSec, rawSince); err != nil {
+		return nil, err
 	}
 
-	// Convert buckets to data points (already ordered by checkedAt ASC).
+	// Convert buckets to data points ordered by time.
+	bucketKeys := slices.Sorted(maps.Keys(buckets))
 	points := make([]MetricDataPoint, 0, len(bucketKeys))
 	for _, key := range bucketKeys {
 		b := buckets[key]
@@ -452,10 +521,57 @@ func (s *PulseStore) QueryMetrics(ctx context.Context, deviceID, metric, timeRan
 		DeviceID: deviceID,
 		Metric:   metric,
 		Range:    timeRange,
+		Vantage:  vantage,
 		Points:   points,
 	}, nil
 }
 
+// addRawBuckets aggregates the raw results of a device since the given time
+// into buckets.
+func (s *PulseStore) addRawBuckets(ctx context.Context, buckets map[int64]*metricBucket, deviceID, vantage string, bucketSec int64, since time.Time) error {
+	query := `
+		SELECT latency_ms, packet_loss, success, checked_at
+		FROM pulse_check_results
+		WHERE device_id = ? AND checked_at >= ?`
+	args := []any{deviceID, since}
+	switch vantage {
+	case "":
+	case vantageServer:
+		query += ` AND agent_id = ''`
+	default:
+		query += ` AND agent_id = ?`
+		args = append(args, vantage)
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("query metrics: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var latency, packetLoss float64
+		var successInt int
+		var checkedAt time.Time
+		if err := rows.Scan(&latency, &packetLoss, &successInt, &checkedAt); err != nil {
+			return fmt.Errorf("scan metric row: %w", err)
+		}
+		key := (checkedAt.Unix() / bucketSec) * bucketSec
+		b, exists := buckets[key]
+		if !exists {
+			b = &metricBucket{}
+			buckets[key] = b
+		}
+		b.latencySum += latency
+		b.packetLossSum += packetLoss
+		b.successCount += successInt
+		b.total++
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate metric rows: %w", err)
+	}
+	return nil
+}
+
 // -- Alerts --
 
 // InsertAlert inserts a new monitoring alert.
@@ -471,15 +587,18 @@ func (s *PulseStore) InsertAlert(ctx context.Context, a *Alert) error {
 	_, err := s.db.ExecContext(ctx, `
 		INSERT INTO pulse_alerts (
 			id, check_id, device_id, severity, message, triggered_at, resolved_at,
-			consecutive_failures, suppressed, suppressed_by
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			consecutive_failures, suppressed, suppressed_by, maint_window_id
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		a.ID, a.CheckID, a.DeviceID, a.Severity, a.Message,
 		a.TriggeredAt, resolvedAt, a.ConsecutiveFailures,
-		suppressed, a.SuppressedBy,
+		suppressed, a.SuppressedBy, a.MaintWindowID,
 	)
 	if err != nil {
 		return fmt.Errorf("insert alert: %w", err)
 	}
+	if a.ResolvedAt == nil && a.MaintWindowID == "" {
+		return s.attachIncident(ctx, a)
+	}
 	return nil
 }
 
@@ -492,7 +611,7 @@ func (s *PulseStore) ResolveAlert(ctx context.Context, id string, resolvedAt tim
 	if err != nil {
 		return fmt.Errorf("resolve alert: %w", err)
 	}
-	return nil
+	return s.closeIncidentIfResolved(ctx, id, resolvedAt)
 }
 
 // GetActiveAlert returns the active (unresolved) alert for a check. Returns nil, nil if none.
@@ -502,13 +621,14 @@ func (s *PulseStore) GetActiveAlert(ctx context.Context, checkID string) (*Alert
 	var suppressedInt int
 	err := s.db.QueryRowContext(ctx, `
 		SELECT id, check_id, device_id, severity, message, triggered_at, resolved_at,
-			acknowledged_at, consecutive_failures, suppressed, suppressed_by
+			acknowledged_at, consecutive_failures, suppressed, suppressed_by, maint_window_id,
+			incident_id
 		FROM pulse_alerts WHERE check_id = ? AND resolved_at IS NULL`,
 		checkID,
 	).Scan(
 		&a.ID, &a.CheckID, &a.DeviceID, &a.Severity, &a.Message,
 		&a.TriggeredAt, &resolvedAt, &acknowledgedAt, &a.ConsecutiveFailures,
-		&suppressedInt, &a.SuppressedBy,
+		&suppressedInt, &a.SuppressedBy, &a.MaintWindowID, &a.IncidentID,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -535,7 +655,7 @@ func (s *PulseStore) ListActiveAlerts(ctx context.Context, deviceID string) ([]A
 	if deviceID == "" {
 		rows, err = s.db.QueryContext(ctx, `
 			SELECT a.id, a.check_id, a.device_id, a.severity, a.message, a.triggered_at, a.resolved_at,
-				a.acknowledged_at, a.consecutive_failures, a.suppressed, a.suppressed_by,
+				a.acknowledged_at, a.consecutive_failures, a.suppressed, a.suppressed_by, a.maint_window_id, a.incident_id,
 				COALESCE(NULLIF(d.hostname, ''), json_extract(d.ip_addresses, '$[0]'), a.device_id) AS device_name
 			FROM pulse_alerts a
 			LEFT JOIN recon_devices d ON d.id = a.device_id
@@ -544,7 +664,7 @@ func (s *PulseStore) ListActiveAlerts(ctx context.Context, deviceID string) ([]A
 	} else {
 		rows, err = s.db.QueryContext(ctx, `
 			SELECT a.id, a.check_id, a.device_id, a.severity, a.message, a.triggered_at, a.resolved_at,
-				a.acknowledged_at, a.consecutive_failures, a.suppressed, a.suppressed_by,
+				a.acknowledged_at, a.consecutive_failures, a.suppressed, a.suppressed_by, a.maint_window_id, a.incident_id,
 				COALESCE(NULLIF(d.hostname, ''), json_extract(d.ip_addresses, '$[0]'), a.device_id) AS device_name
 			FROM pulse_alerts a
 			LEFT JOIN recon_devices d ON d.id = a.device_id
@@ -567,13 +687,14 @@ func (s *PulseStore) GetAlert(ctx context.Context, id string) (*Alert, error) {
 	var suppressedInt int
 	err := s.db.QueryRowContext(ctx, `
 		SELECT id, check_id, device_id, severity, message, triggered_at, resolved_at,
-			acknowledged_at, consecutive_failures, suppressed, suppressed_by
+			acknowledged_at, consecutive_failures, suppressed, suppressed_by, maint_window_id,
+			incident_id
 		FROM pulse_alerts WHERE id = ?`,
 		id,
 	).Scan(
 		&a.ID, &a.CheckID, &a.DeviceID, &a.Severity, &a.Message,
 		&a.TriggeredAt, &resolvedAt, &acknowledgedAt, &a.ConsecutiveFailures,
-		&suppressedInt, &a.SuppressedBy,
+		&suppressedInt, &a.SuppressedBy, &a.MaintWindowID, &a.IncidentID,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -594,11 +715,47 @@ func (s *PulseStore) GetAlert(ctx context.Context, id string) (*Alert, error) {
 // ListAlerts returns alerts matching the given filters.
 // Device names are resolved via LEFT JOIN with recon_devices.
 func (s *PulseStore) ListAlerts(ctx context.Context, filters AlertFilters) ([]Alert, error) {
+	where, args := alertFilterClause(filters)
 	query := `SELECT a.id, a.check_id, a.device_id, a.severity, a.message, a.triggered_at, a.resolved_at,
-		a.acknowledged_at, a.consecutive_failures, a.suppressed, a.suppressed_by,
+		a.acknowledged_at, a.consecutive_failures, a.suppressed, a.suppressed_by, a.maint_window_id, a.incident_id,
 		COALESCE(NULLIF(d.hostname, ''), json_extract(d.ip_addresses, '$[0]'), a.device_id) AS device_name
 		FROM pulse_alerts a
-		LEFT JOIN recon_devices d ON d.id = a.device_id`
+		LEFT JOIN recon_devices d ON d.id = a.device_id` + where
+
+	query += " ORDER BY a.triggered_at DESC"
+
+	limit := filters.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	query += fmt.Sprintf(" LIMIT %d", limit)
+	if filters.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d", filters.Offset)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list alerts: %w", err)
+	}
+	defer rows.Close()
+
+	return scanAlertRows(rows)
+}
+
+// CountAlerts returns the number of alerts matching the given filters,
+// ignoring Limit and Offset.
+func (s *PulseStore) CountAlerts(ctx context.Context, filters AlertFilters) (int, error) {
+	where, args := alertFilterClause(filters)
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM pulse_alerts a`+where, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("count alerts: %w", err)
+	}
+	return total, nil
+}
+
+// alertFilterClause builds the WHERE clause, with its leading space, and
+// arguments for filters over pulse_alerts aliased as a.
+func alertFilterClause(filters AlertFilters) (string, []any) {
 	var conditions []string
 	var args []any
 
@@ -620,29 +777,36 @@ func (s *PulseStore) ListAlerts(ctx context.Context, filters AlertFilters) ([]Al
 			conditions = append(conditions, "a.suppressed = 0")
 		}
 	}
-
-	if len(conditions) > 0 {
-		query += " WHERE " + conditions[0]
-		for i := 1; i < len(conditions); i++ {
-			query += " AND " + conditions[i]
-		}
+	if !filters.Since.IsZero() {
+		conditions = append(conditions, "a.triggered_at >= ?")
+		args = append(args, filters.Since.UTC())
+	}
+	if !filters.Until.IsZero() {
+		conditions = append(conditions, "a.triggered_at <= ?")
+		args = append(args, filters.Until.UTC())
 	}
 
-	query += " ORDER BY a.triggered_at DESC"
-
-	limit := filters.Limit
-	if limit <= 0 {
-		limit = 50
+	if len(conditions) == 0 {
+		return "", args
 	}
-	query += fmt.Sprintf(" LIMIT %d", limit)
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+// ClearAlertSuppression unsuppresses an alert, e.g. once the maintenance
+// window that suppressed it has ended.
+func (s *PulseStore) ClearAlertSuppression(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE pulse_alerts SET suppressed = 0, suppressed_by = '', maint_window_id = '' WHERE id = ?`,
+		id,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("list alerts: %w", err)
+		return fmt.Errorf("clear alert suppression: %w", err)
 	}
-	defer rows.Close()
-
-	return scanAlertRows(rows)
+	a, err := s.GetAlert(ctx, id)
+	if err != nil || a == nil || a.IncidentID != "" || a.ResolvedAt != nil {
+		return err
+	}
+	return s.attachIncident(ctx, a)
 }
 
 // AcknowledgeAlert sets the acknowledged_at timestamp on an alert.
@@ -671,7 +835,7 @@ func (s *PulseStore) DeleteOldAlerts(ctx context.Context, before time.Time) (int
 }
 
 // scanAlertRows scans alert rows into a slice, handling nullable columns.
-// Expects 12 columns: the standard 11 alert columns plus device_name.
+// Expects 14 columns: the standard 13 alert columns plus device_name.
 func scanAlertRows(rows *sql.Rows) ([]Alert, error) {
 	var alerts []Alert
 	for rows.Next() {
@@ -681,7 +845,7 @@ func scanAlertRows(rows *sql.Rows) ([]Alert, error) {
 		if err := rows.Scan(
 			&a.ID, &a.CheckID, &a.DeviceID, &a.Severity, &a.Message,
 			&a.TriggeredAt, &resolvedAt, &acknowledgedAt, &a.ConsecutiveFailures,
-			&suppressedInt, &a.SuppressedBy, &a.DeviceName,
+			&suppressedInt, &a.SuppressedBy, &a.MaintWindowID, &a.IncidentID, &a.DeviceName,
 		); err != nil {
 			return nil, fmt.Errorf("scan alert row: %w", err)
 		}
@@ -793,11 +957,14 @@ func (s *PulseStore) IsSuppressed(ctx context.Context, checkID string) (suppress
 
 // GetParentActiveAlerts returns active alerts for the parent device of the given device,
 // triggered within the specified time window. It joins against recon_devices to find the
-// parent_device_id. Returns the matching alerts and the parent device ID.
+// parent_device_id. Returns the matching alerts and the parent device ID. Devices in the
+// recon trash have no parent, and a parent in the trash suppresses nothing.
 func (s *PulseStore) GetParentActiveAlerts(ctx context.Context, deviceID string, window time.Duration) (alerts []Alert, parentDeviceID string, err error) {
 	// First, resolve the parent device ID.
-	err = s.db.QueryRowContext(ctx,
-		`SELECT parent_device_id FROM recon_devices WHERE id = ? AND parent_device_id != ''`,
+	err = s.db.QueryRowContext(ctx, `
+		SELECT d.parent_device_id FROM recon_devices d
+		JOIN recon_devices p ON p.id = d.parent_device_id AND p.deleted_at IS NULL
+		WHERE d.id = ? AND d.deleted_at IS NULL`,
 		deviceID,
 	).Scan(&parentDeviceID)
 	if err != nil {
@@ -814,7 +981,7 @@ func (s *PulseStore) GetParentActiveAlerts(ctx context.Context, deviceID string,
 	since := time.Now().UTC().Add(-window)
 	rows, err := s.db.QueryContext(ctx, `
 		SELECT a.id, a.check_id, a.device_id, a.severity, a.message, a.triggered_at, a.resolved_at,
-			a.acknowledged_at, a.consecutive_failures, a.suppressed, a.suppressed_by,
+			a.acknowledged_at, a.consecutive_failures, a.suppressed, a.suppressed_by, a.maint_window_id, a.incident_id,
 			COALESCE(NULLIF(d.hostname, ''), json_extract(d.ip_addresses, '$[0]'), a.device_id) AS device_name
 		FROM pulse_alerts a
 		LEFT JOIN recon_devices d ON d.id = a.device_id
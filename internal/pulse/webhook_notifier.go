@@ -11,6 +11,8 @@ import (
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/HerbHall/subnetree/internal/webhook"
 )
 
 // Compile-time interface guard.
@@ -24,9 +26,12 @@ type webhookPayload struct {
 }
 
 // WebhookNotifier delivers notifications via HTTP POST to a configured URL.
+// When a queue is set, deliveries go through it and are retried on failure;
+// otherwise a single request is made.
 type WebhookNotifier struct {
 	client *http.Client
 	cfg    WebhookConfig
+	queue  WebhookQueue
 }
 
 // NewWebhookNotifier creates a new webhook notifier with the given config.
@@ -50,6 +55,21 @@ func (w *WebhookNotifier) Notify(ctx context.Context, alert *Alert, eventType st
 		return fmt.Errorf("marshal webhook payload: %w", err)
 	}
 
+	headers := w.headers(body)
+
+	if w.queue != nil {
+		if _, err := w.queue.Enqueue(ctx, webhook.DeliveryRequest{
+			Source:  "pulse",
+			Topic:   "alert." + eventType,
+			URL:     w.cfg.URL,
+			Body:    body,
+			Headers: headers,
+		}); err != nil {
+			return fmt.Errorf("queue webhook delivery: %w", err)
+		}
+		return nil
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.cfg.URL, bytes.NewReader(body))
 	if err != nil {
 		return fmt.Errorf("create webhook request: %w", err)
@@ -57,17 +77,7 @@ func (w *WebhookNotifier) Notify(ctx context.Context, alert *Alert, eventType st
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "SubNetree-Webhook/0.1")
-
-	// Add HMAC-SHA256 signature if secret is configured.
-	if w.cfg.Secret != "" {
-		mac := hmac.New(sha256.New, []byte(w.cfg.Secret))
-		mac.Write(body)
-		sig := hex.EncodeToString(mac.Sum(nil))
-		req.Header.Set("X-Signature", sig)
-	}
-
-	// Add custom headers.
-	for k, v := range w.cfg.Headers {
+	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
 
@@ -85,6 +95,26 @@ func (w *WebhookNotifier) Notify(ctx context.Context, alert *Alert, eventType st
 	return nil
 }
 
+// headers returns the signature and custom headers for a request body.
+func (w *WebhookNotifier) headers(body []byte) map[string]string {
+	headers := make(map[string]string, len(w.cfg.Headers)+2)
+
+	// Add HMAC-SHA256 signature if secret is configured. X-Signature (bare
+	// hex) is kept for receivers written against earlier releases.
+	if w.cfg.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.cfg.Secret))
+		mac.Write(body)
+		headers["X-Signature"] = hex.EncodeToString(mac.Sum(nil))
+		headers[webhook.SignatureHeader] = webhook.Sign(w.cfg.Secret, body)
+	}
+
+	// Add custom headers.
+	for k, v := range w.cfg.Headers {
+		headers[k] = v
+	}
+	return headers
+}
+
 // Type returns the notifier type identifier.
 func (w *WebhookNotifier) Type() string {
 	return "webhook"
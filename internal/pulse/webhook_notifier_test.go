@@ -11,6 +11,8 @@ import (
 	"net/http/httptest"
 	"testing"
 	"time"
+
+	"github.com/HerbHall/subnetree/internal/webhook"
 )
 
 func TestWebhookNotifier_Notify_Success(t *testing.T) {
@@ -53,13 +55,48 @@ func TestWebhookNotifier_Notify_Success(t *testing.T) {
 	}
 }
 
+type fakeWebhookQueue struct {
+	reqs []webhook.DeliveryRequest
+}
+
+func (f *fakeWebhookQueue) Enqueue(_ context.Context, req webhook.DeliveryRequest) (*webhook.Delivery, error) {
+	f.reqs = append(f.reqs, req)
+	return &webhook.Delivery{ID: "d1"}, nil
+}
+
+func TestWebhookNotifier_Notify_UsesQueue(t *testing.T) {
+	queue := &fakeWebhookQueue{}
+	notifier, err := buildNotifier(NotificationChannel{
+		Type:   "webhook",
+		Config: `{"url":"http://hooks.example/alert","secret":"s3cret","headers":{"X-Team":"ops"}}`,
+	}, queue)
+	if err != nil {
+		t.Fatalf("buildNotifier: %v", err)
+	}
+
+	if err := notifier.Notify(context.Background(), &Alert{ID: "a1"}, "triggered"); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if len(queue.reqs) != 1 {
+		t.Fatalf("queued %d deliveries, want 1", len(queue.reqs))
+	}
+	req := queue.reqs[0]
+	if req.URL != "http://hooks.example/alert" || req.Source != "pulse" {
+		t.Errorf("request = %+v", req)
+	}
+	if req.Headers["X-Team"] != "ops" || !webhook.Verify("s3cret", req.Body, req.Headers[webhook.SignatureHeader]) {
+		t.Errorf("headers = %v", req.Headers)
+	}
+}
+
 func TestWebhookNotifier_Notify_HMACSignature(t *testing.T) {
 	secret := "test-secret-key"
-	var receivedSig string
+	var receivedSig, receivedSubNetreeSig string
 	var receivedBody []byte
 
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		receivedSig = r.Header.Get("X-Signature")
+		receivedSubNetreeSig = r.Header.Get(webhook.SignatureHeader)
 		receivedBody, _ = io.ReadAll(r.Body)
 		w.WriteHeader(http.StatusOK)
 	}))
@@ -93,6 +130,9 @@ func TestWebhookNotifier_Notify_HMACSignature(t *testing.T) {
 	if receivedSig != expectedSig {
 		t.Errorf("signature mismatch: got %q, want %q", receivedSig, expectedSig)
 	}
+	if !webhook.Verify(secret, receivedBody, receivedSubNetreeSig) {
+		t.Errorf("%s = %q does not verify", webhook.SignatureHeader, receivedSubNetreeSig)
+	}
 }
 
 func TestWebhookNotifier_Notify_CustomHeaders(t *testing.T) {
@@ -0,0 +1,308 @@
+package recon
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"go.uber.org/zap"
+)
+
+// InterfaceCounters is one reading of an interface's IF-MIB octet counters.
+// HC is true when the values came from the 64-bit ifHC* columns and false
+// when the device only exposes the 32-bit ifInOctets/ifOutOctets.
+type InterfaceCounters struct {
+	Index     int
+	Name      string
+	InOctets  uint64
+	OutOctets uint64
+	HC        bool
+}
+
+// GetInterfaceCounters walks the IF-MIB octet counters of a target. The
+// 64-bit ifHCInOctets/ifHCOutOctets columns are preferred; devices that do
+// not implement ifXTable (or return nothing for it) fall back to the 32-bit
+// ifTable counters.
+func (c *SNMPCollector) GetInterfaceCounters(ctx context.Context, target string, cred CredentialAccessor, credID string) ([]InterfaceCounters, error) {
+	credential, err := cred.GetCredential(ctx, credID)
+	if err != nil {
+		return nil, fmt.Errorf("get credential: %w", err)
+	}
+
+	g, err := c.newGoSNMP(target, credential)
+	if err != nil {
+		return nil, fmt.Errorf("configure SNMP: %w", err)
+	}
+
+	if err := g.Connect(); err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", target, err)
+	}
+	defer func() { _ = g.Conn.Close() }()
+
+	hc := true
+	in, err := g.BulkWalkAll(OIDIfHCInOctets)
+	if err != nil || len(in) == 0 {
+		hc = false
+		if in, err = g.BulkWalkAll(OIDIfInOctets); err != nil {
+			return nil, fmt.Errorf("SNMP walk ifInOctets: %w", err)
+		}
+	}
+	outOID := OIDIfHCOutOctets
+	if !hc {
+		outOID = OIDIfOutOctets
+	}
+	out, err := g.BulkWalkAll(outOID)
+	if err != nil {
+		return nil, fmt.Errorf("SNMP walk ifOutOctets: %w", err)
+	}
+
+	// ifName is optional; interfaces without one are labelled by index.
+	names, err := g.BulkWalkAll(OIDIfName)
+	if err != nil {
+		c.logger.Debug("SNMP ifName walk failed", zap.String("target", target), zap.Error(err))
+	}
+
+	return mergeInterfaceCounters(in, out, names, hc), nil
+}
+
+// mergeInterfaceCounters joins the in-octet, out-octet, and ifName walks by
+// ifIndex. Only interfaces present in both counter walks are returned,
+// ordered by index.
+func mergeInterfaceCounters(in, out, names []gosnmp.SnmpPDU, hc bool) []InterfaceCounters {
+	outByIndex := make(map[int]uint64, len(out))
+	for _, pdu := range out {
+		if idx := extractOIDIndex(pdu.Name); idx >= 0 {
+			outByIndex[idx] = parsePDUUint64(pdu)
+		}
+	}
+	nameByIndex := make(map[int]string, len(names))
+	for _, pdu := range names {
+		if idx := extractOIDIndex(pdu.Name); idx >= 0 {
+			nameByIndex[idx] = parsePDUString(pdu)
+		}
+	}
+
+	counters := make([]InterfaceCounters, 0, len(in))
+	for _, pdu := range in {
+		idx := extractOIDIndex(pdu.Name)
+		if idx < 0 {
+			continue
+		}
+		outOctets, ok := outByIndex[idx]
+		if !ok {
+			continue
+		}
+		name := nameByIndex[idx]
+		if name == "" {
+			name = "if" + strconv.Itoa(idx)
+		}
+		counters = append(counters, InterfaceCounters{
+			Index:     idx,
+			Name:      name,
+			InOctets:  parsePDUUint64(pdu),
+			OutOctets: outOctets,
+			HC:        hc,
+		})
+	}
+	return counters
+}
+
+// counterDelta returns the increase of a counter between two readings,
+// allowing for a single wrap at the counter's width. A decrease that would
+// imply more than half the counter range passed is treated as a reset (a
+// reboot or counter clear) and reported as not ok, since a real wrap of a
+// polled counter leaves a small delta.
+func counterDelta(prev, cur uint64, hc bool) (uint64, bool) {
+	if cur >= prev {
+		return cur - prev, true
+	}
+	limit := uint64(math.MaxUint64)
+	if !hc {
+		limit = math.MaxUint32
+		if prev > limit {
+			return 0, false
+		}
+	}
+	delta := limit - prev + cur + 1
+	if delta > limit/2 {
+		return 0, false
+	}
+	return delta, true
+}
+
+// interfaceReading is the previous counter reading of an interface, kept
+// to compute the next delta.
+type interfaceReading struct {
+	counters InterfaceCounters
+	at       time.Time
+}
+
+// BandwidthPoller periodically reads the interface octet counters of every
+// device that has an SNMP credential in the vault and stores the per-second
+// rate between consecutive readings as a bandwidth sample.
+type BandwidthPoller struct {
+	store     *ReconStore
+	snmp      *SNMPCollector
+	logger    *zap.Logger
+	interval  time.Duration
+	retention time.Duration
+
+	mu         sync.RWMutex
+	credLookup CredentialLookup
+	creds      CredentialAccessor
+
+	// last is keyed by device ID and ifIndex. Only the Run goroutine
+	// touches it.
+	last map[string]map[int]interfaceReading
+}
+
+// NewBandwidthPoller creates a bandwidth poller using cfg's interval and
+// retention.
+func NewBandwidthPoller(store *ReconStore, snmp *SNMPCollector, logger *zap.Logger, cfg BandwidthConfig) *BandwidthPoller {
+	return &BandwidthPoller{
+		store:     store,
+		snmp:      snmp,
+		logger:    logger,
+		interval:  cfg.Interval,
+		retention: cfg.Retention,
+		last:      make(map[string]map[int]interfaceReading),
+	}
+}
+
+// SetCredentials configures how the poller finds and reads a device's
+// SNMP credential.
+func (p *BandwidthPoller) SetCredentials(lookup CredentialLookup, creds CredentialAccessor) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.credLookup = lookup
+	p.creds = creds
+}
+
+// Run polls devices every interval and prunes old samples until ctx is
+// cancelled.
+func (p *BandwidthPoller) Run(ctx context.Context) {
+	p.logger.Info("SNMP bandwidth poller started", zap.Duration("interval", p.interval))
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	lastPrune := time.Time{}
+	for {
+		p.poll(ctx)
+		if p.retention > 0 && time.Since(lastPrune) >= time.Hour {
+			lastPrune = time.Now()
+			n, err := p.store.PruneBandwidthSamples(ctx, lastPrune.Add(-p.retention))
+			if err != nil && ctx.Err() == nil {
+				p.logger.Warn("failed to prune bandwidth samples", zap.Error(err))
+			} else if n > 0 {
+				p.logger.Debug("pruned bandwidth samples", zap.Int64("deleted", n))
+			}
+		}
+		select {
+		case <-ctx.Done():
+			p.logger.Info("SNMP bandwidth poller stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll reads counters from every device with an SNMP credential.
+func (p *BandwidthPoller) poll(ctx context.Context) {
+	p.mu.RLock()
+	lookup, creds := p.credLookup, p.creds
+	p.mu.RUnlock()
+	if lookup == nil || creds == nil {
+		return
+	}
+
+	devices, err := p.store.ListAllDevices(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			p.logger.Warn("failed to list devices for bandwidth polling", zap.Error(err))
+		}
+		return
+	}
+
+	seen := make(map[string]bool, len(devices))
+	for i := range devices {
+		if ctx.Err() != nil {
+			return
+		}
+		d := &devices[i]
+		if len(d.IPAddresses) == 0 {
+			continue
+		}
+		credID, err := lookup.FindSNMPCredentialForDevice(ctx, d.ID)
+		if err != nil || credID == "" {
+			continue
+		}
+		seen[d.ID] = true
+
+		counters, err := p.snmp.GetInterfaceCounters(ctx, d.IPAddresses[0], creds, credID)
+		if err != nil {
+			p.logger.Debug("bandwidth poll failed", zap.String("device_id", d.ID), zap.Error(err))
+			continue
+		}
+		p.record(ctx, d.ID, counters, time.Now().UTC())
+	}
+
+	// Forget devices that were deleted or lost their credential.
+	for id := range p.last {
+		if !seen[id] {
+			delete(p.last, id)
+		}
+	}
+}
+
+// record converts a device's counter readings into bandwidth samples
+// against its previous readings and stores them. The first reading of an
+// interface, and any reading after a counter reset, only primes the delta.
+func (p *BandwidthPoller) record(ctx context.Context, deviceID string, counters []InterfaceCounters, at time.Time) {
+	prev := p.last[deviceID]
+	next := make(map[int]interfaceReading, len(counters))
+	samples := make([]BandwidthSample, 0, len(counters))
+	for i := range counters {
+		c := counters[i]
+		next[c.Index] = interfaceReading{counters: c, at: at}
+
+		last, ok := prev[c.Index]
+		if !ok || last.counters.HC != c.HC {
+			continue
+		}
+		if s, ok := bandwidthSample(&last, c, at); ok {
+			samples = append(samples, s)
+		}
+	}
+	p.last[deviceID] = next
+
+	if err := p.store.InsertBandwidthSamples(ctx, deviceID, at, samples); err != nil && ctx.Err() == nil {
+		p.logger.Warn("failed to store bandwidth samples", zap.String("device_id", deviceID), zap.Error(err))
+	}
+}
+
+// bandwidthSample computes the in and out rates in bits per second between
+// a previous reading and a new one.
+func bandwidthSample(prev *interfaceReading, cur InterfaceCounters, at time.Time) (BandwidthSample, bool) {
+	elapsed := at.Sub(prev.at).Seconds()
+	if elapsed <= 0 {
+		return BandwidthSample{}, false
+	}
+	in, ok := counterDelta(prev.counters.InOctets, cur.InOctets, cur.HC)
+	if !ok {
+		return BandwidthSample{}, false
+	}
+	out, ok := counterDelta(prev.counters.OutOctets, cur.OutOctets, cur.HC)
+	if !ok {
+		return BandwidthSample{}, false
+	}
+	return BandwidthSample{
+		Interface: cur.Name,
+		IfIndex:   cur.Index,
+		InBps:     float64(in) * 8 / elapsed,
+		OutBps:    float64(out) * 8 / elapsed,
+	}, true
+}
@@ -0,0 +1,60 @@
+package recon
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// handleDeviceBandwidth returns a device's interface throughput over time.
+//
+//	@Summary		Device bandwidth
+//	@Description	Returns inbound and outbound throughput in bits per second, computed from SNMP
+//	@Description	IF-MIB octet counters. Without an interface filter the series is the device total.
+//	@Tags			recon
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id			path		string	true	"Device ID"
+//	@Param			range		query		string	false	"Time range" Enums(1h, 6h, 24h, 7d, 30d) default(24h)
+//	@Param			interface	query		string	false	"Only this interface (ifName)"
+//	@Success		200			{object}	BandwidthSeries
+//	@Failure		400			{object}	models.APIProblem
+//	@Failure		404			{object}	models.APIProblem
+//	@Failure		500			{object}	models.APIProblem
+//	@Router			/recon/devices/{id}/bandwidth [get]
+func (m *Module) handleDeviceBandwidth(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "device ID is required")
+		return
+	}
+
+	timeRange := r.URL.Query().Get("range")
+	if timeRange == "" {
+		timeRange = "24h"
+	}
+	if _, ok := bandwidthRanges[timeRange]; !ok {
+		writeError(w, http.StatusBadRequest, "range must be 1h, 6h, 24h, 7d, or 30d")
+		return
+	}
+
+	if _, err := m.store.GetDevice(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "device not found")
+			return
+		}
+		m.logger.Error("failed to get device", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to query bandwidth")
+		return
+	}
+
+	series, err := m.store.QueryBandwidth(r.Context(), id, timeRange, r.URL.Query().Get("interface"))
+	if err != nil {
+		m.logger.Error("failed to query bandwidth", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to query bandwidth")
+		return
+	}
+	writeJSON(w, http.StatusOK, series)
+}
@@ -0,0 +1,178 @@
+package recon
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// bandwidthRanges maps time range strings to their durations. They match
+// the ranges of pulse device metrics.
+var bandwidthRanges = map[string]time.Duration{
+	"1h":  1 * time.Hour,
+	"6h":  6 * time.Hour,
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+// BandwidthSample is the average throughput of one interface between two
+// counter readings, in bits per second.
+type BandwidthSample struct {
+	Interface string  `json:"interface"`
+	IfIndex   int     `json:"if_index"`
+	InBps     float64 `json:"in_bps"`
+	OutBps    float64 `json:"out_bps"`
+}
+
+// BandwidthPoint is a throughput value at a point in time.
+type BandwidthPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// BandwidthSeries is a downsampled throughput time series of a device. In
+// and Out have the point shape of a pulse device metric series. Interfaces
+// lists the interfaces that reported samples in the range.
+type BandwidthSeries struct {
+	DeviceID   string           `json:"device_id"`
+	Range      string           `json:"range"`
+	Interface  string           `json:"interface,omitempty"`
+	Interfaces []string         `json:"interfaces"`
+	In         []BandwidthPoint `json:"in_bps"`
+	Out        []BandwidthPoint `json:"out_bps"`
+}
+
+// InsertBandwidthSamples stores samples recorded for a device at the given time.
+func (s *ReconStore) InsertBandwidthSamples(ctx context.Context, deviceID string, at time.Time, samples []BandwidthSample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin insert bandwidth samples: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO recon_interface_bandwidth (device_id, if_index, interface, in_bps, out_bps, recorded_at)
+		VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("prepare insert bandwidth sample: %w", err)
+	}
+	defer stmt.Close()
+
+	at = at.UTC()
+	for i := range samples {
+		smp := &samples[i]
+		if _, err := stmt.ExecContext(ctx, deviceID, smp.IfIndex, smp.Interface, smp.InBps, smp.OutBps, at); err != nil {
+			return fmt.Errorf("insert bandwidth sample: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// QueryBandwidth returns a device's throughput over a time range. Samples
+// are averaged per interface into buckets sized like pulse device metrics
+// (1 minute up to a day, 5 minutes up to a week, then 1 hour) and the
+// interface averages summed, so without a filter the series is the total
+// throughput of the device. A non-empty iface limits it to one interface.
+// Bucketing is performed in Go to avoid SQLite date-format parsing issues.
+func (s *ReconStore) QueryBandwidth(ctx context.Context, deviceID, timeRange, iface string) (*BandwidthSeries, error) {
+	duration, ok := bandwidthRanges[timeRange]
+	if !ok {
+		return nil, fmt.Errorf("unknown range %q: must be 1h, 6h, 24h, 7d, or 30d", timeRange)
+	}
+	since := time.Now().UTC().Add(-duration)
+
+	var bucketSec int64
+	switch {
+	case duration <= 24*time.Hour:
+		bucketSec = 60
+	case duration <= 7*24*time.Hour:
+		bucketSec = 300
+	default:
+		bucketSec = 3600
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT interface, in_bps, out_bps, recorded_at FROM recon_interface_bandwidth
+		WHERE device_id = ? AND recorded_at >= ?`,
+		deviceID, since,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query bandwidth: %w", err)
+	}
+	defer rows.Close()
+
+	type bucketKey struct {
+		at    int64
+		iface string
+	}
+	type bucket struct {
+		in, out float64
+		count   int
+	}
+	buckets := make(map[bucketKey]*bucket)
+	interfaces := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		var in, out float64
+		var recordedAt time.Time
+		if err := rows.Scan(&name, &in, &out, &recordedAt); err != nil {
+			return nil, fmt.Errorf("scan bandwidth row: %w", err)
+		}
+		interfaces[name] = true
+		if iface != "" && name != iface {
+			continue
+		}
+		key := bucketKey{at: (recordedAt.Unix() / bucketSec) * bucketSec, iface: name}
+		b, exists := buckets[key]
+		if !exists {
+			b = &bucket{}
+			buckets[key] = b
+		}
+		b.in += in
+		b.out += out
+		b.count++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate bandwidth rows: %w", err)
+	}
+
+	inTotals := make(map[int64]float64)
+	outTotals := make(map[int64]float64)
+	for key, b := range buckets {
+		inTotals[key.at] += b.in / float64(b.count)
+		outTotals[key.at] += b.out / float64(b.count)
+	}
+
+	keys := slices.Sorted(maps.Keys(inTotals))
+	series := &BandwidthSeries{
+		DeviceID:   deviceID,
+		Range:      timeRange,
+		Interface:  iface,
+		Interfaces: slices.Sorted(maps.Keys(interfaces)),
+		In:         make([]BandwidthPoint, 0, len(keys)),
+		Out:        make([]BandwidthPoint, 0, len(keys)),
+	}
+	for _, key := range keys {
+		ts := time.Unix(key, 0).UTC()
+		series.In = append(series.In, BandwidthPoint{Timestamp: ts, Value: inTotals[key]})
+		series.Out = append(series.Out, BandwidthPoint{Timestamp: ts, Value: outTotals[key]})
+	}
+	return series, nil
+}
+
+// PruneBandwidthSamples deletes samples recorded before cutoff and returns
+// the number deleted.
+func (s *ReconStore) PruneBandwidthSamples(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM recon_interface_bandwidth WHERE recorded_at < ?`, cutoff.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("prune bandwidth samples: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,166 @@
+package recon
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"go.uber.org/zap"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+func TestCounterDelta(t *testing.T) {
+	tests := []struct {
+		name      string
+		prev, cur uint64
+		hc        bool
+		want      uint64
+		wantOK    bool
+	}{
+		{"increase", 1000, 1500, true, 500, true},
+		{"unchanged", 1000, 1000, false, 0, true},
+		{"32-bit wrap", math.MaxUint32 - 99, 400, false, 500, true},
+		{"64-bit wrap", math.MaxUint64 - 99, 400, true, 500, true},
+		{"32-bit reset", 1_000_000_000, 1000, false, 0, false},
+		{"64-bit reset", 1 << 40, 1000, true, 0, false},
+		{"32-bit value above range", 1 << 33, 10, false, 0, false},
+	}
+	for _, tc := range tests {
+		got, ok := counterDelta(tc.prev, tc.cur, tc.hc)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("%s: counterDelta(%d, %d) = %d, %v; want %d, %v", tc.name, tc.prev, tc.cur, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestMergeInterfaceCounters(t *testing.T) {
+	in := []gosnmp.SnmpPDU{
+		{Name: "." + OIDIfHCInOctets + ".1", Type: gosnmp.Counter64, Value: uint64(100)},
+		{Name: "." + OIDIfHCInOctets + ".2", Type: gosnmp.Counter64, Value: uint64(200)},
+		{Name: "." + OIDIfHCInOctets + ".3", Type: gosnmp.Counter64, Value: uint64(300)}, // no out counter
+	}
+	out := []gosnmp.SnmpPDU{
+		{Name: "." + OIDIfHCOutOctets + ".1", Type: gosnmp.Counter64, Value: uint64(10)},
+		{Name: "." + OIDIfHCOutOctets + ".2", Type: gosnmp.Counter64, Value: uint64(20)},
+	}
+	names := []gosnmp.SnmpPDU{
+		{Name: "." + OIDIfName + ".1", Type: gosnmp.OctetString, Value: []byte("Gi0/1")},
+	}
+
+	got := mergeInterfaceCounters(in, out, names, true)
+	if len(got) != 2 {
+		t.Fatalf("got %d interfaces, want 2: %+v", len(got), got)
+	}
+	if got[0] != (InterfaceCounters{Index: 1, Name: "Gi0/1", InOctets: 100, OutOctets: 10, HC: true}) {
+		t.Errorf("interface 1 = %+v", got[0])
+	}
+	if got[1].Name != "if2" || got[1].InOctets != 200 || got[1].OutOctets != 20 {
+		t.Errorf("interface 2 = %+v", got[1])
+	}
+}
+
+func TestBandwidthPoller_Record(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+	dev := &models.Device{
+		Hostname: "core-switch", IPAddresses: []string{"10.0.0.2"}, MACAddress: "AA:BB:CC:00:50:01",
+		Status: models.DeviceStatusOnline, DiscoveryMethod: models.DiscoveryICMP,
+	}
+	if _, err := s.UpsertDevice(ctx, dev); err != nil {
+		t.Fatalf("UpsertDevice: %v", err)
+	}
+	p := NewBandwidthPoller(s, nil, zap.NewNop(), DefaultConfig().Bandwidth)
+
+	start := time.Now().UTC().Add(-2 * time.Minute)
+	p.record(ctx, dev.ID, []InterfaceCounters{
+		{Index: 1, Name: "Gi0/1", InOctets: math.MaxUint32 - 999, OutOctets: 0},
+		{Index: 2, Name: "Gi0/2", InOctets: 5000, OutOctets: 5000},
+	}, start)
+	// 60s later: Gi0/1 wrapped after 7500 bytes in, Gi0/2 was reset.
+	p.record(ctx, dev.ID, []InterfaceCounters{
+		{Index: 1, Name: "Gi0/1", InOctets: 6500, OutOctets: 750},
+		{Index: 2, Name: "Gi0/2", InOctets: 10, OutOctets: 10},
+	}, start.Add(time.Minute))
+
+	series, err := s.QueryBandwidth(ctx, dev.ID, "1h", "")
+	if err != nil {
+		t.Fatalf("QueryBandwidth: %v", err)
+	}
+	if len(series.In) != 1 || len(series.Out) != 1 {
+		t.Fatalf("series = %+v, want one point", series)
+	}
+	if series.In[0].Value != 1000 || series.Out[0].Value != 100 {
+		t.Errorf("in/out = %v/%v bps, want 1000/100", series.In[0].Value, series.Out[0].Value)
+	}
+	if len(series.Interfaces) != 1 || series.Interfaces[0] != "Gi0/1" {
+		t.Errorf("interfaces = %v, want [Gi0/1]", series.Interfaces)
+	}
+
+	n, err := s.PruneBandwidthSamples(ctx, time.Now())
+	if err != nil || n != 1 {
+		t.Errorf("PruneBandwidthSamples = %d, %v; want 1", n, err)
+	}
+}
+
+func TestQueryBandwidth_SumsInterfaces(t *testing.T) {
+	m := newTestModule(t)
+	ctx := context.Background()
+	dev := addRelationshipDevices(t, m.store, 1)[0]
+	at := time.Now().UTC().Truncate(time.Minute)
+	if err := m.store.InsertBandwidthSamples(ctx, dev.ID, at, []BandwidthSample{
+		{Interface: "eth0", IfIndex: 1, InBps: 100, OutBps: 10},
+		{Interface: "eth1", IfIndex: 2, InBps: 300, OutBps: 30},
+	}); err != nil {
+		t.Fatalf("InsertBandwidthSamples: %v", err)
+	}
+	if err := m.store.InsertBandwidthSamples(ctx, dev.ID, at.Add(10*time.Second), []BandwidthSample{
+		{Interface: "eth0", IfIndex: 1, InBps: 200, OutBps: 20},
+	}); err != nil {
+		t.Fatalf("InsertBandwidthSamples: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /devices/{id}/bandwidth", m.handleDeviceBandwidth)
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+		return rec
+	}
+
+	rec := get("/devices/" + dev.ID + "/bandwidth?range=1h")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var series BandwidthSeries
+	if err := json.NewDecoder(rec.Body).Decode(&series); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	// eth0 averages 150 in the bucket, plus eth1's 300.
+	if len(series.In) != 1 || series.In[0].Value != 450 || series.Out[0].Value != 45 {
+		t.Errorf("series = %+v, want 450/45 bps", series)
+	}
+
+	rec = get("/devices/" + dev.ID + "/bandwidth?range=1h&interface=eth1")
+	series = BandwidthSeries{}
+	if err := json.NewDecoder(rec.Body).Decode(&series); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if series.Interface != "eth1" || len(series.In) != 1 || series.In[0].Value != 300 {
+		t.Errorf("eth1 series = %+v", series)
+	}
+
+	for path, want := range map[string]int{
+		"/devices/" + dev.ID + "/bandwidth?range=2h": http.StatusBadRequest,
+		"/devices/missing/bandwidth":                 http.StatusNotFound,
+	} {
+		if rec := get(path); rec.Code != want {
+			t.Errorf("GET %s status = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
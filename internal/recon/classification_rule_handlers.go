@@ -0,0 +1,168 @@
+package recon
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// handleListClassificationRules returns all classification rules in
+// evaluation order.
+//
+//	@Summary		List classification rules
+//	@Description	Returns all user-defined device classification rules, ordered by priority.
+//	@Tags			recon
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{array}		ClassificationRule
+//	@Failure		500	{object}	models.APIProblem
+//	@Router			/recon/classification-rules [get]
+func (m *Module) handleListClassificationRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := m.store.ListClassificationRules(r.Context())
+	if err != nil {
+		m.logger.Error("failed to list classification rules", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to list classification rules")
+		return
+	}
+	if rules == nil {
+		rules = []ClassificationRule{}
+	}
+	writeJSON(w, http.StatusOK, rules)
+}
+
+// handleCreateClassificationRule creates a classification rule.
+//
+//	@Summary		Create classification rule
+//	@Description	Creates a rule that sets the device type of matching devices on every upsert. Rules are enabled with confidence 90 unless the body says otherwise.
+//	@Tags			recon
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		ClassificationRule	true	"Rule to create"
+//	@Success		201		{object}	ClassificationRule
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/recon/classification-rules [post]
+func (m *Module) handleCreateClassificationRule(w http.ResponseWriter, r *http.Request) {
+	rule := ClassificationRule{Enabled: true, Confidence: DefaultRuleConfidence}
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	rule.ID = ""
+	if err := rule.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := m.store.CreateClassificationRule(r.Context(), &rule); err != nil {
+		m.logger.Error("failed to create classification rule", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to create classification rule")
+		return
+	}
+	writeJSON(w, http.StatusCreated, rule)
+}
+
+// handleGetClassificationRule returns a single classification rule.
+//
+//	@Summary		Get classification rule
+//	@Description	Returns a user-defined device classification rule by ID.
+//	@Tags			recon
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Rule ID"
+//	@Success		200	{object}	ClassificationRule
+//	@Failure		404	{object}	models.APIProblem
+//	@Failure		500	{object}	models.APIProblem
+//	@Router			/recon/classification-rules/{id} [get]
+func (m *Module) handleGetClassificationRule(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	rule, err := m.store.GetClassificationRule(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "classification rule not found")
+			return
+		}
+		m.logger.Error("failed to get classification rule", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to get classification rule")
+		return
+	}
+	writeJSON(w, http.StatusOK, rule)
+}
+
+// handleUpdateClassificationRule updates a classification rule. Fields
+// omitted from the body keep their current values.
+//
+//	@Summary		Update classification rule
+//	@Description	Updates a user-defined device classification rule. Omitted fields are left unchanged.
+//	@Tags			recon
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string				true	"Rule ID"
+//	@Param			request	body		ClassificationRule	true	"Fields to update"
+//	@Success		200		{object}	ClassificationRule
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		404		{object}	models.APIProblem
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/recon/classification-rules/{id} [put]
+func (m *Module) handleUpdateClassificationRule(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	rule, err := m.store.GetClassificationRule(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "classification rule not found")
+			return
+		}
+		m.logger.Error("failed to get classification rule", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to update classification rule")
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(rule); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	rule.ID = id
+	if err := rule.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := m.store.UpdateClassificationRule(r.Context(), rule); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "classification rule not found")
+			return
+		}
+		m.logger.Error("failed to update classification rule", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to update classification rule")
+		return
+	}
+	writeJSON(w, http.StatusOK, rule)
+}
+
+// handleDeleteClassificationRule deletes a classification rule.
+//
+//	@Summary		Delete classification rule
+//	@Description	Deletes a user-defined device classification rule. Devices it already classified keep their type.
+//	@Tags			recon
+//	@Security		BearerAuth
+//	@Param			id	path	string	true	"Rule ID"
+//	@Success		204	"No content"
+//	@Failure		404	{object}	models.APIProblem
+//	@Failure		500	{object}	models.APIProblem
+//	@Router			/recon/classification-rules/{id} [delete]
+func (m *Module) handleDeleteClassificationRule(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := m.store.DeleteClassificationRule(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "classification rule not found")
+			return
+		}
+		m.logger.Error("failed to delete classification rule", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to delete classification rule")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,152 @@
+package recon
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+	"github.com/google/uuid"
+)
+
+const classificationRuleColumns = `id, name, priority, enabled, manufacturer, oui_prefix,
+	open_ports, hostname_regex, os, device_type, confidence, created_at, updated_at`
+
+// CreateClassificationRule inserts a new classification rule.
+func (s *ReconStore) CreateClassificationRule(ctx context.Context, rule *ClassificationRule) error {
+	if rule.ID == "" {
+		rule.ID = uuid.New().String()
+	}
+	now := time.Now().UTC()
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+	portsJSON, _ := json.Marshal(rule.OpenPorts)
+	if rule.OpenPorts == nil {
+		portsJSON = []byte("[]")
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO recon_classification_rules (`+classificationRuleColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rule.ID, rule.Name, rule.Priority, rule.Enabled, rule.Manufacturer, rule.OUIPrefix,
+		string(portsJSON), rule.HostnameRegex, rule.OS, string(rule.DeviceType), rule.Confidence,
+		rule.CreatedAt, rule.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("create classification rule: %w", err)
+	}
+	return nil
+}
+
+// ListClassificationRules returns all classification rules in evaluation
+// order: ascending priority, then oldest first.
+func (s *ReconStore) ListClassificationRules(ctx context.Context) ([]ClassificationRule, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+classificationRuleColumns+`
+		FROM recon_classification_rules
+		ORDER BY priority ASC, created_at ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("list classification rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []ClassificationRule
+	for rows.Next() {
+		rule, err := scanClassificationRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+	return rules, rows.Err()
+}
+
+// GetClassificationRule returns a classification rule by ID.
+// Returns sql.ErrNoRows if it does not exist.
+func (s *ReconStore) GetClassificationRule(ctx context.Context, id string) (*ClassificationRule, error) {
+	return scanClassificationRule(s.db.QueryRowContext(ctx, `SELECT `+classificationRuleColumns+`
+		FROM recon_classification_rules WHERE id = ?`, id))
+}
+
+// UpdateClassificationRule replaces every editable field of an existing rule.
+// Returns sql.ErrNoRows if it does not exist.
+func (s *ReconStore) UpdateClassificationRule(ctx context.Context, rule *ClassificationRule) error {
+	rule.UpdatedAt = time.Now().UTC()
+	portsJSON, _ := json.Marshal(rule.OpenPorts)
+	if rule.OpenPorts == nil {
+		portsJSON = []byte("[]")
+	}
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE recon_classification_rules SET
+			name = ?, priority = ?, enabled = ?, manufacturer = ?, oui_prefix = ?,
+			open_ports = ?, hostname_regex = ?, os = ?, device_type = ?, confidence = ?,
+			updated_at = ?
+		WHERE id = ?`,
+		rule.Name, rule.Priority, rule.Enabled, rule.Manufacturer, rule.OUIPrefix,
+		string(portsJSON), rule.HostnameRegex, rule.OS, string(rule.DeviceType), rule.Confidence,
+		rule.UpdatedAt, rule.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update classification rule: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteClassificationRule removes a classification rule. Devices it already
+// classified keep their type. Returns sql.ErrNoRows if it does not exist.
+func (s *ReconStore) DeleteClassificationRule(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM recon_classification_rules WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete classification rule: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// matchClassificationRule returns the first enabled rule matching the device,
+// or nil. Open-port criteria are checked against the ports recorded for
+// deviceID by earlier scans; a device not yet stored has none. Lookup errors
+// are treated as no match so rules never block a device upsert.
+func (s *ReconStore) matchClassificationRule(ctx context.Context, d *models.Device, deviceID string) *ClassificationRule {
+	rules, err := s.ListClassificationRules(ctx)
+	if err != nil || len(rules) == 0 {
+		return nil
+	}
+
+	var openPorts []int
+	if deviceID != "" {
+		ports, err := s.GetDevicePorts(ctx, deviceID)
+		if err == nil {
+			for i := range ports {
+				if ports[i].Protocol == "tcp" {
+					openPorts = append(openPorts, ports[i].Port)
+				}
+			}
+		}
+	}
+	return matchRule(rules, d, openPorts)
+}
+
+// scanClassificationRule scans a rule from a row selected with
+// classificationRuleColumns.
+func scanClassificationRule(row interface{ Scan(...any) error }) (*ClassificationRule, error) {
+	var rule ClassificationRule
+	var deviceType, portsJSON string
+	err := row.Scan(&rule.ID, &rule.Name, &rule.Priority, &rule.Enabled, &rule.Manufacturer, &rule.OUIPrefix,
+		&portsJSON, &rule.HostnameRegex, &rule.OS, &deviceType, &rule.Confidence,
+		&rule.CreatedAt, &rule.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scan classification rule: %w", err)
+	}
+	rule.DeviceType = models.DeviceType(deviceType)
+	_ = json.Unmarshal([]byte(portsJSON), &rule.OpenPorts)
+	return &rule, nil
+}
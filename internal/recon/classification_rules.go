@@ -0,0 +1,146 @@
+package recon
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+// ClassificationSourceRule is the classification_source written for devices
+// typed by a user-defined classification rule.
+const ClassificationSourceRule = "rule"
+
+// DefaultRuleConfidence is the confidence assigned by a rule that does not
+// set one. Rule confidence is capped below 100 so a manual classification
+// always wins.
+const DefaultRuleConfidence = 90
+
+// ClassificationRule is a user-defined rule that sets a device type when all
+// of its non-empty match criteria hold. Rules are evaluated in ascending
+// priority order and the first match wins.
+type ClassificationRule struct {
+	ID       string `json:"id"`
+	Name     string `json:"name" example:"Shelly plugs"`
+	Priority int    `json:"priority" example:"10"` // Lower runs first
+	Enabled  bool   `json:"enabled"`
+
+	// Match criteria. Empty criteria are ignored; at least one must be set.
+	Manufacturer  string `json:"manufacturer,omitempty" example:"Shelly"`    // Case-insensitive substring
+	OUIPrefix     string `json:"oui_prefix,omitempty" example:"E8:DB:84"`    // MAC prefix, any separator
+	OpenPorts     []int  `json:"open_ports,omitempty"`                       // All must be recorded open
+	HostnameRegex string `json:"hostname_regex,omitempty" example:"^shelly"` // Go regexp syntax
+	OS            string `json:"os,omitempty" example:"linux"`               // Case-insensitive substring
+
+	DeviceType models.DeviceType `json:"device_type" example:"iot"`
+	Confidence int               `json:"confidence" example:"90"` // 1-99
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// Validate checks that the rule has a name, at least one match criterion,
+// well-formed criteria, a concrete device type, and a confidence in 1-99.
+func (r *ClassificationRule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if r.Manufacturer == "" && r.OUIPrefix == "" && len(r.OpenPorts) == 0 && r.HostnameRegex == "" && r.OS == "" {
+		return fmt.Errorf("at least one of manufacturer, oui_prefix, open_ports, hostname_regex, or os is required")
+	}
+	if r.OUIPrefix != "" {
+		prefix := normalizeMACPrefix(r.OUIPrefix)
+		if len(prefix) < 6 || len(prefix) > 12 || len(prefix)%2 != 0 {
+			return fmt.Errorf("oui_prefix must be 3 to 6 MAC address bytes")
+		}
+		if _, err := hex.DecodeString(prefix); err != nil {
+			return fmt.Errorf("oui_prefix must be hexadecimal")
+		}
+	}
+	for _, port := range r.OpenPorts {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("open_ports entry %d out of range 1-65535", port)
+		}
+	}
+	if r.HostnameRegex != "" {
+		if _, err := regexp.Compile(r.HostnameRegex); err != nil {
+			return fmt.Errorf("invalid hostname_regex: %w", err)
+		}
+	}
+	if r.DeviceType == models.DeviceTypeUnknown || !slices.Contains(classifiableDeviceTypes, r.DeviceType) {
+		return fmt.Errorf("invalid device_type %q", r.DeviceType)
+	}
+	if r.Confidence < 1 || r.Confidence > 99 {
+		return fmt.Errorf("confidence must be between 1 and 99")
+	}
+	return nil
+}
+
+// Matches reports whether every criterion set on the rule holds for the
+// device. openPorts are the ports recorded open for the device.
+func (r *ClassificationRule) Matches(d *models.Device, openPorts []int) bool {
+	if r.Manufacturer != "" &&
+		!strings.Contains(strings.ToLower(d.Manufacturer), strings.ToLower(r.Manufacturer)) {
+		return false
+	}
+	if r.OUIPrefix != "" {
+		mac := normalizeMACPrefix(d.MACAddress)
+		if mac == "" || !strings.HasPrefix(mac, normalizeMACPrefix(r.OUIPrefix)) {
+			return false
+		}
+	}
+	for _, port := range r.OpenPorts {
+		if !slices.Contains(openPorts, port) {
+			return false
+		}
+	}
+	if r.HostnameRegex != "" {
+		re, err := regexp.Compile(r.HostnameRegex)
+		if err != nil || !re.MatchString(d.Hostname) {
+			return false
+		}
+	}
+	if r.OS != "" && !strings.Contains(strings.ToLower(d.OS), strings.ToLower(r.OS)) {
+		return false
+	}
+	return true
+}
+
+// signalsJSON returns the classification_signals recorded for a device the
+// rule classified.
+func (r *ClassificationRule) signalsJSON() string {
+	b, _ := json.Marshal([]ClassificationSignal{{
+		Source:     ClassificationSourceRule,
+		DeviceType: r.DeviceType,
+		Weight:     r.Confidence,
+		Detail:     fmt.Sprintf("Matched classification rule %q", r.Name),
+	}})
+	return string(b)
+}
+
+// normalizeMACPrefix strips separators from a MAC address or prefix and
+// uppercases it, so "e8-db-84" and "E8:DB:84" compare equal.
+func normalizeMACPrefix(mac string) string {
+	return strings.ToUpper(strings.NewReplacer(":", "", "-", "", ".", "").Replace(strings.TrimSpace(mac)))
+}
+
+// matchRule returns the first rule in rules that matches the device, or nil.
+func matchRule(rules []ClassificationRule, d *models.Device, openPorts []int) *ClassificationRule {
+	for i := range rules {
+		if rules[i].Enabled && rules[i].Matches(d, openPorts) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// isClassificationPinned reports whether a device's type was set by the user,
+// directly or through a rule, and must not be changed by the built-in
+// classifiers.
+func isClassificationPinned(d *models.Device) bool {
+	return d.ClassificationSource == "manual" || d.ClassificationSource == ClassificationSourceRule
+}
@@ -0,0 +1,217 @@
+package recon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+func TestClassificationRule_Validate(t *testing.T) {
+	valid := func() ClassificationRule {
+		return ClassificationRule{Name: "shelly", Manufacturer: "Shelly", DeviceType: models.DeviceTypeIoT, Confidence: 90}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(r *ClassificationRule)
+		wantErr bool
+	}{
+		{"valid", func(*ClassificationRule) {}, false},
+		{"missing name", func(r *ClassificationRule) { r.Name = " " }, true},
+		{"no criteria", func(r *ClassificationRule) { r.Manufacturer = "" }, true},
+		{"oui prefix with dashes", func(r *ClassificationRule) { r.OUIPrefix = "e8-db-84" }, false},
+		{"short oui prefix", func(r *ClassificationRule) { r.OUIPrefix = "E8:DB" }, true},
+		{"non-hex oui prefix", func(r *ClassificationRule) { r.OUIPrefix = "ZZ:DB:84" }, true},
+		{"port out of range", func(r *ClassificationRule) { r.OpenPorts = []int{0} }, true},
+		{"bad regex", func(r *ClassificationRule) { r.HostnameRegex = "(" }, true},
+		{"unknown device type", func(r *ClassificationRule) { r.DeviceType = models.DeviceTypeUnknown }, true},
+		{"made-up device type", func(r *ClassificationRule) { r.DeviceType = "toaster" }, true},
+		{"manual confidence", func(r *ClassificationRule) { r.Confidence = 100 }, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := valid()
+			tc.mutate(&r)
+			if err := r.Validate(); (err != nil) != tc.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestClassificationRule_Matches(t *testing.T) {
+	device := &models.Device{
+		Hostname:     "shellyplug-s-1A2B",
+		MACAddress:   "E8:DB:84:12:34:56",
+		Manufacturer: "Espressif Inc.",
+		OS:           "Linux 4.x",
+	}
+
+	tests := []struct {
+		name  string
+		rule  ClassificationRule
+		ports []int
+		want  bool
+	}{
+		{"manufacturer substring", ClassificationRule{Manufacturer: "espressif"}, nil, true},
+		{"oui prefix", ClassificationRule{OUIPrefix: "e8db84"}, nil, true},
+		{"oui prefix mismatch", ClassificationRule{OUIPrefix: "00:11:22"}, nil, false},
+		{"hostname regex", ClassificationRule{HostnameRegex: "^shelly"}, nil, true},
+		{"os substring", ClassificationRule{OS: "linux"}, nil, true},
+		{"all ports open", ClassificationRule{OpenPorts: []int{80, 1883}}, []int{22, 80, 1883}, true},
+		{"port missing", ClassificationRule{OpenPorts: []int{80, 1883}}, []int{80}, false},
+		{"every criterion must hold", ClassificationRule{Manufacturer: "Espressif", HostnameRegex: "^tasmota"}, nil, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.rule.Matches(device, tc.ports); got != tc.want {
+				t.Errorf("Matches() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUpsertDevice_AppliesClassificationRules(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+
+	rules := []*ClassificationRule{
+		{Name: "disabled", Priority: 0, Enabled: false, Manufacturer: "Espressif", DeviceType: models.DeviceTypeCamera, Confidence: 80},
+		{Name: "shelly", Priority: 10, Enabled: true, HostnameRegex: "^shelly", DeviceType: models.DeviceTypeIoT, Confidence: 85},
+		{Name: "web ui", Priority: 20, Enabled: true, OpenPorts: []int{8291}, DeviceType: models.DeviceTypeRouter, Confidence: 70},
+	}
+	for _, r := range rules {
+		if err := s.CreateClassificationRule(ctx, r); err != nil {
+			t.Fatalf("CreateClassificationRule(%s): %v", r.Name, err)
+		}
+	}
+
+	// New device: the first enabled matching rule wins.
+	plug := &models.Device{
+		Hostname:        "shellyplug-1",
+		IPAddresses:     []string{"10.0.0.60"},
+		MACAddress:      "E8:DB:84:00:00:60",
+		Manufacturer:    "Espressif Inc.",
+		DeviceType:      models.DeviceTypeUnknown,
+		DiscoveryMethod: models.DiscoveryICMP,
+	}
+	if _, err := s.UpsertDevice(ctx, plug); err != nil {
+		t.Fatalf("UpsertDevice: %v", err)
+	}
+	got, err := s.GetDevice(ctx, plug.ID)
+	if err != nil {
+		t.Fatalf("GetDevice: %v", err)
+	}
+	if got.DeviceType != models.DeviceTypeIoT || got.ClassificationSource != ClassificationSourceRule || got.ClassificationConfidence != 85 {
+		t.Errorf("new device = %s/%s/%d, want iot/rule/85", got.DeviceType, got.ClassificationSource, got.ClassificationConfidence)
+	}
+
+	// Existing device: port criteria match ports recorded by an earlier scan,
+	// and the rule overrides the type set by the built-in classifier.
+	mikrotik := &models.Device{
+		IPAddresses:     []string{"10.0.0.61"},
+		MACAddress:      "AA:BB:CC:00:00:61",
+		DeviceType:      models.DeviceTypeServer,
+		DiscoveryMethod: models.DiscoveryICMP,
+	}
+	if _, err := s.UpsertDevice(ctx, mikrotik); err != nil {
+		t.Fatalf("UpsertDevice: %v", err)
+	}
+	if err := s.SaveDevicePortScan(ctx, mikrotik.ID, "tcp", []int{8291}, []int{8291}, time.Now()); err != nil {
+		t.Fatalf("SaveDevicePortScan: %v", err)
+	}
+	if _, err := s.UpsertDevice(ctx, &models.Device{IPAddresses: []string{"10.0.0.61"}, MACAddress: "AA:BB:CC:00:00:61"}); err != nil {
+		t.Fatalf("re-UpsertDevice: %v", err)
+	}
+	got, err = s.GetDevice(ctx, mikrotik.ID)
+	if err != nil {
+		t.Fatalf("GetDevice: %v", err)
+	}
+	if got.DeviceType != models.DeviceTypeRouter || got.ClassificationSource != ClassificationSourceRule {
+		t.Errorf("rescanned device = %s/%s, want router/rule", got.DeviceType, got.ClassificationSource)
+	}
+
+	// A manual classification still overrides rules.
+	manual := string(models.DeviceTypeNAS)
+	if err := s.UpdateDevice(ctx, plug.ID, UpdateDeviceParams{DeviceType: &manual}); err != nil {
+		t.Fatalf("UpdateDevice: %v", err)
+	}
+	if _, err := s.UpsertDevice(ctx, &models.Device{Hostname: "shellyplug-1", MACAddress: "E8:DB:84:00:00:60"}); err != nil {
+		t.Fatalf("re-UpsertDevice: %v", err)
+	}
+	got, err = s.GetDevice(ctx, plug.ID)
+	if err != nil {
+		t.Fatalf("GetDevice: %v", err)
+	}
+	if got.DeviceType != models.DeviceTypeNAS || got.ClassificationSource != "manual" {
+		t.Errorf("manual device = %s/%s, want nas/manual", got.DeviceType, got.ClassificationSource)
+	}
+}
+
+func TestClassificationRuleHandlers_CRUD(t *testing.T) {
+	m := newTestModule(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /classification-rules", m.handleListClassificationRules)
+	mux.HandleFunc("POST /classification-rules", m.handleCreateClassificationRule)
+	mux.HandleFunc("GET /classification-rules/{id}", m.handleGetClassificationRule)
+	mux.HandleFunc("PUT /classification-rules/{id}", m.handleUpdateClassificationRule)
+	mux.HandleFunc("DELETE /classification-rules/{id}", m.handleDeleteClassificationRule)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, path, bytes.NewBufferString(body)))
+		return w
+	}
+
+	w := do("POST", "/classification-rules", `{"name":"cams","oui_prefix":"00:12:34"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("create without device_type: status = %d, want 400", w.Code)
+	}
+
+	w = do("POST", "/classification-rules", `{"name":"cams","oui_prefix":"00:12:34","device_type":"camera"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d, want 201: %s", w.Code, w.Body.String())
+	}
+	var created ClassificationRule
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !created.Enabled || created.Confidence != DefaultRuleConfidence {
+		t.Errorf("defaults: enabled=%v confidence=%d, want true/%d", created.Enabled, created.Confidence, DefaultRuleConfidence)
+	}
+
+	w = do("PUT", "/classification-rules/"+created.ID, `{"enabled":false}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	w = do("GET", "/classification-rules/"+created.ID, "")
+	var updated ClassificationRule
+	if err := json.NewDecoder(w.Body).Decode(&updated); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if updated.Enabled || updated.OUIPrefix != "00:12:34" {
+		t.Errorf("after update: enabled=%v oui_prefix=%q, want false/00:12:34", updated.Enabled, updated.OUIPrefix)
+	}
+
+	w = do("GET", "/classification-rules", "")
+	var list []ClassificationRule
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(list) != 1 {
+		t.Errorf("list length = %d, want 1", len(list))
+	}
+
+	if w = do("DELETE", "/classification-rules/"+created.ID, ""); w.Code != http.StatusNoContent {
+		t.Errorf("delete: status = %d, want 204", w.Code)
+	}
+	if w = do("GET", "/classification-rules/"+created.ID, ""); w.Code != http.StatusNotFound {
+		t.Errorf("get after delete: status = %d, want 404", w.Code)
+	}
+}
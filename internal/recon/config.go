@@ -1,20 +1,57 @@
 package recon
 
-import "time"
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/plugin"
+)
+
+// Bounds for the scan tuning keys. Worker counts are capped so a typo
+// cannot spawn thousands of goroutines and sockets on a small box.
+const (
+	MaxConcurrency         = 1024
+	MaxPortScanConcurrency = 256
+	MaxPingCount           = 10
+	MinHostTimeout         = 100 * time.Millisecond
+	MaxHostTimeout         = 30 * time.Second
+)
 
 // ReconConfig holds the Recon module configuration.
 type ReconConfig struct {
-	ScanTimeout     time.Duration  `mapstructure:"scan_timeout"`
-	PingTimeout     time.Duration  `mapstructure:"ping_timeout"`
-	PingCount       int            `mapstructure:"ping_count"`
-	Concurrency     int            `mapstructure:"concurrency"`
-	ARPEnabled      bool           `mapstructure:"arp_enabled"`
-	DeviceLostAfter time.Duration  `mapstructure:"device_lost_after"`
-	MDNSEnabled     bool           `mapstructure:"mdns_enabled"`
-	MDNSInterval    time.Duration  `mapstructure:"mdns_interval"`
-	UPNPEnabled     bool           `mapstructure:"upnp_enabled"`
-	UPNPInterval    time.Duration  `mapstructure:"upnp_interval"`
-	Schedule        ScheduleConfig `mapstructure:"schedule"`
+	ScanTimeout         time.Duration      `mapstructure:"scan_timeout"`
+	PingTimeout         time.Duration      `mapstructure:"ping_timeout"`
+	PingCount           int                `mapstructure:"ping_count"`
+	Concurrency         int                `mapstructure:"concurrency"`
+	PortScanConcurrency int                `mapstructure:"port_scan_concurrency"`
+	PortTimeout         time.Duration      `mapstructure:"port_timeout"`
+	ARPEnabled          bool               `mapstructure:"arp_enabled"`
+	DeviceLostAfter     time.Duration      `mapstructure:"device_lost_after"`
+	TrashRetention      time.Duration      `mapstructure:"trash_retention"`
+	MDNSEnabled         bool               `mapstructure:"mdns_enabled"`
+	MDNSInterval        time.Duration      `mapstructure:"mdns_interval"`
+	UPNPEnabled         bool               `mapstructure:"upnp_enabled"`
+	UPNPInterval        time.Duration      `mapstructure:"upnp_interval"`
+	Schedule            ScheduleConfig     `mapstructure:"schedule"`
+	Syslog              SyslogConfig       `mapstructure:"syslog"`
+	SNMPTrap            SNMPTrapConfig     `mapstructure:"snmp_trap"`
+	Bandwidth           BandwidthConfig    `mapstructure:"bandwidth"`
+	SubnetReport        SubnetReportConfig `mapstructure:"subnet_report"`
+	ReverseDNS          ReverseDNSConfig   `mapstructure:"reverse_dns"`
+	GeoIP               GeoIPConfig        `mapstructure:"geoip"`
+
+	// ContainerDevices creates child container devices under a host for the
+	// Docker containers its Scout agent reports.
+	ContainerDevices bool `mapstructure:"container_devices"`
+
+	// LabelBaseURL is the dashboard origin encoded into device QR labels,
+	// e.g. "https://subnetree.lan". When empty it is derived from the
+	// request that renders the label.
+	LabelBaseURL string `mapstructure:"label_base_url"`
 }
 
 // ScheduleConfig holds configuration for recurring scheduled scans.
@@ -26,22 +63,311 @@ type ScheduleConfig struct {
 	Subnet     string        `mapstructure:"subnet"`
 }
 
+// SyslogConfig holds configuration for the syslog listener that records
+// messages from known devices as device events.
+type SyslogConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	ListenAddr string        `mapstructure:"listen_addr"`
+	Retention  time.Duration `mapstructure:"retention"`
+}
+
+// SNMPTrapConfig holds configuration for the SNMP trap receiver that
+// records traps from known devices as device events.
+type SNMPTrapConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	ListenAddr string        `mapstructure:"listen_addr"`
+	Retention  time.Duration `mapstructure:"retention"`
+}
+
+// BandwidthConfig holds configuration for the SNMP interface counter
+// poller that records per-interface throughput of devices with an SNMP
+// credential in the vault.
+type BandwidthConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	Interval  time.Duration `mapstructure:"interval"`
+	Retention time.Duration `mapstructure:"retention"`
+}
+
+// ReverseDNSConfig controls reverse DNS hostname enrichment for devices
+// that were discovered without a hostname.
+type ReverseDNSConfig struct {
+	// Enabled runs the enrichment pass after every scan. On-demand
+	// lookups through the API work regardless.
+	Enabled bool `mapstructure:"enabled"`
+	// Resolver is the DNS server ("host" or "host:port") to query. When
+	// empty the system resolver is used.
+	Resolver string        `mapstructure:"resolver"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+}
+
+// GeoIPConfig locates the MaxMind-format databases used to tag devices
+// that have public IPs with their location and ASN. GeoIP enrichment is
+// off unless at least one path is set.
+type GeoIPConfig struct {
+	// CityDB is a GeoLite2/GeoIP2 City or Country database (.mmdb).
+	CityDB string `mapstructure:"city_db"`
+	// ASNDB is a GeoLite2/GeoIP2 ASN database (.mmdb).
+	ASNDB string `mapstructure:"asn_db"`
+}
+
+// SubnetReportConfig controls the subnet utilization report.
+type SubnetReportConfig struct {
+	// Subnets are the IPv4 CIDRs to report on. When empty, the /24 of every
+	// known device address is reported.
+	Subnets []string `mapstructure:"subnets"`
+	// Threshold is the utilization percentage at or above which a subnet
+	// is flagged.
+	Threshold float64 `mapstructure:"threshold"`
+}
+
 // DefaultConfig returns the default configuration for the Recon module.
 func DefaultConfig() ReconConfig {
 	return ReconConfig{
-		ScanTimeout:     5 * time.Minute,
-		PingTimeout:     2 * time.Second,
-		PingCount:       3,
-		Concurrency:     64,
-		ARPEnabled:      true,
-		DeviceLostAfter: 24 * time.Hour,
-		MDNSEnabled:     true,
-		MDNSInterval:    60 * time.Second,
-		UPNPEnabled:     true,
-		UPNPInterval:    5 * time.Minute,
+		ScanTimeout:         5 * time.Minute,
+		PingTimeout:         2 * time.Second,
+		PingCount:           3,
+		Concurrency:         64,
+		PortScanConcurrency: 10,
+		PortTimeout:         2 * time.Second,
+		ARPEnabled:          true,
+		DeviceLostAfter:     24 * time.Hour,
+		TrashRetention:      30 * 24 * time.Hour,
+		MDNSEnabled:         true,
+		MDNSInterval:        60 * time.Second,
+		UPNPEnabled:         true,
+		UPNPInterval:        5 * time.Minute,
+		ContainerDevices:    true,
 		Schedule: ScheduleConfig{
 			Enabled:  false,
 			Interval: time.Hour,
 		},
+		Syslog: SyslogConfig{
+			Enabled:    false,
+			ListenAddr: ":514",
+			Retention:  30 * 24 * time.Hour,
+		},
+		SNMPTrap: SNMPTrapConfig{
+			Enabled:    false,
+			ListenAddr: ":162",
+			Retention:  30 * 24 * time.Hour,
+		},
+		Bandwidth: BandwidthConfig{
+			Enabled:   false,
+			Interval:  5 * time.Minute,
+			Retention: 30 * 24 * time.Hour,
+		},
+		SubnetReport: SubnetReportConfig{
+			Threshold: 80,
+		},
+		ReverseDNS: ReverseDNSConfig{
+			Enabled: true,
+			Timeout: 2 * time.Second,
+		},
+	}
+}
+
+// loadConfig reads the module configuration from the plugin config,
+// falling back to DefaultConfig for unset keys. A nil config yields the
+// defaults.
+func loadConfig(c plugin.Config) (ReconConfig, error) {
+	cfg := DefaultConfig()
+	if c != nil {
+		if d := c.GetDuration("scan_timeout"); d > 0 {
+			cfg.ScanTimeout = d
+		}
+		if d := c.GetDuration("ping_timeout"); d > 0 {
+			cfg.PingTimeout = d
+		}
+		if v := c.GetInt("ping_count"); v > 0 {
+			cfg.PingCount = v
+		}
+		if v := c.GetInt("concurrency"); v > 0 {
+			cfg.Concurrency = v
+		}
+		if v := c.GetInt("port_scan_concurrency"); v > 0 {
+			cfg.PortScanConcurrency = v
+		}
+		if d := c.GetDuration("port_timeout"); d > 0 {
+			cfg.PortTimeout = d
+		}
+		if c.IsSet("arp_enabled") {
+			cfg.ARPEnabled = c.GetBool("arp_enabled")
+		}
+		if d := c.GetDuration("device_lost_after"); d > 0 {
+			cfg.DeviceLostAfter = d
+		}
+		if c.IsSet("trash_retention") {
+			cfg.TrashRetention = c.GetDuration("trash_retention")
+		}
+		if c.IsSet("mdns_enabled") {
+			cfg.MDNSEnabled = c.GetBool("mdns_enabled")
+		}
+		if d := c.GetDuration("mdns_interval"); d > 0 {
+			cfg.MDNSInterval = d
+		}
+		if c.IsSet("upnp_enabled") {
+			cfg.UPNPEnabled = c.GetBool("upnp_enabled")
+		}
+		if d := c.GetDuration("upnp_interval"); d > 0 {
+			cfg.UPNPInterval = d
+		}
+		if c.IsSet("schedule.enabled") {
+			cfg.Schedule.Enabled = c.GetBool("schedule.enabled")
+		}
+		if d := c.GetDuration("schedule.interval"); d > 0 {
+			cfg.Schedule.Interval = d
+		}
+		if v := c.GetString("schedule.quiet_start"); v != "" {
+			cfg.Schedule.QuietStart = v
+		}
+		if v := c.GetString("schedule.quiet_end"); v != "" {
+			cfg.Schedule.QuietEnd = v
+		}
+		if v := c.GetString("schedule.subnet"); v != "" {
+			cfg.Schedule.Subnet = v
+		}
+		if c.IsSet("syslog.enabled") {
+			cfg.Syslog.Enabled = c.GetBool("syslog.enabled")
+		}
+		if v := c.GetString("syslog.listen_addr"); v != "" {
+			cfg.Syslog.ListenAddr = v
+		}
+		if d := c.GetDuration("syslog.retention"); d > 0 {
+			cfg.Syslog.Retention = d
+		}
+		if c.IsSet("snmp_trap.enabled") {
+			cfg.SNMPTrap.Enabled = c.GetBool("snmp_trap.enabled")
+		}
+		if v := c.GetString("snmp_trap.listen_addr"); v != "" {
+			cfg.SNMPTrap.ListenAddr = v
+		}
+		if d := c.GetDuration("snmp_trap.retention"); d > 0 {
+			cfg.SNMPTrap.Retention = d
+		}
+		if c.IsSet("bandwidth.enabled") {
+			cfg.Bandwidth.Enabled = c.GetBool("bandwidth.enabled")
+		}
+		if d := c.GetDuration("bandwidth.interval"); d > 0 {
+			cfg.Bandwidth.Interval = d
+		}
+		if d := c.GetDuration("bandwidth.retention"); d > 0 {
+			cfg.Bandwidth.Retention = d
+		}
+		if c.IsSet("reverse_dns.enabled") {
+			cfg.ReverseDNS.Enabled = c.GetBool("reverse_dns.enabled")
+		}
+		if v := c.GetString("reverse_dns.resolver"); v != "" {
+			cfg.ReverseDNS.Resolver = v
+		}
+		if d := c.GetDuration("reverse_dns.timeout"); d > 0 {
+			cfg.ReverseDNS.Timeout = d
+		}
+		if v := c.GetString("geoip.city_db"); v != "" {
+			cfg.GeoIP.CityDB = v
+		}
+		if v := c.GetString("geoip.asn_db"); v != "" {
+			cfg.GeoIP.ASNDB = v
+		}
+		if v := c.GetString("label_base_url"); v != "" {
+			cfg.LabelBaseURL = strings.TrimRight(v, "/")
+		}
+		if c.IsSet("subnet_report") {
+			if err := c.Sub("subnet_report").Unmarshal(&cfg.SubnetReport); err != nil {
+				return cfg, fmt.Errorf("unmarshal recon subnet_report config: %w", err)
+			}
+		}
+	}
+
+	// Allow disabling discovery via environment for QC/testing containers.
+	// Viper's Sub() does not inherit AutomaticEnv, so plugin-scoped env vars
+	// like NV_RECON_MDNS_ENABLED are not visible to the sub-Viper. We check
+	// them explicitly here.
+	if v := os.Getenv("NV_RECON_MDNS_ENABLED"); strings.EqualFold(v, "false") {
+		cfg.MDNSEnabled = false
+	}
+	if v := os.Getenv("NV_RECON_UPNP_ENABLED"); strings.EqualFold(v, "false") {
+		cfg.UPNPEnabled = false
+	}
+	if v := os.Getenv("NV_RECON_SCHEDULE_ENABLED"); strings.EqualFold(v, "false") {
+		cfg.Schedule.Enabled = false
+	}
+	return cfg, nil
+}
+
+// restartRequiredChanges returns the keys of settings that differ between
+// the running and reloaded config but are only read at startup. The scan
+// schedule and port scan tuning are applied live and never reported.
+func restartRequiredChanges(running, reloaded *ReconConfig) []string {
+	checks := []struct {
+		key     string
+		changed bool
+	}{
+		{"scan_timeout", running.ScanTimeout != reloaded.ScanTimeout},
+		{"ping_timeout", running.PingTimeout != reloaded.PingTimeout},
+		{"ping_count", running.PingCount != reloaded.PingCount},
+		{"concurrency", running.Concurrency != reloaded.Concurrency},
+		{"arp_enabled", running.ARPEnabled != reloaded.ARPEnabled},
+		{"device_lost_after", running.DeviceLostAfter != reloaded.DeviceLostAfter},
+		{"trash_retention", running.TrashRetention != reloaded.TrashRetention},
+		{"mdns_enabled", running.MDNSEnabled != reloaded.MDNSEnabled},
+		{"mdns_interval", running.MDNSInterval != reloaded.MDNSInterval},
+		{"upnp_enabled", running.UPNPEnabled != reloaded.UPNPEnabled},
+		{"upnp_interval", running.UPNPInterval != reloaded.UPNPInterval},
+		{"syslog", running.Syslog != reloaded.Syslog},
+		{"snmp_trap", running.SNMPTrap != reloaded.SNMPTrap},
+		{"bandwidth", running.Bandwidth != reloaded.Bandwidth},
+		{"subnet_report", !reflect.DeepEqual(running.SubnetReport, reloaded.SubnetReport)},
+		{"container_devices", running.ContainerDevices != reloaded.ContainerDevices},
+		{"label_base_url", running.LabelBaseURL != reloaded.LabelBaseURL},
+		{"reverse_dns", running.ReverseDNS != reloaded.ReverseDNS},
+		{"geoip", running.GeoIP != reloaded.GeoIP},
+	}
+	var keys []string
+	for _, c := range checks {
+		if c.changed {
+			keys = append(keys, c.key)
+		}
+	}
+	return keys
+}
+
+// Validate checks the scan tuning values against their bounds and the
+// subnet report settings for well-formed values.
+func (c *ReconConfig) Validate() error {
+	if c.Concurrency < 1 || c.Concurrency > MaxConcurrency {
+		return fmt.Errorf("concurrency %d out of range [1, %d]", c.Concurrency, MaxConcurrency)
+	}
+	if c.PortScanConcurrency < 1 || c.PortScanConcurrency > MaxPortScanConcurrency {
+		return fmt.Errorf("port_scan_concurrency %d out of range [1, %d]", c.PortScanConcurrency, MaxPortScanConcurrency)
+	}
+	if c.PingCount < 1 || c.PingCount > MaxPingCount {
+		return fmt.Errorf("ping_count %d out of range [1, %d]", c.PingCount, MaxPingCount)
+	}
+	if c.PingTimeout < MinHostTimeout || c.PingTimeout > MaxHostTimeout {
+		return fmt.Errorf("ping_timeout %s out of range [%s, %s]", c.PingTimeout, MinHostTimeout, MaxHostTimeout)
+	}
+	if c.PortTimeout < MinHostTimeout || c.PortTimeout > MaxHostTimeout {
+		return fmt.Errorf("port_timeout %s out of range [%s, %s]", c.PortTimeout, MinHostTimeout, MaxHostTimeout)
+	}
+	if c.SubnetReport.Threshold < 0 || c.SubnetReport.Threshold > 100 {
+		return fmt.Errorf("subnet_report.threshold %v out of range [0, 100]", c.SubnetReport.Threshold)
+	}
+	if _, err := parseReportSubnets(c.SubnetReport.Subnets); err != nil {
+		return fmt.Errorf("subnet_report.subnets: %w", err)
+	}
+	if c.ReverseDNS.Timeout < MinHostTimeout || c.ReverseDNS.Timeout > MaxHostTimeout {
+		return fmt.Errorf("reverse_dns.timeout %s out of range [%s, %s]", c.ReverseDNS.Timeout, MinHostTimeout, MaxHostTimeout)
+	}
+	if c.ReverseDNS.Resolver != "" {
+		if _, err := resolverAddr(c.ReverseDNS.Resolver); err != nil {
+			return fmt.Errorf("reverse_dns.resolver: %w", err)
+		}
+	}
+	if c.LabelBaseURL != "" {
+		u, err := url.Parse(c.LabelBaseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("label_base_url %q must be an absolute http(s) URL", c.LabelBaseURL)
+		}
 	}
+	return nil
 }
@@ -0,0 +1,109 @@
+package recon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ContainerData represents a Docker container reported by a Scout agent.
+type ContainerData struct {
+	ContainerID string
+	Name        string
+	Image       string
+	State       string
+}
+
+// bridgeContainers mirrors an agent's reported containers as child
+// DeviceTypeContainer records under the agent's host device. Containers that
+// are no longer reported are marked offline rather than deleted.
+func (m *Module) bridgeContainers(ctx context.Context, agent *AgentInfo) {
+	if !m.cfg.ContainerDevices {
+		return
+	}
+
+	containers, err := m.profileSource.GetContainers(ctx, agent.ID)
+	if err != nil {
+		m.logger.Error("failed to get containers for bridge",
+			zap.String("agent_id", agent.ID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	now := time.Now().UTC()
+	seen := make(map[string]bool, len(containers))
+	for i := range containers {
+		if containers[i].Name == "" {
+			continue
+		}
+		deviceID, err := m.upsertContainerDevice(ctx, &containers[i], agent.DeviceID, now)
+		if err != nil {
+			m.logger.Warn("failed to upsert container device",
+				zap.String("container", containers[i].Name),
+				zap.String("host_device_id", agent.DeviceID),
+				zap.Error(err),
+			)
+			continue
+		}
+		seen[deviceID] = true
+	}
+
+	children, err := m.store.FindChildDevicesByDiscovery(ctx, agent.DeviceID, string(models.DiscoveryAgent))
+	if err != nil {
+		m.logger.Warn("failed to find container devices", zap.String("host_device_id", agent.DeviceID), zap.Error(err))
+		return
+	}
+	for i := range children {
+		if children[i].DeviceType != models.DeviceTypeContainer || seen[children[i].ID] {
+			continue
+		}
+		if children[i].Status == models.DeviceStatusOnline {
+			if err := m.store.MarkDeviceOffline(ctx, children[i].ID); err != nil {
+				m.logger.Warn("failed to mark container device offline", zap.String("device_id", children[i].ID), zap.Error(err))
+			}
+		}
+	}
+}
+
+// upsertContainerDevice creates or updates the child device for a container
+// and returns its device ID.
+func (m *Module) upsertContainerDevice(ctx context.Context, c *ContainerData, parentID string, now time.Time) (string, error) {
+	status := models.DeviceStatusOnline
+	if c.State != "" && !strings.EqualFold(c.State, "running") {
+		status = models.DeviceStatusOffline
+	}
+
+	existing, err := m.store.FindDeviceByHostnameAndParent(ctx, c.Name, parentID)
+	if err != nil {
+		return "", fmt.Errorf("find existing device: %w", err)
+	}
+	if existing != nil {
+		// Update status and last_seen directly (avoids UpsertDevice's MAC/IP lookup).
+		if err := m.store.UpdateDeviceStatus(ctx, existing.ID, status, now); err != nil {
+			return "", fmt.Errorf("update device: %w", err)
+		}
+		return existing.ID, nil
+	}
+
+	dev := &models.Device{
+		ID:              uuid.New().String(),
+		Hostname:        c.Name,
+		DeviceType:      models.DeviceTypeContainer,
+		Status:          status,
+		DiscoveryMethod: models.DiscoveryAgent,
+		ParentDeviceID:  parentID,
+		NetworkLayer:    models.NetworkLayerEndpoint,
+		FirstSeen:       now,
+		LastSeen:        now,
+	}
+	if _, err := m.store.UpsertDevice(ctx, dev); err != nil {
+		return "", fmt.Errorf("create device: %w", err)
+	}
+	return dev.ID, nil
+}
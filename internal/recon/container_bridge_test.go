@@ -0,0 +1,99 @@
+package recon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+	"go.uber.org/zap"
+)
+
+// fakeProfileSource is a ProfileSource that serves a fixed container list.
+type fakeProfileSource struct {
+	containers []ContainerData
+}
+
+func (f *fakeProfileSource) GetAgent(_ context.Context, _ string) (*AgentInfo, error) {
+	return nil, nil
+}
+
+func (f *fakeProfileSource) GetHardwareProfile(_ context.Context, _ string) (*HardwareProfileData, error) {
+	return nil, nil
+}
+
+func (f *fakeProfileSource) GetServices(_ context.Context, _ string) ([]*ServiceData, error) {
+	return nil, nil
+}
+
+func (f *fakeProfileSource) GetContainers(_ context.Context, _ string) ([]ContainerData, error) {
+	return f.containers, nil
+}
+
+func TestBridgeContainers(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+
+	host := &models.Device{
+		ID:              "docker-host",
+		Hostname:        "docker-host",
+		DeviceType:      models.DeviceTypeServer,
+		Status:          models.DeviceStatusOnline,
+		DiscoveryMethod: models.DiscoveryAgent,
+	}
+	if _, err := s.UpsertDevice(ctx, host); err != nil {
+		t.Fatalf("upsert host: %v", err)
+	}
+
+	src := &fakeProfileSource{containers: []ContainerData{
+		{ContainerID: "a1", Name: "web", Image: "nginx", State: "running"},
+		{ContainerID: "b2", Name: "worker", Image: "app", State: "exited"},
+	}}
+	m := &Module{logger: zap.NewNop(), cfg: DefaultConfig(), store: s, profileSource: src}
+	agent := &AgentInfo{ID: "agent-1", DeviceID: host.ID}
+
+	m.bridgeContainers(ctx, agent)
+
+	web, err := s.FindDeviceByHostnameAndParent(ctx, "web", host.ID)
+	if err != nil || web == nil {
+		t.Fatalf("web container device not created: %v", err)
+	}
+	if web.DeviceType != models.DeviceTypeContainer || web.Status != models.DeviceStatusOnline {
+		t.Errorf("web = %s/%s, want container/online", web.DeviceType, web.Status)
+	}
+	worker, _ := s.FindDeviceByHostnameAndParent(ctx, "worker", host.ID)
+	if worker == nil || worker.Status != models.DeviceStatusOffline {
+		t.Errorf("worker = %+v, want offline container", worker)
+	}
+
+	// A second report without "web" reuses the record and marks it offline.
+	src.containers = src.containers[1:]
+	m.bridgeContainers(ctx, agent)
+
+	children, err := s.FindChildDevicesByDiscovery(ctx, host.ID, string(models.DiscoveryAgent))
+	if err != nil {
+		t.Fatalf("FindChildDevicesByDiscovery: %v", err)
+	}
+	if len(children) != 2 {
+		t.Fatalf("got %d child devices, want 2", len(children))
+	}
+	web, _ = s.FindDeviceByHostnameAndParent(ctx, "web", host.ID)
+	if web.Status != models.DeviceStatusOffline {
+		t.Errorf("web status = %s, want offline", web.Status)
+	}
+}
+
+func TestBridgeContainers_Disabled(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+
+	cfg := DefaultConfig()
+	cfg.ContainerDevices = false
+	src := &fakeProfileSource{containers: []ContainerData{{Name: "web", State: "running"}}}
+	m := &Module{logger: zap.NewNop(), cfg: cfg, store: s, profileSource: src}
+
+	m.bridgeContainers(ctx, &AgentInfo{ID: "agent-1", DeviceID: "host-1"})
+
+	if dev, _ := s.FindDeviceByHostnameAndParent(ctx, "web", "host-1"); dev != nil {
+		t.Errorf("container device created while disabled: %+v", dev)
+	}
+}
@@ -0,0 +1,175 @@
+package recon
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// normalizeCustomFieldParams type-checks the custom fields of an update
+// against their definitions and replaces them with normalized values.
+// Returns false after writing an error response.
+func (m *Module) normalizeCustomFieldParams(w http.ResponseWriter, r *http.Request, params *UpdateDeviceParams) bool {
+	if params.CustomFields == nil {
+		return true
+	}
+	defs, err := m.store.customFieldDefMap(r.Context())
+	if err != nil {
+		m.logger.Error("failed to load custom field definitions", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to validate custom fields")
+		return false
+	}
+	fields, err := validateCustomFields(*params.CustomFields, defs)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+	params.CustomFields = &fields
+	return true
+}
+
+// handleListCustomFields returns all custom field definitions.
+//
+//	@Summary		List custom fields
+//	@Description	Returns the typed custom field definitions applied to device custom_fields.
+//	@Tags			recon
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{array}		CustomFieldDef
+//	@Failure		500	{object}	models.APIProblem
+//	@Router			/recon/custom-fields [get]
+func (m *Module) handleListCustomFields(w http.ResponseWriter, r *http.Request) {
+	defs, err := m.store.ListCustomFieldDefs(r.Context())
+	if err != nil {
+		m.logger.Error("failed to list custom fields", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to list custom fields")
+		return
+	}
+	if defs == nil {
+		defs = []CustomFieldDef{}
+	}
+	writeJSON(w, http.StatusOK, defs)
+}
+
+// handleCreateCustomField defines a typed custom field.
+//
+//	@Summary		Create custom field
+//	@Description	Defines a custom field of type string, number, bool, date, or enum. Device updates must then supply values of that type.
+//	@Tags			recon
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		CustomFieldDef	true	"Field to define"
+//	@Success		201		{object}	CustomFieldDef
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		409		{object}	models.APIProblem
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/recon/custom-fields [post]
+func (m *Module) handleCreateCustomField(w http.ResponseWriter, r *http.Request) {
+	var def CustomFieldDef
+	if err := json.NewDecoder(r.Body).Decode(&def); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := def.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, err := m.store.GetCustomFieldDef(r.Context(), def.Name); err == nil {
+		writeError(w, http.StatusConflict, "custom field already exists")
+		return
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		m.logger.Error("failed to get custom field", zap.String("name", def.Name), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to create custom field")
+		return
+	}
+
+	if err := m.store.CreateCustomFieldDef(r.Context(), &def); err != nil {
+		m.logger.Error("failed to create custom field", zap.String("name", def.Name), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to create custom field")
+		return
+	}
+	writeJSON(w, http.StatusCreated, def)
+}
+
+// handleUpdateCustomField changes the label, type, or options of a custom
+// field. Fields omitted from the body keep their current values.
+//
+//	@Summary		Update custom field
+//	@Description	Updates a custom field definition. The name cannot change. Existing device values are not rewritten.
+//	@Tags			recon
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			name	path		string			true	"Field name"
+//	@Param			request	body		CustomFieldDef	true	"Fields to update"
+//	@Success		200		{object}	CustomFieldDef
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		404		{object}	models.APIProblem
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/recon/custom-fields/{name} [put]
+func (m *Module) handleUpdateCustomField(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	def, err := m.store.GetCustomFieldDef(r.Context(), name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "custom field not found")
+			return
+		}
+		m.logger.Error("failed to get custom field", zap.String("name", name), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to update custom field")
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(def); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	def.Name = name
+	if def.Type != CustomFieldEnum {
+		def.Options = nil
+	}
+	if err := def.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := m.store.UpdateCustomFieldDef(r.Context(), def); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "custom field not found")
+			return
+		}
+		m.logger.Error("failed to update custom field", zap.String("name", name), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to update custom field")
+		return
+	}
+	writeJSON(w, http.StatusOK, def)
+}
+
+// handleDeleteCustomField removes a custom field definition.
+//
+//	@Summary		Delete custom field
+//	@Description	Deletes a custom field definition. Device values of the field are kept as free-form strings.
+//	@Tags			recon
+//	@Security		BearerAuth
+//	@Param			name	path	string	true	"Field name"
+//	@Success		204		"No content"
+//	@Failure		404		{object}	models.APIProblem
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/recon/custom-fields/{name} [delete]
+func (m *Module) handleDeleteCustomField(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if err := m.store.DeleteCustomFieldDef(r.Context(), name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "custom field not found")
+			return
+		}
+		m.logger.Error("failed to delete custom field", zap.String("name", name), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to delete custom field")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
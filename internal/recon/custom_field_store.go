@@ -0,0 +1,205 @@
+package recon
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+// scanCustomFieldDef scans a recon_custom_field_defs row.
+func scanCustomFieldDef(row interface{ Scan(...any) error }) (*CustomFieldDef, error) {
+	var def CustomFieldDef
+	var optionsJSON string
+	if err := row.Scan(&def.Name, &def.Label, &def.Type, &optionsJSON, &def.CreatedAt, &def.UpdatedAt); err != nil {
+		return nil, err
+	}
+	_ = json.Unmarshal([]byte(optionsJSON), &def.Options)
+	return &def, nil
+}
+
+// CreateCustomFieldDef inserts a new custom field definition.
+func (s *ReconStore) CreateCustomFieldDef(ctx context.Context, def *CustomFieldDef) error {
+	now := time.Now().UTC()
+	def.CreatedAt = now
+	def.UpdatedAt = now
+	optionsJSON, _ := json.Marshal(def.Options)
+	if def.Options == nil {
+		optionsJSON = []byte("[]")
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO recon_custom_field_defs (name, label, type, options, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		def.Name, def.Label, def.Type, string(optionsJSON), def.CreatedAt, def.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("create custom field definition: %w", err)
+	}
+	return nil
+}
+
+// ListCustomFieldDefs returns all custom field definitions ordered by name.
+func (s *ReconStore) ListCustomFieldDefs(ctx context.Context) ([]CustomFieldDef, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT name, label, type, options, created_at, updated_at
+		FROM recon_custom_field_defs ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("list custom field definitions: %w", err)
+	}
+	defer rows.Close()
+
+	var defs []CustomFieldDef
+	for rows.Next() {
+		def, err := scanCustomFieldDef(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan custom field definition: %w", err)
+		}
+		defs = append(defs, *def)
+	}
+	return defs, rows.Err()
+}
+
+// GetCustomFieldDef returns a custom field definition by name.
+// Returns sql.ErrNoRows if it does not exist.
+func (s *ReconStore) GetCustomFieldDef(ctx context.Context, name string) (*CustomFieldDef, error) {
+	return scanCustomFieldDef(s.db.QueryRowContext(ctx, `
+		SELECT name, label, type, options, created_at, updated_at
+		FROM recon_custom_field_defs WHERE name = ?`, name))
+}
+
+// UpdateCustomFieldDef replaces the label, type, and options of a definition.
+// Stored device values are not rewritten; values that no longer parse as the
+// new type are ignored by custom field filters and sort until next edited.
+// Returns sql.ErrNoRows if it does not exist.
+func (s *ReconStore) UpdateCustomFieldDef(ctx context.Context, def *CustomFieldDef) error {
+	def.UpdatedAt = time.Now().UTC()
+	optionsJSON, _ := json.Marshal(def.Options)
+	if def.Options == nil {
+		optionsJSON = []byte("[]")
+	}
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE recon_custom_field_defs SET label = ?, type = ?, options = ?, updated_at = ?
+		WHERE name = ?`,
+		def.Label, def.Type, string(optionsJSON), def.UpdatedAt, def.Name,
+	)
+	if err != nil {
+		return fmt.Errorf("update custom field definition: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteCustomFieldDef removes a custom field definition. Device values of
+// the field are kept and become free-form strings again.
+// Returns sql.ErrNoRows if it does not exist.
+func (s *ReconStore) DeleteCustomFieldDef(ctx context.Context, name string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM recon_custom_field_defs WHERE name = ?`, name)
+	if err != nil {
+		return fmt.Errorf("delete custom field definition: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetDeviceCustomField sets one custom field value on a device and keeps its
+// other fields. The value is not validated against field definitions; it is
+// meant for integrations recording values such as external IDs.
+// Returns sql.ErrNoRows if the device does not exist.
+func (s *ReconStore) SetDeviceCustomField(ctx context.Context, deviceID, key, value string) error {
+	return s.SetDeviceCustomFields(ctx, deviceID, map[string]string{key: value})
+}
+
+// SetDeviceCustomFields sets several custom field values on a device in one
+// transaction, keeping its other fields. Like SetDeviceCustomField, values
+// are not validated against field definitions.
+func (s *ReconStore) SetDeviceCustomFields(ctx context.Context, deviceID string, values map[string]string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
+
+	var cfJSON string
+	err = tx.QueryRowContext(ctx, `SELECT custom_fields FROM recon_devices WHERE id = ?`, deviceID).Scan(&cfJSON)
+	if err != nil {
+		return err
+	}
+	var fields map[string]string
+	_ = json.Unmarshal([]byte(cfJSON), &fields)
+	if fields == nil {
+		fields = make(map[string]string, len(values))
+	}
+	for k, v := range values {
+		fields[k] = v
+	}
+
+	updated, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("marshal custom fields: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE recon_devices SET custom_fields = ? WHERE id = ?`, string(updated), deviceID); err != nil {
+		return fmt.Errorf("update custom_fields: %w", err)
+	}
+	return tx.Commit()
+}
+
+// customFieldDefMap returns the custom field definitions keyed by name.
+func (s *ReconStore) customFieldDefMap(ctx context.Context) (map[string]*CustomFieldDef, error) {
+	defs, err := s.ListCustomFieldDefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]*CustomFieldDef, len(defs))
+	for i := range defs {
+		m[defs[i].Name] = &defs[i]
+	}
+	return m, nil
+}
+
+// listDevicesByCustomFields serves ListDevices when custom field filters or
+// sort are set. custom_fields is a JSON column whose functions differ
+// between SQLite and PostgreSQL, so the rows matching the SQL filters are
+// loaded and the custom field filters, sort, and pagination are applied in Go.
+func (s *ReconStore) listDevicesByCustomFields(ctx context.Context, where string, args []any, opts ListDevicesOptions) ([]models.Device, int, error) {
+	//nolint:gosec // where uses parameterized placeholders only
+	rows, err := s.db.QueryContext(ctx, "SELECT "+
+		"id, hostname, ip_addresses, mac_address, manufacturer, "+
+		"device_type, os, status, discovery_method, agent_id, "+
+		"first_seen, last_seen, notes, tags, custom_fields, "+
+		"location, category, primary_role, owner, "+
+		"classification_confidence, classification_source, classification_signals, "+
+		"parent_device_id, network_layer, connection_type "+
+		"FROM recon_devices WHERE "+where+" ORDER BY last_seen DESC, id ASC",
+		args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list devices: %w", err)
+	}
+	defer rows.Close()
+
+	var devices []models.Device
+	for rows.Next() {
+		d, err := s.scanDeviceRow(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		devices = append(devices, *d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	devices = filterAndSortByCustomFields(devices, opts.CustomFilters, opts.CustomSort)
+	total := len(devices)
+	if opts.Offset >= total {
+		return nil, total, nil
+	}
+	end := min(opts.Offset+opts.Limit, total)
+	return devices[opts.Offset:end], total, nil
+}
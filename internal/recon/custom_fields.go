@@ -0,0 +1,330 @@
+package recon
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+// Custom field value types.
+const (
+	CustomFieldString = "string"
+	CustomFieldNumber = "number"
+	CustomFieldBool   = "bool"
+	CustomFieldDate   = "date"
+	CustomFieldEnum   = "enum"
+)
+
+var customFieldTypes = []string{CustomFieldString, CustomFieldNumber, CustomFieldBool, CustomFieldDate, CustomFieldEnum}
+
+// customFieldNameRe restricts field names to identifiers so they can be used
+// unambiguously in query parameters such as cf.<name>.lt.
+var customFieldNameRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// CustomFieldDef declares the type of a device custom field. Values are still
+// stored as strings in the device's custom_fields JSON; the definition is
+// enforced when devices are updated through the API. Fields without a
+// definition remain free-form strings.
+type CustomFieldDef struct {
+	Name      string    `json:"name" example:"warranty_expiry"`
+	Label     string    `json:"label,omitempty" example:"Warranty expiry"`
+	Type      string    `json:"type" example:"date"` // string, number, bool, date, or enum
+	Options   []string  `json:"options,omitempty"`   // Allowed values of an enum field
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks the field name and type, and that only enum fields have
+// options.
+func (d *CustomFieldDef) Validate() error {
+	if !customFieldNameRe.MatchString(d.Name) {
+		return fmt.Errorf("name must start with a lowercase letter and contain only lowercase letters, digits, and underscores (max 64)")
+	}
+	if !slices.Contains(customFieldTypes, d.Type) {
+		return fmt.Errorf("type must be one of %s", strings.Join(customFieldTypes, ", "))
+	}
+	if d.Type != CustomFieldEnum {
+		if len(d.Options) > 0 {
+			return fmt.Errorf("options are only allowed for enum fields")
+		}
+		return nil
+	}
+	if len(d.Options) == 0 {
+		return fmt.Errorf("enum fields require at least one option")
+	}
+	seen := make(map[string]bool, len(d.Options))
+	for _, opt := range d.Options {
+		if strings.TrimSpace(opt) == "" {
+			return fmt.Errorf("enum options must not be empty")
+		}
+		if seen[opt] {
+			return fmt.Errorf("duplicate enum option %q", opt)
+		}
+		seen[opt] = true
+	}
+	return nil
+}
+
+// normalizeCustomValue checks v against a field type and returns its
+// canonical form: numbers as shortest decimal, bools as "true"/"false",
+// dates as YYYY-MM-DD, and enum values as the matching option.
+func normalizeCustomValue(def *CustomFieldDef, v string) (string, error) {
+	v = strings.TrimSpace(v)
+	switch def.Type {
+	case CustomFieldNumber:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return "", fmt.Errorf("custom field %q must be a number", def.Name)
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	case CustomFieldBool:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return "", fmt.Errorf("custom field %q must be true or false", def.Name)
+		}
+		return strconv.FormatBool(b), nil
+	case CustomFieldDate:
+		t, err := parseCustomDate(v)
+		if err != nil {
+			return "", fmt.Errorf("custom field %q must be a date (YYYY-MM-DD)", def.Name)
+		}
+		return t.Format(time.DateOnly), nil
+	case CustomFieldEnum:
+		for _, opt := range def.Options {
+			if strings.EqualFold(opt, v) {
+				return opt, nil
+			}
+		}
+		return "", fmt.Errorf("custom field %q must be one of %s", def.Name, strings.Join(def.Options, ", "))
+	default:
+		return v, nil
+	}
+}
+
+// parseCustomDate accepts a plain date or an RFC 3339 timestamp.
+func parseCustomDate(v string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
+}
+
+// validateCustomFields checks the values of defined fields against their
+// definitions and returns a copy with normalized values. Empty values of
+// defined fields are dropped so clearing a field in a form unsets it.
+// Undefined fields pass through unchanged.
+func validateCustomFields(fields map[string]string, defs map[string]*CustomFieldDef) (map[string]string, error) {
+	out := make(map[string]string, len(fields))
+	for name, v := range fields {
+		def, ok := defs[name]
+		if !ok {
+			out[name] = v
+			continue
+		}
+		if strings.TrimSpace(v) == "" {
+			continue
+		}
+		norm, err := normalizeCustomValue(def, v)
+		if err != nil {
+			return nil, err
+		}
+		out[name] = norm
+	}
+	return out, nil
+}
+
+// compareCustomValues orders two values of a field type. ok is false when
+// either value does not parse as the type, e.g. a value stored before the
+// field was defined.
+func compareCustomValues(typ, a, b string) (c int, ok bool) {
+	switch typ {
+	case CustomFieldNumber:
+		fa, errA := strconv.ParseFloat(strings.TrimSpace(a), 64)
+		fb, errB := strconv.ParseFloat(strings.TrimSpace(b), 64)
+		if errA != nil || errB != nil {
+			return 0, false
+		}
+		return cmp.Compare(fa, fb), true
+	case CustomFieldBool:
+		ba, errA := strconv.ParseBool(strings.TrimSpace(a))
+		bb, errB := strconv.ParseBool(strings.TrimSpace(b))
+		if errA != nil || errB != nil {
+			return 0, false
+		}
+		switch {
+		case ba == bb:
+			return 0, true
+		case bb:
+			return -1, true
+		default:
+			return 1, true
+		}
+	case CustomFieldDate:
+		ta, errA := parseCustomDate(strings.TrimSpace(a))
+		tb, errB := parseCustomDate(strings.TrimSpace(b))
+		if errA != nil || errB != nil {
+			return 0, false
+		}
+		return ta.Compare(tb), true
+	default:
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b)), true
+	}
+}
+
+// CustomFieldFilter restricts ListDevices to devices whose custom field
+// compares to Value with Op (eq, lt, lte, gt, or gte).
+type CustomFieldFilter struct {
+	Field string
+	Type  string
+	Op    string
+	Value string
+}
+
+// matches reports whether fields satisfies the filter. Devices without the
+// field, or with a value that does not parse as the field type, never match.
+func (f *CustomFieldFilter) matches(fields map[string]string) bool {
+	v, ok := fields[f.Field]
+	if !ok || v == "" {
+		return false
+	}
+	c, ok := compareCustomValues(f.Type, v, f.Value)
+	if !ok {
+		return false
+	}
+	switch f.Op {
+	case "lt":
+		return c < 0
+	case "lte":
+		return c <= 0
+	case "gt":
+		return c > 0
+	case "gte":
+		return c >= 0
+	default:
+		return c == 0
+	}
+}
+
+// CustomFieldSort orders ListDevices by a custom field.
+type CustomFieldSort struct {
+	Field string
+	Type  string
+	Desc  bool
+}
+
+// filterAndSortByCustomFields applies custom field filters and sort to
+// devices, which must already be in the default last_seen order. Devices
+// without a sortable value come last in either direction and keep their
+// relative order.
+func filterAndSortByCustomFields(devices []models.Device, filters []CustomFieldFilter, sortBy *CustomFieldSort) []models.Device {
+	var out []models.Device
+	for i := range devices {
+		keep := true
+		for j := range filters {
+			if !filters[j].matches(devices[i].CustomFields) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			out = append(out, devices[i])
+		}
+	}
+	if sortBy == nil {
+		return out
+	}
+
+	slices.SortStableFunc(out, func(a, b models.Device) int {
+		va, vb := a.CustomFields[sortBy.Field], b.CustomFields[sortBy.Field]
+		_, okA := compareCustomValues(sortBy.Type, va, va)
+		_, okB := compareCustomValues(sortBy.Type, vb, vb)
+		okA = okA && va != ""
+		okB = okB && vb != ""
+		switch {
+		case !okA && !okB:
+			return 0
+		case !okA:
+			return 1
+		case !okB:
+			return -1
+		}
+		c, _ := compareCustomValues(sortBy.Type, va, vb)
+		if sortBy.Desc {
+			return -c
+		}
+		return c
+	})
+	return out
+}
+
+// customFieldOps are the comparison suffixes accepted on cf.<name> query
+// parameters. A parameter with no suffix is an equality filter.
+var customFieldOps = []string{"lt", "lte", "gt", "gte"}
+
+// parseCustomFieldQuery reads cf.<name>[.<op>] filters and a sort=cf.<name>
+// parameter from a device list query. Filter values of defined fields are
+// checked against the field type; undefined fields compare as strings.
+func parseCustomFieldQuery(q url.Values, defs map[string]*CustomFieldDef) ([]CustomFieldFilter, *CustomFieldSort, error) {
+	typeOf := func(name string) string {
+		if def, ok := defs[name]; ok {
+			return def.Type
+		}
+		return CustomFieldString
+	}
+
+	var filters []CustomFieldFilter
+	for key, values := range q {
+		rest, ok := strings.CutPrefix(key, "cf.")
+		if !ok {
+			continue
+		}
+		name, op, hasOp := strings.Cut(rest, ".")
+		if !hasOp {
+			op = "eq"
+		} else if !slices.Contains(customFieldOps, op) {
+			return nil, nil, fmt.Errorf("unsupported custom field operator %q (use lt, lte, gt, or gte)", op)
+		}
+		if !customFieldNameRe.MatchString(name) {
+			return nil, nil, fmt.Errorf("invalid custom field name %q", name)
+		}
+		for _, v := range values {
+			if def, ok := defs[name]; ok {
+				norm, err := normalizeCustomValue(def, v)
+				if err != nil {
+					return nil, nil, err
+				}
+				v = norm
+			}
+			filters = append(filters, CustomFieldFilter{Field: name, Type: typeOf(name), Op: op, Value: v})
+		}
+	}
+	// Map iteration order is random; keep filters deterministic.
+	slices.SortFunc(filters, func(a, b CustomFieldFilter) int {
+		return cmp.Or(strings.Compare(a.Field, b.Field), strings.Compare(a.Op, b.Op), strings.Compare(a.Value, b.Value))
+	})
+
+	var sortBy *CustomFieldSort
+	if s := q.Get("sort"); s != "" {
+		name, ok := strings.CutPrefix(s, "cf.")
+		if !ok || !customFieldNameRe.MatchString(name) {
+			return nil, nil, fmt.Errorf("sort must be cf.<field name>")
+		}
+		order := q.Get("order")
+		if order != "" && order != "asc" && order != "desc" {
+			return nil, nil, fmt.Errorf("order must be 'asc' or 'desc'")
+		}
+		sortBy = &CustomFieldSort{Field: name, Type: typeOf(name), Desc: order == "desc"}
+	}
+	return filters, sortBy, nil
+}
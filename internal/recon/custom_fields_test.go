@@ -0,0 +1,234 @@
+package recon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+func TestCustomFieldDef_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		def     CustomFieldDef
+		wantErr bool
+	}{
+		{"date", CustomFieldDef{Name: "warranty_expiry", Type: CustomFieldDate}, false},
+		{"enum", CustomFieldDef{Name: "rack", Type: CustomFieldEnum, Options: []string{"A", "B"}}, false},
+		{"uppercase name", CustomFieldDef{Name: "Rack", Type: CustomFieldString}, true},
+		{"dotted name", CustomFieldDef{Name: "rack.unit", Type: CustomFieldNumber}, true},
+		{"unknown type", CustomFieldDef{Name: "rack", Type: "json"}, true},
+		{"enum without options", CustomFieldDef{Name: "rack", Type: CustomFieldEnum}, true},
+		{"duplicate option", CustomFieldDef{Name: "rack", Type: CustomFieldEnum, Options: []string{"A", "A"}}, true},
+		{"options on string", CustomFieldDef{Name: "rack", Type: CustomFieldString, Options: []string{"A"}}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.def.Validate(); (err != nil) != tc.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCustomFields(t *testing.T) {
+	defs := map[string]*CustomFieldDef{
+		"warranty_expiry": {Name: "warranty_expiry", Type: CustomFieldDate},
+		"rack_unit":       {Name: "rack_unit", Type: CustomFieldNumber},
+		"managed":         {Name: "managed", Type: CustomFieldBool},
+		"tier":            {Name: "tier", Type: CustomFieldEnum, Options: []string{"Gold", "Silver"}},
+	}
+
+	got, err := validateCustomFields(map[string]string{
+		"warranty_expiry": "2027-03-01T10:00:00Z",
+		"rack_unit":       " 04 ",
+		"managed":         "1",
+		"tier":            "gold",
+		"asset_tag":       "A-17", // undefined, kept as-is
+		"notes_url":       "",     // undefined, kept as-is
+	}, defs)
+	if err != nil {
+		t.Fatalf("validateCustomFields: %v", err)
+	}
+	want := map[string]string{
+		"warranty_expiry": "2027-03-01",
+		"rack_unit":       "4",
+		"managed":         "true",
+		"tier":            "Gold",
+		"asset_tag":       "A-17",
+		"notes_url":       "",
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+
+	if got, err := validateCustomFields(map[string]string{"rack_unit": ""}, defs); err != nil || len(got) != 0 {
+		t.Errorf("empty defined value = %v, %v; want it dropped", got, err)
+	}
+
+	for field, value := range map[string]string{
+		"warranty_expiry": "next year",
+		"rack_unit":       "4U",
+		"managed":         "maybe",
+		"tier":            "Bronze",
+	} {
+		if _, err := validateCustomFields(map[string]string{field: value}, defs); err == nil {
+			t.Errorf("%s=%q accepted, want error", field, value)
+		}
+	}
+}
+
+func TestFilterAndSortByCustomFields(t *testing.T) {
+	devices := []models.Device{
+		{ID: "a", CustomFields: map[string]string{"warranty_expiry": "2026-12-01", "rack_unit": "10"}},
+		{ID: "b", CustomFields: map[string]string{"warranty_expiry": "2025-06-30", "rack_unit": "2"}},
+		{ID: "c", CustomFields: map[string]string{"rack_unit": "9"}},
+		{ID: "d", CustomFields: map[string]string{"warranty_expiry": "soon"}}, // stored before typing
+		{ID: "e", CustomFields: map[string]string{"warranty_expiry": "2027-01-15"}},
+	}
+	ids := func(ds []models.Device) string {
+		var b bytes.Buffer
+		for i := range ds {
+			b.WriteString(ds[i].ID)
+		}
+		return b.String()
+	}
+
+	// Numeric sort, not lexicographic: 2 < 9 < 10.
+	got := filterAndSortByCustomFields(devices, nil, &CustomFieldSort{Field: "rack_unit", Type: CustomFieldNumber})
+	if ids(got) != "bcade" {
+		t.Errorf("sort by rack_unit = %s, want bcade", ids(got))
+	}
+
+	// Descending keeps devices without a date last, in their original order.
+	got = filterAndSortByCustomFields(devices, nil, &CustomFieldSort{Field: "warranty_expiry", Type: CustomFieldDate, Desc: true})
+	if ids(got) != "eabcd" {
+		t.Errorf("sort by warranty_expiry desc = %s, want eabcd", ids(got))
+	}
+
+	got = filterAndSortByCustomFields(devices, []CustomFieldFilter{
+		{Field: "warranty_expiry", Type: CustomFieldDate, Op: "lt", Value: "2027-01-01"},
+	}, nil)
+	if ids(got) != "ab" {
+		t.Errorf("warranty_expiry < 2027-01-01 = %s, want ab", ids(got))
+	}
+
+	got = filterAndSortByCustomFields(devices, []CustomFieldFilter{
+		{Field: "rack_unit", Type: CustomFieldNumber, Op: "gte", Value: "9"},
+		{Field: "warranty_expiry", Type: CustomFieldDate, Op: "gt", Value: "2026-01-01"},
+	}, nil)
+	if ids(got) != "a" {
+		t.Errorf("combined filters = %s, want a", ids(got))
+	}
+}
+
+func TestParseCustomFieldQuery(t *testing.T) {
+	defs := map[string]*CustomFieldDef{
+		"warranty_expiry": {Name: "warranty_expiry", Type: CustomFieldDate},
+	}
+
+	q := url.Values{
+		"cf.warranty_expiry.lt": {"2027-01-01T00:00:00Z"},
+		"cf.asset_tag":          {"A-17"},
+		"sort":                  {"cf.warranty_expiry"},
+		"order":                 {"desc"},
+		"status":                {"online"},
+	}
+	filters, sortBy, err := parseCustomFieldQuery(q, defs)
+	if err != nil {
+		t.Fatalf("parseCustomFieldQuery: %v", err)
+	}
+	if len(filters) != 2 {
+		t.Fatalf("filters = %+v, want 2", filters)
+	}
+	if f := filters[0]; f.Field != "asset_tag" || f.Type != CustomFieldString || f.Op != "eq" {
+		t.Errorf("filters[0] = %+v, want asset_tag string eq", f)
+	}
+	if f := filters[1]; f.Field != "warranty_expiry" || f.Op != "lt" || f.Value != "2027-01-01" {
+		t.Errorf("filters[1] = %+v, want normalized warranty_expiry lt 2027-01-01", f)
+	}
+	if sortBy == nil || sortBy.Field != "warranty_expiry" || sortBy.Type != CustomFieldDate || !sortBy.Desc {
+		t.Errorf("sort = %+v, want warranty_expiry date desc", sortBy)
+	}
+
+	for _, bad := range []url.Values{
+		{"cf.warranty_expiry": {"tomorrow"}},
+		{"cf.rack.between": {"1"}},
+		{"cf.Rack": {"1"}},
+		{"sort": {"hostname"}},
+		{"sort": {"cf.rack"}, "order": {"up"}},
+	} {
+		if _, _, err := parseCustomFieldQuery(bad, defs); err == nil {
+			t.Errorf("parseCustomFieldQuery(%v) = nil error, want error", bad)
+		}
+	}
+}
+
+func TestCustomFieldHandlers_TypedDeviceUpdate(t *testing.T) {
+	m := newTestModule(t)
+	ctx := context.Background()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /custom-fields", m.handleCreateCustomField)
+	mux.HandleFunc("GET /devices", m.handleListDevices)
+	mux.HandleFunc("PUT /devices/{id}", m.handleUpdateDevice)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, path, bytes.NewBufferString(body)))
+		return w
+	}
+
+	if w := do("POST", "/custom-fields", `{"name":"warranty_expiry","type":"date"}`); w.Code != http.StatusCreated {
+		t.Fatalf("create field: status = %d, want 201: %s", w.Code, w.Body.String())
+	}
+	if w := do("POST", "/custom-fields", `{"name":"warranty_expiry","type":"string"}`); w.Code != http.StatusConflict {
+		t.Errorf("duplicate field: status = %d, want 409", w.Code)
+	}
+
+	var ids []string
+	for i, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		d := &models.Device{IPAddresses: []string{ip}, MACAddress: fmt.Sprintf("AA:BB:CC:00:00:%02X", i+1), DiscoveryMethod: models.DiscoveryICMP}
+		if _, err := m.store.UpsertDevice(ctx, d); err != nil {
+			t.Fatalf("UpsertDevice: %v", err)
+		}
+		ids = append(ids, d.ID)
+	}
+
+	if w := do("PUT", "/devices/"+ids[0], `{"custom_fields":{"warranty_expiry":"next year"}}`); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid date: status = %d, want 400", w.Code)
+	}
+	for i, date := range []string{"2027-05-01", "2026-11-30"} {
+		if w := do("PUT", "/devices/"+ids[i], `{"custom_fields":{"warranty_expiry":"`+date+`"}}`); w.Code != http.StatusOK {
+			t.Fatalf("update device: status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+	}
+
+	w := do("GET", "/devices?cf.warranty_expiry.lt=2027-01-01", "")
+	var list DeviceListResponse
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if list.Total != 1 || len(list.Devices) != 1 || list.Devices[0].ID != ids[1] {
+		t.Errorf("filtered list = total %d %+v, want only %s", list.Total, list.Devices, ids[1])
+	}
+
+	w = do("GET", "/devices?sort=cf.warranty_expiry&limit=2", "")
+	list = DeviceListResponse{}
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if list.Total != 3 || len(list.Devices) != 2 || list.Devices[0].ID != ids[1] || list.Devices[1].ID != ids[0] {
+		t.Errorf("sorted page = total %d %+v, want %s then %s", list.Total, list.Devices, ids[1], ids[0])
+	}
+}
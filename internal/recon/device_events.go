@@ -0,0 +1,86 @@
+package recon
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// deviceCacheTTL is how long a source IP to device match is cached.
+const deviceCacheTTL = 5 * time.Minute
+
+// deviceIPCache resolves sender IPs to device IDs for the passive event
+// receivers (syslog, SNMP traps), caching both matches and misses.
+type deviceIPCache struct {
+	store  *ReconStore
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	entries map[string]deviceCacheEntry
+}
+
+type deviceCacheEntry struct {
+	deviceID string
+	expires  time.Time
+}
+
+func newDeviceIPCache(store *ReconStore, logger *zap.Logger) *deviceIPCache {
+	return &deviceIPCache{
+		store:   store,
+		logger:  logger,
+		entries: make(map[string]deviceCacheEntry),
+	}
+}
+
+// lookup returns the device ID for ip, or "" if no device has that address.
+func (c *deviceIPCache) lookup(ctx context.Context, ip string) string {
+	now := time.Now()
+	c.mu.Lock()
+	entry, ok := c.entries[ip]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.deviceID
+	}
+
+	deviceID := ""
+	device, err := c.store.GetDeviceByIP(ctx, ip)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		c.logger.Warn("device lookup by IP failed", zap.String("ip", ip), zap.Error(err))
+	case device != nil:
+		deviceID = device.ID
+	}
+
+	c.mu.Lock()
+	c.entries[ip] = deviceCacheEntry{deviceID: deviceID, expires: now.Add(deviceCacheTTL)}
+	c.mu.Unlock()
+	return deviceID
+}
+
+// runDeviceEventPruner deletes events from source older than retention once
+// an hour until ctx is cancelled. A zero retention keeps events forever.
+func runDeviceEventPruner(ctx context.Context, store *ReconStore, logger *zap.Logger, source string, retention time.Duration) {
+	if retention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		n, err := store.PruneDeviceLogEvents(ctx, source, time.Now().Add(-retention))
+		if err != nil && ctx.Err() == nil {
+			logger.Warn("failed to prune device events", zap.String("source", source), zap.Error(err))
+		} else if n > 0 {
+			logger.Debug("pruned device events", zap.String("source", source), zap.Int64("deleted", n))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package recon
+
+import (
+	"net/http"
+
+	// models is imported for swagger annotation resolution (models.APIProblem).
+	_ "github.com/HerbHall/subnetree/pkg/models"
+	"go.uber.org/zap"
+)
+
+// handleDeviceEvents returns events received from a device, such as syslog
+// messages and SNMP traps.
+//
+//	@Summary		Device log events
+//	@Description	Returns syslog messages and SNMP traps received from the device, newest first.
+//	@Tags			recon
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string	true	"Device ID"
+//	@Param			level	query		string	false	"Filter by level (critical, warning, info)"
+//	@Param			source	query		string	false	"Filter by source (syslog, snmp_trap)"
+//	@Param			limit	query		int		false	"Max results"	default(100)
+//	@Success		200		{array}		DeviceLogEvent
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/recon/devices/{id}/events [get]
+func (m *Module) handleDeviceEvents(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "device ID is required")
+		return
+	}
+
+	level := r.URL.Query().Get("level")
+	switch level {
+	case "", EventLevelCritical, EventLevelWarning, EventLevelInfo:
+	default:
+		writeError(w, http.StatusBadRequest, "level must be one of critical, warning, info")
+		return
+	}
+
+	events, err := m.store.ListDeviceLogEvents(r.Context(), id, DeviceLogEventFilter{
+		Level:  level,
+		Source: r.URL.Query().Get("source"),
+		Limit:  queryInt(r, "limit", 100),
+	})
+	if err != nil {
+		m.logger.Error("failed to list device events", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to list device events")
+		return
+	}
+	if events == nil {
+		events = []DeviceLogEvent{}
+	}
+	writeJSON(w, http.StatusOK, events)
+}
@@ -0,0 +1,121 @@
+package recon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DeviceLogEvent is a message received from a device, such as a syslog
+// message or SNMP trap matched to the device by its source IP.
+type DeviceLogEvent struct {
+	ID           int64         `json:"id"`
+	DeviceID     string        `json:"device_id"`
+	Source       string        `json:"source"` // "syslog" or "snmp_trap"
+	SourceIP     string        `json:"source_ip"`
+	Facility     int           `json:"facility"`
+	Severity     int           `json:"severity"`
+	SeverityName string        `json:"severity_name"`
+	Level        string        `json:"level"`
+	Hostname     string        `json:"hostname,omitempty"`
+	AppName      string        `json:"app_name,omitempty"`
+	Message      string        `json:"message"`
+	TrapOID      string        `json:"trap_oid,omitempty"`
+	Varbinds     []TrapVarbind `json:"varbinds,omitempty"`
+	Timestamp    time.Time     `json:"timestamp"`
+	ReceivedAt   time.Time     `json:"received_at"`
+}
+
+// TrapVarbind is a variable binding carried by an SNMP trap.
+type TrapVarbind struct {
+	OID   string `json:"oid"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// DeviceLogEventFilter narrows ListDeviceLogEvents results. Empty fields
+// match everything.
+type DeviceLogEventFilter struct {
+	Level  string
+	Source string
+	Limit  int
+}
+
+// InsertDeviceLogEvent stores a device event and sets its ID.
+func (s *ReconStore) InsertDeviceLogEvent(ctx context.Context, ev *DeviceLogEvent) error {
+	varbinds := ""
+	if len(ev.Varbinds) > 0 {
+		b, err := json.Marshal(ev.Varbinds)
+		if err != nil {
+			return fmt.Errorf("marshal varbinds: %w", err)
+		}
+		varbinds = string(b)
+	}
+	err := s.db.QueryRowContext(ctx, `INSERT INTO recon_device_events (
+		device_id, source, source_ip, facility, severity, severity_name,
+		level, hostname, app_name, message, trap_oid, varbinds, timestamp, received_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	RETURNING id`,
+		ev.DeviceID, ev.Source, ev.SourceIP, ev.Facility, ev.Severity, ev.SeverityName,
+		ev.Level, ev.Hostname, ev.AppName, ev.Message, ev.TrapOID, varbinds, ev.Timestamp, ev.ReceivedAt,
+	).Scan(&ev.ID)
+	if err != nil {
+		return fmt.Errorf("insert device event: %w", err)
+	}
+	return nil
+}
+
+// ListDeviceLogEvents returns a device's most recent events, newest first.
+func (s *ReconStore) ListDeviceLogEvents(ctx context.Context, deviceID string, f DeviceLogEventFilter) ([]DeviceLogEvent, error) {
+	if f.Limit <= 0 {
+		f.Limit = 100
+	}
+	query := `SELECT id, device_id, source, source_ip, facility, severity, severity_name,
+		level, hostname, app_name, message, trap_oid, varbinds, timestamp, received_at
+		FROM recon_device_events WHERE device_id = ?`
+	args := []any{deviceID}
+	if f.Level != "" {
+		query += " AND level = ?"
+		args = append(args, f.Level)
+	}
+	if f.Source != "" {
+		query += " AND source = ?"
+		args = append(args, f.Source)
+	}
+	query += " ORDER BY timestamp DESC, id DESC LIMIT ?"
+	args = append(args, f.Limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...) //nolint:gosec // query uses parameterized placeholders only
+	if err != nil {
+		return nil, fmt.Errorf("list device events: %w", err)
+	}
+	defer rows.Close()
+
+	var result []DeviceLogEvent
+	for rows.Next() {
+		var ev DeviceLogEvent
+		var varbinds string
+		if err := rows.Scan(
+			&ev.ID, &ev.DeviceID, &ev.Source, &ev.SourceIP, &ev.Facility, &ev.Severity, &ev.SeverityName,
+			&ev.Level, &ev.Hostname, &ev.AppName, &ev.Message, &ev.TrapOID, &varbinds, &ev.Timestamp, &ev.ReceivedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan device event row: %w", err)
+		}
+		if varbinds != "" {
+			_ = json.Unmarshal([]byte(varbinds), &ev.Varbinds)
+		}
+		result = append(result, ev)
+	}
+	return result, rows.Err()
+}
+
+// PruneDeviceLogEvents deletes events from source received before the cutoff
+// and returns the number of rows removed.
+func (s *ReconStore) PruneDeviceLogEvents(ctx context.Context, source string, before time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM recon_device_events WHERE source = ? AND received_at < ?`, source, before)
+	if err != nil {
+		return 0, fmt.Errorf("prune device events: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,134 @@
+package recon
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	_ "image/gif" // register GIF decoding for icon uploads
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+// Device icon limits. Uploads are decoded and re-encoded, which drops EXIF
+// and any other embedded metadata, and scaled down to fit within
+// maxIconDimension on both sides.
+const (
+	maxIconUploadBytes = 5 << 20
+	maxIconDimension   = 512
+	// maxIconSourcePixels rejects images whose header claims a pixel count
+	// that would need an outsized buffer to decode.
+	maxIconSourcePixels = 40_000_000
+)
+
+var errUnsupportedIcon = errors.New("icon must be a PNG, JPEG, or GIF image")
+
+// DeviceIcon is a processed, user-supplied device icon or photo.
+type DeviceIcon struct {
+	DeviceID    string
+	ContentType string
+	Data        []byte
+	Width       int
+	Height      int
+	UpdatedAt   time.Time
+}
+
+// deviceIconURL returns the API path serving a device's icon.
+func deviceIconURL(deviceID string) string {
+	return "/api/v1/recon/devices/" + deviceID + "/icon"
+}
+
+// processIcon validates an uploaded image, scales it down to fit within
+// maxIconDimension, and re-encodes it without metadata. JPEG uploads stay
+// JPEG; PNG and GIF (first frame) become PNG.
+func processIcon(data []byte) (*DeviceIcon, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errUnsupportedIcon
+	}
+	if format != "png" && format != "jpeg" && format != "gif" {
+		return nil, errUnsupportedIcon
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxIconSourcePixels {
+		return nil, fmt.Errorf("icon dimensions %dx%d are out of range", cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errUnsupportedIcon
+	}
+	img = fitWithin(img, maxIconDimension)
+
+	var buf bytes.Buffer
+	icon := &DeviceIcon{ContentType: "image/png"}
+	if format == "jpeg" {
+		icon.ContentType = "image/jpeg"
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encode icon: %w", err)
+	}
+	icon.Data = buf.Bytes()
+	icon.Width = img.Bounds().Dx()
+	icon.Height = img.Bounds().Dy()
+	return icon, nil
+}
+
+// fitWithin scales img down with a box filter so neither side exceeds
+// limit, preserving the aspect ratio. Smaller images are returned as-is.
+func fitWithin(img image.Image, limit int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= limit && h <= limit {
+		return img
+	}
+	nw, nh := limit, limit
+	if w > h {
+		nh = max(1, h*limit/w)
+	} else {
+		nw = max(1, w*limit/h)
+	}
+
+	dst := image.NewRGBA64(image.Rect(0, 0, nw, nh))
+	for y := 0; y < nh; y++ {
+		y0, y1 := b.Min.Y+y*h/nh, b.Min.Y+(y+1)*h/nh
+		for x := 0; x < nw; x++ {
+			x0, x1 := b.Min.X+x*w/nw, b.Min.X+(x+1)*w/nw
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.SetRGBA64(x, y, color.RGBA64{
+				R: uint16(r / n), G: uint16(g / n), B: uint16(bl / n), A: uint16(a / n),
+			})
+		}
+	}
+	return dst
+}
+
+// defaultIconSVG renders the fallback icon for a device without an upload:
+// a tile labelled with the device type. The Lucide icon name used by the
+// dashboard is carried in data-icon.
+func defaultIconSVG(dt models.DeviceType) []byte {
+	if dt == "" {
+		dt = models.DeviceTypeUnknown
+	}
+	label := html.EscapeString(strings.ReplaceAll(string(dt), "_", " "))
+	return []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="128" height="128" viewBox="0 0 128 128" data-icon="` +
+		html.EscapeString(dt.Icon()) + `">` +
+		`<rect x="4" y="4" width="120" height="120" rx="16" fill="#e2e8f0" stroke="#64748b" stroke-width="4"/>` +
+		`<text x="64" y="72" font-family="sans-serif" font-size="16" text-anchor="middle" fill="#334155">` +
+		label + `</text></svg>`)
+}
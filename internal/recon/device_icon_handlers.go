@@ -0,0 +1,183 @@
+package recon
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+	"go.uber.org/zap"
+)
+
+// DeviceIconResponse is the response for a device icon upload.
+type DeviceIconResponse struct {
+	DeviceID    string    `json:"device_id"`
+	URL         string    `json:"url" example:"/api/v1/recon/devices/550e8400-e29b-41d4-a716-446655440000/icon"`
+	ContentType string    `json:"content_type" example:"image/png"`
+	Width       int       `json:"width" example:"512"`
+	Height      int       `json:"height" example:"384"`
+	Size        int       `json:"size" example:"48213"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// lookupIconDevice loads the device an icon request targets. Returns nil
+// after writing an error response.
+func (m *Module) lookupIconDevice(w http.ResponseWriter, r *http.Request) *models.Device {
+	id := r.PathValue("id")
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "device ID is required")
+		return nil
+	}
+	device, err := m.store.GetDevice(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "device not found")
+		return nil
+	}
+	if err != nil {
+		m.logger.Error("failed to get device", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to get device")
+		return nil
+	}
+	return device
+}
+
+// handleUploadDeviceIcon stores a custom icon or photo for a device.
+//
+//	@Summary		Upload device icon
+//	@Description	Uploads a PNG, JPEG, or GIF (max 5 MB) as the device's icon. The image is re-encoded without EXIF or other metadata and scaled to fit within 512x512.
+//	@Tags			recon
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string	true	"Device ID"
+//	@Param			file	formData	file	true	"Image file"
+//	@Success		200		{object}	DeviceIconResponse
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		404		{object}	models.APIProblem
+//	@Failure		413		{object}	models.APIProblem
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/recon/devices/{id}/icon [post]
+func (m *Module) handleUploadDeviceIcon(w http.ResponseWriter, r *http.Request) {
+	device := m.lookupIconDevice(w, r)
+	if device == nil {
+		return
+	}
+
+	// Allow for multipart framing on top of the image itself.
+	r.Body = http.MaxBytesReader(w, r.Body, maxIconUploadBytes+64<<10)
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, "icon exceeds the 5 MB upload limit")
+			return
+		}
+		writeError(w, http.StatusBadRequest, "missing or invalid file field")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxIconUploadBytes+1))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read icon")
+		return
+	}
+	if len(data) > maxIconUploadBytes {
+		writeError(w, http.StatusRequestEntityTooLarge, "icon exceeds the 5 MB upload limit")
+		return
+	}
+
+	icon, err := processIcon(data)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	icon.DeviceID = device.ID
+	if err := m.store.SetDeviceIcon(r.Context(), icon); err != nil {
+		m.logger.Error("failed to store device icon", zap.String("id", device.ID), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to store icon")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, DeviceIconResponse{
+		DeviceID:    device.ID,
+		URL:         deviceIconURL(device.ID),
+		ContentType: icon.ContentType,
+		Width:       icon.Width,
+		Height:      icon.Height,
+		Size:        len(icon.Data),
+		UpdatedAt:   icon.UpdatedAt,
+	})
+}
+
+// handleGetDeviceIcon serves a device's icon, falling back to a default
+// for its device type when none has been uploaded.
+//
+//	@Summary		Get device icon
+//	@Description	Returns the device's uploaded icon, or an SVG default for its device type when none is set.
+//	@Tags			recon
+//	@Produce		png
+//	@Produce		jpeg
+//	@Produce		image/svg+xml
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Device ID"
+//	@Success		200	{file}		file	"Icon image"
+//	@Failure		404	{object}	models.APIProblem
+//	@Failure		500	{object}	models.APIProblem
+//	@Router			/recon/devices/{id}/icon [get]
+func (m *Module) handleGetDeviceIcon(w http.ResponseWriter, r *http.Request) {
+	device := m.lookupIconDevice(w, r)
+	if device == nil {
+		return
+	}
+
+	icon, err := m.store.GetDeviceIcon(r.Context(), device.ID)
+	if err != nil {
+		m.logger.Error("failed to get device icon", zap.String("id", device.ID), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to get icon")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if icon == nil {
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(defaultIconSVG(device.DeviceType))
+		return
+	}
+	w.Header().Set("Content-Type", icon.ContentType)
+	http.ServeContent(w, r, "", icon.UpdatedAt, bytes.NewReader(icon.Data))
+}
+
+// handleDeleteDeviceIcon removes a device's uploaded icon, restoring the
+// device-type default.
+//
+//	@Summary		Delete device icon
+//	@Description	Removes the device's uploaded icon so the device-type default is served again.
+//	@Tags			recon
+//	@Security		BearerAuth
+//	@Param			id	path	string	true	"Device ID"
+//	@Success		204	"No content"
+//	@Failure		404	{object}	models.APIProblem
+//	@Failure		500	{object}	models.APIProblem
+//	@Router			/recon/devices/{id}/icon [delete]
+func (m *Module) handleDeleteDeviceIcon(w http.ResponseWriter, r *http.Request) {
+	device := m.lookupIconDevice(w, r)
+	if device == nil {
+		return
+	}
+	err := m.store.DeleteDeviceIcon(r.Context(), device.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "device has no uploaded icon")
+		return
+	}
+	if err != nil {
+		m.logger.Error("failed to delete device icon", zap.String("id", device.ID), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to delete icon")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,79 @@
+package recon
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SetDeviceIcon stores a device's icon, replacing any existing one.
+func (s *ReconStore) SetDeviceIcon(ctx context.Context, icon *DeviceIcon) error {
+	icon.UpdatedAt = time.Now().UTC()
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO recon_device_icons (device_id, content_type, data, width, height, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(device_id) DO UPDATE SET
+			content_type = excluded.content_type,
+			data = excluded.data,
+			width = excluded.width,
+			height = excluded.height,
+			updated_at = excluded.updated_at`,
+		icon.DeviceID, icon.ContentType, icon.Data, icon.Width, icon.Height, icon.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("set device icon: %w", err)
+	}
+	return nil
+}
+
+// GetDeviceIcon returns a device's uploaded icon, or nil if it has none.
+func (s *ReconStore) GetDeviceIcon(ctx context.Context, deviceID string) (*DeviceIcon, error) {
+	icon := DeviceIcon{DeviceID: deviceID}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT content_type, data, width, height, updated_at
+		FROM recon_device_icons WHERE device_id = ?`, deviceID,
+	).Scan(&icon.ContentType, &icon.Data, &icon.Width, &icon.Height, &icon.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get device icon: %w", err)
+	}
+	return &icon, nil
+}
+
+// DeleteDeviceIcon removes a device's uploaded icon. Returns sql.ErrNoRows
+// if the device has none.
+func (s *ReconStore) DeleteDeviceIcon(ctx context.Context, deviceID string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM recon_device_icons WHERE device_id = ?`, deviceID)
+	if err != nil {
+		return fmt.Errorf("delete device icon: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// deviceIconVersions returns the update time of every uploaded icon keyed
+// by device ID, without loading the image data.
+func (s *ReconStore) deviceIconVersions(ctx context.Context) (map[string]time.Time, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT device_id, updated_at FROM recon_device_icons`)
+	if err != nil {
+		return nil, fmt.Errorf("list device icons: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make(map[string]time.Time)
+	for rows.Next() {
+		var id string
+		var updated time.Time
+		if err := rows.Scan(&id, &updated); err != nil {
+			return nil, fmt.Errorf("scan device icon row: %w", err)
+		}
+		versions[id] = updated
+	}
+	return versions, rows.Err()
+}
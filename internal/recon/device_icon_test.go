@@ -0,0 +1,178 @@
+package recon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+// testJPEGWithEXIF encodes a w x h JPEG and splices an EXIF APP1 segment
+// in after the SOI marker.
+func testJPEGWithEXIF(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("encode jpeg: %v", err)
+	}
+	payload := []byte("Exif\x00\x00GPS 51.5074N 0.1278W")
+	size := len(payload) + 2
+	app1 := append([]byte{0xFF, 0xE1, byte(size >> 8), byte(size)}, payload...)
+	raw := buf.Bytes()
+	return append(append(append([]byte{}, raw[:2]...), app1...), raw[2:]...)
+}
+
+func TestProcessIcon(t *testing.T) {
+	t.Run("strips exif and caps dimensions", func(t *testing.T) {
+		src := testJPEGWithEXIF(t, 1024, 768)
+		if !bytes.Contains(src, []byte("Exif")) {
+			t.Fatal("test image is missing its EXIF segment")
+		}
+
+		icon, err := processIcon(src)
+		if err != nil {
+			t.Fatalf("processIcon: %v", err)
+		}
+		if icon.ContentType != "image/jpeg" {
+			t.Errorf("ContentType = %q, want image/jpeg", icon.ContentType)
+		}
+		if icon.Width != maxIconDimension || icon.Height != 384 {
+			t.Errorf("size = %dx%d, want %dx384", icon.Width, icon.Height, maxIconDimension)
+		}
+		if bytes.Contains(icon.Data, []byte("Exif")) || bytes.Contains(icon.Data, []byte("GPS")) {
+			t.Error("processed icon still contains EXIF data")
+		}
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(icon.Data))
+		if err != nil || cfg.Width != icon.Width || cfg.Height != icon.Height {
+			t.Errorf("re-decoded config = %+v, %v; want %dx%d", cfg, err, icon.Width, icon.Height)
+		}
+	})
+
+	t.Run("small png kept as png", func(t *testing.T) {
+		var buf bytes.Buffer
+		_ = png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 64, 32)))
+		icon, err := processIcon(buf.Bytes())
+		if err != nil {
+			t.Fatalf("processIcon: %v", err)
+		}
+		if icon.ContentType != "image/png" || icon.Width != 64 || icon.Height != 32 {
+			t.Errorf("icon = %s %dx%d, want image/png 64x32", icon.ContentType, icon.Width, icon.Height)
+		}
+	})
+
+	t.Run("rejects non-images", func(t *testing.T) {
+		for _, data := range [][]byte{
+			[]byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`),
+			[]byte("not an image"),
+		} {
+			if _, err := processIcon(data); err == nil {
+				t.Errorf("processIcon(%q) succeeded, want error", data[:12])
+			}
+		}
+	})
+}
+
+func TestDeviceIconHandlers(t *testing.T) {
+	m := newTestModule(t)
+	ctx := context.Background()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /devices/{id}/icon", m.handleGetDeviceIcon)
+	mux.HandleFunc("POST /devices/{id}/icon", m.handleUploadDeviceIcon)
+	mux.HandleFunc("DELETE /devices/{id}/icon", m.handleDeleteDeviceIcon)
+	mux.HandleFunc("GET /topology", m.handleTopology)
+
+	d := &models.Device{
+		Hostname:        "nas",
+		IPAddresses:     []string{"10.0.0.8"},
+		MACAddress:      "AA:BB:CC:DD:EE:08",
+		DeviceType:      models.DeviceTypeNAS,
+		DiscoveryMethod: models.DiscoveryICMP,
+	}
+	if _, err := m.store.UpsertDevice(ctx, d); err != nil {
+		t.Fatalf("UpsertDevice: %v", err)
+	}
+
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "/devices/"+d.ID+"/icon", http.NoBody))
+		return w
+	}
+	topologyIconURL := func() string {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "/topology", http.NoBody))
+		var graph TopologyGraph
+		_ = json.NewDecoder(w.Body).Decode(&graph)
+		if len(graph.Nodes) != 1 {
+			t.Fatalf("topology nodes = %d, want 1", len(graph.Nodes))
+		}
+		return graph.Nodes[0].IconURL
+	}
+
+	// Default icon before any upload.
+	w := get()
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/svg+xml" || !strings.Contains(w.Body.String(), `data-icon="hard-drive"`) {
+		t.Fatalf("default icon = %d %q %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+	if url := topologyIconURL(); url != "" {
+		t.Errorf("topology icon_url = %q before upload, want empty", url)
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, _ := mw.CreateFormFile("file", "nas.jpg")
+	_, _ = fw.Write(testJPEGWithEXIF(t, 800, 800))
+	_ = mw.Close()
+	req := httptest.NewRequest("POST", "/devices/"+d.ID+"/icon", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("upload status = %d: %s", w.Code, w.Body.String())
+	}
+	var resp DeviceIconResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Width != maxIconDimension || resp.Height != maxIconDimension || resp.URL != deviceIconURL(d.ID) {
+		t.Errorf("upload response = %+v", resp)
+	}
+
+	w = get()
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/jpeg" || bytes.Contains(w.Body.Bytes(), []byte("Exif")) {
+		t.Errorf("uploaded icon = %d %q (EXIF present: %v)", w.Code, w.Header().Get("Content-Type"), bytes.Contains(w.Body.Bytes(), []byte("Exif")))
+	}
+	if url := topologyIconURL(); !strings.HasPrefix(url, deviceIconURL(d.ID)+"?v=") {
+		t.Errorf("topology icon_url = %q, want versioned icon URL", url)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("DELETE", "/devices/"+d.ID+"/icon", http.NoBody))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("delete status = %d", w.Code)
+	}
+	if w := get(); w.Header().Get("Content-Type") != "image/svg+xml" {
+		t.Errorf("after delete Content-Type = %q, want the SVG default", w.Header().Get("Content-Type"))
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/devices/missing/icon", http.NoBody))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown device status = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,205 @@
+package recon
+
+import (
+	"encoding/base64"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+	qrcode "github.com/skip2/go-qrcode"
+	"go.uber.org/zap"
+)
+
+// QR label sizes in pixels. A label sheet renders at most
+// maxLabelSheetDevices codes so a broad filter cannot build a huge page.
+const (
+	defaultQRSize        = 256
+	minQRSize            = 64
+	maxQRSize            = 1024
+	labelSheetQRSize     = 160
+	maxLabelSheetDevices = 200
+)
+
+// deviceDeepLink returns the dashboard URL of a device's detail page.
+func deviceDeepLink(baseURL, deviceID string) string {
+	return strings.TrimRight(baseURL, "/") + "/devices/" + url.PathEscape(deviceID)
+}
+
+// labelBaseURL returns the dashboard origin to encode in QR labels: the
+// configured label_base_url, or the scheme and host the request arrived on.
+func (m *Module) labelBaseURL(r *http.Request) string {
+	if m.cfg.LabelBaseURL != "" {
+		return m.cfg.LabelBaseURL
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if p := r.Header.Get("X-Forwarded-Proto"); p == "http" || p == "https" {
+		scheme = p
+	}
+	host := r.Header.Get("X-Forwarded-Host")
+	if host == "" {
+		host = r.Host
+	}
+	return scheme + "://" + host
+}
+
+// handleDeviceQR renders a QR code linking to a device's dashboard page.
+//
+//	@Summary		Device QR code
+//	@Description	Returns a PNG QR code encoding the device's dashboard URL, for printing asset labels. The URL base is recon.label_base_url, or the request host when unset.
+//	@Tags			recon
+//	@Produce		png
+//	@Security		BearerAuth
+//	@Param			id		path		string	true	"Device ID"
+//	@Param			size	query		int		false	"Image width and height in pixels (64-1024)"	default(256)
+//	@Success		200		{file}		file	"QR code PNG"
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		404		{object}	models.APIProblem
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/recon/devices/{id}/qr.png [get]
+func (m *Module) handleDeviceQR(w http.ResponseWriter, r *http.Request) {
+	size := defaultQRSize
+	if v := r.URL.Query().Get("size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < minQRSize || n > maxQRSize {
+			writeError(w, http.StatusBadRequest, "size must be between 64 and 1024")
+			return
+		}
+		size = n
+	}
+
+	device := m.lookupIconDevice(w, r)
+	if device == nil {
+		return
+	}
+
+	png, err := qrcode.Encode(deviceDeepLink(m.labelBaseURL(r), device.ID), qrcode.Medium, size)
+	if err != nil {
+		m.logger.Error("failed to encode device QR code", zap.String("id", device.ID), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to render QR code")
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(png)
+}
+
+// deviceLabel is one entry on a printable label sheet.
+type deviceLabel struct {
+	Name    string
+	IP      string
+	Type    string
+	Link    string
+	QRImage template.URL
+}
+
+var labelSheetTmpl = template.Must(template.New("labels").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>SubNetree device labels</title>
+<style>
+body { font-family: sans-serif; margin: 0; }
+.sheet { display: flex; flex-wrap: wrap; gap: 4mm; padding: 8mm; }
+.label { width: 60mm; border: 1px dashed #94a3b8; padding: 3mm; text-align: center; break-inside: avoid; }
+.label img { width: 40mm; height: 40mm; }
+.name { font-weight: bold; overflow-wrap: anywhere; }
+.meta { font-size: 9pt; color: #475569; }
+.link { font-size: 6pt; color: #64748b; overflow-wrap: anywhere; }
+@media print { .label { border-color: #cbd5e1; } }
+</style>
+</head>
+<body>
+<div class="sheet">
+{{- range .}}
+<div class="label">
+<img src="{{.QRImage}}" alt="QR code for {{.Name}}">
+<div class="name">{{.Name}}</div>
+<div class="meta">{{.IP}}{{if .Type}} &middot; {{.Type}}{{end}}</div>
+<div class="link">{{.Link}}</div>
+</div>
+{{- end}}
+</div>
+</body>
+</html>
+`))
+
+// handleDeviceLabelSheet renders a printable HTML sheet of QR labels for
+// the devices matching a filter.
+//
+//	@Summary		Device label sheet
+//	@Description	Returns a printable HTML page with a QR label for each matching device (at most 200). Accepts the same filters as the device list.
+//	@Tags			recon
+//	@Produce		html
+//	@Security		BearerAuth
+//	@Param			status		query		string	false	"Filter by status"
+//	@Param			type		query		string	false	"Filter by device type"
+//	@Param			category	query		string	false	"Filter by category"
+//	@Param			owner		query		string	false	"Filter by owner"
+//	@Param			location	query		string	false	"Filter by location"
+//	@Param			q			query		string	false	"Search hostname, IP, or MAC"
+//	@Success		200			{string}	string	"HTML label sheet"
+//	@Failure		500			{object}	models.APIProblem
+//	@Router			/recon/devices/labels [get]
+func (m *Module) handleDeviceLabelSheet(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	devices, _, err := m.store.ListDevices(r.Context(), ListDevicesOptions{
+		Limit:      maxLabelSheetDevices,
+		Status:     q.Get("status"),
+		DeviceType: q.Get("type"),
+		Category:   q.Get("category"),
+		Owner:      q.Get("owner"),
+		Location:   q.Get("location"),
+		Search:     q.Get("q"),
+	})
+	if err != nil {
+		m.logger.Error("failed to list devices for labels", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to list devices")
+		return
+	}
+
+	base := m.labelBaseURL(r)
+	labels := make([]deviceLabel, 0, len(devices))
+	for i := range devices {
+		d := &devices[i]
+		link := deviceDeepLink(base, d.ID)
+		png, err := qrcode.Encode(link, qrcode.Medium, labelSheetQRSize)
+		if err != nil {
+			m.logger.Error("failed to encode device QR code", zap.String("id", d.ID), zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "failed to render QR code")
+			return
+		}
+		labels = append(labels, deviceLabel{
+			Name:    labelName(d),
+			IP:      strings.Join(d.IPAddresses, ", "),
+			Type:    string(d.DeviceType),
+			Link:    link,
+			QRImage: template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(png)),
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if err := labelSheetTmpl.Execute(w, labels); err != nil {
+		m.logger.Error("failed to render label sheet", zap.Error(err))
+	}
+}
+
+// labelName picks the most readable name for a device label.
+func labelName(d *models.Device) string {
+	switch {
+	case d.Hostname != "":
+		return d.Hostname
+	case len(d.IPAddresses) > 0:
+		return d.IPAddresses[0]
+	case d.MACAddress != "":
+		return d.MACAddress
+	}
+	return d.ID
+}
@@ -0,0 +1,132 @@
+package recon
+
+import (
+	"context"
+	"crypto/tls"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+func TestLabelBaseURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		tls        bool
+		headers    map[string]string
+		want       string
+	}{
+		{name: "request host", want: "http://subnetree.lan:8080"},
+		{name: "tls", tls: true, want: "https://subnetree.lan:8080"},
+		{
+			name:    "forwarded",
+			headers: map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "nms.example.com"},
+			want:    "https://nms.example.com",
+		},
+		{
+			name:       "configured wins",
+			configured: "https://labels.example.com",
+			headers:    map[string]string{"X-Forwarded-Host": "nms.example.com"},
+			want:       "https://labels.example.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Module{cfg: ReconConfig{LabelBaseURL: tt.configured}}
+			r := httptest.NewRequest(http.MethodGet, "http://subnetree.lan:8080/api/v1/recon/devices/x/qr.png", http.NoBody)
+			if tt.tls {
+				r.TLS = &tls.ConnectionState{}
+			}
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			if got := m.labelBaseURL(r); got != tt.want {
+				t.Errorf("labelBaseURL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got, want := deviceDeepLink("https://nms.example.com/", "abc-123"), "https://nms.example.com/devices/abc-123"; got != want {
+		t.Errorf("deviceDeepLink = %q, want %q", got, want)
+	}
+}
+
+func TestDeviceQRHandlers(t *testing.T) {
+	m := newTestModule(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /devices/{id}/qr.png", m.handleDeviceQR)
+	mux.HandleFunc("GET /devices/labels", m.handleDeviceLabelSheet)
+
+	d := &models.Device{
+		Hostname:        "core-switch",
+		IPAddresses:     []string{"10.0.0.2"},
+		MACAddress:      "AA:BB:CC:DD:EE:02",
+		DeviceType:      models.DeviceTypeSwitch,
+		DiscoveryMethod: models.DiscoveryICMP,
+	}
+	if _, err := m.store.UpsertDevice(context.Background(), d); err != nil {
+		t.Fatalf("UpsertDevice: %v", err)
+	}
+
+	t.Run("png", func(t *testing.T) {
+		for _, tc := range []struct {
+			query string
+			size  int
+		}{
+			{"", defaultQRSize},
+			{"?size=512", 512},
+		} {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/devices/"+d.ID+"/qr.png"+tc.query, http.NoBody))
+			if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" {
+				t.Fatalf("qr%s = %d %q: %s", tc.query, w.Code, w.Header().Get("Content-Type"), w.Body.String())
+			}
+			cfg, err := png.DecodeConfig(w.Body)
+			if err != nil {
+				t.Fatalf("decode qr%s: %v", tc.query, err)
+			}
+			if cfg.Width != tc.size || cfg.Height != tc.size {
+				t.Errorf("qr%s size = %dx%d, want %d", tc.query, cfg.Width, cfg.Height, tc.size)
+			}
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for target, want := range map[string]int{
+			"/devices/" + d.ID + "/qr.png?size=10":   http.StatusBadRequest,
+			"/devices/" + d.ID + "/qr.png?size=4096": http.StatusBadRequest,
+			"/devices/" + d.ID + "/qr.png?size=big":  http.StatusBadRequest,
+			"/devices/missing/qr.png":                http.StatusNotFound,
+		} {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, http.NoBody))
+			if w.Code != want {
+				t.Errorf("GET %s = %d, want %d", target, w.Code, want)
+			}
+		}
+	})
+
+	t.Run("label sheet", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://subnetree.lan/devices/labels?type=switch", http.NoBody))
+		body := w.Body.String()
+		if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+			t.Fatalf("label sheet = %d %q", w.Code, w.Header().Get("Content-Type"))
+		}
+		for _, want := range []string{"core-switch", "http://subnetree.lan/devices/" + d.ID, `src="data:image/png;base64,`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("label sheet missing %q", want)
+			}
+		}
+
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/devices/labels?type=router", http.NoBody))
+		if strings.Contains(w.Body.String(), "core-switch") {
+			t.Error("label sheet ignored the type filter")
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package recon
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+// SaveDevicePortScan records the result of probing ports on a device. Every
+// port in open is upserted with seenAt as its last_seen time; ports that were
+// probed but found closed are removed. Ports not probed are left untouched,
+// so a narrow scan does not erase what a wider one found.
+func (s *ReconStore) SaveDevicePortScan(ctx context.Context, deviceID, protocol string, probed, open []int, seenAt time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
+
+	isOpen := make(map[int]bool, len(open))
+	for _, port := range open {
+		isOpen[port] = true
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO recon_device_ports (device_id, port, protocol, service, last_seen)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(device_id, port, protocol) DO UPDATE SET
+				service = excluded.service,
+				last_seen = excluded.last_seen`,
+			deviceID, port, protocol, lookupServiceName(port), seenAt.UTC())
+		if err != nil {
+			return fmt.Errorf("upsert device port %d: %w", port, err)
+		}
+	}
+
+	for _, port := range probed {
+		if isOpen[port] {
+			continue
+		}
+		_, err := tx.ExecContext(ctx,
+			`DELETE FROM recon_device_ports WHERE device_id = ? AND port = ? AND protocol = ?`,
+			deviceID, port, protocol)
+		if err != nil {
+			return fmt.Errorf("delete device port %d: %w", port, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetDevicePorts returns the open ports recorded for a device, ordered by
+// port number.
+func (s *ReconStore) GetDevicePorts(ctx context.Context, deviceID string) ([]models.DevicePort, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT device_id, port, protocol, service, last_seen
+		FROM recon_device_ports
+		WHERE device_id = ?
+		ORDER BY port, protocol`, deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("get device ports: %w", err)
+	}
+	defer rows.Close()
+
+	var ports []models.DevicePort
+	for rows.Next() {
+		var p models.DevicePort
+		if err := rows.Scan(&p.DeviceID, &p.Port, &p.Protocol, &p.Service, &p.LastSeen); err != nil {
+			return nil, fmt.Errorf("scan device port row: %w", err)
+		}
+		ports = append(ports, p)
+	}
+	return ports, rows.Err()
+}
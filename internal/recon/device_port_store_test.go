@@ -0,0 +1,91 @@
+package recon
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+func seedPortDevice(t *testing.T, s *ReconStore, ip, mac string) *models.Device {
+	t.Helper()
+	device := &models.Device{
+		IPAddresses:     []string{ip},
+		MACAddress:      mac,
+		Status:          models.DeviceStatusOnline,
+		DiscoveryMethod: models.DiscoveryICMP,
+	}
+	if _, err := s.UpsertDevice(context.Background(), device); err != nil {
+		t.Fatalf("create device: %v", err)
+	}
+	return device
+}
+
+func TestSaveDevicePortScan(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+	device := seedPortDevice(t, s, "10.0.0.40", "AA:BB:CC:DD:40:01")
+
+	first := time.Now().Add(-time.Hour)
+	if err := s.SaveDevicePortScan(ctx, device.ID, "tcp", []int{22, 80, 443}, []int{22, 443}, first); err != nil {
+		t.Fatalf("first SaveDevicePortScan: %v", err)
+	}
+	// A narrower rescan closes 443 and leaves 22 alone since it was not probed.
+	if err := s.SaveDevicePortScan(ctx, device.ID, "tcp", []int{80, 443}, []int{80}, time.Now()); err != nil {
+		t.Fatalf("second SaveDevicePortScan: %v", err)
+	}
+
+	got, err := s.GetDevicePorts(ctx, device.ID)
+	if err != nil {
+		t.Fatalf("GetDevicePorts: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("port count = %d, want 2: %+v", len(got), got)
+	}
+	if got[0].Port != 22 || got[1].Port != 80 {
+		t.Errorf("ports = %d, %d; want 22, 80", got[0].Port, got[1].Port)
+	}
+	if got[0].Service != "ssh" || got[0].Protocol != "tcp" {
+		t.Errorf("port 22 service/protocol = %q/%q, want ssh/tcp", got[0].Service, got[0].Protocol)
+	}
+	if !got[1].LastSeen.After(got[0].LastSeen) {
+		t.Errorf("port 80 last_seen %v should be after port 22 last_seen %v", got[1].LastSeen, got[0].LastSeen)
+	}
+}
+
+func TestListDevices_OpenPort(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+	smb := seedPortDevice(t, s, "10.0.0.41", "AA:BB:CC:DD:41:01")
+	web := seedPortDevice(t, s, "10.0.0.42", "AA:BB:CC:DD:42:01")
+
+	if err := s.SaveDevicePortScan(ctx, smb.ID, "tcp", []int{445}, []int{445}, time.Now()); err != nil {
+		t.Fatalf("SaveDevicePortScan: %v", err)
+	}
+	if err := s.SaveDevicePortScan(ctx, web.ID, "tcp", []int{80}, []int{80}, time.Now()); err != nil {
+		t.Fatalf("SaveDevicePortScan: %v", err)
+	}
+
+	devices, total, err := s.ListDevices(ctx, ListDevicesOptions{OpenPort: 445})
+	if err != nil {
+		t.Fatalf("ListDevices: %v", err)
+	}
+	if total != 1 || len(devices) != 1 || devices[0].ID != smb.ID {
+		t.Errorf("ListDevices(open_port=445) = %d devices (total %d), want only %s", len(devices), total, smb.ID)
+	}
+}
+
+func TestHandleListDevices_InvalidOpenPort(t *testing.T) {
+	m := newTestModule(t)
+
+	for _, v := range []string{"abc", "0", "70000"} {
+		w := httptest.NewRecorder()
+		m.handleListDevices(w, httptest.NewRequest("GET", "/devices?open_port="+v, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("open_port=%s: status = %d, want 400", v, w.Code)
+		}
+	}
+}
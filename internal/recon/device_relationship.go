@@ -0,0 +1,62 @@
+package recon
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Common device relationship types. Any lowercase snake_case type is
+// accepted; these are the ones the dashboard offers.
+const (
+	RelationshipPoweredBy = "powered_by"
+	RelationshipBacksUpTo = "backs_up_to"
+	RelationshipDependsOn = "depends_on"
+	RelationshipHostedOn  = "hosted_on"
+	RelationshipManagedBy = "managed_by"
+)
+
+// maxRelationshipNoteLen caps the free-text note on a relationship.
+const maxRelationshipNoteLen = 1000
+
+var relationshipTypePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// ErrRelationshipExists is returned when a relationship of the same type
+// already links the same two devices in the same direction.
+var ErrRelationshipExists = errors.New("relationship already exists")
+
+// DeviceRelationship is a typed, directed edge between two devices that is
+// not network parenthood, such as "server powered_by pdu" or "nas
+// backs_up_to offsite". The parent_device_id hierarchy stays the source of
+// truth for topology; relationships sit alongside it.
+type DeviceRelationship struct {
+	ID           string `json:"id"`
+	FromDeviceID string `json:"from_device_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ToDeviceID   string `json:"to_device_id" example:"660f9500-f30c-52e5-b827-557766551111"`
+	Type         string `json:"type" example:"powered_by"`
+	Note         string `json:"note,omitempty" example:"PDU outlet 4"`
+	// FromHostname and ToHostname are filled in on reads and ignored on writes.
+	FromHostname string    `json:"from_hostname,omitempty" example:"web-server-01"`
+	ToHostname   string    `json:"to_hostname,omitempty" example:"rack-pdu"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Validate checks that the relationship links two different devices with a
+// snake_case type and a note of reasonable length.
+func (r *DeviceRelationship) Validate() error {
+	if r.FromDeviceID == "" || r.ToDeviceID == "" {
+		return fmt.Errorf("from_device_id and to_device_id are required")
+	}
+	if r.FromDeviceID == r.ToDeviceID {
+		return fmt.Errorf("a device cannot have a relationship with itself")
+	}
+	if !relationshipTypePattern.MatchString(r.Type) {
+		return fmt.Errorf("type must be lowercase snake_case, at most 64 characters")
+	}
+	if len(r.Note) > maxRelationshipNoteLen {
+		return fmt.Errorf("note must be at most %d characters", maxRelationshipNoteLen)
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package recon
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// handleListDeviceRelationships returns all typed device relationships.
+//
+//	@Summary		List device relationships
+//	@Description	Returns all typed relationships between devices, such as powered_by or backs_up_to. Relationships of devices in the trash are omitted.
+//	@Tags			recon
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			type	query		string	false	"Only relationships of this type"
+//	@Success		200		{array}		DeviceRelationship
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/recon/relationships [get]
+func (m *Module) handleListDeviceRelationships(w http.ResponseWriter, r *http.Request) {
+	rels, err := m.store.ListAllDeviceRelationships(r.Context(), r.URL.Query().Get("type"))
+	if err != nil {
+		m.logger.Error("failed to list device relationships", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to list relationships")
+		return
+	}
+	if rels == nil {
+		rels = []DeviceRelationship{}
+	}
+	writeJSON(w, http.StatusOK, rels)
+}
+
+// handleGetDeviceRelationshipsForDevice returns the relationships a device
+// is on either end of.
+//
+//	@Summary		List a device's relationships
+//	@Description	Returns the typed relationships where the device is either the source or the target.
+//	@Tags			recon
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Device ID"
+//	@Success		200	{array}		DeviceRelationship
+//	@Failure		404	{object}	models.APIProblem
+//	@Failure		500	{object}	models.APIProblem
+//	@Router			/recon/devices/{id}/relationships [get]
+func (m *Module) handleGetDeviceRelationshipsForDevice(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := m.store.GetDevice(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "device not found")
+			return
+		}
+		m.logger.Error("failed to get device", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to list relationships")
+		return
+	}
+
+	rels, err := m.store.ListDeviceRelationships(r.Context(), id)
+	if err != nil {
+		m.logger.Error("failed to list device relationships", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to list relationships")
+		return
+	}
+	if rels == nil {
+		rels = []DeviceRelationship{}
+	}
+	writeJSON(w, http.StatusOK, rels)
+}
+
+// handleCreateDeviceRelationship records a typed relationship between two
+// devices.
+//
+//	@Summary		Create device relationship
+//	@Description	Records a directed, typed relationship from one device to another, e.g. {"from_device_id": server, "to_device_id": pdu, "type": "powered_by"}. Types are lowercase snake_case.
+//	@Tags			recon
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		DeviceRelationship	true	"Relationship to create"
+//	@Success		201		{object}	DeviceRelationship
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		409		{object}	models.APIProblem
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/recon/relationships [post]
+func (m *Module) handleCreateDeviceRelationship(w http.ResponseWriter, r *http.Request) {
+	var rel DeviceRelationship
+	if err := json.NewDecoder(r.Body).Decode(&rel); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	rel.ID = ""
+	if err := rel.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, deviceID := range []string{rel.FromDeviceID, rel.ToDeviceID} {
+		if _, err := m.store.GetDevice(r.Context(), deviceID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				writeError(w, http.StatusBadRequest, "device "+deviceID+" not found")
+				return
+			}
+			m.logger.Error("failed to get device", zap.String("id", deviceID), zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "failed to create relationship")
+			return
+		}
+	}
+
+	if err := m.store.CreateDeviceRelationship(r.Context(), &rel); err != nil {
+		if errors.Is(err, ErrRelationshipExists) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		m.logger.Error("failed to create device relationship", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to create relationship")
+		return
+	}
+	created, err := m.store.GetDeviceRelationship(r.Context(), rel.ID)
+	if err != nil {
+		m.logger.Error("failed to reload device relationship", zap.String("id", rel.ID), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to create relationship")
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// handleGetDeviceRelationship returns a single relationship.
+//
+//	@Summary		Get device relationship
+//	@Description	Returns a typed device relationship by ID.
+//	@Tags			recon
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Relationship ID"
+//	@Success		200	{object}	DeviceRelationship
+//	@Failure		404	{object}	models.APIProblem
+//	@Failure		500	{object}	models.APIProblem
+//	@Router			/recon/relationships/{id} [get]
+func (m *Module) handleGetDeviceRelationship(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	rel, err := m.store.GetDeviceRelationship(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "relationship not found")
+			return
+		}
+		m.logger.Error("failed to get device relationship", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to get relationship")
+		return
+	}
+	writeJSON(w, http.StatusOK, rel)
+}
+
+// handleUpdateDeviceRelationship changes the type or note of a
+// relationship. Fields omitted from the body keep their current values.
+//
+//	@Summary		Update device relationship
+//	@Description	Updates the type or note of a device relationship. The devices it links cannot change; delete it and create a new one instead.
+//	@Tags			recon
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string				true	"Relationship ID"
+//	@Param			request	body		DeviceRelationship	true	"Fields to update"
+//	@Success		200		{object}	DeviceRelationship
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		404		{object}	models.APIProblem
+//	@Failure		409		{object}	models.APIProblem
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/recon/relationships/{id} [put]
+func (m *Module) handleUpdateDeviceRelationship(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	rel, err := m.store.GetDeviceRelationship(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "relationship not found")
+			return
+		}
+		m.logger.Error("failed to get device relationship", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to update relationship")
+		return
+	}
+
+	from, to := rel.FromDeviceID, rel.ToDeviceID
+	if err := json.NewDecoder(r.Body).Decode(rel); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	rel.ID, rel.FromDeviceID, rel.ToDeviceID = id, from, to
+	if err := rel.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := m.store.UpdateDeviceRelationship(r.Context(), rel); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			writeError(w, http.StatusNotFound, "relationship not found")
+		case errors.Is(err, ErrRelationshipExists):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			m.logger.Error("failed to update device relationship", zap.String("id", id), zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "failed to update relationship")
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, rel)
+}
+
+// handleDeleteDeviceRelationship removes a relationship.
+//
+//	@Summary		Delete device relationship
+//	@Description	Deletes a typed device relationship. The devices themselves are not affected.
+//	@Tags			recon
+//	@Security		BearerAuth
+//	@Param			id	path	string	true	"Relationship ID"
+//	@Success		204	"No content"
+//	@Failure		404	{object}	models.APIProblem
+//	@Failure		500	{object}	models.APIProblem
+//	@Router			/recon/relationships/{id} [delete]
+func (m *Module) handleDeleteDeviceRelationship(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := m.store.DeleteDeviceRelationship(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "relationship not found")
+			return
+		}
+		m.logger.Error("failed to delete device relationship", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to delete relationship")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,154 @@
+package recon
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// deviceRelationshipSelect reads relationships whose devices are both live.
+// Relationships of a device in the trash reappear when it is restored and
+// are removed with it when it is purged.
+const deviceRelationshipSelect = `SELECT
+	r.id, r.from_device_id, r.to_device_id, r.type, r.note,
+	f.hostname, t.hostname, r.created_at, r.updated_at
+	FROM recon_device_relationships r
+	JOIN recon_devices f ON f.id = r.from_device_id AND f.deleted_at IS NULL
+	JOIN recon_devices t ON t.id = r.to_device_id AND t.deleted_at IS NULL`
+
+// CreateDeviceRelationship inserts a relationship. Both devices must exist.
+// Returns ErrRelationshipExists if the same typed edge is already recorded.
+func (s *ReconStore) CreateDeviceRelationship(ctx context.Context, rel *DeviceRelationship) error {
+	if err := s.checkRelationshipUnique(ctx, rel); err != nil {
+		return err
+	}
+	if rel.ID == "" {
+		rel.ID = uuid.New().String()
+	}
+	now := time.Now().UTC()
+	rel.CreatedAt = now
+	rel.UpdatedAt = now
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO recon_device_relationships
+			(id, from_device_id, to_device_id, type, note, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		rel.ID, rel.FromDeviceID, rel.ToDeviceID, rel.Type, rel.Note, rel.CreatedAt, rel.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("create device relationship: %w", err)
+	}
+	return nil
+}
+
+// GetDeviceRelationship returns a relationship by ID. Returns sql.ErrNoRows
+// if it does not exist or either device is in the trash.
+func (s *ReconStore) GetDeviceRelationship(ctx context.Context, id string) (*DeviceRelationship, error) {
+	return scanDeviceRelationship(s.db.QueryRowContext(ctx, deviceRelationshipSelect+` WHERE r.id = ?`, id))
+}
+
+// ListDeviceRelationships returns every relationship the device is on
+// either end of, oldest first.
+func (s *ReconStore) ListDeviceRelationships(ctx context.Context, deviceID string) ([]DeviceRelationship, error) {
+	return s.queryDeviceRelationships(ctx,
+		deviceRelationshipSelect+` WHERE r.from_device_id = ? OR r.to_device_id = ?
+		ORDER BY r.created_at ASC, r.id ASC`, deviceID, deviceID)
+}
+
+// ListAllDeviceRelationships returns all relationships, optionally only
+// those of relType, oldest first.
+func (s *ReconStore) ListAllDeviceRelationships(ctx context.Context, relType string) ([]DeviceRelationship, error) {
+	if relType != "" {
+		return s.queryDeviceRelationships(ctx,
+			deviceRelationshipSelect+` WHERE r.type = ? ORDER BY r.created_at ASC, r.id ASC`, relType)
+	}
+	return s.queryDeviceRelationships(ctx, deviceRelationshipSelect+` ORDER BY r.created_at ASC, r.id ASC`)
+}
+
+// UpdateDeviceRelationship changes the type and note of a relationship. The
+// devices it links cannot change. Returns sql.ErrNoRows if it does not
+// exist and ErrRelationshipExists if the new type duplicates another edge.
+func (s *ReconStore) UpdateDeviceRelationship(ctx context.Context, rel *DeviceRelationship) error {
+	if err := s.checkRelationshipUnique(ctx, rel); err != nil {
+		return err
+	}
+	rel.UpdatedAt = time.Now().UTC()
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE recon_device_relationships SET type = ?, note = ?, updated_at = ?
+		WHERE id = ?`,
+		rel.Type, rel.Note, rel.UpdatedAt, rel.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update device relationship: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteDeviceRelationship removes a relationship. Returns sql.ErrNoRows if
+// it does not exist.
+func (s *ReconStore) DeleteDeviceRelationship(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM recon_device_relationships WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete device relationship: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// checkRelationshipUnique returns ErrRelationshipExists if another
+// relationship already has rel's endpoints and type.
+func (s *ReconStore) checkRelationshipUnique(ctx context.Context, rel *DeviceRelationship) error {
+	var id string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id FROM recon_device_relationships
+		WHERE from_device_id = ? AND to_device_id = ? AND type = ? AND id != ?`,
+		rel.FromDeviceID, rel.ToDeviceID, rel.Type, rel.ID,
+	).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil
+	case err != nil:
+		return fmt.Errorf("check device relationship: %w", err)
+	default:
+		return ErrRelationshipExists
+	}
+}
+
+func (s *ReconStore) queryDeviceRelationships(ctx context.Context, query string, args ...any) ([]DeviceRelationship, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list device relationships: %w", err)
+	}
+	defer rows.Close()
+
+	var rels []DeviceRelationship
+	for rows.Next() {
+		rel, err := scanDeviceRelationship(rows)
+		if err != nil {
+			return nil, err
+		}
+		rels = append(rels, *rel)
+	}
+	return rels, rows.Err()
+}
+
+// scanDeviceRelationship scans a row selected with deviceRelationshipSelect.
+func scanDeviceRelationship(row interface{ Scan(...any) error }) (*DeviceRelationship, error) {
+	var rel DeviceRelationship
+	err := row.Scan(&rel.ID, &rel.FromDeviceID, &rel.ToDeviceID, &rel.Type, &rel.Note,
+		&rel.FromHostname, &rel.ToHostname, &rel.CreatedAt, &rel.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scan device relationship: %w", err)
+	}
+	return &rel, nil
+}
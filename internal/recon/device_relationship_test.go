@@ -0,0 +1,219 @@
+package recon
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+func TestDeviceRelationship_Validate(t *testing.T) {
+	valid := DeviceRelationship{FromDeviceID: "a", ToDeviceID: "b", Type: RelationshipPoweredBy}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("valid relationship: %v", err)
+	}
+
+	for name, mutate := range map[string]func(*DeviceRelationship){
+		"missing from":   func(r *DeviceRelationship) { r.FromDeviceID = "" },
+		"self loop":      func(r *DeviceRelationship) { r.ToDeviceID = r.FromDeviceID },
+		"empty type":     func(r *DeviceRelationship) { r.Type = "" },
+		"spaced type":    func(r *DeviceRelationship) { r.Type = "powered by" },
+		"uppercase type": func(r *DeviceRelationship) { r.Type = "PoweredBy" },
+		"long note":      func(r *DeviceRelationship) { r.Note = strings.Repeat("x", maxRelationshipNoteLen+1) },
+	} {
+		rel := valid
+		mutate(&rel)
+		if err := rel.Validate(); err == nil {
+			t.Errorf("%s: Validate succeeded, want error", name)
+		}
+	}
+}
+
+// addRelationshipDevices stores n devices named host-1..host-n.
+func addRelationshipDevices(t *testing.T, s *ReconStore, n int) []*models.Device {
+	t.Helper()
+	devices := make([]*models.Device, n)
+	for i := range devices {
+		devices[i] = &models.Device{
+			Hostname: fmt.Sprintf("host-%d", i+1), IPAddresses: []string{fmt.Sprintf("10.0.1.%d", i+1)},
+			MACAddress: fmt.Sprintf("AA:BB:CC:00:40:%02X", i+1), Status: models.DeviceStatusOnline,
+			DiscoveryMethod: models.DiscoveryICMP,
+		}
+		if _, err := s.UpsertDevice(context.Background(), devices[i]); err != nil {
+			t.Fatalf("UpsertDevice: %v", err)
+		}
+	}
+	return devices
+}
+
+func TestDeviceRelationshipStore(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+	d := addRelationshipDevices(t, s, 3)
+	server, pdu, nas := d[0], d[1], d[2]
+
+	power := &DeviceRelationship{FromDeviceID: server.ID, ToDeviceID: pdu.ID, Type: RelationshipPoweredBy, Note: "outlet 4"}
+	if err := s.CreateDeviceRelationship(ctx, power); err != nil {
+		t.Fatalf("CreateDeviceRelationship: %v", err)
+	}
+	backup := &DeviceRelationship{FromDeviceID: server.ID, ToDeviceID: nas.ID, Type: RelationshipBacksUpTo}
+	if err := s.CreateDeviceRelationship(ctx, backup); err != nil {
+		t.Fatalf("CreateDeviceRelationship: %v", err)
+	}
+
+	dup := &DeviceRelationship{FromDeviceID: server.ID, ToDeviceID: pdu.ID, Type: RelationshipPoweredBy}
+	if err := s.CreateDeviceRelationship(ctx, dup); !errors.Is(err, ErrRelationshipExists) {
+		t.Errorf("duplicate create err = %v, want ErrRelationshipExists", err)
+	}
+
+	got, err := s.GetDeviceRelationship(ctx, power.ID)
+	if err != nil {
+		t.Fatalf("GetDeviceRelationship: %v", err)
+	}
+	if got.FromHostname != "host-1" || got.ToHostname != "host-2" || got.Note != "outlet 4" {
+		t.Errorf("relationship = %+v", got)
+	}
+
+	if rels, _ := s.ListDeviceRelationships(ctx, server.ID); len(rels) != 2 {
+		t.Errorf("server relationships = %d, want 2", len(rels))
+	}
+	if rels, _ := s.ListDeviceRelationships(ctx, pdu.ID); len(rels) != 1 || rels[0].ID != power.ID {
+		t.Errorf("pdu relationships = %+v, want the incoming power edge", rels)
+	}
+	if rels, _ := s.ListAllDeviceRelationships(ctx, RelationshipBacksUpTo); len(rels) != 1 || rels[0].ID != backup.ID {
+		t.Errorf("backs_up_to relationships = %+v", rels)
+	}
+
+	// Retyping into an existing edge conflicts.
+	backup.Type = RelationshipPoweredBy
+	backup.ToDeviceID = pdu.ID
+	if err := s.UpdateDeviceRelationship(ctx, backup); !errors.Is(err, ErrRelationshipExists) {
+		t.Errorf("conflicting update err = %v, want ErrRelationshipExists", err)
+	}
+	backup.Type, backup.ToDeviceID, backup.Note = RelationshipDependsOn, nas.ID, "nightly"
+	if err := s.UpdateDeviceRelationship(ctx, backup); err != nil {
+		t.Fatalf("UpdateDeviceRelationship: %v", err)
+	}
+	if got, _ := s.GetDeviceRelationship(ctx, backup.ID); got.Type != RelationshipDependsOn || got.Note != "nightly" {
+		t.Errorf("updated relationship = %+v", got)
+	}
+
+	// Trashed devices hide their relationships until restored.
+	if err := s.DeleteDevice(ctx, pdu.ID); err != nil {
+		t.Fatalf("DeleteDevice: %v", err)
+	}
+	if rels, _ := s.ListDeviceRelationships(ctx, server.ID); len(rels) != 1 {
+		t.Errorf("relationships with pdu trashed = %d, want 1", len(rels))
+	}
+	if err := s.RestoreDevice(ctx, pdu.ID); err != nil {
+		t.Fatalf("RestoreDevice: %v", err)
+	}
+	if rels, _ := s.ListDeviceRelationships(ctx, server.ID); len(rels) != 2 {
+		t.Errorf("relationships after restore = %d, want 2", len(rels))
+	}
+
+	// Purging a device removes its relationships.
+	if err := s.PurgeDevice(ctx, nas.ID); err != nil {
+		t.Fatalf("PurgeDevice: %v", err)
+	}
+	if err := s.DeleteDeviceRelationship(ctx, backup.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("delete after purge err = %v, want sql.ErrNoRows", err)
+	}
+
+	if err := s.DeleteDeviceRelationship(ctx, power.ID); err != nil {
+		t.Fatalf("DeleteDeviceRelationship: %v", err)
+	}
+	if _, err := s.GetDeviceRelationship(ctx, power.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("get after delete err = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestDeviceRelationshipHandlers(t *testing.T) {
+	m := newTestModule(t)
+	d := addRelationshipDevices(t, m.store, 2)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /relationships", m.handleCreateDeviceRelationship)
+	mux.HandleFunc("GET /relationships/{id}", m.handleGetDeviceRelationship)
+	mux.HandleFunc("PUT /relationships/{id}", m.handleUpdateDeviceRelationship)
+	mux.HandleFunc("DELETE /relationships/{id}", m.handleDeleteDeviceRelationship)
+	mux.HandleFunc("GET /devices/{id}/relationships", m.handleGetDeviceRelationshipsForDevice)
+	mux.HandleFunc("GET /topology", m.handleTopology)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	body := fmt.Sprintf(`{"from_device_id":%q,"to_device_id":%q,"type":"powered_by","note":"outlet 4"}`, d[0].ID, d[1].ID)
+	w := do("POST", "/relationships", body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create status = %d: %s", w.Code, w.Body.String())
+	}
+	var rel DeviceRelationship
+	if err := json.NewDecoder(w.Body).Decode(&rel); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if rel.ID == "" || rel.ToHostname != "host-2" {
+		t.Errorf("created = %+v", rel)
+	}
+
+	if w := do("POST", "/relationships", body); w.Code != http.StatusConflict {
+		t.Errorf("duplicate create status = %d, want 409", w.Code)
+	}
+	missing := fmt.Sprintf(`{"from_device_id":%q,"to_device_id":"nope","type":"powered_by"}`, d[0].ID)
+	if w := do("POST", "/relationships", missing); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown device create status = %d, want 400", w.Code)
+	}
+
+	// Endpoints are fixed; only type and note change.
+	w = do("PUT", "/relationships/"+rel.ID, `{"to_device_id":"other","note":"outlet 5"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update status = %d: %s", w.Code, w.Body.String())
+	}
+	var updated DeviceRelationship
+	_ = json.NewDecoder(w.Body).Decode(&updated)
+	if updated.ToDeviceID != d[1].ID || updated.Type != RelationshipPoweredBy || updated.Note != "outlet 5" {
+		t.Errorf("updated = %+v", updated)
+	}
+
+	w = do("GET", "/devices/"+d[1].ID+"/relationships", "")
+	var rels []DeviceRelationship
+	if err := json.NewDecoder(w.Body).Decode(&rels); err != nil || len(rels) != 1 {
+		t.Errorf("device relationships = %v (%v), want 1", rels, err)
+	}
+	if w := do("GET", "/devices/missing/relationships", ""); w.Code != http.StatusNotFound {
+		t.Errorf("unknown device status = %d, want 404", w.Code)
+	}
+
+	countRelEdges := func(path string) int {
+		var graph TopologyGraph
+		_ = json.NewDecoder(do("GET", path, "").Body).Decode(&graph)
+		n := 0
+		for _, e := range graph.Edges {
+			if e.LinkType == relationshipLinkType && e.Relationship == RelationshipPoweredBy {
+				n++
+			}
+		}
+		return n
+	}
+	if n := countRelEdges("/topology"); n != 0 {
+		t.Errorf("default topology has %d relationship edges, want 0", n)
+	}
+	if n := countRelEdges("/topology?relationships=true"); n != 1 {
+		t.Errorf("topology?relationships=true has %d relationship edges, want 1", n)
+	}
+
+	if w := do("DELETE", "/relationships/"+rel.ID, ""); w.Code != http.StatusNoContent {
+		t.Errorf("delete status = %d, want 204", w.Code)
+	}
+	if w := do("GET", "/relationships/"+rel.ID, ""); w.Code != http.StatusNotFound {
+		t.Errorf("get after delete status = %d, want 404", w.Code)
+	}
+}
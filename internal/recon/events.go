@@ -11,11 +11,22 @@ const (
 	TopicDeviceDiscovered = "recon.device.discovered"
 	TopicDeviceUpdated    = "recon.device.updated"
 	TopicDeviceLost       = "recon.device.lost"
+	TopicDeviceDeleted    = "recon.device.deleted"
+	TopicDeviceRestored   = "recon.device.restored"
 	TopicScanStarted      = "recon.scan.started"
 	TopicScanCompleted    = "recon.scan.completed"
 	TopicScanProgress     = "recon.scan.progress"
 	TopicServiceMoved            = "recon.service.moved"
 	TopicDeviceHardwareUpdated   = "recon.device.hardware.updated"
+
+	// Syslog topics are published for warning and worse messages; the payload
+	// is a *DeviceLogEvent.
+	TopicDeviceSyslogCritical = "recon.device.syslog.critical"
+	TopicDeviceSyslogWarning  = "recon.device.syslog.warning"
+
+	// TopicSNMPTrapReceived is published for every stored SNMP trap; the
+	// payload is a *DeviceLogEvent.
+	TopicSNMPTrapReceived = "recon.snmp.trap.received"
 )
 
 // DeviceLostEvent is the payload for TopicDeviceLost events.
@@ -25,6 +36,13 @@ type DeviceLostEvent struct {
 	LastSeen time.Time `json:"last_seen"`
 }
 
+// DeviceDeletedEvent is the payload for TopicDeviceDeleted events.
+type DeviceDeletedEvent struct {
+	DeviceID string `json:"device_id"`
+	Hostname string `json:"hostname,omitempty"`
+	Purged   bool   `json:"purged"` // false when the device was moved to the trash
+}
+
 // DeviceEvent wraps a device with its scan ID for event payloads.
 type DeviceEvent struct {
 	ScanID string         `json:"scan_id"`
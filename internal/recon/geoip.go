@@ -0,0 +1,279 @@
+package recon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+
+	"github.com/oschwald/maxminddb-golang"
+	"go.uber.org/zap"
+)
+
+// Device custom fields written by GeoIP enrichment.
+const (
+	GeoCountryField = "geo_country"
+	GeoCityField    = "geo_city"
+	GeoASNField     = "geo_asn"
+)
+
+// errGeoIPNotFound is returned when no configured database covers an IP.
+var errGeoIPNotFound = errors.New("address not found in GeoIP database")
+
+// cgnatPrefix is the RFC 6598 shared address space used by carrier-grade NAT.
+var cgnatPrefix = netip.MustParsePrefix("100.64.0.0/10")
+
+// GeoIPInfo is the location and network owner of a public IP address.
+type GeoIPInfo struct {
+	IP          string  `json:"ip" example:"8.8.8.8"`
+	CountryCode string  `json:"country_code,omitempty" example:"US"`
+	Country     string  `json:"country,omitempty" example:"United States"`
+	City        string  `json:"city,omitempty" example:"Mountain View"`
+	Latitude    float64 `json:"latitude,omitempty" example:"37.386"`
+	Longitude   float64 `json:"longitude,omitempty" example:"-122.0838"`
+	ASN         uint    `json:"asn,omitempty" example:"15169"`
+	ASOrg       string  `json:"as_org,omitempty" example:"Google LLC"`
+}
+
+// GeoIPLookup resolves public IP addresses to location and ASN data.
+// Implementations return errGeoIPNotFound when the IP is not covered.
+type GeoIPLookup interface {
+	Lookup(ip netip.Addr) (*GeoIPInfo, error)
+}
+
+// isPublicIP reports whether ip is globally routable: not RFC 1918 or
+// IPv6 ULA, loopback, link-local, multicast, unspecified, or CGNAT space.
+func isPublicIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsValid() &&
+		ip.IsGlobalUnicast() &&
+		!ip.IsPrivate() &&
+		!cgnatPrefix.Contains(ip)
+}
+
+// mmdbCity is the subset of a GeoLite2/GeoIP2 City or Country record used
+// for enrichment.
+type mmdbCity struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	Location struct {
+		Latitude  float64 `maxminddb:"latitude"`
+		Longitude float64 `maxminddb:"longitude"`
+	} `maxminddb:"location"`
+}
+
+// mmdbASN is a GeoLite2/GeoIP2 ASN record.
+type mmdbASN struct {
+	Number uint   `maxminddb:"autonomous_system_number"`
+	Org    string `maxminddb:"autonomous_system_organization"`
+}
+
+// MaxMindGeoIP looks up addresses in MaxMind-format (.mmdb) City or Country
+// and ASN databases. Either database may be absent.
+type MaxMindGeoIP struct {
+	city *maxminddb.Reader
+	asn  *maxminddb.Reader
+}
+
+var _ GeoIPLookup = (*MaxMindGeoIP)(nil)
+
+// OpenMaxMindGeoIP opens the databases configured in cfg. At least one
+// path must be set.
+func OpenMaxMindGeoIP(cfg GeoIPConfig) (*MaxMindGeoIP, error) {
+	if cfg.CityDB == "" && cfg.ASNDB == "" {
+		return nil, errors.New("no GeoIP database configured")
+	}
+	g := &MaxMindGeoIP{}
+	if cfg.CityDB != "" {
+		r, err := maxminddb.Open(cfg.CityDB)
+		if err != nil {
+			return nil, fmt.Errorf("open GeoIP city database: %w", err)
+		}
+		g.city = r
+	}
+	if cfg.ASNDB != "" {
+		r, err := maxminddb.Open(cfg.ASNDB)
+		if err != nil {
+			g.Close()
+			return nil, fmt.Errorf("open GeoIP ASN database: %w", err)
+		}
+		g.asn = r
+	}
+	return g, nil
+}
+
+// Lookup implements GeoIPLookup.
+func (g *MaxMindGeoIP) Lookup(ip netip.Addr) (*GeoIPInfo, error) {
+	addr := net.IP(ip.Unmap().AsSlice())
+	info := &GeoIPInfo{IP: ip.String()}
+	found := false
+
+	if g.city != nil {
+		var rec mmdbCity
+		_, ok, err := g.city.LookupNetwork(addr, &rec)
+		if err != nil {
+			return nil, fmt.Errorf("GeoIP city lookup: %w", err)
+		}
+		if ok {
+			found = true
+			info.CountryCode = rec.Country.ISOCode
+			info.Country = rec.Country.Names["en"]
+			info.City = rec.City.Names["en"]
+			info.Latitude = rec.Location.Latitude
+			info.Longitude = rec.Location.Longitude
+		}
+	}
+	if g.asn != nil {
+		var rec mmdbASN
+		_, ok, err := g.asn.LookupNetwork(addr, &rec)
+		if err != nil {
+			return nil, fmt.Errorf("GeoIP ASN lookup: %w", err)
+		}
+		if ok {
+			found = true
+			info.ASN = rec.Number
+			info.ASOrg = rec.Org
+		}
+	}
+	if !found {
+		return nil, errGeoIPNotFound
+	}
+	return info, nil
+}
+
+// Close releases the database files.
+func (g *MaxMindGeoIP) Close() {
+	if g.city != nil {
+		_ = g.city.Close()
+	}
+	if g.asn != nil {
+		_ = g.asn.Close()
+	}
+}
+
+// geoCustomFields maps a lookup result to the device custom fields it sets.
+// Empty values are omitted.
+func geoCustomFields(info *GeoIPInfo) map[string]string {
+	fields := make(map[string]string, 3)
+	if info.CountryCode != "" {
+		fields[GeoCountryField] = info.CountryCode
+	}
+	if info.City != "" {
+		fields[GeoCityField] = info.City
+	}
+	if info.ASN != 0 {
+		asn := "AS" + strconv.FormatUint(uint64(info.ASN), 10)
+		if info.ASOrg != "" {
+			asn += " " + info.ASOrg
+		}
+		fields[GeoASNField] = asn
+	}
+	return fields
+}
+
+// enrichGeoIP tags devices that have a public IP with the country, city,
+// and ASN of their first public address. Returns the number of devices
+// whose fields changed.
+func enrichGeoIP(ctx context.Context, store *ReconStore, geo GeoIPLookup, logger *zap.Logger) int {
+	devices, err := store.ListAllDevices(ctx)
+	if err != nil {
+		logger.Warn("failed to list devices for GeoIP enrichment", zap.Error(err))
+		return 0
+	}
+
+	tagged := 0
+	for i := range devices {
+		if ctx.Err() != nil {
+			break
+		}
+		d := &devices[i]
+		for _, s := range d.IPAddresses {
+			ip, err := netip.ParseAddr(s)
+			if err != nil || !isPublicIP(ip) {
+				continue
+			}
+			info, err := geo.Lookup(ip)
+			if errors.Is(err, errGeoIPNotFound) {
+				continue
+			}
+			if err != nil {
+				logger.Warn("GeoIP lookup failed", zap.String("ip", s), zap.Error(err))
+				break
+			}
+			fields := geoCustomFields(info)
+			if customFieldsContain(d.CustomFields, fields) {
+				break
+			}
+			if err := store.SetDeviceCustomFields(ctx, d.ID, fields); err != nil {
+				logger.Warn("failed to store GeoIP fields", zap.String("device_id", d.ID), zap.Error(err))
+				break
+			}
+			tagged++
+			break
+		}
+	}
+	if tagged > 0 {
+		logger.Info("GeoIP enrichment complete", zap.Int("devices_tagged", tagged))
+	}
+	return tagged
+}
+
+// customFieldsContain reports whether have already holds every entry of want.
+func customFieldsContain(have, want map[string]string) bool {
+	for k, v := range want {
+		if have[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// handleGeoIPLookup returns the GeoIP location and ASN of a public IP.
+//
+//	@Summary		GeoIP lookup
+//	@Description	Looks up a public IP address in the configured MaxMind-format databases. Private, loopback, link-local, and CGNAT addresses are rejected.
+//	@Tags			recon
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			ip	path		string	true	"IPv4 or IPv6 address"
+//	@Success		200	{object}	GeoIPInfo
+//	@Failure		400	{object}	models.APIProblem
+//	@Failure		404	{object}	models.APIProblem
+//	@Failure		500	{object}	models.APIProblem
+//	@Failure		503	{object}	models.APIProblem
+//	@Router			/recon/geoip/{ip} [get]
+func (m *Module) handleGeoIPLookup(w http.ResponseWriter, r *http.Request) {
+	ip, err := netip.ParseAddr(r.PathValue("ip"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid IP address")
+		return
+	}
+	if !isPublicIP(ip) {
+		writeError(w, http.StatusBadRequest, "address is private or reserved")
+		return
+	}
+	if m.geoIP == nil {
+		writeError(w, http.StatusServiceUnavailable, "GeoIP database not configured")
+		return
+	}
+
+	info, err := m.geoIP.Lookup(ip)
+	if errors.Is(err, errGeoIPNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		m.logger.Error("GeoIP lookup failed", zap.String("ip", ip.String()), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "GeoIP lookup failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
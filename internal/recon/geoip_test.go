@@ -0,0 +1,149 @@
+package recon
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"path/filepath"
+	"testing"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+	"go.uber.org/zap"
+)
+
+// fakeGeoIP answers lookups from a fixed table.
+type fakeGeoIP map[string]*GeoIPInfo
+
+func (f fakeGeoIP) Lookup(ip netip.Addr) (*GeoIPInfo, error) {
+	if info, ok := f[ip.String()]; ok {
+		return info, nil
+	}
+	return nil, errGeoIPNotFound
+}
+
+func TestIsPublicIP(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"8.8.8.8":          true,
+		"2606:4700::1111":  true,
+		"::ffff:1.1.1.1":   true,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.1.1":      false,
+		"100.64.0.1":       false,
+		"127.0.0.1":        false,
+		"169.254.10.10":    false,
+		"224.0.0.251":      false,
+		"0.0.0.0":          false,
+		"255.255.255.255":  false,
+		"fd12:3456::1":     false,
+		"fe80::1":          false,
+		"::1":              false,
+		"::ffff:192.0.2.1": true,
+	} {
+		if got := isPublicIP(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("isPublicIP(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestOpenMaxMindGeoIP_Errors(t *testing.T) {
+	if _, err := OpenMaxMindGeoIP(GeoIPConfig{}); err == nil {
+		t.Error("OpenMaxMindGeoIP with no paths succeeded, want error")
+	}
+	missing := filepath.Join(t.TempDir(), "GeoLite2-City.mmdb")
+	if _, err := OpenMaxMindGeoIP(GeoIPConfig{CityDB: missing}); err == nil {
+		t.Error("OpenMaxMindGeoIP with a missing file succeeded, want error")
+	}
+}
+
+func TestEnrichGeoIP(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+
+	add := func(mac string, ips ...string) *models.Device {
+		d := &models.Device{
+			IPAddresses:     ips,
+			MACAddress:      mac,
+			Status:          models.DeviceStatusOnline,
+			DiscoveryMethod: models.DiscoveryICMP,
+			CustomFields:    map[string]string{"asset_tag": "A-1"},
+		}
+		if _, err := s.UpsertDevice(ctx, d); err != nil {
+			t.Fatalf("UpsertDevice: %v", err)
+		}
+		return d
+	}
+	gateway := add("AA:BB:CC:00:20:01", "203.0.113.7")
+	lan := add("AA:BB:CC:00:20:02", "192.168.1.20")
+
+	geo := fakeGeoIP{
+		"203.0.113.7": {IP: "203.0.113.7", CountryCode: "NL", City: "Amsterdam", ASN: 64500, ASOrg: "Example Net"},
+		// Would match if private addresses were not skipped.
+		"192.168.1.20": {IP: "192.168.1.20", CountryCode: "XX"},
+	}
+	if got := enrichGeoIP(ctx, s, geo, zap.NewNop()); got != 1 {
+		t.Errorf("enrichGeoIP = %d, want 1", got)
+	}
+
+	got, _ := s.GetDevice(ctx, gateway.ID)
+	want := map[string]string{
+		"asset_tag":     "A-1",
+		GeoCountryField: "NL",
+		GeoCityField:    "Amsterdam",
+		GeoASNField:     "AS64500 Example Net",
+	}
+	for k, v := range want {
+		if got.CustomFields[k] != v {
+			t.Errorf("gateway custom field %s = %q, want %q", k, got.CustomFields[k], v)
+		}
+	}
+	got, _ = s.GetDevice(ctx, lan.ID)
+	if _, ok := got.CustomFields[GeoCountryField]; ok {
+		t.Errorf("private device tagged with GeoIP fields: %v", got.CustomFields)
+	}
+
+	// A second pass with unchanged data writes nothing.
+	if got := enrichGeoIP(ctx, s, geo, zap.NewNop()); got != 0 {
+		t.Errorf("second enrichGeoIP = %d, want 0", got)
+	}
+}
+
+func TestHandleGeoIPLookup(t *testing.T) {
+	m := newTestModule(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /geoip/{ip}", m.handleGeoIPLookup)
+	get := func(ip string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "/geoip/"+ip, http.NoBody))
+		return w
+	}
+
+	if w := get("8.8.8.8"); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("unconfigured status = %d, want 503", w.Code)
+	}
+
+	m.geoIP = fakeGeoIP{"8.8.8.8": {IP: "8.8.8.8", CountryCode: "US", ASN: 15169, ASOrg: "Google LLC"}}
+	w := get("8.8.8.8")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var info GeoIPInfo
+	if err := json.NewDecoder(w.Body).Decode(&info); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if info.CountryCode != "US" || info.ASN != 15169 {
+		t.Errorf("info = %+v", info)
+	}
+
+	for ip, want := range map[string]int{
+		"not-an-ip":   http.StatusBadRequest,
+		"192.168.1.1": http.StatusBadRequest,
+		"1.1.1.1":     http.StatusNotFound,
+	} {
+		if w := get(ip); w.Code != want {
+			t.Errorf("GET /geoip/%s = %d, want %d", ip, w.Code, want)
+		}
+	}
+}
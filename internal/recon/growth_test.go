@@ -0,0 +1,69 @@
+package recon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBuildGrowthSeries_Daily(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	devices := []DeviceFirstSeen{
+		{FirstSeen: now.AddDate(0, 0, -30), DeviceType: "router"}, // before the range
+		{FirstSeen: now.AddDate(0, 0, -2), DeviceType: "iot"},
+		{FirstSeen: now.AddDate(0, 0, -2).Add(time.Hour), DeviceType: "iot"},
+		{FirstSeen: now, DeviceType: ""},
+	}
+
+	points := buildGrowthSeries(devices, now, 7, "daily", "type")
+	if len(points) != 7 {
+		t.Fatalf("points = %d, want 7", len(points))
+	}
+	if points[0].PeriodStart != "2026-03-04" || points[6].PeriodStart != "2026-03-10" {
+		t.Errorf("range = %s..%s, want 2026-03-04..2026-03-10", points[0].PeriodStart, points[6].PeriodStart)
+	}
+	if points[0].Total != 1 {
+		t.Errorf("first total = %d, want 1 from the device seen before the range", points[0].Total)
+	}
+	if p := points[4]; p.New != 2 || p.Total != 3 || p.Groups["iot"] != 2 {
+		t.Errorf("2026-03-08 = %+v, want 2 new iot devices and total 3", p)
+	}
+	if p := points[6]; p.New != 1 || p.Total != 4 || p.Groups["unknown"] != 1 {
+		t.Errorf("2026-03-10 = %+v, want 1 new unknown-type device and total 4", p)
+	}
+}
+
+func TestBuildGrowthSeries_WeeklyStartsMonday(t *testing.T) {
+	now := time.Date(2026, 3, 12, 9, 0, 0, 0, time.UTC) // Thursday
+	devices := []DeviceFirstSeen{
+		{FirstSeen: time.Date(2026, 3, 9, 1, 0, 0, 0, time.UTC)},  // Monday of this week
+		{FirstSeen: time.Date(2026, 3, 8, 23, 0, 0, 0, time.UTC)}, // Sunday of last week
+	}
+
+	points := buildGrowthSeries(devices, now, 14, "weekly", "")
+	if len(points) != 3 {
+		t.Fatalf("points = %d, want 3: %+v", len(points), points)
+	}
+	if points[2].PeriodStart != "2026-03-09" || points[2].New != 1 {
+		t.Errorf("current week = %+v, want start 2026-03-09 with 1 new", points[2])
+	}
+	if points[1].PeriodStart != "2026-03-02" || points[1].New != 1 {
+		t.Errorf("previous week = %+v, want start 2026-03-02 with 1 new", points[1])
+	}
+	if points[2].Groups != nil {
+		t.Errorf("groups = %v, want nil without group_by", points[2].Groups)
+	}
+}
+
+func TestHandleDeviceGrowth_BadRequest(t *testing.T) {
+	m := newTestModule(t)
+
+	for _, query := range []string{"range=90", "range=0d", "range=1000d", "interval=hourly", "group_by=owner"} {
+		w := httptest.NewRecorder()
+		m.handleDeviceGrowth(w, httptest.NewRequest("GET", "/stats/growth?"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, w.Code)
+		}
+	}
+}
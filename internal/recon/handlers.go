@@ -2,8 +2,10 @@ package recon
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
 	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,9 +15,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/HerbHall/subnetree/internal/pagination"
 	"github.com/HerbHall/subnetree/pkg/models"
 	"github.com/HerbHall/subnetree/pkg/roles"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -26,6 +30,42 @@ func writeJSON(w http.ResponseWriter, status int, data any) {
 	_ = json.NewEncoder(w).Encode(data)
 }
 
+// writeJSONWithETag writes a 200 JSON response carrying a weak ETag derived
+// from the encoded body, so any change to an included field changes the tag.
+// When the request's If-None-Match matches, it writes 304 Not Modified
+// without a body.
+func writeJSONWithETag(w http.ResponseWriter, r *http.Request, data any) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to encode response")
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(append(body, '\n'))
+}
+
+// etagMatches reports whether an If-None-Match header matches etag using
+// weak comparison.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
 // writeError writes an RFC 7807 problem detail response.
 func writeError(w http.ResponseWriter, status int, detail string) {
 	w.Header().Set("Content-Type", "application/problem+json")
@@ -55,6 +95,9 @@ type TopologyNode struct {
 	Manufacturer   string              `json:"manufacturer,omitempty" example:"Dell Inc."`
 	ParentDeviceID string              `json:"parent_device_id,omitempty"`
 	NetworkLayer   int                 `json:"network_layer,omitempty"`
+	// IconURL points at the uploaded icon, versioned by its update time.
+	// Empty when the device uses its device-type default.
+	IconURL string `json:"icon_url,omitempty" example:"/api/v1/recon/devices/550e8400-e29b-41d4-a716-446655440000/icon?v=1767225600"`
 }
 
 // TopologyEdge represents a link in the topology graph.
@@ -64,17 +107,27 @@ type TopologyEdge struct {
 	Target   string `json:"target" example:"660f9500-f30c-52e5-b827-557766551111"`
 	LinkType string `json:"link_type" example:"ethernet"`
 	Speed    int    `json:"speed,omitempty" example:"1000"`
+	// Relationship is the device relationship type for edges with link
+	// type "relationship", included when requested with ?relationships=true.
+	Relationship string `json:"relationship,omitempty" example:"powered_by"`
 }
 
+// relationshipLinkType marks topology edges that come from device
+// relationships rather than network links.
+const relationshipLinkType = "relationship"
+
 // ScanRequest is the request body for POST /scan.
 type ScanRequest struct {
 	Subnet string `json:"subnet" example:"192.168.1.0/24"`
+	// TemplateID optionally names a scan template whose settings override
+	// the configured defaults for this scan.
+	TemplateID string `json:"template_id,omitempty"`
 }
 
 // handleScan triggers a new network scan.
 //
 //	@Summary		Start scan
-//	@Description	Trigger a new network scan on the given subnet. Returns immediately with scan ID.
+//	@Description	Trigger a new network scan on the given subnet. Returns immediately with scan ID. An optional template_id runs the scan with that scan template's methods, ports, and tuning.
 //	@Tags			recon
 //	@Accept			json
 //	@Produce		json
@@ -85,69 +138,111 @@ type ScanRequest struct {
 //	@Failure		500		{object}	models.APIProblem
 //	@Router			/recon/scan [post]
 func (m *Module) handleScan(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Subnet string `json:"subnet"`
-	}
+	var req ScanRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid JSON body")
 		return
 	}
-	if req.Subnet == "" {
-		writeError(w, http.StatusBadRequest, "subnet is required")
+
+	scan, err := m.StartScanWithTemplate(r.Context(), req.Subnet, req.TemplateID)
+	if errors.Is(err, ErrInvalidSubnet) {
+		writeError(w, http.StatusBadRequest, strings.TrimPrefix(err.Error(), ErrInvalidSubnet.Error()+": "))
+		return
+	}
+	if errors.Is(err, ErrScanTemplateNotFound) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		m.logger.Error("failed to create scan", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to create scan")
 		return
 	}
 
+	writeJSON(w, http.StatusAccepted, scan)
+}
+
+// ErrInvalidSubnet is returned by StartScan when the subnet is missing,
+// malformed, or larger than the maximum allowed scan size.
+var ErrInvalidSubnet = errors.New("invalid subnet")
+
+// StartScan validates the subnet, records a new scan, and runs it in the
+// background. It returns the scan record in "running" state.
+func (m *Module) StartScan(ctx context.Context, subnet string) (*models.ScanResult, error) {
+	return m.StartScanWithTemplate(ctx, subnet, "")
+}
+
+// StartScanWithTemplate is StartScan with the settings of the scan template
+// templateID, if not empty. It returns ErrScanTemplateNotFound if the
+// template does not exist.
+func (m *Module) StartScanWithTemplate(ctx context.Context, subnet, templateID string) (*models.ScanResult, error) {
+	if subnet == "" {
+		return nil, fmt.Errorf("%w: subnet is required", ErrInvalidSubnet)
+	}
+
 	// Validate CIDR.
-	_, ipNet, err := net.ParseCIDR(req.Subnet)
+	_, ipNet, err := net.ParseCIDR(subnet)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid CIDR: "+err.Error())
-		return
+		return nil, fmt.Errorf("%w: invalid CIDR: %s", ErrInvalidSubnet, err.Error())
 	}
 
 	// Reject subnets larger than /16.
 	ones, bits := ipNet.Mask.Size()
 	if bits-ones > 16 {
-		writeError(w, http.StatusBadRequest, "subnet too large: maximum /16 allowed")
-		return
+		return nil, fmt.Errorf("%w: subnet too large: maximum /16 allowed", ErrInvalidSubnet)
+	}
+
+	var tmpl *ScanTemplate
+	if templateID != "" {
+		tmpl, err = m.store.GetScanTemplate(ctx, templateID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrScanTemplateNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get scan template: %w", err)
+		}
 	}
 
 	// Create scan record.
 	scanID := uuid.New().String()
 	scan := &models.ScanResult{
-		ID:     scanID,
-		Subnet: req.Subnet,
-		Status: "running",
+		ID:         scanID,
+		Subnet:     subnet,
+		Status:     "running",
+		TemplateID: templateID,
 	}
-	if err := m.store.CreateScan(r.Context(), scan); err != nil {
-		m.logger.Error("failed to create scan", zap.Error(err))
-		writeError(w, http.StatusInternalServerError, "failed to create scan")
-		return
+	if err := m.store.CreateScan(ctx, scan); err != nil {
+		return nil, fmt.Errorf("create scan: %w", err)
 	}
 
-	// Store cancel func for this scan.
+	// Store cancel func for this scan. The scan outlives the request, but
+	// keeps its trace so the scan span nests under the API call.
 	scanCtx, cancel := m.newScanContext()
+	scanCtx = trace.ContextWithSpanContext(scanCtx, trace.SpanContextFromContext(ctx))
 	m.activeScans.Store(scanID, cancel)
 	m.wg.Add(1)
 
 	go func() {
 		defer m.wg.Done()
 		defer m.activeScans.Delete(scanID)
-		m.orchestrator.RunScan(scanCtx, scanID, req.Subnet)
+		m.orchestrator.RunScanWithTemplate(scanCtx, scanID, subnet, tmpl)
 	}()
 
-	writeJSON(w, http.StatusAccepted, scan)
+	return scan, nil
 }
 
 // handleListScans returns a paginated list of scans.
 //
 //	@Summary		List scans
-//	@Description	Returns a paginated list of scan results.
+//	@Description	Returns a paginated list of scan results. The total count is in the X-Total-Count header and page links in the Link header.
 //	@Tags			recon
 //	@Produce		json
 //	@Security		BearerAuth
 //	@Param			limit	query		int	false	"Max results"	default(50)
 //	@Param			offset	query		int	false	"Offset"		default(0)
 //	@Success		200		{array}		models.ScanResult
+//	@Header			200		{integer}	X-Total-Count	"Total number of scans"
+//	@Header			200		{string}	Link			"RFC 8288 first/prev/next/last page links"
 //	@Failure		500		{object}	models.APIProblem
 //	@Router			/recon/scans [get]
 func (m *Module) handleListScans(w http.ResponseWriter, r *http.Request) {
@@ -160,9 +255,19 @@ func (m *Module) handleListScans(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to list scans")
 		return
 	}
+	total, err := m.store.CountScans(r.Context())
+	if err != nil {
+		m.logger.Error("failed to count scans", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to list scans")
+		return
+	}
 	if scans == nil {
 		scans = []models.ScanResult{}
 	}
+	if limit <= 0 {
+		limit = 50 // ListScans default
+	}
+	pagination.SetHeaders(w, r, total, limit, offset)
 	writeJSON(w, http.StatusOK, scans)
 }
 
@@ -229,12 +334,15 @@ func (m *Module) handleGetScanMetrics(w http.ResponseWriter, r *http.Request) {
 // handleTopology returns the network topology as a graph.
 //
 //	@Summary		Get topology
-//	@Description	Returns the network topology as a graph of nodes and edges.
+//	@Description	Returns the network topology as a graph of nodes and edges. With relationships=true, typed device relationships are added as edges with link_type "relationship". Responses carry a weak ETag; send it back in If-None-Match to get 304 when nothing changed.
 //	@Tags			recon
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Success		200	{object}	TopologyGraph
-//	@Failure		500	{object}	models.APIProblem
+//	@Param			relationships	query		bool	false	"Include device relationships as edges"
+//	@Param			If-None-Match	header		string	false	"ETag from a previous response"
+//	@Success		200				{object}	TopologyGraph
+//	@Success		304				"Not modified"
+//	@Failure		500				{object}	models.APIProblem
 //	@Router			/recon/topology [get]
 func (m *Module) handleTopology(w http.ResponseWriter, r *http.Request) {
 	devices, _, err := m.store.ListDevices(r.Context(), ListDevicesOptions{Limit: 10000})
@@ -251,6 +359,13 @@ func (m *Module) handleTopology(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	icons, err := m.store.deviceIconVersions(r.Context())
+	if err != nil {
+		m.logger.Error("failed to load device icons", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to load device icons")
+		return
+	}
+
 	graph := TopologyGraph{
 		Nodes: make([]TopologyNode, 0, len(devices)),
 		Edges: make([]TopologyEdge, 0, len(links)),
@@ -262,7 +377,7 @@ func (m *Module) handleTopology(w http.ResponseWriter, r *http.Request) {
 		if label == "" && len(d.IPAddresses) > 0 {
 			label = d.IPAddresses[0]
 		}
-		graph.Nodes = append(graph.Nodes, TopologyNode{
+		node := TopologyNode{
 			ID:             d.ID,
 			Label:          label,
 			DeviceType:     d.DeviceType,
@@ -272,7 +387,11 @@ func (m *Module) handleTopology(w http.ResponseWriter, r *http.Request) {
 			Manufacturer:   d.Manufacturer,
 			ParentDeviceID: d.ParentDeviceID,
 			NetworkLayer:   d.NetworkLayer,
-		})
+		}
+		if updated, ok := icons[d.ID]; ok {
+			node.IconURL = deviceIconURL(d.ID) + "?v=" + strconv.FormatInt(updated.Unix(), 10)
+		}
+		graph.Nodes = append(graph.Nodes, node)
 	}
 
 	// Build a set of existing link pairs so we don't duplicate.
@@ -294,7 +413,25 @@ func (m *Module) handleTopology(w http.ResponseWriter, r *http.Request) {
 	inferred := inferGatewayEdges(devices, existingLinks)
 	graph.Edges = append(graph.Edges, inferred...)
 
-	writeJSON(w, http.StatusOK, graph)
+	if r.URL.Query().Get("relationships") == "true" {
+		rels, err := m.store.ListAllDeviceRelationships(r.Context(), "")
+		if err != nil {
+			m.logger.Error("failed to load device relationships", zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "failed to load device relationships")
+			return
+		}
+		for i := range rels {
+			graph.Edges = append(graph.Edges, TopologyEdge{
+				ID:           rels[i].ID,
+				Source:       rels[i].FromDeviceID,
+				Target:       rels[i].ToDeviceID,
+				LinkType:     relationshipLinkType,
+				Relationship: rels[i].Type,
+			})
+		}
+	}
+
+	writeJSONWithETag(w, r, graph)
 }
 
 // inferGatewayEdges generates synthetic topology edges that model the network
@@ -720,7 +857,7 @@ type CreateDeviceRequest struct {
 // handleListDevices returns a paginated list of devices with optional filters.
 //
 //	@Summary		List devices
-//	@Description	Returns a paginated list of devices with optional status, type, category, and owner filters.
+//	@Description	Returns a paginated list of devices with optional status, type, category, owner, location, open port, text search, and custom field filters, optionally sorted by a custom field. Responses carry a weak ETag; send it back in If-None-Match to get 304 when nothing changed.
 //	@Tags			recon
 //	@Produce		json
 //	@Security		BearerAuth
@@ -730,7 +867,18 @@ type CreateDeviceRequest struct {
 //	@Param			type		query		string	false	"Filter by device type"
 //	@Param			category	query		string	false	"Filter by category"
 //	@Param			owner		query		string	false	"Filter by owner"
+//	@Param			location	query		string	false	"Filter by location"
+//	@Param			q			query		string	false	"Text search (all terms must match)"
+//	@Param			open_port	query		int		false	"Only devices with this TCP port open"
+//	@Param			cf.{name}	query		string	false	"Custom field filter; append .lt, .lte, .gt, or .gte to the key for a range"
+//	@Param			sort		query		string	false	"Sort by a custom field, e.g. cf.warranty_expiry"
+//	@Param			order		query		string	false	"Sort order for sort (asc or desc)"	default(asc)
+//	@Param			If-None-Match	header	string	false	"ETag from a previous response"
 //	@Success		200			{object}	DeviceListResponse
+//	@Header			200			{integer}	X-Total-Count	"Total number of matching devices"
+//	@Header			200			{string}	Link			"RFC 8288 first/prev/next/last page links"
+//	@Success		304			"Not modified"
+//	@Failure		400			{object}	models.APIProblem
 //	@Failure		500			{object}	models.APIProblem
 //	@Router			/recon/devices [get]
 func (m *Module) handleListDevices(w http.ResponseWriter, r *http.Request) {
@@ -741,6 +889,28 @@ func (m *Module) handleListDevices(w http.ResponseWriter, r *http.Request) {
 	category := r.URL.Query().Get("category")
 	owner := r.URL.Query().Get("owner")
 
+	var openPort int
+	if v := r.URL.Query().Get("open_port"); v != "" {
+		p, err := strconv.Atoi(v)
+		if err != nil || p < 1 || p > 65535 {
+			writeError(w, http.StatusBadRequest, "open_port must be between 1 and 65535")
+			return
+		}
+		openPort = p
+	}
+
+	defs, err := m.store.customFieldDefMap(r.Context())
+	if err != nil {
+		m.logger.Error("failed to load custom field definitions", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to list devices")
+		return
+	}
+	customFilters, customSort, err := parseCustomFieldQuery(r.URL.Query(), defs)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	devices, total, err := m.store.ListDevices(r.Context(), ListDevicesOptions{
 		Limit:      limit,
 		Offset:     offset,
@@ -748,6 +918,12 @@ func (m *Module) handleListDevices(w http.ResponseWriter, r *http.Request) {
 		DeviceType: deviceType,
 		Category:   category,
 		Owner:      owner,
+		Location:   r.URL.Query().Get("location"),
+		Search:     r.URL.Query().Get("q"),
+		OpenPort:   openPort,
+
+		CustomFilters: customFilters,
+		CustomSort:    customSort,
 	})
 	if err != nil {
 		m.logger.Error("failed to list devices", zap.Error(err))
@@ -757,7 +933,8 @@ func (m *Module) handleListDevices(w http.ResponseWriter, r *http.Request) {
 	if devices == nil {
 		devices = []models.Device{}
 	}
-	writeJSON(w, http.StatusOK, DeviceListResponse{
+	pagination.SetHeaders(w, r, total, limit, offset)
+	writeJSONWithETag(w, r, DeviceListResponse{
 		Devices: devices,
 		Total:   total,
 		Limit:   limit,
@@ -819,6 +996,13 @@ func (m *Module) handleUpdateDevice(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "invalid JSON body")
 		return
 	}
+	if params.DeviceType != nil && !models.DeviceType(*params.DeviceType).Valid() {
+		writeError(w, http.StatusBadRequest, unknownDeviceTypeMessage(*params.DeviceType))
+		return
+	}
+	if !m.normalizeCustomFieldParams(w, r, &params) {
+		return
+	}
 
 	if err := m.store.UpdateDevice(r.Context(), id, params); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -840,13 +1024,15 @@ func (m *Module) handleUpdateDevice(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, device)
 }
 
-// handleDeleteDevice removes a device by ID.
+// handleDeleteDevice moves a device to the trash, or removes it permanently
+// when purge=true.
 //
 //	@Summary		Delete device
-//	@Description	Permanently removes a device and its history.
+//	@Description	Moves a device to the trash, detaching its child devices. With purge=true the device and its history are removed permanently, whether or not it is already in the trash.
 //	@Tags			recon
 //	@Security		BearerAuth
-//	@Param			id	path	string	true	"Device ID"
+//	@Param			id		path	string	true	"Device ID"
+//	@Param			purge	query	bool	false	"Permanently delete instead of moving to the trash"
 //	@Success		204	"No content"
 //	@Failure		400	{object}	models.APIProblem
 //	@Failure		404	{object}	models.APIProblem
@@ -859,18 +1045,91 @@ func (m *Module) handleDeleteDevice(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := m.store.DeleteDevice(r.Context(), id); err != nil {
+	// Look up the hostname first so subscribers can label the removal.
+	var hostname string
+	if dev, err := m.store.GetDevice(r.Context(), id); err == nil && dev != nil {
+		hostname = dev.Hostname
+	}
+
+	purge := r.URL.Query().Get("purge") == "true"
+	var err error
+	if purge {
+		err = m.store.PurgeDevice(r.Context(), id)
+	} else {
+		err = m.store.DeleteDevice(r.Context(), id)
+	}
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "device not found")
 			return
 		}
-		m.logger.Error("failed to delete device", zap.String("id", id), zap.Error(err))
+		m.logger.Error("failed to delete device", zap.String("id", id), zap.Bool("purge", purge), zap.Error(err))
 		writeError(w, http.StatusInternalServerError, "failed to delete device")
 		return
 	}
+
+	m.publishEvent(r.Context(), TopicDeviceDeleted, &DeviceDeletedEvent{DeviceID: id, Hostname: hostname, Purged: purge})
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// handleListDeletedDevices lists the devices in the trash.
+//
+//	@Summary		List deleted devices
+//	@Description	Returns devices in the trash, most recently deleted first. Trashed devices are purged permanently after the configured retention.
+//	@Tags			recon
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{array}		DeletedDevice
+//	@Failure		500	{object}	models.APIProblem
+//	@Router			/recon/devices/trash [get]
+func (m *Module) handleListDeletedDevices(w http.ResponseWriter, r *http.Request) {
+	devices, err := m.store.ListDeletedDevices(r.Context())
+	if err != nil {
+		m.logger.Error("failed to list deleted devices", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to list deleted devices")
+		return
+	}
+	writeJSON(w, http.StatusOK, devices)
+}
+
+// handleRestoreDevice moves a device out of the trash.
+//
+//	@Summary		Restore device
+//	@Description	Restores a device from the trash. Child devices detached on delete are not re-attached.
+//	@Tags			recon
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Device ID"
+//	@Success		200	{object}	models.Device
+//	@Failure		404	{object}	models.APIProblem
+//	@Failure		409	{object}	models.APIProblem
+//	@Failure		500	{object}	models.APIProblem
+//	@Router			/recon/devices/{id}/restore [post]
+func (m *Module) handleRestoreDevice(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := m.store.RestoreDevice(r.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			writeError(w, http.StatusNotFound, "device not found in trash")
+		case errors.Is(err, ErrDeviceRestoreConflict):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			m.logger.Error("failed to restore device", zap.String("id", id), zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "failed to restore device")
+		}
+		return
+	}
+
+	device, err := m.store.GetDevice(r.Context(), id)
+	if err != nil {
+		m.logger.Error("failed to load restored device", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to load restored device")
+		return
+	}
+	m.publishEvent(r.Context(), TopicDeviceRestored, &DeviceEvent{Device: device})
+	writeJSON(w, http.StatusOK, device)
+}
+
 // handleCreateDevice manually creates a new device.
 //
 //	@Summary		Create device
@@ -898,6 +1157,10 @@ func (m *Module) handleCreateDevice(w http.ResponseWriter, r *http.Request) {
 	dt := models.DeviceTypeUnknown
 	if req.DeviceType != "" {
 		dt = models.DeviceType(req.DeviceType)
+		if !dt.Valid() {
+			writeError(w, http.StatusBadRequest, unknownDeviceTypeMessage(req.DeviceType))
+			return
+		}
 	}
 
 	device := &models.Device{
@@ -920,13 +1183,16 @@ func (m *Module) handleCreateDevice(w http.ResponseWriter, r *http.Request) {
 // handleDeviceHistory returns status change history for a device.
 //
 //	@Summary		Device status history
-//	@Description	Returns the status change timeline for a device.
+//	@Description	Returns the status change timeline for a device, newest first. The total count is in the X-Total-Count header and page links in the Link header.
 //	@Tags			recon
 //	@Produce		json
 //	@Security		BearerAuth
 //	@Param			id		path		string	true	"Device ID"
 //	@Param			limit	query		int		false	"Max results"	default(50)
+//	@Param			offset	query		int		false	"Offset"		default(0)
 //	@Success		200		{array}		DeviceStatusEvent
+//	@Header			200		{integer}	X-Total-Count	"Total number of status changes"
+//	@Header			200		{string}	Link			"RFC 8288 first/prev/next/last page links"
 //	@Failure		400		{object}	models.APIProblem
 //	@Failure		500		{object}	models.APIProblem
 //	@Router			/recon/devices/{id}/history [get]
@@ -938,8 +1204,12 @@ func (m *Module) handleDeviceHistory(w http.ResponseWriter, r *http.Request) {
 	}
 
 	limit := queryInt(r, "limit", 50)
+	if limit <= 0 {
+		limit = 50 // GetDeviceHistory default
+	}
+	offset := queryInt(r, "offset", 0)
 
-	changes, _, err := m.store.GetDeviceHistory(r.Context(), id, limit, 0)
+	changes, total, err := m.store.GetDeviceHistory(r.Context(), id, limit, offset)
 	if err != nil {
 		m.logger.Error("failed to get device history", zap.String("id", id), zap.Error(err))
 		writeError(w, http.StatusInternalServerError, "failed to get device history")
@@ -956,6 +1226,7 @@ func (m *Module) handleDeviceHistory(w http.ResponseWriter, r *http.Request) {
 			Timestamp: changes[i].ChangedAt.Format(time.RFC3339),
 		})
 	}
+	pagination.SetHeaders(w, r, total, limit, offset)
 	writeJSON(w, http.StatusOK, events)
 }
 
@@ -1016,7 +1287,7 @@ type BulkUpdateResponse struct {
 // handleInventorySummary returns aggregate inventory statistics.
 //
 //	@Summary		Inventory summary
-//	@Description	Returns aggregate device inventory statistics including counts by status, category, and type.
+//	@Description	Returns aggregate device inventory statistics including counts by status, category, and type. Online, offline, and other (degraded or unknown) counts sum to the total; stale_count is the subset of online devices not seen within stale_days.
 //	@Tags			recon
 //	@Produce		json
 //	@Security		BearerAuth
@@ -1059,6 +1330,13 @@ func (m *Module) handleBulkUpdateDevices(w http.ResponseWriter, r *http.Request)
 		writeError(w, http.StatusBadRequest, "device_ids is required")
 		return
 	}
+	if req.Updates.DeviceType != nil && !models.DeviceType(*req.Updates.DeviceType).Valid() {
+		writeError(w, http.StatusBadRequest, unknownDeviceTypeMessage(*req.Updates.DeviceType))
+		return
+	}
+	if !m.normalizeCustomFieldParams(w, r, &req.Updates) {
+		return
+	}
 
 	updated, err := m.store.BulkUpdateDevices(r.Context(), req.DeviceIDs, req.Updates)
 	if err != nil {
@@ -1069,6 +1347,77 @@ func (m *Module) handleBulkUpdateDevices(w http.ResponseWriter, r *http.Request)
 	writeJSON(w, http.StatusOK, BulkUpdateResponse{Updated: updated})
 }
 
+// BulkTagsRequest is the request body for POST /devices/bulk-tags.
+type BulkTagsRequest struct {
+	DeviceIDs []string `json:"device_ids"`
+	Tags      []string `json:"tags" example:"audited"`
+	Op        string   `json:"op" example:"add" enums:"add,remove"`
+}
+
+// handleBulkTags adds or removes tags on multiple devices without replacing
+// their other tags.
+//
+//	@Summary		Bulk add or remove device tags
+//	@Description	Adds tags to (op "add") or removes tags from (op "remove") multiple devices, preserving each device's other tags. The response counts devices whose tags changed.
+//	@Tags			recon
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		BulkTagsRequest	true	"Device IDs, tags, and operation"
+//	@Success		200		{object}	BulkUpdateResponse
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/recon/devices/bulk-tags [post]
+func (m *Module) handleBulkTags(w http.ResponseWriter, r *http.Request) {
+	var req BulkTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if len(req.DeviceIDs) == 0 {
+		writeError(w, http.StatusBadRequest, "device_ids is required")
+		return
+	}
+	tags := make([]string, 0, len(req.Tags))
+	for _, t := range req.Tags {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	if len(tags) == 0 {
+		writeError(w, http.StatusBadRequest, "tags is required")
+		return
+	}
+
+	var updated int
+	var err error
+	switch req.Op {
+	case "add":
+		updated, err = m.store.BulkAddTags(r.Context(), req.DeviceIDs, tags)
+	case "remove":
+		updated, err = m.store.BulkRemoveTags(r.Context(), req.DeviceIDs, tags)
+	default:
+		writeError(w, http.StatusBadRequest, `op must be "add" or "remove"`)
+		return
+	}
+	if err != nil {
+		m.logger.Error("failed to bulk update device tags", zap.String("op", req.Op), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to update device tags")
+		return
+	}
+	writeJSON(w, http.StatusOK, BulkUpdateResponse{Updated: updated})
+}
+
+// unknownDeviceTypeMessage is the 400 detail for a device_type outside
+// models.DeviceTypes.
+func unknownDeviceTypeMessage(dt string) string {
+	known := make([]string, len(models.DeviceTypes))
+	for i, t := range models.DeviceTypes {
+		known[i] = string(t)
+	}
+	return fmt.Sprintf("unknown device_type %q; must be one of %s", dt, strings.Join(known, ", "))
+}
+
 // queryInt extracts an integer query parameter with a default value.
 func queryInt(r *http.Request, key string, defaultVal int) int {
 	s := r.URL.Query().Get(key)
@@ -1189,6 +1538,25 @@ type SNMPSystemInfoResponse struct {
 	Location    string `json:"location"`
 }
 
+// LLDPPollResponse is the result of an on-demand LLDP neighbor poll.
+type LLDPPollResponse struct {
+	DeviceID     string         `json:"device_id"`
+	Neighbors    []LLDPNeighbor `json:"neighbors"`
+	LinksCreated int            `json:"links_created"`
+}
+
+// ResolveDNSResponse is the response for an on-demand reverse DNS lookup.
+type ResolveDNSResponse struct {
+	DeviceID string `json:"device_id"`
+	// ResolvedName is the PTR name found, or empty if no address has one.
+	ResolvedName   string `json:"resolved_name" example:"nas.home.arpa"`
+	Hostname       string `json:"hostname" example:"nas.home.arpa"`
+	HostnameSource string `json:"hostname_source" example:"reverse_dns"`
+	// Updated is false when the device already had a hostname or its
+	// hostname was set manually.
+	Updated bool `json:"updated"`
+}
+
 // SNMPInterfaceResponse wraps SNMPInterface for JSON serialization.
 type SNMPInterfaceResponse struct {
 	Index       int    `json:"index"`
@@ -1434,6 +1802,127 @@ func (m *Module) handleSNMPInterfaces(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, resp)
 }
 
+// handleDevicePollLLDP polls a device's LLDP neighbor table over SNMP.
+//
+//	@Summary		Poll LLDP neighbors
+//	@Description	Reads the device's LLDP-MIB remote table using its SNMP credential and replaces its LLDP topology links.
+//	@Tags			recon
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string				true	"Device ID"
+//	@Success		200	{object}	LLDPPollResponse	"Neighbors and links created"
+//	@Failure		400	{object}	models.APIProblem
+//	@Failure		404	{object}	models.APIProblem
+//	@Failure		500	{object}	models.APIProblem
+//	@Failure		503	{object}	models.APIProblem
+//	@Router			/recon/devices/{id}/lldp [post]
+func (m *Module) handleDevicePollLLDP(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	device, err := m.store.GetDevice(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "device not found")
+		return
+	}
+	if len(device.IPAddresses) == 0 {
+		writeError(w, http.StatusBadRequest, "device has no IP addresses")
+		return
+	}
+
+	credID, err := m.findSNMPCredential(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "no SNMP credentials configured for device")
+		return
+	}
+
+	if m.orchestrator == nil || m.credAccessor == nil {
+		writeError(w, http.StatusServiceUnavailable, "SNMP collector not available")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	neighbors, created, err := m.orchestrator.PollLLDP(ctx, device, credID)
+	if err != nil {
+		m.logger.Error("LLDP poll failed",
+			zap.String("device_id", id),
+			zap.String("ip", device.IPAddresses[0]),
+			zap.Error(err),
+		)
+		writeError(w, http.StatusInternalServerError, "LLDP poll failed: "+err.Error())
+		return
+	}
+
+	if neighbors == nil {
+		neighbors = []LLDPNeighbor{}
+	}
+	writeJSON(w, http.StatusOK, LLDPPollResponse{
+		DeviceID:     id,
+		Neighbors:    neighbors,
+		LinksCreated: created,
+	})
+}
+
+// handleResolveDeviceDNS runs a reverse DNS lookup for a device on demand.
+//
+//	@Summary		Resolve device hostname
+//	@Description	Looks up PTR records for the device's IP addresses and fills its hostname if it is blank. A manually set hostname is never overwritten.
+//	@Tags			recon
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string				true	"Device ID"
+//	@Success		200	{object}	ResolveDNSResponse
+//	@Failure		400	{object}	models.APIProblem
+//	@Failure		404	{object}	models.APIProblem
+//	@Failure		500	{object}	models.APIProblem
+//	@Failure		503	{object}	models.APIProblem
+//	@Router			/recon/devices/{id}/resolve-dns [post]
+func (m *Module) handleResolveDeviceDNS(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	device, err := m.store.GetDevice(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "device not found")
+		return
+	}
+	if err != nil {
+		m.logger.Error("failed to get device", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to get device")
+		return
+	}
+	if len(device.IPAddresses) == 0 {
+		writeError(w, http.StatusBadRequest, "device has no IP addresses")
+		return
+	}
+	if m.reverseDNS == nil {
+		writeError(w, http.StatusServiceUnavailable, "reverse DNS not available")
+		return
+	}
+
+	name, updated, err := m.reverseDNS.ResolveDevice(r.Context(), id, device.IPAddresses)
+	if err != nil {
+		m.logger.Error("failed to store resolved hostname", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to update hostname")
+		return
+	}
+	hostname := device.Hostname
+	if updated {
+		hostname = name
+	}
+	source, err := m.store.GetHostnameSource(r.Context(), id)
+	if err != nil {
+		m.logger.Error("failed to get hostname source", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to get device")
+		return
+	}
+	writeJSON(w, http.StatusOK, ResolveDNSResponse{
+		DeviceID:       id,
+		ResolvedName:   name,
+		Hostname:       hostname,
+		HostnameSource: source,
+		Updated:        updated,
+	})
+}
+
 // findSNMPCredential looks up the first SNMP credential associated with a device.
 func (m *Module) findSNMPCredential(ctx context.Context, deviceID string) (string, error) {
 	if m.credProvider == nil {
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/HerbHall/subnetree/internal/store"
 	"github.com/HerbHall/subnetree/pkg/models"
@@ -177,6 +178,12 @@ func TestHandleListScans_WithData(t *testing.T) {
 	if len(scans) != 2 {
 		t.Errorf("scan count = %d, want 2 (paginated)", len(scans))
 	}
+	if got := w.Header().Get("X-Total-Count"); got != "3" {
+		t.Errorf("X-Total-Count = %q, want 3", got)
+	}
+	if got, want := w.Header().Get("Link"), `</scans?limit=2&offset=2>; rel="next", </scans?limit=2&offset=2>; rel="last"`; got != want {
+		t.Errorf("Link = %q, want %q", got, want)
+	}
 }
 
 func TestHandleGetScan_Found(t *testing.T) {
@@ -409,6 +416,7 @@ func deviceMux(m *Module) *http.ServeMux {
 	mux.HandleFunc("GET /devices", m.handleListDevices)
 	mux.HandleFunc("POST /devices", m.handleCreateDevice)
 	mux.HandleFunc("PATCH /devices/bulk", m.handleBulkUpdateDevices)
+	mux.HandleFunc("POST /devices/bulk-tags", m.handleBulkTags)
 	mux.HandleFunc("GET /devices/{id}", m.handleGetDevice)
 	mux.HandleFunc("PUT /devices/{id}", m.handleUpdateDevice)
 	mux.HandleFunc("DELETE /devices/{id}", m.handleDeleteDevice)
@@ -476,6 +484,58 @@ func TestHandleListDevices_WithDevices(t *testing.T) {
 	}
 }
 
+func TestHandleListDevices_ETag(t *testing.T) {
+	m := newTestModule(t)
+	ctx := context.Background()
+	mux := deviceMux(m)
+	mux.HandleFunc("GET /topology", m.handleTopology)
+
+	d := &models.Device{
+		Hostname:        "nas",
+		IPAddresses:     []string{"10.0.0.5"},
+		MACAddress:      "AA:BB:CC:DD:EE:05",
+		Status:          models.DeviceStatusOnline,
+		DiscoveryMethod: models.DiscoveryICMP,
+	}
+	if _, err := m.store.UpsertDevice(ctx, d); err != nil {
+		t.Fatalf("UpsertDevice: %v", err)
+	}
+
+	get := func(path, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, http.NoBody)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, path := range []string{"/devices", "/topology"} {
+		first := get(path, "")
+		etag := first.Header().Get("ETag")
+		if first.Code != http.StatusOK || !strings.HasPrefix(etag, `W/"`) {
+			t.Fatalf("%s: status = %d, ETag = %q; want 200 with a weak ETag", path, first.Code, etag)
+		}
+		if w := get(path, etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("%s: conditional GET status = %d (body %d bytes), want empty 304", path, w.Code, w.Body.Len())
+		}
+	}
+
+	before := get("/devices", "").Header().Get("ETag")
+	// Same device count, one changed field: the tag must still change.
+	if err := m.store.UpdateDeviceStatus(ctx, d.ID, models.DeviceStatusOffline, time.Now()); err != nil {
+		t.Fatalf("UpdateDeviceStatus: %v", err)
+	}
+	w := get("/devices", before)
+	if w.Code != http.StatusOK {
+		t.Fatalf("after change: status = %d, want 200", w.Code)
+	}
+	if w.Header().Get("ETag") == before {
+		t.Error("ETag unchanged after a device field changed")
+	}
+}
+
 func TestHandleListDevices_FilterByStatus(t *testing.T) {
 	m := newTestModule(t)
 	ctx := context.Background()
@@ -627,6 +687,41 @@ func TestHandleUpdateDevice_NotFound(t *testing.T) {
 	}
 }
 
+func TestHandleUpdateDevice_UnknownDeviceType(t *testing.T) {
+	m := newTestModule(t)
+	ctx := context.Background()
+	mux := deviceMux(m)
+
+	d := &models.Device{
+		Hostname: "typo", IPAddresses: []string{"10.0.0.1"},
+		MACAddress: "AA:BB:CC:DD:EE:01", DeviceType: models.DeviceTypeRouter,
+		DiscoveryMethod: models.DiscoveryICMP,
+	}
+	_, _ = m.store.UpsertDevice(ctx, d)
+
+	for _, tc := range []struct{ method, path, body string }{
+		{"PUT", "/devices/" + d.ID, `{"device_type":"reuter"}`},
+		{"PATCH", "/devices/bulk", `{"device_ids":["` + d.ID + `"],"updates":{"device_type":"reuter"}}`},
+		{"POST", "/devices", `{"hostname":"new","device_type":"reuter"}`},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s %s: status = %d, want %d", tc.method, tc.path, w.Code, http.StatusBadRequest)
+		}
+	}
+
+	got, err := m.store.GetDevice(ctx, d.ID)
+	if err != nil {
+		t.Fatalf("GetDevice: %v", err)
+	}
+	if got.DeviceType != models.DeviceTypeRouter {
+		t.Errorf("DeviceType = %q, want router left unchanged", got.DeviceType)
+	}
+}
+
 func TestHandleUpdateDevice_InvalidJSON(t *testing.T) {
 	m := newTestModule(t)
 	mux := deviceMux(m)
@@ -670,6 +765,55 @@ func TestHandleDeleteDevice_Success(t *testing.T) {
 	}
 }
 
+func TestHandleDeleteDevice_PurgeAndRestore(t *testing.T) {
+	m := newTestModule(t)
+	ctx := context.Background()
+	mux := http.NewServeMux()
+	mux.HandleFunc("DELETE /devices/{id}", m.handleDeleteDevice)
+	mux.HandleFunc("GET /devices/trash", m.handleListDeletedDevices)
+	mux.HandleFunc("POST /devices/{id}/restore", m.handleRestoreDevice)
+
+	d := &models.Device{
+		Hostname: "fat-finger", IPAddresses: []string{"10.0.0.7"},
+		MACAddress: "AA:BB:CC:DD:EE:07", Status: models.DeviceStatusOnline,
+		DiscoveryMethod: models.DiscoveryICMP,
+	}
+	_, _ = m.store.UpsertDevice(ctx, d)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("DELETE", "/devices/"+d.ID, http.NoBody))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("delete: status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/devices/trash", http.NoBody))
+	var trash []DeletedDevice
+	if err := json.NewDecoder(w.Body).Decode(&trash); err != nil {
+		t.Fatalf("decode trash: %v", err)
+	}
+	if len(trash) != 1 || trash[0].ID != d.ID {
+		t.Fatalf("trash = %+v, want the deleted device", trash)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/devices/"+d.ID+"/restore", http.NoBody))
+	if w.Code != http.StatusOK {
+		t.Fatalf("restore: status = %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("DELETE", "/devices/"+d.ID+"?purge=true", http.NoBody))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("purge: status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/devices/"+d.ID+"/restore", http.NoBody))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("restore after purge: status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
 func TestHandleDeleteDevice_NotFound(t *testing.T) {
 	m := newTestModule(t)
 	mux := deviceMux(m)
@@ -938,6 +1082,60 @@ func TestHandleBulkUpdateDevices_EmptyIDs(t *testing.T) {
 	}
 }
 
+func TestHandleBulkTags(t *testing.T) {
+	m := newTestModule(t)
+	ctx := context.Background()
+	mux := deviceMux(m)
+
+	d := &models.Device{
+		Hostname: "tagged", IPAddresses: []string{"10.0.0.1"},
+		MACAddress: "AA:BB:CC:DD:EE:01", Status: models.DeviceStatusOnline,
+		DiscoveryMethod: models.DiscoveryICMP, Tags: []string{"prod"},
+	}
+	_, _ = m.store.UpsertDevice(ctx, d)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/devices/bulk-tags", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(`{"device_ids":["` + d.ID + `"],"tags":["audited"," "],"op":"add"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("add status = %d; body: %s", w.Code, w.Body.String())
+	}
+	var resp BulkUpdateResponse
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Updated != 1 {
+		t.Errorf("add updated = %d, want 1", resp.Updated)
+	}
+	got, _ := m.store.GetDevice(ctx, d.ID)
+	if strings.Join(got.Tags, ",") != "prod,audited" {
+		t.Errorf("tags after add = %v, want [prod audited]", got.Tags)
+	}
+
+	if w := post(`{"device_ids":["` + d.ID + `"],"tags":["prod"],"op":"remove"}`); w.Code != http.StatusOK {
+		t.Fatalf("remove status = %d; body: %s", w.Code, w.Body.String())
+	}
+	got, _ = m.store.GetDevice(ctx, d.ID)
+	if strings.Join(got.Tags, ",") != "audited" {
+		t.Errorf("tags after remove = %v, want [audited]", got.Tags)
+	}
+
+	for _, body := range []string{
+		`not json`,
+		`{"device_ids":[],"tags":["a"],"op":"add"}`,
+		`{"device_ids":["` + d.ID + `"],"tags":[""],"op":"add"}`,
+		`{"device_ids":["` + d.ID + `"],"tags":["a"],"op":"replace"}`,
+	} {
+		if w := post(body); w.Code != http.StatusBadRequest {
+			t.Errorf("POST %s status = %d, want 400", body, w.Code)
+		}
+	}
+}
+
 func TestHandleListDevices_FilterByCategory(t *testing.T) {
 	m := newTestModule(t)
 	ctx := context.Background()
@@ -18,6 +18,7 @@ type ProfileSource interface {
 	GetAgent(ctx context.Context, agentID string) (*AgentInfo, error)
 	GetHardwareProfile(ctx context.Context, agentID string) (*HardwareProfileData, error)
 	GetServices(ctx context.Context, agentID string) ([]*ServiceData, error)
+	GetContainers(ctx context.Context, agentID string) ([]ContainerData, error)
 }
 
 // AgentInfo is the minimal agent data needed for profile bridging.
@@ -234,6 +235,9 @@ func (m *Module) handleDeviceProfiled(ctx context.Context, evt plugin.Event) {
 		}
 	}
 
+	// Mirror Docker containers as child devices of the host.
+	m.bridgeContainers(ctx, agent)
+
 	// Publish recon.device.hardware.updated event.
 	m.publishEvent(ctx, TopicDeviceHardwareUpdated, DeviceHardwareUpdatedEvent{
 		DeviceID:         agent.DeviceID,
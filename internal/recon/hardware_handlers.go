@@ -235,6 +235,37 @@ func (m *Module) handleGetDeviceServices(w http.ResponseWriter, r *http.Request)
 	writeJSON(w, http.StatusOK, svcs)
 }
 
+// handleGetDevicePorts returns the open ports recorded for a device.
+//
+//	@Summary		Get device open ports
+//	@Description	Returns the open ports found on a device by network scans.
+//	@Tags			recon
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Device ID"
+//	@Success		200	{array}		models.DevicePort
+//	@Failure		400	{object}	models.APIProblem
+//	@Failure		500	{object}	models.APIProblem
+//	@Router			/recon/devices/{id}/ports [get]
+func (m *Module) handleGetDevicePorts(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "device ID is required")
+		return
+	}
+
+	ports, err := m.store.GetDevicePorts(r.Context(), id)
+	if err != nil {
+		m.logger.Error("failed to get device ports", zap.String("device_id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to get device ports")
+		return
+	}
+	if ports == nil {
+		ports = []models.DevicePort{}
+	}
+	writeJSON(w, http.StatusOK, ports)
+}
+
 // handleHardwareSummary returns fleet-wide aggregate hardware statistics.
 //
 //	@Summary		Hardware inventory summary
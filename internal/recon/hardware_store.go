@@ -63,20 +63,33 @@ func (s *ReconStore) UpsertDeviceHardware(ctx context.Context, hw *models.Device
 		return nil
 	}
 
-	// Normal upsert (INSERT OR REPLACE).
+	// Normal upsert: every column is overwritten.
 	collectedAt := &now
 	if hw.CollectedAt != nil {
 		collectedAt = hw.CollectedAt
 	}
 
-	_, err = s.db.ExecContext(ctx, `INSERT OR REPLACE INTO recon_device_hardware (
+	_, err = s.db.ExecContext(ctx, `INSERT INTO recon_device_hardware (
 		device_id, hostname, fqdn, os_name, os_version, os_arch, kernel,
 		cpu_model, cpu_cores, cpu_threads, cpu_arch,
 		ram_total_mb, ram_type, ram_slots_used, ram_slots_total,
 		platform_type, hypervisor, vm_host_id,
 		system_manufacturer, system_model, serial_number, bios_version,
 		collection_source, collected_at, updated_at
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(device_id) DO UPDATE SET
+		hostname = excluded.hostname, fqdn = excluded.fqdn,
+		os_name = excluded.os_name, os_version = excluded.os_version,
+		os_arch = excluded.os_arch, kernel = excluded.kernel,
+		cpu_model = excluded.cpu_model, cpu_cores = excluded.cpu_cores,
+		cpu_threads = excluded.cpu_threads, cpu_arch = excluded.cpu_arch,
+		ram_total_mb = excluded.ram_total_mb, ram_type = excluded.ram_type,
+		ram_slots_used = excluded.ram_slots_used, ram_slots_total = excluded.ram_slots_total,
+		platform_type = excluded.platform_type, hypervisor = excluded.hypervisor,
+		vm_host_id = excluded.vm_host_id, system_manufacturer = excluded.system_manufacturer,
+		system_model = excluded.system_model, serial_number = excluded.serial_number,
+		bios_version = excluded.bios_version, collection_source = excluded.collection_source,
+		collected_at = excluded.collected_at, updated_at = excluded.updated_at`,
 		hw.DeviceID, hw.Hostname, hw.FQDN, hw.OSName, hw.OSVersion, hw.OSArch, hw.Kernel,
 		hw.CPUModel, hw.CPUCores, hw.CPUThreads, hw.CPUArch,
 		hw.RAMTotalMB, hw.RAMType, hw.RAMSlotsUsed, hw.RAMSlotsTotal,
@@ -444,7 +457,7 @@ func (s *ReconStore) QueryDevicesByHardware(ctx context.Context, q models.Hardwa
 		q.Limit = 50
 	}
 
-	where := []string{"1=1"}
+	where := []string{"d.deleted_at IS NULL"}
 	args := []any{}
 
 	if q.MinRAMMB > 0 {
@@ -504,7 +517,7 @@ func (s *ReconStore) QueryDevicesByHardware(ctx context.Context, q models.Hardwa
 		"d.parent_device_id, d.network_layer " +
 		"FROM recon_devices d " +
 		"JOIN recon_device_hardware h ON h.device_id = d.id " +
-		"WHERE " + whereClause + " ORDER BY d.last_seen DESC LIMIT ? OFFSET ?"
+		"WHERE " + whereClause + " ORDER BY d.last_seen DESC, d.id LIMIT ? OFFSET ?"
 
 	rows, err := s.db.QueryContext(ctx, dataQuery, queryArgs...)
 	if err != nil {
@@ -1086,8 +1086,8 @@ func TestCascadeDeleteDevice_RemovesAllHardware(t *testing.T) {
 	}
 
 	// Delete the parent device -- CASCADE should remove all children.
-	if err := s.DeleteDevice(ctx, device.ID); err != nil {
-		t.Fatalf("DeleteDevice: %v", err)
+	if err := s.PurgeDevice(ctx, device.ID); err != nil {
+		t.Fatalf("PurgeDevice: %v", err)
 	}
 
 	// Verify all hardware tables are empty for this device.
@@ -85,6 +85,8 @@ func InferHierarchyFromData(devices []models.Device, links []TopologyLink) []Hie
 	linksToTarget := make(map[string][]string)
 	// fdbLinksFromSwitch[switchID] = list of target device IDs (FDB only)
 	fdbLinksFromSwitch := make(map[string][]string)
+	// dhcpServer[deviceID] = router that leased the device its address
+	dhcpServer := make(map[string]string)
 
 	for i := range links {
 		src := links[i].SourceDeviceID
@@ -95,6 +97,9 @@ func InferHierarchyFromData(devices []models.Device, links []TopologyLink) []Hie
 		if links[i].LinkType == "fdb" {
 			fdbLinksFromSwitch[src] = append(fdbLinksFromSwitch[src], tgt)
 		}
+		if links[i].LinkType == "dhcp" {
+			dhcpServer[src] = tgt
+		}
 	}
 
 	assignments := make(map[string]*HierarchyAssignment, len(devices))
@@ -258,7 +263,8 @@ func InferHierarchyFromData(devices []models.Device, links []TopologyLink) []Hie
 		a.NetworkLayer = models.NetworkLayerEndpoint
 	}
 
-	// Assign parentless non-infrastructure devices to the gateway.
+	// Assign parentless non-infrastructure devices to the router that
+	// leased them their address, else to the gateway.
 	for i := range devices {
 		a := assignments[devices[i].ID]
 		if a.ParentDeviceID != "" {
@@ -267,6 +273,10 @@ func InferHierarchyFromData(devices []models.Device, links []TopologyLink) []Hie
 		if a.NetworkLayer == models.NetworkLayerGateway {
 			continue
 		}
+		if server, ok := dhcpServer[devices[i].ID]; ok && deviceByID[server] != nil {
+			a.ParentDeviceID = server
+			continue
+		}
 		if firstRouterID != "" && devices[i].ID != firstRouterID {
 			a.ParentDeviceID = firstRouterID
 		}
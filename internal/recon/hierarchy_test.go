@@ -213,6 +213,25 @@ func TestInferHierarchyFromData(t *testing.T) {
 				assertParent(t, m, "pc-2", "switch-b")
 			},
 		},
+		{
+			name: "DHCP lease links parent endpoints to the leasing router",
+			devices: []models.Device{
+				{ID: "router-1", DeviceType: models.DeviceTypeRouter},
+				{ID: "router-2", DeviceType: models.DeviceTypeRouter},
+				{ID: "laptop-1", DeviceType: models.DeviceTypeLaptop},
+				{ID: "phone-1", DeviceType: models.DeviceTypePhone},
+			},
+			links: []TopologyLink{
+				{SourceDeviceID: "laptop-1", TargetDeviceID: "router-2", LinkType: "dhcp"},
+			},
+			check: func(t *testing.T, result []HierarchyAssignment) {
+				t.Helper()
+				m := assignmentMap(result)
+
+				assertParent(t, m, "laptop-1", "router-2")
+				assertParent(t, m, "phone-1", "router-1")
+			},
+		},
 	}
 
 	for _, tc := range tests {
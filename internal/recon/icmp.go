@@ -38,6 +38,18 @@ func NewICMPScanner(cfg ReconConfig, logger *zap.Logger) *ICMPScanner {
 	}
 }
 
+// WithTuning returns a copy of the scanner with different concurrency and
+// per-host ping settings. A zero count keeps the current ping count.
+func (s *ICMPScanner) WithTuning(concurrency int, timeout time.Duration, count int) PingScanner {
+	tuned := *s
+	tuned.concurrency = concurrency
+	tuned.pingTimeout = timeout
+	if count > 0 {
+		tuned.pingCount = count
+	}
+	return &tuned
+}
+
 // Scan pings all hosts in the given subnet and sends alive hosts to results.
 // The caller must close the results channel after Scan returns.
 func (s *ICMPScanner) Scan(ctx context.Context, subnet *net.IPNet, results chan<- HostResult) error {
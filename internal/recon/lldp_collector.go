@@ -18,6 +18,7 @@ import (
 // lldpRemTable (1.0.8802.1.1.2.1.4.1) columns indexed by timeMark.localPortNum.index.
 const (
 	OIDLLDPRemSysDesc        = "1.0.8802.1.1.2.1.4.1.1.4"  // lldpRemSysDesc
+	OIDLLDPRemChassisID      = "1.0.8802.1.1.2.1.4.1.1.5"  // lldpRemChassisId
 	OIDLLDPRemPortID         = "1.0.8802.1.1.2.1.4.1.1.7"  // lldpRemPortId
 	OIDLLDPRemPortDesc       = "1.0.8802.1.1.2.1.4.1.1.8"  // lldpRemPortDesc
 	OIDLLDPRemSysName        = "1.0.8802.1.1.2.1.4.1.1.9"  // lldpRemSysName
@@ -40,14 +41,15 @@ const (
 
 // LLDPNeighbor holds information about a single LLDP neighbor discovered on a device.
 type LLDPNeighbor struct {
-	LocalPort      string // Local port that sees this neighbor
-	RemoteSysName  string // Neighbor hostname
-	RemoteSysDesc  string // Neighbor system description
-	RemotePortID   string // Neighbor port identifier
-	RemotePortDesc string // Neighbor port description
-	RemoteManAddr  string // Neighbor management IP
-	CapSupported   uint16 // Capabilities bitmap (supported)
-	CapEnabled     uint16 // Capabilities bitmap (enabled)
+	LocalPort       string `json:"local_port"`        // Local port that sees this neighbor
+	RemoteChassisID string `json:"remote_chassis_id"` // Neighbor chassis identifier (usually a MAC)
+	RemoteSysName   string `json:"remote_sys_name"`   // Neighbor hostname
+	RemoteSysDesc   string `json:"remote_sys_desc"`   // Neighbor system description
+	RemotePortID    string `json:"remote_port_id"`    // Neighbor port identifier
+	RemotePortDesc  string `json:"remote_port_desc"`  // Neighbor port description
+	RemoteManAddr   string `json:"remote_man_addr"`   // Neighbor management IP
+	CapSupported    uint16 `json:"cap_supported"`     // Capabilities bitmap (supported)
+	CapEnabled      uint16 `json:"cap_enabled"`       // Capabilities bitmap (enabled)
 }
 
 // LLDPCollector discovers LLDP neighbors via SNMP queries to the LLDP-MIB.
@@ -87,6 +89,7 @@ func (c *LLDPCollector) DiscoverNeighbors(g *gosnmp.GoSNMP) ([]LLDPNeighbor, err
 	// Walk all columns of the lldpRemTable.
 	remTableOIDs := []string{
 		OIDLLDPRemSysDesc,
+		OIDLLDPRemChassisID,
 		OIDLLDPRemPortID,
 		OIDLLDPRemPortDesc,
 		OIDLLDPRemSysName,
@@ -130,6 +133,8 @@ func (c *LLDPCollector) DiscoverNeighbors(g *gosnmp.GoSNMP) ([]LLDPNeighbor, err
 			switch oidPrefix {
 			case OIDLLDPRemSysDesc:
 				entry.neighbor.RemoteSysDesc = parsePDUString(pdu)
+			case OIDLLDPRemChassisID:
+				entry.neighbor.RemoteChassisID = parseLLDPPortID(pdu)
 			case OIDLLDPRemPortID:
 				entry.neighbor.RemotePortID = parseLLDPPortID(pdu)
 			case OIDLLDPRemPortDesc:
@@ -180,9 +185,10 @@ func (c *LLDPCollector) DiscoverNeighbors(g *gosnmp.GoSNMP) ([]LLDPNeighbor, err
 }
 
 // BuildTopologyFromLLDP creates topology links from LLDP neighbor data.
-// For each neighbor that can be matched to an existing device (by management IP
-// or hostname), a topology link with link_type "lldp" is created.
-// Returns the number of links created.
+// For each neighbor that can be matched to an existing device (by management IP,
+// chassis or port MAC, or hostname), a topology link with link_type "lldp" is
+// created. LLDP links win over inferred ones, so ARP links for the source and
+// every matched neighbor are removed. Returns the number of links created.
 func (c *LLDPCollector) BuildTopologyFromLLDP(ctx context.Context, reconStore *ReconStore, neighbors []LLDPNeighbor, sourceDeviceID string) (int, error) {
 	created := 0
 
@@ -197,6 +203,22 @@ func (c *LLDPCollector) BuildTopologyFromLLDP(ctx context.Context, reconStore *R
 			}
 		}
 
+		// Chassis and port IDs are MAC addresses on most switches and on
+		// hosts running lldpd.
+		for _, id := range []string{n.RemoteChassisID, n.RemotePortID} {
+			if targetDevice != nil {
+				break
+			}
+			mac, err := net.ParseMAC(id)
+			if err != nil || len(mac) != 6 {
+				continue
+			}
+			d, err := reconStore.GetDeviceByMAC(ctx, strings.ToUpper(mac.String()))
+			if err == nil && d != nil {
+				targetDevice = d
+			}
+		}
+
 		// Fall back to hostname matching.
 		if targetDevice == nil && n.RemoteSysName != "" {
 			d, err := reconStore.GetDeviceByHostname(ctx, n.RemoteSysName)
@@ -231,6 +253,10 @@ func (c *LLDPCollector) BuildTopologyFromLLDP(ctx context.Context, reconStore *R
 		}
 		created++
 
+		if err := reconStore.RemoveARPLinksForDevice(ctx, targetDevice.ID); err != nil {
+			return created, err
+		}
+
 		c.logger.Debug("LLDP topology link created",
 			zap.String("source", sourceDeviceID),
 			zap.String("target", targetDevice.ID),
@@ -239,9 +265,39 @@ func (c *LLDPCollector) BuildTopologyFromLLDP(ctx context.Context, reconStore *R
 		)
 	}
 
+	if created > 0 {
+		if err := reconStore.RemoveARPLinksForDevice(ctx, sourceDeviceID); err != nil {
+			return created, err
+		}
+	}
+
 	return created, nil
 }
 
+// WalkLLDP connects to target with the given SNMP credential and returns the
+// neighbors in its LLDP-MIB remote table.
+func (c *SNMPCollector) WalkLLDP(ctx context.Context, target string, cred CredentialAccessor, credID string) ([]LLDPNeighbor, error) {
+	if cred == nil || credID == "" {
+		return nil, fmt.Errorf("SNMP credential required for LLDP walk")
+	}
+
+	credential, err := cred.GetCredential(ctx, credID)
+	if err != nil {
+		return nil, fmt.Errorf("get SNMP credential %s: %w", credID, err)
+	}
+
+	g, err := c.newGoSNMP(target, credential)
+	if err != nil {
+		return nil, fmt.Errorf("configure SNMP: %w", err)
+	}
+	if err := g.Connect(); err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", target, err)
+	}
+	defer func() { _ = g.Conn.Close() }()
+
+	return NewLLDPCollector(c.logger).DiscoverNeighbors(g)
+}
+
 // extractLLDPIndex parses the 3-part index (timeMark.localPortNum.index) from
 // an LLDP-MIB OID. Returns the composite key and the local port number string.
 //
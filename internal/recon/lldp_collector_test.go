@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/gosnmp/gosnmp"
+	"go.uber.org/zap"
 
 	"github.com/HerbHall/subnetree/internal/store"
 	"github.com/HerbHall/subnetree/pkg/models"
@@ -557,3 +558,86 @@ func TestBuildTopologyFromLLDP_MatchByHostname(t *testing.T) {
 		t.Errorf("links created = %d, want 1", created)
 	}
 }
+
+// fakeLLDPWalker returns a fixed neighbor table.
+type fakeLLDPWalker struct {
+	neighbors []LLDPNeighbor
+}
+
+func (f *fakeLLDPWalker) WalkLLDP(_ context.Context, _ string, _ CredentialAccessor, _ string) ([]LLDPNeighbor, error) {
+	return f.neighbors, nil
+}
+
+func TestPollLLDP_ReplacesInferredLinks(t *testing.T) {
+	reconStore := testStore(t)
+	ctx := context.Background()
+
+	sw := &models.Device{
+		ID:              "switch-01",
+		Hostname:        "core-switch",
+		IPAddresses:     []string{"192.168.1.2"},
+		DeviceType:      models.DeviceTypeSwitch,
+		Status:          models.DeviceStatusOnline,
+		DiscoveryMethod: models.DiscoverySNMP,
+	}
+	host := &models.Device{
+		ID:              "nas-01",
+		Hostname:        "nas",
+		IPAddresses:     []string{"192.168.1.20"},
+		MACAddress:      "AA:BB:CC:00:11:22",
+		DeviceType:      models.DeviceTypeNAS,
+		Status:          models.DeviceStatusOnline,
+		DiscoveryMethod: models.DiscoveryICMP,
+	}
+	gateway := &models.Device{
+		ID:              "gw-01",
+		IPAddresses:     []string{"192.168.1.1"},
+		DeviceType:      models.DeviceTypeRouter,
+		Status:          models.DeviceStatusOnline,
+		DiscoveryMethod: models.DiscoveryICMP,
+	}
+	for _, d := range []*models.Device{sw, host, gateway} {
+		if _, err := reconStore.UpsertDevice(ctx, d); err != nil {
+			t.Fatalf("seed device %s: %v", d.ID, err)
+		}
+	}
+
+	// Inferred ARP link and a stale LLDP link to the gateway.
+	for _, l := range []*TopologyLink{
+		{SourceDeviceID: host.ID, TargetDeviceID: gateway.ID, LinkType: "arp"},
+		{SourceDeviceID: sw.ID, TargetDeviceID: gateway.ID, LinkType: "lldp"},
+	} {
+		if err := reconStore.UpsertTopologyLink(ctx, l); err != nil {
+			t.Fatalf("seed link: %v", err)
+		}
+	}
+
+	// The neighbor only advertises its chassis MAC, lowercased.
+	o := NewScanOrchestrator(reconStore, nil, nil, nil, nil, zap.NewNop())
+	o.SetLLDPWalker(&fakeLLDPWalker{neighbors: []LLDPNeighbor{
+		{LocalPort: "7", RemoteChassisID: "aa:bb:cc:00:11:22", RemotePortID: "eth0"},
+	}})
+
+	neighbors, created, err := o.PollLLDP(ctx, sw, "cred-1")
+	if err != nil {
+		t.Fatalf("PollLLDP: %v", err)
+	}
+	if len(neighbors) != 1 || created != 1 {
+		t.Fatalf("neighbors = %d, created = %d, want 1, 1", len(neighbors), created)
+	}
+
+	links, err := reconStore.GetTopologyLinks(ctx)
+	if err != nil {
+		t.Fatalf("GetTopologyLinks: %v", err)
+	}
+	if len(links) != 1 {
+		t.Fatalf("links = %+v, want only the new LLDP link", links)
+	}
+	l := links[0]
+	if l.LinkType != "lldp" || l.SourceDeviceID != sw.ID || l.TargetDeviceID != host.ID {
+		t.Errorf("link = %s %s->%s, want lldp %s->%s", l.LinkType, l.SourceDeviceID, l.TargetDeviceID, sw.ID, host.ID)
+	}
+	if l.SourcePort != "7" || l.TargetPort != "eth0" {
+		t.Errorf("ports = %q/%q, want 7/eth0", l.SourcePort, l.TargetPort)
+	}
+}
@@ -0,0 +1,302 @@
+package recon
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/llm"
+	"github.com/HerbHall/subnetree/pkg/models"
+	"go.uber.org/zap"
+)
+
+// ClassificationSourceLLM marks classifications proposed by the LLM.
+const ClassificationSourceLLM = "llm"
+
+// ErrLLMUnavailable is returned by ClassifyDeviceWithLLM when no LLM provider is configured.
+var ErrLLMUnavailable = errors.New("llm provider not configured")
+
+const llmClassifierSystemPrompt = `You classify devices found on a home or small-office network.
+Given what is known about a device, choose the single best device_type from this list:
+%s
+
+Respond with ONLY a JSON object, no prose or code fences:
+{"device_type": "<one of the list>", "confidence": <integer 0-100>, "rationale": "<one or two sentences>"}
+
+Use "unknown" with low confidence when the evidence is insufficient.`
+
+// classifiableDeviceTypes lists the device types the LLM may propose.
+var classifiableDeviceTypes = []models.DeviceType{
+	models.DeviceTypeServer,
+	models.DeviceTypeDesktop,
+	models.DeviceTypeLaptop,
+	models.DeviceTypeMobile,
+	models.DeviceTypeRouter,
+	models.DeviceTypeSwitch,
+	models.DeviceTypePrinter,
+	models.DeviceTypeIoT,
+	models.DeviceTypeAccessPoint,
+	models.DeviceTypeFirewall,
+	models.DeviceTypeNAS,
+	models.DeviceTypePhone,
+	models.DeviceTypeTablet,
+	models.DeviceTypeCamera,
+	models.DeviceTypeVM,
+	models.DeviceTypeContainer,
+	models.DeviceTypeUnknown,
+}
+
+// LLMClassification is the result of an LLM-assisted classification.
+type LLMClassification struct {
+	DeviceID   string            `json:"device_id"`
+	DeviceType models.DeviceType `json:"device_type"`
+	Confidence int               `json:"confidence"`
+	Rationale  string            `json:"rationale"`
+	Model      string            `json:"model,omitempty"`
+	Cached     bool              `json:"cached"`
+	Applied    bool              `json:"applied"` // True if the device classification was updated.
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+// llmClassifierInput is the device evidence sent to the LLM. Its hash keys the cache.
+type llmClassifierInput struct {
+	Hostname     string   `json:"hostname,omitempty"`
+	Manufacturer string   `json:"manufacturer,omitempty"`
+	OS           string   `json:"os,omitempty"`
+	MACAddress   string   `json:"mac_address,omitempty"`
+	Services     []string `json:"services,omitempty"`
+}
+
+// SetLLMProvider sets the LLM provider used for LLM-assisted classification.
+// Called from the composition root after all plugins are initialized.
+func (m *Module) SetLLMProvider(p llm.Provider) {
+	m.llmProvider = p
+}
+
+// ClassifyDeviceWithLLM asks the LLM to propose a device type for the device.
+// Results are cached per device and reused while the device evidence is
+// unchanged. The device is only reclassified when the LLM's confidence beats
+// the existing classification confidence.
+func (m *Module) ClassifyDeviceWithLLM(ctx context.Context, deviceID string) (*LLMClassification, error) {
+	if m.llmProvider == nil {
+		return nil, ErrLLMUnavailable
+	}
+
+	device, err := m.store.GetDevice(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	services, err := m.store.GetDeviceServices(ctx, deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("get device services: %w", err)
+	}
+
+	input := buildLLMClassifierInput(device, services)
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("marshal classifier input: %w", err)
+	}
+	sum := sha256.Sum256(inputJSON)
+	inputHash := hex.EncodeToString(sum[:])
+
+	result, err := m.store.GetLLMClassification(ctx, deviceID, inputHash)
+	if err != nil {
+		return nil, err
+	}
+	if result != nil {
+		result.Cached = true
+	} else {
+		result, err = m.queryLLMClassification(ctx, inputJSON)
+		if err != nil {
+			return nil, err
+		}
+		result.DeviceID = deviceID
+		result.CreatedAt = time.Now().UTC()
+		if err := m.store.SaveLLMClassification(ctx, result, inputHash); err != nil {
+			return nil, err
+		}
+	}
+
+	// Same merge rule as UpsertDevice: only a higher confidence wins.
+	if result.DeviceType != models.DeviceTypeUnknown && result.Confidence > device.ClassificationConfidence {
+		signals, _ := json.Marshal([]ClassificationSignal{{
+			Source:     ClassificationSourceLLM,
+			DeviceType: result.DeviceType,
+			Weight:     result.Confidence,
+			Detail:     result.Rationale,
+		}})
+		if err := m.store.UpdateDeviceClassification(ctx, deviceID, result.DeviceType, result.Confidence, ClassificationSourceLLM, string(signals)); err != nil {
+			return nil, err
+		}
+		result.Applied = true
+	}
+
+	return result, nil
+}
+
+// handleClassifyDeviceLLM asks the configured LLM to classify a device.
+//
+//	@Summary		Classify device with LLM
+//	@Description	Proposes a device type from the device's services, hostname, manufacturer, and OS. The device is only reclassified when the LLM's confidence beats the existing classification.
+//	@Tags			recon
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Device ID"
+//	@Success		200	{object}	LLMClassification
+//	@Failure		404	{object}	models.APIProblem
+//	@Failure		502	{object}	models.APIProblem
+//	@Failure		503	{object}	models.APIProblem
+//	@Router			/recon/devices/{id}/classify-llm [post]
+func (m *Module) handleClassifyDeviceLLM(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "device ID is required")
+		return
+	}
+
+	result, err := m.ClassifyDeviceWithLLM(r.Context(), id)
+	switch {
+	case errors.Is(err, ErrLLMUnavailable):
+		writeError(w, http.StatusServiceUnavailable, "LLM provider not configured")
+		return
+	case errors.Is(err, sql.ErrNoRows):
+		writeError(w, http.StatusNotFound, "device not found")
+		return
+	case err != nil:
+		m.logger.Warn("llm classification failed", zap.String("device_id", id), zap.Error(err))
+		writeError(w, http.StatusBadGateway, "LLM classification failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// queryLLMClassification sends the device evidence to the LLM and parses its proposal.
+func (m *Module) queryLLMClassification(ctx context.Context, inputJSON []byte) (*LLMClassification, error) {
+	types := make([]string, len(classifiableDeviceTypes))
+	for i, dt := range classifiableDeviceTypes {
+		types[i] = string(dt)
+	}
+
+	messages := []llm.Message{
+		{Role: llm.RoleSystem, Content: fmt.Sprintf(llmClassifierSystemPrompt, strings.Join(types, ", "))},
+		{Role: llm.RoleUser, Content: string(inputJSON)},
+	}
+	resp, err := m.llmProvider.Chat(ctx, messages,
+		llm.WithTemperature(0.1),
+		llm.WithMaxTokens(256),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("llm classify: %w", err)
+	}
+
+	result, err := parseLLMClassification(resp.Content)
+	if err != nil {
+		return nil, err
+	}
+	result.Model = resp.Model
+	return result, nil
+}
+
+// parseLLMClassification extracts and validates the JSON object in an LLM reply.
+func parseLLMClassification(content string) (*LLMClassification, error) {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("LLM returned no JSON object")
+	}
+
+	var raw struct {
+		DeviceType string `json:"device_type"`
+		Confidence int    `json:"confidence"`
+		Rationale  string `json:"rationale"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("LLM returned invalid JSON: %w", err)
+	}
+
+	dt := models.DeviceType(strings.ToLower(strings.TrimSpace(raw.DeviceType)))
+	if !isClassifiableDeviceType(dt) {
+		return nil, fmt.Errorf("LLM returned unknown device type %q", raw.DeviceType)
+	}
+	return &LLMClassification{
+		DeviceType: dt,
+		Confidence: min(max(raw.Confidence, 0), 100),
+		Rationale:  strings.TrimSpace(raw.Rationale),
+	}, nil
+}
+
+func isClassifiableDeviceType(dt models.DeviceType) bool {
+	for _, t := range classifiableDeviceTypes {
+		if t == dt {
+			return true
+		}
+	}
+	return false
+}
+
+// buildLLMClassifierInput gathers the classification evidence for a device.
+func buildLLMClassifierInput(device *models.Device, services []models.DeviceService) llmClassifierInput {
+	input := llmClassifierInput{
+		Hostname:     device.Hostname,
+		Manufacturer: device.Manufacturer,
+		OS:           device.OS,
+		MACAddress:   device.MACAddress,
+	}
+	for i := range services {
+		svc := services[i].Name
+		if services[i].Port > 0 {
+			svc = fmt.Sprintf("%s (port %d)", svc, services[i].Port)
+		}
+		input.Services = append(input.Services, svc)
+	}
+	return input
+}
+
+// GetLLMClassification returns the cached LLM classification for a device if
+// it was produced from the same input. Returns nil, nil on a cache miss.
+func (s *ReconStore) GetLLMClassification(ctx context.Context, deviceID, inputHash string) (*LLMClassification, error) {
+	var c LLMClassification
+	var dt string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT device_id, device_type, confidence, rationale, model, created_at
+		FROM recon_llm_classifications
+		WHERE device_id = ? AND input_hash = ?`,
+		deviceID, inputHash,
+	).Scan(&c.DeviceID, &dt, &c.Confidence, &c.Rationale, &c.Model, &c.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get llm classification: %w", err)
+	}
+	c.DeviceType = models.DeviceType(dt)
+	return &c, nil
+}
+
+// SaveLLMClassification stores the latest LLM classification for a device,
+// replacing any previous cache entry.
+func (s *ReconStore) SaveLLMClassification(ctx context.Context, c *LLMClassification, inputHash string) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO recon_llm_classifications (device_id, input_hash, device_type, confidence, rationale, model, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(device_id) DO UPDATE SET
+			input_hash = excluded.input_hash,
+			device_type = excluded.device_type,
+			confidence = excluded.confidence,
+			rationale = excluded.rationale,
+			model = excluded.model,
+			created_at = excluded.created_at`,
+		c.DeviceID, inputHash, string(c.DeviceType), c.Confidence, c.Rationale, c.Model, c.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("save llm classification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package recon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/HerbHall/subnetree/pkg/llm"
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+// mockLLMProvider returns a canned chat reply and counts calls.
+type mockLLMProvider struct {
+	reply string
+	err   error
+	calls int
+}
+
+func (p *mockLLMProvider) Generate(_ context.Context, _ string, _ ...llm.CallOption) (*llm.Response, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *mockLLMProvider) Chat(_ context.Context, _ []llm.Message, _ ...llm.CallOption) (*llm.Response, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &llm.Response{Content: p.reply, Model: "test-model", Done: true}, nil
+}
+
+func insertClassifiableDevice(t *testing.T, m *Module, confidence int) *models.Device {
+	t.Helper()
+	device := &models.Device{
+		Hostname:                 "synology-ds920",
+		Manufacturer:             "Synology Inc.",
+		IPAddresses:              []string{"192.168.1.20"},
+		MACAddress:               "00:11:32:aa:bb:cc",
+		DeviceType:               models.DeviceTypeUnknown,
+		DiscoveryMethod:          models.DiscoveryARP,
+		ClassificationConfidence: confidence,
+		ClassificationSource:     "oui",
+	}
+	if _, err := m.store.UpsertDevice(context.Background(), device); err != nil {
+		t.Fatalf("UpsertDevice: %v", err)
+	}
+	return device
+}
+
+func TestClassifyDeviceWithLLM_AppliesAndCaches(t *testing.T) {
+	m := newTestModule(t)
+	provider := &mockLLMProvider{reply: "```json\n{\"device_type\":\"nas\",\"confidence\":80,\"rationale\":\"Synology hostname\"}\n```"}
+	m.SetLLMProvider(provider)
+	device := insertClassifiableDevice(t, m, 20)
+	ctx := context.Background()
+
+	result, err := m.ClassifyDeviceWithLLM(ctx, device.ID)
+	if err != nil {
+		t.Fatalf("ClassifyDeviceWithLLM: %v", err)
+	}
+	if result.DeviceType != models.DeviceTypeNAS || result.Confidence != 80 {
+		t.Errorf("result = %s/%d, want nas/80", result.DeviceType, result.Confidence)
+	}
+	if !result.Applied || result.Cached {
+		t.Errorf("applied = %v, cached = %v; want applied, not cached", result.Applied, result.Cached)
+	}
+
+	got, err := m.store.GetDevice(ctx, device.ID)
+	if err != nil {
+		t.Fatalf("GetDevice: %v", err)
+	}
+	if got.DeviceType != models.DeviceTypeNAS || got.ClassificationSource != ClassificationSourceLLM {
+		t.Errorf("device = %s (%s), want nas (llm)", got.DeviceType, got.ClassificationSource)
+	}
+
+	// A second call with unchanged evidence must come from the cache.
+	result, err = m.ClassifyDeviceWithLLM(ctx, device.ID)
+	if err != nil {
+		t.Fatalf("ClassifyDeviceWithLLM (cached): %v", err)
+	}
+	if !result.Cached {
+		t.Error("second call should be served from cache")
+	}
+	if provider.calls != 1 {
+		t.Errorf("provider calls = %d, want 1", provider.calls)
+	}
+}
+
+func TestClassifyDeviceWithLLM_KeepsHigherConfidence(t *testing.T) {
+	m := newTestModule(t)
+	m.SetLLMProvider(&mockLLMProvider{reply: `{"device_type":"server","confidence":40,"rationale":"guess"}`})
+	device := insertClassifiableDevice(t, m, 60)
+
+	result, err := m.ClassifyDeviceWithLLM(context.Background(), device.ID)
+	if err != nil {
+		t.Fatalf("ClassifyDeviceWithLLM: %v", err)
+	}
+	if result.Applied {
+		t.Error("lower-confidence result should not be applied")
+	}
+	got, _ := m.store.GetDevice(context.Background(), device.ID)
+	if got.ClassificationConfidence != 60 || got.ClassificationSource != "oui" {
+		t.Errorf("classification = %d (%s), want 60 (oui)", got.ClassificationConfidence, got.ClassificationSource)
+	}
+}
+
+func TestParseLLMClassification_Invalid(t *testing.T) {
+	tests := []string{
+		"no json here",
+		`{"device_type":"toaster","confidence":90}`,
+		`{"device_type":`,
+	}
+	for _, content := range tests {
+		if _, err := parseLLMClassification(content); err == nil {
+			t.Errorf("parseLLMClassification(%q) expected error", content)
+		}
+	}
+}
+
+func TestHandleClassifyDeviceLLM(t *testing.T) {
+	m := newTestModule(t)
+
+	// No provider configured.
+	req := httptest.NewRequest("POST", "/devices/x/classify-llm", http.NoBody)
+	req.SetPathValue("id", "x")
+	w := httptest.NewRecorder()
+	m.handleClassifyDeviceLLM(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("no provider: status = %d, want 503", w.Code)
+	}
+
+	m.SetLLMProvider(&mockLLMProvider{reply: `{"device_type":"nas","confidence":75,"rationale":"ok"}`})
+
+	// Unknown device.
+	w = httptest.NewRecorder()
+	m.handleClassifyDeviceLLM(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing device: status = %d, want 404", w.Code)
+	}
+
+	device := insertClassifiableDevice(t, m, 0)
+	req = httptest.NewRequest("POST", "/devices/"+device.ID+"/classify-llm", http.NoBody)
+	req.SetPathValue("id", device.ID)
+	w = httptest.NewRecorder()
+	m.handleClassifyDeviceLLM(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var result LLMClassification
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if result.DeviceType != models.DeviceTypeNAS || !result.Applied {
+		t.Errorf("result = %+v, want applied nas", result)
+	}
+}
@@ -372,5 +372,328 @@ func migrations() []plugin.Migration {
 				return err
 			},
 		},
+		{
+			Version:     14,
+			Description: "create recon_llm_classifications cache table",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS recon_llm_classifications (
+					device_id   TEXT PRIMARY KEY REFERENCES recon_devices(id) ON DELETE CASCADE,
+					input_hash  TEXT NOT NULL,
+					device_type TEXT NOT NULL,
+					confidence  INTEGER NOT NULL DEFAULT 0,
+					rationale   TEXT NOT NULL DEFAULT '',
+					model       TEXT NOT NULL DEFAULT '',
+					created_at  DATETIME NOT NULL
+				)`)
+				return err
+			},
+		},
+		{
+			Version:     15,
+			Description: "create recon_device_events table for syslog messages",
+			Up: func(tx *sql.Tx) error {
+				stmts := []string{
+					`CREATE TABLE IF NOT EXISTS recon_device_events (
+						id            INTEGER PRIMARY KEY AUTOINCREMENT,
+						device_id     TEXT NOT NULL REFERENCES recon_devices(id) ON DELETE CASCADE,
+						source        TEXT NOT NULL,
+						source_ip     TEXT NOT NULL DEFAULT '',
+						facility      INTEGER NOT NULL DEFAULT 0,
+						severity      INTEGER NOT NULL DEFAULT 0,
+						severity_name TEXT NOT NULL DEFAULT '',
+						level         TEXT NOT NULL,
+						hostname      TEXT NOT NULL DEFAULT '',
+						app_name      TEXT NOT NULL DEFAULT '',
+						message       TEXT NOT NULL,
+						timestamp     DATETIME NOT NULL,
+						received_at   DATETIME NOT NULL
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_device_events_device ON recon_device_events(device_id, timestamp)`,
+					`CREATE INDEX IF NOT EXISTS idx_device_events_received ON recon_device_events(received_at)`,
+				}
+				for _, stmt := range stmts {
+					if _, err := tx.Exec(stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version:     16,
+			Description: "add SNMP trap columns to recon_device_events",
+			Up: func(tx *sql.Tx) error {
+				stmts := []string{
+					`ALTER TABLE recon_device_events ADD COLUMN trap_oid TEXT NOT NULL DEFAULT ''`,
+					`ALTER TABLE recon_device_events ADD COLUMN varbinds TEXT NOT NULL DEFAULT ''`,
+				}
+				for _, stmt := range stmts {
+					if _, err := tx.Exec(stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version:     17,
+			Description: "add deleted_at to recon_devices for soft delete",
+			Up: func(tx *sql.Tx) error {
+				stmts := []string{
+					`ALTER TABLE recon_devices ADD COLUMN deleted_at DATETIME`,
+					`CREATE INDEX IF NOT EXISTS idx_recon_devices_deleted_at ON recon_devices(deleted_at)`,
+				}
+				for _, stmt := range stmts {
+					if _, err := tx.Exec(stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version:     18,
+			Description: "add effective scan tuning to recon_scan_metrics",
+			Up: func(tx *sql.Tx) error {
+				stmts := []string{
+					`ALTER TABLE recon_scan_metrics ADD COLUMN concurrency INTEGER NOT NULL DEFAULT 0`,
+					`ALTER TABLE recon_scan_metrics ADD COLUMN ping_timeout_ms INTEGER NOT NULL DEFAULT 0`,
+					`ALTER TABLE recon_scan_metrics ADD COLUMN port_scan_concurrency INTEGER NOT NULL DEFAULT 0`,
+					`ALTER TABLE recon_scan_metrics ADD COLUMN port_timeout_ms INTEGER NOT NULL DEFAULT 0`,
+				}
+				for _, stmt := range stmts {
+					if _, err := tx.Exec(stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version:     19,
+			Description: "create recon_device_ports for open ports found by scans",
+			Up: func(tx *sql.Tx) error {
+				stmts := []string{
+					`CREATE TABLE recon_device_ports (
+						device_id TEXT NOT NULL REFERENCES recon_devices(id) ON DELETE CASCADE,
+						port      INTEGER NOT NULL,
+						protocol  TEXT NOT NULL DEFAULT 'tcp',
+						service   TEXT NOT NULL DEFAULT '',
+						last_seen DATETIME NOT NULL,
+						PRIMARY KEY (device_id, port, protocol)
+					)`,
+					`CREATE INDEX idx_recon_device_ports_port ON recon_device_ports(port, protocol)`,
+				}
+				for _, stmt := range stmts {
+					if _, err := tx.Exec(stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version:     20,
+			Description: "create recon_classification_rules for user-defined device typing",
+			Up: func(tx *sql.Tx) error {
+				stmts := []string{
+					`CREATE TABLE recon_classification_rules (
+						id             TEXT PRIMARY KEY,
+						name           TEXT NOT NULL,
+						priority       INTEGER NOT NULL DEFAULT 0,
+						enabled        INTEGER NOT NULL DEFAULT 1,
+						manufacturer   TEXT NOT NULL DEFAULT '',
+						oui_prefix     TEXT NOT NULL DEFAULT '',
+						open_ports     TEXT NOT NULL DEFAULT '[]',
+						hostname_regex TEXT NOT NULL DEFAULT '',
+						os             TEXT NOT NULL DEFAULT '',
+						device_type    TEXT NOT NULL,
+						confidence     INTEGER NOT NULL DEFAULT 90,
+						created_at     DATETIME NOT NULL,
+						updated_at     DATETIME NOT NULL
+					)`,
+					`CREATE INDEX idx_recon_classification_rules_priority ON recon_classification_rules(priority, created_at)`,
+				}
+				for _, stmt := range stmts {
+					if _, err := tx.Exec(stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version:     21,
+			Description: "create recon_custom_field_defs for typed device custom fields",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`CREATE TABLE recon_custom_field_defs (
+					name       TEXT PRIMARY KEY,
+					label      TEXT NOT NULL DEFAULT '',
+					type       TEXT NOT NULL,
+					options    TEXT NOT NULL DEFAULT '[]',
+					created_at DATETIME NOT NULL,
+					updated_at DATETIME NOT NULL
+				)`)
+				return err
+			},
+		},
+		{
+			Version:     22,
+			Description: "collapse reversed duplicate arp topology links",
+			Up: func(tx *sql.Tx) error {
+				// ARP links are undirected. Keep the row whose source has the
+				// lower device ID, carry over the newer confirmation time from
+				// its reverse, drop the reverse, then flip the remaining rows
+				// stored the other way round. Mirrors normalizeTopologyLink.
+				stmts := []string{
+					`UPDATE recon_topology_links SET last_confirmed = (
+						SELECT r.last_confirmed FROM recon_topology_links r
+						WHERE r.link_type = 'arp'
+							AND r.source_device_id = recon_topology_links.target_device_id
+							AND r.target_device_id = recon_topology_links.source_device_id
+					)
+					WHERE link_type = 'arp' AND source_device_id < target_device_id
+						AND EXISTS (
+							SELECT 1 FROM recon_topology_links r
+							WHERE r.link_type = 'arp'
+								AND r.source_device_id = recon_topology_links.target_device_id
+								AND r.target_device_id = recon_topology_links.source_device_id
+								AND r.last_confirmed > recon_topology_links.last_confirmed
+						)`,
+					`DELETE FROM recon_topology_links
+					WHERE link_type = 'arp' AND source_device_id > target_device_id
+						AND EXISTS (
+							SELECT 1 FROM recon_topology_links r
+							WHERE r.link_type = 'arp'
+								AND r.source_device_id = recon_topology_links.target_device_id
+								AND r.target_device_id = recon_topology_links.source_device_id
+						)`,
+					`UPDATE recon_topology_links SET
+						source_device_id = target_device_id, target_device_id = source_device_id,
+						source_port = target_port, target_port = source_port
+					WHERE link_type = 'arp' AND source_device_id > target_device_id`,
+				}
+				for _, stmt := range stmts {
+					if _, err := tx.Exec(stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version:     23,
+			Description: "create recon_device_icons for uploaded device icons",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`CREATE TABLE recon_device_icons (
+					device_id    TEXT PRIMARY KEY REFERENCES recon_devices(id) ON DELETE CASCADE,
+					content_type TEXT NOT NULL,
+					data         BLOB NOT NULL,
+					width        INTEGER NOT NULL,
+					height       INTEGER NOT NULL,
+					updated_at   DATETIME NOT NULL
+				)`)
+				return err
+			},
+		},
+		{
+			Version:     24,
+			Description: "add hostname_source to recon_devices",
+			Up: func(tx *sql.Tx) error {
+				stmts := []string{
+					`ALTER TABLE recon_devices ADD COLUMN hostname_source TEXT NOT NULL DEFAULT ''`,
+					// Hostnames entered with manually created devices are manual.
+					`UPDATE recon_devices SET hostname_source = 'manual'
+					WHERE discovery_method = 'manual' AND hostname != ''`,
+				}
+				for _, stmt := range stmts {
+					if _, err := tx.Exec(stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version:     25,
+			Description: "create recon_device_relationships for typed device edges",
+			Up: func(tx *sql.Tx) error {
+				stmts := []string{
+					`CREATE TABLE recon_device_relationships (
+						id             TEXT PRIMARY KEY,
+						from_device_id TEXT NOT NULL REFERENCES recon_devices(id) ON DELETE CASCADE,
+						to_device_id   TEXT NOT NULL REFERENCES recon_devices(id) ON DELETE CASCADE,
+						type           TEXT NOT NULL,
+						note           TEXT NOT NULL DEFAULT '',
+						created_at     DATETIME NOT NULL,
+						updated_at     DATETIME NOT NULL,
+						UNIQUE(from_device_id, to_device_id, type),
+						CHECK(from_device_id != to_device_id)
+					)`,
+					`CREATE INDEX idx_recon_device_relationships_to ON recon_device_relationships(to_device_id)`,
+				}
+				for _, stmt := range stmts {
+					if _, err := tx.Exec(stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version:     26,
+			Description: "create recon_scan_templates and record the template a scan used",
+			Up: func(tx *sql.Tx) error {
+				stmts := []string{
+					`CREATE TABLE recon_scan_templates (
+						id                    TEXT PRIMARY KEY,
+						name                  TEXT NOT NULL UNIQUE,
+						description           TEXT NOT NULL DEFAULT '',
+						methods               TEXT NOT NULL DEFAULT '[]',
+						ports                 TEXT NOT NULL DEFAULT '[]',
+						port_scan_all_hosts   INTEGER NOT NULL DEFAULT 0,
+						concurrency           INTEGER NOT NULL DEFAULT 0,
+						ping_timeout_ms       INTEGER NOT NULL DEFAULT 0,
+						ping_count            INTEGER NOT NULL DEFAULT 0,
+						port_scan_concurrency INTEGER NOT NULL DEFAULT 0,
+						port_timeout_ms       INTEGER NOT NULL DEFAULT 0,
+						created_at            DATETIME NOT NULL,
+						updated_at            DATETIME NOT NULL
+					)`,
+					`ALTER TABLE recon_scans ADD COLUMN template_id TEXT NOT NULL DEFAULT ''`,
+				}
+				for _, stmt := range stmts {
+					if _, err := tx.Exec(stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version:     27,
+			Description: "create recon_interface_bandwidth for SNMP interface throughput samples",
+			Up: func(tx *sql.Tx) error {
+				stmts := []string{
+					`CREATE TABLE recon_interface_bandwidth (
+						id          INTEGER PRIMARY KEY AUTOINCREMENT,
+						device_id   TEXT NOT NULL REFERENCES recon_devices(id) ON DELETE CASCADE,
+						if_index    INTEGER NOT NULL,
+						interface   TEXT NOT NULL,
+						in_bps      REAL NOT NULL,
+						out_bps     REAL NOT NULL,
+						recorded_at DATETIME NOT NULL
+					)`,
+					`CREATE INDEX idx_recon_interface_bandwidth_device ON recon_interface_bandwidth(device_id, recorded_at)`,
+					`CREATE INDEX idx_recon_interface_bandwidth_recorded ON recon_interface_bandwidth(recorded_at)`,
+				}
+				for _, stmt := range stmts {
+					if _, err := tx.Exec(stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
 	}
 }
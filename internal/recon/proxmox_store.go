@@ -29,11 +29,17 @@ type ProxmoxResource struct {
 
 // UpsertProxmoxResource inserts or replaces a resource snapshot for a VM/container.
 func (s *ReconStore) UpsertProxmoxResource(ctx context.Context, r *ProxmoxResource) error {
-	_, err := s.db.ExecContext(ctx, `INSERT OR REPLACE INTO recon_proxmox_resources (
+	_, err := s.db.ExecContext(ctx, `INSERT INTO recon_proxmox_resources (
 		device_id, cpu_percent, mem_used_mb, mem_total_mb,
 		disk_used_gb, disk_total_gb, uptime_sec,
 		netin_bytes, netout_bytes, collected_at
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(device_id) DO UPDATE SET
+		cpu_percent = excluded.cpu_percent, mem_used_mb = excluded.mem_used_mb,
+		mem_total_mb = excluded.mem_total_mb, disk_used_gb = excluded.disk_used_gb,
+		disk_total_gb = excluded.disk_total_gb, uptime_sec = excluded.uptime_sec,
+		netin_bytes = excluded.netin_bytes, netout_bytes = excluded.netout_bytes,
+		collected_at = excluded.collected_at`,
 		r.DeviceID, r.CPUPercent, r.MemUsedMB, r.MemTotalMB,
 		r.DiskUsedGB, r.DiskTotalGB, r.UptimeSec,
 		r.NetInBytes, r.NetOutBytes, r.CollectedAt)
@@ -126,7 +132,7 @@ func (s *ReconStore) FindDeviceByHostnameAndParent(ctx context.Context, hostname
 		location, category, primary_role, owner,
 		classification_confidence, classification_source, classification_signals,
 		parent_device_id, network_layer, connection_type
-		FROM recon_devices WHERE hostname = ? AND parent_device_id = ?`,
+		FROM recon_devices WHERE hostname = ? AND parent_device_id = ? AND deleted_at IS NULL`,
 		hostname, parentID).Scan(
 		&d.ID, &d.Hostname, &ipsJSON, &d.MACAddress, &d.Manufacturer,
 		&dt, &d.OS, &status, &method, &d.AgentID,
@@ -159,7 +165,7 @@ func (s *ReconStore) FindChildDevicesByDiscovery(ctx context.Context, parentID,
 		location, category, primary_role, owner,
 		classification_confidence, classification_source, classification_signals,
 		parent_device_id, network_layer, connection_type
-		FROM recon_devices WHERE parent_device_id = ? AND discovery_method = ?`,
+		FROM recon_devices WHERE parent_device_id = ? AND discovery_method = ? AND deleted_at IS NULL`,
 		parentID, discoveryMethod)
 	if err != nil {
 		return nil, fmt.Errorf("find child devices by discovery: %w", err)
@@ -2,12 +2,11 @@ package recon
 
 import (
 	"context"
-	"os"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
+	"github.com/HerbHall/subnetree/pkg/llm"
 	"github.com/HerbHall/subnetree/pkg/plugin"
 	"github.com/HerbHall/subnetree/pkg/roles"
 	"go.uber.org/zap"
@@ -19,6 +18,8 @@ var (
 	_ plugin.HTTPProvider    = (*Module)(nil)
 	_ plugin.HealthChecker   = (*Module)(nil)
 	_ plugin.EventSubscriber = (*Module)(nil)
+	_ plugin.Validator       = (*Module)(nil)
+	_ plugin.Reloadable      = (*Module)(nil)
 )
 
 // Module implements the Recon network discovery plugin.
@@ -33,13 +34,21 @@ type Module struct {
 	wifiScanner   WifiScanner
 	mdns          *MDNSListener
 	upnp          *UPNPDiscoverer
+	syslog        *SyslogListener
+	traps         *TrapReceiver
+	bandwidth     *BandwidthPoller
+	schedMu       sync.Mutex
 	scheduler     *ScanScheduler
 	consolidator  *ScanConsolidator
 	credAccessor   CredentialAccessor
 	credProvider   roles.CredentialProvider
 	profileSource  ProfileSource
+	scanTargets    ScanTargetSource
 	wifiAPEnumerator APClientEnumerator
 	proxmoxSyncer    *ProxmoxSyncer
+	reverseDNS       *ReverseDNSEnricher
+	geoIP            GeoIPLookup
+	llmProvider      llm.Provider
 	activeScans    sync.Map // scanID -> context.CancelFunc
 	wg            sync.WaitGroup
 	scanCtx       context.Context
@@ -65,69 +74,11 @@ func (m *Module) Init(ctx context.Context, deps plugin.Dependencies) error {
 	m.logger = deps.Logger
 	m.bus = deps.Bus
 
-	// Load config with defaults.
-	m.cfg = DefaultConfig()
-	if deps.Config != nil {
-		if d := deps.Config.GetDuration("scan_timeout"); d > 0 {
-			m.cfg.ScanTimeout = d
-		}
-		if d := deps.Config.GetDuration("ping_timeout"); d > 0 {
-			m.cfg.PingTimeout = d
-		}
-		if v := deps.Config.GetInt("ping_count"); v > 0 {
-			m.cfg.PingCount = v
-		}
-		if v := deps.Config.GetInt("concurrency"); v > 0 {
-			m.cfg.Concurrency = v
-		}
-		if deps.Config.IsSet("arp_enabled") {
-			m.cfg.ARPEnabled = deps.Config.GetBool("arp_enabled")
-		}
-		if d := deps.Config.GetDuration("device_lost_after"); d > 0 {
-			m.cfg.DeviceLostAfter = d
-		}
-		if deps.Config.IsSet("mdns_enabled") {
-			m.cfg.MDNSEnabled = deps.Config.GetBool("mdns_enabled")
-		}
-		if d := deps.Config.GetDuration("mdns_interval"); d > 0 {
-			m.cfg.MDNSInterval = d
-		}
-		if deps.Config.IsSet("upnp_enabled") {
-			m.cfg.UPNPEnabled = deps.Config.GetBool("upnp_enabled")
-		}
-		if d := deps.Config.GetDuration("upnp_interval"); d > 0 {
-			m.cfg.UPNPInterval = d
-		}
-		if deps.Config.IsSet("schedule.enabled") {
-			m.cfg.Schedule.Enabled = deps.Config.GetBool("schedule.enabled")
-		}
-		if d := deps.Config.GetDuration("schedule.interval"); d > 0 {
-			m.cfg.Schedule.Interval = d
-		}
-		if v := deps.Config.GetString("schedule.quiet_start"); v != "" {
-			m.cfg.Schedule.QuietStart = v
-		}
-		if v := deps.Config.GetString("schedule.quiet_end"); v != "" {
-			m.cfg.Schedule.QuietEnd = v
-		}
-		if v := deps.Config.GetString("schedule.subnet"); v != "" {
-			m.cfg.Schedule.Subnet = v
-		}
-	}
-
-	// Allow disabling discovery via environment for QC/testing containers.
-	// Viper's Sub() does not inherit AutomaticEnv, so plugin-scoped env vars
-	// like NV_RECON_MDNS_ENABLED are not visible to the sub-Viper. We check
-	// them explicitly here.
-	if v := os.Getenv("NV_RECON_MDNS_ENABLED"); strings.EqualFold(v, "false") {
-		m.cfg.MDNSEnabled = false
-	}
-	if v := os.Getenv("NV_RECON_UPNP_ENABLED"); strings.EqualFold(v, "false") {
-		m.cfg.UPNPEnabled = false
-	}
-	if v := os.Getenv("NV_RECON_SCHEDULE_ENABLED"); strings.EqualFold(v, "false") {
-		m.cfg.Schedule.Enabled = false
+	cfg, err := loadConfig(deps.Config)
+	if err != nil {
+		return err
 	}
+	m.cfg = cfg
 
 	// Run database migrations.
 	if err := deps.Store.Migrate(ctx, "recon", migrations()); err != nil {
@@ -145,6 +96,7 @@ func (m *Module) Init(ctx context.Context, deps plugin.Dependencies) error {
 	}
 
 	m.orchestrator = NewScanOrchestrator(m.store, m.bus, m.oui, pinger, arp, m.logger)
+	m.orchestrator.SetTuning(ScanTuningFromConfig(m.cfg))
 
 	// Initialize WiFi scanner (auto-detects hardware availability).
 	m.wifiScanner = NewWifiScanner(m.logger.Named("wifi"))
@@ -164,8 +116,37 @@ func (m *Module) Init(ctx context.Context, deps plugin.Dependencies) error {
 		m.upnp = NewUPNPDiscoverer(m.store, m.bus, m.logger.Named("upnp"), m.cfg.UPNPInterval)
 	}
 
+	// Initialize syslog listener if enabled.
+	if m.cfg.Syslog.Enabled {
+		m.syslog = NewSyslogListener(m.store, m.bus, m.logger.Named("syslog"), m.cfg.Syslog)
+	}
+
+	// Initialize SNMP trap receiver if enabled.
+	if m.cfg.SNMPTrap.Enabled {
+		m.traps = NewTrapReceiver(m.store, m.bus, m.logger.Named("snmp-trap"), m.cfg.SNMPTrap)
+	}
+
 	m.proxmoxSyncer = NewProxmoxSyncer(m.store, m.logger.Named("proxmox-sync"))
 
+	// Reverse DNS is always available on demand; the post-scan pass is
+	// optional.
+	m.reverseDNS = NewReverseDNSEnricher(m.store, m.cfg.ReverseDNS, m.logger.Named("reverse-dns"))
+	if m.cfg.ReverseDNS.Enabled {
+		m.orchestrator.SetReverseDNS(m.reverseDNS)
+	}
+
+	// Open GeoIP databases if configured. A missing or unreadable file
+	// disables GeoIP rather than the module.
+	if m.cfg.GeoIP != (GeoIPConfig{}) {
+		geo, err := OpenMaxMindGeoIP(m.cfg.GeoIP)
+		if err != nil {
+			m.logger.Warn("GeoIP enrichment disabled", zap.Error(err))
+		} else {
+			m.geoIP = geo
+			m.orchestrator.SetGeoIP(geo)
+		}
+	}
+
 	m.logger.Info("recon module initialized")
 	return nil
 }
@@ -174,15 +155,22 @@ func (m *Module) Start(_ context.Context) error {
 	m.scanCtx, m.scanCancel = context.WithCancel(context.Background())
 
 	// Initialize SNMP collector and wire it into the scan orchestrator
-	// for FDB table walks during post-scan processing.
+	// for FDB and LLDP table walks during post-scan processing.
 	m.snmpCollector = NewSNMPCollector(m.logger.Named("snmp"))
 	m.orchestrator.SetSNMPWalker(m.snmpCollector)
+	m.orchestrator.SetLLDPWalker(m.snmpCollector)
 	m.orchestrator.SetCredentialLookup(m)
 
 	// Start device-lost checker background goroutine.
 	m.wg.Add(1)
 	go m.runDeviceLostChecker()
 
+	// Purge devices that have been in the trash longer than the retention.
+	if m.cfg.TrashRetention > 0 {
+		m.wg.Add(1)
+		go m.runTrashPurger()
+	}
+
 	// Start mDNS listener background goroutine if configured.
 	if m.mdns != nil {
 		m.wg.Add(1)
@@ -207,26 +195,59 @@ func (m *Module) Start(_ context.Context) error {
 		)
 	}
 
-	// Start scan scheduler if enabled.
-	if m.cfg.Schedule.Enabled && m.cfg.Schedule.Subnet != "" {
-		m.scheduler = NewScanScheduler(
-			m.cfg.Schedule,
-			m.orchestrator,
-			m.store,
-			&m.activeScans,
-			&m.wg,
-			m.newScanContext,
-			m.logger.Named("scheduler"),
-		)
+	// Start syslog listener if configured. A bind failure (e.g. port 514
+	// without privileges) disables the listener rather than the module.
+	if m.syslog != nil {
+		if err := m.syslog.Listen(); err != nil {
+			m.logger.Warn("syslog listener disabled", zap.Error(err))
+			m.syslog = nil
+		} else {
+			m.wg.Add(1)
+			go func() {
+				defer m.wg.Done()
+				m.syslog.Run(m.scanCtx)
+			}()
+		}
+	}
+
+	// Start SNMP trap receiver if configured. Credentials are wired here
+	// because the accessor is set after Init.
+	if m.traps != nil {
+		if err := m.traps.Listen(); err != nil {
+			m.logger.Warn("SNMP trap receiver disabled", zap.Error(err))
+			m.traps = nil
+		} else {
+			if m.credAccessor != nil {
+				m.traps.SetCredentials(m, m.credAccessor)
+			}
+			m.wg.Add(1)
+			go func() {
+				defer m.wg.Done()
+				m.traps.Run(m.scanCtx)
+			}()
+		}
+	}
+
+	// Start SNMP bandwidth poller if configured. It needs the SNMP
+	// collector created above and the credential accessor.
+	if m.cfg.Bandwidth.Enabled {
+		m.bandwidth = NewBandwidthPoller(m.store, m.snmpCollector, m.logger.Named("bandwidth"), m.cfg.Bandwidth)
+		if m.credAccessor != nil {
+			m.bandwidth.SetCredentials(m, m.credAccessor)
+		}
 		m.wg.Add(1)
 		go func() {
 			defer m.wg.Done()
-			m.scheduler.Run(m.scanCtx)
+			m.bandwidth.Run(m.scanCtx)
 		}()
-		m.logger.Info("scan scheduler enabled",
-			zap.Duration("interval", m.cfg.Schedule.Interval),
-			zap.String("subnet", m.cfg.Schedule.Subnet),
-		)
+	}
+
+	// Start scan scheduler if enabled. Without an explicit subnet the scheduler
+	// scans the subnets of the interfaces selected in settings.
+	if m.cfg.Schedule.Enabled {
+		m.schedMu.Lock()
+		m.startScheduler(m.cfg.Schedule)
+		m.schedMu.Unlock()
 	}
 
 	// Start scan metrics consolidator background goroutine.
@@ -262,6 +283,17 @@ func (m *Module) SetProfileSource(ps ProfileSource) {
 	m.profileSource = ps
 }
 
+// SetScanTargetSource sets the source of subnets for scheduled scans when no
+// schedule subnet is configured. Called from the composition root.
+func (m *Module) SetScanTargetSource(src ScanTargetSource) {
+	m.schedMu.Lock()
+	defer m.schedMu.Unlock()
+	m.scanTargets = src
+	if m.scheduler != nil {
+		m.scheduler.SetTargetSource(src)
+	}
+}
+
 // FindSNMPCredentialForDevice implements CredentialLookup by delegating to the
 // Module's credProvider (roles.CredentialProvider).
 func (m *Module) FindSNMPCredentialForDevice(ctx context.Context, deviceID string) (string, error) {
@@ -282,9 +314,11 @@ func (m *Module) FindSNMPCredentialForDevice(ctx context.Context, deviceID strin
 
 func (m *Module) Stop(_ context.Context) error {
 	m.logger.Info("recon module stopping, cancelling active scans")
+	m.schedMu.Lock()
 	if m.scheduler != nil {
 		m.scheduler.Stop()
 	}
+	m.schedMu.Unlock()
 	if m.scanCancel != nil {
 		m.scanCancel()
 	}
@@ -296,10 +330,90 @@ func (m *Module) Stop(_ context.Context) error {
 		return true
 	})
 	m.wg.Wait()
+	if geo, ok := m.geoIP.(*MaxMindGeoIP); ok {
+		geo.Close()
+	}
 	m.logger.Info("recon module stopped")
 	return nil
 }
 
+// startScheduler creates and runs the scan scheduler. The caller must hold
+// schedMu.
+func (m *Module) startScheduler(cfg ScheduleConfig) {
+	m.scheduler = NewScanScheduler(
+		cfg,
+		m.orchestrator,
+		m.store,
+		&m.activeScans,
+		&m.wg,
+		m.newScanContext,
+		m.logger.Named("scheduler"),
+	)
+	if m.scanTargets != nil {
+		m.scheduler.SetTargetSource(m.scanTargets)
+	}
+	sched := m.scheduler
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		sched.Run(m.scanCtx)
+	}()
+	m.logger.Info("scan scheduler enabled",
+		zap.Duration("interval", cfg.Interval),
+		zap.String("subnet", cfg.Subnet),
+	)
+}
+
+// Reload implements plugin.Reloadable. The scan schedule, including
+// enabling or disabling it, and the port scan tuning take effect
+// immediately. Other changed settings are logged as requiring a restart.
+func (m *Module) Reload(_ context.Context, config plugin.Config) error {
+	cfg, err := loadConfig(config)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	m.schedMu.Lock()
+	if cfg.Schedule != m.cfg.Schedule && m.scanCtx != nil {
+		switch {
+		case !cfg.Schedule.Enabled:
+			if m.scheduler != nil {
+				m.scheduler.Stop()
+				m.scheduler = nil
+				m.logger.Info("scan scheduler disabled")
+			}
+		case m.scheduler == nil:
+			m.startScheduler(cfg.Schedule)
+		default:
+			m.scheduler.SetConfig(cfg.Schedule)
+		}
+		m.cfg.Schedule = cfg.Schedule
+	}
+	m.schedMu.Unlock()
+
+	if cfg.PortScanConcurrency != m.cfg.PortScanConcurrency || cfg.PortTimeout != m.cfg.PortTimeout {
+		tuning := m.orchestrator.Tuning()
+		tuning.PortScanConcurrency = cfg.PortScanConcurrency
+		tuning.PortTimeout = cfg.PortTimeout
+		m.orchestrator.SetTuning(tuning)
+		m.cfg.PortScanConcurrency = cfg.PortScanConcurrency
+		m.cfg.PortTimeout = cfg.PortTimeout
+		m.logger.Info("port scan tuning updated",
+			zap.Int("port_scan_concurrency", cfg.PortScanConcurrency),
+			zap.Duration("port_timeout", cfg.PortTimeout),
+		)
+	}
+
+	if keys := restartRequiredChanges(&m.cfg, &cfg); len(keys) > 0 {
+		m.logger.Warn("recon settings changed that require a restart to apply",
+			zap.Strings("keys", keys))
+	}
+	return nil
+}
+
 // Subscriptions implements plugin.EventSubscriber.
 func (m *Module) Subscriptions() []plugin.Subscription {
 	return []plugin.Subscription{
@@ -314,9 +428,15 @@ func (m *Module) Routes() []plugin.Route {
 		{Method: "GET", Path: "/scans", Handler: m.handleListScans},
 		{Method: "GET", Path: "/scans/{id}", Handler: m.handleGetScan},
 		{Method: "GET", Path: "/scans/{id}/metrics", Handler: m.handleGetScanMetrics},
+		{Method: "GET", Path: "/scan-templates", Handler: m.handleListScanTemplates},
+		{Method: "POST", Path: "/scan-templates", Handler: m.handleCreateScanTemplate},
+		{Method: "GET", Path: "/scan-templates/{id}", Handler: m.handleGetScanTemplate},
+		{Method: "PUT", Path: "/scan-templates/{id}", Handler: m.handleUpdateScanTemplate},
+		{Method: "DELETE", Path: "/scan-templates/{id}", Handler: m.handleDeleteScanTemplate},
 		{Method: "GET", Path: "/topology", Handler: m.handleTopology},
 		{Method: "GET", Path: "/hierarchy", Handler: m.handleGetHierarchy},
 		{Method: "GET", Path: "/topology/layouts", Handler: m.handleListTopologyLayouts},
+		{Method: "GET", Path: "/topology/layout/auto", Handler: m.handleAutoLayout},
 		{Method: "POST", Path: "/topology/layouts", Handler: m.handleCreateTopologyLayout},
 		{Method: "PUT", Path: "/topology/layouts/{id}", Handler: m.handleUpdateTopologyLayout},
 		{Method: "DELETE", Path: "/topology/layouts/{id}", Handler: m.handleDeleteTopologyLayout},
@@ -325,17 +445,50 @@ func (m *Module) Routes() []plugin.Route {
 		{Method: "GET", Path: "/devices/export", Handler: m.handleExportCSV},
 		{Method: "GET", Path: "/devices/ansible", Handler: m.handleExportAnsible},
 		{Method: "POST", Path: "/devices/import", Handler: m.handleImportCSV},
+		{Method: "POST", Path: "/import/arp", Handler: m.handleImportARP},
+		{Method: "POST", Path: "/import/dhcp", Handler: m.handleImportDHCP},
+		{Method: "GET", Path: "/devices/trash", Handler: m.handleListDeletedDevices},
 		{Method: "GET", Path: "/devices/{id}", Handler: m.handleGetDevice},
 		{Method: "PUT", Path: "/devices/{id}", Handler: m.handleUpdateDevice},
 		{Method: "DELETE", Path: "/devices/{id}", Handler: m.handleDeleteDevice},
+		{Method: "POST", Path: "/devices/{id}/restore", Handler: m.handleRestoreDevice},
+		{Method: "POST", Path: "/devices/{id}/classify-llm", Handler: m.handleClassifyDeviceLLM},
+		{Method: "POST", Path: "/devices/{id}/lldp", Handler: m.handleDevicePollLLDP},
+		{Method: "POST", Path: "/devices/{id}/resolve-dns", Handler: m.handleResolveDeviceDNS},
 		{Method: "GET", Path: "/devices/{id}/history", Handler: m.handleDeviceHistory},
 		{Method: "GET", Path: "/devices/{id}/scans", Handler: m.handleDeviceScans},
+		{Method: "GET", Path: "/devices/{id}/events", Handler: m.handleDeviceEvents},
+		{Method: "GET", Path: "/devices/{id}/bandwidth", Handler: m.handleDeviceBandwidth},
+		{Method: "GET", Path: "/devices/{id}/relationships", Handler: m.handleGetDeviceRelationshipsForDevice},
+		{Method: "GET", Path: "/devices/{id}/icon", Handler: m.handleGetDeviceIcon},
+		{Method: "POST", Path: "/devices/{id}/icon", Handler: m.handleUploadDeviceIcon},
+		{Method: "DELETE", Path: "/devices/{id}/icon", Handler: m.handleDeleteDeviceIcon},
+		{Method: "GET", Path: "/devices/{id}/qr.png", Handler: m.handleDeviceQR},
+		{Method: "GET", Path: "/devices/labels", Handler: m.handleDeviceLabelSheet},
+		{Method: "GET", Path: "/relationships", Handler: m.handleListDeviceRelationships},
+		{Method: "POST", Path: "/relationships", Handler: m.handleCreateDeviceRelationship},
+		{Method: "GET", Path: "/relationships/{id}", Handler: m.handleGetDeviceRelationship},
+		{Method: "PUT", Path: "/relationships/{id}", Handler: m.handleUpdateDeviceRelationship},
+		{Method: "DELETE", Path: "/relationships/{id}", Handler: m.handleDeleteDeviceRelationship},
 		{Method: "GET", Path: "/inventory/summary", Handler: m.handleInventorySummary},
+		{Method: "GET", Path: "/subnets", Handler: m.handleSubnetReport},
+		{Method: "GET", Path: "/geoip/{ip}", Handler: m.handleGeoIPLookup},
+		{Method: "GET", Path: "/stats/growth", Handler: m.handleDeviceGrowth},
 		{Method: "PATCH", Path: "/devices/bulk", Handler: m.handleBulkUpdateDevices},
+		{Method: "POST", Path: "/devices/bulk-tags", Handler: m.handleBulkTags},
 		{Method: "GET", Path: "/metrics/health-score", Handler: m.handleHealthScore},
 		{Method: "GET", Path: "/metrics/aggregates", Handler: m.handleListMetricsAggregates},
 		{Method: "GET", Path: "/metrics/raw", Handler: m.handleListRawMetrics},
 		{Method: "GET", Path: "/movements", Handler: m.handleListServiceMovements},
+		{Method: "GET", Path: "/classification-rules", Handler: m.handleListClassificationRules},
+		{Method: "POST", Path: "/classification-rules", Handler: m.handleCreateClassificationRule},
+		{Method: "GET", Path: "/classification-rules/{id}", Handler: m.handleGetClassificationRule},
+		{Method: "PUT", Path: "/classification-rules/{id}", Handler: m.handleUpdateClassificationRule},
+		{Method: "DELETE", Path: "/classification-rules/{id}", Handler: m.handleDeleteClassificationRule},
+		{Method: "GET", Path: "/custom-fields", Handler: m.handleListCustomFields},
+		{Method: "POST", Path: "/custom-fields", Handler: m.handleCreateCustomField},
+		{Method: "PUT", Path: "/custom-fields/{name}", Handler: m.handleUpdateCustomField},
+		{Method: "DELETE", Path: "/custom-fields/{name}", Handler: m.handleDeleteCustomField},
 		{Method: "POST", Path: "/snmp/discover", Handler: m.handleSNMPDiscover},
 		{Method: "GET", Path: "/snmp/system/{device_id}", Handler: m.handleSNMPSystemInfo},
 		{Method: "GET", Path: "/snmp/interfaces/{device_id}", Handler: m.handleSNMPInterfaces},
@@ -349,6 +502,7 @@ func (m *Module) Routes() []plugin.Route {
 		{Method: "GET", Path: "/devices/{id}/storage", Handler: m.handleGetDeviceStorage},
 		{Method: "GET", Path: "/devices/{id}/gpu", Handler: m.handleGetDeviceGPU},
 		{Method: "GET", Path: "/devices/{id}/services", Handler: m.handleGetDeviceServices},
+		{Method: "GET", Path: "/devices/{id}/ports", Handler: m.handleGetDevicePorts},
 		{Method: "GET", Path: "/inventory/hardware-summary", Handler: m.handleHardwareSummary},
 		{Method: "GET", Path: "/devices/query/hardware", Handler: m.handleQueryDevicesByHardware},
 		{Method: "GET", Path: "/wifi/clients", Handler: m.handleListWiFiClients},
@@ -358,6 +512,12 @@ func (m *Module) Routes() []plugin.Route {
 	}
 }
 
+// ValidateConfig implements plugin.Validator. Out-of-range scan tuning
+// disables the module rather than letting a scan exhaust the host.
+func (m *Module) ValidateConfig() error {
+	return m.cfg.Validate()
+}
+
 // Health implements plugin.HealthChecker.
 func (m *Module) Health(_ context.Context) plugin.HealthStatus {
 	var activeCount int
@@ -371,6 +531,8 @@ func (m *Module) Health(_ context.Context) plugin.HealthStatus {
 		"arp_enabled":  strconv.FormatBool(m.cfg.ARPEnabled),
 		"mdns_enabled": strconv.FormatBool(m.cfg.MDNSEnabled),
 		"upnp_enabled": strconv.FormatBool(m.cfg.UPNPEnabled),
+		"syslog_enabled": strconv.FormatBool(m.syslog != nil),
+		"snmp_trap_enabled": strconv.FormatBool(m.traps != nil),
 	}
 
 	return plugin.HealthStatus{
@@ -445,6 +607,28 @@ func (m *Module) checkForLostDevices() {
 	}
 }
 
+// runTrashPurger periodically removes devices that were moved to the trash
+// more than TrashRetention ago.
+func (m *Module) runTrashPurger() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		n, err := m.store.PurgeDeletedDevices(m.scanCtx, time.Now().UTC().Add(-m.cfg.TrashRetention))
+		if err != nil && m.scanCtx.Err() == nil {
+			m.logger.Warn("failed to purge deleted devices", zap.Error(err))
+		} else if n > 0 {
+			m.logger.Info("purged deleted devices from trash", zap.Int64("count", n))
+		}
+		select {
+		case <-m.scanCtx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // publishEvent publishes an event to the event bus.
 func (m *Module) publishEvent(ctx context.Context, topic string, payload any) {
 	if m.bus == nil {
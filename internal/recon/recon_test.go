@@ -195,3 +195,38 @@ func TestDeviceLostChecker_StopsOnCancel(t *testing.T) {
 		t.Fatal("device lost checker did not stop within 2 seconds after context cancellation")
 	}
 }
+
+func TestReconConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(*ReconConfig)
+		wantErr bool
+	}{
+		{"defaults", func(*ReconConfig) {}, false},
+		{"max concurrency", func(c *ReconConfig) { c.Concurrency = MaxConcurrency }, false},
+		{"zero concurrency", func(c *ReconConfig) { c.Concurrency = 0 }, true},
+		{"excessive concurrency", func(c *ReconConfig) { c.Concurrency = 10000 }, true},
+		{"excessive port concurrency", func(c *ReconConfig) { c.PortScanConcurrency = MaxPortScanConcurrency + 1 }, true},
+		{"ping count too high", func(c *ReconConfig) { c.PingCount = 50 }, true},
+		{"ping timeout too short", func(c *ReconConfig) { c.PingTimeout = time.Millisecond }, true},
+		{"port timeout too long", func(c *ReconConfig) { c.PortTimeout = time.Minute }, true},
+		{"subnet report threshold over 100", func(c *ReconConfig) { c.SubnetReport.Threshold = 150 }, true},
+		{"subnet report bad cidr", func(c *ReconConfig) { c.SubnetReport.Subnets = []string{"10.0.0.0"} }, true},
+		{"reverse dns resolver", func(c *ReconConfig) { c.ReverseDNS.Resolver = "192.168.1.1" }, false},
+		{"reverse dns resolver with port", func(c *ReconConfig) { c.ReverseDNS.Resolver = "[fd00::1]:5353" }, false},
+		{"reverse dns bad resolver port", func(c *ReconConfig) { c.ReverseDNS.Resolver = "192.168.1.1:dns" }, true},
+		{"reverse dns timeout too long", func(c *ReconConfig) { c.ReverseDNS.Timeout = time.Minute }, true},
+		{"label base url", func(c *ReconConfig) { c.LabelBaseURL = "https://subnetree.lan" }, false},
+		{"label base url not absolute", func(c *ReconConfig) { c.LabelBaseURL = "subnetree.lan" }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.mutate(&cfg)
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
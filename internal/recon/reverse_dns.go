@@ -0,0 +1,150 @@
+package recon
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Hostname sources recorded in recon_devices.hostname_source. An empty
+// source means the hostname came from discovery.
+const (
+	HostnameSourceManual     = "manual"
+	HostnameSourceReverseDNS = "reverse_dns"
+)
+
+// Reverse DNS enrichment limits. A pass after a scan resolves at most
+// reverseDNSBatchSize devices, reverseDNSWorkers at a time.
+const (
+	reverseDNSBatchSize = 256
+	reverseDNSWorkers   = 8
+)
+
+// ReverseDNSEnricher fills in blank device hostnames from PTR records.
+type ReverseDNSEnricher struct {
+	store      *ReconStore
+	timeout    time.Duration
+	lookupAddr func(ctx context.Context, addr string) ([]string, error)
+	logger     *zap.Logger
+}
+
+// NewReverseDNSEnricher creates an enricher that queries cfg.Resolver, or
+// the system resolver when it is empty. cfg must already be validated.
+func NewReverseDNSEnricher(store *ReconStore, cfg ReverseDNSConfig, logger *zap.Logger) *ReverseDNSEnricher {
+	resolver := net.DefaultResolver
+	if cfg.Resolver != "" {
+		addr, _ := resolverAddr(cfg.Resolver)
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			},
+		}
+	}
+	return &ReverseDNSEnricher{
+		store:      store,
+		timeout:    cfg.Timeout,
+		lookupAddr: resolver.LookupAddr,
+		logger:     logger,
+	}
+}
+
+// resolverAddr normalizes a configured resolver to host:port, defaulting
+// the port to 53.
+func resolverAddr(s string) (string, error) {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		// No port given; bare IPv6 addresses land here too.
+		host, port = strings.Trim(s, "[]"), "53"
+	}
+	if host == "" {
+		return "", fmt.Errorf("resolver %q has no host", s)
+	}
+	if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		return "", fmt.Errorf("resolver %q has an invalid port", s)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// Lookup returns the first PTR name for ip without its trailing dot, or ""
+// if there is none or the lookup fails.
+func (e *ReverseDNSEnricher) Lookup(ctx context.Context, ip string) string {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	names, err := e.lookupAddr(ctx, ip)
+	if err != nil || len(names) == 0 {
+		return ""
+	}
+	return strings.TrimSuffix(names[0], ".")
+}
+
+// ResolveDevice looks up the device's addresses in order and, on the first
+// PTR match, sets its hostname if the hostname is still blank and was not
+// set manually. It returns the resolved name, which is empty when no
+// address has a PTR record, and whether the device was updated.
+func (e *ReverseDNSEnricher) ResolveDevice(ctx context.Context, deviceID string, ips []string) (string, bool, error) {
+	for _, ip := range ips {
+		name := e.Lookup(ctx, ip)
+		if name == "" {
+			continue
+		}
+		updated, err := e.store.SetResolvedHostname(ctx, deviceID, name, HostnameSourceReverseDNS)
+		return name, updated, err
+	}
+	return "", false, nil
+}
+
+// EnrichMissing resolves hostnames for devices that have none. It returns
+// the number of devices that gained a hostname.
+func (e *ReverseDNSEnricher) EnrichMissing(ctx context.Context) int {
+	candidates, err := e.store.ListDevicesMissingHostname(ctx, reverseDNSBatchSize)
+	if err != nil {
+		e.logger.Warn("failed to list devices without hostnames", zap.Error(err))
+		return 0
+	}
+
+	var (
+		mu       sync.Mutex
+		resolved int
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, reverseDNSWorkers)
+	)
+	for _, c := range candidates {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(c hostnameCandidate) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			name, updated, err := e.ResolveDevice(ctx, c.ID, c.IPAddresses)
+			if err != nil {
+				e.logger.Warn("failed to store resolved hostname",
+					zap.String("device_id", c.ID), zap.Error(err))
+				return
+			}
+			if updated {
+				e.logger.Debug("hostname resolved via reverse DNS",
+					zap.String("device_id", c.ID), zap.String("hostname", name))
+				mu.Lock()
+				resolved++
+				mu.Unlock()
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	if resolved > 0 {
+		e.logger.Info("reverse DNS enrichment complete",
+			zap.Int("candidates", len(candidates)), zap.Int("resolved", resolved))
+	}
+	return resolved
+}
@@ -0,0 +1,71 @@
+package recon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// hostnameCandidate is a device eligible for reverse DNS enrichment.
+type hostnameCandidate struct {
+	ID          string
+	IPAddresses []string
+}
+
+// ListDevicesMissingHostname returns up to limit non-deleted devices that
+// have an IP address but no hostname and whose hostname was not cleared
+// manually, most recently seen first.
+func (s *ReconStore) ListDevicesMissingHostname(ctx context.Context, limit int) ([]hostnameCandidate, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, ip_addresses FROM recon_devices
+		WHERE deleted_at IS NULL AND hostname = '' AND hostname_source != ?
+			AND ip_addresses NOT IN ('', '[]', 'null')
+		ORDER BY last_seen DESC
+		LIMIT ?`, HostnameSourceManual, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list devices missing hostname: %w", err)
+	}
+	defer rows.Close()
+
+	var out []hostnameCandidate
+	for rows.Next() {
+		var c hostnameCandidate
+		var ipsJSON string
+		if err := rows.Scan(&c.ID, &ipsJSON); err != nil {
+			return nil, fmt.Errorf("scan device row: %w", err)
+		}
+		_ = json.Unmarshal([]byte(ipsJSON), &c.IPAddresses)
+		if len(c.IPAddresses) > 0 {
+			out = append(out, c)
+		}
+	}
+	return out, rows.Err()
+}
+
+// SetResolvedHostname sets a device's hostname from an enrichment source,
+// but only while the hostname is blank and was not set manually. Reports
+// whether the device was updated.
+func (s *ReconStore) SetResolvedHostname(ctx context.Context, deviceID, hostname, source string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE recon_devices SET hostname = ?, hostname_source = ?
+		WHERE id = ? AND hostname = '' AND hostname_source != ?`,
+		hostname, source, deviceID, HostnameSourceManual)
+	if err != nil {
+		return false, fmt.Errorf("set resolved hostname: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// GetHostnameSource returns how a device's hostname was set: "manual",
+// an enrichment source such as "reverse_dns", or "" for discovery.
+func (s *ReconStore) GetHostnameSource(ctx context.Context, deviceID string) (string, error) {
+	var source string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT hostname_source FROM recon_devices WHERE id = ?`, deviceID,
+	).Scan(&source)
+	if err != nil {
+		return "", fmt.Errorf("get hostname source: %w", err)
+	}
+	return source, nil
+}
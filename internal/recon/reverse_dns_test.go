@@ -0,0 +1,187 @@
+package recon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+	"go.uber.org/zap"
+)
+
+// fakePTR returns a lookupAddr func answering from a fixed PTR table.
+func fakePTR(records map[string]string) func(context.Context, string) ([]string, error) {
+	return func(_ context.Context, addr string) ([]string, error) {
+		if name, ok := records[addr]; ok {
+			return []string{name}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+}
+
+func TestResolverAddr(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "192.168.1.1", want: "192.168.1.1:53"},
+		{in: "192.168.1.1:5353", want: "192.168.1.1:5353"},
+		{in: "fd00::1", want: "[fd00::1]:53"},
+		{in: "[fd00::1]:53", want: "[fd00::1]:53"},
+		{in: "dns.lan", want: "dns.lan:53"},
+		{in: "10.0.0.1:0", wantErr: true},
+		{in: ":53", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := resolverAddr(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("resolverAddr(%q) = %q, %v; want %q, wantErr %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestReverseDNSEnrichMissing(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+
+	add := func(hostname, ip, mac string) *models.Device {
+		d := &models.Device{
+			Hostname:        hostname,
+			IPAddresses:     []string{ip},
+			MACAddress:      mac,
+			Status:          models.DeviceStatusOnline,
+			DiscoveryMethod: models.DiscoveryARP,
+		}
+		if _, err := s.UpsertDevice(ctx, d); err != nil {
+			t.Fatalf("UpsertDevice: %v", err)
+		}
+		return d
+	}
+	blank := add("", "10.0.0.10", "AA:BB:CC:00:10:01")
+	named := add("printer", "10.0.0.11", "AA:BB:CC:00:10:02")
+	cleared := add("", "10.0.0.12", "AA:BB:CC:00:10:03")
+	unknown := add("", "10.0.0.13", "AA:BB:CC:00:10:04")
+
+	// A user blanking the hostname opts the device out of enrichment.
+	empty := ""
+	if err := s.UpdateDevice(ctx, cleared.ID, UpdateDeviceParams{Hostname: &empty}); err != nil {
+		t.Fatalf("UpdateDevice: %v", err)
+	}
+
+	e := &ReverseDNSEnricher{
+		store:   s,
+		timeout: time.Second,
+		lookupAddr: fakePTR(map[string]string{
+			"10.0.0.10": "nas.home.arpa.",
+			"10.0.0.11": "other.home.arpa.",
+			"10.0.0.12": "cleared.home.arpa.",
+		}),
+		logger: zap.NewNop(),
+	}
+	if got := e.EnrichMissing(ctx); got != 1 {
+		t.Errorf("EnrichMissing = %d, want 1", got)
+	}
+
+	for _, tc := range []struct {
+		device   *models.Device
+		hostname string
+		source   string
+	}{
+		{blank, "nas.home.arpa", HostnameSourceReverseDNS},
+		{named, "printer", ""},
+		{cleared, "", HostnameSourceManual},
+		{unknown, "", ""},
+	} {
+		got, err := s.GetDevice(ctx, tc.device.ID)
+		if err != nil {
+			t.Fatalf("GetDevice: %v", err)
+		}
+		source, _ := s.GetHostnameSource(ctx, tc.device.ID)
+		if got.Hostname != tc.hostname || source != tc.source {
+			t.Errorf("device %s hostname = %q (%q), want %q (%q)",
+				tc.device.IPAddresses[0], got.Hostname, source, tc.hostname, tc.source)
+		}
+	}
+}
+
+func TestUpsertDevice_KeepsManualHostname(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+
+	d := &models.Device{
+		Hostname: "dhcp-42", IPAddresses: []string{"10.0.0.42"}, MACAddress: "AA:BB:CC:00:00:42",
+		Status: models.DeviceStatusOnline, DiscoveryMethod: models.DiscoveryARP,
+	}
+	if _, err := s.UpsertDevice(ctx, d); err != nil {
+		t.Fatalf("UpsertDevice: %v", err)
+	}
+	name := "office-desktop"
+	if err := s.UpdateDevice(ctx, d.ID, UpdateDeviceParams{Hostname: &name}); err != nil {
+		t.Fatalf("UpdateDevice: %v", err)
+	}
+
+	rescan := &models.Device{
+		Hostname: "dhcp-42.lan", IPAddresses: []string{"10.0.0.42"}, MACAddress: "AA:BB:CC:00:00:42",
+		Status: models.DeviceStatusOnline, DiscoveryMethod: models.DiscoveryARP,
+	}
+	if _, err := s.UpsertDevice(ctx, rescan); err != nil {
+		t.Fatalf("UpsertDevice: %v", err)
+	}
+	got, _ := s.GetDevice(ctx, d.ID)
+	if got.Hostname != name {
+		t.Errorf("Hostname = %q after rescan, want manual %q kept", got.Hostname, name)
+	}
+}
+
+func TestHandleResolveDeviceDNS(t *testing.T) {
+	m := newTestModule(t)
+	ctx := context.Background()
+	m.reverseDNS = &ReverseDNSEnricher{
+		store:      m.store,
+		timeout:    time.Second,
+		lookupAddr: fakePTR(map[string]string{"10.0.0.20": "cam.home.arpa."}),
+		logger:     zap.NewNop(),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /devices/{id}/resolve-dns", m.handleResolveDeviceDNS)
+
+	d := &models.Device{
+		IPAddresses: []string{"10.0.0.20"}, MACAddress: "AA:BB:CC:00:00:20",
+		Status: models.DeviceStatusOnline, DiscoveryMethod: models.DiscoveryARP,
+	}
+	_, _ = m.store.UpsertDevice(ctx, d)
+
+	resolve := func(id string) (*httptest.ResponseRecorder, ResolveDNSResponse) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("POST", "/devices/"+id+"/resolve-dns", http.NoBody))
+		var resp ResolveDNSResponse
+		_ = json.NewDecoder(w.Body).Decode(&resp)
+		return w, resp
+	}
+
+	w, resp := resolve(d.ID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	want := ResolveDNSResponse{
+		DeviceID: d.ID, ResolvedName: "cam.home.arpa", Hostname: "cam.home.arpa",
+		HostnameSource: HostnameSourceReverseDNS, Updated: true,
+	}
+	if resp != want {
+		t.Errorf("response = %+v, want %+v", resp, want)
+	}
+
+	// A second lookup finds the name again but leaves the hostname alone.
+	if _, resp = resolve(d.ID); resp.Updated || resp.Hostname != "cam.home.arpa" {
+		t.Errorf("second response = %+v, want not updated", resp)
+	}
+
+	if w, _ := resolve("missing"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown device status = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,198 @@
+package recon
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Scan enrichment methods a template can enable. ICMP discovery,
+// classification, topology inference, and hierarchy always run.
+const (
+	ScanMethodARP   = "arp"   // read the ARP table for MAC addresses
+	ScanMethodDNS   = "dns"   // reverse DNS for hostnames
+	ScanMethodGeoIP = "geoip" // GeoIP tags for public addresses
+	ScanMethodPorts = "ports" // TCP port fingerprinting
+	ScanMethodSNMP  = "snmp"  // switch FDB and LLDP walks
+	ScanMethodWiFi  = "wifi"  // WiFi survey and AP client enumeration
+)
+
+// ScanMethods lists every enrichment method, in the order stages run.
+var ScanMethods = []string{ScanMethodARP, ScanMethodDNS, ScanMethodGeoIP, ScanMethodPorts, ScanMethodSNMP, ScanMethodWiFi}
+
+// stageMethods maps post-scan stages to the method that enables them.
+// Stages not listed always run.
+var stageMethods = map[string]string{
+	"wifi-scan":       ScanMethodWiFi,
+	"reverse-dns":     ScanMethodDNS,
+	"geoip":           ScanMethodGeoIP,
+	"port-scan":       ScanMethodPorts,
+	"fdb-walk":        ScanMethodSNMP,
+	"lldp-walk":       ScanMethodSNMP,
+	"wifi-ap-clients": ScanMethodWiFi,
+	"wifi-heuristic":  ScanMethodWiFi,
+}
+
+// maxTemplatePorts caps the port list of a scan template.
+const maxTemplatePorts = 1024
+
+// ErrScanTemplateNotFound is returned when a scan names a template that does
+// not exist.
+var ErrScanTemplateNotFound = errors.New("scan template not found")
+
+// ErrScanTemplateExists is returned when a template name is already taken.
+var ErrScanTemplateExists = errors.New("a scan template with that name already exists")
+
+// ScanTemplate is a named set of scan options that overrides the configured
+// defaults for a single scan, such as a fast ping-only sweep or a deep
+// SNMP and port scan. Zero tuning values fall back to the config.
+type ScanTemplate struct {
+	ID          string `json:"id"`
+	Name        string `json:"name" example:"Deep SNMP + ports"`
+	Description string `json:"description,omitempty"`
+
+	// Methods are the enrichment methods to run. Omitted on create means
+	// all methods; an empty list runs discovery only.
+	Methods []string `json:"methods" example:"arp,dns,ports,snmp"`
+	// Ports are the TCP ports probed by the ports method. Empty uses the
+	// built-in infrastructure port list.
+	Ports []int `json:"ports,omitempty"`
+	// PortScanAllHosts probes every live host rather than only devices
+	// whose vendor marks them as network infrastructure.
+	PortScanAllHosts bool `json:"port_scan_all_hosts"`
+
+	Concurrency         int   `json:"concurrency,omitempty" example:"128"`
+	PingTimeoutMs       int64 `json:"ping_timeout_ms,omitempty" example:"500"`
+	PingCount           int   `json:"ping_count,omitempty" example:"1"`
+	PortScanConcurrency int   `json:"port_scan_concurrency,omitempty" example:"20"`
+	PortTimeoutMs       int64 `json:"port_timeout_ms,omitempty" example:"1000"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks the name, methods, ports, and tuning bounds, and
+// normalizes the method list.
+func (t *ScanTemplate) Validate() error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	methods := make([]string, 0, len(t.Methods))
+	for _, method := range t.Methods {
+		if !slices.Contains(ScanMethods, method) {
+			return fmt.Errorf("unknown method %q; valid methods are %s", method, strings.Join(ScanMethods, ", "))
+		}
+		if !slices.Contains(methods, method) {
+			methods = append(methods, method)
+		}
+	}
+	t.Methods = methods
+	if len(t.Ports) > maxTemplatePorts {
+		return fmt.Errorf("at most %d ports allowed", maxTemplatePorts)
+	}
+	for _, port := range t.Ports {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("ports entry %d out of range 1-65535", port)
+		}
+	}
+	if t.Concurrency < 0 || t.Concurrency > MaxConcurrency {
+		return fmt.Errorf("concurrency %d out of range [0, %d]", t.Concurrency, MaxConcurrency)
+	}
+	if t.PortScanConcurrency < 0 || t.PortScanConcurrency > MaxPortScanConcurrency {
+		return fmt.Errorf("port_scan_concurrency %d out of range [0, %d]", t.PortScanConcurrency, MaxPortScanConcurrency)
+	}
+	if t.PingCount < 0 || t.PingCount > MaxPingCount {
+		return fmt.Errorf("ping_count %d out of range [0, %d]", t.PingCount, MaxPingCount)
+	}
+	for _, timeout := range []struct {
+		name string
+		ms   int64
+	}{{"ping_timeout_ms", t.PingTimeoutMs}, {"port_timeout_ms", t.PortTimeoutMs}} {
+		d := time.Duration(timeout.ms) * time.Millisecond
+		if timeout.ms != 0 && (d < MinHostTimeout || d > MaxHostTimeout) {
+			return fmt.Errorf("%s %d out of range [%d, %d]", timeout.name, timeout.ms,
+				MinHostTimeout.Milliseconds(), MaxHostTimeout.Milliseconds())
+		}
+	}
+	return nil
+}
+
+// scanPlan is what a single scan runs with: the configured defaults,
+// overridden by a template when one was chosen.
+type scanPlan struct {
+	pinger           PingScanner
+	tuning           ScanTuning
+	methods          map[string]bool
+	ports            []int
+	portScanAllHosts bool
+}
+
+// enabled reports whether the plan runs the given enrichment method.
+func (p *scanPlan) enabled(method string) bool {
+	return p.methods[method]
+}
+
+// filterStages drops stages whose enabling method is off.
+func (p *scanPlan) filterStages(stages []scanStage) []scanStage {
+	kept := stages[:0:0]
+	for _, stage := range stages {
+		if method, ok := stageMethods[stage.name]; ok && !p.enabled(method) {
+			continue
+		}
+		kept = append(kept, stage)
+	}
+	return kept
+}
+
+// tunablePinger is a PingScanner that can be copied with different
+// per-host settings for a templated scan.
+type tunablePinger interface {
+	WithTuning(concurrency int, timeout time.Duration, count int) PingScanner
+}
+
+// planScan builds the plan for a scan, applying tmpl over the orchestrator
+// defaults. A nil template runs every method with the configured tuning.
+// Ping overrides only take effect when the pinger supports them.
+func (o *ScanOrchestrator) planScan(tmpl *ScanTemplate) *scanPlan {
+	plan := &scanPlan{
+		pinger:  o.pinger,
+		tuning:  o.Tuning(),
+		methods: make(map[string]bool, len(ScanMethods)),
+		ports:   InfrastructurePorts,
+	}
+	if tmpl == nil {
+		for _, method := range ScanMethods {
+			plan.methods[method] = true
+		}
+		return plan
+	}
+
+	for _, method := range tmpl.Methods {
+		plan.methods[method] = true
+	}
+	if len(tmpl.Ports) > 0 {
+		plan.ports = tmpl.Ports
+	}
+	plan.portScanAllHosts = tmpl.PortScanAllHosts
+	if tmpl.PortScanConcurrency > 0 {
+		plan.tuning.PortScanConcurrency = tmpl.PortScanConcurrency
+	}
+	if tmpl.PortTimeoutMs > 0 {
+		plan.tuning.PortTimeout = time.Duration(tmpl.PortTimeoutMs) * time.Millisecond
+	}
+
+	tp, ok := o.pinger.(tunablePinger)
+	if ok && (tmpl.Concurrency > 0 || tmpl.PingTimeoutMs > 0 || tmpl.PingCount > 0) {
+		if tmpl.Concurrency > 0 {
+			plan.tuning.Concurrency = tmpl.Concurrency
+		}
+		if tmpl.PingTimeoutMs > 0 {
+			plan.tuning.PingTimeout = time.Duration(tmpl.PingTimeoutMs) * time.Millisecond
+		}
+		plan.pinger = tp.WithTuning(plan.tuning.Concurrency, plan.tuning.PingTimeout, tmpl.PingCount)
+	}
+	return plan
+}
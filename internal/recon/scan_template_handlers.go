@@ -0,0 +1,180 @@
+package recon
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+
+	"go.uber.org/zap"
+)
+
+// handleListScanTemplates returns all scan templates.
+//
+//	@Summary		List scan templates
+//	@Description	Returns all saved scan templates, ordered by name.
+//	@Tags			recon
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{array}		ScanTemplate
+//	@Failure		500	{object}	models.APIProblem
+//	@Router			/recon/scan-templates [get]
+func (m *Module) handleListScanTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := m.store.ListScanTemplates(r.Context())
+	if err != nil {
+		m.logger.Error("failed to list scan templates", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to list scan templates")
+		return
+	}
+	if templates == nil {
+		templates = []ScanTemplate{}
+	}
+	writeJSON(w, http.StatusOK, templates)
+}
+
+// handleCreateScanTemplate creates a scan template.
+//
+//	@Summary		Create scan template
+//	@Description	Creates a named set of scan options. Pass its ID as template_id to POST /recon/scan to override the configured methods, ports, timeouts, and concurrency for that scan. Omitted methods enable all of arp, dns, geoip, ports, snmp, and wifi; an empty list runs a ping-only sweep. Zero tuning values use the config.
+//	@Tags			recon
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		ScanTemplate	true	"Template to create"
+//	@Success		201		{object}	ScanTemplate
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		409		{object}	models.APIProblem
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/recon/scan-templates [post]
+func (m *Module) handleCreateScanTemplate(w http.ResponseWriter, r *http.Request) {
+	var t ScanTemplate
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	t.ID = ""
+	if t.Methods == nil {
+		t.Methods = slices.Clone(ScanMethods)
+	}
+	if err := t.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := m.store.CreateScanTemplate(r.Context(), &t); err != nil {
+		if errors.Is(err, ErrScanTemplateExists) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		m.logger.Error("failed to create scan template", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to create scan template")
+		return
+	}
+	writeJSON(w, http.StatusCreated, t)
+}
+
+// handleGetScanTemplate returns a single scan template.
+//
+//	@Summary		Get scan template
+//	@Description	Returns a scan template by ID.
+//	@Tags			recon
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Template ID"
+//	@Success		200	{object}	ScanTemplate
+//	@Failure		404	{object}	models.APIProblem
+//	@Failure		500	{object}	models.APIProblem
+//	@Router			/recon/scan-templates/{id} [get]
+func (m *Module) handleGetScanTemplate(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	t, err := m.store.GetScanTemplate(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "scan template not found")
+			return
+		}
+		m.logger.Error("failed to get scan template", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to get scan template")
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+// handleUpdateScanTemplate updates a scan template. Fields omitted from the
+// body keep their current values.
+//
+//	@Summary		Update scan template
+//	@Description	Updates a scan template. Omitted fields are left unchanged.
+//	@Tags			recon
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string			true	"Template ID"
+//	@Param			request	body		ScanTemplate	true	"Fields to update"
+//	@Success		200		{object}	ScanTemplate
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		404		{object}	models.APIProblem
+//	@Failure		409		{object}	models.APIProblem
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/recon/scan-templates/{id} [put]
+func (m *Module) handleUpdateScanTemplate(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	t, err := m.store.GetScanTemplate(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "scan template not found")
+			return
+		}
+		m.logger.Error("failed to get scan template", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to update scan template")
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(t); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	t.ID = id
+	if err := t.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := m.store.UpdateScanTemplate(r.Context(), t); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			writeError(w, http.StatusNotFound, "scan template not found")
+		case errors.Is(err, ErrScanTemplateExists):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			m.logger.Error("failed to update scan template", zap.String("id", id), zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "failed to update scan template")
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+// handleDeleteScanTemplate deletes a scan template.
+//
+//	@Summary		Delete scan template
+//	@Description	Deletes a scan template. Past scans keep the template ID they ran with.
+//	@Tags			recon
+//	@Security		BearerAuth
+//	@Param			id	path	string	true	"Template ID"
+//	@Success		204	"No content"
+//	@Failure		404	{object}	models.APIProblem
+//	@Failure		500	{object}	models.APIProblem
+//	@Router			/recon/scan-templates/{id} [delete]
+func (m *Module) handleDeleteScanTemplate(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := m.store.DeleteScanTemplate(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "scan template not found")
+			return
+		}
+		m.logger.Error("failed to delete scan template", zap.String("id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to delete scan template")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,160 @@
+package recon
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const scanTemplateColumns = `id, name, description, methods, ports, port_scan_all_hosts,
+	concurrency, ping_timeout_ms, ping_count, port_scan_concurrency, port_timeout_ms,
+	created_at, updated_at`
+
+// CreateScanTemplate inserts a new scan template. Returns
+// ErrScanTemplateExists if the name is taken.
+func (s *ReconStore) CreateScanTemplate(ctx context.Context, t *ScanTemplate) error {
+	if err := s.checkScanTemplateName(ctx, t); err != nil {
+		return err
+	}
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	now := time.Now().UTC()
+	t.CreatedAt = now
+	t.UpdatedAt = now
+	methodsJSON, portsJSON := scanTemplateJSON(t)
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO recon_scan_templates (`+scanTemplateColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.ID, t.Name, t.Description, methodsJSON, portsJSON, t.PortScanAllHosts,
+		t.Concurrency, t.PingTimeoutMs, t.PingCount, t.PortScanConcurrency, t.PortTimeoutMs,
+		t.CreatedAt, t.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("create scan template: %w", err)
+	}
+	return nil
+}
+
+// ListScanTemplates returns all scan templates ordered by name.
+func (s *ReconStore) ListScanTemplates(ctx context.Context) ([]ScanTemplate, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+scanTemplateColumns+`
+		FROM recon_scan_templates ORDER BY name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("list scan templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []ScanTemplate
+	for rows.Next() {
+		t, err := scanScanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *t)
+	}
+	return templates, rows.Err()
+}
+
+// GetScanTemplate returns a scan template by ID.
+// Returns sql.ErrNoRows if it does not exist.
+func (s *ReconStore) GetScanTemplate(ctx context.Context, id string) (*ScanTemplate, error) {
+	return scanScanTemplate(s.db.QueryRowContext(ctx, `SELECT `+scanTemplateColumns+`
+		FROM recon_scan_templates WHERE id = ?`, id))
+}
+
+// UpdateScanTemplate replaces every editable field of an existing template.
+// Returns sql.ErrNoRows if it does not exist and ErrScanTemplateExists if
+// the new name is taken.
+func (s *ReconStore) UpdateScanTemplate(ctx context.Context, t *ScanTemplate) error {
+	if err := s.checkScanTemplateName(ctx, t); err != nil {
+		return err
+	}
+	t.UpdatedAt = time.Now().UTC()
+	methodsJSON, portsJSON := scanTemplateJSON(t)
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE recon_scan_templates SET
+			name = ?, description = ?, methods = ?, ports = ?, port_scan_all_hosts = ?,
+			concurrency = ?, ping_timeout_ms = ?, ping_count = ?,
+			port_scan_concurrency = ?, port_timeout_ms = ?, updated_at = ?
+		WHERE id = ?`,
+		t.Name, t.Description, methodsJSON, portsJSON, t.PortScanAllHosts,
+		t.Concurrency, t.PingTimeoutMs, t.PingCount,
+		t.PortScanConcurrency, t.PortTimeoutMs, t.UpdatedAt, t.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update scan template: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteScanTemplate removes a scan template. Scans that already ran with
+// it keep its ID. Returns sql.ErrNoRows if it does not exist.
+func (s *ReconStore) DeleteScanTemplate(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM recon_scan_templates WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete scan template: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// checkScanTemplateName returns ErrScanTemplateExists if another template
+// already uses t's name.
+func (s *ReconStore) checkScanTemplateName(ctx context.Context, t *ScanTemplate) error {
+	var id string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT id FROM recon_scan_templates WHERE name = ? AND id != ?`, t.Name, t.ID,
+	).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil
+	case err != nil:
+		return fmt.Errorf("check scan template name: %w", err)
+	default:
+		return ErrScanTemplateExists
+	}
+}
+
+// scanTemplateJSON encodes the list columns, storing nil lists as "[]".
+func scanTemplateJSON(t *ScanTemplate) (methods, ports string) {
+	methods, ports = "[]", "[]"
+	if len(t.Methods) > 0 {
+		b, _ := json.Marshal(t.Methods)
+		methods = string(b)
+	}
+	if len(t.Ports) > 0 {
+		b, _ := json.Marshal(t.Ports)
+		ports = string(b)
+	}
+	return methods, ports
+}
+
+// scanScanTemplate scans a template from a row selected with
+// scanTemplateColumns.
+func scanScanTemplate(row interface{ Scan(...any) error }) (*ScanTemplate, error) {
+	var t ScanTemplate
+	var methodsJSON, portsJSON string
+	err := row.Scan(&t.ID, &t.Name, &t.Description, &methodsJSON, &portsJSON, &t.PortScanAllHosts,
+		&t.Concurrency, &t.PingTimeoutMs, &t.PingCount, &t.PortScanConcurrency, &t.PortTimeoutMs,
+		&t.CreatedAt, &t.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scan scan template: %w", err)
+	}
+	t.Methods = []string{}
+	_ = json.Unmarshal([]byte(methodsJSON), &t.Methods)
+	_ = json.Unmarshal([]byte(portsJSON), &t.Ports)
+	return &t, nil
+}
@@ -0,0 +1,253 @@
+package recon
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+// tunableMockPinger records the tuning a templated scan asked for.
+type tunableMockPinger struct {
+	mockPingScanner
+	concurrency int
+	timeout     time.Duration
+	count       int
+}
+
+func (p *tunableMockPinger) WithTuning(concurrency int, timeout time.Duration, count int) PingScanner {
+	tuned := *p
+	tuned.concurrency, tuned.timeout, tuned.count = concurrency, timeout, count
+	return &tuned
+}
+
+func TestScanTemplate_Validate(t *testing.T) {
+	tmpl := ScanTemplate{Name: "  Deep  ", Methods: []string{ScanMethodSNMP, ScanMethodPorts, ScanMethodSNMP}}
+	if err := tmpl.Validate(); err != nil {
+		t.Fatalf("valid template: %v", err)
+	}
+	if tmpl.Name != "Deep" || !slices.Equal(tmpl.Methods, []string{ScanMethodSNMP, ScanMethodPorts}) {
+		t.Errorf("normalized template = %q %v", tmpl.Name, tmpl.Methods)
+	}
+
+	for name, bad := range map[string]ScanTemplate{
+		"no name":         {Methods: []string{}},
+		"unknown method":  {Name: "x", Methods: []string{"nmap"}},
+		"port range":      {Name: "x", Ports: []int{0}},
+		"concurrency":     {Name: "x", Concurrency: MaxConcurrency + 1},
+		"port workers":    {Name: "x", PortScanConcurrency: -1},
+		"ping count":      {Name: "x", PingCount: MaxPingCount + 1},
+		"ping timeout":    {Name: "x", PingTimeoutMs: 10},
+		"port timeout":    {Name: "x", PortTimeoutMs: MaxHostTimeout.Milliseconds() + 1},
+		"too many ports":  {Name: "x", Ports: make([]int, maxTemplatePorts+1)},
+		"blank name only": {Name: "   "},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("%s: Validate succeeded, want error", name)
+		}
+	}
+}
+
+func TestPlanScan(t *testing.T) {
+	pinger := &tunableMockPinger{}
+	o := &ScanOrchestrator{pinger: pinger, tuning: ScanTuningFromConfig(DefaultConfig())}
+	defaults := o.Tuning()
+
+	plan := o.planScan(nil)
+	for _, method := range ScanMethods {
+		if !plan.enabled(method) {
+			t.Errorf("default plan has %s disabled", method)
+		}
+	}
+	if plan.tuning != defaults || plan.pinger != PingScanner(pinger) || !slices.Equal(plan.ports, InfrastructurePorts) {
+		t.Errorf("default plan = %+v, want config defaults", plan)
+	}
+
+	plan = o.planScan(&ScanTemplate{
+		Methods:       []string{ScanMethodPorts},
+		Ports:         []int{22, 3389},
+		Concurrency:   256,
+		PingTimeoutMs: 300,
+		PingCount:     1,
+		PortTimeoutMs: 750,
+	})
+	if plan.enabled(ScanMethodARP) || !plan.enabled(ScanMethodPorts) {
+		t.Errorf("template methods = %v", plan.methods)
+	}
+	if !slices.Equal(plan.ports, []int{22, 3389}) {
+		t.Errorf("ports = %v", plan.ports)
+	}
+	want := defaults
+	want.Concurrency, want.PingTimeout, want.PortTimeout = 256, 300*time.Millisecond, 750*time.Millisecond
+	if plan.tuning != want {
+		t.Errorf("tuning = %+v, want %+v", plan.tuning, want)
+	}
+	tuned, ok := plan.pinger.(*tunableMockPinger)
+	if !ok || tuned.concurrency != 256 || tuned.timeout != 300*time.Millisecond || tuned.count != 1 {
+		t.Errorf("pinger = %+v, want tuned copy", plan.pinger)
+	}
+
+	// Ping overrides are not applied, or reported, when the pinger
+	// cannot be retuned.
+	o.pinger = &mockPingScanner{}
+	if plan := o.planScan(&ScanTemplate{Concurrency: 256}); plan.tuning.Concurrency != defaults.Concurrency {
+		t.Errorf("untunable pinger concurrency = %d, want %d", plan.tuning.Concurrency, defaults.Concurrency)
+	}
+}
+
+func TestScanPlan_FilterStages(t *testing.T) {
+	plan := (&ScanOrchestrator{tuning: ScanTuningFromConfig(DefaultConfig())}).planScan(&ScanTemplate{Methods: []string{ScanMethodSNMP}})
+	noop := func(context.Context) {}
+	var names []string
+	for _, s := range plan.filterStages([]scanStage{
+		{"wifi-scan", noop}, {"reverse-dns", noop}, {"port-scan", noop},
+		{"classify", noop}, {"fdb-walk", noop}, {"lldp-walk", noop}, {"hierarchy", noop},
+	}) {
+		names = append(names, s.name)
+	}
+	if want := []string{"classify", "fdb-walk", "lldp-walk", "hierarchy"}; !slices.Equal(names, want) {
+		t.Errorf("stages = %v, want %v", names, want)
+	}
+}
+
+func TestRunScanWithTemplate_PingOnly(t *testing.T) {
+	pinger := &mockPingScanner{results: []HostResult{{IP: "192.168.5.1", Alive: true, Method: "icmp"}}}
+	arp := &mockARPReader{table: map[string]string{"192.168.5.1": "AA:BB:CC:DD:EE:01"}}
+	orch, s, _ := setupOrchestrator(t, pinger, arp, &mockOUI{table: map[string]string{}})
+	ctx := context.Background()
+
+	tmpl := &ScanTemplate{Name: "Ping only", Methods: []string{}}
+	if err := s.CreateScanTemplate(ctx, tmpl); err != nil {
+		t.Fatalf("CreateScanTemplate: %v", err)
+	}
+	_ = s.CreateScan(ctx, &models.ScanResult{ID: "scan-fast", Subnet: "192.168.5.0/24", TemplateID: tmpl.ID})
+	orch.RunScanWithTemplate(ctx, "scan-fast", "192.168.5.0/24", tmpl)
+
+	scan, err := s.GetScan(ctx, "scan-fast")
+	if err != nil {
+		t.Fatalf("GetScan: %v", err)
+	}
+	if scan.Status != "completed" || scan.TemplateID != tmpl.ID {
+		t.Errorf("scan = %+v, want completed with template %s", scan, tmpl.ID)
+	}
+	d, err := s.GetDeviceByIP(ctx, "192.168.5.1")
+	if err != nil || d == nil {
+		t.Fatalf("GetDeviceByIP: %v", err)
+	}
+	if d.MACAddress != "" {
+		t.Errorf("MAC = %q, want none with ARP disabled", d.MACAddress)
+	}
+}
+
+func TestScanTemplateStore(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+
+	deep := &ScanTemplate{Name: "Deep", Methods: []string{ScanMethodSNMP, ScanMethodPorts}, Ports: []int{22, 161}, PortScanAllHosts: true, PortTimeoutMs: 1500}
+	if err := s.CreateScanTemplate(ctx, deep); err != nil {
+		t.Fatalf("CreateScanTemplate: %v", err)
+	}
+	fast := &ScanTemplate{Name: "Fast", Methods: []string{}, Concurrency: 256}
+	if err := s.CreateScanTemplate(ctx, fast); err != nil {
+		t.Fatalf("CreateScanTemplate: %v", err)
+	}
+	if err := s.CreateScanTemplate(ctx, &ScanTemplate{Name: "Deep"}); !errors.Is(err, ErrScanTemplateExists) {
+		t.Errorf("duplicate name err = %v, want ErrScanTemplateExists", err)
+	}
+
+	got, err := s.GetScanTemplate(ctx, deep.ID)
+	if err != nil {
+		t.Fatalf("GetScanTemplate: %v", err)
+	}
+	if !slices.Equal(got.Methods, deep.Methods) || !slices.Equal(got.Ports, deep.Ports) || !got.PortScanAllHosts || got.PortTimeoutMs != 1500 {
+		t.Errorf("round trip = %+v", got)
+	}
+	if got, _ := s.GetScanTemplate(ctx, fast.ID); got.Methods == nil || len(got.Methods) != 0 {
+		t.Errorf("ping-only methods = %#v, want empty list", got.Methods)
+	}
+
+	list, _ := s.ListScanTemplates(ctx)
+	if len(list) != 2 || list[0].Name != "Deep" || list[1].Name != "Fast" {
+		t.Errorf("list = %+v", list)
+	}
+
+	fast.Name = "Deep"
+	if err := s.UpdateScanTemplate(ctx, fast); !errors.Is(err, ErrScanTemplateExists) {
+		t.Errorf("rename onto existing err = %v, want ErrScanTemplateExists", err)
+	}
+	fast.Name, fast.Concurrency = "Faster", 512
+	if err := s.UpdateScanTemplate(ctx, fast); err != nil {
+		t.Fatalf("UpdateScanTemplate: %v", err)
+	}
+	if got, _ := s.GetScanTemplate(ctx, fast.ID); got.Name != "Faster" || got.Concurrency != 512 {
+		t.Errorf("updated = %+v", got)
+	}
+
+	if err := s.DeleteScanTemplate(ctx, fast.ID); err != nil {
+		t.Fatalf("DeleteScanTemplate: %v", err)
+	}
+	if _, err := s.GetScanTemplate(ctx, fast.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("get after delete err = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestScanTemplateHandlers(t *testing.T) {
+	m := newTestModule(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /scan-templates", m.handleCreateScanTemplate)
+	mux.HandleFunc("GET /scan-templates/{id}", m.handleGetScanTemplate)
+	mux.HandleFunc("PUT /scan-templates/{id}", m.handleUpdateScanTemplate)
+	mux.HandleFunc("DELETE /scan-templates/{id}", m.handleDeleteScanTemplate)
+	mux.HandleFunc("POST /scan", m.handleScan)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	w := do("POST", "/scan-templates", `{"name":"Deep","ports":[22,161]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create status = %d: %s", w.Code, w.Body.String())
+	}
+	var tmpl ScanTemplate
+	_ = json.NewDecoder(w.Body).Decode(&tmpl)
+	if !slices.Equal(tmpl.Methods, ScanMethods) {
+		t.Errorf("default methods = %v, want all", tmpl.Methods)
+	}
+
+	if w := do("POST", "/scan-templates", `{"name":"Deep"}`); w.Code != http.StatusConflict {
+		t.Errorf("duplicate status = %d, want 409", w.Code)
+	}
+	if w := do("POST", "/scan-templates", `{"name":"Bad","methods":["nmap"]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown method status = %d, want 400", w.Code)
+	}
+
+	w = do("PUT", "/scan-templates/"+tmpl.ID, `{"methods":["snmp"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update status = %d: %s", w.Code, w.Body.String())
+	}
+	var updated ScanTemplate
+	_ = json.NewDecoder(w.Body).Decode(&updated)
+	if updated.Name != "Deep" || !slices.Equal(updated.Ports, []int{22, 161}) || !slices.Equal(updated.Methods, []string{ScanMethodSNMP}) {
+		t.Errorf("updated = %+v", updated)
+	}
+
+	if w := do("POST", "/scan", `{"subnet":"10.9.0.0/24","template_id":"missing"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("scan with unknown template status = %d, want 400", w.Code)
+	}
+
+	if w := do("DELETE", "/scan-templates/"+tmpl.ID, ""); w.Code != http.StatusNoContent {
+		t.Errorf("delete status = %d, want 204", w.Code)
+	}
+	if w := do("GET", "/scan-templates/"+tmpl.ID, ""); w.Code != http.StatusNotFound {
+		t.Errorf("get after delete status = %d, want 404", w.Code)
+	}
+}
@@ -6,10 +6,14 @@ import (
 	"fmt"
 	"net"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/HerbHall/subnetree/internal/tracing"
 	"github.com/HerbHall/subnetree/pkg/models"
 	"github.com/HerbHall/subnetree/pkg/plugin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.uber.org/zap"
 )
 
@@ -36,6 +40,11 @@ type SNMPWalker interface {
 	WalkFDB(ctx context.Context, target string, cred CredentialAccessor, credID string) ([]FDBEntry, error)
 }
 
+// LLDPWalker reads the LLDP neighbor table from a managed device.
+type LLDPWalker interface {
+	WalkLLDP(ctx context.Context, target string, cred CredentialAccessor, credID string) ([]LLDPNeighbor, error)
+}
+
 // CredentialLookup finds SNMP credentials for a device.
 type CredentialLookup interface {
 	FindSNMPCredentialForDevice(ctx context.Context, deviceID string) (credID string, err error)
@@ -50,13 +59,38 @@ type ScanOrchestrator struct {
 	pinger       PingScanner
 	arp          ARPTableReader
 	snmpWalker   SNMPWalker
+	lldpWalker   LLDPWalker
 	wifiScanner  WifiScanner
 	apEnumerator APClientEnumerator
 	credLookup   CredentialLookup
 	credAccess   CredentialAccessor
+	reverseDNS   *ReverseDNSEnricher
+	geoIP        GeoIPLookup
+	tuningMu     sync.RWMutex
+	tuning       ScanTuning
 	logger       *zap.Logger
 }
 
+// ScanTuning is the effective worker and timeout configuration a scan runs
+// with. It is recorded in the scan metrics so settings can be correlated
+// with scan duration.
+type ScanTuning struct {
+	Concurrency         int
+	PingTimeout         time.Duration
+	PortScanConcurrency int
+	PortTimeout         time.Duration
+}
+
+// ScanTuningFromConfig extracts the scan tuning from the module config.
+func ScanTuningFromConfig(cfg ReconConfig) ScanTuning {
+	return ScanTuning{
+		Concurrency:         cfg.Concurrency,
+		PingTimeout:         cfg.PingTimeout,
+		PortScanConcurrency: cfg.PortScanConcurrency,
+		PortTimeout:         cfg.PortTimeout,
+	}
+}
+
 // NewScanOrchestrator creates a new orchestrator.
 func NewScanOrchestrator(
 	store *ReconStore,
@@ -72,15 +106,38 @@ func NewScanOrchestrator(
 		oui:    oui,
 		pinger: pinger,
 		arp:    arp,
+		tuning: ScanTuningFromConfig(DefaultConfig()),
 		logger: logger,
 	}
 }
 
+// SetTuning configures the worker counts and per-host timeouts used by scans.
+// The ping values must match those the pinger was built with. Safe to call
+// while scans are running; a running scan may pick up the new port values.
+func (o *ScanOrchestrator) SetTuning(t ScanTuning) {
+	o.tuningMu.Lock()
+	o.tuning = t
+	o.tuningMu.Unlock()
+}
+
+// Tuning returns the current scan tuning.
+func (o *ScanOrchestrator) Tuning() ScanTuning {
+	o.tuningMu.RLock()
+	defer o.tuningMu.RUnlock()
+	return o.tuning
+}
+
 // SetSNMPWalker configures the SNMP FDB walker used during scan post-processing.
 func (o *ScanOrchestrator) SetSNMPWalker(w SNMPWalker) {
 	o.snmpWalker = w
 }
 
+// SetLLDPWalker configures the LLDP neighbor walker used during scan
+// post-processing and on-demand LLDP polls.
+func (o *ScanOrchestrator) SetLLDPWalker(w LLDPWalker) {
+	o.lldpWalker = w
+}
+
 // SetWifiScanner configures the WiFi scanner used to discover nearby access points.
 func (o *ScanOrchestrator) SetWifiScanner(ws WifiScanner) {
 	o.wifiScanner = ws
@@ -102,6 +159,18 @@ func (o *ScanOrchestrator) SetCredentialAccessor(ca CredentialAccessor) {
 	o.credAccess = ca
 }
 
+// SetReverseDNS configures the enricher that resolves hostnames for
+// devices still missing one after a scan. Nil disables the pass.
+func (o *ScanOrchestrator) SetReverseDNS(e *ReverseDNSEnricher) {
+	o.reverseDNS = e
+}
+
+// SetGeoIP configures the lookup used to tag devices that have public IPs
+// with their location after a scan. Nil disables the pass.
+func (o *ScanOrchestrator) SetGeoIP(g GeoIPLookup) {
+	o.geoIP = g
+}
+
 // scanStage represents a named post-scan processing stage.
 type scanStage struct {
 	name string
@@ -109,6 +178,7 @@ type scanStage struct {
 }
 
 // runStages executes stages sequentially, checking for cancellation between each.
+// Each stage runs in its own child span of the scan span.
 func (o *ScanOrchestrator) runStages(ctx context.Context, stages []scanStage) {
 	for _, stage := range stages {
 		if ctx.Err() != nil {
@@ -116,16 +186,37 @@ func (o *ScanOrchestrator) runStages(ctx context.Context, stages []scanStage) {
 				zap.String("skipped", stage.name))
 			return
 		}
-		stage.run(ctx)
+		stageCtx, span := tracing.Tracer("recon").Start(ctx, "recon.scan."+stage.name)
+		stage.run(stageCtx)
+		span.End()
 	}
 }
 
 // RunScan executes a full network scan for the given subnet.
 func (o *ScanOrchestrator) RunScan(ctx context.Context, scanID, subnet string) {
+	o.RunScanWithTemplate(ctx, scanID, subnet, nil)
+}
+
+// RunScanWithTemplate executes a full network scan for the given subnet,
+// with tmpl's methods, ports, and tuning overriding the defaults. A nil
+// template behaves like RunScan.
+func (o *ScanOrchestrator) RunScanWithTemplate(ctx context.Context, scanID, subnet string, tmpl *ScanTemplate) {
 	scanStart := time.Now()
+	plan := o.planScan(tmpl)
+
+	ctx, span := tracing.Tracer("recon").Start(ctx, "recon.scan")
+	span.SetAttributes(
+		attribute.String("scan.id", scanID),
+		attribute.String("scan.subnet", subnet),
+	)
+	if tmpl != nil {
+		span.SetAttributes(attribute.String("scan.template_id", tmpl.ID))
+	}
+	defer span.End()
 
 	_, ipNet, err := net.ParseCIDR(subnet)
 	if err != nil {
+		span.SetStatus(codes.Error, "invalid subnet")
 		o.logger.Error("invalid subnet", zap.String("subnet", subnet), zap.Error(err))
 		_ = o.store.UpdateScanError(ctx, scanID, "invalid subnet: "+err.Error())
 		return
@@ -146,15 +237,17 @@ func (o *ScanOrchestrator) RunScan(ctx context.Context, scanID, subnet string) {
 
 	// Read ARP table upfront so we can enrich devices as they arrive.
 	arpTable := map[string]string{}
-	if o.arp != nil {
+	if o.arp != nil && plan.enabled(ScanMethodARP) {
 		arpTable = o.arp.ReadTable(ctx)
 	}
 
-	// Run ICMP scan.
+	// Run ICMP scan. The ping span covers the streaming loop below, so
+	// device upserts made while hosts arrive are recorded under it.
+	pingCtx, pingSpan := tracing.Tracer("recon").Start(ctx, "recon.scan.ping")
 	results := make(chan HostResult, 256)
 	scanDone := make(chan error, 1)
 	go func() {
-		scanDone <- o.pinger.Scan(ctx, ipNet, results)
+		scanDone <- plan.pinger.Scan(pingCtx, ipNet, results)
 		close(results)
 	}()
 
@@ -186,7 +279,10 @@ func (o *ScanOrchestrator) RunScan(ctx context.Context, scanID, subnet string) {
 			discoveryMethod = models.DiscoveryARP
 		}
 
-		hostname := o.resolveHostname(r.IP)
+		hostname := ""
+		if plan.enabled(ScanMethodDNS) {
+			hostname = o.resolveHostname(r.IP)
+		}
 
 		deviceType := models.DeviceTypeUnknown
 		if manufacturer != "" {
@@ -203,7 +299,7 @@ func (o *ScanOrchestrator) RunScan(ctx context.Context, scanID, subnet string) {
 			DiscoveryMethod: discoveryMethod,
 		}
 
-		created, upsertErr := o.store.UpsertDevice(ctx, device)
+		created, upsertErr := o.store.UpsertDevice(pingCtx, device)
 		if upsertErr != nil {
 			o.logger.Error("failed to upsert device", zap.String("ip", r.IP), zap.Error(upsertErr))
 			continue
@@ -216,20 +312,20 @@ func (o *ScanOrchestrator) RunScan(ctx context.Context, scanID, subnet string) {
 		}
 
 		// Link device to scan.
-		if linkErr := o.store.LinkScanDevice(ctx, scanID, device.ID); linkErr != nil {
+		if linkErr := o.store.LinkScanDevice(pingCtx, scanID, device.ID); linkErr != nil {
 			o.logger.Error("failed to link scan device", zap.Error(linkErr))
 		}
 
 		// Emit device event with scan ID immediately.
 		devEvent := &DeviceEvent{ScanID: scanID, Device: device}
 		if created {
-			o.publishEvent(ctx, TopicDeviceDiscovered, devEvent)
+			o.publishEvent(pingCtx, TopicDeviceDiscovered, devEvent)
 		} else {
-			o.publishEvent(ctx, TopicDeviceUpdated, devEvent)
+			o.publishEvent(pingCtx, TopicDeviceUpdated, devEvent)
 		}
 
 		// Emit incremental progress so the UI can show a running count.
-		o.publishEvent(ctx, TopicScanProgress, &ScanProgressEvent{
+		o.publishEvent(pingCtx, TopicScanProgress, &ScanProgressEvent{
 			ScanID:     scanID,
 			HostsAlive: len(alive),
 			SubnetSize: subnetSize,
@@ -238,34 +334,42 @@ func (o *ScanOrchestrator) RunScan(ctx context.Context, scanID, subnet string) {
 
 	// Ping + enrichment happen together in the streaming loop above.
 	enrichDone := time.Now()
+	pingSpan.SetAttributes(attribute.Int("scan.hosts_alive", len(alive)))
+	pingSpan.End()
 
 	// Check for scan error. Use background context for DB cleanup since
 	// the scan context may already be cancelled.
 	cleanupCtx := context.Background()
 	if scanErr := <-scanDone; scanErr != nil {
 		if ctx.Err() != nil {
+			span.SetStatus(codes.Error, "cancelled")
 			o.logger.Info("scan cancelled", zap.String("scan_id", scanID))
 			_ = o.store.UpdateScanError(cleanupCtx, scanID, "cancelled")
 			return
 		}
+		span.RecordError(scanErr)
+		span.SetStatus(codes.Error, scanErr.Error())
 		o.logger.Error("ICMP scan error", zap.Error(scanErr))
 		_ = o.store.UpdateScanError(cleanupCtx, scanID, scanErr.Error())
 		return
 	}
 
 	// Run post-scan processing stages.
-	o.runStages(ctx, []scanStage{
+	o.runStages(ctx, plan.filterStages([]scanStage{
 		{"wifi-scan", func(ctx context.Context) { o.scanWifiNetworks(ctx) }},
-		{"port-scan", func(ctx context.Context) { o.portScanInfraDevices(ctx, alive, arpTable) }},
+		{"reverse-dns", func(ctx context.Context) { o.enrichHostnames(ctx) }},
+		{"geoip", func(ctx context.Context) { o.enrichGeoIP(ctx) }},
+		{"port-scan", func(ctx context.Context) { o.portScanInfraDevices(ctx, alive, arpTable, plan) }},
 		{"classify", func(ctx context.Context) { o.classifyDevices(ctx, alive, arpTable) }},
 		{"unmanaged-switch", func(ctx context.Context) { o.detectUnmanagedSwitches(ctx, alive, arpTable) }},
 		{"fdb-walk", func(ctx context.Context) { o.walkSwitchFDBTables(ctx) }},
+		{"lldp-walk", func(ctx context.Context) { o.walkSwitchLLDPTables(ctx) }},
 		{"wifi-ap-clients", func(ctx context.Context) { o.enumerateAPClients(ctx) }},
 		{"wifi-heuristic", func(ctx context.Context) { o.analyzeWiFiConnections(ctx) }},
 		{"topology-links", func(ctx context.Context) { o.inferTopologyLinks(ctx, subnet, alive) }},
 		{"hierarchy", func(ctx context.Context) { o.inferHierarchy(ctx) }},
 		{"service-movements", func(ctx context.Context) { o.detectAndPublishServiceMovements(ctx, alive) }},
-	})
+	}))
 
 	postDone := time.Now()
 
@@ -284,6 +388,7 @@ func (o *ScanOrchestrator) RunScan(ctx context.Context, scanID, subnet string) {
 
 	// Save scan metrics. Ping and enrichment are combined in the streaming
 	// model, so pingPhaseMs equals the full streaming loop duration.
+	tuning := plan.tuning
 	metrics := &models.ScanMetrics{
 		ScanID:         scanID,
 		DurationMs:     time.Since(scanStart).Milliseconds(),
@@ -294,6 +399,11 @@ func (o *ScanOrchestrator) RunScan(ctx context.Context, scanID, subnet string) {
 		HostsAlive:     len(alive),
 		DevicesCreated: devicesCreated,
 		DevicesUpdated: devicesUpdated,
+
+		Concurrency:         tuning.Concurrency,
+		PingTimeoutMs:       tuning.PingTimeout.Milliseconds(),
+		PortScanConcurrency: tuning.PortScanConcurrency,
+		PortTimeoutMs:       tuning.PortTimeout.Milliseconds(),
 	}
 	if saveErr := o.store.SaveScanMetrics(ctx, metrics); saveErr != nil {
 		o.logger.Error("failed to save scan metrics", zap.Error(saveErr))
@@ -321,6 +431,23 @@ func (o *ScanOrchestrator) resolveHostname(ip string) string {
 	return strings.TrimSuffix(names[0], ".")
 }
 
+// enrichHostnames fills in hostnames via reverse DNS for devices that
+// still lack one, including those found by other discovery sources.
+func (o *ScanOrchestrator) enrichHostnames(ctx context.Context) {
+	if o.reverseDNS == nil {
+		return
+	}
+	o.reverseDNS.EnrichMissing(ctx)
+}
+
+// enrichGeoIP tags devices with public IPs with GeoIP location fields.
+func (o *ScanOrchestrator) enrichGeoIP(ctx context.Context) {
+	if o.geoIP == nil {
+		return
+	}
+	enrichGeoIP(ctx, o.store, o.geoIP, o.logger.Named("geoip"))
+}
+
 // inferTopologyLinks creates topology edges between discovered devices and the
 // subnet gateway. The gateway is assumed to be the first usable IP in the CIDR.
 func (o *ScanOrchestrator) inferTopologyLinks(ctx context.Context, subnet string, hosts []HostResult) {
@@ -337,13 +464,19 @@ func (o *ScanOrchestrator) inferTopologyLinks(ctx context.Context, subnet string
 		return
 	}
 
+	// Devices with LLDP links already have their real attachment point.
+	lldpLinked, err := o.store.DeviceIDsWithLinkType(ctx, "lldp")
+	if err != nil {
+		o.logger.Warn("failed to load LLDP-linked devices", zap.Error(err))
+	}
+
 	var linkCount int
 	for _, host := range hosts {
 		if host.IP == gatewayIP {
 			continue
 		}
 		device, err := o.store.GetDeviceByIP(ctx, host.IP)
-		if err != nil || device == nil {
+		if err != nil || device == nil || lldpLinked[device.ID] {
 			continue
 		}
 		link := &TopologyLink{
@@ -419,9 +552,11 @@ func (o *ScanOrchestrator) detectAndPublishServiceMovements(ctx context.Context,
 }
 
 // portScanInfraDevices performs targeted port scanning on devices identified
-// as potential infrastructure by OUI classification.
-func (o *ScanOrchestrator) portScanInfraDevices(ctx context.Context, alive []HostResult, arpTable map[string]string) {
-	scanner := NewPortScanner(2*time.Second, 10, o.logger)
+// as potential infrastructure by OUI classification, or on every live host
+// when the plan asks for it, probing the plan's ports. The open ports found
+// are recorded per device, and port fingerprinting refines the device type.
+func (o *ScanOrchestrator) portScanInfraDevices(ctx context.Context, alive []HostResult, arpTable map[string]string, plan *scanPlan) {
+	scanner := NewPortScanner(plan.tuning.PortTimeout, plan.tuning.PortScanConcurrency, o.logger)
 
 	var scannedCount int
 	for _, host := range alive {
@@ -429,33 +564,40 @@ func (o *ScanOrchestrator) portScanInfraDevices(ctx context.Context, alive []Hos
 			return
 		}
 
-		// Only scan devices with infrastructure OUI classification.
+		// Unless the plan says otherwise, only scan devices with
+		// infrastructure OUI classification.
 		mac := arpTable[host.IP]
-		if mac == "" {
-			continue
-		}
 		manufacturer := ""
-		if o.oui != nil {
+		if o.oui != nil && mac != "" {
 			manufacturer = o.oui.Lookup(mac)
 		}
 		ouiType := ClassifyByManufacturer(manufacturer)
-		if !IsInfrastructureOUI(ouiType) {
+		if !plan.portScanAllHosts && (mac == "" || !IsInfrastructureOUI(ouiType)) {
 			continue
 		}
 
-		result := scanner.ScanPorts(ctx, host.IP, InfrastructurePorts)
-		if len(result.OpenPorts) == 0 {
-			continue
+		result := scanner.ScanPorts(ctx, host.IP, plan.ports)
+		if ctx.Err() != nil {
+			// A cancelled scan reports unfinished probes as closed.
+			return
 		}
 
-		portType := ClassifyByPorts(result.OpenPorts)
-		if portType == models.DeviceTypeUnknown {
+		device, err := o.store.GetDeviceByIP(ctx, host.IP)
+		if err != nil || device == nil {
+			continue
+		}
+		if saveErr := o.store.SaveDevicePortScan(ctx, device.ID, "tcp", plan.ports, result.OpenPorts, time.Now()); saveErr != nil {
+			o.logger.Error("failed to record open ports",
+				zap.String("device_id", device.ID),
+				zap.Error(saveErr))
+		}
+		if len(result.OpenPorts) == 0 {
 			continue
 		}
 
 		// Update device type if port fingerprinting gives a more specific result.
-		device, err := o.store.GetDeviceByIP(ctx, host.IP)
-		if err != nil || device == nil {
+		portType := ClassifyByPorts(result.OpenPorts)
+		if portType == models.DeviceTypeUnknown || isClassificationPinned(device) {
 			continue
 		}
 
@@ -487,7 +629,7 @@ func (o *ScanOrchestrator) classifyDevices(ctx context.Context, alive []HostResu
 		}
 
 		device, err := o.store.GetDeviceByIP(ctx, host.IP)
-		if err != nil || device == nil {
+		if err != nil || device == nil || isClassificationPinned(device) {
 			continue
 		}
 
@@ -717,6 +859,82 @@ func (o *ScanOrchestrator) walkSwitchFDBTables(ctx context.Context) {
 	}
 }
 
+// walkSwitchLLDPTables polls every classified switch for its LLDP neighbors
+// and replaces its LLDP topology links.
+func (o *ScanOrchestrator) walkSwitchLLDPTables(ctx context.Context) {
+	if o.lldpWalker == nil || o.credLookup == nil || o.credAccess == nil {
+		return
+	}
+
+	switches, _, err := o.store.ListDevices(ctx, ListDevicesOptions{DeviceType: "switch", Limit: 500})
+	if err != nil {
+		o.logger.Error("failed to list switches for LLDP walk", zap.Error(err))
+		return
+	}
+
+	var totalLinks int
+	for i := range switches {
+		if ctx.Err() != nil {
+			return
+		}
+
+		sw := &switches[i]
+		if sw.ClassificationConfidence < 50 || len(sw.IPAddresses) == 0 {
+			continue
+		}
+
+		credID, credErr := o.credLookup.FindSNMPCredentialForDevice(ctx, sw.ID)
+		if credErr != nil || credID == "" {
+			continue
+		}
+
+		_, created, pollErr := o.PollLLDP(ctx, sw, credID)
+		if pollErr != nil {
+			o.logger.Warn("LLDP walk failed for switch",
+				zap.String("device_id", sw.ID),
+				zap.String("ip", sw.IPAddresses[0]),
+				zap.Error(pollErr),
+			)
+			continue
+		}
+		totalLinks += created
+	}
+
+	if totalLinks > 0 {
+		o.logger.Info("LLDP topology links created",
+			zap.Int("total_links", totalLinks),
+		)
+	}
+}
+
+// PollLLDP walks the LLDP neighbor table of device using the given SNMP
+// credential and replaces the device's LLDP topology links with the result.
+// It returns the neighbors read and the number of links created.
+func (o *ScanOrchestrator) PollLLDP(ctx context.Context, device *models.Device, credID string) ([]LLDPNeighbor, int, error) {
+	if o.lldpWalker == nil {
+		return nil, 0, fmt.Errorf("LLDP walker not configured")
+	}
+	if len(device.IPAddresses) == 0 {
+		return nil, 0, fmt.Errorf("device %s has no IP addresses", device.ID)
+	}
+
+	neighbors, err := o.lldpWalker.WalkLLDP(ctx, device.IPAddresses[0], o.credAccess, credID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Drop links to neighbors that have since disappeared.
+	if err := o.store.RemoveLLDPLinksForDevice(ctx, device.ID); err != nil {
+		return neighbors, 0, err
+	}
+
+	created, err := NewLLDPCollector(o.logger).BuildTopologyFromLLDP(ctx, o.store, neighbors, device.ID)
+	if err != nil {
+		return neighbors, created, err
+	}
+	return neighbors, created, nil
+}
+
 // inferHierarchy runs network hierarchy inference after topology links are built.
 func (o *ScanOrchestrator) inferHierarchy(ctx context.Context) {
 	inferrer := NewHierarchyInferrer(o.store, o.logger)
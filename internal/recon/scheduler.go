@@ -11,10 +11,19 @@ import (
 	"go.uber.org/zap"
 )
 
+// ScanTargetSource resolves the subnets to scan when the schedule has no
+// explicit subnet, e.g. from the scan interfaces selected in settings.
+// Defined here (consumer-side interface) to avoid coupling recon -> settings.
+type ScanTargetSource interface {
+	ScanSubnets(ctx context.Context) ([]string, error)
+}
+
 // ScanScheduler runs recurring network scans on a configurable interval,
 // respecting quiet hours when no scans should be triggered.
 type ScanScheduler struct {
+	cfgMu        sync.RWMutex
 	cfg          ScheduleConfig
+	reloadCh     chan struct{}
 	orchestrator *ScanOrchestrator
 	store        *ReconStore
 	activeScans  *sync.Map
@@ -23,6 +32,9 @@ type ScanScheduler struct {
 	logger       *zap.Logger
 	nowFunc      func() time.Time
 
+	targetsMu sync.RWMutex
+	targets   ScanTargetSource
+
 	stopOnce sync.Once
 	stopCh   chan struct{}
 }
@@ -47,6 +59,7 @@ func NewScanScheduler(
 		newScanCtx:   newScanCtx,
 		logger:       logger,
 		nowFunc:      time.Now,
+		reloadCh:     make(chan struct{}, 1),
 		stopCh:       make(chan struct{}),
 	}
 }
@@ -54,14 +67,15 @@ func NewScanScheduler(
 // Run starts the ticker loop. It blocks until the context is cancelled
 // or Stop is called. The caller should run this in a goroutine.
 func (s *ScanScheduler) Run(ctx context.Context) {
-	ticker := time.NewTicker(s.cfg.Interval)
+	cfg := s.config()
+	ticker := time.NewTicker(cfg.Interval)
 	defer ticker.Stop()
 
 	s.logger.Info("scan scheduler started",
-		zap.Duration("interval", s.cfg.Interval),
-		zap.String("subnet", s.cfg.Subnet),
-		zap.String("quiet_start", s.cfg.QuietStart),
-		zap.String("quiet_end", s.cfg.QuietEnd),
+		zap.Duration("interval", cfg.Interval),
+		zap.String("subnet", cfg.Subnet),
+		zap.String("quiet_start", cfg.QuietStart),
+		zap.String("quiet_end", cfg.QuietEnd),
 	)
 
 	for {
@@ -72,12 +86,48 @@ func (s *ScanScheduler) Run(ctx context.Context) {
 		case <-s.stopCh:
 			s.logger.Info("scan scheduler stopped")
 			return
+		case <-s.reloadCh:
+			cfg := s.config()
+			ticker.Reset(cfg.Interval)
+			s.logger.Info("scan scheduler reconfigured",
+				zap.Duration("interval", cfg.Interval),
+				zap.String("subnet", cfg.Subnet),
+				zap.String("quiet_start", cfg.QuietStart),
+				zap.String("quiet_end", cfg.QuietEnd),
+			)
 		case <-ticker.C:
 			s.tick()
 		}
 	}
 }
 
+// SetTargetSource sets the source consulted for subnets when the schedule has
+// no explicit subnet configured. Safe to call while the scheduler is running.
+func (s *ScanScheduler) SetTargetSource(src ScanTargetSource) {
+	s.targetsMu.Lock()
+	s.targets = src
+	s.targetsMu.Unlock()
+}
+
+// SetConfig replaces the schedule configuration. The interval restarts from
+// the time of the call. Safe to call while the scheduler is running; the
+// Enabled field is ignored.
+func (s *ScanScheduler) SetConfig(cfg ScheduleConfig) {
+	s.cfgMu.Lock()
+	s.cfg = cfg
+	s.cfgMu.Unlock()
+	select {
+	case s.reloadCh <- struct{}{}:
+	default:
+	}
+}
+
+func (s *ScanScheduler) config() ScheduleConfig {
+	s.cfgMu.RLock()
+	defer s.cfgMu.RUnlock()
+	return s.cfg
+}
+
 // Stop signals the scheduler to exit its run loop.
 func (s *ScanScheduler) Stop() {
 	s.stopOnce.Do(func() {
@@ -89,11 +139,12 @@ func (s *ScanScheduler) Stop() {
 // before triggering a new scan.
 func (s *ScanScheduler) tick() {
 	now := s.nowFunc()
+	cfg := s.config()
 
-	if isQuietHours(now, s.cfg.QuietStart, s.cfg.QuietEnd) {
+	if isQuietHours(now, cfg.QuietStart, cfg.QuietEnd) {
 		s.logger.Debug("scheduled scan skipped: quiet hours",
-			zap.String("quiet_start", s.cfg.QuietStart),
-			zap.String("quiet_end", s.cfg.QuietEnd),
+			zap.String("quiet_start", cfg.QuietStart),
+			zap.String("quiet_end", cfg.QuietEnd),
 		)
 		return
 	}
@@ -116,51 +167,99 @@ func (s *ScanScheduler) hasActiveScan() bool {
 	return active
 }
 
-// triggerScan starts a new scheduled scan, mirroring the pattern from handleScan.
-func (s *ScanScheduler) triggerScan() {
-	subnet := s.cfg.Subnet
-
-	// Validate CIDR before starting.
-	if _, _, err := net.ParseCIDR(subnet); err != nil {
-		s.logger.Error("scheduled scan: invalid subnet",
-			zap.String("subnet", subnet),
-			zap.Error(err),
-		)
-		return
+// subnets returns the subnets to scan on this tick: the configured schedule
+// subnet if set, otherwise the subnets reported by the target source.
+func (s *ScanScheduler) subnets(ctx context.Context) []string {
+	if subnet := s.config().Subnet; subnet != "" {
+		return []string{subnet}
 	}
 
-	scanID := fmt.Sprintf("scheduled-%d", s.nowFunc().UnixMilli())
+	s.targetsMu.RLock()
+	src := s.targets
+	s.targetsMu.RUnlock()
+	if src == nil {
+		return nil
+	}
 
-	scan := &models.ScanResult{
-		ID:     scanID,
-		Subnet: subnet,
-		Status: "running",
+	subnets, err := src.ScanSubnets(ctx)
+	if err != nil {
+		s.logger.Error("scheduled scan: failed to resolve scan subnets", zap.Error(err))
+		return nil
 	}
+	return subnets
+}
 
+// scheduledScan pairs a scan record with its cancellable context.
+type scheduledScan struct {
+	id     string
+	subnet string
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// triggerScan starts a new scheduled scan for each target subnet, mirroring
+// the pattern from handleScan. Multiple subnets are scanned one after another
+// in a single goroutine so that interfaces do not compete for bandwidth.
+func (s *ScanScheduler) triggerScan() {
 	ctx := context.Background()
-	if err := s.store.CreateScan(ctx, scan); err != nil {
-		s.logger.Error("scheduled scan: failed to create scan record",
-			zap.Error(err),
-		)
+	subnets := s.subnets(ctx)
+	if len(subnets) == 0 {
+		s.logger.Debug("scheduled scan skipped: no subnet configured")
 		return
 	}
 
-	scanCtx, cancel := s.newScanCtx()
-	s.activeScans.Store(scanID, cancel)
-	s.wg.Add(1)
+	stamp := s.nowFunc().UnixMilli()
+	scans := make([]scheduledScan, 0, len(subnets))
+	for i, subnet := range subnets {
+		// Validate CIDR before starting.
+		if _, _, err := net.ParseCIDR(subnet); err != nil {
+			s.logger.Error("scheduled scan: invalid subnet",
+				zap.String("subnet", subnet),
+				zap.Error(err),
+			)
+			continue
+		}
 
-	s.logger.Info("scheduled scan started",
-		zap.String("scan_id", scanID),
-		zap.String("subnet", subnet),
-	)
+		scanID := fmt.Sprintf("scheduled-%d", stamp)
+		if len(subnets) > 1 {
+			scanID = fmt.Sprintf("scheduled-%d-%d", stamp, i)
+		}
 
+		scan := &models.ScanResult{
+			ID:     scanID,
+			Subnet: subnet,
+			Status: "running",
+		}
+		if err := s.store.CreateScan(ctx, scan); err != nil {
+			s.logger.Error("scheduled scan: failed to create scan record",
+				zap.String("subnet", subnet),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		scanCtx, cancel := s.newScanCtx()
+		s.activeScans.Store(scanID, cancel)
+		scans = append(scans, scheduledScan{id: scanID, subnet: subnet, ctx: scanCtx, cancel: cancel})
+	}
+	if len(scans) == 0 {
+		return
+	}
+
+	s.wg.Add(1)
 	go func() {
 		defer s.wg.Done()
-		defer s.activeScans.Delete(scanID)
-		s.orchestrator.RunScan(scanCtx, scanID, subnet)
-		s.logger.Info("scheduled scan completed",
-			zap.String("scan_id", scanID),
-		)
+		for _, sc := range scans {
+			s.logger.Info("scheduled scan started",
+				zap.String("scan_id", sc.id),
+				zap.String("subnet", sc.subnet),
+			)
+			s.orchestrator.RunScan(sc.ctx, sc.id, sc.subnet)
+			s.activeScans.Delete(sc.id)
+			s.logger.Info("scheduled scan completed",
+				zap.String("scan_id", sc.id),
+			)
+		}
 	}()
 }
 
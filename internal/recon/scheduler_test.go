@@ -379,3 +379,90 @@ func TestScheduler_SkipsWhenScanAlreadyActive(t *testing.T) {
 		t.Errorf("expected 0 scans when scan already active, got %d", len(scans))
 	}
 }
+
+// staticTargets is a ScanTargetSource returning a fixed subnet list.
+type staticTargets []string
+
+func (s staticTargets) ScanSubnets(_ context.Context) ([]string, error) {
+	return s, nil
+}
+
+func TestScheduler_TriggersScanPerTargetSubnet(t *testing.T) {
+	cfg := ScheduleConfig{
+		Enabled:  true,
+		Interval: time.Hour,
+	}
+	sched, s := setupTestScheduler(t, cfg)
+	sched.SetTargetSource(staticTargets{"10.0.1.0/24", "10.0.2.0/24", "not-a-cidr"})
+
+	sched.triggerScan()
+	sched.wg.Wait()
+
+	scans, err := s.ListScans(context.Background(), 100, 0)
+	if err != nil {
+		t.Fatalf("ListScans: %v", err)
+	}
+	if len(scans) != 2 {
+		t.Fatalf("len(scans) = %d, want 2 (invalid subnet skipped)", len(scans))
+	}
+	got := map[string]bool{}
+	for _, scan := range scans {
+		got[scan.Subnet] = true
+	}
+	if !got["10.0.1.0/24"] || !got["10.0.2.0/24"] {
+		t.Errorf("scanned subnets = %v, want both target subnets", got)
+	}
+}
+
+func TestScheduler_NoSubnetNoTargets(t *testing.T) {
+	cfg := ScheduleConfig{
+		Enabled:  true,
+		Interval: time.Hour,
+	}
+	sched, s := setupTestScheduler(t, cfg)
+
+	sched.triggerScan()
+	sched.wg.Wait()
+
+	scans, err := s.ListScans(context.Background(), 100, 0)
+	if err != nil {
+		t.Fatalf("ListScans: %v", err)
+	}
+	if len(scans) != 0 {
+		t.Errorf("len(scans) = %d, want 0", len(scans))
+	}
+}
+
+func TestScheduler_SetConfigResetsInterval(t *testing.T) {
+	cfg := ScheduleConfig{
+		Enabled:  true,
+		Interval: time.Hour,
+		Subnet:   "10.0.0.0/24",
+	}
+	sched, s := setupTestScheduler(t, cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		sched.Run(ctx)
+		close(done)
+	}()
+
+	// Shorten the interval while running; the hour-long ticker must not
+	// hold back the next scan.
+	cfg.Interval = 50 * time.Millisecond
+	sched.SetConfig(cfg)
+
+	time.Sleep(200 * time.Millisecond)
+	cancel()
+	<-done
+
+	scans, err := s.ListScans(context.Background(), 100, 0)
+	if err != nil {
+		t.Fatalf("ListScans: %v", err)
+	}
+	if len(scans) == 0 {
+		t.Error("expected a scheduled scan after shortening the interval, got 0")
+	}
+}
@@ -109,27 +109,8 @@ func (c *SNMPCollector) newGoSNMP(target string, cred *SNMPCredential) (*gosnmp.
 	case "snmp_v3":
 		g.Version = gosnmp.Version3
 		g.SecurityModel = gosnmp.UserSecurityModel
-
-		// Map security level to MsgFlags.
-		switch cred.SecurityLevel {
-		case "noAuthNoPriv":
-			g.MsgFlags = gosnmp.NoAuthNoPriv
-		case "authNoPriv":
-			g.MsgFlags = gosnmp.AuthNoPriv
-		case "authPriv":
-			g.MsgFlags = gosnmp.AuthPriv
-		default:
-			g.MsgFlags = gosnmp.AuthPriv
-		}
-
-		g.SecurityParameters = &gosnmp.UsmSecurityParameters{
-			UserName:                 cred.Username,
-			AuthenticationProtocol:   mapAuthProtocol(cred.AuthProtocol),
-			AuthenticationPassphrase: cred.AuthPassphrase,
-			PrivacyProtocol:          mapPrivProtocol(cred.PrivacyProtocol),
-			PrivacyPassphrase:        cred.PrivacyPassphrase,
-			AuthoritativeEngineID:    cred.AuthoritativeEngineID,
-		}
+		g.MsgFlags = mapSecurityLevel(cred.SecurityLevel)
+		g.SecurityParameters = usmSecurityParameters(cred)
 
 		if cred.ContextName != "" {
 			g.ContextName = cred.ContextName
@@ -142,6 +123,31 @@ func (c *SNMPCollector) newGoSNMP(target string, cred *SNMPCredential) (*gosnmp.
 	return g, nil
 }
 
+// mapSecurityLevel converts an SNMPv3 security level to gosnmp message flags,
+// defaulting to authPriv.
+func mapSecurityLevel(level string) gosnmp.SnmpV3MsgFlags {
+	switch level {
+	case "noAuthNoPriv":
+		return gosnmp.NoAuthNoPriv
+	case "authNoPriv":
+		return gosnmp.AuthNoPriv
+	default:
+		return gosnmp.AuthPriv
+	}
+}
+
+// usmSecurityParameters builds SNMPv3 USM parameters from a credential.
+func usmSecurityParameters(cred *SNMPCredential) *gosnmp.UsmSecurityParameters {
+	return &gosnmp.UsmSecurityParameters{
+		UserName:                 cred.Username,
+		AuthenticationProtocol:   mapAuthProtocol(cred.AuthProtocol),
+		AuthenticationPassphrase: cred.AuthPassphrase,
+		PrivacyProtocol:          mapPrivProtocol(cred.PrivacyProtocol),
+		PrivacyPassphrase:        cred.PrivacyPassphrase,
+		AuthoritativeEngineID:    cred.AuthoritativeEngineID,
+	}
+}
+
 // mapAuthProtocol converts an auth protocol string to the gosnmp constant.
 func mapAuthProtocol(s string) gosnmp.SnmpV3AuthProtocol {
 	switch strings.ToUpper(s) {
@@ -46,3 +46,12 @@ const (
 
 // IF-MIB extensions (1.3.6.1.2.1.31.1.1.1).
 const OIDIfName = "1.3.6.1.2.1.31.1.1.1.1" // ifName (short name like "Gi0/1")
+
+// IF-MIB octet counters. The ifXTable HC columns are 64-bit; the ifTable
+// columns are 32-bit and wrap within a minute on a busy gigabit link.
+const (
+	OIDIfInOctets    = "1.3.6.1.2.1.2.2.1.10"
+	OIDIfOutOctets   = "1.3.6.1.2.1.2.2.1.16"
+	OIDIfHCInOctets  = "1.3.6.1.2.1.31.1.1.1.6"
+	OIDIfHCOutOctets = "1.3.6.1.2.1.31.1.1.1.10"
+)
@@ -0,0 +1,334 @@
+package recon
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gosnmp/gosnmp"
+	"go.uber.org/zap"
+
+	"github.com/HerbHall/subnetree/pkg/plugin"
+)
+
+const (
+	// trapMaxPacket is the largest SNMP message accepted (RFC 3417 minimum is 484).
+	trapMaxPacket = 65535
+
+	oidSysUpTime   = "1.3.6.1.2.1.1.3.0"
+	oidSnmpTrapOID = "1.3.6.1.6.3.1.1.4.1.0"
+	oidStdTraps    = "1.3.6.1.6.3.1.1.5."
+)
+
+// standardTraps names the SNMPv2-MIB generic traps, keyed by the last OID arc.
+var standardTraps = map[string]string{
+	"1": "coldStart",
+	"2": "warmStart",
+	"3": "linkDown",
+	"4": "linkUp",
+	"5": "authenticationFailure",
+	"6": "egpNeighborLoss",
+}
+
+// trapLevel maps a trap OID to an event level. Restarts, link loss, and
+// authentication failures are warnings; everything else is informational.
+func trapLevel(trapOID string) string {
+	switch strings.TrimPrefix(trapOID, oidStdTraps) {
+	case "1", "2", "3", "5", "6":
+		return EventLevelWarning
+	default:
+		return EventLevelInfo
+	}
+}
+
+// trapName returns the well-known name of a trap OID, or the OID itself.
+func trapName(trapOID string) string {
+	if rest, ok := strings.CutPrefix(trapOID, oidStdTraps); ok {
+		if name, ok := standardTraps[rest]; ok {
+			return name
+		}
+	}
+	return trapOID
+}
+
+// TrapReceiver listens for SNMP traps and informs, matches each sender to a
+// device by IP, and stores the trap OID and varbinds as a device event.
+//
+// v1 and v2c traps are accepted from any known device; when the device has
+// an snmp_v2c credential in the vault, the trap community must match it.
+// v3 traps are authenticated and decrypted with the device's snmp_v3
+// credential, which must carry the device's authoritative engine ID.
+type TrapReceiver struct {
+	store     *ReconStore
+	bus       plugin.EventBus
+	logger    *zap.Logger
+	addr      string
+	retention time.Duration
+	devices   *deviceIPCache
+
+	conn net.PacketConn
+
+	mu         sync.RWMutex
+	credLookup CredentialLookup
+	creds      CredentialAccessor
+}
+
+// NewTrapReceiver creates an SNMP trap receiver for cfg.ListenAddr.
+func NewTrapReceiver(store *ReconStore, bus plugin.EventBus, logger *zap.Logger, cfg SNMPTrapConfig) *TrapReceiver {
+	return &TrapReceiver{
+		store:     store,
+		bus:       bus,
+		logger:    logger,
+		addr:      cfg.ListenAddr,
+		retention: cfg.Retention,
+		devices:   newDeviceIPCache(store, logger),
+	}
+}
+
+// SetCredentials configures how the receiver finds a device's SNMP
+// credentials for community checks and v3 authentication.
+func (t *TrapReceiver) SetCredentials(lookup CredentialLookup, creds CredentialAccessor) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.credLookup = lookup
+	t.creds = creds
+}
+
+// Listen binds the UDP socket.
+func (t *TrapReceiver) Listen() error {
+	conn, err := net.ListenPacket("udp", t.addr)
+	if err != nil {
+		return fmt.Errorf("listen snmp trap udp %s: %w", t.addr, err)
+	}
+	t.conn = conn
+	return nil
+}
+
+// Run receives traps until ctx is cancelled. Listen must be called first.
+func (t *TrapReceiver) Run(ctx context.Context) {
+	t.logger.Info("SNMP trap receiver started", zap.String("addr", t.conn.LocalAddr().String()))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		runDeviceEventPruner(ctx, t.store, t.logger, "snmp_trap", t.retention)
+	}()
+
+	stop := context.AfterFunc(ctx, func() { t.conn.Close() })
+	defer stop()
+
+	buf := make([]byte, trapMaxPacket)
+	for {
+		n, addr, err := t.conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() == nil {
+				t.logger.Warn("snmp trap read failed", zap.Error(err))
+			}
+			break
+		}
+		t.handle(ctx, buf[:n], addr)
+	}
+	wg.Wait()
+	t.logger.Info("SNMP trap receiver stopped")
+}
+
+// handle decodes a trap, matches its sender to a device, and stores it.
+// Traps from unknown senders or with bad credentials are dropped.
+func (t *TrapReceiver) handle(ctx context.Context, raw []byte, addr net.Addr) {
+	ip := hostIP(addr)
+	deviceID := t.devices.lookup(ctx, ip)
+	if deviceID == "" {
+		t.logger.Debug("ignoring SNMP trap from unknown device", zap.String("ip", ip))
+		return
+	}
+
+	version, err := snmpMessageVersion(raw)
+	if err != nil {
+		t.logger.Debug("ignoring malformed SNMP trap", zap.String("ip", ip), zap.Error(err))
+		return
+	}
+	cred := t.deviceCredential(ctx, deviceID)
+
+	g := &gosnmp.GoSNMP{Version: version}
+	if version == gosnmp.Version3 {
+		if cred == nil || cred.Type != "snmp_v3" {
+			t.logger.Debug("ignoring SNMPv3 trap: device has no snmp_v3 credential", zap.String("ip", ip))
+			return
+		}
+		g.SecurityModel = gosnmp.UserSecurityModel
+		g.MsgFlags = mapSecurityLevel(cred.SecurityLevel)
+		g.SecurityParameters = usmSecurityParameters(cred)
+	}
+
+	packet, err := g.UnmarshalTrap(raw, false)
+	if err != nil {
+		t.logger.Debug("failed to decode SNMP trap", zap.String("ip", ip), zap.Error(err))
+		return
+	}
+	switch packet.PDUType {
+	case gosnmp.Trap, gosnmp.SNMPv2Trap, gosnmp.InformRequest:
+	default:
+		return
+	}
+	if version != gosnmp.Version3 && cred != nil && cred.Type == "snmp_v2c" && packet.Community != cred.Community {
+		t.logger.Warn("ignoring SNMP trap with wrong community", zap.String("ip", ip))
+		return
+	}
+
+	now := time.Now().UTC()
+	ev := trapEvent(packet)
+	ev.DeviceID = deviceID
+	ev.SourceIP = ip
+	ev.Timestamp = now
+	ev.ReceivedAt = now
+	if err := t.store.InsertDeviceLogEvent(ctx, ev); err != nil {
+		t.logger.Warn("failed to store SNMP trap", zap.String("device_id", deviceID), zap.Error(err))
+		return
+	}
+
+	if t.bus != nil {
+		t.bus.PublishAsync(ctx, plugin.Event{
+			Topic:     TopicSNMPTrapReceived,
+			Source:    "recon",
+			Timestamp: now,
+			Payload:   ev,
+		})
+	}
+
+	if packet.PDUType == gosnmp.InformRequest {
+		t.acknowledge(packet, addr)
+	}
+}
+
+// acknowledge answers an inform with a response carrying the same varbinds.
+func (t *TrapReceiver) acknowledge(packet *gosnmp.SnmpPacket, addr net.Addr) {
+	packet.PDUType = gosnmp.GetResponse
+	packet.Error = gosnmp.NoError
+	packet.ErrorIndex = 0
+	out, err := packet.MarshalMsg()
+	if err != nil {
+		t.logger.Debug("failed to encode inform response", zap.Error(err))
+		return
+	}
+	if _, err := t.conn.WriteTo(out, addr); err != nil {
+		t.logger.Debug("failed to send inform response", zap.Error(err))
+	}
+}
+
+// deviceCredential returns the device's SNMP credential, or nil if it has none.
+func (t *TrapReceiver) deviceCredential(ctx context.Context, deviceID string) *SNMPCredential {
+	t.mu.RLock()
+	lookup, creds := t.credLookup, t.creds
+	t.mu.RUnlock()
+	if lookup == nil || creds == nil {
+		return nil
+	}
+
+	credID, err := lookup.FindSNMPCredentialForDevice(ctx, deviceID)
+	if err != nil || credID == "" {
+		return nil
+	}
+	cred, err := creds.GetCredential(ctx, credID)
+	if err != nil {
+		t.logger.Warn("failed to load SNMP credential for trap",
+			zap.String("device_id", deviceID), zap.Error(err))
+		return nil
+	}
+	return cred
+}
+
+// trapEvent converts a decoded trap into a device event. The trap OID is
+// derived from the v1 header per RFC 3584 or from snmpTrapOID.0 for v2c/v3.
+func trapEvent(packet *gosnmp.SnmpPacket) *DeviceLogEvent {
+	var trapOID string
+	var varbinds []TrapVarbind
+
+	if packet.PDUType == gosnmp.Trap {
+		enterprise := strings.TrimPrefix(packet.Enterprise, ".")
+		if packet.GenericTrap >= 0 && packet.GenericTrap < 6 {
+			trapOID = oidStdTraps + strconv.Itoa(packet.GenericTrap+1)
+		} else {
+			trapOID = enterprise + ".0." + strconv.Itoa(packet.SpecificTrap)
+		}
+	}
+	for _, pdu := range packet.Variables {
+		oid := strings.TrimPrefix(pdu.Name, ".")
+		switch oid {
+		case oidSysUpTime:
+			continue
+		case oidSnmpTrapOID:
+			if s, ok := pdu.Value.(string); ok {
+				trapOID = strings.TrimPrefix(s, ".")
+			}
+			continue
+		}
+		varbinds = append(varbinds, TrapVarbind{
+			OID:   oid,
+			Type:  pdu.Type.String(),
+			Value: varbindValue(pdu),
+		})
+	}
+
+	level := trapLevel(trapOID)
+	severity := 6
+	if level == EventLevelWarning {
+		severity = 4
+	}
+	return &DeviceLogEvent{
+		Source:       "snmp_trap",
+		Severity:     severity,
+		SeverityName: syslogSeverityNames[severity],
+		Level:        level,
+		AppName:      "snmp",
+		Message:      trapName(trapOID),
+		TrapOID:      trapOID,
+		Varbinds:     varbinds,
+	}
+}
+
+// varbindValue renders a varbind value as text. Non-printable octet strings
+// (such as MAC addresses) are hex-encoded.
+func varbindValue(pdu gosnmp.SnmpPDU) string {
+	switch v := pdu.Value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		if utf8.Valid(v) && !strings.ContainsFunc(string(v), func(r rune) bool { return r < 0x20 && r != '\t' }) {
+			return string(v)
+		}
+		return hex.EncodeToString(v)
+	case string:
+		return strings.TrimPrefix(v, ".")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// snmpMessageVersion reads the version field from the start of a BER-encoded
+// SNMP message: SEQUENCE { INTEGER version, ... }.
+func snmpMessageVersion(b []byte) (gosnmp.SnmpVersion, error) {
+	if len(b) < 2 || b[0] != 0x30 {
+		return 0, errors.New("not an SNMP message")
+	}
+	i := 2
+	if b[1]&0x80 != 0 {
+		i += int(b[1] & 0x7f)
+	}
+	if len(b) < i+3 || b[i] != 0x02 || b[i+1] != 0x01 {
+		return 0, errors.New("missing SNMP version")
+	}
+	switch v := gosnmp.SnmpVersion(b[i+2]); v {
+	case gosnmp.Version1, gosnmp.Version2c, gosnmp.Version3:
+		return v, nil
+	default:
+		return 0, fmt.Errorf("unsupported SNMP version %d", v)
+	}
+}
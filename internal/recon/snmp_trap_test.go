@@ -0,0 +1,212 @@
+package recon
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"go.uber.org/zap"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+type fakeTrapCreds struct {
+	cred *SNMPCredential
+}
+
+func (f *fakeTrapCreds) FindSNMPCredentialForDevice(_ context.Context, _ string) (string, error) {
+	if f.cred == nil {
+		return "", nil
+	}
+	return "cred-1", nil
+}
+
+func (f *fakeTrapCreds) GetCredential(_ context.Context, id string) (*SNMPCredential, error) {
+	if id != "cred-1" {
+		return nil, errors.New("not found")
+	}
+	return f.cred, nil
+}
+
+func TestSNMPMessageVersion(t *testing.T) {
+	tests := []struct {
+		raw  []byte
+		want gosnmp.SnmpVersion
+		ok   bool
+	}{
+		{[]byte{0x30, 0x10, 0x02, 0x01, 0x00}, gosnmp.Version1, true},
+		{[]byte{0x30, 0x81, 0x90, 0x02, 0x01, 0x01}, gosnmp.Version2c, true},
+		{[]byte{0x30, 0x82, 0x01, 0x00, 0x02, 0x01, 0x03}, gosnmp.Version3, true},
+		{[]byte{0x30, 0x10, 0x02, 0x01, 0x02}, 0, false},
+		{[]byte{0x04, 0x10}, 0, false},
+		{nil, 0, false},
+	}
+	for _, tc := range tests {
+		got, err := snmpMessageVersion(tc.raw)
+		if (err == nil) != tc.ok || got != tc.want {
+			t.Errorf("snmpMessageVersion(% x) = %v, %v; want %v, ok=%v", tc.raw, got, err, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestTrapEvent_V1(t *testing.T) {
+	packet := &gosnmp.SnmpPacket{
+		PDUType: gosnmp.Trap,
+		Variables: []gosnmp.SnmpPDU{
+			{Name: ".1.3.6.1.2.1.2.2.1.1.3", Type: gosnmp.Integer, Value: 3},
+		},
+		SnmpTrap: gosnmp.SnmpTrap{
+			Enterprise:  ".1.3.6.1.4.1.9",
+			GenericTrap: 2,
+		},
+	}
+	ev := trapEvent(packet)
+	if ev.TrapOID != "1.3.6.1.6.3.1.1.5.3" || ev.Message != "linkDown" || ev.Level != EventLevelWarning {
+		t.Errorf("event = %+v, want linkDown warning", ev)
+	}
+	if len(ev.Varbinds) != 1 || ev.Varbinds[0].OID != "1.3.6.1.2.1.2.2.1.1.3" || ev.Varbinds[0].Value != "3" {
+		t.Errorf("varbinds = %+v", ev.Varbinds)
+	}
+
+	packet.GenericTrap = 6
+	packet.SpecificTrap = 42
+	if ev := trapEvent(packet); ev.TrapOID != "1.3.6.1.4.1.9.0.42" || ev.Level != EventLevelInfo {
+		t.Errorf("enterprise-specific event = %+v", ev)
+	}
+}
+
+func TestTrapEvent_V2(t *testing.T) {
+	packet := &gosnmp.SnmpPacket{
+		PDUType: gosnmp.SNMPv2Trap,
+		Variables: []gosnmp.SnmpPDU{
+			{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(100)},
+			{Name: ".1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.4"},
+			{Name: ".1.3.6.1.2.1.2.2.1.2.3", Type: gosnmp.OctetString, Value: []byte("eth0")},
+			{Name: ".1.3.6.1.2.1.2.2.1.6.3", Type: gosnmp.OctetString, Value: []byte{0x00, 0x1a, 0x2b}},
+		},
+	}
+	ev := trapEvent(packet)
+	if ev.TrapOID != "1.3.6.1.6.3.1.1.5.4" || ev.Message != "linkUp" || ev.Level != EventLevelInfo {
+		t.Errorf("event = %+v, want linkUp info", ev)
+	}
+	if len(ev.Varbinds) != 2 || ev.Varbinds[0].Value != "eth0" || ev.Varbinds[1].Value != "001a2b" {
+		t.Errorf("varbinds = %+v", ev.Varbinds)
+	}
+}
+
+// startTrapReceiver runs a receiver on a loopback port for a device at 127.0.0.1.
+func startTrapReceiver(t *testing.T, creds *fakeTrapCreds) (*TrapReceiver, *ReconStore, *mockEventBus, string) {
+	t.Helper()
+	s := testStore(t)
+	bus := &mockEventBus{}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	d := &models.Device{IPAddresses: []string{"127.0.0.1"}, DiscoveryMethod: models.DiscoveryICMP}
+	if _, err := s.UpsertDevice(ctx, d); err != nil {
+		t.Fatalf("UpsertDevice: %v", err)
+	}
+
+	r := NewTrapReceiver(s, bus, zap.NewNop(), SNMPTrapConfig{ListenAddr: "127.0.0.1:0"})
+	if creds != nil {
+		r.SetCredentials(creds, creds)
+	}
+	if err := r.Listen(); err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		r.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return r, s, bus, d.ID
+}
+
+func sendTestTrap(t *testing.T, r *TrapReceiver, community string, inform bool) error {
+	t.Helper()
+	_, portStr, _ := net.SplitHostPort(r.conn.LocalAddr().String())
+	port, _ := strconv.Atoi(portStr)
+	g := &gosnmp.GoSNMP{
+		Target:    "127.0.0.1",
+		Port:      uint16(port),
+		Version:   gosnmp.Version2c,
+		Community: community,
+		Timeout:   time.Second,
+	}
+	if err := g.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer g.Conn.Close()
+	_, err := g.SendTrap(gosnmp.SnmpTrap{
+		IsInform: inform,
+		Variables: []gosnmp.SnmpPDU{
+			{Name: ".1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.3"},
+			{Name: ".1.3.6.1.2.1.2.2.1.1.7", Type: gosnmp.Integer, Value: 7},
+		},
+	})
+	return err
+}
+
+func waitForEvents(t *testing.T, s *ReconStore, deviceID string, want int) []DeviceLogEvent {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		events, err := s.ListDeviceLogEvents(context.Background(), deviceID, DeviceLogEventFilter{Source: "snmp_trap"})
+		if err != nil {
+			t.Fatalf("ListDeviceLogEvents: %v", err)
+		}
+		if len(events) >= want || time.Now().After(deadline) {
+			return events
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestTrapReceiver_V2cInform(t *testing.T) {
+	r, s, bus, deviceID := startTrapReceiver(t, nil)
+
+	// An inform blocks until the receiver acknowledges it.
+	if err := sendTestTrap(t, r, "public", true); err != nil {
+		t.Fatalf("SendTrap inform: %v", err)
+	}
+
+	events := waitForEvents(t, s, deviceID, 1)
+	if len(events) != 1 {
+		t.Fatalf("events = %d, want 1", len(events))
+	}
+	ev := events[0]
+	if ev.TrapOID != "1.3.6.1.6.3.1.1.5.3" || ev.Message != "linkDown" || len(ev.Varbinds) != 1 {
+		t.Errorf("event = %+v", ev)
+	}
+	published := bus.Events()
+	if len(published) != 1 || published[0].Topic != TopicSNMPTrapReceived {
+		t.Errorf("published = %+v, want one %s event", published, TopicSNMPTrapReceived)
+	}
+}
+
+func TestTrapReceiver_CommunityMismatch(t *testing.T) {
+	creds := &fakeTrapCreds{cred: &SNMPCredential{Type: "snmp_v2c", Community: "s3cret"}}
+	r, s, _, deviceID := startTrapReceiver(t, creds)
+
+	if err := sendTestTrap(t, r, "public", false); err != nil {
+		t.Fatalf("SendTrap: %v", err)
+	}
+	if err := sendTestTrap(t, r, "s3cret", false); err != nil {
+		t.Fatalf("SendTrap: %v", err)
+	}
+
+	events := waitForEvents(t, s, deviceID, 1)
+	// Give a wrongly accepted first trap time to land before counting.
+	time.Sleep(50 * time.Millisecond)
+	events = waitForEvents(t, s, deviceID, len(events))
+	if len(events) != 1 {
+		t.Errorf("events = %d, want only the trap with the matching community", len(events))
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -53,6 +55,20 @@ type ListDevicesOptions struct {
 	ScanID     string
 	Category   string
 	Owner      string
+	Location   string
+	Search     string // Whitespace-separated terms; each must match a text field
+	OpenPort   int    // Only devices with this TCP port recorded open
+
+	CustomFilters []CustomFieldFilter // All must match
+	CustomSort    *CustomFieldSort    // Replaces the default last_seen order
+}
+
+// likeEscaper escapes LIKE wildcards in user-supplied search terms.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// deviceSearchColumns are the text columns matched by ListDevicesOptions.Search.
+var deviceSearchColumns = []string{
+	"hostname", "manufacturer", "location", "notes", "os", "tags", "ip_addresses", "mac_address",
 }
 
 // UpdateDeviceParams holds partial update fields for a device.
@@ -69,15 +85,31 @@ type UpdateDeviceParams struct {
 }
 
 // InventorySummary provides aggregate statistics about the device inventory.
+// TotalDevices = OnlineCount + OfflineCount + OtherCount. StaleCount is not
+// a separate status: it is the subset of OnlineCount not seen within the
+// requested number of days. The device lost checker marks such devices
+// offline once device_lost_after passes, so StaleCount is only non-zero when
+// stale_days is shorter than device_lost_after or between checks.
 type InventorySummary struct {
 	TotalDevices int            `json:"total_devices"`
 	OnlineCount  int            `json:"online_count"`
 	OfflineCount int            `json:"offline_count"`
-	StaleCount   int            `json:"stale_count"`
+	OtherCount   int            `json:"other_count"` // Degraded or unknown status
+	StaleCount   int            `json:"stale_count"` // Subset of OnlineCount
 	ByCategory   map[string]int `json:"by_category"`
 	ByType       map[string]int `json:"by_type"`
 }
 
+// ErrDeviceRestoreConflict is returned when restoring a device whose MAC
+// address now belongs to another live device.
+var ErrDeviceRestoreConflict = errors.New("another device with the same MAC address exists")
+
+// DeletedDevice is a device in the trash.
+type DeletedDevice struct {
+	models.Device
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
 // DeviceStatusChange records a status transition for a device.
 type DeviceStatusChange struct {
 	ID        string    `json:"id"`
@@ -117,6 +149,8 @@ type ScanSummary struct {
 
 // UpsertDevice inserts a new device or updates an existing one.
 // If the device has a MAC address, it matches by MAC; otherwise by IP.
+// The first matching classification rule sets the device type unless the
+// device was classified manually.
 // Returns the final device record and whether it was newly created.
 func (s *ReconStore) UpsertDevice(ctx context.Context, device *models.Device) (created bool, err error) {
 	now := time.Now().UTC()
@@ -153,9 +187,12 @@ func (s *ReconStore) UpsertDevice(ctx context.Context, device *models.Device) (c
 		if device.Manufacturer != "" {
 			manufacturer = device.Manufacturer
 		}
+		// Discovered names never replace a hostname set by hand.
 		hostname := existing.Hostname
 		if device.Hostname != "" {
-			hostname = device.Hostname
+			if src, _ := s.GetHostnameSource(ctx, existing.ID); src != HostnameSourceManual {
+				hostname = device.Hostname
+			}
 		}
 		osField := existing.OS
 		if device.OS != "" {
@@ -217,6 +254,18 @@ func (s *ReconStore) UpsertDevice(ctx context.Context, device *models.Device) (c
 			classSignals = existing.ClassificationSignals
 		}
 
+		// User-defined rules outrank the built-in classifiers but never a
+		// manual classification.
+		if existing.ClassificationSource != "manual" {
+			merged := models.Device{Hostname: hostname, MACAddress: mac, Manufacturer: manufacturer, OS: osField}
+			if rule := s.matchClassificationRule(ctx, &merged, existing.ID); rule != nil {
+				deviceType = string(rule.DeviceType)
+				classConfidence = rule.Confidence
+				classSource = ClassificationSourceRule
+				classSignals = rule.signalsJSON()
+			}
+		}
+
 		// Merge connection type: prefer non-empty/non-unknown incoming value.
 		connType := existing.ConnectionType
 		if device.ConnectionType != "" && device.ConnectionType != models.ConnectionUnknown {
@@ -256,6 +305,14 @@ func (s *ReconStore) UpsertDevice(ctx context.Context, device *models.Device) (c
 	if device.ID == "" {
 		device.ID = uuid.New().String()
 	}
+	if device.ClassificationSource != "manual" {
+		if rule := s.matchClassificationRule(ctx, device, ""); rule != nil {
+			device.DeviceType = rule.DeviceType
+			device.ClassificationConfidence = rule.Confidence
+			device.ClassificationSource = ClassificationSourceRule
+			device.ClassificationSignals = rule.signalsJSON()
+		}
+	}
 	ipsJSON, _ := json.Marshal(device.IPAddresses)
 	tagsJSON, _ := json.Marshal(device.Tags)
 	if device.Tags == nil {
@@ -303,7 +360,7 @@ func (s *ReconStore) GetDevice(ctx context.Context, id string) (*models.Device,
 		location, category, primary_role, owner,
 		classification_confidence, classification_source, classification_signals,
 		parent_device_id, network_layer, connection_type
-		FROM recon_devices WHERE id = ?`, id))
+		FROM recon_devices WHERE id = ? AND deleted_at IS NULL`, id))
 }
 
 // GetDeviceByMAC returns a device by MAC address.
@@ -315,7 +372,7 @@ func (s *ReconStore) GetDeviceByMAC(ctx context.Context, mac string) (*models.De
 		location, category, primary_role, owner,
 		classification_confidence, classification_source, classification_signals,
 		parent_device_id, network_layer, connection_type
-		FROM recon_devices WHERE mac_address = ?`, mac))
+		FROM recon_devices WHERE mac_address = ? AND deleted_at IS NULL`, mac))
 }
 
 // GetDeviceByIP returns the first device matching the given IP address.
@@ -328,7 +385,7 @@ func (s *ReconStore) GetDeviceByIP(ctx context.Context, ip string) (*models.Devi
 		location, category, primary_role, owner,
 		classification_confidence, classification_source, classification_signals,
 		parent_device_id, network_layer, connection_type
-		FROM recon_devices WHERE ip_addresses LIKE ?`, "%\""+ip+"\"%"))
+		FROM recon_devices WHERE ip_addresses LIKE ? AND deleted_at IS NULL`, "%\""+ip+"\"%"))
 }
 
 // GetDeviceByHostname returns the first device matching the given hostname.
@@ -340,7 +397,7 @@ func (s *ReconStore) GetDeviceByHostname(ctx context.Context, hostname string) (
 		location, category, primary_role, owner,
 		classification_confidence, classification_source, classification_signals,
 		parent_device_id, network_layer, connection_type
-		FROM recon_devices WHERE hostname = ?`, hostname))
+		FROM recon_devices WHERE hostname = ? AND deleted_at IS NULL`, hostname))
 }
 
 // ListDevices returns a paginated list of devices.
@@ -349,8 +406,8 @@ func (s *ReconStore) ListDevices(ctx context.Context, opts ListDevicesOptions) (
 		opts.Limit = 50
 	}
 
-	// Build WHERE clause.
-	where := "1=1"
+	// Build WHERE clause. Devices in the trash are never listed here.
+	where := "deleted_at IS NULL"
 	args := []any{}
 	if opts.Status != "" {
 		where += " AND status = ?"
@@ -372,6 +429,27 @@ func (s *ReconStore) ListDevices(ctx context.Context, opts ListDevicesOptions) (
 		where += " AND owner = ?"
 		args = append(args, opts.Owner)
 	}
+	if opts.Location != "" {
+		where += " AND LOWER(location) = LOWER(?)"
+		args = append(args, opts.Location)
+	}
+	if opts.OpenPort > 0 {
+		where += " AND id IN (SELECT device_id FROM recon_device_ports WHERE port = ? AND protocol = 'tcp')"
+		args = append(args, opts.OpenPort)
+	}
+	for _, term := range strings.Fields(opts.Search) {
+		pattern := "%" + likeEscaper.Replace(term) + "%"
+		clauses := make([]string, len(deviceSearchColumns))
+		for i, col := range deviceSearchColumns {
+			clauses[i] = col + ` LIKE ? ESCAPE '\'`
+			args = append(args, pattern)
+		}
+		where += " AND (" + strings.Join(clauses, " OR ") + ")"
+	}
+
+	if len(opts.CustomFilters) > 0 || opts.CustomSort != nil {
+		return s.listDevicesByCustomFields(ctx, where, args, opts)
+	}
 
 	// Count total.
 	// The where clause is built above using only ? placeholders; no user input is concatenated.
@@ -383,7 +461,7 @@ func (s *ReconStore) ListDevices(ctx context.Context, opts ListDevicesOptions) (
 		return nil, 0, fmt.Errorf("count devices: %w", err)
 	}
 
-	// Query with pagination.
+	// Query with pagination. id breaks last_seen ties so pages never overlap.
 	queryArgs := make([]any, 0, len(args)+2)
 	queryArgs = append(queryArgs, args...)
 	queryArgs = append(queryArgs, opts.Limit, opts.Offset)
@@ -395,7 +473,7 @@ func (s *ReconStore) ListDevices(ctx context.Context, opts ListDevicesOptions) (
 		"location, category, primary_role, owner, "+
 		"classification_confidence, classification_source, classification_signals, "+
 		"parent_device_id, network_layer, connection_type "+
-		"FROM recon_devices WHERE "+where+" ORDER BY last_seen DESC LIMIT ? OFFSET ?",
+		"FROM recon_devices WHERE "+where+" ORDER BY last_seen DESC, id ASC LIMIT ? OFFSET ?",
 		queryArgs...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("list devices: %w", err)
@@ -425,9 +503,9 @@ func (s *ReconStore) CreateScan(ctx context.Context, scan *models.ScanResult) er
 		scan.Status = "running"
 	}
 	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO recon_scans (id, subnet, started_at, status)
-		VALUES (?, ?, ?, ?)`,
-		scan.ID, scan.Subnet, scan.StartedAt, scan.Status,
+		INSERT INTO recon_scans (id, subnet, started_at, status, template_id)
+		VALUES (?, ?, ?, ?, ?)`,
+		scan.ID, scan.Subnet, scan.StartedAt, scan.Status, scan.TemplateID,
 	)
 	if err != nil {
 		return fmt.Errorf("insert scan: %w", err)
@@ -464,9 +542,9 @@ func (s *ReconStore) GetScan(ctx context.Context, id string) (*models.ScanResult
 	var endedAt sql.NullString
 	var errorMsg string
 	err := s.db.QueryRowContext(ctx, `
-		SELECT id, subnet, started_at, ended_at, status, total, online, error_msg
+		SELECT id, subnet, started_at, ended_at, status, total, online, error_msg, template_id
 		FROM recon_scans WHERE id = ?`, id,
-	).Scan(&scan.ID, &scan.Subnet, &scan.StartedAt, &endedAt, &scan.Status, &scan.Total, &scan.Online, &errorMsg)
+	).Scan(&scan.ID, &scan.Subnet, &scan.StartedAt, &endedAt, &scan.Status, &scan.Total, &scan.Online, &errorMsg, &scan.TemplateID)
 	if err != nil {
 		return nil, fmt.Errorf("get scan: %w", err)
 	}
@@ -482,7 +560,7 @@ func (s *ReconStore) ListScans(ctx context.Context, limit, offset int) ([]models
 		limit = 50
 	}
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, subnet, started_at, ended_at, status, total, online, error_msg
+		SELECT id, subnet, started_at, ended_at, status, total, online, error_msg, template_id
 		FROM recon_scans ORDER BY started_at DESC LIMIT ? OFFSET ?`,
 		limit, offset,
 	)
@@ -496,7 +574,7 @@ func (s *ReconStore) ListScans(ctx context.Context, limit, offset int) ([]models
 		var scan models.ScanResult
 		var endedAt sql.NullString
 		var errorMsg string
-		if err := rows.Scan(&scan.ID, &scan.Subnet, &scan.StartedAt, &endedAt, &scan.Status, &scan.Total, &scan.Online, &errorMsg); err != nil {
+		if err := rows.Scan(&scan.ID, &scan.Subnet, &scan.StartedAt, &endedAt, &scan.Status, &scan.Total, &scan.Online, &errorMsg, &scan.TemplateID); err != nil {
 			return nil, fmt.Errorf("scan row: %w", err)
 		}
 		if endedAt.Valid {
@@ -507,6 +585,15 @@ func (s *ReconStore) ListScans(ctx context.Context, limit, offset int) ([]models
 	return scans, rows.Err()
 }
 
+// CountScans returns the total number of scans.
+func (s *ReconStore) CountScans(ctx context.Context) (int, error) {
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM recon_scans`).Scan(&total); err != nil {
+		return 0, fmt.Errorf("count scans: %w", err)
+	}
+	return total, nil
+}
+
 // LinkScanDevice associates a device with a scan.
 func (s *ReconStore) LinkScanDevice(ctx context.Context, scanID, deviceID string) error {
 	_, err := s.db.ExecContext(ctx, `
@@ -516,11 +603,31 @@ func (s *ReconStore) LinkScanDevice(ctx context.Context, scanID, deviceID string
 	return err
 }
 
+// undirectedLinkTypes are topology link types with no meaningful direction.
+// They are stored with the lower device ID as source so that A-B and B-A
+// collapse into one row under the (source, target, link_type) unique index.
+// Directional types such as fdb (switch to device), lldp (local to
+// neighbor), dhcp (client to server), and tailscale_route (subnet router to
+// routed device) are stored as given.
+var undirectedLinkTypes = map[string]bool{"arp": true}
+
+// normalizeTopologyLink orders the endpoints of an undirected link.
+func normalizeTopologyLink(link *TopologyLink) {
+	if !undirectedLinkTypes[link.LinkType] || link.SourceDeviceID <= link.TargetDeviceID {
+		return
+	}
+	link.SourceDeviceID, link.TargetDeviceID = link.TargetDeviceID, link.SourceDeviceID
+	link.SourcePort, link.TargetPort = link.TargetPort, link.SourcePort
+}
+
 // UpsertTopologyLink creates or updates a topology link between two devices.
+// Undirected links are normalized first, so link may have its endpoints
+// swapped on return.
 func (s *ReconStore) UpsertTopologyLink(ctx context.Context, link *TopologyLink) error {
 	if link.ID == "" {
 		link.ID = uuid.New().String()
 	}
+	normalizeTopologyLink(link)
 	now := time.Now().UTC()
 	_, err := s.db.ExecContext(ctx, `
 		INSERT INTO recon_topology_links (
@@ -539,12 +646,15 @@ func (s *ReconStore) UpsertTopologyLink(ctx context.Context, link *TopologyLink)
 	return nil
 }
 
-// GetTopologyLinks returns all topology links.
+// GetTopologyLinks returns all topology links between live devices. Links
+// of a device in the trash reappear when it is restored.
 func (s *ReconStore) GetTopologyLinks(ctx context.Context) ([]TopologyLink, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, source_device_id, target_device_id, source_port, target_port,
-			link_type, speed, discovered_at, last_confirmed
-		FROM recon_topology_links`)
+		SELECT l.id, l.source_device_id, l.target_device_id, l.source_port, l.target_port,
+			l.link_type, l.speed, l.discovered_at, l.last_confirmed
+		FROM recon_topology_links l
+		JOIN recon_devices src ON src.id = l.source_device_id AND src.deleted_at IS NULL
+		JOIN recon_devices tgt ON tgt.id = l.target_device_id AND tgt.deleted_at IS NULL`)
 	if err != nil {
 		return nil, fmt.Errorf("get topology links: %w", err)
 	}
@@ -573,7 +683,7 @@ func (s *ReconStore) FindStaleDevices(ctx context.Context, threshold time.Time)
 		location, category, primary_role, owner,
 		classification_confidence, classification_source, classification_signals,
 		parent_device_id, network_layer, connection_type
-		FROM recon_devices WHERE status = ? AND last_seen < ?`,
+		FROM recon_devices WHERE status = ? AND last_seen < ? AND deleted_at IS NULL`,
 		string(models.DeviceStatusOnline), threshold,
 	)
 	if err != nil {
@@ -712,7 +822,8 @@ func (s *ReconStore) UpdateDevice(ctx context.Context, id string, params UpdateD
 	}
 
 	if params.Hostname != nil {
-		_, err = s.db.ExecContext(ctx, `UPDATE recon_devices SET hostname = ? WHERE id = ?`, *params.Hostname, id)
+		_, err = s.db.ExecContext(ctx, `UPDATE recon_devices SET hostname = ?, hostname_source = ? WHERE id = ?`,
+			*params.Hostname, HostnameSourceManual, id)
 		if err != nil {
 			return fmt.Errorf("update hostname: %w", err)
 		}
@@ -797,9 +908,18 @@ func (s *ReconStore) UpdateDeviceClassification(ctx context.Context, deviceID st
 	return nil
 }
 
-// DeleteDevice removes a device by ID. Returns an error if the device does not exist.
+// DeleteDevice moves a device to the trash and detaches its children.
+// Returns sql.ErrNoRows if the device does not exist or is already in the trash.
 func (s *ReconStore) DeleteDevice(ctx context.Context, id string) error {
-	res, err := s.db.ExecContext(ctx, `DELETE FROM recon_devices WHERE id = ?`, id)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE recon_devices SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`,
+		time.Now().UTC(), id)
 	if err != nil {
 		return fmt.Errorf("delete device: %w", err)
 	}
@@ -807,9 +927,109 @@ func (s *ReconStore) DeleteDevice(ctx context.Context, id string) error {
 	if n == 0 {
 		return sql.ErrNoRows
 	}
+
+	// Detach children so they stay visible at the top of the hierarchy
+	// rather than pointing at a device in the trash.
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE recon_devices SET parent_device_id = '' WHERE parent_device_id = ?`, id,
+	); err != nil {
+		return fmt.Errorf("detach child devices: %w", err)
+	}
+	return tx.Commit()
+}
+
+// PurgeDevice permanently removes a device, live or in the trash, along
+// with its history.
+func (s *ReconStore) PurgeDevice(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM recon_devices WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("purge device: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RestoreDevice moves a device out of the trash. It returns sql.ErrNoRows
+// if the device is not in the trash, and ErrDeviceRestoreConflict if a live
+// device has since been discovered with the same MAC address.
+func (s *ReconStore) RestoreDevice(ctx context.Context, id string) error {
+	var mac string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT mac_address FROM recon_devices WHERE id = ? AND deleted_at IS NOT NULL`, id,
+	).Scan(&mac)
+	if err != nil {
+		return err
+	}
+	if mac != "" {
+		if _, err := s.GetDeviceByMAC(ctx, mac); err == nil {
+			return ErrDeviceRestoreConflict
+		}
+	}
+
+	_, err = s.db.ExecContext(ctx, `UPDATE recon_devices SET deleted_at = NULL WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("restore device: %w", err)
+	}
 	return nil
 }
 
+// ListDeletedDevices returns the devices in the trash, most recently
+// deleted first.
+func (s *ReconStore) ListDeletedDevices(ctx context.Context) ([]DeletedDevice, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT
+		id, hostname, ip_addresses, mac_address, manufacturer,
+		device_type, os, status, discovery_method, agent_id,
+		first_seen, last_seen, notes, tags, custom_fields,
+		location, category, primary_role, owner,
+		classification_confidence, classification_source, classification_signals,
+		parent_device_id, network_layer, connection_type, deleted_at
+		FROM recon_devices WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("list deleted devices: %w", err)
+	}
+	defer rows.Close()
+
+	devices := []DeletedDevice{}
+	for rows.Next() {
+		var d DeletedDevice
+		var ipsJSON, tagsJSON, cfJSON string
+		var dt, status, method string
+		if err := rows.Scan(
+			&d.ID, &d.Hostname, &ipsJSON, &d.MACAddress, &d.Manufacturer,
+			&dt, &d.OS, &status, &method, &d.AgentID,
+			&d.FirstSeen, &d.LastSeen, &d.Notes, &tagsJSON, &cfJSON,
+			&d.Location, &d.Category, &d.PrimaryRole, &d.Owner,
+			&d.ClassificationConfidence, &d.ClassificationSource, &d.ClassificationSignals,
+			&d.ParentDeviceID, &d.NetworkLayer, &d.ConnectionType, &d.DeletedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan deleted device: %w", err)
+		}
+		d.DeviceType = models.DeviceType(dt)
+		d.Status = models.DeviceStatus(status)
+		d.DiscoveryMethod = models.DiscoveryMethod(method)
+		_ = json.Unmarshal([]byte(ipsJSON), &d.IPAddresses)
+		_ = json.Unmarshal([]byte(tagsJSON), &d.Tags)
+		_ = json.Unmarshal([]byte(cfJSON), &d.CustomFields)
+		devices = append(devices, d)
+	}
+	return devices, rows.Err()
+}
+
+// PurgeDeletedDevices permanently removes devices that were moved to the
+// trash before the given time.
+func (s *ReconStore) PurgeDeletedDevices(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM recon_devices WHERE deleted_at IS NOT NULL AND deleted_at < ?`, before)
+	if err != nil {
+		return 0, fmt.Errorf("purge deleted devices: %w", err)
+	}
+	return res.RowsAffected()
+}
+
 // InsertManualDevice creates a new device record with discovery_method=manual.
 func (s *ReconStore) InsertManualDevice(ctx context.Context, device *models.Device) error {
 	now := time.Now().UTC()
@@ -840,6 +1060,10 @@ func (s *ReconStore) InsertManualDevice(ctx context.Context, device *models.Devi
 	if manualConnType == "" {
 		manualConnType = models.ConnectionUnknown
 	}
+	hostnameSource := ""
+	if device.Hostname != "" {
+		hostnameSource = HostnameSourceManual
+	}
 	_, err := s.db.ExecContext(ctx, `
 		INSERT INTO recon_devices (
 			id, hostname, ip_addresses, mac_address, manufacturer,
@@ -847,14 +1071,14 @@ func (s *ReconStore) InsertManualDevice(ctx context.Context, device *models.Devi
 			first_seen, last_seen, notes, tags, custom_fields,
 			location, category, primary_role, owner,
 			classification_confidence, classification_source, classification_signals,
-			parent_device_id, network_layer, connection_type
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			parent_device_id, network_layer, connection_type, hostname_source
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		device.ID, device.Hostname, string(ipsJSON), device.MACAddress, device.Manufacturer,
 		string(device.DeviceType), device.OS, string(device.Status), string(device.DiscoveryMethod), device.AgentID,
 		now, now, device.Notes, string(tagsJSON), string(cfJSON),
 		device.Location, device.Category, device.PrimaryRole, device.Owner,
 		device.ClassificationConfidence, device.ClassificationSource, device.ClassificationSignals,
-		device.ParentDeviceID, device.NetworkLayer, manualConnType,
+		device.ParentDeviceID, device.NetworkLayer, manualConnType, hostnameSource,
 	)
 	if err != nil {
 		return fmt.Errorf("insert manual device: %w", err)
@@ -955,16 +1179,18 @@ func (s *ReconStore) GetInventorySummary(ctx context.Context, staleDays int) (*I
 			COUNT(*),
 			COALESCE(SUM(CASE WHEN status = 'online' THEN 1 ELSE 0 END), 0),
 			COALESCE(SUM(CASE WHEN status = 'offline' THEN 1 ELSE 0 END), 0)
-		FROM recon_devices`,
+		FROM recon_devices WHERE deleted_at IS NULL`,
 	).Scan(&summary.TotalDevices, &summary.OnlineCount, &summary.OfflineCount)
 	if err != nil {
 		return nil, fmt.Errorf("inventory counts: %w", err)
 	}
+	summary.OtherCount = summary.TotalDevices - summary.OnlineCount - summary.OfflineCount
 
-	// Stale count: online devices not seen in staleDays.
+	// Stale count: online devices not seen in staleDays. These are already
+	// counted in OnlineCount.
 	threshold := time.Now().UTC().Add(-time.Duration(staleDays) * 24 * time.Hour)
 	err = s.db.QueryRowContext(ctx,
-		`SELECT COUNT(*) FROM recon_devices WHERE status = 'online' AND last_seen < ?`,
+		`SELECT COUNT(*) FROM recon_devices WHERE status = 'online' AND last_seen < ? AND deleted_at IS NULL`,
 		threshold,
 	).Scan(&summary.StaleCount)
 	if err != nil {
@@ -973,7 +1199,7 @@ func (s *ReconStore) GetInventorySummary(ctx context.Context, staleDays int) (*I
 
 	// Group by category.
 	catRows, err := s.db.QueryContext(ctx,
-		`SELECT category, COUNT(*) FROM recon_devices WHERE category != '' GROUP BY category`)
+		`SELECT category, COUNT(*) FROM recon_devices WHERE category != '' AND deleted_at IS NULL GROUP BY category`)
 	if err != nil {
 		return nil, fmt.Errorf("by category: %w", err)
 	}
@@ -992,7 +1218,7 @@ func (s *ReconStore) GetInventorySummary(ctx context.Context, staleDays int) (*I
 
 	// Group by device_type.
 	typeRows, err := s.db.QueryContext(ctx,
-		`SELECT device_type, COUNT(*) FROM recon_devices GROUP BY device_type`)
+		`SELECT device_type, COUNT(*) FROM recon_devices WHERE deleted_at IS NULL GROUP BY device_type`)
 	if err != nil {
 		return nil, fmt.Errorf("by type: %w", err)
 	}
@@ -1036,6 +1262,55 @@ func (s *ReconStore) RemoveFDBLinksForDevice(ctx context.Context, deviceID strin
 	return nil
 }
 
+// RemoveLLDPLinksForDevice removes LLDP topology links where the given device
+// is the source. Called before re-inserting a fresh LLDP neighbor table.
+func (s *ReconStore) RemoveLLDPLinksForDevice(ctx context.Context, deviceID string) error {
+	_, err := s.db.ExecContext(ctx,
+		`DELETE FROM recon_topology_links WHERE link_type = 'lldp' AND source_device_id = ?`,
+		deviceID)
+	if err != nil {
+		return fmt.Errorf("remove LLDP links for device %s: %w", deviceID, err)
+	}
+	return nil
+}
+
+// RemoveTailscaleRouteLinksForDevice removes the Tailscale route links where
+// the given device is the subnet router. Called before re-inserting the
+// devices in its current routes.
+func (s *ReconStore) RemoveTailscaleRouteLinksForDevice(ctx context.Context, deviceID string) error {
+	_, err := s.db.ExecContext(ctx,
+		`DELETE FROM recon_topology_links WHERE link_type = 'tailscale_route' AND source_device_id = ?`,
+		deviceID)
+	if err != nil {
+		return fmt.Errorf("remove Tailscale route links for device %s: %w", deviceID, err)
+	}
+	return nil
+}
+
+// DeviceIDsWithLinkType returns the set of devices at either end of a
+// topology link of the given type.
+func (s *ReconStore) DeviceIDsWithLinkType(ctx context.Context, linkType string) (map[string]bool, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT source_device_id FROM recon_topology_links WHERE link_type = ?
+		UNION
+		SELECT target_device_id FROM recon_topology_links WHERE link_type = ?`,
+		linkType, linkType)
+	if err != nil {
+		return nil, fmt.Errorf("devices with %s links: %w", linkType, err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan device id: %w", err)
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
 // BulkUpdateDevices applies the same partial update to all devices matching the given IDs.
 // Returns the number of updated rows.
 func (s *ReconStore) BulkUpdateDevices(ctx context.Context, ids []string, params UpdateDeviceParams) (int, error) {
@@ -1117,6 +1392,106 @@ func (s *ReconStore) BulkUpdateDevices(ctx context.Context, ids []string, params
 	return int(n), nil
 }
 
+// BulkAddTags adds tags to every listed device, keeping the tags each
+// device already has. Returns the number of devices whose tags changed.
+func (s *ReconStore) BulkAddTags(ctx context.Context, ids, tags []string) (int, error) {
+	return s.bulkEditTags(ctx, ids, func(existing []string) []string {
+		seen := make(map[string]bool, len(existing))
+		for _, t := range existing {
+			seen[t] = true
+		}
+		out := existing
+		for _, t := range tags {
+			if !seen[t] {
+				seen[t] = true
+				out = append(out, t)
+			}
+		}
+		return out
+	})
+}
+
+// BulkRemoveTags removes tags from every listed device, keeping their other
+// tags. Returns the number of devices whose tags changed.
+func (s *ReconStore) BulkRemoveTags(ctx context.Context, ids, tags []string) (int, error) {
+	drop := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		drop[t] = true
+	}
+	return s.bulkEditTags(ctx, ids, func(existing []string) []string {
+		out := make([]string, 0, len(existing))
+		for _, t := range existing {
+			if !drop[t] {
+				out = append(out, t)
+			}
+		}
+		return out
+	})
+}
+
+// bulkEditTags rewrites the tags of the listed non-deleted devices with
+// edit inside one transaction, writing back only the devices it changed.
+func (s *ReconStore) bulkEditTags(ctx context.Context, ids []string, edit func([]string) []string) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback on commit is a no-op
+
+	placeholders := make([]string, len(ids))
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	query := "SELECT id, tags FROM recon_devices WHERE deleted_at IS NULL AND id IN (" + //nolint:gosec // G202: dynamic SQL uses parameterized placeholders only
+		strings.Join(placeholders, ", ") + ")"
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("query device tags: %w", err)
+	}
+	current := make(map[string][]string)
+	for rows.Next() {
+		var id, tagsJSON string
+		if err := rows.Scan(&id, &tagsJSON); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan device tags: %w", err)
+		}
+		var tags []string
+		_ = json.Unmarshal([]byte(tagsJSON), &tags)
+		current[id] = tags
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterate device tags: %w", err)
+	}
+
+	updated := 0
+	for id, tags := range current {
+		next := edit(append([]string(nil), tags...))
+		if slices.Equal(next, tags) {
+			continue
+		}
+		if next == nil {
+			next = []string{}
+		}
+		tagsJSON, _ := json.Marshal(next)
+		if _, err := tx.ExecContext(ctx, `UPDATE recon_devices SET tags = ? WHERE id = ?`, string(tagsJSON), id); err != nil {
+			return 0, fmt.Errorf("update tags: %w", err)
+		}
+		updated++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+	return updated, nil
+}
+
 // CreateTopologyLayout inserts a new topology layout record.
 func (s *ReconStore) CreateTopologyLayout(ctx context.Context, layout *TopologyLayout) error {
 	if layout.ID == "" {
@@ -1201,10 +1576,12 @@ func (s *ReconStore) SaveScanMetrics(ctx context.Context, m *models.ScanMetrics)
 	_, err := s.db.ExecContext(ctx, `
 		INSERT INTO recon_scan_metrics (
 			scan_id, duration_ms, ping_phase_ms, enrich_phase_ms, post_process_ms,
-			hosts_scanned, hosts_alive, devices_created, devices_updated, created_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			hosts_scanned, hosts_alive, devices_created, devices_updated,
+			concurrency, ping_timeout_ms, port_scan_concurrency, port_timeout_ms, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		m.ScanID, m.DurationMs, m.PingPhaseMs, m.EnrichPhaseMs, m.PostProcessMs,
-		m.HostsScanned, m.HostsAlive, m.DevicesCreated, m.DevicesUpdated, m.CreatedAt,
+		m.HostsScanned, m.HostsAlive, m.DevicesCreated, m.DevicesUpdated,
+		m.Concurrency, m.PingTimeoutMs, m.PortScanConcurrency, m.PortTimeoutMs, m.CreatedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("insert scan metrics: %w", err)
@@ -1218,10 +1595,12 @@ func (s *ReconStore) GetScanMetrics(ctx context.Context, scanID string) (*models
 	var m models.ScanMetrics
 	err := s.db.QueryRowContext(ctx, `
 		SELECT scan_id, duration_ms, ping_phase_ms, enrich_phase_ms, post_process_ms,
-			hosts_scanned, hosts_alive, devices_created, devices_updated, created_at
+			hosts_scanned, hosts_alive, devices_created, devices_updated,
+			concurrency, ping_timeout_ms, port_scan_concurrency, port_timeout_ms, created_at
 		FROM recon_scan_metrics WHERE scan_id = ?`, scanID,
 	).Scan(&m.ScanID, &m.DurationMs, &m.PingPhaseMs, &m.EnrichPhaseMs, &m.PostProcessMs,
-		&m.HostsScanned, &m.HostsAlive, &m.DevicesCreated, &m.DevicesUpdated, &m.CreatedAt)
+		&m.HostsScanned, &m.HostsAlive, &m.DevicesCreated, &m.DevicesUpdated,
+		&m.Concurrency, &m.PingTimeoutMs, &m.PortScanConcurrency, &m.PortTimeoutMs, &m.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -1313,7 +1692,8 @@ func (s *ReconStore) GetRawMetricsSince(ctx context.Context, since time.Time) ([
 	sinceStr := since.UTC().Format(time.RFC3339)
 	rows, err := s.db.QueryContext(ctx, `
 		SELECT scan_id, duration_ms, ping_phase_ms, enrich_phase_ms, post_process_ms,
-			hosts_scanned, hosts_alive, devices_created, devices_updated, created_at
+			hosts_scanned, hosts_alive, devices_created, devices_updated,
+			concurrency, ping_timeout_ms, port_scan_concurrency, port_timeout_ms, created_at
 		FROM recon_scan_metrics
 		WHERE created_at >= ?
 		ORDER BY created_at ASC`, sinceStr)
@@ -1327,7 +1707,8 @@ func (s *ReconStore) GetRawMetricsSince(ctx context.Context, since time.Time) ([
 		var m models.ScanMetrics
 		if err := rows.Scan(
 			&m.ScanID, &m.DurationMs, &m.PingPhaseMs, &m.EnrichPhaseMs, &m.PostProcessMs,
-			&m.HostsScanned, &m.HostsAlive, &m.DevicesCreated, &m.DevicesUpdated, &m.CreatedAt,
+			&m.HostsScanned, &m.HostsAlive, &m.DevicesCreated, &m.DevicesUpdated,
+			&m.Concurrency, &m.PingTimeoutMs, &m.PortScanConcurrency, &m.PortTimeoutMs, &m.CreatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan raw metrics row: %w", err)
 		}
@@ -1342,7 +1723,8 @@ func (s *ReconStore) GetRawMetricsInRange(ctx context.Context, start, end time.T
 	endStr := end.UTC().Format(time.RFC3339)
 	rows, err := s.db.QueryContext(ctx, `
 		SELECT scan_id, duration_ms, ping_phase_ms, enrich_phase_ms, post_process_ms,
-			hosts_scanned, hosts_alive, devices_created, devices_updated, created_at
+			hosts_scanned, hosts_alive, devices_created, devices_updated,
+			concurrency, ping_timeout_ms, port_scan_concurrency, port_timeout_ms, created_at
 		FROM recon_scan_metrics
 		WHERE created_at >= ? AND created_at < ?
 		ORDER BY created_at ASC`, startStr, endStr)
@@ -1356,7 +1738,8 @@ func (s *ReconStore) GetRawMetricsInRange(ctx context.Context, start, end time.T
 		var m models.ScanMetrics
 		if err := rows.Scan(
 			&m.ScanID, &m.DurationMs, &m.PingPhaseMs, &m.EnrichPhaseMs, &m.PostProcessMs,
-			&m.HostsScanned, &m.HostsAlive, &m.DevicesCreated, &m.DevicesUpdated, &m.CreatedAt,
+			&m.HostsScanned, &m.HostsAlive, &m.DevicesCreated, &m.DevicesUpdated,
+			&m.Concurrency, &m.PingTimeoutMs, &m.PortScanConcurrency, &m.PortTimeoutMs, &m.CreatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan raw metrics row: %w", err)
 		}
@@ -1372,7 +1755,8 @@ func (s *ReconStore) ListRawMetrics(ctx context.Context, limit int) ([]models.Sc
 	}
 	rows, err := s.db.QueryContext(ctx, `
 		SELECT scan_id, duration_ms, ping_phase_ms, enrich_phase_ms, post_process_ms,
-			hosts_scanned, hosts_alive, devices_created, devices_updated, created_at
+			hosts_scanned, hosts_alive, devices_created, devices_updated,
+			concurrency, ping_timeout_ms, port_scan_concurrency, port_timeout_ms, created_at
 		FROM recon_scan_metrics
 		ORDER BY created_at DESC
 		LIMIT ?`, limit)
@@ -1386,7 +1770,8 @@ func (s *ReconStore) ListRawMetrics(ctx context.Context, limit int) ([]models.Sc
 		var m models.ScanMetrics
 		if err := rows.Scan(
 			&m.ScanID, &m.DurationMs, &m.PingPhaseMs, &m.EnrichPhaseMs, &m.PostProcessMs,
-			&m.HostsScanned, &m.HostsAlive, &m.DevicesCreated, &m.DevicesUpdated, &m.CreatedAt,
+			&m.HostsScanned, &m.HostsAlive, &m.DevicesCreated, &m.DevicesUpdated,
+			&m.Concurrency, &m.PingTimeoutMs, &m.PortScanConcurrency, &m.PortTimeoutMs, &m.CreatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan raw metrics row: %w", err)
 		}
@@ -1469,8 +1854,9 @@ func (s *ReconStore) GetDeviceTree(ctx context.Context) ([]DeviceTreeNode, error
 	rows, err := s.db.QueryContext(ctx, `
 		SELECT d.id, d.hostname, d.device_type, d.status, d.ip_addresses,
 			d.parent_device_id, d.network_layer,
-			(SELECT COUNT(*) FROM recon_devices c WHERE c.parent_device_id = d.id) AS child_count
+			(SELECT COUNT(*) FROM recon_devices c WHERE c.parent_device_id = d.id AND c.deleted_at IS NULL) AS child_count
 		FROM recon_devices d
+		WHERE d.deleted_at IS NULL
 		ORDER BY d.network_layer ASC, d.hostname ASC`)
 	if err != nil {
 		return nil, fmt.Errorf("get device tree: %w", err)
@@ -1513,7 +1899,7 @@ func (s *ReconStore) ListAllDevices(ctx context.Context) ([]models.Device, error
 		location, category, primary_role, owner,
 		classification_confidence, classification_source, classification_signals,
 		parent_device_id, network_layer, connection_type
-		FROM recon_devices`)
+		FROM recon_devices WHERE deleted_at IS NULL`)
 	if err != nil {
 		return nil, fmt.Errorf("list all devices: %w", err)
 	}
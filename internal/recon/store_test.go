@@ -2,12 +2,16 @@ package recon
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/HerbHall/subnetree/internal/store"
 	"github.com/HerbHall/subnetree/pkg/models"
+	"github.com/HerbHall/subnetree/pkg/plugin"
 )
 
 func testStore(t *testing.T) *ReconStore {
@@ -182,6 +186,49 @@ func TestListDevices_Pagination(t *testing.T) {
 	}
 }
 
+func TestListDevices_PaginationWithTiedLastSeen(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+
+	for i := 0; i < 7; i++ {
+		d := &models.Device{
+			IPAddresses:     []string{fmt.Sprintf("10.0.2.%d", i+1)},
+			MACAddress:      fmt.Sprintf("AA:BB:CC:DD:02:%02X", i),
+			Status:          models.DeviceStatusOnline,
+			DiscoveryMethod: models.DiscoveryICMP,
+		}
+		if _, err := s.UpsertDevice(ctx, d); err != nil {
+			t.Fatalf("create device %d: %v", i, err)
+		}
+	}
+	// Every device shares one last_seen, as after a bulk import.
+	if _, err := s.db.ExecContext(ctx, "UPDATE recon_devices SET last_seen = ?", time.Now().UTC()); err != nil {
+		t.Fatalf("set last_seen: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	var prev string
+	for offset := 0; offset < 7; offset += 3 {
+		page, _, err := s.ListDevices(ctx, ListDevicesOptions{Limit: 3, Offset: offset})
+		if err != nil {
+			t.Fatalf("ListDevices offset %d: %v", offset, err)
+		}
+		for i := range page {
+			if seen[page[i].ID] {
+				t.Errorf("device %s repeated at offset %d", page[i].ID, offset)
+			}
+			if page[i].ID < prev {
+				t.Errorf("device %s after %s, want ascending id among ties", page[i].ID, prev)
+			}
+			seen[page[i].ID] = true
+			prev = page[i].ID
+		}
+	}
+	if len(seen) != 7 {
+		t.Errorf("paged through %d distinct devices, want 7", len(seen))
+	}
+}
+
 func TestListDevices_FilterByStatus(t *testing.T) {
 	s := testStore(t)
 	ctx := context.Background()
@@ -456,6 +503,167 @@ func TestTopologyLinkCRUD(t *testing.T) {
 	}
 }
 
+func TestGetTopologyLinks_ExcludesTrashedDevices(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+
+	d1 := &models.Device{
+		IPAddresses: []string{"10.0.0.1"}, MACAddress: "AA:00:00:00:00:01",
+		Status: models.DeviceStatusOnline, DiscoveryMethod: models.DiscoveryARP,
+	}
+	d2 := &models.Device{
+		IPAddresses: []string{"10.0.0.2"}, MACAddress: "AA:00:00:00:00:02",
+		Status: models.DeviceStatusOnline, DiscoveryMethod: models.DiscoveryARP,
+	}
+	_, _ = s.UpsertDevice(ctx, d1)
+	_, _ = s.UpsertDevice(ctx, d2)
+	if err := s.UpsertTopologyLink(ctx, &TopologyLink{
+		SourceDeviceID: d1.ID, TargetDeviceID: d2.ID, LinkType: "arp",
+	}); err != nil {
+		t.Fatalf("UpsertTopologyLink: %v", err)
+	}
+
+	if err := s.DeleteDevice(ctx, d2.ID); err != nil {
+		t.Fatalf("DeleteDevice: %v", err)
+	}
+	links, err := s.GetTopologyLinks(ctx)
+	if err != nil {
+		t.Fatalf("GetTopologyLinks: %v", err)
+	}
+	if len(links) != 0 {
+		t.Errorf("link count = %d, want 0 (target in trash)", len(links))
+	}
+
+	if err := s.RestoreDevice(ctx, d2.ID); err != nil {
+		t.Fatalf("RestoreDevice: %v", err)
+	}
+	links, err = s.GetTopologyLinks(ctx)
+	if err != nil {
+		t.Fatalf("GetTopologyLinks: %v", err)
+	}
+	if len(links) != 1 {
+		t.Errorf("link count after restore = %d, want 1", len(links))
+	}
+}
+
+func TestUpsertTopologyLink_UndirectedCollapses(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+
+	a, b := "device-a", "device-b"
+	for _, id := range []string{a, b} {
+		if _, err := s.db.ExecContext(ctx, "INSERT INTO recon_devices (id) VALUES (?)", id); err != nil {
+			t.Fatalf("insert device: %v", err)
+		}
+	}
+	for _, l := range []TopologyLink{
+		{SourceDeviceID: a, TargetDeviceID: b, LinkType: "arp"},
+		{SourceDeviceID: b, TargetDeviceID: a, LinkType: "arp"},
+		{SourceDeviceID: a, TargetDeviceID: b, LinkType: "fdb"},
+		{SourceDeviceID: b, TargetDeviceID: a, LinkType: "fdb"},
+	} {
+		if err := s.UpsertTopologyLink(ctx, &l); err != nil {
+			t.Fatalf("UpsertTopologyLink(%s %s->%s): %v", l.LinkType, l.SourceDeviceID, l.TargetDeviceID, err)
+		}
+	}
+
+	links, err := s.GetTopologyLinks(ctx)
+	if err != nil {
+		t.Fatalf("GetTopologyLinks: %v", err)
+	}
+	var arp, fdb int
+	for _, l := range links {
+		switch l.LinkType {
+		case "arp":
+			arp++
+			if l.SourceDeviceID != a || l.TargetDeviceID != b {
+				t.Errorf("arp link = %s->%s, want lower ID %s as source", l.SourceDeviceID, l.TargetDeviceID, a)
+			}
+		case "fdb":
+			fdb++
+		}
+	}
+	if arp != 1 {
+		t.Errorf("arp links = %d, want 1 regardless of direction", arp)
+	}
+	if fdb != 2 {
+		t.Errorf("fdb links = %d, want 2 (directional)", fdb)
+	}
+}
+
+func TestMigration_DedupesReversedARPLinks(t *testing.T) {
+	db, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+
+	// Stop just before the dedupe migration so the duplicates can be seeded.
+	all := migrations()
+	var before []plugin.Migration
+	for _, m := range all {
+		if m.Version < 22 {
+			before = append(before, m)
+		}
+	}
+	if err := db.Migrate(ctx, "recon", before); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	for _, id := range []string{"a", "b", "c", "d"} {
+		if _, err := db.DB().ExecContext(ctx, "INSERT INTO recon_devices (id) VALUES (?)", id); err != nil {
+			t.Fatalf("insert device: %v", err)
+		}
+	}
+
+	older := time.Now().UTC().Add(-time.Hour)
+	newer := time.Now().UTC()
+	rows := []struct {
+		id, src, tgt, srcPort, linkType string
+		confirmed                       time.Time
+	}{
+		{"1", "a", "b", "", "arp", older},
+		{"2", "b", "a", "", "arp", newer},     // reverse of 1
+		{"3", "d", "c", "eth0", "arp", older}, // stored high-to-low, no twin
+		{"4", "b", "a", "", "fdb", older},     // directional, untouched
+	}
+	for _, r := range rows {
+		if _, err := db.DB().ExecContext(ctx, `
+			INSERT INTO recon_topology_links (id, source_device_id, target_device_id, source_port, target_port,
+				link_type, speed, discovered_at, last_confirmed)
+			VALUES (?, ?, ?, ?, '', ?, 0, ?, ?)`,
+			r.id, r.src, r.tgt, r.srcPort, r.linkType, r.confirmed, r.confirmed); err != nil {
+			t.Fatalf("insert link %s: %v", r.id, err)
+		}
+	}
+
+	if err := db.Migrate(ctx, "recon", all); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	links, err := NewReconStore(db.DB()).GetTopologyLinks(ctx)
+	if err != nil {
+		t.Fatalf("GetTopologyLinks: %v", err)
+	}
+	byID := make(map[string]TopologyLink)
+	for _, l := range links {
+		byID[l.ID] = l
+	}
+	if len(byID) != 3 {
+		t.Fatalf("links = %d, want 3: %+v", len(byID), links)
+	}
+	if l, ok := byID["1"]; !ok || !l.LastConfirmed.Equal(newer) {
+		t.Errorf("kept link 1 = %+v, want last_confirmed carried over from its reverse", l)
+	}
+	if l := byID["3"]; l.SourceDeviceID != "c" || l.TargetDeviceID != "d" || l.TargetPort != "eth0" {
+		t.Errorf("link 3 = %s(%s)->%s(%s), want flipped to c->d(eth0)", l.SourceDeviceID, l.SourcePort, l.TargetDeviceID, l.TargetPort)
+	}
+	if l := byID["4"]; l.SourceDeviceID != "b" {
+		t.Errorf("fdb link source = %s, want b (unchanged)", l.SourceDeviceID)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Device CRUD store tests
 // ---------------------------------------------------------------------------
@@ -596,6 +804,125 @@ func TestDeleteDevice_NotFound(t *testing.T) {
 	}
 }
 
+func TestDeleteDevice_TrashAndRestore(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+
+	parent := &models.Device{
+		Hostname: "switch", IPAddresses: []string{"10.0.0.2"},
+		MACAddress: "AA:BB:CC:DD:EE:10", Status: models.DeviceStatusOnline,
+		DiscoveryMethod: models.DiscoveryICMP,
+	}
+	_, _ = s.UpsertDevice(ctx, parent)
+	child := &models.Device{
+		Hostname: "laptop", IPAddresses: []string{"10.0.0.3"},
+		MACAddress: "AA:BB:CC:DD:EE:11", Status: models.DeviceStatusOnline,
+		DiscoveryMethod: models.DiscoveryICMP,
+	}
+	_, _ = s.UpsertDevice(ctx, child)
+	if err := s.UpdateDeviceHierarchy(ctx, child.ID, parent.ID, 2); err != nil {
+		t.Fatalf("UpdateDeviceHierarchy: %v", err)
+	}
+
+	if err := s.DeleteDevice(ctx, parent.ID); err != nil {
+		t.Fatalf("DeleteDevice: %v", err)
+	}
+	if err := s.DeleteDevice(ctx, parent.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("second DeleteDevice error = %v, want sql.ErrNoRows", err)
+	}
+
+	_, total, err := s.ListDevices(ctx, ListDevicesOptions{})
+	if err != nil {
+		t.Fatalf("ListDevices: %v", err)
+	}
+	if total != 1 {
+		t.Errorf("ListDevices total = %d, want 1 (trashed device excluded)", total)
+	}
+
+	// Children are detached, not deleted.
+	gotChild, err := s.GetDevice(ctx, child.ID)
+	if err != nil {
+		t.Fatalf("GetDevice(child): %v", err)
+	}
+	if gotChild.ParentDeviceID != "" {
+		t.Errorf("child ParentDeviceID = %q, want detached", gotChild.ParentDeviceID)
+	}
+
+	trash, err := s.ListDeletedDevices(ctx)
+	if err != nil {
+		t.Fatalf("ListDeletedDevices: %v", err)
+	}
+	if len(trash) != 1 || trash[0].ID != parent.ID || trash[0].DeletedAt.IsZero() {
+		t.Fatalf("ListDeletedDevices = %+v, want the parent with deleted_at set", trash)
+	}
+
+	if err := s.RestoreDevice(ctx, parent.ID); err != nil {
+		t.Fatalf("RestoreDevice: %v", err)
+	}
+	if _, err := s.GetDevice(ctx, parent.ID); err != nil {
+		t.Errorf("GetDevice after restore: %v", err)
+	}
+	if err := s.RestoreDevice(ctx, parent.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("RestoreDevice of live device error = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestRestoreDevice_MACConflict(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+
+	d := &models.Device{
+		Hostname: "nas", IPAddresses: []string{"10.0.0.4"},
+		MACAddress: "AA:BB:CC:DD:EE:12", Status: models.DeviceStatusOnline,
+		DiscoveryMethod: models.DiscoveryICMP,
+	}
+	_, _ = s.UpsertDevice(ctx, d)
+	if err := s.DeleteDevice(ctx, d.ID); err != nil {
+		t.Fatalf("DeleteDevice: %v", err)
+	}
+
+	// A scan rediscovers the same MAC as a new device.
+	again := &models.Device{
+		IPAddresses: []string{"10.0.0.4"}, MACAddress: "AA:BB:CC:DD:EE:12",
+		Status: models.DeviceStatusOnline, DiscoveryMethod: models.DiscoveryICMP,
+	}
+	created, err := s.UpsertDevice(ctx, again)
+	if err != nil || !created {
+		t.Fatalf("UpsertDevice created = %v, err = %v; want a new device", created, err)
+	}
+
+	if err := s.RestoreDevice(ctx, d.ID); !errors.Is(err, ErrDeviceRestoreConflict) {
+		t.Errorf("RestoreDevice error = %v, want ErrDeviceRestoreConflict", err)
+	}
+}
+
+func TestPurgeDeletedDevices(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+
+	d := &models.Device{
+		Hostname: "old", IPAddresses: []string{"10.0.0.5"},
+		MACAddress: "AA:BB:CC:DD:EE:13", Status: models.DeviceStatusOnline,
+		DiscoveryMethod: models.DiscoveryICMP,
+	}
+	_, _ = s.UpsertDevice(ctx, d)
+	if err := s.DeleteDevice(ctx, d.ID); err != nil {
+		t.Fatalf("DeleteDevice: %v", err)
+	}
+
+	n, err := s.PurgeDeletedDevices(ctx, time.Now().UTC().Add(-time.Hour))
+	if err != nil || n != 0 {
+		t.Fatalf("PurgeDeletedDevices(before deletion) = %d, %v; want 0, nil", n, err)
+	}
+	n, err = s.PurgeDeletedDevices(ctx, time.Now().UTC().Add(time.Minute))
+	if err != nil || n != 1 {
+		t.Fatalf("PurgeDeletedDevices = %d, %v; want 1, nil", n, err)
+	}
+	if trash, _ := s.ListDeletedDevices(ctx); len(trash) != 0 {
+		t.Errorf("trash has %d devices after purge, want 0", len(trash))
+	}
+}
+
 func TestInsertManualDevice(t *testing.T) {
 	s := testStore(t)
 	ctx := context.Background()
@@ -1179,6 +1506,54 @@ func TestListDevices_FilterByOwner(t *testing.T) {
 	}
 }
 
+func TestListDevices_FilterByLocationAndSearch(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+
+	d1 := &models.Device{
+		Hostname: "garage-cam", IPAddresses: []string{"10.0.0.1"}, MACAddress: "AA:BB:CC:00:00:01",
+		Status: models.DeviceStatusOnline, DiscoveryMethod: models.DiscoveryICMP,
+	}
+	d2 := &models.Device{
+		Hostname: "office_printer", IPAddresses: []string{"10.0.0.2"}, MACAddress: "AA:BB:CC:00:00:02",
+		Status: models.DeviceStatusOnline, DiscoveryMethod: models.DiscoveryICMP,
+	}
+	_, _ = s.UpsertDevice(ctx, d1)
+	_, _ = s.UpsertDevice(ctx, d2)
+
+	garage := "Garage"
+	_ = s.UpdateDevice(ctx, d1.ID, UpdateDeviceParams{Location: &garage})
+
+	devices, _, err := s.ListDevices(ctx, ListDevicesOptions{Location: "garage"})
+	if err != nil {
+		t.Fatalf("ListDevices(location): %v", err)
+	}
+	if len(devices) != 1 || devices[0].ID != d1.ID {
+		t.Errorf("location filter = %v, want only %s", devices, d1.ID)
+	}
+
+	tests := []struct {
+		search string
+		want   int
+	}{
+		{"cam", 1},
+		{"GARAGE cam", 1},
+		{"10.0.0", 2},
+		{"office_", 1},
+		{"cam printer", 0},
+		{"%", 0},
+	}
+	for _, tt := range tests {
+		_, total, err := s.ListDevices(ctx, ListDevicesOptions{Search: tt.search})
+		if err != nil {
+			t.Fatalf("ListDevices(%q): %v", tt.search, err)
+		}
+		if total != tt.want {
+			t.Errorf("search %q total = %d, want %d", tt.search, total, tt.want)
+		}
+	}
+}
+
 func TestGetInventorySummary(t *testing.T) {
 	s := testStore(t)
 	ctx := context.Background()
@@ -1251,6 +1626,60 @@ func TestGetInventorySummary_Empty(t *testing.T) {
 	}
 }
 
+func TestGetInventorySummary_StaleIsSubsetOfOnline(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+
+	devices := map[string]*models.Device{}
+	for i, name := range []string{"fresh", "stale", "offline", "degraded"} {
+		d := &models.Device{
+			IPAddresses:     []string{fmt.Sprintf("10.0.1.%d", i+1)},
+			MACAddress:      fmt.Sprintf("AA:BB:CC:00:01:%02X", i+1),
+			Status:          models.DeviceStatusOnline,
+			DiscoveryMethod: models.DiscoveryICMP,
+		}
+		if _, err := s.UpsertDevice(ctx, d); err != nil {
+			t.Fatalf("UpsertDevice(%s): %v", name, err)
+		}
+		devices[name] = d
+	}
+	old := time.Now().Add(-72 * time.Hour)
+	_, _ = s.db.ExecContext(ctx, "UPDATE recon_devices SET last_seen = ? WHERE id IN (?, ?)",
+		old, devices["stale"].ID, devices["offline"].ID)
+	_, _ = s.db.ExecContext(ctx, "UPDATE recon_devices SET status = 'offline' WHERE id = ?", devices["offline"].ID)
+	_, _ = s.db.ExecContext(ctx, "UPDATE recon_devices SET status = 'degraded' WHERE id = ?", devices["degraded"].ID)
+
+	check := func(wantOnline, wantOffline, wantStale int) {
+		t.Helper()
+		summary, err := s.GetInventorySummary(ctx, 1)
+		if err != nil {
+			t.Fatalf("GetInventorySummary: %v", err)
+		}
+		if summary.OnlineCount != wantOnline || summary.OfflineCount != wantOffline || summary.StaleCount != wantStale {
+			t.Errorf("online/offline/stale = %d/%d/%d, want %d/%d/%d",
+				summary.OnlineCount, summary.OfflineCount, summary.StaleCount, wantOnline, wantOffline, wantStale)
+		}
+		if summary.OtherCount != 1 {
+			t.Errorf("OtherCount = %d, want 1", summary.OtherCount)
+		}
+		if sum := summary.OnlineCount + summary.OfflineCount + summary.OtherCount; sum != summary.TotalDevices {
+			t.Errorf("online+offline+other = %d, want TotalDevices %d", sum, summary.TotalDevices)
+		}
+		if summary.StaleCount > summary.OnlineCount {
+			t.Errorf("StaleCount %d exceeds OnlineCount %d", summary.StaleCount, summary.OnlineCount)
+		}
+	}
+
+	// The stale device is still online; the old offline device is not stale.
+	check(2, 1, 1)
+
+	// Once the device lost checker marks it offline it leaves both counts.
+	if err := s.MarkDeviceOffline(ctx, devices["stale"].ID); err != nil {
+		t.Fatalf("MarkDeviceOffline: %v", err)
+	}
+	check(1, 2, 0)
+}
+
 func TestBulkUpdateDevices(t *testing.T) {
 	s := testStore(t)
 	ctx := context.Background()
@@ -1333,6 +1762,74 @@ func TestBulkUpdateDevices_NoFields(t *testing.T) {
 	}
 }
 
+func TestBulkAddRemoveTags(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+
+	initial := [][]string{{"rack-a", "linux"}, {"audited"}, nil}
+	ids := make([]string, len(initial))
+	for i, tags := range initial {
+		d := &models.Device{
+			IPAddresses:     []string{fmt.Sprintf("10.0.0.%d", i+1)},
+			MACAddress:      fmt.Sprintf("AA:BB:CC:DD:EE:%02X", i+30),
+			Status:          models.DeviceStatusOnline,
+			DiscoveryMethod: models.DiscoveryICMP,
+			Tags:            tags,
+		}
+		if _, err := s.UpsertDevice(ctx, d); err != nil {
+			t.Fatalf("UpsertDevice: %v", err)
+		}
+		ids[i] = d.ID
+	}
+	tagsOf := func(id string) []string {
+		got, err := s.GetDevice(ctx, id)
+		if err != nil {
+			t.Fatalf("GetDevice: %v", err)
+		}
+		return got.Tags
+	}
+
+	// The second device already has "audited", so only two change.
+	updated, err := s.BulkAddTags(ctx, append(ids, "missing"), []string{"audited", "q3"})
+	if err != nil {
+		t.Fatalf("BulkAddTags: %v", err)
+	}
+	if updated != 3 {
+		t.Errorf("BulkAddTags updated = %d, want 3", updated)
+	}
+	for i, want := range [][]string{
+		{"rack-a", "linux", "audited", "q3"},
+		{"audited", "q3"},
+		{"audited", "q3"},
+	} {
+		if got := tagsOf(ids[i]); !slices.Equal(got, want) {
+			t.Errorf("device %d tags after add = %v, want %v", i, got, want)
+		}
+	}
+
+	updated, err = s.BulkRemoveTags(ctx, ids[:2], []string{"q3", "linux"})
+	if err != nil {
+		t.Fatalf("BulkRemoveTags: %v", err)
+	}
+	if updated != 2 {
+		t.Errorf("BulkRemoveTags updated = %d, want 2", updated)
+	}
+	for i, want := range [][]string{
+		{"rack-a", "audited"},
+		{"audited"},
+		{"audited", "q3"},
+	} {
+		if got := tagsOf(ids[i]); !slices.Equal(got, want) {
+			t.Errorf("device %d tags after remove = %v, want %v", i, got, want)
+		}
+	}
+
+	// Removing tags no device has changes nothing.
+	if updated, err = s.BulkRemoveTags(ctx, ids, []string{"nope"}); err != nil || updated != 0 {
+		t.Errorf("BulkRemoveTags(nope) = %d, %v; want 0, nil", updated, err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Scan metrics store tests
 // ---------------------------------------------------------------------------
@@ -1358,6 +1855,11 @@ func TestSaveScanMetrics(t *testing.T) {
 		DevicesCreated: 3,
 		DevicesUpdated: 9,
 		CreatedAt:      time.Now().UTC().Format(time.RFC3339),
+
+		Concurrency:         32,
+		PingTimeoutMs:       1500,
+		PortScanConcurrency: 8,
+		PortTimeoutMs:       2000,
 	}
 
 	if err := s.SaveScanMetrics(ctx, metrics); err != nil {
@@ -1399,6 +1901,12 @@ func TestSaveScanMetrics(t *testing.T) {
 	if got.DevicesUpdated != 9 {
 		t.Errorf("DevicesUpdated = %d, want 9", got.DevicesUpdated)
 	}
+	if got.Concurrency != 32 || got.PortScanConcurrency != 8 {
+		t.Errorf("concurrency = %d/%d, want 32/8", got.Concurrency, got.PortScanConcurrency)
+	}
+	if got.PingTimeoutMs != 1500 || got.PortTimeoutMs != 2000 {
+		t.Errorf("timeouts = %d/%d ms, want 1500/2000", got.PingTimeoutMs, got.PortTimeoutMs)
+	}
 }
 
 func TestGetScanMetrics_NotFound(t *testing.T) {
@@ -0,0 +1,197 @@
+package recon
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"go.uber.org/zap"
+)
+
+// SubnetUtilization reports how many host addresses of a subnet are in use
+// by known devices.
+type SubnetUtilization struct {
+	Subnet        string  `json:"subnet" example:"192.168.1.0/24"`
+	Total         int     `json:"total" example:"254"`           // Usable host addresses
+	Used          int     `json:"used" example:"212"`            // Addresses held by known devices
+	Available     int     `json:"available" example:"42"`        // Total minus used
+	Utilization   float64 `json:"utilization" example:"83.5"`    // Percent of total in use
+	OverThreshold bool    `json:"over_threshold" example:"true"` // Utilization at or above the threshold
+	Inferred      bool    `json:"inferred"`                      // Derived from device IPs rather than configured
+}
+
+// SubnetReport is the response for GET /recon/subnets.
+type SubnetReport struct {
+	Threshold float64             `json:"threshold" example:"80"`
+	Subnets   []SubnetUtilization `json:"subnets"`
+	// Unassigned counts device IPv4 addresses outside every configured
+	// subnet. Always zero when subnets are inferred.
+	Unassigned int `json:"unassigned"`
+}
+
+// parseReportSubnets parses configured subnet CIDRs. Only IPv4 is supported.
+func parseReportSubnets(cidrs []string) ([]*net.IPNet, error) {
+	subnets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q", cidr)
+		}
+		if ipNet.IP.To4() == nil {
+			return nil, fmt.Errorf("%q is not an IPv4 subnet", cidr)
+		}
+		subnets = append(subnets, ipNet)
+	}
+	return subnets, nil
+}
+
+// usableHosts returns the number of assignable addresses in an IPv4 subnet:
+// all addresses less the network and broadcast addresses, except for /31
+// point-to-point links and /32 single hosts, which use every address.
+func usableHosts(ipNet *net.IPNet) int {
+	ones, bits := ipNet.Mask.Size()
+	size := 1 << (bits - ones)
+	if size <= 2 {
+		return size
+	}
+	return size - 2
+}
+
+// isHostAddress reports whether ip is assignable in ipNet, i.e. not its
+// network or broadcast address. Every address of a /31 or /32 is assignable.
+func isHostAddress(ip net.IP, ipNet *net.IPNet) bool {
+	if ones, bits := ipNet.Mask.Size(); bits-ones <= 1 {
+		return true
+	}
+	ip4 := ip.To4()
+	network := ipNet.IP.To4()
+	broadcast := make(net.IP, net.IPv4len)
+	for i := range broadcast {
+		broadcast[i] = network[i] | ^ipNet.Mask[i]
+	}
+	return !ip4.Equal(network) && !ip4.Equal(broadcast)
+}
+
+// buildSubnetReport groups the unique IPv4 addresses in ips into subnets and
+// computes their utilization. With no configured subnets, the /24 of each
+// address is reported instead. An address is counted in every configured
+// subnet that contains it, so overlapping subnets each see their share.
+func buildSubnetReport(ips []string, configured []*net.IPNet, threshold float64) SubnetReport {
+	report := SubnetReport{Threshold: threshold}
+
+	seen := make(map[string]bool)
+	var addrs []net.IP
+	for _, s := range ips {
+		ip := net.ParseIP(s).To4()
+		if ip == nil || seen[ip.String()] {
+			continue
+		}
+		seen[ip.String()] = true
+		addrs = append(addrs, ip)
+	}
+
+	inferred := len(configured) == 0
+	subnets := configured
+	if inferred {
+		byKey := make(map[string]*net.IPNet)
+		for _, ip := range addrs {
+			ipNet := &net.IPNet{IP: ip.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}
+			byKey[ipNet.String()] = ipNet
+		}
+		subnets = make([]*net.IPNet, 0, len(byKey))
+		for _, ipNet := range byKey {
+			subnets = append(subnets, ipNet)
+		}
+	}
+
+	used := make([]int, len(subnets))
+	for _, ip := range addrs {
+		matched := false
+		for i, ipNet := range subnets {
+			if ipNet.Contains(ip) {
+				matched = true
+				if isHostAddress(ip, ipNet) {
+					used[i]++
+				}
+			}
+		}
+		if !matched {
+			report.Unassigned++
+		}
+	}
+
+	report.Subnets = make([]SubnetUtilization, 0, len(subnets))
+	for i, ipNet := range subnets {
+		total := usableHosts(ipNet)
+		u := SubnetUtilization{
+			Subnet:    ipNet.String(),
+			Total:     total,
+			Used:      used[i],
+			Available: total - used[i],
+			Inferred:  inferred,
+		}
+		if total > 0 {
+			u.Utilization = float64(used[i]) * 100 / float64(total)
+		}
+		u.OverThreshold = u.Utilization >= threshold
+		report.Subnets = append(report.Subnets, u)
+	}
+
+	sort.Slice(report.Subnets, func(a, b int) bool {
+		na, _, _ := net.ParseCIDR(report.Subnets[a].Subnet)
+		nb, _, _ := net.ParseCIDR(report.Subnets[b].Subnet)
+		if c := bytes.Compare(na.To4(), nb.To4()); c != 0 {
+			return c < 0
+		}
+		return report.Subnets[a].Total > report.Subnets[b].Total
+	})
+	return report
+}
+
+// handleSubnetReport reports address utilization per subnet.
+//
+//	@Summary		Subnet utilization
+//	@Description	Groups known device IPv4 addresses into the configured subnets (or their /24s when none are configured) and reports total, used, and available addresses. Subnets at or above the threshold are flagged.
+//	@Tags			recon
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			threshold	query		number	false	"Utilization percentage to flag (defaults to subnet_report.threshold)"
+//	@Success		200			{object}	SubnetReport
+//	@Failure		400			{object}	models.APIProblem
+//	@Failure		500			{object}	models.APIProblem
+//	@Router			/recon/subnets [get]
+func (m *Module) handleSubnetReport(w http.ResponseWriter, r *http.Request) {
+	threshold := m.cfg.SubnetReport.Threshold
+	if v := r.URL.Query().Get("threshold"); v != "" {
+		t, err := strconv.ParseFloat(v, 64)
+		if err != nil || t < 0 || t > 100 {
+			writeError(w, http.StatusBadRequest, "threshold must be a number between 0 and 100")
+			return
+		}
+		threshold = t
+	}
+
+	configured, err := parseReportSubnets(m.cfg.SubnetReport.Subnets)
+	if err != nil {
+		// ValidateConfig rejects bad subnets at startup, so this is unexpected.
+		m.logger.Error("invalid subnet_report.subnets", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "invalid subnet configuration")
+		return
+	}
+
+	devices, err := m.store.ListAllDevices(r.Context())
+	if err != nil {
+		m.logger.Error("failed to list devices for subnet report", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to list devices")
+		return
+	}
+	var ips []string
+	for i := range devices {
+		ips = append(ips, devices[i].IPAddresses...)
+	}
+
+	writeJSON(w, http.StatusOK, buildSubnetReport(ips, configured, threshold))
+}
@@ -0,0 +1,82 @@
+package recon
+
+import (
+	"testing"
+)
+
+func TestBuildSubnetReport_Inferred(t *testing.T) {
+	ips := []string{
+		"192.168.1.10", "192.168.1.11", "192.168.1.10", // duplicate counted once
+		"192.168.2.5",
+		"fe80::1", "not-an-ip",
+	}
+	report := buildSubnetReport(ips, nil, 1)
+
+	if len(report.Subnets) != 2 {
+		t.Fatalf("subnets = %d, want 2: %+v", len(report.Subnets), report.Subnets)
+	}
+	first := report.Subnets[0]
+	if first.Subnet != "192.168.1.0/24" || first.Total != 254 || first.Used != 2 || first.Available != 252 {
+		t.Errorf("first subnet = %+v, want 192.168.1.0/24 with 2 of 254 used", first)
+	}
+	if !first.Inferred {
+		t.Error("inferred = false, want true when no subnets are configured")
+	}
+	if first.OverThreshold {
+		t.Errorf("2/254 = %.2f%% flagged over a 1%% threshold", first.Utilization)
+	}
+	if report.Subnets[1].Subnet != "192.168.2.0/24" || report.Subnets[1].Used != 1 {
+		t.Errorf("second subnet = %+v, want 192.168.2.0/24 with 1 used", report.Subnets[1])
+	}
+}
+
+func TestBuildSubnetReport_Configured(t *testing.T) {
+	configured, err := parseReportSubnets([]string{"10.0.0.0/29", "10.0.0.0/30", "10.0.1.0/31"})
+	if err != nil {
+		t.Fatalf("parseReportSubnets: %v", err)
+	}
+	ips := []string{
+		"10.0.0.0", // network address, not a host
+		"10.0.0.1", "10.0.0.2", "10.0.0.6",
+		"10.0.0.7", // broadcast of the /29
+		"10.0.1.0", "10.0.1.1",
+		"172.16.0.1",
+	}
+	report := buildSubnetReport(ips, configured, 50)
+
+	want := map[string]struct{ total, used int }{
+		"10.0.0.0/29": {6, 3},
+		"10.0.0.0/30": {2, 2},
+		"10.0.1.0/31": {2, 2},
+	}
+	if len(report.Subnets) != len(want) {
+		t.Fatalf("subnets = %d, want %d", len(report.Subnets), len(want))
+	}
+	for _, s := range report.Subnets {
+		w, ok := want[s.Subnet]
+		if !ok {
+			t.Errorf("unexpected subnet %s", s.Subnet)
+			continue
+		}
+		if s.Total != w.total || s.Used != w.used {
+			t.Errorf("%s total/used = %d/%d, want %d/%d", s.Subnet, s.Total, s.Used, w.total, w.used)
+		}
+		if !s.OverThreshold {
+			t.Errorf("%s at %.1f%% not flagged over 50%%", s.Subnet, s.Utilization)
+		}
+	}
+	if report.Subnets[0].Subnet != "10.0.0.0/29" {
+		t.Errorf("first subnet = %s, want the wider 10.0.0.0/29 before 10.0.0.0/30", report.Subnets[0].Subnet)
+	}
+	if report.Unassigned != 1 {
+		t.Errorf("unassigned = %d, want 1", report.Unassigned)
+	}
+}
+
+func TestParseReportSubnets_Invalid(t *testing.T) {
+	for _, cidr := range []string{"10.0.0.0", "fd00::/64", "300.0.0.0/24"} {
+		if _, err := parseReportSubnets([]string{cidr}); err == nil {
+			t.Errorf("parseReportSubnets(%q) = nil error, want error", cidr)
+		}
+	}
+}
@@ -0,0 +1,400 @@
+package recon
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/HerbHall/subnetree/pkg/plugin"
+)
+
+const (
+	// syslogMaxMessage bounds a single syslog message (RFC 5425 recommends
+	// receivers accept at least 8 KiB).
+	syslogMaxMessage = 8192
+	// syslogTCPIdleTimeout closes TCP senders that go quiet.
+	syslogTCPIdleTimeout = 5 * time.Minute
+	// syslogMaxTCPConns bounds concurrent TCP senders.
+	syslogMaxTCPConns = 64
+)
+
+// Event levels used for device events, matching alert severities so the
+// dashboard and device docs can reuse their icons.
+const (
+	EventLevelCritical = "critical"
+	EventLevelWarning  = "warning"
+	EventLevelInfo     = "info"
+)
+
+// syslogSeverityNames are the RFC 5424 severity keywords, indexed by code.
+var syslogSeverityNames = [8]string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// syslogLevel maps a syslog severity code to an event level: emerg through
+// err are critical, warning is warning, and the rest are informational.
+func syslogLevel(severity int) string {
+	switch {
+	case severity <= 3:
+		return EventLevelCritical
+	case severity == 4:
+		return EventLevelWarning
+	default:
+		return EventLevelInfo
+	}
+}
+
+// syslogMessage is a parsed RFC 3164 or RFC 5424 message.
+type syslogMessage struct {
+	Facility  int
+	Severity  int
+	Timestamp time.Time
+	Hostname  string
+	AppName   string
+	Message   string
+}
+
+// parseSyslog parses a single syslog message. RFC 5424 is detected by its
+// version field; anything else is parsed leniently as RFC 3164, falling back
+// to treating everything after the priority as the message. received is used
+// when the message carries no usable timestamp.
+func parseSyslog(raw []byte, received time.Time) (*syslogMessage, error) {
+	s := strings.TrimRight(string(raw), "\r\n\x00")
+	if !strings.HasPrefix(s, "<") {
+		return nil, errors.New("missing priority")
+	}
+	end := strings.IndexByte(s, '>')
+	if end < 2 || end > 4 {
+		return nil, errors.New("malformed priority")
+	}
+	pri, err := strconv.Atoi(s[1:end])
+	if err != nil || pri < 0 || pri > 191 {
+		return nil, fmt.Errorf("invalid priority %q", s[1:end])
+	}
+	msg := &syslogMessage{Facility: pri / 8, Severity: pri % 8, Timestamp: received}
+	rest := s[end+1:]
+
+	if strings.HasPrefix(rest, "1 ") {
+		parse5424(msg, rest[2:])
+	} else {
+		parse3164(msg, rest, received)
+	}
+	msg.Message = strings.TrimSpace(msg.Message)
+	return msg, nil
+}
+
+// parse5424 parses "TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD MSG".
+func parse5424(msg *syslogMessage, rest string) {
+	fields := strings.SplitN(rest, " ", 6)
+	if len(fields) < 6 {
+		msg.Message = rest
+		return
+	}
+	if ts, err := time.Parse(time.RFC3339Nano, fields[0]); err == nil {
+		msg.Timestamp = ts
+	}
+	msg.Hostname = nilValue(fields[1])
+	msg.AppName = nilValue(fields[2])
+
+	// Skip structured data: "-" or one or more bracketed elements.
+	body := fields[5]
+	if strings.HasPrefix(body, "-") {
+		body = body[1:]
+	} else {
+		for strings.HasPrefix(body, "[") {
+			i := 1
+			for i < len(body) && body[i] != ']' {
+				if body[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(body) {
+				body = ""
+				break
+			}
+			body = body[i+1:]
+		}
+	}
+	msg.Message = strings.TrimPrefix(strings.TrimPrefix(body, " "), "\ufeff")
+}
+
+// parse3164 parses "Mmm dd hh:mm:ss HOSTNAME TAG: MSG".
+func parse3164(msg *syslogMessage, rest string, received time.Time) {
+	const stampLen = len(time.Stamp)
+	if len(rest) <= stampLen {
+		msg.Message = rest
+		return
+	}
+	ts, err := time.ParseInLocation(time.Stamp, rest[:stampLen], received.Location())
+	if err != nil {
+		msg.Message = rest
+		return
+	}
+	// RFC 3164 timestamps carry no year; assume the most recent one.
+	ts = ts.AddDate(received.Year(), 0, 0)
+	if ts.After(received.Add(24 * time.Hour)) {
+		ts = ts.AddDate(-1, 0, 0)
+	}
+	msg.Timestamp = ts
+
+	host, body, _ := strings.Cut(strings.TrimPrefix(rest[stampLen:], " "), " ")
+	msg.Hostname = host
+
+	// TAG is alphanumeric, optionally followed by "[pid]", then ":".
+	if i := strings.IndexByte(body, ':'); i > 0 && i <= 48 && !strings.ContainsAny(body[:i], " \t") {
+		tag := body[:i]
+		if j := strings.IndexByte(tag, '['); j > 0 {
+			tag = tag[:j]
+		}
+		msg.AppName = tag
+		body = body[i+1:]
+	}
+	msg.Message = body
+}
+
+func nilValue(s string) string {
+	if s == "-" {
+		return ""
+	}
+	return s
+}
+
+// SyslogListener receives syslog over UDP and TCP, matches each sender to a
+// device by IP, and stores the messages as device events.
+type SyslogListener struct {
+	store     *ReconStore
+	bus       plugin.EventBus
+	logger    *zap.Logger
+	addr      string
+	retention time.Duration
+
+	udp     net.PacketConn
+	tcp     net.Listener
+	conns   chan struct{} // TCP connection slots
+	devices *deviceIPCache
+}
+
+// NewSyslogListener creates a syslog listener for addr (host:port).
+func NewSyslogListener(store *ReconStore, bus plugin.EventBus, logger *zap.Logger, cfg SyslogConfig) *SyslogListener {
+	return &SyslogListener{
+		store:     store,
+		bus:       bus,
+		logger:    logger,
+		addr:      cfg.ListenAddr,
+		retention: cfg.Retention,
+		conns:     make(chan struct{}, syslogMaxTCPConns),
+		devices:   newDeviceIPCache(store, logger),
+	}
+}
+
+// Listen binds the UDP and TCP sockets.
+func (l *SyslogListener) Listen() error {
+	udp, err := net.ListenPacket("udp", l.addr)
+	if err != nil {
+		return fmt.Errorf("listen syslog udp %s: %w", l.addr, err)
+	}
+	tcp, err := net.Listen("tcp", l.addr)
+	if err != nil {
+		udp.Close()
+		return fmt.Errorf("listen syslog tcp %s: %w", l.addr, err)
+	}
+	l.udp, l.tcp = udp, tcp
+	return nil
+}
+
+// Run receives messages until ctx is cancelled. Listen must be called first.
+func (l *SyslogListener) Run(ctx context.Context) {
+	l.logger.Info("syslog listener started",
+		zap.String("udp_addr", l.udp.LocalAddr().String()),
+		zap.String("tcp_addr", l.tcp.Addr().String()),
+	)
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		l.serveUDP(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		l.serveTCP(ctx, &wg)
+	}()
+	go func() {
+		defer wg.Done()
+		runDeviceEventPruner(ctx, l.store, l.logger, "syslog", l.retention)
+	}()
+
+	<-ctx.Done()
+	l.udp.Close()
+	l.tcp.Close()
+	wg.Wait()
+	l.logger.Info("syslog listener stopped")
+}
+
+func (l *SyslogListener) serveUDP(ctx context.Context) {
+	buf := make([]byte, syslogMaxMessage)
+	for {
+		n, addr, err := l.udp.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() == nil {
+				l.logger.Warn("syslog udp read failed", zap.Error(err))
+			}
+			return
+		}
+		l.handle(ctx, buf[:n], hostIP(addr))
+	}
+}
+
+func (l *SyslogListener) serveTCP(ctx context.Context, wg *sync.WaitGroup) {
+	for {
+		conn, err := l.tcp.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				l.logger.Warn("syslog tcp accept failed", zap.Error(err))
+			}
+			return
+		}
+		select {
+		case l.conns <- struct{}{}:
+		default:
+			l.logger.Warn("syslog tcp connection limit reached; rejecting sender",
+				zap.String("remote", conn.RemoteAddr().String()))
+			conn.Close()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-l.conns }()
+			l.serveConn(ctx, conn)
+		}()
+	}
+}
+
+// serveConn reads octet-counted (RFC 6587 3.4.1) or newline-delimited
+// messages from a TCP sender.
+func (l *SyslogListener) serveConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	ip := hostIP(conn.RemoteAddr())
+	r := bufio.NewReaderSize(conn, syslogMaxMessage)
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(syslogTCPIdleTimeout))
+		frame, err := readSyslogFrame(r)
+		if len(frame) > 0 {
+			l.handle(ctx, frame, ip)
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) && ctx.Err() == nil {
+				l.logger.Debug("syslog tcp sender closed", zap.String("ip", ip), zap.Error(err))
+			}
+			return
+		}
+	}
+}
+
+// readSyslogFrame reads one message using octet counting when the frame
+// starts with a digit, and newline framing otherwise.
+func readSyslogFrame(r *bufio.Reader) ([]byte, error) {
+	first, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	if first[0] >= '1' && first[0] <= '9' {
+		lenStr, err := r.ReadString(' ')
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(lenStr, " "))
+		if err != nil || n <= 0 || n > syslogMaxMessage {
+			return nil, fmt.Errorf("invalid syslog frame length %q", lenStr)
+		}
+		frame := make([]byte, n)
+		if _, err := io.ReadFull(r, frame); err != nil {
+			return nil, err
+		}
+		return frame, nil
+	}
+	line, err := r.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		// Oversized line: keep the first chunk and discard the remainder.
+		frame := bytes.Clone(line)
+		for errors.Is(err, bufio.ErrBufferFull) {
+			_, err = r.ReadSlice('\n')
+		}
+		return frame, err
+	}
+	return bytes.Clone(line), err
+}
+
+// handle parses a message, matches its sender to a device, and stores it.
+// Messages from addresses with no known device are dropped.
+func (l *SyslogListener) handle(ctx context.Context, raw []byte, ip string) {
+	now := time.Now().UTC()
+	msg, err := parseSyslog(raw, now)
+	if err != nil {
+		l.logger.Debug("ignoring malformed syslog message", zap.String("ip", ip), zap.Error(err))
+		return
+	}
+
+	deviceID := l.devices.lookup(ctx, ip)
+	if deviceID == "" {
+		l.logger.Debug("ignoring syslog from unknown device", zap.String("ip", ip))
+		return
+	}
+
+	ev := &DeviceLogEvent{
+		DeviceID:     deviceID,
+		Source:       "syslog",
+		SourceIP:     ip,
+		Facility:     msg.Facility,
+		Severity:     msg.Severity,
+		SeverityName: syslogSeverityNames[msg.Severity],
+		Level:        syslogLevel(msg.Severity),
+		Hostname:     msg.Hostname,
+		AppName:      msg.AppName,
+		Message:      msg.Message,
+		Timestamp:    msg.Timestamp.UTC(),
+		ReceivedAt:   now,
+	}
+	if err := l.store.InsertDeviceLogEvent(ctx, ev); err != nil {
+		l.logger.Warn("failed to store syslog event", zap.String("device_id", deviceID), zap.Error(err))
+		return
+	}
+
+	topic := ""
+	switch ev.Level {
+	case EventLevelCritical:
+		topic = TopicDeviceSyslogCritical
+	case EventLevelWarning:
+		topic = TopicDeviceSyslogWarning
+	}
+	if topic != "" && l.bus != nil {
+		l.bus.PublishAsync(ctx, plugin.Event{
+			Topic:     topic,
+			Source:    "recon",
+			Timestamp: now,
+			Payload:   ev,
+		})
+	}
+}
+
+// hostIP returns the IP portion of a network address.
+func hostIP(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
@@ -0,0 +1,241 @@
+package recon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+	"go.uber.org/zap"
+)
+
+func TestParseSyslog(t *testing.T) {
+	received := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		raw      string
+		facility int
+		severity int
+		ts       time.Time
+		host     string
+		app      string
+		msg      string
+	}{
+		{
+			name:     "rfc5424",
+			raw:      "<165>1 2026-03-10T11:59:58.003Z router1 sshd 1234 ID47 - Failed password for root",
+			facility: 20, severity: 5,
+			ts:   time.Date(2026, 3, 10, 11, 59, 58, 3_000_000, time.UTC),
+			host: "router1", app: "sshd", msg: "Failed password for root",
+		},
+		{
+			name:     "rfc5424 structured data",
+			raw:      `<11>1 - nas - - - [exampleSDID@32473 iut="3" note="a\]b"][other@1 x="y"] disk failure`,
+			facility: 1, severity: 3,
+			ts:   received,
+			host: "nas", msg: "disk failure",
+		},
+		{
+			name:     "rfc3164",
+			raw:      "<34>Mar  9 22:14:15 switch01 kernel[0]: eth0 link down\n",
+			facility: 4, severity: 2,
+			ts:   time.Date(2026, 3, 9, 22, 14, 15, 0, time.UTC),
+			host: "switch01", app: "kernel", msg: "eth0 link down",
+		},
+		{
+			name:     "rfc3164 previous year",
+			raw:      "<12>Dec 31 23:59:59 ap1 hostapd: deauth",
+			facility: 1, severity: 4,
+			ts:   time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC),
+			host: "ap1", app: "hostapd", msg: "deauth",
+		},
+		{
+			name:     "bare message",
+			raw:      "<14>something happened",
+			facility: 1, severity: 6,
+			ts:  received,
+			msg: "something happened",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseSyslog([]byte(tc.raw), received)
+			if err != nil {
+				t.Fatalf("parseSyslog: %v", err)
+			}
+			if got.Facility != tc.facility || got.Severity != tc.severity {
+				t.Errorf("facility/severity = %d/%d, want %d/%d", got.Facility, got.Severity, tc.facility, tc.severity)
+			}
+			if !got.Timestamp.Equal(tc.ts) {
+				t.Errorf("timestamp = %v, want %v", got.Timestamp, tc.ts)
+			}
+			if got.Hostname != tc.host || got.AppName != tc.app || got.Message != tc.msg {
+				t.Errorf("host/app/msg = %q/%q/%q, want %q/%q/%q",
+					got.Hostname, got.AppName, got.Message, tc.host, tc.app, tc.msg)
+			}
+		})
+	}
+}
+
+func TestParseSyslog_Invalid(t *testing.T) {
+	for _, raw := range []string{"", "no priority", "<>x", "<999>x", "<ab>x"} {
+		if _, err := parseSyslog([]byte(raw), time.Now()); err == nil {
+			t.Errorf("parseSyslog(%q) succeeded, want error", raw)
+		}
+	}
+}
+
+func TestSyslogLevel(t *testing.T) {
+	want := []string{"critical", "critical", "critical", "critical", "warning", "info", "info", "info"}
+	for sev, w := range want {
+		if got := syslogLevel(sev); got != w {
+			t.Errorf("syslogLevel(%d) = %q, want %q", sev, got, w)
+		}
+	}
+}
+
+func TestReadSyslogFrame(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("11 <14>1 - a b\n<13>line two\n"))
+	frame, err := readSyslogFrame(r)
+	if err != nil || string(frame) != "<14>1 - a b" {
+		t.Fatalf("octet-counted frame = %q, %v", frame, err)
+	}
+	// The newline after the counted frame is an empty trailer line.
+	if frame, _ = readSyslogFrame(r); strings.TrimSpace(string(frame)) != "" {
+		t.Fatalf("trailer = %q", frame)
+	}
+	frame, err = readSyslogFrame(r)
+	if err != nil || string(frame) != "<13>line two\n" {
+		t.Fatalf("newline frame = %q, %v", frame, err)
+	}
+}
+
+func TestSyslogListener_StoresAndPublishes(t *testing.T) {
+	m, s, bus := setupTestModule(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d := &models.Device{
+		IPAddresses:     []string{"127.0.0.1"},
+		Status:          models.DeviceStatusOnline,
+		DiscoveryMethod: models.DiscoveryICMP,
+	}
+	if _, err := s.UpsertDevice(ctx, d); err != nil {
+		t.Fatalf("UpsertDevice: %v", err)
+	}
+
+	l := NewSyslogListener(s, bus, zap.NewNop(), SyslogConfig{ListenAddr: "127.0.0.1:0"})
+	if err := l.Listen(); err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		l.Run(ctx)
+		close(done)
+	}()
+
+	udp, err := net.Dial("udp", l.udp.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("dial udp: %v", err)
+	}
+	defer udp.Close()
+	fmt.Fprint(udp, "<11>Mar  9 22:14:15 nas smartd: disk failing")
+
+	tcp, err := net.Dial("tcp", l.tcp.Addr().String())
+	if err != nil {
+		t.Fatalf("dial tcp: %v", err)
+	}
+	fmt.Fprint(tcp, "<14>1 - nas app - - - backup done\n")
+	tcp.Close()
+
+	var events []DeviceLogEvent
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		events, err = s.ListDeviceLogEvents(ctx, d.ID, DeviceLogEventFilter{Limit: 10})
+		if err != nil {
+			t.Fatalf("ListDeviceLogEvents: %v", err)
+		}
+		if len(events) == 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(events) != 2 {
+		t.Fatalf("events = %d, want 2", len(events))
+	}
+
+	published := bus.Events()
+	if len(published) != 1 || published[0].Topic != TopicDeviceSyslogCritical {
+		t.Errorf("published = %+v, want one %s event", published, TopicDeviceSyslogCritical)
+	}
+
+	// The handler filters by level.
+	req := httptest.NewRequest(http.MethodGet, "/devices/"+d.ID+"/events?level=critical", http.NoBody)
+	req.SetPathValue("id", d.ID)
+	w := httptest.NewRecorder()
+	m.handleDeviceEvents(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var got []DeviceLogEvent
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got) != 1 || got[0].Message != "disk failing" || got[0].SeverityName != "err" {
+		t.Errorf("critical events = %+v", got)
+	}
+
+	cancel()
+	<-done
+}
+
+func TestSyslogListener_DropsUnknownSender(t *testing.T) {
+	s := testStore(t)
+	bus := &mockEventBus{}
+	l := NewSyslogListener(s, bus, zap.NewNop(), SyslogConfig{})
+
+	l.handle(context.Background(), []byte("<10>1 - - - - - - boom"), "10.9.9.9")
+
+	var n int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM recon_device_events").Scan(&n); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if n != 0 || len(bus.Events()) != 0 {
+		t.Errorf("stored %d events and published %d, want none", n, len(bus.Events()))
+	}
+}
+
+func TestPruneDeviceLogEvents(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+	d := &models.Device{IPAddresses: []string{"10.0.0.5"}, DiscoveryMethod: models.DiscoveryICMP}
+	if _, err := s.UpsertDevice(ctx, d); err != nil {
+		t.Fatalf("UpsertDevice: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, age := range []time.Duration{48 * time.Hour, time.Minute} {
+		ev := &DeviceLogEvent{
+			DeviceID: d.ID, Source: "syslog", Level: EventLevelInfo, Message: "m",
+			Timestamp: now.Add(-age), ReceivedAt: now.Add(-age),
+		}
+		if err := s.InsertDeviceLogEvent(ctx, ev); err != nil {
+			t.Fatalf("InsertDeviceLogEvent: %v", err)
+		}
+	}
+
+	n, err := s.PruneDeviceLogEvents(ctx, "syslog", now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("PruneDeviceLogEvents: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("pruned = %d, want 1", n)
+	}
+}
@@ -0,0 +1,266 @@
+package recon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+	"go.uber.org/zap"
+)
+
+// maxTableImportEntries bounds a single ARP/DHCP table import.
+const maxTableImportEntries = 10000
+
+// TableImportEntry is one row of a router ARP or DHCP table. Clients convert
+// their router's native format into this shape:
+//
+//   - dnsmasq leases file ("<expiry epoch> <mac> <ip> <hostname> <client-id>"):
+//     mac, ip, hostname ("*" means none), and expires_at from the epoch.
+//   - `ip neigh` output ("<ip> dev <if> lladdr <mac> <state>"): ip and mac;
+//     drop FAILED and INCOMPLETE rows, which have no lladdr.
+type TableImportEntry struct {
+	IP        string     `json:"ip" example:"192.168.1.50"`
+	MAC       string     `json:"mac" example:"00:1a:2b:3c:4d:5e"`
+	Hostname  string     `json:"hostname,omitempty" example:"laptop"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2026-01-15T10:30:00Z"` // DHCP lease expiry; expired leases are skipped
+}
+
+// TableImportRequest is the request body for POST /recon/import/arp and
+// POST /recon/import/dhcp.
+type TableImportRequest struct {
+	// RouterIP is the router that supplied the table. For DHCP imports it
+	// becomes the parent of every leased device; when omitted, the first
+	// usable address of the first lease's /24 is assumed.
+	RouterIP string             `json:"router_ip,omitempty" example:"192.168.1.1"`
+	Entries  []TableImportEntry `json:"entries"`
+}
+
+// TableImportResult summarizes an ARP or DHCP table import.
+type TableImportResult struct {
+	ImportResult
+	RouterID     string `json:"router_id,omitempty"`
+	LinksCreated int    `json:"links_created"`
+}
+
+// normalizeImportEntry validates an entry and canonicalizes its MAC to the
+// uppercase colon form used by the ARP reader.
+func normalizeImportEntry(e TableImportEntry) (TableImportEntry, error) {
+	e.IP = strings.TrimSpace(e.IP)
+	if net.ParseIP(e.IP) == nil {
+		return e, fmt.Errorf("invalid ip %q", e.IP)
+	}
+	mac, err := net.ParseMAC(strings.TrimSpace(e.MAC))
+	if err != nil || len(mac) != 6 {
+		return e, fmt.Errorf("invalid mac %q", e.MAC)
+	}
+	e.MAC = strings.ToUpper(mac.String())
+	e.Hostname = strings.TrimSpace(e.Hostname)
+	if e.Hostname == "*" {
+		e.Hostname = ""
+	}
+	return e, nil
+}
+
+// inferRouterIP returns the assumed gateway for ip: the first usable address
+// of its /24, matching the convention used for scan topology inference.
+func inferRouterIP(ip string) string {
+	_, ipNet, err := net.ParseCIDR(ip + "/24")
+	if err != nil {
+		return ""
+	}
+	return firstUsableIP(ipNet)
+}
+
+// importTable upserts the devices in entries. For DHCP imports it also
+// resolves the router and links every leased device to it.
+func (m *Module) importTable(ctx context.Context, req TableImportRequest, method models.DiscoveryMethod) (*TableImportResult, error) {
+	result := &TableImportResult{}
+	now := time.Now()
+
+	var router *models.Device
+	if method == models.DiscoveryDHCP {
+		routerIP := req.RouterIP
+		if routerIP == "" && len(req.Entries) > 0 {
+			routerIP = inferRouterIP(strings.TrimSpace(req.Entries[0].IP))
+		}
+		var err error
+		router, err = m.resolveImportRouter(ctx, routerIP, req.RouterIP != "")
+		if err != nil {
+			return nil, err
+		}
+		if router != nil {
+			result.RouterID = router.ID
+		}
+	}
+
+	for i := range req.Entries {
+		entry, err := normalizeImportEntry(req.Entries[i])
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("entry %d: %v", i+1, err))
+			result.Skipped++
+			continue
+		}
+		if entry.ExpiresAt != nil && entry.ExpiresAt.Before(now) {
+			result.Skipped++
+			continue
+		}
+
+		device := &models.Device{
+			Hostname:        entry.Hostname,
+			IPAddresses:     []string{entry.IP},
+			MACAddress:      entry.MAC,
+			DeviceType:      models.DeviceTypeUnknown,
+			DiscoveryMethod: method,
+		}
+		if m.oui != nil {
+			device.Manufacturer = m.oui.Lookup(entry.MAC)
+		}
+		created, err := m.store.UpsertDevice(ctx, device)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("entry %d: %v", i+1, err))
+			result.Skipped++
+			continue
+		}
+		if created {
+			result.Created++
+			m.publishEvent(ctx, TopicDeviceDiscovered, &DeviceEvent{Device: device})
+		} else {
+			result.Updated++
+			m.publishEvent(ctx, TopicDeviceUpdated, &DeviceEvent{Device: device})
+		}
+
+		if router == nil || device.ID == router.ID {
+			continue
+		}
+		link := &TopologyLink{
+			SourceDeviceID: device.ID,
+			TargetDeviceID: router.ID,
+			LinkType:       "dhcp",
+		}
+		if err := m.store.UpsertTopologyLink(ctx, link); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("entry %d: %v", i+1, err))
+			continue
+		}
+		result.LinksCreated++
+	}
+
+	// Re-derive parents now that leased devices link to the router.
+	if result.LinksCreated > 0 {
+		if err := NewHierarchyInferrer(m.store, m.logger).InferHierarchy(ctx); err != nil {
+			m.logger.Warn("hierarchy inference after DHCP import failed", zap.Error(err))
+		}
+	}
+
+	return result, nil
+}
+
+// resolveImportRouter finds the router device at ip. An explicitly named
+// router missing from the inventory is created; an inferred one is not.
+func (m *Module) resolveImportRouter(ctx context.Context, ip string, explicit bool) (*models.Device, error) {
+	if ip == "" {
+		return nil, nil
+	}
+	if net.ParseIP(ip) == nil {
+		return nil, fmt.Errorf("invalid router_ip %q", ip)
+	}
+	router, err := m.store.GetDeviceByIP(ctx, ip)
+	if err == nil && router != nil {
+		if explicit && router.DeviceType == models.DeviceTypeUnknown {
+			dt := string(models.DeviceTypeRouter)
+			if err := m.store.UpdateDevice(ctx, router.ID, UpdateDeviceParams{DeviceType: &dt}); err != nil {
+				return nil, fmt.Errorf("mark router device: %w", err)
+			}
+			router.DeviceType = models.DeviceTypeRouter
+		}
+		return router, nil
+	}
+	if !explicit {
+		return nil, nil
+	}
+
+	router = &models.Device{
+		IPAddresses:     []string{ip},
+		DeviceType:      models.DeviceTypeRouter,
+		DiscoveryMethod: models.DiscoveryDHCP,
+	}
+	if _, err := m.store.UpsertDevice(ctx, router); err != nil {
+		return nil, fmt.Errorf("create router device: %w", err)
+	}
+	return router, nil
+}
+
+// handleImportARP imports a router's ARP table.
+//
+//	@Summary		Import ARP table
+//	@Description	Upserts a device for each IP/MAC pair from a router's ARP table (e.g. parsed "ip neigh" output).
+//	@Tags			recon
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		TableImportRequest	true	"ARP entries"
+//	@Success		200		{object}	TableImportResult
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/recon/import/arp [post]
+func (m *Module) handleImportARP(w http.ResponseWriter, r *http.Request) {
+	m.handleTableImport(w, r, models.DiscoveryARP)
+}
+
+// handleImportDHCP imports a router's DHCP lease table.
+//
+//	@Summary		Import DHCP leases
+//	@Description	Upserts a device for each active lease (e.g. a parsed dnsmasq leases file) and links it to the router as its parent.
+//	@Tags			recon
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		TableImportRequest	true	"DHCP leases"
+//	@Success		200		{object}	TableImportResult
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		500		{object}	models.APIProblem
+//	@Router			/recon/import/dhcp [post]
+func (m *Module) handleImportDHCP(w http.ResponseWriter, r *http.Request) {
+	m.handleTableImport(w, r, models.DiscoveryDHCP)
+}
+
+func (m *Module) handleTableImport(w http.ResponseWriter, r *http.Request, method models.DiscoveryMethod) {
+	r.Body = http.MaxBytesReader(w, r.Body, 10<<20)
+
+	var req TableImportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if len(req.Entries) == 0 {
+		writeError(w, http.StatusBadRequest, "entries is required")
+		return
+	}
+	if len(req.Entries) > maxTableImportEntries {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("too many entries (max %d)", maxTableImportEntries))
+		return
+	}
+	if req.RouterIP != "" && net.ParseIP(req.RouterIP) == nil {
+		writeError(w, http.StatusBadRequest, "invalid router_ip")
+		return
+	}
+
+	result, err := m.importTable(r.Context(), req, method)
+	if err != nil {
+		m.logger.Error("table import failed", zap.String("method", string(method)), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "import failed: "+err.Error())
+		return
+	}
+
+	m.logger.Info("imported router table",
+		zap.String("method", string(method)),
+		zap.Int("created", result.Created),
+		zap.Int("updated", result.Updated),
+		zap.Int("skipped", result.Skipped),
+	)
+	writeJSON(w, http.StatusOK, result)
+}
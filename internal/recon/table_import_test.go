@@ -0,0 +1,151 @@
+package recon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+func postTableImport(t *testing.T, m *Module, path string, req TableImportRequest) (*httptest.ResponseRecorder, TableImportResult) {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /import/arp", m.handleImportARP)
+	mux.HandleFunc("POST /import/dhcp", m.handleImportDHCP)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", path, bytes.NewReader(body)))
+
+	var result TableImportResult
+	if w.Code == http.StatusOK {
+		if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+	}
+	return w, result
+}
+
+func TestHandleImportARP(t *testing.T) {
+	m := newTestModule(t)
+	ctx := context.Background()
+
+	existing := &models.Device{
+		Hostname:        "nas",
+		IPAddresses:     []string{"192.168.1.20"},
+		MACAddress:      "AA:BB:CC:00:00:20",
+		DeviceType:      models.DeviceTypeNAS,
+		DiscoveryMethod: models.DiscoveryICMP,
+	}
+	if _, err := m.store.UpsertDevice(ctx, existing); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	w, result := postTableImport(t, m, "/import/arp", TableImportRequest{Entries: []TableImportEntry{
+		{IP: "192.168.1.20", MAC: "aa-bb-cc-00-00-20"},
+		{IP: "192.168.1.30", MAC: "aa:bb:cc:00:00:30", Hostname: "*"},
+		{IP: "192.168.1.40", MAC: ""},
+	}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if result.Created != 1 || result.Updated != 1 || result.Skipped != 1 {
+		t.Errorf("created/updated/skipped = %d/%d/%d, want 1/1/1", result.Created, result.Updated, result.Skipped)
+	}
+	if len(result.Errors) != 1 {
+		t.Errorf("errors = %v, want one for the missing MAC", result.Errors)
+	}
+
+	d, err := m.store.GetDeviceByMAC(ctx, "AA:BB:CC:00:00:30")
+	if err != nil || d == nil {
+		t.Fatalf("imported device not found: %v", err)
+	}
+	if d.DiscoveryMethod != models.DiscoveryARP {
+		t.Errorf("discovery method = %q, want %q", d.DiscoveryMethod, models.DiscoveryARP)
+	}
+	if d.Hostname != "" {
+		t.Errorf("hostname = %q, want empty for \"*\"", d.Hostname)
+	}
+}
+
+func TestHandleImportDHCP_LinksRouter(t *testing.T) {
+	m := newTestModule(t)
+	ctx := context.Background()
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	w, result := postTableImport(t, m, "/import/dhcp", TableImportRequest{
+		RouterIP: "10.0.0.1",
+		Entries: []TableImportEntry{
+			{IP: "10.0.0.50", MAC: "AA:BB:CC:00:00:50", Hostname: "laptop", ExpiresAt: &future},
+			{IP: "10.0.0.51", MAC: "AA:BB:CC:00:00:51", Hostname: "old-phone", ExpiresAt: &past},
+		},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if result.Created != 1 || result.Skipped != 1 || result.LinksCreated != 1 {
+		t.Errorf("created/skipped/links = %d/%d/%d, want 1/1/1", result.Created, result.Skipped, result.LinksCreated)
+	}
+	if result.RouterID == "" {
+		t.Fatal("router_id is empty, want the created router")
+	}
+
+	router, err := m.store.GetDevice(ctx, result.RouterID)
+	if err != nil {
+		t.Fatalf("GetDevice(router): %v", err)
+	}
+	if router.DeviceType != models.DeviceTypeRouter {
+		t.Errorf("router type = %q, want router", router.DeviceType)
+	}
+
+	laptop, err := m.store.GetDeviceByMAC(ctx, "AA:BB:CC:00:00:50")
+	if err != nil || laptop == nil {
+		t.Fatalf("leased device not found: %v", err)
+	}
+	if laptop.DiscoveryMethod != models.DiscoveryDHCP {
+		t.Errorf("discovery method = %q, want dhcp", laptop.DiscoveryMethod)
+	}
+	if laptop.ParentDeviceID != router.ID {
+		t.Errorf("parent = %q, want router %q", laptop.ParentDeviceID, router.ID)
+	}
+}
+
+func TestHandleImportDHCP_InferredRouterMustExist(t *testing.T) {
+	m := newTestModule(t)
+
+	w, result := postTableImport(t, m, "/import/dhcp", TableImportRequest{Entries: []TableImportEntry{
+		{IP: "10.0.0.50", MAC: "AA:BB:CC:00:00:50"},
+	}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if result.RouterID != "" || result.LinksCreated != 0 {
+		t.Errorf("router_id = %q, links = %d; want no router when 10.0.0.1 is unknown", result.RouterID, result.LinksCreated)
+	}
+}
+
+func TestHandleTableImport_BadRequest(t *testing.T) {
+	m := newTestModule(t)
+
+	tests := []struct {
+		name string
+		req  TableImportRequest
+	}{
+		{"no entries", TableImportRequest{}},
+		{"bad router ip", TableImportRequest{RouterIP: "router", Entries: []TableImportEntry{{IP: "10.0.0.2", MAC: "AA:BB:CC:00:00:02"}}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w, _ := postTableImport(t, m, "/import/dhcp", tc.req)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package recon
+
+import (
+	"cmp"
+	"encoding/json"
+	"math"
+	"net/http"
+	"slices"
+
+	"go.uber.org/zap"
+)
+
+// Auto-layout algorithms.
+const (
+	LayoutHierarchical = "hierarchical"
+	LayoutForce        = "force"
+)
+
+// Auto-layout geometry, matching the dashboard's 180x60 device nodes.
+const (
+	layoutColumnPitch = 260.0 // node width plus horizontal gap
+	layoutRowPitch    = 210.0 // node height plus gap between layers
+	// layoutMaxRowNodes wraps wide layers (typically endpoints) onto
+	// extra rows so the graph stays roughly screen-shaped.
+	layoutMaxRowNodes = 24
+	// forceIterations is the number of force-directed simulation steps.
+	forceIterations = 300
+)
+
+// NodePosition is a node's placement in a topology layout, in the same
+// shape the dashboard stores in TopologyLayout.Positions.
+type NodePosition struct {
+	ID       string  `json:"id"`
+	Position XYPoint `json:"position"`
+}
+
+// XYPoint is a canvas coordinate.
+type XYPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// AutoLayoutResponse is a computed layout. It can be posted as-is to
+// POST /topology/layouts to save it.
+type AutoLayoutResponse struct {
+	Name      string `json:"name" example:"Auto (hierarchical)"`
+	Algorithm string `json:"algorithm" example:"hierarchical"`
+	Positions string `json:"positions"` // JSON array of {id, position: {x, y}}
+}
+
+// hierarchicalLayout places nodes in rows by network layer (gateways at the
+// top, endpoints at the bottom, unknown layers last). Within a row, nodes
+// follow a depth-first walk of the parent tree so children sit near their
+// parents. Rows wider than layoutMaxRowNodes wrap.
+func hierarchicalLayout(nodes []DeviceTreeNode) []NodePosition {
+	order := treeOrder(nodes)
+
+	byLayer := make(map[int][]DeviceTreeNode)
+	for _, n := range nodes {
+		layer := n.NetworkLayer
+		if layer <= 0 {
+			layer = math.MaxInt // unknown layers go last
+		}
+		byLayer[layer] = append(byLayer[layer], n)
+	}
+	layers := make([]int, 0, len(byLayer))
+	for l := range byLayer {
+		layers = append(layers, l)
+	}
+	slices.Sort(layers)
+
+	positions := make([]NodePosition, 0, len(nodes))
+	row := 0
+	for _, l := range layers {
+		members := byLayer[l]
+		slices.SortFunc(members, func(a, b DeviceTreeNode) int {
+			return cmp.Compare(order[a.ID], order[b.ID])
+		})
+		for start := 0; start < len(members); start += layoutMaxRowNodes {
+			chunk := members[start:min(start+layoutMaxRowNodes, len(members))]
+			offset := float64(len(chunk)-1) / 2
+			for i, n := range chunk {
+				positions = append(positions, NodePosition{
+					ID:       n.ID,
+					Position: XYPoint{X: (float64(i) - offset) * layoutColumnPitch, Y: float64(row) * layoutRowPitch},
+				})
+			}
+			row++
+		}
+	}
+	return positions
+}
+
+// treeOrder returns each node's index in a depth-first walk of the parent
+// tree, visiting roots and siblings by hostname then ID.
+func treeOrder(nodes []DeviceTreeNode) map[string]int {
+	known := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		known[n.ID] = true
+	}
+	children := make(map[string][]DeviceTreeNode)
+	var roots []DeviceTreeNode
+	for _, n := range nodes {
+		if n.ParentDeviceID == "" || !known[n.ParentDeviceID] || n.ParentDeviceID == n.ID {
+			roots = append(roots, n)
+		} else {
+			children[n.ParentDeviceID] = append(children[n.ParentDeviceID], n)
+		}
+	}
+	byName := func(a, b DeviceTreeNode) int {
+		return cmp.Or(cmp.Compare(a.Hostname, b.Hostname), cmp.Compare(a.ID, b.ID))
+	}
+
+	order := make(map[string]int, len(nodes))
+	var visit func(n DeviceTreeNode)
+	visit = func(n DeviceTreeNode) {
+		if _, seen := order[n.ID]; seen {
+			return
+		}
+		order[n.ID] = len(order)
+		kids := children[n.ID]
+		slices.SortFunc(kids, byName)
+		for _, c := range kids {
+			visit(c)
+		}
+	}
+	slices.SortFunc(roots, byName)
+	for _, r := range roots {
+		visit(r)
+	}
+	// Nodes caught in a parent cycle are not reachable from a root.
+	for _, n := range nodes {
+		visit(n)
+	}
+	return order
+}
+
+// forceLayout runs a Fruchterman-Reingold simulation over the topology
+// links and parent relationships. It starts from a circle in tree order, so
+// the result is deterministic for a given graph.
+func forceLayout(nodes []DeviceTreeNode, links []TopologyLink) []NodePosition {
+	n := len(nodes)
+	if n == 0 {
+		return []NodePosition{}
+	}
+	order := treeOrder(nodes)
+	sorted := slices.Clone(nodes)
+	slices.SortFunc(sorted, func(a, b DeviceTreeNode) int {
+		return cmp.Compare(order[a.ID], order[b.ID])
+	})
+	index := make(map[string]int, n)
+	for i, node := range sorted {
+		index[node.ID] = i
+	}
+
+	type edge struct{ a, b int }
+	seen := make(map[edge]bool)
+	var edges []edge
+	addEdge := func(from, to string) {
+		a, okA := index[from]
+		b, okB := index[to]
+		if !okA || !okB || a == b {
+			return
+		}
+		if a > b {
+			a, b = b, a
+		}
+		if e := (edge{a, b}); !seen[e] {
+			seen[e] = true
+			edges = append(edges, e)
+		}
+	}
+	for _, l := range links {
+		addEdge(l.SourceDeviceID, l.TargetDeviceID)
+	}
+	for _, node := range sorted {
+		addEdge(node.ParentDeviceID, node.ID)
+	}
+
+	// k is the ideal edge length; the area scales so k stays near one
+	// node pitch regardless of graph size.
+	k := layoutColumnPitch
+	side := k * math.Sqrt(float64(n))
+	x := make([]float64, n)
+	y := make([]float64, n)
+	for i := range sorted {
+		angle := 2 * math.Pi * float64(i) / float64(n)
+		x[i] = side / 2 * math.Cos(angle)
+		y[i] = side / 2 * math.Sin(angle)
+	}
+
+	dx := make([]float64, n)
+	dy := make([]float64, n)
+	temp := side / 10
+	cool := temp / float64(forceIterations+1)
+	for iter := 0; iter < forceIterations; iter++ {
+		clear(dx)
+		clear(dy)
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				ddx, ddy := x[i]-x[j], y[i]-y[j]
+				dist := math.Max(math.Hypot(ddx, ddy), 1)
+				f := k * k / dist
+				fx, fy := ddx/dist*f, ddy/dist*f
+				dx[i] += fx
+				dy[i] += fy
+				dx[j] -= fx
+				dy[j] -= fy
+			}
+		}
+		for _, e := range edges {
+			ddx, ddy := x[e.a]-x[e.b], y[e.a]-y[e.b]
+			dist := math.Max(math.Hypot(ddx, ddy), 1)
+			f := dist * dist / k
+			fx, fy := ddx/dist*f, ddy/dist*f
+			dx[e.a] -= fx
+			dy[e.a] -= fy
+			dx[e.b] += fx
+			dy[e.b] += fy
+		}
+		for i := 0; i < n; i++ {
+			disp := math.Hypot(dx[i], dy[i])
+			if disp > 0 {
+				step := math.Min(disp, temp)
+				x[i] += dx[i] / disp * step
+				y[i] += dy[i] / disp * step
+			}
+		}
+		temp -= cool
+	}
+
+	// Shift so the top-left node sits at the origin, matching the
+	// hierarchical layout's coordinate range.
+	minX, minY := slices.Min(x), slices.Min(y)
+	positions := make([]NodePosition, n)
+	for i, node := range sorted {
+		positions[i] = NodePosition{
+			ID:       node.ID,
+			Position: XYPoint{X: math.Round(x[i] - minX), Y: math.Round(y[i] - minY)},
+		}
+	}
+	return positions
+}
+
+// handleAutoLayout computes topology node positions server-side.
+//
+//	@Summary		Compute topology layout
+//	@Description	Computes node positions for all devices. "hierarchical" stacks devices in rows by network layer with children near their parents; "force" runs a force-directed simulation over topology links. The response can be saved with POST /recon/topology/layouts.
+//	@Tags			recon
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			algorithm	query		string	false	"Layout algorithm"	Enums(hierarchical, force)	default(hierarchical)
+//	@Success		200			{object}	AutoLayoutResponse
+//	@Failure		400			{object}	models.APIProblem
+//	@Failure		500			{object}	models.APIProblem
+//	@Router			/recon/topology/layout/auto [get]
+func (m *Module) handleAutoLayout(w http.ResponseWriter, r *http.Request) {
+	algorithm := r.URL.Query().Get("algorithm")
+	if algorithm == "" {
+		algorithm = LayoutHierarchical
+	}
+	if algorithm != LayoutHierarchical && algorithm != LayoutForce {
+		writeError(w, http.StatusBadRequest, `algorithm must be "hierarchical" or "force"`)
+		return
+	}
+
+	nodes, err := m.store.GetDeviceTree(r.Context())
+	if err != nil {
+		m.logger.Error("failed to get device tree for layout", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to compute layout")
+		return
+	}
+
+	var positions []NodePosition
+	if algorithm == LayoutForce {
+		links, err := m.store.GetTopologyLinks(r.Context())
+		if err != nil {
+			m.logger.Error("failed to get topology links for layout", zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "failed to compute layout")
+			return
+		}
+		positions = forceLayout(nodes, links)
+	} else {
+		positions = hierarchicalLayout(nodes)
+	}
+
+	data, err := json.Marshal(positions)
+	if err != nil {
+		m.logger.Error("failed to marshal layout", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to compute layout")
+		return
+	}
+	writeJSON(w, http.StatusOK, AutoLayoutResponse{
+		Name:      "Auto (" + algorithm + ")",
+		Algorithm: algorithm,
+		Positions: string(data),
+	})
+}
@@ -0,0 +1,171 @@
+package recon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+// layoutTestTree is a gateway with two switches, each with endpoints, plus
+// a device whose layer is unknown.
+func layoutTestTree() []DeviceTreeNode {
+	return []DeviceTreeNode{
+		{ID: "gw", Hostname: "gateway", NetworkLayer: 1},
+		{ID: "sw-b", Hostname: "switch-b", NetworkLayer: 3, ParentDeviceID: "gw"},
+		{ID: "sw-a", Hostname: "switch-a", NetworkLayer: 3, ParentDeviceID: "gw"},
+		{ID: "pc-b1", Hostname: "pc-b1", NetworkLayer: 4, ParentDeviceID: "sw-b"},
+		{ID: "pc-a1", Hostname: "pc-a1", NetworkLayer: 4, ParentDeviceID: "sw-a"},
+		{ID: "pc-a2", Hostname: "pc-a2", NetworkLayer: 4, ParentDeviceID: "sw-a"},
+		{ID: "mystery", Hostname: "mystery", NetworkLayer: 0},
+	}
+}
+
+func positionMap(positions []NodePosition) map[string]XYPoint {
+	m := make(map[string]XYPoint, len(positions))
+	for _, p := range positions {
+		m[p.ID] = p.Position
+	}
+	return m
+}
+
+func TestHierarchicalLayout(t *testing.T) {
+	pos := positionMap(hierarchicalLayout(layoutTestTree()))
+	if len(pos) != 7 {
+		t.Fatalf("positioned %d nodes, want 7", len(pos))
+	}
+
+	// Rows follow network_layer, with unknown last.
+	for id, row := range map[string]int{"gw": 0, "sw-a": 1, "sw-b": 1, "pc-a1": 2, "pc-a2": 2, "pc-b1": 2, "mystery": 3} {
+		if want := float64(row) * layoutRowPitch; pos[id].Y != want {
+			t.Errorf("%s y = %v, want %v", id, pos[id].Y, want)
+		}
+	}
+	// Siblings are ordered by hostname and children follow their parent.
+	if !(pos["sw-a"].X < pos["sw-b"].X) {
+		t.Errorf("switch-a x = %v, want left of switch-b x = %v", pos["sw-a"].X, pos["sw-b"].X)
+	}
+	if !(pos["pc-a1"].X < pos["pc-a2"].X && pos["pc-a2"].X < pos["pc-b1"].X) {
+		t.Errorf("endpoint order = %v, %v, %v; want switch-a's children before switch-b's",
+			pos["pc-a1"].X, pos["pc-a2"].X, pos["pc-b1"].X)
+	}
+	// Rows are centred on x = 0.
+	if pos["gw"].X != 0 || pos["sw-a"].X != -pos["sw-b"].X {
+		t.Errorf("rows not centred: gw=%v sw-a=%v sw-b=%v", pos["gw"].X, pos["sw-a"].X, pos["sw-b"].X)
+	}
+}
+
+func TestHierarchicalLayout_WrapsWideLayers(t *testing.T) {
+	nodes := []DeviceTreeNode{{ID: "gw", NetworkLayer: 1}}
+	for i := 0; i < layoutMaxRowNodes+5; i++ {
+		nodes = append(nodes, DeviceTreeNode{ID: fmt.Sprintf("ep-%02d", i), Hostname: fmt.Sprintf("ep-%02d", i), NetworkLayer: 4, ParentDeviceID: "gw"})
+	}
+	rows := make(map[float64]int)
+	for _, p := range hierarchicalLayout(nodes) {
+		rows[p.Position.Y]++
+	}
+	if len(rows) != 3 || rows[layoutRowPitch] != layoutMaxRowNodes || rows[2*layoutRowPitch] != 5 {
+		t.Errorf("row sizes = %v, want 1, %d, and 5", rows, layoutMaxRowNodes)
+	}
+}
+
+func TestTreeOrder_ParentCycle(t *testing.T) {
+	order := treeOrder([]DeviceTreeNode{
+		{ID: "a", ParentDeviceID: "b"},
+		{ID: "b", ParentDeviceID: "a"},
+		{ID: "c"},
+	})
+	if len(order) != 3 {
+		t.Errorf("treeOrder covered %d nodes, want 3: %v", len(order), order)
+	}
+}
+
+func TestForceLayout(t *testing.T) {
+	nodes := layoutTestTree()
+	links := []TopologyLink{{SourceDeviceID: "mystery", TargetDeviceID: "sw-b"}}
+	positions := forceLayout(nodes, links)
+	pos := positionMap(positions)
+	if len(pos) != len(nodes) {
+		t.Fatalf("positioned %d nodes, want %d", len(pos), len(nodes))
+	}
+
+	minX, minY := math.Inf(1), math.Inf(1)
+	for id, p := range pos {
+		if math.IsNaN(p.X) || math.IsNaN(p.Y) {
+			t.Fatalf("%s position is NaN", id)
+		}
+		minX, minY = math.Min(minX, p.X), math.Min(minY, p.Y)
+	}
+	if minX != 0 || minY != 0 {
+		t.Errorf("layout origin = (%v, %v), want (0, 0)", minX, minY)
+	}
+
+	// Connected nodes end up closer than unconnected ones.
+	dist := func(a, b string) float64 { return math.Hypot(pos[a].X-pos[b].X, pos[a].Y-pos[b].Y) }
+	if dist("pc-a1", "sw-a") >= dist("pc-a1", "pc-b1") {
+		t.Errorf("pc-a1 is %v from its switch but %v from pc-b1", dist("pc-a1", "sw-a"), dist("pc-a1", "pc-b1"))
+	}
+
+	// Deterministic for the same input.
+	again := positionMap(forceLayout(nodes, links))
+	for id, p := range pos {
+		if again[id] != p {
+			t.Errorf("%s moved between runs: %v then %v", id, p, again[id])
+		}
+	}
+
+	if got := forceLayout(nil, nil); len(got) != 0 {
+		t.Errorf("empty graph layout = %v", got)
+	}
+}
+
+func TestHandleAutoLayout(t *testing.T) {
+	m := newTestModule(t)
+	ctx := context.Background()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /topology/layout/auto", m.handleAutoLayout)
+
+	for i := 1; i <= 3; i++ {
+		d := &models.Device{
+			Hostname: fmt.Sprintf("host-%d", i), IPAddresses: []string{fmt.Sprintf("10.0.0.%d", i)},
+			MACAddress: fmt.Sprintf("AA:BB:CC:00:30:%02X", i), Status: models.DeviceStatusOnline,
+			DiscoveryMethod: models.DiscoveryICMP,
+		}
+		if _, err := m.store.UpsertDevice(ctx, d); err != nil {
+			t.Fatalf("UpsertDevice: %v", err)
+		}
+	}
+
+	for _, algorithm := range []string{"", LayoutHierarchical, LayoutForce} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "/topology/layout/auto?algorithm="+algorithm, http.NoBody))
+		if w.Code != http.StatusOK {
+			t.Fatalf("algorithm %q status = %d: %s", algorithm, w.Code, w.Body.String())
+		}
+		var resp AutoLayoutResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		var positions []NodePosition
+		if err := json.Unmarshal([]byte(resp.Positions), &positions); err != nil {
+			t.Fatalf("positions is not a JSON array: %v", err)
+		}
+		if len(positions) != 3 {
+			t.Errorf("algorithm %q positioned %d nodes, want 3", algorithm, len(positions))
+		}
+		if algorithm == "" && resp.Algorithm != LayoutHierarchical {
+			t.Errorf("default algorithm = %q, want hierarchical", resp.Algorithm)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/topology/layout/auto?algorithm=circular", http.NoBody))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("unknown algorithm status = %d, want 400", w.Code)
+	}
+}
@@ -25,13 +25,20 @@ type WiFiClientSnapshot struct {
 
 // UpsertWiFiClient inserts or replaces a WiFi client snapshot.
 func (s *ReconStore) UpsertWiFiClient(ctx context.Context, snap *WiFiClientSnapshot) error {
-	_, err := s.db.ExecContext(ctx, `INSERT OR REPLACE INTO recon_wifi_clients (
+	_, err := s.db.ExecContext(ctx, `INSERT INTO recon_wifi_clients (
 		device_id, signal_dbm, signal_avg_dbm,
 		connected_sec, inactive_sec,
 		rx_bitrate_bps, tx_bitrate_bps,
 		rx_bytes, tx_bytes,
 		ap_bssid, ap_ssid, collected_at
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(device_id) DO UPDATE SET
+		signal_dbm = excluded.signal_dbm, signal_avg_dbm = excluded.signal_avg_dbm,
+		connected_sec = excluded.connected_sec, inactive_sec = excluded.inactive_sec,
+		rx_bitrate_bps = excluded.rx_bitrate_bps, tx_bitrate_bps = excluded.tx_bitrate_bps,
+		rx_bytes = excluded.rx_bytes, tx_bytes = excluded.tx_bytes,
+		ap_bssid = excluded.ap_bssid, ap_ssid = excluded.ap_ssid,
+		collected_at = excluded.collected_at`,
 		snap.DeviceID, snap.SignalDBm, snap.SignalAvgDBm,
 		snap.ConnectedSec, snap.InactiveSec,
 		snap.RxBitrate, snap.TxBitrate,
@@ -275,6 +275,45 @@ func (r *Registry) StopAll(ctx context.Context) {
 	}
 }
 
+// Reloadable reports whether the named plugin is active and supports config
+// hot-reload.
+func (r *Registry) Reloadable(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.disabled[name] {
+		return false
+	}
+	_, ok := r.plugins[name].(plugin.Reloadable)
+	return ok
+}
+
+// Reload applies a new configuration to a running plugin. Returns an error
+// if the plugin is not active or does not implement plugin.Reloadable.
+func (r *Registry) Reload(ctx context.Context, name string, cfg plugin.Config) (err error) {
+	r.mu.RLock()
+	p, ok := r.plugins[name]
+	disabled := r.disabled[name]
+	r.mu.RUnlock()
+
+	if !ok || disabled {
+		return fmt.Errorf("plugin %q is not active", name)
+	}
+	rp, ok := p.(plugin.Reloadable)
+	if !ok {
+		return fmt.Errorf("plugin %q does not support reload", name)
+	}
+
+	r.logger.Info("reloading plugin config", zap.String("name", name))
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("plugin panicked during Reload: %v", rec)
+			r.logger.Error("plugin panic recovered during Reload",
+				zap.String("plugin", name), zap.Any("panic", rec))
+		}
+	}()
+	return rp.Reload(ctx, cfg)
+}
+
 // Get returns a plugin by name.
 func (r *Registry) Get(name string) (plugin.Plugin, bool) {
 	r.mu.RLock()
@@ -890,3 +890,50 @@ func TestStopAll_ConcurrentSafety(t *testing.T) {
 		t.Errorf("stop count = %d, want 3", stopCount)
 	}
 }
+
+// reloadPlugin records the configs passed to Reload.
+type reloadPlugin struct {
+	*testPlugin
+	reloaded []plugin.Config
+}
+
+func (p *reloadPlugin) Reload(_ context.Context, cfg plugin.Config) error {
+	p.reloaded = append(p.reloaded, cfg)
+	return nil
+}
+
+func TestReload(t *testing.T) {
+	reg := New(testLogger())
+	rp := &reloadPlugin{testPlugin: newTestPlugin("reloadable")}
+	reg.Register(rp)
+	reg.Register(newTestPlugin("static"))
+	broken := newTestPlugin("broken")
+	broken.initErr = errors.New("init failed")
+	reg.Register(&reloadPlugin{testPlugin: broken})
+	reg.Validate()
+
+	ctx := context.Background()
+	reg.InitAll(ctx, testDeps())
+
+	if !reg.Reloadable("reloadable") {
+		t.Error("Reloadable(reloadable) = false, want true")
+	}
+	if reg.Reloadable("static") {
+		t.Error("Reloadable(static) = true, want false")
+	}
+	if reg.Reloadable("broken") {
+		t.Error("Reloadable(broken) = true for a disabled plugin, want false")
+	}
+
+	if err := reg.Reload(ctx, "reloadable", nil); err != nil {
+		t.Fatalf("Reload(reloadable) error = %v", err)
+	}
+	if len(rp.reloaded) != 1 {
+		t.Errorf("Reload called %d times, want 1", len(rp.reloaded))
+	}
+	for _, name := range []string{"static", "broken", "missing"} {
+		if err := reg.Reload(ctx, name, nil); err == nil {
+			t.Errorf("Reload(%s) error = nil, want error", name)
+		}
+	}
+}
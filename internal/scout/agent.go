@@ -12,10 +12,12 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
 	"github.com/HerbHall/subnetree/internal/ca"
+	"github.com/HerbHall/subnetree/internal/scout/eventlog"
 	"github.com/HerbHall/subnetree/internal/scout/metrics"
 	"github.com/HerbHall/subnetree/internal/scout/profiler"
 	"github.com/HerbHall/subnetree/internal/scout/restarter"
@@ -37,8 +39,12 @@ type Agent struct {
 	client    scoutpb.ScoutServiceClient
 	collector metrics.Collector
 	profiler  *profiler.Profiler
+	events    *eventlog.Collector
 	restarter restarter.Restarter
 	updater   *updater.Updater
+
+	// commandsRunning guards against overlapping command streams.
+	commandsRunning atomic.Bool
 }
 
 // NewAgent creates a new Scout agent instance.
@@ -49,6 +55,18 @@ func NewAgent(config *Config, logger *zap.Logger) *Agent {
 		collector: metrics.NewCollector(logger),
 		profiler:  profiler.NewProfiler(logger.Named("profiler")),
 	}
+	if len(config.EventChannels) > 0 {
+		events, err := eventlog.NewCollector(eventlog.Config{
+			Channels: config.EventChannels,
+			Levels:   config.EventLevels,
+			Sources:  config.EventSources,
+		}, logger.Named("eventlog"))
+		if err != nil {
+			logger.Warn("event log forwarding disabled", zap.Error(err))
+		} else {
+			a.events = events
+		}
+	}
 	if config.AutoRestart {
 		a.restarter = restarter.Detect()
 		if a.restarter != nil {
@@ -119,6 +137,11 @@ func (a *Agent) Run(ctx context.Context) error {
 	profileTicker := time.NewTicker(profileInterval)
 	defer profileTicker.Stop()
 
+	// Event log forwarding: new entries since the previous read.
+	const eventInterval = time.Minute
+	eventTicker := time.NewTicker(eventInterval)
+	defer eventTicker.Stop()
+
 	// Initial check-in.
 	a.checkIn(ctx)
 
@@ -134,6 +157,8 @@ func (a *Agent) Run(ctx context.Context) error {
 			a.checkIn(ctx)
 		case <-profileTicker.C:
 			a.collectAndSendProfile(ctx)
+		case <-eventTicker.C:
+			a.forwardEvents(ctx)
 		}
 	}
 }
@@ -432,6 +457,11 @@ func (a *Agent) checkIn(ctx context.Context) {
 		go a.applyUpdate(ctx, resp.UpdateUrl)
 	}
 
+	// Run any remote commands the server has queued for this agent.
+	if len(resp.PendingCommands) > 0 {
+		go a.runPendingCommands(ctx)
+	}
+
 	// Handle certificate renewal response.
 	if renewalKey != nil && len(resp.SignedCertificate) > 0 {
 		// Save new private key first (atomic swap: key then cert).
@@ -559,6 +589,39 @@ func (a *Agent) collectAndSendProfile(ctx context.Context) {
 	)
 }
 
+// forwardEvents sends event log entries written since the last forward.
+// Entries are read again on the next tick if the report fails.
+func (a *Agent) forwardEvents(ctx context.Context) {
+	if a.events == nil || a.client == nil {
+		return
+	}
+
+	events, err := a.events.Collect(ctx)
+	if err != nil {
+		a.logger.Warn("event log collection failed", zap.Error(err))
+		return
+	}
+	if len(events) == 0 {
+		a.events.Commit()
+		return
+	}
+
+	ack, err := a.client.ReportEvents(ctx, &scoutpb.EventReport{
+		AgentId: a.config.AgentID,
+		Events:  events,
+	})
+	if err != nil {
+		a.logger.Warn("event report failed", zap.Error(err))
+		return
+	}
+	if !ack.GetSuccess() {
+		a.logger.Warn("event report not acknowledged")
+		return
+	}
+	a.events.Commit()
+	a.logger.Debug("events reported", zap.Int("count", len(events)))
+}
+
 // Agent ID persistence -- simple JSON file in config directory.
 type agentState struct {
 	AgentID string `json:"agent_id"`
@@ -0,0 +1,135 @@
+package scout
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime"
+	"time"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+	probing "github.com/prometheus-community/pro-bing"
+	"go.uber.org/zap"
+)
+
+// checkPingCount is the number of echo requests sent by an ICMP check.
+const checkPingCount = 3
+
+// handleCheckCommand runs a monitoring check requested by the server so the
+// result reflects this agent's network location rather than the server's.
+// A failed check is a successful command; the outcome is in the output.
+func (a *Agent) handleCheckCommand(ctx context.Context, cmd *scoutpb.Command) *scoutpb.CommandResponse {
+	resp := &scoutpb.CommandResponse{CommandId: cmd.GetId()}
+
+	var payload scoutpb.CheckPayload
+	if err := json.Unmarshal(cmd.GetPayload(), &payload); err != nil {
+		resp.Error = fmt.Sprintf("invalid check payload: %v", err)
+		return resp
+	}
+
+	a.logger.Debug("running remote check",
+		zap.String("command_id", cmd.GetId()),
+		zap.String("check_type", payload.CheckType),
+		zap.String("target", payload.Target),
+	)
+	out, err := runCheck(ctx, payload)
+	if err != nil {
+		resp.Error = err.Error()
+		return resp
+	}
+	resp.Success = true
+	resp.Output, _ = json.Marshal(out)
+	return resp
+}
+
+// runCheck runs an icmp, tcp, or http check with the payload's timeout. It
+// mirrors the server's Pulse checkers: tcp succeeds on connect, http on a
+// 2xx response, and icmp when any echo reply is received. An error is
+// returned only for an unsupported check type.
+func runCheck(ctx context.Context, payload scoutpb.CheckPayload) (scoutpb.CheckOutput, error) {
+	timeout := time.Duration(payload.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	switch payload.CheckType {
+	case "icmp", "":
+		return runICMPCheck(ctx, payload.Target, timeout), nil
+	case "tcp":
+		return runTCPCheck(ctx, payload.Target, timeout), nil
+	case "http":
+		return runHTTPCheck(ctx, payload.Target, timeout), nil
+	default:
+		return scoutpb.CheckOutput{}, fmt.Errorf("unsupported check type %q", payload.CheckType)
+	}
+}
+
+func runICMPCheck(ctx context.Context, target string, timeout time.Duration) scoutpb.CheckOutput {
+	pinger, err := probing.NewPinger(target)
+	if err != nil {
+		return scoutpb.CheckOutput{PacketLoss: 1, Error: fmt.Sprintf("create pinger: %v", err)}
+	}
+	pinger.Count = checkPingCount
+	pinger.Timeout = timeout
+	pinger.SetPrivileged(runtime.GOOS == "windows")
+
+	if err := pinger.RunWithContext(ctx); err != nil {
+		return scoutpb.CheckOutput{PacketLoss: 1, Error: err.Error()}
+	}
+	stats := pinger.Statistics()
+	out := scoutpb.CheckOutput{
+		Success:    stats.PacketsRecv > 0,
+		LatencyMs:  float64(stats.AvgRtt) / float64(time.Millisecond),
+		PacketLoss: stats.PacketLoss / 100.0, // pro-bing returns 0-100
+	}
+	if !out.Success {
+		out.Error = "all packets lost"
+	}
+	return out
+}
+
+func runTCPCheck(ctx context.Context, target string, timeout time.Duration) scoutpb.CheckOutput {
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		return scoutpb.CheckOutput{Error: fmt.Sprintf("invalid target %q: %v", target, err)}
+	}
+
+	start := time.Now()
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", target)
+	latency := float64(time.Since(start)) / float64(time.Millisecond)
+	if err != nil {
+		return scoutpb.CheckOutput{LatencyMs: latency, Error: err.Error()}
+	}
+	conn.Close()
+	return scoutpb.CheckOutput{Success: true, LatencyMs: latency}
+}
+
+func runHTTPCheck(ctx context.Context, target string, timeout time.Duration) scoutpb.CheckOutput {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, http.NoBody)
+	if err != nil {
+		return scoutpb.CheckOutput{Error: fmt.Sprintf("invalid URL %q: %v", target, err)}
+	}
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: true}, //nolint:gosec // G402: monitoring must work with self-signed certs
+			DisableKeepAlives: true,
+		},
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	latency := float64(time.Since(start)) / float64(time.Millisecond)
+	if err != nil {
+		return scoutpb.CheckOutput{LatencyMs: latency, Error: err.Error()}
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return scoutpb.CheckOutput{LatencyMs: latency, Error: fmt.Sprintf("HTTP %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))}
+	}
+	return scoutpb.CheckOutput{Success: true, LatencyMs: latency}
+}
@@ -0,0 +1,66 @@
+package scout
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestRunCheck_TCPAndHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	out, err := runCheck(context.Background(), scoutpb.CheckPayload{CheckType: "http", Target: srv.URL, TimeoutSeconds: 5})
+	require.NoError(t, err)
+	assert.True(t, out.Success)
+	assert.Empty(t, out.Error)
+
+	out, err = runCheck(context.Background(), scoutpb.CheckPayload{CheckType: "http", Target: srv.URL + "/down", TimeoutSeconds: 5})
+	require.NoError(t, err)
+	assert.False(t, out.Success)
+	assert.Contains(t, out.Error, "503")
+
+	out, err = runCheck(context.Background(), scoutpb.CheckPayload{CheckType: "tcp", Target: strings.TrimPrefix(srv.URL, "http://"), TimeoutSeconds: 5})
+	require.NoError(t, err)
+	assert.True(t, out.Success)
+
+	out, err = runCheck(context.Background(), scoutpb.CheckPayload{CheckType: "tcp", Target: "no-port"})
+	require.NoError(t, err)
+	assert.False(t, out.Success)
+	assert.Contains(t, out.Error, "invalid target")
+
+	_, err = runCheck(context.Background(), scoutpb.CheckPayload{CheckType: "dns", Target: "example.com"})
+	assert.Error(t, err)
+}
+
+func TestHandleCommand_Check(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+
+	a := &Agent{logger: zaptest.NewLogger(t)}
+	payload, err := json.Marshal(scoutpb.CheckPayload{CheckType: "http", Target: srv.URL, TimeoutSeconds: 5})
+	require.NoError(t, err)
+
+	resp := a.handleCommand(context.Background(), &scoutpb.Command{Id: "c1", Type: scoutpb.CommandTypeCheck, Payload: payload})
+	assert.Equal(t, "c1", resp.CommandId)
+	assert.True(t, resp.Success)
+	var out scoutpb.CheckOutput
+	require.NoError(t, json.Unmarshal(resp.Output, &out))
+	assert.True(t, out.Success)
+
+	resp = a.handleCommand(context.Background(), &scoutpb.Command{Id: "c2", Type: scoutpb.CommandTypeCheck, Payload: []byte("{")})
+	assert.False(t, resp.Success)
+	assert.Contains(t, resp.Error, "invalid check payload")
+}
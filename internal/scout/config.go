@@ -18,6 +18,17 @@ type Config struct {
 	RenewalThreshold time.Duration `mapstructure:"renewal_threshold"` // renew when cert expires within this (default 30 days)
 	AutoRestart      bool          `mapstructure:"auto_restart"`      // enable init-system-aware restart on version rejection
 	AutoUpdate       bool          `mapstructure:"auto_update"`       // enable automatic binary self-update
+
+	// Windows event log forwarding. Other platforms ignore these.
+	EventChannels []string `mapstructure:"event_channels"` // channels to forward; empty disables forwarding
+	EventLevels   []string `mapstructure:"event_levels"`   // levels to forward: critical, error, warning, information, verbose
+	EventSources  []string `mapstructure:"event_sources"`  // forward only these event providers; empty forwards all
+
+	// ExecAllowlist lists the command lines this agent will run for the
+	// server's remote exec, using the scoutpb.ExecAllowed pattern syntax.
+	// It is enforced locally, so a compromised server cannot run anything
+	// else. Empty refuses all remote exec.
+	ExecAllowlist []string `mapstructure:"exec_allowlist"`
 }
 
 // DefaultConfig returns the default agent configuration.
@@ -27,6 +38,8 @@ func DefaultConfig() *Config {
 		CheckInterval:    30,
 		Insecure:         true,              // backward compat: insecure by default until TLS is configured
 		RenewalThreshold: 30 * 24 * time.Hour, // renew when cert expires within 30 days
+		EventChannels:    []string{"System", "Application"},
+		EventLevels:      []string{"critical", "error", "warning"},
 	}
 }
 
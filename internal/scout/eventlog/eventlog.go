@@ -0,0 +1,240 @@
+// Package eventlog reads new entries from Windows event log channels for
+// forwarding to the server. On other platforms the collector reads nothing.
+package eventlog
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxEventsPerRead caps the events read from one channel per Collect. A
+// backlog is drained over later calls instead of in one large report.
+const maxEventsPerRead = 200
+
+// maxMessageLen caps the rendered message length forwarded per event.
+const maxMessageLen = 4096
+
+// Config selects the channels and events to forward.
+type Config struct {
+	Channels []string // Channels to read, e.g. System, Application
+	Levels   []string // Levels to forward; see scoutpb.EventLevels
+	Sources  []string // Provider names to forward; empty forwards all
+}
+
+// queryFunc runs an XPath query against a channel and returns the matching
+// events as rendered XML wrapped in an <Events> root element. It is nil on
+// platforms without an event log.
+type queryFunc func(ctx context.Context, channel, xpath string, count int, newestFirst bool) ([]byte, error)
+
+// Collector reads events newer than the last committed record of each
+// channel. The first read of a channel only records its newest record, so
+// history is not replayed when the agent starts.
+type Collector struct {
+	channels []string
+	levels   []string
+	sources  map[string]bool
+	logger   *zap.Logger
+	query    queryFunc
+
+	mu        sync.Mutex
+	positions map[string]uint64 // Last committed record ID per channel
+	pending   map[string]uint64 // Positions reached by the last Collect
+}
+
+// NewCollector returns a collector for cfg. Levels default to critical,
+// error, and warning.
+func NewCollector(cfg Config, logger *zap.Logger) (*Collector, error) {
+	levels := cfg.Levels
+	if len(levels) == 0 {
+		levels = []string{scoutpb.EventLevelCritical, scoutpb.EventLevelError, scoutpb.EventLevelWarning}
+	}
+	for _, level := range levels {
+		if !slices.Contains(scoutpb.EventLevels, level) {
+			return nil, fmt.Errorf("unknown event level %q; valid levels are %s", level, strings.Join(scoutpb.EventLevels, ", "))
+		}
+	}
+	sources := make(map[string]bool, len(cfg.Sources))
+	for _, src := range cfg.Sources {
+		sources[strings.ToLower(src)] = true
+	}
+	return &Collector{
+		channels:  cfg.Channels,
+		levels:    levels,
+		sources:   sources,
+		logger:    logger,
+		query:     queryEvents,
+		positions: make(map[string]uint64),
+		pending:   make(map[string]uint64),
+	}, nil
+}
+
+// Collect returns the events written since the last Commit, oldest first
+// per channel. A channel that cannot be read is logged and skipped.
+func (c *Collector) Collect(ctx context.Context) ([]*scoutpb.LogEvent, error) {
+	if c.query == nil {
+		return nil, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var events []*scoutpb.LogEvent
+	for _, channel := range c.channels {
+		last, ok := c.positions[channel]
+		if !ok {
+			newest, err := c.newestRecord(ctx, channel)
+			if err != nil {
+				c.logger.Warn("failed to read event log channel", zap.String("channel", channel), zap.Error(err))
+				continue
+			}
+			c.positions[channel] = newest
+			continue
+		}
+
+		out, err := c.query(ctx, channel, c.xpath(last), maxEventsPerRead, false)
+		if err != nil {
+			c.logger.Warn("failed to read event log channel", zap.String("channel", channel), zap.Error(err))
+			continue
+		}
+		read, err := parseEvents(out)
+		if err != nil {
+			c.logger.Warn("failed to parse event log output", zap.String("channel", channel), zap.Error(err))
+			continue
+		}
+		for _, ev := range read {
+			last = max(last, ev.GetRecordId())
+			if len(c.sources) > 0 && !c.sources[strings.ToLower(ev.GetSource())] {
+				continue
+			}
+			if ev.Channel == "" {
+				ev.Channel = channel
+			}
+			events = append(events, ev)
+		}
+		c.pending[channel] = last
+	}
+	return events, nil
+}
+
+// Commit marks the events returned by the last Collect as forwarded. Until
+// then, Collect returns them again.
+func (c *Collector) Commit() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for channel, pos := range c.pending {
+		c.positions[channel] = pos
+	}
+	clear(c.pending)
+}
+
+// newestRecord returns the record ID of the newest event in a channel, or 0
+// if it is empty.
+func (c *Collector) newestRecord(ctx context.Context, channel string) (uint64, error) {
+	out, err := c.query(ctx, channel, "*", 1, true)
+	if err != nil {
+		return 0, err
+	}
+	events, err := parseEvents(out)
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+	return events[0].GetRecordId(), nil
+}
+
+// xpath selects events at the configured levels after record last.
+func (c *Collector) xpath(last uint64) string {
+	var conds []string
+	for _, level := range c.levels {
+		for _, n := range levelValues[level] {
+			conds = append(conds, fmt.Sprintf("Level=%d", n))
+		}
+	}
+	return fmt.Sprintf("*[System[(%s) and EventRecordID > %d]]", strings.Join(conds, " or "), last)
+}
+
+// levelValues maps level names to Windows event level values. Level 0
+// (LogAlways) is reported as information.
+var levelValues = map[string][]int{
+	scoutpb.EventLevelCritical:    {1},
+	scoutpb.EventLevelError:       {2},
+	scoutpb.EventLevelWarning:     {3},
+	scoutpb.EventLevelInformation: {0, 4},
+	scoutpb.EventLevelVerbose:     {5},
+}
+
+// levelName returns the level name for a Windows event level value.
+func levelName(v int) string {
+	for name, values := range levelValues {
+		if slices.Contains(values, v) {
+			return name
+		}
+	}
+	return scoutpb.EventLevelInformation
+}
+
+// xmlEvents is the rendered XML of a wevtutil query with an <Events> root.
+type xmlEvents struct {
+	Events []struct {
+		System struct {
+			Provider struct {
+				Name string `xml:"Name,attr"`
+			} `xml:"Provider"`
+			EventID     string `xml:"EventID"`
+			Level       int    `xml:"Level"`
+			TimeCreated struct {
+				SystemTime string `xml:"SystemTime,attr"`
+			} `xml:"TimeCreated"`
+			EventRecordID uint64 `xml:"EventRecordID"`
+			Channel       string `xml:"Channel"`
+		} `xml:"System"`
+		Data    []string `xml:"EventData>Data"`
+		Message string   `xml:"RenderingInfo>Message"`
+	} `xml:"Event"`
+}
+
+// parseEvents converts rendered event XML to LogEvents. Events without a
+// rendered message carry their event data values instead.
+func parseEvents(out []byte) ([]*scoutpb.LogEvent, error) {
+	if len(strings.TrimSpace(string(out))) == 0 {
+		return nil, nil
+	}
+	var doc xmlEvents
+	if err := xml.Unmarshal(out, &doc); err != nil {
+		return nil, fmt.Errorf("decode events: %w", err)
+	}
+
+	events := make([]*scoutpb.LogEvent, 0, len(doc.Events))
+	for i := range doc.Events {
+		e := &doc.Events[i]
+		id, _ := strconv.ParseUint(strings.TrimSpace(e.System.EventID), 10, 32)
+		msg := strings.TrimSpace(e.Message)
+		if msg == "" {
+			msg = strings.Join(e.Data, "; ")
+		}
+		if r := []rune(msg); len(r) > maxMessageLen {
+			msg = string(r[:maxMessageLen])
+		}
+		ev := &scoutpb.LogEvent{
+			Channel:  e.System.Channel,
+			Source:   e.System.Provider.Name,
+			EventId:  uint32(id),
+			Level:    levelName(e.System.Level),
+			Message:  msg,
+			RecordId: e.System.EventRecordID,
+		}
+		if t, err := time.Parse(time.RFC3339Nano, e.System.TimeCreated.SystemTime); err == nil {
+			ev.TimeCreated = timestamppb.New(t)
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
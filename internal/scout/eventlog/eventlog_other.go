@@ -0,0 +1,7 @@
+//go:build !windows
+
+package eventlog
+
+// queryEvents is nil: event log forwarding is Windows only, so Collect
+// returns nothing on other platforms.
+var queryEvents queryFunc
@@ -0,0 +1,108 @@
+package eventlog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+const sampleEvents = `<Events>
+<Event xmlns='http://schemas.microsoft.com/win/2004/08/events/event'><System><Provider Name='Service Control Manager' Guid='{555908d1-a6d7-4695-8e1e-26931d2012f4}' EventSourceName='Service Control Manager'/><EventID Qualifiers='49152'>7031</EventID><Level>2</Level><TimeCreated SystemTime='2026-01-15T10:30:00.1234567Z'/><EventRecordID>101</EventRecordID><Channel>System</Channel><Computer>srv01</Computer></System><EventData><Data Name='param1'>Print Spooler</Data></EventData><RenderingInfo Culture='en-US'><Message>The Print Spooler service terminated unexpectedly.</Message><Level>Error</Level></RenderingInfo></Event>
+<Event xmlns='http://schemas.microsoft.com/win/2004/08/events/event'><System><Provider Name='disk'/><EventID>153</EventID><Level>3</Level><TimeCreated SystemTime='2026-01-15T10:31:00Z'/><EventRecordID>102</EventRecordID><Channel>System</Channel></System><EventData><Data>\Device\Harddisk0\DR0</Data><Data>0x2a</Data></EventData></Event>
+</Events>`
+
+func TestParseEvents(t *testing.T) {
+	events, err := parseEvents([]byte(sampleEvents))
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	spooler := events[0]
+	assert.Equal(t, "System", spooler.GetChannel())
+	assert.Equal(t, "Service Control Manager", spooler.GetSource())
+	assert.Equal(t, uint32(7031), spooler.GetEventId())
+	assert.Equal(t, scoutpb.EventLevelError, spooler.GetLevel())
+	assert.Equal(t, "The Print Spooler service terminated unexpectedly.", spooler.GetMessage())
+	assert.Equal(t, uint64(101), spooler.GetRecordId())
+	assert.Equal(t, int64(1768473000), spooler.GetTimeCreated().GetSeconds())
+
+	// Without a rendered message the event data is forwarded.
+	assert.Equal(t, scoutpb.EventLevelWarning, events[1].GetLevel())
+	assert.Equal(t, `\Device\Harddisk0\DR0; 0x2a`, events[1].GetMessage())
+
+	events, err = parseEvents(nil)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	_, err = parseEvents([]byte("<Events><Event>"))
+	assert.Error(t, err)
+}
+
+func TestNewCollector_Levels(t *testing.T) {
+	_, err := NewCollector(Config{Levels: []string{"fatal"}}, zaptest.NewLogger(t))
+	assert.Error(t, err)
+
+	c, err := NewCollector(Config{}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	assert.Equal(t, "*[System[(Level=1 or Level=2 or Level=3) and EventRecordID > 7]]", c.xpath(7))
+
+	c, err = NewCollector(Config{Levels: []string{scoutpb.EventLevelInformation}}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	assert.Equal(t, "*[System[(Level=0 or Level=4) and EventRecordID > 0]]", c.xpath(0))
+}
+
+func TestCollector_Collect(t *testing.T) {
+	c, err := NewCollector(Config{
+		Channels: []string{"System", "Missing"},
+		Sources:  []string{"service control manager"},
+	}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	var queries []string
+	c.query = func(_ context.Context, channel, xpath string, count int, newestFirst bool) ([]byte, error) {
+		if channel == "Missing" {
+			return nil, fmt.Errorf("channel not found")
+		}
+		queries = append(queries, xpath)
+		if newestFirst {
+			require.Equal(t, 1, count)
+			return []byte(`<Events><Event><System><EventRecordID>100</EventRecordID></System></Event></Events>`), nil
+		}
+		return []byte(sampleEvents), nil
+	}
+	ctx := context.Background()
+
+	// The first read only records the newest record.
+	events, err := c.Collect(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	events, err = c.Collect(ctx)
+	require.NoError(t, err)
+	require.Len(t, events, 1, "disk event is filtered out by source")
+	assert.Equal(t, uint32(7031), events[0].GetEventId())
+	assert.True(t, strings.HasSuffix(queries[len(queries)-1], "EventRecordID > 100]]"))
+
+	// Until committed, the same events are read again.
+	_, _ = c.Collect(ctx)
+	assert.True(t, strings.HasSuffix(queries[len(queries)-1], "EventRecordID > 100]]"))
+
+	c.Commit()
+	_, _ = c.Collect(ctx)
+	assert.True(t, strings.HasSuffix(queries[len(queries)-1], "EventRecordID > 102]]"))
+}
+
+func TestCollector_NoEventLog(t *testing.T) {
+	c, err := NewCollector(Config{Channels: []string{"System"}}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	c.query = nil
+
+	events, err := c.Collect(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
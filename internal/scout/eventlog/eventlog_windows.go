@@ -0,0 +1,29 @@
+//go:build windows
+
+package eventlog
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// queryTimeout bounds a single wevtutil query.
+const queryTimeout = 30 * time.Second
+
+// queryEvents runs wevtutil to query a channel, returning rendered XML so
+// messages are formatted by their providers.
+func queryEvents(ctx context.Context, channel, xpath string, count int, newestFirst bool) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "wevtutil", "qe", channel,
+		"/q:"+xpath,
+		"/c:"+strconv.Itoa(count),
+		"/rd:"+strconv.FormatBool(newestFirst),
+		"/f:RenderedXml",
+		"/e:Events",
+	)
+	return cmd.Output()
+}
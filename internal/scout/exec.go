@@ -0,0 +1,169 @@
+package scout
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"time"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+)
+
+// maxCommandOutput caps captured stdout and stderr per remote command.
+const maxCommandOutput = 64 * 1024
+
+// runPendingCommands opens a CommandStream, runs every command the server
+// sends until the end marker, and reports each result. Only one stream runs
+// at a time; overlapping calls return immediately.
+func (a *Agent) runPendingCommands(ctx context.Context) {
+	if !a.commandsRunning.CompareAndSwap(false, true) {
+		return
+	}
+	defer a.commandsRunning.Store(false)
+
+	ctx = metadata.AppendToOutgoingContext(ctx, scoutpb.AgentIDMetadataKey, a.config.AgentID)
+	stream, err := a.client.CommandStream(ctx)
+	if err != nil {
+		a.logger.Warn("failed to open command stream", zap.Error(err))
+		return
+	}
+
+	for {
+		cmd, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if err != nil {
+			a.logger.Warn("command stream receive failed", zap.Error(err))
+			return
+		}
+		if cmd.GetType() == scoutpb.CommandTypeEnd {
+			break
+		}
+
+		resp := a.handleCommand(ctx, cmd)
+		if err := stream.Send(resp); err != nil {
+			a.logger.Warn("failed to send command result", zap.String("command_id", cmd.GetId()), zap.Error(err))
+			return
+		}
+	}
+
+	if err := stream.CloseSend(); err != nil {
+		a.logger.Warn("failed to close command stream", zap.Error(err))
+		return
+	}
+	// Drain until the server finishes recording results.
+	for {
+		if _, err := stream.Recv(); err != nil {
+			return
+		}
+	}
+}
+
+// handleCommand executes a single server command and builds its response.
+func (a *Agent) handleCommand(ctx context.Context, cmd *scoutpb.Command) *scoutpb.CommandResponse {
+	resp := &scoutpb.CommandResponse{CommandId: cmd.GetId()}
+
+	switch cmd.GetType() {
+	case scoutpb.CommandTypeExec:
+	case scoutpb.CommandTypeCheck:
+		return a.handleCheckCommand(ctx, cmd)
+	default:
+		resp.Error = fmt.Sprintf("unsupported command type %q", cmd.GetType())
+		return resp
+	}
+	var payload scoutpb.ExecPayload
+	if err := json.Unmarshal(cmd.GetPayload(), &payload); err != nil {
+		resp.Error = fmt.Sprintf("invalid exec payload: %v", err)
+		return resp
+	}
+
+	if !scoutpb.ExecAllowed(a.config.ExecAllowlist, payload.Command, payload.Args) {
+		a.logger.Warn("refused remote command not in local exec allowlist",
+			zap.String("command_id", cmd.GetId()),
+			zap.String("command", payload.Command),
+			zap.Strings("args", payload.Args),
+		)
+		resp.Error = fmt.Sprintf("command %q with these args is not in the agent's exec allowlist", payload.Command)
+		return resp
+	}
+
+	a.logger.Info("running remote command",
+		zap.String("command_id", cmd.GetId()),
+		zap.String("command", payload.Command),
+		zap.Strings("args", payload.Args),
+	)
+	out, err := runExec(ctx, payload)
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	resp.Success = err == nil && out.ExitCode != nil && *out.ExitCode == 0
+	resp.Output, _ = json.Marshal(out)
+	return resp
+}
+
+// runExec runs payload.Command directly (never through a shell) with the
+// payload's timeout and captures bounded stdout and stderr. A non-zero exit
+// status is reported via ExitCode, not as an error.
+func runExec(ctx context.Context, payload scoutpb.ExecPayload) (scoutpb.ExecOutput, error) {
+	var out scoutpb.ExecOutput
+	timeout := time.Duration(payload.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	stdout := &limitedBuffer{limit: maxCommandOutput}
+	stderr := &limitedBuffer{limit: maxCommandOutput}
+	c := exec.CommandContext(ctx, payload.Command, payload.Args...) //nolint:gosec // command is allowlisted on the server and the agent
+	c.Stdout = stdout
+	c.Stderr = stderr
+
+	err := c.Run()
+	out.Stdout = stdout.String()
+	out.Stderr = stderr.String()
+	if ctx.Err() == context.DeadlineExceeded {
+		return out, fmt.Errorf("command timed out after %s", timeout)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code := exitErr.ExitCode()
+		out.ExitCode = &code
+		return out, nil
+	}
+	if err != nil {
+		return out, err
+	}
+	code := 0
+	out.ExitCode = &code
+	return out, nil
+}
+
+// limitedBuffer is an io.Writer that keeps at most limit bytes and silently
+// discards the rest so a chatty command cannot exhaust agent memory.
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
@@ -0,0 +1,81 @@
+package scout
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestLimitedBuffer(t *testing.T) {
+	b := &limitedBuffer{limit: 5}
+	n, err := b.Write([]byte("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	n, err = b.Write([]byte("defgh"))
+	require.NoError(t, err)
+	assert.Equal(t, 5, n, "writes report full length so the child is not blocked")
+	assert.Equal(t, "abcde", b.String())
+}
+
+func TestRunExec(t *testing.T) {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	// The test binary lists matching tests for -test.list and exits 0.
+	out, err := runExec(context.Background(), scoutpb.ExecPayload{
+		Command:        exe,
+		Args:           []string{"-test.list", "TestLimitedBuffer"},
+		TimeoutSeconds: 30,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, out.ExitCode)
+	assert.Equal(t, 0, *out.ExitCode)
+	assert.Contains(t, out.Stdout, "TestLimitedBuffer")
+
+	_, err = runExec(context.Background(), scoutpb.ExecPayload{Command: "subnetree-no-such-command"})
+	assert.Error(t, err)
+}
+
+func TestHandleCommand_UnsupportedType(t *testing.T) {
+	a := &Agent{logger: zaptest.NewLogger(t)}
+	resp := a.handleCommand(context.Background(), &scoutpb.Command{Id: "c1", Type: "reboot"})
+	assert.Equal(t, "c1", resp.CommandId)
+	assert.False(t, resp.Success)
+	assert.Contains(t, resp.Error, "unsupported")
+
+	resp = a.handleCommand(context.Background(), &scoutpb.Command{Id: "c2", Type: scoutpb.CommandTypeExec, Payload: []byte("{")})
+	assert.False(t, resp.Success)
+	assert.Contains(t, resp.Error, "invalid exec payload")
+
+	var out scoutpb.ExecOutput
+	assert.Error(t, json.Unmarshal(resp.Output, &out), "no output for rejected payloads")
+}
+
+func TestHandleCommand_ExecAllowlist(t *testing.T) {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	a := &Agent{
+		logger: zaptest.NewLogger(t),
+		config: &Config{ExecAllowlist: []string{exe + " -test.list *"}},
+	}
+	run := func(args ...string) *scoutpb.CommandResponse {
+		payload, err := json.Marshal(scoutpb.ExecPayload{Command: exe, Args: args, TimeoutSeconds: 30})
+		require.NoError(t, err)
+		return a.handleCommand(context.Background(), &scoutpb.Command{Id: "c1", Type: scoutpb.CommandTypeExec, Payload: payload})
+	}
+
+	assert.True(t, run("-test.list", "TestLimitedBuffer").Success)
+
+	resp := run("-test.run", "TestLimitedBuffer")
+	assert.False(t, resp.Success)
+	assert.Contains(t, resp.Error, "not in the agent's exec allowlist")
+
+	resp = run("-test.list", "-test.v")
+	assert.False(t, resp.Success, "wildcards do not match options")
+}
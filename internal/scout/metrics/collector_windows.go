@@ -75,6 +75,10 @@ func (c *windowsCollector) Collect(ctx context.Context) (*scoutpb.SystemMetrics,
 		c.logger.Debug("collected docker container stats", zap.Int("count", len(containerStats)))
 	}
 
+	// GPU and temperature sensors (graceful degradation if unavailable).
+	m.Gpus = collectGPUMetrics(ctx, c.logger)
+	m.Temperatures = collectTemperatures(ctx, c.logger)
+
 	return m, nil
 }
 
@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+	"go.uber.org/zap"
+)
+
+// gpuQueryTimeout bounds each vendor tool invocation so a hung driver does
+// not stall the check-in loop.
+const gpuQueryTimeout = 5 * time.Second
+
+// nvidiaSMIQuery is the field list passed to nvidia-smi --query-gpu.
+const nvidiaSMIQuery = "index,name,utilization.gpu,memory.used,memory.total,temperature.gpu"
+
+// collectGPUMetrics samples GPU utilization, VRAM, and temperature using
+// nvidia-smi (NVML) or rocm-smi, whichever is installed. Returns nil on
+// hosts without a supported GPU tool (graceful degradation).
+func collectGPUMetrics(ctx context.Context, logger *zap.Logger) []*scoutpb.GPUMetric {
+	if _, err := exec.LookPath("nvidia-smi"); err == nil {
+		out, err := runGPUTool(ctx, "nvidia-smi", "--query-gpu="+nvidiaSMIQuery, "--format=csv,noheader,nounits")
+		if err != nil {
+			logger.Debug("nvidia-smi query failed", zap.Error(err))
+		} else if gpus := parseNvidiaSMI(out); len(gpus) > 0 {
+			return gpus
+		}
+	}
+
+	if _, err := exec.LookPath("rocm-smi"); err == nil {
+		out, err := runGPUTool(ctx, "rocm-smi", "--showuse", "--showmeminfo", "vram", "--showtemp", "--showproductname", "--json")
+		if err != nil {
+			logger.Debug("rocm-smi query failed", zap.Error(err))
+			return nil
+		}
+		gpus, err := parseROCmSMI(out)
+		if err != nil {
+			logger.Debug("rocm-smi output not understood", zap.Error(err))
+			return nil
+		}
+		return gpus
+	}
+
+	return nil
+}
+
+// runGPUTool runs a vendor tool with gpuQueryTimeout and returns its stdout.
+func runGPUTool(ctx context.Context, name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, gpuQueryTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %w (stderr: %s)", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// parseNvidiaSMI parses nvidia-smi CSV output in nvidiaSMIQuery field order.
+// Memory is reported in MiB. Fields the driver does not support ("[N/A]",
+// "[Not Supported]") are left at zero.
+func parseNvidiaSMI(out []byte) []*scoutpb.GPUMetric {
+	var gpus []*scoutpb.GPUMetric
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Split(line, ",")
+		if len(fields) < 6 {
+			continue
+		}
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		index, err := strconv.ParseUint(fields[0], 10, 32)
+		if err != nil {
+			continue
+		}
+		gpus = append(gpus, &scoutpb.GPUMetric{
+			Index:              uint32(index),
+			Name:               fields[1],
+			UtilizationPercent: parseGPUValue(fields[2]),
+			MemoryUsedBytes:    parseGPUValue(fields[3]) * 1024 * 1024,
+			MemoryTotalBytes:   parseGPUValue(fields[4]) * 1024 * 1024,
+			TemperatureCelsius: parseGPUValue(fields[5]),
+		})
+	}
+	return gpus
+}
+
+// parseROCmSMI parses rocm-smi --json output, which maps "cardN" to a
+// table of human-readable field names.
+func parseROCmSMI(out []byte) ([]*scoutpb.GPUMetric, error) {
+	var cards map[string]map[string]string
+	if err := json.Unmarshal(out, &cards); err != nil {
+		return nil, fmt.Errorf("decode rocm-smi output: %w", err)
+	}
+
+	var gpus []*scoutpb.GPUMetric
+	for card, fields := range cards {
+		index, err := strconv.ParseUint(strings.TrimPrefix(card, "card"), 10, 32)
+		if err != nil {
+			continue // "system" and other non-card entries
+		}
+		gpu := &scoutpb.GPUMetric{
+			Index:              uint32(index),
+			Name:               firstField(fields, "Card Series", "Card series", "Card Model", "Card model"),
+			UtilizationPercent: parseGPUValue(fields["GPU use (%)"]),
+			MemoryUsedBytes:    parseGPUValue(fields["VRAM Total Used Memory (B)"]),
+			MemoryTotalBytes:   parseGPUValue(fields["VRAM Total Memory (B)"]),
+			TemperatureCelsius: parseGPUValue(firstField(fields,
+				"Temperature (Sensor edge) (C)", "Temperature (Sensor junction) (C)")),
+		}
+		gpus = append(gpus, gpu)
+	}
+	sort.Slice(gpus, func(i, j int) bool { return gpus[i].Index < gpus[j].Index })
+	return gpus, nil
+}
+
+// firstField returns the first non-empty value among keys.
+func firstField(fields map[string]string, keys ...string) string {
+	for _, k := range keys {
+		if v := strings.TrimSpace(fields[k]); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// parseGPUValue parses a numeric tool field, returning 0 for unsupported
+// or malformed values.
+func parseGPUValue(s string) float64 {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import "testing"
+
+func TestParseNvidiaSMI(t *testing.T) {
+	out := []byte("0, NVIDIA GeForce RTX 3090, 87, 20480, 24576, 71\n" +
+		"1, Tesla K80, [N/A], 512, 11441, [Not Supported]\n" +
+		"garbage line\n")
+
+	gpus := parseNvidiaSMI(out)
+	if len(gpus) != 2 {
+		t.Fatalf("got %d gpus, want 2", len(gpus))
+	}
+	g := gpus[0]
+	if g.Index != 0 || g.Name != "NVIDIA GeForce RTX 3090" {
+		t.Errorf("gpu 0 = %d %q", g.Index, g.Name)
+	}
+	if g.UtilizationPercent != 87 || g.TemperatureCelsius != 71 {
+		t.Errorf("gpu 0 util=%v temp=%v", g.UtilizationPercent, g.TemperatureCelsius)
+	}
+	if g.MemoryUsedBytes != 20480*1024*1024 || g.MemoryTotalBytes != 24576*1024*1024 {
+		t.Errorf("gpu 0 memory = %v / %v", g.MemoryUsedBytes, g.MemoryTotalBytes)
+	}
+	if gpus[1].UtilizationPercent != 0 || gpus[1].TemperatureCelsius != 0 {
+		t.Errorf("unsupported fields should be zero: %+v", gpus[1])
+	}
+}
+
+func TestParseROCmSMI(t *testing.T) {
+	out := []byte(`{
+		"card1": {"GPU use (%)": "5", "VRAM Total Memory (B)": "8573157376", "VRAM Total Used Memory (B)": "12288000",
+			"Temperature (Sensor junction) (C)": "48.0", "Card series": "Navi 23"},
+		"card0": {"GPU use (%)": "42", "VRAM Total Memory (B)": "17163091968", "VRAM Total Used Memory (B)": "1073741824",
+			"Temperature (Sensor edge) (C)": "55.0", "Temperature (Sensor junction) (C)": "61.0", "Card series": "Navi 21"},
+		"system": {"Driver version": "6.2.4"}
+	}`)
+
+	gpus, err := parseROCmSMI(out)
+	if err != nil {
+		t.Fatalf("parseROCmSMI: %v", err)
+	}
+	if len(gpus) != 2 {
+		t.Fatalf("got %d gpus, want 2", len(gpus))
+	}
+	if gpus[0].Index != 0 || gpus[0].Name != "Navi 21" || gpus[0].UtilizationPercent != 42 {
+		t.Errorf("gpu 0 = %+v", gpus[0])
+	}
+	if gpus[0].TemperatureCelsius != 55 {
+		t.Errorf("gpu 0 temp = %v, want edge sensor 55", gpus[0].TemperatureCelsius)
+	}
+	if gpus[1].TemperatureCelsius != 48 || gpus[1].MemoryUsedBytes != 12288000 {
+		t.Errorf("gpu 1 = %+v", gpus[1])
+	}
+
+	if _, err := parseROCmSMI([]byte("not json")); err == nil {
+		t.Error("expected error for invalid output")
+	}
+}
@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+)
+
+// Plausible sensor range in degrees Celsius. Readings outside it come from
+// disconnected or misconfigured sensor inputs and are dropped.
+const (
+	minPlausibleCelsius = -40
+	maxPlausibleCelsius = 150
+)
+
+// hwmonCPUChips are hwmon driver names that report CPU package or core
+// temperatures.
+var hwmonCPUChips = map[string]bool{
+	"coretemp":    true,
+	"k10temp":     true,
+	"k8temp":      true,
+	"zenpower":    true,
+	"cpu_thermal": true,
+	"via_cputemp": true,
+}
+
+// hwmonSkipChips are hwmon drivers for GPUs and drives. GPU temperatures
+// are reported with GPU metrics; drive temperatures are not chassis readings.
+var hwmonSkipChips = map[string]bool{
+	"amdgpu":    true,
+	"radeon":    true,
+	"nouveau":   true,
+	"nvme":      true,
+	"drivetemp": true,
+}
+
+// readHwmonTemperatures reads temperature inputs from a Linux hwmon tree
+// such as /sys/class/hwmon. Inputs are millidegrees Celsius.
+func readHwmonTemperatures(root string) []*scoutpb.TemperatureMetric {
+	chips, err := filepath.Glob(filepath.Join(root, "hwmon*"))
+	if err != nil {
+		return nil
+	}
+	sort.Strings(chips)
+
+	var temps []*scoutpb.TemperatureMetric
+	for _, chip := range chips {
+		name := readTrimmed(filepath.Join(chip, "name"))
+		if name == "" || hwmonSkipChips[name] {
+			continue
+		}
+		kind := scoutpb.TemperatureKindChassis
+		if hwmonCPUChips[name] {
+			kind = scoutpb.TemperatureKindCPU
+		}
+
+		inputs, _ := filepath.Glob(filepath.Join(chip, "temp*_input"))
+		sort.Strings(inputs)
+		for _, input := range inputs {
+			milli, err := strconv.ParseFloat(readTrimmed(input), 64)
+			if err != nil {
+				continue
+			}
+			celsius := milli / 1000
+			if celsius < minPlausibleCelsius || celsius > maxPlausibleCelsius {
+				continue
+			}
+			sensor := strings.TrimSuffix(filepath.Base(input), "_input")
+			if label := readTrimmed(strings.TrimSuffix(input, "_input") + "_label"); label != "" {
+				sensor = label
+			}
+			temps = append(temps, &scoutpb.TemperatureMetric{
+				Sensor:  name + " " + sensor,
+				Kind:    kind,
+				Celsius: celsius,
+			})
+		}
+	}
+	return temps
+}
+
+// readTrimmed returns the trimmed contents of a sysfs file, or "" if it
+// cannot be read.
+func readTrimmed(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// parseThermalZoneCSV parses MSAcpi_ThermalZoneTemperature rows exported
+// with ConvertTo-Csv. CurrentTemperature is in tenths of a kelvin.
+func parseThermalZoneCSV(data []byte) ([]*scoutpb.TemperatureMetric, error) {
+	data = bytes.TrimPrefix(data, []byte{0xEF, 0xBB, 0xBF})
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse thermal zone csv: %w", err)
+	}
+	if len(records) < 2 {
+		return nil, nil
+	}
+
+	nameCol, tempCol := -1, -1
+	for i, h := range records[0] {
+		switch h {
+		case "InstanceName":
+			nameCol = i
+		case "CurrentTemperature":
+			tempCol = i
+		}
+	}
+	if nameCol < 0 || tempCol < 0 {
+		return nil, fmt.Errorf("thermal zone csv missing columns: %v", records[0])
+	}
+
+	var temps []*scoutpb.TemperatureMetric
+	for _, rec := range records[1:] {
+		if len(rec) <= max(nameCol, tempCol) {
+			continue
+		}
+		decikelvin, err := strconv.ParseFloat(rec[tempCol], 64)
+		if err != nil {
+			continue
+		}
+		celsius := math.Round(decikelvin-2731.5) / 10
+		if celsius < minPlausibleCelsius || celsius > maxPlausibleCelsius {
+			continue
+		}
+		temps = append(temps, &scoutpb.TemperatureMetric{
+			Sensor:  rec[nameCol],
+			Kind:    scoutpb.TemperatureKindChassis,
+			Celsius: celsius,
+		})
+	}
+	return temps, nil
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package metrics
+
+import (
+	"context"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+	"go.uber.org/zap"
+)
+
+// hwmonRoot is the Linux hardware monitoring sysfs tree.
+const hwmonRoot = "/sys/class/hwmon"
+
+// collectTemperatures reads CPU and chassis sensors from hwmon. Returns nil
+// on hosts without hwmon sensors (graceful degradation).
+func collectTemperatures(_ context.Context, _ *zap.Logger) []*scoutpb.TemperatureMetric {
+	return readHwmonTemperatures(hwmonRoot)
+}
@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+)
+
+func writeHwmon(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadHwmonTemperatures(t *testing.T) {
+	root := t.TempDir()
+	writeHwmon(t, filepath.Join(root, "hwmon0"), map[string]string{
+		"name": "acpitz", "temp1_input": "27800",
+	})
+	writeHwmon(t, filepath.Join(root, "hwmon1"), map[string]string{
+		"name": "coretemp", "temp1_input": "54000", "temp1_label": "Package id 0",
+		"temp2_input": "-273150", // disconnected input
+	})
+	writeHwmon(t, filepath.Join(root, "hwmon2"), map[string]string{
+		"name": "nvme", "temp1_input": "41850",
+	})
+
+	temps := readHwmonTemperatures(root)
+	if len(temps) != 2 {
+		t.Fatalf("got %d readings, want 2: %v", len(temps), temps)
+	}
+	if temps[0].Sensor != "acpitz temp1" || temps[0].Kind != scoutpb.TemperatureKindChassis || temps[0].Celsius != 27.8 {
+		t.Errorf("reading 0 = %+v", temps[0])
+	}
+	if temps[1].Sensor != "coretemp Package id 0" || temps[1].Kind != scoutpb.TemperatureKindCPU || temps[1].Celsius != 54 {
+		t.Errorf("reading 1 = %+v", temps[1])
+	}
+
+	if temps := readHwmonTemperatures(filepath.Join(root, "missing")); len(temps) != 0 {
+		t.Errorf("missing hwmon tree returned %v", temps)
+	}
+}
+
+func TestParseThermalZoneCSV(t *testing.T) {
+	data := []byte("\xEF\xBB\xBF\"InstanceName\",\"CurrentTemperature\"\r\n" +
+		"\"ACPI\\ThermalZone\\THM0_0\",\"3032\"\r\n" +
+		"\"ACPI\\ThermalZone\\BAD_0\",\"0\"\r\n")
+
+	temps, err := parseThermalZoneCSV(data)
+	if err != nil {
+		t.Fatalf("parseThermalZoneCSV: %v", err)
+	}
+	if len(temps) != 1 {
+		t.Fatalf("got %d readings, want 1", len(temps))
+	}
+	if temps[0].Sensor != `ACPI\ThermalZone\THM0_0` || temps[0].Celsius != 30.1 {
+		t.Errorf("reading = %+v", temps[0])
+	}
+
+	if _, err := parseThermalZoneCSV([]byte("\"Other\"\r\n\"x\"\r\n")); err == nil {
+		t.Error("expected error for missing columns")
+	}
+}
@@ -0,0 +1,39 @@
+//go:build windows
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+
+	scoutpb "github.com/HerbHall/subnetree/api/proto/v1"
+	"go.uber.org/zap"
+)
+
+// thermalZoneQuery reads ACPI thermal zones. The class requires
+// administrator rights, which the Scout service has.
+const thermalZoneQuery = "Get-CimInstance -Namespace root/wmi -ClassName MSAcpi_ThermalZoneTemperature | " +
+	"Select-Object InstanceName,CurrentTemperature | ConvertTo-Csv -NoTypeInformation"
+
+// collectTemperatures reads ACPI thermal zone temperatures via CIM. Returns
+// nil when the firmware exposes no thermal zones (graceful degradation).
+func collectTemperatures(ctx context.Context, logger *zap.Logger) []*scoutpb.TemperatureMetric {
+	ctx, cancel := context.WithTimeout(ctx, gpuQueryTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "powershell.exe", "-NoProfile", "-Command", thermalZoneQuery)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		logger.Debug("thermal zone query failed", zap.Error(err))
+		return nil
+	}
+
+	temps, err := parseThermalZoneCSV(stdout.Bytes())
+	if err != nil {
+		logger.Debug("thermal zone output not understood", zap.Error(err))
+		return nil
+	}
+	return temps
+}
@@ -6,6 +6,10 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"os/exec"
 	"runtime"
@@ -31,8 +35,12 @@ func collectSoftware(ctx context.Context, logger *zap.Logger) (*scoutpb.Software
 	sw.OsVersion = osVersion
 	sw.OsBuild = osBuild
 
-	// Installed packages via dpkg or rpm.
-	sw.Packages = collectLinuxPackages(ctx, logger)
+	// Installed packages via brew/pkgutil on macOS, dpkg or rpm elsewhere.
+	if runtime.GOOS == "darwin" {
+		sw.Packages = collectDarwinPackages(ctx, logger)
+	} else {
+		sw.Packages = collectLinuxPackages(ctx, logger)
+	}
 
 	// Docker containers (best-effort).
 	sw.DockerContainers = collectDockerContainers(ctx, logger)
@@ -173,12 +181,123 @@ func tryRPM(ctx context.Context, logger *zap.Logger) []*scoutpb.InstalledPackage
 	return packages
 }
 
-// collectDockerContainers runs docker ps to list running containers.
+// collectDarwinPackages combines Homebrew formulae and casks with pkgutil
+// receipts, stopping at maxPackages.
+func collectDarwinPackages(ctx context.Context, logger *zap.Logger) []*scoutpb.InstalledPackage {
+	cmdCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	var packages []*scoutpb.InstalledPackage
+	for _, args := range [][]string{
+		{"brew", "list", "--formula", "--versions"},
+		{"brew", "list", "--cask", "--versions"},
+	} {
+		out, err := runOutput(cmdCtx, args[0], args[1:]...)
+		if err != nil {
+			logger.Debug("brew not available", zap.Error(err))
+			break
+		}
+		packages = append(packages, parseBrewVersions(out, "Homebrew")...)
+	}
+
+	if out, err := runOutput(cmdCtx, "pkgutil", "--pkgs"); err != nil {
+		logger.Debug("pkgutil not available", zap.Error(err))
+	} else {
+		packages = append(packages, parsePkgutilPkgs(out)...)
+	}
+
+	if len(packages) > maxPackages {
+		packages = packages[:maxPackages]
+	}
+	logger.Debug("collected macOS packages", zap.Int("count", len(packages)))
+	return packages
+}
+
+// runOutput runs a command and returns its stdout.
+func runOutput(ctx context.Context, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// parseBrewVersions parses "brew list --versions" output ("name v1 [v2...]").
+// When several versions are installed the last one listed is reported.
+func parseBrewVersions(out, publisher string) []*scoutpb.InstalledPackage {
+	var packages []*scoutpb.InstalledPackage
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		pkg := &scoutpb.InstalledPackage{Name: fields[0], Publisher: publisher}
+		if len(fields) > 1 {
+			pkg.Version = fields[len(fields)-1]
+		}
+		packages = append(packages, pkg)
+	}
+	return packages
+}
+
+// parsePkgutilPkgs parses "pkgutil --pkgs" output (one receipt ID per line).
+// The vendor is derived from the reverse-DNS prefix, e.g. "com.apple".
+func parsePkgutilPkgs(out string) []*scoutpb.InstalledPackage {
+	var packages []*scoutpb.InstalledPackage
+	for _, line := range strings.Split(out, "\n") {
+		id := strings.TrimSpace(line)
+		if id == "" {
+			continue
+		}
+		pkg := &scoutpb.InstalledPackage{Name: id}
+		if parts := strings.SplitN(id, ".", 3); len(parts) == 3 {
+			pkg.Publisher = parts[0] + "." + parts[1]
+		}
+		packages = append(packages, pkg)
+	}
+	return packages
+}
+
+// dockerSocket is the Docker Engine API socket queried for running containers.
+var dockerSocket = "/var/run/docker.sock"
+
+// dockerAPIContainer is the subset of the Engine API /containers/json
+// response used to build the container inventory.
+type dockerAPIContainer struct {
+	ID     string          `json:"Id"`
+	Names  []string        `json:"Names"`
+	Image  string          `json:"Image"`
+	State  string          `json:"State"`
+	Status string          `json:"Status"`
+	Ports  []dockerAPIPort `json:"Ports"`
+}
+
+// dockerAPIPort is a single port mapping on a container.
+type dockerAPIPort struct {
+	IP          string `json:"IP"`
+	PrivatePort uint16 `json:"PrivatePort"`
+	PublicPort  uint16 `json:"PublicPort"`
+	Type        string `json:"Type"`
+}
+
+// collectDockerContainers lists running containers through the Docker socket,
+// falling back to the docker CLI. Returns nil when Docker is not available.
 func collectDockerContainers(ctx context.Context, logger *zap.Logger) []*scoutpb.DockerContainer {
+	if _, err := os.Stat(dockerSocket); err == nil {
+		containers, err := listDockerSocketContainers(ctx)
+		if err == nil {
+			logger.Debug("collected docker containers", zap.Int("count", len(containers)))
+			return containers
+		}
+		logger.Debug("docker socket query failed, falling back to docker ps", zap.Error(err))
+	}
+
 	cmdCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(cmdCtx, "docker", "ps", "--format", "{{.ID}}\t{{.Names}}\t{{.Image}}\t{{.Status}}")
+	cmd := exec.CommandContext(cmdCtx, "docker", "ps", "--format", "{{.ID}}\t{{.Names}}\t{{.Image}}\t{{.Status}}\t{{.State}}\t{{.Ports}}")
 	var stdout bytes.Buffer
 	cmd.Stdout = &stdout
 
@@ -194,18 +313,88 @@ func collectDockerContainers(ctx context.Context, logger *zap.Logger) []*scoutpb
 		if line == "" {
 			continue
 		}
-		parts := strings.SplitN(line, "\t", 4)
+		parts := strings.SplitN(line, "\t", 6)
 		if len(parts) < 4 {
 			continue
 		}
-		containers = append(containers, &scoutpb.DockerContainer{
+		c := &scoutpb.DockerContainer{
 			ContainerId: parts[0],
 			Name:        parts[1],
 			Image:       parts[2],
 			Status:      parts[3],
-		})
+		}
+		if len(parts) > 4 {
+			c.State = parts[4]
+		}
+		if len(parts) > 5 && parts[5] != "" {
+			for _, port := range strings.Split(parts[5], ",") {
+				c.Ports = append(c.Ports, strings.TrimSpace(port))
+			}
+		}
+		containers = append(containers, c)
 	}
 
 	logger.Debug("collected docker containers", zap.Int("count", len(containers)))
 	return containers
 }
+
+// listDockerSocketContainers queries the Engine API over the Unix socket.
+func listDockerSocketContainers(ctx context.Context) ([]*scoutpb.DockerContainer, error) {
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(_ context.Context, _, _ string) (net.Conn, error) {
+				return net.DialTimeout("unix", dockerSocket, 5*time.Second)
+			},
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/v1.41/containers/json", http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("docker API returned status %d", resp.StatusCode)
+	}
+
+	var raw []dockerAPIContainer
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("decode containers: %w", err)
+	}
+	return convertDockerContainers(raw), nil
+}
+
+// convertDockerContainers maps Engine API containers to the proto type,
+// formatting ports the way docker ps does (e.g. "0.0.0.0:8080->80/tcp").
+func convertDockerContainers(raw []dockerAPIContainer) []*scoutpb.DockerContainer {
+	containers := make([]*scoutpb.DockerContainer, 0, len(raw))
+	for i := range raw {
+		rc := &raw[i]
+		c := &scoutpb.DockerContainer{
+			ContainerId: rc.ID,
+			Image:       rc.Image,
+			State:       rc.State,
+			Status:      rc.Status,
+		}
+		if len(c.ContainerId) > 12 {
+			c.ContainerId = c.ContainerId[:12]
+		}
+		if len(rc.Names) > 0 {
+			c.Name = strings.TrimPrefix(rc.Names[0], "/")
+		}
+		for _, p := range rc.Ports {
+			if p.PublicPort != 0 {
+				c.Ports = append(c.Ports, fmt.Sprintf("%s:%d->%d/%s", p.IP, p.PublicPort, p.PrivatePort, p.Type))
+			} else {
+				c.Ports = append(c.Ports, fmt.Sprintf("%d/%s", p.PrivatePort, p.Type))
+			}
+		}
+		containers = append(containers, c)
+	}
+	return containers
+}
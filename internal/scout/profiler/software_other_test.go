@@ -0,0 +1,83 @@
+//go:build !windows
+
+package profiler
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestParseBrewVersions(t *testing.T) {
+	out := "git 2.45.0\nnode 20.11.1 22.3.0\n\nwget\n"
+	pkgs := parseBrewVersions(out, "Homebrew")
+	if len(pkgs) != 3 {
+		t.Fatalf("got %d packages, want 3", len(pkgs))
+	}
+	tests := []struct {
+		name, version string
+	}{
+		{"git", "2.45.0"},
+		{"node", "22.3.0"},
+		{"wget", ""},
+	}
+	for i, tt := range tests {
+		if pkgs[i].Name != tt.name || pkgs[i].Version != tt.version {
+			t.Errorf("pkgs[%d] = %s %s, want %s %s", i, pkgs[i].Name, pkgs[i].Version, tt.name, tt.version)
+		}
+		if pkgs[i].Publisher != "Homebrew" {
+			t.Errorf("pkgs[%d].Publisher = %q", i, pkgs[i].Publisher)
+		}
+	}
+}
+
+func TestParsePkgutilPkgs(t *testing.T) {
+	pkgs := parsePkgutilPkgs("com.apple.pkg.CLTools_Executables\norg.python.Python.PythonFramework-3.12\n\nlocal\n")
+	if len(pkgs) != 3 {
+		t.Fatalf("got %d packages, want 3", len(pkgs))
+	}
+	if pkgs[0].Publisher != "com.apple" || pkgs[1].Publisher != "org.python" {
+		t.Errorf("publishers = %q, %q", pkgs[0].Publisher, pkgs[1].Publisher)
+	}
+	if pkgs[2].Name != "local" || pkgs[2].Publisher != "" {
+		t.Errorf("pkgs[2] = %+v", pkgs[2])
+	}
+}
+
+func TestConvertDockerContainers(t *testing.T) {
+	raw := []dockerAPIContainer{{
+		ID:     "0123456789abcdef0123",
+		Names:  []string{"/web"},
+		Image:  "nginx:1.27",
+		State:  "running",
+		Status: "Up 2 hours",
+		Ports: []dockerAPIPort{
+			{IP: "0.0.0.0", PrivatePort: 80, PublicPort: 8080, Type: "tcp"},
+			{PrivatePort: 443, Type: "tcp"},
+		},
+	}}
+
+	got := convertDockerContainers(raw)
+	if len(got) != 1 {
+		t.Fatalf("got %d containers, want 1", len(got))
+	}
+	c := got[0]
+	if c.ContainerId != "0123456789ab" || c.Name != "web" || c.Image != "nginx:1.27" || c.State != "running" {
+		t.Errorf("unexpected container: %+v", c)
+	}
+	if len(c.Ports) != 2 || c.Ports[0] != "0.0.0.0:8080->80/tcp" || c.Ports[1] != "443/tcp" {
+		t.Errorf("Ports = %v", c.Ports)
+	}
+}
+
+func TestCollectDockerContainers_NoSocket(t *testing.T) {
+	orig := dockerSocket
+	dockerSocket = t.TempDir() + "/missing.sock"
+	t.Cleanup(func() { dockerSocket = orig })
+	t.Setenv("PATH", t.TempDir())
+
+	if got := collectDockerContainers(context.Background(), zap.NewNop()); got != nil {
+		t.Errorf("expected nil without docker, got %v", got)
+	}
+}
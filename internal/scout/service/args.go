@@ -0,0 +1,49 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/HerbHall/subnetree/internal/scout"
+)
+
+// buildServiceArgs constructs the command-line arguments for the service binary.
+// These are passed after the executable path when the init system starts the service.
+func buildServiceArgs(config *scout.Config) []string {
+	args := []string{"run"}
+
+	if config.ServerAddr != "" {
+		args = append(args, "--server", config.ServerAddr)
+	}
+	if config.CheckInterval > 0 {
+		args = append(args, "--interval", fmt.Sprintf("%d", config.CheckInterval))
+	}
+	if config.CertPath != "" {
+		args = append(args, "--cert", config.CertPath)
+	}
+	if config.KeyPath != "" {
+		args = append(args, "--key", config.KeyPath)
+	}
+	if config.CACertPath != "" {
+		args = append(args, "--ca-cert", config.CACertPath)
+	}
+	if config.Insecure {
+		args = append(args, "--insecure")
+	}
+	// Event log flags use the --flag=value form so an empty list, which
+	// disables forwarding, survives init system quoting.
+	if config.EventChannels != nil {
+		args = append(args, "--event-channels="+strings.Join(config.EventChannels, ","))
+	}
+	if len(config.EventLevels) > 0 {
+		args = append(args, "--event-levels="+strings.Join(config.EventLevels, ","))
+	}
+	if len(config.EventSources) > 0 {
+		args = append(args, "--event-sources="+strings.Join(config.EventSources, ","))
+	}
+	if len(config.ExecAllowlist) > 0 {
+		args = append(args, "--exec-allowlist="+strings.Join(config.ExecAllowlist, ","))
+	}
+
+	return args
+}
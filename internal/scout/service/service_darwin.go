@@ -0,0 +1,154 @@
+//go:build darwin
+
+package service
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/HerbHall/subnetree/internal/scout"
+	"go.uber.org/zap"
+)
+
+const (
+	// ServiceName is the Windows service name. Defined on all platforms for cross-platform code.
+	ServiceName = "SubNetreeScout"
+	// LaunchdLabel is the launchd job label installed by InstallService.
+	LaunchdLabel = "com.subnetree.scout"
+)
+
+// Overridable for tests.
+var (
+	launchdPlistPath = defaultLaunchdPlistPath
+	launchctl        = func(args ...string) error {
+		out, err := exec.Command("launchctl", args...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("launchctl %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+)
+
+// defaultLaunchdPlistPath returns the LaunchDaemons path when running as root
+// and the current user's LaunchAgents path otherwise.
+func defaultLaunchdPlistPath() (string, error) {
+	if os.Geteuid() == 0 {
+		return "/Library/LaunchDaemons/" + LaunchdLabel + ".plist", nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve home directory: %w", err)
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", LaunchdLabel+".plist"), nil
+}
+
+// RunAsService is not needed under launchd; the job runs "scout run" directly.
+func RunAsService(_ *scout.Config, _ *zap.Logger) error {
+	return fmt.Errorf("windows service mode is not supported on this platform")
+}
+
+// InstallService writes a launchd property list for the Scout agent and
+// loads it with launchctl so it starts at login (or boot, for root).
+func InstallService(exePath string, config *scout.Config) error {
+	path, err := launchdPlistPath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("service %s already exists", LaunchdLabel)
+	}
+
+	// launchd starts jobs with "/" as the working directory, so relative
+	// TLS paths given on the command line must be resolved now.
+	cfg := *config
+	for _, p := range []*string{&cfg.CertPath, &cfg.KeyPath, &cfg.CACertPath} {
+		if *p == "" {
+			continue
+		}
+		abs, err := filepath.Abs(*p)
+		if err != nil {
+			return fmt.Errorf("resolve path %q: %w", *p, err)
+		}
+		*p = abs
+	}
+
+	plist, err := renderLaunchdPlist(exePath, buildServiceArgs(&cfg))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, plist, 0o644); err != nil { //nolint:gosec // launchd requires world-readable plists
+		return fmt.Errorf("write plist: %w", err)
+	}
+
+	return launchctl("load", "-w", path)
+}
+
+// UninstallService unloads the launchd job and removes its property list.
+func UninstallService() error {
+	path, err := launchdPlistPath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("service %s not found", LaunchdLabel)
+	}
+
+	if err := launchctl("unload", "-w", path); err != nil {
+		// Non-fatal: the plist is removed regardless.
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("remove plist: %w", err)
+	}
+	return nil
+}
+
+// IsService always returns false on macOS; launchd needs no special handling.
+func IsService() (bool, error) {
+	return false, nil
+}
+
+// StartHint returns the command an operator runs to start the installed service.
+func StartHint() string {
+	return "launchctl start " + LaunchdLabel
+}
+
+// renderLaunchdPlist builds the property list for the given executable and arguments.
+func renderLaunchdPlist(exePath string, args []string) ([]byte, error) {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	b.WriteString(`<plist version="1.0">` + "\n<dict>\n")
+	b.WriteString("\t<key>Label</key>\n")
+	if err := writePlistString(&b, "\t", LaunchdLabel); err != nil {
+		return nil, err
+	}
+	b.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, a := range append([]string{exePath}, args...) {
+		if err := writePlistString(&b, "\t\t", a); err != nil {
+			return nil, err
+		}
+	}
+	b.WriteString("\t</array>\n")
+	b.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	b.WriteString("\t<key>KeepAlive</key>\n\t<dict>\n\t\t<key>SuccessfulExit</key>\n\t\t<false/>\n\t</dict>\n")
+	b.WriteString("</dict>\n</plist>\n")
+	return []byte(b.String()), nil
+}
+
+// writePlistString writes s as an XML-escaped <string> element.
+func writePlistString(b *strings.Builder, indent, s string) error {
+	b.WriteString(indent + "<string>")
+	if err := xml.EscapeText(b, []byte(s)); err != nil {
+		return fmt.Errorf("escape plist value: %w", err)
+	}
+	b.WriteString("</string>\n")
+	return nil
+}
@@ -0,0 +1,69 @@
+//go:build darwin
+
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/HerbHall/subnetree/internal/scout"
+)
+
+func TestRenderLaunchdPlist(t *testing.T) {
+	plist, err := renderLaunchdPlist("/usr/local/bin/scout", buildServiceArgs(&scout.Config{
+		ServerAddr:    "nv.example:9090",
+		CheckInterval: 60,
+		CACertPath:    "/etc/scout/a&b.pem",
+	}))
+	if err != nil {
+		t.Fatalf("renderLaunchdPlist: %v", err)
+	}
+	s := string(plist)
+	for _, want := range []string{
+		"<string>" + LaunchdLabel + "</string>",
+		"<string>/usr/local/bin/scout</string>",
+		"<string>run</string>",
+		"<string>nv.example:9090</string>",
+		"<string>60</string>",
+		"<string>/etc/scout/a&amp;b.pem</string>",
+		"<key>RunAtLoad</key>",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("plist missing %q:\n%s", want, s)
+		}
+	}
+}
+
+func TestInstallUninstallService_Launchd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "LaunchAgents", LaunchdLabel+".plist")
+
+	origPath, origCtl := launchdPlistPath, launchctl
+	t.Cleanup(func() { launchdPlistPath, launchctl = origPath, origCtl })
+
+	var calls []string
+	launchdPlistPath = func() (string, error) { return path, nil }
+	launchctl = func(args ...string) error {
+		calls = append(calls, strings.Join(args, " "))
+		return nil
+	}
+
+	if err := InstallService("/opt/scout", &scout.Config{ServerAddr: "srv:9090"}); err != nil {
+		t.Fatalf("InstallService: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("plist not written: %v", err)
+	}
+	if err := UninstallService(); err != nil {
+		t.Fatalf("UninstallService: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("plist still present: %v", err)
+	}
+
+	want := []string{"load -w " + path, "unload -w " + path}
+	if strings.Join(calls, ",") != strings.Join(want, ",") {
+		t.Errorf("launchctl calls = %v, want %v", calls, want)
+	}
+}
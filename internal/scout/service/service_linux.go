@@ -0,0 +1,139 @@
+//go:build linux
+
+package service
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/HerbHall/subnetree/internal/scout"
+	"go.uber.org/zap"
+)
+
+const (
+	// ServiceName is the Windows service name. Defined on all platforms for cross-platform code.
+	ServiceName = "SubNetreeScout"
+	// SystemdUnitName is the systemd unit installed by InstallService.
+	SystemdUnitName = "subnetree-scout.service"
+	// ServiceDescription is the description written to the unit file.
+	ServiceDescription = "SubNetree network monitoring agent"
+)
+
+// Overridable for tests.
+var (
+	systemdUnitPath = "/etc/systemd/system/" + SystemdUnitName
+	systemdRunDir   = "/run/systemd/system"
+	systemctl       = func(args ...string) error {
+		out, err := exec.Command("systemctl", args...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("systemctl %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+)
+
+// RunAsService is not needed under systemd; the unit runs "scout run" directly.
+func RunAsService(_ *scout.Config, _ *zap.Logger) error {
+	return fmt.Errorf("windows service mode is not supported on this platform")
+}
+
+// InstallService writes a systemd unit for the Scout agent, reloads the
+// systemd daemon, and enables the unit so it starts on boot.
+func InstallService(exePath string, config *scout.Config) error {
+	if _, err := os.Stat(systemdRunDir); err != nil {
+		return fmt.Errorf("systemd not detected on this host: service installation requires systemd")
+	}
+	if _, err := os.Stat(systemdUnitPath); err == nil {
+		return fmt.Errorf("service %s already exists", SystemdUnitName)
+	}
+
+	// systemd starts units with "/" as the working directory, so relative
+	// TLS paths given on the command line must be resolved now.
+	cfg := *config
+	for _, p := range []*string{&cfg.CertPath, &cfg.KeyPath, &cfg.CACertPath} {
+		if *p == "" {
+			continue
+		}
+		abs, err := filepath.Abs(*p)
+		if err != nil {
+			return fmt.Errorf("resolve path %q: %w", *p, err)
+		}
+		*p = abs
+	}
+
+	unit := renderSystemdUnit(exePath, buildServiceArgs(&cfg))
+	if err := os.WriteFile(systemdUnitPath, []byte(unit), 0o644); err != nil { //nolint:gosec // unit files must be world-readable
+		return fmt.Errorf("write unit file: %w", err)
+	}
+
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	if err := systemctl("enable", SystemdUnitName); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UninstallService stops and disables the systemd unit, removes the unit
+// file, and reloads the systemd daemon.
+func UninstallService() error {
+	if _, err := os.Stat(systemdUnitPath); err != nil {
+		return fmt.Errorf("service %s not found", SystemdUnitName)
+	}
+
+	if err := systemctl("disable", "--now", SystemdUnitName); err != nil {
+		// Non-fatal: the unit file is removed regardless.
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	if err := os.Remove(systemdUnitPath); err != nil {
+		return fmt.Errorf("remove unit file: %w", err)
+	}
+	return systemctl("daemon-reload")
+}
+
+// IsService always returns false on Linux; systemd needs no special handling.
+func IsService() (bool, error) {
+	return false, nil
+}
+
+// StartHint returns the command an operator runs to start the installed service.
+func StartHint() string {
+	return "systemctl start " + SystemdUnitName
+}
+
+// renderSystemdUnit builds the unit file contents for the given executable and arguments.
+func renderSystemdUnit(exePath string, args []string) string {
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, quoteUnitArg(exePath))
+	for _, a := range args {
+		parts = append(parts, quoteUnitArg(a))
+	}
+
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	fmt.Fprintf(&b, "Description=%s\n", ServiceDescription)
+	b.WriteString("Wants=network-online.target\n")
+	b.WriteString("After=network-online.target\n")
+	b.WriteString("\n[Service]\n")
+	b.WriteString("Type=simple\n")
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(parts, " "))
+	b.WriteString("Restart=on-failure\n")
+	b.WriteString("RestartSec=5\n")
+	b.WriteString("\n[Install]\n")
+	b.WriteString("WantedBy=multi-user.target\n")
+	return b.String()
+}
+
+// quoteUnitArg quotes an ExecStart argument when it contains characters that
+// systemd would otherwise split on or interpret.
+func quoteUnitArg(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\$%") {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", "$$", "%", "%%")
+	return `"` + r.Replace(s) + `"`
+}
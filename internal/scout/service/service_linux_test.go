@@ -0,0 +1,105 @@
+//go:build linux
+
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/HerbHall/subnetree/internal/scout"
+)
+
+func TestRenderSystemdUnit(t *testing.T) {
+	unit := renderSystemdUnit("/usr/local/bin/scout", buildServiceArgs(&scout.Config{
+		ServerAddr:    "nv.example:9090",
+		CheckInterval: 60,
+		CACertPath:    "/etc/scout/my ca.pem",
+		Insecure:      true,
+	}))
+
+	want := `ExecStart=/usr/local/bin/scout run --server nv.example:9090 --interval 60 --ca-cert "/etc/scout/my ca.pem" --insecure`
+	if !strings.Contains(unit, want+"\n") {
+		t.Errorf("unit missing %q:\n%s", want, unit)
+	}
+	for _, line := range []string{"[Service]", "Restart=on-failure", "WantedBy=multi-user.target"} {
+		if !strings.Contains(unit, line) {
+			t.Errorf("unit missing %q", line)
+		}
+	}
+}
+
+func TestQuoteUnitArg(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain"},
+		{"", `""`},
+		{"a b", `"a b"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{"$HOME", `"$$HOME"`},
+		{"100%", `"100%%"`},
+	}
+	for _, tc := range tests {
+		if got := quoteUnitArg(tc.in); got != tc.want {
+			t.Errorf("quoteUnitArg(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestInstallUninstallService_Systemd(t *testing.T) {
+	dir := t.TempDir()
+	runDir := filepath.Join(dir, "run")
+	if err := os.Mkdir(runDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	origPath, origRun, origCtl := systemdUnitPath, systemdRunDir, systemctl
+	t.Cleanup(func() { systemdUnitPath, systemdRunDir, systemctl = origPath, origRun, origCtl })
+
+	var calls []string
+	systemdUnitPath = filepath.Join(dir, SystemdUnitName)
+	systemdRunDir = runDir
+	systemctl = func(args ...string) error {
+		calls = append(calls, strings.Join(args, " "))
+		return nil
+	}
+
+	if err := InstallService("/opt/scout", &scout.Config{ServerAddr: "srv:9090", CheckInterval: 30}); err != nil {
+		t.Fatalf("InstallService: %v", err)
+	}
+	data, err := os.ReadFile(systemdUnitPath)
+	if err != nil {
+		t.Fatalf("read unit: %v", err)
+	}
+	if !strings.Contains(string(data), "ExecStart=/opt/scout run --server srv:9090 --interval 30\n") {
+		t.Errorf("unexpected unit:\n%s", data)
+	}
+	if err := InstallService("/opt/scout", &scout.Config{}); err == nil {
+		t.Error("second install should fail")
+	}
+
+	if err := UninstallService(); err != nil {
+		t.Fatalf("UninstallService: %v", err)
+	}
+	if _, err := os.Stat(systemdUnitPath); !os.IsNotExist(err) {
+		t.Errorf("unit file still present: %v", err)
+	}
+
+	want := []string{"daemon-reload", "enable " + SystemdUnitName, "disable --now " + SystemdUnitName, "daemon-reload"}
+	if strings.Join(calls, ",") != strings.Join(want, ",") {
+		t.Errorf("systemctl calls = %v, want %v", calls, want)
+	}
+}
+
+func TestInstallService_NoSystemd(t *testing.T) {
+	origRun := systemdRunDir
+	t.Cleanup(func() { systemdRunDir = origRun })
+	systemdRunDir = filepath.Join(t.TempDir(), "missing")
+
+	if err := InstallService("/opt/scout", &scout.Config{}); err == nil {
+		t.Error("expected error when systemd is absent")
+	}
+}
@@ -1,4 +1,4 @@
-//go:build !windows
+//go:build !windows && !linux && !darwin
 
 package service
 
@@ -31,3 +31,8 @@ func UninstallService() error {
 func IsService() (bool, error) {
 	return false, nil
 }
+
+// StartHint returns an empty string; there is no service to start on this platform.
+func StartHint() string {
+	return ""
+}
@@ -199,29 +199,7 @@ func IsService() (bool, error) {
 	return svc.IsWindowsService()
 }
 
-// buildServiceArgs constructs the command-line arguments for the service binary.
-// These are passed after the executable path when the SCM starts the service.
-func buildServiceArgs(config *scout.Config) []string {
-	args := []string{"run"}
-
-	if config.ServerAddr != "" {
-		args = append(args, "--server", config.ServerAddr)
-	}
-	if config.CheckInterval > 0 {
-		args = append(args, "--interval", fmt.Sprintf("%d", config.CheckInterval))
-	}
-	if config.CertPath != "" {
-		args = append(args, "--cert", config.CertPath)
-	}
-	if config.KeyPath != "" {
-		args = append(args, "--key", config.KeyPath)
-	}
-	if config.CACertPath != "" {
-		args = append(args, "--ca-cert", config.CACertPath)
-	}
-	if config.Insecure {
-		args = append(args, "--insecure")
-	}
-
-	return args
+// StartHint returns the command an operator runs to start the installed service.
+func StartHint() string {
+	return "sc start " + ServiceName
 }
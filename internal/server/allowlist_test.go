@@ -0,0 +1,97 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIPAllowlist_DisabledByDefault(t *testing.T) {
+	mw, err := NewIPAllowlistMiddleware(DefaultIPAllowlistConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := serve(mw(okHandler()), "GET", "/api/v1/vault/credentials", "203.0.113.5:1"); w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", w.Code)
+	}
+}
+
+func TestIPAllowlist_RequiresNetworks(t *testing.T) {
+	if _, err := NewIPAllowlistMiddleware(IPAllowlistConfig{Enabled: true}); err == nil {
+		t.Error("expected error without allowed_networks")
+	}
+	if _, err := NewIPAllowlistMiddleware(IPAllowlistConfig{Enabled: true, AllowedNetworks: []string{"not-a-cidr"}}); err == nil {
+		t.Error("expected error for an invalid network")
+	}
+}
+
+func TestIPAllowlist_ProtectsPrefixes(t *testing.T) {
+	cfg := DefaultIPAllowlistConfig()
+	cfg.Enabled = true
+	cfg.AllowedNetworks = []string{"192.168.10.0/24", "10.0.0.7"}
+	mw, err := NewIPAllowlistMiddleware(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := mw(okHandler())
+
+	tests := []struct {
+		name       string
+		path       string
+		remoteAddr string
+		want       int
+	}{
+		{"allowed subnet", "/api/v1/vault/credentials", "192.168.10.20:5000", http.StatusOK},
+		{"allowed address", "/api/v1/gateway/sessions", "10.0.0.7:5000", http.StatusOK},
+		{"outside network", "/api/v1/vault/credentials", "192.168.11.20:5000", http.StatusForbidden},
+		{"exact prefix", "/api/v1/users", "203.0.113.5:1", http.StatusForbidden},
+		{"admin route", "/api/v1/admin/backups", "203.0.113.5:1", http.StatusForbidden},
+		{"unprotected route", "/api/v1/recon/devices", "203.0.113.5:1", http.StatusOK},
+		{"prefix is not a substring match", "/api/v1/vaults", "203.0.113.5:1", http.StatusOK},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if w := serve(h, "GET", tc.path, tc.remoteAddr); w.Code != tc.want {
+				t.Errorf("status = %d, want %d", w.Code, tc.want)
+			}
+		})
+	}
+}
+
+func TestIPAllowlist_ForwardedFor(t *testing.T) {
+	mw, err := NewIPAllowlistMiddleware(IPAllowlistConfig{
+		Enabled:         true,
+		AllowedNetworks: []string{"192.168.10.0/24"},
+		PathPrefixes:    []string{"/api/v1/vault/"},
+		TrustedProxies:  []string{"127.0.0.1", "172.16.0.0/12"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := mw(okHandler())
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        string
+		want       int
+	}{
+		{"trusted proxy forwards allowed client", "127.0.0.1:4000", "192.168.10.20", http.StatusOK},
+		{"chained trusted proxies", "127.0.0.1:4000", "192.168.10.20, 172.16.0.3", http.StatusOK},
+		{"trusted proxy forwards other client", "127.0.0.1:4000", "203.0.113.5", http.StatusForbidden},
+		{"spoofed leftmost entry is ignored", "127.0.0.1:4000", "192.168.10.20, 203.0.113.5", http.StatusForbidden},
+		{"untrusted peer header is ignored", "203.0.113.5:4000", "192.168.10.20", http.StatusForbidden},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/vault/credentials", http.NoBody)
+			req.RemoteAddr = tc.remoteAddr
+			req.Header.Set("X-Forwarded-For", tc.xff)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != tc.want {
+				t.Errorf("status = %d, want %d", w.Code, tc.want)
+			}
+		})
+	}
+}
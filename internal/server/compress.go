@@ -0,0 +1,239 @@
+package server
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CompressionConfig configures response compression. Responses smaller than
+// MinSize bytes are sent uncompressed, since the encoding overhead outweighs
+// the savings.
+type CompressionConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	MinSize int  `mapstructure:"min_size"`
+}
+
+// DefaultCompressionConfig returns compression enabled for responses of at
+// least 1 KiB.
+func DefaultCompressionConfig() CompressionConfig {
+	return CompressionConfig{
+		Enabled: true,
+		MinSize: 1024,
+	}
+}
+
+// incompressibleTypes are content type prefixes that are already compressed.
+var incompressibleTypes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"font/woff",
+	"font/woff2",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/zstd",
+	"application/x-7z-compressed",
+	"application/x-bzip2",
+	"application/x-xz",
+	"application/octet-stream",
+	"text/event-stream",
+}
+
+// NewCompressionMiddleware builds a middleware that gzip- or
+// deflate-encodes responses according to the request's Accept-Encoding.
+// WebSocket upgrades, SSE streams, range requests, and already-compressed
+// content types pass through unchanged.
+func NewCompressionMiddleware(cfg CompressionConfig) Middleware {
+	if !cfg.Enabled {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	if cfg.MinSize < 0 {
+		cfg.MinSize = 0
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead || r.Header.Get("Upgrade") != "" ||
+				r.Header.Get("Range") != "" ||
+				strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Accept-Encoding")
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: w,
+				encoding:       encoding,
+				minSize:        cfg.MinSize,
+				status:         http.StatusOK,
+			}
+			defer cw.Close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiateEncoding picks gzip or deflate from an Accept-Encoding header,
+// preferring gzip when both are equally acceptable. It returns "" when
+// neither is accepted.
+func negotiateEncoding(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		switch name {
+		case "gzip", "*":
+			if q > bestQ || (q == bestQ && best != "gzip") {
+				best, bestQ = "gzip", q
+			}
+		case "deflate":
+			if q > bestQ {
+				best, bestQ = "deflate", q
+			}
+		}
+	}
+	return best
+}
+
+// compressWriter buffers the start of a response until minSize bytes have
+// been written, then decides whether to compress based on the final headers.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+
+	status      int
+	wroteHeader bool // WriteHeader called by the handler
+	decided     bool // headers sent downstream
+	buf         []byte
+	enc         io.WriteCloser
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.wroteHeader || w.decided {
+		return
+	}
+	if code >= 100 && code < 200 {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+	w.wroteHeader = true
+	if code == http.StatusNoContent || code == http.StatusNotModified {
+		_ = w.decide(false)
+	}
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.decided {
+		w.buf = append(w.buf, b...)
+		if len(w.buf) < w.minSize {
+			return len(b), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if w.enc != nil {
+		return w.enc.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// decide sends the headers downstream, compressing the response when want
+// is set and the headers allow it, and writes out the buffered bytes.
+func (w *compressWriter) decide(want bool) error {
+	w.decided = true
+	h := w.Header()
+	if h.Get("Content-Type") == "" && len(w.buf) > 0 {
+		h.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+
+	if want && w.compressible(h) {
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", w.encoding)
+		if w.encoding == "gzip" {
+			w.enc = gzip.NewWriter(w.ResponseWriter)
+		} else {
+			w.enc = zlib.NewWriter(w.ResponseWriter)
+		}
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if w.enc != nil {
+		_, err := w.enc.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// compressible reports whether the response headers permit compression.
+func (w *compressWriter) compressible(h http.Header) bool {
+	if h.Get("Content-Encoding") != "" || w.status == http.StatusPartialContent {
+		return false
+	}
+	ct := strings.ToLower(h.Get("Content-Type"))
+	for _, prefix := range incompressibleTypes {
+		if strings.HasPrefix(ct, prefix) && !strings.HasPrefix(ct, "image/svg+xml") {
+			return false
+		}
+	}
+	return true
+}
+
+// Flush sends any buffered data downstream. A response flushed before
+// reaching minSize is streamed uncompressed.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide(len(w.buf) >= w.minSize)
+	}
+	if f, ok := w.enc.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Close writes out a response that never reached minSize and terminates
+// the compressed stream.
+func (w *compressWriter) Close() {
+	if !w.decided {
+		_ = w.decide(false)
+	}
+	if w.enc != nil {
+		_ = w.enc.Close()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
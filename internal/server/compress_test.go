@@ -0,0 +1,119 @@
+package server
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompressionMiddleware(t *testing.T) {
+	large := strings.Repeat(`{"id":"device"},`, 200)
+	small := `{"id":"device"}`
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		header         map[string]string // extra request headers
+		contentType    string
+		body           string
+		wantEncoding   string
+	}{
+		{"gzip", "gzip, deflate", nil, "application/json", large, "gzip"},
+		{"deflate only", "deflate", nil, "application/json", large, "deflate"},
+		{"deflate preferred by q", "gzip;q=0.5, deflate", nil, "application/json", large, "deflate"},
+		{"gzip refused", "gzip;q=0", nil, "application/json", large, ""},
+		{"no accept-encoding", "", nil, "application/json", large, ""},
+		{"below min size", "gzip", nil, "application/json", small, ""},
+		{"already compressed type", "gzip", nil, "image/png", large, ""},
+		{"sse", "gzip", map[string]string{"Accept": "text/event-stream"}, "text/event-stream", large, ""},
+		{"websocket upgrade", "gzip", map[string]string{"Upgrade": "websocket"}, "application/json", large, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.Header().Set("Content-Length", "999")
+				_, _ = io.WriteString(w, tt.body[:len(tt.body)/2])
+				_, _ = io.WriteString(w, tt.body[len(tt.body)/2:])
+			})
+			handler := NewCompressionMiddleware(DefaultCompressionConfig())(inner)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/recon/devices", http.NoBody)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Fatalf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+
+			var r io.Reader = w.Body
+			switch tt.wantEncoding {
+			case "gzip":
+				zr, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatalf("gzip reader: %v", err)
+				}
+				r = zr
+			case "deflate":
+				zr, err := zlib.NewReader(w.Body)
+				if err != nil {
+					t.Fatalf("zlib reader: %v", err)
+				}
+				r = zr
+			}
+			if tt.wantEncoding != "" && w.Header().Get("Content-Length") != "" {
+				t.Error("Content-Length should be removed from compressed responses")
+			}
+			body, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("read body: %v", err)
+			}
+			if string(body) != tt.body {
+				t.Errorf("body length = %d, want %d", len(body), len(tt.body))
+			}
+		})
+	}
+}
+
+func TestCompressionMiddleware_Disabled(t *testing.T) {
+	inner := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, strings.Repeat("a", 4096))
+	})
+	handler := NewCompressionMiddleware(CompressionConfig{Enabled: false})(inner)
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("Content-Encoding = %q, want none when disabled", got)
+	}
+}
+
+func TestCompressionMiddleware_StatusPreserved(t *testing.T) {
+	inner := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, "missing")
+	})
+	handler := NewCompressionMiddleware(DefaultCompressionConfig())(inner)
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound || w.Body.String() != "missing" {
+		t.Errorf("got %d %q, want 404 %q", w.Code, w.Body.String(), "missing")
+	}
+}
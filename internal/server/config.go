@@ -30,13 +30,16 @@ func LoadConfig(configPath string) (*viper.Viper, error) {
 	v.SetDefault("logging.format", "json")
 	v.SetDefault("database.driver", "sqlite")
 	v.SetDefault("database.dsn", "./data/subnetree.db")
+	v.SetDefault("events.persist", false)
+	v.SetDefault("events.retention", "168h")
 
 	// Plugin defaults
 	v.SetDefault("plugins.recon.enabled", true)
 	v.SetDefault("plugins.recon.scan_timeout", "5m")
 	v.SetDefault("plugins.recon.ping_timeout", "2s")
 	v.SetDefault("plugins.recon.ping_count", 3)
-	v.SetDefault("plugins.recon.concurrency", 64)
+	// plugins.recon.concurrency is left unset so the hardware tier default
+	// applies; the recon module falls back to 64 when no tier matches.
 	v.SetDefault("plugins.recon.arp_enabled", true)
 	v.SetDefault("plugins.recon.device_lost_after", "24h")
 	v.SetDefault("plugins.pulse.enabled", true)
@@ -50,6 +53,7 @@ func LoadConfig(configPath string) (*viper.Viper, error) {
 	v.SetDefault("plugins.dispatch.enabled", true)
 	v.SetDefault("plugins.vault.enabled", true)
 	v.SetDefault("plugins.vault.audit_retention_period", "2160h")
+	v.SetDefault("plugins.vault.access_log_retention_period", "2160h")
 	v.SetDefault("plugins.vault.maintenance_interval", "1h")
 	v.SetDefault("plugins.gateway.enabled", true)
 	v.SetDefault("plugins.gateway.session_timeout", "30m")
@@ -57,6 +61,9 @@ func LoadConfig(configPath string) (*viper.Viper, error) {
 	v.SetDefault("plugins.gateway.audit_retention_days", 90)
 	v.SetDefault("plugins.gateway.maintenance_interval", "5m")
 	v.SetDefault("plugins.gateway.default_proxy_port", 80)
+	v.SetDefault("plugins.gateway.record_sessions", false)
+	v.SetDefault("plugins.gateway.recording_dir", "data/recordings")
+	v.SetDefault("plugins.gateway.sftp_max_file_size", 100<<20)
 	v.SetDefault("plugins.webhook.enabled", true)
 	v.SetDefault("plugins.webhook.url", "")
 	v.SetDefault("plugins.webhook.timeout", "10s")
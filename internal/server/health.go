@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/plugin"
+)
+
+// Component and overall health states reported by GET /api/v1/health.
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+	HealthDown     = "down"
+)
+
+// componentCheckTimeout bounds each component check so one hung dependency
+// cannot stall the whole health report.
+const componentCheckTimeout = 3 * time.Second
+
+// ComponentHealth is the health of a single server component.
+type ComponentHealth struct {
+	Name     string            `json:"name" example:"database"`
+	Type     string            `json:"type" example:"core"` // "core" or "plugin"
+	Status   string            `json:"status" example:"ok"` // "ok", "degraded", or "down"
+	Critical bool              `json:"critical"`            // A critical component being down takes the server down
+	Message  string            `json:"message,omitempty"`
+	Details  map[string]string `json:"details,omitempty"`
+}
+
+// HealthCheckFunc reports the health of a component. It has the same shape
+// as plugin.HealthChecker so core components report like plugins do.
+type HealthCheckFunc func(ctx context.Context) plugin.HealthStatus
+
+// componentCheck is a registered non-plugin health check.
+type componentCheck struct {
+	name     string
+	critical bool
+	check    HealthCheckFunc
+}
+
+// AddHealthCheck registers a core component in the GET /api/v1/health
+// report. When critical is true, the component being down reports the
+// whole server as down. Call before Start.
+func (s *Server) AddHealthCheck(name string, critical bool, check HealthCheckFunc) {
+	s.healthChecks = append(s.healthChecks, componentCheck{name: name, critical: critical, check: check})
+}
+
+// checkComponents runs the database check, every plugin's HealthChecker, and
+// the registered core checks concurrently, returning results in that order
+// together with the overall rollup.
+func (s *Server) checkComponents(ctx context.Context) (string, []ComponentHealth) {
+	type job struct {
+		result ComponentHealth
+		check  HealthCheckFunc
+	}
+	var jobs []job
+
+	if s.ready != nil {
+		ready := s.ready
+		jobs = append(jobs, job{
+			result: ComponentHealth{Name: "database", Type: "core", Critical: true},
+			check: func(ctx context.Context) plugin.HealthStatus {
+				if err := ready(ctx); err != nil {
+					return plugin.HealthStatus{Status: "unhealthy", Message: err.Error()}
+				}
+				return plugin.HealthStatus{Status: "healthy"}
+			},
+		})
+	}
+	for _, p := range s.plugins.All() {
+		info := p.Info()
+		j := job{result: ComponentHealth{Name: info.Name, Type: "plugin", Critical: info.Required}}
+		if hc, ok := p.(plugin.HealthChecker); ok {
+			j.check = hc.Health
+		}
+		jobs = append(jobs, j)
+	}
+	for _, c := range s.healthChecks {
+		jobs = append(jobs, job{
+			result: ComponentHealth{Name: c.name, Type: "core", Critical: c.critical},
+			check:  c.check,
+		})
+	}
+
+	components := make([]ComponentHealth, len(jobs))
+	var wg sync.WaitGroup
+	for i := range jobs {
+		components[i] = jobs[i].result
+		if jobs[i].check == nil {
+			// Plugins without a health check are healthy once started.
+			components[i].Status = HealthOK
+			continue
+		}
+		wg.Add(1)
+		go func(c *ComponentHealth, check HealthCheckFunc) {
+			defer wg.Done()
+			runComponentCheck(ctx, c, check)
+		}(&components[i], jobs[i].check)
+	}
+	wg.Wait()
+
+	return rollupHealth(components), components
+}
+
+// runComponentCheck runs check with a timeout and records the result in c.
+func runComponentCheck(ctx context.Context, c *ComponentHealth, check HealthCheckFunc) {
+	ctx, cancel := context.WithTimeout(ctx, componentCheckTimeout)
+	defer cancel()
+
+	done := make(chan plugin.HealthStatus, 1)
+	go func() { done <- check(ctx) }()
+
+	select {
+	case hs := <-done:
+		c.Status = normalizeHealthStatus(hs.Status)
+		c.Message = hs.Message
+		c.Details = hs.Details
+	case <-ctx.Done():
+		c.Status = HealthDown
+		c.Message = "health check timed out"
+	}
+}
+
+// normalizeHealthStatus maps the status strings plugins report ("healthy",
+// "ok", "degraded", "unhealthy") onto ok, degraded, or down.
+func normalizeHealthStatus(status string) string {
+	switch status {
+	case "healthy", "ok", "":
+		return HealthOK
+	case "degraded":
+		return HealthDegraded
+	default:
+		return HealthDown
+	}
+}
+
+// rollupHealth derives the overall status: down if any critical component
+// is down, degraded if any component is not ok, ok otherwise.
+func rollupHealth(components []ComponentHealth) string {
+	overall := HealthOK
+	for i := range components {
+		switch {
+		case components[i].Status == HealthOK:
+		case components[i].Status == HealthDown && components[i].Critical:
+			return HealthDown
+		default:
+			overall = HealthDegraded
+		}
+	}
+	return overall
+}
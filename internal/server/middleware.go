@@ -4,17 +4,19 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
-	"net"
 	"net/http"
 	"strconv"
-	"strings"
-	"sync"
 	"time"
 
+	"github.com/HerbHall/subnetree/internal/tracing"
 	"github.com/HerbHall/subnetree/internal/version"
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
-	"golang.org/x/time/rate"
 )
 
 // Prometheus HTTP metrics.
@@ -76,6 +78,42 @@ func RequestIDMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// TracingMiddleware starts a server span for each request, continuing any
+// W3C trace context sent by the caller. Spans are named after the route
+// pattern matched in mux, so traces group by endpoint rather than raw path.
+// When tracing is disabled the global no-op provider makes this a
+// pass-through.
+func TracingMiddleware(mux *http.ServeMux) Middleware {
+	tracer := tracing.Tracer("server")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			name := r.Method
+			if _, pattern := mux.Handler(r); pattern != "" {
+				name = pattern
+			}
+
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracer.Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("url.path", r.URL.Path),
+					attribute.String("request.id", RequestID(ctx)),
+				),
+			)
+			defer span.End()
+
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r.WithContext(ctx))
+
+			span.SetAttributes(attribute.Int("http.response.status_code", sw.status))
+			if sw.status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(sw.status))
+			}
+		})
+	}
+}
+
 // LoggingMiddleware logs each HTTP request with duration and status, and
 // records Prometheus metrics (request count and duration histogram).
 // Paths in skipPaths are excluded from logging but still recorded in metrics.
@@ -154,95 +192,6 @@ func RecoveryMiddleware(logger *zap.Logger) Middleware {
 	}
 }
 
-// RateLimitMiddleware enforces per-IP rate limiting.
-// Requests to paths in skipPaths are not rate limited.
-func RateLimitMiddleware(rps float64, burst int, skipPaths []string) Middleware {
-	rl := &ipRateLimiter{
-		rateVal: rate.Limit(rps),
-		burst:   burst,
-	}
-	skip := make(map[string]bool, len(skipPaths))
-	for _, p := range skipPaths {
-		skip[p] = true
-	}
-
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if skip[r.URL.Path] {
-				next.ServeHTTP(w, r)
-				return
-			}
-
-			ip := clientIP(r)
-			if !rl.allow(ip) {
-				RateLimited(w, "rate limit exceeded", r.URL.Path)
-				return
-			}
-
-			next.ServeHTTP(w, r)
-		})
-	}
-}
-
-// ipRateLimiter tracks per-IP token-bucket rate limiters.
-type ipRateLimiter struct {
-	mu       sync.Mutex
-	limiters map[string]*rateLimitEntry
-	rateVal  rate.Limit
-	burst    int
-}
-
-type rateLimitEntry struct {
-	limiter  *rate.Limiter
-	lastSeen time.Time
-}
-
-func (l *ipRateLimiter) allow(ip string) bool {
-	l.mu.Lock()
-	defer l.mu.Unlock()
-
-	if l.limiters == nil {
-		l.limiters = make(map[string]*rateLimitEntry)
-	}
-
-	e, ok := l.limiters[ip]
-	if !ok {
-		if len(l.limiters) >= 10000 {
-			l.cleanup()
-		}
-		e = &rateLimitEntry{limiter: rate.NewLimiter(l.rateVal, l.burst)}
-		l.limiters[ip] = e
-	}
-	e.lastSeen = time.Now()
-
-	return e.limiter.Allow()
-}
-
-// cleanup removes entries not seen in the last 10 minutes.
-// Must be called with l.mu held.
-func (l *ipRateLimiter) cleanup() {
-	cutoff := time.Now().Add(-10 * time.Minute)
-	for ip, e := range l.limiters {
-		if e.lastSeen.Before(cutoff) {
-			delete(l.limiters, ip)
-		}
-	}
-}
-
-// clientIP extracts the client IP from the request.
-func clientIP(r *http.Request) string {
-	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		if parts := strings.SplitN(xff, ",", 2); len(parts) > 0 {
-			return strings.TrimSpace(parts[0])
-		}
-	}
-	ip, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil {
-		return r.RemoteAddr
-	}
-	return ip
-}
-
 // statusWriter wraps ResponseWriter to capture the status code.
 type statusWriter struct {
 	http.ResponseWriter
@@ -265,6 +214,12 @@ func (w *statusWriter) Write(b []byte) (int, error) {
 	return w.ResponseWriter.Write(b)
 }
 
+// Unwrap exposes the underlying writer to http.ResponseController, so
+// streaming handlers can flush and extend their write deadline.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // generateID creates a random 32-character hex string for request IDs.
 func generateID() string {
 	b := make([]byte, 16)
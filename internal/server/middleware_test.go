@@ -5,6 +5,11 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.uber.org/zap"
 )
 
@@ -148,6 +153,62 @@ func TestRecoveryMiddleware_NoPanic(t *testing.T) {
 	}
 }
 
+// installTestTracer routes spans to an in-memory recorder for the test.
+func installTestTracer(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	prevTP, prevProp := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevProp)
+	})
+	return rec
+}
+
+func TestTracingMiddleware_NamesSpanByPattern(t *testing.T) {
+	rec := installTestTracer(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/devices/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	handler := TracingMiddleware(mux)(mux)
+
+	req := httptest.NewRequest("GET", "/api/v1/devices/abc", http.NoBody)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /api/v1/devices/{id}" {
+		t.Errorf("span name = %q, want route pattern", span.Name())
+	}
+	if got := span.Parent().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("parent trace ID = %q, want propagated traceparent", got)
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("span status = %v, want Error for a 500", span.Status().Code)
+	}
+}
+
+func TestTracingMiddleware_UnmatchedRouteUsesMethod(t *testing.T) {
+	rec := installTestTracer(t)
+
+	mux := http.NewServeMux()
+	handler := TracingMiddleware(mux)(mux)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/nope", http.NoBody))
+
+	spans := rec.Ended()
+	if len(spans) != 1 || spans[0].Name() != "GET" {
+		t.Fatalf("spans = %v, want one span named GET", spans)
+	}
+}
+
 func TestRateLimitMiddleware_AllowsTraffic(t *testing.T) {
 	inner := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -253,22 +314,35 @@ func TestChain(t *testing.T) {
 	}
 }
 
-func TestClientIP_RemoteAddr(t *testing.T) {
+func TestForwardedClientIP_RemoteAddr(t *testing.T) {
 	req := httptest.NewRequest("GET", "/", http.NoBody)
 	req.RemoteAddr = "192.168.1.100:12345"
 
-	if ip := clientIP(req); ip != "192.168.1.100" {
-		t.Errorf("clientIP = %q, want %q", ip, "192.168.1.100")
+	if ip := forwardedClientIP(req, nil); ip != "192.168.1.100" {
+		t.Errorf("forwardedClientIP = %q, want %q", ip, "192.168.1.100")
 	}
 }
 
-func TestClientIP_XForwardedFor(t *testing.T) {
+func TestForwardedClientIP_XForwardedFor(t *testing.T) {
+	proxies, err := parseNetworks([]string{"127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	req := httptest.NewRequest("GET", "/", http.NoBody)
 	req.RemoteAddr = "127.0.0.1:12345"
 	req.Header.Set("X-Forwarded-For", "203.0.113.50, 70.41.3.18")
 
-	if ip := clientIP(req); ip != "203.0.113.50" {
-		t.Errorf("clientIP = %q, want %q", ip, "203.0.113.50")
+	// The rightmost hop is the one the trusted proxy saw; the rest were
+	// sent by the client.
+	if ip := forwardedClientIP(req, proxies); ip != "70.41.3.18" {
+		t.Errorf("forwardedClientIP = %q, want %q", ip, "70.41.3.18")
+	}
+
+	// From an untrusted peer the header is ignored.
+	req.RemoteAddr = "10.0.0.9:12345"
+	if ip := forwardedClientIP(req, proxies); ip != "10.0.0.9" {
+		t.Errorf("forwardedClientIP = %q, want %q", ip, "10.0.0.9")
 	}
 }
 
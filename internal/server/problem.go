@@ -65,6 +65,17 @@ func InternalError(w http.ResponseWriter, detail, instance string) {
 	})
 }
 
+// Forbidden writes a 403 problem response.
+func Forbidden(w http.ResponseWriter, detail, instance string) {
+	WriteProblem(w, Problem{
+		Type:     ProblemTypeForbidden,
+		Title:    "Forbidden",
+		Status:   http.StatusForbidden,
+		Detail:   detail,
+		Instance: instance,
+	})
+}
+
 // RateLimited writes a 429 problem response.
 func RateLimited(w http.ResponseWriter, detail, instance string) {
 	WriteProblem(w, Problem{
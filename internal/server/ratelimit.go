@@ -0,0 +1,352 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimitConfig configures the API rate limiter. Each client gets a token
+// bucket refilled at RequestsPerSecond and holding up to Burst requests.
+type RateLimitConfig struct {
+	Enabled           bool     `mapstructure:"enabled"`
+	RequestsPerSecond float64  `mapstructure:"requests_per_second"`
+	Burst             int      `mapstructure:"burst"`
+	TrustedNetworks   []string `mapstructure:"trusted_networks"` // CIDRs exempt from limiting
+	SkipPaths         []string `mapstructure:"skip_paths"`
+	// TrustedProxies are reverse proxies whose X-Forwarded-For header is
+	// believed. Without them the connection's address is always used.
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+	// FailedAuthRequestsPerSecond and FailedAuthBurst limit, per client IP,
+	// requests that authentication rejects with 401. They are checked before
+	// authentication runs, since rejected requests never reach the per-user
+	// limit. A zero burst disables this limit.
+	FailedAuthRequestsPerSecond float64 `mapstructure:"failed_auth_requests_per_second"`
+	FailedAuthBurst             int     `mapstructure:"failed_auth_burst"`
+	// Routes overrides the limit for matching requests. The longest
+	// matching prefix wins; a matched request draws only from its route's
+	// bucket.
+	Routes []RouteRateLimit `mapstructure:"routes"`
+
+	// KeyFunc identifies the client a request is charged to. When it
+	// returns "", the client IP is used.
+	KeyFunc func(r *http.Request) string `mapstructure:"-"`
+}
+
+// RouteRateLimit is a per-route rate limit override.
+type RouteRateLimit struct {
+	Method            string  `mapstructure:"method"` // Empty matches any method.
+	PathPrefix        string  `mapstructure:"path_prefix"`
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+	Burst             int     `mapstructure:"burst"`
+}
+
+// DefaultRateLimitConfig returns the default limits: 100 req/s with a burst
+// of 200 per client, 20 rejected credentials per client IP refilled every
+// 5 seconds, and stricter limits on scans and traceroutes, which send
+// traffic onto the network.
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		Enabled:                     true,
+		RequestsPerSecond:           100,
+		Burst:                       200,
+		FailedAuthRequestsPerSecond: 0.2,
+		FailedAuthBurst:             20,
+		SkipPaths:                   []string{"/healthz", "/readyz", "/metrics"},
+		Routes: []RouteRateLimit{
+			{Method: http.MethodPost, PathPrefix: "/api/v1/recon/scan", RequestsPerSecond: 0.2, Burst: 5},
+			{Method: http.MethodPost, PathPrefix: "/api/v1/recon/traceroute", RequestsPerSecond: 0.5, Burst: 5},
+		},
+	}
+}
+
+// RateLimitMiddleware enforces per-IP rate limiting.
+// Requests to paths in skipPaths are not rate limited.
+func RateLimitMiddleware(rps float64, burst int, skipPaths []string) Middleware {
+	mw, _ := NewRateLimitMiddleware(RateLimitConfig{
+		Enabled:           true,
+		RequestsPerSecond: rps,
+		Burst:             burst,
+		SkipPaths:         skipPaths,
+	})
+	return mw
+}
+
+// RateLimiter holds the two halves of API rate limiting, which sit on
+// either side of authentication.
+type RateLimiter struct {
+	// FailedAuth runs before authentication and throttles clients whose
+	// credentials keep being rejected.
+	FailedAuth Middleware
+	// Limit runs after authentication so it can charge the user.
+	Limit Middleware
+}
+
+// NewRateLimiter builds both rate limiting middlewares from cfg.
+func NewRateLimiter(cfg RateLimitConfig) (*RateLimiter, error) {
+	limit, err := NewRateLimitMiddleware(cfg)
+	if err != nil {
+		return nil, err
+	}
+	failedAuth, err := NewFailedAuthLimitMiddleware(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &RateLimiter{FailedAuth: failedAuth, Limit: limit}, nil
+}
+
+// NewRateLimitMiddleware builds a rate limiting middleware from cfg.
+// Limited requests get a 429 response with a Retry-After header.
+func NewRateLimitMiddleware(cfg RateLimitConfig) (Middleware, error) {
+	if !cfg.Enabled {
+		return func(next http.Handler) http.Handler { return next }, nil
+	}
+
+	clients, err := newRateLimitClients(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultBucket := &rateLimiter{rateVal: rate.Limit(cfg.RequestsPerSecond), burst: cfg.Burst}
+	routeBuckets := make([]*rateLimiter, len(cfg.Routes))
+	for i, rt := range cfg.Routes {
+		if rt.PathPrefix == "" || rt.Burst <= 0 {
+			return nil, fmt.Errorf("rate limit route %d: path_prefix and a positive burst are required", i)
+		}
+		routeBuckets[i] = &rateLimiter{rateVal: rate.Limit(rt.RequestsPerSecond), burst: rt.Burst}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip, limited := clients.resolve(r)
+			if !limited {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := ""
+			if cfg.KeyFunc != nil {
+				key = cfg.KeyFunc(r)
+			}
+			if key == "" {
+				key = "ip:" + ip
+			}
+
+			bucket := defaultBucket
+			if i := matchRoute(cfg.Routes, r); i >= 0 {
+				bucket = routeBuckets[i]
+			}
+
+			if ok, retryAfter := bucket.allow(key); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				RateLimited(w, "rate limit exceeded", r.URL.Path)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// NewFailedAuthLimitMiddleware builds the limit on rejected credentials
+// from cfg. It must run before authentication: a client IP with no failure
+// allowance left gets a 429 without its credentials being checked, and
+// every 401 from further in is charged to the IP.
+func NewFailedAuthLimitMiddleware(cfg RateLimitConfig) (Middleware, error) {
+	if !cfg.Enabled || cfg.FailedAuthBurst <= 0 {
+		return func(next http.Handler) http.Handler { return next }, nil
+	}
+
+	clients, err := newRateLimitClients(cfg)
+	if err != nil {
+		return nil, err
+	}
+	bucket := &rateLimiter{rateVal: rate.Limit(cfg.FailedAuthRequestsPerSecond), burst: cfg.FailedAuthBurst}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip, limited := clients.resolve(r)
+			if !limited {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := "ip:" + ip
+			if ok, retryAfter := bucket.peek(key); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				RateLimited(w, "too many failed authentication attempts", r.URL.Path)
+				return
+			}
+
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r)
+			if sw.status == http.StatusUnauthorized {
+				bucket.allow(key)
+			}
+		})
+	}, nil
+}
+
+// rateLimitClients decides which requests are limited and which client
+// address they come from.
+type rateLimitClients struct {
+	trusted []*net.IPNet
+	proxies []*net.IPNet
+	skip    map[string]bool
+}
+
+func newRateLimitClients(cfg RateLimitConfig) (*rateLimitClients, error) {
+	trusted, err := parseNetworks(cfg.TrustedNetworks)
+	if err != nil {
+		return nil, fmt.Errorf("rate limit trusted network %w", err)
+	}
+	proxies, err := parseNetworks(cfg.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("rate limit trusted proxy %w", err)
+	}
+	skip := make(map[string]bool, len(cfg.SkipPaths))
+	for _, p := range cfg.SkipPaths {
+		skip[p] = true
+	}
+	return &rateLimitClients{trusted: trusted, proxies: proxies, skip: skip}, nil
+}
+
+// resolve returns the client IP of r and whether r is subject to limiting.
+// X-Forwarded-For is only believed from a trusted proxy, so a client
+// cannot get a fresh bucket, or a trusted address, by sending the header.
+func (c *rateLimitClients) resolve(r *http.Request) (ip string, limited bool) {
+	if c.skip[r.URL.Path] {
+		return "", false
+	}
+	ip = forwardedClientIP(r, c.proxies)
+	return ip, !isTrusted(c.trusted, ip)
+}
+
+// matchRoute returns the index of the route override with the longest
+// matching path prefix, or -1.
+func matchRoute(routes []RouteRateLimit, r *http.Request) int {
+	best, bestLen := -1, 0
+	for i, rt := range routes {
+		if rt.Method != "" && !strings.EqualFold(rt.Method, r.Method) {
+			continue
+		}
+		if strings.HasPrefix(r.URL.Path, rt.PathPrefix) && len(rt.PathPrefix) > bestLen {
+			best, bestLen = i, len(rt.PathPrefix)
+		}
+	}
+	return best
+}
+
+// parseNetworks parses CIDRs and bare addresses, which match only
+// themselves.
+func parseNetworks(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", cidr, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+func isTrusted(nets []*net.IPNet, ip string) bool {
+	if len(nets) == 0 {
+		return false
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, n := range nets {
+		if n.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// rateLimiter tracks per-client token-bucket rate limiters.
+type rateLimiter struct {
+	mu       sync.Mutex
+	limiters map[string]*rateLimitEntry
+	rateVal  rate.Limit
+	burst    int
+}
+
+type rateLimitEntry struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// allow reports whether a request for key may proceed, taking a token if
+// so. When it may not, it also returns the number of seconds until a token
+// is available.
+func (l *rateLimiter) allow(key string) (ok bool, retryAfter int) {
+	return l.reserve(key, true)
+}
+
+// peek is like allow but leaves the token in the bucket.
+func (l *rateLimiter) peek(key string) (ok bool, retryAfter int) {
+	return l.reserve(key, false)
+}
+
+func (l *rateLimiter) reserve(key string, take bool) (ok bool, retryAfter int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limiters == nil {
+		l.limiters = make(map[string]*rateLimitEntry)
+	}
+
+	e, found := l.limiters[key]
+	if !found {
+		if len(l.limiters) >= 10000 {
+			l.cleanup()
+		}
+		e = &rateLimitEntry{limiter: rate.NewLimiter(l.rateVal, l.burst)}
+		l.limiters[key] = e
+	}
+	now := time.Now()
+	e.lastSeen = now
+
+	res := e.limiter.ReserveN(now, 1)
+	if !res.OK() {
+		return false, 60
+	}
+	delay := res.DelayFrom(now)
+	if delay == 0 {
+		if !take {
+			res.CancelAt(now)
+		}
+		return true, 0
+	}
+	res.CancelAt(now)
+	return false, max(1, int(math.Ceil(delay.Seconds())))
+}
+
+// cleanup removes entries not seen in the last 10 minutes.
+// Must be called with l.mu held.
+func (l *rateLimiter) cleanup() {
+	cutoff := time.Now().Add(-10 * time.Minute)
+	for key, e := range l.limiters {
+		if e.lastSeen.Before(cutoff) {
+			delete(l.limiters, key)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func serve(h http.Handler, method, path, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, http.NoBody)
+	req.RemoteAddr = remoteAddr
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimit_RetryAfter(t *testing.T) {
+	mw, err := NewRateLimitMiddleware(RateLimitConfig{Enabled: true, RequestsPerSecond: 0.5, Burst: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := mw(okHandler())
+
+	if w := serve(h, "GET", "/api/v1/recon/devices", "10.0.0.1:1"); w.Code != http.StatusOK {
+		t.Fatalf("first request: status = %d, want 200", w.Code)
+	}
+	w := serve(h, "GET", "/api/v1/recon/devices", "10.0.0.1:1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request: status = %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want %q", got, "2")
+	}
+}
+
+func TestRateLimit_RouteOverride(t *testing.T) {
+	mw, err := NewRateLimitMiddleware(RateLimitConfig{
+		Enabled: true, RequestsPerSecond: 1000, Burst: 1000,
+		Routes: []RouteRateLimit{{Method: "POST", PathPrefix: "/api/v1/recon/scan", RequestsPerSecond: 0.01, Burst: 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := mw(okHandler())
+
+	if w := serve(h, "POST", "/api/v1/recon/scan", "10.0.0.2:1"); w.Code != http.StatusOK {
+		t.Fatalf("first scan: status = %d, want 200", w.Code)
+	}
+	if w := serve(h, "POST", "/api/v1/recon/scan", "10.0.0.2:1"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("second scan: status = %d, want 429", w.Code)
+	}
+	// Other routes and methods use the default bucket.
+	if w := serve(h, "GET", "/api/v1/recon/scans", "10.0.0.2:1"); w.Code != http.StatusOK {
+		t.Errorf("list scans: status = %d, want 200", w.Code)
+	}
+}
+
+func TestRateLimit_TrustedNetwork(t *testing.T) {
+	mw, err := NewRateLimitMiddleware(RateLimitConfig{
+		Enabled: true, RequestsPerSecond: 0.01, Burst: 1,
+		TrustedNetworks: []string{"192.168.10.0/24", "127.0.0.1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := mw(okHandler())
+
+	for i := 0; i < 5; i++ {
+		if w := serve(h, "GET", "/api/v1/plugins", "192.168.10.7:1"); w.Code != http.StatusOK {
+			t.Fatalf("trusted request %d: status = %d, want 200", i, w.Code)
+		}
+		if w := serve(h, "GET", "/api/v1/plugins", "127.0.0.1:1"); w.Code != http.StatusOK {
+			t.Fatalf("loopback request %d: status = %d, want 200", i, w.Code)
+		}
+	}
+}
+
+func TestRateLimit_ForwardedFor(t *testing.T) {
+	mw, err := NewRateLimitMiddleware(RateLimitConfig{
+		Enabled: true, RequestsPerSecond: 0.01, Burst: 1,
+		TrustedNetworks: []string{"192.168.10.0/24"},
+		TrustedProxies:  []string{"10.0.0.100"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := mw(okHandler())
+
+	send := func(addr, xff string) int {
+		req := httptest.NewRequest("GET", "/api/v1/plugins", http.NoBody)
+		req.RemoteAddr = addr
+		req.Header.Set("X-Forwarded-For", xff)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// A direct client cannot pick a fresh or trusted address via the header.
+	if code := send("10.0.0.6:1", "203.0.113.1"); code != http.StatusOK {
+		t.Fatalf("first: status = %d, want 200", code)
+	}
+	if code := send("10.0.0.6:1", "203.0.113.2"); code != http.StatusTooManyRequests {
+		t.Errorf("spoofed fresh address: status = %d, want 429", code)
+	}
+	if code := send("10.0.0.6:1", "192.168.10.7"); code != http.StatusTooManyRequests {
+		t.Errorf("spoofed trusted address: status = %d, want 429", code)
+	}
+
+	// Behind a trusted proxy each forwarded client has its own bucket.
+	if code := send("10.0.0.100:1", "203.0.113.3"); code != http.StatusOK {
+		t.Errorf("proxied client: status = %d, want 200", code)
+	}
+	if code := send("10.0.0.100:1", "203.0.113.4"); code != http.StatusOK {
+		t.Errorf("second proxied client: status = %d, want 200", code)
+	}
+}
+
+func TestFailedAuthLimit(t *testing.T) {
+	mw, err := NewFailedAuthLimitMiddleware(RateLimitConfig{
+		Enabled: true, FailedAuthRequestsPerSecond: 0.01, FailedAuthBurst: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	send := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/v1/plugins", http.NoBody)
+		req.RemoteAddr = "10.0.0.7:1"
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	// Successful requests are not charged.
+	for i := 0; i < 5; i++ {
+		if w := send("good"); w.Code != http.StatusOK {
+			t.Fatalf("good request %d: status = %d, want 200", i, w.Code)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if w := send("bad"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("bad request %d: status = %d, want 401", i, w.Code)
+		}
+	}
+	w := send("bad")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("after burst: status = %d, want 429", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("missing Retry-After header")
+	}
+	// The client address stays blocked until failures drain, whatever it sends.
+	if w := send("good"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("good request after burst: status = %d, want 429", w.Code)
+	}
+}
+
+func TestRateLimit_KeyFunc(t *testing.T) {
+	mw, err := NewRateLimitMiddleware(RateLimitConfig{
+		Enabled: true, RequestsPerSecond: 0.01, Burst: 1,
+		KeyFunc: func(r *http.Request) string { return r.Header.Get("X-Test-User") },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := mw(okHandler())
+
+	send := func(user, addr string) int {
+		req := httptest.NewRequest("GET", "/api/v1/plugins", http.NoBody)
+		req.RemoteAddr = addr
+		req.Header.Set("X-Test-User", user)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := send("alice", "10.0.0.3:1"); code != http.StatusOK {
+		t.Fatalf("alice first: status = %d, want 200", code)
+	}
+	// Same user from another IP shares the bucket.
+	if code := send("alice", "10.0.0.4:1"); code != http.StatusTooManyRequests {
+		t.Errorf("alice second: status = %d, want 429", code)
+	}
+	// A different user from the same IP has its own bucket.
+	if code := send("bob", "10.0.0.3:1"); code != http.StatusOK {
+		t.Errorf("bob: status = %d, want 200", code)
+	}
+}
+
+func TestRateLimit_Disabled(t *testing.T) {
+	mw, err := NewRateLimitMiddleware(RateLimitConfig{Enabled: false, RequestsPerSecond: 0.01, Burst: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := mw(okHandler())
+	for i := 0; i < 3; i++ {
+		if w := serve(h, "GET", "/api/v1/plugins", "10.0.0.5:1"); w.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200", i, w.Code)
+		}
+	}
+}
+
+func TestNewRateLimitMiddleware_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  RateLimitConfig
+	}{
+		{"bad cidr", RateLimitConfig{Enabled: true, Burst: 1, TrustedNetworks: []string{"not-a-net/99"}}},
+		{"route without prefix", RateLimitConfig{Enabled: true, Burst: 1, Routes: []RouteRateLimit{{Burst: 1}}}},
+		{"bad proxy", RateLimitConfig{Enabled: true, Burst: 1, TrustedProxies: []string{"proxy.local"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRateLimitMiddleware(tt.cfg); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
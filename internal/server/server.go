@@ -40,6 +40,8 @@ type Server struct {
 	logger     *zap.Logger
 	mux        *http.ServeMux
 	ready      ReadinessChecker
+
+	healthChecks []componentCheck
 }
 
 // SimpleRouteRegistrar can register routes without middleware.
@@ -50,10 +52,16 @@ type SimpleRouteRegistrar interface {
 // New creates a new Server with middleware and routes.
 // The auth parameter is optional; pass nil to disable authentication.
 // The dashboard parameter is optional; pass nil to disable dashboard serving.
+// The rateLimit parameter is optional; pass nil for DefaultRateLimitConfig.
+// Its failed-auth limit runs before auth and its main limit after, so the
+// latter can key limits by the authenticated user.
+// The compress parameter is optional; pass nil for DefaultCompressionConfig.
+// The allowlist parameter is optional; pass nil to allow all client addresses.
+// It runs before auth so restricted routes are refused even with a valid token.
 // When devMode is true, Swagger UI is served at /swagger/.
 // When demoMode is true, all write operations (POST/PUT/DELETE/PATCH) are blocked.
 // Additional route registrars can be passed to register extra API routes.
-func New(addr string, plugins PluginSource, logger *zap.Logger, ready ReadinessChecker, auth RouteRegistrar, dashboard http.Handler, devMode, demoMode bool, extraRoutes ...SimpleRouteRegistrar) *Server {
+func New(addr string, plugins PluginSource, logger *zap.Logger, ready ReadinessChecker, auth RouteRegistrar, dashboard http.Handler, rateLimit *RateLimiter, compress, allowlist Middleware, devMode, demoMode bool, extraRoutes ...SimpleRouteRegistrar) *Server {
 	mux := http.NewServeMux()
 
 	s := &Server{
@@ -88,14 +96,28 @@ func New(addr string, plugins PluginSource, logger *zap.Logger, ready ReadinessC
 	middlewares := []Middleware{
 		RecoveryMiddleware(logger),
 		RequestIDMiddleware,
+		TracingMiddleware(mux),
 		LoggingMiddleware(logger, []string{"/healthz", "/readyz", "/metrics"}),
+	}
+	if compress == nil {
+		compress = NewCompressionMiddleware(DefaultCompressionConfig())
+	}
+	middlewares = append(middlewares,
+		compress,
 		SecurityHeadersMiddleware,
 		VersionHeaderMiddleware,
-		RateLimitMiddleware(100, 200, []string{"/healthz", "/readyz", "/metrics"}),
+	)
+	if allowlist != nil {
+		middlewares = append(middlewares, allowlist)
 	}
+	if rateLimit == nil {
+		rateLimit, _ = NewRateLimiter(DefaultRateLimitConfig())
+	}
+	middlewares = append(middlewares, rateLimit.FailedAuth)
 	if auth != nil {
 		middlewares = append(middlewares, auth.Middleware())
 	}
+	middlewares = append(middlewares, rateLimit.Limit)
 	if demoMode {
 		middlewares = append(middlewares, DemoMiddleware)
 		logger.Warn("DEMO MODE ACTIVE: all write operations are blocked")
@@ -182,9 +204,10 @@ func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
 
 // HealthResponse is the response for GET /health.
 type HealthResponse struct {
-	Status  string            `json:"status" example:"ok"`
-	Service string            `json:"service" example:"subnetree"`
-	Version map[string]string `json:"version"`
+	Status     string            `json:"status" example:"ok"` // "ok", "degraded", or "down"
+	Service    string            `json:"service" example:"subnetree"`
+	Version    map[string]string `json:"version"`
+	Components []ComponentHealth `json:"components"`
 }
 
 // PluginResponse describes a registered plugin.
@@ -197,17 +220,24 @@ type PluginResponse struct {
 // handleHealth returns detailed health information (versioned API endpoint).
 //
 //	@Summary		Health check
-//	@Description	Returns service health status with version information.
+//	@Description	Returns overall and per-component health with version information. Responds 503 when a critical component is down.
 //	@Tags			system
 //	@Produce		json
 //	@Success		200	{object}	HealthResponse
+//	@Failure		503	{object}	HealthResponse
 //	@Router			/health [get]
-func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	status, components := s.checkComponents(r.Context())
+
 	w.Header().Set("Content-Type", "application/json")
+	if status == HealthDown {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
 	_ = json.NewEncoder(w).Encode(HealthResponse{
-		Status:  "ok",
-		Service: "subnetree",
-		Version: version.Map(),
+		Status:     status,
+		Service:    "subnetree",
+		Version:    version.Map(),
+		Components: components,
 	})
 }
 
@@ -57,7 +57,7 @@ func newTestServer(ready ReadinessChecker) *Server {
 			}},
 		},
 	}
-	return New("127.0.0.1:0", plugins, logger, ready, nil, nil, false, false)
+	return New("127.0.0.1:0", plugins, logger, ready, nil, nil, nil, nil, nil, false, false)
 }
 
 func TestHandleHealthz(t *testing.T) {
@@ -159,6 +159,102 @@ func TestHandleHealth(t *testing.T) {
 	}
 }
 
+// healthStubPlugin is a stubPlugin that implements plugin.HealthChecker.
+type healthStubPlugin struct {
+	stubPlugin
+	status plugin.HealthStatus
+}
+
+func (s *healthStubPlugin) Health(_ context.Context) plugin.HealthStatus { return s.status }
+
+func TestHandleHealth_Components(t *testing.T) {
+	tests := []struct {
+		name       string
+		ready      ReadinessChecker
+		pluginHS   string
+		required   bool
+		extraHS    string
+		wantStatus string
+		wantCode   int
+	}{
+		{"all healthy", nil, "healthy", false, "healthy", HealthOK, http.StatusOK},
+		{"optional plugin down", nil, "unhealthy", false, "healthy", HealthDegraded, http.StatusOK},
+		{"core check degraded", nil, "ok", false, "degraded", HealthDegraded, http.StatusOK},
+		{"required plugin down", nil, "unhealthy", true, "healthy", HealthDown, http.StatusServiceUnavailable},
+		{
+			"database down",
+			func(_ context.Context) error { return errors.New("database unreachable") },
+			"healthy", false, "healthy", HealthDown, http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			plugins := &mockPluginSource{
+				plugins: []plugin.Plugin{
+					&healthStubPlugin{
+						stubPlugin: stubPlugin{info: plugin.PluginInfo{Name: "recon", Required: tc.required}},
+						status:     plugin.HealthStatus{Status: tc.pluginHS},
+					},
+					&stubPlugin{info: plugin.PluginInfo{Name: "docs"}},
+				},
+			}
+			srv := New("127.0.0.1:0", plugins, zap.NewNop(), tc.ready, nil, nil, nil, nil, nil, false, false)
+			srv.AddHealthCheck("scheduler", false, func(_ context.Context) plugin.HealthStatus {
+				return plugin.HealthStatus{Status: tc.extraHS}
+			})
+
+			req := httptest.NewRequest("GET", "/api/v1/health", http.NoBody)
+			w := httptest.NewRecorder()
+			srv.mux.ServeHTTP(w, req)
+
+			if w.Code != tc.wantCode {
+				t.Fatalf("status code = %d, want %d", w.Code, tc.wantCode)
+			}
+			var body HealthResponse
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if body.Status != tc.wantStatus {
+				t.Errorf("status = %q, want %q", body.Status, tc.wantStatus)
+			}
+
+			names := make([]string, len(body.Components))
+			for i, c := range body.Components {
+				names[i] = c.Name
+			}
+			want := []string{"recon", "docs", "scheduler"}
+			if tc.ready != nil {
+				want = append([]string{"database"}, want...)
+			}
+			if strings.Join(names, ",") != strings.Join(want, ",") {
+				t.Errorf("components = %v, want %v", names, want)
+			}
+		})
+	}
+}
+
+func TestHandleHealth_CheckTimeout(t *testing.T) {
+	srv := newTestServer(nil)
+	srv.AddHealthCheck("stuck", true, func(ctx context.Context) plugin.HealthStatus {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		return plugin.HealthStatus{Status: "healthy"}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	status, components := srv.checkComponents(ctx)
+
+	if status != HealthDown {
+		t.Errorf("status = %q, want %q", status, HealthDown)
+	}
+	last := components[len(components)-1]
+	if last.Status != HealthDown || last.Message != "health check timed out" {
+		t.Errorf("stuck component = %+v, want down with timeout message", last)
+	}
+}
+
 func TestHandlePlugins(t *testing.T) {
 	srv := newTestServer(nil)
 
@@ -240,7 +336,7 @@ func TestPluginRoutes_Mounted(t *testing.T) {
 			},
 		},
 	}
-	srv := New("127.0.0.1:0", plugins, logger, nil, nil, nil, false, false)
+	srv := New("127.0.0.1:0", plugins, logger, nil, nil, nil, nil, nil, nil, false, false)
 
 	req := httptest.NewRequest("POST", "/api/v1/recon/scan", http.NoBody)
 	w := httptest.NewRecorder()
@@ -273,7 +369,7 @@ func newTestServerWithListener(t *testing.T, slowHandler http.HandlerFunc) (*Ser
 	}
 
 	addr := listener.Addr().String()
-	srv := New(addr, plugins, logger, nil, nil, nil, false, false)
+	srv := New(addr, plugins, logger, nil, nil, nil, nil, nil, nil, false, false)
 
 	return srv, listener, addr
 }
@@ -59,7 +59,7 @@ const deviceColumns = `id, hostname, ip_addresses, mac_address, manufacturer,
 
 func (r *SQLiteDeviceRepository) Get(ctx context.Context, id string) (*models.Device, error) {
 	row := r.db.QueryRowContext(ctx,
-		`SELECT `+deviceColumns+` FROM recon_devices WHERE id = ?`, id)
+		`SELECT `+deviceColumns+` FROM recon_devices WHERE id = ? AND deleted_at IS NULL`, id)
 	d, err := scanDevice(row)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -88,8 +88,9 @@ func (r *SQLiteDeviceRepository) List(ctx context.Context, filter DeviceFilter,
 		}
 	}
 
-	// Build WHERE clause with parameterized placeholders.
-	where := "1=1"
+	// Build WHERE clause with parameterized placeholders. Devices in the
+	// recon trash are never listed.
+	where := "deleted_at IS NULL"
 	var args []any
 
 	if filter.Status != "" {
@@ -220,7 +221,7 @@ func (r *SQLiteDeviceRepository) Update(ctx context.Context, device *models.Devi
 			hostname = ?, ip_addresses = ?, mac_address = ?, manufacturer = ?,
 			device_type = ?, os = ?, status = ?, discovery_method = ?, agent_id = ?,
 			last_seen = ?, notes = ?, tags = ?, custom_fields = ?
-		WHERE id = ?`,
+		WHERE id = ? AND deleted_at IS NULL`,
 		device.Hostname, string(ipsJSON), device.MACAddress, device.Manufacturer,
 		string(device.DeviceType), device.OS, string(device.Status), string(device.DiscoveryMethod), device.AgentID,
 		device.LastSeen, device.Notes, string(tagsJSON), string(cfJSON),
@@ -236,9 +237,19 @@ func (r *SQLiteDeviceRepository) Update(ctx context.Context, device *models.Devi
 	return nil
 }
 
+// Delete moves a device to the recon trash and detaches its children, the
+// same as deleting it through the recon API. The recon module restores or
+// purges it from there.
 func (r *SQLiteDeviceRepository) Delete(ctx context.Context, id string) error {
-	res, err := r.db.ExecContext(ctx,
-		`DELETE FROM recon_devices WHERE id = ?`, id)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE recon_devices SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`,
+		time.Now().UTC(), id)
 	if err != nil {
 		return fmt.Errorf("delete device: %w", err)
 	}
@@ -246,7 +257,12 @@ func (r *SQLiteDeviceRepository) Delete(ctx context.Context, id string) error {
 	if n == 0 {
 		return ErrNotFound
 	}
-	return nil
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE recon_devices SET parent_device_id = '' WHERE parent_device_id = ?`, id,
+	); err != nil {
+		return fmt.Errorf("detach child devices: %w", err)
+	}
+	return tx.Commit()
 }
 
 // scanDevice scans a single *sql.Row into a Device.
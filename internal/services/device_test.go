@@ -35,7 +35,9 @@ var reconMigrations = []plugin.Migration{
 					last_seen        DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 					notes            TEXT NOT NULL DEFAULT '',
 					tags             TEXT NOT NULL DEFAULT '[]',
-					custom_fields    TEXT NOT NULL DEFAULT '{}'
+					custom_fields    TEXT NOT NULL DEFAULT '{}',
+					parent_device_id TEXT NOT NULL DEFAULT '',
+					deleted_at       DATETIME
 				)`,
 				`CREATE INDEX idx_recon_devices_mac ON recon_devices(mac_address)`,
 				`CREATE INDEX idx_recon_devices_status ON recon_devices(status)`,
@@ -194,6 +196,62 @@ func TestSQLiteDeviceRepository_Delete(t *testing.T) {
 	}
 }
 
+func TestSQLiteDeviceRepository_DeleteMovesToTrash(t *testing.T) {
+	repo, db := newDeviceRepo(t)
+	ctx := context.Background()
+
+	parent := testutil.NewDevice()
+	child := testutil.NewDevice()
+	for _, d := range []*models.Device{&parent, &child} {
+		if err := repo.Create(ctx, d); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	if _, err := db.ExecContext(ctx,
+		`UPDATE recon_devices SET parent_device_id = ? WHERE id = ?`, parent.ID, child.ID,
+	); err != nil {
+		t.Fatalf("set parent: %v", err)
+	}
+
+	if err := repo.Delete(ctx, parent.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	var deletedAt sql.NullTime
+	if err := db.QueryRowContext(ctx,
+		`SELECT deleted_at FROM recon_devices WHERE id = ?`, parent.ID,
+	).Scan(&deletedAt); err != nil {
+		t.Fatalf("row should remain in trash: %v", err)
+	}
+	if !deletedAt.Valid {
+		t.Error("deleted_at not set")
+	}
+
+	var parentID string
+	if err := db.QueryRowContext(ctx,
+		`SELECT parent_device_id FROM recon_devices WHERE id = ?`, child.ID,
+	).Scan(&parentID); err != nil {
+		t.Fatalf("query child: %v", err)
+	}
+	if parentID != "" {
+		t.Errorf("child parent_device_id = %q, want detached", parentID)
+	}
+
+	result, err := repo.List(ctx, services.DeviceFilter{}, services.ListOptions{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if result.Total != 1 || len(result.Items) != 1 || result.Items[0].ID != child.ID {
+		t.Errorf("List = %d devices (total %d), want only the child", len(result.Items), result.Total)
+	}
+	if err := repo.Update(ctx, &parent); err != services.ErrNotFound {
+		t.Errorf("Update trashed = %v, want ErrNotFound", err)
+	}
+	if err := repo.Delete(ctx, parent.ID); err != services.ErrNotFound {
+		t.Errorf("second Delete = %v, want ErrNotFound", err)
+	}
+}
+
 func TestSQLiteDeviceRepository_DeleteNotFound(t *testing.T) {
 	repo, _ := newDeviceRepo(t)
 	ctx := context.Background()
@@ -1,10 +1,14 @@
 package services
 
 import (
+	"encoding/json"
 	"net"
 	"strings"
 )
 
+// ScanInterfacesKey is the settings key holding the interfaces selected for scanning.
+const ScanInterfacesKey = "scan_interface"
+
 // NetworkInterface represents a network interface with its properties.
 type NetworkInterface struct {
 	Name      string `json:"name" example:"eth0"`
@@ -89,6 +93,75 @@ func (s *InterfaceService) ListNetworkInterfaces() ([]NetworkInterface, error) {
 	return result, nil
 }
 
+// SubnetsForInterfaces returns the IPv4 subnets of the named interfaces,
+// de-duplicated and in the order given. Names that are not present on this
+// host are ignored.
+func (s *InterfaceService) SubnetsForInterfaces(names []string) ([]string, error) {
+	ifaces, err := s.ListNetworkInterfaces()
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]string, len(ifaces))
+	for i := range ifaces {
+		byName[ifaces[i].Name] = ifaces[i].Subnet
+	}
+
+	seen := make(map[string]bool, len(names))
+	subnets := make([]string, 0, len(names))
+	for _, name := range names {
+		subnet, ok := byName[name]
+		if !ok || subnet == "" || seen[subnet] {
+			continue
+		}
+		seen[subnet] = true
+		subnets = append(subnets, subnet)
+	}
+	return subnets, nil
+}
+
+// ParseScanInterfaces decodes the stored scan interface setting. Older
+// releases stored a single bare interface name; current releases store a
+// JSON array. An empty value yields an empty list (auto-detect).
+func ParseScanInterfaces(value string) []string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return []string{}
+	}
+	if strings.HasPrefix(value, "[") {
+		var names []string
+		if err := json.Unmarshal([]byte(value), &names); err == nil {
+			return NormalizeInterfaceNames(names)
+		}
+	}
+	return []string{value}
+}
+
+// EncodeScanInterfaces encodes interface names for storage under ScanInterfacesKey.
+func EncodeScanInterfaces(names []string) string {
+	names = NormalizeInterfaceNames(names)
+	if len(names) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(names)
+	return string(data)
+}
+
+// NormalizeInterfaceNames trims names and drops blanks and duplicates while
+// preserving order.
+func NormalizeInterfaceNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		n = strings.TrimSpace(n)
+		if n == "" || seen[n] {
+			continue
+		}
+		seen[n] = true
+		out = append(out, n)
+	}
+	return out
+}
+
 // itoa converts an integer to a string (simple implementation to avoid strconv import).
 func itoa(n int) string {
 	if n == 0 {
@@ -77,3 +77,40 @@ func TestNetworkInterface_MACFormat(t *testing.T) {
 		}
 	}
 }
+
+func TestParseScanInterfaces(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{"empty", "", []string{}},
+		{"legacy bare string", "eth0", []string{"eth0"}},
+		{"json array", `["eth0","eth1"]`, []string{"eth0", "eth1"}},
+		{"json array with blanks and dupes", `["eth0"," ","eth0","eth1"]`, []string{"eth0", "eth1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := services.ParseScanInterfaces(tt.value)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseScanInterfaces(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("ParseScanInterfaces(%q)[%d] = %q, want %q", tt.value, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeScanInterfaces_RoundTrip(t *testing.T) {
+	if got := services.EncodeScanInterfaces(nil); got != "" {
+		t.Errorf("EncodeScanInterfaces(nil) = %q, want empty", got)
+	}
+	encoded := services.EncodeScanInterfaces([]string{"eth0", "eth1"})
+	got := services.ParseScanInterfaces(encoded)
+	if len(got) != 2 || got[0] != "eth0" || got[1] != "eth1" {
+		t.Errorf("round trip = %v, want [eth0 eth1]", got)
+	}
+}
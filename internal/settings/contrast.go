@@ -0,0 +1,160 @@
+package settings
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// minContrastRatio is the WCAG 2.x AA threshold for normal-size text.
+const minContrastRatio = 4.5
+
+// contrastPair names a foreground/background token pair that must stay readable.
+type contrastPair struct {
+	fg string
+	bg string
+}
+
+// contrastPairs are the key text-on-surface combinations checked when saving a theme.
+var contrastPairs = []contrastPair{
+	{fg: "text-primary", bg: "bg-root"},
+	{fg: "text-primary", bg: "bg-card"},
+	{fg: "input-text", bg: "input-bg"},
+	{fg: "btn-primary-text", bg: "btn-primary-bg"},
+	{fg: "btn-danger-text", bg: "btn-danger-bg"},
+}
+
+// ContrastIssue describes a token pair that falls below the minimum contrast ratio.
+type ContrastIssue struct {
+	Foreground string  `json:"foreground"`
+	Background string  `json:"background"`
+	Ratio      float64 `json:"ratio"`
+}
+
+// checkContrast returns every configured pair whose contrast ratio is below
+// minContrastRatio. Pairs where either token is missing or is not a plain hex
+// color (e.g. rgba() overlays) are skipped, since they cannot be evaluated in
+// isolation. When touched is non-nil, only pairs with at least one token in
+// touched are checked so that unrelated edits to an existing theme are not
+// rejected.
+func checkContrast(t *ThemeTokens, touched map[string]bool) []ContrastIssue {
+	flat := make(map[string]string)
+	for _, m := range []map[string]string{t.Backgrounds, t.Text, t.Buttons, t.Inputs} {
+		for k, v := range m {
+			flat[k] = v
+		}
+	}
+
+	var issues []ContrastIssue
+	for _, p := range contrastPairs {
+		if touched != nil && !touched[p.fg] && !touched[p.bg] {
+			continue
+		}
+		fg, ok := parseHexColor(flat[p.fg])
+		if !ok {
+			continue
+		}
+		bg, ok := parseHexColor(flat[p.bg])
+		if !ok {
+			continue
+		}
+		ratio := contrastRatio(fg, bg)
+		if ratio < minContrastRatio {
+			issues = append(issues, ContrastIssue{
+				Foreground: p.fg,
+				Background: p.bg,
+				Ratio:      math.Round(ratio*100) / 100,
+			})
+		}
+	}
+	return issues
+}
+
+// tokenKeys returns the set of token names present in t.
+func tokenKeys(t *ThemeTokens) map[string]bool {
+	keys := make(map[string]bool)
+	for _, m := range []map[string]string{t.Backgrounds, t.Text, t.Buttons, t.Inputs} {
+		for k := range m {
+			keys[k] = true
+		}
+	}
+	return keys
+}
+
+// changedTokenKeys returns the contrast-checked tokens whose value differs
+// between old and updated, so a replaced theme is checked like a patch.
+func changedTokenKeys(old, updated *ThemeTokens) map[string]bool {
+	before := make(map[string]string)
+	for _, m := range []map[string]string{old.Backgrounds, old.Text, old.Buttons, old.Inputs} {
+		for k, v := range m {
+			before[k] = v
+		}
+	}
+	keys := make(map[string]bool)
+	for _, m := range []map[string]string{updated.Backgrounds, updated.Text, updated.Buttons, updated.Inputs} {
+		for k, v := range m {
+			if before[k] != v {
+				keys[k] = true
+			}
+		}
+	}
+	return keys
+}
+
+// formatContrastIssues renders issues as a single human-readable detail string.
+func formatContrastIssues(issues []ContrastIssue) string {
+	parts := make([]string, 0, len(issues))
+	for _, is := range issues {
+		parts = append(parts, fmt.Sprintf("%s on %s is %.2f:1", is.Foreground, is.Background, is.Ratio))
+	}
+	return fmt.Sprintf("insufficient contrast (minimum %.1f:1): %s", minContrastRatio, strings.Join(parts, "; "))
+}
+
+// parseHexColor parses #rgb or #rrggbb into 0-255 channel values.
+func parseHexColor(s string) ([3]float64, bool) {
+	var rgb [3]float64
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "#") {
+		return rgb, false
+	}
+	s = s[1:]
+	switch len(s) {
+	case 3:
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	case 6:
+	default:
+		return rgb, false
+	}
+	for i := 0; i < 3; i++ {
+		v, err := strconv.ParseUint(s[i*2:i*2+2], 16, 8)
+		if err != nil {
+			return rgb, false
+		}
+		rgb[i] = float64(v)
+	}
+	return rgb, true
+}
+
+// relativeLuminance computes the WCAG relative luminance of an sRGB color.
+func relativeLuminance(rgb [3]float64) float64 {
+	var lin [3]float64
+	for i, c := range rgb {
+		c /= 255
+		if c <= 0.03928 {
+			lin[i] = c / 12.92
+		} else {
+			lin[i] = math.Pow((c+0.055)/1.055, 2.4)
+		}
+	}
+	return 0.2126*lin[0] + 0.7152*lin[1] + 0.0722*lin[2]
+}
+
+// contrastRatio returns the WCAG contrast ratio between two colors (1 to 21).
+func contrastRatio(a, b [3]float64) float64 {
+	la, lb := relativeLuminance(a), relativeLuminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
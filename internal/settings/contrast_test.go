@@ -0,0 +1,64 @@
+package settings
+
+import (
+	"math"
+	"testing"
+)
+
+func TestContrastRatio(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want float64
+	}{
+		{"black on white", "#000000", "#ffffff", 21},
+		{"identical", "#336699", "#336699", 1},
+		{"short hex", "#fff", "#000", 21},
+		{"mid gray on white", "#777777", "#ffffff", 4.48},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, ok := parseHexColor(tt.a)
+			if !ok {
+				t.Fatalf("parseHexColor(%q) failed", tt.a)
+			}
+			b, ok := parseHexColor(tt.b)
+			if !ok {
+				t.Fatalf("parseHexColor(%q) failed", tt.b)
+			}
+			got := contrastRatio(a, b)
+			if math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("contrastRatio(%s, %s) = %.3f, want %.2f", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHexColor_Invalid(t *testing.T) {
+	for _, s := range []string{"", "red", "#12", "#gggggg", "rgba(0, 0, 0, 0.5)"} {
+		if _, ok := parseHexColor(s); ok {
+			t.Errorf("parseHexColor(%q) ok = true, want false", s)
+		}
+	}
+}
+
+func TestCheckContrast(t *testing.T) {
+	tokens := ThemeTokens{
+		Backgrounds: map[string]string{"bg-root": "#101010", "bg-card": "rgba(0, 0, 0, 0.5)"},
+		Text:        map[string]string{"text-primary": "#151515"},
+		Buttons:     map[string]string{"btn-primary-bg": "#000000", "btn-primary-text": "#ffffff"},
+	}
+
+	issues := checkContrast(&tokens, nil)
+	if len(issues) != 1 {
+		t.Fatalf("len(issues) = %d, want 1: %+v", len(issues), issues)
+	}
+	if issues[0].Foreground != "text-primary" || issues[0].Background != "bg-root" {
+		t.Errorf("issue = %+v, want text-primary on bg-root", issues[0])
+	}
+
+	// Only pairs touching the given keys are evaluated.
+	if got := checkContrast(&tokens, map[string]bool{"btn-primary-bg": true}); len(got) != 0 {
+		t.Errorf("checkContrast with unrelated touched keys = %+v, want none", got)
+	}
+}
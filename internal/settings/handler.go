@@ -5,25 +5,40 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/HerbHall/subnetree/internal/auth"
 	"github.com/HerbHall/subnetree/internal/services"
 	"go.uber.org/zap"
 )
 
-// ScanInterfaceRequest represents a request to set the scan interface.
-// @Description Request body for setting the network scan interface.
+// ScanInterfaceRequest represents a request to set the scan interfaces.
+// @Description Request body for setting the network scan interfaces. interface_name accepts either a single name or an array of names.
 type ScanInterfaceRequest struct {
-	InterfaceName string `json:"interface_name" example:"eth0"`
+	InterfaceName  any      `json:"interface_name" swaggertype:"array,string" example:"eth0,eth1"`
+	InterfaceNames []string `json:"interface_names,omitempty" example:"eth0,eth1"`
 }
 
 // ScanInterfaceResponse represents the current scan interface setting.
-// @Description Response containing the configured scan interface.
+// @Description Response containing the configured scan interfaces. interface_name holds the first entry for older clients.
 type ScanInterfaceResponse struct {
-	InterfaceName string `json:"interface_name" example:"eth0"`
+	InterfaceName  string   `json:"interface_name" example:"eth0"`
+	InterfaceNames []string `json:"interface_names" example:"eth0,eth1"`
+}
+
+// newScanInterfaceResponse builds a response from a normalized name list.
+func newScanInterfaceResponse(names []string) ScanInterfaceResponse {
+	resp := ScanInterfaceResponse{InterfaceNames: names}
+	if len(names) > 0 {
+		resp.InterfaceName = names[0]
+	}
+	return resp
 }
 
 // SettingsProblemDetail represents an RFC 7807 error response for settings endpoints.
@@ -35,6 +50,22 @@ type SettingsProblemDetail struct {
 	Detail string `json:"detail" example:"interface not found: eth99"`
 }
 
+// SettingsExport is a portable snapshot of all settings key/value pairs.
+// @Description Snapshot of application settings for backup and restore.
+type SettingsExport struct {
+	Version    int               `json:"version" example:"1"`
+	ExportedAt string            `json:"exported_at" example:"2026-01-15T10:30:00Z"`
+	Settings   map[string]string `json:"settings"`
+}
+
+// SettingsImportResponse summarizes the result of a settings import.
+// @Description Counts of settings written and left untouched by an import.
+type SettingsImportResponse struct {
+	Imported  int `json:"imported" example:"12"`
+	Unchanged int `json:"unchanged" example:"3"`
+	Skipped   int `json:"skipped" example:"1"`
+}
+
 // ThemeLayer identifies a composable layer of a theme.
 type ThemeLayer string
 
@@ -68,6 +99,23 @@ type ThemeTokens struct {
 	Effects     map[string]string `json:"effects,omitempty"`
 }
 
+// categories returns a pointer to each token category keyed by its JSON name.
+func (t *ThemeTokens) categories() map[string]*map[string]string {
+	return map[string]*map[string]string{
+		"backgrounds": &t.Backgrounds,
+		"text":        &t.Text,
+		"borders":     &t.Borders,
+		"buttons":     &t.Buttons,
+		"inputs":      &t.Inputs,
+		"sidebar":     &t.Sidebar,
+		"status":      &t.Status,
+		"charts":      &t.Charts,
+		"typography":  &t.Typography,
+		"spacing":     &t.Spacing,
+		"effects":     &t.Effects,
+	}
+}
+
 // ThemeDefinition represents a complete or partial theme configuration.
 // @Description A theme with metadata, layer declarations, and CSS token overrides.
 type ThemeDefinition struct {
@@ -84,9 +132,10 @@ type ThemeDefinition struct {
 }
 
 // ActiveThemeResponse represents the currently active theme reference.
-// @Description Response containing the active theme ID.
+// @Description Response containing the active theme ID and whether it comes from the caller's own preference or the global default.
 type ActiveThemeResponse struct {
 	ThemeID string `json:"theme_id" example:"builtin-forest-dark"`
+	Scope   string `json:"scope" example:"user" enums:"user,global"`
 }
 
 // ActiveThemeRequest represents a request to set the active theme.
@@ -118,6 +167,10 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/v1/settings/scan-interface", h.handleGetScanInterface)
 	mux.HandleFunc("POST /api/v1/settings/scan-interface", h.handleSetScanInterface)
 
+	// Backup and restore
+	mux.HandleFunc("GET /api/v1/settings/export", h.handleExportSettings)
+	mux.HandleFunc("POST /api/v1/settings/import", h.handleImportSettings)
+
 	// Theme endpoints (literal paths before wildcard)
 	mux.HandleFunc("GET /api/v1/settings/themes", h.handleListThemes)
 	mux.HandleFunc("GET /api/v1/settings/themes/active", h.handleGetActiveTheme)
@@ -126,6 +179,8 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/v1/settings/themes/{id}", h.handleGetTheme)
 	mux.HandleFunc("PUT /api/v1/settings/themes/{id}", h.handleUpdateTheme)
 	mux.HandleFunc("DELETE /api/v1/settings/themes/{id}", h.handleDeleteTheme)
+	mux.HandleFunc("POST /api/v1/settings/themes/{id}/duplicate", h.handleDuplicateTheme)
+	mux.HandleFunc("GET /api/v1/settings/themes/{id}/css", h.handleGetThemeCSS)
 }
 
 // handleListInterfaces returns all available network interfaces.
@@ -148,21 +203,21 @@ func (h *Handler) handleListInterfaces(w http.ResponseWriter, _ *http.Request) {
 	writeJSON(w, http.StatusOK, interfaces)
 }
 
-// handleGetScanInterface returns the currently configured scan interface.
+// handleGetScanInterface returns the currently configured scan interfaces.
 //
 //	@Summary		Get scan interface
-//	@Description	Get the currently configured network interface for scanning.
+//	@Description	Get the currently configured network interfaces for scanning.
 //	@Tags			settings
 //	@Produce		json
 //	@Success		200	{object}	ScanInterfaceResponse	"Current scan interface"
 //	@Failure		500	{object}	SettingsProblemDetail	"Internal server error"
 //	@Router			/settings/scan-interface [get]
 func (h *Handler) handleGetScanInterface(w http.ResponseWriter, r *http.Request) {
-	setting, err := h.settings.Get(r.Context(), "scan_interface")
+	setting, err := h.settings.Get(r.Context(), services.ScanInterfacesKey)
 	if err != nil {
 		if err == services.ErrNotFound {
 			// No interface configured yet -- return empty response
-			writeJSON(w, http.StatusOK, map[string]string{"interface_name": ""})
+			writeJSON(w, http.StatusOK, newScanInterfaceResponse([]string{}))
 			return
 		}
 		h.logger.Error("failed to get scan interface setting", zap.Error(err))
@@ -170,13 +225,13 @@ func (h *Handler) handleGetScanInterface(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]string{"interface_name": setting.Value})
+	writeJSON(w, http.StatusOK, newScanInterfaceResponse(services.ParseScanInterfaces(setting.Value)))
 }
 
-// handleSetScanInterface saves the selected scan interface.
+// handleSetScanInterface saves the selected scan interfaces.
 //
 //	@Summary		Set scan interface
-//	@Description	Configure which network interface to use for scanning.
+//	@Description	Configure which network interfaces to use for scanning. Accepts a single name or an array; an empty value resets to auto-detect.
 //	@Tags			settings
 //	@Accept			json
 //	@Produce		json
@@ -187,41 +242,257 @@ func (h *Handler) handleGetScanInterface(w http.ResponseWriter, r *http.Request)
 //	@Router			/settings/scan-interface [post]
 func (h *Handler) handleSetScanInterface(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		InterfaceName string `json:"interface_name"`
+		InterfaceName  json.RawMessage `json:"interface_name"`
+		InterfaceNames []string        `json:"interface_names"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeSettingsError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
-	// Validate that the interface exists (if non-empty)
-	if req.InterfaceName != "" {
+	// interface_name may be a bare string (legacy clients) or an array.
+	names := req.InterfaceNames
+	if len(req.InterfaceName) > 0 && string(req.InterfaceName) != "null" {
+		var single string
+		var list []string
+		switch {
+		case json.Unmarshal(req.InterfaceName, &single) == nil:
+			names = append(names, single)
+		case json.Unmarshal(req.InterfaceName, &list) == nil:
+			names = append(names, list...)
+		default:
+			writeSettingsError(w, http.StatusBadRequest, "interface_name must be a string or an array of strings")
+			return
+		}
+	}
+	names = services.NormalizeInterfaceNames(names)
+
+	// Validate that every interface exists.
+	if len(names) > 0 {
 		interfaces, err := h.interfaces.ListNetworkInterfaces()
 		if err != nil {
 			h.logger.Error("failed to list interfaces for validation", zap.Error(err))
 			writeSettingsError(w, http.StatusInternalServerError, "failed to validate interface")
 			return
 		}
-		found := false
+		known := make(map[string]bool, len(interfaces))
 		for i := range interfaces {
-			if interfaces[i].Name == req.InterfaceName {
-				found = true
-				break
-			}
+			known[interfaces[i].Name] = true
 		}
-		if !found {
-			writeSettingsError(w, http.StatusBadRequest, "interface not found: "+req.InterfaceName)
-			return
+		for _, name := range names {
+			if !known[name] {
+				writeSettingsError(w, http.StatusBadRequest, "interface not found: "+name)
+				return
+			}
 		}
 	}
 
-	if err := h.settings.Set(r.Context(), "scan_interface", req.InterfaceName); err != nil {
+	if err := h.settings.Set(r.Context(), services.ScanInterfacesKey, services.EncodeScanInterfaces(names)); err != nil {
 		h.logger.Error("failed to set scan interface", zap.Error(err))
 		writeSettingsError(w, http.StatusInternalServerError, "failed to save scan interface")
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]string{"interface_name": req.InterfaceName})
+	writeJSON(w, http.StatusOK, newScanInterfaceResponse(names))
+}
+
+// settingsExportVersion is the format version written by handleExportSettings.
+const settingsExportVersion = 1
+
+// handleExportSettings returns every stored setting as a JSON snapshot.
+//
+//	@Summary		Export settings
+//	@Description	Export all settings key/value pairs as JSON for backup. The built-in theme seed marker is omitted unless include_seed_marker=true.
+//	@Tags			settings
+//	@Produce		json
+//	@Param			include_seed_marker	query		bool					false	"Include the built-in theme seed marker"
+//	@Success		200					{object}	SettingsExport			"Settings snapshot"
+//	@Failure		500					{object}	SettingsProblemDetail	"Internal server error"
+//	@Router			/settings/export [get]
+func (h *Handler) handleExportSettings(w http.ResponseWriter, r *http.Request) {
+	all, err := h.settings.GetAll(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list settings for export", zap.Error(err))
+		writeSettingsError(w, http.StatusInternalServerError, "failed to export settings")
+		return
+	}
+
+	includeSeed := r.URL.Query().Get("include_seed_marker") == "true"
+	export := SettingsExport{
+		Version:    settingsExportVersion,
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		Settings:   make(map[string]string, len(all)),
+	}
+	for i := range all {
+		if all[i].Key == themeSeededKey && !includeSeed {
+			continue
+		}
+		export.Settings[all[i].Key] = all[i].Value
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="subnetree-settings.json"`)
+	writeJSON(w, http.StatusOK, export)
+}
+
+// handleImportSettings restores settings from a snapshot produced by
+// handleExportSettings. Only keys present in the snapshot are written; existing
+// settings that are absent from it are left untouched, so importing the same
+// snapshot twice is a no-op. Changed values are validated as their own
+// endpoints validate them, and nothing is written if any is invalid.
+//
+//	@Summary		Import settings
+//	@Description	Restore settings from an export snapshot. Keys not present in the snapshot are preserved. Themes, the active theme, and scan interfaces are validated as on their own endpoints; the whole import is rejected on the first invalid value. The built-in theme seed marker is ignored unless include_seed_marker=true. Admin only when authenticated.
+//	@Tags			settings
+//	@Accept			json
+//	@Produce		json
+//	@Param			request				body		SettingsExport			true	"Settings snapshot"
+//	@Param			include_seed_marker	query		bool					false	"Also restore the built-in theme seed marker"
+//	@Param			allow_low_contrast	query		bool					false	"Skip WCAG contrast validation of imported themes"
+//	@Success		200					{object}	SettingsImportResponse	"Import summary"
+//	@Failure		400					{object}	SettingsProblemDetail	"Invalid snapshot"
+//	@Failure		403					{object}	SettingsProblemDetail	"Admin role required"
+//	@Failure		500					{object}	SettingsProblemDetail	"Internal server error"
+//	@Router			/settings/import [post]
+func (h *Handler) handleImportSettings(w http.ResponseWriter, r *http.Request) {
+	if claims := auth.UserFromContext(r.Context()); claims != nil && auth.Role(claims.Role) != auth.RoleAdmin {
+		writeSettingsError(w, http.StatusForbidden, "admin role required to import settings")
+		return
+	}
+
+	var req SettingsExport
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeSettingsError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Version > settingsExportVersion {
+		writeSettingsError(w, http.StatusBadRequest, fmt.Sprintf("unsupported export version %d", req.Version))
+		return
+	}
+	if req.Settings == nil {
+		writeSettingsError(w, http.StatusBadRequest, "settings is required")
+		return
+	}
+
+	includeSeed := r.URL.Query().Get("include_seed_marker") == "true"
+
+	// Imported active themes may name a built-in theme.
+	if err := h.ensureBuiltInThemes(r.Context()); err != nil {
+		h.logger.Error("failed to ensure built-in themes", zap.Error(err))
+	}
+
+	// Validate and write in key order so failures are deterministic.
+	keys := make([]string, 0, len(req.Settings))
+	for k := range req.Settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var resp SettingsImportResponse
+	var changed []string
+	for _, key := range keys {
+		if strings.TrimSpace(key) == "" || (key == themeSeededKey && !includeSeed) {
+			resp.Skipped++
+			continue
+		}
+		value := req.Settings[key]
+		if existing, err := h.settings.Get(r.Context(), key); err == nil && existing.Value == value {
+			resp.Unchanged++
+			continue
+		}
+		invalid, err := h.validateImportedSetting(r.Context(), key, value, req.Settings, allowLowContrast(r))
+		if err != nil {
+			h.logger.Error("failed to validate imported setting", zap.String("key", key), zap.Error(err))
+			writeSettingsError(w, http.StatusInternalServerError, "failed to validate setting: "+key)
+			return
+		}
+		if invalid != "" {
+			writeSettingsError(w, http.StatusBadRequest, fmt.Sprintf("invalid setting %q: %s", key, invalid))
+			return
+		}
+		changed = append(changed, key)
+	}
+
+	for _, key := range changed {
+		if err := h.settings.Set(r.Context(), key, req.Settings[key]); err != nil {
+			h.logger.Error("failed to import setting", zap.String("key", key), zap.Error(err))
+			writeSettingsError(w, http.StatusInternalServerError, "failed to import setting: "+key)
+			return
+		}
+		resp.Imported++
+	}
+
+	h.logger.Info("settings imported",
+		zap.Int("imported", resp.Imported),
+		zap.Int("unchanged", resp.Unchanged),
+		zap.Int("skipped", resp.Skipped),
+	)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// validateImportedSetting applies the checks the dedicated endpoint for key
+// would apply to value. It returns a description of the problem when value
+// is invalid; snapshot is the whole import, so an active theme may refer to
+// a theme imported alongside it.
+func (h *Handler) validateImportedSetting(ctx context.Context, key, value string, snapshot map[string]string, allowLow bool) (string, error) {
+	switch {
+	case key == services.ScanInterfacesKey:
+		names := services.ParseScanInterfaces(value)
+		if len(names) == 0 {
+			return "", nil
+		}
+		interfaces, err := h.interfaces.ListNetworkInterfaces()
+		if err != nil {
+			return "", err
+		}
+		known := make(map[string]bool, len(interfaces))
+		for i := range interfaces {
+			known[interfaces[i].Name] = true
+		}
+		for _, name := range names {
+			if !known[name] {
+				return "interface not found: " + name, nil
+			}
+		}
+		return "", nil
+
+	case key == themeActiveKey || strings.HasPrefix(key, userThemeKeyPrefix):
+		if strings.TrimSpace(value) == "" {
+			return "theme_id is required", nil
+		}
+		if _, ok := snapshot[themeKeyPrefix+value]; ok {
+			return "", nil
+		}
+		if _, err := h.settings.Get(ctx, themeKeyPrefix+value); err != nil {
+			if errors.Is(err, services.ErrNotFound) {
+				return "theme not found: " + value, nil
+			}
+			return "", err
+		}
+		return "", nil
+
+	case strings.HasPrefix(key, themeKeyPrefix) && key != themeSeededKey:
+		var td ThemeDefinition
+		if err := json.Unmarshal([]byte(value), &td); err != nil {
+			return "invalid theme JSON", nil
+		}
+		if td.ID != strings.TrimPrefix(key, themeKeyPrefix) {
+			return "theme id does not match its key", nil
+		}
+		// Like an update, only contrast pairs the import changes are checked.
+		var touched map[string]bool
+		if stored, err := h.settings.Get(ctx, key); err == nil {
+			var old ThemeDefinition
+			if json.Unmarshal([]byte(stored.Value), &old) == nil {
+				touched = changedTokenKeys(&old.Tokens, &td.Tokens)
+			}
+		} else if !errors.Is(err, services.ErrNotFound) {
+			return "", err
+		}
+		if err := validateTheme(&td, touched, allowLow); err != nil {
+			return err.Error(), nil
+		}
+	}
+	return "", nil
 }
 
 // writeJSON writes a JSON response with the given status code.
@@ -250,6 +521,13 @@ const (
 	themeActiveKey    = "theme:active"
 	themeSeededKey    = "theme:builtin:seeded"
 	defaultThemeID   = "builtin-forest-dark"
+
+	// userThemeKeyPrefix stores per-user active theme preferences. It lives
+	// outside themeKeyPrefix so preferences are never listed as themes.
+	userThemeKeyPrefix = "user_theme:"
+
+	themeScopeUser   = "user"
+	themeScopeGlobal = "global"
 )
 
 // handleListThemes returns all stored themes.
@@ -354,10 +632,11 @@ func (h *Handler) handleGetTheme(w http.ResponseWriter, r *http.Request) {
 //	@Tags			settings
 //	@Accept			json
 //	@Produce		json
-//	@Param			request	body		ThemeDefinition			true	"Theme definition (id, created_at, updated_at, version, built_in are ignored)"
-//	@Success		201		{object}	ThemeDefinition			"Created theme"
-//	@Failure		400		{object}	SettingsProblemDetail	"Validation error"
-//	@Failure		500		{object}	SettingsProblemDetail	"Internal server error"
+//	@Param			request				body		ThemeDefinition			true	"Theme definition (id, created_at, updated_at, version, built_in are ignored)"
+//	@Param			allow_low_contrast	query		bool					false	"Skip WCAG contrast validation"
+//	@Success		201					{object}	ThemeDefinition			"Created theme"
+//	@Failure		400					{object}	SettingsProblemDetail	"Validation error or insufficient contrast"
+//	@Failure		500					{object}	SettingsProblemDetail	"Internal server error"
 //	@Router			/settings/themes [post]
 func (h *Handler) handleCreateTheme(w http.ResponseWriter, r *http.Request) {
 	var req ThemeDefinition
@@ -366,12 +645,8 @@ func (h *Handler) handleCreateTheme(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if strings.TrimSpace(req.Name) == "" {
-		writeSettingsError(w, http.StatusBadRequest, "name is required")
-		return
-	}
-	if req.BaseMode != "dark" && req.BaseMode != "light" {
-		writeSettingsError(w, http.StatusBadRequest, "base_mode must be \"dark\" or \"light\"")
+	if err := validateTheme(&req, nil, allowLowContrast(r)); err != nil {
+		writeSettingsError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -415,16 +690,18 @@ func (h *Handler) handleCreateTheme(w http.ResponseWriter, r *http.Request) {
 // handleUpdateTheme updates an existing custom theme.
 //
 //	@Summary		Update theme
-//	@Description	Update a custom theme. Built-in themes cannot be modified.
+//	@Description	Update a custom theme. Built-in themes cannot be modified. Token categories in the body replace the stored ones; send an empty object or null to clear a category. Omitted categories are unchanged.
 //	@Tags			settings
 //	@Accept			json
 //	@Produce		json
-//	@Param			id		path		string					true	"Theme ID"
-//	@Param			request	body		ThemeDefinition			true	"Fields to update"
-//	@Success		200		{object}	ThemeDefinition			"Updated theme"
-//	@Failure		403		{object}	SettingsProblemDetail	"Cannot modify built-in theme"
-//	@Failure		404		{object}	SettingsProblemDetail	"Theme not found"
-//	@Failure		500		{object}	SettingsProblemDetail	"Internal server error"
+//	@Param			id					path		string					true	"Theme ID"
+//	@Param			request				body		ThemeDefinition			true	"Fields to update"
+//	@Param			allow_low_contrast	query		bool					false	"Skip WCAG contrast validation"
+//	@Success		200					{object}	ThemeDefinition			"Updated theme"
+//	@Failure		400					{object}	SettingsProblemDetail	"Validation error or insufficient contrast"
+//	@Failure		403					{object}	SettingsProblemDetail	"Cannot modify built-in theme"
+//	@Failure		404					{object}	SettingsProblemDetail	"Theme not found"
+//	@Failure		500					{object}	SettingsProblemDetail	"Internal server error"
 //	@Router			/settings/themes/{id} [put]
 func (h *Handler) handleUpdateTheme(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
@@ -452,8 +729,22 @@ func (h *Handler) handleUpdateTheme(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeSettingsError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
 	var patch ThemeDefinition
-	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+	if err := json.Unmarshal(body, &patch); err != nil {
+		writeSettingsError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	// Decoding into maps cannot tell an omitted token category from one sent
+	// as {} or null, so record which categories the body mentions.
+	var present struct {
+		Tokens map[string]json.RawMessage `json:"tokens"`
+	}
+	if err := json.Unmarshal(body, &present); err != nil {
 		writeSettingsError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
@@ -472,38 +763,24 @@ func (h *Handler) handleUpdateTheme(w http.ResponseWriter, r *http.Request) {
 		}
 		existing.BaseMode = patch.BaseMode
 	}
-	if patch.Tokens.Backgrounds != nil {
-		existing.Tokens.Backgrounds = patch.Tokens.Backgrounds
-	}
-	if patch.Tokens.Text != nil {
-		existing.Tokens.Text = patch.Tokens.Text
-	}
-	if patch.Tokens.Borders != nil {
-		existing.Tokens.Borders = patch.Tokens.Borders
-	}
-	if patch.Tokens.Buttons != nil {
-		existing.Tokens.Buttons = patch.Tokens.Buttons
-	}
-	if patch.Tokens.Inputs != nil {
-		existing.Tokens.Inputs = patch.Tokens.Inputs
-	}
-	if patch.Tokens.Sidebar != nil {
-		existing.Tokens.Sidebar = patch.Tokens.Sidebar
-	}
-	if patch.Tokens.Status != nil {
-		existing.Tokens.Status = patch.Tokens.Status
-	}
-	if patch.Tokens.Charts != nil {
-		existing.Tokens.Charts = patch.Tokens.Charts
-	}
-	if patch.Tokens.Typography != nil {
-		existing.Tokens.Typography = patch.Tokens.Typography
-	}
-	if patch.Tokens.Spacing != nil {
-		existing.Tokens.Spacing = patch.Tokens.Spacing
+	// A token category in the body replaces the stored one. Sending {} or
+	// null clears it so the base theme's defaults apply again; omitted
+	// categories are left unchanged.
+	patchCategories := patch.Tokens.categories()
+	for name, dst := range existing.Tokens.categories() {
+		if _, ok := present.Tokens[name]; !ok {
+			continue
+		}
+		if src := *patchCategories[name]; len(src) > 0 {
+			*dst = src
+		} else {
+			*dst = nil
+		}
 	}
-	if patch.Tokens.Effects != nil {
-		existing.Tokens.Effects = patch.Tokens.Effects
+
+	if err := validateTheme(&existing, tokenKeys(&patch.Tokens), allowLowContrast(r)); err != nil {
+		writeSettingsError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
 	existing.Version++
@@ -574,42 +851,265 @@ func (h *Handler) handleDeleteTheme(w http.ResponseWriter, r *http.Request) {
 		_ = h.settings.Set(r.Context(), themeActiveKey, defaultThemeID)
 	}
 
+	// Drop per-user preferences for the deleted theme so those users fall
+	// back to the global default.
+	if all, err := h.settings.GetAll(r.Context()); err == nil {
+		for i := range all {
+			if strings.HasPrefix(all[i].Key, userThemeKeyPrefix) && all[i].Value == id {
+				_ = h.settings.Delete(r.Context(), all[i].Key)
+			}
+		}
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// handleGetActiveTheme returns the currently active theme ID.
+// handleDuplicateTheme copies an existing theme into a new editable custom theme.
+//
+//	@Summary		Duplicate theme
+//	@Description	Copy a theme (built-in or custom) into a new custom theme that can be edited and deleted.
+//	@Tags			settings
+//	@Produce		json
+//	@Param			id	path		string					true	"Source theme ID"
+//	@Success		201	{object}	ThemeDefinition			"Duplicated theme"
+//	@Failure		404	{object}	SettingsProblemDetail	"Theme not found"
+//	@Failure		500	{object}	SettingsProblemDetail	"Internal server error"
+//	@Router			/settings/themes/{id}/duplicate [post]
+func (h *Handler) handleDuplicateTheme(w http.ResponseWriter, r *http.Request) {
+	// Built-ins may not be seeded yet if the caller duplicates before listing.
+	if err := h.ensureBuiltInThemes(r.Context()); err != nil {
+		h.logger.Error("failed to ensure built-in themes", zap.Error(err))
+	}
+
+	id := r.PathValue("id")
+	setting, err := h.settings.Get(r.Context(), themeKeyPrefix+id)
+	if err != nil {
+		if err == services.ErrNotFound {
+			writeSettingsError(w, http.StatusNotFound, "theme not found")
+			return
+		}
+		h.logger.Error("failed to get theme for duplicate", zap.String("id", id), zap.Error(err))
+		writeSettingsError(w, http.StatusInternalServerError, "failed to get theme")
+		return
+	}
+
+	var source ThemeDefinition
+	if err := json.Unmarshal([]byte(setting.Value), &source); err != nil {
+		h.logger.Error("failed to parse theme for duplicate", zap.String("id", id), zap.Error(err))
+		writeSettingsError(w, http.StatusInternalServerError, "failed to parse theme")
+		return
+	}
+
+	newID, err := generateID()
+	if err != nil {
+		h.logger.Error("failed to generate theme ID", zap.Error(err))
+		writeSettingsError(w, http.StatusInternalServerError, "failed to generate theme ID")
+		return
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	td := ThemeDefinition{
+		ID:          newID,
+		Name:        source.Name + " (copy)",
+		Description: source.Description,
+		BaseMode:    source.BaseMode,
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		BuiltIn:     false,
+		Layers:      append([]ThemeLayer(nil), source.Layers...),
+		Tokens:      source.Tokens,
+	}
+
+	data, err := json.Marshal(td)
+	if err != nil {
+		h.logger.Error("failed to marshal duplicated theme", zap.Error(err))
+		writeSettingsError(w, http.StatusInternalServerError, "failed to store theme")
+		return
+	}
+
+	if err := h.settings.Set(r.Context(), themeKeyPrefix+td.ID, string(data)); err != nil {
+		h.logger.Error("failed to save duplicated theme", zap.Error(err))
+		writeSettingsError(w, http.StatusInternalServerError, "failed to store theme")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, td)
+}
+
+// handleGetThemeCSS renders a theme's tokens as a CSS stylesheet.
+//
+//	@Summary		Get theme CSS
+//	@Description	Render a theme's token overrides as a :root stylesheet of CSS custom properties. Only token categories belonging to the theme's declared layers are emitted.
+//	@Tags			settings
+//	@Produce		text/css
+//	@Param			id	path		string					true	"Theme ID"
+//	@Success		200	{string}	string					"CSS stylesheet"
+//	@Failure		404	{object}	SettingsProblemDetail	"Theme not found"
+//	@Failure		500	{object}	SettingsProblemDetail	"Internal server error"
+//	@Router			/settings/themes/{id}/css [get]
+func (h *Handler) handleGetThemeCSS(w http.ResponseWriter, r *http.Request) {
+	if err := h.ensureBuiltInThemes(r.Context()); err != nil {
+		h.logger.Error("failed to ensure built-in themes", zap.Error(err))
+	}
+
+	id := r.PathValue("id")
+	setting, err := h.settings.Get(r.Context(), themeKeyPrefix+id)
+	if err != nil {
+		if err == services.ErrNotFound {
+			writeSettingsError(w, http.StatusNotFound, "theme not found")
+			return
+		}
+		h.logger.Error("failed to get theme for css", zap.String("id", id), zap.Error(err))
+		writeSettingsError(w, http.StatusInternalServerError, "failed to get theme")
+		return
+	}
+
+	var td ThemeDefinition
+	if err := json.Unmarshal([]byte(setting.Value), &td); err != nil {
+		h.logger.Error("failed to parse theme for css", zap.String("id", id), zap.Error(err))
+		writeSettingsError(w, http.StatusInternalServerError, "failed to parse theme")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(themeCSS(&td)))
+}
+
+// cssVarPrefix matches the prefix the dashboard uses when flattening tokens.
+const cssVarPrefix = "--nv-"
+
+// layerCategories returns the token categories that belong to a layer.
+func layerCategories(t *ThemeTokens, layer ThemeLayer) []map[string]string {
+	switch layer {
+	case LayerColors:
+		return []map[string]string{
+			t.Backgrounds, t.Text, t.Borders, t.Buttons,
+			t.Inputs, t.Sidebar, t.Status, t.Charts,
+		}
+	case LayerTypography:
+		return []map[string]string{t.Typography}
+	case LayerShape:
+		return []map[string]string{t.Spacing}
+	case LayerEffects:
+		return []map[string]string{t.Effects}
+	default:
+		return nil
+	}
+}
+
+// themeCSS renders the theme's tokens as a :root block. Themes that declare
+// no layers are treated as full themes covering every layer.
+func themeCSS(td *ThemeDefinition) string {
+	layers := td.Layers
+	if len(layers) == 0 {
+		layers = allLayers
+	}
+
+	vars := make(map[string]string)
+	for _, layer := range layers {
+		for _, category := range layerCategories(&td.Tokens, layer) {
+			for k, v := range category {
+				if !validCSSName(k) {
+					continue
+				}
+				vars[k] = sanitizeCSSValue(v)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(vars))
+	for k := range vars {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "/* %s */\n", strings.ReplaceAll(td.Name, "*/", ""))
+	b.WriteString(":root {\n")
+	for _, k := range names {
+		fmt.Fprintf(&b, "  %s%s: %s;\n", cssVarPrefix, k, vars[k])
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// validCSSName reports whether s is safe to use as a custom property name.
+func validCSSName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '-' && c != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// sanitizeCSSValue strips characters that could terminate the declaration
+// or the enclosing rule.
+func sanitizeCSSValue(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ';', '{', '}', '<', '>', '\n', '\r':
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// handleGetActiveTheme returns the caller's active theme ID, falling back to
+// the global default when the caller has not chosen one.
 //
 //	@Summary		Get active theme
-//	@Description	Get the ID of the currently active theme.
+//	@Description	Get the ID of the active theme for the authenticated user, or the global default if the user has no preference.
 //	@Tags			settings
 //	@Produce		json
 //	@Success		200	{object}	ActiveThemeResponse		"Active theme ID"
 //	@Failure		500	{object}	SettingsProblemDetail	"Internal server error"
 //	@Router			/settings/themes/active [get]
 func (h *Handler) handleGetActiveTheme(w http.ResponseWriter, r *http.Request) {
+	if claims := auth.UserFromContext(r.Context()); claims != nil && claims.UserID != "" {
+		pref, err := h.settings.Get(r.Context(), userThemeKeyPrefix+claims.UserID)
+		if err == nil {
+			writeJSON(w, http.StatusOK, ActiveThemeResponse{ThemeID: pref.Value, Scope: themeScopeUser})
+			return
+		}
+		if err != services.ErrNotFound {
+			h.logger.Error("failed to get user theme preference", zap.String("user_id", claims.UserID), zap.Error(err))
+			writeSettingsError(w, http.StatusInternalServerError, "failed to get active theme")
+			return
+		}
+	}
+
 	setting, err := h.settings.Get(r.Context(), themeActiveKey)
 	if err != nil {
 		if err == services.ErrNotFound {
-			writeJSON(w, http.StatusOK, ActiveThemeResponse{ThemeID: defaultThemeID})
+			writeJSON(w, http.StatusOK, ActiveThemeResponse{ThemeID: defaultThemeID, Scope: themeScopeGlobal})
 			return
 		}
 		h.logger.Error("failed to get active theme", zap.Error(err))
 		writeSettingsError(w, http.StatusInternalServerError, "failed to get active theme")
 		return
 	}
-	writeJSON(w, http.StatusOK, ActiveThemeResponse{ThemeID: setting.Value})
+	writeJSON(w, http.StatusOK, ActiveThemeResponse{ThemeID: setting.Value, Scope: themeScopeGlobal})
 }
 
-// handleSetActiveTheme sets the active theme.
+// handleSetActiveTheme sets the caller's active theme. Unauthenticated callers
+// (e.g. the setup wizard) and admins passing scope=global set the global default.
 //
 //	@Summary		Set active theme
-//	@Description	Set which theme is currently active.
+//	@Description	Set the active theme for the authenticated user. Admins may pass scope=global to change the default for users without a preference.
 //	@Tags			settings
 //	@Accept			json
 //	@Produce		json
 //	@Param			request	body		ActiveThemeRequest		true	"Theme ID to activate"
+//	@Param			scope	query		string					false	"Set to \"global\" to change the default theme (admin only)"
 //	@Success		200		{object}	ActiveThemeResponse		"Active theme set"
 //	@Failure		400		{object}	SettingsProblemDetail	"Validation error"
+//	@Failure		403		{object}	SettingsProblemDetail	"Only admins may change the global default"
 //	@Failure		404		{object}	SettingsProblemDetail	"Theme not found"
 //	@Failure		500		{object}	SettingsProblemDetail	"Internal server error"
 //	@Router			/settings/themes/active [put]
@@ -641,13 +1141,25 @@ func (h *Handler) handleSetActiveTheme(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.settings.Set(r.Context(), themeActiveKey, req.ThemeID); err != nil {
-		h.logger.Error("failed to set active theme", zap.Error(err))
+	key, scope := themeActiveKey, themeScopeGlobal
+	if claims := auth.UserFromContext(r.Context()); claims != nil && claims.UserID != "" {
+		if r.URL.Query().Get("scope") == themeScopeGlobal {
+			if auth.Role(claims.Role) != auth.RoleAdmin {
+				writeSettingsError(w, http.StatusForbidden, "only admins may change the global default theme")
+				return
+			}
+		} else {
+			key, scope = userThemeKeyPrefix+claims.UserID, themeScopeUser
+		}
+	}
+
+	if err := h.settings.Set(r.Context(), key, req.ThemeID); err != nil {
+		h.logger.Error("failed to set active theme", zap.String("scope", scope), zap.Error(err))
 		writeSettingsError(w, http.StatusInternalServerError, "failed to set active theme")
 		return
 	}
 
-	writeJSON(w, http.StatusOK, ActiveThemeResponse(req))
+	writeJSON(w, http.StatusOK, ActiveThemeResponse{ThemeID: req.ThemeID, Scope: scope})
 }
 
 // ensureBuiltInThemes seeds built-in themes, adding any new ones that don't exist yet.
@@ -1388,6 +1900,28 @@ func sageBerryTokens() ThemeTokens {
 	}
 }
 
+// allowLowContrast reports whether the caller opted out of contrast validation.
+func allowLowContrast(r *http.Request) bool {
+	return r.URL.Query().Get("allow_low_contrast") == "true"
+}
+
+// validateTheme checks a theme before it is stored. touched limits the
+// contrast check as in checkContrast; allowLow skips it.
+func validateTheme(td *ThemeDefinition, touched map[string]bool, allowLow bool) error {
+	if strings.TrimSpace(td.Name) == "" {
+		return errors.New("name is required")
+	}
+	if td.BaseMode != "dark" && td.BaseMode != "light" {
+		return errors.New(`base_mode must be "dark" or "light"`)
+	}
+	if !allowLow {
+		if issues := checkContrast(&td.Tokens, touched); len(issues) > 0 {
+			return errors.New(formatContrastIssues(issues))
+		}
+	}
+	return nil
+}
+
 // generateID returns a random 32-character hex string suitable for use as a theme ID.
 func generateID() (string, error) {
 	b := make([]byte, 16)
@@ -6,8 +6,11 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/HerbHall/subnetree/internal/auth"
 	"github.com/HerbHall/subnetree/internal/services"
 	"github.com/HerbHall/subnetree/internal/settings"
 	"github.com/HerbHall/subnetree/internal/testutil"
@@ -250,6 +253,65 @@ func TestHandleUpdateTheme(t *testing.T) {
 	}
 }
 
+func TestHandleUpdateTheme_ClearTokenCategory(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+
+	w := doRequest(mux, "POST", "/api/v1/settings/themes", map[string]any{
+		"name":      "Overrides",
+		"base_mode": "dark",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("CreateTheme status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	var created settings.ThemeDefinition
+	_ = json.NewDecoder(w.Body).Decode(&created)
+	path := "/api/v1/settings/themes/" + created.ID
+
+	update := func(body map[string]any) settings.ThemeDefinition {
+		t.Helper()
+		w := doRequest(mux, "PUT", path, body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("UpdateTheme status = %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+		}
+		var td settings.ThemeDefinition
+		_ = json.NewDecoder(w.Body).Decode(&td)
+		return td
+	}
+
+	// Set overrides in two categories.
+	td := update(map[string]any{"tokens": map[string]any{
+		"backgrounds": map[string]string{"bg-root": "#000000"},
+		"borders":     map[string]string{"border-default": "#333333"},
+	}})
+	if td.Tokens.Backgrounds["bg-root"] != "#000000" {
+		t.Fatalf("Tokens.Backgrounds = %v, want bg-root set", td.Tokens.Backgrounds)
+	}
+
+	// An update that omits tokens keeps them.
+	td = update(map[string]any{"name": "Renamed"})
+	if len(td.Tokens.Backgrounds) != 1 || len(td.Tokens.Borders) != 1 {
+		t.Errorf("omitted tokens changed: %+v", td.Tokens)
+	}
+
+	// An empty object clears only that category.
+	td = update(map[string]any{"tokens": map[string]any{"backgrounds": map[string]string{}}})
+	if td.Tokens.Backgrounds != nil {
+		t.Errorf("Tokens.Backgrounds = %v, want cleared", td.Tokens.Backgrounds)
+	}
+	if td.Tokens.Borders["border-default"] != "#333333" {
+		t.Errorf("Tokens.Borders = %v, want unchanged", td.Tokens.Borders)
+	}
+
+	// null clears as well, and the result is persisted.
+	update(map[string]any{"tokens": map[string]any{"borders": nil}})
+	w = doRequest(mux, "GET", path, nil)
+	var stored settings.ThemeDefinition
+	_ = json.NewDecoder(w.Body).Decode(&stored)
+	if stored.Tokens.Borders != nil || stored.Tokens.Backgrounds != nil {
+		t.Errorf("stored tokens = %+v, want backgrounds and borders cleared", stored.Tokens)
+	}
+}
+
 func TestHandleUpdateTheme_BuiltIn(t *testing.T) {
 	_, mux := setupHandlerEnv(t)
 
@@ -483,3 +545,517 @@ func TestHandleCreateTheme_Validation(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleDuplicateTheme_BuiltIn(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+
+	w := doRequest(mux, "POST", "/api/v1/settings/themes/builtin-navy-copper/duplicate", nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("DuplicateTheme status = %d, want %d; body: %s", w.Code, http.StatusCreated, w.Body.String())
+	}
+
+	var dup settings.ThemeDefinition
+	if err := json.NewDecoder(w.Body).Decode(&dup); err != nil {
+		t.Fatalf("Decode response: %v", err)
+	}
+	if dup.ID == "" || dup.ID == "builtin-navy-copper" {
+		t.Errorf("ID = %q, want new generated ID", dup.ID)
+	}
+	if dup.Name != "Navy Copper (copy)" {
+		t.Errorf("Name = %q, want %q", dup.Name, "Navy Copper (copy)")
+	}
+	if dup.BuiltIn {
+		t.Error("BuiltIn = true, want false")
+	}
+	if got := dup.Tokens.Backgrounds["bg-root"]; got != "#0D2238" {
+		t.Errorf("Tokens.Backgrounds[bg-root] = %q, want %q", got, "#0D2238")
+	}
+	if len(dup.Layers) != 2 {
+		t.Errorf("len(Layers) = %d, want 2", len(dup.Layers))
+	}
+
+	// The copy must be editable.
+	w2 := doRequest(mux, "PUT", "/api/v1/settings/themes/"+dup.ID, map[string]any{"name": "Mine"})
+	if w2.Code != http.StatusOK {
+		t.Errorf("UpdateTheme on copy status = %d, want %d; body: %s", w2.Code, http.StatusOK, w2.Body.String())
+	}
+}
+
+func TestHandleDuplicateTheme_NotFound(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+
+	w := doRequest(mux, "POST", "/api/v1/settings/themes/does-not-exist/duplicate", nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("DuplicateTheme status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleDuplicateTheme_Custom(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+
+	w := doRequest(mux, "POST", "/api/v1/settings/themes", map[string]any{
+		"name":        "Mine",
+		"description": "Custom palette",
+		"base_mode":   "light",
+		"tokens": map[string]any{
+			"backgrounds": map[string]string{"bg-root": "#fafafa"},
+			"text":        map[string]string{"text-primary": "#111111"},
+		},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("CreateTheme status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	var src settings.ThemeDefinition
+	_ = json.NewDecoder(w.Body).Decode(&src)
+
+	// Bump the source version so the copy's reset to 1 is observable.
+	doRequest(mux, "PUT", "/api/v1/settings/themes/"+src.ID, map[string]any{"name": "Mine"})
+
+	w2 := doRequest(mux, "POST", "/api/v1/settings/themes/"+src.ID+"/duplicate", nil)
+	if w2.Code != http.StatusCreated {
+		t.Fatalf("DuplicateTheme status = %d, want %d; body: %s", w2.Code, http.StatusCreated, w2.Body.String())
+	}
+	var dup settings.ThemeDefinition
+	if err := json.NewDecoder(w2.Body).Decode(&dup); err != nil {
+		t.Fatalf("Decode response: %v", err)
+	}
+
+	if dup.Description != "Custom palette" {
+		t.Errorf("Description = %q, want %q", dup.Description, "Custom palette")
+	}
+	if dup.BaseMode != "light" {
+		t.Errorf("BaseMode = %q, want %q", dup.BaseMode, "light")
+	}
+	if got := dup.Tokens.Backgrounds["bg-root"]; got != "#fafafa" {
+		t.Errorf("Tokens.Backgrounds[bg-root] = %q, want %q", got, "#fafafa")
+	}
+	if got := dup.Tokens.Text["text-primary"]; got != "#111111" {
+		t.Errorf("Tokens.Text[text-primary] = %q, want %q", got, "#111111")
+	}
+	if dup.Version != 1 {
+		t.Errorf("Version = %d, want 1", dup.Version)
+	}
+
+	// The copy must be deletable.
+	w3 := doRequest(mux, "DELETE", "/api/v1/settings/themes/"+dup.ID, nil)
+	if w3.Code != http.StatusNoContent {
+		t.Errorf("DeleteTheme on copy status = %d, want %d; body: %s", w3.Code, http.StatusNoContent, w3.Body.String())
+	}
+}
+
+func TestHandleGetThemeCSS(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+
+	w := doRequest(mux, "GET", "/api/v1/settings/themes/builtin-navy-copper/css", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GetThemeCSS status = %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/css") {
+		t.Errorf("Content-Type = %q, want text/css", ct)
+	}
+
+	css := w.Body.String()
+	if !strings.Contains(css, ":root {") {
+		t.Errorf("CSS missing :root block: %s", css)
+	}
+	if !strings.Contains(css, "--nv-bg-root: #0D2238;") {
+		t.Errorf("CSS missing bg-root declaration: %s", css)
+	}
+	if !strings.Contains(css, "--nv-shadow-glow:") {
+		t.Errorf("CSS missing effects declaration: %s", css)
+	}
+}
+
+func TestHandleGetThemeCSS_LayerFilter(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+
+	// A shape-only theme with stray color tokens should only emit shape tokens.
+	w := doRequest(mux, "POST", "/api/v1/settings/themes", map[string]any{
+		"name":      "Shape Only",
+		"base_mode": "dark",
+		"layers":    []string{"shape"},
+		"tokens": map[string]any{
+			"spacing":     map[string]string{"radius-md": "6px"},
+			"backgrounds": map[string]string{"bg-root": "#000000"},
+		},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("CreateTheme status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	var created settings.ThemeDefinition
+	_ = json.NewDecoder(w.Body).Decode(&created)
+
+	w2 := doRequest(mux, "GET", "/api/v1/settings/themes/"+created.ID+"/css", nil)
+	if w2.Code != http.StatusOK {
+		t.Fatalf("GetThemeCSS status = %d, want %d", w2.Code, http.StatusOK)
+	}
+	css := w2.Body.String()
+	if !strings.Contains(css, "--nv-radius-md: 6px;") {
+		t.Errorf("CSS missing radius-md declaration: %s", css)
+	}
+	if strings.Contains(css, "bg-root") {
+		t.Errorf("CSS contains color token outside declared layers: %s", css)
+	}
+}
+
+func TestHandleGetThemeCSS_NotFound(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+
+	w := doRequest(mux, "GET", "/api/v1/settings/themes/does-not-exist/css", nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GetThemeCSS status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleCreateTheme_LowContrast(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+
+	body := map[string]any{
+		"name":      "Unreadable",
+		"base_mode": "dark",
+		"tokens": map[string]any{
+			"backgrounds": map[string]string{"bg-root": "#222222"},
+			"text":        map[string]string{"text-primary": "#2a2a2a"},
+		},
+	}
+
+	w := doRequest(mux, "POST", "/api/v1/settings/themes", body)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("CreateTheme low contrast status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if !strings.Contains(w.Body.String(), "text-primary on bg-root") {
+		t.Errorf("error body = %s, want mention of text-primary on bg-root", w.Body.String())
+	}
+
+	w2 := doRequest(mux, "POST", "/api/v1/settings/themes?allow_low_contrast=true", body)
+	if w2.Code != http.StatusCreated {
+		t.Errorf("CreateTheme with override status = %d, want %d; body: %s", w2.Code, http.StatusCreated, w2.Body.String())
+	}
+}
+
+func TestHandleUpdateTheme_LowContrast(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+
+	w := doRequest(mux, "POST", "/api/v1/settings/themes", map[string]any{
+		"name":      "Readable",
+		"base_mode": "dark",
+		"tokens": map[string]any{
+			"backgrounds": map[string]string{"bg-root": "#000000"},
+			"text":        map[string]string{"text-primary": "#ffffff"},
+		},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("CreateTheme status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	var created settings.ThemeDefinition
+	_ = json.NewDecoder(w.Body).Decode(&created)
+
+	patch := map[string]any{
+		"tokens": map[string]any{
+			"text": map[string]string{"text-primary": "#0a0a0a"},
+		},
+	}
+	w2 := doRequest(mux, "PUT", "/api/v1/settings/themes/"+created.ID, patch)
+	if w2.Code != http.StatusBadRequest {
+		t.Errorf("UpdateTheme low contrast status = %d, want %d", w2.Code, http.StatusBadRequest)
+	}
+
+	w3 := doRequest(mux, "PUT", "/api/v1/settings/themes/"+created.ID+"?allow_low_contrast=true", patch)
+	if w3.Code != http.StatusOK {
+		t.Errorf("UpdateTheme with override status = %d, want %d; body: %s", w3.Code, http.StatusOK, w3.Body.String())
+	}
+}
+
+func TestHandleUpdateTheme_DuplicatedBuiltInRename(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+
+	// Nordic's primary button sits below 4.5:1; renaming a copy must still work.
+	w := doRequest(mux, "POST", "/api/v1/settings/themes/builtin-nordic/duplicate", nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("DuplicateTheme status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	var dup settings.ThemeDefinition
+	_ = json.NewDecoder(w.Body).Decode(&dup)
+
+	w2 := doRequest(mux, "PUT", "/api/v1/settings/themes/"+dup.ID, map[string]any{"name": "My Nordic"})
+	if w2.Code != http.StatusOK {
+		t.Errorf("UpdateTheme rename status = %d, want %d; body: %s", w2.Code, http.StatusOK, w2.Body.String())
+	}
+}
+
+// authedRequest issues a request through the auth middleware as the given user.
+func authedRequest(t *testing.T, h http.Handler, tokens *auth.TokenService, user *auth.User, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	token, err := tokens.IssueAccessToken(user)
+	if err != nil {
+		t.Fatalf("IssueAccessToken: %v", err)
+	}
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandleActiveTheme_PerUser(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+	tokens := auth.NewTokenService([]byte("test-secret-test-secret-test-secret"), time.Minute, time.Hour)
+	h := auth.AuthMiddleware(tokens)(mux)
+
+	alice := &auth.User{ID: "user-alice", Username: "alice", Role: auth.RoleViewer}
+	bob := &auth.User{ID: "user-bob", Username: "bob", Role: auth.RoleViewer}
+
+	w := authedRequest(t, h, tokens, alice, "PUT", "/api/v1/settings/themes/active", map[string]any{
+		"theme_id": "builtin-forest-light",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("SetActiveTheme status = %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var resp settings.ActiveThemeResponse
+	w = authedRequest(t, h, tokens, alice, "GET", "/api/v1/settings/themes/active", nil)
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+	if resp.ThemeID != "builtin-forest-light" || resp.Scope != "user" {
+		t.Errorf("alice active = %+v, want builtin-forest-light/user", resp)
+	}
+
+	// Bob has no preference and gets the global default.
+	w = authedRequest(t, h, tokens, bob, "GET", "/api/v1/settings/themes/active", nil)
+	resp = settings.ActiveThemeResponse{}
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+	if resp.ThemeID != "builtin-forest-dark" || resp.Scope != "global" {
+		t.Errorf("bob active = %+v, want builtin-forest-dark/global", resp)
+	}
+}
+
+func TestHandleSetActiveTheme_GlobalScope(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+	tokens := auth.NewTokenService([]byte("test-secret-test-secret-test-secret"), time.Minute, time.Hour)
+	h := auth.AuthMiddleware(tokens)(mux)
+
+	admin := &auth.User{ID: "user-admin", Username: "admin", Role: auth.RoleAdmin}
+	viewer := &auth.User{ID: "user-viewer", Username: "viewer", Role: auth.RoleViewer}
+	body := map[string]any{"theme_id": "builtin-nordic"}
+
+	w := authedRequest(t, h, tokens, viewer, "PUT", "/api/v1/settings/themes/active?scope=global", body)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("viewer global SetActiveTheme status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+
+	w = authedRequest(t, h, tokens, admin, "PUT", "/api/v1/settings/themes/active?scope=global", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("admin global SetActiveTheme status = %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	// The viewer has no preference, so sees the new global default.
+	var resp settings.ActiveThemeResponse
+	w = authedRequest(t, h, tokens, viewer, "GET", "/api/v1/settings/themes/active", nil)
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+	if resp.ThemeID != "builtin-nordic" {
+		t.Errorf("viewer active = %q, want %q", resp.ThemeID, "builtin-nordic")
+	}
+}
+
+func TestHandleExportImportSettings(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+
+	// Seed themes (writes the seed marker) and a plain setting.
+	doRequest(mux, "GET", "/api/v1/settings/themes", nil)
+	doRequest(mux, "POST", "/api/v1/settings/scan-interface", map[string]string{"interface_name": ""})
+
+	w := doRequest(mux, "GET", "/api/v1/settings/export", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("ExportSettings status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var export settings.SettingsExport
+	if err := json.NewDecoder(w.Body).Decode(&export); err != nil {
+		t.Fatalf("Decode response: %v", err)
+	}
+	if export.Version != 1 {
+		t.Errorf("Version = %d, want 1", export.Version)
+	}
+	if _, ok := export.Settings["theme:builtin:seeded"]; ok {
+		t.Error("export contains seed marker without include_seed_marker")
+	}
+	if _, ok := export.Settings["theme:builtin-forest-dark"]; !ok {
+		t.Error("export missing built-in theme")
+	}
+
+	w = doRequest(mux, "GET", "/api/v1/settings/export?include_seed_marker=true", nil)
+	var withSeed settings.SettingsExport
+	_ = json.NewDecoder(w.Body).Decode(&withSeed)
+	if _, ok := withSeed.Settings["theme:builtin:seeded"]; !ok {
+		t.Error("export missing seed marker with include_seed_marker=true")
+	}
+
+	// Change a setting after the snapshot, then restore.
+	doRequest(mux, "PUT", "/api/v1/settings/themes/active", map[string]any{"theme_id": "builtin-nordic"})
+	export.Settings["theme:active"] = "builtin-forest-light"
+
+	w = doRequest(mux, "POST", "/api/v1/settings/import", export)
+	if w.Code != http.StatusOK {
+		t.Fatalf("ImportSettings status = %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var first settings.SettingsImportResponse
+	_ = json.NewDecoder(w.Body).Decode(&first)
+	if first.Imported != 1 {
+		t.Errorf("first import Imported = %d, want 1", first.Imported)
+	}
+
+	var active settings.ActiveThemeResponse
+	w = doRequest(mux, "GET", "/api/v1/settings/themes/active", nil)
+	_ = json.NewDecoder(w.Body).Decode(&active)
+	if active.ThemeID != "builtin-forest-light" {
+		t.Errorf("active theme after import = %q, want %q", active.ThemeID, "builtin-forest-light")
+	}
+
+	// Re-importing the same snapshot is a no-op.
+	w = doRequest(mux, "POST", "/api/v1/settings/import", export)
+	var second settings.SettingsImportResponse
+	_ = json.NewDecoder(w.Body).Decode(&second)
+	if second.Imported != 0 {
+		t.Errorf("second import Imported = %d, want 0", second.Imported)
+	}
+}
+
+func TestHandleImportSettings_PreservesAbsentKeys(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+
+	doRequest(mux, "PUT", "/api/v1/settings/themes/active", map[string]any{"theme_id": "builtin-nordic"})
+
+	w := doRequest(mux, "POST", "/api/v1/settings/import", map[string]any{
+		"version":  1,
+		"settings": map[string]string{"custom:key": "value"},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("ImportSettings status = %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var active settings.ActiveThemeResponse
+	w = doRequest(mux, "GET", "/api/v1/settings/themes/active", nil)
+	_ = json.NewDecoder(w.Body).Decode(&active)
+	if active.ThemeID != "builtin-nordic" {
+		t.Errorf("active theme = %q, want %q (absent keys must be preserved)", active.ThemeID, "builtin-nordic")
+	}
+}
+
+func TestHandleImportSettings_Invalid(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+
+	tests := []struct {
+		name string
+		body any
+	}{
+		{"missing settings", map[string]any{"version": 1}},
+		{"future version", map[string]any{"version": 99, "settings": map[string]string{}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doRequest(mux, "POST", "/api/v1/settings/import", tt.body)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("ImportSettings status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestHandleImportSettings_ValidatesValues(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+
+	theme := func(id, baseMode, text string) string {
+		data, _ := json.Marshal(map[string]any{
+			"id":        id,
+			"name":      "Imported",
+			"base_mode": baseMode,
+			"tokens": map[string]any{
+				"backgrounds": map[string]string{"bg-root": "#000000"},
+				"text":        map[string]string{"text-primary": text},
+			},
+		})
+		return string(data)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		settings map[string]string
+		want     int
+	}{
+		{"low contrast theme", "", map[string]string{"theme:imp": theme("imp", "dark", "#0a0a0a")}, http.StatusBadRequest},
+		{"bad base mode", "", map[string]string{"theme:imp": theme("imp", "sepia", "#ffffff")}, http.StatusBadRequest},
+		{"id does not match key", "", map[string]string{"theme:imp": theme("other", "dark", "#ffffff")}, http.StatusBadRequest},
+		{"active theme missing", "", map[string]string{"theme:active": "no-such-theme"}, http.StatusBadRequest},
+		{"unknown interface", "", map[string]string{services.ScanInterfacesKey: "no-such-if0"}, http.StatusBadRequest},
+		{"low contrast override", "?allow_low_contrast=true", map[string]string{"theme:imp": theme("imp", "dark", "#0a0a0a")}, http.StatusOK},
+		{"active theme in snapshot", "", map[string]string{"theme:imp2": theme("imp2", "dark", "#ffffff"), "theme:active": "imp2"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A valid key alongside the invalid one must not be written.
+			tt.settings["custom:"+tt.name] = "value"
+			w := doRequest(mux, "POST", "/api/v1/settings/import"+tt.query, map[string]any{"version": 1, "settings": tt.settings})
+			if w.Code != tt.want {
+				t.Fatalf("ImportSettings status = %d, want %d; body: %s", w.Code, tt.want, w.Body.String())
+			}
+			export := doRequest(mux, "GET", "/api/v1/settings/export", nil)
+			var snap settings.SettingsExport
+			_ = json.NewDecoder(export.Body).Decode(&snap)
+			if _, written := snap.Settings["custom:"+tt.name]; written != (tt.want == http.StatusOK) {
+				t.Errorf("custom key written = %v after status %d", written, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandleSetScanInterface_Array(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+
+	ifaces, err := services.NewInterfaceService().ListNetworkInterfaces()
+	if err != nil || len(ifaces) == 0 {
+		t.Skip("no non-loopback IPv4 interfaces available")
+	}
+	name := ifaces[0].Name
+
+	// Array form, with a duplicate that should be normalized away.
+	w := doRequest(mux, "POST", "/api/v1/settings/scan-interface", map[string]any{
+		"interface_name": []string{name, name},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("SetScanInterface status = %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp settings.ScanInterfaceResponse
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+	if len(resp.InterfaceNames) != 1 || resp.InterfaceNames[0] != name {
+		t.Errorf("InterfaceNames = %v, want [%s]", resp.InterfaceNames, name)
+	}
+
+	w = doRequest(mux, "GET", "/api/v1/settings/scan-interface", nil)
+	resp = settings.ScanInterfaceResponse{}
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+	if resp.InterfaceName != name || len(resp.InterfaceNames) != 1 {
+		t.Errorf("GetScanInterface = %+v, want %s", resp, name)
+	}
+}
+
+func TestHandleSetScanInterface_ArrayWithUnknown(t *testing.T) {
+	_, mux := setupHandlerEnv(t)
+
+	w := doRequest(mux, "POST", "/api/v1/settings/scan-interface", map[string]any{
+		"interface_name": []string{"nonexistent_interface_xyz"},
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("SetScanInterface status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	w = doRequest(mux, "POST", "/api/v1/settings/scan-interface", map[string]any{
+		"interface_name": 42,
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("SetScanInterface with number status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
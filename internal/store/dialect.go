@@ -0,0 +1,95 @@
+package store
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// The stores and migrations are written in SQLite syntax. rewritePostgres
+// translates a statement to PostgreSQL: "?" placeholders become "$n", and
+// the SQLite types, functions, and conflict clauses used in this codebase
+// are mapped to their PostgreSQL equivalents. Anything not covered here is
+// passed through unchanged, so new SQL should stick to the portable subset.
+var postgresRules = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	// Functions first: the DATETIME type rule would otherwise match datetime(.
+	{regexp.MustCompile(`(?i)\bdatetime\('now'\)`), `CURRENT_TIMESTAMP`},
+	{regexp.MustCompile(`(?i)\bstrftime\('%Y-%m-%dT%H:%M:%fZ',\s*'now'\)`),
+		`to_char(CURRENT_TIMESTAMP AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.MS"Z"')`},
+	{regexp.MustCompile(`(?i)\bjson_extract\(([\w.]+),\s*'\$\[(\d+)\]'\)`), `($1::json->>$2)`},
+
+	// Column types. SQLite INTEGER is 64-bit, so map it to BIGINT.
+	{regexp.MustCompile(`(?i)\bINTEGER\s+PRIMARY\s+KEY\s+AUTOINCREMENT\b`), `BIGSERIAL PRIMARY KEY`},
+	{regexp.MustCompile(`(?i)\bINTEGER\b`), `BIGINT`},
+	{regexp.MustCompile(`(?i)\bDATETIME\b`), `TIMESTAMPTZ`},
+	{regexp.MustCompile(`(?i)\bBLOB\b`), `BYTEA`},
+	{regexp.MustCompile(`(?i)\bREAL\b`), `DOUBLE PRECISION`},
+
+	// SQLite LIKE is case-insensitive for ASCII.
+	{regexp.MustCompile(`(?i)\bLIKE\b`), `ILIKE`},
+
+	// PostgreSQL has no NOCASE collation: compare and sort lower-cased
+	// values instead. A comparison against a placeholder lowers both sides.
+	{regexp.MustCompile(`(?i)([\w.]+)\s*=\s*\?\s+COLLATE\s+NOCASE\b`), `LOWER($1) = LOWER(?)`},
+	{regexp.MustCompile(`(?i)([\w.]+)\s+COLLATE\s+NOCASE\b`), `LOWER($1)`},
+}
+
+var insertOrIgnore = regexp.MustCompile(`(?i)^(\s*)INSERT\s+OR\s+IGNORE\s+INTO\b`)
+
+// postgresQueries caches rewritten statements; the set of distinct queries
+// is small and fixed by the code.
+var postgresQueries sync.Map // string -> string
+
+// rewritePostgres returns query translated to PostgreSQL syntax.
+func rewritePostgres(query string) string {
+	if v, ok := postgresQueries.Load(query); ok {
+		return v.(string)
+	}
+
+	q := query
+	if insertOrIgnore.MatchString(q) {
+		q = insertOrIgnore.ReplaceAllString(q, "${1}INSERT INTO")
+		q = strings.TrimRight(q, "; \t\r\n") + " ON CONFLICT DO NOTHING"
+	}
+	for _, r := range postgresRules {
+		q = r.re.ReplaceAllString(q, r.repl)
+	}
+	q = rebindPlaceholders(q)
+
+	postgresQueries.Store(query, q)
+	return q
+}
+
+// rebindPlaceholders replaces "?" with "$1", "$2", ... outside quoted
+// strings and identifiers.
+func rebindPlaceholders(query string) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	n := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?':
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,139 @@
+package store
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRewritePostgres(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "placeholders",
+			query: "SELECT id FROM t WHERE a = ? AND b = ?",
+			want:  "SELECT id FROM t WHERE a = $1 AND b = $2",
+		},
+		{
+			name:  "quoted question mark untouched",
+			query: "SELECT '?' FROM t WHERE a = ?",
+			want:  "SELECT '?' FROM t WHERE a = $1",
+		},
+		{
+			name:  "insert or ignore",
+			query: "INSERT OR IGNORE INTO t (a) VALUES (?)",
+			want:  "INSERT INTO t (a) VALUES ($1) ON CONFLICT DO NOTHING",
+		},
+		{
+			name:  "column types",
+			query: "CREATE TABLE t (id INTEGER PRIMARY KEY AUTOINCREMENT, n INTEGER, at DATETIME, b BLOB, r REAL)",
+			want:  "CREATE TABLE t (id BIGSERIAL PRIMARY KEY, n BIGINT, at TIMESTAMPTZ, b BYTEA, r DOUBLE PRECISION)",
+		},
+		{
+			name:  "datetime now",
+			query: "UPDATE t SET updated_at = datetime('now')",
+			want:  "UPDATE t SET updated_at = CURRENT_TIMESTAMP",
+		},
+		{
+			name:  "json_extract",
+			query: "SELECT json_extract(d.ips, '$[0]') FROM d",
+			want:  "SELECT (d.ips::json->>0) FROM d",
+		},
+		{
+			name:  "like",
+			query: "SELECT id FROM t WHERE name LIKE ?",
+			want:  "SELECT id FROM t WHERE name ILIKE $1",
+		},
+		{
+			name:  "collate nocase order",
+			query: "SELECT name FROM t ORDER BY sw.name COLLATE NOCASE, version",
+			want:  "SELECT name FROM t ORDER BY LOWER(sw.name), version",
+		},
+		{
+			name:  "collate nocase comparison",
+			query: "SELECT id FROM t WHERE location = ? COLLATE NOCASE",
+			want:  "SELECT id FROM t WHERE LOWER(location) = LOWER($1)",
+		},
+		{
+			name:  "upsert passes through",
+			query: "INSERT INTO t (k, v) VALUES (?, ?) ON CONFLICT(k) DO UPDATE SET v = excluded.v",
+			want:  "INSERT INTO t (k, v) VALUES ($1, $2) ON CONFLICT(k) DO UPDATE SET v = excluded.v",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rewritePostgres(tt.query); got != tt.want {
+				t.Errorf("rewritePostgres(%q)\n got: %s\nwant: %s", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpen_UnknownDriver(t *testing.T) {
+	if _, err := Open("mysql", "x"); err == nil {
+		t.Error("Open with unknown driver should fail")
+	}
+}
+
+// sqliteOnly matches SQLite syntax that PostgreSQL rejects.
+var sqliteOnly = regexp.MustCompile(`(?i)\bCOLLATE\s+NOCASE\b|\bAUTOINCREMENT\b|\bINSERT\s+OR\s+(IGNORE|REPLACE)\b|\b(datetime|strftime|json_extract|julianday|ifnull|group_concat)\(`)
+
+// TestRewritePostgres_ModuleQueries runs every string literal in the
+// non-test sources through the rewriter and fails on SQLite-only syntax
+// that would reach PostgreSQL unchanged.
+func TestRewritePostgres_ModuleQueries(t *testing.T) {
+	root := filepath.Join("..", "..")
+	fset := token.NewFileSet()
+	checked := 0
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case "node_modules", "web", "dist", "vendor", ".git":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// dialect.go holds the rewrite patterns themselves.
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") || path == filepath.Join(root, "internal", "store", "dialect.go") {
+			return nil
+		}
+		f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			lit, ok := n.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			s, err := strconv.Unquote(lit.Value)
+			if err != nil || !sqliteOnly.MatchString(s) {
+				return true
+			}
+			checked++
+			if got := rewritePostgres(s); sqliteOnly.MatchString(got) {
+				t.Errorf("%s: SQLite-only syntax survives rewrite: %s", fset.Position(lit.Pos()), strings.TrimSpace(got))
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checked == 0 {
+		t.Fatal("found no SQLite-specific statements to check")
+	}
+}
@@ -6,7 +6,11 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/HerbHall/subnetree/internal/tracing"
 	"github.com/HerbHall/subnetree/pkg/plugin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/mod/semver"
 	_ "modernc.org/sqlite" // Pure-Go SQLite driver
 )
@@ -15,16 +19,53 @@ import (
 // of SubNetree than the currently running binary.
 var ErrNewerSchema = fmt.Errorf("database was created by a newer version of SubNetree")
 
-// Compile-time interface guard.
-var _ plugin.Store = (*SQLiteStore)(nil)
+// Compile-time interface guards.
+var (
+	_ Store = (*SQLiteStore)(nil)
+	_ Store = (*PostgresStore)(nil)
+)
 
-// SQLiteStore implements plugin.Store backed by SQLite via modernc.org/sqlite.
-type SQLiteStore struct {
+// Supported values for the database.driver setting.
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// Store is the database handle owned by the composition root: a plugin.Store
+// plus schema version checks and shutdown.
+type Store interface {
+	plugin.Store
+	CheckVersion(ctx context.Context, currentVersion string) error
+	Close() error
+}
+
+// Open opens the database for the given driver. For SQLite dsn is a file
+// path (or ":memory:"); for PostgreSQL it is a connection string. An empty
+// driver selects SQLite.
+func Open(driver, dsn string) (Store, error) {
+	switch driver {
+	case "", DriverSQLite:
+		return New(dsn)
+	case DriverPostgres:
+		return NewPostgres(dsn)
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q (want %q or %q)", driver, DriverSQLite, DriverPostgres)
+	}
+}
+
+// sqlStore holds the driver-independent plugin.Store implementation. Its
+// queries use SQLite syntax; the PostgreSQL connection rewrites them.
+type sqlStore struct {
 	db   *sql.DB
 	mu   sync.Mutex // Serialize migrations
 	once sync.Once  // Ensure _migrations table created once
 }
 
+// SQLiteStore implements plugin.Store backed by SQLite via modernc.org/sqlite.
+type SQLiteStore struct {
+	sqlStore
+}
+
 // New opens (or creates) a SQLite database at the given path and applies
 // recommended pragmas for WAL mode, foreign keys, and performance.
 // Returns the concrete type; callers assign to plugin.Store where needed.
@@ -58,17 +99,20 @@ func New(path string) (*SQLiteStore, error) {
 		}
 	}
 
-	return &SQLiteStore{db: db}, nil
+	return &SQLiteStore{sqlStore{db: db}}, nil
 }
 
 // DB returns the underlying *sql.DB for direct queries.
-func (s *SQLiteStore) DB() *sql.DB {
+func (s *sqlStore) DB() *sql.DB {
 	return s.db
 }
 
 // Tx executes fn within a database transaction. The transaction is
 // committed if fn returns nil, rolled back otherwise.
-func (s *SQLiteStore) Tx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+func (s *sqlStore) Tx(ctx context.Context, fn func(tx *sql.Tx) error) (err error) {
+	ctx, span := tracing.Tracer("store").Start(ctx, "store.tx")
+	defer func() { endSpan(span, err) }()
+
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("begin tx: %w", err)
@@ -87,7 +131,11 @@ func (s *SQLiteStore) Tx(ctx context.Context, fn func(tx *sql.Tx) error) error {
 // Migrate runs pending migrations for the named plugin. Already-applied
 // migrations (tracked in the shared _migrations table) are skipped.
 // Migrations must be provided in ascending Version order.
-func (s *SQLiteStore) Migrate(ctx context.Context, pluginName string, migrations []plugin.Migration) error {
+func (s *sqlStore) Migrate(ctx context.Context, pluginName string, migrations []plugin.Migration) (err error) {
+	ctx, span := tracing.Tracer("store").Start(ctx, "store.migrate")
+	span.SetAttributes(attribute.String("plugin.name", pluginName))
+	defer func() { endSpan(span, err) }()
+
 	if err := s.ensureMigrationsTable(ctx); err != nil {
 		return err
 	}
@@ -112,8 +160,17 @@ func (s *SQLiteStore) Migrate(ctx context.Context, pluginName string, migrations
 	return nil
 }
 
+// endSpan records err on span, if any, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
 // Close closes the underlying database connection.
-func (s *SQLiteStore) Close() error {
+func (s *sqlStore) Close() error {
 	return s.db.Close()
 }
 
@@ -121,7 +178,7 @@ func (s *SQLiteStore) Close() error {
 // in the database. It prevents an older binary from opening a database created
 // by a newer version, which could corrupt data. The special version "dev"
 // always passes (both as stored and as current).
-func (s *SQLiteStore) CheckVersion(ctx context.Context, currentVersion string) error {
+func (s *sqlStore) CheckVersion(ctx context.Context, currentVersion string) error {
 	if err := s.ensureSchemaMetaTable(ctx); err != nil {
 		return fmt.Errorf("ensure schema meta table: %w", err)
 	}
@@ -180,7 +237,7 @@ func (s *SQLiteStore) CheckVersion(ctx context.Context, currentVersion string) e
 }
 
 // ensureSchemaMetaTable creates the _schema_meta table if it doesn't exist.
-func (s *SQLiteStore) ensureSchemaMetaTable(ctx context.Context) error {
+func (s *sqlStore) ensureSchemaMetaTable(ctx context.Context) error {
 	_, err := s.db.ExecContext(ctx, `
 		CREATE TABLE IF NOT EXISTS _schema_meta (
 			id           INTEGER  PRIMARY KEY CHECK (id = 1),
@@ -201,7 +258,7 @@ func normalizeVersion(v string) string {
 
 // ensureMigrationsTable creates the shared _migrations tracking table if it
 // doesn't already exist. Safe to call multiple times (uses sync.Once).
-func (s *SQLiteStore) ensureMigrationsTable(ctx context.Context) error {
+func (s *sqlStore) ensureMigrationsTable(ctx context.Context) error {
 	var err error
 	s.once.Do(func() {
 		_, err = s.db.ExecContext(ctx, `
@@ -217,7 +274,7 @@ func (s *SQLiteStore) ensureMigrationsTable(ctx context.Context) error {
 	return err
 }
 
-func (s *SQLiteStore) isMigrationApplied(ctx context.Context, pluginName string, version int) (bool, error) {
+func (s *sqlStore) isMigrationApplied(ctx context.Context, pluginName string, version int) (bool, error) {
 	var count int
 	err := s.db.QueryRowContext(ctx,
 		"SELECT COUNT(*) FROM _migrations WHERE plugin_name = ? AND version = ?",
@@ -229,7 +286,7 @@ func (s *SQLiteStore) isMigrationApplied(ctx context.Context, pluginName string,
 	return count > 0, nil
 }
 
-func (s *SQLiteStore) applyMigration(ctx context.Context, pluginName string, m plugin.Migration) error {
+func (s *sqlStore) applyMigration(ctx context.Context, pluginName string, m plugin.Migration) error {
 	return s.Tx(ctx, func(tx *sql.Tx) error {
 		if err := m.Up(tx); err != nil {
 			return err
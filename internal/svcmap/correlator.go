@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/HerbHall/subnetree/internal/tracing"
 	"github.com/HerbHall/subnetree/pkg/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.uber.org/zap"
 )
 
@@ -61,7 +64,20 @@ func (c *Correlator) CorrelateDevice(
 	agentID string,
 	svcSource ServiceSource,
 	appSource AppSource,
-) error {
+) (err error) {
+	ctx, span := tracing.Tracer("svcmap").Start(ctx, "svcmap.correlate_device")
+	span.SetAttributes(
+		attribute.String("device.id", deviceID),
+		attribute.String("agent.id", agentID),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
 	now := time.Now().UTC()
 
 	// Fetch Scout services if agent is linked.
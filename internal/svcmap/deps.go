@@ -0,0 +1,141 @@
+package svcmap
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+// AlertSource reports devices with active critical alerts (e.g., from Pulse).
+type AlertSource interface {
+	CriticalDeviceIDs(ctx context.Context) (map[string]bool, error)
+}
+
+// ServiceGraph is the dependency graph around a service: the services it
+// transitively depends on and the services that transitively depend on it.
+type ServiceGraph struct {
+	RootID string              `json:"root_id"`
+	Nodes  []ServiceGraphNode  `json:"nodes"`
+	Edges  []ServiceDependency `json:"edges"` // service_id depends on depends_on_id
+}
+
+// ServiceGraphNode is a service in a ServiceGraph with its alert impact.
+type ServiceGraphNode struct {
+	models.Service
+	DeviceCritical bool     `json:"device_critical"`       // The service's own device has a critical alert
+	Impacted       bool     `json:"impacted"`              // An upstream service's device has a critical alert
+	ImpactedBy     []string `json:"impacted_by,omitempty"` // IDs of those upstream services
+}
+
+// dependencyIndex indexes dependencies in both directions.
+type dependencyIndex struct {
+	upstream   map[string][]string // service ID -> services it depends on
+	downstream map[string][]string // service ID -> services depending on it
+}
+
+func newDependencyIndex(deps []ServiceDependency) dependencyIndex {
+	idx := dependencyIndex{
+		upstream:   make(map[string][]string),
+		downstream: make(map[string][]string),
+	}
+	for _, d := range deps {
+		idx.upstream[d.ServiceID] = append(idx.upstream[d.ServiceID], d.DependsOnID)
+		idx.downstream[d.DependsOnID] = append(idx.downstream[d.DependsOnID], d.ServiceID)
+	}
+	return idx
+}
+
+// reachable returns the services reachable from id along edges, excluding
+// id itself, in breadth-first order.
+func reachable(edges map[string][]string, id string) []string {
+	seen := map[string]bool{id: true}
+	var order []string
+	queue := []string{id}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, next := range edges[cur] {
+			if seen[next] {
+				continue
+			}
+			seen[next] = true
+			order = append(order, next)
+			queue = append(queue, next)
+		}
+	}
+	return order
+}
+
+// createsCycle reports whether making serviceID depend on dependsOnID would
+// close a dependency cycle.
+func createsCycle(deps []ServiceDependency, serviceID, dependsOnID string) bool {
+	if serviceID == dependsOnID {
+		return true
+	}
+	for _, id := range reachable(newDependencyIndex(deps).upstream, dependsOnID) {
+		if id == serviceID {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildServiceGraph returns the dependency graph around a service. When
+// alerts is set, services whose device has a critical alert are flagged and
+// every service depending on them, directly or transitively, is marked
+// impacted. Returns nil, nil if the service does not exist.
+func BuildServiceGraph(ctx context.Context, store *Store, rootID string, alerts AlertSource) (*ServiceGraph, error) {
+	services, err := store.ListServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*models.Service, len(services))
+	for i := range services {
+		byID[services[i].ID] = &services[i]
+	}
+	if byID[rootID] == nil {
+		return nil, nil
+	}
+
+	deps, err := store.ListDependencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	idx := newDependencyIndex(deps)
+
+	critical := map[string]bool{}
+	if alerts != nil {
+		if critical, err = alerts.CriticalDeviceIDs(ctx); err != nil {
+			return nil, fmt.Errorf("critical devices: %w", err)
+		}
+	}
+
+	ids := []string{rootID}
+	ids = append(ids, reachable(idx.upstream, rootID)...)
+	ids = append(ids, reachable(idx.downstream, rootID)...)
+	inGraph := make(map[string]bool, len(ids))
+
+	graph := &ServiceGraph{RootID: rootID, Nodes: []ServiceGraphNode{}, Edges: []ServiceDependency{}}
+	for _, id := range ids {
+		svc := byID[id]
+		if svc == nil {
+			continue
+		}
+		inGraph[id] = true
+		node := ServiceGraphNode{Service: *svc, DeviceCritical: critical[svc.DeviceID]}
+		for _, upID := range reachable(idx.upstream, id) {
+			if up := byID[upID]; up != nil && critical[up.DeviceID] {
+				node.ImpactedBy = append(node.ImpactedBy, upID)
+			}
+		}
+		node.Impacted = len(node.ImpactedBy) > 0
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	for _, d := range deps {
+		if inGraph[d.ServiceID] && inGraph[d.DependsOnID] {
+			graph.Edges = append(graph.Edges, d)
+		}
+	}
+	return graph, nil
+}
@@ -0,0 +1,130 @@
+package svcmap
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+// stubAlertSource reports a fixed set of critical devices.
+type stubAlertSource struct {
+	critical map[string]bool
+}
+
+func (s *stubAlertSource) CriticalDeviceIDs(_ context.Context) (map[string]bool, error) {
+	return s.critical, nil
+}
+
+func addDependency(t *testing.T, mux *http.ServeMux, serviceID, dependsOnID string) int {
+	t.Helper()
+	body := `{"depends_on_id":"` + dependsOnID + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/svcmap/services/"+serviceID+"/dependencies", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestHandleAddDependency_Validation(t *testing.T) {
+	store, mux := testHarness(t)
+	seedService(t, store, "svc-app", "app", "dev-1", models.ServiceStatusRunning)
+	seedService(t, store, "svc-db", "postgres", "dev-2", models.ServiceStatusRunning)
+
+	if code := addDependency(t, mux, "svc-app", "svc-db"); code != http.StatusCreated {
+		t.Fatalf("add dependency: expected 201, got %d", code)
+	}
+	tests := []struct {
+		name        string
+		serviceID   string
+		dependsOnID string
+		want        int
+	}{
+		{"cycle", "svc-db", "svc-app", http.StatusConflict},
+		{"self", "svc-app", "svc-app", http.StatusConflict},
+		{"unknown upstream", "svc-app", "svc-missing", http.StatusNotFound},
+		{"missing depends_on_id", "svc-app", "", http.StatusBadRequest},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if code := addDependency(t, mux, tc.serviceID, tc.dependsOnID); code != tc.want {
+				t.Errorf("expected %d, got %d", tc.want, code)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/svcmap/services/svc-app/dependencies/svc-db", http.NoBody)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("delete dependency: expected 204, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/v1/svcmap/services/svc-app/dependencies/svc-db", http.NoBody))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("delete missing dependency: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestBuildServiceGraph_Impact(t *testing.T) {
+	store, mux := testHarness(t)
+	// web -> app -> db, and an unrelated cache.
+	seedService(t, store, "svc-web", "nginx", "dev-web", models.ServiceStatusRunning)
+	seedService(t, store, "svc-app", "app", "dev-app", models.ServiceStatusRunning)
+	seedService(t, store, "svc-db", "postgres", "dev-db", models.ServiceStatusRunning)
+	seedService(t, store, "svc-cache", "redis", "dev-cache", models.ServiceStatusRunning)
+	for _, d := range [][2]string{{"svc-web", "svc-app"}, {"svc-app", "svc-db"}} {
+		if code := addDependency(t, mux, d[0], d[1]); code != http.StatusCreated {
+			t.Fatalf("add dependency %v: expected 201, got %d", d, code)
+		}
+	}
+
+	alerts := &stubAlertSource{critical: map[string]bool{"dev-db": true}}
+	graph, err := BuildServiceGraph(context.Background(), store, "svc-app", alerts)
+	if err != nil {
+		t.Fatalf("BuildServiceGraph: %v", err)
+	}
+	if len(graph.Nodes) != 3 || len(graph.Edges) != 2 {
+		t.Fatalf("graph has %d nodes and %d edges, want 3 and 2", len(graph.Nodes), len(graph.Edges))
+	}
+	nodes := make(map[string]ServiceGraphNode)
+	for _, n := range graph.Nodes {
+		nodes[n.ID] = n
+	}
+	if _, ok := nodes["svc-cache"]; ok {
+		t.Error("unrelated service should not be in the graph")
+	}
+	if n := nodes["svc-db"]; !n.DeviceCritical || n.Impacted {
+		t.Errorf("db node = %+v, want device critical and not impacted", n)
+	}
+	for _, id := range []string{"svc-app", "svc-web"} {
+		if n := nodes[id]; !n.Impacted || len(n.ImpactedBy) != 1 || n.ImpactedBy[0] != "svc-db" {
+			t.Errorf("%s node = %+v, want impacted by svc-db", id, n)
+		}
+	}
+
+	// Without an alert source nothing is impacted.
+	handlerGraph := httptest.NewRecorder()
+	mux.ServeHTTP(handlerGraph, httptest.NewRequest(http.MethodGet, "/api/v1/svcmap/services/svc-web/graph", http.NoBody))
+	if handlerGraph.Code != http.StatusOK {
+		t.Fatalf("graph: expected 200, got %d", handlerGraph.Code)
+	}
+	var got ServiceGraph
+	if err := json.NewDecoder(handlerGraph.Body).Decode(&got); err != nil {
+		t.Fatalf("decode graph: %v", err)
+	}
+	for _, n := range got.Nodes {
+		if n.Impacted {
+			t.Errorf("node %s impacted without an alert source", n.ID)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/svcmap/services/svc-missing/graph", http.NoBody))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("graph of missing service: expected 404, got %d", rec.Code)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"database/sql"
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/HerbHall/subnetree/pkg/models"
 	"go.uber.org/zap"
@@ -13,6 +16,7 @@ import (
 type Handler struct {
 	store    *Store
 	hwSource HardwareSource
+	alerts   AlertSource
 	logger   *zap.Logger
 }
 
@@ -21,14 +25,28 @@ func NewHandler(store *Store, hwSource HardwareSource, logger *zap.Logger) *Hand
 	return &Handler{store: store, hwSource: hwSource, logger: logger}
 }
 
+// SetAlertSource sets the source of critical device alerts used to mark
+// impacted services in dependency graphs. Called from the composition root
+// before the server starts.
+func (h *Handler) SetAlertSource(alerts AlertSource) {
+	h.alerts = alerts
+}
+
 // RegisterRoutes registers svcmap HTTP routes on the given mux.
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/v1/svcmap/services", h.handleListServices)
 	mux.HandleFunc("GET /api/v1/svcmap/services/{id}", h.handleGetService)
 	mux.HandleFunc("PATCH /api/v1/svcmap/services/{id}", h.handleUpdateDesiredState)
+	mux.HandleFunc("GET /api/v1/svcmap/services/{id}/dependencies", h.handleListDependencies)
+	mux.HandleFunc("POST /api/v1/svcmap/services/{id}/dependencies", h.handleAddDependency)
+	mux.HandleFunc("DELETE /api/v1/svcmap/services/{id}/dependencies/{depends_on_id}", h.handleRemoveDependency)
+	mux.HandleFunc("GET /api/v1/svcmap/services/{id}/graph", h.handleServiceGraph)
 	mux.HandleFunc("GET /api/v1/svcmap/devices/{device_id}/services", h.handleDeviceServices)
 	mux.HandleFunc("GET /api/v1/svcmap/devices/{device_id}/utilization", h.handleDeviceUtilization)
 	mux.HandleFunc("GET /api/v1/svcmap/utilization/fleet", h.handleFleetSummary)
+	mux.HandleFunc("GET /api/v1/svcmap/port-names", h.handleListPortNames)
+	mux.HandleFunc("POST /api/v1/svcmap/port-names", h.handleSetPortName)
+	mux.HandleFunc("DELETE /api/v1/svcmap/port-names/{protocol}/{port}", h.handleDeletePortName)
 }
 
 // handleListServices returns all services, optionally filtered by query params.
@@ -154,6 +172,155 @@ func (h *Handler) handleUpdateDesiredState(w http.ResponseWriter, r *http.Reques
 	svcmapWriteJSON(w, http.StatusOK, svc)
 }
 
+// handleListDependencies returns the dependencies of a service in both
+// directions.
+//
+//	@Summary		List service dependencies
+//	@Description	Returns the dependencies where the service is either the dependent or the dependency.
+//	@Tags			svcmap
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Service ID"
+//	@Success		200	{array}		ServiceDependency
+//	@Router			/svcmap/services/{id}/dependencies [get]
+func (h *Handler) handleListDependencies(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	deps, err := h.store.ListDependencies(r.Context())
+	if err != nil {
+		h.logger.Warn("failed to list service dependencies", zap.String("id", id), zap.Error(err))
+		svcmapWriteError(w, http.StatusInternalServerError, "failed to list service dependencies")
+		return
+	}
+
+	result := []ServiceDependency{}
+	for _, d := range deps {
+		if d.ServiceID == id || d.DependsOnID == id {
+			result = append(result, d)
+		}
+	}
+	svcmapWriteJSON(w, http.StatusOK, result)
+}
+
+// addDependencyRequest is the request body for declaring a dependency.
+type addDependencyRequest struct {
+	DependsOnID string `json:"depends_on_id"`
+}
+
+// handleAddDependency declares that a service depends on another service.
+//
+//	@Summary		Add service dependency
+//	@Description	Declares that the service depends on another service, e.g. an application on its database. Dependencies that would form a cycle are rejected.
+//	@Tags			svcmap
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string					true	"Service ID"
+//	@Param			body	body		addDependencyRequest	true	"Upstream service"
+//	@Success		201		{object}	ServiceDependency
+//	@Failure		400		{object}	models.APIProblem
+//	@Failure		404		{object}	models.APIProblem
+//	@Failure		409		{object}	models.APIProblem
+//	@Router			/svcmap/services/{id}/dependencies [post]
+func (h *Handler) handleAddDependency(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var req addDependencyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		svcmapWriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.DependsOnID == "" {
+		svcmapWriteError(w, http.StatusBadRequest, "depends_on_id is required")
+		return
+	}
+
+	for _, sid := range []string{id, req.DependsOnID} {
+		svc, err := h.store.GetService(r.Context(), sid)
+		if err != nil {
+			h.logger.Warn("failed to get service", zap.String("id", sid), zap.Error(err))
+			svcmapWriteError(w, http.StatusInternalServerError, "failed to get service")
+			return
+		}
+		if svc == nil {
+			svcmapWriteError(w, http.StatusNotFound, "service not found: "+sid)
+			return
+		}
+	}
+
+	deps, err := h.store.ListDependencies(r.Context())
+	if err != nil {
+		h.logger.Warn("failed to list service dependencies", zap.Error(err))
+		svcmapWriteError(w, http.StatusInternalServerError, "failed to add service dependency")
+		return
+	}
+	if createsCycle(deps, id, req.DependsOnID) {
+		svcmapWriteError(w, http.StatusConflict, "dependency would create a cycle")
+		return
+	}
+
+	dep := &ServiceDependency{ServiceID: id, DependsOnID: req.DependsOnID, CreatedAt: time.Now().UTC()}
+	if err := h.store.AddDependency(r.Context(), dep); err != nil {
+		h.logger.Warn("failed to add service dependency", zap.String("id", id), zap.Error(err))
+		svcmapWriteError(w, http.StatusInternalServerError, "failed to add service dependency")
+		return
+	}
+	svcmapWriteJSON(w, http.StatusCreated, dep)
+}
+
+// handleRemoveDependency removes a service dependency.
+//
+//	@Summary		Remove service dependency
+//	@Description	Removes the dependency of a service on another service.
+//	@Tags			svcmap
+//	@Security		BearerAuth
+//	@Param			id				path	string	true	"Service ID"
+//	@Param			depends_on_id	path	string	true	"Upstream service ID"
+//	@Success		204	"No Content"
+//	@Failure		404	{object}	models.APIProblem
+//	@Router			/svcmap/services/{id}/dependencies/{depends_on_id} [delete]
+func (h *Handler) handleRemoveDependency(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	dependsOnID := r.PathValue("depends_on_id")
+
+	if err := h.store.RemoveDependency(r.Context(), id, dependsOnID); err != nil {
+		if err == sql.ErrNoRows {
+			svcmapWriteError(w, http.StatusNotFound, "dependency not found")
+			return
+		}
+		h.logger.Warn("failed to remove service dependency", zap.String("id", id), zap.Error(err))
+		svcmapWriteError(w, http.StatusInternalServerError, "failed to remove service dependency")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleServiceGraph returns the dependency graph around a service.
+//
+//	@Summary		Service dependency graph
+//	@Description	Returns the services a service transitively depends on and the services that transitively depend on it. Services downstream of a service whose device has a critical alert are marked impacted.
+//	@Tags			svcmap
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Service ID"
+//	@Success		200	{object}	ServiceGraph
+//	@Failure		404	{object}	models.APIProblem
+//	@Router			/svcmap/services/{id}/graph [get]
+func (h *Handler) handleServiceGraph(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	graph, err := BuildServiceGraph(r.Context(), h.store, id, h.alerts)
+	if err != nil {
+		h.logger.Warn("failed to build service graph", zap.String("id", id), zap.Error(err))
+		svcmapWriteError(w, http.StatusInternalServerError, "failed to build service graph")
+		return
+	}
+	if graph == nil {
+		svcmapWriteError(w, http.StatusNotFound, "service not found")
+		return
+	}
+	svcmapWriteJSON(w, http.StatusOK, graph)
+}
+
 // handleDeviceServices returns all services for a specific device.
 //
 //	@Summary		Device services
@@ -246,6 +413,114 @@ func (h *Handler) handleFleetSummary(w http.ResponseWriter, r *http.Request) {
 	svcmapWriteJSON(w, http.StatusOK, fleet)
 }
 
+// handleListPortNames returns the custom port name mappings.
+//
+//	@Summary		List port names
+//	@Description	Returns the user-defined port to service name mappings. These override the built-in IANA and homelab names.
+//	@Tags			svcmap
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{array}	PortName
+//	@Router			/svcmap/port-names [get]
+func (h *Handler) handleListPortNames(w http.ResponseWriter, r *http.Request) {
+	names, err := h.store.ListPortNames(r.Context())
+	if err != nil {
+		h.logger.Warn("failed to list port names", zap.Error(err))
+		svcmapWriteError(w, http.StatusInternalServerError, "failed to list port names")
+		return
+	}
+	if names == nil {
+		names = []PortName{}
+	}
+	svcmapWriteJSON(w, http.StatusOK, names)
+}
+
+// setPortNameRequest is the request body for a custom port name mapping.
+type setPortNameRequest struct {
+	Port     int    `json:"port" example:"32400"`
+	Protocol string `json:"protocol,omitempty" example:"tcp"`
+	Name     string `json:"name" example:"Plex"`
+}
+
+// handleSetPortName creates or replaces a custom port name mapping.
+//
+//	@Summary		Set port name
+//	@Description	Creates or replaces the service name for a port. The protocol defaults to tcp.
+//	@Tags			svcmap
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			body	body		setPortNameRequest	true	"Port name mapping"
+//	@Success		200		{object}	PortName
+//	@Failure		400		{object}	models.APIProblem
+//	@Router			/svcmap/port-names [post]
+func (h *Handler) handleSetPortName(w http.ResponseWriter, r *http.Request) {
+	var req setPortNameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		svcmapWriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Protocol == "" {
+		req.Protocol = "tcp"
+	}
+	req.Protocol = strings.ToLower(req.Protocol)
+	req.Name = strings.TrimSpace(req.Name)
+
+	if !validPort(req.Port) {
+		svcmapWriteError(w, http.StatusBadRequest, "port must be between 1 and 65535")
+		return
+	}
+	if !validProtocol(req.Protocol) {
+		svcmapWriteError(w, http.StatusBadRequest, "protocol must be tcp or udp")
+		return
+	}
+	if req.Name == "" {
+		svcmapWriteError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	pn := &PortName{Port: req.Port, Protocol: req.Protocol, Name: req.Name, UpdatedAt: time.Now().UTC()}
+	if err := h.store.SetPortName(r.Context(), pn); err != nil {
+		h.logger.Warn("failed to set port name", zap.Int("port", req.Port), zap.Error(err))
+		svcmapWriteError(w, http.StatusInternalServerError, "failed to set port name")
+		return
+	}
+	svcmapWriteJSON(w, http.StatusOK, pn)
+}
+
+// handleDeletePortName removes a custom port name mapping, restoring the
+// built-in name if there is one.
+//
+//	@Summary		Delete port name
+//	@Description	Removes a user-defined port name mapping.
+//	@Tags			svcmap
+//	@Security		BearerAuth
+//	@Param			protocol	path	string	true	"Protocol (tcp or udp)"
+//	@Param			port		path	int		true	"Port number"
+//	@Success		204	"No Content"
+//	@Failure		400	{object}	models.APIProblem
+//	@Failure		404	{object}	models.APIProblem
+//	@Router			/svcmap/port-names/{protocol}/{port} [delete]
+func (h *Handler) handleDeletePortName(w http.ResponseWriter, r *http.Request) {
+	protocol := strings.ToLower(r.PathValue("protocol"))
+	port, err := strconv.Atoi(r.PathValue("port"))
+	if err != nil || !validPort(port) || !validProtocol(protocol) {
+		svcmapWriteError(w, http.StatusBadRequest, "invalid port or protocol")
+		return
+	}
+
+	if err := h.store.DeletePortName(r.Context(), port, protocol); err != nil {
+		if err == sql.ErrNoRows {
+			svcmapWriteError(w, http.StatusNotFound, "port name not found")
+			return
+		}
+		h.logger.Warn("failed to delete port name", zap.Int("port", port), zap.Error(err))
+		svcmapWriteError(w, http.StatusInternalServerError, "failed to delete port name")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func svcmapWriteJSON(w http.ResponseWriter, status int, data any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
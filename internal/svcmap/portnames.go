@@ -0,0 +1,116 @@
+package svcmap
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed port_names.txt
+var portNamesRawData []byte
+
+// portKey identifies a port mapping by port number and protocol.
+type portKey struct {
+	port     int
+	protocol string
+}
+
+var (
+	builtinPortNamesOnce sync.Once
+	builtinPortNames     map[portKey]string
+)
+
+// builtinPortName returns the embedded name for a port, or "" if unknown.
+func builtinPortName(port int, protocol string) string {
+	builtinPortNamesOnce.Do(loadBuiltinPortNames)
+	return builtinPortNames[portKey{port: port, protocol: protocol}]
+}
+
+// loadBuiltinPortNames parses the embedded port data. Later lines override
+// earlier ones, so the homelab entries take precedence over IANA names.
+func loadBuiltinPortNames() {
+	builtinPortNames = make(map[portKey]string, 128)
+	scanner := bufio.NewScanner(bytes.NewReader(portNamesRawData))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		port, protocol, ok := parsePortSpec(parts[0])
+		name := strings.TrimSpace(parts[1])
+		if ok && name != "" {
+			builtinPortNames[portKey{port: port, protocol: protocol}] = name
+		}
+	}
+}
+
+// parsePortSpec extracts the port and protocol from a service port entry
+// such as "8123", "53/udp", or a Docker mapping like "0.0.0.0:8080->80/tcp",
+// where the container port is used. The protocol defaults to tcp.
+func parsePortSpec(spec string) (port int, protocol string, ok bool) {
+	spec = strings.TrimSpace(spec)
+	if i := strings.LastIndex(spec, "->"); i >= 0 {
+		spec = spec[i+2:]
+	}
+	protocol = "tcp"
+	if p, proto, found := strings.Cut(spec, "/"); found {
+		spec, protocol = p, strings.ToLower(proto)
+	}
+	if i := strings.LastIndex(spec, ":"); i >= 0 {
+		spec = spec[i+1:]
+	}
+	port, err := strconv.Atoi(spec)
+	if err != nil || !validPort(port) || !validProtocol(protocol) {
+		return 0, "", false
+	}
+	return port, protocol, true
+}
+
+func validPort(port int) bool {
+	return port >= 1 && port <= 65535
+}
+
+func validProtocol(protocol string) bool {
+	return protocol == "tcp" || protocol == "udp"
+}
+
+// PortNames resolves port numbers to service names. Custom mappings take
+// precedence over the embedded IANA and homelab defaults.
+type PortNames struct {
+	custom map[portKey]string
+}
+
+// Lookup returns the service name for a port, or "" if unknown.
+func (p *PortNames) Lookup(port int, protocol string) string {
+	if name := p.custom[portKey{port: port, protocol: protocol}]; name != "" {
+		return name
+	}
+	return builtinPortName(port, protocol)
+}
+
+// Resolve returns the names of the given service port entries keyed by
+// entry. Entries without a known name are omitted; returns nil if none
+// resolve.
+func (p *PortNames) Resolve(specs []string) map[string]string {
+	var names map[string]string
+	for _, spec := range specs {
+		port, protocol, ok := parsePortSpec(spec)
+		if !ok {
+			continue
+		}
+		if name := p.Lookup(port, protocol); name != "" {
+			if names == nil {
+				names = make(map[string]string)
+			}
+			names[spec] = name
+		}
+	}
+	return names
+}
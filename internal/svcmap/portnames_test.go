@@ -0,0 +1,157 @@
+package svcmap
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+func TestParsePortSpec(t *testing.T) {
+	tests := []struct {
+		spec      string
+		wantPort  int
+		wantProto string
+		wantOK    bool
+	}{
+		{"8123", 8123, "tcp", true},
+		{"53/udp", 53, "udp", true},
+		{"80/TCP", 80, "tcp", true},
+		{"0.0.0.0:8080->80/tcp", 80, "tcp", true},
+		{"[::]:32400->32400/tcp", 32400, "tcp", true},
+		{"127.0.0.1:5432", 5432, "tcp", true},
+		{"0", 0, "", false},
+		{"70000", 0, "", false},
+		{"80/sctp", 0, "", false},
+		{"http", 0, "", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.spec, func(t *testing.T) {
+			port, proto, ok := parsePortSpec(tc.spec)
+			if port != tc.wantPort || proto != tc.wantProto || ok != tc.wantOK {
+				t.Errorf("parsePortSpec(%q) = %d, %q, %v; want %d, %q, %v",
+					tc.spec, port, proto, ok, tc.wantPort, tc.wantProto, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestBuiltinPortName(t *testing.T) {
+	tests := []struct {
+		port     int
+		protocol string
+		want     string
+	}{
+		{22, "tcp", "ssh"},
+		{32400, "tcp", "Plex"},
+		{8123, "tcp", "Home Assistant"},
+		{9000, "tcp", "Portainer"}, // homelab entry overrides IANA
+		{51820, "udp", "WireGuard"},
+		{51820, "tcp", ""},
+		{1, "tcp", ""},
+	}
+	for _, tc := range tests {
+		if got := builtinPortName(tc.port, tc.protocol); got != tc.want {
+			t.Errorf("builtinPortName(%d, %q) = %q, want %q", tc.port, tc.protocol, got, tc.want)
+		}
+	}
+}
+
+func TestStore_PortNames(t *testing.T) {
+	store, _ := testHarness(t)
+	ctx := context.Background()
+
+	if err := store.SetPortName(ctx, &PortName{Port: 8123, Protocol: "tcp", Name: "HA", UpdatedAt: time.Now().UTC()}); err != nil {
+		t.Fatalf("SetPortName: %v", err)
+	}
+	if err := store.SetPortName(ctx, &PortName{Port: 7777, Protocol: "tcp", Name: "Agent", UpdatedAt: time.Now().UTC()}); err != nil {
+		t.Fatalf("SetPortName: %v", err)
+	}
+
+	names, err := store.LoadPortNames(ctx)
+	if err != nil {
+		t.Fatalf("LoadPortNames: %v", err)
+	}
+	got := names.Resolve([]string{"0.0.0.0:8123->8123/tcp", "7777/tcp", "22", "1234/tcp"})
+	want := map[string]string{"0.0.0.0:8123->8123/tcp": "HA", "7777/tcp": "Agent", "22": "ssh"}
+	if len(got) != len(want) {
+		t.Fatalf("Resolve = %v, want %v", got, want)
+	}
+	for spec, name := range want {
+		if got[spec] != name {
+			t.Errorf("Resolve[%q] = %q, want %q", spec, got[spec], name)
+		}
+	}
+
+	if name, err := store.LookupPortName(ctx, 7777, "tcp"); err != nil || name != "Agent" {
+		t.Errorf("LookupPortName(7777) = %q, %v; want Agent", name, err)
+	}
+	if err := store.DeletePortName(ctx, 8123, "tcp"); err != nil {
+		t.Fatalf("DeletePortName: %v", err)
+	}
+	if name, err := store.LookupPortName(ctx, 8123, "tcp"); err != nil || name != "Home Assistant" {
+		t.Errorf("LookupPortName(8123) after delete = %q, %v; want built-in name", name, err)
+	}
+}
+
+func TestHandlePortNames(t *testing.T) {
+	store, mux := testHarness(t)
+	seedService(t, store, "svc-plex", "plex", "dev-1", models.ServiceStatusRunning)
+	svc, err := store.GetService(context.Background(), "svc-plex")
+	if err != nil || svc == nil {
+		t.Fatalf("GetService: %v", err)
+	}
+	svc.Ports = []string{"0.0.0.0:32400->32400/tcp", "9999/tcp"}
+	if err := store.UpsertService(context.Background(), svc); err != nil {
+		t.Fatalf("UpsertService: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"valid", `{"port":9999,"name":"Tautulli"}`, http.StatusOK},
+		{"port out of range", `{"port":0,"name":"x"}`, http.StatusBadRequest},
+		{"bad protocol", `{"port":9999,"protocol":"icmp","name":"x"}`, http.StatusBadRequest},
+		{"missing name", `{"port":9999,"name":" "}`, http.StatusBadRequest},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/svcmap/port-names", strings.NewReader(tc.body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != tc.want {
+				t.Errorf("expected %d, got %d: %s", tc.want, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/svcmap/services", http.NoBody)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	var services []models.Service
+	if err := json.NewDecoder(rec.Body).Decode(&services); err != nil {
+		t.Fatalf("decode services: %v", err)
+	}
+	if len(services) != 1 {
+		t.Fatalf("expected 1 service, got %d", len(services))
+	}
+	if got := services[0].PortNames; got["0.0.0.0:32400->32400/tcp"] != "Plex" || got["9999/tcp"] != "Tautulli" {
+		t.Errorf("port_names = %v, want Plex and Tautulli", got)
+	}
+
+	for _, want := range []int{http.StatusNoContent, http.StatusNotFound} {
+		req = httptest.NewRequest(http.MethodDelete, "/api/v1/svcmap/port-names/tcp/9999", http.NoBody)
+		rec = httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("delete port name: expected %d, got %d", want, rec.Code)
+		}
+	}
+}
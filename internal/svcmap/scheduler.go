@@ -2,8 +2,12 @@ package svcmap
 
 import (
 	"context"
+	"sync"
 	"time"
 
+	"github.com/HerbHall/subnetree/internal/tracing"
+	"github.com/HerbHall/subnetree/pkg/plugin"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -29,6 +33,11 @@ type Scheduler struct {
 	logger     *zap.Logger
 	cancel     context.CancelFunc
 	done       chan struct{}
+
+	mu      sync.Mutex // guards the fields below
+	running bool
+	lastRun time.Time
+	lastErr error
 }
 
 // NewScheduler creates a new correlation scheduler.
@@ -54,6 +63,9 @@ func NewScheduler(
 // Start begins periodic correlation in a background goroutine.
 func (s *Scheduler) Start(ctx context.Context) {
 	ctx, s.cancel = context.WithCancel(ctx)
+	s.mu.Lock()
+	s.running = true
+	s.mu.Unlock()
 	go s.run(ctx)
 	s.logger.Info("svcmap scheduler started", zap.Duration("interval", s.interval))
 }
@@ -64,9 +76,45 @@ func (s *Scheduler) Stop() {
 		s.cancel()
 	}
 	<-s.done
+	s.mu.Lock()
+	s.running = false
+	s.mu.Unlock()
 	s.logger.Info("svcmap scheduler stopped")
 }
 
+// Health reports whether correlation cycles are running on schedule. It has
+// the shape of plugin.HealthChecker so the server can report it alongside
+// plugins.
+func (s *Scheduler) Health(_ context.Context) plugin.HealthStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		return plugin.HealthStatus{Status: "unhealthy", Message: "scheduler not running"}
+	}
+	details := map[string]string{"interval": s.interval.String()}
+	if s.lastRun.IsZero() {
+		return plugin.HealthStatus{Status: "healthy", Message: "waiting for first correlation cycle", Details: details}
+	}
+	details["last_run"] = s.lastRun.UTC().Format(time.RFC3339)
+	if s.lastErr != nil {
+		return plugin.HealthStatus{Status: "degraded", Message: "last correlation cycle failed: " + s.lastErr.Error(), Details: details}
+	}
+	// Allow a few missed ticks before flagging a stall.
+	if time.Since(s.lastRun) > 3*s.interval {
+		return plugin.HealthStatus{Status: "degraded", Message: "correlation cycle overdue", Details: details}
+	}
+	return plugin.HealthStatus{Status: "healthy", Details: details}
+}
+
+// recordRun records the outcome of a correlation cycle for Health.
+func (s *Scheduler) recordRun(err error) {
+	s.mu.Lock()
+	s.lastRun = time.Now()
+	s.lastErr = err
+	s.mu.Unlock()
+}
+
 func (s *Scheduler) run(ctx context.Context) {
 	defer close(s.done)
 
@@ -84,8 +132,13 @@ func (s *Scheduler) run(ctx context.Context) {
 }
 
 func (s *Scheduler) correlateAll(ctx context.Context) {
+	ctx, span := tracing.Tracer("svcmap").Start(ctx, "svcmap.correlate_all")
+	defer span.End()
+
 	agents, err := s.agents.ListAgentsWithDevice(ctx)
+	s.recordRun(err)
 	if err != nil {
+		span.RecordError(err)
 		s.logger.Warn("failed to list agents for correlation", zap.Error(err))
 		return
 	}
@@ -109,6 +162,10 @@ func (s *Scheduler) correlateAll(ctx context.Context) {
 		}
 		correlated++
 	}
+	span.SetAttributes(
+		attribute.Int("svcmap.agents", len(agents)),
+		attribute.Int("svcmap.correlated", correlated),
+	)
 
 	s.logger.Debug("correlation cycle complete",
 		zap.Int("agents", len(agents)),
@@ -0,0 +1,54 @@
+package svcmap
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// stubAgentLister returns a fixed agent list or error.
+type stubAgentLister struct {
+	err error
+}
+
+func (s *stubAgentLister) ListAgentsWithDevice(_ context.Context) ([]AgentRef, error) {
+	return nil, s.err
+}
+
+func TestSchedulerHealth(t *testing.T) {
+	agents := &stubAgentLister{}
+	s := NewScheduler(nil, nil, nil, agents, time.Minute, zap.NewNop())
+	ctx := context.Background()
+
+	if got := s.Health(ctx).Status; got != "unhealthy" {
+		t.Errorf("before Start: status = %q, want %q", got, "unhealthy")
+	}
+
+	s.Start(ctx)
+	defer s.Stop()
+	if got := s.Health(ctx).Status; got != "healthy" {
+		t.Errorf("before first cycle: status = %q, want %q", got, "healthy")
+	}
+
+	agents.err = errors.New("db locked")
+	s.correlateAll(ctx)
+	if got := s.Health(ctx).Status; got != "degraded" {
+		t.Errorf("after failed cycle: status = %q, want %q", got, "degraded")
+	}
+
+	agents.err = nil
+	s.correlateAll(ctx)
+	if got := s.Health(ctx).Status; got != "healthy" {
+		t.Errorf("after good cycle: status = %q, want %q", got, "healthy")
+	}
+
+	s.mu.Lock()
+	s.lastRun = time.Now().Add(-time.Hour)
+	s.mu.Unlock()
+	if got := s.Health(ctx).Status; got != "degraded" {
+		t.Errorf("overdue cycle: status = %q, want %q", got, "degraded")
+	}
+}
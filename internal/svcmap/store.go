@@ -15,7 +15,8 @@ type Store struct {
 	db *sql.DB
 }
 
-// NewStore creates a new Store and ensures the services table exists.
+// NewStore creates a new Store and ensures the services, service
+// dependency, and port name tables exist.
 func NewStore(db *sql.DB) (*Store, error) {
 	s := &Store{db: db}
 	if err := s.migrate(); err != nil {
@@ -44,6 +45,23 @@ func (s *Store) migrate() error {
 
 		CREATE INDEX IF NOT EXISTS idx_services_device_id ON services(device_id);
 		CREATE INDEX IF NOT EXISTS idx_services_service_type ON services(service_type);
+
+		CREATE TABLE IF NOT EXISTS svcmap_service_deps (
+			service_id    TEXT NOT NULL,
+			depends_on_id TEXT NOT NULL,
+			created_at    DATETIME NOT NULL,
+			PRIMARY KEY (service_id, depends_on_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_svcmap_service_deps_depends_on ON svcmap_service_deps(depends_on_id);
+
+		CREATE TABLE IF NOT EXISTS svcmap_port_names (
+			port       INTEGER NOT NULL,
+			protocol   TEXT NOT NULL,
+			name       TEXT NOT NULL,
+			updated_at DATETIME NOT NULL,
+			PRIMARY KEY (port, protocol)
+		);
 	`)
 	return err
 }
@@ -150,7 +168,8 @@ func (s *Store) UpdateDesiredState(ctx context.Context, id string, state models.
 	return nil
 }
 
-// DeleteService removes a service by ID.
+// DeleteService removes a service by ID, along with its dependencies in
+// either direction.
 func (s *Store) DeleteService(ctx context.Context, id string) error {
 	res, err := s.db.ExecContext(ctx, `DELETE FROM services WHERE id = ?`, id)
 	if err != nil {
@@ -160,6 +179,11 @@ func (s *Store) DeleteService(ctx context.Context, id string) error {
 	if n == 0 {
 		return sql.ErrNoRows
 	}
+	if _, err := s.db.ExecContext(ctx,
+		`DELETE FROM svcmap_service_deps WHERE service_id = ? OR depends_on_id = ?`, id, id,
+	); err != nil {
+		return fmt.Errorf("delete service dependencies: %w", err)
+	}
 	return nil
 }
 
@@ -246,7 +270,8 @@ type ServiceFilter struct {
 	Status      string
 }
 
-// ListServicesFiltered returns services matching the given filter criteria.
+// ListServicesFiltered returns services matching the given filter criteria,
+// with their ports resolved to service names.
 func (s *Store) ListServicesFiltered(ctx context.Context, filter ServiceFilter) ([]models.Service, error) {
 	query := `SELECT id, name, display_name, service_type, device_id,
 		application_id, status, desired_state, ports_json,
@@ -273,7 +298,173 @@ func (s *Store) ListServicesFiltered(ctx context.Context, filter ServiceFilter)
 		return nil, fmt.Errorf("list services filtered: %w", err)
 	}
 	defer rows.Close()
-	return scanServices(rows)
+	services, err := scanServices(rows)
+	if err != nil || len(services) == 0 {
+		return services, err
+	}
+
+	names, err := s.LoadPortNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range services {
+		services[i].PortNames = names.Resolve(services[i].Ports)
+	}
+	return services, nil
+}
+
+// ServiceDependency records that a service depends on another service, e.g.
+// an application on its database.
+type ServiceDependency struct {
+	ServiceID   string    `json:"service_id"`
+	DependsOnID string    `json:"depends_on_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AddDependency records a service dependency. Adding an existing dependency
+// is a no-op.
+func (s *Store) AddDependency(ctx context.Context, dep *ServiceDependency) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO svcmap_service_deps (service_id, depends_on_id, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (service_id, depends_on_id) DO NOTHING`,
+		dep.ServiceID, dep.DependsOnID, dep.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("add service dependency: %w", err)
+	}
+	return nil
+}
+
+// RemoveDependency deletes a service dependency.
+// Returns sql.ErrNoRows if it does not exist.
+func (s *Store) RemoveDependency(ctx context.Context, serviceID, dependsOnID string) error {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM svcmap_service_deps WHERE service_id = ? AND depends_on_id = ?`,
+		serviceID, dependsOnID,
+	)
+	if err != nil {
+		return fmt.Errorf("remove service dependency: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListDependencies returns all service dependencies.
+func (s *Store) ListDependencies(ctx context.Context) ([]ServiceDependency, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT service_id, depends_on_id, created_at
+		FROM svcmap_service_deps ORDER BY service_id, depends_on_id`)
+	if err != nil {
+		return nil, fmt.Errorf("list service dependencies: %w", err)
+	}
+	defer rows.Close()
+
+	var deps []ServiceDependency
+	for rows.Next() {
+		var d ServiceDependency
+		if err := rows.Scan(&d.ServiceID, &d.DependsOnID, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan service dependency row: %w", err)
+		}
+		deps = append(deps, d)
+	}
+	return deps, rows.Err()
+}
+
+// PortName is a user-defined port to service name mapping. It overrides the
+// embedded defaults for the same port and protocol.
+type PortName struct {
+	Port      int       `json:"port" example:"32400"`
+	Protocol  string    `json:"protocol" example:"tcp"`
+	Name      string    `json:"name" example:"Plex"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SetPortName creates or replaces a custom port name mapping.
+func (s *Store) SetPortName(ctx context.Context, pn *PortName) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO svcmap_port_names (port, protocol, name, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (port, protocol) DO UPDATE SET
+			name = excluded.name,
+			updated_at = excluded.updated_at`,
+		pn.Port, pn.Protocol, pn.Name, pn.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("set port name: %w", err)
+	}
+	return nil
+}
+
+// DeletePortName deletes a custom port name mapping.
+// Returns sql.ErrNoRows if it does not exist.
+func (s *Store) DeletePortName(ctx context.Context, port int, protocol string) error {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM svcmap_port_names WHERE port = ? AND protocol = ?`,
+		port, protocol,
+	)
+	if err != nil {
+		return fmt.Errorf("delete port name: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListPortNames returns all custom port name mappings.
+func (s *Store) ListPortNames(ctx context.Context) ([]PortName, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT port, protocol, name, updated_at
+		FROM svcmap_port_names ORDER BY port, protocol`)
+	if err != nil {
+		return nil, fmt.Errorf("list port names: %w", err)
+	}
+	defer rows.Close()
+
+	var names []PortName
+	for rows.Next() {
+		var pn PortName
+		if err := rows.Scan(&pn.Port, &pn.Protocol, &pn.Name, &pn.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan port name row: %w", err)
+		}
+		names = append(names, pn)
+	}
+	return names, rows.Err()
+}
+
+// LoadPortNames returns a resolver over the custom port name mappings and
+// the embedded defaults.
+func (s *Store) LoadPortNames(ctx context.Context) (*PortNames, error) {
+	custom, err := s.ListPortNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := &PortNames{custom: make(map[portKey]string, len(custom))}
+	for _, pn := range custom {
+		names.custom[portKey{port: pn.Port, protocol: pn.Protocol}] = pn.Name
+	}
+	return names, nil
+}
+
+// LookupPortName returns the service name for a port, or "" if unknown.
+func (s *Store) LookupPortName(ctx context.Context, port int, protocol string) (string, error) {
+	var name string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT name FROM svcmap_port_names WHERE port = ? AND protocol = ?`,
+		port, protocol,
+	).Scan(&name)
+	if err == nil {
+		return name, nil
+	}
+	if err != sql.ErrNoRows {
+		return "", fmt.Errorf("lookup port name: %w", err)
+	}
+	return builtinPortName(port, protocol), nil
 }
 
 // scanServices extracts services from database rows.
@@ -21,6 +21,9 @@ type TailscaleDevice struct {
 	LastSeen  string   `json:"lastSeen"` // RFC3339
 	Online    bool     `json:"online"`
 	NodeKey   string   `json:"nodeKey"`
+
+	AdvertisedRoutes []string `json:"advertisedRoutes"` // Subnet and exit routes the device offers
+	EnabledRoutes    []string `json:"enabledRoutes"`    // Advertised routes approved in the admin console
 }
 
 // TailscaleClient is a thin HTTP wrapper for the Tailscale API v2.
@@ -48,9 +51,9 @@ type devicesResponse struct {
 	Devices []TailscaleDevice `json:"devices"`
 }
 
-// ListDevices fetches all devices in the tailnet.
+// ListDevices fetches all devices in the tailnet, including their routes.
 func (c *TailscaleClient) ListDevices(ctx context.Context) (devices []TailscaleDevice, err error) {
-	url := fmt.Sprintf("%s/api/v2/tailnet/%s/devices", c.baseURL, c.tailnet)
+	url := fmt.Sprintf("%s/api/v2/tailnet/%s/devices?fields=all", c.baseURL, c.tailnet)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
 	if err != nil {
@@ -0,0 +1,120 @@
+package tailscale
+
+import (
+	"net/netip"
+	"strings"
+
+	"github.com/HerbHall/subnetree/pkg/models"
+)
+
+// RouteLinkType is the topology link type from a subnet router to a device
+// in one of the subnets it serves.
+const RouteLinkType = "tailscale_route"
+
+// Role tags and categories of devices that route traffic for the tailnet.
+const (
+	roleSubnetRouter = "subnet-router"
+	roleExitNode     = "exit-node"
+)
+
+// subnetRoutes returns the enabled subnet routes of a device. Exit node
+// default routes are excluded; see isExitNode.
+func subnetRoutes(tsDev *TailscaleDevice) []netip.Prefix {
+	var routes []netip.Prefix
+	for _, r := range tsDev.EnabledRoutes {
+		p, err := netip.ParsePrefix(r)
+		if err != nil || p.Bits() == 0 {
+			continue
+		}
+		routes = append(routes, p.Masked())
+	}
+	return routes
+}
+
+// isExitNode reports whether a device is an approved exit node, i.e. has an
+// enabled default route.
+func isExitNode(tsDev *TailscaleDevice) bool {
+	for _, r := range tsDev.EnabledRoutes {
+		if r == "0.0.0.0/0" || r == "::/0" {
+			return true
+		}
+	}
+	return false
+}
+
+// deviceRole returns the routing role of a device, or "" for an ordinary
+// host. A device that is both a subnet router and an exit node is reported
+// as a subnet router.
+func deviceRole(tsDev *TailscaleDevice) string {
+	switch {
+	case len(subnetRoutes(tsDev)) > 0:
+		return roleSubnetRouter
+	case isExitNode(tsDev):
+		return roleExitNode
+	default:
+		return ""
+	}
+}
+
+// syncedTags returns the tags mirrored from Tailscale for a device, before
+// prefixing: its ACL tags followed by its routing roles.
+func syncedTags(tsDev *TailscaleDevice) []string {
+	tags := append([]string(nil), tsDev.Tags...)
+	if len(subnetRoutes(tsDev)) > 0 {
+		tags = append(tags, roleSubnetRouter)
+	}
+	if isExitNode(tsDev) {
+		tags = append(tags, roleExitNode)
+	}
+	return tags
+}
+
+// routeCustomFields returns the custom fields describing a device's routes.
+func routeCustomFields(tsDev *TailscaleDevice) map[string]string {
+	routes := subnetRoutes(tsDev)
+	strs := make([]string, len(routes))
+	for i, r := range routes {
+		strs[i] = r.String()
+	}
+	exitNode := "false"
+	if isExitNode(tsDev) {
+		exitNode = "true"
+	}
+	return map[string]string{
+		"tailscale_routes":    strings.Join(strs, ","),
+		"tailscale_exit_node": exitNode,
+	}
+}
+
+// routedDeviceIDs returns the IDs of devices with an address in one of the
+// given subnets, excluding the router itself.
+func routedDeviceIDs(routes []netip.Prefix, routerID string, devices []models.Device) []string {
+	var ids []string
+	for i := range devices {
+		d := &devices[i]
+		if d.ID == routerID {
+			continue
+		}
+		for _, ip := range d.IPAddresses {
+			addr, err := netip.ParseAddr(ip)
+			if err != nil {
+				continue
+			}
+			if containsAddr(routes, addr) {
+				ids = append(ids, d.ID)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// containsAddr reports whether any of the prefixes contains addr.
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, p := range prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -12,6 +12,12 @@ type DeviceStore interface {
 	UpsertDevice(ctx context.Context, d *models.Device) (bool, error)
 	ListDevices(ctx context.Context, limit, offset int) ([]models.Device, int, error)
 	GetDeviceByMAC(ctx context.Context, mac string) (*models.Device, error)
+	// SetDeviceTags replaces the tags of a device. UpsertDevice only adds
+	// tags, so this is used to drop Tailscale tags removed from the ACLs.
+	SetDeviceTags(ctx context.Context, id string, tags []string) error
+	// SetRouteLinks replaces the RouteLinkType topology links from a subnet
+	// router with links to the given devices.
+	SetRouteLinks(ctx context.Context, routerID string, deviceIDs []string) error
 }
 
 // CredentialDecrypter retrieves decrypted credential data from the vault.
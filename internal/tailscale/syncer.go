@@ -3,6 +3,7 @@ package tailscale
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -10,6 +11,10 @@ import (
 	"go.uber.org/zap"
 )
 
+// tagPrefix marks SubNetree tags that mirror Tailscale ACL tags, so they can
+// be told apart from tags added manually.
+const tagPrefix = "ts:"
+
 // SyncResult summarises a single sync cycle.
 type SyncResult struct {
 	DevicesFound int `json:"devices_found"`
@@ -75,6 +80,11 @@ func (s *Syncer) Sync(ctx context.Context, client *TailscaleClient) (result *Syn
 			}
 		}
 
+		// Tags removed from the ACLs survive the upsert tag merge and must
+		// be dropped explicitly.
+		staleTags := matched != nil && hasStaleTags(matched.Tags, syncedTags(tsDev))
+		wasRouter := matched != nil && matched.CustomFields["tailscale_routes"] != ""
+
 		device := buildDevice(tsDev, shortHostname, matched)
 
 		created, upsertErr := s.store.UpsertDevice(ctx, device)
@@ -85,6 +95,14 @@ func (s *Syncer) Sync(ctx context.Context, client *TailscaleClient) (result *Syn
 			)
 			continue
 		}
+		if staleTags {
+			if err := s.store.SetDeviceTags(ctx, matched.ID, device.Tags); err != nil {
+				s.logger.Warn("failed to update tailscale device tags",
+					zap.String("id", matched.ID),
+					zap.Error(err),
+				)
+			}
+		}
 
 		if matched != nil {
 			seenIDs[matched.ID] = true
@@ -92,6 +110,17 @@ func (s *Syncer) Sync(ctx context.Context, client *TailscaleClient) (result *Syn
 			seenIDs[device.ID] = true
 		}
 
+		// Link subnet routers to the devices they serve; clear the links of
+		// devices that stopped routing.
+		if routes := subnetRoutes(tsDev); device.ID != "" && (len(routes) > 0 || wasRouter) {
+			if err := s.store.SetRouteLinks(ctx, device.ID, routedDeviceIDs(routes, device.ID, existing)); err != nil {
+				s.logger.Warn("failed to update tailscale route links",
+					zap.String("id", device.ID),
+					zap.Error(err),
+				)
+			}
+		}
+
 		switch {
 		case created:
 			res.Created++
@@ -123,7 +152,8 @@ func (s *Syncer) Sync(ctx context.Context, client *TailscaleClient) (result *Syn
 	return res, nil
 }
 
-// buildDevice creates or updates a Device from Tailscale data.
+// buildDevice creates or updates a Device from Tailscale data. Subnet
+// routers and exit nodes get a matching category unless one is already set.
 func buildDevice(tsDev *TailscaleDevice, shortHostname string, existing *models.Device) *models.Device {
 	now := time.Now().UTC()
 
@@ -134,6 +164,9 @@ func buildDevice(tsDev *TailscaleDevice, shortHostname string, existing *models.
 		"tailscale_tags":      strings.Join(tsDev.Tags, ","),
 		"tailscale_device_id": tsDev.ID,
 	}
+	for k, v := range routeCustomFields(tsDev) {
+		customFields[k] = v
+	}
 
 	status := models.DeviceStatusOffline
 	if tsDev.Online {
@@ -163,6 +196,10 @@ func buildDevice(tsDev *TailscaleDevice, shortHostname string, existing *models.
 		}
 
 		existing.IPAddresses = mergedIPs
+		existing.Tags = mergeTags(existing.Tags, syncedTags(tsDev))
+		if existing.Category == "" {
+			existing.Category = deviceRole(tsDev)
+		}
 		existing.Status = status
 		existing.LastSeen = now
 		if existing.OS == "" && tsDev.OS != "" {
@@ -182,7 +219,39 @@ func buildDevice(tsDev *TailscaleDevice, shortHostname string, existing *models.
 		LastSeen:        now,
 		FirstSeen:       now,
 		CustomFields:    customFields,
+		Tags:            mergeTags(nil, syncedTags(tsDev)),
+		Category:        deviceRole(tsDev),
+	}
+}
+
+// mergeTags returns the manually added tags of a device followed by its
+// Tailscale ACL and routing role tags, prefixed with tagPrefix. Mirrored
+// tags no longer set in Tailscale are dropped.
+// ["web", "ts:tag:old"] + ["tag:server"] -> ["web", "ts:tag:server"]
+func mergeTags(existing, aclTags []string) []string {
+	merged := make([]string, 0, len(existing)+len(aclTags))
+	for _, t := range existing {
+		if !strings.HasPrefix(t, tagPrefix) {
+			merged = append(merged, t)
+		}
+	}
+	for _, t := range aclTags {
+		if t = tagPrefix + t; !slices.Contains(merged, t) {
+			merged = append(merged, t)
+		}
+	}
+	return merged
+}
+
+// hasStaleTags reports whether a device carries a mirrored Tailscale tag
+// that is no longer among its synced tags.
+func hasStaleTags(existing, aclTags []string) bool {
+	for _, t := range existing {
+		if strings.HasPrefix(t, tagPrefix) && !slices.Contains(aclTags, strings.TrimPrefix(t, tagPrefix)) {
+			return true
+		}
 	}
+	return false
 }
 
 // extractShortHostname strips the MagicDNS suffix from a Tailscale name.
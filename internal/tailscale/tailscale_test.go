@@ -23,8 +23,9 @@ func TestContract(t *testing.T) {
 // --- Mock store ---
 
 type mockDeviceStore struct {
-	mu      sync.Mutex
-	devices []models.Device
+	mu         sync.Mutex
+	devices    []models.Device
+	routeLinks map[string][]string // router ID -> routed device IDs
 }
 
 func (s *mockDeviceStore) UpsertDevice(_ context.Context, d *models.Device) (bool, error) {
@@ -78,6 +79,30 @@ func (s *mockDeviceStore) GetDeviceByMAC(_ context.Context, mac string) (*models
 	return nil, nil
 }
 
+func (s *mockDeviceStore) SetDeviceTags(_ context.Context, id string, tags []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.devices {
+		if s.devices[i].ID == id {
+			s.devices[i].Tags = tags
+			return nil
+		}
+	}
+	return fmt.Errorf("device %s not found", id)
+}
+
+func (s *mockDeviceStore) SetRouteLinks(_ context.Context, routerID string, deviceIDs []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.routeLinks == nil {
+		s.routeLinks = make(map[string][]string)
+	}
+	s.routeLinks[routerID] = deviceIDs
+	return nil
+}
+
 // --- Syncer tests ---
 
 func newTestSyncer(store *mockDeviceStore) *Syncer {
@@ -315,6 +340,125 @@ func TestSyncer_MarkUnseen(t *testing.T) {
 	t.Error("existing Tailscale device not found in store after sync")
 }
 
+func TestSyncer_ACLTags(t *testing.T) {
+	// Pre-existing device with a manual tag and a tag since removed in Tailscale.
+	store := &mockDeviceStore{
+		devices: []models.Device{
+			{
+				ID:          "existing-1",
+				Hostname:    "web-server",
+				IPAddresses: []string{"192.168.1.10"},
+				Tags:        []string{"rack-2", "ts:tag:old"},
+			},
+		},
+	}
+
+	tsDevices := []TailscaleDevice{
+		{
+			ID:        "ts-1",
+			Name:      "web-server.tail123.ts.net",
+			Addresses: []string{"100.64.0.1"},
+			Tags:      []string{"tag:server", "tag:prod"},
+			Online:    true,
+		},
+		{
+			ID:        "ts-2",
+			Name:      "db-server.tail123.ts.net",
+			Addresses: []string{"100.64.0.2"},
+			Tags:      []string{"tag:db"},
+			Online:    true,
+		},
+	}
+
+	srv := newTestServer(t, tsDevices)
+	defer srv.Close()
+
+	syncer := newTestSyncer(store)
+	client := NewClient("test-key", srv.URL, "-")
+
+	if _, err := syncer.Sync(context.Background(), client); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	want := map[string]string{
+		"web-server": "rack-2,ts:tag:server,ts:tag:prod",
+		"db-server":  "ts:tag:db",
+	}
+	devices, _, _ := store.ListDevices(context.Background(), 100, 0)
+	for _, d := range devices {
+		if got := strings.Join(d.Tags, ","); got != want[d.Hostname] {
+			t.Errorf("%s tags = %q, want %q", d.Hostname, got, want[d.Hostname])
+		}
+	}
+}
+
+func TestSyncer_SubnetRouter(t *testing.T) {
+	store := &mockDeviceStore{
+		devices: []models.Device{
+			{ID: "nas", Hostname: "nas", IPAddresses: []string{"192.168.1.20"}},
+			{ID: "printer", Hostname: "printer", IPAddresses: []string{"192.168.2.5"}},
+			{ID: "gw", Hostname: "gateway", IPAddresses: []string{"192.168.1.1"}, Category: "core"},
+		},
+	}
+
+	tsDevices := []TailscaleDevice{
+		{
+			ID:               "ts-1",
+			Name:             "gateway.tail123.ts.net",
+			Addresses:        []string{"100.64.0.1"},
+			AdvertisedRoutes: []string{"192.168.1.0/24", "192.168.2.0/24", "0.0.0.0/0", "::/0"},
+			EnabledRoutes:    []string{"192.168.1.0/24", "0.0.0.0/0", "::/0"},
+			Online:           true,
+		},
+		{
+			ID:            "ts-2",
+			Name:          "vps.tail123.ts.net",
+			Addresses:     []string{"100.64.0.2"},
+			EnabledRoutes: []string{"0.0.0.0/0", "::/0"},
+			Online:        true,
+		},
+	}
+
+	srv := newTestServer(t, tsDevices)
+	defer srv.Close()
+
+	syncer := newTestSyncer(store)
+	client := NewClient("test-key", srv.URL, "-")
+
+	if _, err := syncer.Sync(context.Background(), client); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	devices, _, _ := store.ListDevices(context.Background(), 100, 0)
+	byHost := make(map[string]models.Device)
+	for _, d := range devices {
+		byHost[d.Hostname] = d
+	}
+
+	gw := byHost["gateway"]
+	if got := strings.Join(gw.Tags, ","); got != "ts:subnet-router,ts:exit-node" {
+		t.Errorf("gateway tags = %q, want ts:subnet-router,ts:exit-node", got)
+	}
+	if gw.Category != "core" {
+		t.Errorf("gateway category = %q, want manual category kept", gw.Category)
+	}
+	if gw.CustomFields["tailscale_routes"] != "192.168.1.0/24" || gw.CustomFields["tailscale_exit_node"] != "true" {
+		t.Errorf("gateway custom fields = %v", gw.CustomFields)
+	}
+	// Only the approved route is linked; the printer's subnet is merely advertised.
+	if got := store.routeLinks["gw"]; len(got) != 1 || got[0] != "nas" {
+		t.Errorf("gateway route links = %v, want [nas]", got)
+	}
+
+	vps := byHost["vps"]
+	if vps.Category != roleExitNode {
+		t.Errorf("vps category = %q, want %q", vps.Category, roleExitNode)
+	}
+	if _, ok := store.routeLinks[vps.ID]; ok {
+		t.Error("exit node without subnet routes should have no route links")
+	}
+}
+
 func TestExtractShortHostname(t *testing.T) {
 	tests := []struct {
 		input string
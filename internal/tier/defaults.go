@@ -9,30 +9,38 @@ import (
 // These are applied ONLY for keys not already set by user config.
 var TierDefaults = map[catalog.HardwareTier]map[string]any{
 	catalog.TierSBC: {
-		"plugins.pulse.check_interval": "5m",
-		"plugins.pulse.max_workers":    2,
-		"plugins.recon.scan_interval":  "5m",
-		"data_retention_days":          7,
-		"plugins.insight.enabled":      false,
-		"plugins.llm.enabled":          false,
+		"plugins.pulse.check_interval":        "5m",
+		"plugins.pulse.max_workers":           2,
+		"plugins.recon.scan_interval":         "5m",
+		"plugins.recon.concurrency":           16,
+		"plugins.recon.port_scan_concurrency": 4,
+		"data_retention_days":                 7,
+		"plugins.insight.enabled":             false,
+		"plugins.llm.enabled":                 false,
 	},
 	catalog.TierMiniPC: {
-		"plugins.pulse.check_interval": "2m",
-		"plugins.pulse.max_workers":    5,
-		"plugins.recon.scan_interval":  "2m",
-		"data_retention_days":          30,
+		"plugins.pulse.check_interval":        "2m",
+		"plugins.pulse.max_workers":           5,
+		"plugins.recon.scan_interval":         "2m",
+		"plugins.recon.concurrency":           64,
+		"plugins.recon.port_scan_concurrency": 10,
+		"data_retention_days":                 30,
 	},
 	catalog.TierCluster: {
-		"plugins.pulse.check_interval": "1m",
-		"plugins.pulse.max_workers":    10,
-		"plugins.recon.scan_interval":  "1m",
-		"data_retention_days":          90,
+		"plugins.pulse.check_interval":        "1m",
+		"plugins.pulse.max_workers":           10,
+		"plugins.recon.scan_interval":         "1m",
+		"plugins.recon.concurrency":           128,
+		"plugins.recon.port_scan_concurrency": 20,
+		"data_retention_days":                 90,
 	},
 	catalog.TierSMB: {
-		"plugins.pulse.check_interval": "1m",
-		"plugins.pulse.max_workers":    10,
-		"plugins.recon.scan_interval":  "1m",
-		"data_retention_days":          180,
+		"plugins.pulse.check_interval":        "1m",
+		"plugins.pulse.max_workers":           10,
+		"plugins.recon.scan_interval":         "1m",
+		"plugins.recon.concurrency":           256,
+		"plugins.recon.port_scan_concurrency": 32,
+		"data_retention_days":                 180,
 	},
 }
 
@@ -1,7 +1,10 @@
 package tier
 
 import (
+	"fmt"
 	"runtime"
+	"strconv"
+	"strings"
 
 	"github.com/HerbHall/subnetree/pkg/catalog"
 )
@@ -37,6 +40,49 @@ func Name(t catalog.HardwareTier) string {
 	}
 }
 
+// Sources of the active tier, reported by GET /api/v1/system/tier.
+const (
+	SourceDetected = "detected" // Chosen by DetectTier
+	SourceOverride = "override" // Forced by the tier.override config key
+)
+
+// Resolve returns the tier named by override, or the detected tier when
+// override is empty, along with the source of the result. Detection is
+// skipped entirely when an override is given.
+func Resolve(override string) (catalog.HardwareTier, string, error) {
+	if override == "" {
+		return DetectTier(), SourceDetected, nil
+	}
+	t, err := Parse(override)
+	if err != nil {
+		return 0, "", err
+	}
+	return t, SourceOverride, nil
+}
+
+// Parse converts a tier name ("sbc", "minipc", "nas", "cluster", "smb") or
+// number (0-4) to a HardwareTier. Names are case-insensitive and may use
+// spaces, dashes, or underscores ("Mini PC", "mini-pc").
+func Parse(s string) (catalog.HardwareTier, error) {
+	key := strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(s)))
+	switch key {
+	case "sbc":
+		return catalog.TierSBC, nil
+	case "minipc":
+		return catalog.TierMiniPC, nil
+	case "nas":
+		return catalog.TierNAS, nil
+	case "cluster":
+		return catalog.TierCluster, nil
+	case "smb", "smbserver":
+		return catalog.TierSMB, nil
+	}
+	if n, err := strconv.Atoi(key); err == nil && n >= int(catalog.TierSBC) && n <= int(catalog.TierSMB) {
+		return catalog.HardwareTier(n), nil
+	}
+	return 0, fmt.Errorf("unknown hardware tier %q (want sbc, minipc, nas, cluster, smb, or 0-4)", s)
+}
+
 // DetectTierWithRAM is exported for testing -- allows injecting RAM value.
 func DetectTierWithRAM(ramBytes uint64, arch string, cores int) catalog.HardwareTier {
 	isARM := arch == "arm" || arch == "arm64"
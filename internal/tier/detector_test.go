@@ -1,6 +1,9 @@
 package tier
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/HerbHall/subnetree/pkg/catalog"
@@ -90,6 +93,74 @@ func TestName(t *testing.T) {
 	}
 }
 
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    catalog.HardwareTier
+		wantErr bool
+	}{
+		{in: "sbc", want: catalog.TierSBC},
+		{in: "Mini PC", want: catalog.TierMiniPC},
+		{in: "mini-pc", want: catalog.TierMiniPC},
+		{in: "NAS", want: catalog.TierNAS},
+		{in: "cluster", want: catalog.TierCluster},
+		{in: "smb_server", want: catalog.TierSMB},
+		{in: "3", want: catalog.TierCluster},
+		{in: "5", wantErr: true},
+		{in: "mainframe", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Parse(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	got, source, err := Resolve("cluster")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got != catalog.TierCluster || source != SourceOverride {
+		t.Errorf("Resolve(\"cluster\") = %d, %q; want %d, %q", got, source, catalog.TierCluster, SourceOverride)
+	}
+
+	if _, source, _ = Resolve(""); source != SourceDetected {
+		t.Errorf("Resolve(\"\") source = %q, want %q", source, SourceDetected)
+	}
+
+	if _, _, err := Resolve("bogus"); err == nil {
+		t.Error("expected error for unknown override")
+	}
+}
+
+func TestHandleGetTier(t *testing.T) {
+	mux := http.NewServeMux()
+	NewHandler(catalog.TierSMB, SourceOverride).RegisterRoutes(mux)
+
+	req := httptest.NewRequest("GET", "/api/v1/system/tier", http.NoBody)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var body StatusResponse
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := StatusResponse{Tier: int(catalog.TierSMB), Name: "SMB Server", Source: SourceOverride}
+	if body != want {
+		t.Errorf("body = %+v, want %+v", body, want)
+	}
+}
+
 func TestApplyDefaults_DoesNotOverrideUserConfig(t *testing.T) {
 	v := viper.New()
 	// User explicitly set a value.
@@ -125,6 +196,9 @@ func TestApplyDefaults_SetsDefaultsForUnsetKeys(t *testing.T) {
 	if got := v.GetInt("data_retention_days"); got != 30 {
 		t.Errorf("data_retention_days = %d, want %d", got, 30)
 	}
+	if got := v.GetInt("plugins.recon.concurrency"); got != 64 {
+		t.Errorf("recon concurrency = %d, want %d", got, 64)
+	}
 }
 
 func TestApplyDefaults_UnknownTierIsNoOp(t *testing.T) {
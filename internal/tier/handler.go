@@ -0,0 +1,48 @@
+package tier
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/HerbHall/subnetree/pkg/catalog"
+)
+
+// StatusResponse is the response for GET /api/v1/system/tier.
+type StatusResponse struct {
+	Tier   int    `json:"tier" example:"1"`
+	Name   string `json:"name" example:"Mini PC"`
+	Source string `json:"source" example:"detected"` // "detected" or "override"
+}
+
+// Handler serves the active hardware tier.
+type Handler struct {
+	status StatusResponse
+}
+
+// NewHandler creates a handler reporting tier t, chosen by source.
+func NewHandler(t catalog.HardwareTier, source string) *Handler {
+	return &Handler{status: StatusResponse{
+		Tier:   int(t),
+		Name:   Name(t),
+		Source: source,
+	}}
+}
+
+// RegisterRoutes implements server.SimpleRouteRegistrar.
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/system/tier", h.handleGetTier)
+}
+
+// handleGetTier returns the active hardware tier.
+//
+//	@Summary		Get hardware tier
+//	@Description	Returns the active hardware tier and whether it was detected or forced by the tier.override config key.
+//	@Tags			system
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	StatusResponse
+//	@Router			/system/tier [get]
+func (h *Handler) handleGetTier(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(h.status)
+}
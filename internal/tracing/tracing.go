@@ -0,0 +1,91 @@
+// Package tracing configures OpenTelemetry tracing for SubNetree.
+//
+// Tracing is off by default. When disabled, the global tracer provider stays
+// the OpenTelemetry no-op provider, so instrumented code paths cost nothing
+// beyond a context lookup.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/HerbHall/subnetree/internal/version"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationPrefix namespaces tracer names by package.
+const instrumentationPrefix = "github.com/HerbHall/subnetree/"
+
+// Config controls trace export.
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Endpoint is the OTLP/HTTP collector URL, e.g.
+	// "http://localhost:4318/v1/traces".
+	Endpoint string `mapstructure:"endpoint"`
+	// SampleRate is the fraction of new traces recorded, 0 to 1. Requests
+	// that arrive with a sampled parent span are always recorded.
+	SampleRate  float64 `mapstructure:"sample_rate"`
+	ServiceName string  `mapstructure:"service_name"`
+}
+
+// DefaultConfig returns tracing disabled, sampling every trace once enabled.
+func DefaultConfig() Config {
+	return Config{
+		Endpoint:    "http://localhost:4318/v1/traces",
+		SampleRate:  1.0,
+		ServiceName: "subnetree",
+	}
+}
+
+// Setup installs the global tracer provider and W3C trace-context
+// propagator described by cfg. The returned function flushes buffered spans
+// and must be called on shutdown. When tracing is disabled Setup installs
+// nothing and returns a no-op shutdown function.
+func Setup(ctx context.Context, cfg Config) (shutdown func(context.Context) error, err error) {
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("tracing endpoint is required when tracing is enabled")
+	}
+	if cfg.SampleRate < 0 || cfg.SampleRate > 1 {
+		return nil, fmt.Errorf("tracing sample rate %v out of range [0, 1]", cfg.SampleRate)
+	}
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = "subnetree"
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("create OTLP exporter: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRate))),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", cfg.ServiceName),
+			attribute.String("service.version", version.Short()),
+		)),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return tp.Shutdown, nil
+}
+
+// Tracer returns the tracer for a SubNetree package, e.g. Tracer("recon").
+// It resolves the global provider on each call, so tracers obtained before
+// Setup still export once tracing is configured.
+func Tracer(pkg string) trace.Tracer {
+	return otel.Tracer(instrumentationPrefix + pkg)
+}
@@ -6,5 +6,6 @@ const (
 	TopicCredentialCreated  = "vault.credential.created"  //nolint:gosec // G101: event topic name, not a credential
 	TopicCredentialUpdated  = "vault.credential.updated"  //nolint:gosec // G101: event topic name, not a credential
 	TopicCredentialDeleted  = "vault.credential.deleted"  //nolint:gosec // G101: event topic name, not a credential
+	TopicCredentialRotated  = "vault.credential.rotated"  //nolint:gosec // G101: event topic name, not a credential
 	TopicKeysRotated        = "vault.keys.rotated"
 )
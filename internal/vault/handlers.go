@@ -2,6 +2,7 @@ package vault
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/HerbHall/subnetree/pkg/plugin"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
 )
 
 // Routes implements plugin.HTTPProvider.
@@ -20,8 +22,10 @@ func (m *Module) Routes() []plugin.Route {
 		{Method: "GET", Path: "/credentials/{id}", Handler: m.handleGetCredential},
 		{Method: "PUT", Path: "/credentials/{id}", Handler: m.handleUpdateCredential},
 		{Method: "DELETE", Path: "/credentials/{id}", Handler: m.handleDeleteCredential},
+		{Method: "POST", Path: "/credentials/{id}/rotate", Handler: m.handleRotateCredential},
 		// Decrypted data retrieval
 		{Method: "GET", Path: "/credentials/{id}/data", Handler: m.handleGetCredentialData},
+		{Method: "GET", Path: "/credentials/{id}/access-log", Handler: m.handleCredentialAccessLog},
 		// Device-scoped listing
 		{Method: "GET", Path: "/device-credentials/{device_id}", Handler: m.handleListDeviceCredentials},
 		// Key management
@@ -198,6 +202,7 @@ func (m *Module) handleGetCredential(w http.ResponseWriter, r *http.Request) {
 		ID: rec.ID, Name: rec.Name, Type: rec.Type,
 		DeviceID: rec.DeviceID, Description: rec.Description,
 		CreatedAt: rec.CreatedAt, UpdatedAt: rec.UpdatedAt,
+		RotatedAt: rec.RotatedAt,
 	}
 	vaultWriteJSON(w, http.StatusOK, meta)
 }
@@ -269,8 +274,9 @@ func (m *Module) handleGetCredentialData(w http.ResponseWriter, r *http.Request)
 			ID: rec.ID, Name: rec.Name, Type: rec.Type,
 			DeviceID: rec.DeviceID, Description: rec.Description,
 			CreatedAt: rec.CreatedAt, UpdatedAt: rec.UpdatedAt,
+			RotatedAt: rec.RotatedAt,
 		},
-		Data: data,
+		Data: maskCredentialData(rec.Type, data),
 	}
 	vaultWriteJSON(w, http.StatusOK, result)
 }
@@ -386,10 +392,57 @@ func (m *Module) handleUpdateCredential(w http.ResponseWriter, r *http.Request)
 		ID: rec.ID, Name: rec.Name, Type: rec.Type,
 		DeviceID: rec.DeviceID, Description: rec.Description,
 		CreatedAt: rec.CreatedAt, UpdatedAt: rec.UpdatedAt,
+		RotatedAt: rec.RotatedAt,
 	}
 	vaultWriteJSON(w, http.StatusOK, meta)
 }
 
+// rotateCredentialRequest is the expected JSON body for POST /credentials/{id}/rotate.
+type rotateCredentialRequest struct {
+	Data map[string]any `json:"data"`
+}
+
+// handleRotateCredential replaces a credential's secret data in place,
+// keeping its ID and metadata. Requires unsealed vault.
+func (m *Module) handleRotateCredential(w http.ResponseWriter, r *http.Request) {
+	if m.store == nil {
+		vaultWriteError(w, http.StatusServiceUnavailable, "vault store not available")
+		return
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		vaultWriteError(w, http.StatusBadRequest, "id is required")
+		return
+	}
+
+	var req rotateCredentialRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		vaultWriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	meta, err := m.RotateCredential(r.Context(), id, req.Data)
+	switch {
+	case errors.Is(err, ErrVaultSealed):
+		vaultWriteError(w, http.StatusServiceUnavailable, "vault is sealed")
+		return
+	case errors.Is(err, ErrCredentialNotFound):
+		vaultWriteError(w, http.StatusNotFound, "credential not found")
+		return
+	case errors.Is(err, errInvalidCredentialData):
+		vaultWriteError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		m.logger.Error("failed to rotate credential", zap.String("credential_id", id), zap.Error(err))
+		vaultWriteError(w, http.StatusInternalServerError, "failed to rotate credential")
+		return
+	}
+
+	m.auditLog(r, id, "rotate", "")
+	vaultWriteJSON(w, http.StatusOK, meta)
+}
+
 // handleDeleteCredential deletes a credential and its key. Works when sealed.
 func (m *Module) handleDeleteCredential(w http.ResponseWriter, r *http.Request) {
 	if m.store == nil {
@@ -670,6 +723,38 @@ func (m *Module) handleCredentialAudit(w http.ResponseWriter, r *http.Request) {
 	vaultWriteJSON(w, http.StatusOK, entries)
 }
 
+// handleCredentialAccessLog returns which modules decrypted a credential and when.
+func (m *Module) handleCredentialAccessLog(w http.ResponseWriter, r *http.Request) {
+	if m.store == nil {
+		vaultWriteError(w, http.StatusServiceUnavailable, "vault store not available")
+		return
+	}
+
+	id := r.PathValue("id")
+	rec, err := m.store.GetCredential(r.Context(), id)
+	if err != nil {
+		m.logger.Warn("failed to get credential", zap.String("id", id), zap.Error(err))
+		vaultWriteError(w, http.StatusInternalServerError, "failed to get credential")
+		return
+	}
+	if rec == nil {
+		vaultWriteError(w, http.StatusNotFound, "credential not found")
+		return
+	}
+
+	limit := vaultParseLimit(r, 100)
+	entries, err := m.store.ListCredentialAccess(r.Context(), id, limit)
+	if err != nil {
+		m.logger.Warn("failed to list credential access", zap.Error(err))
+		vaultWriteError(w, http.StatusInternalServerError, "failed to list credential access")
+		return
+	}
+	if entries == nil {
+		entries = []CredentialAccess{}
+	}
+	vaultWriteJSON(w, http.StatusOK, entries)
+}
+
 // --- Helpers ---
 
 // auditLog records a credential access event. Non-blocking -- errors are logged.
@@ -703,6 +788,43 @@ func (m *Module) publishEvent(topic string, payload any) {
 	})
 }
 
+// maskCredentialData replaces SSH key material with "****" so private keys
+// never leave the vault over the API. The key's public fingerprint is
+// returned instead so users can tell keys apart.
+func maskCredentialData(credType string, data map[string]any) map[string]any {
+	if credType != CredTypeSSHKey {
+		return data
+	}
+	if key, ok := data["private_key"].(string); ok && key != "" {
+		passphrase, _ := data["passphrase"].(string)
+		if fp := sshKeyFingerprint(key, passphrase); fp != "" {
+			data["fingerprint"] = fp
+		}
+	}
+	for _, field := range []string{"private_key", "passphrase"} {
+		if v, ok := data[field].(string); ok && v != "" {
+			data[field] = "****"
+		}
+	}
+	return data
+}
+
+// sshKeyFingerprint returns the SHA256 fingerprint of a private key's public
+// half, or "" if the key cannot be parsed.
+func sshKeyFingerprint(key, passphrase string) string {
+	var signer ssh.Signer
+	var err error
+	if passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(key), []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey([]byte(key))
+	}
+	if err != nil {
+		return ""
+	}
+	return ssh.FingerprintSHA256(signer.PublicKey())
+}
+
 // extractUserID attempts to extract the user ID from the request context.
 // Returns empty string if not available.
 func extractUserID(r *http.Request) string {
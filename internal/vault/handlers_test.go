@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -384,6 +385,45 @@ func TestHandleGetCredentialData_Success(t *testing.T) {
 	}
 }
 
+func TestHandleGetCredentialData_MasksSSHKey(t *testing.T) {
+	m := newTestModule(t)
+	key := testSSHKeyPEM(t, "hunter2")
+	insertTestCredential(t, m, "k1", "Deploy key", CredTypeSSHKey, "", map[string]any{
+		"username": "deploy", "private_key": key, "passphrase": "hunter2",
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/credentials/k1/data", http.NoBody)
+	req.SetPathValue("id", "k1")
+	rr := httptest.NewRecorder()
+	m.handleGetCredentialData(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body = %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var result CredentialData
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if result.Data["private_key"] != "****" || result.Data["passphrase"] != "****" {
+		t.Errorf("key material not masked: %v", result.Data)
+	}
+	if fp, _ := result.Data["fingerprint"].(string); !strings.HasPrefix(fp, "SHA256:") {
+		t.Errorf("fingerprint = %v, want SHA256 fingerprint", result.Data["fingerprint"])
+	}
+	if result.Data["username"] != "deploy" {
+		t.Errorf("username = %v, want deploy", result.Data["username"])
+	}
+
+	// Internal consumers still receive the real key.
+	data, err := m.DecryptCredentialData(context.Background(), "k1")
+	if err != nil {
+		t.Fatalf("DecryptCredentialData: %v", err)
+	}
+	if data["private_key"] != key {
+		t.Error("DecryptCredentialData returned masked key")
+	}
+}
+
 func TestHandleGetCredentialData_Sealed(t *testing.T) {
 	m := newSealedTestModule(t)
 
@@ -509,6 +549,85 @@ func TestHandleUpdateCredential_NotFound(t *testing.T) {
 
 // --- Delete Credential ---
 
+func TestHandleRotateCredential_Success(t *testing.T) {
+	m := newTestModule(t)
+	insertTestCredential(t, m, "c1", "Core SNMP", CredTypeSNMPv2c, "dev-1", map[string]any{"community": "public"})
+
+	body := `{"data":{"community":"n3w-c0mmunity"}}`
+	req := httptest.NewRequest(http.MethodPost, "/credentials/c1/rotate", bytes.NewBufferString(body))
+	req.SetPathValue("id", "c1")
+	rr := httptest.NewRecorder()
+	m.handleRotateCredential(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body = %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var meta CredentialMeta
+	if err := json.NewDecoder(rr.Body).Decode(&meta); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if meta.ID != "c1" || meta.Name != "Core SNMP" || meta.DeviceID != "dev-1" {
+		t.Errorf("metadata changed by rotation: %+v", meta)
+	}
+	if meta.RotatedAt == nil {
+		t.Error("RotatedAt not set")
+	}
+
+	// The same ID now decrypts to the new secret.
+	data, err := m.DecryptCredentialData(context.Background(), "c1")
+	if err != nil {
+		t.Fatalf("DecryptCredentialData: %v", err)
+	}
+	if data["community"] != "n3w-c0mmunity" {
+		t.Errorf("community = %v, want rotated value", data["community"])
+	}
+
+	rec, _ := m.store.GetCredential(context.Background(), "c1")
+	if rec.RotatedAt == nil {
+		t.Error("stored RotatedAt not set")
+	}
+}
+
+func TestHandleRotateCredential_Errors(t *testing.T) {
+	m := newTestModule(t)
+	insertTestCredential(t, m, "c1", "SSH", CredTypeSSHPassword, "", map[string]any{"username": "admin", "password": "old"})
+
+	tests := []struct {
+		name string
+		id   string
+		body string
+		want int
+	}{
+		{"not found", "missing", `{"data":{"username":"a","password":"b"}}`, http.StatusNotFound},
+		{"invalid data", "c1", `{"data":{"username":"admin"}}`, http.StatusBadRequest},
+		{"invalid body", "c1", `{`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/credentials/"+tt.id+"/rotate", bytes.NewBufferString(tt.body))
+			req.SetPathValue("id", tt.id)
+			rr := httptest.NewRecorder()
+			m.handleRotateCredential(rr, req)
+			if rr.Code != tt.want {
+				t.Errorf("status = %d, want %d; body = %s", rr.Code, tt.want, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleRotateCredential_Sealed(t *testing.T) {
+	m := newSealedTestModule(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/credentials/c1/rotate", bytes.NewBufferString(`{"data":{}}`))
+	req.SetPathValue("id", "c1")
+	rr := httptest.NewRecorder()
+	m.handleRotateCredential(rr, req)
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", rr.Code, http.StatusServiceUnavailable)
+	}
+}
+
 func TestHandleDeleteCredential_Success(t *testing.T) {
 	m := newTestModule(t)
 	bus := &testEventBus{}
@@ -919,6 +1038,52 @@ func TestHandleCredentialAudit_Success(t *testing.T) {
 	}
 }
 
+func TestHandleCredentialAccessLog_RecordsDecryptCaller(t *testing.T) {
+	m := newTestModule(t)
+	insertTestCredential(t, m, "cred-1", "Switch", CredTypeSNMPv2c, "", map[string]any{"community": "public"})
+
+	ctx := context.Background()
+	if _, err := m.DecryptCredentialData(WithCaller(ctx, "recon"), "cred-1"); err != nil {
+		t.Fatalf("DecryptCredentialData() error = %v", err)
+	}
+	if _, err := m.DecryptCredentialData(ctx, "cred-1"); err != nil {
+		t.Fatalf("DecryptCredentialData() error = %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/credentials/cred-1/access-log", http.NoBody)
+	req.SetPathValue("id", "cred-1")
+	rr := httptest.NewRecorder()
+	m.handleCredentialAccessLog(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rr.Code, http.StatusOK)
+	}
+	var entries []CredentialAccess
+	if err := json.NewDecoder(rr.Body).Decode(&entries); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("len = %d, want 2", len(entries))
+	}
+	callers := map[string]bool{entries[0].Caller: true, entries[1].Caller: true}
+	if !callers["recon"] || !callers[unknownCaller] {
+		t.Errorf("callers = %v, want recon and %s", callers, unknownCaller)
+	}
+}
+
+func TestHandleCredentialAccessLog_NotFound(t *testing.T) {
+	m := newTestModule(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/credentials/missing/access-log", http.NoBody)
+	req.SetPathValue("id", "missing")
+	rr := httptest.NewRecorder()
+	m.handleCredentialAccessLog(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rr.Code, http.StatusNotFound)
+	}
+}
+
 // --- Nil Store Edge Cases ---
 
 func TestHandleCreateCredential_NilStore(t *testing.T) {
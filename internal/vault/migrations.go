@@ -61,5 +61,35 @@ func migrations() []plugin.Migration {
 				return nil
 			},
 		},
+		{
+			Version:     2,
+			Description: "add rotated_at to vault_credentials",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`ALTER TABLE vault_credentials ADD COLUMN rotated_at DATETIME`)
+				return err
+			},
+		},
+		{
+			Version:     3,
+			Description: "create vault_credential_access table",
+			Up: func(tx *sql.Tx) error {
+				stmts := []string{
+					`CREATE TABLE IF NOT EXISTS vault_credential_access (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						credential_id TEXT NOT NULL,
+						caller TEXT NOT NULL,
+						accessed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_vault_access_credential ON vault_credential_access(credential_id, accessed_at)`,
+					`CREATE INDEX IF NOT EXISTS idx_vault_access_time ON vault_credential_access(accessed_at)`,
+				}
+				for _, stmt := range stmts {
+					if _, err := tx.Exec(stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
 	}
 }
@@ -74,12 +74,13 @@ func (s *VaultStore) InsertCredential(ctx context.Context, cred *CredentialRecor
 // Returns nil, nil if not found.
 func (s *VaultStore) GetCredential(ctx context.Context, id string) (*CredentialRecord, error) {
 	var c CredentialRecord
+	var rotatedAt sql.NullTime
 	err := s.db.QueryRowContext(ctx, `
-		SELECT id, name, type, device_id, description, encrypted_data, created_at, updated_at
+		SELECT id, name, type, device_id, description, encrypted_data, created_at, updated_at, rotated_at
 		FROM vault_credentials WHERE id = ?`,
 		id,
 	).Scan(&c.ID, &c.Name, &c.Type, &c.DeviceID, &c.Description,
-		&c.EncryptedData, &c.CreatedAt, &c.UpdatedAt,
+		&c.EncryptedData, &c.CreatedAt, &c.UpdatedAt, &rotatedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -87,13 +88,16 @@ func (s *VaultStore) GetCredential(ctx context.Context, id string) (*CredentialR
 		}
 		return nil, fmt.Errorf("get credential: %w", err)
 	}
+	if rotatedAt.Valid {
+		c.RotatedAt = &rotatedAt.Time
+	}
 	return &c, nil
 }
 
 // ListCredentials returns metadata for all credentials (no encrypted data).
 func (s *VaultStore) ListCredentials(ctx context.Context) ([]CredentialMeta, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, name, type, device_id, description, created_at, updated_at
+		SELECT id, name, type, device_id, description, created_at, updated_at, rotated_at
 		FROM vault_credentials ORDER BY created_at`,
 	)
 	if err != nil {
@@ -101,22 +105,13 @@ func (s *VaultStore) ListCredentials(ctx context.Context) ([]CredentialMeta, err
 	}
 	defer rows.Close()
 
-	var metas []CredentialMeta
-	for rows.Next() {
-		var m CredentialMeta
-		if err := rows.Scan(&m.ID, &m.Name, &m.Type, &m.DeviceID, &m.Description,
-			&m.CreatedAt, &m.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("scan credential row: %w", err)
-		}
-		metas = append(metas, m)
-	}
-	return metas, rows.Err()
+	return scanCredentialMetas(rows)
 }
 
 // ListCredentialsByDevice returns metadata for credentials associated with a device.
 func (s *VaultStore) ListCredentialsByDevice(ctx context.Context, deviceID string) ([]CredentialMeta, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, name, type, device_id, description, created_at, updated_at
+		SELECT id, name, type, device_id, description, created_at, updated_at, rotated_at
 		FROM vault_credentials WHERE device_id = ? ORDER BY created_at`,
 		deviceID,
 	)
@@ -125,22 +120,13 @@ func (s *VaultStore) ListCredentialsByDevice(ctx context.Context, deviceID strin
 	}
 	defer rows.Close()
 
-	var metas []CredentialMeta
-	for rows.Next() {
-		var m CredentialMeta
-		if err := rows.Scan(&m.ID, &m.Name, &m.Type, &m.DeviceID, &m.Description,
-			&m.CreatedAt, &m.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("scan credential row: %w", err)
-		}
-		metas = append(metas, m)
-	}
-	return metas, rows.Err()
+	return scanCredentialMetas(rows)
 }
 
 // ListCredentialsByType returns metadata for credentials of a given type.
 func (s *VaultStore) ListCredentialsByType(ctx context.Context, credType string) ([]CredentialMeta, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, name, type, device_id, description, created_at, updated_at
+		SELECT id, name, type, device_id, description, created_at, updated_at, rotated_at
 		FROM vault_credentials WHERE type = ? ORDER BY created_at`,
 		credType,
 	)
@@ -149,13 +135,23 @@ func (s *VaultStore) ListCredentialsByType(ctx context.Context, credType string)
 	}
 	defer rows.Close()
 
+	return scanCredentialMetas(rows)
+}
+
+// scanCredentialMetas reads credential metadata rows selected with
+// id, name, type, device_id, description, created_at, updated_at, rotated_at.
+func scanCredentialMetas(rows *sql.Rows) ([]CredentialMeta, error) {
 	var metas []CredentialMeta
 	for rows.Next() {
 		var m CredentialMeta
+		var rotatedAt sql.NullTime
 		if err := rows.Scan(&m.ID, &m.Name, &m.Type, &m.DeviceID, &m.Description,
-			&m.CreatedAt, &m.UpdatedAt); err != nil {
+			&m.CreatedAt, &m.UpdatedAt, &rotatedAt); err != nil {
 			return nil, fmt.Errorf("scan credential row: %w", err)
 		}
+		if rotatedAt.Valid {
+			m.RotatedAt = &rotatedAt.Time
+		}
 		metas = append(metas, m)
 	}
 	return metas, rows.Err()
@@ -181,6 +177,39 @@ func (s *VaultStore) UpdateCredential(ctx context.Context, cred *CredentialRecor
 	return nil
 }
 
+// RotateCredentialData replaces a credential's encrypted data and wrapped key
+// in one transaction and records the rotation time.
+func (s *VaultStore) RotateCredentialData(ctx context.Context, id string, encryptedData, wrappedKey []byte, rotatedAt time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin rotate tx: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // no-op after commit
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE vault_credentials SET
+			encrypted_data = ?, updated_at = ?, rotated_at = ?
+		WHERE id = ?`,
+		encryptedData, rotatedAt, rotatedAt, id,
+	)
+	if err != nil {
+		return fmt.Errorf("rotate credential data: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("credential %q not found", id)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE vault_keys SET wrapped_key = ?, updated_at = ?
+		WHERE credential_id = ?`,
+		wrappedKey, rotatedAt, id,
+	); err != nil {
+		return fmt.Errorf("rotate credential key: %w", err)
+	}
+	return tx.Commit()
+}
+
 // DeleteCredential deletes a credential by ID.
 func (s *VaultStore) DeleteCredential(ctx context.Context, id string) error {
 	result, err := s.db.ExecContext(ctx, `DELETE FROM vault_credentials WHERE id = ?`, id)
@@ -341,3 +370,50 @@ func (s *VaultStore) DeleteOldAuditEntries(ctx context.Context, before time.Time
 	}
 	return result.RowsAffected()
 }
+
+// InsertCredentialAccess records a module decrypting a credential.
+func (s *VaultStore) InsertCredentialAccess(ctx context.Context, access *CredentialAccess) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO vault_credential_access (credential_id, caller, accessed_at)
+		VALUES (?, ?, ?)`,
+		access.CredentialID, access.Caller, access.AccessedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert credential access: %w", err)
+	}
+	return nil
+}
+
+// ListCredentialAccess returns the most recent decryptions of a credential.
+func (s *VaultStore) ListCredentialAccess(ctx context.Context, credentialID string, limit int) ([]CredentialAccess, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, credential_id, caller, accessed_at
+		FROM vault_credential_access WHERE credential_id = ?
+		ORDER BY accessed_at DESC, id DESC LIMIT ?`,
+		credentialID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list credential access: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []CredentialAccess
+	for rows.Next() {
+		var a CredentialAccess
+		if err := rows.Scan(&a.ID, &a.CredentialID, &a.Caller, &a.AccessedAt); err != nil {
+			return nil, fmt.Errorf("scan credential access row: %w", err)
+		}
+		entries = append(entries, a)
+	}
+	return entries, rows.Err()
+}
+
+// DeleteOldCredentialAccess deletes credential access records older than the given time.
+func (s *VaultStore) DeleteOldCredentialAccess(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM vault_credential_access WHERE accessed_at < ?`, before)
+	if err != nil {
+		return 0, fmt.Errorf("delete old credential access: %w", err)
+	}
+	return result.RowsAffected()
+}
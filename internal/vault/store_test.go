@@ -540,3 +540,34 @@ func TestDeleteOldAuditEntries(t *testing.T) {
 		t.Errorf("remaining = %d, want 1", len(entries))
 	}
 }
+
+func TestCredentialAccess_ListAndPrune(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+
+	old := time.Now().Add(-48 * time.Hour).UTC()
+	recent := time.Now().UTC()
+
+	_ = s.InsertCredentialAccess(ctx, &CredentialAccess{CredentialID: "cred-1", Caller: "recon", AccessedAt: old})
+	_ = s.InsertCredentialAccess(ctx, &CredentialAccess{CredentialID: "cred-1", Caller: "gateway", AccessedAt: recent})
+	_ = s.InsertCredentialAccess(ctx, &CredentialAccess{CredentialID: "cred-2", Caller: "recon", AccessedAt: recent})
+
+	entries, err := s.ListCredentialAccess(ctx, "cred-1", 100)
+	if err != nil {
+		t.Fatalf("ListCredentialAccess() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("len = %d, want 2", len(entries))
+	}
+	if entries[0].Caller != "gateway" {
+		t.Errorf("entries[0].Caller = %q, want gateway (newest first)", entries[0].Caller)
+	}
+
+	deleted, err := s.DeleteOldCredentialAccess(ctx, time.Now().Add(-24*time.Hour).UTC())
+	if err != nil {
+		t.Fatalf("DeleteOldCredentialAccess() error = %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("deleted = %d, want 1", deleted)
+	}
+}
@@ -6,8 +6,8 @@ import "time"
 const (
 	CredTypeSSHPassword = "ssh_password"
 	CredTypeSSHKey      = "ssh_key"
-	CredTypeSNMPv2c     = "snmp_v2c"  //nolint:gosec // G101: credential type label, not a secret
-	CredTypeSNMPv3      = "snmp_v3"   //nolint:gosec // G101: credential type label, not a secret
+	CredTypeSNMPv2c     = "snmp_v2c" //nolint:gosec // G101: credential type label, not a secret
+	CredTypeSNMPv3      = "snmp_v3"  //nolint:gosec // G101: credential type label, not a secret
 	CredTypeAPIKey      = "api_key"
 	CredTypeHTTPBasic   = "http_basic"
 	CredTypeCustom      = "custom"
@@ -26,25 +26,27 @@ var ValidCredentialTypes = map[string]bool{
 
 // CredentialRecord is the full database representation of a stored credential.
 type CredentialRecord struct {
-	ID            string    `json:"id"`
-	Name          string    `json:"name"`
-	Type          string    `json:"type"`
-	DeviceID      string    `json:"device_id,omitempty"`
-	Description   string    `json:"description,omitempty"`
-	EncryptedData []byte    `json:"-"` // AES-256-GCM encrypted credential data
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	Type          string     `json:"type"`
+	DeviceID      string     `json:"device_id,omitempty"`
+	Description   string     `json:"description,omitempty"`
+	EncryptedData []byte     `json:"-"` // AES-256-GCM encrypted credential data
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	RotatedAt     *time.Time `json:"rotated_at,omitempty"`
 }
 
 // CredentialMeta is the public-facing metadata (never contains secrets).
 type CredentialMeta struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Type        string    `json:"type"`
-	DeviceID    string    `json:"device_id,omitempty"`
-	Description string    `json:"description,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Type        string     `json:"type"`
+	DeviceID    string     `json:"device_id,omitempty"`
+	Description string     `json:"description,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	RotatedAt   *time.Time `json:"rotated_at,omitempty"`
 }
 
 // CredentialData holds decrypted secret data alongside metadata.
@@ -73,8 +75,17 @@ type AuditEntry struct {
 	ID           int64     `json:"id"`
 	CredentialID string    `json:"credential_id"`
 	UserID       string    `json:"user_id"`
-	Action       string    `json:"action"` // "create", "read", "update", "delete", "rotate_keys"
+	Action       string    `json:"action"` // "create", "read", "update", "delete", "rotate", "rotate_keys"
 	Purpose      string    `json:"purpose,omitempty"`
 	SourceIP     string    `json:"source_ip,omitempty"`
 	Timestamp    time.Time `json:"timestamp"`
 }
+
+// CredentialAccess records a programmatic decryption of a credential by
+// another module (e.g. recon SNMP scans or gateway SSH sessions).
+type CredentialAccess struct {
+	ID           int64     `json:"id"`
+	CredentialID string    `json:"credential_id"`
+	Caller       string    `json:"caller"`
+	AccessedAt   time.Time `json:"accessed_at"`
+}
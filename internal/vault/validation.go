@@ -1,8 +1,11 @@
 package vault
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+
+	"golang.org/x/crypto/ssh"
 )
 
 const maxCredentialNameLen = 255
@@ -38,7 +41,10 @@ func ValidateCredentialData(credType string, data map[string]any) error {
 	case CredTypeSSHPassword:
 		return requireStringFields(data, "username", "password")
 	case CredTypeSSHKey:
-		return requireStringFields(data, "username", "private_key")
+		if err := requireStringFields(data, "username", "private_key"); err != nil {
+			return err
+		}
+		return validateSSHPrivateKey(data)
 	case CredTypeSNMPv2c:
 		return requireStringFields(data, "community")
 	case CredTypeSNMPv3:
@@ -60,6 +66,28 @@ func ValidateCredentialData(credType string, data map[string]any) error {
 	}
 }
 
+// validateSSHPrivateKey checks that private_key parses as an SSH private key,
+// using the optional passphrase field for encrypted keys.
+func validateSSHPrivateKey(data map[string]any) error {
+	key := []byte(data["private_key"].(string))
+	passphrase, _ := data["passphrase"].(string)
+
+	var err error
+	if passphrase != "" {
+		_, err = ssh.ParseRawPrivateKeyWithPassphrase(key, []byte(passphrase))
+	} else {
+		_, err = ssh.ParseRawPrivateKey(key)
+	}
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		return fmt.Errorf("private key is encrypted; %q is required", "passphrase")
+	}
+	if err != nil {
+		return fmt.Errorf("invalid private key: %w", err)
+	}
+	return nil
+}
+
 // requireStringFields checks that all named keys exist in data and are
 // non-empty strings.
 func requireStringFields(data map[string]any, fields ...string) error {
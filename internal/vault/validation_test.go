@@ -1,10 +1,35 @@
 package vault
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
 	"strings"
 	"testing"
+
+	"golang.org/x/crypto/ssh"
 )
 
+// testSSHKeyPEM generates an OpenSSH-format ed25519 private key, encrypted
+// when passphrase is non-empty.
+func testSSHKeyPEM(t *testing.T, passphrase string) string {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	var block *pem.Block
+	if passphrase != "" {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte(passphrase))
+	} else {
+		block, err = ssh.MarshalPrivateKey(priv, "")
+	}
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	return string(pem.EncodeToMemory(block))
+}
+
 func TestValidateCredentialType(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -83,14 +108,21 @@ func TestValidateCredentialData_SSHPassword(t *testing.T) {
 }
 
 func TestValidateCredentialData_SSHKey(t *testing.T) {
+	plainKey := testSSHKeyPEM(t, "")
+	encryptedKey := testSSHKeyPEM(t, "hunter2")
+
 	tests := []struct {
 		name    string
 		data    map[string]any
 		wantErr bool
 	}{
-		{"valid", map[string]any{"username": "admin", "private_key": "-----BEGIN..."}, false},
+		{"valid", map[string]any{"username": "admin", "private_key": plainKey}, false},
+		{"valid_encrypted", map[string]any{"username": "admin", "private_key": encryptedKey, "passphrase": "hunter2"}, false},
+		{"encrypted_missing_passphrase", map[string]any{"username": "admin", "private_key": encryptedKey}, true},
+		{"wrong_passphrase", map[string]any{"username": "admin", "private_key": encryptedKey, "passphrase": "nope"}, true},
+		{"unparseable_key", map[string]any{"username": "admin", "private_key": "-----BEGIN..."}, true},
 		{"missing_key", map[string]any{"username": "admin"}, true},
-		{"missing_username", map[string]any{"private_key": "-----BEGIN..."}, true},
+		{"missing_username", map[string]any{"private_key": plainKey}, true},
 	}
 
 	for _, tt := range tests {
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -201,7 +202,29 @@ func (m *Module) CredentialsForDevice(ctx context.Context, deviceID string) ([]r
 	return result, nil
 }
 
+// unknownCaller is recorded in the access log when the decrypting module
+// did not identify itself via WithCaller.
+const unknownCaller = "unknown"
+
+type callerKey struct{}
+
+// WithCaller returns a context that identifies the module decrypting
+// credentials. DecryptCredentialData records it in the access log.
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// callerFromContext returns the caller set by WithCaller, or unknownCaller.
+func callerFromContext(ctx context.Context) string {
+	if caller, ok := ctx.Value(callerKey{}).(string); ok && caller != "" {
+		return caller
+	}
+	return unknownCaller
+}
+
 // DecryptCredentialData decrypts and returns the credential data for the given ID.
+// Each successful decryption is recorded in the credential access log along
+// with the caller identified by WithCaller.
 // Returns an error if the vault is sealed or the credential doesn't exist.
 func (m *Module) DecryptCredentialData(ctx context.Context, id string) (map[string]any, error) {
 	if m.store == nil {
@@ -240,9 +263,89 @@ func (m *Module) DecryptCredentialData(ctx context.Context, id string) (map[stri
 		return nil, fmt.Errorf("unmarshal credential data: %w", err)
 	}
 
+	m.recordAccess(ctx, id)
 	return data, nil
 }
 
+// recordAccess logs a credential decryption. Non-blocking -- errors are logged.
+func (m *Module) recordAccess(ctx context.Context, credentialID string) {
+	access := &CredentialAccess{
+		CredentialID: credentialID,
+		Caller:       callerFromContext(ctx),
+		AccessedAt:   time.Now().UTC(),
+	}
+	if err := m.store.InsertCredentialAccess(context.WithoutCancel(ctx), access); err != nil {
+		m.logger.Warn("failed to record credential access",
+			zap.String("credential_id", credentialID),
+			zap.String("caller", access.Caller),
+			zap.Error(err),
+		)
+	}
+}
+
+// ErrCredentialNotFound is returned when a credential ID does not exist.
+var ErrCredentialNotFound = errors.New("credential not found")
+
+// errInvalidCredentialData wraps validation failures from RotateCredential.
+var errInvalidCredentialData = errors.New("invalid credential data")
+
+// RotateCredential replaces the secret data of an existing credential while
+// preserving its ID and metadata, so anything referencing the credential by
+// ID keeps working. The data is re-encrypted under a fresh DEK and the
+// rotation time is recorded.
+func (m *Module) RotateCredential(ctx context.Context, id string, newData map[string]any) (*CredentialMeta, error) {
+	if m.store == nil {
+		return nil, fmt.Errorf("vault store not available")
+	}
+	if m.km.IsSealed() {
+		return nil, ErrVaultSealed
+	}
+
+	rec, err := m.store.GetCredential(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get credential: %w", err)
+	}
+	if rec == nil {
+		return nil, ErrCredentialNotFound
+	}
+	if err := ValidateCredentialData(rec.Type, newData); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidCredentialData, err)
+	}
+
+	dataJSON, err := json.Marshal(newData)
+	if err != nil {
+		return nil, fmt.Errorf("marshal credential data: %w", err)
+	}
+
+	dek, err := GenerateDEK()
+	if err != nil {
+		return nil, fmt.Errorf("generate DEK: %w", err)
+	}
+	defer ZeroBytes(dek)
+
+	encrypted, err := Encrypt(dek, dataJSON)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt credential data: %w", err)
+	}
+	wrappedKey, err := m.km.WrapDEK(dek)
+	if err != nil {
+		return nil, fmt.Errorf("wrap DEK: %w", err)
+	}
+
+	now := time.Now().UTC()
+	if err := m.store.RotateCredentialData(ctx, id, encrypted, wrappedKey, now); err != nil {
+		return nil, err
+	}
+
+	m.publishEvent(TopicCredentialRotated, map[string]string{"credential_id": id})
+
+	return &CredentialMeta{
+		ID: rec.ID, Name: rec.Name, Type: rec.Type,
+		DeviceID: rec.DeviceID, Description: rec.Description,
+		CreatedAt: rec.CreatedAt, UpdatedAt: now, RotatedAt: &now,
+	}, nil
+}
+
 // tryUnseal attempts to unseal the vault using env var or interactive prompt.
 func (m *Module) tryUnseal() {
 	passphrase := os.Getenv(PassphraseEnvVar)
@@ -322,6 +425,21 @@ func (m *Module) runMaintenance() {
 			zap.Int64("deleted", deleted),
 		)
 	}
+
+	if m.cfg.AccessLogRetentionPeriod <= 0 {
+		return
+	}
+	accessCutoff := time.Now().Add(-m.cfg.AccessLogRetentionPeriod).UTC()
+	pruned, err := m.store.DeleteOldCredentialAccess(m.ctx, accessCutoff)
+	if err != nil {
+		m.logger.Warn("credential access log maintenance failed", zap.Error(err))
+		return
+	}
+	if pruned > 0 {
+		m.logger.Info("credential access log maintenance complete",
+			zap.Int64("deleted", pruned),
+		)
+	}
 }
 
 // readPassphraseFromStdin prompts for and reads a passphrase from stdin.
@@ -132,7 +132,9 @@ func TestRoutes(t *testing.T) {
 		"GET /credentials/{id}":               "",
 		"PUT /credentials/{id}":               "",
 		"DELETE /credentials/{id}":            "",
+		"POST /credentials/{id}/rotate":       "",
 		"GET /credentials/{id}/data":          "",
+		"GET /credentials/{id}/access-log":    "",
 		"GET /device-credentials/{device_id}": "",
 		"POST /rotate-keys":                   "",
 		"POST /seal":                          "",
@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+)
+
+// writeJSON writes a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+// writeError writes an RFC 7807 problem detail response.
+func writeError(w http.ResponseWriter, status int, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"type":   "https://subnetree.com/problems/" + http.StatusText(status),
+		"title":  http.StatusText(status),
+		"status": status,
+		"detail": detail,
+	})
+}
+
+// handleListDeliveries lists queued and completed webhook deliveries.
+//
+//	@Summary		List webhook deliveries
+//	@Description	Returns webhook deliveries newest first with their status and attempt history.
+//	@Tags			webhook
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			status	query		string	false	"Filter by status (pending, delivered, failed)"
+//	@Param			limit	query		int		false	"Maximum results (default 100, max 1000)"
+//	@Success		200		{array}		Delivery
+//	@Failure		400		{object}	map[string]any
+//	@Failure		500		{object}	map[string]any
+//	@Failure		503		{object}	map[string]any
+//	@Router			/webhook/deliveries [get]
+func (m *Module) handleListDeliveries(w http.ResponseWriter, r *http.Request) {
+	if m.store == nil {
+		writeError(w, http.StatusServiceUnavailable, "webhook delivery queue not available")
+		return
+	}
+
+	filter := DeliveryFilter{Status: r.URL.Query().Get("status"), Limit: 100}
+	switch filter.Status {
+	case "", StatusPending, StatusDelivered, StatusFailed:
+	default:
+		writeError(w, http.StatusBadRequest, "status must be pending, delivered, or failed")
+		return
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 1000 {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and 1000")
+			return
+		}
+		filter.Limit = n
+	}
+
+	deliveries, err := m.store.ListDeliveries(r.Context(), filter)
+	if err != nil {
+		m.logger.Error("list webhook deliveries failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to list deliveries")
+		return
+	}
+	writeJSON(w, http.StatusOK, deliveries)
+}
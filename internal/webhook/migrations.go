@@ -0,0 +1,52 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/HerbHall/subnetree/pkg/plugin"
+)
+
+func migrations() []plugin.Migration {
+	return []plugin.Migration{
+		{
+			Version:     1,
+			Description: "create webhook delivery queue tables",
+			Up: func(tx *sql.Tx) error {
+				stmts := []string{
+					`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+						id TEXT PRIMARY KEY,
+						source TEXT NOT NULL DEFAULT '',
+						topic TEXT NOT NULL DEFAULT '',
+						url TEXT NOT NULL,
+						body BLOB NOT NULL,
+						headers_json TEXT NOT NULL DEFAULT '{}',
+						status TEXT NOT NULL DEFAULT 'pending',
+						attempts INTEGER NOT NULL DEFAULT 0,
+						max_attempts INTEGER NOT NULL,
+						next_attempt_at DATETIME NOT NULL,
+						last_error TEXT NOT NULL DEFAULT '',
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at)`,
+					`CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+						delivery_id TEXT NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+						attempt INTEGER NOT NULL,
+						attempted_at DATETIME NOT NULL,
+						status_code INTEGER NOT NULL DEFAULT 0,
+						error TEXT NOT NULL DEFAULT '',
+						duration_ms INTEGER NOT NULL DEFAULT 0,
+						PRIMARY KEY (delivery_id, attempt)
+					)`,
+				}
+				for _, stmt := range stmts {
+					if _, err := tx.ExecContext(context.Background(), stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+	}
+}
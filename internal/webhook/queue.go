@@ -0,0 +1,210 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// dueBatchSize bounds how many deliveries are attempted per queue pass.
+const dueBatchSize = 50
+
+// DeliveryRequest describes an outbound webhook to queue.
+type DeliveryRequest struct {
+	Source  string // Module that produced the delivery, e.g. "webhook" or "pulse".
+	Topic   string // Event topic or type, for display only.
+	URL     string
+	Body    []byte
+	Headers map[string]string // Sent as-is; include any signature here.
+}
+
+// Queue persists outbound webhook deliveries and retries failures with
+// exponential backoff. Deliveries still pending at shutdown are resumed on
+// the next start. After MaxAttempts failures a delivery is dead-lettered
+// with status "failed".
+type Queue struct {
+	store       *Store
+	client      *http.Client
+	logger      *zap.Logger
+	maxAttempts int
+	retryBase   time.Duration
+	retryMax    time.Duration
+	poll        time.Duration
+	wake        chan struct{}
+	now         func() time.Time
+}
+
+func newQueue(store *Store, client *http.Client, logger *zap.Logger, cfg Config) *Queue {
+	return &Queue{
+		store:       store,
+		client:      client,
+		logger:      logger,
+		maxAttempts: max(cfg.MaxAttempts, 1),
+		retryBase:   cfg.RetryBase,
+		retryMax:    cfg.RetryMax,
+		poll:        cfg.PollInterval,
+		wake:        make(chan struct{}, 1),
+		now:         time.Now,
+	}
+}
+
+// Enqueue stores a pending delivery and schedules an immediate first attempt.
+func (q *Queue) Enqueue(ctx context.Context, req DeliveryRequest) (*Delivery, error) {
+	if req.URL == "" {
+		return nil, errors.New("webhook URL is required")
+	}
+	now := q.now().UTC()
+	d := &Delivery{
+		ID:            uuid.New().String(),
+		Source:        req.Source,
+		Topic:         req.Topic,
+		URL:           req.URL,
+		Body:          req.Body,
+		Headers:       req.Headers,
+		Status:        StatusPending,
+		MaxAttempts:   q.maxAttempts,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if d.Headers == nil {
+		d.Headers = map[string]string{}
+	}
+	if err := q.store.InsertDelivery(ctx, d); err != nil {
+		return nil, err
+	}
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return d, nil
+}
+
+// run attempts due deliveries until ctx is cancelled.
+func (q *Queue) run(ctx context.Context) {
+	ticker := time.NewTicker(q.poll)
+	defer ticker.Stop()
+
+	q.processDue(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-q.wake:
+		}
+		q.processDue(ctx)
+	}
+}
+
+// processDue attempts every delivery whose next attempt time has passed.
+func (q *Queue) processDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		due, err := q.store.DueDeliveries(ctx, q.now(), dueBatchSize)
+		if err != nil {
+			q.logger.Warn("failed to load due webhook deliveries", zap.Error(err))
+			return
+		}
+		for i := range due {
+			q.attempt(ctx, &due[i])
+		}
+		if len(due) < dueBatchSize {
+			return
+		}
+	}
+}
+
+// attempt makes one delivery attempt and records the outcome.
+func (q *Queue) attempt(ctx context.Context, d *Delivery) {
+	start := q.now()
+	statusCode, err := q.send(ctx, d)
+	if ctx.Err() != nil {
+		// Shutting down: leave the delivery pending for the next start.
+		return
+	}
+
+	d.Attempts++
+	a := Attempt{
+		Attempt:     d.Attempts,
+		AttemptedAt: start.UTC(),
+		StatusCode:  statusCode,
+		DurationMs:  q.now().Sub(start).Milliseconds(),
+	}
+	d.UpdatedAt = q.now().UTC()
+
+	switch {
+	case err == nil:
+		d.Status = StatusDelivered
+		d.LastError = ""
+	case d.Attempts >= d.MaxAttempts:
+		a.Error = err.Error()
+		d.Status = StatusFailed
+		d.LastError = a.Error
+		q.logger.Warn("webhook delivery dead-lettered",
+			zap.String("delivery_id", d.ID),
+			zap.String("url", d.URL),
+			zap.String("topic", d.Topic),
+			zap.Int("attempts", d.Attempts),
+			zap.Error(err),
+		)
+	default:
+		a.Error = err.Error()
+		d.LastError = a.Error
+		d.NextAttemptAt = d.UpdatedAt.Add(q.backoff(d.Attempts))
+		q.logger.Debug("webhook delivery failed; will retry",
+			zap.String("delivery_id", d.ID),
+			zap.Int("attempts", d.Attempts),
+			zap.Time("next_attempt_at", d.NextAttemptAt),
+			zap.Error(err),
+		)
+	}
+
+	if err := q.store.RecordAttempt(ctx, d, a); err != nil {
+		q.logger.Error("failed to record webhook attempt",
+			zap.String("delivery_id", d.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+// backoff returns the delay before the retry following the given number of
+// failed attempts: retryBase doubled per attempt, capped at retryMax.
+func (q *Queue) backoff(attempts int) time.Duration {
+	d := q.retryBase
+	for i := 1; i < attempts && d < q.retryMax; i++ {
+		d *= 2
+	}
+	return min(d, q.retryMax)
+}
+
+// send POSTs the delivery body. Any non-2xx response is an error.
+func (q *Queue) send(ctx context.Context, d *Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Body))
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "SubNetree-Webhook/0.1")
+	for k, v := range d.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := q.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body) //nolint:errcheck // drain body for connection reuse
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
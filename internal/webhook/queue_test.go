@@ -0,0 +1,166 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/HerbHall/subnetree/internal/testutil"
+	"github.com/HerbHall/subnetree/pkg/plugin"
+	"go.uber.org/zap"
+)
+
+func testQueue(t *testing.T, cfg Config) (*Queue, *time.Time) {
+	t.Helper()
+	db := testutil.NewStore(t)
+	if err := db.Migrate(context.Background(), "webhook", migrations()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	q := newQueue(NewStore(db.DB()), &http.Client{Timeout: 5 * time.Second}, zap.NewNop(), cfg)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	q.now = func() time.Time { return now }
+	return q, &now
+}
+
+func TestQueue_RetriesUntilDelivered(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(SignatureHeader) != "sha256=abc" {
+			t.Errorf("queued header not sent: %q", r.Header.Get(SignatureHeader))
+		}
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	q, now := testQueue(t, Config{MaxAttempts: 5, RetryBase: time.Minute, RetryMax: time.Hour})
+	ctx := context.Background()
+	d, err := q.Enqueue(ctx, DeliveryRequest{
+		Source:  "pulse",
+		Topic:   "alert.triggered",
+		URL:     srv.URL,
+		Body:    []byte(`{"x":1}`),
+		Headers: map[string]string{SignatureHeader: "sha256=abc"},
+	})
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	q.processDue(ctx) // attempt 1 fails; retry in 1m
+	q.processDue(ctx) // not yet due
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("calls after first pass = %d, want 1", got)
+	}
+	*now = now.Add(time.Minute)
+	q.processDue(ctx) // attempt 2 fails; retry in 2m
+	*now = now.Add(time.Minute)
+	q.processDue(ctx)
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("calls before backoff elapsed = %d, want 2", got)
+	}
+	*now = now.Add(time.Minute)
+	q.processDue(ctx) // attempt 3 succeeds
+
+	got, err := q.store.GetDelivery(ctx, d.ID)
+	if err != nil || got == nil {
+		t.Fatalf("GetDelivery: %v, %v", got, err)
+	}
+	if got.Status != StatusDelivered || got.Attempts != 3 {
+		t.Errorf("status = %s, attempts = %d; want delivered after 3", got.Status, got.Attempts)
+	}
+	if len(got.History) != 3 || got.History[0].StatusCode != 503 || got.History[2].StatusCode != 200 {
+		t.Errorf("history = %+v", got.History)
+	}
+}
+
+func TestQueue_DeadLettersAfterMaxAttempts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	q, now := testQueue(t, Config{MaxAttempts: 2, RetryBase: time.Second, RetryMax: time.Second})
+	ctx := context.Background()
+	d, err := q.Enqueue(ctx, DeliveryRequest{URL: srv.URL, Body: []byte(`{}`)})
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	for range 4 {
+		q.processDue(ctx)
+		*now = now.Add(time.Second)
+	}
+
+	got, err := q.store.GetDelivery(ctx, d.ID)
+	if err != nil {
+		t.Fatalf("GetDelivery: %v", err)
+	}
+	if got.Status != StatusFailed || got.Attempts != 2 {
+		t.Errorf("status = %s, attempts = %d; want failed after 2", got.Status, got.Attempts)
+	}
+	if got.LastError == "" {
+		t.Error("expected last_error on dead-lettered delivery")
+	}
+}
+
+func TestQueue_Backoff(t *testing.T) {
+	q := &Queue{retryBase: 30 * time.Second, retryMax: 5 * time.Minute}
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{4, 4 * time.Minute},
+		{5, 5 * time.Minute},
+		{20, 5 * time.Minute},
+	}
+	for _, tc := range tests {
+		if got := q.backoff(tc.attempts); got != tc.want {
+			t.Errorf("backoff(%d) = %v, want %v", tc.attempts, got, tc.want)
+		}
+	}
+}
+
+func TestHandleListDeliveries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	m := New()
+	if err := m.Init(context.Background(), plugin.Dependencies{
+		Logger: zap.NewNop(),
+		Store:  testutil.NewStore(t),
+		Config: &testConfig{values: map[string]any{"url": srv.URL}},
+	}); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	m.handleEvent(context.Background(), plugin.Event{Topic: "recon.device.lost", Timestamp: time.Now()})
+
+	list := func(query string) (int, []Delivery) {
+		w := httptest.NewRecorder()
+		m.handleListDeliveries(w, httptest.NewRequest(http.MethodGet, "/deliveries"+query, http.NoBody))
+		var out []Delivery
+		_ = json.NewDecoder(w.Body).Decode(&out)
+		return w.Code, out
+	}
+
+	code, out := list("?status=pending")
+	if code != http.StatusOK || len(out) != 1 || out[0].Attempts != 0 {
+		t.Fatalf("pending = %d %+v, want one unattempted delivery", code, out)
+	}
+	m.queue.processDue(context.Background())
+	if _, out = list("?status=delivered"); len(out) != 1 || len(out[0].History) != 1 {
+		t.Errorf("delivered = %+v, want one delivery with one attempt", out)
+	}
+	if code, _ = list("?status=bogus"); code != http.StatusBadRequest {
+		t.Errorf("bogus status = %d, want 400", code)
+	}
+}
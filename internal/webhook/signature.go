@@ -0,0 +1,36 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// SignatureHeader carries the HMAC signature of an outbound webhook body.
+//
+// When a signing secret is configured, every delivery includes
+//
+//	X-SubNetree-Signature: sha256=<hex>
+//
+// where <hex> is the lowercase hex HMAC-SHA256 of the raw request body keyed
+// with the secret. Receivers should recompute the HMAC over the body bytes
+// exactly as received (before any JSON parsing) and compare in constant time.
+const SignatureHeader = "X-SubNetree-Signature"
+
+const signaturePrefix = "sha256="
+
+// Sign returns the SignatureHeader value for body under secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is a valid SignatureHeader value for body.
+func Verify(secret string, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
@@ -0,0 +1,219 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Delivery status values.
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed" // dead-lettered after the final attempt
+)
+
+// Delivery is a queued outbound webhook request and its delivery state.
+// Body and headers are kept out of API responses: headers may carry
+// credentials configured on a notification channel.
+type Delivery struct {
+	ID            string            `json:"id"`
+	Source        string            `json:"source"`
+	Topic         string            `json:"topic"`
+	URL           string            `json:"url"`
+	Body          []byte            `json:"-"`
+	Headers       map[string]string `json:"-"`
+	Status        string            `json:"status"`
+	Attempts      int               `json:"attempts"`
+	MaxAttempts   int               `json:"max_attempts"`
+	NextAttemptAt time.Time         `json:"next_attempt_at"`
+	LastError     string            `json:"last_error,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	History       []Attempt         `json:"history"`
+}
+
+// Attempt records one HTTP delivery attempt.
+type Attempt struct {
+	Attempt     int       `json:"attempt"`
+	AttemptedAt time.Time `json:"attempted_at"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DurationMs  int64     `json:"duration_ms"`
+}
+
+// DeliveryFilter narrows ListDeliveries results.
+type DeliveryFilter struct {
+	Status string
+	Limit  int
+}
+
+// Store persists the webhook delivery queue.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore creates a Store backed by db. Migrations must already be applied.
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+const deliveryColumns = `id, source, topic, url, body, headers_json, status,
+	attempts, max_attempts, next_attempt_at, last_error, created_at, updated_at`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanDelivery(row rowScanner) (*Delivery, error) {
+	var d Delivery
+	var headersJSON string
+	if err := row.Scan(&d.ID, &d.Source, &d.Topic, &d.URL, &d.Body, &headersJSON, &d.Status,
+		&d.Attempts, &d.MaxAttempts, &d.NextAttemptAt, &d.LastError, &d.CreatedAt, &d.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(headersJSON), &d.Headers); err != nil {
+		return nil, fmt.Errorf("unmarshal headers: %w", err)
+	}
+	return &d, nil
+}
+
+// InsertDelivery stores a new pending delivery.
+func (s *Store) InsertDelivery(ctx context.Context, d *Delivery) error {
+	headersJSON, err := json.Marshal(d.Headers)
+	if err != nil {
+		return fmt.Errorf("marshal headers: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (`+deliveryColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		d.ID, d.Source, d.Topic, d.URL, d.Body, string(headersJSON), d.Status,
+		d.Attempts, d.MaxAttempts, d.NextAttemptAt, d.LastError, d.CreatedAt, d.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert delivery: %w", err)
+	}
+	return nil
+}
+
+// GetDelivery returns a delivery with its attempt history, or nil if not found.
+func (s *Store) GetDelivery(ctx context.Context, id string) (*Delivery, error) {
+	d, err := scanDelivery(s.db.QueryRowContext(ctx,
+		`SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get delivery: %w", err)
+	}
+	if d.History, err = s.listAttempts(ctx, d.ID); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// ListDeliveries returns deliveries newest first, each with its attempt history.
+func (s *Store) ListDeliveries(ctx context.Context, filter DeliveryFilter) ([]Delivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries`
+	var args []any
+	if filter.Status != "" {
+		query += ` WHERE status = ?`
+		args = append(args, filter.Status)
+	}
+	query += ` ORDER BY created_at DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	deliveries, err := s.queryDeliveries(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list deliveries: %w", err)
+	}
+	for i := range deliveries {
+		if deliveries[i].History, err = s.listAttempts(ctx, deliveries[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return deliveries, nil
+}
+
+// DueDeliveries returns pending deliveries whose next attempt is at or before now.
+func (s *Store) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]Delivery, error) {
+	deliveries, err := s.queryDeliveries(ctx, `
+		SELECT `+deliveryColumns+` FROM webhook_deliveries
+		WHERE status = ? AND next_attempt_at <= ?
+		ORDER BY next_attempt_at
+		LIMIT ?`, StatusPending, now.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("due deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (s *Store) queryDeliveries(ctx context.Context, query string, args ...any) ([]Delivery, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []Delivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *d)
+	}
+	return deliveries, rows.Err()
+}
+
+// RecordAttempt appends an attempt to a delivery's history and updates its
+// status, attempt count, and next attempt time in one transaction.
+func (s *Store) RecordAttempt(ctx context.Context, d *Delivery, a Attempt) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO webhook_delivery_attempts (delivery_id, attempt, attempted_at, status_code, error, duration_ms)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		d.ID, a.Attempt, a.AttemptedAt, a.StatusCode, a.Error, a.DurationMs); err != nil {
+		return fmt.Errorf("insert attempt: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, next_attempt_at = ?, last_error = ?, updated_at = ?
+		WHERE id = ?`,
+		d.Status, d.Attempts, d.NextAttemptAt, d.LastError, d.UpdatedAt, d.ID); err != nil {
+		return fmt.Errorf("update delivery: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (s *Store) listAttempts(ctx context.Context, deliveryID string) ([]Attempt, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT attempt, attempted_at, status_code, error, duration_ms
+		FROM webhook_delivery_attempts WHERE delivery_id = ? ORDER BY attempt`, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("list attempts: %w", err)
+	}
+	defer rows.Close()
+
+	attempts := []Attempt{}
+	for rows.Next() {
+		var a Attempt
+		if err := rows.Scan(&a.Attempt, &a.AttemptedAt, &a.StatusCode, &a.Error, &a.DurationMs); err != nil {
+			return nil, fmt.Errorf("scan attempt: %w", err)
+		}
+		attempts = append(attempts, a)
+	}
+	return attempts, rows.Err()
+}
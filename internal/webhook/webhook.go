@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
+	"github.com/HerbHall/subnetree/internal/auth"
 	"github.com/HerbHall/subnetree/internal/recon"
 	"github.com/HerbHall/subnetree/pkg/plugin"
 	"go.uber.org/zap"
@@ -16,6 +19,7 @@ import (
 var (
 	_ plugin.Plugin          = (*Module)(nil)
 	_ plugin.EventSubscriber = (*Module)(nil)
+	_ plugin.HTTPProvider    = (*Module)(nil)
 )
 
 // Config holds the webhook plugin configuration.
@@ -23,6 +27,14 @@ type Config struct {
 	URL     string
 	Timeout time.Duration
 	Enabled bool
+	// Secret, when set, signs each delivery; see SignatureHeader.
+	Secret string //nolint:gosec // G101: config field name, not a credential
+
+	// Retry queue settings.
+	MaxAttempts  int           // Attempts before a delivery is dead-lettered
+	RetryBase    time.Duration // Delay after the first failure; doubles per attempt
+	RetryMax     time.Duration // Upper bound on the retry delay
+	PollInterval time.Duration // How often the queue checks for due retries
 }
 
 // Module implements the Webhook notifier plugin.
@@ -30,6 +42,10 @@ type Module struct {
 	logger *zap.Logger
 	cfg    Config
 	client *http.Client
+	store  *Store
+	queue  *Queue
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
 // New creates a new Webhook plugin instance.
@@ -41,19 +57,23 @@ func (m *Module) Info() plugin.PluginInfo {
 	return plugin.PluginInfo{
 		Name:        "webhook",
 		Version:     "0.1.0",
-		Description: "Sends HTTP POST notifications to a configurable webhook URL on device events",
+		Description: "Sends HTTP POST notifications to a configurable webhook URL on device and authentication events",
 		Roles:       []string{"notification"},
 		APIVersion:  plugin.APIVersionCurrent,
 	}
 }
 
-func (m *Module) Init(_ context.Context, deps plugin.Dependencies) error {
+func (m *Module) Init(ctx context.Context, deps plugin.Dependencies) error {
 	m.logger = deps.Logger
 
 	// Defaults.
 	m.cfg = Config{
-		Timeout: 10 * time.Second,
-		Enabled: true,
+		Timeout:      10 * time.Second,
+		Enabled:      true,
+		MaxAttempts:  6,
+		RetryBase:    30 * time.Second,
+		RetryMax:     time.Hour,
+		PollInterval: 15 * time.Second,
 	}
 
 	if deps.Config != nil {
@@ -63,13 +83,36 @@ func (m *Module) Init(_ context.Context, deps plugin.Dependencies) error {
 		if d := deps.Config.GetDuration("timeout"); d > 0 {
 			m.cfg.Timeout = d
 		}
+		if secret := deps.Config.GetString("secret"); secret != "" {
+			m.cfg.Secret = secret
+		}
 		if deps.Config.IsSet("enabled") {
 			m.cfg.Enabled = deps.Config.GetBool("enabled")
 		}
+		if n := deps.Config.GetInt("max_attempts"); n > 0 {
+			m.cfg.MaxAttempts = n
+		}
+		if d := deps.Config.GetDuration("retry_base"); d > 0 {
+			m.cfg.RetryBase = d
+		}
+		if d := deps.Config.GetDuration("retry_max"); d > 0 {
+			m.cfg.RetryMax = d
+		}
+		if d := deps.Config.GetDuration("poll_interval"); d > 0 {
+			m.cfg.PollInterval = d
+		}
 	}
 
 	m.client = &http.Client{Timeout: m.cfg.Timeout}
 
+	if deps.Store != nil {
+		if err := deps.Store.Migrate(ctx, "webhook", migrations()); err != nil {
+			return fmt.Errorf("webhook migrations: %w", err)
+		}
+		m.store = NewStore(deps.Store.DB())
+		m.queue = newQueue(m.store, m.client, m.logger, m.cfg)
+	}
+
 	if m.cfg.URL == "" {
 		m.logger.Warn("webhook URL not configured; notifications will be dropped",
 			zap.String("component", "webhook"),
@@ -80,26 +123,57 @@ func (m *Module) Init(_ context.Context, deps plugin.Dependencies) error {
 		zap.String("url", m.cfg.URL),
 		zap.Duration("timeout", m.cfg.Timeout),
 		zap.Bool("enabled", m.cfg.Enabled),
+		zap.Bool("signed", m.cfg.Secret != ""),
+		zap.Int("max_attempts", m.cfg.MaxAttempts),
 	)
 	return nil
 }
 
 func (m *Module) Start(_ context.Context) error {
-	m.logger.Info("webhook module started")
+	if m.queue != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		m.cancel = cancel
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			m.queue.run(ctx)
+		}()
+	}
+	m.logger.Info("webhook module started", zap.Bool("retry_queue", m.queue != nil))
 	return nil
 }
 
 func (m *Module) Stop(_ context.Context) error {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.wg.Wait()
 	m.logger.Info("webhook module stopped")
 	return nil
 }
 
+// Queue returns the persistent delivery queue, or nil if the module has no
+// store. Other modules route their webhooks through it for retries.
+func (m *Module) Queue() *Queue {
+	return m.queue
+}
+
+// Routes implements plugin.HTTPProvider.
+func (m *Module) Routes() []plugin.Route {
+	return []plugin.Route{
+		{Method: "GET", Path: "/deliveries", Handler: m.handleListDeliveries},
+	}
+}
+
 // Subscriptions implements plugin.EventSubscriber.
 func (m *Module) Subscriptions() []plugin.Subscription {
 	return []plugin.Subscription{
 		{Topic: recon.TopicDeviceDiscovered, Handler: m.handleEvent},
 		{Topic: recon.TopicDeviceUpdated, Handler: m.handleEvent},
 		{Topic: recon.TopicDeviceLost, Handler: m.handleEvent},
+		{Topic: recon.TopicSNMPTrapReceived, Handler: m.handleEvent},
+		{Topic: auth.TopicLoginFailed, Handler: m.handleEvent},
+		{Topic: auth.TopicAccountLocked, Handler: m.handleEvent},
 	}
 }
 
@@ -132,6 +206,26 @@ func (m *Module) handleEvent(ctx context.Context, event plugin.Event) {
 		return
 	}
 
+	if m.queue != nil {
+		headers := map[string]string{}
+		if m.cfg.Secret != "" {
+			headers[SignatureHeader] = Sign(m.cfg.Secret, body)
+		}
+		if _, err := m.queue.Enqueue(ctx, DeliveryRequest{
+			Source:  "webhook",
+			Topic:   event.Topic,
+			URL:     m.cfg.URL,
+			Body:    body,
+			Headers: headers,
+		}); err != nil {
+			m.logger.Error("failed to queue webhook delivery",
+				zap.String("topic", event.Topic),
+				zap.Error(err),
+			)
+		}
+		return
+	}
+
 	m.send(ctx, body, event.Topic)
 }
 
@@ -143,6 +237,9 @@ func (m *Module) send(ctx context.Context, body []byte, topic string) {
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "SubNetree-Webhook/0.1")
+	if m.cfg.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(m.cfg.Secret, body))
+	}
 
 	resp, err := m.client.Do(req)
 	if err != nil {
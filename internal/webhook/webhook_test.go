@@ -3,12 +3,15 @@ package webhook
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/HerbHall/subnetree/internal/auth"
 	"github.com/HerbHall/subnetree/internal/recon"
 	"github.com/HerbHall/subnetree/pkg/plugin"
 	"github.com/HerbHall/subnetree/pkg/plugin/plugintest"
@@ -26,8 +29,8 @@ func TestSubscriptions_ReturnsExpectedTopics(t *testing.T) {
 	}
 
 	subs := m.Subscriptions()
-	if len(subs) != 3 {
-		t.Fatalf("Subscriptions() returned %d, want 3", len(subs))
+	if len(subs) != 6 {
+		t.Fatalf("Subscriptions() returned %d, want 6", len(subs))
 	}
 
 	topics := make(map[string]bool)
@@ -39,6 +42,9 @@ func TestSubscriptions_ReturnsExpectedTopics(t *testing.T) {
 		recon.TopicDeviceDiscovered,
 		recon.TopicDeviceUpdated,
 		recon.TopicDeviceLost,
+		recon.TopicSNMPTrapReceived,
+		auth.TopicLoginFailed,
+		auth.TopicAccountLocked,
 	}
 	for _, topic := range expected {
 		if !topics[topic] {
@@ -164,6 +170,49 @@ func TestHandleEvent_LogsOnServerError(t *testing.T) {
 	})
 }
 
+func TestHandleEvent_SignsWhenSecretSet(t *testing.T) {
+	var mu sync.Mutex
+	var sig string
+	var body []byte
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		sig = r.Header.Get(SignatureHeader)
+		body, _ = io.ReadAll(r.Body)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	m := New()
+	m.Init(context.Background(), plugin.Dependencies{
+		Logger: zap.NewNop(),
+		Config: &testConfig{values: map[string]any{
+			"url":    srv.URL,
+			"secret": "hook-secret",
+		}},
+	})
+
+	m.handleEvent(context.Background(), plugin.Event{
+		Topic:     recon.TopicDeviceLost,
+		Source:    "recon",
+		Timestamp: time.Now(),
+		Payload:   map[string]string{"ip": "192.168.1.1"},
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !strings.HasPrefix(sig, "sha256=") {
+		t.Fatalf("%s = %q, want sha256= prefix", SignatureHeader, sig)
+	}
+	if !Verify("hook-secret", body, sig) {
+		t.Error("signature does not verify against body")
+	}
+	if Verify("wrong-secret", body, sig) {
+		t.Error("signature verified with wrong secret")
+	}
+}
+
 // testConfig is a minimal plugin.Config for tests.
 type testConfig struct {
 	values map[string]any
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/HerbHall/subnetree/internal/auth"
+	"github.com/HerbHall/subnetree/internal/event"
 	"github.com/HerbHall/subnetree/internal/recon"
 	"github.com/HerbHall/subnetree/pkg/models"
 	"github.com/HerbHall/subnetree/pkg/plugin"
@@ -13,12 +14,17 @@ import (
 	"github.com/coder/websocket"
 )
 
-// Handler provides WebSocket endpoints for real-time scan updates.
+// Handler provides WebSocket endpoints for real-time scan updates and a
+// server-sent events stream of the event bus.
 type Handler struct {
 	hub    *Hub
 	tokens *auth.TokenService
 	bus    plugin.EventBus
 	logger *zap.Logger
+
+	eventLog *event.Log
+	creds    auth.CredentialBackend
+	roles    auth.RoleResolver
 }
 
 // Compile-time check that Handler implements the server interface.
@@ -38,9 +44,25 @@ func NewHandler(tokens *auth.TokenService, bus plugin.EventBus, logger *zap.Logg
 	return h
 }
 
+// SetEventLog sets the persistent event log used to replay history to
+// event stream clients that connect with a since parameter.
+func (h *Handler) SetEventLog(l *event.Log) {
+	h.eventLog = l
+}
+
+// SetAccessControl sets the credential backend and role resolver used to
+// authenticate event stream clients and filter their topics. Without a
+// backend only access tokens are accepted and session revocation is not
+// checked; without a resolver the built-in roles apply.
+func (h *Handler) SetAccessControl(creds auth.CredentialBackend, roles auth.RoleResolver) {
+	h.creds = creds
+	h.roles = roles
+}
+
 // RegisterRoutes registers WebSocket routes on the server mux.
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/v1/ws/scan", h.handleScanStream)
+	mux.HandleFunc("GET /api/v1/events/stream", h.handleEventStream)
 }
 
 // handleScanStream upgrades the connection to WebSocket and streams scan events.
@@ -0,0 +1,247 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/HerbHall/subnetree/internal/auth"
+	"github.com/HerbHall/subnetree/internal/event"
+	"github.com/HerbHall/subnetree/pkg/plugin"
+	"go.uber.org/zap"
+)
+
+// sseHeartbeatInterval is how often an idle stream sends a comment line so
+// proxies do not close the connection.
+const sseHeartbeatInterval = 15 * time.Second
+
+// sseBufferSize is the number of events buffered per client. Events are
+// dropped for clients that fall further behind.
+const sseBufferSize = 64
+
+// sseReplayLimit caps the number of persisted events replayed to a client
+// that connects with a since parameter.
+const sseReplayLimit = 1000
+
+// sseRecheckInterval is how often an open stream re-checks its credential,
+// catching revoked API keys and role changes. Session revocations are
+// acted on as soon as they are published.
+const sseRecheckInterval = time.Minute
+
+// StreamEvent is the data payload of each server-sent event.
+type StreamEvent struct {
+	Topic     string    `json:"topic"`
+	Source    string    `json:"source"`
+	Timestamp time.Time `json:"timestamp"`
+	Payload   any       `json:"payload"`
+}
+
+// handleEventStream streams event bus events as text/event-stream.
+//
+//	@Summary		Stream events
+//	@Description	Streams event bus events as server-sent events. Each event's name is its topic and its data is a JSON StreamEvent. Authenticate with the token query parameter (EventSource cannot set headers) or a Bearer header. Only topics of modules the caller's role (and API key scopes) can read are sent; auth topics are for admins. The stream closes when the access token expires or its session is revoked. With since, events recorded by the persistent event log are replayed before live events; since is ignored when the event log is disabled.
+//	@Tags			events
+//	@Produce		text/event-stream
+//	@Param			token	query		string	false	"JWT access token or API key"
+//	@Param			topics	query		string	false	"Comma-separated topics to include; a trailing * matches a prefix (e.g. recon.*)"
+//	@Param			since	query		string	false	"Replay persisted events at or after this RFC 3339 time"
+//	@Success		200		{object}	StreamEvent
+//	@Failure		400		{string}	string	"Invalid since parameter"
+//	@Failure		401		{string}	string	"Missing or invalid token"
+//	@Failure		403		{string}	string	"Unknown role"
+//	@Router			/events/stream [get]
+func (h *Handler) handleEventStream(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	if token == "" {
+		http.Error(w, "missing token parameter", http.StatusUnauthorized)
+		return
+	}
+	access, err := auth.AuthenticateStream(r.Context(), h.tokens, h.creds, h.roles, token)
+	if errors.Is(err, auth.ErrStreamForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, "invalid, expired, or revoked token", http.StatusUnauthorized)
+		return
+	}
+	if h.bus == nil {
+		http.Error(w, "event bus unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	var since time.Time
+	if s := r.URL.Query().Get("since"); s != "" {
+		if since, err = time.Parse(time.RFC3339, s); err != nil {
+			http.Error(w, "since must be an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// The server's write timeout would otherwise end the stream.
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	requested := topicMatcher(r.URL.Query().Get("topics"))
+	match := func(topic string) bool {
+		return requested(topic) && access.CanReadTopic(topic)
+	}
+	events := make(chan plugin.Event, sseBufferSize)
+	recheck := make(chan struct{}, 1)
+	unsubscribe := h.bus.SubscribeAll(func(_ context.Context, ev plugin.Event) {
+		if rev, ok := ev.Payload.(*auth.SessionRevokedEvent); ok && rev.UserID == access.Claims.UserID {
+			select {
+			case recheck <- struct{}{}:
+			default:
+			}
+		}
+		if !match(ev.Topic) {
+			return
+		}
+		select {
+		case events <- ev:
+		default:
+			h.logger.Debug("dropping event for slow SSE client",
+				zap.String("user_id", access.Claims.UserID),
+				zap.String("topic", ev.Topic),
+			)
+		}
+	})
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // disable nginx response buffering
+	w.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprint(w, ": connected\n\n"); err != nil {
+		return
+	}
+	if err := rc.Flush(); err != nil {
+		h.logger.Warn("SSE stream not flushable", zap.Error(err))
+		return
+	}
+
+	// Replay history after subscribing so no event falls between the two.
+	// Live events already covered by the replay are skipped below.
+	var id uint64
+	var replayedUntil time.Time
+	if h.eventLog != nil && !since.IsZero() {
+		records, err := h.eventLog.History(r.Context(), event.HistoryFilter{Since: since, Limit: sseReplayLimit})
+		if err != nil {
+			h.logger.Warn("failed to replay event history", zap.Error(err))
+		}
+		for i := range records {
+			if !match(records[i].Topic) {
+				continue
+			}
+			id++
+			if !h.writeStreamEvent(w, id, records[i].Event()) {
+				return
+			}
+			replayedUntil = records[i].Timestamp
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+	revalidate := time.NewTicker(sseRecheckInterval)
+	defer revalidate.Stop()
+	var expired <-chan time.Time
+	if !access.ExpiresAt.IsZero() {
+		expiry := time.NewTimer(time.Until(access.ExpiresAt))
+		defer expiry.Stop()
+		expired = expiry.C
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-expired:
+			return
+		case <-recheck:
+			if !access.Active(r.Context()) {
+				return
+			}
+		case <-revalidate.C:
+			if !access.Active(r.Context()) {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case ev := <-events:
+			if !replayedUntil.IsZero() && !ev.Timestamp.After(replayedUntil) {
+				continue
+			}
+			id++
+			if !h.writeStreamEvent(w, id, ev) {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeStreamEvent writes one server-sent event. Events whose payload cannot
+// be encoded are skipped. Returns false if the client connection failed.
+func (h *Handler) writeStreamEvent(w http.ResponseWriter, id uint64, ev plugin.Event) bool {
+	data, err := json.Marshal(StreamEvent{
+		Topic:     ev.Topic,
+		Source:    ev.Source,
+		Timestamp: ev.Timestamp,
+		Payload:   ev.Payload,
+	})
+	if err != nil {
+		h.logger.Debug("skipping unencodable event", zap.String("topic", ev.Topic), zap.Error(err))
+		return true
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, ev.Topic, data)
+	return err == nil
+}
+
+// topicMatcher returns a filter for a comma-separated topic list. Entries
+// ending in "*" match by prefix. An empty list matches every topic.
+func topicMatcher(list string) func(topic string) bool {
+	var exact []string
+	var prefixes []string
+	for _, t := range strings.Split(list, ",") {
+		t = strings.TrimSpace(t)
+		switch {
+		case t == "":
+		case strings.HasSuffix(t, "*"):
+			prefixes = append(prefixes, strings.TrimSuffix(t, "*"))
+		default:
+			exact = append(exact, t)
+		}
+	}
+	if len(exact) == 0 && len(prefixes) == 0 {
+		return func(string) bool { return true }
+	}
+	return func(topic string) bool {
+		for _, t := range exact {
+			if topic == t {
+				return true
+			}
+		}
+		for _, p := range prefixes {
+			if strings.HasPrefix(topic, p) {
+				return true
+			}
+		}
+		return false
+	}
+}
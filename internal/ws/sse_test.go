@@ -0,0 +1,229 @@
+package ws
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HerbHall/subnetree/internal/auth"
+	"github.com/HerbHall/subnetree/internal/event"
+	"github.com/HerbHall/subnetree/pkg/plugin"
+)
+
+func TestTopicMatcher(t *testing.T) {
+	tests := []struct {
+		list  string
+		topic string
+		want  bool
+	}{
+		{"", "recon.device.discovered", true},
+		{"recon.device.discovered", "recon.device.discovered", true},
+		{"recon.device.discovered", "recon.device.updated", false},
+		{"pulse.alert.triggered, recon.*", "recon.scan.completed", true},
+		{"recon.*", "pulse.alert.triggered", false},
+	}
+	for _, tt := range tests {
+		if got := topicMatcher(tt.list)(tt.topic); got != tt.want {
+			t.Errorf("topicMatcher(%q)(%q) = %v, want %v", tt.list, tt.topic, got, tt.want)
+		}
+	}
+}
+
+func TestHandleEventStream(t *testing.T) {
+	tokens := auth.NewTokenService([]byte("test-secret-for-sse-stream-tests-0123456789"), time.Minute, time.Hour)
+	token, err := tokens.IssueAccessToken(&auth.User{ID: "u1", Username: "admin", Role: "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus := event.NewBus(testLogger())
+	h := NewHandler(tokens, bus, testLogger())
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	t.Run("rejects missing token", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + "/api/v1/events/stream")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("streams filtered events", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet,
+			srv.URL+"/api/v1/events/stream?topics=pulse.*&token="+token, http.NoBody)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+		}
+
+		reader := bufio.NewReader(resp.Body)
+		// Wait for the connected comment so the subscription is in place.
+		if line, _ := reader.ReadString('\n'); !strings.HasPrefix(line, ": connected") {
+			t.Fatalf("first line = %q, want connected comment", line)
+		}
+
+		_ = bus.Publish(ctx, plugin.Event{Topic: "recon.scan.started", Source: "recon", Payload: map[string]string{"id": "skip"}})
+		_ = bus.Publish(ctx, plugin.Event{Topic: "pulse.alert.triggered", Source: "pulse", Payload: map[string]string{"id": "a1"}})
+
+		var lines []string
+		for len(lines) < 3 {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read stream: %v", err)
+			}
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		if lines[0] != "id: 1" || lines[1] != "event: pulse.alert.triggered" {
+			t.Fatalf("event lines = %q, want id 1 for pulse.alert.triggered", lines)
+		}
+		if !strings.Contains(lines[2], `"payload":{"id":"a1"}`) {
+			t.Errorf("data line = %q, want the a1 payload", lines[2])
+		}
+	})
+}
+
+// fakeCredentials is a CredentialBackend whose sessions can be revoked.
+type fakeCredentials struct {
+	mu      sync.Mutex
+	revoked map[string]bool
+}
+
+func (f *fakeCredentials) AuthenticateAPIKey(context.Context, string) (*auth.Claims, *auth.APIKey, error) {
+	return nil, nil, errors.New("no api keys")
+}
+
+func (f *fakeCredentials) SessionActive(_ context.Context, sessionID string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return !f.revoked[sessionID]
+}
+
+func (f *fakeCredentials) revoke(sessionID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.revoked[sessionID] = true
+}
+
+// openStream connects to the event stream and waits for the connected comment.
+func openStream(t *testing.T, url string) *bufio.Reader {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	reader := bufio.NewReader(resp.Body)
+	if line, _ := reader.ReadString('\n'); !strings.HasPrefix(line, ": connected") {
+		t.Fatalf("first line = %q, want connected comment", line)
+	}
+	return reader
+}
+
+// waitForClose reads until the server ends the stream.
+func waitForClose(t *testing.T, reader *bufio.Reader) {
+	t.Helper()
+	for {
+		if _, err := reader.ReadString('\n'); err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.Errorf("stream ended with %v, want the server to close it", err)
+			}
+			return
+		}
+	}
+}
+
+func TestHandleEventStream_AccessControl(t *testing.T) {
+	tokens := auth.NewTokenService([]byte("test-secret-for-sse-stream-tests-0123456789"), time.Minute, time.Hour)
+	token, err := tokens.IssueSessionAccessToken(&auth.User{ID: "u2", Username: "viewer", Role: auth.RoleViewer}, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds := &fakeCredentials{revoked: map[string]bool{}}
+	bus := event.NewBus(testLogger())
+	h := NewHandler(tokens, bus, testLogger())
+	h.SetAccessControl(creds, nil)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx := context.Background()
+	reader := openStream(t, srv.URL+"/api/v1/events/stream?token="+token)
+
+	// Viewers cannot read vault or auth events.
+	_ = bus.Publish(ctx, plugin.Event{Topic: "vault.credential.created", Source: "vault"})
+	_ = bus.Publish(ctx, plugin.Event{Topic: auth.TopicLoginFailed, Source: "auth"})
+	_ = bus.Publish(ctx, plugin.Event{Topic: "recon.scan.started", Source: "recon"})
+	var got []string
+	for len(got) < 2 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read stream: %v", err)
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			got = append(got, line)
+		}
+	}
+	if got[1] != "event: recon.scan.started" {
+		t.Fatalf("first event = %q, want recon.scan.started", got[1])
+	}
+
+	creds.revoke("s1")
+	_ = bus.Publish(ctx, plugin.Event{
+		Topic:   auth.TopicSessionRevoked,
+		Source:  "auth",
+		Payload: &auth.SessionRevokedEvent{UserID: "u2", SessionID: "s1"},
+	})
+	waitForClose(t, reader)
+
+	// The revoked session cannot reconnect.
+	resp, err := http.Get(srv.URL + "/api/v1/events/stream?token=" + token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("reconnect status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestHandleEventStream_ClosesOnExpiry(t *testing.T) {
+	tokens := auth.NewTokenService([]byte("test-secret-for-sse-stream-tests-0123456789"), 2*time.Second, time.Hour)
+	token, err := tokens.IssueAccessToken(&auth.User{ID: "u1", Username: "admin", Role: auth.RoleAdmin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewHandler(tokens, event.NewBus(testLogger()), testLogger())
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	waitForClose(t, openStream(t, srv.URL+"/api/v1/events/stream?token="+token))
+}
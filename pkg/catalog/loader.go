@@ -46,5 +46,8 @@ func (c *Catalog) load() {
 		c.err = fmt.Errorf("catalog: parse yaml: %w", err)
 		return
 	}
+	for i := range f.Entries {
+		f.Entries[i].Source = SourceBuiltin
+	}
 	c.entries = f.Entries
 }
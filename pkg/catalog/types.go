@@ -43,6 +43,14 @@ const (
 	IntegrationPossible IntegrationStatus = "possible" // Feasible, not scheduled
 )
 
+// EntrySource records which catalog an entry came from.
+type EntrySource string
+
+const (
+	SourceBuiltin EntrySource = "builtin" // Embedded catalog.yaml
+	SourceCustom  EntrySource = "custom"  // User-defined entry
+)
+
 // CatalogEntry represents a single tool in the recommendation catalog.
 type CatalogEntry struct {
 	Name              string            `yaml:"name" json:"name"`
@@ -60,4 +68,8 @@ type CatalogEntry struct {
 	IntegrationStatus IntegrationStatus `yaml:"integration_status" json:"integration_status"`
 	IntegrationNotes  string            `yaml:"integration_notes" json:"integration_notes"`
 	Tags              []string          `yaml:"tags" json:"tags"`
+	DefaultPorts      []int             `yaml:"default_ports" json:"default_ports,omitempty"`
+	DocsURL           string            `yaml:"docs_url" json:"docs_url,omitempty"`
+	Icon              string            `yaml:"icon" json:"icon,omitempty"`
+	Source            EntrySource       `yaml:"-" json:"source"`
 }
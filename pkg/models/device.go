@@ -25,6 +25,25 @@ const (
 	DeviceTypeUnknown     DeviceType = "unknown"
 )
 
+// DeviceTypes lists every known DeviceType.
+var DeviceTypes = []DeviceType{
+	DeviceTypeServer, DeviceTypeDesktop, DeviceTypeLaptop, DeviceTypeMobile,
+	DeviceTypeRouter, DeviceTypeSwitch, DeviceTypePrinter, DeviceTypeIoT,
+	DeviceTypeAccessPoint, DeviceTypeFirewall, DeviceTypeNAS, DeviceTypePhone,
+	DeviceTypeTablet, DeviceTypeCamera, DeviceTypeVM, DeviceTypeContainer,
+	DeviceTypeUnknown,
+}
+
+// Valid reports whether dt is one of DeviceTypes.
+func (dt DeviceType) Valid() bool {
+	for _, known := range DeviceTypes {
+		if dt == known {
+			return true
+		}
+	}
+	return false
+}
+
 // DeviceStatus represents the current state of a device.
 type DeviceStatus string
 
@@ -50,6 +69,7 @@ const (
 	DiscoveryWiFi    DiscoveryMethod = "wifi"
 	DiscoveryProxmox   DiscoveryMethod = "proxmox"
 	DiscoveryTailscale DiscoveryMethod = "tailscale"
+	DiscoveryDHCP      DiscoveryMethod = "dhcp"
 )
 
 // Device represents a network device tracked by SubNetree.
@@ -0,0 +1,16 @@
+package models
+
+import "testing"
+
+func TestDeviceTypeValid(t *testing.T) {
+	for _, dt := range DeviceTypes {
+		if !dt.Valid() {
+			t.Errorf("DeviceType %q not valid", dt)
+		}
+	}
+	for _, dt := range []DeviceType{"reuter", "", "Router"} {
+		if dt.Valid() {
+			t.Errorf("DeviceType %q valid, want invalid", dt)
+		}
+	}
+}
@@ -71,6 +71,15 @@ type DeviceService struct {
 	CollectedAt      *time.Time `json:"collected_at,omitempty"`
 }
 
+// DevicePort is an open port observed on a device during a scan.
+type DevicePort struct {
+	DeviceID string    `json:"device_id"`
+	Port     int       `json:"port" example:"445"`
+	Protocol string    `json:"protocol" example:"tcp"`
+	Service  string    `json:"service,omitempty" example:"smb"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
 // HardwareSummary provides fleet-wide aggregate hardware statistics.
 type HardwareSummary struct {
 	TotalWithHardware int            `json:"total_with_hardware"`
@@ -30,6 +30,9 @@ type ScanResult struct {
 	Devices   []Device `json:"devices,omitempty"`
 	Total     int      `json:"total" example:"12"`
 	Online    int      `json:"online" example:"8"`
+
+	// TemplateID is the scan template the scan ran with, if any.
+	TemplateID string `json:"template_id,omitempty"`
 }
 
 // ScanMetrics holds detailed timing and performance data for a scan.
@@ -43,16 +46,25 @@ type ScanMetrics struct {
 	HostsAlive    int    `json:"hosts_alive" db:"hosts_alive"`
 	DevicesCreated int   `json:"devices_created" db:"devices_created"`
 	DevicesUpdated int   `json:"devices_updated" db:"devices_updated"`
+	// Effective scan tuning the scan ran with, for correlating settings
+	// with duration.
+	Concurrency         int   `json:"concurrency" db:"concurrency"`
+	PingTimeoutMs       int64 `json:"ping_timeout_ms" db:"ping_timeout_ms"`
+	PortScanConcurrency int   `json:"port_scan_concurrency" db:"port_scan_concurrency"`
+	PortTimeoutMs       int64 `json:"port_timeout_ms" db:"port_timeout_ms"`
 	CreatedAt     string `json:"created_at" db:"created_at"`
 }
 
 // AgentInfo represents the state of a connected Scout agent.
 type AgentInfo struct {
-	ID          string `json:"id" example:"agent-550e8400"`
-	DeviceID    string `json:"device_id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Version     string `json:"version" example:"0.1.0"`
-	Status      string `json:"status" example:"connected"`
-	LastCheckIn string `json:"last_check_in" example:"2026-01-15T10:30:00Z"`
-	EnrolledAt  string `json:"enrolled_at" example:"2026-01-10T08:00:00Z"`
-	Platform    string `json:"platform" example:"linux/amd64"`
+	ID          string   `json:"id" example:"agent-550e8400"`
+	DeviceID    string   `json:"device_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Version     string   `json:"version" example:"0.1.0"`
+	Status      string   `json:"status" example:"connected"`
+	Online      bool     `json:"online" example:"true"`
+	LastCheckIn string   `json:"last_check_in" example:"2026-01-15T10:30:00Z"`
+	EnrolledAt  string   `json:"enrolled_at" example:"2026-01-10T08:00:00Z"`
+	Platform    string   `json:"platform" example:"linux/amd64"`
+	Group       string   `json:"group" example:"edge"`
+	Tags        []string `json:"tags" example:"rack-a,poe"`
 }
@@ -33,19 +33,20 @@ const (
 
 // Service represents a tracked service on a device.
 type Service struct {
-	ID            string        `json:"id" example:"svc-550e8400-e29b-41d4-a716-446655440000"`
-	Name          string        `json:"name" example:"nginx"`
-	DisplayName   string        `json:"display_name" example:"NGINX Web Server"`
-	ServiceType   ServiceType   `json:"service_type" example:"docker-container"`
-	DeviceID      string        `json:"device_id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	ApplicationID string        `json:"application_id,omitempty" example:"app-001"`
-	Status        ServiceStatus `json:"status" example:"running"`
-	DesiredState  DesiredState  `json:"desired_state" example:"should-run"`
-	Ports         []string      `json:"ports,omitempty"`
-	CPUPercent    float64       `json:"cpu_percent" example:"12.5"`
-	MemoryBytes   int64         `json:"memory_bytes" example:"134217728"`
-	FirstSeen     time.Time     `json:"first_seen" example:"2026-01-10T08:00:00Z"`
-	LastSeen      time.Time     `json:"last_seen" example:"2026-02-13T10:30:00Z"`
+	ID            string            `json:"id" example:"svc-550e8400-e29b-41d4-a716-446655440000"`
+	Name          string            `json:"name" example:"nginx"`
+	DisplayName   string            `json:"display_name" example:"NGINX Web Server"`
+	ServiceType   ServiceType       `json:"service_type" example:"docker-container"`
+	DeviceID      string            `json:"device_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ApplicationID string            `json:"application_id,omitempty" example:"app-001"`
+	Status        ServiceStatus     `json:"status" example:"running"`
+	DesiredState  DesiredState      `json:"desired_state" example:"should-run"`
+	Ports         []string          `json:"ports,omitempty"`
+	PortNames     map[string]string `json:"port_names,omitempty"` // Port entry -> well-known service name
+	CPUPercent    float64           `json:"cpu_percent" example:"12.5"`
+	MemoryBytes   int64             `json:"memory_bytes" example:"134217728"`
+	FirstSeen     time.Time         `json:"first_seen" example:"2026-01-10T08:00:00Z"`
+	LastSeen      time.Time         `json:"last_seen" example:"2026-02-13T10:30:00Z"`
 }
 
 // UtilizationSummary provides resource usage and grading for a single device.
@@ -71,6 +71,12 @@ export interface ListDevicesParams {
   type?: string
   category?: string
   owner?: string
+  open_port?: number
+  /** Custom field filters keyed by `name` or `name.lt|lte|gt|gte`. */
+  custom_fields?: Record<string, string>
+  /** Custom field to sort by; the default order is last seen. */
+  sort_field?: string
+  order?: 'asc' | 'desc'
 }
 
 /**
@@ -84,6 +90,14 @@ export async function listDevices(params: ListDevicesParams = {}): Promise<Devic
   if (params.type && params.type !== 'all') searchParams.set('type', params.type)
   if (params.category && params.category !== 'all') searchParams.set('category', params.category)
   if (params.owner && params.owner !== 'all') searchParams.set('owner', params.owner)
+  if (params.open_port) searchParams.set('open_port', String(params.open_port))
+  for (const [key, value] of Object.entries(params.custom_fields ?? {})) {
+    searchParams.set(`cf.${key}`, value)
+  }
+  if (params.sort_field) {
+    searchParams.set('sort', `cf.${params.sort_field}`)
+    if (params.order) searchParams.set('order', params.order)
+  }
   const query = searchParams.toString()
   return api.get<DeviceListResponse>(`/recon/devices${query ? `?${query}` : ''}`)
 }
@@ -170,6 +184,9 @@ export interface InventorySummary {
   total_devices: number
   online_count: number
   offline_count: number
+  /** Devices with degraded or unknown status. */
+  other_count: number
+  /** Online devices not seen within stale_days; already included in online_count. */
   stale_count: number
   by_category: Record<string, number>
   by_type: Record<string, number>
@@ -239,3 +256,54 @@ export async function updateTopologyLayout(id: string, name: string, positions:
 export async function deleteTopologyLayout(id: string): Promise<void> {
   return api.delete<void>(`/recon/topology/layouts/${id}`)
 }
+
+// ============================================================================
+// Custom Field Definitions
+// ============================================================================
+
+export type CustomFieldType = 'string' | 'number' | 'bool' | 'date' | 'enum'
+
+/**
+ * Typed definition of a device custom field.
+ */
+export interface CustomFieldDef {
+  name: string
+  label?: string
+  type: CustomFieldType
+  options?: string[]
+  created_at: string
+  updated_at: string
+}
+
+/**
+ * List all custom field definitions.
+ */
+export async function listCustomFields(): Promise<CustomFieldDef[]> {
+  return api.get<CustomFieldDef[]>('/recon/custom-fields')
+}
+
+/**
+ * Define a new custom field.
+ */
+export async function createCustomField(
+  def: Pick<CustomFieldDef, 'name' | 'label' | 'type' | 'options'>
+): Promise<CustomFieldDef> {
+  return api.post<CustomFieldDef>('/recon/custom-fields', def)
+}
+
+/**
+ * Update the label, type, or options of a custom field.
+ */
+export async function updateCustomField(
+  name: string,
+  def: Partial<Pick<CustomFieldDef, 'label' | 'type' | 'options'>>
+): Promise<CustomFieldDef> {
+  return api.put<CustomFieldDef>(`/recon/custom-fields/${name}`, def)
+}
+
+/**
+ * Delete a custom field definition. Device values are kept.
+ */
+export async function deleteCustomField(name: string): Promise<void> {
+  return api.delete<void>(`/recon/custom-fields/${name}`)
+}
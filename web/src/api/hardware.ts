@@ -69,6 +69,14 @@ export interface DeviceService {
   collected_at?: string
 }
 
+export interface DevicePort {
+  device_id: string
+  port: number
+  protocol: string
+  service?: string
+  last_seen: string
+}
+
 export interface DeviceHardwareResponse {
   hardware: DeviceHardware | null
   storage: DeviceStorage[]
@@ -98,6 +106,13 @@ export async function getDeviceHardware(id: string): Promise<DeviceHardwareRespo
   return api.get<DeviceHardwareResponse>(`/recon/devices/${id}/hardware`)
 }
 
+/**
+ * Fetch the open ports recorded for a device by network scans.
+ */
+export async function getDevicePorts(id: string): Promise<DevicePort[]> {
+  return api.get<DevicePort[]>(`/recon/devices/${id}/ports`)
+}
+
 /**
  * Update (or create) a device's hardware profile manually.
  */
@@ -8,6 +8,7 @@ export interface MaintWindow {
   start_time: string
   end_time: string
   recurrence: 'once' | 'daily' | 'weekly' | 'monthly'
+  timezone?: string
   device_ids: string[]
   enabled: boolean
   created_at: string
@@ -20,6 +21,7 @@ export interface CreateMaintWindowRequest {
   start_time: string
   end_time: string
   recurrence: string
+  timezone?: string
   device_ids: string[]
 }
 
@@ -29,6 +31,7 @@ export interface UpdateMaintWindowRequest {
   start_time?: string
   end_time?: string
   recurrence?: string
+  timezone?: string
   device_ids?: string[]
   enabled?: boolean
 }
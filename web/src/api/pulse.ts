@@ -209,14 +209,17 @@ export async function testChannel(id: string): Promise<void> {
 // ============================================================================
 
 /**
- * Get time-series metric data for a device.
+ * Get time-series metric data for a device. Pass a vantage ('server' or an
+ * agent ID) to limit the series to results from that location.
  */
 export async function getDeviceMetrics(
   deviceId: string,
   metric: MetricName,
-  range: MetricRange
+  range: MetricRange,
+  vantage?: string
 ): Promise<MetricSeries> {
+  const vantageParam = vantage ? `&vantage=${encodeURIComponent(vantage)}` : ''
   return api.get<MetricSeries>(
-    `/pulse/metrics/${deviceId}?metric=${metric}&range=${range}`
+    `/pulse/metrics/${deviceId}?metric=${metric}&range=${range}${vantageParam}`
   )
 }
@@ -11,6 +11,11 @@ export interface RawScanMetric {
   hosts_alive: number
   devices_created: number
   devices_updated: number
+  /** Effective scan tuning the scan ran with. */
+  concurrency: number
+  ping_timeout_ms: number
+  port_scan_concurrency: number
+  port_timeout_ms: number
   created_at: string
 }
 
@@ -67,3 +72,30 @@ export async function getAggregateMetrics(
     `/recon/metrics/aggregates?period=${period}&limit=${limit}`,
   )
 }
+
+/** One bucket of the device growth timeline. */
+export interface GrowthPoint {
+  period_start: string
+  new: number
+  total: number
+  groups?: Record<string, number>
+}
+
+/** Device growth timeline from the recon module. */
+export interface GrowthResponse {
+  range: string
+  interval: 'daily' | 'weekly'
+  group_by?: 'category' | 'type'
+  points: GrowthPoint[]
+}
+
+/** Fetch device counts over time, bucketed by first_seen. */
+export async function getDeviceGrowth(
+  range = '90d',
+  interval: 'daily' | 'weekly' = 'daily',
+  groupBy?: 'category' | 'type',
+): Promise<GrowthResponse> {
+  const params = new URLSearchParams({ range, interval })
+  if (groupBy) params.set('group_by', groupBy)
+  return api.get<GrowthResponse>(`/recon/stats/growth?${params.toString()}`)
+}
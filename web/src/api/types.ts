@@ -42,11 +42,22 @@ export interface VersionInfo {
   arch: string
 }
 
+/** Health of a single server component (database, plugin, or core service). */
+export interface ComponentHealth {
+  name: string
+  type: 'core' | 'plugin'
+  status: 'ok' | 'degraded' | 'down'
+  critical: boolean
+  message?: string
+  details?: Record<string, string>
+}
+
 /** Health check response from GET /api/v1/health. */
 export interface HealthResponse {
-  status: string
+  status: 'ok' | 'degraded' | 'down'
   service: string
   version: VersionInfo
+  components: ComponentHealth[]
 }
 
 // ============================================================================
@@ -77,7 +88,7 @@ export type DeviceType =
   | 'unknown'
 
 /** How the device was discovered. */
-export type DiscoveryMethod = 'agent' | 'icmp' | 'arp' | 'snmp' | 'mdns' | 'upnp' | 'wifi' | 'proxmox' | 'tailscale'
+export type DiscoveryMethod = 'agent' | 'icmp' | 'arp' | 'snmp' | 'mdns' | 'upnp' | 'wifi' | 'proxmox' | 'tailscale' | 'dhcp'
 
 /** How the device connects to the network. */
 export type ConnectionType = 'wired' | 'wifi' | 'unknown'
@@ -375,6 +386,14 @@ export interface Check {
   check_type: CheckType
   target: string
   interval_seconds: number
+  /** Scout agent that runs the check; absent when run from the server. */
+  agent_id?: string
+  /** Days of results to keep; absent when the global retention period applies. */
+  retention_days?: number
+  /** Consecutive failures before a warning alert; absent uses the global default. */
+  warning_after?: number
+  /** Consecutive failures before the alert is critical; absent uses twice warning_after. */
+  critical_after?: number
   enabled: boolean
   created_at: string
   updated_at: string
@@ -389,6 +408,8 @@ export interface CheckResult {
   latency_ms: number
   packet_loss: number
   error_message?: string
+  /** Vantage point that produced the result; absent for the server. */
+  agent_id?: string
   checked_at: string
 }
 
@@ -406,6 +427,7 @@ export interface Alert {
   consecutive_failures: number
   suppressed: boolean
   suppressed_by?: string
+  maint_window_id?: string
 }
 
 /** A dependency between a check and an upstream device for alert suppression. */
@@ -421,6 +443,10 @@ export interface CreateCheckRequest {
   check_type: CheckType
   target: string
   interval_seconds?: number
+  agent_id?: string
+  retention_days?: number
+  warning_after?: number
+  critical_after?: number
 }
 
 /** Request body for updating a check. */
@@ -428,6 +454,13 @@ export interface UpdateCheckRequest {
   target?: string
   check_type?: CheckType
   interval_seconds?: number
+  /** Empty string moves the check back to the server. */
+  agent_id?: string
+  /** 0 returns the check to the global retention period. */
+  retention_days?: number
+  /** 0 returns the threshold to its default. */
+  warning_after?: number
+  critical_after?: number
   enabled?: boolean
 }
 
@@ -597,6 +630,8 @@ export interface MetricSeries {
   device_id: string
   metric: string
   range: string
+  /** 'server' or an agent ID when the series is limited to one vantage point. */
+  vantage?: string
   points: MetricDataPoint[]
 }
 
@@ -98,7 +98,7 @@ export function InventorySummaryWidget() {
                   {summary.stale_count > 0 && (
                     <AlertTriangle className="h-3 w-3 text-amber-500" />
                   )}
-                  Stale
+                  Stale (of online)
                 </p>
               </div>
             </div>
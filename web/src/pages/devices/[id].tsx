@@ -66,7 +66,7 @@ import { getDeviceServices, getDeviceUtilization, updateDesiredState } from '@/a
 import { getDeviceMetrics } from '@/api/pulse'
 import { getSNMPSystemInfo, getSNMPInterfaces, runTraceroute } from '@/api/recon'
 import { runDiagPing, runDiagDNS, runDiagPortCheck } from '@/api/diagnostics'
-import { getDeviceHardware } from '@/api/hardware'
+import { getDeviceHardware, getDevicePorts } from '@/api/hardware'
 import { ProxmoxResources } from '@/components/ProxmoxResources'
 import { listDeviceCredentials } from '@/api/vault'
 import type { DeviceType, DeviceStatus, Scan, Service, ServiceType, DesiredState, MetricName, MetricRange, TracerouteResult, DiagPingResult, DiagDNSResult, DiagPortCheckResult } from '@/api/types'
@@ -774,6 +774,9 @@ export function DeviceDetailPage() {
       {/* Hardware Profile */}
       {id && <HardwareProfileSection deviceId={id} />}
 
+      {/* Open Ports (recorded by network scans) */}
+      {id && <OpenPortsSection deviceId={id} />}
+
       {/* Virtualization (Proxmox VMs/containers) */}
       {id && device.device_type === 'server' && <ProxmoxResources deviceId={id} />}
 
@@ -1906,6 +1909,54 @@ function HardwareProfileSection({ deviceId }: { deviceId: string }) {
   )
 }
 
+function OpenPortsSection({ deviceId }: { deviceId: string }) {
+  const { data: ports } = useQuery({
+    queryKey: ['device-ports', deviceId],
+    queryFn: () => getDevicePorts(deviceId),
+    enabled: !!deviceId,
+    retry: false,
+  })
+
+  if (!ports || ports.length === 0) return null
+
+  return (
+    <Card>
+      <CardHeader className="pb-3">
+        <CardTitle className="text-sm font-medium flex items-center gap-2">
+          <Plug className="h-4 w-4 text-muted-foreground" />
+          Open Ports
+        </CardTitle>
+      </CardHeader>
+      <CardContent>
+        <div className="rounded-lg border overflow-hidden">
+          <table className="w-full text-sm">
+            <thead className="bg-muted/50">
+              <tr>
+                <th className="px-4 py-2 text-right font-medium">Port</th>
+                <th className="px-4 py-2 text-left font-medium">Protocol</th>
+                <th className="px-4 py-2 text-left font-medium">Service</th>
+                <th className="px-4 py-2 text-left font-medium">Last Seen</th>
+              </tr>
+            </thead>
+            <tbody className="divide-y">
+              {ports.map((p) => (
+                <tr key={`${p.protocol}-${p.port}`} className="hover:bg-muted/30 transition-colors">
+                  <td className="px-4 py-2 text-right font-mono">{p.port}</td>
+                  <td className="px-4 py-2 text-xs uppercase">{p.protocol}</td>
+                  <td className="px-4 py-2">{p.service || '-'}</td>
+                  <td className="px-4 py-2 text-xs text-muted-foreground">
+                    {new Date(p.last_seen).toLocaleString()}
+                  </td>
+                </tr>
+              ))}
+            </tbody>
+          </table>
+        </div>
+      </CardContent>
+    </Card>
+  )
+}
+
 // ============================================================================
 // SNMP Components
 // ============================================================================
@@ -534,7 +534,13 @@ function AlertsTab() {
                       'hover:bg-muted/30 transition-colors',
                       alert.suppressed && 'opacity-50'
                     )}
-                    title={alert.suppressed ? `Suppressed: upstream device ${alert.suppressed_by ?? ''} is down` : undefined}
+                    title={
+                      alert.suppressed
+                        ? alert.maint_window_id
+                          ? 'Suppressed: device is in a maintenance window'
+                          : `Suppressed: upstream device ${alert.suppressed_by ?? ''} is down`
+                        : undefined
+                    }
                   >
                     <td className="px-4 py-3 text-sm" title={alert.device_id}>
                       {alert.device_name || alert.device_id.slice(0, 8) + '...'}
@@ -60,6 +60,8 @@ const EVENT_TYPE_CONFIG: Record<string, { label: string; color: string; chartCol
   'pulse.alert.triggered': { label: 'Alert', color: 'text-red-500 bg-red-500/10', chartColor: 'var(--nv-chart-red)' },
   'pulse.alert.resolved': { label: 'Resolved', color: 'text-green-500 bg-green-500/10', chartColor: 'var(--nv-chart-green)' },
   'pulse.device.status_changed': { label: 'Status Changed', color: 'text-amber-500 bg-amber-500/10', chartColor: 'var(--nv-chart-amber)' },
+  'recon.device.syslog.critical': { label: 'Syslog Critical', color: 'text-red-500 bg-red-500/10', chartColor: 'var(--nv-chart-red)' },
+  'recon.device.syslog.warning': { label: 'Syslog Warning', color: 'text-amber-500 bg-amber-500/10', chartColor: 'var(--nv-chart-amber)' },
 }
 
 // ---------------------------------------------------------------------------
@@ -85,10 +87,12 @@ function eventIcon(eventType: string) {
       return <ArrowRightLeft className={cn(iconClass, 'text-blue-400')} />
     case 'pulse.alert.fired':
     case 'pulse.alert.triggered':
+    case 'recon.device.syslog.critical':
       return <Bell className={cn(iconClass, 'text-red-500')} />
     case 'pulse.alert.resolved':
       return <BellOff className={cn(iconClass, 'text-green-500')} />
     case 'pulse.device.status_changed':
+    case 'recon.device.syslog.warning':
       return <Activity className={cn(iconClass, 'text-amber-500')} />
     case 'recon.device.updated':
       return <Radio className={cn(iconClass, 'text-blue-500')} />